@@ -4,11 +4,12 @@
 //
 //  1. Load configuration
 //  2. Register with control plane
-//  3. Fetch initial assignments
+//  3. Fetch remote config and initial assignments
 //  4. Start probe loops (one per tier)
 //  5. Start result shipper
 //  6. Start heartbeat loop
-//  7. Run until shutdown signal
+//  7. Serve GET /debug/status on loopback (health.debug_listen)
+//  8. Run until shutdown signal
 package agent
 
 import (
@@ -28,6 +29,7 @@ import (
 	"github.com/pilot-net/icmp-mon/agent/internal/executor"
 	"github.com/pilot-net/icmp-mon/agent/internal/scheduler"
 	"github.com/pilot-net/icmp-mon/agent/internal/shipper"
+	"github.com/pilot-net/icmp-mon/agent/internal/sysinfo"
 	"github.com/pilot-net/icmp-mon/agent/internal/updater"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
@@ -45,11 +47,20 @@ type Agent struct {
 	updater   *updater.Updater
 	logger    *slog.Logger
 
+	// icmpMode is how icmp_ping probes are sent (empty if none usable)
+	icmpMode executor.ICMPMode
+
 	// State
 	agentID           string
 	assignmentVersion int64
 	startTime         time.Time
 
+	// Remote config (see remote_config.go), guarded by mu
+	remote        types.AgentRemoteConfig
+	configVersion int64
+	logLevel      *slog.LevelVar
+	localLogLevel slog.Level
+
 	// Control
 	mu sync.Mutex
 }
@@ -70,20 +81,48 @@ func New(cfg *config.Config, logger *slog.Logger) (*Agent, error) {
 	if cfg.Probing.FpingPath != "" {
 		icmpExec.FpingPath = cfg.Probing.FpingPath
 	}
-	if err := registry.Register(icmpExec); err != nil {
+	icmpExec.RecordTTL = !cfg.Probing.DisableReplyTTL
+	if cfg.Probing.TCPFallbackPort > 0 {
+		icmpExec.TCPPort = cfg.Probing.TCPFallbackPort
+	}
+	if cfg.Probing.FailureLossPct > 0 {
+		icmpExec.FailureLossPct = cfg.Probing.FailureLossPct
+	}
+	if err := selectICMPMode(cfg, icmpExec, logger); err != nil {
+		return nil, err
+	}
+	source, err := sourceBinding(cfg, icmpExec.Type())
+	if err != nil {
+		return nil, err
+	}
+	icmpExec.Source = source
+	if icmpExec.Mode == "" {
+		// Nothing usable in the preference order: say so loudly rather than
+		// registering an executor whose every probe would fail
+		logger.Error("no usable ICMP mode, icmp_ping disabled", "preference", cfg.Probing.ICMPModes)
+	} else if err := registry.Register(icmpExec); err != nil {
 		logger.Warn("failed to register ICMP executor", "error", err)
 		// Continue without ICMP if fping not available
 	} else {
-		logger.Info("registered executor", "type", "icmp_ping")
+		logger.Info("registered executor", "type", "icmp_ping", "mode", icmpExec.Mode)
 	}
 
-	// Register MTR executor for on-demand path tracing
-	mtrExec := executor.NewMTRExecutor()
-	if err := registry.Register(mtrExec); err != nil {
-		logger.Warn("failed to register MTR executor", "error", err)
-		// Continue without MTR if mtr not available
-	} else {
-		logger.Info("registered executor", "type", "mtr")
+	// Register plugin executors (mtr and custom check types, see
+	// executor/plugin.go)
+	for _, name := range executor.Plugins() {
+		source, err := sourceBinding(cfg, name)
+		if err != nil {
+			return nil, err
+		}
+		plugin, err := executor.NewPlugin(name, executor.PluginConfig{Source: source})
+		if err == nil {
+			err = registry.Register(plugin)
+		}
+		if err != nil {
+			logger.Warn("failed to register executor plugin", "type", name, "error", err)
+			continue
+		}
+		logger.Info("registered executor", "type", name, "plugin", true)
 	}
 
 	logger.Info("executor registry ready", "executors", registry.List())
@@ -108,12 +147,76 @@ func New(cfg *config.Config, logger *slog.Logger) (*Agent, error) {
 		registry:  registry,
 		updater:   agentUpdater,
 		logger:    logger,
+		icmpMode:  icmpExec.Mode,
 		startTime: time.Now(),
 	}
 
 	return a, nil
 }
 
+// configureAlignment applies the configured schedule alignment, probing-wide
+// and per tier. An unknown alignment is a startup error.
+func configureAlignment(cfg *config.Config, sched *scheduler.Scheduler) error {
+	defaultAlignment, err := scheduler.ParseAlignment(cfg.Probing.ScheduleAlignment)
+	if err != nil {
+		return fmt.Errorf("probing.schedule_alignment: %w", err)
+	}
+	perTier := make(map[string]scheduler.Alignment)
+	for name, tier := range cfg.Tiers {
+		if tier.ScheduleAlignment == "" {
+			continue
+		}
+		alignment, err := scheduler.ParseAlignment(tier.ScheduleAlignment)
+		if err != nil {
+			return fmt.Errorf("tiers.%s.schedule_alignment: %w", name, err)
+		}
+		perTier[name] = alignment
+	}
+	sched.SetAlignment(defaultAlignment, perTier)
+	return nil
+}
+
+// selectICMPMode detects what the host allows and sets the ICMP executor's
+// mode to the first supported entry of the configured preference order. An
+// invalid preference list is a startup error; no supported mode leaves Mode
+// empty.
+func selectICMPMode(cfg *config.Config, icmpExec *executor.ICMPExecutor, logger *slog.Logger) error {
+	prefs, err := executor.ParseICMPModes(cfg.Probing.ICMPModes)
+	if err != nil {
+		return fmt.Errorf("probing.icmp_modes: %w", err)
+	}
+
+	support := executor.DetectICMPSupport(context.Background(), icmpExec.FpingPath)
+	mode, err := executor.SelectICMPMode(prefs, support)
+	if err != nil {
+		logger.Error("ICMP mode detection", "error", err)
+		return nil
+	}
+	if mode == executor.ICMPModeTCP {
+		logger.Warn("ICMP unavailable, probing with TCP connects; latency is not comparable with ICMP agents",
+			"tcp_port", icmpExec.TCPPort,
+			"fping_works", support.FpingWorks,
+			"unprivileged_icmp", support.Datagram)
+	}
+	icmpExec.Mode = mode
+	return nil
+}
+
+// sourceBinding resolves and validates the configured source binding for an
+// executor type. A bad binding is a startup error rather than silently
+// probing from the wrong path.
+func sourceBinding(cfg *config.Config, executorType string) (executor.SourceBinding, error) {
+	addr, iface := cfg.Probing.SourceFor(executorType)
+	binding := executor.SourceBinding{SourceAddress: addr, Interface: iface}
+	if binding.IsZero() {
+		return binding, nil
+	}
+	if err := binding.Validate(); err != nil {
+		return binding, fmt.Errorf("%s source binding: %w", executorType, err)
+	}
+	return binding, nil
+}
+
 // Run starts the agent and blocks until context is cancelled.
 func (a *Agent) Run(ctx context.Context) error {
 	a.logger.Info("starting agent",
@@ -133,13 +236,17 @@ func (a *Agent) Run(ctx context.Context) error {
 			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
 		}
 	}
+	endpoint, failovers := a.cfg.ControlPlane.ResultEndpoints()
 	a.shipper = shipper.NewShipper(shipper.Config{
-		Endpoint:     a.cfg.ControlPlane.URL + "/api/v1/results",
-		AgentID:      a.agentID,
-		BatchSize:    a.cfg.Probing.ResultBatchSize,
-		BatchTimeout: a.cfg.Probing.ResultBatchTimeout,
-		Client:       shipperClient,
-		Logger:       a.logger,
+		Endpoint:             endpoint,
+		AgentID:              a.agentID,
+		BatchSize:            a.cfg.Probing.ResultBatchSize,
+		BatchTimeout:         a.cfg.Probing.ResultBatchTimeout,
+		Client:               shipperClient,
+		Logger:               a.logger,
+		FailoverEndpoints:    failovers,
+		PrimaryRetryInterval: a.cfg.ControlPlane.PrimaryRetryInterval,
+		Protobuf:             a.cfg.Probing.ResultEncoding == config.ResultEncodingProtobuf,
 	})
 
 	// Create scheduler with result handler
@@ -154,12 +261,37 @@ func (a *Agent) Run(ctx context.Context) error {
 	// Set default tiers (will be overridden by control plane)
 	a.scheduler.SetTiers(defaultTiers())
 
+	poolConfigs := make(map[string]scheduler.PoolConfig)
+	for _, typ := range a.registry.List() {
+		maxConcurrent, queueSize := a.cfg.Probing.ConcurrencyFor(typ)
+		poolConfigs[typ] = scheduler.PoolConfig{MaxConcurrent: maxConcurrent, QueueSize: queueSize}
+	}
+	a.scheduler.SetPoolConfigs(poolConfigs)
+
+	if a.cfg.Probing.AdaptiveInterval {
+		a.scheduler.EnableAdaptive(scheduler.DefaultAdaptiveConfig())
+		a.logger.Info("adaptive probe intervals enabled")
+	}
+
+	if err := configureAlignment(a.cfg, a.scheduler); err != nil {
+		return err
+	}
+
+	// Apply remote config before probing starts
+	if err := a.syncConfig(ctx); err != nil {
+		a.logger.Warn("failed to fetch remote config, using local config", "error", err)
+	}
+
 	// Fetch initial assignments
 	if err := a.syncAssignments(ctx); err != nil {
 		a.logger.Warn("failed to fetch initial assignments", "error", err)
 		// Continue anyway, will retry
 	}
 
+	if a.cfg.Health.DebugEnabled() {
+		go a.serveDebug(ctx)
+	}
+
 	// Run all loops concurrently
 	errCh := make(chan error, 5)
 
@@ -195,6 +327,8 @@ func (a *Agent) Run(ctx context.Context) error {
 // register registers the agent with the control plane.
 func (a *Agent) register(ctx context.Context) error {
 	publicIP := getPublicIP()
+	sysInfo := sysinfo.Collect(ctx)
+	sysInfo.ICMPMode = string(a.icmpMode)
 
 	req := client.RegisterRequest{
 		Name:       a.cfg.Agent.Name,
@@ -206,6 +340,7 @@ func (a *Agent) register(ctx context.Context) error {
 		Version:    Version,
 		Executors:  a.registry.List(),
 		MaxTargets: 10000, // TODO: Make configurable
+		SystemInfo: sysInfo,
 	}
 
 	resp, err := a.client.Register(ctx, req)
@@ -216,7 +351,13 @@ func (a *Agent) register(ctx context.Context) error {
 	a.agentID = resp.AgentID
 	a.logger.Info("registered with control plane",
 		"agent_id", a.agentID,
-		"public_ip", publicIP)
+		"public_ip", publicIP,
+		"kernel", sysInfo.KernelVersion,
+		"virtualization", sysInfo.Virtualization,
+		"nic_driver", sysInfo.NICDriver,
+		"raw_socket", sysInfo.RawSocket,
+		"unprivileged_icmp", sysInfo.UnprivilegedICMP,
+		"icmp_mode", sysInfo.ICMPMode)
 
 	return nil
 }
@@ -228,7 +369,8 @@ func (a *Agent) runScheduler(ctx context.Context) error {
 
 // runHeartbeat sends periodic heartbeats to the control plane.
 func (a *Agent) runHeartbeat(ctx context.Context) error {
-	ticker := time.NewTicker(a.cfg.Health.HeartbeatInterval)
+	interval := a.heartbeatInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -239,10 +381,20 @@ func (a *Agent) runHeartbeat(ctx context.Context) error {
 			if err := a.sendHeartbeat(ctx); err != nil {
 				a.logger.Warn("heartbeat failed", "error", err)
 			}
+			interval = resetIfChanged(ticker, interval, a.heartbeatInterval())
 		}
 	}
 }
 
+// resetIfChanged resets a loop's ticker when the remote config has changed
+// its interval, returning the interval now in use.
+func resetIfChanged(ticker *time.Ticker, current, next time.Duration) time.Duration {
+	if next != current {
+		ticker.Reset(next)
+	}
+	return next
+}
+
 // sendHeartbeat sends a single heartbeat.
 func (a *Agent) sendHeartbeat(ctx context.Context) error {
 	stats := a.scheduler.Stats()
@@ -252,17 +404,30 @@ func (a *Agent) sendHeartbeat(ctx context.Context) error {
 	runtime.ReadMemStats(&m)
 
 	heartbeat := types.Heartbeat{
-		AgentID:           a.agentID,
-		Timestamp:         time.Now(),
-		Version:           Version,
-		Status:            types.AgentStatusActive,
-		ActiveTargets:     stats.TotalTargets,
-		ResultsQueued:     shipperStats.Queued,
-		ResultsShipped:    shipperStats.Shipped,
-		MemoryMB:          float64(m.Alloc) / 1024 / 1024,
-		GoroutineCount:    runtime.NumGoroutine(),
-		AssignmentVersion: a.assignmentVersion,
-		PublicIP:          getPublicIP(),
+		AgentID:                  a.agentID,
+		Timestamp:                time.Now(),
+		Version:                  Version,
+		Status:                   types.AgentStatusActive,
+		ActiveTargets:            stats.TotalTargets,
+		ResultsQueued:            shipperStats.Queued + stats.ProbesQueued,
+		ResultsShipped:           shipperStats.Shipped,
+		ResultsFailed:            shipperStats.Failed,
+		BatchesShipped:           shipperStats.BatchesShipped,
+		ShipFailures:             shipperStats.ShipFailures,
+		BytesShipped:             shipperStats.BytesShipped,
+		BytesShippedUncompressed: shipperStats.BytesUncompressed,
+		ShippingEndpoint:         shipperStats.ActiveEndpoint,
+		EndpointFailovers:        shipperStats.EndpointFailovers,
+		OldestQueuedAgeMs:        shipperStats.OldestQueuedAgeMs,
+		ProbesShedByTier:         stats.ProbesShedByTier,
+		ProbesBackedOffByTier:    stats.BackedOffByTier,
+		EffectiveIntervals:       stats.EffectiveIntervals,
+		ScheduleAlignment:        stats.ScheduleAlignment,
+		MemoryMB:                 float64(m.Alloc) / 1024 / 1024,
+		GoroutineCount:           runtime.NumGoroutine(),
+		AssignmentVersion:        a.assignmentVersion,
+		ConfigVersion:            a.appliedConfigVersion(),
+		PublicIP:                 getPublicIP(),
 	}
 
 	resp, err := a.client.Heartbeat(ctx, heartbeat)
@@ -276,6 +441,16 @@ func (a *Agent) sendHeartbeat(ctx context.Context) error {
 		go a.syncAssignments(context.Background())
 	}
 
+	// Re-fetch remote config if it changed since we applied it
+	if resp.ConfigStale {
+		a.logger.Info("remote config refresh requested")
+		go func() {
+			if err := a.syncConfig(context.Background()); err != nil {
+				a.logger.Warn("remote config sync failed", "error", err)
+			}
+		}()
+	}
+
 	// Execute any commands from heartbeat response
 	for _, cmd := range resp.Commands {
 		go a.executeCommand(context.Background(), cmd)
@@ -291,7 +466,8 @@ func (a *Agent) sendHeartbeat(ctx context.Context) error {
 
 // runAssignmentSync periodically syncs assignments from the control plane.
 func (a *Agent) runAssignmentSync(ctx context.Context) error {
-	ticker := time.NewTicker(a.cfg.Probing.AssignmentPollInterval)
+	interval := a.assignmentPollInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -302,6 +478,7 @@ func (a *Agent) runAssignmentSync(ctx context.Context) error {
 			if err := a.syncAssignments(ctx); err != nil {
 				a.logger.Warn("assignment sync failed", "error", err)
 			}
+			interval = resetIfChanged(ticker, interval, a.assignmentPollInterval())
 		}
 	}
 }
@@ -328,7 +505,8 @@ func (a *Agent) syncAssignments(ctx context.Context) error {
 
 // runCommandPolling polls for and executes on-demand commands.
 func (a *Agent) runCommandPolling(ctx context.Context) error {
-	ticker := time.NewTicker(5 * time.Second) // Poll every 5 seconds
+	interval := a.commandPollInterval()
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -336,6 +514,7 @@ func (a *Agent) runCommandPolling(ctx context.Context) error {
 		case <-ctx.Done():
 			return ctx.Err()
 		case <-ticker.C:
+			interval = resetIfChanged(ticker, interval, a.commandPollInterval())
 			commands, err := a.client.GetCommands(ctx)
 			if err != nil {
 				a.logger.Debug("command poll failed", "error", err)
@@ -364,6 +543,10 @@ func (a *Agent) executeCommand(ctx context.Context, cmd types.Command) {
 	switch cmd.Type {
 	case "mtr":
 		result = a.executeMTR(ctx, cmd)
+	case types.CommandSubnetSweep:
+		result = a.executeSubnetSweep(ctx, cmd)
+	case types.CommandPMTUD:
+		result = a.executePMTUD(ctx, cmd)
 	default:
 		result.Success = false
 		result.Error = fmt.Sprintf("unknown command type: %s", cmd.Type)
@@ -469,18 +652,20 @@ func defaultTiers() map[string]types.Tier {
 			ProbeRetries:  1,
 		},
 		"vip": {
-			Name:          "vip",
-			DisplayName:   "VIP",
-			ProbeInterval: 15 * time.Second,
-			ProbeTimeout:  3 * time.Second,
-			ProbeRetries:  2,
+			Name:                "vip",
+			DisplayName:         "VIP",
+			ProbeInterval:       15 * time.Second,
+			ProbeTimeout:        3 * time.Second,
+			ProbeRetries:        2,
+			AdaptiveMaxInterval: 1 * time.Minute,
 		},
 		"standard": {
-			Name:          "standard",
-			DisplayName:   "Standard",
-			ProbeInterval: 30 * time.Second,
-			ProbeTimeout:  5 * time.Second,
-			ProbeRetries:  0,
+			Name:                "standard",
+			DisplayName:         "Standard",
+			ProbeInterval:       30 * time.Second,
+			ProbeTimeout:        5 * time.Second,
+			ProbeRetries:        0,
+			AdaptiveMaxInterval: 2 * time.Minute,
 		},
 		// VLAN Gateway tier - monitors gateway addresses for subnets
 		"vlan_gateway": {
@@ -512,6 +697,15 @@ func defaultTiers() map[string]types.Tier {
 			ProbeTimeout:  5 * time.Second,
 			ProbeRetries:  0,
 		},
+		// Pipeline canary - the agent's own loopback, probed so the control
+		// plane can tell a stalled results pipeline from a quiet network
+		types.CanaryTierName: {
+			Name:          types.CanaryTierName,
+			DisplayName:   "Pipeline Canary",
+			ProbeInterval: 30 * time.Second,
+			ProbeTimeout:  2 * time.Second,
+			ProbeRetries:  0,
+		},
 	}
 }
 
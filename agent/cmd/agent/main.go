@@ -66,10 +66,10 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Set up logging
-	logLevel := slog.LevelInfo
+	// Set up logging. The level is a LevelVar so remote config can change it.
+	logLevel := new(slog.LevelVar)
 	if *debug {
-		logLevel = slog.LevelDebug
+		logLevel.Set(slog.LevelDebug)
 	}
 	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
 		Level: logLevel,
@@ -123,6 +123,7 @@ func main() {
 		logger.Error("failed to create agent", "error", err)
 		os.Exit(1)
 	}
+	a.SetLogLevel(logLevel)
 
 	// Set up signal handling
 	ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,107 @@
+// Command simagent load-tests the control plane with a fleet of simulated
+// agents. Each registers like a real agent, probes its assignments by
+// generating synthetic icmp_ping results and ships them in real result
+// batches. No packets are sent.
+//
+// # Usage
+//
+//	simagent --control-plane http://localhost:8081 --agents 50 --targets 20000
+//
+// --targets creates that many synthetic targets in 198.18.0.0/15 (tagged
+// simulated=true) before the fleet starts; without it the fleet probes
+// whatever targets already exist. The control plane assigns targets to the
+// virtual agents as it would to real ones.
+//
+// # Examples
+//
+// A fixed rate of 50 agents × their targets every 10s:
+//
+//	simagent --control-plane http://localhost:8081 --agents 50 --interval 10s
+//
+// Exercise anomaly detection with 10% of paths degraded to 5× latency:
+//
+//	simagent --control-plane http://localhost:8081 --agents 20 \
+//	         --degraded 10 --degraded-factor 5 --degraded-loss 20
+//
+// ICMPMON_CONTROL_PLANE_URL and ICMPMON_CONTROL_PLANE_TOKEN are read as
+// for the agent.
+package main
+
+import (
+	"context"
+	"flag"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/pilot-net/icmp-mon/agent"
+	"github.com/pilot-net/icmp-mon/agent/internal/simulate"
+)
+
+func main() {
+	cfg := simulate.DefaultConfig()
+	cfg.ControlPlaneURL = os.Getenv("ICMPMON_CONTROL_PLANE_URL")
+	cfg.Token = os.Getenv("ICMPMON_CONTROL_PLANE_TOKEN")
+	cfg.Version = agent.Version + "-sim"
+
+	var (
+		regions = flag.String("regions", strings.Join(cfg.Regions, ","), "Comma-separated regions, assigned round-robin")
+		targets = flag.Int("targets", 0, "Create this many synthetic targets before starting")
+		tier    = flag.String("tier", "standard", "Tier for created targets")
+		seed    = flag.Uint64("seed", cfg.Seed, "Seed for per-path latency and health")
+		debug   = flag.Bool("debug", false, "Enable debug logging")
+	)
+	flag.StringVar(&cfg.ControlPlaneURL, "control-plane", cfg.ControlPlaneURL, "Control plane URL")
+	flag.StringVar(&cfg.Token, "token", cfg.Token, "Authentication token")
+	flag.BoolVar(&cfg.InsecureSkipVerify, "insecure", false, "Skip TLS verification")
+	flag.IntVar(&cfg.Agents, "agents", cfg.Agents, "Number of virtual agents")
+	flag.StringVar(&cfg.NamePrefix, "prefix", cfg.NamePrefix, "Agent name prefix")
+	flag.DurationVar(&cfg.Interval, "interval", 0, "Probe interval for every target (default: each assignment's)")
+	flag.IntVar(&cfg.BatchSize, "batch-size", cfg.BatchSize, "Max results per shipped batch")
+	flag.DurationVar(&cfg.BatchTimeout, "batch-timeout", cfg.BatchTimeout, "Max time before shipping a batch")
+	flag.BoolVar(&cfg.Protobuf, "protobuf", false, "Ship batches as protobuf instead of JSON")
+	flag.DurationVar(&cfg.HeartbeatInterval, "heartbeat", cfg.HeartbeatInterval, "Heartbeat interval")
+	flag.Float64Var(&cfg.Profile.LatencyMs, "latency", cfg.Profile.LatencyMs, "Typical latency in ms")
+	flag.Float64Var(&cfg.Profile.LatencySpreadMs, "latency-spread", cfg.Profile.LatencySpreadMs, "Spread of per-path latency in ms")
+	flag.Float64Var(&cfg.Profile.JitterMs, "jitter", cfg.Profile.JitterMs, "Per-packet latency stddev in ms")
+	flag.Float64Var(&cfg.Profile.LossPct, "loss", cfg.Profile.LossPct, "Per-packet loss percent on healthy paths")
+	flag.Float64Var(&cfg.Profile.DegradedPct, "degraded", cfg.Profile.DegradedPct, "Percent of paths degraded")
+	flag.Float64Var(&cfg.Profile.DegradedLatencyFactor, "degraded-factor", cfg.Profile.DegradedLatencyFactor, "Latency multiplier on degraded paths")
+	flag.Float64Var(&cfg.Profile.DegradedLossPct, "degraded-loss", cfg.Profile.DegradedLossPct, "Per-packet loss percent on degraded paths")
+	flag.IntVar(&cfg.Profile.Packets, "packets", cfg.Profile.Packets, "Packets per probe")
+	flag.Parse()
+
+	cfg.Regions = strings.Split(*regions, ",")
+	cfg.Seed = *seed
+
+	level := slog.LevelInfo
+	if *debug {
+		level = slog.LevelDebug
+	}
+	logger := slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level}))
+	cfg.Logger = logger
+
+	if err := cfg.Validate(); err != nil {
+		logger.Error("invalid configuration", "error", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *targets > 0 {
+		logger.Info("creating synthetic targets", "count", *targets, "tier", *tier)
+		if err := simulate.CreateTargets(ctx, cfg, *targets, *tier); err != nil {
+			logger.Error("failed to create targets", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	if err := simulate.Run(ctx, cfg); err != nil {
+		logger.Error("simulation failed", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("simulation stopped")
+}
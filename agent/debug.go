@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/scheduler"
+	"github.com/pilot-net/icmp-mon/agent/internal/shipper"
+)
+
+// debugShutdownTimeout bounds how long the debug server waits for open
+// requests when the agent stops.
+const debugShutdownTimeout = 5 * time.Second
+
+// debugStatus is the agent's local view of itself, for debugging on the host
+// without access to the control plane.
+type debugStatus struct {
+	AgentID           string  `json:"agent_id"`
+	Version           string  `json:"version"`
+	UptimeSeconds     float64 `json:"uptime_seconds"`
+	AssignmentVersion int64   `json:"assignment_version"`
+	ConfigVersion     int64   `json:"config_version"`
+
+	Scheduler scheduler.Stats `json:"scheduler"`
+
+	// Shipper includes the results queued for shipping and their age;
+	// LastShip is the most recent send and the control plane's reply.
+	Shipper  shipper.Stats       `json:"shipper"`
+	LastShip *shipper.ShipResult `json:"last_ship"`
+}
+
+// serveDebug serves GET /debug/status on the configured loopback address
+// until ctx is done. The endpoint is a convenience, so failing to listen is
+// logged rather than stopping the agent.
+func (a *Agent) serveDebug(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /debug/status", a.handleDebugStatus)
+
+	srv := &http.Server{
+		Addr:              a.cfg.Health.DebugListen,
+		Handler:           mux,
+		ReadHeaderTimeout: debugShutdownTimeout,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), debugShutdownTimeout)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+	}()
+
+	a.logger.Info("debug status endpoint listening", "addr", srv.Addr)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		a.logger.Warn("debug status endpoint stopped", "addr", srv.Addr, "error", err)
+	}
+}
+
+func (a *Agent) handleDebugStatus(w http.ResponseWriter, r *http.Request) {
+	a.mu.Lock()
+	assignmentVersion := a.assignmentVersion
+	a.mu.Unlock()
+
+	status := debugStatus{
+		AgentID:           a.agentID,
+		Version:           Version,
+		UptimeSeconds:     time.Since(a.startTime).Seconds(),
+		AssignmentVersion: assignmentVersion,
+		ConfigVersion:     a.appliedConfigVersion(),
+		Scheduler:         a.scheduler.Stats(),
+		Shipper:           a.shipper.Stats(),
+		LastShip:          a.shipper.LastShip(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(status)
+}
@@ -6,6 +6,7 @@
 // - Heartbeat: Periodic health reporting
 // - GetAssignments: Fetch target assignments
 // - GetCommands: Poll for on-demand commands
+// - GetConfig: Fetch the remote config to merge over the local one
 // - ReportCommandResult: Return command execution results
 package client
 
@@ -78,6 +79,8 @@ type RegisterRequest struct {
 	Version     string            `json:"version"`
 	Executors   []string          `json:"executors"`
 	MaxTargets  int               `json:"max_targets"`
+
+	SystemInfo *types.AgentSystemInfo `json:"system_info,omitempty"`
 }
 
 // RegisterResponse is returned from agent registration.
@@ -177,6 +180,27 @@ func (c *Client) GetCommands(ctx context.Context) ([]types.Command, error) {
 	return result.Commands, nil
 }
 
+// GetConfig fetches this agent's effective remote config.
+func (c *Client) GetConfig(ctx context.Context) (*types.AgentConfigSet, error) {
+	path := fmt.Sprintf("/api/v1/agents/%s/config", c.agentID)
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, c.readError(resp)
+	}
+
+	var result types.AgentConfigSet
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decoding response: %w", err)
+	}
+
+	return &result, nil
+}
+
 // ReportCommandResult sends the result of an executed command.
 func (c *Client) ReportCommandResult(ctx context.Context, result types.CommandResult) error {
 	path := fmt.Sprintf("/api/v1/agents/%s/commands/%s/result", c.agentID, result.CommandID)
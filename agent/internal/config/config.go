@@ -8,11 +8,18 @@
 // 3. Config file (YAML)
 // 4. Defaults
 //
+// The control plane can override a few settings at runtime (log level,
+// schedule alignment, heartbeat and poll intervals, feature flags); see
+// types.AgentRemoteConfig. Those overrides sit above every local source.
+//
 // # Example Config File
 //
 //	control_plane:
 //	  url: https://monitor.pilot.net
 //	  token: pmon_xxx
+//	  failover_urls:                 # optional, tried in order for results
+//	    - https://monitor-dr.pilot.net
+//	  primary_retry_interval: 5m
 //
 //	agent:
 //	  name: aws-us-east-01
@@ -26,15 +33,35 @@
 //	probing:
 //	  result_batch_size: 1000
 //	  result_batch_timeout: 5s
+//	  result_encoding: json          # or protobuf
+//	  source_address: 203.0.113.10   # optional, all executors
+//	  max_concurrent_probes: 10      # in-flight batches per executor
+//	  probe_queue_size: 1000         # batches waiting per executor
+//	  icmp_modes: [raw, dgram, tcp]  # preference order
+//	  tcp_fallback_port: 443
+//	  failure_loss_pct: 50           # probe fails at this loss or above
+//	  schedule_alignment: drifting   # or aligned (wall-clock boundaries)
+//	  executors:
+//	    mtr:
+//	      interface: eth1             # optional, per-executor override
+//	      max_concurrent: 4
 //
 //	health:
 //	  heartbeat_interval: 30s
+//	  debug_listen: 127.0.0.1:9110   # local GET /debug/status, "off" to disable
+//
+//	tiers:
+//	  infrastructure:
+//	    schedule_alignment: aligned  # per-tier override
 package config
 
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"strconv"
+	"strings"
 	"time"
 
 	"gopkg.in/yaml.v3"
@@ -61,6 +88,25 @@ type ControlPlaneConfig struct {
 	// Timeouts
 	ConnectTimeout time.Duration `yaml:"connect_timeout,omitempty"`
 	RequestTimeout time.Duration `yaml:"request_timeout,omitempty"`
+
+	// FailoverURLs are further control plane URLs results are shipped to,
+	// in order, when URL is unreachable. Shipping returns to URL once it
+	// accepts results again; it is retried every PrimaryRetryInterval
+	// (default 5m) while failed over.
+	FailoverURLs         []string      `yaml:"failover_urls,omitempty"`
+	PrimaryRetryInterval time.Duration `yaml:"primary_retry_interval,omitempty"`
+}
+
+// ResultEndpoints returns the results ingest URL on the primary control
+// plane and on each failover, in order.
+func (c ControlPlaneConfig) ResultEndpoints() (primary string, failovers []string) {
+	endpoint := func(base string) string {
+		return strings.TrimSuffix(base, "/") + "/api/v1/results"
+	}
+	for _, u := range c.FailoverURLs {
+		failovers = append(failovers, endpoint(u))
+	}
+	return endpoint(c.URL), failovers
 }
 
 // AgentConfig defines agent identity and metadata.
@@ -78,6 +124,11 @@ type ProbingConfig struct {
 	ResultBatchSize    int           `yaml:"result_batch_size"`
 	ResultBatchTimeout time.Duration `yaml:"result_batch_timeout"`
 
+	// ResultEncoding is how batches are shipped: ResultEncodingJSON
+	// (default) or ResultEncodingProtobuf, which the control plane parses
+	// several times faster. Both are gzipped.
+	ResultEncoding string `yaml:"result_encoding,omitempty"`
+
 	// Assignment sync
 	AssignmentPollInterval time.Duration `yaml:"assignment_poll_interval"`
 
@@ -87,11 +138,122 @@ type ProbingConfig struct {
 	// Executor settings
 	FpingPath string `yaml:"fping_path,omitempty"`
 	MTRPath   string `yaml:"mtr_path,omitempty"`
+
+	// DisableReplyTTL stops the ICMP executor asking fping for reply TTLs.
+	// Needed only for fping builds older than 4.0 (no --print-ttl).
+	DisableReplyTTL bool `yaml:"disable_reply_ttl,omitempty"`
+
+	// ICMPModes is the preference order for how icmp_ping probes are sent:
+	// "raw", "dgram" (unprivileged ICMP socket) and/or "tcp" (connect to
+	// TCPFallbackPort). The first mode the host supports is used. Default:
+	// raw, dgram, tcp.
+	ICMPModes       []string `yaml:"icmp_modes,omitempty"`
+	TCPFallbackPort int      `yaml:"tcp_fallback_port,omitempty"` // Default: 443
+
+	// Source binding for multi-homed hosts. Applies to every executor
+	// unless overridden in Executors.
+	SourceAddress string `yaml:"source_address,omitempty"`
+	Interface     string `yaml:"interface,omitempty"`
+
+	// Probe concurrency. MaxConcurrentProbes caps in-flight batches per
+	// executor (each fping batch is one process); ProbeQueueSize bounds the
+	// batches waiting for a slot before the oldest are shed. Zero uses the
+	// scheduler defaults (10 and 1000).
+	MaxConcurrentProbes int `yaml:"max_concurrent_probes,omitempty"`
+	ProbeQueueSize      int `yaml:"probe_queue_size,omitempty"`
+
+	// FailureLossPct is the per-probe packet loss at or above which an ICMP
+	// probe is recorded as failed (success=false). Zero uses the executor
+	// default of 50%.
+	FailureLossPct float64 `yaml:"failure_loss_pct,omitempty"`
+
+	// AdaptiveInterval probes stable targets less often, within each tier's
+	// adaptive bounds, and returns them to the tier interval when their
+	// latency variance rises or they lose packets.
+	AdaptiveInterval bool `yaml:"adaptive_interval,omitempty"`
+
+	// ScheduleAlignment times every tier's probe loop unless the tier
+	// overrides it in Tiers: "drifting" (default) runs every interval from
+	// agent start, spreading load across agents; "aligned" runs on
+	// wall-clock multiples of the interval so agents probe in step.
+	ScheduleAlignment string `yaml:"schedule_alignment,omitempty"`
+
+	// Per-executor overrides keyed by executor type (e.g. "icmp_ping", "mtr")
+	Executors map[string]ExecutorConfig `yaml:"executors,omitempty"`
+}
+
+// ExecutorConfig holds per-executor settings.
+type ExecutorConfig struct {
+	SourceAddress string `yaml:"source_address,omitempty"`
+	Interface     string `yaml:"interface,omitempty"`
+	MaxConcurrent int    `yaml:"max_concurrent,omitempty"`
+	QueueSize     int    `yaml:"queue_size,omitempty"`
+}
+
+// SourceFor returns the source address and interface for an executor type,
+// falling back to the probing-wide defaults for any field not overridden.
+func (p ProbingConfig) SourceFor(executorType string) (sourceAddress, iface string) {
+	sourceAddress, iface = p.SourceAddress, p.Interface
+	if ec, ok := p.Executors[executorType]; ok {
+		if ec.SourceAddress != "" {
+			sourceAddress = ec.SourceAddress
+		}
+		if ec.Interface != "" {
+			iface = ec.Interface
+		}
+	}
+	return sourceAddress, iface
+}
+
+// ConcurrencyFor returns the max in-flight batches and queue size for an
+// executor type, falling back to the probing-wide values.
+func (p ProbingConfig) ConcurrencyFor(executorType string) (maxConcurrent, queueSize int) {
+	maxConcurrent, queueSize = p.MaxConcurrentProbes, p.ProbeQueueSize
+	if ec, ok := p.Executors[executorType]; ok {
+		if ec.MaxConcurrent > 0 {
+			maxConcurrent = ec.MaxConcurrent
+		}
+		if ec.QueueSize > 0 {
+			queueSize = ec.QueueSize
+		}
+	}
+	return maxConcurrent, queueSize
 }
 
 // HealthConfig defines health monitoring behavior.
 type HealthConfig struct {
 	HeartbeatInterval time.Duration `yaml:"heartbeat_interval"`
+
+	// DebugListen is the loopback address serving GET /debug/status, or
+	// DebugListenOff.
+	DebugListen string `yaml:"debug_listen,omitempty"`
+}
+
+// DebugListenOff disables the local debug endpoint.
+const DebugListenOff = "off"
+
+// DebugEnabled reports whether the local debug endpoint should be served.
+func (h HealthConfig) DebugEnabled() bool {
+	return h.DebugListen != "" && h.DebugListen != DebugListenOff
+}
+
+// validateDebugListen checks that the debug endpoint is only reachable from
+// the host itself: it reports on the agent without authentication.
+func (h HealthConfig) validateDebugListen() error {
+	if !h.DebugEnabled() {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(h.DebugListen)
+	if err != nil {
+		return fmt.Errorf("health.debug_listen: %w", err)
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("health.debug_listen must be a loopback address or %q", DebugListenOff)
+	}
+	return nil
 }
 
 // TierConfig allows local tier definition/override.
@@ -99,8 +261,17 @@ type TierConfig struct {
 	ProbeInterval time.Duration `yaml:"probe_interval"`
 	ProbeTimeout  time.Duration `yaml:"probe_timeout"`
 	ProbeRetries  int           `yaml:"probe_retries"`
+
+	// ScheduleAlignment overrides probing.schedule_alignment for the tier.
+	ScheduleAlignment string `yaml:"schedule_alignment,omitempty"`
 }
 
+// Result encodings for ProbingConfig.ResultEncoding.
+const (
+	ResultEncodingJSON     = "json"
+	ResultEncodingProtobuf = "protobuf"
+)
+
 // DefaultConfig returns a config with sensible defaults.
 func DefaultConfig() *Config {
 	return &Config{
@@ -119,6 +290,7 @@ func DefaultConfig() *Config {
 		},
 		Health: HealthConfig{
 			HeartbeatInterval: 30 * time.Second,
+			DebugListen:       "127.0.0.1:9110",
 		},
 	}
 }
@@ -143,9 +315,31 @@ func (c *Config) Validate() error {
 	if c.ControlPlane.URL == "" {
 		return fmt.Errorf("control_plane.url is required")
 	}
+	for _, u := range c.ControlPlane.FailoverURLs {
+		if u == "" {
+			return fmt.Errorf("control_plane.failover_urls must not contain empty URLs")
+		}
+	}
 	if c.Agent.Name == "" {
 		return fmt.Errorf("agent.name is required")
 	}
+	if c.Probing.MaxConcurrentProbes < 0 || c.Probing.ProbeQueueSize < 0 {
+		return fmt.Errorf("probing.max_concurrent_probes and probing.probe_queue_size must not be negative")
+	}
+	if c.Probing.TCPFallbackPort < 0 || c.Probing.TCPFallbackPort > 65535 {
+		return fmt.Errorf("probing.tcp_fallback_port must be a valid port")
+	}
+	if c.Probing.FailureLossPct < 0 || c.Probing.FailureLossPct > 100 {
+		return fmt.Errorf("probing.failure_loss_pct must be between 0 and 100")
+	}
+	switch c.Probing.ResultEncoding {
+	case "", ResultEncodingJSON, ResultEncodingProtobuf:
+	default:
+		return fmt.Errorf("probing.result_encoding must be %s or %s", ResultEncodingJSON, ResultEncodingProtobuf)
+	}
+	if err := c.Health.validateDebugListen(); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -153,11 +347,23 @@ func (c *Config) Validate() error {
 // Environment variables use ICMPMON_ prefix:
 // - ICMPMON_CONTROL_PLANE_URL
 // - ICMPMON_CONTROL_PLANE_TOKEN
+// - ICMPMON_CONTROL_PLANE_FAILOVER_URLS (comma-separated)
 // - ICMPMON_AGENT_NAME
 // - ICMPMON_AGENT_REGION
 // - ICMPMON_AGENT_LOCATION
 // - ICMPMON_AGENT_PROVIDER
 // - ICMPMON_AGENT_TAGS (JSON object, e.g., '{"pilot_pop":"NYC1"}')
+// - ICMPMON_PROBE_SOURCE_ADDRESS
+// - ICMPMON_PROBE_INTERFACE
+// - ICMPMON_PROBE_MAX_CONCURRENT
+// - ICMPMON_PROBE_QUEUE_SIZE
+// - ICMPMON_PROBE_DISABLE_TTL
+// - ICMPMON_PROBE_ICMP_MODES (comma-separated, e.g. "raw,tcp")
+// - ICMPMON_PROBE_TCP_PORT
+// - ICMPMON_PROBE_FAILURE_LOSS_PCT
+// - ICMPMON_PROBE_SCHEDULE_ALIGNMENT
+// - ICMPMON_RESULT_ENCODING (json or protobuf)
+// - ICMPMON_DEBUG_LISTEN ("off" to disable)
 func (c *Config) ApplyEnvOverrides() {
 	if v := os.Getenv("ICMPMON_CONTROL_PLANE_URL"); v != "" {
 		c.ControlPlane.URL = v
@@ -165,6 +371,9 @@ func (c *Config) ApplyEnvOverrides() {
 	if v := os.Getenv("ICMPMON_CONTROL_PLANE_TOKEN"); v != "" {
 		c.ControlPlane.Token = v
 	}
+	if v := os.Getenv("ICMPMON_CONTROL_PLANE_FAILOVER_URLS"); v != "" {
+		c.ControlPlane.FailoverURLs = strings.Split(v, ",")
+	}
 	if v := os.Getenv("ICMPMON_CONTROL_PLANE_INSECURE"); v == "true" || v == "1" {
 		c.ControlPlane.InsecureSkipVerify = true
 	}
@@ -180,6 +389,39 @@ func (c *Config) ApplyEnvOverrides() {
 	if v := os.Getenv("ICMPMON_AGENT_PROVIDER"); v != "" {
 		c.Agent.Provider = v
 	}
+	if v := os.Getenv("ICMPMON_PROBE_SOURCE_ADDRESS"); v != "" {
+		c.Probing.SourceAddress = v
+	}
+	if v := os.Getenv("ICMPMON_PROBE_INTERFACE"); v != "" {
+		c.Probing.Interface = v
+	}
+	if n, err := strconv.Atoi(os.Getenv("ICMPMON_PROBE_MAX_CONCURRENT")); err == nil && n > 0 {
+		c.Probing.MaxConcurrentProbes = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("ICMPMON_PROBE_QUEUE_SIZE")); err == nil && n > 0 {
+		c.Probing.ProbeQueueSize = n
+	}
+	if v := os.Getenv("ICMPMON_PROBE_DISABLE_TTL"); v == "true" || v == "1" {
+		c.Probing.DisableReplyTTL = true
+	}
+	if v := os.Getenv("ICMPMON_PROBE_ICMP_MODES"); v != "" {
+		c.Probing.ICMPModes = strings.Split(v, ",")
+	}
+	if n, err := strconv.Atoi(os.Getenv("ICMPMON_PROBE_TCP_PORT")); err == nil && n > 0 {
+		c.Probing.TCPFallbackPort = n
+	}
+	if f, err := strconv.ParseFloat(os.Getenv("ICMPMON_PROBE_FAILURE_LOSS_PCT"), 64); err == nil && f > 0 {
+		c.Probing.FailureLossPct = f
+	}
+	if v := os.Getenv("ICMPMON_PROBE_SCHEDULE_ALIGNMENT"); v != "" {
+		c.Probing.ScheduleAlignment = v
+	}
+	if v := os.Getenv("ICMPMON_RESULT_ENCODING"); v != "" {
+		c.Probing.ResultEncoding = v
+	}
+	if v := os.Getenv("ICMPMON_DEBUG_LISTEN"); v != "" {
+		c.Health.DebugListen = v
+	}
 	if v := os.Getenv("ICMPMON_AGENT_TAGS"); v != "" {
 		var tags map[string]string
 		if err := json.Unmarshal([]byte(v), &tags); err == nil {
@@ -0,0 +1,108 @@
+// Package executor - source address/interface binding for multi-homed agents.
+//
+// An agent with more than one uplink (e.g. transit and peering) can pin each
+// executor to a specific egress path. The binding is passed to the underlying
+// tool (fping -S/-I, mtr --address/--interface) or used as the local address
+// for sockets opened directly by Go executors.
+package executor
+
+import (
+	"fmt"
+	"net"
+	"time"
+)
+
+// SourceBinding selects the source address and/or interface probes egress from.
+// Both fields are optional; an empty binding uses the host's routing table.
+type SourceBinding struct {
+	// SourceAddress is the local IP probes are sent from.
+	SourceAddress string `json:"source_address,omitempty" yaml:"source_address,omitempty"`
+
+	// Interface is the network interface probes are sent on (e.g. "eth1").
+	Interface string `json:"interface,omitempty" yaml:"interface,omitempty"`
+}
+
+// IsZero returns true if no binding is configured.
+func (b SourceBinding) IsZero() bool {
+	return b.SourceAddress == "" && b.Interface == ""
+}
+
+// Validate checks that the source address is a valid IP and is present on
+// this host, and that the interface exists.
+func (b SourceBinding) Validate() error {
+	if b.SourceAddress != "" {
+		ip := net.ParseIP(b.SourceAddress)
+		if ip == nil {
+			return fmt.Errorf("invalid source_address: %s", b.SourceAddress)
+		}
+		if !hostHasIP(ip) {
+			return fmt.Errorf("source_address %s is not assigned to this host", b.SourceAddress)
+		}
+	}
+	if b.Interface != "" {
+		if _, err := net.InterfaceByName(b.Interface); err != nil {
+			return fmt.Errorf("interface %s: %w", b.Interface, err)
+		}
+	}
+	return nil
+}
+
+// LocalIP returns the IP to bind sockets to for the given target family.
+// When only an interface is configured, the first address of the matching
+// family on that interface is used. Returns nil if no binding applies.
+func (b SourceBinding) LocalIP(targetIP net.IP) (net.IP, error) {
+	if b.SourceAddress != "" {
+		return net.ParseIP(b.SourceAddress), nil
+	}
+	if b.Interface == "" {
+		return nil, nil
+	}
+
+	iface, err := net.InterfaceByName(b.Interface)
+	if err != nil {
+		return nil, fmt.Errorf("interface %s: %w", b.Interface, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("listing addresses on %s: %w", b.Interface, err)
+	}
+
+	wantV4 := targetIP == nil || targetIP.To4() != nil
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLinkLocalUnicast() {
+			continue
+		}
+		if (ipNet.IP.To4() != nil) == wantV4 {
+			return ipNet.IP, nil
+		}
+	}
+	return nil, fmt.Errorf("no usable address on interface %s", b.Interface)
+}
+
+// Dialer returns a net.Dialer bound to the source for TCP-based executors.
+func (b SourceBinding) Dialer(targetIP net.IP, timeout time.Duration) (*net.Dialer, error) {
+	d := &net.Dialer{Timeout: timeout}
+	localIP, err := b.LocalIP(targetIP)
+	if err != nil {
+		return nil, err
+	}
+	if localIP != nil {
+		d.LocalAddr = &net.TCPAddr{IP: localIP}
+	}
+	return d, nil
+}
+
+// hostHasIP reports whether ip is assigned to any local interface.
+func hostHasIP(ip net.IP) bool {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return false
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && ipNet.IP.Equal(ip) {
+			return true
+		}
+	}
+	return false
+}
@@ -13,7 +13,8 @@
 //
 //  1. Create a new file (e.g., http.go) implementing the Executor interface
 //  2. Define parameter and result structs for your probe type
-//  3. Register the executor in the registry
+//  3. Register the executor in the registry, or for a custom check register
+//     a factory with RegisterPlugin from init (see plugin.go)
 //
 // Example:
 //
@@ -77,16 +78,19 @@ type ProbeTarget struct {
 	Timeout  time.Duration   `json:"timeout"`
 	Retries  int             `json:"retries"`
 	Params   json.RawMessage `json:"params,omitempty"` // Executor-specific params
+	DSCP     int             `json:"dscp,omitempty"`   // DSCP codepoint to mark packets with (0 = best effort)
 }
 
 // Result is the outcome of a probe execution.
 type Result struct {
-	TargetID  string          `json:"target_id"`
-	Timestamp time.Time       `json:"timestamp"`
-	Duration  time.Duration   `json:"duration"`
-	Success   bool            `json:"success"`
-	Error     string          `json:"error,omitempty"`
-	Payload   json.RawMessage `json:"payload"` // Executor-specific result data
+	TargetID   string          `json:"target_id"`
+	EndpointID string          `json:"endpoint_id,omitempty"` // Set by the scheduler for endpoint assignments
+	ProbeType  string          `json:"probe_type,omitempty"`  // Executor that ran the probe, set by the scheduler
+	Timestamp  time.Time       `json:"timestamp"`
+	Duration   time.Duration   `json:"duration"`
+	Success    bool            `json:"success"`
+	Error      string          `json:"error,omitempty"`
+	Payload    json.RawMessage `json:"payload"` // Executor-specific result data
 }
 
 // =============================================================================
@@ -174,6 +178,12 @@ func MarshalPayload(v any) json.RawMessage {
 	return data
 }
 
+// DSCPToTOS converts a 6-bit DSCP codepoint to the IP TOS byte value
+// (DSCP occupies the upper six bits; the lower two are ECN).
+func DSCPToTOS(dscp int) int {
+	return dscp << 2
+}
+
 // UnmarshalPayload extracts a typed payload from json.RawMessage.
 func UnmarshalPayload[T any](data json.RawMessage) (T, error) {
 	var v T
@@ -290,6 +290,27 @@ func TestUnmarshalPayload(t *testing.T) {
 	}
 }
 
+func TestDSCPToTOS(t *testing.T) {
+	tests := []struct {
+		name string
+		dscp int
+		want int
+	}{
+		{"best effort", 0, 0},
+		{"AF41", 34, 136},
+		{"EF", 46, 184},
+		{"CS7", 56, 224},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DSCPToTOS(tt.dscp); got != tt.want {
+				t.Errorf("DSCPToTOS(%d): got %d, want %d", tt.dscp, got, tt.want)
+			}
+		})
+	}
+}
+
 func TestProbeTarget_Fields(t *testing.T) {
 	target := ProbeTarget{
 		ID:      "target-123",
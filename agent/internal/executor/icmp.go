@@ -21,6 +21,21 @@
 // - Each number is a round-trip time in milliseconds
 // - "-" indicates a timeout/failure for that probe
 //
+// With --print-ttl, fping also writes one line per reply to stdout carrying
+// the TTL of the echo reply, which reveals how many hops the reply took:
+//
+//	192.168.1.1 : [0], 64 bytes, 12.45 ms (12.45 avg, 0% loss) (TTL 58)
+//
+// # Success and Packet Loss
+//
+// A multi-packet probe is one measurement. Its packet_loss_pct forgives the
+// first lost packet (see parseRTTValues), and the result counts as
+// successful only when that loss is below the failure threshold
+// (FailureLossPct, default 50%). With the default 3 packets, one dropped
+// packet is still a success and two are a failure. Reachable in the payload
+// only says whether any reply came back, so a lossy probe can be reachable
+// but unsuccessful; its latency is still reported.
+//
 // # Installation
 //
 //	Ubuntu/Debian: apt-get install fping
@@ -51,21 +66,49 @@ type ICMPExecutor struct {
 
 	// DefaultInterval is the interval between pings in milliseconds. Default: 100
 	DefaultIntervalMs int
+
+	// Source pins probes to a source address and/or interface (optional)
+	Source SourceBinding
+
+	// RecordTTL asks fping for per-reply TTLs (--print-ttl, fping >= 4.0)
+	// and reports the most recent one in the payload. Default: true
+	RecordTTL bool
+
+	// Mode is the detected ICMP mode, recorded in every payload. In
+	// ICMPModeTCP targets are probed with TCP connects to TCPPort instead
+	// of fping. Empty is treated as raw.
+	Mode ICMPMode
+
+	// TCPPort is the port connected to in TCP mode. Default: 443
+	TCPPort int
+
+	// FailureLossPct is the packet loss at or above which a probe counts as
+	// failed. Targets can override it with the failure_loss_pct param.
+	// Default: DefaultFailureLossPct
+	FailureLossPct float64
 }
 
+// DefaultFailureLossPct is the default per-probe loss threshold: a probe
+// losing half or more of its packets (after the first-packet grace) fails.
+const DefaultFailureLossPct = 50.0
+
 // NewICMPExecutor creates a new ICMP executor with sensible defaults.
 func NewICMPExecutor() *ICMPExecutor {
 	return &ICMPExecutor{
 		FpingPath:         "fping",
 		DefaultCount:      3,
 		DefaultIntervalMs: 100,
+		RecordTTL:         true,
+		TCPPort:           443,
+		FailureLossPct:    DefaultFailureLossPct,
 	}
 }
 
 // ICMPParams are executor-specific parameters for ICMP probes.
 type ICMPParams struct {
-	Count      int `json:"count,omitempty"`       // Pings per target (default: 3)
-	IntervalMs int `json:"interval_ms,omitempty"` // Interval between pings (default: 100)
+	Count          int     `json:"count,omitempty"`            // Pings per target (default: 3)
+	IntervalMs     int     `json:"interval_ms,omitempty"`      // Interval between pings (default: 100)
+	FailureLossPct float64 `json:"failure_loss_pct,omitempty"` // Loss at which the probe fails (default: executor's)
 }
 
 // ICMPPayload contains the results of an ICMP probe.
@@ -79,6 +122,32 @@ type ICMPPayload struct {
 	PacketLoss   float64 `json:"packet_loss_pct"`
 	PacketsSent  int     `json:"packets_sent"`
 	PacketsRecvd int     `json:"packets_recvd"`
+
+	// Source binding the probe egressed from (empty if unbound)
+	SourceAddress   string `json:"source_address,omitempty"`
+	SourceInterface string `json:"source_interface,omitempty"`
+
+	// DSCP codepoint the probe was marked with (0 = best effort)
+	DSCP int `json:"dscp,omitempty"`
+
+	// TTL of the most recent echo reply (0 = unknown or no reply)
+	ReplyTTL int `json:"reply_ttl,omitempty"`
+
+	// ICMP mode the probe ran in (see ICMPMode)
+	Mode ICMPMode `json:"mode,omitempty"`
+
+	// Loss threshold the probe was judged against (see Successful)
+	FailureLossPct float64 `json:"failure_loss_pct,omitempty"`
+}
+
+// Successful reports whether the probe counts as a success: some reply came
+// back and packet loss stayed below FailureLossPct. Without a threshold
+// (payloads from older agents) any reply is a success.
+func (p ICMPPayload) Successful() bool {
+	if !p.Reachable {
+		return false
+	}
+	return p.FailureLossPct <= 0 || p.PacketLoss < p.FailureLossPct
 }
 
 // Type returns the executor type identifier.
@@ -88,6 +157,13 @@ func (e *ICMPExecutor) Type() string {
 
 // Capabilities returns what this executor can do.
 func (e *ICMPExecutor) Capabilities() Capabilities {
+	if e.Mode == ICMPModeTCP {
+		return Capabilities{
+			SupportsBatching: true,
+			MaxBatchSize:     500,
+			RequiresRoot:     false,
+		}
+	}
 	return Capabilities{
 		SupportsBatching: true,
 		MaxBatchSize:     500, // fping handles this well
@@ -109,11 +185,38 @@ func (e *ICMPExecutor) Execute(ctx context.Context, target ProbeTarget) (*Result
 }
 
 // ExecuteBatch probes multiple targets efficiently using fping.
+// fping sets one TOS value per process, so targets with different DSCP
+// markings are split into one fping run per codepoint.
 func (e *ICMPExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget) ([]*Result, error) {
 	if len(targets) == 0 {
 		return []*Result{}, nil
 	}
 
+	groups := make(map[int][]ProbeTarget)
+	var order []int
+	for _, t := range targets {
+		if _, ok := groups[t.DSCP]; !ok {
+			order = append(order, t.DSCP)
+		}
+		groups[t.DSCP] = append(groups[t.DSCP], t)
+	}
+	if len(order) == 1 {
+		return e.executeGroup(ctx, targets, order[0])
+	}
+
+	results := make([]*Result, 0, len(targets))
+	for _, dscp := range order {
+		groupResults, err := e.executeGroup(ctx, groups[dscp], dscp)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, groupResults...)
+	}
+	return results, nil
+}
+
+// executeGroup runs a single fping process for targets sharing a DSCP marking.
+func (e *ICMPExecutor) executeGroup(ctx context.Context, targets []ProbeTarget, dscp int) ([]*Result, error) {
 	// Parse params from first target (assume consistent within batch)
 	params := e.parseParams(targets[0].Params)
 	timeout := targets[0].Timeout
@@ -121,6 +224,10 @@ func (e *ICMPExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget)
 		timeout = 5 * time.Second
 	}
 
+	if e.Mode == ICMPModeTCP {
+		return e.executeTCP(ctx, targets, params, timeout, dscp), nil
+	}
+
 	// Build target IP list
 	ips := make([]string, len(targets))
 	ipToTarget := make(map[string]ProbeTarget, len(targets))
@@ -131,7 +238,7 @@ func (e *ICMPExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget)
 
 	// Run fping
 	start := time.Now()
-	output, err := e.runFping(ctx, ips, params, timeout)
+	output, replies, err := e.runFping(ctx, ips, params, timeout, dscp)
 	if err != nil {
 		// fping returns non-zero if any host is unreachable, which is normal
 		// Only treat as error if we got no output at all
@@ -141,12 +248,13 @@ func (e *ICMPExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget)
 	}
 
 	// Parse results
-	results := e.parseOutput(output, ipToTarget, start)
+	results := e.parseOutput(output, parseReplyTTLs(replies), ipToTarget, start)
 	return results, nil
 }
 
-// runFping executes fping and returns the raw output.
-func (e *ICMPExecutor) runFping(ctx context.Context, ips []string, params ICMPParams, timeout time.Duration) ([]byte, error) {
+// runFping executes fping and returns the raw summary output (stderr) and,
+// when RecordTTL is set, the per-reply lines (stdout).
+func (e *ICMPExecutor) runFping(ctx context.Context, ips []string, params ICMPParams, timeout time.Duration, dscp int) ([]byte, []byte, error) {
 	fpingPath := e.FpingPath
 	if fpingPath == "" {
 		fpingPath = "fping"
@@ -164,30 +272,58 @@ func (e *ICMPExecutor) runFping(ctx context.Context, ips []string, params ICMPPa
 
 	// Build command arguments
 	// -C n  : Send n pings to each target
-	// -q    : Quiet mode (summary output only)
 	// -t ms : Initial timeout in milliseconds
 	// -p ms : Interval between pings to same target
 	// -B 1  : Backoff multiplier (1 = no exponential backoff)
 	args := []string{
 		"-C", strconv.Itoa(count),
-		"-q",
 		"-t", strconv.FormatInt(timeout.Milliseconds(), 10),
 		"-p", strconv.Itoa(intervalMs),
 		"-B", "1",
 	}
+	// --print-ttl : Per-reply lines with the reply TTL (stdout)
+	// -q          : Quiet mode (summary output only)
+	if e.RecordTTL {
+		args = append(args, "--print-ttl")
+	} else {
+		args = append(args, "-q")
+	}
+	args = append(args, e.sourceArgs()...)
+	// -O tos : Type of service byte (DSCP << 2)
+	if dscp > 0 {
+		args = append(args, "-O", strconv.Itoa(DSCPToTOS(dscp)))
+	}
 	args = append(args, ips...)
 
 	cmd := exec.CommandContext(ctx, fpingPath, args...)
 
-	// fping writes results to stderr (historical quirk)
-	var stderr bytes.Buffer
+	// fping writes the -C summary to stderr (historical quirk) and
+	// per-reply lines to stdout
+	var stdout, stderr bytes.Buffer
 	cmd.Stderr = &stderr
+	if e.RecordTTL {
+		cmd.Stdout = &stdout
+	}
 
 	// Run command - ignore error because fping returns non-zero
 	// when any host is unreachable (which is expected)
 	_ = cmd.Run()
 
-	return stderr.Bytes(), nil
+	return stderr.Bytes(), stdout.Bytes(), nil
+}
+
+// sourceArgs returns fping flags for the configured source binding.
+// -S addr : Source address
+// -I if   : Bind to interface (SO_BINDTODEVICE)
+func (e *ICMPExecutor) sourceArgs() []string {
+	var args []string
+	if e.Source.SourceAddress != "" {
+		args = append(args, "-S", e.Source.SourceAddress)
+	}
+	if e.Source.Interface != "" {
+		args = append(args, "-I", e.Source.Interface)
+	}
+	return args
 }
 
 // parseOutput parses fping output and returns results.
@@ -196,8 +332,9 @@ func (e *ICMPExecutor) runFping(ctx context.Context, ips []string, params ICMPPa
 //
 //	192.168.1.1 : 12.45 13.22 - 11.80
 //
-// Where numbers are RTTs in ms and "-" indicates timeout.
-func (e *ICMPExecutor) parseOutput(output []byte, ipToTarget map[string]ProbeTarget, timestamp time.Time) []*Result {
+// Where numbers are RTTs in ms and "-" indicates timeout. ttls holds the
+// latest reply TTL per IP (may be nil).
+func (e *ICMPExecutor) parseOutput(output []byte, ttls map[string]int, ipToTarget map[string]ProbeTarget, timestamp time.Time) []*Result {
 	results := make([]*Result, 0, len(ipToTarget))
 	seen := make(map[string]bool)
 
@@ -222,11 +359,19 @@ func (e *ICMPExecutor) parseOutput(output []byte, ipToTarget map[string]ProbeTar
 
 		// Parse RTT values
 		payload := e.parseRTTValues(valuesStr)
+		payload.SourceAddress = e.Source.SourceAddress
+		payload.SourceInterface = e.Source.Interface
+		payload.DSCP = target.DSCP
+		payload.Mode = e.Mode
+		payload.FailureLossPct = e.failureLossPct(target.Params)
+		if payload.Reachable {
+			payload.ReplyTTL = ttls[ip]
+		}
 
 		results = append(results, &Result{
 			TargetID:  target.ID,
 			Timestamp: timestamp,
-			Success:   payload.Reachable,
+			Success:   payload.Successful(),
 			Error:     e.errorMessage(payload),
 			Payload:   MarshalPayload(payload),
 		})
@@ -236,9 +381,14 @@ func (e *ICMPExecutor) parseOutput(output []byte, ipToTarget map[string]ProbeTar
 	for ip, target := range ipToTarget {
 		if !seen[ip] {
 			payload := ICMPPayload{
-				Reachable:   false,
-				PacketLoss:  100.0,
-				PacketsSent: e.DefaultCount,
+				Reachable:       false,
+				PacketLoss:      100.0,
+				PacketsSent:     e.DefaultCount,
+				SourceAddress:   e.Source.SourceAddress,
+				SourceInterface: e.Source.Interface,
+				DSCP:            target.DSCP,
+				Mode:            e.Mode,
+				FailureLossPct:  e.failureLossPct(target.Params),
 			}
 			results = append(results, &Result{
 				TargetID:  target.ID,
@@ -253,6 +403,41 @@ func (e *ICMPExecutor) parseOutput(output []byte, ipToTarget map[string]ProbeTar
 	return results
 }
 
+// parseReplyTTLs extracts the TTL of the last reply per IP from fping
+// per-reply output:
+//
+//	192.168.1.1 : [2], 64 bytes, 11.80 ms (12.49 avg, 0% loss) (TTL 58)
+//
+// Replies fping could not read a TTL for ("(TTL unknown)") are skipped.
+func parseReplyTTLs(output []byte) map[string]int {
+	ttls := make(map[string]int)
+
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		ip, rest, ok := strings.Cut(line, " : ")
+		if !ok {
+			continue
+		}
+		_, ttlStr, ok := strings.Cut(rest, "(TTL ")
+		if !ok {
+			continue
+		}
+		ttlStr, _, ok = strings.Cut(ttlStr, ")")
+		if !ok {
+			continue
+		}
+		ttl, err := strconv.Atoi(ttlStr)
+		if err != nil || ttl <= 0 {
+			continue
+		}
+		ttls[strings.TrimSpace(ip)] = ttl
+	}
+
+	return ttls
+}
+
 // parseRTTValues parses the RTT values from fping output.
 func (e *ICMPExecutor) parseRTTValues(valuesStr string) ICMPPayload {
 	values := strings.Fields(valuesStr)
@@ -341,9 +526,21 @@ func (e *ICMPExecutor) parseParams(raw json.RawMessage) ICMPParams {
 	return params
 }
 
+// failureLossPct returns the loss threshold for a target: its
+// failure_loss_pct param if set, else the executor's.
+func (e *ICMPExecutor) failureLossPct(raw json.RawMessage) float64 {
+	if pct := e.parseParams(raw).FailureLossPct; pct > 0 {
+		return pct
+	}
+	if e.FailureLossPct > 0 {
+		return e.FailureLossPct
+	}
+	return DefaultFailureLossPct
+}
+
 // errorMessage generates an error message for failed probes.
 func (e *ICMPExecutor) errorMessage(payload ICMPPayload) string {
-	if payload.Reachable {
+	if payload.Successful() {
 		return ""
 	}
 	if payload.PacketsRecvd == 0 {
@@ -0,0 +1,250 @@
+// Package executor - ICMP mode detection and TCP fallback.
+//
+// # Modes
+//
+// The icmp_ping executor can measure reachability and latency three ways:
+//
+//   - raw:   fping over a raw ICMP socket (root, CAP_NET_RAW, or a setuid /
+//     file-capability fping binary)
+//   - dgram: fping over an unprivileged ICMP datagram socket (Linux, when the
+//     agent's group is within net.ipv4.ping_group_range)
+//   - tcp:   TCP connect to TCPPort; a SYN-ACK or RST both count as a reply
+//
+// fping always opens a raw socket when it can and only falls back to a
+// datagram socket when it can't, so "dgram" in the preference order means
+// "accept the unprivileged socket when raw isn't available".
+//
+// The mode is chosen once at startup from the configured preference order
+// (default raw, dgram, tcp) and reported to the control plane, so a
+// locked-down host degrades to TCP instead of silently failing every probe.
+//
+// # Latency Consistency
+//
+// raw and dgram are equivalent: the same echo request/reply is timed by fping
+// in user space, and only the socket type differs. Expect agreement within a
+// few microseconds.
+//
+// tcp is systematically different. The RTT is SYN to SYN-ACK/RST as seen by
+// connect(), which adds kernel TCP handling on both ends (typically +0.05 to
+// 0.3 ms against end hosts). Routers and firewalls often answer ICMP from a
+// slow path but drop or tarpit TCP to closed ports, so TCP RTTs to network
+// devices are not comparable with ICMP at all. Every result records its mode
+// in the payload so the two can be told apart.
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/sysinfo"
+)
+
+// ICMPMode is how the icmp_ping executor reaches targets.
+type ICMPMode string
+
+const (
+	ICMPModeRaw      ICMPMode = "raw"
+	ICMPModeDatagram ICMPMode = "dgram"
+	ICMPModeTCP      ICMPMode = "tcp"
+)
+
+// DefaultICMPModes is the default preference order.
+var DefaultICMPModes = []ICMPMode{ICMPModeRaw, ICMPModeDatagram, ICMPModeTCP}
+
+// ParseICMPModes validates a preference order. An empty list returns the
+// default order.
+func ParseICMPModes(modes []string) ([]ICMPMode, error) {
+	if len(modes) == 0 {
+		return DefaultICMPModes, nil
+	}
+	parsed := make([]ICMPMode, 0, len(modes))
+	for _, m := range modes {
+		switch mode := ICMPMode(strings.ToLower(strings.TrimSpace(m))); mode {
+		case ICMPModeRaw, ICMPModeDatagram, ICMPModeTCP:
+			parsed = append(parsed, mode)
+		default:
+			return nil, fmt.Errorf("unknown ICMP mode %q (want raw, dgram or tcp)", m)
+		}
+	}
+	return parsed, nil
+}
+
+// ICMPSupport is what the host allows fping to do.
+type ICMPSupport struct {
+	// FpingWorks is true if fping could ping the loopback address.
+	FpingWorks bool
+
+	// Raw is true if fping gets a raw socket; Datagram is true if it can
+	// use an unprivileged ICMP socket.
+	Raw      bool
+	Datagram bool
+}
+
+// fpingSelfTestTimeout bounds the startup loopback ping.
+const fpingSelfTestTimeout = 2 * time.Second
+
+// DetectICMPSupport checks whether fping works on this host and which socket
+// type it ends up using.
+//
+// Raw access is inferred rather than observed: the agent itself can open a
+// raw socket, fping is setuid, or fping works while unprivileged ICMP is
+// disallowed. A file-capability fping on a host that also allows
+// unprivileged ICMP is reported as Datagram only, which is harmless given
+// the two measure identically.
+func DetectICMPSupport(ctx context.Context, fpingPath string) ICMPSupport {
+	datagram := sysinfo.UnprivilegedICMPAllowed()
+	support := ICMPSupport{Datagram: datagram}
+
+	if fpingPath == "" {
+		fpingPath = "fping"
+	}
+	path, err := exec.LookPath(fpingPath)
+	if err != nil {
+		return support
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fpingSelfTestTimeout)
+	defer cancel()
+	// -c 1 : one ping; -t : timeout (ms); -q : no per-reply output
+	if err := exec.CommandContext(ctx, path, "-c", "1", "-t", "500", "-q", "127.0.0.1").Run(); err != nil {
+		return support
+	}
+	support.FpingWorks = true
+
+	setuid := false
+	if fi, err := os.Stat(path); err == nil {
+		setuid = fi.Mode()&os.ModeSetuid != 0
+	}
+	support.Raw = sysinfo.RawSocketAvailable() || setuid || !datagram
+	return support
+}
+
+// SelectICMPMode returns the first mode in prefs the host supports. TCP is
+// always supported.
+func SelectICMPMode(prefs []ICMPMode, support ICMPSupport) (ICMPMode, error) {
+	for _, mode := range prefs {
+		switch mode {
+		case ICMPModeRaw:
+			if support.FpingWorks && support.Raw {
+				return ICMPModeRaw, nil
+			}
+		case ICMPModeDatagram:
+			// fping prefers raw whenever it is available
+			if support.FpingWorks && support.Raw {
+				return ICMPModeRaw, nil
+			}
+			if support.FpingWorks && support.Datagram {
+				return ICMPModeDatagram, nil
+			}
+		case ICMPModeTCP:
+			return ICMPModeTCP, nil
+		}
+	}
+	return "", fmt.Errorf("no usable ICMP mode among %v (fping works: %t, raw: %t, dgram: %t)",
+		prefs, support.FpingWorks, support.Raw, support.Datagram)
+}
+
+// tcpConcurrency caps simultaneous connects within one TCP-mode batch.
+const tcpConcurrency = 64
+
+// executeTCP probes targets with TCP connects, Count attempts each.
+func (e *ICMPExecutor) executeTCP(ctx context.Context, targets []ProbeTarget, params ICMPParams, timeout time.Duration, dscp int) []*Result {
+	count := params.Count
+	if count <= 0 {
+		count = e.DefaultCount
+	}
+	intervalMs := params.IntervalMs
+	if intervalMs <= 0 {
+		intervalMs = e.DefaultIntervalMs
+	}
+	interval := time.Duration(intervalMs) * time.Millisecond
+
+	start := time.Now()
+	results := make([]*Result, len(targets))
+	sem := make(chan struct{}, tcpConcurrency)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = e.tcpProbe(ctx, t, count, interval, timeout, dscp, start)
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// tcpProbe runs count connects to one target and summarises them exactly
+// like an fping result line, so loss grace and statistics match ICMP mode.
+func (e *ICMPExecutor) tcpProbe(ctx context.Context, target ProbeTarget, count int, interval, timeout time.Duration, dscp int, timestamp time.Time) *Result {
+	values := make([]string, count)
+	for n := range values {
+		values[n] = "-"
+		if n > 0 {
+			select {
+			case <-ctx.Done():
+				continue
+			case <-time.After(interval):
+			}
+		}
+		if rtt, ok := e.tcpConnect(ctx, target.IP, timeout, dscp); ok {
+			values[n] = strconv.FormatFloat(float64(rtt.Microseconds())/1000, 'f', 3, 64)
+		}
+	}
+
+	payload := e.parseRTTValues(strings.Join(values, " "))
+	payload.SourceAddress = e.Source.SourceAddress
+	payload.SourceInterface = e.Source.Interface
+	payload.DSCP = target.DSCP
+	payload.Mode = ICMPModeTCP
+	payload.FailureLossPct = e.failureLossPct(target.Params)
+
+	return &Result{
+		TargetID:  target.ID,
+		Timestamp: timestamp,
+		Success:   payload.Successful(),
+		Error:     e.errorMessage(payload),
+		Payload:   MarshalPayload(payload),
+	}
+}
+
+// tcpConnect times a TCP handshake to ip:TCPPort. A refused connection still
+// proves the host answered, so it counts as a reply.
+func (e *ICMPExecutor) tcpConnect(ctx context.Context, ip string, timeout time.Duration, dscp int) (time.Duration, bool) {
+	targetIP := net.ParseIP(ip)
+	dialer := net.Dialer{Timeout: timeout}
+	if local, err := e.Source.LocalIP(targetIP); err == nil && local != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: local}
+	}
+	if dscp > 0 {
+		dialer.Control = dscpControl(targetIP, dscp)
+	}
+
+	port := e.TCPPort
+	if port <= 0 {
+		port = 443
+	}
+
+	start := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	rtt := time.Since(start)
+	if err == nil {
+		conn.Close()
+		return rtt, true
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return rtt, true
+	}
+	return 0, false
+}
@@ -0,0 +1,144 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestParseICMPModes(t *testing.T) {
+	tests := []struct {
+		name    string
+		modes   []string
+		want    []ICMPMode
+		wantErr bool
+	}{
+		{name: "empty uses default", modes: nil, want: DefaultICMPModes},
+		{name: "custom order", modes: []string{"tcp", " RAW "}, want: []ICMPMode{ICMPModeTCP, ICMPModeRaw}},
+		{name: "unknown mode", modes: []string{"raw", "udp"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseICMPModes(tt.modes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseICMPModes() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("ParseICMPModes() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ParseICMPModes() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestSelectICMPMode(t *testing.T) {
+	rawHost := ICMPSupport{FpingWorks: true, Raw: true}
+	dgramHost := ICMPSupport{FpingWorks: true, Datagram: true}
+	lockedDown := ICMPSupport{}
+
+	tests := []struct {
+		name    string
+		prefs   []ICMPMode
+		support ICMPSupport
+		want    ICMPMode
+		wantErr bool
+	}{
+		{name: "raw available", prefs: DefaultICMPModes, support: rawHost, want: ICMPModeRaw},
+		{name: "falls back to dgram", prefs: DefaultICMPModes, support: dgramHost, want: ICMPModeDatagram},
+		{name: "falls back to tcp", prefs: DefaultICMPModes, support: lockedDown, want: ICMPModeTCP},
+		{name: "dgram not allowed", prefs: []ICMPMode{ICMPModeRaw, ICMPModeTCP}, support: dgramHost, want: ICMPModeTCP},
+		{name: "fping prefers raw", prefs: []ICMPMode{ICMPModeDatagram}, support: rawHost, want: ICMPModeRaw},
+		{name: "tcp preferred", prefs: []ICMPMode{ICMPModeTCP, ICMPModeRaw}, support: rawHost, want: ICMPModeTCP},
+		{name: "fping broken", prefs: []ICMPMode{ICMPModeRaw, ICMPModeDatagram}, support: ICMPSupport{Datagram: true}, wantErr: true},
+		{name: "nothing usable", prefs: []ICMPMode{ICMPModeRaw}, support: dgramHost, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SelectICMPMode(tt.prefs, tt.support)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SelectICMPMode() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("SelectICMPMode() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestICMPExecutor_TCPMode(t *testing.T) {
+	// An open port answers with SYN-ACK
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer open.Close()
+	go func() {
+		for {
+			conn, err := open.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	// A closed port answers with RST, which still proves the host is up
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	tests := []struct {
+		name string
+		port int
+	}{
+		{name: "open port", port: open.Addr().(*net.TCPAddr).Port},
+		{name: "closed port", port: closedPort},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewICMPExecutor()
+			e.Mode = ICMPModeTCP
+			e.TCPPort = tt.port
+			e.DefaultIntervalMs = 1
+
+			result, err := e.Execute(context.Background(), ProbeTarget{ID: "t1", IP: "127.0.0.1", Timeout: time.Second})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !result.Success {
+				t.Fatalf("expected success, got error %q", result.Error)
+			}
+
+			var payload ICMPPayload
+			if err := json.Unmarshal(result.Payload, &payload); err != nil {
+				t.Fatal(err)
+			}
+			if payload.Mode != ICMPModeTCP {
+				t.Errorf("mode = %q, want tcp", payload.Mode)
+			}
+			if payload.PacketsSent != 3 || payload.PacketsRecvd != 3 {
+				t.Errorf("sent/recvd = %d/%d, want 3/3", payload.PacketsSent, payload.PacketsRecvd)
+			}
+		})
+	}
+}
+
+func TestICMPExecutor_CapabilitiesTCPMode(t *testing.T) {
+	e := NewICMPExecutor()
+	e.Mode = ICMPModeTCP
+	if deps := e.Capabilities().Dependencies; len(deps) != 0 {
+		t.Errorf("TCP mode should not need fping, got dependencies %v", deps)
+	}
+}
@@ -58,15 +58,28 @@ func TestICMPExecutor_ParseRTTValues(t *testing.T) {
 			wantRecvd:     3,
 		},
 		{
+			// The first lost packet is forgiven, see "Success and Packet
+			// Loss" in icmp.go
 			name:          "partial loss",
 			input:         "12.45 - 11.80",
 			wantReachable: true,
-			wantLoss:      33.33333333333333,
+			wantLoss:      0.0,
 			wantMinMs:     11.80,
 			wantMaxMs:     12.45,
 			wantPackets:   3,
 			wantRecvd:     2,
 		},
+		{
+			// Losses after the first count over the remaining packets
+			name:          "two lost",
+			input:         "- 12.45 -",
+			wantReachable: true,
+			wantLoss:      50.0,
+			wantMinMs:     12.45,
+			wantMaxMs:     12.45,
+			wantPackets:   3,
+			wantRecvd:     1,
+		},
 		{
 			name:          "all failed",
 			input:         "- - -",
@@ -141,7 +154,7 @@ func TestICMPExecutor_ParseOutput(t *testing.T) {
 `)
 
 	timestamp := time.Now()
-	results := e.parseOutput(output, ipToTarget, timestamp)
+	results := e.parseOutput(output, nil, ipToTarget, timestamp)
 
 	if len(results) != 3 {
 		t.Fatalf("expected 3 results, got %d", len(results))
@@ -175,9 +188,10 @@ func TestICMPExecutor_ParseOutput(t *testing.T) {
 	if !r.Success {
 		t.Error("cloudflare should be successful (partial loss)")
 	}
+	// One lost packet of three is within the first-packet grace
 	json.Unmarshal(r.Payload, &payload)
-	if payload.PacketLoss < 30 || payload.PacketLoss > 35 {
-		t.Errorf("cloudflare packet loss: got %f, want ~33", payload.PacketLoss)
+	if payload.PacketLoss != 0 {
+		t.Errorf("cloudflare packet loss: got %f, want 0", payload.PacketLoss)
 	}
 
 	// Check internal (all failed)
@@ -248,6 +262,195 @@ func TestICMPExecutor_ParseParams(t *testing.T) {
 	}
 }
 
+func TestICMPExecutor_SourceArgs(t *testing.T) {
+	tests := []struct {
+		name   string
+		source SourceBinding
+		want   []string
+	}{
+		{
+			name:   "unbound",
+			source: SourceBinding{},
+			want:   nil,
+		},
+		{
+			name:   "source address only",
+			source: SourceBinding{SourceAddress: "203.0.113.10"},
+			want:   []string{"-S", "203.0.113.10"},
+		},
+		{
+			name:   "interface only",
+			source: SourceBinding{Interface: "eth1"},
+			want:   []string{"-I", "eth1"},
+		},
+		{
+			name:   "both",
+			source: SourceBinding{SourceAddress: "203.0.113.10", Interface: "eth1"},
+			want:   []string{"-S", "203.0.113.10", "-I", "eth1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewICMPExecutor()
+			e.Source = tt.source
+			got := e.sourceArgs()
+			if len(got) != len(tt.want) {
+				t.Fatalf("args: got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("args[%d]: got %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestICMPExecutor_ParseOutput_ReportsSource(t *testing.T) {
+	e := NewICMPExecutor()
+	e.Source = SourceBinding{SourceAddress: "203.0.113.10", Interface: "eth1"}
+
+	ipToTarget := map[string]ProbeTarget{
+		"192.0.2.1": {ID: "t1", IP: "192.0.2.1"},
+		"192.0.2.2": {ID: "t2", IP: "192.0.2.2"},
+	}
+	results := e.parseOutput([]byte("192.0.2.1 : 1.00 1.10 1.20\n"), nil, ipToTarget, time.Now())
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	for _, r := range results {
+		payload, err := UnmarshalPayload[ICMPPayload](r.Payload)
+		if err != nil {
+			t.Fatalf("unmarshal payload: %v", err)
+		}
+		if payload.SourceAddress != "203.0.113.10" || payload.SourceInterface != "eth1" {
+			t.Errorf("%s: source not reported: %+v", r.TargetID, payload)
+		}
+	}
+}
+
+func TestParseReplyTTLs(t *testing.T) {
+	tests := []struct {
+		name   string
+		output string
+		want   map[string]int
+	}{
+		{
+			name: "last reply wins",
+			output: `192.0.2.1 : [0], 64 bytes, 1.00 ms (1.00 avg, 0% loss) (TTL 58)
+192.0.2.1 : [1], 64 bytes, 1.10 ms (1.05 avg, 0% loss) (TTL 57)
+`,
+			want: map[string]int{"192.0.2.1": 57},
+		},
+		{
+			name:   "ipv6 address",
+			output: "2001:db8::1 : [0], 64 bytes, 2.00 ms (2.00 avg, 0% loss) (TTL 60)\n",
+			want:   map[string]int{"2001:db8::1": 60},
+		},
+		{
+			name:   "ttl unknown skipped",
+			output: "192.0.2.1 : [0], 64 bytes, 1.00 ms (1.00 avg, 0% loss) (TTL unknown)\n",
+			want:   map[string]int{},
+		},
+		{
+			name:   "no ttl printed",
+			output: "192.0.2.1 : [0], 64 bytes, 1.00 ms (1.00 avg, 0% loss)\n",
+			want:   map[string]int{},
+		},
+		{
+			name:   "summary line ignored",
+			output: "192.0.2.1 : 1.00 1.10 1.20\n",
+			want:   map[string]int{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseReplyTTLs([]byte(tt.output))
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for ip, ttl := range tt.want {
+				if got[ip] != ttl {
+					t.Errorf("%s: got TTL %d, want %d", ip, got[ip], ttl)
+				}
+			}
+		})
+	}
+}
+
+func TestICMPExecutor_ParseOutput_ReplyTTL(t *testing.T) {
+	e := NewICMPExecutor()
+
+	ipToTarget := map[string]ProbeTarget{
+		"192.0.2.1": {ID: "up", IP: "192.0.2.1"},
+		"192.0.2.2": {ID: "down", IP: "192.0.2.2"},
+	}
+	ttls := map[string]int{"192.0.2.1": 58, "192.0.2.2": 60}
+	results := e.parseOutput([]byte("192.0.2.1 : 1.00 1.10 1.20\n192.0.2.2 : - - -\n"), ttls, ipToTarget, time.Now())
+
+	want := map[string]int{"up": 58, "down": 0}
+	for _, r := range results {
+		payload, err := UnmarshalPayload[ICMPPayload](r.Payload)
+		if err != nil {
+			t.Fatalf("unmarshal payload: %v", err)
+		}
+		if payload.ReplyTTL != want[r.TargetID] {
+			t.Errorf("%s: reply TTL %d, want %d", r.TargetID, payload.ReplyTTL, want[r.TargetID])
+		}
+	}
+}
+
+func TestICMPExecutor_ParseOutput_FailureThreshold(t *testing.T) {
+	tests := []struct {
+		name        string
+		values      string
+		executorPct float64
+		params      string
+		wantSuccess bool
+		wantPct     float64
+	}{
+		{"no loss", "1.0 1.1 1.2", 0, "", true, DefaultFailureLossPct},
+		{"one lost is forgiven", "1.0 - 1.2", 0, "", true, DefaultFailureLossPct},
+		{"two lost fails at default", "1.0 - -", 0, "", false, DefaultFailureLossPct},
+		{"executor threshold above loss", "1.0 - -", 75, "", true, 75},
+		{"target param overrides executor", "1.0 - -", 75, `{"failure_loss_pct": 40}`, false, 40},
+		{"all lost", "- - -", 100, "", false, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewICMPExecutor()
+			e.FailureLossPct = tt.executorPct
+			target := ProbeTarget{ID: "t", IP: "192.0.2.1"}
+			if tt.params != "" {
+				target.Params = json.RawMessage(tt.params)
+			}
+
+			results := e.parseOutput([]byte("192.0.2.1 : "+tt.values+"\n"), nil,
+				map[string]ProbeTarget{target.IP: target}, time.Now())
+			if len(results) != 1 {
+				t.Fatalf("expected 1 result, got %d", len(results))
+			}
+			r := results[0]
+			if r.Success != tt.wantSuccess {
+				t.Errorf("success = %v, want %v (error %q)", r.Success, tt.wantSuccess, r.Error)
+			}
+			if r.Success != (r.Error == "") {
+				t.Errorf("success %v inconsistent with error %q", r.Success, r.Error)
+			}
+			payload, err := UnmarshalPayload[ICMPPayload](r.Payload)
+			if err != nil {
+				t.Fatalf("unmarshal payload: %v", err)
+			}
+			if payload.FailureLossPct != tt.wantPct {
+				t.Errorf("failure_loss_pct = %v, want %v", payload.FailureLossPct, tt.wantPct)
+			}
+		})
+	}
+}
+
 func TestICMPExecutor_ErrorMessage(t *testing.T) {
 	e := NewICMPExecutor()
 
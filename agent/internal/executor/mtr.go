@@ -32,6 +32,14 @@ import (
 	"time"
 )
 
+func init() {
+	RegisterPlugin("mtr", func(cfg PluginConfig) (Executor, error) {
+		e := NewMTRExecutor()
+		e.Source = cfg.Source
+		return e, nil
+	})
+}
+
 // MTRExecutor runs MTR path traces.
 type MTRExecutor struct {
 	// MTRPath is the path to the mtr binary. Default: "mtr"
@@ -39,6 +47,9 @@ type MTRExecutor struct {
 
 	// DefaultCycles is the number of probe cycles per hop. Default: 10
 	DefaultCycles int
+
+	// Source pins traces to a source address and/or interface (optional)
+	Source SourceBinding
 }
 
 // NewMTRExecutor creates a new MTR executor with sensible defaults.
@@ -62,6 +73,13 @@ type MTRPayload struct {
 	ReachedDst bool      `json:"reached_dst"`
 	DstLatency float64   `json:"dst_latency_ms,omitempty"`
 	RawOutput  string    `json:"raw_output,omitempty"`
+
+	// Source binding the trace egressed from (empty if unbound)
+	SourceAddress   string `json:"source_address,omitempty"`
+	SourceInterface string `json:"source_interface,omitempty"`
+
+	// DSCP codepoint the trace was marked with (0 = best effort)
+	DSCP int `json:"dscp,omitempty"`
 }
 
 // MTRHop contains statistics for a single hop.
@@ -104,7 +122,7 @@ func (e *MTRExecutor) Execute(ctx context.Context, target ProbeTarget) (*Result,
 	start := time.Now()
 
 	// Run mtr with JSON output
-	output, err := e.runMTR(ctx, target.IP, params, timeout)
+	output, err := e.runMTR(ctx, target.IP, params, timeout, target.DSCP)
 	duration := time.Since(start)
 
 	if err != nil && len(output) == 0 {
@@ -114,12 +132,20 @@ func (e *MTRExecutor) Execute(ctx context.Context, target ProbeTarget) (*Result,
 			Duration:  duration,
 			Success:   false,
 			Error:     fmt.Sprintf("mtr failed: %v", err),
-			Payload:   MarshalPayload(MTRPayload{Target: target.IP}),
+			Payload: MarshalPayload(MTRPayload{
+				Target:          target.IP,
+				SourceAddress:   e.Source.SourceAddress,
+				SourceInterface: e.Source.Interface,
+				DSCP:            target.DSCP,
+			}),
 		}, nil
 	}
 
 	// Parse the JSON output
 	payload := e.parseOutput(target.IP, output)
+	payload.SourceAddress = e.Source.SourceAddress
+	payload.SourceInterface = e.Source.Interface
+	payload.DSCP = target.DSCP
 
 	return &Result{
 		TargetID:  target.ID,
@@ -145,7 +171,7 @@ func (e *MTRExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget) (
 }
 
 // runMTR executes mtr and returns the raw output.
-func (e *MTRExecutor) runMTR(ctx context.Context, ip string, params MTRParams, timeout time.Duration) ([]byte, error) {
+func (e *MTRExecutor) runMTR(ctx context.Context, ip string, params MTRParams, timeout time.Duration, dscp int) ([]byte, error) {
 	mtrPath := e.MTRPath
 	if mtrPath == "" {
 		mtrPath = "mtr"
@@ -161,12 +187,24 @@ func (e *MTRExecutor) runMTR(ctx context.Context, ip string, params MTRParams, t
 	// --report-cycles : Number of pings per hop
 	// --json : Output in JSON format
 	// --no-dns : Skip DNS resolution for faster execution (optional)
+	// --address : Source address (multi-homed agents)
+	// --interface : Bind to interface (multi-homed agents)
+	// --tos : Type of service byte (DSCP << 2)
 	args := []string{
 		"--report-wide",
 		fmt.Sprintf("--report-cycles=%d", cycles),
 		"--json",
-		ip,
 	}
+	if e.Source.SourceAddress != "" {
+		args = append(args, "--address="+e.Source.SourceAddress)
+	}
+	if e.Source.Interface != "" {
+		args = append(args, "--interface="+e.Source.Interface)
+	}
+	if dscp > 0 {
+		args = append(args, fmt.Sprintf("--tos=%d", DSCPToTOS(dscp)))
+	}
+	args = append(args, ip)
 
 	// Create context with timeout
 	ctx, cancel := context.WithTimeout(ctx, timeout)
@@ -0,0 +1,146 @@
+// Package executor - plugin registration for custom check types.
+//
+// Executors register themselves by name from an init function, and the
+// agent builds every registered plugin at startup:
+//
+//	func init() {
+//		executor.RegisterPlugin("tls_cert", func(cfg executor.PluginConfig) (executor.Executor, error) {
+//			return NewTLSCertExecutor(cfg.Source), nil
+//		})
+//	}
+//
+// icmp_ping is the exception, built by the agent itself: its mode is chosen
+// by probing the host for raw sockets, unprivileged sockets and fping in
+// the configured preference order, most of its settings come from the
+// agent's probing config, and the agent reports the chosen mode in its
+// heartbeat. None of that fits a factory that only gets a PluginConfig.
+//
+// A plugin that only probes one target at a time can implement Prober and
+// be wrapped with NewProberExecutor rather than implementing all of
+// Executor. The control plane treats probe types as opaque names: a target
+// whose probe_type names a plugin is handed to it with the target's
+// probe_params.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Prober is the minimal interface for a custom check: probe one target.
+type Prober interface {
+	Probe(ctx context.Context, target ProbeTarget) (*Result, error)
+}
+
+// PluginConfig is what the agent hands a plugin factory.
+type PluginConfig struct {
+	// Source is the egress binding configured for the plugin's type
+	Source SourceBinding
+}
+
+// PluginFactory builds a plugin executor. The executor's Type must be the
+// name the factory was registered under.
+type PluginFactory func(cfg PluginConfig) (Executor, error)
+
+var (
+	pluginsMu sync.RWMutex
+	plugins   = make(map[string]PluginFactory)
+)
+
+// RegisterPlugin makes a plugin available under name. It is meant to be
+// called from init and panics on an empty or duplicate name, as
+// database/sql does for drivers.
+func RegisterPlugin(name string, factory PluginFactory) {
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+	if name == "" || factory == nil {
+		panic("executor: RegisterPlugin needs a name and factory")
+	}
+	if _, dup := plugins[name]; dup {
+		panic("executor: RegisterPlugin called twice for " + name)
+	}
+	plugins[name] = factory
+}
+
+// Plugins returns the registered plugin names, sorted.
+func Plugins() []string {
+	pluginsMu.RLock()
+	defer pluginsMu.RUnlock()
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// NewPlugin builds the plugin registered under name.
+func NewPlugin(name string, cfg PluginConfig) (Executor, error) {
+	pluginsMu.RLock()
+	factory, ok := plugins[name]
+	pluginsMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown executor plugin: %s", name)
+	}
+
+	e, err := factory(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("building executor plugin %s: %w", name, err)
+	}
+	if e.Type() != name {
+		return nil, fmt.Errorf("executor plugin %s reports type %q", name, e.Type())
+	}
+	return e, nil
+}
+
+// NewProberExecutor turns a Prober into an Executor of type typ that
+// probes batches one target at a time.
+func NewProberExecutor(typ string, caps Capabilities, p Prober) Executor {
+	return &proberExecutor{typ: typ, caps: caps, prober: p}
+}
+
+type proberExecutor struct {
+	typ    string
+	caps   Capabilities
+	prober Prober
+}
+
+func (e *proberExecutor) Type() string { return e.typ }
+
+func (e *proberExecutor) Capabilities() Capabilities { return e.caps }
+
+func (e *proberExecutor) Execute(ctx context.Context, target ProbeTarget) (*Result, error) {
+	return e.prober.Probe(ctx, target)
+}
+
+func (e *proberExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget) ([]*Result, error) {
+	return ExecuteEach(ctx, targets, e.prober.Probe)
+}
+
+// ExecuteEach probes targets one after another. A target whose probe
+// errors gets a failed result carrying the error, so one bad target
+// doesn't cost the rest of the batch; cancellation stops the batch.
+func ExecuteEach(ctx context.Context, targets []ProbeTarget, probe func(context.Context, ProbeTarget) (*Result, error)) ([]*Result, error) {
+	results := make([]*Result, 0, len(targets))
+	for _, target := range targets {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		result, err := probe(ctx, target)
+		if err != nil {
+			result = &Result{
+				TargetID:  target.ID,
+				Timestamp: start,
+				Duration:  time.Since(start),
+				Error:     err.Error(),
+				Payload:   MarshalPayload(struct{}{}),
+			}
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
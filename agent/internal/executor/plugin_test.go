@@ -0,0 +1,82 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+)
+
+type proberFunc func(ctx context.Context, target ProbeTarget) (*Result, error)
+
+func (f proberFunc) Probe(ctx context.Context, target ProbeTarget) (*Result, error) {
+	return f(ctx, target)
+}
+
+func TestNewPlugin_Registered(t *testing.T) {
+	for _, name := range []string{"mtr", "tcp_connect", "tls_cert"} {
+		if !slices.Contains(Plugins(), name) {
+			t.Fatalf("Plugins() = %v, want %s registered", Plugins(), name)
+		}
+	}
+
+	e, err := NewPlugin("tls_cert", PluginConfig{})
+	if err != nil {
+		t.Fatalf("NewPlugin(tls_cert) error = %v", err)
+	}
+	if e.Type() != "tls_cert" {
+		t.Errorf("Type() = %q, want tls_cert", e.Type())
+	}
+
+	if _, err := NewPlugin("no_such_check", PluginConfig{}); err == nil {
+		t.Error("NewPlugin(no_such_check) error = nil, want unknown plugin")
+	}
+}
+
+func TestNewPlugin_TypeMismatch(t *testing.T) {
+	RegisterPlugin("test_mismatch", func(PluginConfig) (Executor, error) {
+		return &MockExecutor{TypeName: "something_else"}, nil
+	})
+
+	if _, err := NewPlugin("test_mismatch", PluginConfig{}); err == nil {
+		t.Error("NewPlugin() error = nil, want type mismatch")
+	}
+}
+
+func TestRegisterPlugin_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RegisterPlugin() did not panic on a duplicate name")
+		}
+	}()
+	RegisterPlugin("tls_cert", func(PluginConfig) (Executor, error) { return nil, nil })
+}
+
+func TestProberExecutor_BatchKeepsGoingPastErrors(t *testing.T) {
+	e := NewProberExecutor("test_probe", Capabilities{}, proberFunc(func(_ context.Context, target ProbeTarget) (*Result, error) {
+		if target.ID == "bad" {
+			return nil, errors.New("connection refused")
+		}
+		return &Result{TargetID: target.ID, Success: true}, nil
+	}))
+
+	results, err := e.ExecuteBatch(context.Background(), []ProbeTarget{{ID: "a"}, {ID: "bad"}, {ID: "c"}})
+	if err != nil {
+		t.Fatalf("ExecuteBatch() error = %v", err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3", len(results))
+	}
+	if r := results[1]; r.TargetID != "bad" || r.Success || r.Error != "connection refused" {
+		t.Errorf("failed target result = %+v, want failure carrying the error", r)
+	}
+	if !results[0].Success || !results[2].Success {
+		t.Error("targets after the failure were not probed")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := e.ExecuteBatch(ctx, []ProbeTarget{{ID: "a"}}); !errors.Is(err, context.Canceled) {
+		t.Errorf("ExecuteBatch() on cancelled context error = %v, want context.Canceled", err)
+	}
+}
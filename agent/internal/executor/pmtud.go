@@ -0,0 +1,156 @@
+// Package executor - path MTU discovery using fping.
+//
+// # How it works
+//
+// fping -M sets the don't-fragment bit, so a ping larger than the smallest
+// link on the path is dropped (or answered with a "fragmentation needed"
+// error the host may never see) instead of being split. Starting from the
+// largest size and narrowing down, the largest ping that still gets a reply
+// is the path MTU.
+//
+// Sizes are whole IP packets; fping's -b takes the ICMP data length, so the
+// IP and ICMP headers are subtracted first. IPv6 routers never fragment, so
+// for IPv6 the search works the same way without needing the DF bit.
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os/exec"
+	"strconv"
+	"time"
+)
+
+// Header overhead of an echo request: IP header plus the 8-byte ICMP header.
+const (
+	pmtudOverheadIPv4 = 20 + 8
+	pmtudOverheadIPv6 = 40 + 8
+)
+
+// Each size gets a few pings so a single lost packet isn't mistaken for a
+// size that doesn't fit.
+const (
+	pmtudPingCount  = 3
+	pmtudIntervalMs = 200
+)
+
+// fping exits 1 when a host didn't answer; anything higher is a failure to
+// run the ping at all.
+const fpingExitUnreachable = 1
+
+// MTUProbe is one size tried during path MTU discovery.
+type MTUProbe struct {
+	Size    int
+	Replied bool
+}
+
+// DiscoverPathMTU finds the largest IP packet between minMTU and maxMTU that
+// reaches ip and gets a reply without fragmenting. It returns 0 if even
+// minMTU got no reply. The sizes tried are returned in the order sent.
+func (e *ICMPExecutor) DiscoverPathMTU(ctx context.Context, ip string, minMTU, maxMTU int, timeout time.Duration) (int, []MTUProbe, error) {
+	if e.Mode == ICMPModeTCP {
+		return 0, nil, fmt.Errorf("path MTU discovery needs ICMP, executor is in %s mode", ICMPModeTCP)
+	}
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return 0, nil, fmt.Errorf("invalid address %q", ip)
+	}
+	overhead := pmtudOverheadIPv4
+	if addr.To4() == nil {
+		overhead = pmtudOverheadIPv6
+	}
+	if minMTU <= overhead || minMTU > maxMTU {
+		return 0, nil, fmt.Errorf("invalid MTU range %d-%d", minMTU, maxMTU)
+	}
+
+	probe := func(ctx context.Context, size int) (bool, error) {
+		return e.pingDF(ctx, ip, size-overhead, timeout)
+	}
+	return searchPathMTU(ctx, minMTU, maxMTU, probe)
+}
+
+// searchPathMTU tries maxMTU first, since most paths carry full-size
+// packets, then minMTU, then binary searches between them. probe reports
+// whether a packet of the given size got a reply.
+func searchPathMTU(ctx context.Context, minMTU, maxMTU int, probe func(context.Context, int) (bool, error)) (int, []MTUProbe, error) {
+	var probes []MTUProbe
+	try := func(size int) (bool, error) {
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+		ok, err := probe(ctx, size)
+		if err != nil {
+			return false, fmt.Errorf("probing %d bytes: %w", size, err)
+		}
+		probes = append(probes, MTUProbe{Size: size, Replied: ok})
+		return ok, nil
+	}
+
+	ok, err := try(maxMTU)
+	if err != nil {
+		return 0, probes, err
+	}
+	if ok {
+		return maxMTU, probes, nil
+	}
+	if minMTU == maxMTU {
+		return 0, probes, nil
+	}
+	ok, err = try(minMTU)
+	if err != nil || !ok {
+		return 0, probes, err
+	}
+
+	// Invariant: lo got a reply, hi didn't
+	lo, hi := minMTU, maxMTU
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		ok, err := try(mid)
+		if err != nil {
+			return 0, probes, err
+		}
+		if ok {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return lo, probes, nil
+}
+
+// pingDF sends pmtudPingCount don't-fragment pings carrying dataBytes of
+// ICMP data and reports whether any were answered.
+//
+// -M    : Set the don't-fragment bit
+// -b n  : ICMP data bytes
+// -c n  : Pings to send
+// -q    : Quiet; only the exit status matters
+func (e *ICMPExecutor) pingDF(ctx context.Context, ip string, dataBytes int, timeout time.Duration) (bool, error) {
+	fpingPath := e.FpingPath
+	if fpingPath == "" {
+		fpingPath = "fping"
+	}
+	args := []string{
+		"-M",
+		"-b", strconv.Itoa(dataBytes),
+		"-c", strconv.Itoa(pmtudPingCount),
+		"-t", strconv.FormatInt(timeout.Milliseconds(), 10),
+		"-p", strconv.Itoa(pmtudIntervalMs),
+		"-q",
+	}
+	args = append(args, e.sourceArgs()...)
+	args = append(args, ip)
+
+	err := exec.CommandContext(ctx, fpingPath, args...).Run()
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return true, nil
+	case errors.As(err, &exitErr) && exitErr.ExitCode() == fpingExitUnreachable:
+		return false, nil
+	default:
+		return false, fmt.Errorf("running fping: %w", err)
+	}
+}
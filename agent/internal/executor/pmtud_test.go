@@ -0,0 +1,72 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSearchPathMTU_Cases(t *testing.T) {
+	tests := []struct {
+		name       string
+		min, max   int
+		pathMTU    int // largest size the fake path carries; 0 drops everything
+		wantMTU    int
+		wantProbes int
+	}{
+		{"full size fits", 576, 1500, 1500, 1500, 1},
+		{"tunnel overhead", 576, 1500, 1476, 1476, 12},
+		{"just under max", 576, 1500, 1499, 1499, 12},
+		{"exactly min", 576, 1500, 576, 576, 11},
+		{"nothing answers", 576, 1500, 0, 0, 2},
+		{"single size dropped", 1500, 1500, 1400, 0, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := func(_ context.Context, size int) (bool, error) {
+				return size <= tt.pathMTU, nil
+			}
+			mtu, probes, err := searchPathMTU(context.Background(), tt.min, tt.max, probe)
+			if err != nil {
+				t.Fatalf("searchPathMTU() error = %v", err)
+			}
+			if mtu != tt.wantMTU {
+				t.Errorf("mtu = %d, want %d", mtu, tt.wantMTU)
+			}
+			if len(probes) != tt.wantProbes {
+				t.Errorf("sent %d probes, want %d: %v", len(probes), tt.wantProbes, probes)
+			}
+			if probes[0].Size != tt.max {
+				t.Errorf("first probe = %d bytes, want the max %d", probes[0].Size, tt.max)
+			}
+		})
+	}
+}
+
+func TestSearchPathMTU_ProbeError(t *testing.T) {
+	boom := errors.New("fping missing")
+	probe := func(_ context.Context, size int) (bool, error) {
+		if size < 1500 {
+			return false, boom
+		}
+		return false, nil
+	}
+
+	_, probes, err := searchPathMTU(context.Background(), 576, 1500, probe)
+	if !errors.Is(err, boom) {
+		t.Fatalf("error = %v, want %v", err, boom)
+	}
+	if len(probes) != 1 {
+		t.Errorf("recorded %d probes, want only the one that completed", len(probes))
+	}
+}
+
+func TestICMPExecutor_DiscoverPathMTU_TCPMode(t *testing.T) {
+	e := NewICMPExecutor()
+	e.Mode = ICMPModeTCP
+
+	if _, _, err := e.DiscoverPathMTU(context.Background(), "192.0.2.1", 576, 1500, 0); err == nil {
+		t.Fatal("expected an error in TCP mode")
+	}
+}
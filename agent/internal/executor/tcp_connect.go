@@ -0,0 +1,152 @@
+// Package executor - TCP connect probe.
+//
+// tcp_connect times TCP handshakes to a target port, for devices that drop
+// ICMP but answer on a service port (443 unless the target's probe_params
+// set one). Each probe makes up to 1 + Retries connect attempts, each
+// bounded by the tier timeout, and stops at the first that connects.
+// Attempts are reported like ping packets, so results carry avg_ms and
+// packet_loss_pct and the control plane handles them like icmp_ping
+// results.
+//
+// Unlike the icmp_ping executor's TCP fallback, which only needs to know
+// the host is there, a refused connection is a failed attempt: the port
+// being probed is the service.
+package executor
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// tcpConnectDefaultTimeout bounds each attempt when the tier sets no timeout.
+const tcpConnectDefaultTimeout = 5 * time.Second
+
+func init() {
+	RegisterPlugin(types.ProbeTypeTCPConnect, func(cfg PluginConfig) (Executor, error) {
+		return NewTCPConnectExecutor(cfg.Source), nil
+	})
+}
+
+// TCPConnectExecutor probes targets with TCP connects.
+type TCPConnectExecutor struct {
+	// Source pins connections to a source address and/or interface (optional)
+	Source SourceBinding
+}
+
+// NewTCPConnectExecutor creates a TCP connect executor.
+func NewTCPConnectExecutor(source SourceBinding) *TCPConnectExecutor {
+	return &TCPConnectExecutor{Source: source}
+}
+
+// Type returns the executor type identifier.
+func (e *TCPConnectExecutor) Type() string {
+	return types.ProbeTypeTCPConnect
+}
+
+// Capabilities returns what this executor can do.
+func (e *TCPConnectExecutor) Capabilities() Capabilities {
+	return Capabilities{
+		SupportsBatching: false,
+		MaxBatchSize:     1,
+	}
+}
+
+// ExecuteBatch probes each target in turn.
+func (e *TCPConnectExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget) ([]*Result, error) {
+	return ExecuteEach(ctx, targets, e.Execute)
+}
+
+// Execute probes one target. Failed connects are failed results, not
+// errors.
+func (e *TCPConnectExecutor) Execute(ctx context.Context, target ProbeTarget) (*Result, error) {
+	params, err := types.ParseTCPConnectParams(target.Params)
+	if err != nil {
+		return nil, err
+	}
+	timeout := target.Timeout
+	if timeout <= 0 {
+		timeout = tcpConnectDefaultTimeout
+	}
+
+	start := time.Now()
+	result := &Result{TargetID: target.ID, Timestamp: start}
+	payload := types.TCPConnectPayload{Port: params.Port, DSCP: target.DSCP}
+
+	var lastErr error
+	for attempt := 0; attempt <= max(target.Retries, 0); attempt++ {
+		if err := ctx.Err(); err != nil {
+			lastErr = err
+			break
+		}
+		payload.PacketsSent++
+		rtt, err := e.connect(ctx, target.IP, params.Port, timeout, target.DSCP)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		ms := float64(rtt.Microseconds()) / 1000
+		payload.PacketsRecvd = 1
+		payload.Reachable = true
+		payload.LatencyMs, payload.MinMs, payload.MaxMs, payload.AvgMs = ms, ms, ms, ms
+		break
+	}
+	result.Duration = time.Since(start)
+
+	result.Success = payload.Reachable
+	if !result.Success && lastErr != nil {
+		result.Error = lastErr.Error()
+	}
+	if payload.PacketsSent > 0 {
+		payload.PacketLoss = float64(payload.PacketsSent-payload.PacketsRecvd) / float64(payload.PacketsSent) * 100
+	}
+
+	result.Payload = MarshalPayload(payload)
+	return result, nil
+}
+
+// connect times one TCP handshake to ip:port.
+func (e *TCPConnectExecutor) connect(ctx context.Context, ip string, port int, timeout time.Duration, dscp int) (time.Duration, error) {
+	targetIP := net.ParseIP(ip)
+	dialer, err := e.Source.Dialer(targetIP, timeout)
+	if err != nil {
+		return 0, err
+	}
+	if dscp > 0 {
+		dialer.Control = dscpControl(targetIP, dscp)
+	}
+
+	begin := time.Now()
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(port)))
+	rtt := time.Since(begin)
+	if err != nil {
+		return 0, fmt.Errorf("tcp connect: %w", err)
+	}
+	conn.Close()
+	return rtt, nil
+}
+
+// dscpControl returns a dialer Control function that marks the socket's
+// packets with dscp, using the traffic class for IPv6 targets.
+func dscpControl(targetIP net.IP, dscp int) func(network, address string, c syscall.RawConn) error {
+	level, opt := syscall.IPPROTO_IP, syscall.IP_TOS
+	if targetIP != nil && targetIP.To4() == nil {
+		level, opt = syscall.IPPROTO_IPV6, syscall.IPV6_TCLASS
+	}
+	tos := DSCPToTOS(dscp)
+	return func(network, address string, c syscall.RawConn) error {
+		var sockErr error
+		if err := c.Control(func(fd uintptr) {
+			sockErr = syscall.SetsockoptInt(int(fd), level, opt, tos)
+		}); err != nil {
+			return err
+		}
+		return sockErr
+	}
+}
@@ -0,0 +1,91 @@
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/payload"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestTCPConnectExecutor_Execute(t *testing.T) {
+	open, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer open.Close()
+	openPort := open.Addr().(*net.TCPAddr).Port
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedPort := closed.Addr().(*net.TCPAddr).Port
+	closed.Close()
+
+	tests := []struct {
+		name        string
+		port        int
+		retries     int
+		wantSuccess bool
+		wantSent    int
+		wantLoss    float64
+	}{
+		{name: "open port", port: openPort, wantSuccess: true, wantSent: 1},
+		{name: "open port stops retrying", port: openPort, retries: 2, wantSuccess: true, wantSent: 1},
+		{name: "refused", port: closedPort, wantSent: 1, wantLoss: 100},
+		{name: "refused with retries", port: closedPort, retries: 2, wantSent: 3, wantLoss: 100},
+	}
+
+	e := NewTCPConnectExecutor(SourceBinding{})
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := e.Execute(context.Background(), ProbeTarget{
+				ID:      "t-1",
+				IP:      "127.0.0.1",
+				Timeout: time.Second,
+				Retries: tt.retries,
+				Params:  json.RawMessage(fmt.Sprintf(`{"port": %d}`, tt.port)),
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Success != tt.wantSuccess {
+				t.Errorf("Success = %v, want %v (error %q)", result.Success, tt.wantSuccess, result.Error)
+			}
+			if !tt.wantSuccess && result.Error == "" {
+				t.Error("failed result has no error")
+			}
+
+			p, err := UnmarshalPayload[types.TCPConnectPayload](result.Payload)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Port != tt.port || p.PacketsSent != tt.wantSent || p.PacketLoss != tt.wantLoss {
+				t.Errorf("payload port %d sent %d loss %v, want %d, %d, %v",
+					p.Port, p.PacketsSent, p.PacketLoss, tt.port, tt.wantSent, tt.wantLoss)
+			}
+
+			// The control plane reads latency and loss like an icmp_ping payload
+			m := payload.Decode(result.Payload)
+			if !m.Valid() || m.PacketLoss() == nil || *m.PacketLoss() != tt.wantLoss {
+				t.Errorf("decoded payload loss = %v, want %v", m.PacketLoss(), tt.wantLoss)
+			}
+			if tt.wantSuccess && (m.Latency() == nil || *m.Latency() <= 0) {
+				t.Errorf("decoded latency = %v, want > 0", m.Latency())
+			}
+		})
+	}
+}
+
+func TestTCPConnectExecutor_InvalidParams(t *testing.T) {
+	e := NewTCPConnectExecutor(SourceBinding{})
+	_, err := e.Execute(context.Background(), ProbeTarget{ID: "t-1", IP: "127.0.0.1", Params: json.RawMessage(`{"port": 0x}`)})
+	if err == nil {
+		t.Fatal("Execute() with malformed params succeeded")
+	}
+}
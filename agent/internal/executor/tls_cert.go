@@ -0,0 +1,168 @@
+// Package executor - TLS certificate check.
+//
+// tls_cert connects to a target's TLS port, completes a handshake and
+// reports the leaf certificate: subject, issuer, names, validity and days
+// to expiry, and whether the chain verifies. The probe succeeds when the
+// handshake does; the control plane raises cert_expiry alerts from the
+// reported expiry against its own thresholds, so an expiring certificate
+// doesn't also read as the service being down.
+//
+// The handshake accepts any certificate so that an expired or otherwise
+// invalid one can still be read; chain validity is checked separately
+// against the system roots.
+//
+// It is registered as a plugin (see plugin.go) and serves as the example
+// of a custom check type.
+package executor
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// tlsCertDefaultTimeout bounds the handshake when the tier sets no timeout.
+const tlsCertDefaultTimeout = 10 * time.Second
+
+func init() {
+	RegisterPlugin(types.ProbeTypeTLSCert, func(cfg PluginConfig) (Executor, error) {
+		return NewTLSCertExecutor(cfg.Source), nil
+	})
+}
+
+// TLSCertExecutor reads TLS certificates.
+type TLSCertExecutor struct {
+	// Source pins connections to a source address and/or interface (optional)
+	Source SourceBinding
+
+	// Roots verifies certificate chains. Nil uses the system roots.
+	Roots *x509.CertPool
+
+	// now is the clock expiry is measured against (tests)
+	now func() time.Time
+}
+
+// NewTLSCertExecutor creates a TLS certificate executor.
+func NewTLSCertExecutor(source SourceBinding) *TLSCertExecutor {
+	return &TLSCertExecutor{Source: source}
+}
+
+// Type returns the executor type identifier.
+func (e *TLSCertExecutor) Type() string {
+	return types.ProbeTypeTLSCert
+}
+
+// Capabilities returns what this executor can do.
+func (e *TLSCertExecutor) Capabilities() Capabilities {
+	return Capabilities{
+		SupportsBatching: false,
+		MaxBatchSize:     1,
+	}
+}
+
+// ExecuteBatch checks each target in turn.
+func (e *TLSCertExecutor) ExecuteBatch(ctx context.Context, targets []ProbeTarget) ([]*Result, error) {
+	return ExecuteEach(ctx, targets, e.Execute)
+}
+
+// Execute reads one target's certificate. Connection and handshake
+// failures are failed results, not errors.
+func (e *TLSCertExecutor) Execute(ctx context.Context, target ProbeTarget) (*Result, error) {
+	params, err := types.ParseTLSCertParams(target.Params)
+	if err != nil {
+		return nil, err
+	}
+	timeout := target.Timeout
+	if timeout <= 0 {
+		timeout = tlsCertDefaultTimeout
+	}
+
+	start, began := e.clock(), time.Now()
+	result := &Result{TargetID: target.ID, Timestamp: start}
+	payload := types.TLSCertPayload{Port: params.Port, ServerName: params.ServerName}
+
+	state, err := e.handshake(ctx, target.IP, params, timeout)
+	result.Duration = time.Since(began)
+	payload.HandshakeMs = float64(result.Duration.Microseconds()) / 1000
+	if err != nil {
+		result.Error = err.Error()
+		result.Payload = MarshalPayload(payload)
+		return result, nil
+	}
+
+	leaf := state.PeerCertificates[0]
+	payload.Subject = leaf.Subject.String()
+	payload.Issuer = leaf.Issuer.String()
+	payload.DNSNames = leaf.DNSNames
+	payload.SerialNumber = leaf.SerialNumber.Text(16)
+	payload.NotBefore = leaf.NotBefore
+	payload.NotAfter = leaf.NotAfter
+	payload.DaysRemaining = types.DaysUntil(leaf.NotAfter, start)
+	if err := e.verify(state, params.ServerName); err != nil {
+		payload.VerifyError = err.Error()
+	} else {
+		payload.ChainValid = true
+	}
+
+	result.Success = true
+	result.Payload = MarshalPayload(payload)
+	return result, nil
+}
+
+// handshake connects and completes a TLS handshake, accepting any
+// certificate.
+func (e *TLSCertExecutor) handshake(ctx context.Context, ip string, params types.TLSCertParams, timeout time.Duration) (tls.ConnectionState, error) {
+	dialer, err := e.Source.Dialer(net.ParseIP(ip), timeout)
+	if err != nil {
+		return tls.ConnectionState{}, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	conn, err := (&tls.Dialer{
+		NetDialer: dialer,
+		Config: &tls.Config{
+			ServerName:         params.ServerName,
+			InsecureSkipVerify: true, // read the certificate even when invalid; see verify
+		},
+	}).DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(params.Port)))
+	if err != nil {
+		return tls.ConnectionState{}, fmt.Errorf("tls handshake: %w", err)
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	if len(state.PeerCertificates) == 0 {
+		return tls.ConnectionState{}, fmt.Errorf("server presented no certificate")
+	}
+	return state, nil
+}
+
+// verify checks the presented chain against the roots and, when set, the
+// server name.
+func (e *TLSCertExecutor) verify(state tls.ConnectionState, serverName string) error {
+	intermediates := x509.NewCertPool()
+	for _, cert := range state.PeerCertificates[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := state.PeerCertificates[0].Verify(x509.VerifyOptions{
+		DNSName:       serverName,
+		Roots:         e.Roots,
+		Intermediates: intermediates,
+		CurrentTime:   e.clock(),
+	})
+	return err
+}
+
+func (e *TLSCertExecutor) clock() time.Time {
+	if e.now == nil {
+		return time.Now()
+	}
+	return e.now()
+}
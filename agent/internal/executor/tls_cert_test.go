@@ -0,0 +1,129 @@
+package executor
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestTLSCertExecutor_Execute(t *testing.T) {
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // the probe hangs up after the handshake
+	srv.StartTLS()
+	defer srv.Close()
+
+	host, port, err := net.SplitHostPort(srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	notAfter := srv.Certificate().NotAfter
+	roots := x509.NewCertPool()
+	roots.AddCert(srv.Certificate())
+
+	tests := []struct {
+		name       string
+		now        time.Time
+		params     string
+		wantDays   int
+		wantValid  bool
+		wantVerify string
+	}{
+		{
+			name:      "valid with matching name",
+			now:       notAfter.Add(-30 * 24 * time.Hour),
+			params:    fmt.Sprintf(`{"port": %s, "server_name": "example.com"}`, port),
+			wantDays:  30,
+			wantValid: true,
+		},
+		{
+			name:      "chain verified without a name",
+			now:       notAfter.Add(-5*24*time.Hour - time.Hour),
+			params:    fmt.Sprintf(`{"port": %s}`, port),
+			wantDays:  5,
+			wantValid: true,
+		},
+		{
+			name:       "name mismatch",
+			now:        notAfter.Add(-30 * 24 * time.Hour),
+			params:     fmt.Sprintf(`{"port": %s, "server_name": "wrong.test"}`, port),
+			wantDays:   30,
+			wantVerify: "wrong.test",
+		},
+		{
+			name:       "expired still succeeds, reported negative",
+			now:        notAfter.Add(time.Hour),
+			params:     fmt.Sprintf(`{"port": %s}`, port),
+			wantDays:   -1,
+			wantVerify: "expired",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := NewTLSCertExecutor(SourceBinding{})
+			e.Roots = roots
+			e.now = func() time.Time { return tt.now }
+
+			result, err := e.Execute(context.Background(), ProbeTarget{
+				ID: "t1", IP: host, Timeout: 5 * time.Second, Params: json.RawMessage(tt.params),
+			})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !result.Success {
+				t.Fatalf("Success = false (error %q), want true", result.Error)
+			}
+
+			payload, err := UnmarshalPayload[types.TLSCertPayload](result.Payload)
+			if err != nil {
+				t.Fatalf("decoding payload: %v", err)
+			}
+			if !payload.NotAfter.Equal(notAfter) {
+				t.Errorf("NotAfter = %v, want %v", payload.NotAfter, notAfter)
+			}
+			if payload.DaysRemaining != tt.wantDays {
+				t.Errorf("DaysRemaining = %d, want %d", payload.DaysRemaining, tt.wantDays)
+			}
+			if payload.ChainValid != tt.wantValid {
+				t.Errorf("ChainValid = %v, want %v (verify error %q)", payload.ChainValid, tt.wantValid, payload.VerifyError)
+			}
+			if tt.wantVerify != "" && !strings.Contains(payload.VerifyError, tt.wantVerify) {
+				t.Errorf("VerifyError = %q, want it to mention %q", payload.VerifyError, tt.wantVerify)
+			}
+			if len(payload.DNSNames) == 0 || payload.SerialNumber == "" {
+				t.Errorf("payload missing names or serial: %+v", payload)
+			}
+		})
+	}
+}
+
+func TestTLSCertExecutor_ConnectionFailure(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := ln.Addr().(*net.TCPAddr).Port
+	ln.Close() // nothing listening any more
+
+	e := NewTLSCertExecutor(SourceBinding{})
+	result, err := e.Execute(context.Background(), ProbeTarget{
+		ID: "t1", IP: "127.0.0.1", Timeout: time.Second, Params: json.RawMessage(fmt.Sprintf(`{"port": %d}`, port)),
+	})
+	if err != nil {
+		t.Fatalf("Execute() error = %v, want a failed result", err)
+	}
+	if result.Success || !strings.Contains(result.Error, "tls handshake") {
+		t.Errorf("result = %+v, want handshake failure", result)
+	}
+}
@@ -0,0 +1,196 @@
+package scheduler
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/payload"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Adaptive probing
+//
+// With adaptive probing on, each target in a tier with an AdaptiveMaxInterval
+// gets its own effective interval between the tier's ProbeInterval and
+// AdaptiveMaxInterval. The tier loop still ticks at ProbeInterval and skips
+// targets that aren't due.
+//
+// The control loop backs off slowly and recovers fast. A target must show
+// StableStreak consecutive low-variance results before its interval doubles,
+// while a failure, any packet loss or high variance returns it to the base
+// interval at once. Variance between StableCV and UnstableCV holds the
+// interval where it is; that gap is what stops a borderline target from
+// flapping between intervals.
+
+// AdaptiveConfig tunes the adaptive interval control loop.
+type AdaptiveConfig struct {
+	// Alpha is the EWMA weight of each new latency sample.
+	Alpha float64
+
+	// StableCV is the coefficient of variation (stddev / mean latency)
+	// below which a result counts toward backing off.
+	StableCV float64
+
+	// UnstableCV is the coefficient of variation above which the target
+	// returns to the base interval.
+	UnstableCV float64
+
+	// StableStreak is how many consecutive stable results a target needs
+	// at its current interval before the interval doubles. Also the number
+	// of samples needed before the variance estimate is trusted.
+	StableStreak int
+}
+
+// DefaultAdaptiveConfig returns the default control loop tuning.
+func DefaultAdaptiveConfig() AdaptiveConfig {
+	return AdaptiveConfig{
+		Alpha:        0.2,
+		StableCV:     0.1,
+		UnstableCV:   0.3,
+		StableStreak: 10,
+	}
+}
+
+// adaptiveState tracks one target's latency statistics and interval.
+type adaptiveState struct {
+	tier     string
+	mean     float64 // EWMA latency
+	variance float64 // EWMA variance of latency
+	samples  int
+	streak   int // consecutive stable results at the current interval
+	interval time.Duration
+	nextDue  time.Time
+}
+
+// adaptiveController holds per-target adaptive state across tier loops.
+type adaptiveController struct {
+	config AdaptiveConfig
+
+	mu      sync.Mutex
+	targets map[string]*adaptiveState // target ID -> state
+}
+
+func newAdaptiveController(config AdaptiveConfig) *adaptiveController {
+	return &adaptiveController{config: config, targets: make(map[string]*adaptiveState)}
+}
+
+// adaptiveEnabled reports whether the tier has room to back off.
+func adaptiveEnabled(tier types.Tier) bool {
+	return tier.AdaptiveMaxInterval > tier.ProbeInterval
+}
+
+// due reports whether a target should be probed this tick. Targets without
+// state are always due. Half a base interval of slack absorbs ticker jitter
+// so a target isn't pushed a whole extra tick late.
+func (c *adaptiveController) due(targetID string, tier types.Tier, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.targets[targetID]
+	if !ok {
+		return true
+	}
+	return !now.Before(st.nextDue.Add(-tier.ProbeInterval / 2))
+}
+
+// observe feeds a probe result into the target's control loop and schedules
+// its next probe.
+func (c *adaptiveController) observe(tierName string, tier types.Tier, r *executor.Result, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	st, ok := c.targets[r.TargetID]
+	if !ok || st.tier != tierName {
+		st = &adaptiveState{tier: tierName, interval: tier.ProbeInterval}
+		c.targets[r.TargetID] = st
+	}
+
+	latency, loss, hasLatency := resultLatency(r)
+	switch {
+	case !r.Success || loss > 0:
+		st.reset(tier)
+	case hasLatency:
+		st.update(latency, c.config.Alpha)
+		c.step(st, tier)
+	}
+	st.nextDue = now.Add(st.interval)
+}
+
+// step applies the hysteresis rules after a successful sample.
+func (c *adaptiveController) step(st *adaptiveState, tier types.Tier) {
+	if st.samples < c.config.StableStreak || st.mean <= 0 {
+		return
+	}
+	cv := math.Sqrt(st.variance) / st.mean
+	switch {
+	case cv > c.config.UnstableCV:
+		st.reset(tier)
+	case cv < c.config.StableCV:
+		st.streak++
+		if st.streak >= c.config.StableStreak && st.interval < tier.AdaptiveMaxInterval {
+			st.interval = min(st.interval*2, tier.AdaptiveMaxInterval)
+			st.streak = 0
+		}
+	default:
+		st.streak = 0
+	}
+}
+
+func (st *adaptiveState) reset(tier types.Tier) {
+	st.interval = tier.ProbeInterval
+	st.streak = 0
+}
+
+// update folds a latency sample into the EWMA mean and variance.
+func (st *adaptiveState) update(latency, alpha float64) {
+	if st.samples == 0 {
+		st.mean = latency
+	} else {
+		diff := latency - st.mean
+		st.mean += alpha * diff
+		st.variance = (1 - alpha) * (st.variance + alpha*diff*diff)
+	}
+	st.samples++
+}
+
+// resultLatency extracts latency and loss from an ICMP-style payload.
+// Payloads without latency (e.g. MTR) report hasLatency false.
+func resultLatency(r *executor.Result) (latency, loss float64, hasLatency bool) {
+	m := payload.Decode(r.Payload)
+	if !m.Valid() {
+		return 0, 0, false
+	}
+	if m.AvgMs != nil {
+		latency = *m.AvgMs
+	}
+	if latency == 0 && m.LatencyMs != nil {
+		latency = *m.LatencyMs
+	}
+	return latency, *m.PacketLoss(), latency > 0
+}
+
+// retain drops state for targets no longer assigned.
+func (c *adaptiveController) retain(assigned map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range c.targets {
+		if !assigned[id] {
+			delete(c.targets, id)
+		}
+	}
+}
+
+// intervals counts targets per effective interval, per tier.
+func (c *adaptiveController) intervals() map[string]map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]map[string]int)
+	for _, st := range c.targets {
+		if out[st.tier] == nil {
+			out[st.tier] = make(map[string]int)
+		}
+		out[st.tier][st.interval.String()]++
+	}
+	return out
+}
@@ -0,0 +1,110 @@
+package scheduler
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func icmpResult(latencyMs, lossPct float64, success bool) *executor.Result {
+	payload, _ := json.Marshal(executor.ICMPPayload{AvgMs: latencyMs, LatencyMs: latencyMs, PacketLoss: lossPct})
+	return &executor.Result{TargetID: "t-1", Success: success, Payload: payload}
+}
+
+// steady returns n results at a constant latency.
+func steady(n int, latencyMs float64) []*executor.Result {
+	results := make([]*executor.Result, n)
+	for i := range results {
+		results[i] = icmpResult(latencyMs, 0, true)
+	}
+	return results
+}
+
+// alternating returns n results swinging +/- swing around latencyMs.
+func alternating(n int, latencyMs, swing float64) []*executor.Result {
+	results := make([]*executor.Result, n)
+	for i := range results {
+		if i%2 == 0 {
+			results[i] = icmpResult(latencyMs+swing, 0, true)
+		} else {
+			results[i] = icmpResult(latencyMs-swing, 0, true)
+		}
+	}
+	return results
+}
+
+func concat(parts ...[]*executor.Result) []*executor.Result {
+	var out []*executor.Result
+	for _, p := range parts {
+		out = append(out, p...)
+	}
+	return out
+}
+
+func TestAdaptiveController_Interval(t *testing.T) {
+	tier := types.Tier{ProbeInterval: 30 * time.Second, AdaptiveMaxInterval: 2 * time.Minute}
+	streak := DefaultAdaptiveConfig().StableStreak
+
+	tests := []struct {
+		name    string
+		results []*executor.Result
+		want    time.Duration
+	}{
+		{"warming up", steady(streak-1, 20), 30 * time.Second},
+		{"stable backs off once", steady(2*streak, 20), time.Minute},
+		{"stable caps at max", steady(10*streak, 20), 2 * time.Minute},
+		{"failure resets", concat(steady(10*streak, 20), []*executor.Result{icmpResult(0, 100, false)}), 30 * time.Second},
+		{"packet loss resets", concat(steady(10*streak, 20), []*executor.Result{icmpResult(20, 10, true)}), 30 * time.Second},
+		{"noisy stays at base", alternating(10*streak, 20, 10), 30 * time.Second},
+		// CV of about 0.2 sits between StableCV and UnstableCV: no back-off,
+		// but no reset either
+		{"borderline holds", concat(steady(2*streak, 20), alternating(10*streak, 20, 4)), time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newAdaptiveController(DefaultAdaptiveConfig())
+			now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+			for _, r := range tt.results {
+				c.observe("standard", tier, r, now)
+				now = now.Add(tier.ProbeInterval)
+			}
+			if got := c.targets["t-1"].interval; got != tt.want {
+				t.Errorf("interval = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAdaptiveController_Due(t *testing.T) {
+	tier := types.Tier{ProbeInterval: 30 * time.Second, AdaptiveMaxInterval: 2 * time.Minute}
+	c := newAdaptiveController(DefaultAdaptiveConfig())
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	if !c.due("t-1", tier, start) {
+		t.Fatal("unknown target should be due")
+	}
+
+	c.observe("standard", tier, icmpResult(20, 0, true), start)
+	c.targets["t-1"].interval = time.Minute
+	c.targets["t-1"].nextDue = start.Add(time.Minute)
+
+	tests := []struct {
+		after time.Duration
+		want  bool
+	}{
+		{30 * time.Second, false},
+		{59 * time.Second, true}, // early tick within jitter slack
+		{time.Minute, true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.after), func(t *testing.T) {
+			if got := c.due("t-1", tier, start.Add(tt.after)); got != tt.want {
+				t.Errorf("due after %s = %v, want %v", tt.after, got, tt.want)
+			}
+		})
+	}
+}
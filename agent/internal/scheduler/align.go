@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Schedule alignment
+//
+// A drifting tier loop ticks every interval from when the agent started, so
+// probe times differ between agents and spread the load on shared targets
+// and links. An aligned loop runs on wall-clock multiples of the interval
+// (every minute on the minute, every 5s on :00, :05, ...), so every aligned
+// agent probes a target at the same instant. That makes results easy to
+// correlate with other systems and across agents, at the cost of bursts:
+// all aligned agents hit their targets together, and all fping batches of a
+// tier start at once.
+
+// Alignment is how a tier's probe loop is timed.
+type Alignment string
+
+const (
+	AlignmentDrifting Alignment = "drifting"
+	AlignmentAligned  Alignment = "aligned"
+)
+
+// ParseAlignment validates an alignment. Empty is drifting.
+func ParseAlignment(s string) (Alignment, error) {
+	switch a := Alignment(strings.ToLower(strings.TrimSpace(s))); a {
+	case "":
+		return AlignmentDrifting, nil
+	case AlignmentDrifting, AlignmentAligned:
+		return a, nil
+	default:
+		return "", fmt.Errorf("unknown schedule alignment %q (want aligned or drifting)", s)
+	}
+}
+
+// SetAlignment sets each tier's loop alignment. Tiers without an entry use
+// defaultAlignment. Must be called before Run.
+func (s *Scheduler) SetAlignment(defaultAlignment Alignment, perTier map[string]Alignment) {
+	s.tierMu.Lock()
+	defer s.tierMu.Unlock()
+	s.defaultAlignment = defaultAlignment
+	s.alignment = perTier
+}
+
+// alignmentFor returns a tier's alignment.
+func (s *Scheduler) alignmentFor(tierName string) Alignment {
+	s.tierMu.RLock()
+	defer s.tierMu.RUnlock()
+	if a, ok := s.alignment[tierName]; ok {
+		return a
+	}
+	if s.defaultAlignment != "" {
+		return s.defaultAlignment
+	}
+	return AlignmentDrifting
+}
+
+// nextAlignedRun returns the first interval boundary after now. Boundaries
+// are multiples of interval from Go's zero time, so agents agree on them
+// without coordinating (minutes land on :00, days on 00:00 UTC).
+func nextAlignedRun(now time.Time, interval time.Duration) time.Time {
+	return now.Truncate(interval).Add(interval)
+}
+
+// runAlignedLoop runs a tier's probes on interval boundaries. The first run
+// waits for the next boundary; a run that overruns skips the boundaries it
+// missed rather than firing late.
+func (s *Scheduler) runAlignedLoop(ctx context.Context, tierName string, tier types.Tier) {
+	for {
+		timer := time.NewTimer(time.Until(nextAlignedRun(time.Now(), tier.ProbeInterval)))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			s.logger.Info("stopping probe loop", "tier", tierName)
+			return
+		case <-timer.C:
+			s.executeTierProbes(ctx, tierName, tier)
+		}
+	}
+}
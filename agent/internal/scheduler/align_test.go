@@ -0,0 +1,59 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAlignment_Values(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Alignment
+		wantErr bool
+	}{
+		{"empty defaults to drifting", "", AlignmentDrifting, false},
+		{"drifting", "drifting", AlignmentDrifting, false},
+		{"aligned", "aligned", AlignmentAligned, false},
+		{"case and space insensitive", " Aligned ", AlignmentAligned, false},
+		{"unknown", "smeared", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseAlignment(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAlignment(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAlignment(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextAlignedRun_Boundaries(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		interval time.Duration
+		want     time.Time
+	}{
+		{"mid interval", base.Add(7 * time.Second), 5 * time.Second, base.Add(10 * time.Second)},
+		{"on boundary moves to next", base, time.Minute, base.Add(time.Minute)},
+		{"just before boundary", base.Add(59*time.Second + 999*time.Millisecond), time.Minute, base.Add(time.Minute)},
+		{"minute interval on the minute", base.Add(90 * time.Second), time.Minute, base.Add(2 * time.Minute)},
+		{"thirty seconds", base.Add(31 * time.Second), 30 * time.Second, base.Add(time.Minute)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := nextAlignedRun(tt.now, tt.interval)
+			if !got.Equal(tt.want) {
+				t.Errorf("nextAlignedRun(%v, %v) = %v, want %v", tt.now, tt.interval, got, tt.want)
+			}
+		})
+	}
+}
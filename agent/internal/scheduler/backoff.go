@@ -0,0 +1,114 @@
+package scheduler
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Down target backoff
+//
+// The control plane sets BackoffMaxInterval on the assignments of targets it
+// holds DOWN or EXCLUDED in tiers that allow it. Each failed probe of such a
+// target doubles its interval, up to that cap; the first successful probe
+// drops it straight back to the tier interval, so recovery is seen at full
+// cadence without waiting for the control plane to reassign. As with
+// adaptive probing, the tier loop keeps ticking at ProbeInterval and skips
+// targets that aren't due.
+
+// backoffState tracks one down target's backed-off interval.
+type backoffState struct {
+	tier     string
+	interval time.Duration
+	nextDue  time.Time
+}
+
+// backoffController holds per-target backoff state across tier loops.
+type backoffController struct {
+	mu      sync.Mutex
+	targets map[string]*backoffState // probe ID -> state
+}
+
+func newBackoffController() *backoffController {
+	return &backoffController{targets: make(map[string]*backoffState)}
+}
+
+// filter returns the assignments due this tick, and the backoff cap of each
+// assignment allowed to back off. Assignments without a cap above the tier
+// interval are always due.
+func (c *backoffController) filter(assignments []types.Assignment, tier types.Tier, now time.Time) ([]types.Assignment, map[string]time.Duration) {
+	caps := make(map[string]time.Duration)
+	for _, a := range assignments {
+		if a.BackoffMaxInterval > tier.ProbeInterval {
+			caps[a.ProbeID()] = a.BackoffMaxInterval
+		}
+	}
+	if len(caps) == 0 {
+		return assignments, nil
+	}
+
+	due := make([]types.Assignment, 0, len(assignments))
+	for _, a := range assignments {
+		if _, ok := caps[a.ProbeID()]; !ok || c.due(a.ProbeID(), tier, now) {
+			due = append(due, a)
+		}
+	}
+	return due, caps
+}
+
+// due reports whether a target should be probed this tick, with the same
+// half-interval jitter slack as adaptive probing.
+func (c *backoffController) due(probeID string, tier types.Tier, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.targets[probeID]
+	if !ok {
+		return true
+	}
+	return !now.Before(st.nextDue.Add(-tier.ProbeInterval / 2))
+}
+
+// observe doubles the target's interval on a failed probe, up to maxInterval,
+// and forgets it on a successful one.
+func (c *backoffController) observe(tierName string, tier types.Tier, maxInterval time.Duration, r *executor.Result, now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if r.Success {
+		delete(c.targets, r.TargetID)
+		return
+	}
+
+	st, ok := c.targets[r.TargetID]
+	if !ok || st.tier != tierName {
+		st = &backoffState{tier: tierName, interval: tier.ProbeInterval}
+		c.targets[r.TargetID] = st
+	}
+	st.interval = min(st.interval*2, maxInterval)
+	st.nextDue = now.Add(st.interval)
+}
+
+// retain drops state for targets no longer assigned with a backoff cap,
+// whether unassigned or brought back up by the control plane.
+func (c *backoffController) retain(backingOff map[string]bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for id := range c.targets {
+		if !backingOff[id] {
+			delete(c.targets, id)
+		}
+	}
+}
+
+// backedOff counts targets per tier probed at a backed-off interval.
+func (c *backoffController) backedOff() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string]int)
+	for _, st := range c.targets {
+		out[st.tier]++
+	}
+	return out
+}
@@ -0,0 +1,87 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestBackoffController_Interval(t *testing.T) {
+	tier := types.Tier{ProbeInterval: 30 * time.Second}
+	maxInterval := 5 * time.Minute
+	failed := &executor.Result{TargetID: "t-1"}
+	succeeded := &executor.Result{TargetID: "t-1", Success: true}
+
+	tests := []struct {
+		name    string
+		results []*executor.Result
+		want    time.Duration // zero: no backoff state
+	}{
+		{"first failure doubles", []*executor.Result{failed}, time.Minute},
+		{"keeps doubling", []*executor.Result{failed, failed, failed}, 4 * time.Minute},
+		{"caps at max", []*executor.Result{failed, failed, failed, failed, failed}, 5 * time.Minute},
+		{"response resets", []*executor.Result{failed, failed, failed, succeeded}, 0},
+		{"fails again after response", []*executor.Result{failed, failed, succeeded, failed}, time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newBackoffController()
+			now := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+			for _, r := range tt.results {
+				c.observe("standard", tier, maxInterval, r, now)
+				now = now.Add(tier.ProbeInterval)
+			}
+			var got time.Duration
+			if st, ok := c.targets["t-1"]; ok {
+				got = st.interval
+			}
+			if got != tt.want {
+				t.Errorf("interval = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffController_Filter(t *testing.T) {
+	tier := types.Tier{ProbeInterval: 30 * time.Second}
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	assignments := []types.Assignment{
+		{TargetID: "up"},
+		{TargetID: "down", BackoffMaxInterval: 5 * time.Minute},
+		{TargetID: "cap too low", BackoffMaxInterval: 30 * time.Second},
+	}
+
+	c := newBackoffController()
+	c.observe("standard", tier, 5*time.Minute, &executor.Result{TargetID: "down"}, start)
+
+	tests := []struct {
+		name  string
+		after time.Duration
+		want  []string
+	}{
+		{"backed off target skipped", 30 * time.Second, []string{"up", "cap too low"}},
+		{"due within jitter slack", 45 * time.Second, []string{"up", "down", "cap too low"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			due, caps := c.filter(assignments, tier, start.Add(tt.after))
+			var got []string
+			for _, a := range due {
+				got = append(got, a.TargetID)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("due = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("due = %v, want %v", got, tt.want)
+				}
+			}
+			if len(caps) != 1 || caps["down"] != 5*time.Minute {
+				t.Errorf("caps = %v, want only down at 5m", caps)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"maps"
+	"sync"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+)
+
+// ErrShed is returned for a batch dropped from a saturated queue.
+var ErrShed = errors.New("probe queue full, batch shed")
+
+// Default pool sizing, matching the agent config defaults.
+const (
+	DefaultMaxConcurrent = 10
+	DefaultQueueSize     = 1000
+)
+
+// PoolConfig sizes the worker pool for one executor.
+type PoolConfig struct {
+	// MaxConcurrent is the number of batches executed at once.
+	MaxConcurrent int
+
+	// QueueSize is the number of batches that may wait for a worker.
+	// When full, the oldest batch of the lowest-priority tier is shed.
+	QueueSize int
+}
+
+// DefaultPoolConfig returns the default pool sizing.
+func DefaultPoolConfig() PoolConfig {
+	return PoolConfig{MaxConcurrent: DefaultMaxConcurrent, QueueSize: DefaultQueueSize}
+}
+
+// PoolStats is a snapshot of a pool's queue and workers.
+type PoolStats struct {
+	MaxConcurrent int   `json:"max_concurrent"`
+	QueueSize     int   `json:"queue_size"`
+	QueuedBatches int   `json:"queued_batches"`
+	QueuedTargets int   `json:"queued_targets"`
+	InFlight      int   `json:"in_flight"`
+	Shed          int64 `json:"shed_total"`
+
+	// ShedByTier counts shed targets (not batches) per tier
+	ShedByTier map[string]int64 `json:"shed_targets_by_tier,omitempty"`
+}
+
+// job is one batch waiting for a worker. done is called exactly once.
+type job struct {
+	ctx      context.Context
+	tier     string
+	priority int
+	targets  []executor.ProbeTarget
+	done     func(results []*executor.Result, err error)
+}
+
+// Pool executes probe batches for one executor on a fixed set of workers.
+// All tiers share the pool, so the cap holds across concurrent tier loops.
+//
+// Workers take batches by smooth weighted round-robin across the tiers with
+// queued work, weighted by tier priority, so critical tiers get most of the
+// capacity without starving the rest. Within a tier batches run in order.
+type Pool struct {
+	exec   executor.Executor
+	config PoolConfig
+	logger *slog.Logger
+
+	mu            sync.Mutex
+	queue         []*job
+	queuedTargets int
+	inFlight      int
+	shed          int64
+	shedByTier    map[string]int64
+	credit        map[string]int // weighted round-robin state per tier
+	closed        error
+
+	wake chan struct{}
+}
+
+// NewPool creates a pool. Call Start to run its workers.
+func NewPool(exec executor.Executor, config PoolConfig, logger *slog.Logger) *Pool {
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = DefaultMaxConcurrent
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	return &Pool{
+		exec:       exec,
+		config:     config,
+		logger:     logger.With("executor", exec.Type()),
+		shedByTier: make(map[string]int64),
+		credit:     make(map[string]int),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// Start launches the workers. They exit when ctx is cancelled, failing any
+// batches still queued.
+func (p *Pool) Start(ctx context.Context) {
+	for i := 0; i < p.config.MaxConcurrent; i++ {
+		go p.worker(ctx)
+	}
+}
+
+// Submit queues a batch with no tier and the lowest priority.
+func (p *Pool) Submit(ctx context.Context, targets []executor.ProbeTarget, done func([]*executor.Result, error)) {
+	p.SubmitTier(ctx, "", 0, targets, done)
+}
+
+// SubmitTier queues a batch for a tier. If the queue is full the oldest batch
+// of the lowest-priority tier is shed (its done gets ErrShed): fresher probes
+// are worth more than stale ones, and critical tiers more than either. When
+// every queued batch outranks the new one, the new batch is shed instead.
+func (p *Pool) SubmitTier(ctx context.Context, tier string, priority int, targets []executor.ProbeTarget, done func([]*executor.Result, error)) {
+	p.mu.Lock()
+	if p.closed != nil {
+		err := p.closed
+		p.mu.Unlock()
+		done(nil, err)
+		return
+	}
+
+	j := &job{ctx: ctx, tier: tier, priority: priority, targets: targets, done: done}
+	var shed *job
+	if len(p.queue) >= p.config.QueueSize {
+		shed = j
+		if i := p.lowestPriority(); p.queue[i].priority <= priority {
+			shed = p.removeAt(i)
+		}
+		p.shed++
+		p.shedByTier[shed.tier] += int64(len(shed.targets))
+	}
+	if shed != j {
+		p.queue = append(p.queue, j)
+		p.queuedTargets += len(targets)
+	}
+	depth := len(p.queue)
+	p.mu.Unlock()
+
+	p.signal()
+
+	if shed != nil {
+		p.logger.Warn("probe queue saturated, shedding lowest-priority batch",
+			"shed_tier", shed.tier,
+			"shed_targets", len(shed.targets),
+			"queue_depth", depth,
+			"max_concurrent", p.config.MaxConcurrent)
+		shed.done(nil, ErrShed)
+	}
+}
+
+// lowestPriority returns the index of the oldest batch with the lowest
+// priority. Caller holds mu and the queue is non-empty.
+func (p *Pool) lowestPriority() int {
+	low := 0
+	for i, j := range p.queue {
+		if j.priority < p.queue[low].priority {
+			low = i
+		}
+	}
+	return low
+}
+
+// removeAt takes the batch at index i out of the queue. Caller holds mu.
+func (p *Pool) removeAt(i int) *job {
+	j := p.queue[i]
+	copy(p.queue[i:], p.queue[i+1:])
+	p.queue[len(p.queue)-1] = nil
+	p.queue = p.queue[:len(p.queue)-1]
+	p.queuedTargets -= len(j.targets)
+	return j
+}
+
+// pick returns the index of the batch to run next: the oldest batch of the
+// tier chosen by smooth weighted round-robin. Caller holds mu and the queue
+// is non-empty.
+func (p *Pool) pick() int {
+	oldest := make(map[string]int)
+	for i, j := range p.queue {
+		if _, ok := oldest[j.tier]; !ok {
+			oldest[j.tier] = i
+		}
+	}
+
+	// Tiers that drained start from scratch when they return
+	for tier := range p.credit {
+		if _, ok := oldest[tier]; !ok {
+			delete(p.credit, tier)
+		}
+	}
+
+	best, total := -1, 0
+	for tier, i := range oldest {
+		w := max(p.queue[i].priority, 1)
+		p.credit[tier] += w
+		total += w
+		if best < 0 {
+			best = i
+			continue
+		}
+		bestTier := p.queue[best].tier
+		if p.credit[tier] > p.credit[bestTier] || (p.credit[tier] == p.credit[bestTier] && i < best) {
+			best = i
+		}
+	}
+	p.credit[p.queue[best].tier] -= total
+	return best
+}
+
+// Stats returns a snapshot of the pool.
+func (p *Pool) Stats() PoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return PoolStats{
+		MaxConcurrent: p.config.MaxConcurrent,
+		QueueSize:     p.config.QueueSize,
+		QueuedBatches: len(p.queue),
+		QueuedTargets: p.queuedTargets,
+		InFlight:      p.inFlight,
+		Shed:          p.shed,
+		ShedByTier:    maps.Clone(p.shedByTier),
+	}
+}
+
+func (p *Pool) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Pool) worker(ctx context.Context) {
+	for {
+		j := p.next(ctx)
+		if j == nil {
+			p.close(ctx.Err())
+			return
+		}
+
+		results, err := p.exec.ExecuteBatch(j.ctx, j.targets)
+
+		p.mu.Lock()
+		p.inFlight--
+		p.mu.Unlock()
+
+		j.done(results, err)
+	}
+}
+
+// next blocks until a batch is available or ctx is done (returns nil).
+func (p *Pool) next(ctx context.Context) *job {
+	for {
+		p.mu.Lock()
+		if len(p.queue) > 0 {
+			j := p.removeAt(p.pick())
+			p.inFlight++
+			more := len(p.queue) > 0
+			p.mu.Unlock()
+
+			// Pass the wakeup on so idle workers pick up the rest
+			if more {
+				p.signal()
+			}
+			return j
+		}
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-p.wake:
+		}
+	}
+}
+
+// close rejects further submissions and fails anything still queued.
+func (p *Pool) close(err error) {
+	p.mu.Lock()
+	if p.closed == nil {
+		p.closed = err
+	}
+	pending := p.queue
+	p.queue = nil
+	p.queuedTargets = 0
+	p.mu.Unlock()
+
+	for _, j := range pending {
+		j.done(nil, err)
+	}
+}
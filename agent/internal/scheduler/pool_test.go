@@ -0,0 +1,405 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// fakeExecutor records peak concurrency and can block until released.
+type fakeExecutor struct {
+	batchSize int
+	delay     time.Duration
+	release   chan struct{} // if set, ExecuteBatch waits for it
+
+	inFlight atomic.Int64
+	peak     atomic.Int64
+	calls    atomic.Int64
+}
+
+func (f *fakeExecutor) Type() string { return "fake" }
+
+func (f *fakeExecutor) Capabilities() executor.Capabilities {
+	return executor.Capabilities{SupportsBatching: true, MaxBatchSize: f.batchSize}
+}
+
+func (f *fakeExecutor) Execute(ctx context.Context, t executor.ProbeTarget) (*executor.Result, error) {
+	results, err := f.ExecuteBatch(ctx, []executor.ProbeTarget{t})
+	if err != nil {
+		return nil, err
+	}
+	return results[0], nil
+}
+
+func (f *fakeExecutor) ExecuteBatch(ctx context.Context, targets []executor.ProbeTarget) ([]*executor.Result, error) {
+	n := f.inFlight.Add(1)
+	defer f.inFlight.Add(-1)
+	for {
+		p := f.peak.Load()
+		if n <= p || f.peak.CompareAndSwap(p, n) {
+			break
+		}
+	}
+	f.calls.Add(1)
+
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if f.delay > 0 {
+		time.Sleep(f.delay)
+	}
+
+	results := make([]*executor.Result, len(targets))
+	for i, t := range targets {
+		results[i] = &executor.Result{TargetID: t.ID, Success: true}
+	}
+	return results, nil
+}
+
+func discardLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
+
+func batchOf(n int) []executor.ProbeTarget {
+	targets := make([]executor.ProbeTarget, n)
+	for i := range targets {
+		targets[i] = executor.ProbeTarget{ID: fmt.Sprintf("t-%d", i)}
+	}
+	return targets
+}
+
+func TestPool_BoundsConcurrency(t *testing.T) {
+	tests := []struct {
+		name          string
+		maxConcurrent int
+		batches       int
+	}{
+		{"fewer batches than workers", 8, 3},
+		{"more batches than workers", 4, 50},
+		{"single worker", 1, 20},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			exec := &fakeExecutor{delay: time.Millisecond}
+			pool := NewPool(exec, PoolConfig{MaxConcurrent: tt.maxConcurrent, QueueSize: tt.batches}, discardLogger())
+			pool.Start(ctx)
+
+			var wg sync.WaitGroup
+			var failed atomic.Int64
+			for i := 0; i < tt.batches; i++ {
+				wg.Add(1)
+				pool.Submit(ctx, batchOf(2), func(_ []*executor.Result, err error) {
+					if err != nil {
+						failed.Add(1)
+					}
+					wg.Done()
+				})
+			}
+			wg.Wait()
+
+			if failed.Load() != 0 {
+				t.Errorf("%d batches failed", failed.Load())
+			}
+			if got := exec.calls.Load(); got != int64(tt.batches) {
+				t.Errorf("executed %d batches, want %d", got, tt.batches)
+			}
+			if peak := exec.peak.Load(); peak > int64(tt.maxConcurrent) {
+				t.Errorf("peak concurrency %d exceeds limit %d", peak, tt.maxConcurrent)
+			}
+		})
+	}
+}
+
+func TestPool_ShedsOldestWhenFull(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exec := &fakeExecutor{release: make(chan struct{})}
+	pool := NewPool(exec, PoolConfig{MaxConcurrent: 1, QueueSize: 2}, discardLogger())
+	pool.Start(ctx)
+
+	var mu sync.Mutex
+	outcome := make(map[string]error)
+	var wg sync.WaitGroup
+	submit := func(id string) {
+		wg.Add(1)
+		pool.Submit(ctx, []executor.ProbeTarget{{ID: id}}, func(_ []*executor.Result, err error) {
+			mu.Lock()
+			outcome[id] = err
+			mu.Unlock()
+			wg.Done()
+		})
+	}
+
+	// First batch occupies the only worker
+	submit("running")
+	waitFor(t, func() bool { return pool.Stats().InFlight == 1 })
+
+	// Queue holds two; the third and fourth push out the oldest
+	for _, id := range []string{"q1", "q2", "q3", "q4"} {
+		submit(id)
+	}
+
+	stats := pool.Stats()
+	if stats.QueuedBatches != 2 || stats.QueuedTargets != 2 || stats.Shed != 2 {
+		t.Errorf("stats = %+v, want 2 queued, 2 shed", stats)
+	}
+
+	close(exec.release)
+	wg.Wait()
+
+	want := map[string]error{"running": nil, "q1": ErrShed, "q2": ErrShed, "q3": nil, "q4": nil}
+	for id, wantErr := range want {
+		if !errors.Is(outcome[id], wantErr) {
+			t.Errorf("%s: err = %v, want %v", id, outcome[id], wantErr)
+		}
+	}
+}
+
+func TestPool_ShedsLowestPriorityFirst(t *testing.T) {
+	type batch struct {
+		id       string
+		tier     string
+		priority int
+	}
+	tests := []struct {
+		name     string
+		queued   []batch
+		incoming batch
+		wantShed string
+	}{
+		{
+			name:     "low tier makes room for high tier",
+			queued:   []batch{{"std", "standard", PriorityNormal}, {"infra1", "infrastructure", PriorityCritical}},
+			incoming: batch{"infra2", "infrastructure", PriorityCritical},
+			wantShed: "std",
+		},
+		{
+			name:     "incoming batch shed when it ranks lowest",
+			queued:   []batch{{"infra", "infrastructure", PriorityCritical}, {"vip", "vip", PriorityHigh}},
+			incoming: batch{"disc", "discovery", PriorityLow},
+			wantShed: "disc",
+		},
+		{
+			name:     "oldest shed within equal priority",
+			queued:   []batch{{"std1", "standard", PriorityNormal}, {"std2", "standard", PriorityNormal}},
+			incoming: batch{"std3", "standard", PriorityNormal},
+			wantShed: "std1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			exec := &fakeExecutor{release: make(chan struct{})}
+			pool := NewPool(exec, PoolConfig{MaxConcurrent: 1, QueueSize: len(tt.queued)}, discardLogger())
+			pool.Start(ctx)
+
+			var mu sync.Mutex
+			outcome := make(map[string]error)
+			var wg sync.WaitGroup
+			submit := func(b batch) {
+				wg.Add(1)
+				pool.SubmitTier(ctx, b.tier, b.priority, []executor.ProbeTarget{{ID: b.id}}, func(_ []*executor.Result, err error) {
+					mu.Lock()
+					outcome[b.id] = err
+					mu.Unlock()
+					wg.Done()
+				})
+			}
+
+			submit(batch{"running", "infrastructure", PriorityCritical})
+			waitFor(t, func() bool { return pool.Stats().InFlight == 1 })
+			for _, b := range tt.queued {
+				submit(b)
+			}
+			submit(tt.incoming)
+
+			close(exec.release)
+			wg.Wait()
+
+			for id, err := range outcome {
+				wantShed := id == tt.wantShed
+				if errors.Is(err, ErrShed) != wantShed {
+					t.Errorf("%s: err = %v, want shed %v", id, err, wantShed)
+				}
+			}
+
+			shedTier := tt.incoming.tier
+			for _, b := range tt.queued {
+				if b.id == tt.wantShed {
+					shedTier = b.tier
+				}
+			}
+			stats := pool.Stats()
+			if stats.Shed != 1 || stats.ShedByTier[shedTier] != 1 {
+				t.Errorf("stats = %+v, want 1 target shed from %s", stats, shedTier)
+			}
+		})
+	}
+}
+
+func TestPool_WeightedDequeue(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	const perTier = 11
+	exec := &fakeExecutor{release: make(chan struct{})}
+	pool := NewPool(exec, PoolConfig{MaxConcurrent: 1, QueueSize: 2 * perTier}, discardLogger())
+	pool.Start(ctx)
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	submit := func(tier string, priority int) {
+		wg.Add(1)
+		pool.SubmitTier(ctx, tier, priority, batchOf(1), func(_ []*executor.Result, err error) {
+			mu.Lock()
+			order = append(order, tier)
+			mu.Unlock()
+			wg.Done()
+		})
+	}
+
+	submit("running", PriorityCritical)
+	waitFor(t, func() bool { return pool.Stats().InFlight == 1 })
+	for i := 0; i < perTier; i++ {
+		submit("discovery", PriorityLow)
+	}
+	for i := 0; i < perTier; i++ {
+		submit("infrastructure", PriorityCritical)
+	}
+
+	close(exec.release)
+	wg.Wait()
+
+	// Weights 100:10 give infrastructure ten of every eleven slots, but
+	// discovery still gets one despite being queued behind it
+	first := make(map[string]int)
+	for _, tier := range order[1 : perTier+1] {
+		first[tier]++
+	}
+	if first["infrastructure"] != perTier-1 || first["discovery"] != 1 {
+		t.Errorf("first %d batches = %v, want %d infrastructure and 1 discovery", perTier, first, perTier-1)
+	}
+}
+
+func TestPool_CancelFailsQueued(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	exec := &fakeExecutor{release: make(chan struct{})}
+	pool := NewPool(exec, PoolConfig{MaxConcurrent: 1, QueueSize: 10}, discardLogger())
+	pool.Start(ctx)
+
+	errs := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		pool.Submit(ctx, batchOf(1), func(_ []*executor.Result, err error) { errs <- err })
+	}
+	waitFor(t, func() bool { return pool.Stats().InFlight == 1 })
+	cancel()
+
+	for i := 0; i < 3; i++ {
+		select {
+		case err := <-errs:
+			if err == nil {
+				t.Error("expected error after cancel")
+			}
+		case <-time.After(time.Second):
+			t.Fatal("batch not completed after cancel")
+		}
+	}
+
+	// Submissions after shutdown fail immediately
+	done := make(chan error, 1)
+	pool.Submit(ctx, batchOf(1), func(_ []*executor.Result, err error) { done <- err })
+	if err := <-done; err == nil {
+		t.Error("expected error submitting to a closed pool")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// BenchmarkScheduler_LargeTargetSet runs full probe cycles over 50k targets
+// and reports peak executor concurrency and heap growth, both of which stay
+// flat regardless of target count.
+func BenchmarkScheduler_LargeTargetSet(b *testing.B) {
+	const targetCount = 50000
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	exec := &fakeExecutor{batchSize: 100, delay: 50 * time.Microsecond}
+	registry := executor.NewRegistry()
+	if err := registry.Register(exec); err != nil {
+		b.Fatal(err)
+	}
+
+	var received atomic.Int64
+	s := NewScheduler(registry, func(results []*executor.Result) {
+		received.Add(int64(len(results)))
+	}, discardLogger())
+	s.SetPoolConfigs(map[string]PoolConfig{"fake": {MaxConcurrent: 8, QueueSize: targetCount / 100}})
+	s.startPools(ctx)
+
+	assignments := make([]types.Assignment, targetCount)
+	for i := range assignments {
+		assignments[i] = types.Assignment{
+			TargetID:  fmt.Sprintf("t-%d", i),
+			IP:        "192.0.2.1",
+			Tier:      "bench",
+			ProbeType: "fake",
+		}
+	}
+	s.UpdateAssignments(assignments)
+	tier := types.Tier{Name: "bench", ProbeTimeout: time.Second}
+
+	var before runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		s.executeTierProbes(ctx, "bench", tier)
+	}
+	b.StopTimer()
+
+	var after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+
+	if got := received.Load(); got != int64(b.N*targetCount) {
+		b.Fatalf("received %d results, want %d", got, b.N*targetCount)
+	}
+	if peak := exec.peak.Load(); peak > 8 {
+		b.Fatalf("peak concurrency %d exceeds limit 8", peak)
+	}
+	b.ReportMetric(float64(exec.peak.Load()), "peak-inflight")
+	b.ReportMetric(float64(int64(after.HeapInuse)-int64(before.HeapInuse))/1024, "heap-growth-KiB")
+}
@@ -0,0 +1,36 @@
+package scheduler
+
+// Tier priorities weight how much pool capacity each tier gets while work is
+// queued, and decide what is shed first when the queue overflows. Higher is
+// more important; relative size is what matters for the weighting.
+const (
+	PriorityCritical = 100
+	PriorityHigh     = 60
+	PriorityNormal   = 30
+	PriorityLow      = 10
+
+	// DefaultTierPriority applies to tiers not listed in tierPriorities.
+	DefaultTierPriority = PriorityNormal
+)
+
+// tierPriorities ranks the known tiers. Infrastructure and gateway coverage
+// is what operators can least afford to lose; rechecks are cheap to retry
+// on the next cycle.
+var tierPriorities = map[string]int{
+	"pilot_infra":      PriorityCritical,
+	"infrastructure":   PriorityCritical,
+	"vlan_gateway":     PriorityHigh,
+	"vip":              PriorityHigh,
+	"standard":         PriorityNormal,
+	"discovery":        PriorityLow,
+	"inactive_recheck": PriorityLow,
+	"smart_recheck":    PriorityLow,
+}
+
+// TierPriority returns the scheduling priority for a tier.
+func TierPriority(tier string) int {
+	if p, ok := tierPriorities[tier]; ok {
+		return p
+	}
+	return DefaultTierPriority
+}
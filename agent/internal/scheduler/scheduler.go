@@ -11,19 +11,50 @@
 // For each tier:
 //  1. Collect all assigned targets for this tier
 //  2. Batch targets (respecting executor limits)
-//  3. Execute batches concurrently (with limit)
+//  3. Submit batches to the executor's worker pool
 //  4. Send results to shipper
 //  5. Sleep until next interval
 //
+// # Concurrency
+//
+// Each executor has one Pool shared by all tiers: a fixed number of workers
+// (max in-flight batches) fed by a bounded queue. This caps open sockets and
+// child processes regardless of assignment size. Workers favour batches from
+// higher-priority tiers (see TierPriority) without starving the rest. When
+// the queue is full the oldest batch of the lowest-priority tier is shed and
+// logged rather than letting work pile up; shed counts per tier are reported
+// in Stats so the control plane can see which coverage was lost.
+//
+// # Adaptive Intervals
+//
+// When enabled with EnableAdaptive, targets in tiers with an
+// AdaptiveMaxInterval are probed less often while stable and return to the
+// tier interval as soon as they degrade (see adaptive.go).
+//
+// # Down Target Backoff
+//
+// Assignments the control plane marks with a BackoffMaxInterval are down
+// targets: each failed probe doubles their interval up to that cap, and the
+// first response returns them to the tier interval (see backoff.go).
+//
+// # Schedule Alignment
+//
+// Tier loops drift by default: they run at start, then every interval from
+// there. Aligned tiers run on wall-clock multiples of the interval instead,
+// coordinated across agents (see align.go).
+//
 // # Graceful Handling
 //
-// - If probe execution takes longer than interval, next run starts immediately
+// - If probe execution takes longer than interval, the next run of a
+//   drifting tier starts immediately; an aligned tier waits for the next
+//   boundary
 // - Context cancellation stops all loops gracefully
 // - Assignment updates are applied atomically between probe cycles
 package scheduler
 
 import (
 	"context"
+	"errors"
 	"log/slog"
 	"sync"
 	"time"
@@ -45,10 +76,25 @@ type Scheduler struct {
 	tiers map[string]types.Tier
 	tierMu sync.RWMutex
 
+	// Loop alignment per tier, falling back to defaultAlignment (see align.go)
+	alignment        map[string]Alignment
+	defaultAlignment Alignment
+
 	// Current assignments grouped by tier
 	assignments map[string][]types.Assignment // tier -> assignments
 	assignMu    sync.RWMutex
 
+	// Worker pools per executor type, created by Run
+	poolConfigs map[string]PoolConfig
+	pools       map[string]*Pool
+	poolMu      sync.RWMutex
+
+	// Per-target adaptive intervals; nil when adaptive probing is off
+	adaptive *adaptiveController
+
+	// Per-target intervals of down targets backing off (see backoff.go)
+	backoff *backoffController
+
 	// Control
 	wg sync.WaitGroup
 }
@@ -64,9 +110,26 @@ func NewScheduler(registry *executor.Registry, handler ResultHandler, logger *sl
 		logger:      logger,
 		tiers:       make(map[string]types.Tier),
 		assignments: make(map[string][]types.Assignment),
+		poolConfigs: make(map[string]PoolConfig),
+		pools:       make(map[string]*Pool),
+		backoff:     newBackoffController(),
 	}
 }
 
+// SetPoolConfigs sets worker pool sizing per executor type. Executors
+// without an entry use DefaultPoolConfig. Must be called before Run.
+func (s *Scheduler) SetPoolConfigs(configs map[string]PoolConfig) {
+	s.poolMu.Lock()
+	defer s.poolMu.Unlock()
+	s.poolConfigs = configs
+}
+
+// EnableAdaptive turns on adaptive probe intervals for tiers that define an
+// AdaptiveMaxInterval. Must be called before Run.
+func (s *Scheduler) EnableAdaptive(config AdaptiveConfig) {
+	s.adaptive = newAdaptiveController(config)
+}
+
 // SetTiers updates the tier configurations.
 func (s *Scheduler) SetTiers(tiers map[string]types.Tier) {
 	s.tierMu.Lock()
@@ -86,6 +149,22 @@ func (s *Scheduler) UpdateAssignments(assignments []types.Assignment) {
 	s.assignments = grouped
 	s.assignMu.Unlock()
 
+	if s.adaptive != nil {
+		assigned := make(map[string]bool, len(assignments))
+		for _, a := range assignments {
+			assigned[a.ProbeID()] = true
+		}
+		s.adaptive.retain(assigned)
+	}
+
+	backingOff := make(map[string]bool)
+	for _, a := range assignments {
+		if a.BackoffMaxInterval > 0 {
+			backingOff[a.ProbeID()] = true
+		}
+	}
+	s.backoff.retain(backingOff)
+
 	// Log assignment counts
 	for tier, assigns := range grouped {
 		s.logger.Info("assignments updated",
@@ -126,6 +205,8 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	}
 	s.tierMu.RUnlock()
 
+	s.startPools(ctx)
+
 	// Start a loop for each tier
 	for _, tier := range tiers {
 		s.wg.Add(1)
@@ -140,6 +221,31 @@ func (s *Scheduler) Run(ctx context.Context) error {
 	return ctx.Err()
 }
 
+// startPools creates and starts a worker pool for every registered executor.
+func (s *Scheduler) startPools(ctx context.Context) {
+	s.poolMu.Lock()
+	defer s.poolMu.Unlock()
+
+	for _, typ := range s.registry.List() {
+		exec, ok := s.registry.Get(typ)
+		if !ok {
+			continue
+		}
+		cfg, ok := s.poolConfigs[typ]
+		if !ok {
+			cfg = DefaultPoolConfig()
+		}
+		pool := NewPool(exec, cfg, s.logger)
+		pool.Start(ctx)
+		s.pools[typ] = pool
+
+		s.logger.Info("probe pool started",
+			"executor", typ,
+			"max_concurrent", pool.config.MaxConcurrent,
+			"queue_size", pool.config.QueueSize)
+	}
+}
+
 // runTierLoop runs the probe loop for a single tier.
 func (s *Scheduler) runTierLoop(ctx context.Context, tierName string) {
 	s.tierMu.RLock()
@@ -151,9 +257,16 @@ func (s *Scheduler) runTierLoop(ctx context.Context, tierName string) {
 		return
 	}
 
+	alignment := s.alignmentFor(tierName)
 	s.logger.Info("starting probe loop",
 		"tier", tierName,
-		"interval", tier.ProbeInterval)
+		"interval", tier.ProbeInterval,
+		"alignment", alignment)
+
+	if alignment == AlignmentAligned {
+		s.runAlignedLoop(ctx, tierName, tier)
+		return
+	}
 
 	ticker := time.NewTicker(tier.ProbeInterval)
 	defer ticker.Stop()
@@ -185,75 +298,71 @@ func (s *Scheduler) executeTierProbes(ctx context.Context, tierName string, tier
 
 	start := time.Now()
 
-	// Get executor (default to icmp_ping)
-	probeType := "icmp_ping"
-	if len(assignments) > 0 && assignments[0].ProbeType != "" {
-		probeType = assignments[0].ProbeType
+	// Adaptive tiers probe only the targets whose own interval has elapsed
+	adaptive := s.adaptive != nil && adaptiveEnabled(tier)
+	if adaptive {
+		due := make([]types.Assignment, 0, len(assignments))
+		for _, a := range assignments {
+			if s.adaptive.due(a.ProbeID(), tier, start) {
+				due = append(due, a)
+			}
+		}
+		if len(due) == 0 {
+			return
+		}
+		assignments = due
 	}
 
-	exec, ok := s.registry.Get(probeType)
-	if !ok {
-		s.logger.Error("executor not found", "type", probeType)
+	// Down targets allowed to back off probe only once their interval elapses
+	assignments, backoffCaps := s.backoff.filter(assignments, tier, start)
+	if len(assignments) == 0 {
 		return
 	}
 
-	// Convert assignments to probe targets
-	targets := make([]executor.ProbeTarget, len(assignments))
-	for i, a := range assignments {
-		targets[i] = executor.ProbeTarget{
-			ID:      a.TargetID,
-			IP:      a.IP,
-			Timeout: tier.ProbeTimeout,
-			Retries: tier.ProbeRetries,
-			Params:  a.ProbeParams,
+	// Queue every batch on its executor's pool; workers bound concurrency
+	// across all tiers. A tier may mix probe types, each going to its own
+	// executor.
+	resultsChan := make(chan []*executor.Result, len(assignments))
+	var wg sync.WaitGroup
+	var shedTargets int
+	var shedMu sync.Mutex
+	for _, group := range groupByProbeType(assignments) {
+		s.poolMu.RLock()
+		pool, ok := s.pools[group.probeType]
+		s.poolMu.RUnlock()
+		if !ok {
+			s.logger.Error("executor not found",
+				"type", group.probeType,
+				"tier", tierName,
+				"targets", len(group.assignments))
+			continue
 		}
-	}
 
-	// Execute in batches concurrently
-	caps := exec.Capabilities()
-	batchSize := caps.MaxBatchSize
-	if batchSize <= 0 || !caps.SupportsBatching {
-		batchSize = 1
-	}
-
-	// Split targets into batches
-	var batches [][]executor.ProbeTarget
-	for i := 0; i < len(targets); i += batchSize {
-		end := i + batchSize
-		if end > len(targets) {
-			end = len(targets)
+		for i, batch := range batchTargets(pool.exec.Capabilities(), probeTargets(group.assignments, tier)) {
+			wg.Add(1)
+			batchNum, batch, probeType := i, batch, group.probeType
+			pool.SubmitTier(ctx, tierName, TierPriority(tierName), batch, func(results []*executor.Result, err error) {
+				defer wg.Done()
+				switch {
+				case errors.Is(err, ErrShed):
+					shedMu.Lock()
+					shedTargets += len(batch)
+					shedMu.Unlock()
+				case err != nil:
+					s.logger.Error("batch execution failed",
+						"tier", tierName,
+						"executor", probeType,
+						"error", err,
+						"batch_num", batchNum,
+						"batch_size", len(batch))
+				default:
+					for _, r := range results {
+						r.ProbeType = probeType
+					}
+					resultsChan <- results
+				}
+			})
 		}
-		batches = append(batches, targets[i:end])
-	}
-
-	// Execute batches concurrently with a limit to avoid overwhelming the system
-	maxConcurrent := 10 // Run up to 10 fping processes in parallel
-	if len(batches) < maxConcurrent {
-		maxConcurrent = len(batches)
-	}
-
-	resultsChan := make(chan []*executor.Result, len(batches))
-	sem := make(chan struct{}, maxConcurrent)
-
-	var wg sync.WaitGroup
-	for i, batch := range batches {
-		wg.Add(1)
-		go func(batchNum int, batchTargets []executor.ProbeTarget) {
-			defer wg.Done()
-			sem <- struct{}{}        // Acquire semaphore
-			defer func() { <-sem }() // Release semaphore
-
-			results, err := exec.ExecuteBatch(ctx, batchTargets)
-			if err != nil {
-				s.logger.Error("batch execution failed",
-					"tier", tierName,
-					"error", err,
-					"batch_num", batchNum,
-					"batch_size", len(batchTargets))
-				return
-			}
-			resultsChan <- results
-		}(i, batch)
 	}
 
 	// Wait for all batches to complete then close the channel
@@ -268,6 +377,22 @@ func (s *Scheduler) executeTierProbes(ctx context.Context, tierName string, tier
 		allResults = append(allResults, results...)
 	}
 
+	if adaptive {
+		now := time.Now()
+		for _, r := range allResults {
+			s.adaptive.observe(tierName, tier, r, now)
+		}
+	}
+	if len(backoffCaps) > 0 {
+		now := time.Now()
+		for _, r := range allResults {
+			if maxInterval, ok := backoffCaps[r.TargetID]; ok {
+				s.backoff.observe(tierName, tier, maxInterval, r, now)
+			}
+		}
+	}
+	restoreEndpointResults(assignments, allResults)
+
 	// Send results to handler
 	if len(allResults) > 0 && s.handler != nil {
 		s.handler(allResults)
@@ -276,16 +401,118 @@ func (s *Scheduler) executeTierProbes(ctx context.Context, tierName string, tier
 	elapsed := time.Since(start)
 	s.logger.Debug("probe cycle complete",
 		"tier", tierName,
-		"targets", len(targets),
+		"targets", len(assignments),
 		"results", len(allResults),
+		"shed", shedTargets,
 		"elapsed", elapsed)
 }
 
+// probeTypeGroup is a tier's assignments for one executor.
+type probeTypeGroup struct {
+	probeType   string
+	assignments []types.Assignment
+}
+
+// groupByProbeType splits assignments by probe type, in order of first
+// appearance. An empty probe type means icmp_ping.
+func groupByProbeType(assignments []types.Assignment) []probeTypeGroup {
+	var groups []probeTypeGroup
+	index := make(map[string]int)
+	for _, a := range assignments {
+		probeType := a.ProbeType
+		if probeType == "" {
+			probeType = "icmp_ping"
+		}
+		i, ok := index[probeType]
+		if !ok {
+			i = len(groups)
+			index[probeType] = i
+			groups = append(groups, probeTypeGroup{probeType: probeType})
+		}
+		groups[i].assignments = append(groups[i].assignments, a)
+	}
+	return groups
+}
+
+// probeTargets converts assignments into probe targets with the tier's
+// timeout and retries. Endpoint assignments are probed under their endpoint
+// ID so they don't collide with the target's primary IP.
+func probeTargets(assignments []types.Assignment, tier types.Tier) []executor.ProbeTarget {
+	targets := make([]executor.ProbeTarget, len(assignments))
+	for i, a := range assignments {
+		targets[i] = executor.ProbeTarget{
+			ID:      a.ProbeID(),
+			IP:      a.IP,
+			Timeout: tier.ProbeTimeout,
+			Retries: tier.ProbeRetries,
+			Params:  a.ProbeParams,
+			DSCP:    a.DSCP,
+		}
+	}
+	return targets
+}
+
+// restoreEndpointResults points results probed under an endpoint ID back at
+// their target, recording the endpoint separately.
+func restoreEndpointResults(assignments []types.Assignment, results []*executor.Result) {
+	targetOf := make(map[string]string)
+	for _, a := range assignments {
+		if a.EndpointID != "" {
+			targetOf[a.EndpointID] = a.TargetID
+		}
+	}
+	if len(targetOf) == 0 {
+		return
+	}
+	for _, r := range results {
+		if targetID, ok := targetOf[r.TargetID]; ok {
+			r.EndpointID = r.TargetID
+			r.TargetID = targetID
+		}
+	}
+}
+
+// batchTargets splits targets into batches the executor accepts.
+func batchTargets(caps executor.Capabilities, targets []executor.ProbeTarget) [][]executor.ProbeTarget {
+	batchSize := caps.MaxBatchSize
+	if batchSize <= 0 || !caps.SupportsBatching {
+		batchSize = 1
+	}
+
+	var batches [][]executor.ProbeTarget
+	for i := 0; i < len(targets); i += batchSize {
+		end := i + batchSize
+		if end > len(targets) {
+			end = len(targets)
+		}
+		batches = append(batches, targets[i:end])
+	}
+	return batches
+}
+
 // Stats returns current scheduler statistics.
 type Stats struct {
 	TierCounts     map[string]int `json:"tier_counts"`
 	TotalTargets   int            `json:"total_targets"`
 	ActiveTiers    int            `json:"active_tiers"`
+
+	// ProbesQueued is the number of targets waiting for a pool worker
+	ProbesQueued int                  `json:"probes_queued"`
+	Pools        map[string]PoolStats `json:"pools"`
+
+	// ProbesShedByTier is the number of targets shed per tier since start
+	ProbesShedByTier map[string]int64 `json:"probes_shed_by_tier"`
+
+	// EffectiveIntervals counts targets per effective probe interval, per
+	// adaptive tier. Nil when adaptive probing is off.
+	EffectiveIntervals map[string]map[string]int `json:"effective_intervals,omitempty"`
+
+	// BackedOffByTier counts down targets per tier probed at a backed-off
+	// interval
+	BackedOffByTier map[string]int `json:"backed_off_by_tier"`
+
+	// ScheduleAlignment is each tier's loop alignment
+	ScheduleAlignment map[string]string `json:"schedule_alignment"`
 }
 
 func (s *Scheduler) Stats() Stats {
@@ -294,9 +521,39 @@ func (s *Scheduler) Stats() Stats {
 	for _, c := range counts {
 		total += c
 	}
-	return Stats{
+	stats := Stats{
 		TierCounts:   counts,
 		TotalTargets: total,
 		ActiveTiers:  len(counts),
+		Pools:        make(map[string]PoolStats),
+
+		ProbesShedByTier: make(map[string]int64),
+		BackedOffByTier:  s.backoff.backedOff(),
+	}
+	if s.adaptive != nil {
+		stats.EffectiveIntervals = s.adaptive.intervals()
+	}
+
+	s.tierMu.RLock()
+	tiers := make([]string, 0, len(s.tiers))
+	for name := range s.tiers {
+		tiers = append(tiers, name)
+	}
+	s.tierMu.RUnlock()
+	stats.ScheduleAlignment = make(map[string]string, len(tiers))
+	for _, name := range tiers {
+		stats.ScheduleAlignment[name] = string(s.alignmentFor(name))
+	}
+
+	s.poolMu.RLock()
+	defer s.poolMu.RUnlock()
+	for typ, pool := range s.pools {
+		ps := pool.Stats()
+		stats.Pools[typ] = ps
+		stats.ProbesQueued += ps.QueuedTargets
+		for tier, n := range ps.ShedByTier {
+			stats.ProbesShedByTier[tier] += n
+		}
 	}
+	return stats
 }
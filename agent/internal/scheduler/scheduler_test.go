@@ -0,0 +1,94 @@
+package scheduler
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestGroupByProbeType_MixedTier(t *testing.T) {
+	assignments := []types.Assignment{
+		{TargetID: "a"},
+		{TargetID: "b", ProbeType: "tls_cert"},
+		{TargetID: "c", ProbeType: "icmp_ping"},
+		{TargetID: "d", ProbeType: "tls_cert"},
+	}
+
+	groups := groupByProbeType(assignments)
+
+	got := make(map[string][]string)
+	var order []string
+	for _, g := range groups {
+		order = append(order, g.probeType)
+		for _, a := range g.assignments {
+			got[g.probeType] = append(got[g.probeType], a.TargetID)
+		}
+	}
+	if want := []string{"icmp_ping", "tls_cert"}; !reflect.DeepEqual(order, want) {
+		t.Errorf("group order = %v, want %v", order, want)
+	}
+	want := map[string][]string{"icmp_ping": {"a", "c"}, "tls_cert": {"b", "d"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("groups = %v, want %v", got, want)
+	}
+}
+
+func TestBatchTargets_Sizes(t *testing.T) {
+	targets := make([]executor.ProbeTarget, 5)
+
+	tests := []struct {
+		name string
+		caps executor.Capabilities
+		want []int
+	}{
+		{name: "batching", caps: executor.Capabilities{SupportsBatching: true, MaxBatchSize: 2}, want: []int{2, 2, 1}},
+		{name: "no batching", caps: executor.Capabilities{MaxBatchSize: 2}, want: []int{1, 1, 1, 1, 1}},
+		{name: "unbounded batch size", caps: executor.Capabilities{SupportsBatching: true}, want: []int{1, 1, 1, 1, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var sizes []int
+			for _, b := range batchTargets(tt.caps, targets) {
+				sizes = append(sizes, len(b))
+			}
+			if !reflect.DeepEqual(sizes, tt.want) {
+				t.Errorf("batch sizes = %v, want %v", sizes, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestoreEndpointResults_DualStack(t *testing.T) {
+	assignments := []types.Assignment{
+		{TargetID: "t1", IP: "192.0.2.1"},
+		{TargetID: "t1", IP: "2001:db8::1", EndpointID: "e1"},
+	}
+
+	ids := make([]string, 0, len(assignments))
+	for _, pt := range probeTargets(assignments, types.Tier{}) {
+		ids = append(ids, pt.ID)
+	}
+	if want := []string{"t1", "e1"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("probe IDs = %v, want %v", ids, want)
+	}
+
+	results := []*executor.Result{{TargetID: "t1"}, {TargetID: "e1"}, {TargetID: "other"}}
+	restoreEndpointResults(assignments, results)
+
+	tests := []struct {
+		target, endpoint string
+	}{
+		{target: "t1", endpoint: ""},
+		{target: "t1", endpoint: "e1"},
+		{target: "other", endpoint: ""},
+	}
+	for i, tt := range tests {
+		if results[i].TargetID != tt.target || results[i].EndpointID != tt.endpoint {
+			t.Errorf("result %d = (%q, %q), want (%q, %q)", i,
+				results[i].TargetID, results[i].EndpointID, tt.target, tt.endpoint)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package shipper
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// defaultPrimaryRetryInterval is how long the shipper stays failed over
+// before trying the primary endpoint again.
+const defaultPrimaryRetryInterval = 5 * time.Minute
+
+// endpointFailover orders result endpoints for each send: the primary
+// first, then the failovers in configured order. After a send lands on a
+// failover endpoint it stays active, so batches don't pay a timeout against
+// a dead primary every time, until the primary is due a retry; the first
+// send to succeed against the primary returns shipping to it.
+//
+// It is only used under the shipper's flushMu.
+type endpointFailover struct {
+	endpoints    []string
+	active       int
+	retryPrimary time.Duration
+	primaryTried time.Time // last time the primary was tried while failed over
+	failovers    int64     // switches to a failover endpoint
+}
+
+func newEndpointFailover(endpoints []string, retryPrimary time.Duration) *endpointFailover {
+	if retryPrimary <= 0 {
+		retryPrimary = defaultPrimaryRetryInterval
+	}
+	return &endpointFailover{endpoints: endpoints, retryPrimary: retryPrimary}
+}
+
+// order returns the endpoint indexes to try for a send: the primary if it
+// is due a retry, then the active endpoint and the ones after it, wrapping
+// round to those before it.
+func (f *endpointFailover) order(now time.Time) []int {
+	n := len(f.endpoints)
+	order := make([]int, 0, n)
+	if f.active != 0 && now.Sub(f.primaryTried) >= f.retryPrimary {
+		order = append(order, 0)
+	}
+	for i := 0; i < n; i++ {
+		idx := (f.active + i) % n
+		if idx == 0 && len(order) > 0 && order[0] == 0 {
+			continue
+		}
+		order = append(order, idx)
+	}
+	return order
+}
+
+// record notes the outcome of sending to endpoint idx, switching the active
+// endpoint to the first one that accepts a batch.
+func (f *endpointFailover) record(idx int, ok bool, now time.Time) {
+	if idx == 0 && f.active != 0 {
+		f.primaryTried = now
+	}
+	if !ok || idx == f.active {
+		return
+	}
+	if idx != 0 {
+		f.failovers++
+		// Give the failover a full retry interval before trying the primary
+		f.primaryTried = now
+	}
+	f.active = idx
+}
+
+// current returns the active endpoint.
+func (f *endpointFailover) current() string {
+	return f.endpoints[f.active]
+}
+
+// statusError is a response the control plane rejected.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status %d: %s", e.code, e.body)
+}
+
+// shouldFailOver reports whether a send error means the endpoint itself is
+// unhealthy. Transport errors and 5xx responses are; other rejections would
+// be the same from any control plane instance.
+func shouldFailOver(err error) bool {
+	var se *statusError
+	if errors.As(err, &se) {
+		return se.code >= http.StatusInternalServerError
+	}
+	return true
+}
@@ -0,0 +1,136 @@
+package shipper
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEndpointFailover_Order(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	endpoints := []string{"primary", "second", "third"}
+
+	tests := []struct {
+		name         string
+		active       int
+		primaryTried time.Time
+		want         []int
+	}{
+		{"on primary", 0, time.Time{}, []int{0, 1, 2}},
+		{"failed over, primary not due", 1, now.Add(-time.Minute), []int{1, 2, 0}},
+		{"failed over, primary due", 2, now.Add(-10 * time.Minute), []int{0, 2, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newEndpointFailover(endpoints, 5*time.Minute)
+			f.active, f.primaryTried = tt.active, tt.primaryTried
+			if got := f.order(now); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("order = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldFailOver_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"transport error", io.ErrUnexpectedEOF, true},
+		{"server error", &statusError{code: http.StatusBadGateway}, true},
+		{"rejected", &statusError{code: http.StatusBadRequest}, false},
+		{"unauthorized", &statusError{code: http.StatusUnauthorized}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := shouldFailOver(tt.err); got != tt.want {
+				t.Errorf("shouldFailOver = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShipper_FailoverAndReturnToPrimary(t *testing.T) {
+	primary := &recordingServer{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+	secondary := &recordingServer{}
+	primarySrv := httptest.NewServer(primary)
+	t.Cleanup(primarySrv.Close)
+	secondarySrv := httptest.NewServer(secondary)
+	t.Cleanup(secondarySrv.Close)
+
+	const retryPrimary = time.Hour
+	s := NewShipper(Config{
+		Endpoint:             primarySrv.URL,
+		FailoverEndpoints:    []string{secondarySrv.URL},
+		PrimaryRetryInterval: retryPrimary,
+		AgentID:              "agent-1",
+		Logger:               slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+
+	// Primary down: the batch lands on the secondary in the same attempt
+	s.Add(result("t1"))
+	s.Flush(context.Background())
+	if len(primary.batches) != 1 || len(secondary.batches) != 1 {
+		t.Fatalf("sends = %d primary, %d secondary; want 1, 1", len(primary.batches), len(secondary.batches))
+	}
+	stats := s.Stats()
+	if stats.ActiveEndpoint != secondarySrv.URL || stats.EndpointFailovers != 1 || stats.ShipFailures != 0 {
+		t.Errorf("stats = %+v, want active secondary, 1 failover, no ship failures", stats)
+	}
+
+	// Stays on the secondary until the primary is due a retry
+	s.Add(result("t2"))
+	s.Flush(context.Background())
+	if len(primary.batches) != 1 || len(secondary.batches) != 2 {
+		t.Fatalf("sends = %d primary, %d secondary; want 1, 2", len(primary.batches), len(secondary.batches))
+	}
+
+	// Primary retried while still down, then back
+	s.failover.primaryTried = time.Now().Add(-retryPrimary)
+	s.Add(result("t3"))
+	s.Flush(context.Background())
+	if len(primary.batches) != 2 || len(secondary.batches) != 3 {
+		t.Fatalf("sends = %d primary, %d secondary; want 2, 3", len(primary.batches), len(secondary.batches))
+	}
+
+	s.failover.primaryTried = time.Now().Add(-retryPrimary)
+	s.Add(result("t4"))
+	s.Flush(context.Background())
+	if len(primary.batches) != 3 || len(secondary.batches) != 3 {
+		t.Fatalf("sends = %d primary, %d secondary; want 3, 3", len(primary.batches), len(secondary.batches))
+	}
+	if got := s.Stats().ActiveEndpoint; got != primarySrv.URL {
+		t.Errorf("active endpoint = %s, want primary %s", got, primarySrv.URL)
+	}
+}
+
+func TestShipper_RejectionDoesNotFailOver(t *testing.T) {
+	primary := &recordingServer{statuses: []int{http.StatusBadRequest}}
+	secondary := &recordingServer{}
+	primarySrv := httptest.NewServer(primary)
+	t.Cleanup(primarySrv.Close)
+	secondarySrv := httptest.NewServer(secondary)
+	t.Cleanup(secondarySrv.Close)
+
+	s := NewShipper(Config{
+		Endpoint:          primarySrv.URL,
+		FailoverEndpoints: []string{secondarySrv.URL},
+		AgentID:           "agent-1",
+		Logger:            slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+	s.Add(result("t1"))
+	s.Flush(context.Background())
+
+	if len(secondary.batches) != 0 {
+		t.Errorf("secondary got %d sends, want 0", len(secondary.batches))
+	}
+	if stats := s.Stats(); stats.ActiveEndpoint != primarySrv.URL || stats.ShipFailures != 1 {
+		t.Errorf("stats = %+v, want active primary and 1 ship failure", stats)
+	}
+}
@@ -12,6 +12,47 @@
 // - Results are retained on temporary failures (with limit)
 // - Exponential backoff on repeated failures
 // - Graceful degradation when control plane is unavailable
+//
+// # Encoding
+//
+// Batches are JSON unless Config.Protobuf is set, in which case they are
+// encoded with resultpb and sent as application/x-protobuf. Either way the
+// body is gzipped.
+//
+// # Sequencing
+//
+// Every batch carries a sequence number one higher than the last. A batch
+// that fails to ship is retried as-is (same batch ID and sequence) before
+// anything newer is sent, up to maxShipAttempts. If the first attempt was in
+// fact stored and only its response was lost, the control plane recognises
+// the repeated sequence and acknowledges it without inserting it again.
+//
+// The counter is seeded from the wall clock at startup, so sequences keep
+// increasing across agent restarts without persisting any state.
+//
+// # Endpoint Failover
+//
+// Results go to the primary endpoint unless failover endpoints are
+// configured. A send that fails with a transport error or a 5xx response
+// moves on to the next endpoint in order within the same attempt, and
+// shipping stays on whichever endpoint accepted the batch. The primary is
+// retried once per PrimaryRetryInterval and takes over again as soon as it
+// accepts a batch.
+//
+// # Metrics
+//
+// Stats reports running totals since start: results shipped and dropped,
+// batches shipped, failed send attempts, and the bytes of shipped batches
+// both before and after gzip. Only successful sends count towards bytes, so
+// a retried batch is counted once. It also reports the active endpoint, how
+// many times shipping has failed over, and the age of the oldest result not
+// yet shipped: during backpressure results wait here, and without the age a
+// backed-up agent's data looks as fresh as anyone's.
+//
+// LastShip reports the most recent send: when, where, the batch's sequence
+// and size, and either the error or the control plane's reply (accepted,
+// rejected with reasons, deduplicated, and the server's queue depth).
+// Rejections are logged as warnings, since they are results lost for good.
 package shipper
 
 import (
@@ -27,33 +68,53 @@ import (
 	"time"
 
 	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/resultpb"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
 
 // Shipper batches and ships results to the control plane.
 type Shipper struct {
 	client   *http.Client
-	endpoint string
+	failover *endpointFailover
 	agentID  string
 	logger   *slog.Logger
 
 	// Batching config
 	batchSize    int
 	batchTimeout time.Duration
+	protobuf     bool
 
 	// Buffer
 	buffer   []*executor.Result
 	bufferMu sync.Mutex
 
 	// Metrics
-	shipped int64
-	failed  int64
-	metricsMu sync.Mutex
+	shipped           int64
+	failed            int64
+	batchesShipped    int64
+	shipFailures      int64 // failed send attempts, including retried ones
+	bytesShipped      int64 // gzip-compressed request bodies
+	bytesUncompressed int64 // encoded batches before compression
+	retrying          int   // results in a batch awaiting retry
+	retryingOldest    time.Time
+	activeEndpoint    string
+	endpointFailovers int64
+	lastShip          *ShipResult
+	metricsMu         sync.Mutex
+
+	// Sequencing; flushMu keeps batches shipping one at a time, in order
+	flushMu         sync.Mutex
+	sequence        int64
+	pending         *types.ResultBatch
+	pendingAttempts int
 
 	// Control
 	flushCh chan struct{}
 }
 
+// maxShipAttempts is how many times a batch is sent before it is dropped.
+const maxShipAttempts = 3
+
 // Config for the shipper.
 type Config struct {
 	Endpoint     string        // URL to POST results
@@ -62,6 +123,15 @@ type Config struct {
 	BatchTimeout time.Duration // Max time before sending batch
 	Client       *http.Client  // HTTP client (optional)
 	Logger       *slog.Logger  // Logger (optional)
+
+	// FailoverEndpoints are tried in order when Endpoint is unreachable.
+	// PrimaryRetryInterval is how often Endpoint is retried while failed
+	// over (default 5m).
+	FailoverEndpoints    []string
+	PrimaryRetryInterval time.Duration
+
+	// Protobuf ships batches as application/x-protobuf instead of JSON.
+	Protobuf bool
 }
 
 // NewShipper creates a new result shipper.
@@ -79,15 +149,19 @@ func NewShipper(cfg Config) *Shipper {
 		cfg.BatchTimeout = 5 * time.Second
 	}
 
+	endpoints := append([]string{cfg.Endpoint}, cfg.FailoverEndpoints...)
 	return &Shipper{
-		client:       cfg.Client,
-		endpoint:     cfg.Endpoint,
-		agentID:      cfg.AgentID,
-		logger:       cfg.Logger,
-		batchSize:    cfg.BatchSize,
-		batchTimeout: cfg.BatchTimeout,
-		buffer:       make([]*executor.Result, 0, cfg.BatchSize),
-		flushCh:      make(chan struct{}, 1),
+		client:         cfg.Client,
+		failover:       newEndpointFailover(endpoints, cfg.PrimaryRetryInterval),
+		agentID:        cfg.AgentID,
+		logger:         cfg.Logger,
+		batchSize:      cfg.BatchSize,
+		batchTimeout:   cfg.BatchTimeout,
+		protobuf:       cfg.Protobuf,
+		buffer:         make([]*executor.Result, 0, cfg.BatchSize),
+		activeEndpoint: cfg.Endpoint,
+		sequence:       time.Now().UnixNano(),
+		flushCh:        make(chan struct{}, 1),
 	}
 }
 
@@ -126,8 +200,18 @@ func (s *Shipper) Run(ctx context.Context) error {
 	}
 }
 
-// flush sends buffered results to the control plane.
+// flush sends buffered results to the control plane, retrying a previously
+// failed batch first.
 func (s *Shipper) flush(ctx context.Context) {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	if s.pending != nil && !s.shipPending(ctx) {
+		// Keep newer results buffered so batches arrive in sequence order
+		return
+	}
+	s.setRetrying()
+
 	s.bufferMu.Lock()
 	if len(s.buffer) == 0 {
 		s.bufferMu.Unlock()
@@ -139,100 +223,311 @@ func (s *Shipper) flush(ctx context.Context) {
 	s.buffer = make([]*executor.Result, 0, s.batchSize)
 	s.bufferMu.Unlock()
 
-	// Ship results
-	err := s.ship(ctx, results)
+	s.sequence++
+	s.pending = &types.ResultBatch{
+		AgentID:   s.agentID,
+		BatchID:   fmt.Sprintf("%s-%d", s.agentID, s.sequence),
+		Results:   convertResults(results),
+		CreatedAt: time.Now(),
+		Sequence:  s.sequence,
+	}
+	s.pendingAttempts = 0
+	s.shipPending(ctx)
+	s.setRetrying()
+}
+
+// shipPending sends the pending batch, clearing it on success or once it has
+// used up its attempts. Returns true if nothing is left pending.
+func (s *Shipper) shipPending(ctx context.Context) bool {
+	batch := s.pending
+	s.pendingAttempts++
+
+	size, resp, err := s.ship(ctx, batch)
 	if err != nil {
-		s.logger.Error("failed to ship results",
-			"count", len(results),
+		s.metricsMu.Lock()
+		s.shipFailures++
+		s.metricsMu.Unlock()
+
+		if s.pendingAttempts < maxShipAttempts {
+			s.logger.Warn("failed to ship results, will retry",
+				"count", len(batch.Results),
+				"sequence", batch.Sequence,
+				"attempt", s.pendingAttempts,
+				"error", err)
+			return false
+		}
+
+		s.logger.Error("failed to ship results, dropping batch",
+			"count", len(batch.Results),
+			"sequence", batch.Sequence,
+			"attempts", s.pendingAttempts,
 			"error", err)
 
 		s.metricsMu.Lock()
-		s.failed += int64(len(results))
+		s.failed += int64(len(batch.Results))
 		s.metricsMu.Unlock()
 
-		// TODO: Could re-add to buffer with retry logic
-		// For now, we log and drop (control plane will detect gaps)
-		return
+		// Control plane will detect gaps
+		s.pending = nil
+		return true
 	}
 
 	s.metricsMu.Lock()
-	s.shipped += int64(len(results))
+	s.shipped += int64(len(batch.Results))
+	s.batchesShipped++
+	s.bytesShipped += int64(size.compressed)
+	s.bytesUncompressed += int64(size.uncompressed)
 	s.metricsMu.Unlock()
 
-	s.logger.Debug("shipped results", "count", len(results))
+	s.logger.Debug("shipped results",
+		"count", len(batch.Results),
+		"sequence", batch.Sequence,
+		"bytes", size.compressed,
+		"uncompressed_bytes", size.uncompressed)
+	s.logResponse(batch, resp)
+	s.pending = nil
+	return true
 }
 
-// ship sends a batch of results to the control plane.
-func (s *Shipper) ship(ctx context.Context, results []*executor.Result) error {
-	// Build batch payload
-	batch := types.ResultBatch{
-		AgentID:   s.agentID,
-		BatchID:   fmt.Sprintf("%s-%d", s.agentID, time.Now().UnixNano()),
-		Results:   convertResults(results),
-		CreatedAt: time.Now(),
+// logResponse logs the parts of the control plane's reply worth an
+// operator's attention.
+func (s *Shipper) logResponse(batch *types.ResultBatch, resp *types.IngestResponse) {
+	if resp == nil {
+		return
+	}
+	if resp.Rejected > 0 {
+		s.logger.Warn("control plane rejected results",
+			"sequence", batch.Sequence,
+			"accepted", resp.Accepted,
+			"rejected", resp.Rejected,
+			"reasons", resp.Reasons)
+	}
+	if resp.Duplicate {
+		s.logger.Info("control plane had already accepted batch",
+			"sequence", batch.Sequence,
+			"deduplicated", resp.Deduplicated)
 	}
+}
 
-	// Marshal to JSON
-	data, err := json.Marshal(batch)
+// setRetrying publishes the pending batch size for Stats, which must not
+// wait on flushMu while a send is in flight.
+func (s *Shipper) setRetrying() {
+	retrying := 0
+	var oldest time.Time
+	if s.pending != nil {
+		retrying = len(s.pending.Results)
+		for _, r := range s.pending.Results {
+			if oldest.IsZero() || r.Timestamp.Before(oldest) {
+				oldest = r.Timestamp
+			}
+		}
+	}
+	s.metricsMu.Lock()
+	s.retrying = retrying
+	s.retryingOldest = oldest
+	s.metricsMu.Unlock()
+}
+
+// payloadSize is the size of a batch's request body.
+type payloadSize struct {
+	compressed   int
+	uncompressed int
+}
+
+// ship sends a batch of results to the control plane, returning the size
+// of the request body and the control plane's reply, nil if it sent none
+// that could be read. An unhealthy endpoint fails over to the next one.
+func (s *Shipper) ship(ctx context.Context, batch *types.ResultBatch) (payloadSize, *types.IngestResponse, error) {
+	data, contentType, err := s.encode(batch)
 	if err != nil {
-		return fmt.Errorf("marshaling batch: %w", err)
+		return payloadSize{}, nil, err
 	}
 
 	// Compress with gzip
 	var buf bytes.Buffer
 	gz := gzip.NewWriter(&buf)
 	if _, err := gz.Write(data); err != nil {
-		return fmt.Errorf("compressing batch: %w", err)
+		return payloadSize{}, nil, fmt.Errorf("compressing batch: %w", err)
 	}
 	if err := gz.Close(); err != nil {
-		return fmt.Errorf("closing gzip: %w", err)
+		return payloadSize{}, nil, fmt.Errorf("closing gzip: %w", err)
 	}
+	size := payloadSize{compressed: buf.Len(), uncompressed: len(data)}
+
+	for _, idx := range s.failover.order(time.Now()) {
+		endpoint := s.failover.endpoints[idx]
+		var resp *types.IngestResponse
+		resp, err = s.post(ctx, endpoint, contentType, buf.Bytes())
+		s.failover.record(idx, err == nil, time.Now())
+		s.publishEndpoint()
+		s.recordShip(batch, endpoint, resp, err)
+		if err == nil {
+			return size, resp, nil
+		}
+		if !shouldFailOver(err) || ctx.Err() != nil {
+			break
+		}
+		s.logger.Warn("result endpoint unavailable",
+			"endpoint", endpoint,
+			"sequence", batch.Sequence,
+			"error", err)
+	}
+	return size, nil, err
+}
 
-	// Create request
-	req, err := http.NewRequestWithContext(ctx, "POST", s.endpoint, &buf)
+// encode marshals a batch in the configured encoding, returning it with
+// its content type.
+func (s *Shipper) encode(batch *types.ResultBatch) ([]byte, string, error) {
+	if s.protobuf {
+		data, err := resultpb.Marshal(batch)
+		if err != nil {
+			return nil, "", err
+		}
+		return data, resultpb.ContentType, nil
+	}
+	data, err := json.Marshal(batch)
 	if err != nil {
-		return fmt.Errorf("creating request: %w", err)
+		return nil, "", fmt.Errorf("marshaling batch: %w", err)
 	}
-	req.Header.Set("Content-Type", "application/json")
+	return data, "application/json", nil
+}
+
+// post sends a compressed batch body to one endpoint, returning its reply.
+// A reply that can't be decoded still counts as delivered: the status code
+// is what acknowledges the batch.
+func (s *Shipper) post(ctx context.Context, endpoint, contentType string, body []byte) (*types.IngestResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
 	req.Header.Set("Content-Encoding", "gzip")
 
 	// Send request
 	resp, err := s.client.Do(req)
 	if err != nil {
-		return fmt.Errorf("sending request: %w", err)
+		return nil, fmt.Errorf("sending request: %w", err)
 	}
 	defer resp.Body.Close()
 
 	// Check response
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
-		return fmt.Errorf("unexpected status %d: %s", resp.StatusCode, string(body))
+		return nil, &statusError{code: resp.StatusCode, body: string(body)}
+	}
+
+	var reply types.IngestResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		s.logger.Debug("unreadable result ingest reply", "endpoint", endpoint, "error", err)
+		return nil, nil
+	}
+	return &reply, nil
+}
+
+// ShipResult is the outcome of one attempt to send a batch.
+type ShipResult struct {
+	At       time.Time             `json:"at"`
+	Endpoint string                `json:"endpoint"`
+	Sequence int64                 `json:"sequence"`
+	Results  int                   `json:"results"`
+	Error    string                `json:"error,omitempty"`
+	Response *types.IngestResponse `json:"response,omitempty"`
+}
+
+// recordShip keeps the outcome of a send attempt for LastShip.
+func (s *Shipper) recordShip(batch *types.ResultBatch, endpoint string, resp *types.IngestResponse, err error) {
+	result := &ShipResult{
+		At:       time.Now(),
+		Endpoint: endpoint,
+		Sequence: batch.Sequence,
+		Results:  len(batch.Results),
+		Response: resp,
+	}
+	if err != nil {
+		result.Error = err.Error()
 	}
+	s.metricsMu.Lock()
+	s.lastShip = result
+	s.metricsMu.Unlock()
+}
 
-	return nil
+// LastShip returns the outcome of the most recent send attempt, or nil if
+// nothing has been sent yet.
+func (s *Shipper) LastShip() *ShipResult {
+	s.metricsMu.Lock()
+	defer s.metricsMu.Unlock()
+	if s.lastShip == nil {
+		return nil
+	}
+	last := *s.lastShip
+	return &last
+}
+
+// publishEndpoint copies the failover state for Stats, logging a change of
+// active endpoint.
+func (s *Shipper) publishEndpoint() {
+	active := s.failover.current()
+	s.metricsMu.Lock()
+	previous := s.activeEndpoint
+	s.activeEndpoint = active
+	s.endpointFailovers = s.failover.failovers
+	s.metricsMu.Unlock()
+
+	if active != previous {
+		s.logger.Warn("result shipping switched endpoint", "from", previous, "to", active)
+	}
 }
 
 // Stats returns shipper statistics.
 type Stats struct {
-	Queued  int   `json:"queued"`
-	Shipped int64 `json:"shipped"`
-	Failed  int64 `json:"failed"`
+	Queued            int    `json:"queued"`
+	Shipped           int64  `json:"shipped"`
+	Failed            int64  `json:"failed"`
+	BatchesShipped    int64  `json:"batches_shipped"`
+	ShipFailures      int64  `json:"ship_failures"`
+	BytesShipped      int64  `json:"bytes_shipped"`
+	BytesUncompressed int64  `json:"bytes_uncompressed"`
+	ActiveEndpoint    string `json:"active_endpoint"`
+	EndpointFailovers int64  `json:"endpoint_failovers"`
+
+	// OldestQueuedAgeMs is how long ago the oldest unshipped result was
+	// probed, 0 when nothing is queued.
+	OldestQueuedAgeMs int64 `json:"oldest_queued_age_ms"`
 }
 
 func (s *Shipper) Stats() Stats {
 	s.bufferMu.Lock()
 	queued := len(s.buffer)
+	var oldest time.Time
+	for _, r := range s.buffer {
+		if oldest.IsZero() || r.Timestamp.Before(oldest) {
+			oldest = r.Timestamp
+		}
+	}
 	s.bufferMu.Unlock()
 
 	s.metricsMu.Lock()
-	shipped := s.shipped
-	failed := s.failed
-	s.metricsMu.Unlock()
+	defer s.metricsMu.Unlock()
+
+	if !s.retryingOldest.IsZero() && (oldest.IsZero() || s.retryingOldest.Before(oldest)) {
+		oldest = s.retryingOldest
+	}
+	var oldestAgeMs int64
+	if !oldest.IsZero() {
+		oldestAgeMs = max(time.Since(oldest).Milliseconds(), 0)
+	}
 
 	return Stats{
-		Queued:  queued,
-		Shipped: shipped,
-		Failed:  failed,
+		Queued:            queued + s.retrying,
+		Shipped:           s.shipped,
+		Failed:            s.failed,
+		BatchesShipped:    s.batchesShipped,
+		ShipFailures:      s.shipFailures,
+		BytesShipped:      s.bytesShipped,
+		BytesUncompressed: s.bytesUncompressed,
+		ActiveEndpoint:    s.activeEndpoint,
+		EndpointFailovers: s.endpointFailovers,
+		OldestQueuedAgeMs: oldestAgeMs,
 	}
 }
 
@@ -245,14 +540,19 @@ func (s *Shipper) Flush(ctx context.Context) {
 func convertResults(results []*executor.Result) []types.ProbeResult {
 	out := make([]types.ProbeResult, len(results))
 	for i, r := range results {
+		probeType := r.ProbeType
+		if probeType == "" {
+			probeType = types.DefaultProbeType
+		}
 		out[i] = types.ProbeResult{
-			TargetID:  r.TargetID,
-			Timestamp: r.Timestamp,
-			Duration:  r.Duration,
-			Success:   r.Success,
-			Error:     r.Error,
-			ProbeType: "icmp_ping", // TODO: pass through from executor
-			Payload:   r.Payload,
+			TargetID:   r.TargetID,
+			EndpointID: r.EndpointID,
+			Timestamp:  r.Timestamp,
+			Duration:   r.Duration,
+			Success:    r.Success,
+			Error:      r.Error,
+			ProbeType:  probeType,
+			Payload:    r.Payload,
 		}
 	}
 	return out
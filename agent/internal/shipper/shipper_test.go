@@ -0,0 +1,427 @@
+package shipper
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/resultpb"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// recordingServer decodes every batch it receives, JSON or protobuf by
+// content type, and answers with the next status in statuses (202 once they
+// run out), and reply as the body.
+type recordingServer struct {
+	mu           sync.Mutex
+	statuses     []int
+	reply        string
+	batches      []types.ResultBatch
+	sizes        []payloadSize // request body size per send
+	contentTypes []string
+}
+
+func (rs *recordingServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(body))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var batch types.ResultBatch
+	contentType := r.Header.Get("Content-Type")
+	if contentType == resultpb.ContentType {
+		err = resultpb.Unmarshal(data, &batch)
+	} else {
+		err = json.Unmarshal(data, &batch)
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	rs.mu.Lock()
+	rs.batches = append(rs.batches, batch)
+	rs.contentTypes = append(rs.contentTypes, contentType)
+	rs.sizes = append(rs.sizes, payloadSize{compressed: len(body), uncompressed: len(data)})
+	status := http.StatusAccepted
+	if len(rs.statuses) > 0 {
+		status, rs.statuses = rs.statuses[0], rs.statuses[1:]
+	}
+	rs.mu.Unlock()
+
+	w.WriteHeader(status)
+	io.WriteString(w, rs.reply)
+}
+
+func newTestShipper(t *testing.T, rs *recordingServer) *Shipper {
+	t.Helper()
+	srv := httptest.NewServer(rs)
+	t.Cleanup(srv.Close)
+	return NewShipper(Config{
+		Endpoint: srv.URL,
+		AgentID:  "agent-1",
+		Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+	})
+}
+
+func result(targetID string) []*executor.Result {
+	return []*executor.Result{{TargetID: targetID, Timestamp: time.Now(), Success: true}}
+}
+
+func TestShipper_SequenceIncreasesPerBatch(t *testing.T) {
+	rs := &recordingServer{}
+	s := newTestShipper(t, rs)
+
+	for _, id := range []string{"t1", "t2", "t3"} {
+		s.Add(result(id))
+		s.Flush(context.Background())
+	}
+
+	if len(rs.batches) != 3 {
+		t.Fatalf("got %d batches, want 3", len(rs.batches))
+	}
+	for i := 1; i < len(rs.batches); i++ {
+		if rs.batches[i].Sequence != rs.batches[i-1].Sequence+1 {
+			t.Errorf("batch %d sequence = %d, want %d", i, rs.batches[i].Sequence, rs.batches[i-1].Sequence+1)
+		}
+	}
+}
+
+func TestShipper_Encoding(t *testing.T) {
+	tests := []struct {
+		name            string
+		protobuf        bool
+		wantContentType string
+	}{
+		{name: "json by default", wantContentType: "application/json"},
+		{name: "protobuf", protobuf: true, wantContentType: resultpb.ContentType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &recordingServer{}
+			srv := httptest.NewServer(rs)
+			defer srv.Close()
+			s := NewShipper(Config{
+				Endpoint: srv.URL,
+				AgentID:  "agent-1",
+				Logger:   slog.New(slog.NewTextHandler(io.Discard, nil)),
+				Protobuf: tt.protobuf,
+			})
+
+			s.Add(result("t1"))
+			s.Flush(context.Background())
+
+			if len(rs.batches) != 1 {
+				t.Fatalf("got %d batches, want 1", len(rs.batches))
+			}
+			if rs.contentTypes[0] != tt.wantContentType {
+				t.Errorf("content type = %q, want %q", rs.contentTypes[0], tt.wantContentType)
+			}
+			got := rs.batches[0]
+			if got.AgentID != "agent-1" || len(got.Results) != 1 || got.Results[0].TargetID != "t1" {
+				t.Errorf("decoded batch = %+v", got)
+			}
+			if stats := s.Stats(); stats.BytesUncompressed != int64(rs.sizes[0].uncompressed) {
+				t.Errorf("BytesUncompressed = %d, want %d", stats.BytesUncompressed, rs.sizes[0].uncompressed)
+			}
+		})
+	}
+}
+
+func TestShipper_SequenceSurvivesRestart(t *testing.T) {
+	rs := &recordingServer{}
+
+	first := newTestShipper(t, rs)
+	first.Add(result("t1"))
+	first.Flush(context.Background())
+
+	second := newTestShipper(t, rs)
+	second.Add(result("t2"))
+	second.Flush(context.Background())
+
+	if len(rs.batches) != 2 {
+		t.Fatalf("got %d batches, want 2", len(rs.batches))
+	}
+	if rs.batches[1].Sequence <= rs.batches[0].Sequence {
+		t.Errorf("restarted shipper sequence %d not above previous %d", rs.batches[1].Sequence, rs.batches[0].Sequence)
+	}
+}
+
+func TestShipper_RetryReplaysSameBatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		statuses    []int
+		flushes     int
+		wantSends   int
+		wantShipped int64
+		wantFailed  int64
+	}{
+		{
+			name:        "succeeds on retry",
+			statuses:    []int{http.StatusServiceUnavailable},
+			flushes:     2,
+			wantSends:   2,
+			wantShipped: 1,
+		},
+		{
+			name:       "dropped after max attempts",
+			statuses:   []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			flushes:    maxShipAttempts,
+			wantSends:  maxShipAttempts,
+			wantFailed: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &recordingServer{statuses: tt.statuses}
+			s := newTestShipper(t, rs)
+
+			s.Add(result("t1"))
+			for i := 0; i < tt.flushes; i++ {
+				s.Flush(context.Background())
+			}
+
+			if len(rs.batches) != tt.wantSends {
+				t.Fatalf("got %d sends, want %d", len(rs.batches), tt.wantSends)
+			}
+			for _, b := range rs.batches[1:] {
+				if b.Sequence != rs.batches[0].Sequence || b.BatchID != rs.batches[0].BatchID {
+					t.Errorf("retry sent sequence %d (%s), want %d (%s)",
+						b.Sequence, b.BatchID, rs.batches[0].Sequence, rs.batches[0].BatchID)
+				}
+			}
+
+			stats := s.Stats()
+			if stats.Shipped != tt.wantShipped || stats.Failed != tt.wantFailed || stats.Queued != 0 {
+				t.Errorf("stats = %+v, want shipped %d failed %d queued 0", stats, tt.wantShipped, tt.wantFailed)
+			}
+		})
+	}
+}
+
+func TestShipper_NewerResultsWaitForRetry(t *testing.T) {
+	rs := &recordingServer{statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable}}
+	s := newTestShipper(t, rs)
+
+	s.Add(result("t1"))
+	s.Flush(context.Background()) // fails
+	s.Add(result("t2"))
+	s.Flush(context.Background()) // retry fails; t2 stays buffered
+
+	if got := s.Stats().Queued; got != 2 {
+		t.Errorf("queued = %d, want 2", got)
+	}
+
+	s.Flush(context.Background()) // retry succeeds, then t2 ships
+
+	if len(rs.batches) != 4 {
+		t.Fatalf("got %d sends, want 4", len(rs.batches))
+	}
+	last, retried := rs.batches[3], rs.batches[2]
+	if last.Results[0].TargetID != "t2" || last.Sequence != retried.Sequence+1 {
+		t.Errorf("final batch = %s seq %d, want t2 seq %d", last.Results[0].TargetID, last.Sequence, retried.Sequence+1)
+	}
+}
+
+func TestShipper_OldestQueuedAge(t *testing.T) {
+	aged := func(targetID string, age time.Duration) []*executor.Result {
+		return []*executor.Result{{TargetID: targetID, Timestamp: time.Now().Add(-age), Success: true}}
+	}
+
+	tests := []struct {
+		name     string
+		statuses []int
+		setup    func(s *Shipper)
+		wantMin  time.Duration
+		wantMax  time.Duration
+	}{
+		{
+			name:  "nothing queued",
+			setup: func(s *Shipper) {},
+		},
+		{
+			name: "buffered",
+			setup: func(s *Shipper) {
+				s.Add(aged("t1", time.Minute))
+				s.Add(aged("t2", 2*time.Minute))
+			},
+			wantMin: 2 * time.Minute,
+			wantMax: 3 * time.Minute,
+		},
+		{
+			name:     "pending retry older than buffer",
+			statuses: []int{http.StatusServiceUnavailable},
+			setup: func(s *Shipper) {
+				s.Add(aged("t1", 5*time.Minute))
+				s.Flush(context.Background()) // fails, t1 awaits retry
+				s.Add(aged("t2", time.Minute))
+			},
+			wantMin: 5 * time.Minute,
+			wantMax: 6 * time.Minute,
+		},
+		{
+			name: "shipped",
+			setup: func(s *Shipper) {
+				s.Add(aged("t1", time.Minute))
+				s.Flush(context.Background())
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestShipper(t, &recordingServer{statuses: tt.statuses})
+			tt.setup(s)
+
+			got := time.Duration(s.Stats().OldestQueuedAgeMs) * time.Millisecond
+			if got < tt.wantMin || got > tt.wantMax {
+				t.Errorf("oldest queued age = %v, want %v to %v", got, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestShipper_PayloadStats(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		flushes      int
+		wantBatches  int64
+		wantFailures int64
+	}{
+		{"first attempt", nil, 1, 1, 0},
+		{"succeeds on retry", []int{http.StatusServiceUnavailable}, 2, 1, 1},
+		{"dropped", []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway}, maxShipAttempts, 0, maxShipAttempts},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &recordingServer{statuses: tt.statuses}
+			s := newTestShipper(t, rs)
+
+			s.Add(append(result("t1"), result("t2")...))
+			for i := 0; i < tt.flushes; i++ {
+				s.Flush(context.Background())
+			}
+
+			stats := s.Stats()
+			if stats.BatchesShipped != tt.wantBatches || stats.ShipFailures != tt.wantFailures {
+				t.Errorf("batches = %d, failures = %d; want %d, %d",
+					stats.BatchesShipped, stats.ShipFailures, tt.wantBatches, tt.wantFailures)
+			}
+
+			// Bytes count the accepted send only, as the server received it
+			var want payloadSize
+			if tt.wantBatches > 0 {
+				want = rs.sizes[len(rs.sizes)-1]
+			}
+			if stats.BytesShipped != int64(want.compressed) || stats.BytesUncompressed != int64(want.uncompressed) {
+				t.Errorf("bytes = %d (uncompressed %d), want %d (%d)",
+					stats.BytesShipped, stats.BytesUncompressed, want.compressed, want.uncompressed)
+			}
+		})
+	}
+}
+
+func TestConvertResults_ProbeType(t *testing.T) {
+	tests := []struct {
+		name      string
+		probeType string
+		want      string
+	}{
+		{name: "executor type passed through", probeType: types.ProbeTypeTCPConnect, want: types.ProbeTypeTCPConnect},
+		{name: "unset defaults to icmp_ping", want: types.DefaultProbeType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := convertResults([]*executor.Result{{TargetID: "t-1", ProbeType: tt.probeType}})
+			if got := out[0].ProbeType; got != tt.want {
+				t.Errorf("ProbeType = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShipper_LastShip(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int
+		reply        string
+		wantError    bool
+		wantResponse *types.IngestResponse
+	}{
+		{
+			name:  "reply with rejections",
+			reply: `{"accepted":0,"rejected":1,"reasons":{"unknown_target":1},"queue_depth":42}`,
+			wantResponse: &types.IngestResponse{
+				Rejected:   1,
+				Reasons:    map[string]int{"unknown_target": 1},
+				QueueDepth: ptr(int64(42)),
+			},
+		},
+		{
+			name:         "duplicate batch",
+			reply:        `{"accepted":0,"rejected":0,"reasons":{},"duplicate":true,"deduplicated":1}`,
+			wantResponse: &types.IngestResponse{Reasons: map[string]int{}, Duplicate: true, Deduplicated: 1},
+		},
+		{
+			name: "no reply body",
+		},
+		{
+			name:      "send failed",
+			statuses:  []int{http.StatusInternalServerError},
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rs := &recordingServer{statuses: tt.statuses, reply: tt.reply}
+			s := newTestShipper(t, rs)
+			if s.LastShip() != nil {
+				t.Fatal("LastShip before any send is not nil")
+			}
+
+			s.Add(result("t1"))
+			s.Flush(context.Background())
+
+			last := s.LastShip()
+			if last == nil {
+				t.Fatal("LastShip after a send is nil")
+			}
+			if last.Sequence != rs.batches[0].Sequence || last.Results != 1 {
+				t.Errorf("LastShip sequence %d results %d, want %d and 1", last.Sequence, last.Results, rs.batches[0].Sequence)
+			}
+			if (last.Error != "") != tt.wantError {
+				t.Errorf("LastShip error = %q, want error %v", last.Error, tt.wantError)
+			}
+			if !reflect.DeepEqual(last.Response, tt.wantResponse) {
+				t.Errorf("LastShip response = %+v, want %+v", last.Response, tt.wantResponse)
+			}
+		})
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -0,0 +1,411 @@
+package simulate
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/client"
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/agent/internal/shipper"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Config describes a simulated fleet.
+type Config struct {
+	ControlPlaneURL    string
+	Token              string
+	InsecureSkipVerify bool
+
+	// Agents is the number of virtual agents. They are named
+	// NamePrefix-001 and up, and spread round-robin over Regions.
+	Agents     int
+	NamePrefix string
+	Regions    []string
+	Version    string // Reported on registration and heartbeat
+
+	// Interval, when set, replaces every assignment's probe interval, to
+	// drive a fixed result rate: agents × targets per agent / Interval.
+	Interval time.Duration
+
+	BatchSize         int
+	BatchTimeout      time.Duration
+	Protobuf          bool // ship batches as protobuf rather than JSON
+	HeartbeatInterval time.Duration
+	AssignmentRefresh time.Duration
+
+	Profile Profile
+	Seed    uint64
+
+	// StatsInterval is how often fleet-wide shipping totals are logged.
+	StatsInterval time.Duration
+
+	Logger *slog.Logger
+}
+
+// DefaultConfig returns a one-agent fleet with the agent's own batching
+// and heartbeat defaults.
+func DefaultConfig() Config {
+	return Config{
+		Agents:            1,
+		NamePrefix:        "sim",
+		Regions:           []string{"sim-east", "sim-west"},
+		Version:           "dev",
+		BatchSize:         1000,
+		BatchTimeout:      5 * time.Second,
+		HeartbeatInterval: 30 * time.Second,
+		AssignmentRefresh: 5 * time.Minute,
+		Profile:           DefaultProfile(),
+		Seed:              1,
+		StatsInterval:     30 * time.Second,
+		Logger:            slog.Default(),
+	}
+}
+
+// Validate reports a config the fleet can't run with.
+func (c Config) Validate() error {
+	if c.ControlPlaneURL == "" {
+		return fmt.Errorf("control plane URL is required")
+	}
+	if c.Agents < 1 {
+		return fmt.Errorf("agents must be at least 1, got %d", c.Agents)
+	}
+	if len(c.Regions) == 0 {
+		return fmt.Errorf("at least one region is required")
+	}
+	if c.Interval < 0 {
+		return fmt.Errorf("interval must not be negative, got %s", c.Interval)
+	}
+	if err := c.Profile.Validate(); err != nil {
+		return fmt.Errorf("profile: %w", err)
+	}
+	return nil
+}
+
+// agentPublicIPs is where virtual agents' reported public IPs come from,
+// so each agent looks distinct without colliding with real hosts.
+var agentPublicIPs = netip.MustParsePrefix("100.64.0.0/10")
+
+// Run registers the fleet and simulates probing until ctx is cancelled.
+// The first agent that fails to register stops the fleet.
+func Run(ctx context.Context, cfg Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if cfg.Logger == nil {
+		cfg.Logger = slog.Default()
+	}
+
+	model := NewModel(cfg.Profile, cfg.Seed)
+	agents := make([]*virtualAgent, cfg.Agents)
+	for i := range agents {
+		agents[i] = newVirtualAgent(&cfg, model, i)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	for _, a := range agents {
+		g.Go(func() error { return a.run(gctx) })
+	}
+	g.Go(func() error {
+		logFleetStats(gctx, cfg.Logger, cfg.StatsInterval, agents)
+		return nil
+	})
+
+	cfg.Logger.Info("simulated fleet started", "agents", cfg.Agents, "seed", cfg.Seed)
+	if err := g.Wait(); err != nil && ctx.Err() == nil {
+		return err
+	}
+	return nil
+}
+
+// logFleetStats periodically logs shipping totals across the fleet.
+func logFleetStats(ctx context.Context, logger *slog.Logger, interval time.Duration, agents []*virtualAgent) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var lastShipped int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		var total shipper.Stats
+		targets := 0
+		for _, a := range agents {
+			s := a.stats()
+			total.Queued += s.Queued
+			total.Shipped += s.Shipped
+			total.Failed += s.Failed
+			total.BatchesShipped += s.BatchesShipped
+			total.ShipFailures += s.ShipFailures
+			targets += a.targetCount()
+		}
+		logger.Info("simulated fleet",
+			"targets", targets,
+			"shipped", total.Shipped,
+			"results_per_second", float64(total.Shipped-lastShipped)/interval.Seconds(),
+			"queued", total.Queued,
+			"dropped", total.Failed,
+			"batches", total.BatchesShipped,
+			"ship_failures", total.ShipFailures)
+		lastShipped = total.Shipped
+	}
+}
+
+// simTarget is an assigned target (or one of its endpoints) and when it is
+// next probed.
+type simTarget struct {
+	targetID   string
+	endpointID string
+	interval   time.Duration
+	next       time.Time
+}
+
+// virtualAgent is one simulated agent: a control plane client, a shipper
+// and a probe loop that generates results instead of sending packets.
+type virtualAgent struct {
+	cfg    *Config
+	model  *Model
+	name   string
+	region string
+	ip     string
+	client *client.Client
+	logger *slog.Logger
+
+	shipper *shipper.Shipper
+
+	mu      sync.Mutex
+	rng     *rand.Rand
+	targets map[string]*simTarget
+	version int64
+}
+
+func newVirtualAgent(cfg *Config, model *Model, i int) *virtualAgent {
+	name := fmt.Sprintf("%s-%03d", cfg.NamePrefix, i+1)
+	return &virtualAgent{
+		cfg:    cfg,
+		model:  model,
+		name:   name,
+		region: cfg.Regions[i%len(cfg.Regions)],
+		ip:     nthAddr(agentPublicIPs, i+1).String(),
+		client: client.NewClient(client.Config{
+			BaseURL:            cfg.ControlPlaneURL,
+			AuthToken:          cfg.Token,
+			InsecureSkipVerify: cfg.InsecureSkipVerify,
+		}),
+		logger:  cfg.Logger.With("agent", name),
+		rng:     rand.New(rand.NewPCG(cfg.Seed, uint64(i))),
+		targets: make(map[string]*simTarget),
+	}
+}
+
+func (a *virtualAgent) run(ctx context.Context) error {
+	resp, err := a.client.Register(ctx, client.RegisterRequest{
+		Name:       a.name,
+		Region:     a.region,
+		Location:   "Simulated " + a.region,
+		Provider:   "simulated",
+		Tags:       map[string]string{"simulated": "true"},
+		PublicIP:   a.ip,
+		Version:    a.cfg.Version,
+		Executors:  []string{types.DefaultProbeType},
+		MaxTargets: 10000,
+	})
+	if err != nil {
+		return fmt.Errorf("registering %s: %w", a.name, err)
+	}
+
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	if a.cfg.InsecureSkipVerify {
+		httpClient.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}
+	}
+	a.mu.Lock()
+	a.shipper = shipper.NewShipper(shipper.Config{
+		Endpoint:     strings.TrimSuffix(a.cfg.ControlPlaneURL, "/") + "/api/v1/results",
+		AgentID:      resp.AgentID,
+		BatchSize:    a.cfg.BatchSize,
+		BatchTimeout: a.cfg.BatchTimeout,
+		Protobuf:     a.cfg.Protobuf,
+		Client:       httpClient,
+		Logger:       a.logger,
+	})
+	a.mu.Unlock()
+
+	if err := a.syncAssignments(ctx); err != nil {
+		a.logger.Warn("failed to fetch assignments", "error", err)
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.Go(func() error { return a.shipper.Run(gctx) })
+	g.Go(func() error { return a.runHeartbeat(gctx) })
+	g.Go(func() error { return a.runAssignmentSync(gctx) })
+	g.Go(func() error { return a.runProbes(gctx) })
+	return g.Wait()
+}
+
+// syncAssignments replaces the target set with the control plane's. Known
+// targets keep their schedule; new ones start at a random point in their
+// interval so probes spread out instead of arriving in bursts.
+func (a *virtualAgent) syncAssignments(ctx context.Context) error {
+	set, err := a.client.GetAssignments(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	targets := make(map[string]*simTarget, len(set.Assignments))
+	for _, asg := range set.Assignments {
+		interval := asg.ProbeInterval
+		if a.cfg.Interval > 0 {
+			interval = a.cfg.Interval
+		}
+		if interval <= 0 {
+			continue
+		}
+		t, ok := a.targets[asg.ProbeID()]
+		if !ok {
+			t = &simTarget{
+				targetID:   asg.TargetID,
+				endpointID: asg.EndpointID,
+				next:       now.Add(time.Duration(a.rng.Int64N(int64(interval)))),
+			}
+		}
+		t.interval = interval
+		targets[asg.ProbeID()] = t
+	}
+	a.targets = targets
+	a.version = set.Version
+	a.logger.Debug("assignments synced", "targets", len(targets), "version", set.Version)
+	return nil
+}
+
+func (a *virtualAgent) runAssignmentSync(ctx context.Context) error {
+	ticker := time.NewTicker(a.cfg.AssignmentRefresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			if err := a.syncAssignments(ctx); err != nil {
+				a.logger.Warn("failed to sync assignments", "error", err)
+			}
+		}
+	}
+}
+
+func (a *virtualAgent) runHeartbeat(ctx context.Context) error {
+	ticker := time.NewTicker(a.cfg.HeartbeatInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		stats := a.shipper.Stats()
+		a.mu.Lock()
+		hb := types.Heartbeat{
+			AgentID:           a.client.AgentID(),
+			Timestamp:         time.Now(),
+			Version:           a.cfg.Version,
+			Status:            types.AgentStatusActive,
+			ActiveTargets:     len(a.targets),
+			ResultsQueued:     stats.Queued,
+			ResultsShipped:    stats.Shipped,
+			ResultsFailed:     stats.Failed,
+			BatchesShipped:    stats.BatchesShipped,
+			ShipFailures:      stats.ShipFailures,
+			BytesShipped:      stats.BytesShipped,
+			OldestQueuedAgeMs: stats.OldestQueuedAgeMs,
+			AssignmentVersion: a.version,
+			PublicIP:          a.ip,
+		}
+		a.mu.Unlock()
+
+		resp, err := a.client.Heartbeat(ctx, hb)
+		if err != nil {
+			a.logger.Warn("heartbeat failed", "error", err)
+			continue
+		}
+		if resp.AssignmentStale {
+			if err := a.syncAssignments(ctx); err != nil {
+				a.logger.Warn("failed to sync assignments", "error", err)
+			}
+		}
+	}
+}
+
+// probeTick is how often the probe loop looks for due targets.
+const probeTick = 250 * time.Millisecond
+
+func (a *virtualAgent) runProbes(ctx context.Context) error {
+	ticker := time.NewTicker(probeTick)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if results := a.probeDue(now); len(results) > 0 {
+				a.shipper.Add(results)
+			}
+		}
+	}
+}
+
+// probeDue generates a result for every target due at now and schedules
+// its next probe. A target that fell more than an interval behind skips
+// ahead rather than catching up in a burst.
+func (a *virtualAgent) probeDue(now time.Time) []*executor.Result {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	var results []*executor.Result
+	for id, t := range a.targets {
+		if now.Before(t.next) {
+			continue
+		}
+		r := a.model.Probe(a.rng, a.name, id, now)
+		r.TargetID, r.EndpointID = t.targetID, t.endpointID
+		results = append(results, r)
+		t.next = t.next.Add(t.interval)
+		if t.next.Before(now) {
+			t.next = now.Add(t.interval)
+		}
+	}
+	return results
+}
+
+func (a *virtualAgent) stats() shipper.Stats {
+	a.mu.Lock()
+	s := a.shipper
+	a.mu.Unlock()
+	if s == nil {
+		return shipper.Stats{}
+	}
+	return s.Stats()
+}
+
+func (a *virtualAgent) targetCount() int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return len(a.targets)
+}
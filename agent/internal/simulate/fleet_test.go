@@ -0,0 +1,89 @@
+package simulate
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// fakeControlPlane registers agents, assigns every agent the same targets
+// and records shipped results per agent.
+type fakeControlPlane struct {
+	mu      sync.Mutex
+	names   []string
+	results map[string]int
+}
+
+func (f *fakeControlPlane) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.URL.Path == "/api/v1/agents/register":
+		var req struct {
+			Name string `json:"name"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.names = append(f.names, req.Name)
+		f.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]string{"agent_id": "id-" + req.Name})
+	case strings.HasSuffix(r.URL.Path, "/assignments"):
+		json.NewEncoder(w).Encode(types.AssignmentSet{Version: 1, Assignments: []types.Assignment{
+			{TargetID: "t1", ProbeInterval: time.Minute},
+			{TargetID: "t2", ProbeInterval: time.Minute},
+		}})
+	case r.URL.Path == "/api/v1/results":
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		var batch types.ResultBatch
+		if err := json.NewDecoder(gz).Decode(&batch); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.results[batch.AgentID] += len(batch.Results)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	default:
+		json.NewEncoder(w).Encode(types.HeartbeatResponse{})
+	}
+}
+
+func TestRun_ShipsResults(t *testing.T) {
+	cp := &fakeControlPlane{results: make(map[string]int)}
+	srv := httptest.NewServer(cp)
+	defer srv.Close()
+
+	cfg := DefaultConfig()
+	cfg.ControlPlaneURL = srv.URL
+	cfg.Agents = 3
+	cfg.Interval = 100 * time.Millisecond
+	cfg.BatchSize = 2
+	cfg.BatchTimeout = 50 * time.Millisecond
+
+	ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+	defer cancel()
+	if err := Run(ctx, cfg); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	if len(cp.names) != cfg.Agents {
+		t.Fatalf("registered %v, want %d agents", cp.names, cfg.Agents)
+	}
+	for _, name := range cp.names {
+		if cp.results["id-"+name] == 0 {
+			t.Errorf("agent %s shipped no results", name)
+		}
+	}
+}
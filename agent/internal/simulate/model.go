@@ -0,0 +1,195 @@
+// Package simulate runs virtual agents that register with the control plane
+// and ship synthetic probe results, for load-testing ingestion, the
+// evaluator and dashboards without real hosts or ICMP.
+//
+// # Results
+//
+// Each virtual agent probes whatever the control plane assigns it, on the
+// assignment's interval (or a fixed override), and generates icmp_ping
+// results from a Profile instead of sending packets. Results go through the
+// real shipper, so batching, gzip and sequencing match a real agent.
+//
+// Every agent-target pair gets a stable base latency, and a stable fraction
+// of pairs is degraded (higher latency and loss), so baselines form and the
+// evaluator has anomalies to find. A pair's traits depend only on the seed,
+// the agent name and the target, so reruns with the same seed agree.
+package simulate
+
+import (
+	"fmt"
+	"hash/fnv"
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+)
+
+// Profile shapes the generated results.
+type Profile struct {
+	// LatencyMs is the typical round-trip time of a healthy pair. Each pair
+	// gets a base latency spread uniformly within LatencySpreadMs of it.
+	LatencyMs       float64
+	LatencySpreadMs float64
+
+	// JitterMs is the standard deviation of each packet around its pair's
+	// base latency.
+	JitterMs float64
+
+	// LossPct is the chance, in percent, that a packet of a healthy pair is
+	// lost.
+	LossPct float64
+
+	// DegradedPct is the percentage of pairs that are degraded: their
+	// latency is multiplied by DegradedLatencyFactor and their packets are
+	// lost with DegradedLossPct.
+	DegradedPct           float64
+	DegradedLatencyFactor float64
+	DegradedLossPct       float64
+
+	// Packets is the number of echo requests per probe.
+	Packets int
+}
+
+// DefaultProfile returns a mostly healthy fleet with a few degraded paths.
+func DefaultProfile() Profile {
+	return Profile{
+		LatencyMs:             40,
+		LatencySpreadMs:       30,
+		JitterMs:              2,
+		LossPct:               0.5,
+		DegradedPct:           2,
+		DegradedLatencyFactor: 4,
+		DegradedLossPct:       30,
+		Packets:               3,
+	}
+}
+
+// Validate reports a profile that can't generate sensible results.
+func (p Profile) Validate() error {
+	switch {
+	case p.LatencyMs <= 0:
+		return fmt.Errorf("latency must be positive, got %v", p.LatencyMs)
+	case p.LatencySpreadMs < 0 || p.LatencySpreadMs >= p.LatencyMs:
+		return fmt.Errorf("latency spread must be at least 0 and below the latency, got %v", p.LatencySpreadMs)
+	case p.JitterMs < 0:
+		return fmt.Errorf("jitter must not be negative, got %v", p.JitterMs)
+	case !isPct(p.LossPct) || !isPct(p.DegradedPct) || !isPct(p.DegradedLossPct):
+		return fmt.Errorf("percentages must be between 0 and 100")
+	case p.DegradedLatencyFactor < 1:
+		return fmt.Errorf("degraded latency factor must be at least 1, got %v", p.DegradedLatencyFactor)
+	case p.Packets < 1:
+		return fmt.Errorf("packets must be at least 1, got %d", p.Packets)
+	}
+	return nil
+}
+
+func isPct(v float64) bool {
+	return v >= 0 && v <= 100
+}
+
+// pairTraits is what stays fixed for an agent-target pair across probes.
+type pairTraits struct {
+	baseMs   float64
+	lossPct  float64
+	degraded bool
+}
+
+// Model generates results for a profile. It holds no mutable state and is
+// safe for concurrent use; per-probe randomness comes from the caller.
+type Model struct {
+	profile Profile
+	seed    uint64
+}
+
+// NewModel creates a model. The seed fixes each pair's traits.
+func NewModel(profile Profile, seed uint64) *Model {
+	return &Model{profile: profile, seed: seed}
+}
+
+// traits derives a pair's base latency and health from the seed.
+func (m *Model) traits(agentName, targetID string) pairTraits {
+	h := fnv.New64a()
+	h.Write([]byte(agentName))
+	h.Write([]byte{0})
+	h.Write([]byte(targetID))
+	r := rand.New(rand.NewPCG(m.seed, h.Sum64()))
+
+	p := m.profile
+	t := pairTraits{
+		baseMs:  p.LatencyMs + (r.Float64()*2-1)*p.LatencySpreadMs,
+		lossPct: p.LossPct,
+	}
+	if r.Float64()*100 < p.DegradedPct {
+		t.degraded = true
+		t.baseMs *= p.DegradedLatencyFactor
+		t.lossPct = p.DegradedLossPct
+	}
+	return t
+}
+
+// Degraded reports whether the model degrades a pair.
+func (m *Model) Degraded(agentName, targetID string) bool {
+	return m.traits(agentName, targetID).degraded
+}
+
+// Probe generates one icmp_ping result for a pair at now, shaped like the
+// ICMP executor's: the first lost packet is forgiven when computing loss,
+// and the probe fails at DefaultFailureLossPct.
+func (m *Model) Probe(r *rand.Rand, agentName, targetID string, now time.Time) *executor.Result {
+	t := m.traits(agentName, targetID)
+
+	payload := executor.ICMPPayload{
+		PacketsSent:    m.profile.Packets,
+		FailureLossPct: executor.DefaultFailureLossPct,
+	}
+	var sum, sumSq float64
+	for i := 0; i < m.profile.Packets; i++ {
+		if r.Float64()*100 < t.lossPct {
+			continue
+		}
+		rtt := math.Max(t.baseMs+r.NormFloat64()*m.profile.JitterMs, 0.1)
+		if payload.PacketsRecvd == 0 || rtt < payload.MinMs {
+			payload.MinMs = rtt
+		}
+		payload.MaxMs = math.Max(payload.MaxMs, rtt)
+		payload.LatencyMs = rtt
+		payload.PacketsRecvd++
+		sum += rtt
+		sumSq += rtt * rtt
+	}
+
+	result := &executor.Result{
+		TargetID:  targetID,
+		Timestamp: now,
+		Duration:  time.Duration(payload.MaxMs * float64(time.Millisecond)),
+	}
+	if payload.PacketsRecvd == 0 {
+		payload.PacketLoss = 100
+		result.Error = "no response (simulated)"
+		result.Payload = executor.MarshalPayload(payload)
+		return result
+	}
+
+	n := float64(payload.PacketsRecvd)
+	payload.Reachable = true
+	payload.AvgMs = sum / n
+	payload.StdDevMs = math.Sqrt(math.Max(sumSq/n-payload.AvgMs*payload.AvgMs, 0))
+	payload.PacketLoss = lossWithGrace(payload.PacketsSent, payload.PacketsRecvd)
+	result.Success = payload.Successful()
+	result.Payload = executor.MarshalPayload(payload)
+	return result
+}
+
+// lossWithGrace is the executor's loss calculation: a single lost packet
+// out of several doesn't count.
+func lossWithGrace(sent, recvd int) float64 {
+	if sent == 1 {
+		return float64(sent-recvd) * 100
+	}
+	lost := sent - recvd
+	if lost > 0 {
+		lost--
+	}
+	return float64(lost) / float64(sent-1) * 100
+}
@@ -0,0 +1,205 @@
+package simulate
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+)
+
+func TestProfile_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(*Profile)
+		wantErr bool
+	}{
+		{name: "default", modify: func(*Profile) {}},
+		{name: "zero latency", modify: func(p *Profile) { p.LatencyMs = 0 }, wantErr: true},
+		{name: "spread reaches zero latency", modify: func(p *Profile) { p.LatencySpreadMs = p.LatencyMs }, wantErr: true},
+		{name: "negative jitter", modify: func(p *Profile) { p.JitterMs = -1 }, wantErr: true},
+		{name: "loss over 100", modify: func(p *Profile) { p.LossPct = 101 }, wantErr: true},
+		{name: "degraded faster", modify: func(p *Profile) { p.DegradedLatencyFactor = 0.5 }, wantErr: true},
+		{name: "no packets", modify: func(p *Profile) { p.Packets = 0 }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultProfile()
+			tt.modify(&p)
+			if err := p.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func decodePayload(t *testing.T, r *executor.Result) executor.ICMPPayload {
+	t.Helper()
+	var p executor.ICMPPayload
+	if err := json.Unmarshal(r.Payload, &p); err != nil {
+		t.Fatalf("decoding payload: %v", err)
+	}
+	return p
+}
+
+func TestModel_Probe(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		modify      func(*Profile)
+		wantSuccess bool
+		wantMinMs   float64
+		wantMaxMs   float64
+	}{
+		{
+			name:        "healthy",
+			modify:      func(p *Profile) { p.LossPct, p.DegradedPct = 0, 0 },
+			wantSuccess: true,
+			wantMinMs:   10 - 1,
+			wantMaxMs:   70 + 1,
+		},
+		{
+			name:        "all degraded",
+			modify:      func(p *Profile) { p.LossPct, p.DegradedPct, p.DegradedLossPct = 0, 100, 0 },
+			wantSuccess: true,
+			wantMinMs:   4*10 - 1,
+			wantMaxMs:   4*70 + 1,
+		},
+		{
+			name:   "total loss",
+			modify: func(p *Profile) { p.LossPct, p.DegradedPct = 100, 0 },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := DefaultProfile()
+			p.JitterMs = 0.1
+			tt.modify(&p)
+			m := NewModel(p, 7)
+			r := rand.New(rand.NewPCG(1, 2))
+
+			for i := 0; i < 50; i++ {
+				res := m.Probe(r, "sim-001", fmt.Sprintf("target-%d", i), now)
+				payload := decodePayload(t, res)
+				if res.Success != tt.wantSuccess || payload.Successful() != tt.wantSuccess {
+					t.Fatalf("probe %d: Success = %v, want %v", i, res.Success, tt.wantSuccess)
+				}
+				if !tt.wantSuccess {
+					if payload.PacketLoss != 100 || payload.Reachable {
+						t.Errorf("probe %d: loss = %v reachable = %v, want total loss", i, payload.PacketLoss, payload.Reachable)
+					}
+					continue
+				}
+				if payload.AvgMs < tt.wantMinMs || payload.AvgMs > tt.wantMaxMs {
+					t.Errorf("probe %d: avg = %.1fms, want %v-%v", i, payload.AvgMs, tt.wantMinMs, tt.wantMaxMs)
+				}
+				if payload.PacketsRecvd != p.Packets || payload.PacketLoss != 0 {
+					t.Errorf("probe %d: recvd %d loss %v, want all packets", i, payload.PacketsRecvd, payload.PacketLoss)
+				}
+			}
+		})
+	}
+}
+
+func TestModel_PairTraitsStable(t *testing.T) {
+	p := DefaultProfile()
+	p.DegradedPct = 25
+	m := NewModel(p, 42)
+	other := NewModel(p, 42)
+
+	degraded := 0
+	for i := 0; i < 1000; i++ {
+		target := fmt.Sprintf("target-%d", i)
+		got := m.traits("sim-001", target)
+		if got != other.traits("sim-001", target) {
+			t.Fatalf("traits for %s differ between models with the same seed", target)
+		}
+		if got.degraded {
+			degraded++
+		}
+	}
+	if degraded < 200 || degraded > 300 {
+		t.Errorf("%d of 1000 pairs degraded, want about 250", degraded)
+	}
+}
+
+func TestLossWithGrace(t *testing.T) {
+	tests := []struct {
+		sent, recvd int
+		want        float64
+	}{
+		{sent: 3, recvd: 3, want: 0},
+		{sent: 3, recvd: 2, want: 0},
+		{sent: 3, recvd: 1, want: 50},
+		{sent: 3, recvd: 0, want: 100},
+		{sent: 1, recvd: 0, want: 100},
+		{sent: 1, recvd: 1, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d of %d", tt.recvd, tt.sent), func(t *testing.T) {
+			if got := lossWithGrace(tt.sent, tt.recvd); got != tt.want {
+				t.Errorf("lossWithGrace(%d, %d) = %v, want %v", tt.sent, tt.recvd, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTargetIP_Range(t *testing.T) {
+	tests := []struct {
+		i       int
+		want    string
+		wantErr bool
+	}{
+		{i: 0, want: "198.18.0.1"},
+		{i: 255, want: "198.18.1.0"},
+		{i: maxTargets - 1, want: "198.19.255.254"},
+		{i: maxTargets, wantErr: true},
+		{i: -1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.i), func(t *testing.T) {
+			got, err := TargetIP(tt.i)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("TargetIP(%d) error = %v, wantErr %v", tt.i, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("TargetIP(%d) = %s, want %s", tt.i, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVirtualAgent_ProbeDue(t *testing.T) {
+	cfg := DefaultConfig()
+	a := newVirtualAgent(&cfg, NewModel(cfg.Profile, 1), 0)
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	a.targets = map[string]*simTarget{
+		"due":    {targetID: "due", interval: 10 * time.Second, next: start},
+		"later":  {targetID: "later", interval: 10 * time.Second, next: start.Add(5 * time.Second)},
+		"behind": {targetID: "due", endpointID: "behind", interval: 10 * time.Second, next: start.Add(-time.Minute)},
+	}
+
+	results := a.probeDue(start)
+	if len(results) != 2 {
+		t.Fatalf("probeDue() returned %d results, want 2", len(results))
+	}
+	for _, r := range results {
+		if r.TargetID != "due" {
+			t.Errorf("result target = %q, want the endpoint's target", r.TargetID)
+		}
+	}
+	if next := a.targets["due"].next; !next.Equal(start.Add(10 * time.Second)) {
+		t.Errorf("due target next = %s, want one interval later", next)
+	}
+	if next := a.targets["behind"].next; !next.Equal(start.Add(10 * time.Second)) {
+		t.Errorf("behind target next = %s, want skipped ahead to one interval from now", next)
+	}
+	if got := len(a.probeDue(start.Add(5 * time.Second))); got != 1 {
+		t.Errorf("probeDue() at +5s returned %d results, want 1", got)
+	}
+}
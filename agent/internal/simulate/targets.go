@@ -0,0 +1,97 @@
+package simulate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"strings"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// targetRange is the benchmarking range (RFC 2544) synthetic targets are
+// numbered from, so they can't be mistaken for customer addresses.
+var targetRange = netip.MustParsePrefix("198.18.0.0/15")
+
+// maxTargets is how many synthetic targets targetRange holds.
+const maxTargets = 1<<17 - 2
+
+// createConcurrency is how many target creations are in flight at once.
+const createConcurrency = 16
+
+// TargetIP returns the address of the i-th synthetic target (from 0).
+func TargetIP(i int) (netip.Addr, error) {
+	if i < 0 || i >= maxTargets {
+		return netip.Addr{}, fmt.Errorf("target index %d outside 0-%d", i, maxTargets-1)
+	}
+	return nthAddr(targetRange, i+1), nil
+}
+
+// nthAddr returns the address n past the start of an IPv4 prefix.
+func nthAddr(prefix netip.Prefix, n int) netip.Addr {
+	b := prefix.Addr().As4()
+	v := uint32(b[0])<<24 | uint32(b[1])<<16 | uint32(b[2])<<8 | uint32(b[3])
+	v += uint32(n)
+	return netip.AddrFrom4([4]byte{byte(v >> 24), byte(v >> 16), byte(v >> 8), byte(v)})
+}
+
+// CreateTargets makes sure the first n synthetic targets exist in tier,
+// tagged simulated=true. Targets that already exist are left as they are,
+// so reruns are cheap.
+func CreateTargets(ctx context.Context, cfg Config, n int, tier string) error {
+	if n > maxTargets {
+		return fmt.Errorf("at most %d synthetic targets, got %d", maxTargets, n)
+	}
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	endpoint := strings.TrimSuffix(cfg.ControlPlaneURL, "/") + "/api/v1/targets"
+
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(createConcurrency)
+	for i := 0; i < n; i++ {
+		ip, err := TargetIP(i)
+		if err != nil {
+			return err
+		}
+		g.Go(func() error {
+			return createTarget(gctx, httpClient, endpoint, cfg.Token, ip, tier)
+		})
+	}
+	return g.Wait()
+}
+
+func createTarget(ctx context.Context, httpClient *http.Client, endpoint, token string, ip netip.Addr, tier string) error {
+	body, err := json.Marshal(map[string]any{
+		"ip":           ip.String(),
+		"tier":         tier,
+		"tags":         map[string]string{"simulated": "true"},
+		"on_duplicate": "return",
+	})
+	if err != nil {
+		return fmt.Errorf("marshaling target: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("creating target %s: %w", ip, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("creating target %s: status %d: %s", ip, resp.StatusCode, msg)
+	}
+	return nil
+}
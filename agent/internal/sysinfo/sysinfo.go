@@ -0,0 +1,146 @@
+// Package sysinfo collects host details the agent reports at registration.
+//
+// Probe behavior varies by platform: fping over an unprivileged ICMP
+// datagram socket timestamps replies in a different place than over a raw
+// socket, and virtual NICs add scheduling jitter a bare-metal NIC doesn't.
+// Reporting the kernel, NIC driver, virtualization type and ICMP socket
+// capabilities lets latency differences between agents be explained.
+//
+// Collection is best effort: anything that can't be determined is left
+// empty rather than failing registration.
+package sysinfo
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/shirou/gopsutil/v3/host"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// rtfUp is the RTF_UP flag in /proc/net/route.
+const rtfUp = 0x1
+
+// Collect gathers system info for the running host.
+func Collect(ctx context.Context) *types.AgentSystemInfo {
+	info := &types.AgentSystemInfo{
+		OS:   runtime.GOOS,
+		Arch: runtime.GOARCH,
+	}
+
+	if platform, _, version, err := host.PlatformInformationWithContext(ctx); err == nil {
+		info.Platform = strings.TrimSpace(platform + " " + version)
+	}
+	if kernel, err := host.KernelVersionWithContext(ctx); err == nil {
+		info.KernelVersion = kernel
+	}
+	if system, role, err := host.VirtualizationWithContext(ctx); err == nil {
+		info.Virtualization = system
+		info.VirtualizationRole = role
+	}
+
+	if route, err := os.ReadFile("/proc/net/route"); err == nil {
+		info.Interface = defaultRouteInterface(string(route))
+	}
+	if info.Interface != "" {
+		info.NICDriver = nicDriver("/sys/class/net", info.Interface)
+	}
+
+	info.RawSocket = RawSocketAvailable()
+	info.UnprivilegedICMP = UnprivilegedICMPAllowed()
+
+	return info
+}
+
+// defaultRouteInterface returns the interface of the IPv4 default route from
+// the contents of /proc/net/route, or "" if there is none.
+func defaultRouteInterface(route string) string {
+	lines := strings.Split(route, "\n")
+	for _, line := range lines[min(1, len(lines)):] { // skip header
+		fields := strings.Fields(line)
+		if len(fields) < 8 {
+			continue
+		}
+		// Destination and Mask are both 0 for the default route
+		if fields[1] != "00000000" || fields[7] != "00000000" {
+			continue
+		}
+		flags, err := strconv.ParseUint(fields[3], 16, 32)
+		if err != nil || flags&rtfUp == 0 {
+			continue
+		}
+		return fields[0]
+	}
+	return ""
+}
+
+// nicDriver returns the kernel driver bound to an interface, read from the
+// device/driver symlink under sysClassNet. Virtual interfaces (bridges,
+// veth, tun) have no backing device and return "".
+func nicDriver(sysClassNet, iface string) string {
+	target, err := filepath.EvalSymlinks(filepath.Join(sysClassNet, iface, "device", "driver"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// pingGroupAllowed reports whether any of gids falls within the inclusive
+// range in net.ipv4.ping_group_range. The kernel default "1 0" is an empty
+// range, i.e. unprivileged ICMP disabled.
+func pingGroupAllowed(groupRange string, gids []int) bool {
+	fields := strings.Fields(groupRange)
+	if len(fields) != 2 {
+		return false
+	}
+	lo, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return false
+	}
+	hi, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return false
+	}
+	for _, gid := range gids {
+		if int64(gid) >= lo && int64(gid) <= hi {
+			return true
+		}
+	}
+	return false
+}
+
+// UnprivilegedICMPAllowed reports whether the process may open ICMP datagram
+// sockets without privileges (Linux only; false elsewhere).
+func UnprivilegedICMPAllowed() bool {
+	groupRange, err := os.ReadFile("/proc/sys/net/ipv4/ping_group_range")
+	if err != nil {
+		return false
+	}
+	return pingGroupAllowed(string(groupRange), processGroups())
+}
+
+// processGroups returns the primary and supplementary group IDs of the
+// agent process.
+func processGroups() []int {
+	gids := []int{os.Getgid()}
+	if extra, err := os.Getgroups(); err == nil {
+		gids = append(gids, extra...)
+	}
+	return gids
+}
+
+// RawSocketAvailable reports whether the process may open a raw ICMP socket.
+func RawSocketAvailable() bool {
+	conn, err := net.ListenPacket("ip4:icmp", "0.0.0.0")
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
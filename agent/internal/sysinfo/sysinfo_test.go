@@ -0,0 +1,110 @@
+package sysinfo
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaultRouteInterface(t *testing.T) {
+	const header = "Iface\tDestination\tGateway \tFlags\tRefCnt\tUse\tMetric\tMask\t\tMTU\tWindow\tIRTT\n"
+
+	tests := []struct {
+		name  string
+		route string
+		want  string
+	}{
+		{
+			name: "default route",
+			route: header +
+				"eth0\t0000A8C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n" +
+				"eth0\t00000000\t0100A8C0\t0003\t0\t0\t0\t00000000\t0\t0\t0\n",
+			want: "eth0",
+		},
+		{
+			name: "default route down",
+			route: header +
+				"eth1\t00000000\t0100A8C0\t0002\t0\t0\t0\t00000000\t0\t0\t0\n",
+			want: "",
+		},
+		{
+			name: "no default route",
+			route: header +
+				"ens3\t0000A8C0\t00000000\t0001\t0\t0\t0\t00FFFFFF\t0\t0\t0\n",
+			want: "",
+		},
+		{
+			name:  "empty",
+			route: "",
+			want:  "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := defaultRouteInterface(tt.route); got != tt.want {
+				t.Errorf("defaultRouteInterface() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPingGroupAllowed(t *testing.T) {
+	tests := []struct {
+		name       string
+		groupRange string
+		gids       []int
+		want       bool
+	}{
+		{name: "kernel default disabled", groupRange: "1\t0\n", gids: []int{0, 1000}, want: false},
+		{name: "all groups", groupRange: "0\t2147483647\n", gids: []int{1000}, want: true},
+		{name: "supplementary group in range", groupRange: "100 200", gids: []int{1000, 150}, want: true},
+		{name: "outside range", groupRange: "100 200", gids: []int{1000}, want: false},
+		{name: "malformed", groupRange: "garbage", gids: []int{0}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := pingGroupAllowed(tt.groupRange, tt.gids); got != tt.want {
+				t.Errorf("pingGroupAllowed(%q, %v) = %v, want %v", tt.groupRange, tt.gids, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNICDriver(t *testing.T) {
+	root := t.TempDir()
+	driverDir := filepath.Join(root, "bus", "pci", "drivers", "virtio_net")
+	if err := os.MkdirAll(driverDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	netDir := filepath.Join(root, "class", "net")
+
+	// eth0 is backed by a PCI device; br0 is virtual and has no device
+	if err := os.MkdirAll(filepath.Join(netDir, "eth0", "device"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(driverDir, filepath.Join(netDir, "eth0", "device", "driver")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(netDir, "br0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		iface string
+		want  string
+	}{
+		{iface: "eth0", want: "virtio_net"},
+		{iface: "br0", want: ""},
+		{iface: "missing", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.iface, func(t *testing.T) {
+			if got := nicDriver(netDir, tt.iface); got != tt.want {
+				t.Errorf("nicDriver(%q) = %q, want %q", tt.iface, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// pmtudTimeout is the per-ping timeout while searching. Discovery takes a
+// dozen or so sizes, so a slow target still finishes well within a minute.
+const pmtudTimeout = time.Second
+
+// executePMTUD finds the path MTU to the command's target with the ICMP
+// executor's don't-fragment pings.
+func (a *Agent) executePMTUD(ctx context.Context, cmd types.Command) types.CommandResult {
+	result := types.CommandResult{
+		CommandID: cmd.ID,
+		AgentID:   a.agentID,
+	}
+
+	var params types.PMTUDParams
+	if len(cmd.Params) > 0 {
+		if err := json.Unmarshal(cmd.Params, &params); err != nil {
+			result.Error = fmt.Sprintf("invalid pmtud params: %v", err)
+			return result
+		}
+	}
+	if err := params.Validate(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	ip := net.ParseIP(cmd.TargetIP)
+	if ip == nil {
+		result.Error = fmt.Sprintf("invalid target address %q", cmd.TargetIP)
+		return result
+	}
+
+	exec, ok := a.registry.Get("icmp_ping")
+	if !ok {
+		result.Error = "ICMP executor not available"
+		return result
+	}
+	icmp, ok := exec.(*executor.ICMPExecutor)
+	if !ok {
+		result.Error = "ICMP executor does not support path MTU discovery"
+		return result
+	}
+
+	minMTU, maxMTU := params.Range(ip.To4() == nil)
+	mtu, probes, err := icmp.DiscoverPathMTU(ctx, cmd.TargetIP, minMTU, maxMTU, pmtudTimeout)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	payload := types.PMTUDPayload{
+		TargetIP: cmd.TargetIP,
+		PathMTU:  mtu,
+		AtMax:    mtu == maxMTU,
+		MinMTU:   minMTU,
+		MaxMTU:   maxMTU,
+		Probes:   make([]types.PMTUDProbe, len(probes)),
+	}
+	for i, p := range probes {
+		payload.Probes[i] = types.PMTUDProbe{Size: p.Size, Replied: p.Replied}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		result.Error = fmt.Sprintf("encoding pmtud result: %v", err)
+		return result
+	}
+	result.Payload = data
+	if mtu == 0 {
+		result.Error = fmt.Sprintf("no reply from %s at %d bytes or more with fragmentation prohibited", cmd.TargetIP, minMTU)
+	} else {
+		result.Success = true
+	}
+
+	a.logger.Info("path MTU discovery finished",
+		"command", cmd.ID,
+		"target", cmd.TargetIP,
+		"path_mtu", mtu,
+		"probes", len(probes))
+	return result
+}
@@ -0,0 +1,120 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// REMOTE CONFIG
+// =============================================================================
+//
+// The control plane can push the settings in types.AgentRemoteConfig. They
+// are merged over the local config when fetched: at startup and whenever a
+// heartbeat reports the config stale. The applied version goes back in
+// every heartbeat.
+
+// SetLogLevel gives the agent the level its logger's handler reads, so a
+// remote log_level can change it. The level's current value is the local
+// default, restored when the remote config stops setting one.
+func (a *Agent) SetLogLevel(level *slog.LevelVar) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.logLevel = level
+	a.localLogLevel = level.Level()
+}
+
+// syncConfig fetches the remote config and applies it.
+func (a *Agent) syncConfig(ctx context.Context) error {
+	set, err := a.client.GetConfig(ctx)
+	if err != nil {
+		return err
+	}
+	return a.applyRemoteConfig(set)
+}
+
+// applyRemoteConfig merges a remote config over the local config. A config
+// that can't be applied is rejected whole and its version isn't recorded,
+// so the control plane keeps seeing the agent as not applied.
+func (a *Agent) applyRemoteConfig(set *types.AgentConfigSet) error {
+	remote := set.Config
+	if err := remote.Validate(); err != nil {
+		return fmt.Errorf("remote config version %d: %w", set.Version, err)
+	}
+
+	level := a.localLogLevel
+	if remote.LogLevel != nil {
+		if err := level.UnmarshalText([]byte(*remote.LogLevel)); err != nil {
+			return fmt.Errorf("remote config version %d: %w", set.Version, err)
+		}
+	}
+
+	if a.scheduler != nil {
+		local := *a.cfg
+		if remote.ScheduleAlignment != nil {
+			local.Probing.ScheduleAlignment = *remote.ScheduleAlignment
+		}
+		if err := configureAlignment(&local, a.scheduler); err != nil {
+			return fmt.Errorf("remote config version %d: %w", set.Version, err)
+		}
+	}
+
+	a.mu.Lock()
+	a.remote = remote
+	a.configVersion = set.Version
+	if a.logLevel != nil {
+		a.logLevel.Set(level)
+	}
+	a.mu.Unlock()
+
+	a.logger.Info("remote config applied",
+		"version", set.Version,
+		"log_level", level,
+		"heartbeat_interval", a.heartbeatInterval(),
+		"assignment_poll_interval", a.assignmentPollInterval(),
+		"command_poll_interval", a.commandPollInterval(),
+		"feature_flags", remote.FeatureFlags)
+	return nil
+}
+
+// appliedConfigVersion returns the version of the remote config in effect.
+func (a *Agent) appliedConfigVersion() int64 {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.configVersion
+}
+
+// remoteInterval returns the remote override of an interval, in seconds,
+// or the local value when there is none.
+func (a *Agent) remoteInterval(override func(types.AgentRemoteConfig) *int, local time.Duration) time.Duration {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if s := override(a.remote); s != nil {
+		return time.Duration(*s) * time.Second
+	}
+	return local
+}
+
+func (a *Agent) heartbeatInterval() time.Duration {
+	return a.remoteInterval(func(c types.AgentRemoteConfig) *int { return c.HeartbeatIntervalS }, a.cfg.Health.HeartbeatInterval)
+}
+
+func (a *Agent) assignmentPollInterval() time.Duration {
+	return a.remoteInterval(func(c types.AgentRemoteConfig) *int { return c.AssignmentPollIntervalS }, a.cfg.Probing.AssignmentPollInterval)
+}
+
+func (a *Agent) commandPollInterval() time.Duration {
+	return a.remoteInterval(func(c types.AgentRemoteConfig) *int { return c.CommandPollIntervalS }, a.cfg.Probing.CommandPollInterval)
+}
+
+// featureEnabled reports whether the remote config turns a feature flag
+// on. Flags the control plane hasn't set are off.
+func (a *Agent) featureEnabled(name string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.remote.FeatureFlags[name]
+}
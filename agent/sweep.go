@@ -0,0 +1,90 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/agent/internal/executor"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Sweep probes are kept light: a couple of pings with a short timeout is
+// enough to tell a live address from an empty one.
+const (
+	sweepPingCount = 2
+	sweepTimeout   = time.Second
+)
+
+// executeSubnetSweep pings each address in the command's params and reports
+// the ones that answered.
+func (a *Agent) executeSubnetSweep(ctx context.Context, cmd types.Command) types.CommandResult {
+	result := types.CommandResult{
+		CommandID: cmd.ID,
+		AgentID:   a.agentID,
+	}
+
+	var params types.SubnetSweepParams
+	if err := json.Unmarshal(cmd.Params, &params); err != nil {
+		result.Error = fmt.Sprintf("invalid sweep params: %v", err)
+		return result
+	}
+	if err := params.Validate(); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	exec, ok := a.registry.Get("icmp_ping")
+	if !ok {
+		result.Error = "ICMP executor not available"
+		return result
+	}
+
+	probeParams, _ := json.Marshal(executor.ICMPParams{Count: sweepPingCount})
+	targets := make([]executor.ProbeTarget, len(params.IPs))
+	for i, ip := range params.IPs {
+		targets[i] = executor.ProbeTarget{
+			ID:      ip,
+			IP:      ip,
+			Timeout: sweepTimeout,
+			Params:  probeParams,
+		}
+	}
+
+	batchSize := exec.Capabilities().MaxBatchSize
+	if batchSize <= 0 {
+		batchSize = len(targets)
+	}
+
+	payload := types.SubnetSweepPayload{Scanned: len(targets), Responsive: []types.SweepHost{}}
+	for start := 0; start < len(targets); start += batchSize {
+		end := min(start+batchSize, len(targets))
+		probes, err := exec.ExecuteBatch(ctx, targets[start:end])
+		if err != nil {
+			result.Error = err.Error()
+			return result
+		}
+		for _, p := range probes {
+			var icmp executor.ICMPPayload
+			if json.Unmarshal(p.Payload, &icmp) != nil || !icmp.Reachable {
+				continue
+			}
+			payload.Responsive = append(payload.Responsive, types.SweepHost{IP: p.TargetID, LatencyMs: icmp.AvgMs})
+		}
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		result.Error = fmt.Sprintf("encoding sweep result: %v", err)
+		return result
+	}
+	result.Success = true
+	result.Payload = data
+
+	a.logger.Info("subnet sweep finished",
+		"command", cmd.ID,
+		"scanned", payload.Scanned,
+		"responsive", len(payload.Responsive))
+	return result
+}
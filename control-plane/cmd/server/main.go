@@ -19,21 +19,29 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"syscall"
 	"time"
 
 	"github.com/pilot-net/icmp-mon/control-plane/internal/api"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/buffer"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/cache"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/enrollment"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/ipasn"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/kafka"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/mail"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/metrics"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/notify"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/pilot"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/report"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/rollout"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/secrets"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/worker"
 	"github.com/pilot-net/icmp-mon/db/migrate"
+	"github.com/pilot-net/icmp-mon/pkg/payload"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
 
@@ -72,19 +80,47 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	db, err := store.NewStoreFromURL(ctx, *dbURL)
+	// Optional read replica for dashboard reads
+	replicaConfig := store.ReplicaConfig{URL: os.Getenv("ICMPMON_DATABASE_REPLICA_URL")}
+	if v := os.Getenv("ICMPMON_DATABASE_REPLICA_MAX_LAG"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			replicaConfig.MaxLag = d
+		} else {
+			logger.Warn("invalid ICMPMON_DATABASE_REPLICA_MAX_LAG, using default", "value", v)
+		}
+	}
+
+	db, err := store.NewStoreWithReplica(ctx, *dbURL, replicaConfig, logger)
 	if err != nil {
 		logger.Error("failed to connect to database", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
+	// Workers that write shared state run on one instance at a time, elected
+	// through advisory locks held on this session
+	lockSession := db.NewLockSession()
+	defer lockSession.Close(context.Background())
+
 	// Verify database connection
 	if err := db.Ping(ctx); err != nil {
 		logger.Error("database ping failed", "error", err)
 		os.Exit(1)
 	}
 	logger.Info("connected to database")
+	if configured, inUse, lag := db.ReplicaStatus(); configured {
+		logger.Info("read replica configured", "in_use", inUse, "lag", lag)
+	}
+
+	// Parallel batches for the evaluator's bulk pair lookups
+	if v := os.Getenv("ICMPMON_BULK_QUERY_CONCURRENCY"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			logger.Error("invalid ICMPMON_BULK_QUERY_CONCURRENCY (want a positive integer)", "value", v)
+			os.Exit(1)
+		}
+		db.SetBulkConcurrency(n)
+	}
 
 	// Run database migrations
 	// This ensures the schema is up-to-date before starting services
@@ -97,6 +133,43 @@ func main() {
 
 	// Create service
 	svc := service.NewService(db, logger)
+	svc.SetAlertTunableDefaults(worker.DefaultAlertTunables())
+
+	// Bounds on result timestamps; results outside them are rejected at ingest
+	validation := service.DefaultResultValidation()
+	for _, env := range []struct {
+		name string
+		dst  *time.Duration
+	}{
+		{"ICMPMON_RESULT_MAX_CLOCK_SKEW", &validation.MaxClockSkew},
+		{"ICMPMON_RESULT_MAX_AGE", &validation.MaxAge},
+	} {
+		v := os.Getenv(env.name)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			logger.Error("invalid "+env.name, "value", v)
+			os.Exit(1)
+		}
+		*env.dst = d
+	}
+	svc.SetResultValidation(validation)
+
+	// Payload sampling: store routine successful payloads in full only at
+	// this rate (failed and lossy probes are always kept in full)
+	var payloadSampling *payload.Sampling
+	if v := os.Getenv("ICMPMON_PAYLOAD_SAMPLE_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			logger.Error("invalid ICMPMON_PAYLOAD_SAMPLE_RATE (want 0 to 1)", "value", v)
+			os.Exit(1)
+		}
+		payloadSampling = &payload.Sampling{Rate: rate}
+		db.SetPayloadSampling(*payloadSampling)
+		logger.Info("payload sampling enabled", "rate", rate)
+	}
 
 	// Initialize Redis buffer for probe results (optional - only if Redis URL is configured)
 	var resultBuffer *buffer.ResultBuffer
@@ -112,7 +185,36 @@ func main() {
 			svc.SetResultBuffer(resultBuffer)
 
 			// Start background flusher
-			bufferFlusher = buffer.NewFlusher(resultBuffer, db.Pool(), logger)
+			flusherConfig := buffer.DefaultFlusherConfig()
+			if v := os.Getenv("ICMPMON_FLUSH_BATCH_SIZE"); v != "" {
+				n, err := strconv.Atoi(v)
+				if err != nil {
+					logger.Error("invalid ICMPMON_FLUSH_BATCH_SIZE", "value", v)
+					os.Exit(1)
+				}
+				flusherConfig.FlushBatchSize = n
+				flusherConfig.MinBatchSize = min(flusherConfig.MinBatchSize, n)
+				flusherConfig.MaxBatchSize = max(flusherConfig.MaxBatchSize, n)
+			}
+			if v := os.Getenv("ICMPMON_FLUSH_INTERVAL"); v != "" {
+				d, err := time.ParseDuration(v)
+				if err != nil {
+					logger.Error("invalid ICMPMON_FLUSH_INTERVAL", "value", v)
+					os.Exit(1)
+				}
+				flusherConfig.FlushInterval = d
+			}
+			if v := os.Getenv("ICMPMON_FLUSH_ADAPTIVE"); v == "false" || v == "0" {
+				flusherConfig.Adaptive = false
+			}
+			if err := flusherConfig.Validate(); err != nil {
+				logger.Error("invalid flusher config", "error", err)
+				os.Exit(1)
+			}
+			bufferFlusher = buffer.NewFlusher(resultBuffer, db.Pool(), flusherConfig, logger)
+			if payloadSampling != nil {
+				bufferFlusher.SetPayloadSampling(*payloadSampling)
+			}
 			bufferFlusher.Start()
 			logger.Info("redis buffer enabled", "redis_url", redisURL)
 		}
@@ -120,30 +222,92 @@ func main() {
 		logger.Info("redis buffer disabled - ICMPMON_REDIS_URL not set")
 	}
 
+	// Initialize Kafka publishing (optional - only if a REST Proxy and a
+	// topic are configured). Results still go to the database as well.
+	var kafkaProducer *kafka.Producer
+	kafkaConfig := kafka.ConfigFromEnv()
+	if kafkaConfig.Enabled() {
+		kafkaProducer = kafka.NewProducer(kafkaConfig, logger)
+		kafkaProducer.Start()
+		if kafkaConfig.ResultsTopic != "" {
+			svc.SetResultPublisher(kafkaProducer)
+		}
+		logger.Info("kafka publishing enabled",
+			"rest_url", kafkaConfig.RESTURL,
+			"results_topic", kafkaConfig.ResultsTopic,
+			"events_topic", kafkaConfig.EventsTopic,
+		)
+	}
+
 	// Initialize metrics collector for infrastructure health monitoring
 	var metricsCollector *metrics.Collector
-	if resultBuffer != nil {
-		metricsCollector = metrics.NewCollector(db, resultBuffer)
+	if bufferFlusher != nil {
+		// The flusher reports the buffer's stats along with its own
+		metricsCollector = metrics.NewCollector(db, bufferFlusher)
 	} else {
 		metricsCollector = metrics.NewCollector(db, nil)
 	}
+	metricsCollector.SetAssignmentCacheStats(svc)
 	logger.Info("metrics collector initialized")
 
-	// Initialize response cache (uses same Redis connection)
-	var responseCache *cache.Cache
-	if redisURL != "" {
-		var err error
-		responseCache, err = cache.New(redisURL, logger)
+	// Initialize response cache: Redis (shared across instances) when
+	// configured, otherwise an in-process LRU. ICMPMON_RESPONSE_CACHE
+	// forces redis, memory or off.
+	var responseCache cache.Cache
+	cacheBackend := os.Getenv("ICMPMON_RESPONSE_CACHE")
+	if cacheBackend == "" {
+		cacheBackend = "memory"
+		if redisURL != "" {
+			cacheBackend = "redis"
+		}
+	}
+	cacheEntries := config.CacheMemoryMaxEntries
+	if v := os.Getenv("ICMPMON_RESPONSE_CACHE_MAX_ENTRIES"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			logger.Error("invalid ICMPMON_RESPONSE_CACHE_MAX_ENTRIES (want a positive integer)", "value", v)
+			os.Exit(1)
+		}
+		cacheEntries = n
+	}
+	switch cacheBackend {
+	case "redis":
+		if redisURL == "" {
+			logger.Error("ICMPMON_RESPONSE_CACHE=redis requires ICMPMON_REDIS_URL")
+			os.Exit(1)
+		}
+		redisCache, err := cache.NewRedis(redisURL, logger)
 		if err != nil {
-			logger.Warn("response cache disabled - connection failed", "error", err)
+			logger.Warn("redis response cache unavailable, using in-memory cache", "error", err)
+			responseCache = cache.NewMemory(cacheEntries)
 		} else {
-			logger.Info("response cache enabled")
+			responseCache = redisCache
+			logger.Info("response cache enabled", "backend", "redis")
 		}
+	case "memory":
+		responseCache = cache.NewMemory(cacheEntries)
+		logger.Info("response cache enabled", "backend", "memory", "max_entries", cacheEntries)
+	case "off":
+		logger.Info("response cache disabled")
+	default:
+		logger.Error("invalid ICMPMON_RESPONSE_CACHE (want redis, memory or off)", "value", cacheBackend)
+		os.Exit(1)
 	}
 
 	// Create API server
 	apiServer := api.NewServer(svc, metricsCollector, responseCache, logger)
 
+	// Operator tokens identify who made management changes in the audit log
+	// (name:role:token,...). Without them every change is audited as anonymous.
+	if spec := os.Getenv("ICMPMON_OPERATOR_TOKENS"); spec != "" {
+		tokens, err := api.ParseOperatorTokens(spec)
+		if err != nil {
+			logger.Error("invalid ICMPMON_OPERATOR_TOKENS", "error", err)
+			os.Exit(1)
+		}
+		apiServer.SetOperatorTokens(tokens)
+	}
+
 	// Initialize enrollment service (optional - only if secrets backend is configured)
 	keyStore, err := secrets.NewKeyStore(secrets.ConfigFromEnv(), logger)
 	if err != nil {
@@ -185,21 +349,24 @@ func main() {
 
 	// Initialize state worker for monitoring state transitions
 	stateStoreAdapter := &storeStateAdapter{db: db}
-	stateWorker := worker.NewStateWorker(stateStoreAdapter, worker.DefaultStateWorkerConfig(), logger)
-	stateWorker.Start(context.Background())
-	defer stateWorker.Stop()
+	stateConfig := workerConfig(worker.StateWorkerConfigFromEnv, logger)
+	svc.SetNeverRespondedPolicy(stateConfig.NeverResponded)
+	stateWorker := worker.NewStateWorker(stateStoreAdapter, stateConfig, logger)
+	stateSingleton := startSingleton("state_worker", stateWorker, lockSession, logger)
+	defer stateSingleton.Stop()
 	logger.Info("state worker started")
 
 	// Initialize assignment worker for automatic redistribution
 	rebalancer := service.NewRebalancer(db, logger)
+	svc.SetRebalancer(rebalancer)
 	assignmentWorker := worker.NewAssignmentWorker(
 		db,
 		rebalancer,
-		worker.DefaultAssignmentWorkerConfig(),
+		workerConfig(worker.AssignmentWorkerConfigFromEnv, logger),
 		logger,
 	)
-	assignmentWorker.Start(context.Background())
-	defer assignmentWorker.Stop()
+	assignmentSingleton := startSingleton("assignment_worker", assignmentWorker, lockSession, logger)
+	defer assignmentSingleton.Stop()
 	logger.Info("assignment worker started")
 
 	// Register assignment management routes
@@ -211,11 +378,11 @@ func main() {
 	evaluatorStoreAdapter := &storeEvaluatorAdapter{db: db}
 	evaluatorWorker := worker.NewEvaluatorWorker(
 		evaluatorStoreAdapter,
-		worker.DefaultEvaluatorWorkerConfig(),
+		workerConfig(worker.EvaluatorWorkerConfigFromEnv, logger),
 		logger,
 	)
-	evaluatorWorker.Start(context.Background())
-	defer evaluatorWorker.Stop()
+	evaluatorSingleton := startSingleton("evaluator_worker", evaluatorWorker, lockSession, logger)
+	defer evaluatorSingleton.Stop()
 	logger.Info("evaluator worker started")
 
 	// Initialize alert worker for evolving alerts and incident correlation
@@ -223,13 +390,134 @@ func main() {
 	alertWorker := worker.NewAlertWorker(
 		alertStoreAdapter,
 		alertStoreAdapter, // Store implements both AlertStore and IncidentStore interfaces
-		worker.DefaultAlertWorkerConfig(),
+		workerConfig(worker.AlertWorkerConfigFromEnv, logger),
 		logger,
 	)
-	alertWorker.Start(context.Background())
-	defer alertWorker.Stop()
+	// Webhooks are configured through alert_config at runtime, so the
+	// notifier always runs and does nothing until webhook_urls is set
+	webhookNotifier := notify.NewWebhookNotifier(db, notify.DefaultWebhookConfig(), logger)
+	webhookNotifier.Start(context.Background())
+	defer webhookNotifier.Stop()
+	notifiers := notify.NewFanout(webhookNotifier)
+	emailConfig, err := notify.EmailConfigFromEnv()
+	if err != nil {
+		logger.Warn("alert email disabled - invalid configuration", "error", err)
+	} else if emailConfig.Enabled() {
+		emailNotifier, err := notify.NewEmailNotifier(emailConfig, logger)
+		if err != nil {
+			logger.Warn("alert email disabled - invalid templates", "error", err)
+		} else {
+			emailNotifier.Start(context.Background())
+			defer emailNotifier.Stop()
+			notifiers.Add(emailNotifier)
+			logger.Info("alert email notifications enabled", "digest_interval", emailConfig.DigestInterval)
+		}
+	}
+	digestConfig, err := notify.DigestConfigFromEnv()
+	if err != nil {
+		logger.Warn("alert digest using defaults - invalid configuration", "error", err)
+		digestConfig = notify.DefaultDigestConfig()
+	}
+	digester := notify.NewDigester(notifiers, digestConfig, logger)
+	digester.Start(context.Background())
+	defer digester.Stop()
+	alertWorker.SetNotifier(digester)
+	logger.Info("alert notifications enabled",
+		"notifiers", notifiers.Len(),
+		"digest_threshold", digestConfig.Threshold,
+		"digest_window", digestConfig.Window,
+	)
+	alertSingleton := startSingleton("alert_worker", alertWorker, lockSession, logger)
+	defer alertSingleton.Stop()
 	logger.Info("alert worker started")
 
+	// Initialize report worker for scheduled report delivery
+	mailConfig := mail.ConfigFromEnv()
+	reportWorker := worker.NewReportWorker(
+		db,
+		report.NewSender(mailConfig),
+		workerConfig(worker.ReportWorkerConfigFromEnv, logger),
+		logger,
+	)
+	defer startSingleton("report_worker", reportWorker, lockSession, logger).Stop()
+	logger.Info("report worker started", "smtp_enabled", mailConfig.Enabled())
+
+	// Initialize retention worker to preserve raw results for targets with a
+	// retention override beyond the probe_results policy
+	retentionWorker := worker.NewRetentionWorker(db, workerConfig(worker.RetentionWorkerConfigFromEnv, logger), logger)
+	defer startSingleton("retention_worker", retentionWorker, lockSession, logger).Stop()
+
+	// Initialize canary watchdog to alert when the pipeline canary's results
+	// stop landing or being evaluated
+	canaryWatchdog := worker.NewCanaryWatchdog(db, rebalancer, workerConfig(worker.CanaryWatchdogConfigFromEnv, logger), logger)
+	defer startSingleton("canary_watchdog", canaryWatchdog, lockSession, logger).Stop()
+
+	// Initialize coverage watchdog to alert when targets have fewer reporting
+	// agents than their tier requires
+	coverageWatchdog := worker.NewCoverageWatchdog(db, workerConfig(worker.CoverageWatchdogConfigFromEnv, logger), logger)
+	defer startSingleton("coverage_watchdog", coverageWatchdog, lockSession, logger).Stop()
+
+	// Initialize certificate expiry watchdog to alert when tls_cert targets'
+	// certificates near expiry
+	certExpiryWatchdog := worker.NewCertExpiryWatchdog(db, workerConfig(worker.CertExpiryWatchdogConfigFromEnv, logger), logger)
+	defer startSingleton("cert_expiry_watchdog", certExpiryWatchdog, lockSession, logger).Stop()
+
+	// Initialize endpoint family watchdog to alert when one address family
+	// of a dual-stacked target fails while another answers
+	endpointFamilyWatchdog := worker.NewEndpointFamilyWatchdog(db, workerConfig(worker.EndpointFamilyWatchdogConfigFromEnv, logger), logger)
+	defer startSingleton("endpoint_family_watchdog", endpointFamilyWatchdog, lockSession, logger).Stop()
+
+	// Initialize latency asymmetry watchdog to alert when one agent region
+	// sees a target far slower than another
+	latencyAsymmetryWatchdog := worker.NewLatencyAsymmetryWatchdog(db, workerConfig(worker.LatencyAsymmetryWatchdogConfigFromEnv, logger), logger)
+	defer startSingleton("latency_asymmetry_watchdog", latencyAsymmetryWatchdog, lockSession, logger).Stop()
+
+	// Initialize ship lag watchdog to alert when an agent's results arrive
+	// long after they were probed
+	shipLagWatchdog := worker.NewShipLagWatchdog(db, workerConfig(worker.ShipLagWatchdogConfigFromEnv, logger), logger)
+	defer startSingleton("ship_lag_watchdog", shipLagWatchdog, lockSession, logger).Stop()
+
+	// Initialize ASN enrichment (optional - only if an IP-to-ASN dataset is
+	// configured) to tag targets with their origin ASN and country
+	if asnPath := os.Getenv("ICMPMON_ASN_DATABASE"); asnPath != "" {
+		asnDB, err := ipasn.Open(asnPath)
+		if err != nil {
+			logger.Error("failed to load ASN dataset", "path", asnPath, "error", err)
+			os.Exit(1)
+		}
+		asnEnrichment := worker.NewASNEnrichmentWorker(db, asnDB, workerConfig(worker.ASNEnrichmentConfigFromEnv, logger), logger)
+		defer startSingleton("asn_enrichment", asnEnrichment, lockSession, logger).Stop()
+	}
+
+	// Initialize event dispatcher to sequence the outbox and push events to
+	// webhook and Kafka consumers
+	eventSinks := worker.EventSinks{types.EventConsumerWebhook: worker.NewWebhookEventSink()}
+	if kafkaProducer != nil {
+		eventSinks[types.EventConsumerKafka] = kafkaProducer
+		if kafkaConfig.EventsTopic != "" {
+			feed := &types.EventConsumer{
+				Name:    "kafka",
+				Kind:    types.EventConsumerKafka,
+				URL:     kafkaConfig.EventsTopic,
+				Enabled: true,
+			}
+			if err := svc.EnsureEventConsumer(context.Background(), feed); err != nil {
+				logger.Error("kafka events feed disabled", "error", err)
+			}
+		}
+	}
+	eventDispatcher := worker.NewEventDispatcher(db, eventSinks, workerConfig(worker.EventDispatcherConfigFromEnv, logger), logger)
+	eventDispatcher.Start(context.Background())
+	defer eventDispatcher.Stop()
+
+	// Initialize route worker to detect hop-count changes from reply TTLs
+	routeConfig := workerConfig(worker.RouteWorkerConfigFromEnv, logger)
+	if v := os.Getenv("ICMPMON_ROUTE_CHANGE_ALERTS"); v == "true" || v == "1" {
+		routeConfig.RaiseAlerts = true
+	}
+	routeWorker := worker.NewRouteWorker(db, routeConfig, logger)
+	defer startSingleton("route_worker", routeWorker, lockSession, logger).Stop()
+
 	// Initialize Pilot sync worker (optional - only if API credentials are configured)
 	fdAPIURL := os.Getenv("FD_API_URL")
 	fdBearer := os.Getenv("FD_BEARER")
@@ -241,19 +529,30 @@ func main() {
 		}, logger)
 
 		pilotSyncStore := &storePilotSyncAdapter{db: db}
+		pilotSyncConfig := workerConfig(worker.PilotSyncConfigFromEnv, logger)
+		if v := os.Getenv("ICMPMON_SERVICE_STATUS_ALERTS"); v == "true" || v == "1" {
+			pilotSyncConfig.ServiceStatusAlerts = true
+		}
 		pilotSyncWorker := worker.NewPilotSyncWorker(
 			pilotClient,
 			pilotSyncStore,
-			worker.DefaultPilotSyncConfig(),
+			pilotSyncConfig,
 			logger,
 		)
-		pilotSyncWorker.Start(context.Background())
-		defer pilotSyncWorker.Stop()
+		defer startSingleton("pilot_sync", pilotSyncWorker, lockSession, logger).Stop()
 		logger.Info("pilot sync worker started", "max_subnets", "unlimited")
 	} else {
 		logger.Info("pilot sync disabled - FD_API_URL and FD_BEARER not set")
 	}
 
+	// Readiness: only take traffic once the database, schema and core workers are up
+	apiServer.AddReadinessCheck("database", db.Ping)
+	apiServer.AddReadinessCheck("migrations", migrationsApplied(db.Pool()))
+	apiServer.AddReadinessCheck("state_worker", workerReady(stateSingleton))
+	apiServer.AddReadinessCheck("assignment_worker", workerReady(assignmentSingleton))
+	apiServer.AddReadinessCheck("evaluator_worker", workerReady(evaluatorSingleton))
+	apiServer.AddReadinessCheck("alert_worker", workerReady(alertSingleton))
+
 	// Create HTTP server
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", *port),
@@ -262,6 +561,7 @@ func main() {
 		WriteTimeout: 30 * time.Second,
 		IdleTimeout:  120 * time.Second,
 	}
+	server.RegisterOnShutdown(apiServer.CloseStreams)
 
 	// Start server
 	go func() {
@@ -297,6 +597,11 @@ func main() {
 		logger.Error("shutdown error", "error", err)
 	}
 
+	// Publish results still queued for Kafka now that ingestion has stopped
+	if kafkaProducer != nil {
+		kafkaProducer.Close()
+	}
+
 	logger.Info("shutdown complete")
 }
 
@@ -619,24 +924,24 @@ type storeStateAdapter struct {
 	db *store.Store
 }
 
-func (a *storeStateAdapter) GetTargetsForDownTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
-	return a.db.GetTargetsForDownTransition(ctx, threshold)
+func (a *storeStateAdapter) GetTargetsForDownTransition(ctx context.Context, now time.Time, defaultThreshold time.Duration) ([]types.Target, error) {
+	return a.db.GetTargetsForDownTransition(ctx, now, defaultThreshold)
 }
 
-func (a *storeStateAdapter) GetTargetsForUnresponsiveTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
-	return a.db.GetTargetsForUnresponsiveTransition(ctx, threshold)
+func (a *storeStateAdapter) GetTargetsForUnresponsiveTransition(ctx context.Context, cutoff time.Time) ([]types.Target, error) {
+	return a.db.GetTargetsForUnresponsiveTransition(ctx, cutoff)
 }
 
-func (a *storeStateAdapter) GetTargetsForExcludedTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
-	return a.db.GetTargetsForExcludedTransition(ctx, threshold)
+func (a *storeStateAdapter) GetTargetsForExcludedTransition(ctx context.Context, cutoff time.Time) ([]types.Target, error) {
+	return a.db.GetTargetsForExcludedTransition(ctx, cutoff)
 }
 
 func (a *storeStateAdapter) GetTargetsForSmartRecheck(ctx context.Context) ([]types.Target, error) {
 	return a.db.GetTargetsForSmartRecheck(ctx)
 }
 
-func (a *storeStateAdapter) GetTargetsForBaselineCheck(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
-	return a.db.GetTargetsForBaselineCheck(ctx, threshold)
+func (a *storeStateAdapter) GetTargetsForBaselineCheck(ctx context.Context, cutoff time.Time) ([]types.Target, error) {
+	return a.db.GetTargetsForBaselineCheck(ctx, cutoff)
 }
 
 func (a *storeStateAdapter) TransitionTargetState(ctx context.Context, targetID string, newState types.MonitoringState, reason, triggeredBy string) error {
@@ -667,6 +972,14 @@ func (a *storeStateAdapter) PromoteStandbyToRepresentative(ctx context.Context,
 	return a.db.PromoteStandbyToRepresentative(ctx, subnetID)
 }
 
+func (a *storeStateAdapter) GetTargetsNeverResponded(ctx context.Context, cutoff time.Time, minAttempts, limit int) ([]types.Target, error) {
+	return a.db.GetTargetsNeverResponded(ctx, cutoff, minAttempts, limit)
+}
+
+func (a *storeStateAdapter) ArchiveTargetBy(ctx context.Context, targetID, reason, triggeredBy string) error {
+	return a.db.ArchiveTargetBy(ctx, targetID, reason, triggeredBy)
+}
+
 // =============================================================================
 // PILOT SYNC STORE ADAPTER
 // =============================================================================
@@ -720,6 +1033,18 @@ func (a *storePilotSyncAdapter) ResolveAlertsBySubnet(ctx context.Context, subne
 	return a.db.ResolveAlertsBySubnet(ctx, subnetID, reason)
 }
 
+func (a *storePilotSyncAdapter) ResolveIncidentsBySubnet(ctx context.Context, subnetID string, reason string) (int, error) {
+	return a.db.ResolveIncidentsBySubnet(ctx, subnetID, reason)
+}
+
+func (a *storePilotSyncAdapter) LogSubnetActivity(ctx context.Context, subnetID, eventType, triggeredBy, severity string, details map[string]interface{}) error {
+	return a.db.LogSubnetActivity(ctx, subnetID, eventType, triggeredBy, severity, details)
+}
+
+func (a *storePilotSyncAdapter) CreateAlert(ctx context.Context, alert *types.Alert) error {
+	return a.db.CreateAlert(ctx, alert)
+}
+
 // =============================================================================
 // ALERT WORKER STORE ADAPTER
 // =============================================================================
@@ -782,10 +1107,30 @@ func (a *storeAlertAdapter) LinkAlertToIncident(ctx context.Context, alertID, in
 	return a.db.LinkAlertToIncident(ctx, alertID, incidentID)
 }
 
+func (a *storeAlertAdapter) UpdateAlertSummary(ctx context.Context, alertID, title, message string) error {
+	return a.db.UpdateAlertSummary(ctx, alertID, title, message)
+}
+
+func (a *storeAlertAdapter) GetSubnet(ctx context.Context, id string) (*types.Subnet, error) {
+	return a.db.GetSubnet(ctx, id)
+}
+
 func (a *storeAlertAdapter) GetUnlinkedAlertsByCorrelation(ctx context.Context, correlationKey string, window time.Duration) ([]types.Alert, error) {
 	return a.db.GetUnlinkedAlertsByCorrelation(ctx, correlationKey, window)
 }
 
+func (a *storeAlertAdapter) ListEscalationPolicies(ctx context.Context, enabledOnly bool) ([]types.EscalationPolicy, error) {
+	return a.db.ListEscalationPolicies(ctx, enabledOnly)
+}
+
+func (a *storeAlertAdapter) ListUnacknowledgedAlerts(ctx context.Context, limit int) ([]types.Alert, error) {
+	return a.db.ListUnacknowledgedAlerts(ctx, limit)
+}
+
+func (a *storeAlertAdapter) AdvanceAlertEscalation(ctx context.Context, alertID, policyID string, step int, severity types.AlertSeverity, description string) (bool, error) {
+	return a.db.AdvanceAlertEscalation(ctx, alertID, policyID, step, severity, description)
+}
+
 func (a *storeAlertAdapter) GetAlertConfigInt(ctx context.Context, key string, defaultVal int) (int, error) {
 	return a.db.GetAlertConfigInt(ctx, key, defaultVal)
 }
@@ -835,3 +1180,39 @@ func (a *storeEvaluatorAdapter) BulkUpsertAgentTargetStates(ctx context.Context,
 func (a *storeEvaluatorAdapter) UpsertBaseline(ctx context.Context, baseline *store.AgentTargetBaseline) error {
 	return a.db.UpsertBaseline(ctx, baseline)
 }
+
+func (a *storeEvaluatorAdapter) RecalculateAllBaselines(ctx context.Context) (int, error) {
+	return a.db.RecalculateAllBaselines(ctx)
+}
+
+func (a *storeEvaluatorAdapter) GetBaselineDrift(ctx context.Context, lookback time.Duration, minIncreasePct, minIncreaseMs float64) ([]store.BaselineDrift, error) {
+	return a.db.GetBaselineDrift(ctx, lookback, minIncreasePct, minIncreaseMs)
+}
+
+func (a *storeEvaluatorAdapter) GetAlertConfigInt(ctx context.Context, key string, defaultVal int) (int, error) {
+	return a.db.GetAlertConfigInt(ctx, key, defaultVal)
+}
+
+func (a *storeEvaluatorAdapter) GetAlertConfigFloat(ctx context.Context, key string, defaultVal float64) (float64, error) {
+	return a.db.GetAlertConfigFloat(ctx, key, defaultVal)
+}
+
+func (a *storeEvaluatorAdapter) CreateAlert(ctx context.Context, alert *types.Alert) error {
+	return a.db.CreateAlert(ctx, alert)
+}
+
+func (a *storeEvaluatorAdapter) FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error) {
+	return a.db.FindActiveAlertForTarget(ctx, targetID, alertType, agentID)
+}
+
+func (a *storeEvaluatorAdapter) UpdateAlertSummary(ctx context.Context, alertID, title, message string) error {
+	return a.db.UpdateAlertSummary(ctx, alertID, title, message)
+}
+
+func (a *storeEvaluatorAdapter) ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error) {
+	return a.db.ListAlerts(ctx, filter)
+}
+
+func (a *storeEvaluatorAdapter) ResolveAlert(ctx context.Context, alertID string, description string) error {
+	return a.db.ResolveAlert(ctx, alertID, description)
+}
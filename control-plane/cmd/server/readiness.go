@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/api"
+	"github.com/pilot-net/icmp-mon/db/migrate"
+)
+
+// migrationsApplied is ready once no embedded migration is pending. Once
+// satisfied it stays ready, so readiness probes don't query the schema
+// table forever.
+func migrationsApplied(pool *pgxpool.Pool) api.ReadinessCheck {
+	var done atomic.Bool
+	return func(ctx context.Context) error {
+		if done.Load() {
+			return nil
+		}
+		status, err := migrate.GetStatus(ctx, pool)
+		if err != nil {
+			return fmt.Errorf("checking migrations: %w", err)
+		}
+		if len(status.Pending) > 0 {
+			return fmt.Errorf("%d migrations pending", len(status.Pending))
+		}
+		done.Store(true)
+		return nil
+	}
+}
+
+// workerReady is ready once the worker has completed its first cycle.
+func workerReady(w interface{ Ready() bool }) api.ReadinessCheck {
+	return func(ctx context.Context) error {
+		if !w.Ready() {
+			return errors.New("waiting for first cycle")
+		}
+		return nil
+	}
+}
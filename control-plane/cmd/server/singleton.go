@@ -0,0 +1,16 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/worker"
+)
+
+// startSingleton starts w on whichever control plane instance holds its
+// lock in locks. Stop the returned singleton rather than w.
+func startSingleton(name string, w worker.SingletonWorker, locks worker.SingletonLocker, logger *slog.Logger) *worker.Singleton {
+	s := worker.NewSingleton(name, w, locks, worker.DefaultSingletonConfig(), logger)
+	s.Start(context.Background())
+	return s
+}
@@ -0,0 +1,18 @@
+package main
+
+import (
+	"log/slog"
+	"os"
+)
+
+// workerConfig loads a worker's config from its defaults and environment
+// overrides, exiting on an invalid override rather than running a worker
+// tuned differently from what the operator asked for.
+func workerConfig[C any](fromEnv func() (C, error), logger *slog.Logger) C {
+	cfg, err := fromEnv()
+	if err != nil {
+		logger.Error("invalid worker configuration", "error", err)
+		os.Exit(1)
+	}
+	return cfg
+}
@@ -8,44 +8,148 @@
 //   - GET  /api/v1/agents/{id}/assignments - Get assignments
 //   - GET  /api/v1/agents/{id}/commands - Poll for commands
 //   - POST /api/v1/agents/{id}/commands/{cmd}/result - Report command result
+//   - GET  /api/v1/agents/{id}/config - Effective remote config to merge over the local one ({version, config})
 //
 // Management API:
 //   - GET  /api/v1/agents - List agents
+//   - GET  /api/v1/agents/anomalies - Rank agents by open anomalies/alerts, flag likely agent-side issues
 //   - GET  /api/v1/agents/{id} - Get agent details
 //   - PUT  /api/v1/agents/{id} - Update agent info
 //   - GET  /api/v1/agents/{id}/metrics - Get agent metrics history
 //   - GET  /api/v1/agents/{id}/stats - Get agent current stats
+//   - GET  /api/v1/agents/{id}/assignments/diff - Net assignment changes between versions (?from defaults to the agent's last-reported version, ?to to current)
 //   - POST /api/v1/agents/{id}/archive - Archive agent (soft-delete)
 //   - POST /api/v1/agents/{id}/unarchive - Restore archived agent
+//   - GET  /api/v1/agent-config - Remote config every agent receives
+//   - PUT  /api/v1/agent-config - Replace the global remote config (log_level, schedule_alignment, *_interval_s, feature_flags)
+//   - PUT  /api/v1/agents/{id}/config - Replace an agent's overrides of the global config
+//   - DELETE /api/v1/agents/{id}/config - Remove an agent's overrides
+//   - GET  /api/v1/agents/{id}/config/status - Effective config, its layers and whether the agent has applied it
 //   - GET  /api/v1/fleet/overview - Get fleet overview stats
 //   - GET  /api/v1/fleet/agents/stats - Get all agents current stats
-//   - GET  /api/v1/targets - List targets
-//   - POST /api/v1/targets - Create target
+//   - GET  /api/v1/fleet/providers - Compare agent health and probe performance by provider (?window=24h)
+//   - GET  /api/v1/accounting/targets - Probe counts per target by period (?period=day|week|month, ?start, ?end, ?target_id)
+//   - GET  /api/v1/accounting/agents - Probe counts per agent by period (?period, ?start, ?end, ?agent_id)
+//   - GET  /api/v1/targets - List targets (?limit/offset or ?cursor for keyset pages)
+//   - POST /api/v1/targets - Create target (on_duplicate: reject, return or merge an existing target with the IP)
+//   - POST /api/v1/targets/bulk - Create up to 5000 targets in one transaction, skipping taken IPs (per-row created/skipped_duplicate/error)
+//   - POST /api/v1/targets/tier/bulk - Move targets matching a filter to a tier ({filter, tier} -> {tier, changed})
+//   - GET  /api/v1/certificates - Latest certificate of each tls_cert target, soonest expiry first (?within_days, ?limit)
 //   - GET  /api/v1/tiers - List tiers
+//   - POST /api/v1/tiers/{name}/preview - Project probes/sec and per-agent load at a proposed interval ({probe_interval_seconds})
+//   - GET  /api/v1/config/alert - Every alert tunable with its effective value and default, and every alert_config key
+//   - GET  /api/v1/config/archival - When auto targets that never answer discovery are archived
+//
+// Health Exclusion API (agent results left out of health and alerting):
+//   - GET    /api/v1/health-exclusions - List exclusions (?agent_id)
+//   - POST   /api/v1/health-exclusions - Exclude an agent for a target or subnet
+//   - GET    /api/v1/health-exclusions/{id} - Get exclusion
+//   - PUT    /api/v1/health-exclusions/{id} - Update exclusion reason
+//   - DELETE /api/v1/health-exclusions/{id} - Remove exclusion
+//
+// Affinity Rule API (tag-based constraints on which agents probe which targets):
+//   - GET    /api/v1/affinity-rules - List rules in application order
+//   - POST   /api/v1/affinity-rules - Create rule ({rule, check}; check counts targets left without an agent)
+//   - GET    /api/v1/affinity-rules/{id} - Get rule
+//   - PUT    /api/v1/affinity-rules/{id} - Update rule
+//   - DELETE /api/v1/affinity-rules/{id} - Remove rule
+//   - GET    /api/v1/affinity-rules/{id}/check - Re-check rule against the current fleet
+//
+// Escalation Policy API (re-notify and raise severity of unacknowledged alerts):
+//   - GET    /api/v1/escalation-policies - List policies by name
+//   - POST   /api/v1/escalation-policies - Create policy
+//   - GET    /api/v1/escalation-policies/{id} - Get policy
+//   - PUT    /api/v1/escalation-policies/{id} - Update policy
+//   - DELETE /api/v1/escalation-policies/{id} - Remove policy
 //
 // Subnet API:
-//   - GET    /api/v1/subnets - List all subnets
+//   - GET    /api/v1/subnets - List all subnets (?limit/offset or ?cursor for keyset pages)
 //   - POST   /api/v1/subnets - Create subnet
 //   - GET    /api/v1/subnets/{id} - Get subnet details
 //   - PUT    /api/v1/subnets/{id} - Update subnet
 //   - POST   /api/v1/subnets/{id}/archive - Archive subnet
 //   - GET    /api/v1/subnets/{id}/targets - List targets in subnet
 //   - GET    /api/v1/subnets/{id}/stats - Get subnet target counts
+//   - GET    /api/v1/subnets/{id}/coverage-gaps - Active targets without a reporting or in-market agent
+//   - POST   /api/v1/subnets/{id}/sweep - Ping-sweep the subnet for live addresses (optionally seed them)
 //
 // Target State API:
 //   - GET    /api/v1/targets/review - List targets needing review
 //   - POST   /api/v1/targets/{id}/state - Transition target state
 //   - POST   /api/v1/targets/{id}/acknowledge - Acknowledge target
+//   - POST   /api/v1/targets/{id}/disable - Stop probing without archiving
+//   - POST   /api/v1/targets/{id}/enable - Resume probing
 //   - GET    /api/v1/targets/{id}/state-history - Get state transition history
+//   - GET    /api/v1/targets/{id}/hops - Get hop-count history and route changes
+//   - GET    /api/v1/targets/status - Status of every target with its health score (?sort=ip|health_score|-health_score)
+//   - GET    /api/v1/targets/{id}/live/stream - Server-Sent Events of new probe results, from the last 30s on
+//   - GET    /api/v1/targets/{id}/worst-agents - Agents ranked by loss then latency (?window=15m, ?limit=20)
+//   - GET    /api/v1/targets/{id}/annotations - Manual and incident annotations (?window=24h)
+//   - POST   /api/v1/targets/{id}/annotations - Annotate a point in time or range (starts_at, ends_at, text)
+//   - DELETE /api/v1/targets/{id}/annotations/{annotation_id} - Remove a manual annotation
+//
+// Target Endpoint API (extra addresses/ports, e.g. IPv6 of a dual-stacked host):
+//   - GET    /api/v1/targets/{id}/endpoints - List a target's additional endpoints
+//   - POST   /api/v1/targets/{id}/endpoints - Add an endpoint (ip, port, label)
+//   - DELETE /api/v1/targets/{id}/endpoints/{endpoint_id} - Remove an endpoint
+//   - GET    /api/v1/targets/{id}/endpoints/status - Per-endpoint and per-family state (?window=5m)
+//   - GET    /api/v1/targets/{id}/history/by-endpoint - History per endpoint, primary IP included (?window=1h)
+//
+// History responses (/targets/{id}/history, /history/by-agent and
+// /history/in-market) include the window's annotations for chart overlays.
 //
 // Results API:
-//   - POST /api/v1/results - Ingest probe results
+//   - POST /api/v1/results - Ingest probe results, JSON or application/x-protobuf ({accepted, rejected, reasons})
+//   - GET  /api/v1/targets/{id}/results - Raw probe results, newest first (?cursor, ?probe_type, ?reply_ttl)
+//
+// Forecast API:
+//   - GET /api/v1/targets/{id}/forecast - Projected latency trend and threshold breach (?horizon=24h)
+//
+// Availability API:
+//   - GET /api/v1/targets/{id}/availability - Success ratio, in-market success ratio and MTBF over 1h, 24h, 7d and 30d
+//
+// Event Stream API (outbox of alert, incident and target state events):
+//   - GET    /api/v1/events - Events after a position, oldest first (?since, type, limit -> {events, next})
+//   - GET    /api/v1/event-consumers - List push consumers with offsets and delivery state
+//   - POST   /api/v1/event-consumers - Add a webhook or kafka consumer ({name, kind, url (the topic for kafka), event_types, start_seq})
+//   - GET    /api/v1/event-consumers/{name} - Get consumer
+//   - DELETE /api/v1/event-consumers/{name} - Remove consumer
+//   - POST   /api/v1/event-consumers/{name}/seek - Move the offset to replay or skip ({seq})
+//
+// Metrics Query API:
+//   - POST /api/v1/metrics/query - Flexible metrics query (MetricsQuery JSON body)
+//   - GET  /api/v1/metrics/query - Same query from URL params (?metrics, group_by, window|start,end, bucket, limit,
+//     agent_id, agent_region, agent_provider, agent_tag=k:v, target_id, target_tier, target_region, target_tag=k:v)
+//
+// Grafana JSON Data Source API (data source URL /api/v1/grafana):
+//   - GET  /api/v1/grafana/ - Connection test
+//   - POST /api/v1/grafana/search - Metrics, group_by dimensions, tiers, agents or targets[:text] ({target})
+//   - POST /api/v1/grafana/query - One series per panel target; target is the metric, payload holds filters and group_by
+//   - POST /api/v1/grafana/annotations - Incidents and operator annotations in range (query: incidents, annotations or empty)
+//
+// Diagnostics API:
+//   - GET /api/v1/diagnostics/target/{id} - Triage bundle: status, latest result per agent, 1h history,
+//     active alerts, baselines, recent commands and subnet summary (failed sections listed in errors)
+//   - POST /api/v1/targets/{id}/pmtud - Find the path MTU from agents ({agent_ids, min_mtu, max_mtu}; results via /commands/{id})
+//
+// Incident API:
+//   - GET /api/v1/incidents/{id}/postmortem - Review document: timeline, alerts, peaks, probe history (?format=markdown)
+//   - DELETE /api/v1/incidents/{id} - Purge a resolved test/noise incident, unlinking its alerts (admin operator token required)
+//
+// Audit API (admin operator token required):
+//   - GET /api/v1/audit - Management mutations, newest first (?actor, entity_type, entity_id, since, until, limit)
 //
 // Health:
-//   - GET /api/v1/health - Health check
+//   - GET /api/v1/health/live  - Liveness: process is serving requests
+//   - GET /api/v1/health/ready - Readiness: DB, migrations and workers are up (503 otherwise)
+//   - GET /api/v1/health       - Legacy health check, same as live
+//
+// Prometheus:
+//   - GET /metrics - Target up, latency and loss per agent region, and fleet gauges (text format)
 package api
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"fmt"
@@ -54,11 +158,13 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pilot-net/icmp-mon/control-plane/internal/cache"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/metrics"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/report"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
 	"github.com/pilot-net/icmp-mon/pkg/types"
@@ -68,22 +174,33 @@ import (
 type Server struct {
 	svc              *service.Service
 	metricsCollector *metrics.Collector
-	cache            *cache.Cache
+	cache            cache.Cache
 	logger           *slog.Logger
 	mux              *http.ServeMux
 
 	// Agent authentication (disabled by default for grace period)
 	agentAuthEnabled bool
+
+	// readinessChecks gate GET /api/v1/health/ready
+	readinessChecks []namedReadinessCheck
+
+	// operatorTokens identify management API callers (see SetOperatorTokens)
+	operatorTokens []operatorToken
+
+	// streamsClosed is closed by CloseStreams to end live streams
+	streamsClosed    chan struct{}
+	closeStreamsOnce sync.Once
 }
 
 // NewServer creates a new API server.
-func NewServer(svc *service.Service, metricsCollector *metrics.Collector, responseCache *cache.Cache, logger *slog.Logger) *Server {
+func NewServer(svc *service.Service, metricsCollector *metrics.Collector, responseCache cache.Cache, logger *slog.Logger) *Server {
 	s := &Server{
 		svc:              svc,
 		metricsCollector: metricsCollector,
 		cache:            responseCache,
 		logger:           logger,
 		mux:              http.NewServeMux(),
+		streamsClosed:    make(chan struct{}),
 	}
 	s.registerRoutes()
 	return s
@@ -113,9 +230,13 @@ func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Log request
+	// Log request; management mutations also go to the audit log
 	start := time.Now()
-	s.mux.ServeHTTP(w, r)
+	if isAuditedRequest(r) {
+		s.serveAudited(w, r)
+	} else {
+		s.mux.ServeHTTP(w, r)
+	}
 	s.logger.Debug("request",
 		"method", r.Method,
 		"path", r.URL.Path,
@@ -129,10 +250,15 @@ func (s *Server) registerRoutes() {
 		Logger:  s.logger,
 	})
 
-	// Health
+	// Health: liveness (restart if failing) vs readiness (route traffic only if passing)
+	s.mux.HandleFunc("GET /api/v1/health/live", s.handleLive)
+	s.mux.HandleFunc("GET /api/v1/health/ready", s.handleReady)
 	s.mux.HandleFunc("GET /api/v1/health", s.handleHealth)
 	s.mux.HandleFunc("GET /api/v1/infrastructure/health", s.handleInfrastructureHealth)
 
+	// Prometheus scrape target
+	s.mux.HandleFunc("GET /metrics", s.handlePrometheusMetrics)
+
 	// Agent registration (open - no auth required, agents don't have keys yet)
 	s.mux.HandleFunc("POST /api/v1/agents/register", s.handleAgentRegister)
 
@@ -141,19 +267,30 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("GET /api/v1/agents/{id}/assignments", wrapHandler(s.handleAgentAssignments, agentAuth))
 	s.mux.HandleFunc("GET /api/v1/agents/{id}/commands", wrapHandler(s.handleAgentCommands, agentAuth))
 	s.mux.HandleFunc("POST /api/v1/agents/{id}/commands/{cmd}/result", wrapHandler(s.handleAgentCommandResult, agentAuth))
+	s.mux.HandleFunc("GET /api/v1/agents/{id}/config", wrapHandler(s.handleAgentConfig, agentAuth))
 
 	// Agent management
 	s.mux.HandleFunc("GET /api/v1/agents", s.handleListAgents)
+	s.mux.HandleFunc("GET /api/v1/agents/anomalies", s.handleGetAgentAnomalies)
 	s.mux.HandleFunc("GET /api/v1/agents/{id}", s.handleGetAgent)
 	s.mux.HandleFunc("PUT /api/v1/agents/{id}", s.handleUpdateAgent)
 	s.mux.HandleFunc("GET /api/v1/agents/{id}/metrics", s.handleAgentMetrics)
 	s.mux.HandleFunc("GET /api/v1/agents/{id}/stats", s.handleAgentStats)
+	s.mux.HandleFunc("GET /api/v1/agents/{id}/assignments/diff", s.handleAgentAssignmentDiff)
 	s.mux.HandleFunc("POST /api/v1/agents/{id}/archive", s.handleArchiveAgent)
 	s.mux.HandleFunc("POST /api/v1/agents/{id}/unarchive", s.handleUnarchiveAgent)
 
+	// Remote agent config
+	s.mux.HandleFunc("GET /api/v1/agent-config", s.handleGetGlobalAgentConfig)
+	s.mux.HandleFunc("PUT /api/v1/agent-config", s.handleSetGlobalAgentConfig)
+	s.mux.HandleFunc("PUT /api/v1/agents/{id}/config", s.handleSetAgentConfig)
+	s.mux.HandleFunc("DELETE /api/v1/agents/{id}/config", s.handleDeleteAgentConfig)
+	s.mux.HandleFunc("GET /api/v1/agents/{id}/config/status", s.handleGetAgentConfigStatus)
+
 	// Fleet overview
 	s.mux.HandleFunc("GET /api/v1/fleet/overview", s.handleFleetOverview)
 	s.mux.HandleFunc("GET /api/v1/fleet/agents/stats", s.handleAllAgentsStats)
+	s.mux.HandleFunc("GET /api/v1/fleet/providers", s.handleGetProviderHealth)
 
 	// Targets - static routes must come before wildcard {id} routes
 	s.mux.HandleFunc("GET /api/v1/targets", s.handleListTargets)
@@ -161,12 +298,27 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("GET /api/v1/targets/status", s.handleGetAllTargetStatuses)
 	s.mux.HandleFunc("GET /api/v1/targets/review", s.handleListTargetsNeedingReview)
 	s.mux.HandleFunc("GET /api/v1/targets/tag-keys", s.handleGetTargetTagKeys)
+	s.mux.HandleFunc("POST /api/v1/targets/bulk", s.handleBulkCreateTargets)
+	s.mux.HandleFunc("POST /api/v1/targets/tier/bulk", s.handleBulkReassignTier)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}", s.handleGetTarget)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}/status", s.handleGetTargetStatus)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}/history", s.handleGetTargetHistory)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/results", s.handleListTargetResults)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}/history/by-agent", s.handleGetTargetHistoryByAgent)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/history/in-market", s.handleGetTargetHistoryInMarket)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/worst-agents", s.handleGetTargetWorstAgents)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/annotations", s.handleListTargetAnnotations)
+	s.mux.HandleFunc("POST /api/v1/targets/{id}/annotations", s.handleCreateTargetAnnotation)
+	s.mux.HandleFunc("DELETE /api/v1/targets/{id}/annotations/{annotation_id}", s.handleDeleteTargetAnnotation)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/endpoints", s.handleListTargetEndpoints)
+	s.mux.HandleFunc("POST /api/v1/targets/{id}/endpoints", s.handleCreateTargetEndpoint)
+	s.mux.HandleFunc("DELETE /api/v1/targets/{id}/endpoints/{endpoint_id}", s.handleDeleteTargetEndpoint)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/endpoints/status", s.handleGetTargetEndpointStatus)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/history/by-endpoint", s.handleGetTargetHistoryByEndpoint)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}/live", s.handleGetTargetLive)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/live/stream", s.handleStreamTargetLive)
 	s.mux.HandleFunc("POST /api/v1/targets/{id}/mtr", s.handleTriggerMTR)
+	s.mux.HandleFunc("POST /api/v1/targets/{id}/pmtud", s.handleTriggerPMTUD)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}/commands", s.handleGetTargetCommands)
 
 	// Commands
@@ -177,6 +329,13 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("GET /api/v1/metrics/latency/in-market", s.handleGetInMarketLatencyTrend)
 	s.mux.HandleFunc("GET /api/v1/metrics/latency/matrix", s.handleGetLatencyMatrix)
 	s.mux.HandleFunc("POST /api/v1/metrics/query", s.handleQueryMetrics)
+	s.mux.HandleFunc("GET /api/v1/metrics/query", s.handleQueryMetricsGET)
+
+	// Grafana JSON data source
+	s.mux.HandleFunc("GET /api/v1/grafana/{$}", s.handleGrafanaTest)
+	s.mux.HandleFunc("POST /api/v1/grafana/search", s.handleGrafanaSearch)
+	s.mux.HandleFunc("POST /api/v1/grafana/query", s.handleGrafanaQuery)
+	s.mux.HandleFunc("POST /api/v1/grafana/annotations", s.handleGrafanaAnnotations)
 
 	// Tiers
 	s.mux.HandleFunc("GET /api/v1/tiers", s.handleListTiers)
@@ -184,6 +343,10 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("POST /api/v1/tiers", s.handleCreateTier)
 	s.mux.HandleFunc("PUT /api/v1/tiers/{name}", s.handleUpdateTier)
 	s.mux.HandleFunc("DELETE /api/v1/tiers/{name}", s.handleDeleteTier)
+	s.mux.HandleFunc("POST /api/v1/tiers/{name}/preview", s.handlePreviewTier)
+
+	// Audit log
+	s.mux.HandleFunc("GET /api/v1/audit", s.handleListAudit)
 
 	// Incidents
 	s.mux.HandleFunc("GET /api/v1/incidents", s.handleListIncidents)
@@ -191,15 +354,69 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("POST /api/v1/incidents/{id}/acknowledge", s.handleAcknowledgeIncident)
 	s.mux.HandleFunc("POST /api/v1/incidents/{id}/resolve", s.handleResolveIncident)
 	s.mux.HandleFunc("PUT /api/v1/incidents/{id}/notes", s.handleAddIncidentNote)
+	s.mux.HandleFunc("GET /api/v1/incidents/{id}/postmortem", s.handleGetIncidentPostmortem)
+	s.mux.HandleFunc("DELETE /api/v1/incidents/{id}", s.handleDeleteIncident)
 
 	// Baselines
 	s.mux.HandleFunc("GET /api/v1/baselines/{agent_id}/{target_id}", s.handleGetBaseline)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}/baselines", s.handleGetTargetBaselines)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/forecast", s.handleGetTargetForecast)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/availability", s.handleGetTargetAvailability)
 	s.mux.HandleFunc("POST /api/v1/baselines/recalculate", s.handleRecalculateBaselines)
+	s.mux.HandleFunc("POST /api/v1/targets/{id}/recalculate-baseline", s.handleRecalculateTargetBaselines)
 
 	// Reports
 	s.mux.HandleFunc("GET /api/v1/reports/targets/{id}", s.handleGetTargetReport)
 
+	// Scheduled reports
+	s.mux.HandleFunc("GET /api/v1/reports/schedules", s.handleListReportSchedules)
+	s.mux.HandleFunc("POST /api/v1/reports/schedules", s.handleCreateReportSchedule)
+	s.mux.HandleFunc("GET /api/v1/reports/schedules/{id}", s.handleGetReportSchedule)
+	s.mux.HandleFunc("PUT /api/v1/reports/schedules/{id}", s.handleUpdateReportSchedule)
+	s.mux.HandleFunc("DELETE /api/v1/reports/schedules/{id}", s.handleDeleteReportSchedule)
+	s.mux.HandleFunc("POST /api/v1/reports/schedules/{id}/run", s.handleRunReportSchedule)
+	s.mux.HandleFunc("GET /api/v1/reports/schedules/{id}/deliveries", s.handleListReportDeliveries)
+
+	// Agent health exclusions
+	s.mux.HandleFunc("GET /api/v1/health-exclusions", s.handleListHealthExclusions)
+	s.mux.HandleFunc("POST /api/v1/health-exclusions", s.handleCreateHealthExclusion)
+	s.mux.HandleFunc("GET /api/v1/health-exclusions/{id}", s.handleGetHealthExclusion)
+	s.mux.HandleFunc("PUT /api/v1/health-exclusions/{id}", s.handleUpdateHealthExclusion)
+	s.mux.HandleFunc("DELETE /api/v1/health-exclusions/{id}", s.handleDeleteHealthExclusion)
+
+	// Assignment affinity rules
+	s.mux.HandleFunc("GET /api/v1/affinity-rules", s.handleListAffinityRules)
+	s.mux.HandleFunc("POST /api/v1/affinity-rules", s.handleCreateAffinityRule)
+	s.mux.HandleFunc("GET /api/v1/affinity-rules/{id}", s.handleGetAffinityRule)
+	s.mux.HandleFunc("PUT /api/v1/affinity-rules/{id}", s.handleUpdateAffinityRule)
+	s.mux.HandleFunc("DELETE /api/v1/affinity-rules/{id}", s.handleDeleteAffinityRule)
+	s.mux.HandleFunc("GET /api/v1/affinity-rules/{id}/check", s.handleCheckAffinityRule)
+
+	// Alert escalation policies
+	s.mux.HandleFunc("GET /api/v1/escalation-policies", s.handleListEscalationPolicies)
+	s.mux.HandleFunc("POST /api/v1/escalation-policies", s.handleCreateEscalationPolicy)
+	s.mux.HandleFunc("GET /api/v1/escalation-policies/{id}", s.handleGetEscalationPolicy)
+	s.mux.HandleFunc("PUT /api/v1/escalation-policies/{id}", s.handleUpdateEscalationPolicy)
+	s.mux.HandleFunc("DELETE /api/v1/escalation-policies/{id}", s.handleDeleteEscalationPolicy)
+
+	// Probe accounting for capacity planning and billing
+	s.mux.HandleFunc("GET /api/v1/accounting/targets", s.handleGetTargetAccounting)
+	s.mux.HandleFunc("GET /api/v1/accounting/agents", s.handleGetAgentAccounting)
+
+	// TLS certificates seen by tls_cert checks
+	s.mux.HandleFunc("GET /api/v1/certificates", s.handleListCertificates)
+
+	// Event stream for integrations
+	s.mux.HandleFunc("GET /api/v1/events", s.handleListEvents)
+	s.mux.HandleFunc("GET /api/v1/event-consumers", s.handleListEventConsumers)
+	s.mux.HandleFunc("POST /api/v1/event-consumers", s.handleCreateEventConsumer)
+	s.mux.HandleFunc("GET /api/v1/event-consumers/{name}", s.handleGetEventConsumer)
+	s.mux.HandleFunc("DELETE /api/v1/event-consumers/{name}", s.handleDeleteEventConsumer)
+	s.mux.HandleFunc("POST /api/v1/event-consumers/{name}/seek", s.handleSeekEventConsumer)
+
+	// Diagnostics
+	s.mux.HandleFunc("GET /api/v1/diagnostics/target/{id}", s.handleGetTargetDiagnostics)
+
 	// Results ingestion (authenticated - agents submit probe results)
 	s.mux.HandleFunc("POST /api/v1/results", wrapHandler(s.handleIngestResults, agentAuth))
 
@@ -211,12 +428,17 @@ func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("POST /api/v1/subnets/{id}/archive", s.handleArchiveSubnet)
 	s.mux.HandleFunc("GET /api/v1/subnets/{id}/targets", s.handleListSubnetTargets)
 	s.mux.HandleFunc("GET /api/v1/subnets/{id}/stats", s.handleGetSubnetStats)
+	s.mux.HandleFunc("GET /api/v1/subnets/{id}/coverage-gaps", s.handleGetSubnetCoverageGaps)
 	s.mux.HandleFunc("POST /api/v1/subnets/{id}/seed", s.handleSeedSubnetTargets)
+	s.mux.HandleFunc("POST /api/v1/subnets/{id}/sweep", s.handleSweepSubnet)
 
 	// Target state management (dynamic routes already registered above)
 	s.mux.HandleFunc("POST /api/v1/targets/{id}/state", s.handleTransitionTargetState)
 	s.mux.HandleFunc("POST /api/v1/targets/{id}/acknowledge", s.handleAcknowledgeTarget)
+	s.mux.HandleFunc("POST /api/v1/targets/{id}/disable", s.handleDisableTargetProbing)
+	s.mux.HandleFunc("POST /api/v1/targets/{id}/enable", s.handleEnableTargetProbing)
 	s.mux.HandleFunc("GET /api/v1/targets/{id}/state-history", s.handleGetTargetStateHistory)
+	s.mux.HandleFunc("GET /api/v1/targets/{id}/hops", s.handleGetTargetHops)
 
 	// Target update/delete
 	s.mux.HandleFunc("PUT /api/v1/targets/{id}", s.handleUpdateTarget)
@@ -238,6 +460,8 @@ func (s *Server) registerRoutes() {
 
 	// Alert configuration
 	s.mux.HandleFunc("GET /api/v1/alerts/config", s.handleListAlertConfigs)
+	s.mux.HandleFunc("GET /api/v1/config/alert", s.handleGetAlertTunables)
+	s.mux.HandleFunc("GET /api/v1/config/archival", s.handleGetNeverRespondedPolicy)
 	s.mux.HandleFunc("PUT /api/v1/alerts/config/{key}", s.handleUpdateAlertConfig)
 
 	// Agent binary packages (for enrollment)
@@ -245,16 +469,9 @@ func (s *Server) registerRoutes() {
 }
 
 // =============================================================================
-// HEALTH
+// INFRASTRUCTURE HEALTH
 // =============================================================================
 
-func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	s.writeJSON(w, http.StatusOK, map[string]string{
-		"status": "ok",
-		"time":   time.Now().UTC().Format(time.RFC3339),
-	})
-}
-
 func (s *Server) handleInfrastructureHealth(w http.ResponseWriter, r *http.Request) {
 	if s.metricsCollector == nil {
 		s.writeError(w, http.StatusServiceUnavailable, "metrics collector not initialized")
@@ -303,6 +520,8 @@ type registerRequest struct {
 	Version    string            `json:"version"`
 	Executors  []string          `json:"executors"`
 	MaxTargets int               `json:"max_targets"`
+
+	SystemInfo *types.AgentSystemInfo `json:"system_info"`
 }
 
 func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
@@ -327,6 +546,7 @@ func (s *Server) handleAgentRegister(w http.ResponseWriter, r *http.Request) {
 		Version:    req.Version,
 		Executors:  req.Executors,
 		MaxTargets: req.MaxTargets,
+		SystemInfo: req.SystemInfo,
 	})
 	if err != nil {
 		s.logger.Error("agent registration failed", "error", err)
@@ -567,8 +787,7 @@ func (s *Server) handleArchiveAgent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.svc.ArchiveAgent(r.Context(), agentID, req.Reason); err != nil {
-		s.logger.Error("archive agent failed", "agent", agentID, "error", err)
-		s.writeError(w, http.StatusInternalServerError, "failed to archive agent")
+		s.writeServiceError(w, err, "failed to archive agent")
 		return
 	}
 
@@ -586,8 +805,7 @@ func (s *Server) handleUnarchiveAgent(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.svc.UnarchiveAgent(r.Context(), agentID); err != nil {
-		s.logger.Error("unarchive agent failed", "agent", agentID, "error", err)
-		s.writeError(w, http.StatusInternalServerError, "failed to unarchive agent")
+		s.writeServiceError(w, err, "failed to unarchive agent")
 		return
 	}
 
@@ -712,12 +930,13 @@ func (s *Server) handleAllAgentsStats(w http.ResponseWriter, r *http.Request) {
 func (s *Server) handleListTargets(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
-	// Check if pagination is requested
+	// Check if pagination is requested (?cursor= selects keyset pagination)
 	limitStr := query.Get("limit")
 	offsetStr := query.Get("offset")
+	_, useCursor := query["cursor"]
 
 	// If pagination params present, use paginated endpoint
-	if limitStr != "" || offsetStr != "" {
+	if limitStr != "" || offsetStr != "" || useCursor {
 		params := store.TargetListParams{
 			Tier:            query.Get("tier"),
 			MonitoringState: query.Get("state"),
@@ -735,11 +954,14 @@ func (s *Server) handleListTargets(w http.ResponseWriter, r *http.Request) {
 				params.Offset = offset
 			}
 		}
+		if useCursor {
+			cursor := query.Get("cursor")
+			params.Cursor = &cursor
+		}
 
 		result, err := s.svc.ListTargetsPaginated(r.Context(), params)
 		if err != nil {
-			s.logger.Error("list targets paginated failed", "error", err)
-			s.writeError(w, http.StatusInternalServerError, "failed to list targets")
+			s.writeServiceError(w, err, "failed to list targets")
 			return
 		}
 
@@ -790,6 +1012,23 @@ type createTargetRequest struct {
 	SubscriberID    string                 `json:"subscriber_id,omitempty"`
 	Tags            map[string]string      `json:"tags,omitempty"`
 	ExpectedOutcome *types.ExpectedOutcome `json:"expected_outcome,omitempty"`
+	DSCP            *int                   `json:"dscp,omitempty"`
+	Region          string                 `json:"region,omitempty"`
+	RetentionDays   *int                   `json:"retention_days,omitempty"`
+	DownThreshold   *int                   `json:"down_threshold_seconds,omitempty"`
+	ProbeType       string                 `json:"probe_type,omitempty"`
+	ProbeParams     json.RawMessage        `json:"probe_params,omitempty"`
+
+	// OnDuplicate is what to do if a target already holds the IP: "reject"
+	// (default, 409), "return" it unchanged, or "merge" tags and metadata
+	// into it.
+	OnDuplicate string `json:"on_duplicate,omitempty"`
+}
+
+// createTargetResponse is the target plus whether this request created it.
+type createTargetResponse struct {
+	*types.Target
+	Created bool `json:"created"`
 }
 
 func (s *Server) handleCreateTarget(w http.ResponseWriter, r *http.Request) {
@@ -803,24 +1042,36 @@ func (s *Server) handleCreateTarget(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, http.StatusBadRequest, "ip is required")
 		return
 	}
-	if req.Tier == "" {
-		req.Tier = "standard"
+	if err := types.ValidateDSCP(req.DSCP); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateRetentionDays(req.RetentionDays); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateDownThreshold(req.DownThreshold); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
 	}
 
-	target, err := s.svc.CreateTarget(r.Context(), service.CreateTargetRequest{
-		IP:              req.IP,
-		Tier:            req.Tier,
-		SubscriberID:    req.SubscriberID,
-		Tags:            req.Tags,
-		ExpectedOutcome: req.ExpectedOutcome,
-	})
+	onDuplicate, err := service.ParseOnDuplicate(req.OnDuplicate)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to create target")
+		return
+	}
+
+	target, created, err := s.svc.CreateTarget(r.Context(), req.serviceRequest(onDuplicate))
 	if err != nil {
-		s.logger.Error("create target failed", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "failed to create target")
+		s.writeServiceError(w, err, "failed to create target")
 		return
 	}
 
-	s.writeJSON(w, http.StatusCreated, target)
+	status := http.StatusCreated
+	if !created {
+		status = http.StatusOK
+	}
+	s.writeJSON(w, status, createTargetResponse{Target: target, Created: created})
 }
 
 func (s *Server) handleGetTarget(w http.ResponseWriter, r *http.Request) {
@@ -885,12 +1136,39 @@ func (s *Server) handleCreateTier(w http.ResponseWriter, r *http.Request) {
 		ProbeTimeoutS  int                       `json:"probe_timeout_seconds"`
 		ProbeRetries   int                       `json:"probe_retries"`
 		AgentSelection types.AgentSelectionPolicy `json:"agent_selection"`
+		DSCP           *int                       `json:"dscp,omitempty"`
+		RetentionDays  *int                       `json:"retention_days,omitempty"`
+		IngestMode     string                     `json:"ingest_mode,omitempty"`
+		MinAgents      *int                       `json:"min_agents,omitempty"`
+
+		// DownBackoffMaxS caps probe backoff for DOWN and EXCLUDED targets
+		DownBackoffMaxS int `json:"down_backoff_max_seconds,omitempty"`
+
+		// Aggregated ingest rollup width and raw retention; 0 is the default
+		AggregateBucketS int `json:"aggregate_bucket_seconds,omitempty"`
+		RawRetentionS    int `json:"raw_retention_seconds,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if err := types.ValidateDSCP(req.DSCP); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateRetentionDays(req.RetentionDays); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateTierIngestMode(req.IngestMode); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateMinAgents(req.MinAgents); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	if req.Name == "" {
 		s.writeError(w, http.StatusBadRequest, "name is required")
@@ -904,6 +1182,14 @@ func (s *Server) handleCreateTier(w http.ResponseWriter, r *http.Request) {
 		ProbeTimeout:   time.Duration(req.ProbeTimeoutS) * time.Second,
 		ProbeRetries:   req.ProbeRetries,
 		AgentSelection: req.AgentSelection,
+		DSCP:           req.DSCP,
+		RetentionDays:  req.RetentionDays,
+		IngestMode:     req.IngestMode,
+		MinAgents:      req.MinAgents,
+
+		DownBackoffMaxInterval: time.Duration(req.DownBackoffMaxS) * time.Second,
+		AggregateBucket:        time.Duration(req.AggregateBucketS) * time.Second,
+		RawRetention:           time.Duration(req.RawRetentionS) * time.Second,
 	}
 
 	if tier.DisplayName == "" {
@@ -920,8 +1206,7 @@ func (s *Server) handleCreateTier(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if err := s.svc.CreateTier(r.Context(), tier); err != nil {
-		s.logger.Error("create tier failed", "error", err, "name", req.Name)
-		s.writeError(w, http.StatusInternalServerError, err.Error())
+		s.writeServiceError(w, err, "failed to create tier")
 		return
 	}
 
@@ -937,12 +1222,39 @@ func (s *Server) handleUpdateTier(w http.ResponseWriter, r *http.Request) {
 		ProbeTimeoutS  int                       `json:"probe_timeout_seconds"`
 		ProbeRetries   int                       `json:"probe_retries"`
 		AgentSelection types.AgentSelectionPolicy `json:"agent_selection"`
+		DSCP           *int                       `json:"dscp,omitempty"`
+		RetentionDays  *int                       `json:"retention_days,omitempty"`
+		IngestMode     string                     `json:"ingest_mode,omitempty"`
+		MinAgents      *int                       `json:"min_agents,omitempty"`
+
+		// DownBackoffMaxS caps probe backoff for DOWN and EXCLUDED targets
+		DownBackoffMaxS int `json:"down_backoff_max_seconds,omitempty"`
+
+		// Aggregated ingest rollup width and raw retention; 0 is the default
+		AggregateBucketS int `json:"aggregate_bucket_seconds,omitempty"`
+		RawRetentionS    int `json:"raw_retention_seconds,omitempty"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		s.writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if err := types.ValidateDSCP(req.DSCP); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateRetentionDays(req.RetentionDays); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateTierIngestMode(req.IngestMode); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateMinAgents(req.MinAgents); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
 
 	tier := &types.Tier{
 		Name:           name,
@@ -951,15 +1263,18 @@ func (s *Server) handleUpdateTier(w http.ResponseWriter, r *http.Request) {
 		ProbeTimeout:   time.Duration(req.ProbeTimeoutS) * time.Second,
 		ProbeRetries:   req.ProbeRetries,
 		AgentSelection: req.AgentSelection,
+		DSCP:           req.DSCP,
+		RetentionDays:  req.RetentionDays,
+		IngestMode:     req.IngestMode,
+		MinAgents:      req.MinAgents,
+
+		DownBackoffMaxInterval: time.Duration(req.DownBackoffMaxS) * time.Second,
+		AggregateBucket:        time.Duration(req.AggregateBucketS) * time.Second,
+		RawRetention:           time.Duration(req.RawRetentionS) * time.Second,
 	}
 
 	if err := s.svc.UpdateTier(r.Context(), tier); err != nil {
-		s.logger.Error("update tier failed", "error", err, "name", name)
-		if err.Error() == "tier not found: "+name {
-			s.writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			s.writeError(w, http.StatusInternalServerError, err.Error())
-		}
+		s.writeServiceError(w, err, "failed to update tier")
 		return
 	}
 
@@ -970,14 +1285,7 @@ func (s *Server) handleDeleteTier(w http.ResponseWriter, r *http.Request) {
 	name := r.PathValue("name")
 
 	if err := s.svc.DeleteTier(r.Context(), name); err != nil {
-		s.logger.Error("delete tier failed", "error", err, "name", name)
-		if strings.Contains(err.Error(), "targets are using it") {
-			s.writeError(w, http.StatusConflict, err.Error())
-		} else if strings.Contains(err.Error(), "not found") {
-			s.writeError(w, http.StatusNotFound, err.Error())
-		} else {
-			s.writeError(w, http.StatusInternalServerError, err.Error())
-		}
+		s.writeServiceError(w, err, "failed to delete tier")
 		return
 	}
 
@@ -1002,12 +1310,13 @@ func (s *Server) handleIngestResults(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var batch types.ResultBatch
-	if err := json.NewDecoder(reader).Decode(&batch); err != nil {
+	if err := decodeResultBatch(r.Header.Get("Content-Type"), reader, &batch); err != nil {
 		s.writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
 
-	if err := s.svc.IngestResults(r.Context(), batch); err != nil {
+	summary, err := s.svc.IngestResults(r.Context(), batch)
+	if err != nil {
 		s.logger.Error("result ingestion failed",
 			"agent", batch.AgentID,
 			"count", len(batch.Results),
@@ -1016,9 +1325,11 @@ func (s *Server) handleIngestResults(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.writeJSON(w, http.StatusAccepted, map[string]any{
-		"accepted": len(batch.Results),
-	})
+	// A replayed batch is acknowledged like a fresh one so the agent stops
+	// retrying it; rejected results are dropped either way, and the reasons
+	// tell the agent why.
+	resp := summary.Response()
+	s.writeJSON(w, http.StatusAccepted, resp)
 }
 
 // =============================================================================
@@ -1036,9 +1347,7 @@ func (s *Server) writeJSON(w http.ResponseWriter, status int, v any) {
 }
 
 func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
-	s.writeJSON(w, status, map[string]string{
-		"error": message,
-	})
+	writeError(w, status, message)
 }
 
 // getAgentID extracts agent ID from request header or path.
@@ -1062,7 +1371,12 @@ func getAgentID(r *http.Request) string {
 // =============================================================================
 
 func (s *Server) handleGetAllTargetStatuses(w http.ResponseWriter, r *http.Request) {
-	const cacheKey = "target_statuses"
+	sortBy := r.URL.Query().Get("sort")
+	if err := service.ValidateTargetStatusSort(sortBy); err != nil {
+		s.writeServiceError(w, err, "invalid sort")
+		return
+	}
+	cacheKey := "target_statuses:" + sortBy
 
 	// Try cache first
 	if s.cache != nil {
@@ -1080,6 +1394,7 @@ func (s *Server) handleGetAllTargetStatuses(w http.ResponseWriter, r *http.Reque
 		s.writeError(w, http.StatusInternalServerError, "failed to get target statuses")
 		return
 	}
+	service.SortTargetStatuses(statuses, sortBy)
 
 	response := map[string]any{
 		"statuses": statuses,
@@ -1141,9 +1456,10 @@ func (s *Server) handleGetTargetHistory(w http.ResponseWriter, r *http.Request)
 	}
 
 	s.writeJSON(w, http.StatusOK, map[string]any{
-		"target_id": targetID,
-		"window":    window.String(),
-		"history":   history,
+		"target_id":   targetID,
+		"window":      window.String(),
+		"history":     history,
+		"annotations": s.historyAnnotations(r, targetID, window),
 	})
 }
 
@@ -1171,9 +1487,45 @@ func (s *Server) handleGetTargetHistoryByAgent(w http.ResponseWriter, r *http.Re
 	}
 
 	s.writeJSON(w, http.StatusOK, map[string]any{
-		"target_id": targetID,
-		"window":    window.String(),
-		"history":   history,
+		"target_id":   targetID,
+		"window":      window.String(),
+		"history":     history,
+		"annotations": s.historyAnnotations(r, targetID, window),
+	})
+}
+
+// handleGetTargetHistoryInMarket returns in-market and all-agent history
+// series for a target in one response so the UI can overlay them.
+func (s *Server) handleGetTargetHistoryInMarket(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	if targetID == "" {
+		s.writeError(w, http.StatusBadRequest, "target ID required")
+		return
+	}
+
+	// Get window from query param, default to 1 hour
+	windowStr := r.URL.Query().Get("window")
+	window := time.Hour
+	if windowStr != "" {
+		if parsed, err := time.ParseDuration(windowStr); err == nil {
+			window = parsed
+		}
+	}
+
+	comparison, err := s.svc.GetTargetHistoryComparison(r.Context(), targetID, window)
+	if err != nil {
+		s.logger.Error("get target in-market history failed", "target", targetID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to get target in-market history")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"target_id":   targetID,
+		"window":      window.String(),
+		"bucket_size": comparison.BucketSize.String(),
+		"in_market":   comparison.InMarket,
+		"all":         comparison.All,
+		"annotations": s.historyAnnotations(r, targetID, window),
 	})
 }
 
@@ -1193,16 +1545,27 @@ func (s *Server) handleGetTargetLive(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	results, err := s.svc.GetTargetLiveResults(r.Context(), targetID, seconds)
+	// Optional cap on results per agent (0 = none)
+	perAgent := 0
+	if v := r.URL.Query().Get("per_agent"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed < 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid per_agent")
+			return
+		}
+		perAgent = parsed
+	}
+
+	results, err := s.svc.GetTargetLiveResults(r.Context(), targetID, seconds, perAgent)
 	if err != nil {
-		s.logger.Error("get target live results failed", "target", targetID, "error", err)
-		s.writeError(w, http.StatusInternalServerError, "failed to get live results")
+		s.writeServiceError(w, err, "failed to get live results")
 		return
 	}
 
 	s.writeJSON(w, http.StatusOK, map[string]any{
 		"target_id": targetID,
 		"seconds":   seconds,
+		"per_agent": perAgent,
 		"count":     len(results),
 		"results":   results,
 	})
@@ -1614,17 +1977,29 @@ func (s *Server) handleGetBaseline(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, http.StatusOK, baseline)
 }
 
+// handleRecalculateBaselines recalculates baselines, optionally scoped by a
+// store.BaselineScope body. No body recalculates everything.
 func (s *Server) handleRecalculateBaselines(w http.ResponseWriter, r *http.Request) {
-	count, err := s.svc.RecalculateAllBaselines(r.Context())
+	var scope store.BaselineScope
+	if r.ContentLength != 0 {
+		if err := s.readJSON(r, &scope); err != nil && err != io.EOF {
+			s.writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	start := time.Now()
+	count, err := s.svc.RecalculateBaselines(r.Context(), scope)
 	if err != nil {
-		s.logger.Error("recalculate baselines failed", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "failed to recalculate baselines")
+		s.writeServiceError(w, err, "failed to recalculate baselines")
 		return
 	}
 
 	s.writeJSON(w, http.StatusOK, map[string]any{
 		"status":        "completed",
 		"pairs_updated": count,
+		"scope":         scope,
+		"duration_ms":   time.Since(start).Milliseconds(),
 	})
 }
 
@@ -1653,7 +2028,14 @@ func (s *Server) handleGetTargetReport(w http.ResponseWriter, r *http.Request) {
 		windowDays = 365
 	}
 
-	report, err := s.svc.GetTargetReport(r.Context(), targetID, windowDays)
+	// Optional IANA time zone aligns day boundaries to the customer's midnight
+	tz := r.URL.Query().Get("tz")
+	if _, err := report.LoadTimeZone(tz); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rows, err := s.svc.GetTargetReport(r.Context(), targetID, windowDays, tz)
 	if err != nil {
 		s.logger.Error("get target report failed", "target", targetID, "error", err)
 		s.writeError(w, http.StatusInternalServerError, "failed to get target report")
@@ -1662,15 +2044,75 @@ func (s *Server) handleGetTargetReport(w http.ResponseWriter, r *http.Request) {
 
 	// Get target info
 	target, _ := s.svc.GetTarget(r.Context(), targetID)
+	targetIP := ""
+	if target != nil {
+		targetIP = target.IP
+	}
+
+	opts := reportFormatOptions(r)
+	formatted := report.FormatTargetReport(rows, opts)
+
+	if r.URL.Query().Get("format") == "pdf" {
+		var buf bytes.Buffer
+		meta := report.PDFMeta{
+			TargetID:    targetID,
+			TargetIP:    targetIP,
+			WindowDays:  windowDays,
+			TimeZone:    tz,
+			GeneratedAt: time.Now(),
+		}
+		if err := report.RenderTargetReportPDF(&buf, meta, formatted); err != nil {
+			s.logger.Error("render target report pdf failed", "target", targetID, "error", err)
+			s.writeError(w, http.StatusInternalServerError, "failed to render report")
+			return
+		}
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="target-report-%s-%dd.pdf"`, targetID, windowDays))
+		w.WriteHeader(http.StatusOK)
+		w.Write(buf.Bytes())
+		return
+	}
 
+	daily, err := s.svc.GetTargetDailyReport(r.Context(), targetID, windowDays, tz)
+	if err != nil {
+		s.logger.Error("get target daily report failed", "target", targetID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to get target report")
+		return
+	}
+
+	timezone := tz
+	if timezone == "" {
+		timezone = "UTC"
+	}
 	s.writeJSON(w, http.StatusOK, map[string]any{
 		"target_id":   targetID,
-		"target_ip":   target.IP,
+		"target_ip":   targetIP,
 		"window_days": windowDays,
-		"report":      report,
+		"timezone":    timezone,
+		"report":      rows,
+		"daily":       daily,
+		"formatted":   formatted,
+		"precision":   opts,
 	})
 }
 
+// reportFormatOptions reads optional precision overrides from the query
+// string, clamped to what the report will actually render.
+func reportFormatOptions(r *http.Request) report.FormatOptions {
+	opts := report.DefaultFormatOptions()
+	q := r.URL.Query()
+	if v, err := strconv.Atoi(q.Get("latency_decimals")); err == nil {
+		opts.LatencyDecimals = v
+	}
+	if v, err := strconv.Atoi(q.Get("loss_decimals")); err == nil {
+		opts.LossDecimals = v
+	}
+	if v, err := strconv.Atoi(q.Get("uptime_decimals")); err == nil {
+		opts.UptimeDecimals = v
+	}
+	return opts.Normalize()
+}
+
 // =============================================================================
 // FLEXIBLE METRICS QUERY
 // =============================================================================
@@ -1682,20 +2124,7 @@ func (s *Server) handleQueryMetrics(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate the query
-	if err := query.Validate(); err != nil {
-		s.writeError(w, http.StatusBadRequest, err.Error())
-		return
-	}
-
-	result, err := s.svc.QueryMetrics(r.Context(), &query)
-	if err != nil {
-		s.logger.Error("metrics query failed", "error", err)
-		s.writeError(w, http.StatusInternalServerError, "failed to execute metrics query")
-		return
-	}
-
-	s.writeJSON(w, http.StatusOK, result)
+	s.runMetricsQuery(w, r, &query)
 }
 
 // parseInt parses a string to int, returning error if invalid.
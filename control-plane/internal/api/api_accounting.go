@@ -0,0 +1,75 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// PROBE ACCOUNTING ENDPOINTS
+// =============================================================================
+
+// parseAccountingQuery reads period, start, end and the id filter named by
+// idParam. It writes the error response and returns false on bad input.
+func (s *Server) parseAccountingQuery(w http.ResponseWriter, r *http.Request, idParam string) (service.AccountingQuery, bool) {
+	q := r.URL.Query()
+
+	period, err := types.ParseAccountingPeriod(q.Get("period"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return service.AccountingQuery{}, false
+	}
+	query := service.AccountingQuery{Period: period, ID: q.Get(idParam)}
+
+	for _, param := range []struct {
+		name string
+		dst  *time.Time
+	}{{"start", &query.Start}, {"end", &query.End}} {
+		v := q.Get(param.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			if t, err = time.Parse(time.DateOnly, v); err != nil {
+				s.writeError(w, http.StatusBadRequest, param.name+" must be a date or RFC 3339 time")
+				return service.AccountingQuery{}, false
+			}
+		}
+		*param.dst = t
+	}
+	return query, true
+}
+
+func (s *Server) handleGetTargetAccounting(w http.ResponseWriter, r *http.Request) {
+	query, ok := s.parseAccountingQuery(w, r, "target_id")
+	if !ok {
+		return
+	}
+
+	report, err := s.svc.GetTargetProbeAccounting(r.Context(), query)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get target probe accounting")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, report)
+}
+
+func (s *Server) handleGetAgentAccounting(w http.ResponseWriter, r *http.Request) {
+	query, ok := s.parseAccountingQuery(w, r, "agent_id")
+	if !ok {
+		return
+	}
+
+	report, err := s.svc.GetAgentProbeAccounting(r.Context(), query)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get agent probe accounting")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, report)
+}
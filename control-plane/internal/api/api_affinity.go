@@ -0,0 +1,165 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ASSIGNMENT AFFINITY RULE ENDPOINTS
+// =============================================================================
+
+type affinityRuleRequest struct {
+	Name          string                 `json:"name"`
+	Description   *string                `json:"description"`
+	Enabled       *bool                  `json:"enabled"`
+	Priority      *int                   `json:"priority"`
+	Mode          types.AffinityMode     `json:"mode"`
+	Fallback      types.AffinityFallback `json:"fallback"`
+	TargetFilters []types.TagFilter      `json:"target_filters"`
+	AgentFilters  []types.TagFilter      `json:"agent_filters"`
+}
+
+// apply copies the fields set in the request onto r.
+func (req *affinityRuleRequest) apply(r *types.AffinityRule) {
+	if req.Name != "" {
+		r.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Description != nil {
+		r.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		r.Enabled = *req.Enabled
+	}
+	if req.Priority != nil {
+		r.Priority = *req.Priority
+	}
+	if req.Mode != "" {
+		r.Mode = req.Mode
+	}
+	if req.Fallback != "" {
+		r.Fallback = req.Fallback
+	}
+	if req.TargetFilters != nil {
+		r.TargetFilters = req.TargetFilters
+	}
+	if req.AgentFilters != nil {
+		r.AgentFilters = req.AgentFilters
+	}
+}
+
+func (s *Server) handleListAffinityRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := s.svc.ListAffinityRules(r.Context())
+	if err != nil {
+		s.logger.Error("list affinity rules failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list affinity rules")
+		return
+	}
+	if rules == nil {
+		rules = []types.AffinityRule{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"rules": rules,
+		"count": len(rules),
+	})
+}
+
+func (s *Server) handleGetAffinityRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := s.svc.GetAffinityRule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get affinity rule")
+		return
+	}
+	if rule == nil {
+		s.writeError(w, http.StatusNotFound, "affinity rule not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, rule)
+}
+
+func (s *Server) handleCreateAffinityRule(w http.ResponseWriter, r *http.Request) {
+	var req affinityRuleRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rule := &types.AffinityRule{Enabled: true, Priority: types.DefaultAffinityPriority}
+	req.apply(rule)
+
+	check, err := s.svc.CreateAffinityRule(r.Context(), rule)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to create affinity rule")
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, map[string]any{
+		"rule":  rule,
+		"check": check,
+	})
+}
+
+func (s *Server) handleUpdateAffinityRule(w http.ResponseWriter, r *http.Request) {
+	var req affinityRuleRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rule, err := s.svc.GetAffinityRule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get affinity rule")
+		return
+	}
+	if rule == nil {
+		s.writeError(w, http.StatusNotFound, "affinity rule not found")
+		return
+	}
+
+	req.apply(rule)
+
+	check, err := s.svc.UpdateAffinityRule(r.Context(), rule)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to update affinity rule")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"rule":  rule,
+		"check": check,
+	})
+}
+
+func (s *Server) handleDeleteAffinityRule(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.DeleteAffinityRule(r.Context(), r.PathValue("id")); err != nil {
+		s.writeServiceError(w, err, "failed to delete affinity rule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleCheckAffinityRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := s.svc.GetAffinityRule(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get affinity rule")
+		return
+	}
+	if rule == nil {
+		s.writeError(w, http.StatusNotFound, "affinity rule not found")
+		return
+	}
+
+	check, err := s.svc.CheckAffinityRule(r.Context(), rule)
+	if err != nil {
+		s.logger.Error("check affinity rule failed", "rule_id", rule.ID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to check affinity rule")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, check)
+}
@@ -0,0 +1,18 @@
+package api
+
+import "net/http"
+
+// =============================================================================
+// AGENT ANOMALY TRIAGE ENDPOINT
+// =============================================================================
+
+func (s *Server) handleGetAgentAnomalies(w http.ResponseWriter, r *http.Request) {
+	report, err := s.svc.GetAgentAnomalies(r.Context())
+	if err != nil {
+		s.logger.Error("get agent anomalies failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to get agent anomalies")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, report)
+}
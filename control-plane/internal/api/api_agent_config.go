@@ -0,0 +1,78 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// REMOTE AGENT CONFIG ENDPOINTS
+// =============================================================================
+
+// handleAgentConfig serves an agent its effective remote config. Agents
+// fetch it at startup and whenever a heartbeat reports it stale.
+func (s *Server) handleAgentConfig(w http.ResponseWriter, r *http.Request) {
+	set, err := s.svc.GetAgentConfig(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get agent config")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, set)
+}
+
+func (s *Server) handleGetAgentConfigStatus(w http.ResponseWriter, r *http.Request) {
+	status, err := s.svc.GetAgentConfigStatus(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get agent config status")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleSetAgentConfig(w http.ResponseWriter, r *http.Request) {
+	var config types.AgentRemoteConfig
+	if err := s.readJSON(r, &config); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	scope, err := s.svc.SetAgentConfigOverride(r.Context(), r.PathValue("id"), config)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to set agent config")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, scope)
+}
+
+func (s *Server) handleDeleteAgentConfig(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.DeleteAgentConfigOverride(r.Context(), r.PathValue("id")); err != nil {
+		s.writeServiceError(w, err, "failed to delete agent config")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGetGlobalAgentConfig(w http.ResponseWriter, r *http.Request) {
+	scope, err := s.svc.GetGlobalAgentConfig(r.Context())
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get global agent config")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, scope)
+}
+
+func (s *Server) handleSetGlobalAgentConfig(w http.ResponseWriter, r *http.Request) {
+	var config types.AgentRemoteConfig
+	if err := s.readJSON(r, &config); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	scope, err := s.svc.SetGlobalAgentConfig(r.Context(), config)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to set global agent config")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, scope)
+}
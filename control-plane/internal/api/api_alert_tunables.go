@@ -0,0 +1,14 @@
+package api
+
+import "net/http"
+
+// handleGetAlertTunables returns the complete alert tuning surface: every
+// knob's effective value and default, and every alert_config key.
+func (s *Server) handleGetAlertTunables(w http.ResponseWriter, r *http.Request) {
+	report, err := s.svc.GetAlertTunables(r.Context())
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get alert config")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, report)
+}
@@ -203,8 +203,7 @@ func (s *Server) handleUpdateAlertConfig(w http.ResponseWriter, r *http.Request)
 	}
 
 	if err := s.svc.SetAlertConfig(r.Context(), key, req.Value, req.Description); err != nil {
-		s.logger.Error("update alert config failed", "key", key, "error", err)
-		s.writeError(w, http.StatusInternalServerError, "failed to update alert config")
+		s.writeServiceError(w, err, "failed to update alert config")
 		return
 	}
 
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET ANNOTATION ENDPOINTS
+// =============================================================================
+
+type annotationRequest struct {
+	StartsAt  *time.Time `json:"starts_at"` // defaults to now
+	EndsAt    *time.Time `json:"ends_at"`   // omit for a point in time
+	Text      string     `json:"text"`
+	CreatedBy string     `json:"created_by"`
+}
+
+func (s *Server) handleCreateTargetAnnotation(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	var req annotationRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	a := &types.TargetAnnotation{
+		TargetID:  targetID,
+		StartsAt:  time.Now(),
+		EndsAt:    req.EndsAt,
+		Text:      strings.TrimSpace(req.Text),
+		CreatedBy: req.CreatedBy,
+	}
+	if req.StartsAt != nil {
+		a.StartsAt = *req.StartsAt
+	}
+	if err := s.svc.CreateTargetAnnotation(r.Context(), a); err != nil {
+		s.writeServiceError(w, err, "failed to create annotation")
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, a)
+}
+
+func (s *Server) handleListTargetAnnotations(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	window := config.AnnotationDefaultWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		parsed, err := types.ParseDuration(v)
+		if err != nil || parsed <= 0 || parsed > config.AnnotationMaxWindow {
+			s.writeError(w, http.StatusBadRequest, "invalid window")
+			return
+		}
+		window = parsed
+	}
+
+	annotations, err := s.svc.GetTargetAnnotations(r.Context(), targetID, window)
+	if err != nil {
+		s.logger.Error("list target annotations failed", "target", targetID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list annotations")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"target_id":   targetID,
+		"window":      window.String(),
+		"annotations": annotations,
+	})
+}
+
+func (s *Server) handleDeleteTargetAnnotation(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	annotationID := r.PathValue("annotation_id")
+
+	if err := s.svc.DeleteTargetAnnotation(r.Context(), targetID, annotationID); err != nil {
+		s.writeServiceError(w, err, "failed to delete annotation")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// historyAnnotations returns the annotations to overlay on a history
+// response. A failure is logged and yields none rather than failing the
+// chart data it accompanies.
+func (s *Server) historyAnnotations(r *http.Request, targetID string, window time.Duration) []types.TargetAnnotation {
+	annotations, err := s.svc.GetTargetAnnotations(r.Context(), targetID, window)
+	if err != nil {
+		s.logger.Warn("get history annotations failed", "target", targetID, "error", err)
+	}
+	if annotations == nil {
+		annotations = []types.TargetAnnotation{}
+	}
+	return annotations
+}
@@ -0,0 +1,47 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// =============================================================================
+// ASSIGNMENT DIFF ENDPOINTS
+// =============================================================================
+
+// handleAgentAssignmentDiff shows what changed in an agent's assignments
+// between two versions, by default since the version it last reported.
+func (s *Server) handleAgentAssignmentDiff(w http.ResponseWriter, r *http.Request) {
+	agentID := r.PathValue("id")
+	q := r.URL.Query()
+
+	from, err := parseVersionParam(q.Get("from"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid from")
+		return
+	}
+	to, err := parseVersionParam(q.Get("to"))
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid to")
+		return
+	}
+
+	diff, err := s.svc.DiffAgentAssignments(r.Context(), agentID, from, to)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to diff assignments")
+		return
+	}
+	s.writeJSON(w, http.StatusOK, diff)
+}
+
+// parseVersionParam parses an optional assignment version, nil when empty.
+func parseVersionParam(v string) (*int64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	parsed, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+)
+
+// =============================================================================
+// TARGET AVAILABILITY ENDPOINT
+// =============================================================================
+
+func (s *Server) handleGetTargetAvailability(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	if targetID == "" {
+		s.writeError(w, http.StatusBadRequest, "target ID required")
+		return
+	}
+
+	availability, err := s.svc.GetTargetAvailability(r.Context(), targetID)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get target availability")
+		return
+	}
+	if availability == nil {
+		s.writeError(w, http.StatusNotFound, "target not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, availability)
+}
@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TLS CERTIFICATE ENDPOINTS
+// =============================================================================
+
+// handleListCertificates lists the latest certificate of each tls_cert
+// target for expiry dashboards.
+func (s *Server) handleListCertificates(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var withinDays *int
+	if v := q.Get("within_days"); v != "" {
+		parsed, err := parseInt(v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid within_days")
+			return
+		}
+		withinDays = &parsed
+	}
+	var limit int
+	if v := q.Get("limit"); v != "" {
+		parsed, err := parseInt(v)
+		if err != nil || parsed <= 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	certs, err := s.svc.ListCertificates(r.Context(), withinDays, limit)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to list certificates")
+		return
+	}
+	if certs == nil {
+		certs = []types.TargetCertificate{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"certificates": certs,
+		"count":        len(certs),
+	})
+}
@@ -0,0 +1,28 @@
+package api
+
+import "net/http"
+
+// =============================================================================
+// SUBNET COVERAGE GAPS ENDPOINT
+// =============================================================================
+
+func (s *Server) handleGetSubnetCoverageGaps(w http.ResponseWriter, r *http.Request) {
+	subnetID := r.PathValue("id")
+	if subnetID == "" {
+		s.writeError(w, http.StatusBadRequest, "subnet ID required")
+		return
+	}
+
+	coverage, err := s.svc.GetSubnetCoverageGaps(r.Context(), subnetID)
+	if err != nil {
+		s.logger.Error("get subnet coverage gaps failed", "subnet", subnetID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to get subnet coverage gaps")
+		return
+	}
+	if coverage == nil {
+		s.writeError(w, http.StatusNotFound, "subnet not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, coverage)
+}
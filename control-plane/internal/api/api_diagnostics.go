@@ -0,0 +1,49 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// DIAGNOSTICS ENDPOINTS
+// =============================================================================
+
+// handleGetTargetDiagnostics returns the one-shot triage bundle for a target.
+// Sections that failed are listed under errors; the rest are still returned.
+func (s *Server) handleGetTargetDiagnostics(w http.ResponseWriter, r *http.Request) {
+	diag, err := s.svc.GetTargetDiagnostics(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get target diagnostics")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, diag)
+}
+
+// handleTriggerPMTUD queues path MTU discovery to a target. The optional
+// body picks agents and narrows the sizes searched.
+func (s *Server) handleTriggerPMTUD(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		AgentIDs []string `json:"agent_ids,omitempty"`
+		types.PMTUDParams
+	}
+	s.readJSON(r, &req) // Ignore error, use defaults if not provided
+
+	cmd, err := s.svc.CreatePMTUDCommand(r.Context(), r.PathValue("id"), req.PMTUDParams, req.AgentIDs)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to create pmtud command")
+		return
+	}
+
+	s.writeJSON(w, http.StatusAccepted, map[string]any{
+		"command_id":   cmd.ID,
+		"command_type": cmd.CommandType,
+		"target_id":    cmd.TargetID,
+		"target_ip":    cmd.TargetIP,
+		"agent_ids":    cmd.AgentIDs,
+		"status":       cmd.Status,
+		"message":      "path MTU discovery queued; results at /api/v1/commands/" + cmd.ID,
+	})
+}
@@ -0,0 +1,129 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ALERT ESCALATION POLICY ENDPOINTS
+// =============================================================================
+
+type escalationPolicyRequest struct {
+	Name        string                 `json:"name"`
+	Description *string                `json:"description"`
+	Enabled     *bool                  `json:"enabled"`
+	Tiers       []string               `json:"tiers"`
+	Regions     []string               `json:"regions"`
+	Steps       []types.EscalationStep `json:"steps"`
+}
+
+// apply copies the fields set in the request onto p. An empty tiers or
+// regions list widens the policy to any.
+func (req *escalationPolicyRequest) apply(p *types.EscalationPolicy) {
+	if req.Name != "" {
+		p.Name = strings.TrimSpace(req.Name)
+	}
+	if req.Description != nil {
+		p.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		p.Enabled = *req.Enabled
+	}
+	if req.Tiers != nil {
+		p.Tiers = req.Tiers
+	}
+	if req.Regions != nil {
+		p.Regions = req.Regions
+	}
+	if req.Steps != nil {
+		p.Steps = req.Steps
+	}
+}
+
+func (s *Server) handleListEscalationPolicies(w http.ResponseWriter, r *http.Request) {
+	policies, err := s.svc.ListEscalationPolicies(r.Context())
+	if err != nil {
+		s.logger.Error("list escalation policies failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list escalation policies")
+		return
+	}
+	if policies == nil {
+		policies = []types.EscalationPolicy{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"policies": policies,
+		"count":    len(policies),
+	})
+}
+
+func (s *Server) handleGetEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := s.svc.GetEscalationPolicy(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get escalation policy")
+		return
+	}
+	if policy == nil {
+		s.writeError(w, http.StatusNotFound, "escalation policy not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, policy)
+}
+
+func (s *Server) handleCreateEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var req escalationPolicyRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	policy := &types.EscalationPolicy{Enabled: true}
+	req.apply(policy)
+
+	if err := s.svc.CreateEscalationPolicy(r.Context(), policy); err != nil {
+		s.writeServiceError(w, err, "failed to create escalation policy")
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, policy)
+}
+
+func (s *Server) handleUpdateEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	var req escalationPolicyRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	policy, err := s.svc.GetEscalationPolicy(r.Context(), r.PathValue("id"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get escalation policy")
+		return
+	}
+	if policy == nil {
+		s.writeError(w, http.StatusNotFound, "escalation policy not found")
+		return
+	}
+
+	req.apply(policy)
+
+	if err := s.svc.UpdateEscalationPolicy(r.Context(), policy); err != nil {
+		s.writeServiceError(w, err, "failed to update escalation policy")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, policy)
+}
+
+func (s *Server) handleDeleteEscalationPolicy(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.DeleteEscalationPolicy(r.Context(), r.PathValue("id")); err != nil {
+		s.writeServiceError(w, err, "failed to delete escalation policy")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// EVENT STREAM ENDPOINTS
+// =============================================================================
+
+// handleListEvents serves the event stream to pull consumers. Clients pass
+// back the returned next as since to resume; it stays at since when there
+// is nothing new.
+func (s *Server) handleListEvents(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var since int64
+	if v := q.Get("since"); v != "" {
+		parsed, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid since")
+			return
+		}
+		since = parsed
+	}
+	var limit int
+	if v := q.Get("limit"); v != "" {
+		parsed, err := parseInt(v)
+		if err != nil || parsed <= 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	events, err := s.svc.ListEvents(r.Context(), since, q.Get("type"), limit)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to list events")
+		return
+	}
+	if events == nil {
+		events = []types.Event{}
+	}
+
+	next := since
+	if len(events) > 0 {
+		next = events[len(events)-1].Seq
+	}
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"events": events,
+		"count":  len(events),
+		"next":   next,
+	})
+}
+
+type eventConsumerRequest struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Enabled    *bool    `json:"enabled"`
+	StartSeq   *int64   `json:"start_seq"` // omit to start after the newest event
+}
+
+func (s *Server) handleListEventConsumers(w http.ResponseWriter, r *http.Request) {
+	consumers, err := s.svc.ListEventConsumers(r.Context())
+	if err != nil {
+		s.logger.Error("list event consumers failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list event consumers")
+		return
+	}
+	if consumers == nil {
+		consumers = []types.EventConsumer{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"consumers": consumers,
+		"count":     len(consumers),
+	})
+}
+
+func (s *Server) handleGetEventConsumer(w http.ResponseWriter, r *http.Request) {
+	consumer, err := s.svc.GetEventConsumer(r.Context(), r.PathValue("name"))
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get event consumer")
+		return
+	}
+	if consumer == nil {
+		s.writeError(w, http.StatusNotFound, "event consumer not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, consumer)
+}
+
+func (s *Server) handleCreateEventConsumer(w http.ResponseWriter, r *http.Request) {
+	var req eventConsumerRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	consumer := &types.EventConsumer{
+		Name:       strings.TrimSpace(req.Name),
+		Kind:       req.Kind,
+		URL:        strings.TrimSpace(req.URL),
+		EventTypes: req.EventTypes,
+		Enabled:    true,
+	}
+	if consumer.Kind == "" {
+		consumer.Kind = types.EventConsumerWebhook
+	}
+	if req.Enabled != nil {
+		consumer.Enabled = *req.Enabled
+	}
+
+	if err := s.svc.CreateEventConsumer(r.Context(), consumer, req.StartSeq); err != nil {
+		s.writeServiceError(w, err, "failed to create event consumer")
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, consumer)
+}
+
+func (s *Server) handleDeleteEventConsumer(w http.ResponseWriter, r *http.Request) {
+	if err := s.svc.DeleteEventConsumer(r.Context(), r.PathValue("name")); err != nil {
+		s.writeServiceError(w, err, "failed to delete event consumer")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleSeekEventConsumer(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Seq *int64 `json:"seq"`
+	}
+	if err := s.readJSON(r, &req); err != nil || req.Seq == nil {
+		s.writeError(w, http.StatusBadRequest, "seq is required")
+		return
+	}
+
+	name := r.PathValue("name")
+	if err := s.svc.SeekEventConsumer(r.Context(), name, *req.Seq); err != nil {
+		s.writeServiceError(w, err, "failed to seek event consumer")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"name":     name,
+		"last_seq": *req.Seq,
+	})
+}
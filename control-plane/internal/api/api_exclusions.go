@@ -0,0 +1,110 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// AGENT HEALTH EXCLUSION ENDPOINTS
+// =============================================================================
+
+type healthExclusionRequest struct {
+	AgentID   string `json:"agent_id"`
+	TargetID  string `json:"target_id"`
+	SubnetID  string `json:"subnet_id"`
+	Reason    string `json:"reason"`
+	CreatedBy string `json:"created_by"`
+}
+
+func (s *Server) handleListHealthExclusions(w http.ResponseWriter, r *http.Request) {
+	exclusions, err := s.svc.ListHealthExclusions(r.Context(), r.URL.Query().Get("agent_id"))
+	if err != nil {
+		s.logger.Error("list health exclusions failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list health exclusions")
+		return
+	}
+	if exclusions == nil {
+		exclusions = []types.AgentHealthExclusion{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"exclusions": exclusions,
+		"count":      len(exclusions),
+	})
+}
+
+func (s *Server) handleGetHealthExclusion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	e, err := s.svc.GetHealthExclusion(r.Context(), id)
+	if err != nil {
+		s.logger.Error("get health exclusion failed", "exclusion_id", id, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to get health exclusion")
+		return
+	}
+	if e == nil {
+		s.writeError(w, http.StatusNotFound, "health exclusion not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) handleCreateHealthExclusion(w http.ResponseWriter, r *http.Request) {
+	var req healthExclusionRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	e := &types.AgentHealthExclusion{
+		AgentID:   req.AgentID,
+		TargetID:  req.TargetID,
+		SubnetID:  req.SubnetID,
+		Reason:    req.Reason,
+		CreatedBy: req.CreatedBy,
+	}
+	if err := s.svc.CreateHealthExclusion(r.Context(), e); err != nil {
+		s.writeServiceError(w, err, "failed to create health exclusion")
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, e)
+}
+
+func (s *Server) handleUpdateHealthExclusion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := s.svc.UpdateHealthExclusionReason(r.Context(), id, req.Reason); err != nil {
+		s.writeServiceError(w, err, "failed to update health exclusion")
+		return
+	}
+
+	e, err := s.svc.GetHealthExclusion(r.Context(), id)
+	if err != nil || e == nil {
+		s.writeJSON(w, http.StatusOK, map[string]string{"status": "updated"})
+		return
+	}
+	s.writeJSON(w, http.StatusOK, e)
+}
+
+func (s *Server) handleDeleteHealthExclusion(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := s.svc.DeleteHealthExclusion(r.Context(), id); err != nil {
+		s.writeServiceError(w, err, "failed to delete health exclusion")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
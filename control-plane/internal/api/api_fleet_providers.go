@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// PER-PROVIDER FLEET HEALTH ENDPOINT
+// =============================================================================
+
+func (s *Server) handleGetProviderHealth(w http.ResponseWriter, r *http.Request) {
+	window := config.ProviderHealthDefaultWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		parsed, err := types.ParseDuration(v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid window")
+			return
+		}
+		window = parsed
+	}
+
+	report, err := s.svc.GetProviderHealth(r.Context(), window)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get provider health")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, report)
+}
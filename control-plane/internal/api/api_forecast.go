@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// LATENCY FORECAST ENDPOINT
+// =============================================================================
+
+func (s *Server) handleGetTargetForecast(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	if targetID == "" {
+		s.writeError(w, http.StatusBadRequest, "target ID required")
+		return
+	}
+
+	horizon := config.ForecastDefaultHorizon
+	if h := r.URL.Query().Get("horizon"); h != "" {
+		parsed, err := types.ParseDuration(h)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid horizon")
+			return
+		}
+		horizon = parsed
+	}
+
+	fc, err := s.svc.ForecastTargetLatency(r.Context(), targetID, horizon)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to forecast target latency")
+		return
+	}
+	if fc == nil {
+		s.writeError(w, http.StatusNotFound, "target not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, fc)
+}
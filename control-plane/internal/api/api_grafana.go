@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// GRAFANA DATA SOURCE ENDPOINTS
+// =============================================================================
+//
+// Point a Grafana JSON data source at /api/v1/grafana. The handlers answer
+// in the shapes the plugin expects, so errors aside nothing is wrapped.
+
+// handleGrafanaTest answers the data source's connection test.
+func (s *Server) handleGrafanaTest(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleGrafanaSearch lists metrics, group-by dimensions, tiers, agents or
+// targets for panel editors and template variables.
+func (s *Server) handleGrafanaSearch(w http.ResponseWriter, r *http.Request) {
+	var req types.GrafanaSearchRequest
+	s.readJSON(r, &req) // Ignore error, an empty search lists metrics
+
+	results, err := s.svc.GrafanaSearch(r.Context(), req)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to search")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, results)
+}
+
+// handleGrafanaQuery returns the time series for a panel's targets.
+func (s *Server) handleGrafanaQuery(w http.ResponseWriter, r *http.Request) {
+	var req types.GrafanaQueryRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	series, err := s.svc.GrafanaQuery(r.Context(), &req)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to query metrics")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, series)
+}
+
+// handleGrafanaAnnotations returns incidents and operator annotations in
+// the dashboard's range.
+func (s *Server) handleGrafanaAnnotations(w http.ResponseWriter, r *http.Request) {
+	var req types.GrafanaAnnotationRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	annotations, err := s.svc.GrafanaAnnotations(r.Context(), &req)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get annotations")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, annotations)
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// =============================================================================
+// HEALTH
+// =============================================================================
+//
+// Liveness and readiness are separate questions:
+//   - live: the process is up and serving HTTP. A failure means restart it.
+//   - ready: the database is reachable, migrations are applied and critical
+//     workers have completed a cycle. A failure means stop routing traffic
+//     to it, not restart it.
+
+// ReadinessCheck reports whether one dependency is ready, returning an error
+// that explains why not.
+type ReadinessCheck func(ctx context.Context) error
+
+type namedReadinessCheck struct {
+	name  string
+	check ReadinessCheck
+}
+
+// AddReadinessCheck registers a check for GET /api/v1/health/ready.
+// Must be called before the server starts handling requests.
+func (s *Server) AddReadinessCheck(name string, check ReadinessCheck) {
+	s.readinessChecks = append(s.readinessChecks, namedReadinessCheck{name: name, check: check})
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]string{
+		"status": "ok",
+		"time":   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// handleLive answers as long as the HTTP server can schedule a handler. It
+// deliberately checks no dependencies: a database outage should take the
+// instance out of rotation, not get it restarted.
+func (s *Server) handleLive(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, map[string]string{
+		"status": "alive",
+		"time":   time.Now().UTC().Format(time.RFC3339),
+	})
+}
+
+// handleReady runs every readiness check and returns 503 if any fail.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), config.ReadinessCheckTimeout)
+	defer cancel()
+
+	ready := true
+	checks := make(map[string]string, len(s.readinessChecks))
+	for _, c := range s.readinessChecks {
+		if err := c.check(ctx); err != nil {
+			ready = false
+			checks[c.name] = err.Error()
+			continue
+		}
+		checks[c.name] = "ok"
+	}
+
+	status, code := "ready", http.StatusOK
+	if !ready {
+		status, code = "not_ready", http.StatusServiceUnavailable
+	}
+	s.writeJSON(w, code, map[string]any{
+		"status": status,
+		"checks": checks,
+		"time":   time.Now().UTC().Format(time.RFC3339),
+	})
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// handleDeleteIncident permanently deletes a resolved incident raised by
+// testing or a bug, unlinking its alerts. Admin only: unlike resolving, it
+// rewrites incident history.
+func (s *Server) handleDeleteIncident(w http.ResponseWriter, r *http.Request) {
+	op, ok := s.operatorFor(r)
+	if !ok {
+		s.writeError(w, http.StatusUnauthorized, "operator token required")
+		return
+	}
+	if op.Role != types.OperatorRoleAdmin {
+		s.writeError(w, http.StatusForbidden, "deleting incidents requires the admin role")
+		return
+	}
+
+	incidentID := r.PathValue("id")
+	unlinked, err := s.svc.DeleteIncident(r.Context(), incidentID)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to delete incident")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"id":              incidentID,
+		"deleted":         true,
+		"alerts_unlinked": unlinked,
+	})
+}
@@ -0,0 +1,25 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+
+	"github.com/pilot-net/icmp-mon/pkg/resultpb"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// decodeResultBatch decodes an ingest body as a protobuf ResultBatch when
+// its content type says so, and as JSON otherwise, so agents that predate
+// protobuf shipping keep working.
+func decodeResultBatch(contentType string, body io.Reader, batch *types.ResultBatch) error {
+	if mediaType, _, _ := mime.ParseMediaType(contentType); mediaType != resultpb.ContentType {
+		return json.NewDecoder(body).Decode(batch)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return fmt.Errorf("reading body: %w", err)
+	}
+	return resultpb.Unmarshal(data, batch)
+}
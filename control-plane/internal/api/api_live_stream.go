@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// handleStreamTargetLive streams a target's probe results as Server-Sent
+// Events, so dashboards needn't poll GET /api/v1/targets/{id}/live. Each
+// new result is a "result" event carrying a LiveProbeResult, oldest first;
+// the first batch is the last config.LiveStreamLookback. A comment line is
+// sent whenever the stream has been quiet for config.LiveStreamKeepalive.
+// The stream ends when the client disconnects or the server shuts down.
+func (s *Server) handleStreamTargetLive(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	ctx := r.Context()
+
+	stream, err := s.svc.OpenLiveStream(ctx, targetID)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to open live stream")
+		return
+	}
+	defer stream.Close()
+
+	// The server's write timeout is meant for ordinary requests; a stream
+	// lasts as long as its client
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		s.logger.Warn("clearing live stream write deadline failed", "target", targetID, "error", err)
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // Disable nginx buffering
+	w.WriteHeader(http.StatusOK)
+
+	ticker := time.NewTicker(config.LiveStreamPollInterval)
+	defer ticker.Stop()
+	lastWrite := time.Now()
+
+	for {
+		results, err := stream.Poll(ctx)
+		if err != nil && ctx.Err() == nil {
+			// Keep the stream open; the next poll may well succeed
+			s.logger.Warn("live stream poll failed", "target", targetID, "error", err)
+		}
+		for _, result := range results {
+			data, err := json.Marshal(result)
+			if err != nil {
+				s.logger.Error("failed to marshal live result", "target", targetID, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: result\ndata: %s\n\n", data); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		}
+		if time.Since(lastWrite) >= config.LiveStreamKeepalive {
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+			lastWrite = time.Now()
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.streamsClosed:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// CloseStreams ends every open live stream. Register it with
+// http.Server.RegisterOnShutdown so streams don't hold up a graceful
+// shutdown until its deadline.
+func (s *Server) CloseStreams() {
+	s.closeStreamsOnce.Do(func() { close(s.streamsClosed) })
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// handleQueryMetricsGET runs a metrics query given as URL params, for
+// dashboards that can only issue GETs. See types.MetricsQueryFromValues
+// for the subset supported; the full language stays on POST.
+func (s *Server) handleQueryMetricsGET(w http.ResponseWriter, r *http.Request) {
+	query, err := types.MetricsQueryFromValues(r.URL.Query())
+	if err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.runMetricsQuery(w, r, query)
+}
+
+// runMetricsQuery validates and executes a metrics query, sharing cached
+// results between the POST and GET forms.
+func (s *Server) runMetricsQuery(w http.ResponseWriter, r *http.Request, query *types.MetricsQuery) {
+	if err := query.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	// Dashboards re-run identical queries across users; share results briefly
+	cacheKey, cacheable := query.CacheKey(time.Now())
+	cacheable = cacheable && s.cache != nil
+	if cacheable {
+		if data, err := s.cache.Get(r.Context(), cacheKey); err == nil && data != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+			return
+		}
+	}
+
+	result, err := s.svc.QueryMetrics(r.Context(), query)
+	if err != nil {
+		s.logger.Error("metrics query failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to execute metrics query")
+		return
+	}
+
+	if cacheable {
+		if err := s.cache.SetJSON(r.Context(), cacheKey, result, config.CacheTTLMetricsQuery); err != nil {
+			s.logger.Warn("failed to cache metrics query", "error", err)
+		}
+	}
+
+	s.writeJSON(w, http.StatusOK, result)
+}
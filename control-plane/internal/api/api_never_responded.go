@@ -0,0 +1,9 @@
+package api
+
+import "net/http"
+
+// handleGetNeverRespondedPolicy returns when auto targets that never answer
+// discovery are archived.
+func (s *Server) handleGetNeverRespondedPolicy(w http.ResponseWriter, r *http.Request) {
+	s.writeJSON(w, http.StatusOK, s.svc.NeverRespondedPolicy())
+}
@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
+)
+
+// =============================================================================
+// INCIDENT POSTMORTEM ENDPOINT
+// =============================================================================
+
+func (s *Server) handleGetIncidentPostmortem(w http.ResponseWriter, r *http.Request) {
+	incidentID := r.PathValue("id")
+	if incidentID == "" {
+		s.writeError(w, http.StatusBadRequest, "incident ID required")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "markdown" {
+		s.writeError(w, http.StatusBadRequest, "format must be json or markdown")
+		return
+	}
+
+	pm, err := s.svc.GetIncidentPostmortem(r.Context(), incidentID)
+	if err != nil {
+		s.logger.Error("build incident postmortem failed", "incident", incidentID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to build incident postmortem")
+		return
+	}
+	if pm == nil {
+		s.writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+
+	if format == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(service.RenderPostmortemMarkdown(pm)))
+		return
+	}
+	s.writeJSON(w, http.StatusOK, pm)
+}
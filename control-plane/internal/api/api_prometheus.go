@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
+)
+
+// handlePrometheusMetrics serves target and fleet gauges for Prometheus to
+// scrape. The exposition is cached briefly, so scrapes from several
+// Prometheus replicas cost one set of queries.
+func (s *Server) handlePrometheusMetrics(w http.ResponseWriter, r *http.Request) {
+	const cacheKey = "prometheus_metrics"
+
+	var body []byte
+	if s.cache != nil {
+		if data, err := s.cache.Get(r.Context(), cacheKey); err == nil {
+			body = data
+		}
+	}
+	if body == nil {
+		var err error
+		body, err = s.svc.PrometheusMetrics(r.Context())
+		if err != nil {
+			s.logger.Error("prometheus metrics failed", "error", err)
+			s.writeError(w, http.StatusInternalServerError, "failed to collect metrics")
+			return
+		}
+		if s.cache != nil {
+			if err := s.cache.Set(r.Context(), cacheKey, body, config.CacheTTLPrometheus); err != nil {
+				s.logger.Warn("failed to cache prometheus metrics", "error", err)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", service.PrometheusContentType)
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
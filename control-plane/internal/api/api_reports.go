@@ -0,0 +1,190 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// REPORT SCHEDULE ENDPOINTS
+// =============================================================================
+
+type reportScheduleRequest struct {
+	Name           string                     `json:"name"`
+	SubscriberName string                     `json:"subscriber_name"`
+	TargetIDs      []string                   `json:"target_ids"`
+	WindowDays     int                        `json:"window_days"`
+	CronExpression string                     `json:"cron_expression"`
+	DeliveryMethod types.ReportDeliveryMethod `json:"delivery_method"`
+	Recipients     []string                   `json:"recipients"`
+	WebhookURL     string                     `json:"webhook_url"`
+	Format         types.ReportFormat         `json:"format"`
+	Enabled        *bool                      `json:"enabled"`
+}
+
+// defaultReportWindowDays is used when a schedule doesn't specify a window.
+const defaultReportWindowDays = 30
+
+func (req *reportScheduleRequest) toSchedule(id string) *types.ReportSchedule {
+	rs := &types.ReportSchedule{
+		ID:             id,
+		Name:           strings.TrimSpace(req.Name),
+		SubscriberName: strings.TrimSpace(req.SubscriberName),
+		TargetIDs:      req.TargetIDs,
+		WindowDays:     req.WindowDays,
+		CronExpression: strings.TrimSpace(req.CronExpression),
+		DeliveryMethod: req.DeliveryMethod,
+		Recipients:     req.Recipients,
+		WebhookURL:     strings.TrimSpace(req.WebhookURL),
+		Format:         req.Format,
+		Enabled:        true,
+	}
+	if rs.WindowDays == 0 {
+		rs.WindowDays = defaultReportWindowDays
+	}
+	if rs.Format == "" {
+		rs.Format = types.ReportFormatPDF
+	}
+	if req.Enabled != nil {
+		rs.Enabled = *req.Enabled
+	}
+	return rs
+}
+
+func (s *Server) handleListReportSchedules(w http.ResponseWriter, r *http.Request) {
+	schedules, err := s.svc.ListReportSchedules(r.Context())
+	if err != nil {
+		s.logger.Error("list report schedules failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list report schedules")
+		return
+	}
+	if schedules == nil {
+		schedules = []types.ReportSchedule{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"schedules": schedules,
+		"count":     len(schedules),
+	})
+}
+
+func (s *Server) handleGetReportSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	rs, err := s.svc.GetReportSchedule(r.Context(), id)
+	if err != nil {
+		s.logger.Error("get report schedule failed", "schedule_id", id, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to get report schedule")
+		return
+	}
+	if rs == nil {
+		s.writeError(w, http.StatusNotFound, "report schedule not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, rs)
+}
+
+func (s *Server) handleCreateReportSchedule(w http.ResponseWriter, r *http.Request) {
+	var req reportScheduleRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rs := req.toSchedule("")
+	if err := service.ValidateReportSchedule(rs); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.svc.CreateReportSchedule(r.Context(), rs); err != nil {
+		s.writeServiceError(w, err, "failed to create report schedule")
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, rs)
+}
+
+func (s *Server) handleUpdateReportSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	var req reportScheduleRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	rs := req.toSchedule(id)
+	if err := service.ValidateReportSchedule(rs); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.svc.UpdateReportSchedule(r.Context(), rs); err != nil {
+		s.writeServiceError(w, err, "failed to update report schedule")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, rs)
+}
+
+func (s *Server) handleDeleteReportSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	if err := s.svc.DeleteReportSchedule(r.Context(), id); err != nil {
+		s.writeServiceError(w, err, "failed to delete report schedule")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleRunReportSchedule(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	found, err := s.svc.TriggerReportSchedule(r.Context(), id)
+	if err != nil {
+		s.logger.Error("trigger report schedule failed", "schedule_id", id, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to trigger report schedule")
+		return
+	}
+	if !found {
+		s.writeError(w, http.StatusNotFound, "report schedule not found")
+		return
+	}
+
+	s.writeJSON(w, http.StatusAccepted, map[string]string{
+		"status":  "queued",
+		"message": "Report will be generated on the next scheduler run",
+	})
+}
+
+func (s *Server) handleListReportDeliveries(w http.ResponseWriter, r *http.Request) {
+	id := r.PathValue("id")
+
+	limit := config.DefaultPaginationLimit
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 {
+		limit = min(l, config.MaxPaginationLimit)
+	}
+
+	deliveries, err := s.svc.ListReportDeliveries(r.Context(), id, limit)
+	if err != nil {
+		s.logger.Error("list report deliveries failed", "schedule_id", id, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list report deliveries")
+		return
+	}
+	if deliveries == nil {
+		deliveries = []types.ReportDelivery{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"deliveries": deliveries,
+		"count":      len(deliveries),
+	})
+}
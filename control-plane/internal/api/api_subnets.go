@@ -1,8 +1,10 @@
 package api
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
@@ -16,12 +18,13 @@ import (
 func (s *Server) handleListSubnets(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query()
 
-	// Check if pagination is requested
+	// Check if pagination is requested (?cursor= selects keyset pagination)
 	limitStr := query.Get("limit")
 	offsetStr := query.Get("offset")
+	_, useCursor := query["cursor"]
 
 	// If pagination params present, use paginated endpoint
-	if limitStr != "" || offsetStr != "" {
+	if limitStr != "" || offsetStr != "" || useCursor {
 		params := store.SubnetListParams{
 			POPName:         query.Get("pop"),
 			City:            query.Get("city"),
@@ -46,11 +49,14 @@ func (s *Server) handleListSubnets(w http.ResponseWriter, r *http.Request) {
 				params.SubscriberID = &subID
 			}
 		}
+		if useCursor {
+			cursor := query.Get("cursor")
+			params.Cursor = &cursor
+		}
 
 		result, err := s.svc.ListSubnetsPaginated(r.Context(), params)
 		if err != nil {
-			s.logger.Error("list subnets paginated failed", "error", err)
-			s.writeError(w, http.StatusInternalServerError, "failed to list subnets")
+			s.writeServiceError(w, err, "failed to list subnets")
 			return
 		}
 
@@ -325,6 +331,38 @@ func (s *Server) handleSeedSubnetTargets(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+func (s *Server) handleSweepSubnet(w http.ResponseWriter, r *http.Request) {
+	subnetID := r.PathValue("id")
+	if subnetID == "" {
+		s.writeError(w, http.StatusBadRequest, "subnet ID required")
+		return
+	}
+
+	// Optional body: specific agents and whether to seed what answers
+	var req struct {
+		AgentIDs []string `json:"agent_ids,omitempty"`
+		AutoSeed bool     `json:"auto_seed,omitempty"`
+	}
+	s.readJSON(r, &req) // Ignore error, use defaults if not provided
+
+	cmd, err := s.svc.CreateSubnetSweep(r.Context(), subnetID, req.AgentIDs, req.AutoSeed)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to create subnet sweep")
+		return
+	}
+
+	s.writeJSON(w, http.StatusAccepted, map[string]any{
+		"command_id":   cmd.ID,
+		"command_type": cmd.CommandType,
+		"subnet_id":    subnetID,
+		"addresses":    len(cmd.Params["ips"].([]string)),
+		"agent_ids":    cmd.AgentIDs,
+		"auto_seed":    req.AutoSeed,
+		"status":       cmd.Status,
+		"message":      "subnet sweep queued; results at /api/v1/commands/" + cmd.ID,
+	})
+}
+
 // =============================================================================
 // TARGET STATE ENDPOINTS
 // =============================================================================
@@ -464,6 +502,76 @@ func (s *Server) handleGetTargetStateHistory(w http.ResponseWriter, r *http.Requ
 	})
 }
 
+func (s *Server) handleGetTargetHops(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	if targetID == "" {
+		s.writeError(w, http.StatusBadRequest, "target ID required")
+		return
+	}
+
+	// Get window from query param, default to 24 hours
+	window := 24 * time.Hour
+	if windowStr := r.URL.Query().Get("window"); windowStr != "" {
+		if parsed, err := time.ParseDuration(windowStr); err == nil && parsed > 0 {
+			window = parsed
+		}
+	}
+
+	history, err := s.svc.GetTargetHopHistory(r.Context(), targetID, window)
+	if err != nil {
+		s.logger.Error("get target hop history failed", "target", targetID, "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to get target hop history")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"target_id": targetID,
+		"window":    window.String(),
+		"points":    history.Points,
+		"changes":   history.Changes,
+	})
+}
+
+func (s *Server) handleListTargetResults(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	if targetID == "" {
+		s.writeError(w, http.StatusBadRequest, "target ID required")
+		return
+	}
+
+	query := r.URL.Query()
+	params := store.ProbeResultListParams{
+		TargetID:  targetID,
+		AgentID:   query.Get("agent_id"),
+		Cursor:    query.Get("cursor"),
+		ProbeType: query.Get("probe_type"),
+	}
+	if v := query.Get("reply_ttl"); v != "" {
+		ttl, err := strconv.Atoi(v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "invalid reply_ttl")
+			return
+		}
+		params.ReplyTTL = &ttl
+	}
+	if limit, err := strconv.Atoi(query.Get("limit")); err == nil {
+		params.Limit = limit
+	}
+	if windowStr := query.Get("window"); windowStr != "" {
+		if window, err := time.ParseDuration(windowStr); err == nil && window > 0 {
+			params.Since = time.Now().Add(-window)
+		}
+	}
+
+	result, err := s.svc.ListProbeResults(r.Context(), params)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to list probe results")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, result)
+}
+
 // =============================================================================
 // TARGET UPDATE/DELETE
 // =============================================================================
@@ -474,6 +582,12 @@ type updateTargetRequest struct {
 	DisplayName     string             `json:"display_name,omitempty"`
 	Notes           string             `json:"notes,omitempty"`
 	ExpectedOutcome *types.ExpectedOutcome `json:"expected_outcome,omitempty"`
+	DSCP            *int               `json:"dscp,omitempty"`
+	Region          *string            `json:"region,omitempty"`
+	RetentionDays   *int               `json:"retention_days,omitempty"`
+	DownThreshold   *int               `json:"down_threshold_seconds,omitempty"`
+	ProbeType       *string            `json:"probe_type,omitempty"` // "" resets to icmp_ping
+	ProbeParams     json.RawMessage    `json:"probe_params,omitempty"`
 }
 
 func (s *Server) handleUpdateTarget(w http.ResponseWriter, r *http.Request) {
@@ -488,6 +602,28 @@ func (s *Server) handleUpdateTarget(w http.ResponseWriter, r *http.Request) {
 		s.writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	if err := types.ValidateDSCP(req.DSCP); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateRetentionDays(req.RetentionDays); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := types.ValidateDownThreshold(req.DownThreshold); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := req.ExpectedOutcome.Validate(); err != nil {
+		s.writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if req.ProbeType != nil {
+		if err := types.ValidateProbeType(*req.ProbeType, req.ProbeParams); err != nil {
+			s.writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
 
 	target, err := s.svc.UpdateTarget(r.Context(), service.UpdateTargetRequest{
 		ID:              targetID,
@@ -496,6 +632,12 @@ func (s *Server) handleUpdateTarget(w http.ResponseWriter, r *http.Request) {
 		DisplayName:     req.DisplayName,
 		Notes:           req.Notes,
 		ExpectedOutcome: req.ExpectedOutcome,
+		DSCP:            req.DSCP,
+		Region:          req.Region,
+		RetentionDays:   req.RetentionDays,
+		DownThreshold:   req.DownThreshold,
+		ProbeType:       req.ProbeType,
+		ProbeParams:     req.ProbeParams,
 	})
 	if err != nil {
 		s.logger.Error("update target failed", "target", targetID, "error", err)
@@ -532,6 +674,43 @@ func (s *Server) handleDeleteTarget(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+func (s *Server) handleDisableTargetProbing(w http.ResponseWriter, r *http.Request) {
+	s.setTargetProbing(w, r, false)
+}
+
+func (s *Server) handleEnableTargetProbing(w http.ResponseWriter, r *http.Request) {
+	s.setTargetProbing(w, r, true)
+}
+
+// setTargetProbing toggles probing for a target. Unlike archiving or the
+// INACTIVE state, the target keeps its monitoring state and history.
+func (s *Server) setTargetProbing(w http.ResponseWriter, r *http.Request, enabled bool) {
+	targetID := r.PathValue("id")
+	if targetID == "" {
+		s.writeError(w, http.StatusBadRequest, "target ID required")
+		return
+	}
+
+	var req struct {
+		Reason      string `json:"reason,omitempty"`
+		TriggeredBy string `json:"triggered_by,omitempty"`
+	}
+	if err := s.readJSON(r, &req); err != nil {
+		// Body is optional
+	}
+	if req.TriggeredBy == "" {
+		req.TriggeredBy = "api"
+	}
+
+	target, err := s.svc.SetTargetProbing(r.Context(), targetID, enabled, req.Reason, req.TriggeredBy)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to update target probing")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, target)
+}
+
 // =============================================================================
 // TARGET TAG ENDPOINTS
 // =============================================================================
@@ -635,10 +814,16 @@ func (s *Server) handleGetSubnetActivity(w http.ResponseWriter, r *http.Request)
 		s.writeError(w, http.StatusInternalServerError, "failed to get subnet activity")
 		return
 	}
+	statusChanges, err := s.svc.GetSubnetServiceStatusChanges(r.Context(), subnetID)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get subnet service status changes")
+		return
+	}
 
 	s.writeJSON(w, http.StatusOK, map[string]any{
-		"subnet_id": subnetID,
-		"activity":  activity,
-		"count":     len(activity),
+		"subnet_id":              subnetID,
+		"activity":               activity,
+		"count":                  len(activity),
+		"service_status_changes": statusChanges,
 	})
 }
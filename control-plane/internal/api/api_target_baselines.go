@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// handleRecalculateTargetBaselines recalculates the baselines of every agent
+// probing a target and returns them.
+func (s *Server) handleRecalculateTargetBaselines(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	start := time.Now()
+	baselines, err := s.svc.RecalculateTargetBaselines(r.Context(), targetID)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to recalculate target baselines")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"target_id":   targetID,
+		"baselines":   baselines,
+		"count":       len(baselines),
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+}
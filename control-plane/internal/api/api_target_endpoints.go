@@ -0,0 +1,123 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET ENDPOINT ENDPOINTS
+// =============================================================================
+
+type targetEndpointRequest struct {
+	IP    string `json:"ip"`
+	Port  *int   `json:"port,omitempty"`
+	Label string `json:"label,omitempty"`
+}
+
+func (s *Server) handleListTargetEndpoints(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	endpoints, err := s.svc.ListTargetEndpoints(r.Context(), targetID)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to list target endpoints")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"target_id": targetID,
+		"endpoints": endpoints,
+		"count":     len(endpoints),
+	})
+}
+
+func (s *Server) handleCreateTargetEndpoint(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	var req targetEndpointRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	e, err := s.svc.AddTargetEndpoint(r.Context(), targetID, service.AddTargetEndpointRequest{
+		IP:    req.IP,
+		Port:  req.Port,
+		Label: req.Label,
+	})
+	if err != nil {
+		s.writeServiceError(w, err, "failed to add target endpoint")
+		return
+	}
+
+	s.writeJSON(w, http.StatusCreated, e)
+}
+
+func (s *Server) handleDeleteTargetEndpoint(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+	endpointID := r.PathValue("endpoint_id")
+
+	if err := s.svc.DeleteTargetEndpoint(r.Context(), targetID, endpointID); err != nil {
+		s.writeServiceError(w, err, "failed to delete target endpoint")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) handleGetTargetEndpointStatus(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	window, ok := s.endpointWindow(w, r, config.EndpointStatusDefaultWindow)
+	if !ok {
+		return
+	}
+
+	status, err := s.svc.GetTargetEndpointStatus(r.Context(), targetID, window)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get target endpoint status")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, status)
+}
+
+func (s *Server) handleGetTargetHistoryByEndpoint(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	window, ok := s.endpointWindow(w, r, config.EndpointHistoryDefaultWindow)
+	if !ok {
+		return
+	}
+
+	history, err := s.svc.GetTargetEndpointHistory(r.Context(), targetID, window)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get target endpoint history")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"target_id": targetID,
+		"window":    window.String(),
+		"history":   history,
+	})
+}
+
+// endpointWindow parses the window query parameter, writing a 400 and
+// returning false if it is malformed or out of range.
+func (s *Server) endpointWindow(w http.ResponseWriter, r *http.Request, def time.Duration) (time.Duration, bool) {
+	v := r.URL.Query().Get("window")
+	if v == "" {
+		return def, true
+	}
+	window, err := types.ParseDuration(v)
+	if err != nil || window <= 0 || window > config.EndpointStatusMaxWindow {
+		s.writeError(w, http.StatusBadRequest, "invalid window")
+		return 0, false
+	}
+	return window, true
+}
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// BULK TIER REASSIGNMENT ENDPOINT
+// =============================================================================
+
+type bulkTierRequest struct {
+	Filter      *types.TargetFilter `json:"filter"`
+	Tier        string              `json:"tier"`
+	TriggeredBy string              `json:"triggered_by,omitempty"`
+}
+
+func (s *Server) handleBulkReassignTier(w http.ResponseWriter, r *http.Request) {
+	var req bulkTierRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.TriggeredBy == "" {
+		req.TriggeredBy = "api"
+	}
+
+	result, err := s.svc.BulkReassignTier(r.Context(), req.Filter, strings.TrimSpace(req.Tier), req.TriggeredBy)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to reassign targets")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, result)
+}
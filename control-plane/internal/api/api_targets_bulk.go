@@ -0,0 +1,59 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
+)
+
+// =============================================================================
+// BULK TARGET CREATION ENDPOINT
+// =============================================================================
+
+// serviceRequest converts a create request for the service, defaulting the
+// tier to standard.
+func (req *createTargetRequest) serviceRequest(onDuplicate service.OnDuplicate) service.CreateTargetRequest {
+	tier := req.Tier
+	if tier == "" {
+		tier = "standard"
+	}
+	return service.CreateTargetRequest{
+		IP:              req.IP,
+		Tier:            tier,
+		SubscriberID:    req.SubscriberID,
+		Tags:            req.Tags,
+		ExpectedOutcome: req.ExpectedOutcome,
+		DSCP:            req.DSCP,
+		Region:          req.Region,
+		RetentionDays:   req.RetentionDays,
+		DownThreshold:   req.DownThreshold,
+		ProbeType:       req.ProbeType,
+		ProbeParams:     req.ProbeParams,
+		OnDuplicate:     onDuplicate,
+	}
+}
+
+// handleBulkCreateTargets creates an array of targets in one transaction.
+// Rows fail or are skipped individually, so the response is 200 whenever
+// the batch itself was acceptable; each row's status says what happened.
+// on_duplicate is not used: a row whose IP is taken is skipped.
+func (s *Server) handleBulkCreateTargets(w http.ResponseWriter, r *http.Request) {
+	var reqs []createTargetRequest
+	if err := s.readJSON(r, &reqs); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body: expected an array of targets")
+		return
+	}
+
+	svcReqs := make([]service.CreateTargetRequest, len(reqs))
+	for i := range reqs {
+		svcReqs[i] = reqs[i].serviceRequest("")
+	}
+
+	result, err := s.svc.BulkCreateTargets(r.Context(), svcReqs)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to create targets")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, result)
+}
@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+// =============================================================================
+// TIER INTERVAL PREVIEW ENDPOINT
+// =============================================================================
+
+type tierPreviewRequest struct {
+	ProbeIntervalS int `json:"probe_interval_seconds"`
+}
+
+// handlePreviewTier projects probe volume and per-agent load at a proposed
+// probe interval without changing the tier.
+func (s *Server) handlePreviewTier(w http.ResponseWriter, r *http.Request) {
+	var req tierPreviewRequest
+	if err := s.readJSON(r, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	preview, err := s.svc.PreviewTierInterval(r.Context(), r.PathValue("name"),
+		time.Duration(req.ProbeIntervalS)*time.Second)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to preview tier change")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, preview)
+}
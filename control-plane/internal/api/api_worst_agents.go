@@ -0,0 +1,41 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func (s *Server) handleGetTargetWorstAgents(w http.ResponseWriter, r *http.Request) {
+	targetID := r.PathValue("id")
+
+	window := config.WorstAgentsDefaultWindow
+	if v := r.URL.Query().Get("window"); v != "" {
+		parsed, err := types.ParseDuration(v)
+		if err != nil || parsed <= 0 || parsed > config.WorstAgentsMaxWindow {
+			s.writeError(w, http.StatusBadRequest, "invalid window")
+			return
+		}
+		window = parsed
+	}
+
+	limit := config.WorstAgentsDefaultLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		parsed, err := strconv.Atoi(v)
+		if err != nil || parsed <= 0 || parsed > config.WorstAgentsMaxLimit {
+			s.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		limit = parsed
+	}
+
+	ranking, err := s.svc.GetTargetWorstAgents(r.Context(), targetID, window, limit)
+	if err != nil {
+		s.writeServiceError(w, err, "failed to get worst agents")
+		return
+	}
+
+	s.writeJSON(w, http.StatusOK, ranking)
+}
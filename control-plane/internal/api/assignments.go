@@ -111,7 +111,5 @@ func (h *AssignmentHandler) writeJSON(w http.ResponseWriter, status int, v any)
 }
 
 func (h *AssignmentHandler) writeError(w http.ResponseWriter, status int, message string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	w.Write([]byte(`{"error":"` + message + `"}`))
+	writeError(w, status, message)
 }
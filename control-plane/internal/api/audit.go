@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// OPERATOR IDENTITY & AUDIT LOG
+// =============================================================================
+//
+// Operators identify themselves to the management API with a bearer token
+// from ICMPMON_OPERATOR_TOKENS. The management API stays open to requests
+// without a token (they are audited as anonymous); tokens are required only
+// where a role is, such as reading the audit log.
+
+// Operator is an authenticated management API caller.
+type Operator struct {
+	Name string
+	Role string
+}
+
+type operatorToken struct {
+	hash     [sha256.Size]byte
+	operator Operator
+}
+
+// ParseOperatorTokens parses "name:role:token" entries separated by commas.
+// Role is admin or operator.
+func ParseOperatorTokens(spec string) (map[string]Operator, error) {
+	tokens := make(map[string]Operator)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("operator token entry must be name:role:token")
+		}
+		role := parts[1]
+		if role != types.OperatorRoleAdmin && role != types.OperatorRoleOperator {
+			return nil, fmt.Errorf("operator %q: unknown role %q", parts[0], role)
+		}
+		tokens[parts[2]] = Operator{Name: parts[0], Role: role}
+	}
+	return tokens, nil
+}
+
+// SetOperatorTokens configures the tokens that identify operators, keyed by
+// token. Only hashes of the tokens are kept.
+func (s *Server) SetOperatorTokens(tokens map[string]Operator) {
+	s.operatorTokens = make([]operatorToken, 0, len(tokens))
+	for token, op := range tokens {
+		s.operatorTokens = append(s.operatorTokens, operatorToken{hash: sha256.Sum256([]byte(token)), operator: op})
+	}
+	s.logger.Info("operator tokens configured", "count", len(s.operatorTokens))
+}
+
+// operatorFor resolves the bearer token on a request to an operator.
+func (s *Server) operatorFor(r *http.Request) (Operator, bool) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return Operator{}, false
+	}
+	hash := sha256.Sum256([]byte(token))
+	for _, t := range s.operatorTokens {
+		if subtle.ConstantTimeCompare(hash[:], t.hash[:]) == 1 {
+			return t.operator, true
+		}
+	}
+	return Operator{}, false
+}
+
+// isAuditedRequest reports whether a request is a management mutation.
+// Agent-to-control-plane calls (registration, heartbeats, command results,
+// result ingest) are operational traffic, not operator actions.
+func isAuditedRequest(r *http.Request) bool {
+	switch r.Method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return false
+	}
+	path := r.URL.Path
+	if !strings.HasPrefix(path, "/api/v1/") || r.Header.Get("X-Agent-ID") != "" {
+		return false
+	}
+	switch {
+	case path == "/api/v1/agents/register", path == "/api/v1/results":
+		return false
+	case strings.HasPrefix(path, "/api/v1/agents/") &&
+		(strings.HasSuffix(path, "/heartbeat") || strings.HasSuffix(path, "/result")):
+		return false
+	}
+	return true
+}
+
+// auditEntity extracts the entity type and ID from /api/v1/{type}/{id}/...
+func auditEntity(path string) (entityType, entityID string) {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v1/"), "/"), "/")
+	entityType = segments[0]
+	if len(segments) > 1 {
+		entityID = segments[1]
+	}
+	return entityType, entityID
+}
+
+// statusRecorder captures the response status for the audit entry.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.status == 0 {
+		r.status = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming responses (enrollment progress) working.
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// serveAudited runs a management mutation and records it in the audit log.
+// A failure to record is logged but does not fail the request, which has
+// already been applied.
+func (s *Server) serveAudited(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+
+	var bodyHash string
+	if r.Body != nil {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, "failed to read request body")
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		if len(body) > 0 {
+			sum := sha256.Sum256(body)
+			bodyHash = hex.EncodeToString(sum[:])
+		}
+	}
+
+	rec := &statusRecorder{ResponseWriter: w}
+	s.mux.ServeHTTP(rec, r)
+	if rec.status == 0 {
+		rec.status = http.StatusOK
+	}
+
+	entry := &types.AuditEntry{
+		Time:       start,
+		Actor:      types.AuditActorAnonymous,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		BodySHA256: bodyHash,
+		StatusCode: rec.status,
+		Result:     types.AuditResultSuccess,
+		RemoteAddr: r.RemoteAddr,
+		DurationMs: int(time.Since(start).Milliseconds()),
+	}
+	if op, ok := s.operatorFor(r); ok {
+		entry.Actor, entry.ActorRole = op.Name, op.Role
+	}
+	if rec.status >= http.StatusBadRequest {
+		entry.Result = types.AuditResultFailure
+	}
+	entry.EntityType, entry.EntityID = auditEntity(r.URL.Path)
+
+	if err := s.svc.RecordAudit(context.WithoutCancel(r.Context()), entry); err != nil {
+		s.logger.Error("failed to record audit entry",
+			"method", entry.Method, "path", entry.Path, "actor", entry.Actor, "error", err)
+	}
+}
+
+// =============================================================================
+// AUDIT LOG ENDPOINT
+// =============================================================================
+
+func (s *Server) handleListAudit(w http.ResponseWriter, r *http.Request) {
+	op, ok := s.operatorFor(r)
+	if !ok {
+		s.writeError(w, http.StatusUnauthorized, "operator token required")
+		return
+	}
+	if op.Role != types.OperatorRoleAdmin {
+		s.writeError(w, http.StatusForbidden, "audit log requires the admin role")
+		return
+	}
+
+	q := r.URL.Query()
+	filter := types.AuditFilter{
+		Actor:      q.Get("actor"),
+		EntityType: q.Get("entity_type"),
+		EntityID:   q.Get("entity_id"),
+	}
+	for _, param := range []struct {
+		name string
+		dst  **time.Time
+	}{{"since", &filter.Since}, {"until", &filter.Until}} {
+		v := q.Get(param.name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			s.writeError(w, http.StatusBadRequest, param.name+" must be an RFC 3339 time")
+			return
+		}
+		*param.dst = &t
+	}
+	if v := q.Get("limit"); v != "" {
+		limit, err := parseInt(v)
+		if err != nil || limit <= 0 {
+			s.writeError(w, http.StatusBadRequest, "invalid limit")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	entries, err := s.svc.ListAuditEntries(r.Context(), filter)
+	if err != nil {
+		s.logger.Error("list audit entries failed", "error", err)
+		s.writeError(w, http.StatusInternalServerError, "failed to list audit entries")
+		return
+	}
+	if entries == nil {
+		entries = []types.AuditEntry{}
+	}
+
+	s.writeJSON(w, http.StatusOK, map[string]any{
+		"entries": entries,
+		"count":   len(entries),
+	})
+}
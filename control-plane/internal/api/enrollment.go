@@ -18,13 +18,6 @@ func writeJSON(w http.ResponseWriter, status int, v any) {
 	json.NewEncoder(w).Encode(v)
 }
 
-// writeError writes a JSON error response.
-func writeError(w http.ResponseWriter, status int, message string) {
-	writeJSON(w, status, map[string]string{
-		"error": message,
-	})
-}
-
 // EnrollmentHandler handles agent enrollment API requests.
 type EnrollmentHandler struct {
 	service *enrollment.Service
@@ -0,0 +1,116 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
+)
+
+// =============================================================================
+// ERROR RESPONSES
+// =============================================================================
+//
+// Every error response has the same shape:
+//
+//	{"error": {"code": "tier_in_use", "message": "...", "details": {...}}}
+//
+// Codes are stable and meant for clients to branch on; messages are for
+// people and may change. Details are present only when the error carries
+// structured context.
+
+// Error codes.
+const (
+	codeInvalidRequest = "invalid_request"
+	codeInvalidInput   = "invalid_input"
+	codeInvalidCursor  = "invalid_cursor"
+	codeUnauthorized   = "unauthorized"
+	codeForbidden      = "forbidden"
+	codeNotFound       = "not_found"
+	codeConflict       = "conflict"
+	codeTierInUse      = "tier_in_use"
+	codeRateLimited    = "rate_limited"
+	codeNotImplemented = "not_implemented"
+	codeUnavailable    = "unavailable"
+	codeInternal       = "internal"
+)
+
+type errorBody struct {
+	Code    string         `json:"code"`
+	Message string         `json:"message"`
+	Details map[string]any `json:"details,omitempty"`
+}
+
+// writeErrorBody writes an error response with an explicit code.
+func writeErrorBody(w http.ResponseWriter, status int, code, message string, details map[string]any) {
+	writeJSON(w, status, map[string]errorBody{
+		"error": {Code: code, Message: message, Details: details},
+	})
+}
+
+// writeError writes an error response whose code follows from the status.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeErrorBody(w, status, statusCode(status), message, nil)
+}
+
+// statusCode is the default error code for an HTTP status.
+func statusCode(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return codeInvalidRequest
+	case http.StatusUnauthorized:
+		return codeUnauthorized
+	case http.StatusForbidden:
+		return codeForbidden
+	case http.StatusNotFound:
+		return codeNotFound
+	case http.StatusConflict:
+		return codeConflict
+	case http.StatusTooManyRequests:
+		return codeRateLimited
+	case http.StatusNotImplemented:
+		return codeNotImplemented
+	case http.StatusServiceUnavailable:
+		return codeUnavailable
+	}
+	if status >= http.StatusInternalServerError {
+		return codeInternal
+	}
+	return codeInvalidRequest
+}
+
+// serviceErrors maps domain error kinds to responses, most specific first.
+var serviceErrors = []struct {
+	kind   error
+	status int
+	code   string
+}{
+	{service.ErrTierInUse, http.StatusConflict, codeTierInUse},
+	{service.ErrInvalidCursor, http.StatusBadRequest, codeInvalidCursor},
+	{service.ErrInvalidInput, http.StatusBadRequest, codeInvalidInput},
+	{service.ErrNotFound, http.StatusNotFound, codeNotFound},
+	{service.ErrConflict, http.StatusConflict, codeConflict},
+	{service.ErrRateLimited, http.StatusTooManyRequests, codeRateLimited},
+}
+
+// writeServiceError writes the response for an error returned by the
+// service layer. Domain errors get their mapped status and code with the
+// error's own message; anything else is logged and reported as a 500 with
+// fallback as the message, so internal details don't leak to clients.
+func (s *Server) writeServiceError(w http.ResponseWriter, err error, fallback string) {
+	for _, m := range serviceErrors {
+		if !errors.Is(err, m.kind) {
+			continue
+		}
+		message, details := err.Error(), map[string]any(nil)
+		var domainErr *service.Error
+		if errors.As(err, &domainErr) {
+			message, details = domainErr.Message, domainErr.Details
+		}
+		writeErrorBody(w, m.status, m.code, message, details)
+		return
+	}
+
+	s.logger.Error(fallback, "error", err)
+	writeError(w, http.StatusInternalServerError, fallback)
+}
@@ -42,7 +42,7 @@ func (s *Server) AgentAuthMiddleware(config AgentAuthConfig) func(http.Handler)
 						"agent_id", agentID,
 						"has_auth_header", authHeader != "",
 					)
-					http.Error(w, "unauthorized: missing credentials", http.StatusUnauthorized)
+					writeError(w, http.StatusUnauthorized, "unauthorized: missing credentials")
 					return
 				}
 				// Grace period: log but allow
@@ -63,7 +63,7 @@ func (s *Server) AgentAuthMiddleware(config AgentAuthConfig) func(http.Handler)
 					"agent_id", agentID,
 					"error", err,
 				)
-				http.Error(w, "internal server error", http.StatusInternalServerError)
+				writeError(w, http.StatusInternalServerError, "internal server error")
 				return
 			}
 
@@ -74,7 +74,7 @@ func (s *Server) AgentAuthMiddleware(config AgentAuthConfig) func(http.Handler)
 						"agent_id", agentID,
 						"path", r.URL.Path,
 					)
-					http.Error(w, "unauthorized: no API key configured", http.StatusUnauthorized)
+					writeError(w, http.StatusUnauthorized, "unauthorized: no API key configured")
 					return
 				}
 				// Grace period: log but allow
@@ -92,7 +92,7 @@ func (s *Server) AgentAuthMiddleware(config AgentAuthConfig) func(http.Handler)
 						"agent_id", agentID,
 						"path", r.URL.Path,
 					)
-					http.Error(w, "unauthorized: invalid API key", http.StatusUnauthorized)
+					writeError(w, http.StatusUnauthorized, "unauthorized: invalid API key")
 					return
 				}
 				// Grace period: log but allow
@@ -64,9 +64,7 @@ func (h *RolloutHandler) handleListReleases(w http.ResponseWriter, r *http.Reque
 // POST /api/v1/releases
 func (h *RolloutHandler) handleCreateRelease(w http.ResponseWriter, r *http.Request) {
 	// TODO: Implement with store and binary upload
-	writeJSON(w, http.StatusNotImplemented, map[string]string{
-		"error": "release upload not yet implemented",
-	})
+	writeError(w, http.StatusNotImplemented, "release upload not yet implemented")
 }
 
 // handleGetRelease returns release details.
@@ -79,9 +77,7 @@ func (h *RolloutHandler) handleGetRelease(w http.ResponseWriter, r *http.Request
 	}
 
 	// TODO: Implement with store
-	writeJSON(w, http.StatusNotFound, map[string]string{
-		"error": "release not found",
-	})
+	writeError(w, http.StatusNotFound, "release not found")
 }
 
 // handlePublishRelease publishes a release.
@@ -0,0 +1,372 @@
+package buffer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/payload"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// AGGREGATE-AT-INGEST
+// =============================================================================
+//
+// Results for tiers with an aggregated ingest mode are rolled up into one
+// Redis hash per bucket (icmpmon:agg:<unix start>:<width seconds>), with
+// fields "<agent>|<target>|<stat>". The width is the tier's aggregate
+// bucket, so tiers of different granularity never share a hash. A sorted
+// set indexes the open buckets by when they end so the flusher can collect
+// the ones past their grace period. Every stat is a count, a sum or a
+// min/max, so rollups from several control planes, and late results for a
+// bucket that was already flushed, merge by addition.
+
+const (
+	keyAggregatePrefix = "icmpmon:agg:"
+	keyAggregateIndex  = "icmpmon:agg:index"
+)
+
+// Hash field suffixes for each rollup stat.
+const (
+	statProbes       = "n"
+	statSuccesses    = "ok"
+	statLatencyCount = "lc"
+	statLatencySum   = "ls"
+	statLatencySumSq = "lq"
+	statLatencyMin   = "lmin"
+	statLatencyMax   = "lmax"
+	statLossCount    = "xc"
+	statLossSum      = "xs"
+)
+
+// aggregateArgsPerPair is the number of script arguments per (agent, target).
+const aggregateArgsPerPair = 10
+
+// aggregateScript merges pre-rolled pairs into a bucket's hash. Min and max
+// can't be expressed as increments, hence a script rather than a pipeline.
+//
+// KEYS[1] bucket hash, KEYS[2] index; ARGV[1] bucket end (unix), ARGV[2] TTL
+// seconds, then per pair: prefix, n, ok, lc, ls, lq, lmin, lmax, xc, xs
+// (lmin/lmax empty when the pair had no latency).
+var aggregateScript = redis.NewScript(`
+redis.call('ZADD', KEYS[2], ARGV[1], KEYS[1])
+for i = 3, #ARGV, 10 do
+	local p = ARGV[i] .. '|'
+	redis.call('HINCRBY', KEYS[1], p .. 'n', ARGV[i+1])
+	redis.call('HINCRBY', KEYS[1], p .. 'ok', ARGV[i+2])
+	redis.call('HINCRBY', KEYS[1], p .. 'lc', ARGV[i+3])
+	redis.call('HINCRBYFLOAT', KEYS[1], p .. 'ls', ARGV[i+4])
+	redis.call('HINCRBYFLOAT', KEYS[1], p .. 'lq', ARGV[i+5])
+	if ARGV[i+6] ~= '' then
+		local cur = redis.call('HGET', KEYS[1], p .. 'lmin')
+		if not cur or tonumber(ARGV[i+6]) < tonumber(cur) then
+			redis.call('HSET', KEYS[1], p .. 'lmin', ARGV[i+6])
+		end
+		cur = redis.call('HGET', KEYS[1], p .. 'lmax')
+		if not cur or tonumber(ARGV[i+7]) > tonumber(cur) then
+			redis.call('HSET', KEYS[1], p .. 'lmax', ARGV[i+7])
+		end
+	end
+	redis.call('HINCRBY', KEYS[1], p .. 'xc', ARGV[i+8])
+	redis.call('HINCRBYFLOAT', KEYS[1], p .. 'xs', ARGV[i+9])
+end
+redis.call('EXPIRE', KEYS[1], ARGV[2])
+return 1
+`)
+
+// Aggregate is a rollup of one agent's results for one target over the
+// Width starting at Bucket. Latency is each probe's average RTT; min/max
+// are over those averages, not individual packets.
+type Aggregate struct {
+	Bucket   time.Time
+	Width    time.Duration
+	AgentID  string
+	TargetID string
+
+	ProbeCount   int64
+	SuccessCount int64
+
+	LatencyCount int64
+	LatencySum   float64
+	LatencySumSq float64
+	LatencyMin   *float64
+	LatencyMax   *float64
+
+	LossCount int64
+	LossSum   float64
+}
+
+// add folds one probe result into the rollup.
+func (a *Aggregate) add(r types.ProbeResult) {
+	a.ProbeCount++
+	if r.Success {
+		a.SuccessCount++
+	}
+	m := payload.Decode(r.Payload)
+	if lat := m.Latency(); lat != nil {
+		a.LatencyCount++
+		a.LatencySum += *lat
+		a.LatencySumSq += *lat * *lat
+		if a.LatencyMin == nil || *lat < *a.LatencyMin {
+			v := *lat
+			a.LatencyMin = &v
+		}
+		if a.LatencyMax == nil || *lat > *a.LatencyMax {
+			v := *lat
+			a.LatencyMax = &v
+		}
+	}
+	if loss := m.PacketLoss(); loss != nil {
+		a.LossCount++
+		a.LossSum += *loss
+	}
+}
+
+// aggregateResults rolls results up per (bucket, agent, target) in buckets
+// of the given width, ordered by bucket, then agent, then target.
+func aggregateResults(results []types.ProbeResult, width time.Duration) []Aggregate {
+	type key struct {
+		bucket            int64
+		agentID, targetID string
+	}
+	index := make(map[key]int)
+	var aggs []Aggregate
+	for _, r := range results {
+		start := r.Timestamp.Truncate(width).UTC()
+		k := key{start.Unix(), r.AgentID, r.TargetID}
+		i, ok := index[k]
+		if !ok {
+			i = len(aggs)
+			index[k] = i
+			aggs = append(aggs, Aggregate{Bucket: start, Width: width, AgentID: r.AgentID, TargetID: r.TargetID})
+		}
+		aggs[i].add(r)
+	}
+	sortAggregates(aggs)
+	return aggs
+}
+
+func sortAggregates(aggs []Aggregate) {
+	sort.Slice(aggs, func(i, j int) bool {
+		if !aggs[i].Bucket.Equal(aggs[j].Bucket) {
+			return aggs[i].Bucket.Before(aggs[j].Bucket)
+		}
+		if aggs[i].Width != aggs[j].Width {
+			return aggs[i].Width < aggs[j].Width
+		}
+		if aggs[i].AgentID != aggs[j].AgentID {
+			return aggs[i].AgentID < aggs[j].AgentID
+		}
+		return aggs[i].TargetID < aggs[j].TargetID
+	})
+}
+
+// scriptArgs encodes a rollup for aggregateScript.
+func (a *Aggregate) scriptArgs() []any {
+	var latMin, latMax string
+	if a.LatencyMin != nil && a.LatencyMax != nil {
+		latMin, latMax = formatFloat(*a.LatencyMin), formatFloat(*a.LatencyMax)
+	}
+	return []any{
+		a.AgentID + "|" + a.TargetID,
+		a.ProbeCount, a.SuccessCount,
+		a.LatencyCount, formatFloat(a.LatencySum), formatFloat(a.LatencySumSq),
+		latMin, latMax,
+		a.LossCount, formatFloat(a.LossSum),
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// parseAggregateHash decodes one bucket's hash back into rollups.
+func parseAggregateHash(bucket time.Time, width time.Duration, fields map[string]string) ([]Aggregate, error) {
+	index := make(map[string]int)
+	var aggs []Aggregate
+	for field, value := range fields {
+		cut := strings.LastIndex(field, "|")
+		if cut < 0 {
+			return nil, fmt.Errorf("malformed aggregate field %q", field)
+		}
+		pair, stat := field[:cut], field[cut+1:]
+		agentID, targetID, ok := strings.Cut(pair, "|")
+		if !ok {
+			return nil, fmt.Errorf("malformed aggregate field %q", field)
+		}
+
+		i, seen := index[pair]
+		if !seen {
+			i = len(aggs)
+			index[pair] = i
+			aggs = append(aggs, Aggregate{Bucket: bucket, Width: width, AgentID: agentID, TargetID: targetID})
+		}
+		if err := aggs[i].setStat(stat, value); err != nil {
+			return nil, fmt.Errorf("parsing aggregate field %q: %w", field, err)
+		}
+	}
+	sortAggregates(aggs)
+	return aggs, nil
+}
+
+func (a *Aggregate) setStat(stat, value string) error {
+	switch stat {
+	case statProbes, statSuccesses, statLatencyCount, statLossCount:
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return err
+		}
+		switch stat {
+		case statProbes:
+			a.ProbeCount = n
+		case statSuccesses:
+			a.SuccessCount = n
+		case statLatencyCount:
+			a.LatencyCount = n
+		case statLossCount:
+			a.LossCount = n
+		}
+	case statLatencySum, statLatencySumSq, statLatencyMin, statLatencyMax, statLossSum:
+		v, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		switch stat {
+		case statLatencySum:
+			a.LatencySum = v
+		case statLatencySumSq:
+			a.LatencySumSq = v
+		case statLatencyMin:
+			a.LatencyMin = &v
+		case statLatencyMax:
+			a.LatencyMax = &v
+		case statLossSum:
+			a.LossSum = v
+		}
+	default:
+		return fmt.Errorf("unknown stat %q", stat)
+	}
+	return nil
+}
+
+// PushAggregates rolls results up into their buckets in Redis, each in
+// buckets of the width its target's tier aggregates at.
+func (b *ResultBuffer) PushAggregates(ctx context.Context, results []types.ProbeResult, width func(targetID string) time.Duration) error {
+	byWidth := make(map[time.Duration][]types.ProbeResult)
+	for _, r := range results {
+		w := width(r.TargetID)
+		byWidth[w] = append(byWidth[w], r)
+	}
+
+	var aggs []Aggregate
+	for w, group := range byWidth {
+		aggs = append(aggs, aggregateResults(group, w)...)
+	}
+	sortAggregates(aggs)
+	return b.pushAggregates(ctx, aggs)
+}
+
+// pushAggregates merges sorted rollups into Redis, one script call per
+// bucket.
+func (b *ResultBuffer) pushAggregates(ctx context.Context, aggs []Aggregate) error {
+	if len(aggs) == 0 {
+		return nil
+	}
+	ttl := int64(config.ProbeAggregateKeyTTL / time.Second)
+
+	pipe := b.client.Pipeline()
+	for start := 0; start < len(aggs); {
+		end := start
+		for end < len(aggs) && aggs[end].Bucket.Equal(aggs[start].Bucket) && aggs[end].Width == aggs[start].Width {
+			end++
+		}
+		bucket, width := aggs[start].Bucket, aggs[start].Width
+		args := make([]any, 0, 2+aggregateArgsPerPair*(end-start))
+		args = append(args, bucket.Add(width).Unix(), ttl)
+		for i := start; i < end; i++ {
+			args = append(args, aggs[i].scriptArgs()...)
+		}
+		// EVAL rather than Run: Run falls back from EVALSHA on NOSCRIPT,
+		// which a pipeline only reports at Exec, too late to retry
+		aggregateScript.Eval(ctx, pipe, []string{aggregateKey(bucket, width), keyAggregateIndex}, args...)
+		start = end
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("pushing aggregates to redis: %w", err)
+	}
+	return nil
+}
+
+// PopClosedAggregates removes and returns every bucket that ended at or
+// before closedBefore. Each bucket is read and deleted atomically, so
+// concurrent flushers never both collect it.
+func (b *ResultBuffer) PopClosedAggregates(ctx context.Context, closedBefore time.Time) ([]Aggregate, error) {
+	keys, err := b.client.ZRangeByScore(ctx, keyAggregateIndex, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: strconv.FormatInt(closedBefore.Unix(), 10),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("listing closed aggregate buckets: %w", err)
+	}
+
+	var aggs []Aggregate
+	for _, key := range keys {
+		bucket, width, err := parseAggregateKey(key)
+		if err != nil {
+			b.logger.Warn("dropping malformed aggregate key", "key", key)
+			b.client.ZRem(ctx, keyAggregateIndex, key)
+			continue
+		}
+
+		var fields *redis.MapStringStringCmd
+		_, err = b.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			fields = pipe.HGetAll(ctx, key)
+			pipe.Del(ctx, key)
+			pipe.ZRem(ctx, keyAggregateIndex, key)
+			return nil
+		})
+		if err != nil {
+			return aggs, fmt.Errorf("popping aggregate bucket %s: %w", key, err)
+		}
+
+		parsed, err := parseAggregateHash(bucket, width, fields.Val())
+		if err != nil {
+			b.logger.Warn("dropping malformed aggregate bucket", "key", key, "error", err)
+			continue
+		}
+		aggs = append(aggs, parsed...)
+	}
+	return aggs, nil
+}
+
+func aggregateKey(bucket time.Time, width time.Duration) string {
+	return keyAggregatePrefix + strconv.FormatInt(bucket.Unix(), 10) + ":" + strconv.FormatInt(int64(width/time.Second), 10)
+}
+
+// parseAggregateKey reverses aggregateKey. Keys written before buckets had
+// a width carry only the start and are one minute wide; they are indexed by
+// their start, so are collected a minute early, and results arriving after
+// that merge into the stored row like any late result.
+func parseAggregateKey(key string) (time.Time, time.Duration, error) {
+	startStr, widthStr, hasWidth := strings.Cut(strings.TrimPrefix(key, keyAggregatePrefix), ":")
+	start, err := strconv.ParseInt(startStr, 10, 64)
+	if err != nil {
+		return time.Time{}, 0, err
+	}
+	width := time.Minute
+	if hasWidth {
+		secs, err := strconv.ParseInt(widthStr, 10, 64)
+		if err != nil || secs <= 0 {
+			return time.Time{}, 0, fmt.Errorf("invalid aggregate width %q", widthStr)
+		}
+		width = time.Duration(secs) * time.Second
+	}
+	return time.Unix(start, 0).UTC(), width, nil
+}
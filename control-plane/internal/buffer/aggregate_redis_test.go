@@ -0,0 +1,149 @@
+package buffer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// newTestBuffer returns a buffer on a fresh in-memory Redis, which has no
+// scripts loaded, as after a Redis restart.
+func newTestBuffer(t *testing.T) *ResultBuffer {
+	t.Helper()
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return &ResultBuffer{client: client, logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+}
+
+func TestPushAggregates_RoundTrip(t *testing.T) {
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	result := func(target string, offset time.Duration, payload string) types.ProbeResult {
+		return types.ProbeResult{
+			AgentID: "a1", TargetID: target, Timestamp: start.Add(offset),
+			Success: true, Payload: json.RawMessage(payload),
+		}
+	}
+	widths := map[string]time.Duration{"t1": time.Minute, "t5": 5 * time.Minute}
+	width := func(targetID string) time.Duration { return widths[targetID] }
+
+	tests := []struct {
+		name         string
+		results      []types.ProbeResult
+		pushes       int
+		closedBefore time.Time
+		want         []Aggregate
+	}{
+		{
+			name: "each target at its tier's width",
+			results: []types.ProbeResult{
+				result("t1", 10*time.Second, `{"avg_ms":10,"packet_loss_pct":0}`),
+				result("t1", 70*time.Second, `{"avg_ms":20,"packet_loss_pct":0}`),
+				result("t5", 10*time.Second, `{"avg_ms":4,"packet_loss_pct":0}`),
+				result("t5", 250*time.Second, `{"avg_ms":8,"packet_loss_pct":50}`),
+			},
+			pushes:       1,
+			closedBefore: start.Add(5 * time.Minute),
+			want: []Aggregate{
+				{Bucket: start, Width: time.Minute, AgentID: "a1", TargetID: "t1", ProbeCount: 1, SuccessCount: 1,
+					LatencyCount: 1, LatencySum: 10, LatencySumSq: 100, LatencyMin: ptr(10.0), LatencyMax: ptr(10.0), LossCount: 1},
+				{Bucket: start, Width: 5 * time.Minute, AgentID: "a1", TargetID: "t5", ProbeCount: 2, SuccessCount: 2,
+					LatencyCount: 2, LatencySum: 12, LatencySumSq: 80, LatencyMin: ptr(4.0), LatencyMax: ptr(8.0), LossCount: 2, LossSum: 50},
+				{Bucket: start.Add(time.Minute), Width: time.Minute, AgentID: "a1", TargetID: "t1", ProbeCount: 1, SuccessCount: 1,
+					LatencyCount: 1, LatencySum: 20, LatencySumSq: 400, LatencyMin: ptr(20.0), LatencyMax: ptr(20.0), LossCount: 1},
+			},
+		},
+		{
+			name: "open buckets stay in redis",
+			results: []types.ProbeResult{
+				result("t1", 10*time.Second, `{"avg_ms":10,"packet_loss_pct":0}`),
+				result("t5", 10*time.Second, `{"avg_ms":4,"packet_loss_pct":0}`),
+			},
+			pushes:       1,
+			closedBefore: start.Add(time.Minute),
+			want: []Aggregate{
+				{Bucket: start, Width: time.Minute, AgentID: "a1", TargetID: "t1", ProbeCount: 1, SuccessCount: 1,
+					LatencyCount: 1, LatencySum: 10, LatencySumSq: 100, LatencyMin: ptr(10.0), LatencyMax: ptr(10.0), LossCount: 1},
+			},
+		},
+		{
+			name: "repeated pushes merge",
+			results: []types.ProbeResult{
+				result("t1", 10*time.Second, `{"avg_ms":10,"packet_loss_pct":0}`),
+				result("t1", 20*time.Second, `{"avg_ms":30,"packet_loss_pct":0}`),
+			},
+			pushes:       2,
+			closedBefore: start.Add(time.Minute),
+			want: []Aggregate{
+				{Bucket: start, Width: time.Minute, AgentID: "a1", TargetID: "t1", ProbeCount: 4, SuccessCount: 4,
+					LatencyCount: 4, LatencySum: 80, LatencySumSq: 2000, LatencyMin: ptr(10.0), LatencyMax: ptr(30.0), LossCount: 4},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newTestBuffer(t)
+			ctx := context.Background()
+
+			for i := 0; i < tt.pushes; i++ {
+				if err := b.PushAggregates(ctx, tt.results, width); err != nil {
+					t.Fatalf("PushAggregates: %v", err)
+				}
+			}
+			got, err := b.PopClosedAggregates(ctx, tt.closedBefore)
+			if err != nil {
+				t.Fatalf("PopClosedAggregates: %v", err)
+			}
+			sortAggregates(got) // popped in order of bucket end
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("popped %+v\nwant %+v", got, tt.want)
+			}
+
+			again, err := b.PopClosedAggregates(ctx, tt.closedBefore)
+			if err != nil || len(again) != 0 {
+				t.Errorf("second pop = %+v, %v, want nothing", again, err)
+			}
+		})
+	}
+}
+
+func TestPushAggregates_Requeue(t *testing.T) {
+	b := newTestBuffer(t)
+	ctx := context.Background()
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	results := []types.ProbeResult{{
+		AgentID: "a1", TargetID: "t1", Timestamp: start.Add(time.Second),
+		Success: true, Payload: json.RawMessage(`{"avg_ms":6,"packet_loss_pct":0}`),
+	}}
+	width := func(string) time.Duration { return time.Minute }
+
+	if err := b.PushAggregates(ctx, results, width); err != nil {
+		t.Fatalf("PushAggregates: %v", err)
+	}
+	popped, err := b.PopClosedAggregates(ctx, start.Add(time.Minute))
+	if err != nil || len(popped) != 1 {
+		t.Fatalf("PopClosedAggregates = %+v, %v, want one rollup", popped, err)
+	}
+
+	// As flushAggregates does when the database write fails
+	if err := b.pushAggregates(ctx, popped); err != nil {
+		t.Fatalf("requeueing aggregates: %v", err)
+	}
+	requeued, err := b.PopClosedAggregates(ctx, start.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("PopClosedAggregates after requeue: %v", err)
+	}
+	if !reflect.DeepEqual(requeued, popped) {
+		t.Errorf("requeued %+v, want %+v", requeued, popped)
+	}
+}
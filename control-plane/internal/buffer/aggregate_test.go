@@ -0,0 +1,222 @@
+package buffer
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestAggregateResults_Rollup(t *testing.T) {
+	minute := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	result := func(agent, target string, offset time.Duration, success bool, payload string) types.ProbeResult {
+		return types.ProbeResult{
+			AgentID: agent, TargetID: target, Timestamp: minute.Add(offset),
+			Success: success, Payload: json.RawMessage(payload),
+		}
+	}
+
+	tests := []struct {
+		name    string
+		results []types.ProbeResult
+		width   time.Duration
+		want    []Aggregate
+	}{
+		{
+			name: "same minute merged",
+			results: []types.ProbeResult{
+				result("a1", "t1", 5*time.Second, true, `{"avg_ms":10,"packet_loss_pct":0}`),
+				result("a1", "t1", 35*time.Second, true, `{"avg_ms":30,"packet_loss_pct":20}`),
+				result("a1", "t1", 50*time.Second, false, `{"packet_loss_pct":100}`),
+			},
+			want: []Aggregate{{
+				Bucket: minute, Width: time.Minute, AgentID: "a1", TargetID: "t1",
+				ProbeCount: 3, SuccessCount: 2,
+				LatencyCount: 2, LatencySum: 40, LatencySumSq: 1000,
+				LatencyMin: ptr(10.0), LatencyMax: ptr(30.0),
+				LossCount: 3, LossSum: 120,
+			}},
+		},
+		{
+			name: "split by minute and pair",
+			results: []types.ProbeResult{
+				result("a1", "t2", 70*time.Second, true, `{"avg_ms":5,"packet_loss_pct":0}`),
+				result("a2", "t1", 10*time.Second, true, `{"avg_ms":7,"packet_loss_pct":0}`),
+				result("a1", "t1", 10*time.Second, true, `{"avg_ms":9,"packet_loss_pct":0}`),
+			},
+			want: []Aggregate{
+				{Bucket: minute, Width: time.Minute, AgentID: "a1", TargetID: "t1", ProbeCount: 1, SuccessCount: 1,
+					LatencyCount: 1, LatencySum: 9, LatencySumSq: 81, LatencyMin: ptr(9.0), LatencyMax: ptr(9.0), LossCount: 1},
+				{Bucket: minute, Width: time.Minute, AgentID: "a2", TargetID: "t1", ProbeCount: 1, SuccessCount: 1,
+					LatencyCount: 1, LatencySum: 7, LatencySumSq: 49, LatencyMin: ptr(7.0), LatencyMax: ptr(7.0), LossCount: 1},
+				{Bucket: minute.Add(time.Minute), Width: time.Minute, AgentID: "a1", TargetID: "t2", ProbeCount: 1, SuccessCount: 1,
+					LatencyCount: 1, LatencySum: 5, LatencySumSq: 25, LatencyMin: ptr(5.0), LatencyMax: ptr(5.0), LossCount: 1},
+			},
+		},
+		{
+			name: "wider bucket spans minutes",
+			results: []types.ProbeResult{
+				result("a1", "t1", 10*time.Second, true, `{"avg_ms":4,"packet_loss_pct":0}`),
+				result("a1", "t1", 250*time.Second, true, `{"avg_ms":6,"packet_loss_pct":0}`),
+				result("a1", "t1", 310*time.Second, true, `{"avg_ms":8,"packet_loss_pct":0}`),
+			},
+			width: 5 * time.Minute,
+			want: []Aggregate{
+				{Bucket: minute, Width: 5 * time.Minute, AgentID: "a1", TargetID: "t1", ProbeCount: 2, SuccessCount: 2,
+					LatencyCount: 2, LatencySum: 10, LatencySumSq: 52, LatencyMin: ptr(4.0), LatencyMax: ptr(6.0), LossCount: 2},
+				{Bucket: minute.Add(5 * time.Minute), Width: 5 * time.Minute, AgentID: "a1", TargetID: "t1", ProbeCount: 1, SuccessCount: 1,
+					LatencyCount: 1, LatencySum: 8, LatencySumSq: 64, LatencyMin: ptr(8.0), LatencyMax: ptr(8.0), LossCount: 1},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			width := tt.width
+			if width == 0 {
+				width = time.Minute
+			}
+			got := aggregateResults(tt.results, width)
+			assertAggregates(t, got, tt.want)
+		})
+	}
+}
+
+func TestParseAggregateHash_RoundTrip(t *testing.T) {
+	bucket := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		aggs    []Aggregate
+		wantErr bool
+		fields  map[string]string // used instead of encoding aggs when set
+	}{
+		{
+			name: "with latency",
+			aggs: []Aggregate{{
+				Bucket: bucket, Width: time.Minute, AgentID: "a1", TargetID: "t1",
+				ProbeCount: 4, SuccessCount: 3, LatencyCount: 3, LatencySum: 31.5, LatencySumSq: 400.25,
+				LatencyMin: ptr(2.5), LatencyMax: ptr(20.0), LossCount: 4, LossSum: 25,
+			}},
+		},
+		{
+			name: "no latency",
+			aggs: []Aggregate{{
+				Bucket: bucket, Width: time.Minute, AgentID: "a1", TargetID: "t2",
+				ProbeCount: 2, LossCount: 2, LossSum: 200,
+			}},
+		},
+		{
+			name:    "unknown stat",
+			fields:  map[string]string{"a1|t1|bogus": "1"},
+			wantErr: true,
+		},
+		{
+			name:    "missing target",
+			fields:  map[string]string{"a1|n": "1"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fields := tt.fields
+			if fields == nil {
+				fields = encodeHash(tt.aggs)
+			}
+			got, err := parseAggregateHash(bucket, time.Minute, fields)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr {
+				assertAggregates(t, got, tt.aggs)
+			}
+		})
+	}
+}
+
+func TestParseAggregateKey_Width(t *testing.T) {
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		key       string
+		wantWidth time.Duration
+		wantErr   bool
+	}{
+		{name: "round trip", key: aggregateKey(start, 15*time.Minute), wantWidth: 15 * time.Minute},
+		{name: "legacy key without width", key: keyAggregatePrefix + "1773144000", wantWidth: time.Minute},
+		{name: "bad start", key: keyAggregatePrefix + "noon:60", wantErr: true},
+		{name: "zero width", key: keyAggregatePrefix + "1773144000:0", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, width, err := parseAggregateKey(tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if !bucket.Equal(start) || width != tt.wantWidth {
+				t.Errorf("parseAggregateKey(%q) = %s, %s; want %s, %s", tt.key, bucket, width, start, tt.wantWidth)
+			}
+		})
+	}
+}
+
+// encodeHash mirrors what aggregateScript stores for fresh pairs.
+func encodeHash(aggs []Aggregate) map[string]string {
+	stats := []string{
+		statProbes, statSuccesses, statLatencyCount, statLatencySum, statLatencySumSq,
+		statLatencyMin, statLatencyMax, statLossCount, statLossSum,
+	}
+	fields := make(map[string]string)
+	for _, a := range aggs {
+		args := a.scriptArgs()
+		prefix := args[0].(string)
+		for i, stat := range stats {
+			v := args[i+1]
+			if s, ok := v.(string); ok && s == "" {
+				continue
+			}
+			fields[prefix+"|"+stat] = toString(v)
+		}
+	}
+	return fields
+}
+
+func toString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case int64:
+		return formatFloat(float64(v))
+	}
+	return ""
+}
+
+func assertAggregates(t *testing.T, got, want []Aggregate) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d aggregates, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if !g.Bucket.Equal(w.Bucket) || g.Width != w.Width || g.AgentID != w.AgentID || g.TargetID != w.TargetID ||
+			g.ProbeCount != w.ProbeCount || g.SuccessCount != w.SuccessCount ||
+			g.LatencyCount != w.LatencyCount || g.LatencySum != w.LatencySum || g.LatencySumSq != w.LatencySumSq ||
+			g.LossCount != w.LossCount || g.LossSum != w.LossSum ||
+			!equalPtr(g.LatencyMin, w.LatencyMin) || !equalPtr(g.LatencyMax, w.LatencyMax) {
+			t.Errorf("aggregate %d = %+v, want %+v", i, g, w)
+		}
+	}
+}
+
+func equalPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func ptr(v float64) *float64 { return &v }
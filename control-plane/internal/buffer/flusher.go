@@ -2,7 +2,6 @@ package buffer
 
 import (
 	"context"
-	"encoding/json"
 	"log/slog"
 	"sync"
 	"time"
@@ -10,38 +9,61 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 
+	"github.com/pilot-net/icmp-mon/pkg/payload"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
 
 // Flusher reads from the Redis buffer and writes to TimescaleDB.
 type Flusher struct {
-	buffer   *ResultBuffer
-	pool     *pgxpool.Pool
-	logger   *slog.Logger
-	interval time.Duration
-	batch    int
+	buffer *ResultBuffer
+	pool   *pgxpool.Pool
+	logger *slog.Logger
+	config FlusherConfig
+	batch  int // current batch size, adapted to load
+	stats  flushStats
+
+	// Ingest policies of targets in aggregated tiers; absent targets are raw.
+	ingestPolicies   map[string]ingestPolicy
+	ingestPoliciesAt time.Time
+	lastRawPruneAt   time.Time
+
+	// payloadSampling reduces routine payloads; nil stores all in full.
+	payloadSampling *payload.Sampling
 
 	stopCh chan struct{}
 	wg     sync.WaitGroup
 }
 
-// NewFlusher creates a new buffer flusher.
-func NewFlusher(buffer *ResultBuffer, pool *pgxpool.Pool, logger *slog.Logger) *Flusher {
-	return &Flusher{
-		buffer:   buffer,
-		pool:     pool,
-		logger:   logger.With("component", "buffer_flusher"),
-		interval: DefaultFlushInterval,
-		batch:    DefaultBatchSize,
-		stopCh:   make(chan struct{}),
+// NewFlusher creates a new buffer flusher. The config must be valid.
+func NewFlusher(buffer *ResultBuffer, pool *pgxpool.Pool, config FlusherConfig, logger *slog.Logger) *Flusher {
+	f := &Flusher{
+		buffer: buffer,
+		pool:   pool,
+		logger: logger.With("component", "buffer_flusher"),
+		config: config,
+		batch:  config.FlushBatchSize,
+		stopCh: make(chan struct{}),
 	}
+	f.stats.setBatchSize(f.batch)
+	return f
+}
+
+// SetPayloadSampling stores routine payloads in full only at the sampling
+// rate, as Store.SetPayloadSampling does for direct writes. Call it before
+// Start.
+func (f *Flusher) SetPayloadSampling(sampling payload.Sampling) {
+	f.payloadSampling = &sampling
 }
 
 // Start begins the background flushing loop.
 func (f *Flusher) Start() {
 	f.wg.Add(1)
 	go f.run()
-	f.logger.Info("buffer flusher started", "interval", f.interval, "batch_size", f.batch)
+	f.logger.Info("buffer flusher started",
+		"interval", f.config.FlushInterval,
+		"batch_size", f.batch,
+		"adaptive", f.config.Adaptive,
+	)
 }
 
 // Stop stops the flusher and waits for completion.
@@ -54,7 +76,7 @@ func (f *Flusher) Stop() {
 func (f *Flusher) run() {
 	defer f.wg.Done()
 
-	ticker := time.NewTicker(f.interval)
+	ticker := time.NewTicker(f.config.FlushInterval)
 	defer ticker.Stop()
 
 	for {
@@ -72,6 +94,14 @@ func (f *Flusher) run() {
 func (f *Flusher) flush() {
 	ctx := context.Background()
 
+	f.flushResults(ctx)
+	f.flushAggregates(ctx)
+	f.pruneAggregatedRaw(ctx)
+}
+
+func (f *Flusher) flushResults(ctx context.Context) {
+	start := time.Now()
+
 	// Check buffer size
 	size, err := f.buffer.Len(ctx)
 	if err != nil {
@@ -80,21 +110,32 @@ func (f *Flusher) flush() {
 	}
 
 	if size == 0 {
+		f.adapt(0, 0, start)
 		return
 	}
 
-	// Pop results from buffer
-	results, err := f.buffer.Pop(ctx, f.batch)
+	// Pop results from buffer; Pop pipelines one RPOP per result asked
+	// for, so don't ask for more than are queued
+	results, err := f.buffer.Pop(ctx, int(min(int64(f.batch), size)))
 	if err != nil {
 		f.logger.Error("failed to pop from buffer", "error", err)
 		return
 	}
 
-	if len(results) == 0 {
+	popped := len(results)
+	remaining := size - int64(popped)
+	defer func() { f.adapt(popped, remaining, start) }()
+
+	if popped == 0 {
 		return
 	}
 
-	start := time.Now()
+	// Tiers with aggregate ingest are rolled up in Redis; only those that
+	// still keep raw rows go on to COPY.
+	results = f.aggregate(ctx, results)
+	if len(results) == 0 {
+		return
+	}
 
 	// Use COPY for maximum throughput
 	err = f.copyResults(ctx, results)
@@ -109,11 +150,36 @@ func (f *Flusher) flush() {
 
 	f.logger.Info("flushed results to database",
 		"count", len(results),
-		"remaining", size-int64(len(results)),
+		"remaining", remaining,
+		"batch_size", f.batch,
 		"duration", time.Since(start),
 	)
 }
 
+// adapt records a flush that popped results, started at start, and sizes
+// the next batch.
+func (f *Flusher) adapt(popped int, remaining int64, start time.Time) {
+	f.stats.record(popped, time.Since(start), time.Now())
+
+	next := f.config.nextBatchSize(f.batch, popped, remaining)
+	if next != f.batch {
+		f.logger.Debug("flush batch size changed", "from", f.batch, "to", next, "remaining", remaining)
+		f.batch = next
+		f.stats.setBatchSize(next)
+	}
+}
+
+// GetStats returns the buffer's stats with the flusher's batch and
+// latency metrics, for health monitoring.
+func (f *Flusher) GetStats(ctx context.Context) (types.BufferStats, error) {
+	stats, err := f.buffer.GetStats(ctx)
+	if err != nil {
+		return stats, err
+	}
+	f.stats.apply(&stats)
+	return stats, nil
+}
+
 // copyResults uses PostgreSQL COPY via a temp table for high-throughput bulk inserts.
 // This approach allows handling duplicates gracefully (ON CONFLICT DO NOTHING).
 func (f *Flusher) copyResults(ctx context.Context, results []types.ProbeResult) error {
@@ -134,7 +200,9 @@ func (f *Flusher) copyResults(ctx context.Context, results []types.ProbeResult)
 			error_message TEXT,
 			latency_ms DOUBLE PRECISION,
 			packet_loss_pct DOUBLE PRECISION,
-			payload JSONB
+			reply_ttl SMALLINT,
+			payload JSONB,
+			probe_type TEXT
 		) ON COMMIT DROP
 	`)
 	if err != nil {
@@ -142,42 +210,25 @@ func (f *Flusher) copyResults(ctx context.Context, results []types.ProbeResult)
 	}
 
 	// COPY data into temp table (very fast)
-	rows := make([][]any, len(results))
-	for i, r := range results {
-		rows[i] = []any{
-			r.Timestamp, r.TargetID, r.AgentID, r.Success, r.Error,
-			getLatency(r.Payload), getPacketLoss(r.Payload), r.Payload,
-		}
-	}
-
 	_, err = tx.CopyFrom(ctx,
 		pgx.Identifier{"probe_results_staging"},
-		[]string{"time", "target_id", "agent_id", "success", "error_message", "latency_ms", "packet_loss_pct", "payload"},
-		pgx.CopyFromRows(rows),
+		[]string{"time", "target_id", "agent_id", "success", "error_message", "latency_ms", "packet_loss_pct", "reply_ttl", "payload", "probe_type"},
+		pgx.CopyFromRows(copyRows(results, f.payloadSampling)),
 	)
 	if err != nil {
 		return err
 	}
 
 	// INSERT from temp to permanent table with conflict handling
-	// Computes agent_region, target_region, and is_in_market via JOINs
-	// Gateway targets are excluded from region metrics (they deprioritize ICMP, skewing latency)
 	_, err = tx.Exec(ctx, `
-		INSERT INTO probe_results (time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct, payload,
-		                           agent_region, target_region, is_in_market)
+		INSERT INTO probe_results (time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct, reply_ttl, payload,
+		                           probe_type, agent_region, target_region, is_in_market)
 		SELECT
-			s.time, s.target_id, s.agent_id, s.success, s.error_message, s.latency_ms, s.packet_loss_pct, s.payload,
-			CASE WHEN t.ip_type = 'gateway' THEN NULL ELSE LOWER(TRIM(a.region)) END,
-			CASE WHEN t.ip_type = 'gateway' THEN NULL ELSE LOWER(TRIM(sub.region)) END,
-			CASE WHEN t.ip_type = 'gateway' THEN NULL ELSE
-				(LOWER(TRIM(COALESCE(a.region, ''))) = LOWER(TRIM(COALESCE(sub.region, '')))
-				 AND a.region IS NOT NULL AND a.region != ''
-				 AND sub.region IS NOT NULL AND sub.region != '')
-			END
+			s.time, s.target_id, s.agent_id, s.success, s.error_message, s.latency_ms, s.packet_loss_pct, s.reply_ttl, s.payload,
+			NULLIF(s.probe_type, ''),
+			`+regionColumnsSQL+`
 		FROM probe_results_staging s
-		JOIN agents a ON s.agent_id = a.id
-		LEFT JOIN targets t ON s.target_id = t.id
-		LEFT JOIN subnets sub ON t.subnet_id = sub.id
+		`+regionJoinsSQL+`
 		ON CONFLICT (time, target_id, agent_id) DO NOTHING
 	`)
 	if err != nil {
@@ -187,24 +238,41 @@ func (f *Flusher) copyResults(ctx context.Context, results []types.ProbeResult)
 	return tx.Commit(ctx)
 }
 
-// getLatency extracts avg_ms from payload if present
-func getLatency(payload json.RawMessage) *float64 {
-	var p struct {
-		AvgMs float64 `json:"avg_ms"`
-	}
-	if err := json.Unmarshal(payload, &p); err == nil && p.AvgMs > 0 {
-		return &p.AvgMs
+// copyRows builds the staging rows for results, decoding each payload for
+// the typed columns.
+func copyRows(results []types.ProbeResult, sampling *payload.Sampling) [][]any {
+	rows := make([][]any, len(results))
+	for i, r := range results {
+		m := payload.Decode(r.Payload)
+		rows[i] = []any{
+			r.Timestamp, r.TargetID, r.AgentID, r.Success, r.Error,
+			m.Latency(), m.PacketLoss(), m.ReplyTTL(), sampling.Store(r, m), r.ProbeType,
+		}
 	}
-	return nil
+	return rows
 }
 
-// getPacketLoss extracts packet_loss_pct from payload if present
-func getPacketLoss(payload json.RawMessage) *float64 {
-	var p struct {
-		PacketLoss float64 `json:"packet_loss_pct"`
-	}
-	if err := json.Unmarshal(payload, &p); err == nil {
-		return &p.PacketLoss
-	}
-	return nil
-}
+// Region columns shared by raw and aggregated ingest, selected from a
+// staging table aliased s. They compute agent_region, target_region, and
+// is_in_market via JOINs; target_region prefers the target's own region
+// over its subnet's. Gateway targets are excluded from region metrics
+// (they deprioritize ICMP, skewing latency).
+const (
+	regionColumnsSQL = `
+			CASE WHEN t.ip_type = 'gateway' THEN NULL ELSE LOWER(TRIM(a.region)) END,
+			CASE WHEN t.ip_type = 'gateway' THEN NULL ELSE tr.region END,
+			CASE WHEN t.ip_type = 'gateway' THEN NULL ELSE
+				(LOWER(TRIM(COALESCE(a.region, ''))) = COALESCE(tr.region, '')
+				 AND a.region IS NOT NULL AND a.region != ''
+				 AND tr.region IS NOT NULL AND tr.region != '')
+			END`
+
+	regionJoinsSQL = `
+		JOIN agents a ON s.agent_id = a.id
+		LEFT JOIN targets t ON s.target_id = t.id
+		LEFT JOIN subnets sub ON t.subnet_id = sub.id
+		-- An explicit target region (manual targets) wins over the subnet's
+		CROSS JOIN LATERAL (
+			SELECT LOWER(TRIM(COALESCE(NULLIF(TRIM(t.region), ''), sub.region))) AS region
+		) tr`
+)
@@ -0,0 +1,227 @@
+package buffer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// ingestPolicy is how a target's tier persists its results.
+type ingestPolicy struct {
+	mode   string
+	bucket time.Duration // rollup width
+}
+
+// aggregate rolls up results for targets in aggregated tiers, each at its
+// tier's bucket width, and returns the ones that still need a raw row. If
+// the rollup can't be written to Redis the results are kept raw rather
+// than dropped.
+func (f *Flusher) aggregate(ctx context.Context, results []types.ProbeResult) []types.ProbeResult {
+	policies := f.targetIngestPolicies(ctx)
+	if len(policies) == 0 {
+		return results
+	}
+
+	var raw, rollup []types.ProbeResult
+	for _, r := range results {
+		switch policies[r.TargetID].mode {
+		case types.TierIngestAggregate:
+			raw = append(raw, r)
+			rollup = append(rollup, r)
+		case types.TierIngestAggregateOnly:
+			rollup = append(rollup, r)
+		default:
+			raw = append(raw, r)
+		}
+	}
+	if len(rollup) == 0 {
+		return results
+	}
+
+	width := func(targetID string) time.Duration { return policies[targetID].bucket }
+	if err := f.buffer.PushAggregates(ctx, rollup, width); err != nil {
+		f.logger.Error("failed to aggregate results, writing them raw", "error", err, "count", len(rollup))
+		return results
+	}
+	return raw
+}
+
+// targetIngestPolicies returns the ingest policy of every target in an
+// aggregated tier, reloading it every IngestModeRefreshInterval. On a failed
+// reload the previous policies are kept.
+func (f *Flusher) targetIngestPolicies(ctx context.Context) map[string]ingestPolicy {
+	if time.Since(f.ingestPoliciesAt) < config.IngestModeRefreshInterval {
+		return f.ingestPolicies
+	}
+	f.ingestPoliciesAt = time.Now()
+
+	rows, err := f.pool.Query(ctx, `
+		SELECT t.id::text, tr.ingest_mode, COALESCE(tr.aggregate_bucket_seconds, $2)
+		FROM targets t
+		JOIN tiers tr ON tr.name = t.tier
+		WHERE tr.ingest_mode <> $1
+	`, types.TierIngestRaw, int(config.ProbeAggregateBucket/time.Second))
+	if err != nil {
+		f.logger.Warn("failed to load tier ingest policies", "error", err)
+		return f.ingestPolicies
+	}
+	defer rows.Close()
+
+	policies := make(map[string]ingestPolicy)
+	for rows.Next() {
+		var targetID, mode string
+		var bucketSecs int
+		if err := rows.Scan(&targetID, &mode, &bucketSecs); err != nil {
+			f.logger.Warn("failed to scan tier ingest policy", "error", err)
+			return f.ingestPolicies
+		}
+		policies[targetID] = ingestPolicy{mode: mode, bucket: time.Duration(bucketSecs) * time.Second}
+	}
+	if err := rows.Err(); err != nil {
+		f.logger.Warn("failed to load tier ingest policies", "error", err)
+		return f.ingestPolicies
+	}
+	f.ingestPolicies = policies
+	return policies
+}
+
+// flushAggregates writes buckets past their grace period to probe_1min.
+// Rollups that fail to write are pushed back to Redis to retry next flush.
+func (f *Flusher) flushAggregates(ctx context.Context) {
+	closedBefore := time.Now().Add(-config.ProbeAggregateGrace)
+	aggs, err := f.buffer.PopClosedAggregates(ctx, closedBefore)
+	if err != nil {
+		f.logger.Error("failed to pop aggregates", "error", err)
+	}
+	if len(aggs) == 0 {
+		return
+	}
+
+	start := time.Now()
+	if err := f.upsertAggregates(ctx, aggs); err != nil {
+		f.logger.Error("failed to write aggregates to database", "error", err, "count", len(aggs))
+		if err := f.buffer.pushAggregates(ctx, aggs); err != nil {
+			f.logger.Error("failed to requeue aggregates, dropping them", "error", err, "count", len(aggs))
+		}
+		return
+	}
+
+	f.logger.Info("flushed aggregates to database", "count", len(aggs), "duration", time.Since(start))
+}
+
+// upsertAggregates COPYs rollups into a staging table and merges them into
+// probe_1min, adding to any row already written for the same bucket. A
+// tier whose bucket width changed can meet a row of the old width starting
+// at the same time; the merged row takes the wider of the two.
+func (f *Flusher) upsertAggregates(ctx context.Context, aggs []Aggregate) error {
+	tx, err := f.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE probe_1min_staging (
+			bucket TIMESTAMPTZ NOT NULL,
+			bucket_seconds INTEGER NOT NULL,
+			target_id UUID NOT NULL,
+			agent_id UUID NOT NULL,
+			probe_count INTEGER NOT NULL,
+			success_count INTEGER NOT NULL,
+			latency_count INTEGER NOT NULL,
+			latency_sum DOUBLE PRECISION NOT NULL,
+			latency_sum_sq DOUBLE PRECISION NOT NULL,
+			latency_min DOUBLE PRECISION,
+			latency_max DOUBLE PRECISION,
+			loss_count INTEGER NOT NULL,
+			loss_sum DOUBLE PRECISION NOT NULL
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return fmt.Errorf("creating staging table: %w", err)
+	}
+
+	rows := make([][]any, len(aggs))
+	for i, a := range aggs {
+		rows[i] = []any{
+			a.Bucket, int(a.Width / time.Second), a.TargetID, a.AgentID, a.ProbeCount, a.SuccessCount,
+			a.LatencyCount, a.LatencySum, a.LatencySumSq, a.LatencyMin, a.LatencyMax,
+			a.LossCount, a.LossSum,
+		}
+	}
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"probe_1min_staging"},
+		[]string{"bucket", "bucket_seconds", "target_id", "agent_id", "probe_count", "success_count",
+			"latency_count", "latency_sum", "latency_sum_sq", "latency_min", "latency_max",
+			"loss_count", "loss_sum"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return fmt.Errorf("copying aggregates: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO probe_1min (bucket, bucket_seconds, target_id, agent_id, probe_count, success_count,
+		                        latency_count, latency_sum, latency_sum_sq, latency_min, latency_max,
+		                        loss_count, loss_sum, agent_region, target_region, is_in_market)
+		SELECT
+			s.bucket, s.bucket_seconds, s.target_id, s.agent_id, s.probe_count, s.success_count,
+			s.latency_count, s.latency_sum, s.latency_sum_sq, s.latency_min, s.latency_max,
+			s.loss_count, s.loss_sum,
+			`+regionColumnsSQL+`
+		FROM probe_1min_staging s
+		`+regionJoinsSQL+`
+		ON CONFLICT (bucket, target_id, agent_id) DO UPDATE SET
+			bucket_seconds = GREATEST(probe_1min.bucket_seconds, EXCLUDED.bucket_seconds),
+			probe_count = probe_1min.probe_count + EXCLUDED.probe_count,
+			success_count = probe_1min.success_count + EXCLUDED.success_count,
+			latency_count = probe_1min.latency_count + EXCLUDED.latency_count,
+			latency_sum = probe_1min.latency_sum + EXCLUDED.latency_sum,
+			latency_sum_sq = probe_1min.latency_sum_sq + EXCLUDED.latency_sum_sq,
+			latency_min = LEAST(probe_1min.latency_min, EXCLUDED.latency_min),
+			latency_max = GREATEST(probe_1min.latency_max, EXCLUDED.latency_max),
+			loss_count = probe_1min.loss_count + EXCLUDED.loss_count,
+			loss_sum = probe_1min.loss_sum + EXCLUDED.loss_sum
+	`)
+	if err != nil {
+		return fmt.Errorf("merging aggregates: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// pruneAggregatedRaw deletes raw results older than their tier's raw
+// retention (AggregatedRawRetention by default) for targets in "aggregate"
+// tiers. The delete only looks two prune intervals back from each tier's
+// cutoff so it stays on recent, uncompressed chunks; rows missed while no
+// flusher ran age out under the global policy.
+func (f *Flusher) pruneAggregatedRaw(ctx context.Context) {
+	now := time.Now()
+	if now.Sub(f.lastRawPruneAt) < config.AggregatedRawPruneInterval {
+		return
+	}
+	f.lastRawPruneAt = now
+
+	tag, err := f.pool.Exec(ctx, `
+		DELETE FROM probe_results p
+		USING targets t
+		JOIN tiers tr ON tr.name = t.tier
+		WHERE p.target_id = t.id
+		  AND tr.ingest_mode = $1
+		  AND p.time < $2::timestamptz - make_interval(secs => COALESCE(tr.raw_retention_seconds, $3))
+		  AND p.time >= $2::timestamptz - make_interval(secs => COALESCE(tr.raw_retention_seconds, $3) + $4)
+	`, types.TierIngestAggregate, now,
+		int(config.AggregatedRawRetention/time.Second), int(2*config.AggregatedRawPruneInterval/time.Second))
+	if err != nil {
+		f.logger.Error("failed to prune raw results for aggregated tiers", "error", err)
+		return
+	}
+	if tag.RowsAffected() > 0 {
+		f.logger.Info("pruned raw results for aggregated tiers", "count", tag.RowsAffected())
+	}
+}
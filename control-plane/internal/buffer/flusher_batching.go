@@ -0,0 +1,129 @@
+package buffer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// FlusherConfig holds configuration for the buffer flusher.
+type FlusherConfig struct {
+	// FlushBatchSize is the most results popped per flush, or the starting
+	// size when Adaptive is set. Larger batches amortise the COPY and
+	// transaction; smaller ones hold less in memory and land sooner.
+	FlushBatchSize int
+
+	// FlushInterval is how often the flusher runs.
+	FlushInterval time.Duration
+
+	// Adaptive doubles the batch size while a full batch leaves a backlog
+	// and halves it while batches come back under a quarter full, within
+	// MinBatchSize and MaxBatchSize.
+	Adaptive     bool
+	MinBatchSize int
+	MaxBatchSize int
+}
+
+// DefaultFlusherConfig returns sensible defaults.
+func DefaultFlusherConfig() FlusherConfig {
+	return FlusherConfig{
+		FlushBatchSize: DefaultBatchSize,
+		FlushInterval:  DefaultFlushInterval,
+		Adaptive:       true,
+		MinBatchSize:   config.BufferFlushMinBatchSize,
+		MaxBatchSize:   config.BufferFlushMaxBatchSize,
+	}
+}
+
+// Validate checks that the sizes and interval are usable.
+func (c FlusherConfig) Validate() error {
+	if c.FlushBatchSize <= 0 {
+		return fmt.Errorf("flush batch size must be positive, got %d", c.FlushBatchSize)
+	}
+	if c.FlushInterval <= 0 {
+		return fmt.Errorf("flush interval must be positive, got %s", c.FlushInterval)
+	}
+	if c.Adaptive && (c.MinBatchSize <= 0 || c.MinBatchSize > c.FlushBatchSize || c.FlushBatchSize > c.MaxBatchSize) {
+		return fmt.Errorf("adaptive batch sizes must satisfy 0 < min (%d) <= batch (%d) <= max (%d)",
+			c.MinBatchSize, c.FlushBatchSize, c.MaxBatchSize)
+	}
+	return nil
+}
+
+// nextBatchSize returns the batch size for the next flush given how many
+// results the last one popped and how many were left behind.
+func (c FlusherConfig) nextBatchSize(current, popped int, remaining int64) int {
+	if !c.Adaptive {
+		return current
+	}
+	switch {
+	case popped >= current && remaining > 0:
+		return min(current*2, c.MaxBatchSize)
+	case popped < current/4:
+		return max(current/2, c.MinBatchSize)
+	}
+	return current
+}
+
+// flushStatsSmoothing weights the latest flush in the moving averages.
+const flushStatsSmoothing = 0.2
+
+// flushStats tracks flush sizes, throughput and latency for health
+// reporting. It is written by the flush loop and read by the API.
+type flushStats struct {
+	mu sync.Mutex
+
+	batchSize     int
+	lastBatchSize int
+	lastFlushAt   time.Time
+	latencyMs     float64 // moving average over non-empty flushes
+	ratePerSecond float64 // moving average of results flushed per second
+	flushed       int64
+}
+
+// record notes a flush of count results, taking took, at now. Empty
+// flushes count toward the rate, so it falls to zero when idle, but not
+// toward latency.
+func (s *flushStats) record(count int, took time.Duration, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.lastFlushAt.IsZero() {
+		if elapsed := now.Sub(s.lastFlushAt).Seconds(); elapsed > 0 {
+			s.ratePerSecond += flushStatsSmoothing * (float64(count)/elapsed - s.ratePerSecond)
+		}
+	}
+	s.lastFlushAt = now
+
+	if count == 0 {
+		return
+	}
+	latencyMs := float64(took) / float64(time.Millisecond)
+	if s.flushed == 0 {
+		s.latencyMs = latencyMs
+	} else {
+		s.latencyMs += flushStatsSmoothing * (latencyMs - s.latencyMs)
+	}
+	s.lastBatchSize = count
+	s.flushed += int64(count)
+}
+
+func (s *flushStats) setBatchSize(n int) {
+	s.mu.Lock()
+	s.batchSize = n
+	s.mu.Unlock()
+}
+
+// apply copies the flush metrics into buffer stats.
+func (s *flushStats) apply(stats *types.BufferStats) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats.FlushRate = s.ratePerSecond
+	stats.BatchSize = s.batchSize
+	stats.LastBatchSize = s.lastBatchSize
+	stats.FlushLatencyMs = s.latencyMs
+	stats.Flushed = s.flushed
+}
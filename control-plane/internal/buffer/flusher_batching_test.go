@@ -0,0 +1,128 @@
+package buffer
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/payload"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestFlusherConfig_NextBatchSize(t *testing.T) {
+	cfg := FlusherConfig{FlushBatchSize: 4000, Adaptive: true, MinBatchSize: 1000, MaxBatchSize: 10000}
+
+	tests := []struct {
+		name      string
+		config    FlusherConfig
+		current   int
+		popped    int
+		remaining int64
+		want      int
+	}{
+		{name: "full batch with backlog grows", config: cfg, current: 4000, popped: 4000, remaining: 1, want: 8000},
+		{name: "growth capped at max", config: cfg, current: 8000, popped: 8000, remaining: 50000, want: 10000},
+		{name: "full batch that drained the queue holds", config: cfg, current: 4000, popped: 4000, want: 4000},
+		{name: "partly full holds", config: cfg, current: 4000, popped: 1500, want: 4000},
+		{name: "mostly empty shrinks", config: cfg, current: 4000, popped: 900, want: 2000},
+		{name: "idle shrinks", config: cfg, current: 4000, want: 2000},
+		{name: "shrink floored at min", config: cfg, current: 1500, want: 1000},
+		{name: "fixed when not adaptive", config: FlusherConfig{FlushBatchSize: 4000}, current: 4000, popped: 4000, remaining: 100, want: 4000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.config.nextBatchSize(tt.current, tt.popped, tt.remaining); got != tt.want {
+				t.Errorf("nextBatchSize() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFlusherConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  FlusherConfig
+		wantErr bool
+	}{
+		{name: "defaults", config: DefaultFlusherConfig()},
+		{name: "fixed without bounds", config: FlusherConfig{FlushBatchSize: 500, FlushInterval: time.Second}},
+		{name: "zero batch", config: FlusherConfig{FlushInterval: time.Second}, wantErr: true},
+		{name: "zero interval", config: FlusherConfig{FlushBatchSize: 500}, wantErr: true},
+		{
+			name:    "batch above adaptive max",
+			config:  FlusherConfig{FlushBatchSize: 500, FlushInterval: time.Second, Adaptive: true, MinBatchSize: 100, MaxBatchSize: 400},
+			wantErr: true,
+		},
+		{
+			name:    "adaptive without min",
+			config:  FlusherConfig{FlushBatchSize: 500, FlushInterval: time.Second, Adaptive: true, MaxBatchSize: 1000},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestFlushStats_Record(t *testing.T) {
+	var s flushStats
+	s.setBatchSize(2000)
+	t0 := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	s.record(1000, 100*time.Millisecond, t0)
+	s.record(2000, 200*time.Millisecond, t0.Add(2*time.Second))
+	s.record(0, 0, t0.Add(4*time.Second))
+
+	var stats types.BufferStats
+	s.apply(&stats)
+	if stats.Flushed != 3000 || stats.LastBatchSize != 2000 || stats.BatchSize != 2000 {
+		t.Errorf("stats = %+v, want 3000 flushed, last batch 2000", stats)
+	}
+	// 100ms then 200ms; the empty flush doesn't count toward latency
+	if want := 100 + flushStatsSmoothing*100; stats.FlushLatencyMs != want {
+		t.Errorf("FlushLatencyMs = %v, want %v", stats.FlushLatencyMs, want)
+	}
+	// 1000/s, then decaying toward 0/s on the idle flush
+	if want := flushStatsSmoothing * 1000 * (1 - flushStatsSmoothing); stats.FlushRate != want {
+		t.Errorf("FlushRate = %v, want %v", stats.FlushRate, want)
+	}
+}
+
+func BenchmarkCopyRows(b *testing.B) {
+	payloadJSON := json.RawMessage(`{"reachable":true,"latency_ms":11.8,"min_ms":10.2,"max_ms":14.1,` +
+		`"avg_ms":11.8,"stddev_ms":1.1,"packet_loss_pct":0,"reply_ttl":57,"rtts":[10.2,11.5,12.0,11.2,14.1]}`)
+	start := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, size := range []int{1000, DefaultBatchSize, 80000} {
+		results := make([]types.ProbeResult, size)
+		for i := range results {
+			results[i] = types.ProbeResult{
+				TargetID:  fmt.Sprintf("t%d", i%5000),
+				AgentID:   fmt.Sprintf("a%d", i%20),
+				Timestamp: start.Add(time.Duration(i) * time.Millisecond),
+				Success:   true,
+				Payload:   payloadJSON,
+			}
+		}
+		for _, sampling := range []*payload.Sampling{nil, {Rate: 0.1}} {
+			name := fmt.Sprintf("batch=%d/full", size)
+			if sampling != nil {
+				name = fmt.Sprintf("batch=%d/sampled", size)
+			}
+			b.Run(name, func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					_ = copyRows(results, sampling)
+				}
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*size), "ns/result")
+			})
+		}
+	}
+}
@@ -1,4 +1,5 @@
-// Package cache provides Redis-backed caching for API responses.
+// Package cache provides caching for API responses, backed by Redis or, when
+// Redis isn't configured, by an in-process LRU.
 package cache
 
 import (
@@ -16,14 +17,25 @@ const (
 	keyPrefix = "icmpmon:cache:"
 )
 
-// Cache provides Redis-backed response caching.
-type Cache struct {
+// Cache stores API responses for a TTL. Get returns nil data on a miss.
+type Cache interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, data []byte, ttl time.Duration) error
+	GetJSON(ctx context.Context, key string, v any) (bool, error)
+	SetJSON(ctx context.Context, key string, v any, ttl time.Duration) error
+	Delete(ctx context.Context, key string) error
+	DeletePattern(ctx context.Context, pattern string) error
+}
+
+// RedisCache provides Redis-backed response caching, shared by every
+// control plane instance using the same Redis.
+type RedisCache struct {
 	client *redis.Client
 	logger *slog.Logger
 }
 
-// New creates a new Redis-backed cache.
-func New(redisURL string, logger *slog.Logger) (*Cache, error) {
+// NewRedis creates a new Redis-backed cache.
+func NewRedis(redisURL string, logger *slog.Logger) (*RedisCache, error) {
 	opts, err := redis.ParseURL(redisURL)
 	if err != nil {
 		return nil, fmt.Errorf("invalid redis URL: %w", err)
@@ -39,14 +51,14 @@ func New(redisURL string, logger *slog.Logger) (*Cache, error) {
 		return nil, fmt.Errorf("redis connection failed: %w", err)
 	}
 
-	return &Cache{
+	return &RedisCache{
 		client: client,
 		logger: logger,
 	}, nil
 }
 
 // Get retrieves a cached value. Returns nil if not found or expired.
-func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
+func (c *RedisCache) Get(ctx context.Context, key string) ([]byte, error) {
 	data, err := c.client.Get(ctx, keyPrefix+key).Bytes()
 	if err == redis.Nil {
 		return nil, nil // Cache miss
@@ -58,41 +70,27 @@ func (c *Cache) Get(ctx context.Context, key string) ([]byte, error) {
 }
 
 // Set stores a value in the cache with the given TTL.
-func (c *Cache) Set(ctx context.Context, key string, data []byte, ttl time.Duration) error {
+func (c *RedisCache) Set(ctx context.Context, key string, data []byte, ttl time.Duration) error {
 	return c.client.Set(ctx, keyPrefix+key, data, ttl).Err()
 }
 
 // GetJSON retrieves and unmarshals a cached JSON value.
-func (c *Cache) GetJSON(ctx context.Context, key string, v any) (bool, error) {
-	data, err := c.Get(ctx, key)
-	if err != nil {
-		return false, err
-	}
-	if data == nil {
-		return false, nil // Cache miss
-	}
-	if err := json.Unmarshal(data, v); err != nil {
-		return false, err
-	}
-	return true, nil
+func (c *RedisCache) GetJSON(ctx context.Context, key string, v any) (bool, error) {
+	return getJSON(ctx, c, key, v)
 }
 
 // SetJSON marshals and stores a JSON value in the cache.
-func (c *Cache) SetJSON(ctx context.Context, key string, v any, ttl time.Duration) error {
-	data, err := json.Marshal(v)
-	if err != nil {
-		return err
-	}
-	return c.Set(ctx, key, data, ttl)
+func (c *RedisCache) SetJSON(ctx context.Context, key string, v any, ttl time.Duration) error {
+	return setJSON(ctx, c, key, v, ttl)
 }
 
 // Delete removes a key from the cache.
-func (c *Cache) Delete(ctx context.Context, key string) error {
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
 	return c.client.Del(ctx, keyPrefix+key).Err()
 }
 
 // DeletePattern removes all keys matching a pattern.
-func (c *Cache) DeletePattern(ctx context.Context, pattern string) error {
+func (c *RedisCache) DeletePattern(ctx context.Context, pattern string) error {
 	keys, err := c.client.Keys(ctx, keyPrefix+pattern).Result()
 	if err != nil {
 		return err
@@ -102,3 +100,27 @@ func (c *Cache) DeletePattern(ctx context.Context, pattern string) error {
 	}
 	return nil
 }
+
+// getJSON implements GetJSON on top of a cache's Get.
+func getJSON(ctx context.Context, c Cache, key string, v any) (bool, error) {
+	data, err := c.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if data == nil {
+		return false, nil // Cache miss
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// setJSON implements SetJSON on top of a cache's Set.
+func setJSON(ctx context.Context, c Cache, key string, v any, ttl time.Duration) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return c.Set(ctx, key, data, ttl)
+}
@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/clock"
+)
+
+// MemoryCache is an in-process LRU response cache for deployments without
+// Redis. Entries are private to this instance, so with several control
+// planes behind a load balancer each warms its own copy.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	order      *list.List // front is most recently used
+	entries    map[string]*list.Element
+	clock      clock.Clock
+}
+
+type memoryEntry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time
+}
+
+// NewMemory creates an in-memory cache holding at most maxEntries values,
+// evicting the least recently used beyond that.
+func NewMemory(maxEntries int) *MemoryCache {
+	return &MemoryCache{
+		maxEntries: max(maxEntries, 1),
+		order:      list.New(),
+		entries:    make(map[string]*list.Element),
+		clock:      clock.Real{},
+	}
+}
+
+// SetClock replaces the clock used for expiry. For tests.
+func (c *MemoryCache) SetClock(clk clock.Clock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.clock = clk
+}
+
+// Get retrieves a cached value. Returns nil if not found or expired.
+func (c *MemoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	e := el.Value.(*memoryEntry)
+	if !e.expiresAt.IsZero() && !c.clock.Now().Before(e.expiresAt) {
+		c.remove(el)
+		return nil, nil
+	}
+	c.order.MoveToFront(el)
+	return e.data, nil
+}
+
+// Set stores a value in the cache with the given TTL. A TTL of zero keeps
+// the value until it is evicted.
+func (c *MemoryCache) Set(_ context.Context, key string, data []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expiresAt time.Time
+	if ttl > 0 {
+		expiresAt = c.clock.Now().Add(ttl)
+	}
+
+	if el, ok := c.entries[key]; ok {
+		e := el.Value.(*memoryEntry)
+		e.data, e.expiresAt = data, expiresAt
+		c.order.MoveToFront(el)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(&memoryEntry{key: key, data: data, expiresAt: expiresAt})
+	for c.order.Len() > c.maxEntries {
+		c.remove(c.order.Back())
+	}
+	return nil
+}
+
+// GetJSON retrieves and unmarshals a cached JSON value.
+func (c *MemoryCache) GetJSON(ctx context.Context, key string, v any) (bool, error) {
+	return getJSON(ctx, c, key, v)
+}
+
+// SetJSON marshals and stores a JSON value in the cache.
+func (c *MemoryCache) SetJSON(ctx context.Context, key string, v any, ttl time.Duration) error {
+	return setJSON(ctx, c, key, v, ttl)
+}
+
+// Delete removes a key from the cache.
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		c.remove(el)
+	}
+	return nil
+}
+
+// DeletePattern removes all keys matching a glob pattern. Unlike Redis, a
+// '*' doesn't match '/'.
+func (c *MemoryCache) DeletePattern(_ context.Context, pattern string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for key, el := range c.entries {
+		if ok, _ := path.Match(pattern, key); ok {
+			c.remove(el)
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries held, including expired ones not yet
+// evicted.
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// remove drops an entry. The caller holds c.mu.
+func (c *MemoryCache) remove(el *list.Element) {
+	c.order.Remove(el)
+	delete(c.entries, el.Value.(*memoryEntry).key)
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/clock"
+)
+
+func TestMemoryCache_Operations(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		run      func(c *MemoryCache, clk *clock.Fake)
+		wantHits []string
+		wantMiss []string
+	}{
+		{
+			name: "set then get",
+			run: func(c *MemoryCache, _ *clock.Fake) {
+				c.Set(ctx, "a", []byte("1"), time.Minute)
+			},
+			wantHits: []string{"a"},
+			wantMiss: []string{"b"},
+		},
+		{
+			name: "expired entry misses",
+			run: func(c *MemoryCache, clk *clock.Fake) {
+				c.Set(ctx, "a", []byte("1"), time.Minute)
+				c.Set(ctx, "b", []byte("2"), time.Hour)
+				clk.Advance(time.Minute)
+			},
+			wantHits: []string{"b"},
+			wantMiss: []string{"a"},
+		},
+		{
+			name: "least recently used evicted",
+			run: func(c *MemoryCache, _ *clock.Fake) {
+				c.Set(ctx, "a", []byte("1"), time.Minute)
+				c.Set(ctx, "b", []byte("2"), time.Minute)
+				c.Get(ctx, "a")
+				c.Set(ctx, "c", []byte("3"), time.Minute)
+			},
+			wantHits: []string{"a", "c"},
+			wantMiss: []string{"b"},
+		},
+		{
+			name: "overwrite does not evict",
+			run: func(c *MemoryCache, _ *clock.Fake) {
+				c.Set(ctx, "a", []byte("1"), time.Minute)
+				c.Set(ctx, "b", []byte("2"), time.Minute)
+				c.Set(ctx, "a", []byte("3"), time.Minute)
+			},
+			wantHits: []string{"a", "b"},
+		},
+		{
+			name: "delete",
+			run: func(c *MemoryCache, _ *clock.Fake) {
+				c.Set(ctx, "a", []byte("1"), time.Minute)
+				c.Set(ctx, "b", []byte("2"), time.Minute)
+				c.Delete(ctx, "a")
+			},
+			wantHits: []string{"b"},
+			wantMiss: []string{"a"},
+		},
+		{
+			name: "delete pattern",
+			run: func(c *MemoryCache, _ *clock.Fake) {
+				c.Set(ctx, "target_list:1", []byte("1"), time.Minute)
+				c.Set(ctx, "fleet_overview", []byte("2"), time.Minute)
+				c.DeletePattern(ctx, "target_list:*")
+			},
+			wantHits: []string{"fleet_overview"},
+			wantMiss: []string{"target_list:1"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clk := clock.NewFake(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+			c := NewMemory(2)
+			c.SetClock(clk)
+
+			tt.run(c, clk)
+
+			for _, key := range tt.wantHits {
+				if data, _ := c.Get(ctx, key); data == nil {
+					t.Errorf("Get(%q) missed, want hit", key)
+				}
+			}
+			for _, key := range tt.wantMiss {
+				if data, _ := c.Get(ctx, key); data != nil {
+					t.Errorf("Get(%q) = %s, want miss", key, data)
+				}
+			}
+		})
+	}
+}
+
+func TestMemoryCache_JSONRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemory(10)
+
+	type payload struct {
+		Count int `json:"count"`
+	}
+	if err := c.SetJSON(ctx, "k", payload{Count: 3}, time.Minute); err != nil {
+		t.Fatalf("SetJSON() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		key     string
+		wantHit bool
+		want    int
+	}{
+		{name: "hit", key: "k", wantHit: true, want: 3},
+		{name: "miss", key: "missing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got payload
+			hit, err := c.GetJSON(ctx, tt.key, &got)
+			if err != nil {
+				t.Fatalf("GetJSON() error = %v", err)
+			}
+			if hit != tt.wantHit || got.Count != tt.want {
+				t.Errorf("GetJSON() = %v %d, want %v %d", hit, got.Count, tt.wantHit, tt.want)
+			}
+		})
+	}
+}
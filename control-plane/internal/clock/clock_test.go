@@ -0,0 +1,68 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFake_AdvanceAndSet(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		move      func(f *Fake)
+		wantNow   time.Time
+		wantSince time.Duration
+	}{
+		{"stopped", func(f *Fake) {}, start, 0},
+		{"advance", func(f *Fake) { f.Advance(15 * time.Minute) }, start.Add(15 * time.Minute), 15 * time.Minute},
+		{"advance twice", func(f *Fake) { f.Advance(time.Hour); f.Advance(time.Hour) }, start.Add(2 * time.Hour), 2 * time.Hour},
+		{"set back", func(f *Fake) { f.Set(start.Add(-time.Minute)) }, start.Add(-time.Minute), -time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := NewFake(start)
+			tt.move(f)
+			if got := f.Now(); !got.Equal(tt.wantNow) {
+				t.Errorf("Now() = %v, want %v", got, tt.wantNow)
+			}
+			if got := f.Since(start); got != tt.wantSince {
+				t.Errorf("Since(start) = %v, want %v", got, tt.wantSince)
+			}
+		})
+	}
+}
+
+func TestFake_ConcurrentUse(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	f := NewFake(start)
+
+	const goroutines, steps = 8, 100
+	var wg sync.WaitGroup
+	for range goroutines {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range steps {
+				f.Advance(time.Second)
+				_ = f.Now()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got, want := f.Since(start), goroutines*steps*time.Second; got != want {
+		t.Errorf("Since(start) = %v, want %v", got, want)
+	}
+}
+
+func TestReal_Now(t *testing.T) {
+	var c Clock = Real{}
+	before := time.Now()
+	got := c.Now()
+	if got.Before(before) || c.Since(before) < 0 {
+		t.Errorf("Real clock went backwards: %v before %v", got, before)
+	}
+}
@@ -41,6 +41,48 @@ const (
 
 	// BufferFlushInterval is how often to flush the Redis buffer to database.
 	BufferFlushInterval = 2 * time.Second
+
+	// BufferFlushMinBatchSize and BufferFlushMaxBatchSize bound the batch
+	// size when the flusher adapts it to load.
+	BufferFlushMinBatchSize = 1000
+	BufferFlushMaxBatchSize = 80000
+
+	// BulkQueryConcurrency is how many batches of a bulk agent-target pair
+	// lookup run in parallel by default (overridable via
+	// ICMPMON_BULK_QUERY_CONCURRENCY, capped at the pool size).
+	BulkQueryConcurrency = 4
+)
+
+// Aggregate-at-ingest configuration for tiers with an aggregated ingest mode.
+const (
+	// ProbeAggregateBucket is the rollup width written to probe_1min for
+	// tiers that don't set their own aggregate bucket.
+	ProbeAggregateBucket = time.Minute
+
+	// ProbeAggregateGrace is how long after a bucket ends its rollup stays
+	// open in Redis for late results before it is flushed. Results arriving
+	// later still land; they are merged into the stored row.
+	ProbeAggregateGrace = 2 * time.Minute
+
+	// ProbeAggregateKeyTTL expires rollups a flusher never collected
+	// (e.g. every control plane down), so Redis cannot grow unbounded.
+	ProbeAggregateKeyTTL = 24 * time.Hour
+
+	// IngestModeRefreshInterval is how often the flusher reloads which
+	// targets belong to aggregated tiers.
+	IngestModeRefreshInterval = time.Minute
+
+	// AggregatedRawRetention is how long raw results are kept for tiers in
+	// the "aggregate" ingest mode that don't set their own raw retention
+	// (bounded by types.TierMinRawRetention and TierMaxRawRetention). It
+	// must stay under the probe_results compression delay (3h) so pruning
+	// only touches uncompressed chunks, and over the alert evaluation
+	// windows that read raw rows. Baselines for these tiers are computed
+	// from this window rather than 7 days.
+	AggregatedRawRetention = 2 * time.Hour
+
+	// AggregatedRawPruneInterval is how often those raw results are pruned.
+	AggregatedRawPruneInterval = 10 * time.Minute
 )
 
 // Pagination defaults for API list endpoints.
@@ -85,15 +127,40 @@ const (
 
 	// CacheTTLTargetList is the TTL for target list data.
 	CacheTTLTargetList = 60 * time.Second
+
+	// CacheTTLMetricsQuery is the TTL for flexible metrics query results.
+	CacheTTLMetricsQuery = 30 * time.Second
+
+	// CacheTTLPrometheus is the TTL for the Prometheus exposition, short
+	// enough that a typical scrape interval sees fresh values.
+	CacheTTLPrometheus = 15 * time.Second
+
+	// CacheMemoryMaxEntries is how many responses the in-memory cache
+	// holds before evicting the least recently used.
+	CacheMemoryMaxEntries = 10000
 )
 
+// TargetStatusWindow is how far back target statuses, and the Prometheus
+// target gauges built on them, look at probe results.
+const TargetStatusWindow = 2 * time.Minute
+
 // Database connection configuration.
 const (
 	// DatabasePingTimeout is the timeout for database connectivity checks.
 	DatabasePingTimeout = 5 * time.Second
 
+	// ReadinessCheckTimeout bounds all checks behind the readiness endpoint.
+	ReadinessCheckTimeout = 3 * time.Second
+
 	// RedisConnectionTimeout is the timeout for Redis connectivity checks.
 	RedisConnectionTimeout = 5 * time.Second
+
+	// ReplicaMaxLag is the default replication lag beyond which dashboard
+	// reads fall back from the read replica to the primary.
+	ReplicaMaxLag = 10 * time.Second
+
+	// ReplicaLagCheckInterval is how often replica lag is measured.
+	ReplicaLagCheckInterval = 5 * time.Second
 )
 
 // Agent polling and heartbeat intervals.
@@ -103,4 +170,442 @@ const (
 
 	// CommandPollInterval is how often agents poll for commands.
 	CommandPollInterval = 5 * time.Second
+
+	// SubnetSweepCommandTTL is how long a subnet sweep waits for an agent
+	// to pick it up before expiring.
+	SubnetSweepCommandTTL = 10 * time.Minute
+
+	// PMTUDCommandTTL is how long a path MTU discovery command waits for
+	// agents to pick it up before expiring.
+	PMTUDCommandTTL = 5 * time.Minute
+)
+
+// Report formatting precision (decimal places) for customer-facing output.
+const (
+	// ReportLatencyDecimals is the precision for latency and jitter values.
+	ReportLatencyDecimals = 2
+
+	// ReportLossDecimals is the precision for packet loss percentages.
+	ReportLossDecimals = 3
+
+	// ReportUptimeDecimals is the precision for uptime percentages.
+	ReportUptimeDecimals = 3
+
+	// MaxReportDecimals caps caller-requested precision.
+	MaxReportDecimals = 6
 )
+
+// Latency forecasting over probe_hourly.
+const (
+	// ForecastLookback is how much hourly history the trend is fitted to.
+	ForecastLookback = 7 * 24 * time.Hour
+
+	// ForecastDefaultHorizon is how far ahead to project when not specified.
+	ForecastDefaultHorizon = 24 * time.Hour
+
+	// ForecastMaxHorizon caps the projection; a linear fit says little
+	// about latency further out than its own lookback.
+	ForecastMaxHorizon = ForecastLookback
+
+	// ForecastStep is the spacing of projected points, matching probe_hourly.
+	ForecastStep = time.Hour
+
+	// ForecastMinSamples is the fewest hourly samples worth fitting.
+	ForecastMinSamples = 24
+
+	// ForecastConfidenceZ is the z-score for the 95% prediction band and for
+	// deciding whether a slope is significant.
+	ForecastConfidenceZ = 1.96
+
+	// ForecastDefaultThresholdMs is used when the escalation_latency_warning_ms
+	// alert config is unset.
+	ForecastDefaultThresholdMs = 100.0
+)
+
+// Target availability SLIs over probe_hourly.
+const (
+	// AvailabilityFailedHourRatio is the success ratio below which an hour
+	// counts as failed for mean time between failures.
+	AvailabilityFailedHourRatio = 0.5
+
+	// AvailabilityBucket is the width of the hourly aggregates windows are
+	// built from; windows end on a bucket boundary.
+	AvailabilityBucket = time.Hour
+)
+
+// Incident postmortem export.
+const (
+	// PostmortemWindowPadding is how much probe history before detection and
+	// after resolution is included, so the review shows the lead-in and the
+	// recovery.
+	PostmortemWindowPadding = time.Hour
+
+	// PostmortemOpenWindowCap bounds the window of an incident that is still
+	// open, measured back from now.
+	PostmortemOpenWindowCap = 7 * 24 * time.Hour
+
+	// PostmortemMinBucket is the finest probe history bucket.
+	PostmortemMinBucket = time.Minute
+
+	// PostmortemMaxBuckets caps the points per target; long incidents get
+	// coarser buckets instead of more of them.
+	PostmortemMaxBuckets = 240
+
+	// PostmortemMaxAlerts caps the linked alerts listed.
+	PostmortemMaxAlerts = 500
+)
+
+// Subnet coverage gaps.
+const (
+	// CoverageReportingWindow is how recent a probe result must be for its
+	// agent to count as reporting on a target.
+	CoverageReportingWindow = 15 * time.Minute
+)
+
+// Subnet activity.
+const (
+	// SubnetServiceStatusChangesLimit caps the service status changes
+	// listed alongside a subnet's activity, which target events would
+	// otherwise push out of view.
+	SubnetServiceStatusChangesLimit = 20
+)
+
+// Agent anomaly triage ("likely agent-side issue" heuristic).
+const (
+	// AgentSideMinAffectedTargets is the fewest affected targets before an
+	// agent can be flagged; a couple of bad targets say little about it.
+	AgentSideMinAffectedTargets = 3
+
+	// AgentSideFleetMultiple is how many times the fleet median an agent's
+	// affected targets must exceed to be flagged. The median is floored at
+	// one so a quiet fleet doesn't flag every agent with a few problems.
+	AgentSideFleetMultiple = 3.0
+)
+
+// Result batch validation defaults (overridable via ICMPMON_RESULT_MAX_*).
+const (
+	// ResultMaxClockSkew is how far in the future a result may be stamped
+	// before it is rejected as coming from an agent with a bad clock.
+	ResultMaxClockSkew = 5 * time.Minute
+
+	// ResultMaxAge is the oldest result accepted; older ones fall behind
+	// compression and evaluation and are rejected. Zero disables the check.
+	ResultMaxAge = 24 * time.Hour
+
+	// ResultRejectionLogSample caps how many rejected results are logged
+	// per batch.
+	ResultRejectionLogSample = 5
+
+	// ResultClockAheadLogThreshold is how far ahead of the server an agent's
+	// newest result must be before its clock is logged as ahead. Smaller
+	// leads are ordinary NTP jitter and are clamped silently.
+	ResultClockAheadLogThreshold = 5 * time.Second
+)
+
+// Per-provider fleet health rollup.
+const (
+	// ProviderHealthDefaultWindow is the comparison window when not given.
+	ProviderHealthDefaultWindow = 24 * time.Hour
+
+	// ProviderHealthMinWindow matches the probe_hourly bucket the probe
+	// stats are read from.
+	ProviderHealthMinWindow = time.Hour
+
+	// ProviderHealthMaxWindow matches the agent_metrics retention.
+	ProviderHealthMaxWindow = 30 * 24 * time.Hour
+
+	// AgentUptimeBucket is the granularity of agent uptime: a bucket with
+	// at least one heartbeat counts as up. It must exceed the heartbeat
+	// interval.
+	AgentUptimeBucket = time.Minute
+)
+
+// Target annotations.
+const (
+	// AnnotationDefaultWindow is how far back the annotations endpoint
+	// looks when no window is given.
+	AnnotationDefaultWindow = 24 * time.Hour
+
+	// AnnotationMaxWindow bounds the annotations endpoint's window so one
+	// request can't walk a target's whole incident history.
+	AnnotationMaxWindow = 90 * 24 * time.Hour
+)
+
+// Agent assignment fetches.
+const (
+	// AssignmentCacheTTL is how long an agent's computed assignments are
+	// reused for fetches at the same assignment version.
+	AssignmentCacheTTL = 5 * time.Second
+)
+
+// Assignment affinity rules.
+const (
+	// AffinityCheckSampleSize caps the unsatisfiable target IDs listed when
+	// checking a rule.
+	AffinityCheckSampleSize = 10
+)
+
+// Result shipment rollup in the fleet overview.
+const (
+	// ShipmentWindow is how far back the fleet overview totals shipping.
+	ShipmentWindow = time.Hour
+
+	// LargePayloadFleetMultiple is how many times the fleet median bytes
+	// per result an agent's results must exceed to be flagged.
+	LargePayloadFleetMultiple = 3.0
+
+	// LargePayloadMinResults is the fewest results an agent must ship in
+	// the window before its payload size is judged.
+	LargePayloadMinResults = 100
+)
+
+// Tier interval change preview.
+const (
+	// TierPreviewWarnFactor is the probe rate multiple at or above which a
+	// previewed interval change carries a warning.
+	TierPreviewWarnFactor = 2.0
+)
+
+// Event stream (outbox) for integrations.
+const (
+	// EventsDefaultLimit is the number of events returned to a pull
+	// consumer when no limit is given.
+	EventsDefaultLimit = 100
+
+	// EventsMaxLimit caps the events returned in one pull.
+	EventsMaxLimit = 1000
+)
+
+// Kafka publishing (through a Kafka REST Proxy).
+const (
+	// KafkaQueueSize is how many probe results may wait to be published.
+	// When the queue is full, ingestion waits up to KafkaEnqueueTimeout and
+	// then drops the rest of the batch from the Kafka feed (the database
+	// write is unaffected).
+	KafkaQueueSize = 50000
+
+	// KafkaEnqueueTimeout bounds how long one ingest call waits for room in
+	// a full queue.
+	KafkaEnqueueTimeout = 100 * time.Millisecond
+
+	// KafkaBatchSize is the most records sent to the proxy in one request.
+	KafkaBatchSize = 500
+
+	// KafkaFlushInterval is the longest a queued result waits for its batch
+	// to fill before being sent.
+	KafkaFlushInterval = time.Second
+)
+
+// Live probe results view.
+const (
+	// LiveResultsDefaultWindow is how far back the live view looks when no
+	// window is given.
+	LiveResultsDefaultWindow = time.Minute
+
+	// LiveResultsMaxWindow caps how far back the live view looks.
+	LiveResultsMaxWindow = 5 * time.Minute
+
+	// LiveResultsLimit caps the results returned to the live view. Under
+	// high fanout they are sampled evenly across agents.
+	LiveResultsLimit = 500
+
+	// LiveAnomalyZScore is the latency z-score against the agent-target
+	// baseline at which a live result is flagged anomalous, the same as
+	// the evaluator's warning threshold.
+	LiveAnomalyZScore = 3.0
+
+	// LiveStreamPollInterval is how often a live result stream checks for
+	// new results.
+	LiveStreamPollInterval = time.Second
+
+	// LiveStreamLookback is how far back each live stream poll looks, so
+	// results shipped a little late are still sent. The first poll sends
+	// the whole lookback as backfill.
+	LiveStreamLookback = 30 * time.Second
+
+	// LiveStreamKeepalive is the longest a live stream stays silent, so
+	// proxies don't close it as idle.
+	LiveStreamKeepalive = 15 * time.Second
+
+	// LiveStreamsPerTarget caps concurrent live streams of one target on
+	// each instance.
+	LiveStreamsPerTarget = 20
+)
+
+// Target diagnostics bundle.
+const (
+	// DiagnosticsSectionTimeout bounds each section of a diagnostics bundle;
+	// a section that takes longer is reported as failed.
+	DiagnosticsSectionTimeout = 5 * time.Second
+
+	// DiagnosticsHistoryWindow is how much probe history the bundle carries.
+	DiagnosticsHistoryWindow = time.Hour
+
+	// DiagnosticsAlertLimit caps the active alerts in the bundle.
+	DiagnosticsAlertLimit = 50
+
+	// DiagnosticsCommandLimit caps the recent commands (MTRs) in the bundle.
+	DiagnosticsCommandLimit = 10
+)
+
+// Grafana data source.
+const (
+	// GrafanaSearchLimit caps the agents or targets a search returns.
+	GrafanaSearchLimit = 100
+
+	// GrafanaAnnotationLimit caps each source's annotations in a response.
+	GrafanaAnnotationLimit = 1000
+)
+
+// Alert escalation policies.
+const (
+	// EscalationAlertLimit caps the unacknowledged alerts checked against
+	// escalation policies per alert worker cycle, oldest first.
+	EscalationAlertLimit = 1000
+)
+
+// TLS certificate expiry.
+const (
+	// CertExpiryWarningDays is the default days before expiry at which a
+	// certificate raises a warning (alert_config cert_expiry_warning_days).
+	CertExpiryWarningDays = 30
+
+	// CertExpiryCriticalDays is the default days before expiry at which the
+	// alert turns critical (alert_config cert_expiry_critical_days).
+	CertExpiryCriticalDays = 7
+
+	// CertStaleAfter is how long a certificate goes unobserved before the
+	// expiry watchdog stops judging it: the target stopped answering or
+	// was switched to another probe type.
+	CertStaleAfter = 24 * time.Hour
+
+	// CertListDefaultLimit is the number of certificates listed when no
+	// limit is given.
+	CertListDefaultLimit = 500
+
+	// CertListMaxLimit caps the certificates listed in one request.
+	CertListMaxLimit = 5000
+)
+
+// Probe accounting.
+const (
+	// AccountingDefaultWindow is the range reported when no start is given.
+	AccountingDefaultWindow = 30 * 24 * time.Hour
+
+	// AccountingMaxWindow caps the range of one accounting request.
+	AccountingMaxWindow = 2 * 366 * 24 * time.Hour
+)
+
+// Target ASN enrichment.
+const (
+	// ASNRefreshAfter is how long a target's ASN lookup stands before it is
+	// looked up again, picking up a newer dataset or a re-announced prefix.
+	ASNRefreshAfter = 7 * 24 * time.Hour
+)
+
+// Target endpoints.
+const (
+	// EndpointStatusDefaultWindow is how far back endpoint status looks when
+	// no window is given, and the window the endpoint family watchdog judges.
+	EndpointStatusDefaultWindow = 5 * time.Minute
+
+	// EndpointHistoryDefaultWindow is the per-endpoint history range when no
+	// window is given, matching the target history default.
+	EndpointHistoryDefaultWindow = time.Hour
+
+	// EndpointStatusMaxWindow caps the window of one endpoint status or
+	// history request.
+	EndpointStatusMaxWindow = 7 * 24 * time.Hour
+
+	// WorstAgentsDefaultWindow and WorstAgentsMaxWindow bound the recent
+	// raw results a worst-agents ranking reads.
+	WorstAgentsDefaultWindow = 15 * time.Minute
+	WorstAgentsMaxWindow     = 24 * time.Hour
+
+	// WorstAgentsDefaultLimit is how many agents a worst-agents ranking
+	// returns unless asked for more, up to WorstAgentsMaxLimit.
+	WorstAgentsDefaultLimit = 20
+	WorstAgentsMaxLimit     = 500
+
+	// EndpointsPerTargetMax caps the additional endpoints on one target;
+	// each is probed by every agent assigned to the target.
+	EndpointsPerTargetMax = 8
+)
+
+// Regional latency asymmetry.
+const (
+	// LatencyAsymmetryFactor is the default slowest-over-fastest region
+	// latency ratio that counts as asymmetric (alert_config
+	// latency_asymmetry_factor).
+	LatencyAsymmetryFactor = 3.0
+
+	// LatencyAsymmetryMinDeltaMs is the default smallest slowest-minus-fastest
+	// gap that counts (alert_config latency_asymmetry_min_delta_ms).
+	LatencyAsymmetryMinDeltaMs = 30.0
+
+	// LatencyAsymmetrySustain is the default time a target must stay
+	// asymmetric before it is alerted on (alert_config
+	// latency_asymmetry_sustain_minutes).
+	LatencyAsymmetrySustain = 10 * time.Minute
+
+	// LatencyAsymmetryWindow is how recently an agent must have probed a
+	// target for its current latency to count toward its region.
+	LatencyAsymmetryWindow = 5 * time.Minute
+)
+
+// Result ship lag.
+const (
+	// ShipLagWindow is how far back an agent's batch ship lags are
+	// summarised for stats and alerting.
+	ShipLagWindow = 5 * time.Minute
+
+	// ShipLagAlertThreshold is the default p95 ship lag or oldest queued
+	// result age at which an agent is falling behind (alert_config
+	// ship_lag_alert_seconds).
+	ShipLagAlertThreshold = 2 * time.Minute
+)
+
+// Singleton workers, which run on one control plane instance at a time.
+const (
+	// SingletonLockInterval is how often a standby instance tries to take a
+	// singleton worker's advisory lock, and how often the leader checks it
+	// still holds it. A failed-over worker resumes within about this long.
+	SingletonLockInterval = 5 * time.Second
+
+	// SingletonLockTimeout bounds each lock attempt, check and release.
+	SingletonLockTimeout = 5 * time.Second
+)
+
+// Bounds on worker config overrides from the environment.
+const (
+	// WorkerIntervalMin and WorkerIntervalMax bound a worker's run interval.
+	WorkerIntervalMin = time.Second
+	WorkerIntervalMax = 24 * time.Hour
+
+	// WorkerWindowMax bounds lookback windows and state thresholds.
+	WorkerWindowMax = 30 * 24 * time.Hour
+
+	// WorkerBatchSizeMax bounds a worker's batch size.
+	WorkerBatchSizeMax = 100000
+)
+
+// Never-responded archival, which retires auto-owned targets that never
+// answered discovery so they stop using probe budget.
+const (
+	// NeverRespondedWindow is how long after creation a never-responsive
+	// target is given to answer before it is archived.
+	NeverRespondedWindow = 7 * 24 * time.Hour
+
+	// NeverRespondedMaxAttempts is the fewest failed discovery probes a
+	// target must have before it is archived, so targets that were never
+	// actually probed are left alone.
+	NeverRespondedMaxAttempts = 5
+
+	// NeverRespondedArchiveBatch bounds the targets archived per state
+	// worker cycle; the rest are picked up by later cycles.
+	NeverRespondedArchiveBatch = 1000
+)
+
+// BulkTargetCreateMax caps the targets in one bulk create request, which
+// are inserted in a single transaction.
+const BulkTargetCreateMax = 5000
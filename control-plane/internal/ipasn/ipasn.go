@@ -0,0 +1,150 @@
+// Package ipasn maps IP addresses to their origin ASN and country from a
+// local IP-to-ASN dataset, so targets can be enriched without a network
+// lookup per address.
+//
+// The dataset is the tab-separated format published by iptoasn.com
+// (ip2asn-v4.tsv, ip2asn-v6.tsv or ip2asn-combined.tsv), optionally
+// gzipped:
+//
+//	range_start  range_end  as_number  country_code  as_description
+//
+// Ranges announced by no one carry AS 0 and are skipped.
+package ipasn
+
+import (
+	"bufio"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Record is what the dataset knows about an address.
+type Record struct {
+	ASN     int
+	ASName  string
+	Country string // ISO 3166 alpha-2, empty when unknown
+}
+
+type ipRange struct {
+	start, end netip.Addr
+	record     Record
+}
+
+// Database is an in-memory IP-to-ASN table. It is read-only after loading
+// and safe for concurrent use.
+type Database struct {
+	ranges []ipRange
+}
+
+// Open loads a dataset from path, decompressing it if it ends in .gz.
+func Open(path string) (*Database, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening ASN dataset: %w", err)
+	}
+	defer f.Close()
+
+	var r io.Reader = f
+	if strings.HasSuffix(path, ".gz") {
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			return nil, fmt.Errorf("decompressing ASN dataset: %w", err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+	return Load(r)
+}
+
+// Load reads a dataset. Malformed lines are errors; unannounced ranges are
+// dropped.
+func Load(r io.Reader) (*Database, error) {
+	db := &Database{}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := scanner.Text()
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		rng, ok, err := parseLine(text)
+		if err != nil {
+			return nil, fmt.Errorf("ASN dataset line %d: %w", line, err)
+		}
+		if ok {
+			db.ranges = append(db.ranges, rng)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading ASN dataset: %w", err)
+	}
+
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return db.ranges[i].start.Less(db.ranges[j].start)
+	})
+	return db, nil
+}
+
+func parseLine(text string) (ipRange, bool, error) {
+	fields := strings.SplitN(text, "\t", 5)
+	if len(fields) < 4 {
+		return ipRange{}, false, fmt.Errorf("want at least 4 tab-separated fields, got %d", len(fields))
+	}
+	start, err := netip.ParseAddr(fields[0])
+	if err != nil {
+		return ipRange{}, false, fmt.Errorf("range start: %w", err)
+	}
+	end, err := netip.ParseAddr(fields[1])
+	if err != nil {
+		return ipRange{}, false, fmt.Errorf("range end: %w", err)
+	}
+	if start.Is4() != end.Is4() || end.Less(start) {
+		return ipRange{}, false, fmt.Errorf("invalid range %s-%s", start, end)
+	}
+	asn, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return ipRange{}, false, fmt.Errorf("AS number: %w", err)
+	}
+	if asn == 0 {
+		return ipRange{}, false, nil
+	}
+
+	rec := Record{ASN: asn}
+	if cc := fields[3]; cc != "None" && cc != "Unknown" {
+		rec.Country = cc
+	}
+	if len(fields) == 5 && fields[4] != "Not routed" {
+		rec.ASName = fields[4]
+	}
+	return ipRange{start: start, end: end, record: rec}, true, nil
+}
+
+// Len returns the number of announced ranges loaded.
+func (db *Database) Len() int {
+	return len(db.ranges)
+}
+
+// Lookup returns the record of the range containing ip. IPv4-mapped IPv6
+// addresses are looked up as IPv4.
+func (db *Database) Lookup(ip netip.Addr) (Record, bool) {
+	ip = ip.Unmap()
+	// The last range starting at or before ip is the only candidate;
+	// dataset ranges don't overlap
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return ip.Less(db.ranges[i].start)
+	}) - 1
+	if i < 0 {
+		return Record{}, false
+	}
+	rng := db.ranges[i]
+	if rng.start.Is4() != ip.Is4() || rng.end.Less(ip) {
+		return Record{}, false
+	}
+	return rng.record, true
+}
@@ -0,0 +1,69 @@
+package ipasn
+
+import (
+	"net/netip"
+	"strings"
+	"testing"
+)
+
+const dataset = "1.0.0.0\t1.0.0.255\t13335\tUS\tCLOUDFLARENET\n" +
+	"1.0.1.0\t1.0.3.255\t0\tNone\tNot routed\n" +
+	"8.8.8.0\t8.8.8.255\t15169\tUS\tGOOGLE\n" +
+	"2001:4860::\t2001:4860:ffff:ffff:ffff:ffff:ffff:ffff\t15169\tUS\tGOOGLE\n" +
+	"# trailing comment\n" +
+	"203.0.113.0\t203.0.113.127\t64500\tUnknown\n"
+
+func TestDatabase_Lookup(t *testing.T) {
+	db, err := Load(strings.NewReader(dataset))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if db.Len() != 4 {
+		t.Errorf("Len() = %d, want 4 announced ranges", db.Len())
+	}
+
+	tests := []struct {
+		ip     string
+		want   Record
+		wantOK bool
+	}{
+		{ip: "1.0.0.1", want: Record{ASN: 13335, ASName: "CLOUDFLARENET", Country: "US"}, wantOK: true},
+		{ip: "1.0.0.255", want: Record{ASN: 13335, ASName: "CLOUDFLARENET", Country: "US"}, wantOK: true},
+		{ip: "8.8.8.8", want: Record{ASN: 15169, ASName: "GOOGLE", Country: "US"}, wantOK: true},
+		{ip: "::ffff:8.8.8.8", want: Record{ASN: 15169, ASName: "GOOGLE", Country: "US"}, wantOK: true},
+		{ip: "2001:4860:4860::8888", want: Record{ASN: 15169, ASName: "GOOGLE", Country: "US"}, wantOK: true},
+		{ip: "203.0.113.5", want: Record{ASN: 64500}, wantOK: true},
+		{ip: "1.0.2.1"},
+		{ip: "8.8.9.1"},
+		{ip: "0.0.0.1"},
+		{ip: "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			got, ok := db.Lookup(netip.MustParseAddr(tt.ip))
+			if ok != tt.wantOK || got != tt.want {
+				t.Errorf("Lookup(%s) = %+v, %v; want %+v, %v", tt.ip, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestLoad_Malformed(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"too few fields", "1.0.0.0\t1.0.0.255\t13335"},
+		{"bad start", "1.0.0\t1.0.0.255\t13335\tUS\tX"},
+		{"bad asn", "1.0.0.0\t1.0.0.255\tAS13335\tUS\tX"},
+		{"reversed range", "1.0.0.255\t1.0.0.0\t13335\tUS\tX"},
+		{"mixed families", "1.0.0.0\t2001:db8::\t13335\tUS\tX"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(strings.NewReader(tt.line + "\n")); err == nil {
+				t.Error("Load() error = nil, want error")
+			}
+		})
+	}
+}
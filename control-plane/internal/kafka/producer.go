@@ -0,0 +1,313 @@
+// Package kafka publishes probe results and domain events to Kafka topics
+// for data platforms, so analytics can consume the result firehose without
+// querying TimescaleDB. Records go through a Kafka REST Proxy (v2 API),
+// which keeps the control plane free of a native Kafka client.
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+const (
+	contentType = "application/vnd.kafka.json.v2+json"
+	acceptType  = "application/vnd.kafka.v2+json"
+
+	// maxErrorBody caps how much of a failed response is kept for the error.
+	maxErrorBody = 512
+)
+
+// Config holds the Kafka publishing settings.
+type Config struct {
+	RESTURL      string // Kafka REST Proxy base URL
+	ResultsTopic string // Topic for probe results; empty disables them
+	EventsTopic  string // Topic for domain events; empty disables them
+
+	QueueSize      int
+	EnqueueTimeout time.Duration
+	BatchSize      int
+	FlushInterval  time.Duration
+}
+
+// ConfigFromEnv reads the Kafka settings from environment variables.
+func ConfigFromEnv() Config {
+	return Config{
+		RESTURL:        strings.TrimRight(os.Getenv("ICMPMON_KAFKA_REST_URL"), "/"),
+		ResultsTopic:   os.Getenv("ICMPMON_KAFKA_RESULTS_TOPIC"),
+		EventsTopic:    os.Getenv("ICMPMON_KAFKA_EVENTS_TOPIC"),
+		QueueSize:      config.KafkaQueueSize,
+		EnqueueTimeout: config.KafkaEnqueueTimeout,
+		BatchSize:      config.KafkaBatchSize,
+		FlushInterval:  config.KafkaFlushInterval,
+	}
+}
+
+// Enabled reports whether a proxy and at least one topic are configured.
+func (c Config) Enabled() bool {
+	return c.RESTURL != "" && (c.ResultsTopic != "" || c.EventsTopic != "")
+}
+
+// Stats reports what happened to the probe results handed to the producer.
+type Stats struct {
+	Queued    int64 `json:"queued"`    // Accepted into the queue
+	Delivered int64 `json:"delivered"` // Acknowledged by Kafka
+	Failed    int64 `json:"failed"`    // Rejected by the proxy or Kafka
+	Dropped   int64 `json:"dropped"`   // Shed because the queue was full
+}
+
+// record is one Kafka message in the REST Proxy's JSON embedded format.
+type record struct {
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Producer publishes to Kafka. Probe results are queued and sent in batches
+// by a background sender, so a slow or unavailable Kafka never holds up
+// ingestion for longer than EnqueueTimeout; events are published
+// synchronously so the event dispatcher only advances on success.
+type Producer struct {
+	config Config
+	client *http.Client
+	logger *slog.Logger
+
+	queue  chan record
+	stopCh chan struct{}
+	doneCh chan struct{}
+
+	queued    atomic.Int64
+	delivered atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+}
+
+// NewProducer creates a producer. Call Start to begin sending results.
+func NewProducer(cfg Config, logger *slog.Logger) *Producer {
+	return &Producer{
+		config: cfg,
+		client: &http.Client{Timeout: config.DefaultHTTPTimeout},
+		logger: logger.With("component", "kafka_producer"),
+		queue:  make(chan record, cfg.QueueSize),
+		stopCh: make(chan struct{}),
+		doneCh: make(chan struct{}),
+	}
+}
+
+// Start begins the result sender in a goroutine.
+func (p *Producer) Start() {
+	go p.run()
+}
+
+// Close stops the sender after publishing what is still queued.
+func (p *Producer) Close() {
+	close(p.stopCh)
+	<-p.doneCh
+	stats := p.Stats()
+	p.logger.Info("kafka producer stopped",
+		"delivered", stats.Delivered,
+		"failed", stats.Failed,
+		"dropped", stats.Dropped,
+	)
+}
+
+// Stats returns the producer's delivery counters.
+func (p *Producer) Stats() Stats {
+	return Stats{
+		Queued:    p.queued.Load(),
+		Delivered: p.delivered.Load(),
+		Failed:    p.failed.Load(),
+		Dropped:   p.dropped.Load(),
+	}
+}
+
+// PublishResults queues probe results for the results topic, keyed by
+// target so each target's results stay in order within a partition. When
+// the queue is full it waits up to EnqueueTimeout for room and then drops
+// the rest of the batch.
+func (p *Producer) PublishResults(results []types.ProbeResult) {
+	if p.config.ResultsTopic == "" || len(results) == 0 {
+		return
+	}
+
+	var timeout <-chan time.Time
+	for i, r := range results {
+		value, err := json.Marshal(r)
+		if err != nil {
+			p.logger.Error("failed to encode result for kafka", "target", r.TargetID, "error", err)
+			continue
+		}
+		rec := record{Key: r.TargetID, Value: value}
+
+		select {
+		case p.queue <- rec:
+			p.queued.Add(1)
+			continue
+		default:
+		}
+
+		// Queue full: give the sender a moment to drain it
+		if timeout == nil {
+			timer := time.NewTimer(p.config.EnqueueTimeout)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+		select {
+		case p.queue <- rec:
+			p.queued.Add(1)
+		case <-timeout:
+			dropped := len(results) - i
+			p.dropped.Add(int64(dropped))
+			p.logger.Warn("kafka queue full, dropping results",
+				"dropped", dropped,
+				"queue_size", cap(p.queue),
+			)
+			return
+		}
+	}
+}
+
+func (p *Producer) run() {
+	defer close(p.doneCh)
+
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]record, 0, p.config.BatchSize)
+	flush := func(ctx context.Context) {
+		if len(batch) > 0 {
+			p.sendResults(ctx, batch)
+			batch = batch[:0]
+		}
+	}
+
+	for {
+		select {
+		case rec := <-p.queue:
+			batch = append(batch, rec)
+			if len(batch) >= p.config.BatchSize {
+				flush(context.Background())
+			}
+		case <-ticker.C:
+			flush(context.Background())
+		case <-p.stopCh:
+			// Drain what was queued before shutdown, within one timeout
+			ctx, cancel := context.WithTimeout(context.Background(), config.DefaultHTTPTimeout)
+			defer cancel()
+			for {
+				select {
+				case rec := <-p.queue:
+					batch = append(batch, rec)
+					if len(batch) >= p.config.BatchSize {
+						flush(ctx)
+					}
+				default:
+					flush(ctx)
+					return
+				}
+			}
+		}
+	}
+}
+
+// sendResults publishes a batch of results and records the outcome.
+func (p *Producer) sendResults(ctx context.Context, batch []record) {
+	delivered, err := p.produce(ctx, p.config.ResultsTopic, batch)
+	p.delivered.Add(int64(delivered))
+	if failed := len(batch) - delivered; failed > 0 {
+		p.failed.Add(int64(failed))
+		p.logger.Warn("kafka result delivery failed",
+			"topic", p.config.ResultsTopic,
+			"records", len(batch),
+			"failed", failed,
+			"error", err,
+		)
+	}
+}
+
+// Deliver publishes events to the kafka consumer's topic, keyed by entity.
+// It fails unless Kafka acknowledges every event, in which case the event
+// dispatcher retries the whole batch. Deliver implements worker.EventSink.
+func (p *Producer) Deliver(ctx context.Context, consumer *types.EventConsumer, events []types.Event) error {
+	batch := make([]record, 0, len(events))
+	for _, e := range events {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return fmt.Errorf("encoding event %d: %w", e.Seq, err)
+		}
+		batch = append(batch, record{Key: e.EntityID, Value: value})
+	}
+	_, err := p.produce(ctx, consumer.URL, batch)
+	return err
+}
+
+// produceResponse is the REST Proxy's reply to a produce request, with one
+// offset entry per record in request order.
+type produceResponse struct {
+	Offsets []struct {
+		Partition int    `json:"partition"`
+		Offset    int64  `json:"offset"`
+		ErrorCode *int   `json:"error_code"`
+		Error     string `json:"error"`
+	} `json:"offsets"`
+}
+
+// produce sends records to a topic and returns how many Kafka acknowledged.
+// The error describes the first failure when any record was not delivered.
+func (p *Producer) produce(ctx context.Context, topic string, records []record) (int, error) {
+	body, err := json.Marshal(map[string]any{"records": records})
+	if err != nil {
+		return 0, fmt.Errorf("encoding records: %w", err)
+	}
+
+	endpoint := p.config.RESTURL + "/topics/" + url.PathEscape(topic)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("creating produce request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", acceptType)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("posting to kafka rest proxy: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return 0, fmt.Errorf("kafka rest proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+
+	var result produceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return 0, fmt.Errorf("decoding produce response: %w", err)
+	}
+	if len(result.Offsets) != len(records) {
+		return 0, fmt.Errorf("kafka rest proxy acknowledged %d of %d records", len(result.Offsets), len(records))
+	}
+
+	delivered := 0
+	var firstErr error
+	for _, o := range result.Offsets {
+		if o.ErrorCode == nil && o.Error == "" {
+			delivered++
+			continue
+		}
+		if firstErr == nil {
+			firstErr = fmt.Errorf("producing to %s partition %d: %s", topic, o.Partition, o.Error)
+		}
+	}
+	return delivered, firstErr
+}
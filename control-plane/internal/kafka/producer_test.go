@@ -0,0 +1,142 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func testProducer(t *testing.T, handler http.HandlerFunc) *Producer {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return NewProducer(Config{
+		RESTURL:        srv.URL,
+		ResultsTopic:   "icmpmon.results",
+		QueueSize:      2,
+		EnqueueTimeout: 10 * time.Millisecond,
+		BatchSize:      10,
+		FlushInterval:  time.Hour,
+	}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+}
+
+func TestProduce_DeliveryReport(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        int
+		response      string
+		wantDelivered int
+		wantErr       string
+	}{
+		{
+			name:          "all acknowledged",
+			status:        http.StatusOK,
+			response:      `{"offsets":[{"partition":0,"offset":7},{"partition":1,"offset":3}]}`,
+			wantDelivered: 2,
+		},
+		{
+			name:          "one record rejected",
+			status:        http.StatusOK,
+			response:      `{"offsets":[{"partition":0,"offset":7},{"partition":1,"error_code":50003,"error":"leader not available"}]}`,
+			wantDelivered: 1,
+			wantErr:       "leader not available",
+		},
+		{
+			name:     "proxy error",
+			status:   http.StatusNotFound,
+			response: `{"error_code":40401,"message":"Topic not found."}`,
+			wantErr:  "returned 404",
+		},
+		{
+			name:     "short acknowledgement",
+			status:   http.StatusOK,
+			response: `{"offsets":[{"partition":0,"offset":7}]}`,
+			wantErr:  "acknowledged 1 of 2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := testProducer(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/topics/icmpmon.results" {
+					t.Errorf("path = %q", r.URL.Path)
+				}
+				if ct := r.Header.Get("Content-Type"); ct != contentType {
+					t.Errorf("Content-Type = %q", ct)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.response)
+			})
+
+			records := []record{
+				{Key: "t1", Value: json.RawMessage(`{}`)},
+				{Key: "t2", Value: json.RawMessage(`{}`)},
+			}
+			delivered, err := p.produce(context.Background(), "icmpmon.results", records)
+			if delivered != tt.wantDelivered {
+				t.Errorf("delivered = %d, want %d", delivered, tt.wantDelivered)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("produce() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("produce() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPublishResults_Backpressure(t *testing.T) {
+	p := testProducer(t, func(w http.ResponseWriter, r *http.Request) {
+		t.Error("nothing should be sent before Start")
+	})
+
+	// Queue holds 2; with no sender running the rest are dropped
+	results := []types.ProbeResult{{TargetID: "t1"}, {TargetID: "t2"}, {TargetID: "t3"}, {TargetID: "t4"}}
+	p.PublishResults(results)
+
+	stats := p.Stats()
+	if stats.Queued != 2 || stats.Dropped != 2 {
+		t.Fatalf("stats = %+v, want 2 queued and 2 dropped", stats)
+	}
+}
+
+func TestClose_FlushesQueue(t *testing.T) {
+	var keys []string
+	p := testProducer(t, func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Records []record `json:"records"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decoding body: %v", err)
+		}
+		offsets := make([]map[string]any, len(body.Records))
+		for i, rec := range body.Records {
+			keys = append(keys, rec.Key)
+			offsets[i] = map[string]any{"partition": 0, "offset": i}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"offsets": offsets})
+	})
+
+	p.PublishResults([]types.ProbeResult{{TargetID: "t1"}, {TargetID: "t2"}})
+	p.Start()
+	p.Close()
+
+	if got := strings.Join(keys, ","); got != "t1,t2" {
+		t.Fatalf("published keys = %q, want t1,t2", got)
+	}
+	if stats := p.Stats(); stats.Delivered != 2 || stats.Failed != 0 {
+		t.Fatalf("stats = %+v, want 2 delivered", stats)
+	}
+}
@@ -0,0 +1,253 @@
+// Package mail sends email over SMTP for report delivery and alert notifications.
+//
+// Configuration comes from ICMPMON_SMTP_* environment variables. Three TLS
+// modes are supported: "starttls" (default, upgrade required), "tls"
+// (implicit TLS, typically port 465) and "none" (plaintext, for local relays).
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/smtp"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TLSMode selects how the SMTP connection is secured.
+type TLSMode string
+
+const (
+	TLSModeStartTLS TLSMode = "starttls"
+	TLSModeImplicit TLSMode = "tls"
+	TLSModeNone     TLSMode = "none"
+)
+
+// Default SMTP settings.
+const (
+	DefaultPort = 587
+
+	// DialTimeout bounds connecting to the mail server.
+	DialTimeout = 10 * time.Second
+
+	// SendTimeout bounds a full SMTP exchange when the caller's context has no deadline.
+	SendTimeout = 60 * time.Second
+)
+
+// Config holds the outbound mail server settings.
+type Config struct {
+	Host     string
+	Port     int
+	Username string
+	Password string
+	From     string
+	TLS      TLSMode
+}
+
+// ConfigFromEnv loads SMTP settings from ICMPMON_SMTP_* variables.
+func ConfigFromEnv() Config {
+	port, err := strconv.Atoi(os.Getenv("ICMPMON_SMTP_PORT"))
+	if err != nil || port <= 0 {
+		port = DefaultPort
+	}
+	mode := TLSMode(strings.ToLower(os.Getenv("ICMPMON_SMTP_TLS")))
+	if mode == "" {
+		mode = TLSModeStartTLS
+	}
+	return Config{
+		Host:     os.Getenv("ICMPMON_SMTP_HOST"),
+		Port:     port,
+		Username: os.Getenv("ICMPMON_SMTP_USERNAME"),
+		Password: os.Getenv("ICMPMON_SMTP_PASSWORD"),
+		From:     os.Getenv("ICMPMON_SMTP_FROM"),
+		TLS:      mode,
+	}
+}
+
+// Enabled returns true if enough is configured to send mail.
+func (c Config) Enabled() bool {
+	return c.Host != "" && c.From != ""
+}
+
+// Attachment is a file attached to a message.
+type Attachment struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// Message is a plain-text email with optional attachments.
+type Message struct {
+	To          []string
+	Subject     string
+	Text        string
+	Attachments []Attachment
+}
+
+// Send delivers a message via the configured SMTP server.
+func Send(ctx context.Context, cfg Config, msg Message) error {
+	if !cfg.Enabled() {
+		return fmt.Errorf("smtp is not configured (set ICMPMON_SMTP_HOST and ICMPMON_SMTP_FROM)")
+	}
+	if len(msg.To) == 0 {
+		return fmt.Errorf("no recipients")
+	}
+
+	data, err := msg.Bytes(cfg.From)
+	if err != nil {
+		return fmt.Errorf("building message: %w", err)
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, SendTimeout)
+		defer cancel()
+	}
+
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	conn, err := dial(ctx, cfg, addr)
+	if err != nil {
+		return fmt.Errorf("connecting to %s: %w", addr, err)
+	}
+	defer conn.Close()
+
+	// net/smtp has no context support; bound the exchange with a deadline
+	// and close the connection if the context is cancelled first
+	deadline, _ := ctx.Deadline()
+	conn.SetDeadline(deadline)
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	if err := exchange(conn, cfg, msg.To, data); err != nil {
+		return fmt.Errorf("sending mail via %s: %w", addr, err)
+	}
+	return nil
+}
+
+func dial(ctx context.Context, cfg Config, addr string) (net.Conn, error) {
+	dialer := &net.Dialer{Timeout: DialTimeout}
+	if cfg.TLS == TLSModeImplicit {
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: cfg.Host}}
+		return tlsDialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+func exchange(conn net.Conn, cfg Config, to []string, data []byte) error {
+	c, err := smtp.NewClient(conn, cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	if cfg.TLS == TLSModeStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("server does not support STARTTLS (set ICMPMON_SMTP_TLS=none to allow plaintext)")
+		}
+		if err := c.StartTLS(&tls.Config{ServerName: cfg.Host}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+
+	if cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+
+	if err := c.Mail(cfg.From); err != nil {
+		return fmt.Errorf("mail from: %w", err)
+	}
+	for _, rcpt := range to {
+		if err := c.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("rcpt %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("data: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("writing message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("closing message: %w", err)
+	}
+	return c.Quit()
+}
+
+// Bytes renders the message in RFC 5322 format. Messages with attachments
+// are multipart/mixed; otherwise a single text/plain part.
+func (m Message) Bytes(from string) ([]byte, error) {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().UTC().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+
+	if len(m.Attachments) == 0 {
+		msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+		msg.WriteString(m.Text)
+		return msg.Bytes(), nil
+	}
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	textPart, err := mw.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {"text/plain; charset=utf-8"},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.WriteString(textPart, m.Text); err != nil {
+		return nil, err
+	}
+
+	for _, a := range m.Attachments {
+		part, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {a.ContentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename})},
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err := writeBase64Lines(part, a.Data); err != nil {
+			return nil, err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(&msg, "Content-Type: multipart/mixed; boundary=%q\r\n\r\n", mw.Boundary())
+	msg.Write(body.Bytes())
+	return msg.Bytes(), nil
+}
+
+// base64LineLength is the RFC 2045 maximum encoded line length.
+const base64LineLength = 76
+
+func writeBase64Lines(w io.Writer, data []byte) error {
+	encoded := base64.StdEncoding.EncodeToString(data)
+	for len(encoded) > 0 {
+		n := min(base64LineLength, len(encoded))
+		if _, err := io.WriteString(w, encoded[:n]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[n:]
+	}
+	return nil
+}
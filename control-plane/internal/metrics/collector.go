@@ -19,10 +19,17 @@ type BufferStatsProvider interface {
 	GetStats(ctx context.Context) (types.BufferStats, error)
 }
 
+// AssignmentCacheStatsProvider reports how agent assignment fetches were
+// served.
+type AssignmentCacheStatsProvider interface {
+	AssignmentCacheStats() types.AssignmentCacheStats
+}
+
 // Collector gathers infrastructure metrics with caching.
 type Collector struct {
-	store  *store.Store
-	buffer BufferStatsProvider // may be nil if buffer is disabled
+	store       *store.Store
+	buffer      BufferStatsProvider          // may be nil if buffer is disabled
+	assignments AssignmentCacheStatsProvider // may be nil
 
 	startTime time.Time
 
@@ -43,6 +50,11 @@ func NewCollector(store *store.Store, buffer BufferStatsProvider) *Collector {
 	}
 }
 
+// SetAssignmentCacheStats sets where assignment fetch cache stats come from.
+func (c *Collector) SetAssignmentCacheStats(p AssignmentCacheStatsProvider) {
+	c.assignments = p
+}
+
 // GetInfrastructureHealth returns the current infrastructure health metrics.
 // Results are cached for 30 seconds to avoid expensive database queries.
 func (c *Collector) GetInfrastructureHealth(ctx context.Context) (*types.InfrastructureHealth, error) {
@@ -90,6 +102,10 @@ func (c *Collector) collectHealth(ctx context.Context) (*types.InfrastructureHea
 	// Collect buffer metrics if enabled
 	health.Buffer = c.collectBufferHealth(ctx)
 
+	if c.assignments != nil {
+		health.AssignmentCache = c.assignments.AssignmentCacheStats()
+	}
+
 	// Collect storage forecast
 	forecast, err := c.store.GetStorageForecast(ctx)
 	if err != nil {
@@ -194,6 +210,11 @@ func (c *Collector) collectBufferHealth(ctx context.Context) types.BufferHealth
 		Connected:  stats.Connected,
 		QueueDepth: stats.QueueDepth,
 		FlushRate:  stats.FlushRate,
+
+		BatchSize:      stats.BatchSize,
+		LastBatchSize:  stats.LastBatchSize,
+		FlushLatencyMs: stats.FlushLatencyMs,
+		Flushed:        stats.Flushed,
 	}
 }
 
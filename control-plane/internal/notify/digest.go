@@ -0,0 +1,346 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// EventDigest is a periodic summary emitted while a Digester is coalescing.
+const EventDigest EventType = "alert_digest"
+
+// DigestSummary summarizes the alert events coalesced during a storm.
+type DigestSummary struct {
+	Start      time.Time                   `json:"start"`
+	End        time.Time                   `json:"end"`
+	Total      int                         `json:"total"`
+	ByType     map[EventType]int           `json:"by_type"`
+	BySeverity map[types.AlertSeverity]int `json:"by_severity"`
+	ByRegion   map[string]int              `json:"by_region"`
+	TopSubnets []SubnetCount               `json:"top_subnets"`
+
+	// Final is true for the summary sent when the storm subsides and
+	// per-alert notifications resume.
+	Final bool `json:"final"`
+
+	// DashboardURL links to the alerts page, if configured.
+	DashboardURL string `json:"dashboard_url,omitempty"`
+}
+
+// SubnetCount is the number of events for one subnet.
+type SubnetCount struct {
+	SubnetID       string `json:"subnet_id"`
+	SubnetCIDR     string `json:"subnet_cidr,omitempty"`
+	SubscriberName string `json:"subscriber_name,omitempty"`
+	Count          int    `json:"count"`
+}
+
+// DigestConfig controls when the Digester switches to summary mode.
+type DigestConfig struct {
+	// Threshold is the number of events within Window that triggers digest
+	// mode. Zero disables digesting.
+	Threshold int
+
+	// Window is the sliding window the event rate is measured over.
+	Window time.Duration
+
+	// SummaryInterval is how often summaries are sent while digesting.
+	SummaryInterval time.Duration
+
+	// TopSubnets is how many of the most affected subnets to include.
+	TopSubnets int
+
+	// DashboardURL is the UI base URL for the link back to alerts.
+	DashboardURL string
+}
+
+// DefaultDigestConfig returns sensible defaults.
+func DefaultDigestConfig() DigestConfig {
+	return DigestConfig{
+		Threshold:       30,
+		Window:          time.Minute,
+		SummaryInterval: 5 * time.Minute,
+		TopSubnets:      10,
+	}
+}
+
+// DigestConfigFromEnv overrides the defaults with ICMPMON_ALERT_DIGEST_*
+// variables and ICMPMON_DASHBOARD_URL.
+func DigestConfigFromEnv() (DigestConfig, error) {
+	cfg := DefaultDigestConfig()
+	cfg.DashboardURL = strings.TrimRight(os.Getenv("ICMPMON_DASHBOARD_URL"), "/")
+
+	if v := os.Getenv("ICMPMON_ALERT_DIGEST_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			return cfg, fmt.Errorf("invalid ICMPMON_ALERT_DIGEST_THRESHOLD: %q", v)
+		}
+		cfg.Threshold = n
+	}
+	for _, d := range []struct {
+		env string
+		dst *time.Duration
+	}{
+		{"ICMPMON_ALERT_DIGEST_WINDOW", &cfg.Window},
+		{"ICMPMON_ALERT_DIGEST_INTERVAL", &cfg.SummaryInterval},
+	} {
+		if v := os.Getenv(d.env); v != "" {
+			parsed, err := time.ParseDuration(v)
+			if err != nil || parsed <= 0 {
+				return cfg, fmt.Errorf("invalid %s: %q", d.env, v)
+			}
+			*d.dst = parsed
+		}
+	}
+	return cfg, nil
+}
+
+// Digester wraps a Notifier and coalesces events into periodic summaries
+// when the event rate exceeds a threshold. Below the threshold events pass
+// through unchanged; once the rate drops back, a final summary is sent and
+// per-alert notifications resume.
+type Digester struct {
+	next   Notifier
+	config DigestConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	mu        sync.Mutex
+	recent    []time.Time // event times within the window
+	digesting bool
+	pending   []Event
+	since     time.Time
+
+	stopCh chan struct{}
+	doneCh chan struct{}
+}
+
+// NewDigester wraps next with rate-based digesting.
+func NewDigester(next Notifier, config DigestConfig, logger *slog.Logger) *Digester {
+	return &Digester{
+		next:   next,
+		config: config,
+		logger: logger.With("component", "alert_digester"),
+		now:    time.Now,
+	}
+}
+
+// Name implements Notifier.
+func (d *Digester) Name() string { return "digest(" + d.next.Name() + ")" }
+
+// Notify implements Notifier.
+func (d *Digester) Notify(ctx context.Context, event Event) error {
+	if d.config.Threshold <= 0 {
+		return d.next.Notify(ctx, event)
+	}
+
+	d.mu.Lock()
+	now := d.now()
+	d.recent = append(d.pruneLocked(now), now)
+
+	if !d.digesting && len(d.recent) > d.config.Threshold {
+		d.digesting = true
+		d.since = now
+		d.logger.Warn("alert rate above threshold, switching to digest mode",
+			"events", len(d.recent),
+			"window", d.config.Window,
+			"threshold", d.config.Threshold,
+		)
+	}
+	if d.digesting {
+		d.pending = append(d.pending, event)
+		d.mu.Unlock()
+		return nil
+	}
+	d.mu.Unlock()
+
+	return d.next.Notify(ctx, event)
+}
+
+// Digesting reports whether events are currently being coalesced.
+func (d *Digester) Digesting() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.digesting
+}
+
+// Start runs the summary loop.
+func (d *Digester) Start(ctx context.Context) {
+	if d.config.Threshold <= 0 {
+		return
+	}
+	d.stopCh = make(chan struct{})
+	d.doneCh = make(chan struct{})
+	go d.run(ctx)
+}
+
+// Stop sends any pending summary and stops the loop.
+func (d *Digester) Stop() {
+	if d.stopCh == nil {
+		return
+	}
+	close(d.stopCh)
+	<-d.doneCh
+}
+
+func (d *Digester) run(ctx context.Context) {
+	defer close(d.doneCh)
+
+	// Check the rate more often than summaries go out so normal
+	// notifications resume promptly once a storm ends
+	summaryTicker := time.NewTicker(d.config.SummaryInterval)
+	rateTicker := time.NewTicker(d.config.Window)
+	defer summaryTicker.Stop()
+	defer rateTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.stopCh:
+			d.flush(context.Background(), true)
+			return
+		case <-rateTicker.C:
+			d.checkRecovery(ctx)
+		case <-summaryTicker.C:
+			d.flush(ctx, false)
+		}
+	}
+}
+
+// checkRecovery leaves digest mode once the rate is back under threshold.
+func (d *Digester) checkRecovery(ctx context.Context) {
+	d.mu.Lock()
+	d.recent = d.pruneLocked(d.now())
+	recovered := d.digesting && len(d.recent) <= d.config.Threshold
+	d.mu.Unlock()
+
+	if recovered {
+		d.flush(ctx, true)
+	}
+}
+
+// flush sends a summary of pending events. When final, digest mode ends.
+func (d *Digester) flush(ctx context.Context, final bool) {
+	d.mu.Lock()
+	events := d.pending
+	start := d.since
+	now := d.now()
+	d.pending = nil
+	d.since = now
+	if final {
+		d.digesting = false
+	}
+	d.mu.Unlock()
+
+	if len(events) == 0 {
+		if final {
+			d.logger.Info("alert rate back under threshold, resuming per-alert notifications")
+		}
+		return
+	}
+
+	summary := Summarize(events, start, now, d.config.TopSubnets)
+	summary.Final = final
+	summary.DashboardURL = d.dashboardLink()
+
+	err := d.next.Notify(ctx, Event{
+		Type:        EventDigest,
+		Description: summary.Headline(),
+		OccurredAt:  now,
+		Digest:      summary,
+	})
+	if err != nil {
+		d.logger.Error("sending alert digest failed", "events", len(events), "error", err)
+	} else {
+		d.logger.Info("sent alert digest", "events", len(events), "final", final)
+	}
+}
+
+func (d *Digester) dashboardLink() string {
+	if d.config.DashboardURL == "" {
+		return ""
+	}
+	return d.config.DashboardURL + "/alerts"
+}
+
+// pruneLocked drops event times older than the window. Caller holds mu.
+func (d *Digester) pruneLocked(now time.Time) []time.Time {
+	cutoff := now.Add(-d.config.Window)
+	i := 0
+	for i < len(d.recent) && d.recent[i].Before(cutoff) {
+		i++
+	}
+	return d.recent[i:]
+}
+
+// Summarize builds a digest summary from a set of events.
+func Summarize(events []Event, start, end time.Time, topN int) *DigestSummary {
+	s := &DigestSummary{
+		Start:      start,
+		End:        end,
+		Total:      len(events),
+		ByType:     make(map[EventType]int),
+		BySeverity: make(map[types.AlertSeverity]int),
+		ByRegion:   make(map[string]int),
+	}
+
+	subnets := make(map[string]*SubnetCount)
+	for _, e := range events {
+		s.ByType[e.Type]++
+		s.BySeverity[e.Alert.Severity]++
+
+		region := e.Alert.Region
+		if region == "" {
+			region = "unknown"
+		}
+		s.ByRegion[region]++
+
+		if e.Alert.SubnetID == "" {
+			continue
+		}
+		sc, ok := subnets[e.Alert.SubnetID]
+		if !ok {
+			sc = &SubnetCount{
+				SubnetID:       e.Alert.SubnetID,
+				SubnetCIDR:     e.Alert.SubnetCIDR,
+				SubscriberName: e.Alert.SubscriberName,
+			}
+			subnets[e.Alert.SubnetID] = sc
+		}
+		sc.Count++
+	}
+
+	for _, sc := range subnets {
+		s.TopSubnets = append(s.TopSubnets, *sc)
+	}
+	sort.Slice(s.TopSubnets, func(i, j int) bool {
+		if s.TopSubnets[i].Count != s.TopSubnets[j].Count {
+			return s.TopSubnets[i].Count > s.TopSubnets[j].Count
+		}
+		return s.TopSubnets[i].SubnetID < s.TopSubnets[j].SubnetID
+	})
+	if topN > 0 && len(s.TopSubnets) > topN {
+		s.TopSubnets = s.TopSubnets[:topN]
+	}
+	return s
+}
+
+// Headline is a one-line description of the summary.
+func (s *DigestSummary) Headline() string {
+	return fmt.Sprintf("%d alert events (%d critical, %d warning, %d info) across %d region(s)",
+		s.Total,
+		s.BySeverity[types.AlertSeverityCritical],
+		s.BySeverity[types.AlertSeverityWarning],
+		s.BySeverity[types.AlertSeverityInfo],
+		len(s.ByRegion),
+	)
+}
@@ -0,0 +1,203 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// recorder is a Notifier that keeps every event it receives.
+type recorder struct {
+	events []Event
+}
+
+func (r *recorder) Name() string { return "recorder" }
+
+func (r *recorder) Notify(_ context.Context, event Event) error {
+	r.events = append(r.events, event)
+	return nil
+}
+
+func newTestDigester(cfg DigestConfig) (*Digester, *recorder, *time.Time) {
+	rec := &recorder{}
+	d := NewDigester(rec, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	now := time.Date(2026, 1, 2, 3, 0, 0, 0, time.UTC)
+	d.now = func() time.Time { return now }
+	return d, rec, &now
+}
+
+func subnetEvent(sev types.AlertSeverity, region, subnetID string) Event {
+	e := testEvent(sev, "alert")
+	e.Alert.Region = region
+	e.Alert.SubnetID = subnetID
+	e.Alert.SubnetCIDR = subnetID + ".0/24"
+	return e
+}
+
+func TestDigester_Threshold(t *testing.T) {
+	tests := []struct {
+		name          string
+		events        int
+		wantPassed    int
+		wantDigesting bool
+	}{
+		{"under threshold", 3, 3, false},
+		{"at threshold", 5, 5, false},
+		{"over threshold", 8, 5, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, rec, _ := newTestDigester(DigestConfig{Threshold: 5, Window: time.Minute, TopSubnets: 3})
+			for i := 0; i < tt.events; i++ {
+				if err := d.Notify(context.Background(), testEvent(types.AlertSeverityWarning, "alert")); err != nil {
+					t.Fatalf("Notify: %v", err)
+				}
+			}
+			if len(rec.events) != tt.wantPassed {
+				t.Errorf("passed through %d events, want %d", len(rec.events), tt.wantPassed)
+			}
+			if d.Digesting() != tt.wantDigesting {
+				t.Errorf("Digesting() = %v, want %v", d.Digesting(), tt.wantDigesting)
+			}
+		})
+	}
+}
+
+func TestDigester_SummaryAndRecovery(t *testing.T) {
+	d, rec, now := newTestDigester(DigestConfig{
+		Threshold:    2,
+		Window:       time.Minute,
+		TopSubnets:   1,
+		DashboardURL: "https://mon.example.com",
+	})
+	ctx := context.Background()
+
+	events := []Event{
+		subnetEvent(types.AlertSeverityCritical, "us-east", "s1"),
+		subnetEvent(types.AlertSeverityCritical, "us-east", "s1"),
+		subnetEvent(types.AlertSeverityWarning, "us-west", "s2"),
+		subnetEvent(types.AlertSeverityCritical, "", "s1"),
+	}
+	for _, e := range events {
+		d.Notify(ctx, e)
+	}
+	if len(rec.events) != 2 {
+		t.Fatalf("passed through %d events before digesting, want 2", len(rec.events))
+	}
+
+	d.flush(ctx, false)
+	if len(rec.events) != 3 {
+		t.Fatalf("got %d events after flush, want 3", len(rec.events))
+	}
+	summary := rec.events[2].Digest
+	if rec.events[2].Type != EventDigest || summary == nil {
+		t.Fatalf("expected digest event, got %+v", rec.events[2])
+	}
+	if summary.Total != 2 || summary.Final {
+		t.Errorf("summary total=%d final=%v, want 2/false", summary.Total, summary.Final)
+	}
+	if summary.ByRegion["us-west"] != 1 || summary.ByRegion["unknown"] != 1 {
+		t.Errorf("by region = %v", summary.ByRegion)
+	}
+	if summary.DashboardURL != "https://mon.example.com/alerts" {
+		t.Errorf("dashboard url = %q", summary.DashboardURL)
+	}
+
+	// Still over threshold: stays in digest mode
+	d.Notify(ctx, subnetEvent(types.AlertSeverityInfo, "us-east", "s3"))
+	d.checkRecovery(ctx)
+	if !d.Digesting() {
+		t.Fatal("left digest mode while rate still high")
+	}
+
+	// Rate drops: a final summary goes out and events pass through again
+	*now = now.Add(2 * time.Minute)
+	d.checkRecovery(ctx)
+	if d.Digesting() {
+		t.Fatal("still digesting after rate dropped")
+	}
+	final := rec.events[len(rec.events)-1]
+	if final.Digest == nil || !final.Digest.Final || final.Digest.Total != 1 {
+		t.Errorf("expected final summary of 1 event, got %+v", final.Digest)
+	}
+
+	d.Notify(ctx, testEvent(types.AlertSeverityCritical, "after"))
+	if last := rec.events[len(rec.events)-1]; last.Type != EventAlertCreated {
+		t.Errorf("event after recovery not passed through: %s", last.Type)
+	}
+}
+
+func TestSummarize_TopSubnets(t *testing.T) {
+	events := []Event{
+		subnetEvent(types.AlertSeverityCritical, "r", "s1"),
+		subnetEvent(types.AlertSeverityCritical, "r", "s2"),
+		subnetEvent(types.AlertSeverityCritical, "r", "s2"),
+		subnetEvent(types.AlertSeverityCritical, "r", "s3"),
+		subnetEvent(types.AlertSeverityCritical, "r", "s3"),
+		subnetEvent(types.AlertSeverityCritical, "r", "s3"),
+		subnetEvent(types.AlertSeverityWarning, "r", ""),
+	}
+
+	tests := []struct {
+		topN int
+		want string
+	}{
+		{0, "s3,s2,s1"},
+		{2, "s3,s2"},
+		{1, "s3"},
+	}
+	for _, tt := range tests {
+		s := Summarize(events, time.Time{}, time.Time{}, tt.topN)
+		var ids []string
+		for _, sc := range s.TopSubnets {
+			ids = append(ids, sc.SubnetID)
+		}
+		if got := strings.Join(ids, ","); got != tt.want {
+			t.Errorf("topN=%d: got %q, want %q", tt.topN, got, tt.want)
+		}
+		if s.Total != 7 || s.BySeverity[types.AlertSeverityWarning] != 1 {
+			t.Errorf("topN=%d: total=%d warning=%d", tt.topN, s.Total, s.BySeverity[types.AlertSeverityWarning])
+		}
+	}
+}
+
+func TestEmailNotifier_StormSummary(t *testing.T) {
+	n, sent := newTestNotifier(t, EmailConfig{
+		Recipients: Recipients{
+			Default:    []string{"noc@example.com"},
+			BySeverity: map[types.AlertSeverity][]string{types.AlertSeverityCritical: {"oncall@example.com"}},
+		},
+		DigestInterval: time.Hour, // storm summaries bypass batching
+	})
+
+	summary := Summarize([]Event{
+		subnetEvent(types.AlertSeverityCritical, "us-east", "s1"),
+		subnetEvent(types.AlertSeverityWarning, "us-east", "s1"),
+	}, time.Now(), time.Now(), 5)
+	summary.Final = true
+	summary.DashboardURL = "https://mon.example.com/alerts"
+
+	if err := n.Notify(context.Background(), Event{Type: EventDigest, Digest: summary}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(*sent))
+	}
+	msg := (*sent)[0]
+	if got := strings.Join(msg.To, ","); got != "noc@example.com,oncall@example.com" {
+		t.Errorf("to = %q", got)
+	}
+	if !strings.Contains(msg.Subject, "subsiding") {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+	for _, want := range []string{"us-east: 2", "s1.0/24", "https://mon.example.com/alerts", "resume"} {
+		if !strings.Contains(msg.Text, want) {
+			t.Errorf("body missing %q:\n%s", want, msg.Text)
+		}
+	}
+}
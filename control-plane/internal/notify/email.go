@@ -0,0 +1,401 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/mail"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Recipients maps alert severity to email addresses. Severities without an
+// explicit list fall back to Default.
+type Recipients struct {
+	Default    []string
+	BySeverity map[types.AlertSeverity][]string
+}
+
+// For returns the recipients for a severity.
+func (r Recipients) For(sev types.AlertSeverity) []string {
+	if list := r.BySeverity[sev]; len(list) > 0 {
+		return list
+	}
+	return r.Default
+}
+
+// ForEvent returns the recipients for an event: the escalation step's, if
+// it names any, otherwise those for the alert's severity.
+func (r Recipients) ForEvent(event Event) []string {
+	if event.Escalation != nil && len(event.Escalation.Recipients) > 0 {
+		return event.Escalation.Recipients
+	}
+	return r.For(event.Alert.Severity)
+}
+
+// Empty returns true if no recipients are configured at all.
+func (r Recipients) Empty() bool {
+	if len(r.Default) > 0 {
+		return false
+	}
+	for _, list := range r.BySeverity {
+		if len(list) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// EmailConfig configures the SMTP alert notifier.
+type EmailConfig struct {
+	Mail       mail.Config
+	Recipients Recipients
+
+	// SubjectTemplate and BodyTemplate are text/template sources rendered
+	// with an Event. Empty uses the defaults.
+	SubjectTemplate string
+	BodyTemplate    string
+
+	// DigestInterval batches events into one email per interval per
+	// recipient list. Zero sends each event immediately.
+	DigestInterval time.Duration
+}
+
+// EmailConfigFromEnv loads alert email settings. Recipient lists are
+// comma-separated: ICMPMON_ALERT_EMAIL_TO is the default list and
+// ICMPMON_ALERT_EMAIL_TO_{CRITICAL,WARNING,INFO} override it per severity.
+func EmailConfigFromEnv() (EmailConfig, error) {
+	cfg := EmailConfig{
+		Mail: mail.ConfigFromEnv(),
+		Recipients: Recipients{
+			Default: splitList(os.Getenv("ICMPMON_ALERT_EMAIL_TO")),
+			BySeverity: map[types.AlertSeverity][]string{
+				types.AlertSeverityCritical: splitList(os.Getenv("ICMPMON_ALERT_EMAIL_TO_CRITICAL")),
+				types.AlertSeverityWarning:  splitList(os.Getenv("ICMPMON_ALERT_EMAIL_TO_WARNING")),
+				types.AlertSeverityInfo:     splitList(os.Getenv("ICMPMON_ALERT_EMAIL_TO_INFO")),
+			},
+		},
+		SubjectTemplate: os.Getenv("ICMPMON_ALERT_EMAIL_SUBJECT"),
+		BodyTemplate:    os.Getenv("ICMPMON_ALERT_EMAIL_BODY"),
+	}
+	if v := os.Getenv("ICMPMON_ALERT_EMAIL_DIGEST_INTERVAL"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, fmt.Errorf("invalid ICMPMON_ALERT_EMAIL_DIGEST_INTERVAL: %w", err)
+		}
+		cfg.DigestInterval = d
+	}
+	return cfg, nil
+}
+
+// Enabled returns true if SMTP and at least one recipient are configured.
+func (c EmailConfig) Enabled() bool {
+	return c.Mail.Enabled() && !c.Recipients.Empty()
+}
+
+const defaultSubjectTemplate = `[icmp-mon] {{upper (print .Alert.Severity)}}: {{.Alert.Title}}{{if eq .Type "alert_resolved"}} (resolved){{end}}`
+
+const defaultBodyTemplate = `{{.Description}}
+
+Alert:     {{.Alert.Title}}
+Severity:  {{.Alert.Severity}}{{if .PreviousSeverity}} (was {{.PreviousSeverity}}){{end}}
+Status:    {{.Alert.Status}}
+Type:      {{.Alert.AlertType}}
+Target:    {{.Alert.TargetIP}}
+{{- if .Alert.SubscriberName}}
+Subscriber: {{.Alert.SubscriberName}}{{end}}
+{{- if .Alert.City}}
+Location:  {{.Alert.City}}{{if .Alert.Region}}, {{.Alert.Region}}{{end}}{{end}}
+{{- if .Alert.PopName}}
+POP:       {{.Alert.PopName}}{{end}}
+Detected:  {{.Alert.DetectedAt.UTC.Format "2006-01-02 15:04:05 MST"}}
+{{- if .Escalation}}
+Escalation: step {{.Escalation.Step}} of {{.Escalation.Steps}} ({{.Escalation.PolicyName}}), unacknowledged{{end}}
+{{- if .Alert.Message}}
+
+{{.Alert.Message}}{{end}}
+
+Alert ID: {{.Alert.ID}}
+`
+
+const digestSubjectTemplate = `[icmp-mon] Alert digest: {{len .Events}} event(s)`
+
+const digestBodyTemplate = `{{len .Events}} alert event(s) between {{.Start.UTC.Format "15:04"}} and {{.End.UTC.Format "15:04 MST"}}.
+{{range .Events}}
+[{{.Alert.Severity}}] {{.Type}} - {{.Alert.Title}}
+{{- if .Alert.SubscriberName}} ({{.Alert.SubscriberName}}){{end}}
+{{- end}}
+`
+
+const stormSubjectTemplate = `[icmp-mon] {{if .Final}}Alert storm subsiding{{else}}Alert storm in progress{{end}}: {{.Total}} events`
+
+const stormBodyTemplate = `{{.Headline}}
+{{if .Final}}
+The alert rate is back under threshold; individual notifications resume now.
+{{else}}
+Individual notifications are paused while the alert rate is high.
+{{end}}
+Period: {{.Start.UTC.Format "15:04"}} - {{.End.UTC.Format "15:04 MST"}}
+
+By severity:
+{{- range $sev, $n := .BySeverity}}
+  {{$sev}}: {{$n}}
+{{- end}}
+
+By region:
+{{- range $region, $n := .ByRegion}}
+  {{$region}}: {{$n}}
+{{- end}}
+{{if .TopSubnets}}
+Top affected subnets:
+{{- range .TopSubnets}}
+  {{.Count}}  {{if .SubnetCIDR}}{{.SubnetCIDR}}{{else}}{{.SubnetID}}{{end}}{{if .SubscriberName}} ({{.SubscriberName}}){{end}}
+{{- end}}
+{{end}}
+{{- if .DashboardURL}}
+Dashboard: {{.DashboardURL}}
+{{end}}`
+
+var templateFuncs = template.FuncMap{"upper": strings.ToUpper}
+
+// digestData is the template data for digest emails.
+type digestData struct {
+	Events []Event
+	Start  time.Time
+	End    time.Time
+}
+
+// EmailNotifier sends alert events by email, either immediately or as a
+// periodic digest.
+type EmailNotifier struct {
+	config  EmailConfig
+	subject *template.Template
+	body    *template.Template
+	digestS *template.Template
+	digestB *template.Template
+	stormS  *template.Template
+	stormB  *template.Template
+	logger  *slog.Logger
+
+	// send is swapped in tests
+	send func(ctx context.Context, cfg mail.Config, msg mail.Message) error
+
+	mu          sync.Mutex
+	pending     []Event
+	digestStart time.Time
+	stopCh      chan struct{}
+	doneCh      chan struct{}
+}
+
+// NewEmailNotifier creates an SMTP alert notifier.
+func NewEmailNotifier(cfg EmailConfig, logger *slog.Logger) (*EmailNotifier, error) {
+	subjectSrc := cfg.SubjectTemplate
+	if subjectSrc == "" {
+		subjectSrc = defaultSubjectTemplate
+	}
+	bodySrc := cfg.BodyTemplate
+	if bodySrc == "" {
+		bodySrc = defaultBodyTemplate
+	}
+
+	subject, err := template.New("subject").Funcs(templateFuncs).Parse(subjectSrc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing subject template: %w", err)
+	}
+	body, err := template.New("body").Funcs(templateFuncs).Parse(bodySrc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing body template: %w", err)
+	}
+
+	return &EmailNotifier{
+		config:  cfg,
+		subject: subject,
+		body:    body,
+		digestS: template.Must(template.New("digest_subject").Parse(digestSubjectTemplate)),
+		digestB: template.Must(template.New("digest_body").Parse(digestBodyTemplate)),
+		stormS:  template.Must(template.New("storm_subject").Parse(stormSubjectTemplate)),
+		stormB:  template.Must(template.New("storm_body").Parse(stormBodyTemplate)),
+		logger:  logger.With("component", "email_notifier"),
+		send:    mail.Send,
+	}, nil
+}
+
+// Name implements Notifier.
+func (n *EmailNotifier) Name() string { return "email" }
+
+// Notify implements Notifier.
+func (n *EmailNotifier) Notify(ctx context.Context, event Event) error {
+	if event.Type == EventDigest {
+		return n.sendStormSummary(ctx, event)
+	}
+	// An alert easing off isn't worth an email; resolution is
+	if event.Type == EventAlertDeescalated {
+		return nil
+	}
+	if len(n.config.Recipients.ForEvent(event)) == 0 {
+		return nil
+	}
+
+	if n.config.DigestInterval > 0 {
+		n.mu.Lock()
+		if len(n.pending) == 0 {
+			n.digestStart = time.Now()
+		}
+		n.pending = append(n.pending, event)
+		n.mu.Unlock()
+		return nil
+	}
+
+	return n.sendEvent(ctx, event)
+}
+
+// Start runs the digest flush loop. It is a no-op when digests are disabled.
+func (n *EmailNotifier) Start(ctx context.Context) {
+	if n.config.DigestInterval <= 0 {
+		return
+	}
+	n.stopCh = make(chan struct{})
+	n.doneCh = make(chan struct{})
+	go n.run(ctx)
+}
+
+// Stop flushes any pending digest and stops the flush loop.
+func (n *EmailNotifier) Stop() {
+	if n.stopCh == nil {
+		return
+	}
+	close(n.stopCh)
+	<-n.doneCh
+}
+
+func (n *EmailNotifier) run(ctx context.Context) {
+	defer close(n.doneCh)
+
+	ticker := time.NewTicker(n.config.DigestInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.stopCh:
+			n.flush(context.Background())
+			return
+		case <-ticker.C:
+			n.flush(ctx)
+		}
+	}
+}
+
+// flush sends one digest per distinct recipient list.
+func (n *EmailNotifier) flush(ctx context.Context) {
+	n.mu.Lock()
+	events := n.pending
+	start := n.digestStart
+	n.pending = nil
+	n.mu.Unlock()
+
+	if len(events) == 0 {
+		return
+	}
+
+	groups := make(map[string][]Event)
+	recipients := make(map[string][]string)
+	for _, e := range events {
+		to := n.config.Recipients.ForEvent(e)
+		key := strings.Join(to, ",")
+		groups[key] = append(groups[key], e)
+		recipients[key] = to
+	}
+
+	keys := make([]string, 0, len(groups))
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		data := digestData{Events: groups[key], Start: start, End: time.Now()}
+		subject, body, err := render(n.digestS, n.digestB, data)
+		if err != nil {
+			n.logger.Error("rendering digest failed", "error", err)
+			continue
+		}
+		msg := mail.Message{To: recipients[key], Subject: subject, Text: body}
+		if err := n.send(ctx, n.config.Mail, msg); err != nil {
+			n.logger.Error("sending alert digest failed", "recipients", key, "events", len(data.Events), "error", err)
+			continue
+		}
+		n.logger.Info("sent alert digest", "recipients", key, "events", len(data.Events))
+	}
+}
+
+func (n *EmailNotifier) sendEvent(ctx context.Context, event Event) error {
+	subject, body, err := render(n.subject, n.body, event)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, n.config.Mail, mail.Message{
+		To:      n.config.Recipients.ForEvent(event),
+		Subject: subject,
+		Text:    body,
+	})
+}
+
+// sendStormSummary sends a rate-triggered digest to everyone who would have
+// received at least one of the coalesced alerts.
+func (n *EmailNotifier) sendStormSummary(ctx context.Context, event Event) error {
+	if event.Digest == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var to []string
+	for sev := range event.Digest.BySeverity {
+		for _, addr := range n.config.Recipients.For(sev) {
+			if !seen[addr] {
+				seen[addr] = true
+				to = append(to, addr)
+			}
+		}
+	}
+	if len(to) == 0 {
+		return nil
+	}
+	sort.Strings(to)
+
+	subject, body, err := render(n.stormS, n.stormB, event.Digest)
+	if err != nil {
+		return err
+	}
+	return n.send(ctx, n.config.Mail, mail.Message{To: to, Subject: subject, Text: body})
+}
+
+func render(subjectTmpl, bodyTmpl *template.Template, data any) (string, string, error) {
+	var subject, body bytes.Buffer
+	if err := subjectTmpl.Execute(&subject, data); err != nil {
+		return "", "", fmt.Errorf("rendering subject: %w", err)
+	}
+	if err := bodyTmpl.Execute(&body, data); err != nil {
+		return "", "", fmt.Errorf("rendering body: %w", err)
+	}
+	// Headers can't contain newlines
+	return strings.Join(strings.Fields(subject.String()), " "), body.String(), nil
+}
+
+// splitList parses a comma-separated list, dropping empty entries.
+func splitList(s string) []string {
+	var out []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			out = append(out, part)
+		}
+	}
+	return out
+}
@@ -0,0 +1,171 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/mail"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func testEvent(sev types.AlertSeverity, title string) Event {
+	return Event{
+		Type: EventAlertCreated,
+		Alert: types.Alert{
+			ID:         "a-1",
+			Title:      title,
+			Severity:   sev,
+			Status:     types.AlertStatusActive,
+			TargetIP:   "192.0.2.1",
+			DetectedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		},
+	}
+}
+
+func newTestNotifier(t *testing.T, cfg EmailConfig) (*EmailNotifier, *[]mail.Message) {
+	t.Helper()
+	n, err := NewEmailNotifier(cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewEmailNotifier: %v", err)
+	}
+	var sent []mail.Message
+	n.send = func(_ context.Context, _ mail.Config, msg mail.Message) error {
+		sent = append(sent, msg)
+		return nil
+	}
+	return n, &sent
+}
+
+func TestRecipients_For(t *testing.T) {
+	r := Recipients{
+		Default:    []string{"noc@example.com"},
+		BySeverity: map[types.AlertSeverity][]string{types.AlertSeverityCritical: {"oncall@example.com"}},
+	}
+
+	tests := []struct {
+		severity types.AlertSeverity
+		want     string
+	}{
+		{types.AlertSeverityCritical, "oncall@example.com"},
+		{types.AlertSeverityWarning, "noc@example.com"},
+		{types.AlertSeverityInfo, "noc@example.com"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.severity), func(t *testing.T) {
+			if got := strings.Join(r.For(tt.severity), ","); got != tt.want {
+				t.Errorf("For(%s) = %q, want %q", tt.severity, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEmailNotifier_Immediate(t *testing.T) {
+	n, sent := newTestNotifier(t, EmailConfig{
+		Recipients: Recipients{Default: []string{"noc@example.com"}},
+	})
+
+	if err := n.Notify(context.Background(), testEvent(types.AlertSeverityCritical, "192.0.2.1 unreachable")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(*sent) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(*sent))
+	}
+	msg := (*sent)[0]
+	if msg.Subject != "[icmp-mon] CRITICAL: 192.0.2.1 unreachable" {
+		t.Errorf("subject = %q", msg.Subject)
+	}
+	if !strings.Contains(msg.Text, "Target:    192.0.2.1") {
+		t.Errorf("body missing target:\n%s", msg.Text)
+	}
+}
+
+func TestEmailNotifier_DigestGroupsByRecipients(t *testing.T) {
+	n, sent := newTestNotifier(t, EmailConfig{
+		Recipients: Recipients{
+			Default:    []string{"noc@example.com"},
+			BySeverity: map[types.AlertSeverity][]string{types.AlertSeverityCritical: {"oncall@example.com"}},
+		},
+		DigestInterval: time.Hour,
+	})
+
+	ctx := context.Background()
+	n.Notify(ctx, testEvent(types.AlertSeverityCritical, "a"))
+	n.Notify(ctx, testEvent(types.AlertSeverityCritical, "b"))
+	n.Notify(ctx, testEvent(types.AlertSeverityWarning, "c"))
+	if len(*sent) != 0 {
+		t.Fatalf("digest mode sent %d messages before flush", len(*sent))
+	}
+
+	n.flush(ctx)
+	if len(*sent) != 2 {
+		t.Fatalf("sent %d digests, want 2", len(*sent))
+	}
+	for _, msg := range *sent {
+		switch msg.To[0] {
+		case "oncall@example.com":
+			if !strings.Contains(msg.Subject, "2 event(s)") {
+				t.Errorf("oncall digest subject = %q", msg.Subject)
+			}
+		case "noc@example.com":
+			if !strings.Contains(msg.Subject, "1 event(s)") {
+				t.Errorf("noc digest subject = %q", msg.Subject)
+			}
+		default:
+			t.Errorf("unexpected recipient %v", msg.To)
+		}
+	}
+}
+
+func TestEmailNotifier_EscalationRecipients(t *testing.T) {
+	tests := []struct {
+		name       string
+		escalation *Escalation
+		wantTo     string
+		wantBody   string
+	}{
+		{
+			name:       "step recipients replace severity recipients",
+			escalation: &Escalation{PolicyName: "noc ladder", Step: 2, Steps: 3, Recipients: []string{"manager@example.com"}},
+			wantTo:     "manager@example.com",
+			wantBody:   "Escalation: step 2 of 3 (noc ladder), unacknowledged",
+		},
+		{
+			name:       "step without recipients uses severity recipients",
+			escalation: &Escalation{PolicyName: "noc ladder", Step: 1, Steps: 3},
+			wantTo:     "oncall@example.com",
+			wantBody:   "Escalation: step 1 of 3",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n, sent := newTestNotifier(t, EmailConfig{
+				Recipients: Recipients{
+					Default:    []string{"noc@example.com"},
+					BySeverity: map[types.AlertSeverity][]string{types.AlertSeverityCritical: {"oncall@example.com"}},
+				},
+			})
+
+			event := testEvent(types.AlertSeverityCritical, "192.0.2.1 unreachable")
+			event.Type = EventAlertEscalated
+			event.Escalation = tt.escalation
+			if err := n.Notify(context.Background(), event); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			if len(*sent) != 1 {
+				t.Fatalf("sent %d messages, want 1", len(*sent))
+			}
+			msg := (*sent)[0]
+			if got := strings.Join(msg.To, ","); got != tt.wantTo {
+				t.Errorf("To = %q, want %q", got, tt.wantTo)
+			}
+			if !strings.Contains(msg.Text, tt.wantBody) {
+				t.Errorf("body missing %q:\n%s", tt.wantBody, msg.Text)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+// Package notify delivers alert lifecycle events to external destinations.
+//
+// The alert worker emits an Event whenever an alert is created, escalated,
+// de-escalated or resolved. Each destination implements Notifier; a Fanout sends every event
+// to all configured notifiers so one failing destination doesn't block the
+// others.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// EventType identifies what happened to an alert.
+type EventType string
+
+const (
+	EventAlertCreated     EventType = "alert_created"
+	EventAlertEscalated   EventType = "alert_escalated"
+	EventAlertDeescalated EventType = "alert_deescalated"
+	EventAlertResolved    EventType = "alert_resolved"
+)
+
+// Event is a single alert lifecycle change.
+type Event struct {
+	Type  EventType   `json:"type"`
+	Alert types.Alert `json:"alert"`
+
+	// PreviousSeverity is set for escalations and de-escalations.
+	PreviousSeverity types.AlertSeverity `json:"previous_severity,omitempty"`
+
+	// Description is a human-readable summary of the change.
+	Description string `json:"description,omitempty"`
+
+	OccurredAt time.Time `json:"occurred_at"`
+
+	// Digest is set for EventDigest, in which case Alert is empty.
+	Digest *DigestSummary `json:"digest,omitempty"`
+
+	// Escalation is set for escalations fired by an escalation policy.
+	Escalation *Escalation `json:"escalation,omitempty"`
+}
+
+// Escalation identifies the escalation policy step behind an event.
+type Escalation struct {
+	PolicyID   string `json:"policy_id"`
+	PolicyName string `json:"policy_name"`
+	Step       int    `json:"step"` // 1-based
+	Steps      int    `json:"steps"`
+
+	// Recipients replace the usual recipients for the alert's severity
+	// when set.
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// Notifier delivers alert events to a destination.
+type Notifier interface {
+	// Name identifies the notifier in logs.
+	Name() string
+
+	// Notify delivers an event. Implementations may buffer events and
+	// deliver them later (e.g. digests).
+	Notify(ctx context.Context, event Event) error
+}
+
+// Fanout sends each event to every registered notifier.
+type Fanout struct {
+	mu        sync.RWMutex
+	notifiers []Notifier
+}
+
+// NewFanout creates a fanout over the given notifiers.
+func NewFanout(notifiers ...Notifier) *Fanout {
+	return &Fanout{notifiers: notifiers}
+}
+
+// Add registers another notifier.
+func (f *Fanout) Add(n Notifier) {
+	f.mu.Lock()
+	f.notifiers = append(f.notifiers, n)
+	f.mu.Unlock()
+}
+
+// Len returns the number of registered notifiers.
+func (f *Fanout) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return len(f.notifiers)
+}
+
+// Name implements Notifier.
+func (f *Fanout) Name() string { return "fanout" }
+
+// Notify implements Notifier. Every notifier is attempted; failures are
+// returned joined, each prefixed with the notifier's name.
+func (f *Fanout) Notify(ctx context.Context, event Event) error {
+	f.mu.RLock()
+	notifiers := f.notifiers
+	f.mu.RUnlock()
+
+	var errs []error
+	for _, n := range notifiers {
+		if err := n.Notify(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", n.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
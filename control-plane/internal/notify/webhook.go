@@ -0,0 +1,317 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Headers sent with every webhook delivery.
+const (
+	// WebhookSignatureHeader carries "sha256=" and the hex HMAC-SHA256 of
+	// the timestamp header, a dot and the body, keyed by the webhook secret.
+	// It is omitted when no secret is set.
+	WebhookSignatureHeader = "X-Icmpmon-Signature"
+
+	// WebhookTimestampHeader is the Unix time the attempt was sent, so
+	// receivers can reject replays of old deliveries.
+	WebhookTimestampHeader = "X-Icmpmon-Timestamp"
+
+	// WebhookEventHeader is the event type, also in the body.
+	WebhookEventHeader = "X-Icmpmon-Event"
+)
+
+// webhookResponseLimit bounds how much of a receiver's response is read
+// before the connection is reused.
+const webhookResponseLimit = 64 << 10
+
+var errWebhookQueueFull = errors.New("webhook queue full")
+
+// WebhookStore supplies the webhook URLs and secret from alert_config and
+// records delivery outcomes.
+type WebhookStore interface {
+	GetAlertConfigString(ctx context.Context, key, defaultVal string) (string, error)
+
+	// RecordWebhookDelivery counts a delivery to url, failed if deliveryErr
+	// is set.
+	RecordWebhookDelivery(ctx context.Context, url string, deliveryErr error) error
+}
+
+// WebhookConfig controls webhook delivery.
+type WebhookConfig struct {
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+
+	// MaxAttempts is how many times a delivery is tried before it counts as
+	// failed. Network errors, 429s and 5xx responses are retried; other
+	// responses are final.
+	MaxAttempts int
+
+	// RetryBase is the wait after the first failed attempt, doubling after
+	// each further one up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+
+	// QueueSize bounds deliveries waiting for a worker; events arriving when
+	// it is full are dropped and counted as failed.
+	QueueSize int
+
+	// Workers is how many deliveries run at once, so one slow receiver
+	// doesn't hold up the others.
+	Workers int
+}
+
+// DefaultWebhookConfig returns sensible defaults.
+func DefaultWebhookConfig() WebhookConfig {
+	return WebhookConfig{
+		Timeout:     10 * time.Second,
+		MaxAttempts: 5, // about 15s of backoff before giving up
+		RetryBase:   time.Second,
+		RetryMax:    30 * time.Second,
+		QueueSize:   1000,
+		Workers:     4,
+	}
+}
+
+// webhookDelivery is one event bound for one URL.
+type webhookDelivery struct {
+	url    string
+	secret string
+	event  EventType
+	body   []byte
+}
+
+// WebhookNotifier POSTs each alert event as JSON to every URL in the
+// webhook_urls alert_config key. Settings are read per event, so URLs and
+// the secret can change without a restart. Deliveries run in the
+// background with retries, so a slow receiver never blocks the alert
+// worker.
+type WebhookNotifier struct {
+	store  WebhookStore
+	config WebhookConfig
+	client *http.Client
+	logger *slog.Logger
+	queue  chan webhookDelivery
+	now    func() time.Time
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewWebhookNotifier creates a webhook notifier. Call Start to begin
+// delivering.
+func NewWebhookNotifier(store WebhookStore, cfg WebhookConfig, logger *slog.Logger) *WebhookNotifier {
+	return &WebhookNotifier{
+		store:  store,
+		config: cfg,
+		client: &http.Client{},
+		logger: logger.With("component", "webhook_notifier"),
+		queue:  make(chan webhookDelivery, cfg.QueueSize),
+		now:    time.Now,
+	}
+}
+
+// Name implements Notifier.
+func (n *WebhookNotifier) Name() string { return "webhook" }
+
+// Notify implements Notifier. It queues the event for every configured URL
+// and returns; delivery failures are logged and recorded rather than
+// returned.
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	raw, err := n.store.GetAlertConfigString(ctx, types.AlertConfigWebhookURLs, "")
+	if err != nil {
+		return fmt.Errorf("reading webhook urls: %w", err)
+	}
+	urls := splitList(raw)
+	if len(urls) == 0 {
+		return nil
+	}
+	secret, err := n.store.GetAlertConfigString(ctx, types.AlertConfigWebhookSecret, "")
+	if err != nil {
+		return fmt.Errorf("reading webhook secret: %w", err)
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encoding webhook payload: %w", err)
+	}
+
+	dropped := 0
+	for _, u := range urls {
+		select {
+		case n.queue <- webhookDelivery{url: u, secret: secret, event: event.Type, body: body}:
+		default:
+			dropped++
+			n.record(ctx, u, errWebhookQueueFull)
+		}
+	}
+	if dropped > 0 {
+		return fmt.Errorf("%w: dropped %d deliveries", errWebhookQueueFull, dropped)
+	}
+	return nil
+}
+
+// Start runs the delivery workers until Stop or ctx is done.
+func (n *WebhookNotifier) Start(ctx context.Context) {
+	ctx, n.cancel = context.WithCancel(ctx)
+	for range n.config.Workers {
+		n.wg.Add(1)
+		go n.run(ctx)
+	}
+}
+
+// Stop stops the workers, abandoning deliveries still queued or waiting
+// to retry.
+func (n *WebhookNotifier) Stop() {
+	if n.cancel == nil {
+		return
+	}
+	n.cancel()
+	n.wg.Wait()
+	if queued := len(n.queue); queued > 0 {
+		n.logger.Warn("webhook deliveries abandoned at shutdown", "queued", queued)
+	}
+}
+
+func (n *WebhookNotifier) run(ctx context.Context) {
+	defer n.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-n.queue:
+			n.deliver(ctx, d)
+		}
+	}
+}
+
+// deliver sends d, retrying with backoff, and records the outcome.
+func (n *WebhookNotifier) deliver(ctx context.Context, d webhookDelivery) {
+	var err error
+	attempt := 1
+	for ; ; attempt++ {
+		var retry bool
+		retry, err = n.post(ctx, d)
+		if err == nil || !retry || attempt >= n.config.MaxAttempts {
+			break
+		}
+		if !sleepCtx(ctx, n.backoff(attempt)) {
+			err = fmt.Errorf("abandoned after %d attempts: %w", attempt, err)
+			break
+		}
+	}
+
+	if err != nil {
+		n.logger.Warn("webhook delivery failed",
+			"host", webhookHost(d.url),
+			"event", d.event,
+			"attempts", attempt,
+			"error", err,
+		)
+	} else {
+		n.logger.Debug("webhook delivered", "host", webhookHost(d.url), "event", d.event, "attempts", attempt)
+	}
+	n.record(context.WithoutCancel(ctx), d.url, err)
+}
+
+// post makes one attempt, reporting whether a failure is worth retrying.
+func (n *WebhookNotifier) post(ctx context.Context, d webhookDelivery) (retry bool, err error) {
+	ctx, cancel := context.WithTimeout(ctx, n.config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url, bytes.NewReader(d.body))
+	if err != nil {
+		return false, withoutURL(err)
+	}
+	timestamp := strconv.FormatInt(n.now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(WebhookEventHeader, string(d.event))
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	if d.secret != "" {
+		req.Header.Set(WebhookSignatureHeader, SignWebhook(d.secret, timestamp, d.body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return true, withoutURL(err)
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, webhookResponseLimit))
+	resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("receiver returned %s", resp.Status)
+}
+
+// backoff is the wait after the given failed attempt.
+func (n *WebhookNotifier) backoff(attempt int) time.Duration {
+	d := n.config.RetryBase << (attempt - 1)
+	if d <= 0 || d > n.config.RetryMax {
+		return n.config.RetryMax
+	}
+	return d
+}
+
+// sleepCtx waits for d, returning false if ctx is done first.
+func sleepCtx(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func (n *WebhookNotifier) record(ctx context.Context, u string, deliveryErr error) {
+	ctx, cancel := context.WithTimeout(ctx, n.config.Timeout)
+	defer cancel()
+	if err := n.store.RecordWebhookDelivery(ctx, u, deliveryErr); err != nil {
+		n.logger.Error("recording webhook delivery failed", "host", webhookHost(u), "error", err)
+	}
+}
+
+// SignWebhook returns the WebhookSignatureHeader value for a delivery:
+// "sha256=" and the hex HMAC-SHA256 of timestamp + "." + body.
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// withoutURL drops the URL from an HTTP client error. Webhook URLs often
+// embed credentials, and the errors end up in logs and the fleet overview.
+func withoutURL(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+	}
+	return err
+}
+
+// webhookHost returns the host of a webhook URL, safe to log.
+func webhookHost(u string) string {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "invalid"
+	}
+	return parsed.Host
+}
@@ -0,0 +1,137 @@
+package notify
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// fakeWebhookStore serves alert_config strings and reports each recorded
+// delivery on done.
+type fakeWebhookStore struct {
+	config map[string]string
+	done   chan error
+}
+
+func (s *fakeWebhookStore) GetAlertConfigString(_ context.Context, key, defaultVal string) (string, error) {
+	if v, ok := s.config[key]; ok {
+		return v, nil
+	}
+	return defaultVal, nil
+}
+
+func (s *fakeWebhookStore) RecordWebhookDelivery(_ context.Context, _ string, deliveryErr error) error {
+	s.done <- deliveryErr
+	return nil
+}
+
+func TestWebhookNotifier_Delivery(t *testing.T) {
+	tests := []struct {
+		name         string
+		statuses     []int // response per attempt; the last repeats
+		secret       string
+		wantAttempts int32
+		wantFailed   bool
+	}{
+		{name: "delivered first time", statuses: []int{http.StatusOK}, secret: "s3cret", wantAttempts: 1},
+		{name: "unsigned without secret", statuses: []int{http.StatusNoContent}, wantAttempts: 1},
+		{name: "retried until accepted", statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}, wantAttempts: 3},
+		{name: "client error not retried", statuses: []int{http.StatusBadRequest}, wantAttempts: 1, wantFailed: true},
+		{name: "gives up after max attempts", statuses: []int{http.StatusInternalServerError}, wantAttempts: 3, wantFailed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1))
+				body, _ := io.ReadAll(r.Body)
+
+				wantSig := ""
+				if tt.secret != "" {
+					wantSig = SignWebhook(tt.secret, r.Header.Get(WebhookTimestampHeader), body)
+				}
+				if got := r.Header.Get(WebhookSignatureHeader); got != wantSig {
+					t.Errorf("signature = %q, want %q", got, wantSig)
+				}
+				if got := r.Header.Get(WebhookEventHeader); got != string(EventAlertCreated) {
+					t.Errorf("event header = %q, want %q", got, EventAlertCreated)
+				}
+
+				w.WriteHeader(tt.statuses[min(n, len(tt.statuses))-1])
+			}))
+			defer srv.Close()
+
+			store := &fakeWebhookStore{
+				config: map[string]string{
+					types.AlertConfigWebhookURLs:   srv.URL,
+					types.AlertConfigWebhookSecret: tt.secret,
+				},
+				done: make(chan error, 1),
+			}
+			cfg := DefaultWebhookConfig()
+			cfg.MaxAttempts = 3
+			cfg.RetryBase = time.Millisecond
+			n := NewWebhookNotifier(store, cfg, slog.New(slog.NewTextHandler(io.Discard, nil)))
+			n.Start(context.Background())
+			defer n.Stop()
+
+			if err := n.Notify(context.Background(), testEvent(types.AlertSeverityCritical, "down")); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+
+			select {
+			case err := <-store.done:
+				if (err != nil) != tt.wantFailed {
+					t.Errorf("delivery error = %v, want failed %v", err, tt.wantFailed)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("delivery not recorded")
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifier_NoURLs(t *testing.T) {
+	store := &fakeWebhookStore{config: map[string]string{types.AlertConfigWebhookURLs: " , "}, done: make(chan error, 1)}
+	n := NewWebhookNotifier(store, DefaultWebhookConfig(), slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := n.Notify(context.Background(), testEvent(types.AlertSeverityWarning, "slow")); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if queued := len(n.queue); queued != 0 {
+		t.Errorf("queued %d deliveries, want none", queued)
+	}
+}
+
+func TestSignWebhook_Deterministic(t *testing.T) {
+	body := []byte(`{"type":"alert_created"}`)
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		same      bool
+	}{
+		{name: "same inputs", secret: "k", timestamp: "1700000000", same: true},
+		{name: "other secret", secret: "other", timestamp: "1700000000"},
+		{name: "other timestamp", secret: "k", timestamp: "1700000001"},
+	}
+	want := SignWebhook("k", "1700000000", body)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SignWebhook(tt.secret, tt.timestamp, body); (got == want) != tt.same {
+				t.Errorf("SignWebhook = %q, same as reference = %v, want %v", got, got == want, tt.same)
+			}
+		})
+	}
+}
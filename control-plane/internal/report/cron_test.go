@@ -0,0 +1,58 @@
+package report
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseCron_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+	}{
+		{"too few fields", "0 6 1 *"},
+		{"minute out of range", "60 * * * *"},
+		{"bad step", "*/0 * * * *"},
+		{"reversed range", "0 10-5 * * *"},
+		{"not a number", "0 six * * *"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseCron(tt.expr); err == nil {
+				t.Errorf("ParseCron(%q) succeeded, want error", tt.expr)
+			}
+		})
+	}
+}
+
+func TestCronSchedule_Next(t *testing.T) {
+	from := time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC) // Sunday
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{"monthly on the 1st", "0 6 1 * *", time.Date(2026, 4, 1, 6, 0, 0, 0, time.UTC)},
+		{"monthly shorthand", "@monthly", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)},
+		{"every 15 minutes", "*/15 * * * *", time.Date(2026, 3, 15, 10, 45, 0, 0, time.UTC)},
+		{"weekdays at 9", "0 9 * * 1-5", time.Date(2026, 3, 16, 9, 0, 0, 0, time.UTC)},
+		{"sunday as 7", "0 12 * * 7", time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC)},
+		{"dom or dow", "0 0 20 * 3", time.Date(2026, 3, 18, 0, 0, 0, 0, time.UTC)},
+		{"strictly after", "30 10 15 3 *", time.Date(2027, 3, 15, 10, 30, 0, 0, time.UTC)},
+		{"never fires", "0 0 31 2 *", time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseCron(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseCron(%q): %v", tt.expr, err)
+			}
+			if got := s.Next(from); !got.Equal(tt.want) {
+				t.Errorf("Next: got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package report
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/mail"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Sender delivers a rendered report for a schedule.
+type Sender interface {
+	Send(ctx context.Context, schedule *types.ReportSchedule, doc *Document) error
+}
+
+// MultiSender dispatches to the sender for the schedule's delivery method.
+type MultiSender struct {
+	Email   Sender
+	Webhook Sender
+}
+
+// NewSender returns a MultiSender with SMTP email and HTTP webhook delivery.
+// Email delivery fails with a clear error if SMTP is not configured.
+func NewSender(mailCfg mail.Config) *MultiSender {
+	return &MultiSender{
+		Email:   &EmailSender{config: mailCfg},
+		Webhook: &WebhookSender{client: &http.Client{Timeout: config.DefaultHTTPTimeout}},
+	}
+}
+
+// Send implements Sender.
+func (m *MultiSender) Send(ctx context.Context, schedule *types.ReportSchedule, doc *Document) error {
+	switch schedule.DeliveryMethod {
+	case types.ReportDeliveryEmail:
+		return m.Email.Send(ctx, schedule, doc)
+	case types.ReportDeliveryWebhook:
+		return m.Webhook.Send(ctx, schedule, doc)
+	default:
+		return fmt.Errorf("unsupported delivery method: %s", schedule.DeliveryMethod)
+	}
+}
+
+// Destination describes where a schedule delivers, for the delivery log.
+func Destination(schedule *types.ReportSchedule) string {
+	if schedule.DeliveryMethod == types.ReportDeliveryWebhook {
+		return schedule.WebhookURL
+	}
+	return strings.Join(schedule.Recipients, ", ")
+}
+
+// =============================================================================
+// EMAIL
+// =============================================================================
+
+// EmailSender sends reports as an email with the document attached.
+type EmailSender struct {
+	config mail.Config
+}
+
+// Send implements Sender.
+func (e *EmailSender) Send(ctx context.Context, schedule *types.ReportSchedule, doc *Document) error {
+	return mail.Send(ctx, e.config, mail.Message{
+		To:      schedule.Recipients,
+		Subject: doc.Subject,
+		Text:    doc.Summary,
+		Attachments: []mail.Attachment{{
+			Filename:    doc.Filename,
+			ContentType: doc.ContentType,
+			Data:        doc.Body,
+		}},
+	})
+}
+
+// =============================================================================
+// WEBHOOK
+// =============================================================================
+
+// WebhookSender POSTs the report document to the schedule's webhook URL.
+// Metadata is sent in X-Report-* headers so the body can be the raw PDF/JSON.
+type WebhookSender struct {
+	client *http.Client
+}
+
+// Send implements Sender.
+func (s *WebhookSender) Send(ctx context.Context, schedule *types.ReportSchedule, doc *Document) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, schedule.WebhookURL, bytes.NewReader(doc.Body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", doc.ContentType)
+	req.Header.Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": doc.Filename}))
+	req.Header.Set("X-Report-Schedule-ID", schedule.ID)
+	req.Header.Set("X-Report-Name", schedule.Name)
+	req.Header.Set("X-Report-Window-Start", doc.WindowStart.Format(time.RFC3339))
+	req.Header.Set("X-Report-Window-End", doc.WindowEnd.Format(time.RFC3339))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// TargetData is the raw report for one target in a scheduled report.
+type TargetData struct {
+	TargetID string
+	TargetIP string
+	Rows     []store.TargetReport
+}
+
+// Document is a rendered report ready for delivery.
+type Document struct {
+	Subject     string
+	Summary     string
+	Filename    string
+	ContentType string
+	Body        []byte
+	WindowStart time.Time
+	WindowEnd   time.Time
+	TargetCount int
+}
+
+// jsonTargetReport is one target's entry in a JSON-format document.
+type jsonTargetReport struct {
+	TargetID  string                  `json:"target_id"`
+	TargetIP  string                  `json:"target_ip"`
+	Report    []store.TargetReport    `json:"report"`
+	Formatted []FormattedTargetReport `json:"formatted"`
+}
+
+// BuildDocument renders a scheduled report in the schedule's format.
+func BuildDocument(schedule *types.ReportSchedule, targets []TargetData, now time.Time) (*Document, error) {
+	now = now.UTC()
+	doc := &Document{
+		Subject:     fmt.Sprintf("%s - %d day report (%s)", schedule.Name, schedule.WindowDays, now.Format("2006-01-02")),
+		WindowStart: now.AddDate(0, 0, -schedule.WindowDays),
+		WindowEnd:   now,
+		TargetCount: len(targets),
+	}
+
+	opts := DefaultFormatOptions()
+	formatted := make([][]FormattedTargetReport, len(targets))
+	for i, t := range targets {
+		formatted[i] = FormatTargetReport(t.Rows, opts)
+	}
+
+	baseName := fmt.Sprintf("%s-%s", slug(schedule.Name), now.Format("20060102"))
+
+	switch schedule.Format {
+	case types.ReportFormatJSON:
+		entries := make([]jsonTargetReport, len(targets))
+		for i, t := range targets {
+			entries[i] = jsonTargetReport{TargetID: t.TargetID, TargetIP: t.TargetIP, Report: t.Rows, Formatted: formatted[i]}
+		}
+		body, err := json.Marshal(map[string]any{
+			"schedule_id":     schedule.ID,
+			"name":            schedule.Name,
+			"subscriber_name": schedule.SubscriberName,
+			"window_days":     schedule.WindowDays,
+			"window_start":    doc.WindowStart,
+			"window_end":      doc.WindowEnd,
+			"generated_at":    now,
+			"targets":         entries,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("encoding json report: %w", err)
+		}
+		doc.Body = body
+		doc.Filename = baseName + ".json"
+		doc.ContentType = "application/json"
+
+	default:
+		sections := make([]PDFSection, len(targets))
+		for i, t := range targets {
+			sections[i] = PDFSection{
+				Meta: PDFMeta{TargetID: t.TargetID, TargetIP: t.TargetIP, WindowDays: schedule.WindowDays, GeneratedAt: now},
+				Rows: formatted[i],
+			}
+		}
+		var buf bytes.Buffer
+		if err := RenderTargetReportsPDF(&buf, schedule.Name, sections); err != nil {
+			return nil, err
+		}
+		doc.Body = buf.Bytes()
+		doc.Filename = baseName + ".pdf"
+		doc.ContentType = "application/pdf"
+	}
+
+	doc.Summary = summarize(schedule, targets, formatted, doc)
+	return doc, nil
+}
+
+// summarize builds the plain-text body used for email delivery.
+func summarize(schedule *types.ReportSchedule, targets []TargetData, formatted [][]FormattedTargetReport, doc *Document) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s\n\n", schedule.Name)
+	if schedule.SubscriberName != "" {
+		fmt.Fprintf(&b, "Subscriber: %s\n", schedule.SubscriberName)
+	}
+	fmt.Fprintf(&b, "Window: %s to %s\n", doc.WindowStart.Format("2006-01-02"), doc.WindowEnd.Format("2006-01-02"))
+	fmt.Fprintf(&b, "Targets: %d\n\n", len(targets))
+
+	for i, t := range targets {
+		fmt.Fprintf(&b, "%s\n", t.TargetIP)
+		if len(formatted[i]) == 0 {
+			b.WriteString("  no data for this window\n")
+		}
+		for _, r := range formatted[i] {
+			fmt.Fprintf(&b, "  %-20s avg %s  p95 %s  loss %s  uptime %s\n",
+				r.AgentName, r.AvgLatency.Display, r.P95Latency.Display, r.PacketLoss.Display, r.Uptime.Display)
+		}
+	}
+
+	fmt.Fprintf(&b, "\nThe full report is attached (%s).\n", doc.Filename)
+	return b.String()
+}
+
+// slug lowercases s and replaces anything but letters and digits with '-'.
+func slug(s string) string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(s) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	out := strings.TrimSuffix(b.String(), "-")
+	if out == "" {
+		return "report"
+	}
+	return out
+}
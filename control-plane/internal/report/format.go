@@ -0,0 +1,118 @@
+// Package report turns raw report metrics into customer-facing output.
+//
+// The store returns unrounded floats straight from the database. This package
+// applies consistent rounding and unit annotations so every consumer (JSON
+// API, PDF export) presents the same numbers.
+package report
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// Unit labels appended to display values.
+const (
+	UnitMilliseconds = "ms"
+	UnitPercent      = "%"
+)
+
+// NotAvailable is displayed for metrics with no data.
+const NotAvailable = "N/A"
+
+// FormatOptions controls the precision of formatted values.
+type FormatOptions struct {
+	LatencyDecimals int `json:"latency_decimals"`
+	LossDecimals    int `json:"loss_decimals"`
+	UptimeDecimals  int `json:"uptime_decimals"`
+}
+
+// DefaultFormatOptions returns the standard customer-facing precision.
+func DefaultFormatOptions() FormatOptions {
+	return FormatOptions{
+		LatencyDecimals: config.ReportLatencyDecimals,
+		LossDecimals:    config.ReportLossDecimals,
+		UptimeDecimals:  config.ReportUptimeDecimals,
+	}
+}
+
+// FormattedValue is a rounded metric with its display string.
+type FormattedValue struct {
+	Value   *float64 `json:"value"`
+	Unit    string   `json:"unit"`
+	Display string   `json:"display"`
+}
+
+// FormattedTargetReport is the display-ready form of a store.TargetReport.
+type FormattedTargetReport struct {
+	AgentName   string         `json:"agent_name"`
+	AgentRegion string         `json:"agent_region"`
+	AvgLatency  FormattedValue `json:"avg_latency"`
+	P95Latency  FormattedValue `json:"p95_latency"`
+	P99Latency  FormattedValue `json:"p99_latency"`
+	Jitter      FormattedValue `json:"jitter"`
+	PacketLoss  FormattedValue `json:"packet_loss"`
+	Uptime      FormattedValue `json:"uptime"`
+	TotalProbes int64          `json:"total_probes"`
+}
+
+// FormatTargetReport rounds and annotates each row of a target report.
+func FormatTargetReport(rows []store.TargetReport, opts FormatOptions) []FormattedTargetReport {
+	opts = opts.Normalize()
+
+	formatted := make([]FormattedTargetReport, 0, len(rows))
+	for _, r := range rows {
+		formatted = append(formatted, FormattedTargetReport{
+			AgentName:   r.AgentName,
+			AgentRegion: r.AgentRegion,
+			AvgLatency:  formatValue(r.AvgLatencyMs, opts.LatencyDecimals, UnitMilliseconds),
+			P95Latency:  formatValue(r.P95LatencyMs, opts.LatencyDecimals, UnitMilliseconds),
+			P99Latency:  formatValue(r.P99LatencyMs, opts.LatencyDecimals, UnitMilliseconds),
+			Jitter:      formatValue(r.JitterMs, opts.LatencyDecimals, UnitMilliseconds),
+			PacketLoss:  formatValue(r.PacketLoss, opts.LossDecimals, UnitPercent),
+			Uptime:      formatValue(r.UptimePct, opts.UptimeDecimals, UnitPercent),
+			TotalProbes: r.TotalProbes,
+		})
+	}
+	return formatted
+}
+
+// Normalize clamps precision into [0, config.MaxReportDecimals], as
+// FormatTargetReport applies it.
+func (o FormatOptions) Normalize() FormatOptions {
+	o.LatencyDecimals = clampDecimals(o.LatencyDecimals)
+	o.LossDecimals = clampDecimals(o.LossDecimals)
+	o.UptimeDecimals = clampDecimals(o.UptimeDecimals)
+	return o
+}
+
+func clampDecimals(d int) int {
+	if d < 0 {
+		return 0
+	}
+	if d > config.MaxReportDecimals {
+		return config.MaxReportDecimals
+	}
+	return d
+}
+
+// formatValue rounds v to the given precision and renders it with its unit.
+func formatValue(v *float64, decimals int, unit string) FormattedValue {
+	if v == nil || math.IsNaN(*v) || math.IsInf(*v, 0) {
+		return FormattedValue{Unit: unit, Display: NotAvailable}
+	}
+
+	scale := math.Pow(10, float64(decimals))
+	rounded := math.Round(*v*scale) / scale
+
+	display := strconv.FormatFloat(rounded, 'f', decimals, 64)
+	if unit == UnitPercent {
+		display += unit
+	} else {
+		display += " " + unit
+	}
+
+	return FormattedValue{Value: &rounded, Unit: unit, Display: display}
+}
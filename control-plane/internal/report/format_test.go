@@ -0,0 +1,81 @@
+package report
+
+import (
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+func ptr(v float64) *float64 { return &v }
+
+func TestFormatValue_Rounding(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    *float64
+		decimals int
+		unit     string
+		want     string
+	}{
+		{"latency rounds to 2", ptr(12.3456), 2, UnitMilliseconds, "12.35 ms"},
+		{"loss rounds to 3", ptr(0.12345), 3, UnitPercent, "0.123%"},
+		{"pads trailing zeros", ptr(5), 2, UnitMilliseconds, "5.00 ms"},
+		{"zero decimals", ptr(99.6), 0, UnitPercent, "100%"},
+		{"nil is not available", nil, 2, UnitMilliseconds, NotAvailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := formatValue(tt.value, tt.decimals, tt.unit)
+			if got.Display != tt.want {
+				t.Errorf("display: got %q, want %q", got.Display, tt.want)
+			}
+			if tt.value == nil && got.Value != nil {
+				t.Errorf("value: got %v, want nil", *got.Value)
+			}
+		})
+	}
+}
+
+func TestFormatTargetReport_ClampsPrecision(t *testing.T) {
+	rows := []store.TargetReport{{
+		AgentName:    "agent-1",
+		AvgLatencyMs: ptr(1.23456789),
+		PacketLoss:   ptr(0.5),
+		TotalProbes:  10,
+	}}
+
+	got := FormatTargetReport(rows, FormatOptions{LatencyDecimals: 20, LossDecimals: -1, UptimeDecimals: 3})
+	if len(got) != 1 {
+		t.Fatalf("got %d rows, want 1", len(got))
+	}
+	if got[0].AvgLatency.Display != "1.234568 ms" {
+		t.Errorf("avg latency: got %q", got[0].AvgLatency.Display)
+	}
+	if got[0].PacketLoss.Display != "1%" {
+		t.Errorf("packet loss: got %q", got[0].PacketLoss.Display)
+	}
+	if got[0].Uptime.Display != NotAvailable {
+		t.Errorf("uptime: got %q", got[0].Uptime.Display)
+	}
+}
+
+func TestFormatOptions_Normalize(t *testing.T) {
+	tests := []struct {
+		name string
+		in   FormatOptions
+		want FormatOptions
+	}{
+		{name: "in range kept", in: FormatOptions{LatencyDecimals: 2, LossDecimals: 0, UptimeDecimals: 6}, want: FormatOptions{LatencyDecimals: 2, LossDecimals: 0, UptimeDecimals: 6}},
+		{name: "too many decimals capped", in: FormatOptions{LatencyDecimals: 12, LossDecimals: 7, UptimeDecimals: 3}, want: FormatOptions{LatencyDecimals: config.MaxReportDecimals, LossDecimals: config.MaxReportDecimals, UptimeDecimals: 3}},
+		{name: "negative raised to zero", in: FormatOptions{LatencyDecimals: -1, LossDecimals: 1, UptimeDecimals: -5}, want: FormatOptions{LatencyDecimals: 0, LossDecimals: 1, UptimeDecimals: 0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.in.Normalize(); got != tt.want {
+				t.Errorf("Normalize() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package report
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// PDFMeta describes the report subject for the PDF header.
+type PDFMeta struct {
+	TargetID    string
+	TargetIP    string
+	WindowDays  int
+	TimeZone    string // IANA zone the window's days are aligned to; empty = UTC
+	GeneratedAt time.Time
+}
+
+// pdfColumn is one column of the report table.
+type pdfColumn struct {
+	header string
+	width  float64
+	value  func(FormattedTargetReport) string
+}
+
+// Page layout in millimetres (A4 landscape leaves room for all columns).
+const (
+	pdfMargin     = 10.0
+	pdfRowHeight  = 7.0
+	pdfTitleSize  = 16.0
+	pdfHeaderSize = 9.0
+	pdfBodySize   = 9.0
+)
+
+var pdfColumns = []pdfColumn{
+	{"Agent", 45, func(r FormattedTargetReport) string { return r.AgentName }},
+	{"Region", 30, func(r FormattedTargetReport) string { return r.AgentRegion }},
+	{"Avg Latency", 27, func(r FormattedTargetReport) string { return r.AvgLatency.Display }},
+	{"P95 Latency", 27, func(r FormattedTargetReport) string { return r.P95Latency.Display }},
+	{"P99 Latency", 27, func(r FormattedTargetReport) string { return r.P99Latency.Display }},
+	{"Jitter", 25, func(r FormattedTargetReport) string { return r.Jitter.Display }},
+	{"Packet Loss", 27, func(r FormattedTargetReport) string { return r.PacketLoss.Display }},
+	{"Uptime", 27, func(r FormattedTargetReport) string { return r.Uptime.Display }},
+	{"Probes", 25, func(r FormattedTargetReport) string { return strconv.FormatInt(r.TotalProbes, 10) }},
+}
+
+// PDFSection is one target's table within a multi-target PDF.
+type PDFSection struct {
+	Meta PDFMeta
+	Rows []FormattedTargetReport
+}
+
+// RenderTargetReportPDF writes a single-table PDF of a formatted target report.
+func RenderTargetReportPDF(w io.Writer, meta PDFMeta, rows []FormattedTargetReport) error {
+	return RenderTargetReportsPDF(w, "Target Performance Report", []PDFSection{{Meta: meta, Rows: rows}})
+}
+
+// RenderTargetReportsPDF writes a PDF with one section per target, each
+// starting on a new page.
+func RenderTargetReportsPDF(w io.Writer, title string, sections []PDFSection) error {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetMargins(pdfMargin, pdfMargin, pdfMargin)
+	pdf.SetAutoPageBreak(true, pdfMargin)
+	pdf.SetTitle(title, false)
+
+	writeHeader := func() {
+		pdf.SetFont("Helvetica", "B", pdfHeaderSize)
+		pdf.SetFillColor(230, 230, 230)
+		for _, col := range pdfColumns {
+			pdf.CellFormat(col.width, pdfRowHeight, col.header, "1", 0, "C", true, 0, "")
+		}
+		pdf.Ln(-1)
+		pdf.SetFont("Helvetica", "", pdfBodySize)
+	}
+
+	// Repeat the column header when a section's table spills onto a new page
+	inTable := false
+	pdf.SetHeaderFunc(func() {
+		if inTable {
+			writeHeader()
+		}
+	})
+
+	if len(sections) == 0 {
+		pdf.AddPage()
+		pdf.SetFont("Helvetica", "B", pdfTitleSize)
+		pdf.CellFormat(0, pdfRowHeight*1.5, title, "", 1, "L", false, 0, "")
+		pdf.SetFont("Helvetica", "", pdfBodySize)
+		pdf.CellFormat(0, pdfRowHeight, "No targets in this report.", "", 1, "L", false, 0, "")
+	}
+
+	for _, sec := range sections {
+		inTable = false
+		pdf.AddPage()
+		meta := sec.Meta
+
+		pdf.SetFont("Helvetica", "B", pdfTitleSize)
+		pdf.CellFormat(0, pdfRowHeight*1.5, title, "", 1, "L", false, 0, "")
+
+		pdf.SetFont("Helvetica", "", pdfBodySize)
+		pdf.CellFormat(0, pdfRowHeight, fmt.Sprintf("Target: %s (%s)", meta.TargetIP, meta.TargetID), "", 1, "L", false, 0, "")
+		window := fmt.Sprintf("Window: last %d days", meta.WindowDays)
+		if meta.TimeZone != "" {
+			window += " (" + meta.TimeZone + ")"
+		}
+		pdf.CellFormat(0, pdfRowHeight, window, "", 1, "L", false, 0, "")
+		pdf.CellFormat(0, pdfRowHeight, "Generated: "+meta.GeneratedAt.UTC().Format(time.RFC1123), "", 1, "L", false, 0, "")
+		pdf.Ln(pdfRowHeight / 2)
+
+		writeHeader()
+		inTable = true
+
+		if len(sec.Rows) == 0 {
+			pdf.CellFormat(0, pdfRowHeight, "No data for this window.", "1", 1, "C", false, 0, "")
+		}
+		for _, r := range sec.Rows {
+			for i, col := range pdfColumns {
+				align := "R"
+				if i < 2 {
+					align = "L"
+				}
+				pdf.CellFormat(col.width, pdfRowHeight, col.value(r), "1", 0, align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+	}
+	inTable = false
+
+	if err := pdf.Output(w); err != nil {
+		return fmt.Errorf("rendering pdf: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package report
+
+import (
+	"fmt"
+	"time"
+)
+
+// LoadTimeZone validates an IANA time zone name for report day boundaries
+// (e.g. "America/New_York"). The name is passed to Postgres AT TIME ZONE, so
+// only IANA names are accepted: "Local" depends on the server and is rejected.
+// An empty name returns UTC.
+func LoadTimeZone(name string) (*time.Location, error) {
+	if name == "" {
+		return time.UTC, nil
+	}
+	if name == "Local" {
+		return nil, fmt.Errorf("invalid time zone %q: use an IANA name such as America/New_York", name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", name, err)
+	}
+	return loc, nil
+}
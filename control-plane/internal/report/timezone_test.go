@@ -0,0 +1,37 @@
+package report
+
+import "testing"
+
+func TestLoadTimeZone(t *testing.T) {
+	tests := []struct {
+		name    string
+		tz      string
+		want    string
+		wantErr bool
+	}{
+		{"empty is UTC", "", "UTC", false},
+		{"iana name", "America/New_York", "America/New_York", false},
+		{"explicit UTC", "UTC", "UTC", false},
+		{"server local rejected", "Local", "", true},
+		{"unknown zone", "Mars/Olympus_Mons", "", true},
+		{"offset string", "+05:00", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc, err := LoadTimeZone(tt.tz)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("LoadTimeZone(%q) succeeded, want error", tt.tz)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadTimeZone(%q): %v", tt.tz, err)
+			}
+			if loc.String() != tt.want {
+				t.Errorf("LoadTimeZone(%q) = %s, want %s", tt.tz, loc, tt.want)
+			}
+		})
+	}
+}
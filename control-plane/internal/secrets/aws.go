@@ -0,0 +1,399 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// AWSKeyStore stores SSH keys in AWS Secrets Manager.
+//
+// Each key is a single secret named <prefix><key_name>. Rotation calls
+// PutSecretValue, which moves the AWSCURRENT staging label to the new
+// version and AWSPREVIOUS to the old one, so the prior key stays readable
+// until Secrets Manager ages it out.
+//
+// Credentials are read from the standard AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN environment variables.
+type AWSKeyStore struct {
+	region   string
+	prefix   string
+	endpoint string
+	creds    awsCredentials
+	client   *http.Client
+	logger   *slog.Logger
+
+	mu       sync.RWMutex
+	keyCache map[string]*SSHKeyPair
+}
+
+// awsCredentials holds static AWS credentials used for request signing.
+type awsCredentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// AWS Secrets Manager staging labels.
+const (
+	AWSStageCurrent  = "AWSCURRENT"
+	AWSStagePrevious = "AWSPREVIOUS"
+)
+
+const awsSecretsService = "secretsmanager"
+
+// NewAWSKeyStore creates a key store backed by AWS Secrets Manager.
+// If endpoint is empty, the regional public endpoint is used.
+func NewAWSKeyStore(region, prefix, endpoint string, creds awsCredentials, logger *slog.Logger) (*AWSKeyStore, error) {
+	if region == "" {
+		return nil, fmt.Errorf("AWS region is required")
+	}
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("AWS credentials are required")
+	}
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://%s.%s.amazonaws.com", awsSecretsService, region)
+	}
+
+	ks := &AWSKeyStore{
+		region:   region,
+		prefix:   prefix,
+		endpoint: strings.TrimRight(endpoint, "/"),
+		creds:    creds,
+		client:   &http.Client{Timeout: config.DefaultHTTPTimeout},
+		logger:   logger,
+		keyCache: make(map[string]*SSHKeyPair),
+	}
+
+	logger.Info("initialized AWS Secrets Manager key store", "region", region, "prefix", prefix)
+	return ks, nil
+}
+
+// GetOrCreateProvisioningKey returns the control plane's SSH key pair,
+// creating one if it doesn't exist.
+func (ks *AWSKeyStore) GetOrCreateProvisioningKey(ctx context.Context) (*SSHKeyPair, error) {
+	ks.mu.RLock()
+	if cached, ok := ks.keyCache[DefaultKeyName]; ok {
+		ks.mu.RUnlock()
+		return cached, nil
+	}
+	ks.mu.RUnlock()
+
+	keyPair, err := ks.GetKeyVersion(ctx, DefaultKeyName, AWSStageCurrent)
+	if err != nil {
+		return nil, fmt.Errorf("checking for existing key: %w", err)
+	}
+
+	if keyPair == nil {
+		ks.logger.Info("creating new provisioning SSH key in AWS Secrets Manager", "name", DefaultKeyName)
+
+		keyPair, err = GenerateSSHKeyPair(DefaultKeyName)
+		if err != nil {
+			return nil, fmt.Errorf("generating key pair: %w", err)
+		}
+		if err := ks.createSecret(ctx, keyPair); err != nil {
+			return nil, fmt.Errorf("storing key in secrets manager: %w", err)
+		}
+
+		ks.logger.Info("created new provisioning SSH key",
+			"name", DefaultKeyName,
+			"fingerprint", keyPair.Fingerprint,
+			"version", keyPair.ID)
+	}
+
+	ks.mu.Lock()
+	ks.keyCache[DefaultKeyName] = keyPair
+	ks.mu.Unlock()
+
+	return keyPair, nil
+}
+
+// GetPrivateKey retrieves only the private key bytes for a named key.
+func (ks *AWSKeyStore) GetPrivateKey(ctx context.Context, name string) ([]byte, error) {
+	keyPair, err := ks.GetKeyVersion(ctx, name, AWSStageCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if keyPair == nil {
+		return nil, nil
+	}
+	return keyPair.PrivateKey, nil
+}
+
+// GetPublicKey retrieves the public key in OpenSSH format.
+func (ks *AWSKeyStore) GetPublicKey(ctx context.Context, name string) (string, error) {
+	ks.mu.RLock()
+	if cached, ok := ks.keyCache[name]; ok {
+		ks.mu.RUnlock()
+		return cached.PublicKey, nil
+	}
+	ks.mu.RUnlock()
+
+	keyPair, err := ks.GetKeyVersion(ctx, name, AWSStageCurrent)
+	if err != nil {
+		return "", err
+	}
+	if keyPair == nil {
+		return "", fmt.Errorf("key not found: %s", name)
+	}
+	return keyPair.PublicKey, nil
+}
+
+// GetKeyVersion retrieves a named key at a staging label (AWSCURRENT,
+// AWSPREVIOUS) or version ID. Returns nil if the secret or version doesn't exist.
+func (ks *AWSKeyStore) GetKeyVersion(ctx context.Context, name, version string) (*SSHKeyPair, error) {
+	req := map[string]string{"SecretId": ks.prefix + name}
+	if strings.HasPrefix(version, "AWS") {
+		req["VersionStage"] = version
+	} else if version != "" {
+		req["VersionId"] = version
+	}
+
+	var resp struct {
+		SecretString string `json:"SecretString"`
+		VersionId    string `json:"VersionId"`
+	}
+	err := ks.call(ctx, "GetSecretValue", req, &resp)
+	if isAWSNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	keyPair, err := unmarshalStoredKey([]byte(resp.SecretString))
+	if err != nil {
+		return nil, err
+	}
+	keyPair.ID = resp.VersionId
+	return keyPair, nil
+}
+
+// RotateKey writes a new version of the provisioning key. The previous
+// version keeps the AWSPREVIOUS label and can be read with GetKeyVersion.
+func (ks *AWSKeyStore) RotateKey(ctx context.Context) (*SSHKeyPair, error) {
+	newKey, err := GenerateSSHKeyPair(DefaultKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("generating new key: %w", err)
+	}
+	now := time.Now()
+	newKey.RotatedAt = &now
+
+	secret, err := marshalStoredKey(newKey)
+	if err != nil {
+		return nil, fmt.Errorf("encoding key: %w", err)
+	}
+
+	var resp struct {
+		VersionId string `json:"VersionId"`
+	}
+	err = ks.call(ctx, "PutSecretValue", map[string]string{
+		"SecretId":     ks.prefix + DefaultKeyName,
+		"SecretString": string(secret),
+	}, &resp)
+	if isAWSNotFound(err) {
+		// Nothing to rotate yet; create the secret instead
+		err = ks.createSecret(ctx, newKey)
+	} else if err == nil {
+		newKey.ID = resp.VersionId
+	}
+	if err != nil {
+		return nil, fmt.Errorf("storing new key version: %w", err)
+	}
+
+	ks.mu.Lock()
+	ks.keyCache[DefaultKeyName] = newKey
+	ks.mu.Unlock()
+
+	ks.logger.Info("rotated provisioning SSH key",
+		"fingerprint", newKey.Fingerprint,
+		"version", newKey.ID)
+
+	return newKey, nil
+}
+
+// Close releases any resources.
+func (ks *AWSKeyStore) Close() error {
+	ks.mu.Lock()
+	ks.keyCache = make(map[string]*SSHKeyPair)
+	ks.mu.Unlock()
+	ks.client.CloseIdleConnections()
+	return nil
+}
+
+// createSecret creates a new secret holding the key pair.
+func (ks *AWSKeyStore) createSecret(ctx context.Context, keyPair *SSHKeyPair) error {
+	secret, err := marshalStoredKey(keyPair)
+	if err != nil {
+		return fmt.Errorf("encoding key: %w", err)
+	}
+
+	var resp struct {
+		VersionId string `json:"VersionId"`
+	}
+	if err := ks.call(ctx, "CreateSecret", map[string]string{
+		"Name":         ks.prefix + keyPair.Name,
+		"Description":  "ICMP-Mon SSH key for agent enrollment",
+		"SecretString": string(secret),
+	}, &resp); err != nil {
+		return err
+	}
+	keyPair.ID = resp.VersionId
+	return nil
+}
+
+// awsAPIError is an error returned by the Secrets Manager JSON API.
+type awsAPIError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e *awsAPIError) Error() string {
+	return fmt.Sprintf("secrets manager returned %d: %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+// isAWSNotFound reports whether err is a ResourceNotFoundException.
+func isAWSNotFound(err error) bool {
+	apiErr, ok := err.(*awsAPIError)
+	return ok && strings.HasSuffix(apiErr.Type, "ResourceNotFoundException")
+}
+
+// call invokes a Secrets Manager action and decodes the response into out.
+func (ks *AWSKeyStore) call(ctx context.Context, action string, in, out any) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ks.endpoint+"/", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager."+action)
+	signAWSRequest(req, body, ks.creds, ks.region, awsSecretsService, time.Now().UTC())
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("secrets manager request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("reading response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Type    string `json:"__type"`
+			Message string `json:"message"`
+		}
+		_ = json.Unmarshal(respBody, &apiErr)
+		return &awsAPIError{StatusCode: resp.StatusCode, Type: apiErr.Type, Message: apiErr.Message}
+	}
+
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("decoding response: %w", err)
+		}
+	}
+	return nil
+}
+
+// signAWSRequest adds AWS Signature Version 4 headers to req.
+func signAWSRequest(req *http.Request, body []byte, creds awsCredentials, region, service string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	dateStamp := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", creds.SessionToken)
+	}
+
+	// Every header set so far is signed, plus host, which is implicit in
+	// req. Canonical headers are lowercase and sorted.
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[strings.ToLower(name)] = strings.Join(trimmed, ",")
+	}
+	headerNames := make([]string, 0, len(headers))
+	for name := range headers {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	var canonicalHeaders strings.Builder
+	for _, h := range headerNames {
+		canonicalHeaders.WriteString(h + ":" + headers[h] + "\n")
+	}
+	signedHeaders := strings.Join(headerNames, ";")
+
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{dateStamp, region, service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signingKey := hmacSHA256([]byte("AWS4"+creds.SecretAccessKey), dateStamp)
+	signingKey = hmacSHA256(signingKey, region)
+	signingKey = hmacSHA256(signingKey, service)
+	signingKey = hmacSHA256(signingKey, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(signingKey, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		creds.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery encodes query parameters in SigV4 canonical form.
+func canonicalQuery(q url.Values) string {
+	// url.Values.Encode sorts by key; SigV4 requires %20 rather than +
+	return strings.ReplaceAll(q.Encode(), "+", "%20")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,101 @@
+package secrets
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignAWSRequest_TestSuite checks the signer against vectors from AWS's
+// Signature Version 4 test suite, which all share these credentials, date,
+// region and service.
+func TestSignAWSRequest_TestSuite(t *testing.T) {
+	creds := awsCredentials{
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+	}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+	const credential = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, "
+
+	tests := []struct {
+		name    string
+		method  string
+		url     string
+		headers map[string]string
+		body    string
+		want    string
+	}{
+		{
+			name:   "get-vanilla",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/",
+			want:   "SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name:   "post-vanilla",
+			method: http.MethodPost,
+			url:    "https://example.amazonaws.com/",
+			want:   "SignedHeaders=host;x-amz-date, Signature=5da7c1a2acd57cee7505fc6676e4e544621c30862966e37dddb68e92efbe5d6b",
+		},
+		{
+			name:   "get-vanilla-query-order-key-case",
+			method: http.MethodGet,
+			url:    "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			want:   "SignedHeaders=host;x-amz-date, Signature=b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+		{
+			name:    "get-header-value-trim",
+			method:  http.MethodGet,
+			url:     "https://example.amazonaws.com/",
+			headers: map[string]string{"My-Header1": " value1", "My-Header2": ` "a   b   c"`},
+			want:    "SignedHeaders=host;my-header1;my-header2;x-amz-date, Signature=acc3ed3afb60bb290fc8d2dd0098b9911fcaa05412b367055dee359757a9c736",
+		},
+		{
+			name:    "post-x-www-form-urlencoded",
+			method:  http.MethodPost,
+			url:     "https://example.amazonaws.com/",
+			headers: map[string]string{"Content-Type": "application/x-www-form-urlencoded"},
+			body:    "Param1=value1",
+			want:    "SignedHeaders=content-type;host;x-amz-date, Signature=ff11897932ad3f4e8b18135d722051e5ac45fc38421b1da7b9d196a0fe09473a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+
+			signAWSRequest(req, []byte(tt.body), creds, "us-east-1", "service", now)
+
+			if got := req.Header.Get("X-Amz-Date"); got != "20150830T123600Z" {
+				t.Errorf("X-Amz-Date = %q", got)
+			}
+			if got := req.Header.Get("Authorization"); got != credential+tt.want {
+				t.Errorf("Authorization = %q\nwant %q", got, credential+tt.want)
+			}
+		})
+	}
+}
+
+func TestSignAWSRequest_SessionToken(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://secretsmanager.us-east-1.amazonaws.com/", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	creds := awsCredentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret", SessionToken: "token"}
+
+	signAWSRequest(req, nil, creds, "us-east-1", awsSecretsService, time.Now().UTC())
+
+	if got := req.Header.Get("X-Amz-Security-Token"); got != "token" {
+		t.Errorf("X-Amz-Security-Token = %q, want token", got)
+	}
+	if auth := req.Header.Get("Authorization"); !strings.Contains(auth, "SignedHeaders=host;x-amz-date;x-amz-security-token,") {
+		t.Errorf("session token not signed: %s", auth)
+	}
+}
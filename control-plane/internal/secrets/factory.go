@@ -8,7 +8,8 @@ import (
 
 // Config holds configuration for the secrets backend.
 type Config struct {
-	// Backend specifies which backend to use: "1password", "local", or "auto"
+	// Backend specifies which backend to use: "1password", "aws", "vault",
+	// "local", or "auto".
 	// "auto" (default) uses 1Password if configured, otherwise local
 	Backend string
 
@@ -21,6 +22,30 @@ type Config struct {
 
 	// Local storage directory (default: ~/.icmpmon/keys)
 	LocalKeyDir string
+
+	// AWS Secrets Manager configuration
+	// Set via environment: AWS_REGION (or AWS_DEFAULT_REGION),
+	// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN
+	AWSRegion          string
+	AWSAccessKeyID     string
+	AWSSecretAccessKey string
+	AWSSessionToken    string
+
+	// Prefix prepended to secret names (default: "icmpmon/")
+	AWSSecretPrefix string
+
+	// Endpoint override, e.g. for VPC endpoints or LocalStack
+	AWSEndpoint string
+
+	// HashiCorp Vault configuration
+	// Set via environment: VAULT_ADDR, VAULT_TOKEN, VAULT_NAMESPACE
+	VaultAddr      string
+	VaultToken     string
+	VaultNamespace string
+
+	// KV v2 mount (default: "secret") and path under it (default: "icmpmon")
+	VaultMount string
+	VaultPath  string
 }
 
 // ConfigFromEnv creates a Config from environment variables.
@@ -30,6 +55,19 @@ func ConfigFromEnv() Config {
 		OnePasswordToken: os.Getenv("OP_SERVICE_ACCOUNT_TOKEN"),
 		OnePasswordVault: getEnv("OP_VAULT", "icmp-mon keys"),
 		LocalKeyDir:      os.Getenv("ICMPMON_KEY_DIR"),
+
+		AWSRegion:          getEnv("AWS_REGION", os.Getenv("AWS_DEFAULT_REGION")),
+		AWSAccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		AWSSecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		AWSSessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		AWSSecretPrefix:    getEnv("ICMPMON_AWS_SECRET_PREFIX", "icmpmon/"),
+		AWSEndpoint:        os.Getenv("ICMPMON_AWS_SECRETS_ENDPOINT"),
+
+		VaultAddr:      os.Getenv("VAULT_ADDR"),
+		VaultToken:     os.Getenv("VAULT_TOKEN"),
+		VaultNamespace: os.Getenv("VAULT_NAMESPACE"),
+		VaultMount:     getEnv("ICMPMON_VAULT_MOUNT", "secret"),
+		VaultPath:      getEnv("ICMPMON_VAULT_PATH", "icmpmon"),
 	}
 	return cfg
 }
@@ -48,6 +86,19 @@ func NewKeyStore(cfg Config, logger *slog.Logger) (KeyStore, error) {
 		}
 		return NewOnePasswordCLIKeyStore(cfg.OnePasswordToken, cfg.OnePasswordVault, logger)
 
+	case "aws":
+		return NewAWSKeyStore(cfg.AWSRegion, cfg.AWSSecretPrefix, cfg.AWSEndpoint, awsCredentials{
+			AccessKeyID:     cfg.AWSAccessKeyID,
+			SecretAccessKey: cfg.AWSSecretAccessKey,
+			SessionToken:    cfg.AWSSessionToken,
+		}, logger)
+
+	case "vault":
+		if cfg.VaultAddr == "" || cfg.VaultToken == "" {
+			return nil, fmt.Errorf("vault backend requested but VAULT_ADDR or VAULT_TOKEN not set")
+		}
+		return NewVaultKeyStore(cfg.VaultAddr, cfg.VaultToken, cfg.VaultNamespace, cfg.VaultMount, cfg.VaultPath, logger)
+
 	case "local":
 		return NewLocalKeyStore(cfg.LocalKeyDir, logger)
 
@@ -3,12 +3,18 @@
 // This package defines a KeyStore interface for managing SSH keys used during
 // agent enrollment. The primary implementation uses 1Password Connect for
 // production environments, with a local file-based fallback for development.
+// AWS Secrets Manager and HashiCorp Vault (KV v2) are also supported for
+// deployments that mandate a specific secret manager.
+//
+// Agent API keys are not kept here: the control plane stores only their
+// hashes, in the agents table, and never holds a key after issuing it.
 package secrets
 
 import (
 	"context"
 	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/json"
 	"encoding/pem"
 	"fmt"
 	"time"
@@ -96,3 +102,49 @@ func ParsePrivateKey(pemBytes []byte) (ssh.Signer, error) {
 	}
 	return signer, nil
 }
+
+// storedKey is the JSON document written to secret managers that store a
+// single opaque value per secret (AWS Secrets Manager, Vault KV). Each
+// rotation writes a new version of the same document.
+type storedKey struct {
+	Name        string     `json:"name"`
+	KeyType     string     `json:"key_type"`
+	PublicKey   string     `json:"public_key"`
+	PrivateKey  string     `json:"private_key"`
+	Fingerprint string     `json:"fingerprint"`
+	CreatedAt   time.Time  `json:"created_at"`
+	RotatedAt   *time.Time `json:"rotated_at,omitempty"`
+}
+
+// marshalStoredKey encodes a key pair for storage in a secret manager.
+func marshalStoredKey(keyPair *SSHKeyPair) ([]byte, error) {
+	return json.Marshal(storedKey{
+		Name:        keyPair.Name,
+		KeyType:     keyPair.KeyType,
+		PublicKey:   keyPair.PublicKey,
+		PrivateKey:  string(keyPair.PrivateKey),
+		Fingerprint: keyPair.Fingerprint,
+		CreatedAt:   keyPair.CreatedAt,
+		RotatedAt:   keyPair.RotatedAt,
+	})
+}
+
+// unmarshalStoredKey decodes a key pair written by marshalStoredKey.
+func unmarshalStoredKey(data []byte) (*SSHKeyPair, error) {
+	var sk storedKey
+	if err := json.Unmarshal(data, &sk); err != nil {
+		return nil, fmt.Errorf("parsing stored key: %w", err)
+	}
+	if sk.PrivateKey == "" {
+		return nil, fmt.Errorf("stored key %q has no private key", sk.Name)
+	}
+	return &SSHKeyPair{
+		Name:        sk.Name,
+		KeyType:     sk.KeyType,
+		PublicKey:   sk.PublicKey,
+		PrivateKey:  []byte(sk.PrivateKey),
+		Fingerprint: sk.Fingerprint,
+		CreatedAt:   sk.CreatedAt,
+		RotatedAt:   sk.RotatedAt,
+	}, nil
+}
@@ -0,0 +1,334 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// VaultKeyStore stores SSH keys in a HashiCorp Vault KV version 2 secrets engine.
+//
+// Each key is a single secret at <mount>/data/<path>/<key_name>. Rotation
+// writes a new version of the same secret, so previous keys remain readable
+// through Vault's version history (bounded by the engine's max_versions).
+type VaultKeyStore struct {
+	addr      string
+	token     string
+	namespace string
+	mount     string
+	path      string
+	client    *http.Client
+	logger    *slog.Logger
+
+	mu       sync.RWMutex
+	keyCache map[string]*SSHKeyPair
+}
+
+// vaultKVResponse is the response body of a KV v2 read.
+type vaultKVResponse struct {
+	Data struct {
+		Data     map[string]string `json:"data"`
+		Metadata struct {
+			Version     int       `json:"version"`
+			CreatedTime time.Time `json:"created_time"`
+		} `json:"metadata"`
+	} `json:"data"`
+}
+
+// vaultKVWriteResponse is the response body of a KV v2 write.
+type vaultKVWriteResponse struct {
+	Data struct {
+		Version int `json:"version"`
+	} `json:"data"`
+}
+
+// vaultKeyField is the field within the KV secret holding the encoded key.
+const vaultKeyField = "key"
+
+// NewVaultKeyStore creates a key store backed by Vault's KV v2 engine.
+func NewVaultKeyStore(addr, token, namespace, mount, path string, logger *slog.Logger) (*VaultKeyStore, error) {
+	if addr == "" {
+		return nil, fmt.Errorf("vault address is required")
+	}
+	if token == "" {
+		return nil, fmt.Errorf("vault token is required")
+	}
+
+	ks := &VaultKeyStore{
+		addr:      strings.TrimRight(addr, "/"),
+		token:     token,
+		namespace: namespace,
+		mount:     strings.Trim(mount, "/"),
+		path:      strings.Trim(path, "/"),
+		client:    &http.Client{Timeout: config.DefaultHTTPTimeout},
+		logger:    logger,
+		keyCache:  make(map[string]*SSHKeyPair),
+	}
+
+	if err := ks.verifyAccess(); err != nil {
+		return nil, fmt.Errorf("verifying vault access: %w", err)
+	}
+
+	logger.Info("initialized Vault key store", "addr", ks.addr, "mount", ks.mount, "path", ks.path)
+	return ks, nil
+}
+
+// verifyAccess checks that the token is valid.
+func (ks *VaultKeyStore) verifyAccess() error {
+	ctx, cancel := context.WithTimeout(context.Background(), config.DefaultHTTPTimeout)
+	defer cancel()
+
+	resp, err := ks.do(ctx, http.MethodGet, "/v1/auth/token/lookup-self", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return vaultError(resp)
+	}
+	return nil
+}
+
+// GetOrCreateProvisioningKey returns the control plane's SSH key pair,
+// creating one if it doesn't exist.
+func (ks *VaultKeyStore) GetOrCreateProvisioningKey(ctx context.Context) (*SSHKeyPair, error) {
+	ks.mu.RLock()
+	if cached, ok := ks.keyCache[DefaultKeyName]; ok {
+		ks.mu.RUnlock()
+		return cached, nil
+	}
+	ks.mu.RUnlock()
+
+	keyPair, err := ks.readKey(ctx, DefaultKeyName, 0)
+	if err != nil {
+		return nil, fmt.Errorf("checking for existing key: %w", err)
+	}
+
+	if keyPair == nil {
+		ks.logger.Info("creating new provisioning SSH key in Vault", "name", DefaultKeyName)
+
+		keyPair, err = GenerateSSHKeyPair(DefaultKeyName)
+		if err != nil {
+			return nil, fmt.Errorf("generating key pair: %w", err)
+		}
+		if err := ks.writeKey(ctx, keyPair); err != nil {
+			return nil, fmt.Errorf("storing key in vault: %w", err)
+		}
+
+		ks.logger.Info("created new provisioning SSH key",
+			"name", DefaultKeyName,
+			"fingerprint", keyPair.Fingerprint,
+			"version", keyPair.ID)
+	}
+
+	ks.mu.Lock()
+	ks.keyCache[DefaultKeyName] = keyPair
+	ks.mu.Unlock()
+
+	return keyPair, nil
+}
+
+// GetPrivateKey retrieves only the private key bytes for a named key.
+func (ks *VaultKeyStore) GetPrivateKey(ctx context.Context, name string) ([]byte, error) {
+	keyPair, err := ks.readKey(ctx, name, 0)
+	if err != nil {
+		return nil, err
+	}
+	if keyPair == nil {
+		return nil, nil
+	}
+	return keyPair.PrivateKey, nil
+}
+
+// GetPublicKey retrieves the public key in OpenSSH format.
+func (ks *VaultKeyStore) GetPublicKey(ctx context.Context, name string) (string, error) {
+	ks.mu.RLock()
+	if cached, ok := ks.keyCache[name]; ok {
+		ks.mu.RUnlock()
+		return cached.PublicKey, nil
+	}
+	ks.mu.RUnlock()
+
+	keyPair, err := ks.readKey(ctx, name, 0)
+	if err != nil {
+		return "", err
+	}
+	if keyPair == nil {
+		return "", fmt.Errorf("key not found: %s", name)
+	}
+	return keyPair.PublicKey, nil
+}
+
+// GetKeyVersion retrieves a specific version of a named key.
+// Used to read a key that has since been rotated out.
+func (ks *VaultKeyStore) GetKeyVersion(ctx context.Context, name string, version int) (*SSHKeyPair, error) {
+	if version <= 0 {
+		return nil, fmt.Errorf("invalid version: %d", version)
+	}
+	return ks.readKey(ctx, name, version)
+}
+
+// RotateKey writes a new version of the provisioning key. The previous
+// version is retained by Vault and can be read with GetKeyVersion.
+func (ks *VaultKeyStore) RotateKey(ctx context.Context) (*SSHKeyPair, error) {
+	newKey, err := GenerateSSHKeyPair(DefaultKeyName)
+	if err != nil {
+		return nil, fmt.Errorf("generating new key: %w", err)
+	}
+	now := time.Now()
+	newKey.RotatedAt = &now
+
+	if err := ks.writeKey(ctx, newKey); err != nil {
+		return nil, fmt.Errorf("storing new key version: %w", err)
+	}
+
+	ks.mu.Lock()
+	ks.keyCache[DefaultKeyName] = newKey
+	ks.mu.Unlock()
+
+	ks.logger.Info("rotated provisioning SSH key",
+		"fingerprint", newKey.Fingerprint,
+		"version", newKey.ID)
+
+	return newKey, nil
+}
+
+// Close releases any resources.
+func (ks *VaultKeyStore) Close() error {
+	ks.mu.Lock()
+	ks.keyCache = make(map[string]*SSHKeyPair)
+	ks.mu.Unlock()
+	ks.client.CloseIdleConnections()
+	return nil
+}
+
+// readKey reads a key from Vault. A version of 0 reads the latest version.
+// Returns nil if the secret doesn't exist.
+func (ks *VaultKeyStore) readKey(ctx context.Context, name string, version int) (*SSHKeyPair, error) {
+	path := ks.dataPath(name)
+	if version > 0 {
+		path += "?version=" + strconv.Itoa(version)
+	}
+
+	resp, err := ks.do(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, vaultError(resp)
+	}
+
+	var kv vaultKVResponse
+	if err := json.NewDecoder(resp.Body).Decode(&kv); err != nil {
+		return nil, fmt.Errorf("decoding vault response: %w", err)
+	}
+
+	// A deleted (but not destroyed) version returns null data
+	encoded, ok := kv.Data.Data[vaultKeyField]
+	if !ok {
+		return nil, nil
+	}
+
+	keyPair, err := unmarshalStoredKey([]byte(encoded))
+	if err != nil {
+		return nil, err
+	}
+	keyPair.ID = strconv.Itoa(kv.Data.Metadata.Version)
+	return keyPair, nil
+}
+
+// writeKey writes a new version of a key to Vault and records the
+// resulting version number in keyPair.ID.
+func (ks *VaultKeyStore) writeKey(ctx context.Context, keyPair *SSHKeyPair) error {
+	encoded, err := marshalStoredKey(keyPair)
+	if err != nil {
+		return fmt.Errorf("encoding key: %w", err)
+	}
+
+	body, err := json.Marshal(map[string]any{
+		"data": map[string]string{vaultKeyField: string(encoded)},
+	})
+	if err != nil {
+		return fmt.Errorf("encoding request: %w", err)
+	}
+
+	resp, err := ks.do(ctx, http.MethodPost, ks.dataPath(keyPair.Name), body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return vaultError(resp)
+	}
+
+	var written vaultKVWriteResponse
+	if err := json.NewDecoder(resp.Body).Decode(&written); err == nil && written.Data.Version > 0 {
+		keyPair.ID = strconv.Itoa(written.Data.Version)
+	}
+	return nil
+}
+
+// dataPath returns the KV v2 data API path for a key.
+func (ks *VaultKeyStore) dataPath(name string) string {
+	p := "/v1/" + ks.mount + "/data/"
+	if ks.path != "" {
+		p += ks.path + "/"
+	}
+	return p + url.PathEscape(name)
+}
+
+// do sends an authenticated request to Vault.
+func (ks *VaultKeyStore) do(ctx context.Context, method, path string, body []byte) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, ks.addr+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", ks.token)
+	if ks.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", ks.namespace)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := ks.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("vault request: %w", err)
+	}
+	return resp, nil
+}
+
+// vaultError builds an error from a non-success Vault response.
+func vaultError(resp *http.Response) error {
+	var body struct {
+		Errors []string `json:"errors"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&body)
+	if len(body.Errors) > 0 {
+		return fmt.Errorf("vault returned %d: %s", resp.StatusCode, strings.Join(body.Errors, "; "))
+	}
+	return fmt.Errorf("vault returned %d", resp.StatusCode)
+}
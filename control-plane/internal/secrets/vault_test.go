@@ -0,0 +1,158 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeVault is a KV version 2 engine mounted at secret/ that keeps every
+// version written.
+type fakeVault struct {
+	token     string
+	namespace string
+
+	mu       sync.Mutex
+	versions map[string][]map[string]string
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Header.Get("X-Vault-Token") != v.token || r.Header.Get("X-Vault-Namespace") != v.namespace {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string][]string{"errors": {"permission denied"}})
+		return
+	}
+	if r.URL.Path == "/v1/auth/token/lookup-self" {
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{}})
+		return
+	}
+	path, ok := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPost:
+		var body struct {
+			Data map[string]string `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		v.versions[path] = append(v.versions[path], body.Data)
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"version": len(v.versions[path])}})
+	case http.MethodGet:
+		versions := v.versions[path]
+		version := len(versions)
+		if s := r.URL.Query().Get("version"); s != "" {
+			version, _ = strconv.Atoi(s)
+		}
+		if version < 1 || version > len(versions) {
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(map[string][]string{"errors": {}})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{
+			"data":     versions[version-1],
+			"metadata": map[string]any{"version": version},
+		}})
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func newTestVault(t *testing.T) (*fakeVault, *VaultKeyStore) {
+	t.Helper()
+	fake := &fakeVault{token: "s.test", namespace: "ops", versions: make(map[string][]map[string]string)}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	ks, err := NewVaultKeyStore(srv.URL, fake.token, fake.namespace, "secret", "icmpmon", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err != nil {
+		t.Fatalf("NewVaultKeyStore: %v", err)
+	}
+	t.Cleanup(func() { ks.Close() })
+	return fake, ks
+}
+
+func TestVaultKeyStore_Versions(t *testing.T) {
+	fake, ks := newTestVault(t)
+	ctx := context.Background()
+
+	first, err := ks.GetOrCreateProvisioningKey(ctx)
+	if err != nil {
+		t.Fatalf("GetOrCreateProvisioningKey: %v", err)
+	}
+	if first.ID != "1" {
+		t.Errorf("created key version = %q, want 1", first.ID)
+	}
+	if got := len(fake.versions["icmpmon/"+DefaultKeyName]); got != 1 {
+		t.Fatalf("vault holds %d versions after create, want 1", got)
+	}
+
+	second, err := ks.RotateKey(ctx)
+	if err != nil {
+		t.Fatalf("RotateKey: %v", err)
+	}
+	if second.ID != "2" || second.Fingerprint == first.Fingerprint {
+		t.Errorf("rotated key = version %q fingerprint %s, want a new key at version 2", second.ID, second.Fingerprint)
+	}
+
+	tests := []struct {
+		name    string
+		read    func() (*SSHKeyPair, error)
+		want    *SSHKeyPair
+		wantErr bool
+	}{
+		{name: "latest", read: func() (*SSHKeyPair, error) { return ks.readKey(ctx, DefaultKeyName, 0) }, want: second},
+		{name: "rotated out", read: func() (*SSHKeyPair, error) { return ks.GetKeyVersion(ctx, DefaultKeyName, 1) }, want: first},
+		{name: "current", read: func() (*SSHKeyPair, error) { return ks.GetKeyVersion(ctx, DefaultKeyName, 2) }, want: second},
+		{name: "version not written", read: func() (*SSHKeyPair, error) { return ks.GetKeyVersion(ctx, DefaultKeyName, 3) }},
+		{name: "missing key", read: func() (*SSHKeyPair, error) { return ks.readKey(ctx, "other", 0) }},
+		{name: "invalid version", read: func() (*SSHKeyPair, error) { return ks.GetKeyVersion(ctx, DefaultKeyName, 0) }, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.read()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.want == nil {
+				if got != nil {
+					t.Errorf("got key version %q, want none", got.ID)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("got no key")
+			}
+			if got.ID != tt.want.ID || got.Fingerprint != tt.want.Fingerprint || string(got.PrivateKey) != string(tt.want.PrivateKey) {
+				t.Errorf("got version %q fingerprint %s, want version %q fingerprint %s",
+					got.ID, got.Fingerprint, tt.want.ID, tt.want.Fingerprint)
+			}
+		})
+	}
+}
+
+func TestNewVaultKeyStore_BadToken(t *testing.T) {
+	srv := httptest.NewServer(&fakeVault{token: "s.test"})
+	defer srv.Close()
+
+	_, err := NewVaultKeyStore(srv.URL, "s.wrong", "", "secret", "icmpmon", slog.New(slog.NewTextHandler(io.Discard, nil)))
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("NewVaultKeyStore error = %v, want permission denied", err)
+	}
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// PROBE ACCOUNTING
+// =============================================================================
+
+// AccountingQuery selects the range and grouping of an accounting report.
+type AccountingQuery struct {
+	Period types.AccountingPeriod
+
+	// Start and End bound the range; zero Start is AccountingDefaultWindow
+	// before End, and zero End is now. Both are widened to whole UTC days.
+	Start time.Time
+	End   time.Time
+
+	// ID limits the report to one target or agent.
+	ID string
+}
+
+// resolve fills in the default period and resolves the range to whole UTC
+// days, from the day containing Start up to the end of the day containing
+// End.
+func (q *AccountingQuery) resolve(now time.Time) (start, end time.Time, err error) {
+	if q.Period == "" {
+		q.Period = types.AccountingPeriodDay
+	}
+	end = q.End
+	if end.IsZero() {
+		end = now
+	}
+	start = q.Start
+	if start.IsZero() {
+		start = end.Add(-config.AccountingDefaultWindow)
+	}
+
+	day := 24 * time.Hour
+	start = start.UTC().Truncate(day)
+	if t := end.UTC().Truncate(day); t.Equal(end) {
+		end = t
+	} else {
+		end = t.Add(day)
+	}
+
+	if !end.After(start) {
+		return start, end, invalidInput("end must be after start")
+	}
+	if end.Sub(start) > config.AccountingMaxWindow {
+		return start, end, invalidInput("range must not exceed %d days", int(config.AccountingMaxWindow/day))
+	}
+	return start, end, nil
+}
+
+// groupTargetAccounting folds rows, ordered by target, into one entry per
+// target.
+func groupTargetAccounting(rows []store.ProbeAccountingRow) []types.TargetProbeAccounting {
+	var targets []types.TargetProbeAccounting
+	for _, r := range rows {
+		if n := len(targets); n == 0 || targets[n-1].TargetID != r.ID {
+			targets = append(targets, types.TargetProbeAccounting{
+				TargetID:    r.ID,
+				TargetIP:    r.Name,
+				DisplayName: r.Label,
+				Tier:        r.Group,
+			})
+		}
+		t := &targets[len(targets)-1]
+		t.TotalProbes += r.Usage.Probes
+		t.Periods = append(t.Periods, r.Usage)
+	}
+	return targets
+}
+
+// groupAgentAccounting folds rows, ordered by agent, into one entry per
+// agent.
+func groupAgentAccounting(rows []store.ProbeAccountingRow) []types.AgentProbeAccounting {
+	var agents []types.AgentProbeAccounting
+	for _, r := range rows {
+		if n := len(agents); n == 0 || agents[n-1].AgentID != r.ID {
+			agents = append(agents, types.AgentProbeAccounting{
+				AgentID:   r.ID,
+				AgentName: r.Name,
+				Region:    r.Label,
+				Provider:  r.Group,
+			})
+		}
+		a := &agents[len(agents)-1]
+		a.TotalProbes += r.Usage.Probes
+		a.Periods = append(a.Periods, r.Usage)
+	}
+	return agents
+}
+
+// GetTargetProbeAccounting reports probes per target by period.
+func (s *Service) GetTargetProbeAccounting(ctx context.Context, q AccountingQuery) (*types.TargetProbeAccountingReport, error) {
+	start, end, err := q.resolve(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.GetTargetProbeAccounting(ctx, q.Period, start, end, q.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting target probe accounting: %w", err)
+	}
+
+	report := &types.TargetProbeAccountingReport{
+		ProbeAccountingRange: types.ProbeAccountingRange{Period: q.Period, Start: start, End: end},
+		Targets:              groupTargetAccounting(rows),
+	}
+	if report.Targets == nil {
+		report.Targets = []types.TargetProbeAccounting{}
+	}
+	for _, t := range report.Targets {
+		report.TotalProbes += t.TotalProbes
+	}
+	return report, nil
+}
+
+// GetAgentProbeAccounting reports probes per agent by period.
+func (s *Service) GetAgentProbeAccounting(ctx context.Context, q AccountingQuery) (*types.AgentProbeAccountingReport, error) {
+	start, end, err := q.resolve(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	rows, err := s.store.GetAgentProbeAccounting(ctx, q.Period, start, end, q.ID)
+	if err != nil {
+		return nil, fmt.Errorf("getting agent probe accounting: %w", err)
+	}
+
+	report := &types.AgentProbeAccountingReport{
+		ProbeAccountingRange: types.ProbeAccountingRange{Period: q.Period, Start: start, End: end},
+		Agents:               groupAgentAccounting(rows),
+	}
+	if report.Agents == nil {
+		report.Agents = []types.AgentProbeAccounting{}
+	}
+	for _, a := range report.Agents {
+		report.TotalProbes += a.TotalProbes
+	}
+	return report, nil
+}
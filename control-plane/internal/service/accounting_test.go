@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestAccountingQuery_Resolve(t *testing.T) {
+	now := time.Date(2026, 3, 15, 10, 30, 0, 0, time.UTC)
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	tests := []struct {
+		name      string
+		query     AccountingQuery
+		wantStart time.Time
+		wantEnd   time.Time
+		wantErr   bool
+	}{
+		{
+			name:      "defaults to 30 days through today",
+			query:     AccountingQuery{},
+			wantStart: time.Date(2026, 2, 13, 0, 0, 0, 0, time.UTC),
+			wantEnd:   day(16),
+		},
+		{
+			name:      "midnight end is exclusive",
+			query:     AccountingQuery{Start: day(1), End: day(8)},
+			wantStart: day(1),
+			wantEnd:   day(8),
+		},
+		{
+			name:      "partial days widened",
+			query:     AccountingQuery{Start: day(1).Add(5 * time.Hour), End: day(7).Add(time.Minute)},
+			wantStart: day(1),
+			wantEnd:   day(8),
+		},
+		{
+			name:      "offset times in UTC days",
+			query:     AccountingQuery{Start: time.Date(2026, 3, 2, 1, 0, 0, 0, time.FixedZone("UTC+5", 5*3600)), End: day(3)},
+			wantStart: day(1),
+			wantEnd:   day(3),
+		},
+		{name: "end before start", query: AccountingQuery{Start: day(5), End: day(2)}, wantErr: true},
+		{name: "range too long", query: AccountingQuery{Start: day(1).AddDate(-3, 0, 0), End: day(1)}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := tt.query
+			start, end, err := q.resolve(now)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("resolve() error = %v, want invalid input", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("resolve() error = %v", err)
+			}
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("resolve() = %v - %v, want %v - %v", start, end, tt.wantStart, tt.wantEnd)
+			}
+			if q.Period != types.AccountingPeriodDay {
+				t.Errorf("Period = %q, want day by default", q.Period)
+			}
+		})
+	}
+}
+
+func TestGroupTargetAccounting_Totals(t *testing.T) {
+	d1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	d2 := d1.AddDate(0, 0, 1)
+	rows := []store.ProbeAccountingRow{
+		{ID: "t1", Name: "10.0.0.1", Group: "standard", Usage: types.ProbeUsage{PeriodStart: d1, Probes: 100, Agents: 2}},
+		{ID: "t1", Name: "10.0.0.1", Group: "standard", Usage: types.ProbeUsage{PeriodStart: d2, Probes: 50, Agents: 1}},
+		{ID: "t2", Name: "10.0.0.2", Group: "vlan", Usage: types.ProbeUsage{PeriodStart: d1, Probes: 7, Agents: 3}},
+	}
+
+	got := groupTargetAccounting(rows)
+	if len(got) != 2 {
+		t.Fatalf("got %d targets, want 2: %+v", len(got), got)
+	}
+	if got[0].TargetID != "t1" || got[0].TotalProbes != 150 || len(got[0].Periods) != 2 || got[0].Tier != "standard" {
+		t.Errorf("t1 = %+v, want 150 probes over 2 periods", got[0])
+	}
+	if got[1].TargetID != "t2" || got[1].TotalProbes != 7 || got[1].TargetIP != "10.0.0.2" {
+		t.Errorf("t2 = %+v, want 7 probes", got[1])
+	}
+}
+
+func TestGroupAgentAccounting_Totals(t *testing.T) {
+	d1 := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	rows := []store.ProbeAccountingRow{
+		{ID: "a1", Name: "nyc-1", Label: "us-east", Group: "aws", Usage: types.ProbeUsage{PeriodStart: d1, Probes: 10, Targets: 4}},
+		{ID: "a2", Name: "lax-1", Usage: types.ProbeUsage{PeriodStart: d1, Probes: 20, Targets: 5}},
+	}
+
+	got := groupAgentAccounting(rows)
+	if len(got) != 2 {
+		t.Fatalf("got %d agents, want 2: %+v", len(got), got)
+	}
+	if got[0].AgentName != "nyc-1" || got[0].Region != "us-east" || got[0].Provider != "aws" || got[0].TotalProbes != 10 {
+		t.Errorf("a1 = %+v", got[0])
+	}
+	if got[1].TotalProbes != 20 || got[1].Periods[0].Targets != 5 {
+		t.Errorf("a2 = %+v", got[1])
+	}
+}
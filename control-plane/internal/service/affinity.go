@@ -0,0 +1,181 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ASSIGNMENT AFFINITY RULES
+// =============================================================================
+
+// applyAffinityRules narrows the agents a tier policy made eligible for a
+// target to those the rules allow. Rules must be enabled and in priority
+// order. The IDs of rules that would remove every remaining agent are
+// returned as unsatisfied: a rule whose fallback is allow is skipped, any
+// other leaves the target with no eligible agent.
+func applyAffinityRules(rules []types.AffinityRule, target types.Target, agents []types.Agent) (eligible []types.Agent, unsatisfied []string) {
+	eligible = agents
+	for i := range rules {
+		rule := &rules[i]
+		if len(eligible) == 0 || !rule.MatchesTarget(target.Tags) {
+			continue
+		}
+		allowed := make([]types.Agent, 0, len(eligible))
+		for _, agent := range eligible {
+			if rule.AllowsAgent(agent.Tags) {
+				allowed = append(allowed, agent)
+			}
+		}
+		if len(allowed) == 0 {
+			unsatisfied = append(unsatisfied, rule.ID)
+			if rule.FailsOpen() {
+				continue
+			}
+			return nil, unsatisfied
+		}
+		eligible = allowed
+	}
+	return eligible, unsatisfied
+}
+
+// agentAllowedByAffinity reports whether the rules leave agentID among a
+// target's eligible agents.
+func agentAllowedByAffinity(rules []types.AffinityRule, target types.Target, agents []types.Agent, agentID string) bool {
+	eligible, _ := applyAffinityRules(rules, target, agents)
+	return slices.ContainsFunc(eligible, func(a types.Agent) bool { return a.ID == agentID })
+}
+
+// ListAffinityRules returns all affinity rules in application order.
+func (s *Service) ListAffinityRules(ctx context.Context) ([]types.AffinityRule, error) {
+	return s.store.ListAffinityRules(ctx, false)
+}
+
+// GetAffinityRule retrieves an affinity rule by ID.
+func (s *Service) GetAffinityRule(ctx context.Context, id string) (*types.AffinityRule, error) {
+	r, err := s.store.GetAffinityRule(ctx, id)
+	return r, fromStore(err, "")
+}
+
+// CreateAffinityRule validates and stores a rule, then checks it against the
+// current fleet. The check is advisory: an unsatisfiable rule is still
+// saved, since agents may be on their way.
+func (s *Service) CreateAffinityRule(ctx context.Context, r *types.AffinityRule) (*types.AffinityCheck, error) {
+	if err := r.Validate(); err != nil {
+		return nil, invalidInput("%s", err)
+	}
+	if err := s.store.CreateAffinityRule(ctx, r); err != nil {
+		return nil, err
+	}
+	s.logger.Info("affinity rule created", "rule_id", r.ID, "name", r.Name, "mode", r.Mode, "priority", r.Priority)
+	return s.checkSavedAffinityRule(ctx, r), nil
+}
+
+// UpdateAffinityRule validates and saves changes to a rule, then checks it.
+func (s *Service) UpdateAffinityRule(ctx context.Context, r *types.AffinityRule) (*types.AffinityCheck, error) {
+	if err := r.Validate(); err != nil {
+		return nil, invalidInput("%s", err)
+	}
+	if err := s.store.UpdateAffinityRule(ctx, r); err != nil {
+		return nil, fromStore(err, "")
+	}
+	s.logger.Info("affinity rule updated", "rule_id", r.ID, "name", r.Name, "enabled", r.Enabled)
+	return s.checkSavedAffinityRule(ctx, r), nil
+}
+
+// DeleteAffinityRule removes a rule.
+func (s *Service) DeleteAffinityRule(ctx context.Context, id string) error {
+	return fromStore(s.store.DeleteAffinityRule(ctx, id), "")
+}
+
+// checkSavedAffinityRule runs CheckAffinityRule for a rule that was just
+// saved, logging instead of failing the save.
+func (s *Service) checkSavedAffinityRule(ctx context.Context, r *types.AffinityRule) *types.AffinityCheck {
+	check, err := s.CheckAffinityRule(ctx, r)
+	if err != nil {
+		s.logger.Warn("failed to check affinity rule", "rule_id", r.ID, "error", err)
+		return nil
+	}
+	if check.UnsatisfiableTargets > 0 {
+		s.logger.Warn("affinity rule unsatisfiable for some targets",
+			"rule_id", r.ID,
+			"name", r.Name,
+			"matched_targets", check.MatchedTargets,
+			"unsatisfiable_targets", check.UnsatisfiableTargets,
+		)
+	}
+	return check
+}
+
+// CheckAffinityRule counts the probed targets a rule matches and how many no
+// active agent satisfies it for, given the tier policies and the other
+// enabled rules. Unless the rule falls back to allow, those targets are left
+// unassigned. A disabled rule is checked as if enabled.
+func (s *Service) CheckAffinityRule(ctx context.Context, rule *types.AffinityRule) (*types.AffinityCheck, error) {
+	enabled, err := s.store.ListAffinityRules(ctx, true)
+	if err != nil {
+		return nil, fmt.Errorf("listing affinity rules: %w", err)
+	}
+	rules := withAffinityRule(enabled, *rule)
+
+	targets, err := s.store.ListTargets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing targets: %w", err)
+	}
+	agents, err := s.store.ListActiveAgents(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing agents: %w", err)
+	}
+	tiers, err := s.store.ListTiers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing tiers: %w", err)
+	}
+	tierMap := make(map[string]types.Tier, len(tiers))
+	for _, t := range tiers {
+		tierMap[t.Name] = t
+	}
+
+	check := &types.AffinityCheck{RuleID: rule.ID}
+	for _, target := range targets {
+		if target.ArchivedAt != nil || !target.ProbingEnabled || !rule.MatchesTarget(target.Tags) {
+			continue
+		}
+		check.MatchedTargets++
+
+		tier, ok := tierMap[target.Tier]
+		if !ok {
+			continue
+		}
+		_, unsatisfied := applyAffinityRules(rules, target, s.filterAgents(agents, tier.AgentSelection))
+		if slices.Contains(unsatisfied, rule.ID) {
+			check.UnsatisfiableTargets++
+			if len(check.SampleTargetIDs) < config.AffinityCheckSampleSize {
+				check.SampleTargetIDs = append(check.SampleTargetIDs, target.ID)
+			}
+		}
+	}
+	return check, nil
+}
+
+// withAffinityRule returns rules with r in its priority position, replacing
+// any stored copy of it.
+func withAffinityRule(rules []types.AffinityRule, r types.AffinityRule) []types.AffinityRule {
+	out := make([]types.AffinityRule, 0, len(rules)+1)
+	for _, existing := range rules {
+		if existing.ID != r.ID {
+			out = append(out, existing)
+		}
+	}
+	r.Enabled = true
+	out = append(out, r)
+	slices.SortStableFunc(out, func(a, b types.AffinityRule) int {
+		return cmp.Or(cmp.Compare(a.Priority, b.Priority), strings.Compare(a.Name, b.Name))
+	})
+	return out
+}
@@ -0,0 +1,158 @@
+package service
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func affinityAgents() []types.Agent {
+	return []types.Agent{
+		{ID: "a1", Tags: map[string]string{"compliant": "true", "pop": "ord"}},
+		{ID: "a2", Tags: map[string]string{"compliant": "true", "pop": "iad"}},
+		{ID: "a3", Tags: map[string]string{"pop": "ord"}},
+	}
+}
+
+func agentIDs(agents []types.Agent) []string {
+	ids := make([]string, 0, len(agents))
+	for _, a := range agents {
+		ids = append(ids, a.ID)
+	}
+	return ids
+}
+
+func TestApplyAffinityRules_Constraints(t *testing.T) {
+	pci := []types.TagFilter{{Key: "pci", Value: "true"}}
+	requireCompliant := types.AffinityRule{ID: "r-compliant", Mode: types.AffinityModeRequire,
+		TargetFilters: pci, AgentFilters: []types.TagFilter{{Key: "compliant", Value: "true"}}}
+	avoidORD := types.AffinityRule{ID: "r-no-ord", Mode: types.AffinityModeAvoid,
+		TargetFilters: pci, AgentFilters: []types.TagFilter{{Key: "pop", Value: "ord"}}}
+	requireLab := types.AffinityRule{ID: "r-lab", Mode: types.AffinityModeRequire,
+		TargetFilters: pci, AgentFilters: []types.TagFilter{{Key: "env", Value: "lab"}}}
+	avoidIAD := types.AffinityRule{ID: "r-no-iad", Mode: types.AffinityModeAvoid,
+		TargetFilters: pci, AgentFilters: []types.TagFilter{{Key: "pop", Value: "iad"}}}
+	requireLabOrAny, avoidIADOrAny := requireLab, avoidIAD
+	requireLabOrAny.Fallback = types.AffinityFallbackAllow
+	avoidIADOrAny.Fallback = types.AffinityFallbackAllow
+
+	tests := []struct {
+		name            string
+		rules           []types.AffinityRule
+		targetTags      map[string]string
+		wantAgents      []string
+		wantUnsatisfied []string
+	}{
+		{"no rules", nil, map[string]string{"pci": "true"}, []string{"a1", "a2", "a3"}, nil},
+		{"rule does not match target", []types.AffinityRule{requireCompliant}, map[string]string{"pci": "false"}, []string{"a1", "a2", "a3"}, nil},
+		{"affinity", []types.AffinityRule{requireCompliant}, map[string]string{"pci": "true"}, []string{"a1", "a2"}, nil},
+		{"affinity then anti-affinity", []types.AffinityRule{requireCompliant, avoidORD}, map[string]string{"pci": "true"}, []string{"a2"}, nil},
+		{"unsatisfiable rule leaves target unassigned", []types.AffinityRule{requireLab, requireCompliant}, map[string]string{"pci": "true"}, nil, []string{"r-lab"}},
+		{"conflict leaves target unassigned", []types.AffinityRule{requireCompliant, avoidORD, avoidIAD}, map[string]string{"pci": "true"}, nil, []string{"r-no-iad"}},
+		{"unsatisfiable fallback-allow rule skipped", []types.AffinityRule{requireLabOrAny, requireCompliant}, map[string]string{"pci": "true"}, []string{"a1", "a2"}, []string{"r-lab"}},
+		{"fallback-allow rule loses conflict", []types.AffinityRule{requireCompliant, avoidORD, avoidIADOrAny}, map[string]string{"pci": "true"}, []string{"a2"}, []string{"r-no-iad"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, unsatisfied := applyAffinityRules(tt.rules, types.Target{ID: "t1", Tags: tt.targetTags}, affinityAgents())
+			if ids := agentIDs(got); !slices.Equal(ids, tt.wantAgents) {
+				t.Errorf("eligible = %v, want %v", ids, tt.wantAgents)
+			}
+			if !slices.Equal(unsatisfied, tt.wantUnsatisfied) {
+				t.Errorf("unsatisfied = %v, want %v", unsatisfied, tt.wantUnsatisfied)
+			}
+		})
+	}
+}
+
+func TestShouldAssign_CompliantAgentsDown(t *testing.T) {
+	target := types.Target{ID: "t1", IP: "192.0.2.10", Tags: map[string]string{"pci": "true"}}
+	nonCompliant := affinityAgents()[2:] // every compliant agent is down
+
+	tests := []struct {
+		name     string
+		fallback types.AffinityFallback
+		strategy string
+		want     bool
+	}{
+		{"all strategy, hard constraint", "", "all", false},
+		{"distributed, hard constraint", types.AffinityFallbackDeny, "distributed", false},
+		{"all strategy, fallback allow", types.AffinityFallbackAllow, "all", true},
+		{"distributed, fallback allow", types.AffinityFallbackAllow, "distributed", true},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := []types.AffinityRule{{ID: "r1", Mode: types.AffinityModeRequire, Fallback: tt.fallback,
+				TargetFilters: []types.TagFilter{{Key: "pci", Value: "true"}},
+				AgentFilters:  []types.TagFilter{{Key: "compliant", Value: "true"}}}}
+			tier := types.Tier{Name: "standard", AgentSelection: types.AgentSelectionPolicy{Strategy: tt.strategy, Count: 1}}
+			if got := s.shouldAssign(&nonCompliant[0], nonCompliant, target, tier, rules); got != tt.want {
+				t.Errorf("shouldAssign(non-compliant agent) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestShouldAssign_AffinityRules(t *testing.T) {
+	rules := []types.AffinityRule{{ID: "r1", Mode: types.AffinityModeRequire,
+		TargetFilters: []types.TagFilter{{Key: "pci", Value: "true"}},
+		AgentFilters:  []types.TagFilter{{Key: "compliant", Value: "true"}}}}
+	target := types.Target{ID: "t1", IP: "192.0.2.10", Tags: map[string]string{"pci": "true"}}
+	agents := affinityAgents()
+
+	tests := []struct {
+		name   string
+		policy types.AgentSelectionPolicy
+		agent  types.Agent
+		want   bool
+	}{
+		{"all strategy, allowed agent", types.AgentSelectionPolicy{Strategy: "all"}, agents[0], true},
+		{"all strategy, disallowed agent", types.AgentSelectionPolicy{Strategy: "all"}, agents[2], false},
+		{"distributed, disallowed agent never selected", types.AgentSelectionPolicy{Strategy: "distributed", Count: 3}, agents[2], false},
+		{"distributed, allowed agent selected", types.AgentSelectionPolicy{Strategy: "distributed", Count: 3}, agents[1], true},
+	}
+
+	s := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.shouldAssign(&tt.agent, agents, target, types.Tier{Name: "standard", AgentSelection: tt.policy}, rules)
+			if got != tt.want {
+				t.Errorf("shouldAssign(%s) = %v, want %v", tt.agent.ID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithAffinityRule_Order(t *testing.T) {
+	stored := []types.AffinityRule{
+		{ID: "a", Name: "a", Priority: 10},
+		{ID: "b", Name: "b", Priority: 100},
+	}
+
+	tests := []struct {
+		name string
+		rule types.AffinityRule
+		want []string
+	}{
+		{"new rule by priority", types.AffinityRule{ID: "c", Name: "c", Priority: 50}, []string{"a", "c", "b"}},
+		{"name breaks ties", types.AffinityRule{ID: "c", Name: "0-first", Priority: 100}, []string{"a", "c", "b"}},
+		{"replaces stored copy", types.AffinityRule{ID: "b", Name: "b", Priority: 1}, []string{"b", "a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := withAffinityRule(stored, tt.rule)
+			ids := make([]string, 0, len(got))
+			for _, r := range got {
+				ids = append(ids, r.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("order = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// AGENT ANOMALY TRIAGE
+// =============================================================================
+
+// medianAffected returns the median affected-target count across agents.
+func medianAffected(agents []types.AgentAnomalySummary) float64 {
+	if len(agents) == 0 {
+		return 0
+	}
+	counts := make([]int, len(agents))
+	for i, a := range agents {
+		counts[i] = len(a.AffectedTargets)
+	}
+	sort.Ints(counts)
+	mid := len(counts) / 2
+	if len(counts)%2 == 1 {
+		return float64(counts[mid])
+	}
+	return float64(counts[mid-1]+counts[mid]) / 2
+}
+
+// rankAgentAnomalies flags agents whose affected targets are out of line
+// with the fleet and returns those with anything open, most affected first.
+// When most agents see the same problems the median rises with them, so a
+// target- or network-side outage doesn't flag every agent.
+func rankAgentAnomalies(agents []types.AgentAnomalySummary) *types.AgentAnomalyReport {
+	report := &types.AgentAnomalyReport{
+		ActiveAgents:        len(agents),
+		FleetMedianAffected: medianAffected(agents),
+		Agents:              []types.AgentAnomalySummary{},
+	}
+	threshold := config.AgentSideFleetMultiple * math.Max(report.FleetMedianAffected, 1)
+
+	for _, a := range agents {
+		if a.AnomalyCount == 0 && a.AlertCount == 0 {
+			continue
+		}
+		affected := len(a.AffectedTargets)
+		if affected >= config.AgentSideMinAffectedTargets && float64(affected) > threshold {
+			a.LikelyAgentSide = true
+			a.Reason = fmt.Sprintf("%d affected targets vs fleet median %.1f", affected, report.FleetMedianAffected)
+			report.LikelyAgentSideCount++
+		}
+		report.Agents = append(report.Agents, a)
+	}
+
+	sort.SliceStable(report.Agents, func(i, j int) bool {
+		a, b := report.Agents[i], report.Agents[j]
+		if len(a.AffectedTargets) != len(b.AffectedTargets) {
+			return len(a.AffectedTargets) > len(b.AffectedTargets)
+		}
+		if a.AnomalyCount+a.AlertCount != b.AnomalyCount+b.AlertCount {
+			return a.AnomalyCount+a.AlertCount > b.AnomalyCount+b.AlertCount
+		}
+		return a.AgentName < b.AgentName
+	})
+	return report
+}
+
+// GetAgentAnomalies ranks agents by the targets they currently see
+// anomalies or alerts on, flagging likely agent-side issues.
+func (s *Service) GetAgentAnomalies(ctx context.Context) (*types.AgentAnomalyReport, error) {
+	agents, err := s.store.GetAgentAnomalySummaries(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting agent anomaly summaries: %w", err)
+	}
+
+	report := rankAgentAnomalies(agents)
+	report.GeneratedAt = time.Now()
+	return report, nil
+}
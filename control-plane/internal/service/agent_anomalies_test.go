@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestRankAgentAnomalies_Flagging(t *testing.T) {
+	agent := func(name string, affected, alerts int) types.AgentAnomalySummary {
+		a := types.AgentAnomalySummary{AgentID: name, AgentName: name, AlertCount: alerts}
+		for i := 0; i < affected; i++ {
+			a.AffectedTargets = append(a.AffectedTargets, fmt.Sprintf("t%d", i))
+		}
+		a.AnomalyCount = affected
+		return a
+	}
+
+	tests := []struct {
+		name        string
+		agents      []types.AgentAnomalySummary
+		wantOrder   []string
+		wantFlagged []string
+		wantMedian  float64
+	}{
+		{
+			name:        "one outlier in a quiet fleet",
+			agents:      []types.AgentAnomalySummary{agent("a", 0, 0), agent("b", 1, 0), agent("c", 12, 0), agent("d", 0, 0)},
+			wantOrder:   []string{"c", "b"},
+			wantFlagged: []string{"c"},
+			wantMedian:  0.5,
+		},
+		{
+			name:       "target-side outage seen by all agents",
+			agents:     []types.AgentAnomalySummary{agent("a", 10, 0), agent("b", 11, 0), agent("c", 12, 0)},
+			wantOrder:  []string{"c", "b", "a"},
+			wantMedian: 11,
+		},
+		{
+			name:       "too few targets to flag",
+			agents:     []types.AgentAnomalySummary{agent("a", 0, 0), agent("b", 2, 0), agent("c", 0, 0)},
+			wantOrder:  []string{"b"},
+			wantMedian: 0,
+		},
+		{
+			name:       "ties broken by total count then name",
+			agents:     []types.AgentAnomalySummary{agent("b", 1, 0), agent("a", 1, 0), agent("c", 1, 2)},
+			wantOrder:  []string{"c", "a", "b"},
+			wantMedian: 1,
+		},
+		{
+			name:       "empty fleet",
+			wantMedian: 0,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := rankAgentAnomalies(tt.agents)
+
+			var order, flagged []string
+			for _, a := range report.Agents {
+				order = append(order, a.AgentName)
+				if a.LikelyAgentSide {
+					flagged = append(flagged, a.AgentName)
+				}
+			}
+			if strings.Join(order, ",") != strings.Join(tt.wantOrder, ",") {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+			if strings.Join(flagged, ",") != strings.Join(tt.wantFlagged, ",") {
+				t.Errorf("flagged = %v, want %v", flagged, tt.wantFlagged)
+			}
+			if report.LikelyAgentSideCount != len(tt.wantFlagged) {
+				t.Errorf("likely_agent_side_count = %d, want %d", report.LikelyAgentSideCount, len(tt.wantFlagged))
+			}
+			if report.FleetMedianAffected != tt.wantMedian {
+				t.Errorf("median = %v, want %v", report.FleetMedianAffected, tt.wantMedian)
+			}
+			if report.ActiveAgents != len(tt.agents) {
+				t.Errorf("active_agents = %d, want %d", report.ActiveAgents, len(tt.agents))
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// REMOTE AGENT CONFIG
+// =============================================================================
+
+// AgentConfigStatus is an agent's effective remote config with the scopes
+// it was built from and the version the agent last reported applying.
+type AgentConfigStatus struct {
+	types.AgentConfigSet
+
+	Global         *store.AgentConfigScope `json:"global,omitempty"`
+	Override       *store.AgentConfigScope `json:"override,omitempty"`
+	AppliedVersion *int64                  `json:"applied_version"`
+	Applied        bool                    `json:"applied"` // The agent has applied Version
+}
+
+// effectiveAgentConfig merges an agent's override over the global config.
+// Either scope may be nil. The version is the newer scope's, since every
+// write to either takes a fresh one.
+func effectiveAgentConfig(agentID string, global, override *store.AgentConfigScope) *types.AgentConfigSet {
+	set := &types.AgentConfigSet{AgentID: agentID, GeneratedAt: time.Now()}
+	if global != nil {
+		set.Config = global.Config
+		set.Version = global.Version
+	}
+	if override != nil {
+		set.Config = set.Config.Merge(override.Config)
+		set.Version = max(set.Version, override.Version)
+	}
+	return set
+}
+
+// agentConfigScopes returns the global and agent scopes for an agent,
+// failing with ErrNotFound if the agent does not exist.
+func (s *Service) agentConfigScopes(ctx context.Context, agentID string) (global, override *store.AgentConfigScope, err error) {
+	agent, err := s.store.GetAgent(ctx, agentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("getting agent: %w", err)
+	}
+	if agent == nil {
+		return nil, nil, fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
+	}
+	if global, err = s.store.GetAgentConfigScope(ctx, store.AgentConfigGlobalScope); err != nil {
+		return nil, nil, err
+	}
+	if override, err = s.store.GetAgentConfigScope(ctx, agentID); err != nil {
+		return nil, nil, err
+	}
+	return global, override, nil
+}
+
+// GetAgentConfig returns the remote config an agent merges over its local
+// config.
+func (s *Service) GetAgentConfig(ctx context.Context, agentID string) (*types.AgentConfigSet, error) {
+	global, override, err := s.agentConfigScopes(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	return effectiveAgentConfig(agentID, global, override), nil
+}
+
+// GetAgentConfigStatus returns an agent's effective remote config, its
+// layers, and whether the agent has applied it.
+func (s *Service) GetAgentConfigStatus(ctx context.Context, agentID string) (*AgentConfigStatus, error) {
+	global, override, err := s.agentConfigScopes(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+	applied, err := s.store.GetAppliedAgentConfigVersion(ctx, agentID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &AgentConfigStatus{
+		AgentConfigSet: *effectiveAgentConfig(agentID, global, override),
+		Global:         global,
+		Override:       override,
+		AppliedVersion: applied,
+	}
+	status.Applied = applied != nil && *applied == status.Version
+	return status, nil
+}
+
+// GetGlobalAgentConfig returns the config every agent receives. With none
+// set it returns an empty config at version 0.
+func (s *Service) GetGlobalAgentConfig(ctx context.Context) (*store.AgentConfigScope, error) {
+	global, err := s.store.GetAgentConfigScope(ctx, store.AgentConfigGlobalScope)
+	if err != nil {
+		return nil, err
+	}
+	if global == nil {
+		global = &store.AgentConfigScope{Scope: store.AgentConfigGlobalScope}
+	}
+	return global, nil
+}
+
+// SetGlobalAgentConfig replaces the config every agent receives. Agents
+// pick it up after their next heartbeat.
+func (s *Service) SetGlobalAgentConfig(ctx context.Context, config types.AgentRemoteConfig) (*store.AgentConfigScope, error) {
+	if err := config.Validate(); err != nil {
+		return nil, invalidInput("%s", err)
+	}
+	scope, err := s.store.SetAgentConfigScope(ctx, store.AgentConfigGlobalScope, config)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("global agent config updated", "version", scope.Version)
+	return scope, nil
+}
+
+// SetAgentConfigOverride replaces one agent's overrides of the global
+// config.
+func (s *Service) SetAgentConfigOverride(ctx context.Context, agentID string, config types.AgentRemoteConfig) (*store.AgentConfigScope, error) {
+	if err := config.Validate(); err != nil {
+		return nil, invalidInput("%s", err)
+	}
+	agent, err := s.store.GetAgent(ctx, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting agent: %w", err)
+	}
+	if agent == nil {
+		return nil, fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
+	}
+
+	scope, err := s.store.SetAgentConfigScope(ctx, agentID, config)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.Info("agent config override updated", "agent_id", agentID, "version", scope.Version)
+	return scope, nil
+}
+
+// DeleteAgentConfigOverride removes an agent's overrides, returning it to
+// the global config.
+func (s *Service) DeleteAgentConfigOverride(ctx context.Context, agentID string) error {
+	if err := s.store.DeleteAgentConfigScope(ctx, agentID); err != nil {
+		return err
+	}
+	s.logger.Info("agent config override removed", "agent_id", agentID)
+	return nil
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestEffectiveAgentConfig_Layers(t *testing.T) {
+	str := func(s string) *string { return &s }
+
+	global := &store.AgentConfigScope{
+		Scope:   store.AgentConfigGlobalScope,
+		Config:  types.AgentRemoteConfig{LogLevel: str(types.LogLevelInfo), ScheduleAlignment: str("aligned")},
+		Version: 7,
+	}
+	override := &store.AgentConfigScope{
+		Scope:   "agent-1",
+		Config:  types.AgentRemoteConfig{LogLevel: str(types.LogLevelDebug)},
+		Version: 4,
+	}
+
+	tests := []struct {
+		name          string
+		global        *store.AgentConfigScope
+		override      *store.AgentConfigScope
+		wantVersion   int64
+		wantLogLevel  string // "" means unset
+		wantAlignment string
+	}{
+		{"nothing configured", nil, nil, 0, "", ""},
+		{"global only", global, nil, 7, types.LogLevelInfo, "aligned"},
+		{"override only", nil, override, 4, types.LogLevelDebug, ""},
+		{"override wins, newer version kept", global, override, 7, types.LogLevelDebug, "aligned"},
+	}
+
+	deref := func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return *p
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set := effectiveAgentConfig("agent-1", tt.global, tt.override)
+			if set.AgentID != "agent-1" {
+				t.Errorf("agent_id = %q", set.AgentID)
+			}
+			if set.Version != tt.wantVersion {
+				t.Errorf("version = %d, want %d", set.Version, tt.wantVersion)
+			}
+			if got := deref(set.Config.LogLevel); got != tt.wantLogLevel {
+				t.Errorf("log_level = %q, want %q", got, tt.wantLogLevel)
+			}
+			if got := deref(set.Config.ScheduleAlignment); got != tt.wantAlignment {
+				t.Errorf("schedule_alignment = %q, want %q", got, tt.wantAlignment)
+			}
+		})
+	}
+}
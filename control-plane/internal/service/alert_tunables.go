@@ -0,0 +1,25 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// SetAlertTunableDefaults sets the values the alerting workers fall back to
+// when an alert_config key is unset, reported by GetAlertTunables.
+func (s *Service) SetAlertTunableDefaults(defaults types.AlertTunables) {
+	s.alertDefaults = defaults
+}
+
+// GetAlertTunables returns every alert knob with its effective value and
+// default, and every alert_config key.
+func (s *Service) GetAlertTunables(ctx context.Context) (*types.AlertTunablesReport, error) {
+	configs, err := s.store.ListAlertConfigs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing alert config: %w", err)
+	}
+	report := types.BuildAlertTunablesReport(s.alertDefaults, redactAlertConfigs(configs))
+	return &report, nil
+}
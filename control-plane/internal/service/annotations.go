@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET ANNOTATIONS
+// =============================================================================
+
+// CreateTargetAnnotation validates and stores a manual annotation.
+func (s *Service) CreateTargetAnnotation(ctx context.Context, a *types.TargetAnnotation) error {
+	if err := a.Validate(); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := s.store.CreateTargetAnnotation(ctx, a); err != nil {
+		return fromStore(err, "target not found")
+	}
+	s.logger.Info("target annotation created",
+		"annotation_id", a.ID,
+		"target_id", a.TargetID,
+		"starts_at", a.StartsAt,
+		"created_by", a.CreatedBy,
+	)
+	return nil
+}
+
+// DeleteTargetAnnotation removes a manual annotation. Incident annotations
+// aren't stored and so can't be deleted.
+func (s *Service) DeleteTargetAnnotation(ctx context.Context, targetID, id string) error {
+	return fromStore(s.store.DeleteTargetAnnotation(ctx, targetID, id), "")
+}
+
+// GetTargetAnnotations returns the manual and incident annotations that
+// overlap the last window of a target's history, oldest first.
+func (s *Service) GetTargetAnnotations(ctx context.Context, targetID string, window time.Duration) ([]types.TargetAnnotation, error) {
+	to := time.Now()
+	from := to.Add(-window)
+
+	manual, err := s.store.ListTargetAnnotations(ctx, targetID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting annotations: %w", err)
+	}
+	spans, err := s.store.ListTargetIncidentSpans(ctx, targetID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("getting incident annotations: %w", err)
+	}
+	return mergeAnnotations(manual, incidentAnnotations(targetID, spans)), nil
+}
+
+// incidentAnnotations turns the incidents that affected a target into
+// annotations spanning detection to resolution.
+func incidentAnnotations(targetID string, spans []store.TargetIncidentSpan) []types.TargetAnnotation {
+	annotations := make([]types.TargetAnnotation, 0, len(spans))
+	for _, sp := range spans {
+		text := fmt.Sprintf("%s-severity %s incident", sp.Severity, sp.IncidentType)
+		if sp.ResolvedAt == nil {
+			text += " (ongoing)"
+		}
+		annotations = append(annotations, types.TargetAnnotation{
+			ID:        sp.IncidentID,
+			TargetID:  targetID,
+			Source:    types.AnnotationSourceIncident,
+			StartsAt:  sp.DetectedAt,
+			EndsAt:    sp.ResolvedAt,
+			Text:      text,
+			CreatedAt: sp.DetectedAt,
+		})
+	}
+	return annotations
+}
+
+// mergeAnnotations combines annotation lists into one ordered by start
+// time. Annotations starting together keep manual before incident.
+func mergeAnnotations(manual, incidents []types.TargetAnnotation) []types.TargetAnnotation {
+	merged := make([]types.TargetAnnotation, 0, len(manual)+len(incidents))
+	merged = append(merged, manual...)
+	merged = append(merged, incidents...)
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].StartsAt.Before(merged[j].StartsAt)
+	})
+	return merged
+}
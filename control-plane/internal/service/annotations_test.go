@@ -0,0 +1,89 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestMergeAnnotations_Order(t *testing.T) {
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolved := base.Add(30 * time.Minute)
+
+	tests := []struct {
+		name    string
+		manual  []types.TargetAnnotation
+		spans   []store.TargetIncidentSpan
+		wantIDs []string
+		wantTxt []string
+	}{
+		{
+			name:    "nothing to show",
+			wantIDs: []string{},
+			wantTxt: []string{},
+		},
+		{
+			name: "interleaved by start time",
+			manual: []types.TargetAnnotation{
+				{ID: "m1", StartsAt: base, Text: "router upgrade"},
+				{ID: "m2", StartsAt: base.Add(time.Hour), Text: "rollback"},
+			},
+			spans: []store.TargetIncidentSpan{
+				{IncidentID: "i1", IncidentType: "target", Severity: "high", DetectedAt: base.Add(10 * time.Minute), ResolvedAt: &resolved},
+			},
+			wantIDs: []string{"m1", "i1", "m2"},
+			wantTxt: []string{"router upgrade", "high-severity target incident", "rollback"},
+		},
+		{
+			name:   "manual first on a tie and open incident marked ongoing",
+			manual: []types.TargetAnnotation{{ID: "m1", StartsAt: base, Text: "maintenance"}},
+			spans: []store.TargetIncidentSpan{
+				{IncidentID: "i1", IncidentType: "regional", Severity: "critical", DetectedAt: base},
+			},
+			wantIDs: []string{"m1", "i1"},
+			wantTxt: []string{"maintenance", "critical-severity regional incident (ongoing)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := mergeAnnotations(tt.manual, incidentAnnotations("t1", tt.spans))
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("got %d annotations, want %d: %+v", len(got), len(tt.wantIDs), got)
+			}
+			for i, a := range got {
+				if a.ID != tt.wantIDs[i] || a.Text != tt.wantTxt[i] {
+					t.Errorf("annotation %d = %s %q, want %s %q", i, a.ID, a.Text, tt.wantIDs[i], tt.wantTxt[i])
+				}
+			}
+		})
+	}
+}
+
+func TestIncidentAnnotations_Span(t *testing.T) {
+	detected := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolved := detected.Add(time.Hour)
+
+	tests := []struct {
+		name    string
+		span    store.TargetIncidentSpan
+		wantEnd *time.Time
+	}{
+		{"resolved", store.TargetIncidentSpan{IncidentID: "i1", Severity: "low", IncidentType: "target", DetectedAt: detected, ResolvedAt: &resolved}, &resolved},
+		{"open", store.TargetIncidentSpan{IncidentID: "i2", Severity: "low", IncidentType: "target", DetectedAt: detected}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := incidentAnnotations("t1", []store.TargetIncidentSpan{tt.span})[0]
+			if got.Source != types.AnnotationSourceIncident || got.TargetID != "t1" || !got.StartsAt.Equal(detected) {
+				t.Errorf("got %+v, want incident annotation for t1 starting at %v", got, detected)
+			}
+			if (got.EndsAt == nil) != (tt.wantEnd == nil) || (got.EndsAt != nil && !got.EndsAt.Equal(*tt.wantEnd)) {
+				t.Errorf("EndsAt = %v, want %v", got.EndsAt, tt.wantEnd)
+			}
+		})
+	}
+}
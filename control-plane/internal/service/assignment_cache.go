@@ -0,0 +1,94 @@
+package service
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// assignmentCache shares assignment computations between fetches of the
+// same agent at the same assignment version. Every agent refetches after a
+// version bump, and an agent retrying or restarting in that burst would
+// otherwise recompute its set each time; concurrent fetches join the one in
+// flight and fetches within the TTL reuse its result. A version bump misses
+// the cache, so only changes that don't bump the version can be served
+// stale, and only for the TTL.
+type assignmentCache struct {
+	ttl   time.Duration
+	now   func() time.Time
+	group singleflight.Group
+
+	mu      sync.Mutex
+	entries map[string]cachedAssignments // by agent ID
+
+	hits      atomic.Int64
+	coalesced atomic.Int64
+	misses    atomic.Int64
+}
+
+type cachedAssignments struct {
+	version   int64
+	set       *types.AssignmentSet
+	expiresAt time.Time
+}
+
+func newAssignmentCache(ttl time.Duration) *assignmentCache {
+	return &assignmentCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[string]cachedAssignments),
+	}
+}
+
+// get returns the agent's assignments at version, computing them at most
+// once per TTL however many fetches arrive. The set is shared between
+// callers and must not be modified.
+func (c *assignmentCache) get(agentID string, version int64, compute func() (*types.AssignmentSet, error)) (*types.AssignmentSet, error) {
+	c.mu.Lock()
+	entry, ok := c.entries[agentID]
+	c.mu.Unlock()
+	if ok && entry.version == version && c.now().Before(entry.expiresAt) {
+		c.hits.Add(1)
+		return entry.set, nil
+	}
+
+	leader := false
+	v, err, _ := c.group.Do(fmt.Sprintf("%s:%d", agentID, version), func() (any, error) {
+		leader = true
+		set, err := compute()
+		if err != nil {
+			return nil, err
+		}
+		c.mu.Lock()
+		c.entries[agentID] = cachedAssignments{version: version, set: set, expiresAt: c.now().Add(c.ttl)}
+		c.mu.Unlock()
+		return set, nil
+	})
+	if leader {
+		c.misses.Add(1)
+	} else {
+		c.coalesced.Add(1)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v.(*types.AssignmentSet), nil
+}
+
+// stats reports how fetches were served since startup.
+func (c *assignmentCache) stats() types.AssignmentCacheStats {
+	s := types.AssignmentCacheStats{
+		Hits:      c.hits.Load(),
+		Coalesced: c.coalesced.Load(),
+		Misses:    c.misses.Load(),
+	}
+	if total := s.Hits + s.Coalesced + s.Misses; total > 0 {
+		s.HitRate = float64(s.Hits+s.Coalesced) / float64(total)
+	}
+	return s
+}
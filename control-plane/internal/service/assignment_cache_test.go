@@ -0,0 +1,117 @@
+package service
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestAssignmentCache_Reuse(t *testing.T) {
+	errCompute := errors.New("compute failed")
+
+	tests := []struct {
+		name        string
+		firstErr    error
+		agentID     string
+		version     int64
+		advance     time.Duration
+		wantCompute int
+		wantStats   types.AssignmentCacheStats
+	}{
+		{
+			name: "same agent and version within ttl", agentID: "a1", version: 1,
+			wantCompute: 1, wantStats: types.AssignmentCacheStats{Hits: 1, Misses: 1, HitRate: 0.5},
+		},
+		{
+			name: "version bumped", agentID: "a1", version: 2,
+			wantCompute: 2, wantStats: types.AssignmentCacheStats{Misses: 2},
+		},
+		{
+			name: "other agent", agentID: "a2", version: 1,
+			wantCompute: 2, wantStats: types.AssignmentCacheStats{Misses: 2},
+		},
+		{
+			name: "ttl expired", agentID: "a1", version: 1, advance: 5 * time.Second,
+			wantCompute: 2, wantStats: types.AssignmentCacheStats{Misses: 2},
+		},
+		{
+			name: "errors not cached", firstErr: errCompute, agentID: "a1", version: 1,
+			wantCompute: 2, wantStats: types.AssignmentCacheStats{Misses: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			c := newAssignmentCache(5 * time.Second)
+			c.now = func() time.Time { return now }
+
+			computed := 0
+			compute := func(err error) func() (*types.AssignmentSet, error) {
+				return func() (*types.AssignmentSet, error) {
+					computed++
+					if err != nil {
+						return nil, err
+					}
+					return &types.AssignmentSet{Version: 1}, nil
+				}
+			}
+
+			if _, err := c.get("a1", 1, compute(tt.firstErr)); !errors.Is(err, tt.firstErr) {
+				t.Fatalf("first get() error = %v, want %v", err, tt.firstErr)
+			}
+			now = now.Add(tt.advance)
+			if _, err := c.get(tt.agentID, tt.version, compute(nil)); err != nil {
+				t.Fatalf("second get() error = %v", err)
+			}
+
+			if computed != tt.wantCompute {
+				t.Errorf("computed %d times, want %d", computed, tt.wantCompute)
+			}
+			if got := c.stats(); got != tt.wantStats {
+				t.Errorf("stats() = %+v, want %+v", got, tt.wantStats)
+			}
+		})
+	}
+}
+
+func TestAssignmentCache_CoalescesConcurrentFetches(t *testing.T) {
+	c := newAssignmentCache(5 * time.Second)
+
+	const fetches = 10
+	release := make(chan struct{})
+	var computed atomic.Int32
+	var started sync.WaitGroup
+	var done sync.WaitGroup
+	started.Add(fetches)
+	done.Add(fetches)
+
+	for range fetches {
+		go func() {
+			defer done.Done()
+			started.Done()
+			c.get("a1", 1, func() (*types.AssignmentSet, error) {
+				computed.Add(1)
+				<-release
+				return &types.AssignmentSet{}, nil
+			})
+		}()
+	}
+	started.Wait()
+	// Give the goroutines time to join the computation in flight
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	done.Wait()
+
+	stats := c.stats()
+	if got := computed.Load(); got != 1 {
+		t.Errorf("computed %d times, want 1", got)
+	}
+	if total := stats.Hits + stats.Coalesced + stats.Misses; total != fetches || stats.Misses != 1 {
+		t.Errorf("stats() = %+v, want %d fetches with 1 miss", stats, fetches)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ASSIGNMENT DIFF
+// =============================================================================
+
+// diffAssignmentChanges folds an agent's change log, oldest first, into the
+// net targets added and removed. A target's first entry says whether it
+// was assigned before the range (its first change removed it) and its last
+// whether it is assigned after.
+func diffAssignmentChanges(entries []types.AssignmentLogEntry) (added, removed []types.AssignmentLogEntry) {
+	type span struct {
+		before bool
+		last   types.AssignmentLogEntry
+	}
+	spans := make(map[string]*span)
+	var order []string
+	for _, e := range entries {
+		sp, ok := spans[e.TargetID]
+		if !ok {
+			sp = &span{before: e.Action == types.AssignmentChangeRemove}
+			spans[e.TargetID] = sp
+			order = append(order, e.TargetID)
+		}
+		sp.last = e
+	}
+
+	added, removed = []types.AssignmentLogEntry{}, []types.AssignmentLogEntry{}
+	for _, id := range order {
+		sp := spans[id]
+		after := sp.last.Action == types.AssignmentChangeAdd
+		switch {
+		case after && !sp.before:
+			added = append(added, sp.last)
+		case !after && sp.before:
+			removed = append(removed, sp.last)
+		}
+	}
+	return added, removed
+}
+
+// DiffAgentAssignments returns the net change in an agent's assignments
+// between two versions. A nil from defaults to the version the agent last
+// reported applying and a nil to to the current version, so with neither
+// it answers what the agent hasn't picked up yet, or has most recently.
+func (s *Service) DiffAgentAssignments(ctx context.Context, agentID string, from, to *int64) (*types.AssignmentDiff, error) {
+	agent, err := s.store.GetAgent(ctx, agentID)
+	if err != nil {
+		return nil, fromStore(err, "agent not found")
+	}
+	if agent == nil {
+		return nil, newError(ErrNotFound, nil, "agent not found")
+	}
+
+	diff := &types.AssignmentDiff{AgentID: agentID}
+	if to != nil {
+		diff.ToVersion = *to
+	} else if diff.ToVersion, err = s.store.GetAssignmentVersion(ctx); err != nil {
+		return nil, fmt.Errorf("getting assignment version: %w", err)
+	}
+	if from != nil {
+		diff.FromVersion = *from
+	} else {
+		if diff.FromVersion, err = s.store.GetReportedAssignmentVersion(ctx, agentID); err != nil {
+			return nil, err
+		}
+		diff.FromReported = true
+	}
+	if diff.FromVersion < 0 || diff.FromVersion > diff.ToVersion {
+		return nil, invalidInput("from must be between 0 and to (%d)", diff.ToVersion)
+	}
+
+	entries, err := s.store.ListAssignmentChanges(ctx, agentID, diff.FromVersion, diff.ToVersion)
+	if err != nil {
+		return nil, err
+	}
+	diff.Changes = len(entries)
+	diff.Added, diff.Removed = diffAssignmentChanges(entries)
+	return diff, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestDiffAssignmentChanges_NetChanges(t *testing.T) {
+	entry := func(version int64, target, action string) types.AssignmentLogEntry {
+		return types.AssignmentLogEntry{Version: version, TargetID: target, Action: action}
+	}
+	add, remove := types.AssignmentChangeAdd, types.AssignmentChangeRemove
+
+	tests := []struct {
+		name        string
+		entries     []types.AssignmentLogEntry
+		wantAdded   []string
+		wantRemoved []string
+	}{
+		{name: "no changes"},
+		{
+			name:        "simple add and remove",
+			entries:     []types.AssignmentLogEntry{entry(2, "a", add), entry(3, "b", remove)},
+			wantAdded:   []string{"a"},
+			wantRemoved: []string{"b"},
+		},
+		{
+			name:    "added then removed is no change",
+			entries: []types.AssignmentLogEntry{entry(2, "a", add), entry(4, "a", remove)},
+		},
+		{
+			name:    "removed then re-added is no change",
+			entries: []types.AssignmentLogEntry{entry(2, "a", remove), entry(4, "a", add)},
+		},
+		{
+			name:      "flapping ends assigned",
+			entries:   []types.AssignmentLogEntry{entry(2, "a", add), entry(3, "a", remove), entry(5, "a", add)},
+			wantAdded: []string{"a"},
+		},
+		{
+			name:        "order follows first change",
+			entries:     []types.AssignmentLogEntry{entry(2, "c", add), entry(3, "a", add), entry(4, "b", remove)},
+			wantAdded:   []string{"c", "a"},
+			wantRemoved: []string{"b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			added, removed := diffAssignmentChanges(tt.entries)
+			if got := targetIDs(added); !equalStrings(got, tt.wantAdded) {
+				t.Errorf("added = %v, want %v", got, tt.wantAdded)
+			}
+			if got := targetIDs(removed); !equalStrings(got, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", got, tt.wantRemoved)
+			}
+		})
+	}
+}
+
+func TestDiffAssignmentChanges_KeepsLastEntry(t *testing.T) {
+	added, _ := diffAssignmentChanges([]types.AssignmentLogEntry{
+		{Version: 2, TargetID: "a", Action: types.AssignmentChangeAdd, Tier: "standard"},
+		{Version: 3, TargetID: "a", Action: types.AssignmentChangeRemove, Tier: "standard"},
+		{Version: 7, TargetID: "a", Action: types.AssignmentChangeAdd, Tier: "vlan"},
+	})
+	if len(added) != 1 || added[0].Version != 7 || added[0].Tier != "vlan" {
+		t.Errorf("added = %+v, want the version 7 entry", added)
+	}
+}
+
+func targetIDs(entries []types.AssignmentLogEntry) []string {
+	var ids []string
+	for _, e := range entries {
+		ids = append(ids, e.TargetID)
+	}
+	return ids
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
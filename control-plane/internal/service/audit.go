@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// RecordAudit appends a management mutation to the audit log.
+func (s *Service) RecordAudit(ctx context.Context, e *types.AuditEntry) error {
+	return s.store.InsertAuditEntry(ctx, e)
+}
+
+// ListAuditEntries returns audit entries, newest first. The limit defaults
+// to DefaultPaginationLimit and is capped at MaxPaginationLimit.
+func (s *Service) ListAuditEntries(ctx context.Context, filter types.AuditFilter) ([]types.AuditEntry, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = config.DefaultPaginationLimit
+	}
+	filter.Limit = min(filter.Limit, config.MaxPaginationLimit)
+	return s.store.ListAuditEntries(ctx, filter)
+}
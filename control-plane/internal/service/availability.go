@@ -0,0 +1,153 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET AVAILABILITY
+// =============================================================================
+
+// availabilityWindow is a labelled SLI window.
+type availabilityWindow struct {
+	label    string
+	duration time.Duration
+}
+
+// availabilityWindows are the windows reported, shortest first. The last
+// is the longest and sets how much history is read.
+var availabilityWindows = []availabilityWindow{
+	{"1h", time.Hour},
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", 30 * 24 * time.Hour},
+}
+
+// windowTally accumulates one window's counts as hours are walked.
+type windowTally struct {
+	probes, successes, degraded                         int64
+	inMarketProbes, inMarketSuccesses, inMarketDegraded int64
+	healthyHours                                        int
+	failures                                            int
+	inFailure                                           bool
+}
+
+func (t *windowTally) add(h store.AvailabilityHour) {
+	t.probes += h.Probes
+	t.successes += h.Successes
+	t.degraded += h.Degraded
+	t.inMarketProbes += h.InMarketProbes
+	t.inMarketSuccesses += h.InMarketSuccesses
+	t.inMarketDegraded += h.InMarketDegraded
+
+	if h.Probes == 0 {
+		// In-market only hour; says nothing about overall health
+		return
+	}
+	// Degraded replies don't keep an hour healthy
+	failed := float64(h.Successes-h.Degraded)/float64(h.Probes) < config.AvailabilityFailedHourRatio
+	switch {
+	case failed && !t.inFailure:
+		t.failures++
+	case !failed:
+		t.healthyHours++
+	}
+	t.inFailure = failed
+}
+
+// summarizeAvailability computes every window in one pass over the hours,
+// which must be oldest first. end is the exclusive end of all windows.
+func summarizeAvailability(hours []store.AvailabilityHour, end time.Time) []types.AvailabilityWindow {
+	tallies := make([]windowTally, len(availabilityWindows))
+	for _, h := range hours {
+		for i, w := range availabilityWindows {
+			if !h.Bucket.Before(end.Add(-w.duration)) && h.Bucket.Before(end) {
+				tallies[i].add(h)
+			}
+		}
+	}
+
+	windows := make([]types.AvailabilityWindow, len(availabilityWindows))
+	for i, w := range availabilityWindows {
+		t := tallies[i]
+		windows[i] = types.AvailabilityWindow{
+			Window:                 w.label,
+			Start:                  end.Add(-w.duration),
+			End:                    end,
+			Probes:                 t.probes,
+			SuccessRatio:           ratio(float64(t.successes-t.degraded), t.probes),
+			ReachableRatio:         ratio(float64(t.successes), t.probes),
+			InMarketProbes:         t.inMarketProbes,
+			InMarketSuccessRatio:   ratio(float64(t.inMarketSuccesses-t.inMarketDegraded), t.inMarketProbes),
+			InMarketReachableRatio: ratio(float64(t.inMarketSuccesses), t.inMarketProbes),
+			Failures:               t.failures,
+		}
+		if t.failures > 0 {
+			windows[i].MTBFHours = ratio(float64(t.healthyHours)*config.AvailabilityBucket.Hours(), int64(t.failures))
+		}
+	}
+	return windows
+}
+
+// GetTargetAvailability returns a target's availability SLIs over each
+// standard window, ending at the last complete hour. When the target's
+// expected outcome (or its tier's default) uses SLO success criteria,
+// replies outside the SLO count against the success ratios; the reachable
+// ratios count every reply either way. Returns nil if the target does not
+// exist.
+func (s *Service) GetTargetAvailability(ctx context.Context, targetID string) (*types.TargetAvailability, error) {
+	target, err := s.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("getting target: %w", err)
+	}
+	if target == nil {
+		return nil, nil
+	}
+
+	outcome, err := s.effectiveOutcome(ctx, target)
+	if err != nil {
+		return nil, err
+	}
+	maxLatency, maxLoss := outcome.SLOThresholds()
+
+	now := time.Now()
+	end := now.Truncate(config.AvailabilityBucket)
+	longest := availabilityWindows[len(availabilityWindows)-1].duration
+	hours, err := s.store.GetTargetAvailabilityHours(ctx, target.ID, end.Add(-longest), end, maxLatency, maxLoss)
+	if err != nil {
+		return nil, fmt.Errorf("getting availability hours: %w", err)
+	}
+
+	criteria := types.SuccessCriteriaReachable
+	if outcome.UsesSLO() {
+		criteria = types.SuccessCriteriaSLO
+	}
+	return &types.TargetAvailability{
+		TargetID:        target.ID,
+		SuccessCriteria: criteria,
+		GeneratedAt:     now,
+		Windows:         summarizeAvailability(hours, end),
+	}, nil
+}
+
+// effectiveOutcome returns the target's expected outcome, falling back to
+// its tier's default. Either may be nil.
+func (s *Service) effectiveOutcome(ctx context.Context, target *types.Target) (*types.ExpectedOutcome, error) {
+	if target.ExpectedOutcome != nil {
+		return target.ExpectedOutcome, nil
+	}
+	tier, err := s.store.GetTier(ctx, target.Tier)
+	if err != nil {
+		return nil, fmt.Errorf("getting tier: %w", err)
+	}
+	if tier == nil {
+		return nil, nil
+	}
+	return tier.DefaultExpectedOutcome, nil
+}
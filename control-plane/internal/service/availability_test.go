@@ -0,0 +1,124 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+func TestSummarizeAvailability_Windows(t *testing.T) {
+	end := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	hour := func(ago int, probes, successes, inMarket, inMarketOK int64) store.AvailabilityHour {
+		return store.AvailabilityHour{
+			Bucket:            end.Add(-time.Duration(ago) * time.Hour),
+			Probes:            probes,
+			Successes:         successes,
+			InMarketProbes:    inMarket,
+			InMarketSuccesses: inMarketOK,
+		}
+	}
+
+	// Oldest first: a two-hour outage three days ago, a one-hour outage
+	// yesterday, otherwise healthy
+	hours := []store.AvailabilityHour{
+		hour(72, 100, 100, 0, 0),
+		hour(71, 100, 10, 0, 0),
+		hour(70, 100, 0, 0, 0),
+		hour(69, 100, 100, 0, 0),
+		hour(20, 100, 100, 50, 50),
+		hour(19, 100, 20, 50, 0),
+		hour(18, 100, 100, 50, 50),
+		hour(1, 100, 99, 50, 50),
+	}
+
+	windows := summarizeAvailability(hours, end)
+
+	tests := []struct {
+		window    string
+		probes    int64
+		success   float64
+		inMarket  float64
+		failures  int
+		mtbfHours float64 // 0 means nil
+	}{
+		{"1h", 100, 0.99, 1, 0, 0},
+		{"24h", 400, 319.0 / 400, 150.0 / 200, 1, 3},
+		{"7d", 800, 529.0 / 800, 150.0 / 200, 2, 2.5},
+		{"30d", 800, 529.0 / 800, 150.0 / 200, 2, 2.5},
+	}
+	if len(windows) != len(tests) {
+		t.Fatalf("got %d windows, want %d", len(windows), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.window, func(t *testing.T) {
+			w := windows[i]
+			if w.Window != tt.window {
+				t.Fatalf("window = %q, want %q", w.Window, tt.window)
+			}
+			if w.Probes != tt.probes {
+				t.Errorf("probes = %d, want %d", w.Probes, tt.probes)
+			}
+			if w.SuccessRatio == nil || !near(*w.SuccessRatio, tt.success) {
+				t.Errorf("success ratio = %v, want %v", w.SuccessRatio, tt.success)
+			}
+			if w.InMarketSuccessRatio == nil || !near(*w.InMarketSuccessRatio, tt.inMarket) {
+				t.Errorf("in-market success ratio = %v, want %v", w.InMarketSuccessRatio, tt.inMarket)
+			}
+			if w.Failures != tt.failures {
+				t.Errorf("failures = %d, want %d", w.Failures, tt.failures)
+			}
+			switch {
+			case tt.mtbfHours == 0 && w.MTBFHours != nil:
+				t.Errorf("mtbf = %v, want nil", *w.MTBFHours)
+			case tt.mtbfHours != 0 && (w.MTBFHours == nil || !near(*w.MTBFHours, tt.mtbfHours)):
+				t.Errorf("mtbf = %v, want %v", w.MTBFHours, tt.mtbfHours)
+			}
+		})
+	}
+}
+
+func TestSummarizeAvailability_NoData(t *testing.T) {
+	end := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	for _, w := range summarizeAvailability(nil, end) {
+		if w.SuccessRatio != nil || w.InMarketSuccessRatio != nil || w.MTBFHours != nil {
+			t.Errorf("%s: want nil SLIs without data, got %+v", w.Window, w)
+		}
+		if !w.End.Equal(end) {
+			t.Errorf("%s: end = %v, want %v", w.Window, w.End, end)
+		}
+	}
+}
+
+func TestSummarizeAvailability_SLODegraded(t *testing.T) {
+	end := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	// Every probe answered, but one hour's replies all missed the SLO
+	hours := []store.AvailabilityHour{
+		{Bucket: end.Add(-3 * time.Hour), Probes: 100, Successes: 100, InMarketProbes: 50, InMarketSuccesses: 50},
+		{Bucket: end.Add(-2 * time.Hour), Probes: 100, Successes: 100, Degraded: 100, InMarketProbes: 50, InMarketSuccesses: 50, InMarketDegraded: 50},
+		{Bucket: end.Add(-1 * time.Hour), Probes: 100, Successes: 100, Degraded: 5, InMarketProbes: 50, InMarketSuccesses: 50},
+	}
+
+	w := summarizeAvailability(hours, end)[1] // 24h
+	tests := []struct {
+		name string
+		got  *float64
+		want float64
+	}{
+		{"success", w.SuccessRatio, 195.0 / 300},
+		{"reachable", w.ReachableRatio, 1},
+		{"in-market success", w.InMarketSuccessRatio, 100.0 / 150},
+		{"in-market reachable", w.InMarketReachableRatio, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got == nil || !near(*tt.got, tt.want) {
+				t.Errorf("ratio = %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+	if w.Failures != 1 {
+		t.Errorf("failures = %d, want 1 for the degraded hour", w.Failures)
+	}
+}
@@ -0,0 +1,103 @@
+// Package service - Batch sequencing makes result ingestion idempotent.
+//
+// Agents number result batches with a per-agent sequence that only goes up.
+// When an agent retries a batch whose first attempt was stored but whose
+// response was lost, the retry carries a sequence at or below the highest one
+// already accepted; it is acknowledged without being inserted or run through
+// the state machine a second time. The mark is kept in the database, so a
+// retry is caught after a restart and when it reaches a different
+// control-plane instance from the original. Batches with sequence 0 come from
+// agents that predate sequencing and are always ingested.
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// BatchSequenceStore persists the highest accepted sequence per agent so
+// duplicates are recognised after a control-plane restart and across
+// instances. ClaimBatchSequence must check seq against the mark and advance
+// it atomically, committing the advance only if ingest succeeds.
+type BatchSequenceStore interface {
+	ClaimBatchSequence(ctx context.Context, agentID string, seq int64, ingest func() error) (duplicate bool, err error)
+}
+
+// batchSequencer serialises ingestion per agent and skips replayed batches.
+type batchSequencer struct {
+	store  BatchSequenceStore
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	agents map[string]*agentSequence
+}
+
+// agentSequence is the high-water mark this instance has seen for one agent.
+// It only moves up, so a sequence at or below it is a duplicate without
+// asking the store; anything above it may have been accepted by another
+// instance and is claimed through the store. Its mutex is held for the whole
+// ingest so batches from one agent don't queue on the agent's row.
+type agentSequence struct {
+	mu   sync.Mutex
+	last int64
+}
+
+func newBatchSequencer(store BatchSequenceStore, logger *slog.Logger) *batchSequencer {
+	return &batchSequencer{
+		store:  store,
+		logger: logger,
+		agents: make(map[string]*agentSequence),
+	}
+}
+
+// Ingest runs ingest for a batch unless seq has already been accepted from
+// the agent, in which case it reports a duplicate. The sequence only advances
+// after ingest succeeds, so a failed batch can be retried under the same
+// sequence.
+func (b *batchSequencer) Ingest(ctx context.Context, agentID string, seq int64, ingest func() error) (duplicate bool, err error) {
+	if seq <= 0 {
+		return false, ingest()
+	}
+
+	b.mu.Lock()
+	as, ok := b.agents[agentID]
+	if !ok {
+		as = &agentSequence{}
+		b.agents[agentID] = as
+	}
+	b.mu.Unlock()
+
+	as.mu.Lock()
+	defer as.mu.Unlock()
+
+	if seq <= as.last {
+		return true, nil
+	}
+
+	ingested := false
+	duplicate, err = b.store.ClaimBatchSequence(ctx, agentID, seq, func() error {
+		if err := ingest(); err != nil {
+			return err
+		}
+		ingested = true
+		return nil
+	})
+	if ingested {
+		// The batch is stored, so report success even if the sequence can't
+		// be persisted; the in-memory mark still catches retries that reach
+		// this instance.
+		as.last = seq
+		if err != nil {
+			b.logger.Warn("failed to persist batch sequence", "agent", agentID, "sequence", seq, "error", err)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if duplicate {
+		as.last = seq
+	}
+	return duplicate, nil
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TLS CERTIFICATES
+// =============================================================================
+
+// certificatesFromResults picks the latest certificate reported for each
+// target in a batch of results. Failed handshakes carry no certificate and
+// are skipped, as are other probe types.
+func certificatesFromResults(results []types.ProbeResult) []types.TargetCertificate {
+	latest := make(map[string]types.TargetCertificate)
+	for _, r := range results {
+		if r.ProbeType != types.ProbeTypeTLSCert || !r.Success {
+			continue
+		}
+		var p types.TLSCertPayload
+		if err := json.Unmarshal(r.Payload, &p); err != nil || p.NotAfter.IsZero() {
+			continue
+		}
+		if prev, ok := latest[r.TargetID]; ok && prev.ObservedAt.After(r.Timestamp) {
+			continue
+		}
+		latest[r.TargetID] = types.TargetCertificate{
+			TargetID:     r.TargetID,
+			AgentID:      r.AgentID,
+			Port:         p.Port,
+			ServerName:   p.ServerName,
+			Subject:      p.Subject,
+			Issuer:       p.Issuer,
+			DNSNames:     p.DNSNames,
+			SerialNumber: p.SerialNumber,
+			NotBefore:    p.NotBefore,
+			NotAfter:     p.NotAfter,
+			ChainValid:   p.ChainValid,
+			VerifyError:  p.VerifyError,
+			ObservedAt:   r.Timestamp,
+		}
+	}
+
+	certs := make([]types.TargetCertificate, 0, len(latest))
+	for _, c := range latest {
+		certs = append(certs, c)
+	}
+	sort.Slice(certs, func(i, j int) bool { return certs[i].TargetID < certs[j].TargetID })
+	return certs
+}
+
+// recordCertificates stores the certificates in a batch of results.
+func (s *Service) recordCertificates(ctx context.Context, results []types.ProbeResult) {
+	certs := certificatesFromResults(results)
+	if len(certs) == 0 {
+		return
+	}
+	if err := s.store.UpsertTargetCertificates(ctx, certs); err != nil {
+		s.logger.Error("failed to record certificates", "count", len(certs), "error", err)
+	}
+}
+
+// ListCertificates returns the latest certificate of each tls_cert target,
+// soonest expiry first. withinDays, if set, keeps those expiring within that
+// many days, expired ones included.
+func (s *Service) ListCertificates(ctx context.Context, withinDays *int, limit int) ([]types.TargetCertificate, error) {
+	if withinDays != nil && *withinDays < 0 {
+		return nil, invalidInput("within_days must not be negative")
+	}
+	if limit <= 0 {
+		limit = config.CertListDefaultLimit
+	}
+	return s.store.ListTargetCertificates(ctx, types.CertificateFilter{
+		WithinDays: withinDays,
+		Limit:      min(limit, config.CertListMaxLimit),
+	})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestCertificatesFromResults_LatestPerTarget(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	expiry := base.Add(20 * 24 * time.Hour)
+	certPayload := func(serial string) json.RawMessage {
+		raw, _ := json.Marshal(types.TLSCertPayload{Port: 443, SerialNumber: serial, NotAfter: expiry, ChainValid: true})
+		return raw
+	}
+
+	tests := []struct {
+		name       string
+		results    []types.ProbeResult
+		wantSerial map[string]string
+	}{
+		{
+			name: "newest result wins regardless of order",
+			results: []types.ProbeResult{
+				{TargetID: "t1", AgentID: "a1", ProbeType: types.ProbeTypeTLSCert, Success: true, Timestamp: base.Add(time.Minute), Payload: certPayload("new")},
+				{TargetID: "t1", AgentID: "a2", ProbeType: types.ProbeTypeTLSCert, Success: true, Timestamp: base, Payload: certPayload("old")},
+			},
+			wantSerial: map[string]string{"t1": "new"},
+		},
+		{
+			name: "failed handshakes and other probe types skipped",
+			results: []types.ProbeResult{
+				{TargetID: "t1", ProbeType: types.ProbeTypeTLSCert, Success: false, Timestamp: base, Payload: json.RawMessage(`{"port": 443}`)},
+				{TargetID: "t2", ProbeType: types.DefaultProbeType, Success: true, Timestamp: base, Payload: json.RawMessage(`{"avg_ms": 1}`)},
+				{TargetID: "t3", ProbeType: types.ProbeTypeTLSCert, Success: true, Timestamp: base, Payload: certPayload("t3")},
+			},
+			wantSerial: map[string]string{"t3": "t3"},
+		},
+		{
+			name: "payload without expiry or undecodable skipped",
+			results: []types.ProbeResult{
+				{TargetID: "t1", ProbeType: types.ProbeTypeTLSCert, Success: true, Timestamp: base, Payload: json.RawMessage(`{"port": 443}`)},
+				{TargetID: "t2", ProbeType: types.ProbeTypeTLSCert, Success: true, Timestamp: base, Payload: json.RawMessage(`not json`)},
+			},
+			wantSerial: map[string]string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			certs := certificatesFromResults(tt.results)
+			if len(certs) != len(tt.wantSerial) {
+				t.Fatalf("got %d certificates, want %d: %+v", len(certs), len(tt.wantSerial), certs)
+			}
+			for _, c := range certs {
+				if want := tt.wantSerial[c.TargetID]; c.SerialNumber != want {
+					t.Errorf("target %s serial = %q, want %q", c.TargetID, c.SerialNumber, want)
+				}
+				if !c.NotAfter.Equal(expiry) {
+					t.Errorf("target %s NotAfter = %v, want %v", c.TargetID, c.NotAfter, expiry)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// SUBNET COVERAGE GAPS
+// =============================================================================
+
+// coverageStatus classifies a target from its reporting agent counts.
+func coverageStatus(c types.TargetCoverage) string {
+	switch {
+	case c.ReportingAgents == 0:
+		return types.CoverageStatusNoCoverage
+	case c.ReportingInMarketAgents > 0:
+		return types.CoverageStatusCovered
+	case c.Region == nil:
+		return types.CoverageStatusUnknownMarket
+	default:
+		return types.CoverageStatusInMarketGap
+	}
+}
+
+// summarizeCoverage sets each target's status and tallies the subnet totals.
+func summarizeCoverage(subnetID string, targets []types.TargetCoverage) *types.SubnetCoverage {
+	sc := &types.SubnetCoverage{
+		SubnetID:            subnetID,
+		ActiveTargets:       len(targets),
+		InMarketCoveragePct: 100,
+		Targets:             make([]types.TargetCoverage, len(targets)),
+	}
+	for i, t := range targets {
+		t.Status = coverageStatus(t)
+		switch t.Status {
+		case types.CoverageStatusCovered:
+			sc.Covered++
+		case types.CoverageStatusInMarketGap:
+			sc.InMarketGaps++
+		case types.CoverageStatusUnknownMarket:
+			sc.UnknownMarket++
+		case types.CoverageStatusNoCoverage:
+			sc.CoverageGaps++
+		}
+		sc.Targets[i] = t
+	}
+	if sc.ActiveTargets > 0 {
+		sc.InMarketCoveragePct = float64(sc.Covered) / float64(sc.ActiveTargets) * 100
+	}
+	return sc
+}
+
+// GetSubnetCoverageGaps reports which of a subnet's active targets have no
+// reporting agent or no in-market one. Returns nil if the subnet does not
+// exist.
+func (s *Service) GetSubnetCoverageGaps(ctx context.Context, subnetID string) (*types.SubnetCoverage, error) {
+	subnet, err := s.store.GetSubnet(ctx, subnetID)
+	if err != nil {
+		return nil, fmt.Errorf("getting subnet: %w", err)
+	}
+	if subnet == nil {
+		return nil, nil
+	}
+
+	targets, err := s.store.GetSubnetTargetCoverage(ctx, subnetID, config.CoverageReportingWindow)
+	if err != nil {
+		return nil, fmt.Errorf("getting target coverage: %w", err)
+	}
+
+	sc := summarizeCoverage(subnetID, targets)
+	sc.GeneratedAt = time.Now()
+	sc.Window = config.CoverageReportingWindow.String()
+	return sc, nil
+}
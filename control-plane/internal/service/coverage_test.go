@@ -0,0 +1,62 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestSummarizeCoverage_Statuses(t *testing.T) {
+	region := "chicago"
+	tests := []struct {
+		name       string
+		targets    []types.TargetCoverage
+		wantStatus []string
+		wantPct    float64
+	}{
+		{
+			name:    "empty subnet",
+			wantPct: 100,
+		},
+		{
+			name: "mixed",
+			targets: []types.TargetCoverage{
+				{Region: &region, ReportingAgents: 3, ReportingInMarketAgents: 1},
+				{Region: &region, ReportingAgents: 2},
+				{Region: &region, AssignedAgents: 2},
+				{ReportingAgents: 4},
+			},
+			wantStatus: []string{
+				types.CoverageStatusCovered,
+				types.CoverageStatusInMarketGap,
+				types.CoverageStatusNoCoverage,
+				types.CoverageStatusUnknownMarket,
+			},
+			wantPct: 25,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := summarizeCoverage("s1", tt.targets)
+			if len(sc.Targets) != len(tt.wantStatus) {
+				t.Fatalf("got %d targets, want %d", len(sc.Targets), len(tt.wantStatus))
+			}
+			counts := map[string]int{}
+			for i, tc := range sc.Targets {
+				if tc.Status != tt.wantStatus[i] {
+					t.Errorf("target %d status = %s, want %s", i, tc.Status, tt.wantStatus[i])
+				}
+				counts[tc.Status]++
+			}
+			if sc.Covered != counts[types.CoverageStatusCovered] ||
+				sc.InMarketGaps != counts[types.CoverageStatusInMarketGap] ||
+				sc.CoverageGaps != counts[types.CoverageStatusNoCoverage] ||
+				sc.UnknownMarket != counts[types.CoverageStatusUnknownMarket] {
+				t.Errorf("summary %+v does not match statuses %v", sc, counts)
+			}
+			if sc.InMarketCoveragePct != tt.wantPct {
+				t.Errorf("in-market coverage = %v, want %v", sc.InMarketCoveragePct, tt.wantPct)
+			}
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET DIAGNOSTICS
+// =============================================================================
+
+// Diagnostics bundle sections, as named in TargetDiagnostics.Errors.
+const (
+	diagSectionStatus    = "status"
+	diagSectionAgents    = "agents"
+	diagSectionHistory   = "history"
+	diagSectionAlerts    = "active_alerts"
+	diagSectionBaselines = "baselines"
+	diagSectionCommands  = "recent_commands"
+	diagSectionSubnet    = "subnet"
+)
+
+// TargetDiagnostics is everything on-call needs to triage a target, in one
+// response. A section that failed or timed out is left empty and its error
+// is reported in Errors, so one slow query doesn't sink the bundle.
+type TargetDiagnostics struct {
+	Target *types.Target `json:"target"`
+
+	Status         *store.TargetStatus         `json:"status,omitempty"`
+	Agents         []store.LiveProbeResult     `json:"agents"`  // Latest result from each agent
+	History        []store.ProbeHistoryPoint   `json:"history"` // Last DiagnosticsHistoryWindow
+	ActiveAlerts   []types.Alert               `json:"active_alerts"`
+	Baselines      []store.AgentTargetBaseline `json:"baselines"`
+	RecentCommands []store.CommandWithResults  `json:"recent_commands"`
+	Subnet         *SubnetNeighbors            `json:"subnet,omitempty"` // Set when the target is in a subnet
+
+	Errors      map[string]string `json:"errors,omitempty"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
+
+// SubnetNeighbors summarizes the target's subnet: the subnet and how many of
+// its targets are in each monitoring state.
+type SubnetNeighbors struct {
+	Subnet      *types.Subnet  `json:"subnet"`
+	StateCounts map[string]int `json:"state_counts"`
+}
+
+// GetTargetDiagnostics assembles a triage bundle for a target, running the
+// sections concurrently with each bounded by DiagnosticsSectionTimeout.
+func (s *Service) GetTargetDiagnostics(ctx context.Context, targetID string) (*TargetDiagnostics, error) {
+	target, err := s.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return nil, fromStore(err, "")
+	}
+	if target == nil {
+		return nil, fmt.Errorf("target %w: %s", ErrNotFound, targetID)
+	}
+
+	d := &TargetDiagnostics{Target: target, GeneratedAt: time.Now()}
+	activeStatus := types.AlertStatusActive
+	sections := map[string]func(context.Context) error{
+		diagSectionStatus: func(ctx context.Context) (err error) {
+			d.Status, err = s.GetTargetStatus(ctx, targetID)
+			return err
+		},
+		diagSectionAgents: func(ctx context.Context) (err error) {
+			d.Agents, err = s.GetTargetLiveResults(ctx, targetID, int(config.LiveResultsMaxWindow.Seconds()), 1)
+			return err
+		},
+		diagSectionHistory: func(ctx context.Context) (err error) {
+			d.History, err = s.GetTargetHistory(ctx, targetID, config.DiagnosticsHistoryWindow)
+			return err
+		},
+		diagSectionAlerts: func(ctx context.Context) (err error) {
+			d.ActiveAlerts, err = s.ListAlerts(ctx, types.AlertFilter{
+				TargetID: &targetID,
+				Status:   &activeStatus,
+				Limit:    config.DiagnosticsAlertLimit,
+			})
+			return err
+		},
+		diagSectionBaselines: func(ctx context.Context) (err error) {
+			d.Baselines, err = s.GetBaselinesForTarget(ctx, targetID)
+			return err
+		},
+		diagSectionCommands: func(ctx context.Context) (err error) {
+			d.RecentCommands, err = s.GetCommandsByTarget(ctx, targetID, config.DiagnosticsCommandLimit)
+			return err
+		},
+	}
+	if target.SubnetID != nil {
+		subnetID := *target.SubnetID
+		sections[diagSectionSubnet] = func(ctx context.Context) error {
+			subnet, err := s.GetSubnet(ctx, subnetID)
+			if err != nil || subnet == nil {
+				return err
+			}
+			counts, err := s.GetSubnetTargetCounts(ctx, subnetID)
+			if err != nil {
+				return err
+			}
+			d.Subnet = &SubnetNeighbors{Subnet: subnet, StateCounts: counts}
+			return nil
+		}
+	}
+
+	d.Errors = runSections(ctx, config.DiagnosticsSectionTimeout, sections)
+	for name, msg := range d.Errors {
+		s.logger.Warn("diagnostics section failed", "target", targetID, "section", name, "error", msg)
+	}
+	return d, nil
+}
+
+// runSections runs each section concurrently under its own timeout and
+// returns the errors of those that failed, keyed by section name, or nil if
+// all succeeded. A section that overruns is abandoned via its context.
+func runSections(ctx context.Context, timeout time.Duration, sections map[string]func(context.Context) error) map[string]string {
+	var (
+		g    errgroup.Group
+		mu   sync.Mutex
+		errs map[string]string
+	)
+	for name, fn := range sections {
+		g.Go(func() error {
+			sctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			if err := fn(sctx); err != nil {
+				mu.Lock()
+				if errs == nil {
+					errs = make(map[string]string)
+				}
+				errs[name] = err.Error()
+				mu.Unlock()
+			}
+			return nil // a failed section degrades the bundle, not the call
+		})
+	}
+	g.Wait()
+	return errs
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestRunSections_Degrades(t *testing.T) {
+	ok := func(context.Context) error { return nil }
+	failing := func(context.Context) error { return errors.New("query failed") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name     string
+		sections map[string]func(context.Context) error
+		wantErrs []string
+	}{
+		{"all succeed", map[string]func(context.Context) error{"status": ok, "history": ok}, nil},
+		{"one fails", map[string]func(context.Context) error{"status": ok, "alerts": failing}, []string{"alerts"}},
+		{"one times out", map[string]func(context.Context) error{"status": ok, "history": slow}, []string{"history"}},
+		{"fail and timeout", map[string]func(context.Context) error{"alerts": failing, "history": slow, "status": ok}, []string{"alerts", "history"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := runSections(context.Background(), 20*time.Millisecond, tt.sections)
+			got := slices.Sorted(maps.Keys(errs))
+			if !slices.Equal(got, tt.wantErrs) {
+				t.Fatalf("failed sections = %v, want %v", got, tt.wantErrs)
+			}
+		})
+	}
+}
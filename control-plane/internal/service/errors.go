@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// =============================================================================
+// DOMAIN ERRORS
+// =============================================================================
+//
+// Service methods return errors that match one of these kinds with errors.Is,
+// so the API can pick a status code and a stable error code without reading
+// message text. Errors that match none of them are internal failures.
+
+var (
+	// ErrNotFound means the entity being read or changed doesn't exist.
+	ErrNotFound = store.ErrNotFound
+
+	// ErrConflict means the request clashes with existing state.
+	ErrConflict = store.ErrConflict
+
+	// ErrInvalidInput means the request itself is malformed or out of range.
+	ErrInvalidInput = errors.New("invalid input")
+
+	// ErrInvalidCursor means a pagination cursor couldn't be decoded.
+	ErrInvalidCursor = store.ErrInvalidCursor
+
+	// ErrRateLimited means the caller must wait for capacity, such as a free
+	// live stream slot, before retrying.
+	ErrRateLimited = errors.New("rate limited")
+
+	// ErrTierInUse means a tier can't be deleted while targets use it.
+	ErrTierInUse = fmt.Errorf("tier in use: %w", ErrConflict)
+)
+
+// Error is a domain error with a client-facing message and optional
+// structured details. Kind is one of the sentinels above.
+type Error struct {
+	Kind    error
+	Message string
+	Details map[string]any
+}
+
+func (e *Error) Error() string { return e.Message }
+
+func (e *Error) Unwrap() error { return e.Kind }
+
+// newError builds an Error of the given kind with a formatted message.
+func newError(kind error, details map[string]any, format string, args ...any) *Error {
+	return &Error{Kind: kind, Message: fmt.Sprintf(format, args...), Details: details}
+}
+
+// invalidInput is shorthand for an ErrInvalidInput error without details.
+func invalidInput(format string, args ...any) *Error {
+	return newError(ErrInvalidInput, nil, format, args...)
+}
+
+// fromStore converts Postgres input and reference errors into domain errors:
+// an unparseable value (e.g. a malformed UUID) becomes ErrInvalidInput, and a
+// foreign key violation becomes ErrNotFound with the message missing. Other
+// errors are returned unchanged.
+func fromStore(err error, missing string) error {
+	if err == nil {
+		return nil
+	}
+	if msg := store.InvalidInputMessage(err); msg != "" {
+		return invalidInput("%s", msg)
+	}
+	if store.IsForeignKeyViolation(err) {
+		return newError(ErrNotFound, nil, "%s", missing)
+	}
+	return err
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestFromStore_Kinds(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		wantKind error
+		wantMsg  string
+	}{
+		{
+			name:     "malformed uuid",
+			err:      fmt.Errorf("deleting: %w", &pgconn.PgError{Code: "22P02", Message: `invalid input syntax for type uuid: "x"`}),
+			wantKind: ErrInvalidInput,
+			wantMsg:  `invalid input syntax for type uuid: "x"`,
+		},
+		{
+			name:     "foreign key violation",
+			err:      &pgconn.PgError{Code: "23503", Message: "violates foreign key constraint"},
+			wantKind: ErrNotFound,
+			wantMsg:  "target not found",
+		},
+		{
+			name:     "store not found passes through",
+			err:      fmt.Errorf("annotation %w", ErrNotFound),
+			wantKind: ErrNotFound,
+			wantMsg:  "annotation not found",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fromStore(tt.err, "target not found")
+			if !errors.Is(got, tt.wantKind) {
+				t.Errorf("fromStore() = %v, want kind %v", got, tt.wantKind)
+			}
+			if got.Error() != tt.wantMsg {
+				t.Errorf("fromStore() message = %q, want %q", got.Error(), tt.wantMsg)
+			}
+		})
+	}
+
+	if fromStore(nil, "") != nil {
+		t.Error("fromStore(nil) should be nil")
+	}
+	internal := errors.New("connection refused")
+	if got := fromStore(internal, ""); got != internal {
+		t.Errorf("fromStore() = %v, want unchanged internal error", got)
+	}
+}
+
+func TestError_Kinds(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want []error
+	}{
+		{"tier in use is a conflict", newError(ErrTierInUse, nil, "in use"), []error{ErrTierInUse, ErrConflict}},
+		{"invalid input", invalidInput("bad window"), []error{ErrInvalidInput}},
+		{"wrapped", fmt.Errorf("outer: %w", newError(ErrNotFound, nil, "gone")), []error{ErrNotFound}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, kind := range tt.want {
+				if !errors.Is(tt.err, kind) {
+					t.Errorf("errors.Is(%v, %v) = false", tt.err, kind)
+				}
+			}
+			if errors.Is(tt.err, ErrInvalidCursor) {
+				t.Errorf("errors.Is(%v, ErrInvalidCursor) = true", tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ALERT ESCALATION POLICIES
+// =============================================================================
+
+// ListEscalationPolicies returns all escalation policies by name.
+func (s *Service) ListEscalationPolicies(ctx context.Context) ([]types.EscalationPolicy, error) {
+	return s.store.ListEscalationPolicies(ctx, false)
+}
+
+// GetEscalationPolicy retrieves an escalation policy by ID.
+func (s *Service) GetEscalationPolicy(ctx context.Context, id string) (*types.EscalationPolicy, error) {
+	p, err := s.store.GetEscalationPolicy(ctx, id)
+	return p, fromStore(err, "")
+}
+
+// CreateEscalationPolicy validates and stores a policy. The alert worker
+// applies it from its next cycle, to alerts already open as well.
+func (s *Service) CreateEscalationPolicy(ctx context.Context, p *types.EscalationPolicy) error {
+	if err := p.Validate(); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := s.store.CreateEscalationPolicy(ctx, p); err != nil {
+		return fromStore(err, "")
+	}
+	s.logger.Info("escalation policy created", "policy_id", p.ID, "name", p.Name, "steps", len(p.Steps))
+	return nil
+}
+
+// UpdateEscalationPolicy validates and saves changes to a policy.
+func (s *Service) UpdateEscalationPolicy(ctx context.Context, p *types.EscalationPolicy) error {
+	if err := p.Validate(); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := s.store.UpdateEscalationPolicy(ctx, p); err != nil {
+		return fromStore(err, "")
+	}
+	s.logger.Info("escalation policy updated", "policy_id", p.ID, "name", p.Name, "enabled", p.Enabled)
+	return nil
+}
+
+// DeleteEscalationPolicy removes a policy.
+func (s *Service) DeleteEscalationPolicy(ctx context.Context, id string) error {
+	return fromStore(s.store.DeleteEscalationPolicy(ctx, id), "")
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// EVENT STREAM
+// =============================================================================
+
+// parseEventTypes splits a comma-separated event type filter, rejecting
+// unknown types. Empty means every type.
+func parseEventTypes(raw string) ([]string, error) {
+	var eventTypes []string
+	for _, t := range strings.Split(raw, ",") {
+		t = strings.TrimSpace(t)
+		if t == "" {
+			continue
+		}
+		if !slices.Contains(types.EventTypes, t) {
+			return nil, invalidInput("unknown event type %q", t)
+		}
+		eventTypes = append(eventTypes, t)
+	}
+	return eventTypes, nil
+}
+
+// clampEventLimit applies the default and maximum page size for pulls.
+func clampEventLimit(limit int) int {
+	if limit <= 0 {
+		return config.EventsDefaultLimit
+	}
+	return min(limit, config.EventsMaxLimit)
+}
+
+// ListEvents returns events after since, oldest first, for pull consumers.
+// typeFilter is a comma-separated list of event types; empty means all.
+// Events appear once the dispatcher has sequenced them, a moment after
+// their change commits.
+func (s *Service) ListEvents(ctx context.Context, since int64, typeFilter string, limit int) ([]types.Event, error) {
+	if since < 0 {
+		return nil, invalidInput("since must not be negative")
+	}
+	eventTypes, err := parseEventTypes(typeFilter)
+	if err != nil {
+		return nil, err
+	}
+	return s.store.ListEvents(ctx, since, eventTypes, clampEventLimit(limit))
+}
+
+// ListEventConsumers returns every push consumer with its offset and
+// delivery state.
+func (s *Service) ListEventConsumers(ctx context.Context) ([]types.EventConsumer, error) {
+	return s.store.ListEventConsumers(ctx, false)
+}
+
+// GetEventConsumer retrieves a push consumer by name.
+func (s *Service) GetEventConsumer(ctx context.Context, name string) (*types.EventConsumer, error) {
+	return s.store.GetEventConsumer(ctx, name)
+}
+
+// CreateEventConsumer validates and stores a push consumer. It starts after
+// startSeq, or after the newest event when nil, so a new integration isn't
+// handed the whole retained history unless it asks for it.
+func (s *Service) CreateEventConsumer(ctx context.Context, c *types.EventConsumer, startSeq *int64) error {
+	if err := c.Validate(); err != nil {
+		return invalidInput("%s", err)
+	}
+	if startSeq != nil {
+		if *startSeq < 0 {
+			return invalidInput("start_seq must not be negative")
+		}
+		c.LastSeq = *startSeq
+	} else {
+		latest, err := s.store.LatestEventSeq(ctx)
+		if err != nil {
+			return err
+		}
+		c.LastSeq = latest
+	}
+	if err := s.store.CreateEventConsumer(ctx, c); err != nil {
+		return fromStore(err, "")
+	}
+	s.logger.Info("event consumer created", "name", c.Name, "kind", c.Kind, "last_seq", c.LastSeq)
+	return nil
+}
+
+// EnsureEventConsumer creates a consumer configured outside the API, such as
+// the Kafka events feed, unless one with its name already exists. An
+// existing consumer pointed elsewhere is an error rather than silently
+// redirected, since its offset belongs to the old destination.
+func (s *Service) EnsureEventConsumer(ctx context.Context, c *types.EventConsumer) error {
+	existing, err := s.store.GetEventConsumer(ctx, c.Name)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return s.CreateEventConsumer(ctx, c, nil)
+	}
+	if existing.Kind != c.Kind || existing.URL != c.URL {
+		return invalidInput("event consumer %q already exists for %s %s", c.Name, existing.Kind, existing.URL)
+	}
+	*c = *existing
+	return nil
+}
+
+// DeleteEventConsumer removes a push consumer.
+func (s *Service) DeleteEventConsumer(ctx context.Context, name string) error {
+	return fromStore(s.store.DeleteEventConsumer(ctx, name), "")
+}
+
+// SeekEventConsumer moves a consumer's offset so delivery resumes after seq,
+// replaying events it already received or skipping ones it doesn't want.
+func (s *Service) SeekEventConsumer(ctx context.Context, name string, seq int64) error {
+	if seq < 0 {
+		return invalidInput("seq must not be negative")
+	}
+	if err := s.store.SeekEventConsumer(ctx, name, seq); err != nil {
+		return fromStore(err, "")
+	}
+	s.logger.Info("event consumer offset moved", "name", name, "last_seq", seq)
+	return nil
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestParseEventTypes_Filter(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    []string
+		wantErr bool
+	}{
+		{"empty means all", "", nil, false},
+		{"single", "alert.created", []string{types.EventAlertCreated}, false},
+		{"list with spaces", " alert.created , incident.resolved ,", []string{types.EventAlertCreated, types.EventIncidentResolved}, false},
+		{"unknown type", "alert.created,alert.deleted", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseEventTypes(tt.raw)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("parseEventTypes(%q) error = %v, want ErrInvalidInput", tt.raw, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseEventTypes(%q) error = %v", tt.raw, err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parseEventTypes(%q) = %v, want %v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClampEventLimit_Bounds(t *testing.T) {
+	tests := []struct {
+		name  string
+		limit int
+		want  int
+	}{
+		{"unset uses default", 0, config.EventsDefaultLimit},
+		{"within range", 10, 10},
+		{"capped", config.EventsMaxLimit + 1, config.EventsMaxLimit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampEventLimit(tt.limit); got != tt.want {
+				t.Errorf("clampEventLimit(%d) = %d, want %d", tt.limit, got, tt.want)
+			}
+		})
+	}
+}
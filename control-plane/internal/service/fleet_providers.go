@@ -0,0 +1,104 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// PER-PROVIDER FLEET HEALTH
+// =============================================================================
+
+// providerTotals accumulates agent sums for one provider.
+type providerTotals struct {
+	health            types.ProviderHealth
+	sampled, expected int64
+	cpuSum, memSum    float64
+	cpuN, memN        int64
+	successes         int64
+	latSum, lossSum   float64
+	latN, lossN       int64
+}
+
+// rollupProviders groups agent stats by provider, sorted by provider name.
+// Each agent's sampled buckets are capped at its expected ones: the window
+// start rarely falls on a bucket boundary, so one extra bucket can be seen.
+func rollupProviders(stats []store.ProviderAgentStats) []types.ProviderHealth {
+	byProvider := make(map[string]*providerTotals)
+	for _, a := range stats {
+		name := types.ProviderUnknown
+		if a.Provider != nil {
+			name = *a.Provider
+		}
+		t, ok := byProvider[name]
+		if !ok {
+			t = &providerTotals{health: types.ProviderHealth{Provider: name}}
+			byProvider[name] = t
+		}
+
+		t.health.Agents++
+		if a.Online {
+			t.health.OnlineAgents++
+		}
+		t.sampled += min(a.SampledBuckets, a.ExpectedBuckets)
+		t.expected += a.ExpectedBuckets
+		t.cpuSum += a.CPUSum
+		t.cpuN += a.CPUCount
+		t.memSum += a.MemorySum
+		t.memN += a.MemoryCount
+		t.health.ProbeCount += a.ProbeCount
+		t.successes += a.SuccessCount
+		t.latSum += a.LatencySum
+		t.latN += a.LatencyWeight
+		t.lossSum += a.LossSum
+		t.lossN += a.LossWeight
+	}
+
+	providers := make([]types.ProviderHealth, 0, len(byProvider))
+	for _, t := range byProvider {
+		h := t.health
+		h.UptimePct = ratio(float64(t.sampled)*100, t.expected)
+		h.AvgCPUPercent = ratio(t.cpuSum, t.cpuN)
+		h.AvgMemoryMB = ratio(t.memSum, t.memN)
+		h.SuccessRatePct = ratio(float64(t.successes)*100, h.ProbeCount)
+		h.AvgLatencyMs = ratio(t.latSum, t.latN)
+		h.AvgPacketLossPct = ratio(t.lossSum, t.lossN)
+		providers = append(providers, h)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i].Provider < providers[j].Provider })
+	return providers
+}
+
+// ratio returns sum/n, or nil when there is nothing to average.
+func ratio(sum float64, n int64) *float64 {
+	if n == 0 {
+		return nil
+	}
+	v := sum / float64(n)
+	return &v
+}
+
+// GetProviderHealth compares agent reliability and probe performance by
+// hosting provider over window.
+func (s *Service) GetProviderHealth(ctx context.Context, window time.Duration) (*types.ProviderHealthReport, error) {
+	if window < config.ProviderHealthMinWindow || window > config.ProviderHealthMaxWindow {
+		return nil, invalidInput("window must be between %s and %s", config.ProviderHealthMinWindow, config.ProviderHealthMaxWindow)
+	}
+
+	stats, err := s.store.GetProviderAgentStats(ctx, window, config.AgentUptimeBucket, config.AgentOfflineThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("getting provider agent stats: %w", err)
+	}
+
+	return &types.ProviderHealthReport{
+		GeneratedAt: time.Now(),
+		Window:      window.String(),
+		Providers:   rollupProviders(stats),
+	}, nil
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestRollupProviders_Grouping(t *testing.T) {
+	aws, vultr, blank := "aws", "vultr", (*string)(nil)
+	provider := func(s string) *string { return &s }
+
+	tests := []struct {
+		name  string
+		stats []store.ProviderAgentStats
+		want  []types.ProviderHealth
+	}{
+		{
+			name: "empty fleet",
+			want: []types.ProviderHealth{},
+		},
+		{
+			name: "sums weighted across agents",
+			stats: []store.ProviderAgentStats{
+				{Provider: provider(vultr), Online: true, SampledBuckets: 60, ExpectedBuckets: 60,
+					CPUSum: 100, CPUCount: 10, MemorySum: 500, MemoryCount: 10,
+					ProbeCount: 100, SuccessCount: 90, LatencySum: 900, LatencyWeight: 90, LossSum: 1000, LossWeight: 100},
+				{Provider: provider(aws), Online: true, SampledBuckets: 61, ExpectedBuckets: 60,
+					CPUSum: 30, CPUCount: 10, MemorySum: 1000, MemoryCount: 10,
+					ProbeCount: 300, SuccessCount: 300, LatencySum: 6000, LatencyWeight: 300, LossWeight: 300},
+				{Provider: provider(aws), SampledBuckets: 30, ExpectedBuckets: 60,
+					CPUSum: 50, CPUCount: 10, MemorySum: 1000, MemoryCount: 10,
+					ProbeCount: 100, SuccessCount: 50, LatencySum: 2000, LatencyWeight: 50, LossSum: 5000, LossWeight: 100},
+			},
+			want: []types.ProviderHealth{
+				{Provider: aws, Agents: 2, OnlineAgents: 1,
+					UptimePct: ptr(75), AvgCPUPercent: ptr(4), AvgMemoryMB: ptr(100),
+					ProbeCount: 400, SuccessRatePct: ptr(87.5), AvgLatencyMs: ptr(22.857142857142858), AvgPacketLossPct: ptr(12.5)},
+				{Provider: vultr, Agents: 1, OnlineAgents: 1,
+					UptimePct: ptr(100), AvgCPUPercent: ptr(10), AvgMemoryMB: ptr(50),
+					ProbeCount: 100, SuccessRatePct: ptr(90), AvgLatencyMs: ptr(10), AvgPacketLossPct: ptr(10)},
+			},
+		},
+		{
+			name:  "no provider and no data",
+			stats: []store.ProviderAgentStats{{Provider: blank}},
+			want:  []types.ProviderHealth{{Provider: types.ProviderUnknown, Agents: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rollupProviders(tt.stats)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d providers, want %d: %+v", len(got), len(tt.want), got)
+			}
+			for i, w := range tt.want {
+				g := got[i]
+				if g.Provider != w.Provider || g.Agents != w.Agents || g.OnlineAgents != w.OnlineAgents || g.ProbeCount != w.ProbeCount ||
+					!equalOptional(g.UptimePct, w.UptimePct) || !equalOptional(g.AvgCPUPercent, w.AvgCPUPercent) ||
+					!equalOptional(g.AvgMemoryMB, w.AvgMemoryMB) || !equalOptional(g.SuccessRatePct, w.SuccessRatePct) ||
+					!equalOptional(g.AvgLatencyMs, w.AvgLatencyMs) || !equalOptional(g.AvgPacketLossPct, w.AvgPacketLossPct) {
+					t.Errorf("provider %d = %s, want %s", i, describeProvider(g), describeProvider(w))
+				}
+			}
+		})
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func equalOptional(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return math.Abs(*a-*b) < 1e-9
+}
+
+func describeProvider(h types.ProviderHealth) string {
+	opt := func(v *float64) string {
+		if v == nil {
+			return "nil"
+		}
+		return fmt.Sprint(*v)
+	}
+	return fmt.Sprintf("{%s agents=%d online=%d probes=%d uptime=%s cpu=%s mem=%s success=%s latency=%s loss=%s}",
+		h.Provider, h.Agents, h.OnlineAgents, h.ProbeCount, opt(h.UptimePct), opt(h.AvgCPUPercent),
+		opt(h.AvgMemoryMB), opt(h.SuccessRatePct), opt(h.AvgLatencyMs), opt(h.AvgPacketLossPct))
+}
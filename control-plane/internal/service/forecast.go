@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// LATENCY FORECAST
+// =============================================================================
+//
+// The forecast is an ordinary least-squares line through a target's hourly
+// average latency. That is deliberately simple: the goal is to catch steady
+// creep (a filling link, a slowly degrading path) before the threshold alert
+// fires, not to model daily seasonality. The prediction band widens with
+// residual noise and distance from the fitted data, so a noisy target shows
+// a wide band rather than a confident false alarm.
+
+// ForecastModelLinear identifies the model in LatencyForecast.Model.
+const ForecastModelLinear = "linear"
+
+// linearFit is a least-squares line y = intercept + slope*x.
+type linearFit struct {
+	intercept  float64
+	slope      float64
+	n          int
+	meanX      float64
+	sxx        float64 // sum of squared x deviations
+	residualSE float64 // standard error of the residuals
+	rSquared   float64
+}
+
+// fitLinear fits a line to the points. It needs at least three points with
+// distinct x values.
+func fitLinear(xs, ys []float64) (linearFit, bool) {
+	n := len(xs)
+	if n < 3 || n != len(ys) {
+		return linearFit{}, false
+	}
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/float64(n), sumY/float64(n)
+
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx == 0 {
+		return linearFit{}, false
+	}
+
+	f := linearFit{
+		slope: sxy / sxx,
+		n:     n,
+		meanX: meanX,
+		sxx:   sxx,
+	}
+	f.intercept = meanY - f.slope*meanX
+
+	var sse float64
+	for i := range xs {
+		r := ys[i] - f.predict(xs[i])
+		sse += r * r
+	}
+	f.residualSE = math.Sqrt(sse / float64(n-2))
+	f.rSquared = 1
+	if syy > 0 {
+		f.rSquared = 1 - sse/syy
+	}
+	return f, true
+}
+
+func (f linearFit) predict(x float64) float64 {
+	return f.intercept + f.slope*x
+}
+
+// predictionHalfWidth is the half-width of the prediction interval at x for
+// z standard errors.
+func (f linearFit) predictionHalfWidth(x, z float64) float64 {
+	dx := x - f.meanX
+	return z * f.residualSE * math.Sqrt(1+1/float64(f.n)+dx*dx/f.sxx)
+}
+
+// trend classifies the slope, treating one within z standard errors of zero
+// as flat.
+func (f linearFit) trend(z float64) string {
+	slopeSE := f.residualSE / math.Sqrt(f.sxx)
+	switch {
+	case math.Abs(f.slope) <= z*slopeSE:
+		return types.ForecastTrendFlat
+	case f.slope > 0:
+		return types.ForecastTrendRising
+	default:
+		return types.ForecastTrendFalling
+	}
+}
+
+// projectLatency fits the samples and projects them over the horizon at
+// config.ForecastStep intervals. With too few samples only the trend
+// (insufficient_data) and sample count are filled in.
+func projectLatency(samples []store.HourlyLatency, horizon time.Duration, thresholdMs float64) *types.LatencyForecast {
+	fc := &types.LatencyForecast{
+		Model:       ForecastModelLinear,
+		Horizon:     horizon.String(),
+		Samples:     len(samples),
+		Trend:       types.ForecastTrendInsufficientData,
+		ThresholdMs: thresholdMs,
+		Points:      []types.ForecastPoint{},
+	}
+	if len(samples) < config.ForecastMinSamples {
+		return fc
+	}
+
+	// x is hours since the first sample
+	origin := samples[0].Bucket
+	xs := make([]float64, len(samples))
+	ys := make([]float64, len(samples))
+	for i, s := range samples {
+		xs[i] = s.Bucket.Sub(origin).Hours()
+		ys[i] = s.AvgLatencyMs
+	}
+	fit, ok := fitLinear(xs, ys)
+	if !ok {
+		return fc
+	}
+
+	z := config.ForecastConfidenceZ
+	last := samples[len(samples)-1].Bucket
+	lastX := xs[len(xs)-1]
+
+	fc.Trend = fit.trend(z)
+	fc.SlopeMsPerHour = fit.slope
+	fc.RSquared = fit.rSquared
+	fc.CurrentMs = fit.predict(lastX)
+
+	for step := config.ForecastStep; step <= horizon; step += config.ForecastStep {
+		x := lastX + step.Hours()
+		predicted := fit.predict(x)
+		half := fit.predictionHalfWidth(x, z)
+		fc.Points = append(fc.Points, types.ForecastPoint{
+			Time:        last.Add(step),
+			PredictedMs: math.Max(predicted, 0),
+			LowerMs:     math.Max(predicted-half, 0),
+			UpperMs:     math.Max(predicted+half, 0),
+		})
+	}
+
+	if fc.CurrentMs >= thresholdMs {
+		fc.Breached = true
+		return fc
+	}
+	if fc.Trend == types.ForecastTrendRising {
+		hours := (thresholdMs - fc.CurrentMs) / fit.slope
+		if hours <= horizon.Hours() {
+			at := last.Add(time.Duration(hours * float64(time.Hour)))
+			fc.BreachProjected = true
+			fc.BreachAt = &at
+		}
+	}
+	return fc
+}
+
+// ForecastTargetLatency projects a target's latency over the horizon from
+// its recent hourly history, flagging a projected crossing of the
+// escalation_latency_warning_ms threshold. Returns nil if the target does
+// not exist.
+func (s *Service) ForecastTargetLatency(ctx context.Context, targetID string, horizon time.Duration) (*types.LatencyForecast, error) {
+	if horizon < config.ForecastStep || horizon > config.ForecastMaxHorizon {
+		return nil, invalidInput("horizon must be between %s and %s", config.ForecastStep, config.ForecastMaxHorizon)
+	}
+
+	target, err := s.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("getting target: %w", err)
+	}
+	if target == nil {
+		return nil, nil
+	}
+
+	threshold, err := s.store.GetAlertConfigFloat(ctx, "escalation_latency_warning_ms", config.ForecastDefaultThresholdMs)
+	if err != nil {
+		s.logger.Warn("failed to read forecast threshold, using default", "error", err)
+		threshold = config.ForecastDefaultThresholdMs
+	}
+
+	samples, err := s.store.GetTargetHourlyLatency(ctx, targetID, config.ForecastLookback)
+	if err != nil {
+		return nil, fmt.Errorf("getting latency history: %w", err)
+	}
+
+	fc := projectLatency(samples, horizon, threshold)
+	fc.TargetID = targetID
+	fc.GeneratedAt = time.Now()
+	fc.Lookback = config.ForecastLookback.String()
+	return fc, nil
+}
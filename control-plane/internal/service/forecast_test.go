@@ -0,0 +1,167 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// hourlySamples builds n hourly samples with latency f(hour).
+func hourlySamples(n int, f func(h int) float64) []store.HourlyLatency {
+	start := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	samples := make([]store.HourlyLatency, n)
+	for h := range samples {
+		samples[h] = store.HourlyLatency{Bucket: start.Add(time.Duration(h) * time.Hour), AvgLatencyMs: f(h)}
+	}
+	return samples
+}
+
+// jitter alternates +/- amp so the fit has residual noise but no bias.
+func jitter(h int, amp float64) float64 {
+	if h%2 == 0 {
+		return amp
+	}
+	return -amp
+}
+
+func TestFitLinear_ExactLine(t *testing.T) {
+	tests := []struct {
+		name      string
+		xs, ys    []float64
+		wantOK    bool
+		intercept float64
+		slope     float64
+	}{
+		{"rising", []float64{0, 1, 2, 3}, []float64{10, 12, 14, 16}, true, 10, 2},
+		{"falling", []float64{0, 2, 4}, []float64{30, 20, 10}, true, 30, -5},
+		{"flat", []float64{0, 1, 2}, []float64{7, 7, 7}, true, 7, 0},
+		{"too few points", []float64{0, 1}, []float64{1, 2}, false, 0, 0},
+		{"identical x", []float64{1, 1, 1}, []float64{1, 2, 3}, false, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fit, ok := fitLinear(tt.xs, tt.ys)
+			if ok != tt.wantOK {
+				t.Fatalf("fitLinear() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if math.Abs(fit.intercept-tt.intercept) > 1e-9 || math.Abs(fit.slope-tt.slope) > 1e-9 {
+				t.Errorf("fit = %.3f + %.3fx, want %.3f + %.3fx", fit.intercept, fit.slope, tt.intercept, tt.slope)
+			}
+			if fit.residualSE > 1e-9 {
+				t.Errorf("residualSE = %v, want 0 for an exact line", fit.residualSE)
+			}
+		})
+	}
+}
+
+func TestProjectLatency_Scenarios(t *testing.T) {
+	const threshold = 100.0
+	tests := []struct {
+		name          string
+		samples       []store.HourlyLatency
+		horizon       time.Duration
+		wantTrend     string
+		wantBreached  bool
+		wantProjected bool
+		wantBreachIn  time.Duration // after the last sample, when projected
+	}{
+		{
+			name:      "too little history",
+			samples:   hourlySamples(config.ForecastMinSamples-1, func(h int) float64 { return 20 }),
+			horizon:   24 * time.Hour,
+			wantTrend: types.ForecastTrendInsufficientData,
+		},
+		{
+			name:      "noisy but flat",
+			samples:   hourlySamples(72, func(h int) float64 { return 40 + jitter(h, 5) }),
+			horizon:   24 * time.Hour,
+			wantTrend: types.ForecastTrendFlat,
+		},
+		{
+			// 0.5ms/hour from 40ms: the fit reaches 75.5ms at the last sample
+			// and 100ms 49 hours later
+			name:          "slow creep crosses within horizon",
+			samples:       hourlySamples(72, func(h int) float64 { return 40 + 0.5*float64(h) + jitter(h, 1) }),
+			horizon:       72 * time.Hour,
+			wantTrend:     types.ForecastTrendRising,
+			wantProjected: true,
+			wantBreachIn:  49 * time.Hour,
+		},
+		{
+			name:      "slow creep beyond horizon",
+			samples:   hourlySamples(72, func(h int) float64 { return 40 + 0.5*float64(h) + jitter(h, 1) }),
+			horizon:   24 * time.Hour,
+			wantTrend: types.ForecastTrendRising,
+		},
+		{
+			name:         "already over threshold",
+			samples:      hourlySamples(48, func(h int) float64 { return 90 + float64(h) }),
+			horizon:      24 * time.Hour,
+			wantTrend:    types.ForecastTrendRising,
+			wantBreached: true,
+		},
+		{
+			name:      "improving",
+			samples:   hourlySamples(48, func(h int) float64 { return 90 - float64(h) + jitter(h, 1) }),
+			horizon:   24 * time.Hour,
+			wantTrend: types.ForecastTrendFalling,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fc := projectLatency(tt.samples, tt.horizon, threshold)
+
+			if fc.Trend != tt.wantTrend {
+				t.Errorf("Trend = %q, want %q (slope %.3f)", fc.Trend, tt.wantTrend, fc.SlopeMsPerHour)
+			}
+			if fc.Breached != tt.wantBreached {
+				t.Errorf("Breached = %v, want %v", fc.Breached, tt.wantBreached)
+			}
+			if fc.BreachProjected != tt.wantProjected {
+				t.Errorf("BreachProjected = %v, want %v", fc.BreachProjected, tt.wantProjected)
+			}
+			if tt.wantProjected {
+				if fc.BreachAt == nil {
+					t.Fatal("BreachAt not set for a projected breach")
+				}
+				last := tt.samples[len(tt.samples)-1].Bucket
+				if got := fc.BreachAt.Sub(last); (got - tt.wantBreachIn).Abs() > time.Hour {
+					t.Errorf("breach in %s, want about %s", got, tt.wantBreachIn)
+				}
+			}
+
+			if tt.wantTrend == types.ForecastTrendInsufficientData {
+				if len(fc.Points) != 0 {
+					t.Errorf("got %d points without enough history", len(fc.Points))
+				}
+				return
+			}
+			if want := int(tt.horizon / config.ForecastStep); len(fc.Points) != want {
+				t.Fatalf("got %d points, want %d", len(fc.Points), want)
+			}
+			for _, p := range fc.Points {
+				if p.LowerMs > p.PredictedMs || p.PredictedMs > p.UpperMs {
+					t.Errorf("point %s: %.2f outside band [%.2f, %.2f]", p.Time, p.PredictedMs, p.LowerMs, p.UpperMs)
+				}
+			}
+		})
+	}
+}
+
+func TestProjectLatency_BandWidensWithDistance(t *testing.T) {
+	samples := hourlySamples(48, func(h int) float64 { return 50 + jitter(h, 3) })
+	fc := projectLatency(samples, 48*time.Hour, 100)
+
+	first, last := fc.Points[0], fc.Points[len(fc.Points)-1]
+	if first.UpperMs-first.LowerMs >= last.UpperMs-last.LowerMs {
+		t.Errorf("band width %.3f at +1h should be narrower than %.3f at +48h",
+			first.UpperMs-first.LowerMs, last.UpperMs-last.LowerMs)
+	}
+}
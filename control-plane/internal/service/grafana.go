@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// GRAFANA DATA SOURCE
+// =============================================================================
+
+// GrafanaSearch lists what a panel or template variable can pick from. See
+// types.GrafanaSearchRequest for the kinds of search.
+func (s *Service) GrafanaSearch(ctx context.Context, req types.GrafanaSearchRequest) ([]types.GrafanaSearchResult, error) {
+	kind, text, err := req.Parse()
+	if err != nil {
+		return nil, invalidInput("%s", err)
+	}
+
+	switch kind {
+	case types.GrafanaSearchGroupBy:
+		return types.GrafanaNames(types.QueryGroupByDimensions), nil
+
+	case types.GrafanaSearchTiers:
+		tiers, err := s.store.ListTiers(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing tiers: %w", err)
+		}
+		names := make([]string, len(tiers))
+		for i, t := range tiers {
+			names[i] = t.Name
+		}
+		return types.GrafanaNames(names), nil
+
+	case types.GrafanaSearchAgents:
+		agents, err := s.store.ListAgents(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("listing agents: %w", err)
+		}
+		results := make([]types.GrafanaSearchResult, 0, min(len(agents), config.GrafanaSearchLimit))
+		for _, a := range agents[:min(len(agents), config.GrafanaSearchLimit)] {
+			results = append(results, types.GrafanaSearchResult{Text: a.Name, Value: a.ID})
+		}
+		return results, nil
+
+	case types.GrafanaSearchTargets:
+		first := ""
+		page, err := s.store.ListTargetsPaginated(ctx, store.TargetListParams{
+			Limit:  config.GrafanaSearchLimit,
+			Cursor: &first,
+			Search: text,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("searching targets: %w", err)
+		}
+		results := make([]types.GrafanaSearchResult, 0, len(page.Targets))
+		for _, t := range page.Targets {
+			label := t.IP
+			if t.DisplayName != "" {
+				label = fmt.Sprintf("%s (%s)", t.DisplayName, t.IP)
+			}
+			results = append(results, types.GrafanaSearchResult{Text: label, Value: t.ID})
+		}
+		return results, nil
+
+	default:
+		return types.GrafanaNames(types.QueryMetricNames), nil
+	}
+}
+
+// GrafanaQuery runs each visible panel target as a metrics query over the
+// dashboard's range and returns their series together. Targets without a
+// metric, as on a panel still being set up, are skipped.
+func (s *Service) GrafanaQuery(ctx context.Context, req *types.GrafanaQueryRequest) ([]types.GrafanaTimeSeries, error) {
+	if !req.Range.From.Before(req.Range.To) {
+		return nil, invalidInput("range.from must be before range.to")
+	}
+
+	series := []types.GrafanaTimeSeries{}
+	for _, t := range req.Targets {
+		if t.Hide || t.Target == "" {
+			continue
+		}
+		if t.Type != "" && t.Type != "timeserie" {
+			return nil, invalidInput("target %s: only timeserie queries are supported", t.RefID)
+		}
+
+		query := req.MetricsQuery(t)
+		if err := query.Validate(); err != nil {
+			return nil, invalidInput("target %s: %s", t.RefID, err)
+		}
+		result, err := s.store.QueryMetrics(ctx, query)
+		if err != nil {
+			return nil, fmt.Errorf("querying %s for target %s: %w", t.Target, t.RefID, err)
+		}
+		series = append(series, types.GrafanaSeries(t.Target, result)...)
+	}
+	return series, nil
+}
+
+// GrafanaAnnotations returns the incidents and operator annotations that
+// overlap the dashboard's range, oldest first.
+func (s *Service) GrafanaAnnotations(ctx context.Context, req *types.GrafanaAnnotationRequest) ([]types.GrafanaAnnotation, error) {
+	from, to := req.Range.From, req.Range.To
+	if !from.Before(to) {
+		return nil, invalidInput("range.from must be before range.to")
+	}
+	withIncidents, withNotes, err := req.Annotation.Sources()
+	if err != nil {
+		return nil, invalidInput("%s", err)
+	}
+
+	annotations := []types.GrafanaAnnotation{}
+	if withIncidents {
+		spans, err := s.store.ListIncidentSpans(ctx, from, to, config.GrafanaAnnotationLimit)
+		if err != nil {
+			return nil, fmt.Errorf("getting incidents: %w", err)
+		}
+		annotations = append(annotations, grafanaIncidentAnnotations(req.Annotation, spans)...)
+	}
+	if withNotes {
+		notes, err := s.store.ListAnnotations(ctx, from, to, config.GrafanaAnnotationLimit)
+		if err != nil {
+			return nil, fmt.Errorf("getting annotations: %w", err)
+		}
+		annotations = append(annotations, grafanaNoteAnnotations(req.Annotation, notes)...)
+	}
+
+	sort.SliceStable(annotations, func(i, j int) bool {
+		return annotations[i].Time < annotations[j].Time
+	})
+	return annotations, nil
+}
+
+// grafanaIncidentAnnotations draws incidents as regions from detection to
+// resolution. An open incident is a point at detection, since Grafana has
+// no open-ended region.
+func grafanaIncidentAnnotations(q types.GrafanaAnnotationQuery, spans []store.TargetIncidentSpan) []types.GrafanaAnnotation {
+	annotations := make([]types.GrafanaAnnotation, 0, len(spans))
+	for _, sp := range spans {
+		a := types.GrafanaAnnotation{
+			Annotation: q,
+			Time:       sp.DetectedAt.UnixMilli(),
+			Title:      fmt.Sprintf("%s-severity %s incident", sp.Severity, sp.IncidentType),
+			Text:       "Incident " + sp.IncidentID,
+			Tags:       []string{"incident", sp.IncidentType, sp.Severity},
+		}
+		if sp.ResolvedAt != nil {
+			a.TimeEnd = sp.ResolvedAt.UnixMilli()
+			a.IsRegion = true
+		} else {
+			a.Title += " (ongoing)"
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
+
+// grafanaNoteAnnotations draws operator annotations, as regions when they
+// cover a range.
+func grafanaNoteAnnotations(q types.GrafanaAnnotationQuery, notes []types.TargetAnnotation) []types.GrafanaAnnotation {
+	annotations := make([]types.GrafanaAnnotation, 0, len(notes))
+	for _, n := range notes {
+		a := types.GrafanaAnnotation{
+			Annotation: q,
+			Time:       n.StartsAt.UnixMilli(),
+			Title:      n.Text,
+			Text:       "Target " + n.TargetID,
+			Tags:       []string{"annotation", "target:" + n.TargetID},
+		}
+		if n.CreatedBy != "" {
+			a.Text += ", by " + n.CreatedBy
+		}
+		if n.EndsAt != nil {
+			a.TimeEnd = n.EndsAt.UnixMilli()
+			a.IsRegion = true
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestGrafanaIncidentAnnotations_Regions(t *testing.T) {
+	detected := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	resolved := detected.Add(time.Hour)
+	q := types.GrafanaAnnotationQuery{Name: "Incidents", Enable: true}
+
+	got := grafanaIncidentAnnotations(q, []store.TargetIncidentSpan{
+		{IncidentID: "i1", IncidentType: "regional", Severity: "high", DetectedAt: detected, ResolvedAt: &resolved},
+		{IncidentID: "i2", IncidentType: "target", Severity: "low", DetectedAt: detected},
+	})
+
+	want := []types.GrafanaAnnotation{
+		{
+			Annotation: q, Time: detected.UnixMilli(), TimeEnd: resolved.UnixMilli(), IsRegion: true,
+			Title: "high-severity regional incident", Text: "Incident i1",
+			Tags: []string{"incident", "regional", "high"},
+		},
+		{
+			Annotation: q, Time: detected.UnixMilli(),
+			Title: "low-severity target incident (ongoing)", Text: "Incident i2",
+			Tags: []string{"incident", "target", "low"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grafanaIncidentAnnotations() = %+v, want %+v", got, want)
+	}
+}
+
+func TestGrafanaNoteAnnotations_PointsAndRanges(t *testing.T) {
+	start := time.Date(2024, 5, 1, 2, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+	q := types.GrafanaAnnotationQuery{Name: "Notes", Query: "annotations"}
+
+	got := grafanaNoteAnnotations(q, []types.TargetAnnotation{
+		{TargetID: "t1", StartsAt: start, EndsAt: &end, Text: "router maintenance", CreatedBy: "noc"},
+		{TargetID: "t2", StartsAt: start, Text: "config push"},
+	})
+
+	want := []types.GrafanaAnnotation{
+		{
+			Annotation: q, Time: start.UnixMilli(), TimeEnd: end.UnixMilli(), IsRegion: true,
+			Title: "router maintenance", Text: "Target t1, by noc",
+			Tags: []string{"annotation", "target:t1"},
+		},
+		{
+			Annotation: q, Time: start.UnixMilli(),
+			Title: "config push", Text: "Target t2",
+			Tags: []string{"annotation", "target:t2"},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("grafanaNoteAnnotations() = %+v, want %+v", got, want)
+	}
+}
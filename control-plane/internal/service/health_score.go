@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET HEALTH SCORE
+// =============================================================================
+
+// Target status sort orders accepted by SortTargetStatuses.
+const (
+	TargetStatusSortIP              = "ip"
+	TargetStatusSortHealthScore     = "health_score"  // worst first
+	TargetStatusSortHealthScoreDesc = "-health_score" // best first
+)
+
+// healthScoreWeights reads the health_score_weight_* alert_config keys,
+// falling back to the defaults for unset keys.
+func (s *Service) healthScoreWeights(ctx context.Context) (types.HealthScoreWeights, error) {
+	w := types.DefaultHealthScoreWeights()
+	for _, f := range []struct {
+		key string
+		dst *float64
+	}{
+		{"health_score_weight_reachability", &w.Reachability},
+		{"health_score_weight_latency", &w.Latency},
+		{"health_score_weight_loss", &w.Loss},
+		{"health_score_weight_jitter", &w.Jitter},
+	} {
+		v, err := s.store.GetAlertConfigFloat(ctx, f.key, *f.dst)
+		if err != nil {
+			return w, fmt.Errorf("reading %s: %w", f.key, err)
+		}
+		*f.dst = v
+	}
+	return w, nil
+}
+
+// applyHealthScores sets each status's health score from its reachability
+// and loss and the latency and jitter inputs over the status window.
+// targetID narrows the inputs query when statuses holds one target.
+func (s *Service) applyHealthScores(ctx context.Context, statuses []store.TargetStatus, targetID *string) error {
+	if len(statuses) == 0 {
+		return nil
+	}
+	weights, err := s.healthScoreWeights(ctx)
+	if err != nil {
+		return err
+	}
+	inputs, err := s.store.GetTargetHealthInputs(ctx, targetID, config.TargetStatusWindow)
+	if err != nil {
+		return fmt.Errorf("getting health score inputs: %w", err)
+	}
+
+	for i := range statuses {
+		st := &statuses[i]
+		in := inputs[st.TargetID]
+		in.ReachableAgents = st.ReachableAgents
+		in.TotalAgents = st.TotalAgents
+		in.PacketLossPct = st.PacketLossPct
+		st.HealthScore = types.ComputeHealthScore(in, weights)
+	}
+	return nil
+}
+
+// ValidateTargetStatusSort checks a sort order for SortTargetStatuses;
+// empty means by IP.
+func ValidateTargetStatusSort(by string) error {
+	switch by {
+	case "", TargetStatusSortIP, TargetStatusSortHealthScore, TargetStatusSortHealthScoreDesc:
+		return nil
+	}
+	return newError(ErrInvalidInput, nil, "sort must be %s, %s or %s",
+		TargetStatusSortIP, TargetStatusSortHealthScore, TargetStatusSortHealthScoreDesc)
+}
+
+// SortTargetStatuses orders statuses in place: by IP (the store's order),
+// or by health score worst or best first. Targets without a score sort
+// last either way, and ties keep their IP order.
+func SortTargetStatuses(statuses []store.TargetStatus, by string) error {
+	if err := ValidateTargetStatusSort(by); err != nil {
+		return err
+	}
+	if by == "" || by == TargetStatusSortIP {
+		return nil
+	}
+
+	desc := by == TargetStatusSortHealthScoreDesc
+	sort.SliceStable(statuses, func(i, j int) bool {
+		a, b := statuses[i].HealthScore, statuses[j].HealthScore
+		if a == nil || b == nil {
+			return a != nil && b == nil
+		}
+		if desc {
+			return a.Score > b.Score
+		}
+		return a.Score < b.Score
+	})
+	return nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestSortTargetStatuses_Order(t *testing.T) {
+	score := func(v int) *types.HealthScore { return &types.HealthScore{Score: v} }
+	statuses := func() []store.TargetStatus {
+		return []store.TargetStatus{
+			{TargetID: "a", HealthScore: score(80)},
+			{TargetID: "b"},
+			{TargetID: "c", HealthScore: score(20)},
+			{TargetID: "d", HealthScore: score(80)},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		by      string
+		want    []string
+		wantErr bool
+	}{
+		{name: "default keeps order", by: "", want: []string{"a", "b", "c", "d"}},
+		{name: "ip keeps order", by: TargetStatusSortIP, want: []string{"a", "b", "c", "d"}},
+		{name: "worst first, ties stable, unscored last", by: TargetStatusSortHealthScore, want: []string{"c", "a", "d", "b"}},
+		{name: "best first, unscored last", by: TargetStatusSortHealthScoreDesc, want: []string{"a", "d", "c", "b"}},
+		{name: "unknown order rejected", by: "latency", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := statuses()
+			err := SortTargetStatuses(got, tt.by)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Errorf("err = %v, want ErrInvalidInput", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SortTargetStatuses: %v", err)
+			}
+			ids := make([]string, len(got))
+			for i, st := range got {
+				ids[i] = st.TargetID
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("order = %v, want %v", ids, tt.want)
+			}
+		})
+	}
+}
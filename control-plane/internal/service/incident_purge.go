@@ -0,0 +1,24 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// DeleteIncident permanently removes a resolved incident, unlinking its
+// alerts, and returns how many alerts were unlinked. An incident that isn't
+// resolved can't be deleted: it fails with ErrConflict.
+func (s *Service) DeleteIncident(ctx context.Context, id string) (int, error) {
+	unlinked, err := s.store.DeleteIncident(ctx, id)
+	var open *store.IncidentOpenError
+	if errors.As(err, &open) {
+		return 0, newError(ErrConflict, map[string]any{"status": open.Status}, "%s", open)
+	}
+	if err != nil {
+		return 0, fromStore(err, "incident not found")
+	}
+	s.logger.Info("incident deleted", "incident_id", id, "alerts_unlinked", unlinked)
+	return unlinked, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// LATENCY ASYMMETRY
+// =============================================================================
+
+// targetLatencyAsymmetry compares a target's current latency across agent
+// regions. Failures are logged and yield nil, leaving the rest of the
+// target's status intact.
+func (s *Service) targetLatencyAsymmetry(ctx context.Context, targetID string) *types.LatencyAsymmetry {
+	regions, err := s.store.GetRegionLatencies(ctx, []string{targetID}, config.LatencyAsymmetryWindow)
+	if err != nil {
+		s.logger.Warn("failed to get region latencies", "target_id", targetID, "error", err)
+		return nil
+	}
+	if len(regions[targetID]) < 2 {
+		return nil
+	}
+
+	th, err := s.store.GetLatencyAsymmetryThresholds(ctx)
+	if err != nil {
+		s.logger.Warn("failed to read latency asymmetry thresholds, using defaults", "error", err)
+	}
+	return types.AssessLatencyAsymmetry(regions[targetID], th)
+}
@@ -0,0 +1,14 @@
+package service
+
+import "github.com/pilot-net/icmp-mon/control-plane/internal/store"
+
+// markLiveAnomalies flags the live results an operator should look at: a
+// failed probe, or latency at least threshold standard deviations above
+// the agent's baseline for the target. Faster than baseline is never
+// anomalous.
+func markLiveAnomalies(results []store.LiveProbeResult, threshold float64) {
+	for i := range results {
+		r := &results[i]
+		r.Anomaly = !r.Success || (r.ZScore != nil && *r.ZScore >= threshold)
+	}
+}
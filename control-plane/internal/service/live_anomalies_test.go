@@ -0,0 +1,34 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+func TestMarkLiveAnomalies_Threshold(t *testing.T) {
+	z := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name   string
+		result store.LiveProbeResult
+		want   bool
+	}{
+		{name: "within baseline", result: store.LiveProbeResult{Success: true, ZScore: z(1.2)}},
+		{name: "at threshold", result: store.LiveProbeResult{Success: true, ZScore: z(3)}, want: true},
+		{name: "far above baseline", result: store.LiveProbeResult{Success: true, ZScore: z(9.5)}, want: true},
+		{name: "faster than baseline", result: store.LiveProbeResult{Success: true, ZScore: z(-6)}},
+		{name: "no baseline", result: store.LiveProbeResult{Success: true}},
+		{name: "failed without baseline", result: store.LiveProbeResult{}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := []store.LiveProbeResult{tt.result}
+			markLiveAnomalies(results, 3)
+			if results[0].Anomaly != tt.want {
+				t.Errorf("Anomaly = %v, want %v", results[0].Anomaly, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// =============================================================================
+// LIVE RESULT STREAMS
+// =============================================================================
+
+// LiveStream follows one target's probe results for a streaming client.
+// Each Poll returns the results that have arrived since the previous one.
+type LiveStream struct {
+	svc       *Service
+	targetID  string
+	seen      map[liveResultKey]struct{}
+	closeOnce sync.Once
+	release   func()
+}
+
+// liveResultKey identifies a result: an agent probes a target at most once
+// per instant.
+type liveResultKey struct {
+	agentID  string
+	unixNano int64
+}
+
+// OpenLiveStream starts following a target's live results. At most
+// config.LiveStreamsPerTarget streams of a target are open at once; Close
+// frees the stream's slot.
+func (s *Service) OpenLiveStream(ctx context.Context, targetID string) (*LiveStream, error) {
+	target, err := s.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return nil, fromStore(err, "")
+	}
+	if target == nil {
+		return nil, fmt.Errorf("target %w: %s", ErrNotFound, targetID)
+	}
+
+	release, ok := s.liveStreams.acquire(targetID, config.LiveStreamsPerTarget)
+	if !ok {
+		return nil, newError(ErrRateLimited, map[string]any{"limit": config.LiveStreamsPerTarget},
+			"too many live streams of this target (limit %d)", config.LiveStreamsPerTarget)
+	}
+	return &LiveStream{
+		svc:      s,
+		targetID: targetID,
+		seen:     make(map[liveResultKey]struct{}),
+		release:  release,
+	}, nil
+}
+
+// Poll returns the target's results over config.LiveStreamLookback that
+// this stream hasn't returned yet, oldest first. Results are sampled
+// across agents like the live view's.
+func (ls *LiveStream) Poll(ctx context.Context) ([]store.LiveProbeResult, error) {
+	results, err := ls.svc.store.GetTargetLiveResults(ctx, ls.targetID, config.LiveStreamLookback, 0, config.LiveResultsLimit)
+	if err != nil {
+		return nil, fmt.Errorf("getting live results: %w", err)
+	}
+	markLiveAnomalies(results, config.LiveAnomalyZScore)
+	return ls.unseen(results, time.Now().Add(-config.LiveStreamLookback)), nil
+}
+
+// unseen filters results down to those not returned before, oldest first.
+// Results older than cutoff have left the lookback and are forgotten.
+// Keying on agent and time rather than keeping a high-water mark means an
+// agent shipping behind the others still has its results sent.
+func (ls *LiveStream) unseen(results []store.LiveProbeResult, cutoff time.Time) []store.LiveProbeResult {
+	for k := range ls.seen {
+		if k.unixNano < cutoff.UnixNano() {
+			delete(ls.seen, k)
+		}
+	}
+
+	var fresh []store.LiveProbeResult
+	for _, r := range results {
+		k := liveResultKey{agentID: r.AgentID, unixNano: r.Time.UnixNano()}
+		if _, ok := ls.seen[k]; ok {
+			continue
+		}
+		ls.seen[k] = struct{}{}
+		fresh = append(fresh, r)
+	}
+	sort.SliceStable(fresh, func(i, j int) bool { return fresh[i].Time.Before(fresh[j].Time) })
+	return fresh
+}
+
+// Close frees the stream's slot. It is safe to call more than once.
+func (ls *LiveStream) Close() {
+	ls.closeOnce.Do(ls.release)
+}
+
+// liveStreamSlots counts open live streams per target. The zero value is
+// ready to use.
+type liveStreamSlots struct {
+	mu   sync.Mutex
+	open map[string]int
+}
+
+// acquire takes one of limit slots for targetID, returning the function
+// that gives it back, or false if all are taken.
+func (l *liveStreamSlots) acquire(targetID string, limit int) (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.open[targetID] >= limit {
+		return nil, false
+	}
+	if l.open == nil {
+		l.open = make(map[string]int)
+	}
+	l.open[targetID]++
+
+	return func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.open[targetID]--; l.open[targetID] <= 0 {
+			delete(l.open, targetID)
+		}
+	}, true
+}
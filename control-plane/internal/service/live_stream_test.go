@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+func TestLiveStream_Unseen(t *testing.T) {
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(s int) time.Time { return base.Add(time.Duration(s) * time.Second) }
+	res := func(agent string, s int) store.LiveProbeResult {
+		return store.LiveProbeResult{AgentID: agent, Time: at(s)}
+	}
+
+	// Each poll returns the store's newest-first rows; want is what the
+	// stream sends, oldest first.
+	polls := []struct {
+		name   string
+		rows   []store.LiveProbeResult
+		cutoff time.Time
+		want   []store.LiveProbeResult
+	}{
+		{
+			name:   "backfill sorted oldest first",
+			rows:   []store.LiveProbeResult{res("a", 3), res("b", 2), res("a", 1)},
+			cutoff: at(-30),
+			want:   []store.LiveProbeResult{res("a", 1), res("b", 2), res("a", 3)},
+		},
+		{
+			name:   "only new rows",
+			rows:   []store.LiveProbeResult{res("a", 4), res("a", 3), res("b", 2)},
+			cutoff: at(-29),
+			want:   []store.LiveProbeResult{res("a", 4)},
+		},
+		{
+			name:   "late agent older than last sent",
+			rows:   []store.LiveProbeResult{res("a", 4), res("c", 1), res("b", 2)},
+			cutoff: at(-28),
+			want:   []store.LiveProbeResult{res("c", 1)},
+		},
+		{
+			name:   "same instant, other agent",
+			rows:   []store.LiveProbeResult{res("b", 4), res("a", 4)},
+			cutoff: at(-27),
+			want:   []store.LiveProbeResult{res("b", 4)},
+		},
+		{
+			name:   "nothing new",
+			rows:   []store.LiveProbeResult{res("b", 4), res("a", 4)},
+			cutoff: at(-26),
+		},
+	}
+
+	ls := &LiveStream{seen: make(map[liveResultKey]struct{})}
+	for _, p := range polls {
+		got := ls.unseen(p.rows, p.cutoff)
+		if len(got) != len(p.want) {
+			t.Fatalf("%s: got %v, want %v", p.name, got, p.want)
+		}
+		for i := range got {
+			if got[i].AgentID != p.want[i].AgentID || !got[i].Time.Equal(p.want[i].Time) {
+				t.Errorf("%s: result %d = %s@%s, want %s@%s", p.name, i,
+					got[i].AgentID, got[i].Time, p.want[i].AgentID, p.want[i].Time)
+			}
+		}
+	}
+
+	ls.unseen(nil, at(10))
+	if len(ls.seen) != 0 {
+		t.Errorf("%d results remembered past the lookback, want none", len(ls.seen))
+	}
+}
+
+func TestLiveStreamSlots_Limit(t *testing.T) {
+	var slots liveStreamSlots
+
+	var releases []func()
+	for i := 0; i < 2; i++ {
+		release, ok := slots.acquire("t1", 2)
+		if !ok {
+			t.Fatalf("acquire %d refused under the limit", i)
+		}
+		releases = append(releases, release)
+	}
+	if _, ok := slots.acquire("t1", 2); ok {
+		t.Error("acquire succeeded over the limit")
+	}
+	if _, ok := slots.acquire("t2", 2); !ok {
+		t.Error("another target's limit was shared")
+	}
+
+	releases[0]()
+	if _, ok := slots.acquire("t1", 2); !ok {
+		t.Error("released slot not reusable")
+	}
+}
@@ -0,0 +1,15 @@
+package service
+
+import "github.com/pilot-net/icmp-mon/pkg/types"
+
+// SetNeverRespondedPolicy records the state worker's policy for archiving
+// never-responsive auto targets, reported by NeverRespondedPolicy.
+func (s *Service) SetNeverRespondedPolicy(policy types.NeverRespondedPolicy) {
+	s.neverResponded = policy
+}
+
+// NeverRespondedPolicy returns the policy the state worker archives
+// never-responsive auto targets by. It is fixed at startup.
+func (s *Service) NeverRespondedPolicy() types.NeverRespondedPolicy {
+	return s.neverResponded
+}
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// PATH MTU DISCOVERY
+// =============================================================================
+
+// CreatePMTUDCommand queues a pmtud command that has agents find the path
+// MTU to a target. Like MTR, no agentIDs means every agent runs it, which
+// shows whether a small MTU is on one path or near the target. Each agent's
+// types.PMTUDPayload comes back through the command pipeline.
+func (s *Service) CreatePMTUDCommand(ctx context.Context, targetID string, params types.PMTUDParams, agentIDs []string) (*store.Command, error) {
+	if err := params.Validate(); err != nil {
+		return nil, invalidInput("%s", err)
+	}
+	target, err := s.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("getting target: %w", err)
+	}
+	if target == nil {
+		return nil, fmt.Errorf("target %w: %s", ErrNotFound, targetID)
+	}
+
+	now := time.Now()
+	expires := now.Add(config.PMTUDCommandTTL)
+	cmd := &store.Command{
+		ID:          uuid.New().String(),
+		CommandType: types.CommandPMTUD,
+		TargetID:    target.ID,
+		TargetIP:    target.IP,
+		Params:      map[string]any{},
+		AgentIDs:    agentIDs,
+		Status:      "pending",
+		RequestedAt: now,
+		ExpiresAt:   &expires,
+	}
+	if params.MinMTU != 0 {
+		cmd.Params["min_mtu"] = params.MinMTU
+	}
+	if params.MaxMTU != 0 {
+		cmd.Params["max_mtu"] = params.MaxMTU
+	}
+	if err := s.store.CreateCommand(ctx, cmd); err != nil {
+		return nil, fmt.Errorf("creating pmtud command: %w", err)
+	}
+
+	s.logger.Info("path MTU discovery queued",
+		"command_id", cmd.ID,
+		"target_id", target.ID,
+		"target_ip", target.IP,
+		"agents", agentIDs,
+	)
+	return cmd, nil
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// INCIDENT POSTMORTEM
+// =============================================================================
+//
+// A postmortem bundles everything already stored about an incident into one
+// document for review. The only new query is probe history, bounded to the
+// incident window plus padding on each side.
+
+// IncidentPostmortem is the review document for one incident.
+type IncidentPostmortem struct {
+	GeneratedAt      time.Time                  `json:"generated_at"`
+	Incident         *store.Incident            `json:"incident"`
+	Duration         string                     `json:"duration"`
+	Timeline         []PostmortemEvent          `json:"timeline"`
+	AffectedTargets  []store.IncidentTarget     `json:"affected_targets"`
+	AffectedAgents   []store.IncidentAgent      `json:"affected_agents"`
+	Peaks            PostmortemPeaks            `json:"peaks"`
+	BaselineSnapshot json.RawMessage            `json:"baseline_snapshot,omitempty"`
+	Window           PostmortemWindow           `json:"window"`
+	ProbeHistory     []store.TargetHistoryPoint `json:"probe_history"`
+	Alerts           []types.Alert              `json:"alerts"`
+}
+
+// PostmortemEvent is one timeline entry, from the incident itself, its
+// recorded events, or a linked alert.
+type PostmortemEvent struct {
+	Time        time.Time `json:"time"`
+	Source      string    `json:"source"` // incident, alert
+	EventType   string    `json:"event_type"`
+	Description string    `json:"description,omitempty"`
+	AlertID     string    `json:"alert_id,omitempty"`
+}
+
+// PostmortemPeaks are the worst metrics recorded during the incident.
+type PostmortemPeaks struct {
+	ZScore        *float64 `json:"z_score,omitempty"`
+	PacketLossPct *float64 `json:"packet_loss_pct,omitempty"`
+	LatencyMs     *float64 `json:"latency_ms,omitempty"`
+}
+
+// PostmortemWindow is the span of the probe history.
+type PostmortemWindow struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	BucketSize string    `json:"bucket_size"`
+}
+
+// Timeline sources.
+const (
+	PostmortemSourceIncident = "incident"
+	PostmortemSourceAlert    = "alert"
+)
+
+// postmortemWindow returns the probe history window and bucket size for an
+// incident. Open incidents run to now, capped at PostmortemOpenWindowCap.
+func postmortemWindow(inc *store.Incident, now time.Time) (start, end time.Time, bucket time.Duration) {
+	start = inc.DetectedAt.Add(-config.PostmortemWindowPadding)
+	end = now
+	if inc.ResolvedAt != nil {
+		end = inc.ResolvedAt.Add(config.PostmortemWindowPadding)
+		if end.After(now) {
+			end = now
+		}
+	} else if earliest := now.Add(-config.PostmortemOpenWindowCap); start.Before(earliest) {
+		start = earliest
+	}
+
+	bucket = config.PostmortemMinBucket
+	if span := end.Sub(start); span > bucket*config.PostmortemMaxBuckets {
+		bucket = (span / config.PostmortemMaxBuckets).Truncate(config.PostmortemMinBucket) + config.PostmortemMinBucket
+	}
+	return start, end, bucket
+}
+
+// buildTimeline merges the incident's lifecycle, its recorded events and its
+// linked alerts, oldest first. A lifecycle step that also has a recorded
+// event is taken from the event, which carries more detail.
+func buildTimeline(inc *store.Incident, events []store.IncidentEvent, alerts []types.Alert) []PostmortemEvent {
+	recorded := make(map[string]bool, len(events))
+	timeline := make([]PostmortemEvent, 0, len(events)+len(alerts)*2+4)
+	for _, e := range events {
+		recorded[e.EventType] = true
+		timeline = append(timeline, PostmortemEvent{
+			Time:        e.CreatedAt,
+			Source:      PostmortemSourceIncident,
+			EventType:   e.EventType,
+			Description: e.Description,
+		})
+	}
+
+	lifecycle := []struct {
+		eventType   string
+		at          *time.Time
+		description string
+	}{
+		{"detected", &inc.DetectedAt, fmt.Sprintf("%s %s incident detected", inc.Severity, inc.IncidentType)},
+		{"confirmed", inc.ConfirmedAt, "Incident confirmed"},
+		{"acknowledged", inc.AcknowledgedAt, acknowledgedDescription(inc.AcknowledgedBy)},
+		{"resolved", inc.ResolvedAt, "Incident resolved"},
+	}
+	for _, step := range lifecycle {
+		if step.at == nil || recorded[step.eventType] {
+			continue
+		}
+		timeline = append(timeline, PostmortemEvent{
+			Time:        *step.at,
+			Source:      PostmortemSourceIncident,
+			EventType:   step.eventType,
+			Description: step.description,
+		})
+	}
+
+	for _, a := range alerts {
+		timeline = append(timeline, PostmortemEvent{
+			Time:        a.DetectedAt,
+			Source:      PostmortemSourceAlert,
+			EventType:   "alert_detected",
+			Description: fmt.Sprintf("[%s] %s", a.Severity, a.Title),
+			AlertID:     a.ID,
+		})
+		if a.ResolvedAt != nil {
+			timeline = append(timeline, PostmortemEvent{
+				Time:        *a.ResolvedAt,
+				Source:      PostmortemSourceAlert,
+				EventType:   "alert_resolved",
+				Description: a.Title,
+				AlertID:     a.ID,
+			})
+		}
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].Time.Before(timeline[j].Time)
+	})
+	return timeline
+}
+
+func acknowledgedDescription(by string) string {
+	if by == "" {
+		return "Incident acknowledged"
+	}
+	return "Incident acknowledged by " + by
+}
+
+// GetIncidentPostmortem assembles the postmortem for an incident. Returns nil
+// if the incident does not exist.
+func (s *Service) GetIncidentPostmortem(ctx context.Context, incidentID string) (*IncidentPostmortem, error) {
+	inc, err := s.store.GetIncident(ctx, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting incident: %w", err)
+	}
+	if inc == nil {
+		return nil, nil
+	}
+
+	events, err := s.store.GetIncidentEvents(ctx, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("getting incident events: %w", err)
+	}
+	alerts, err := s.store.ListAlerts(ctx, types.AlertFilter{
+		IncidentID: &incidentID,
+		Limit:      config.PostmortemMaxAlerts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting linked alerts: %w", err)
+	}
+	targets, err := s.store.GetIncidentTargets(ctx, inc.AffectedTargetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("getting affected targets: %w", err)
+	}
+	agents, err := s.store.GetIncidentAgents(ctx, inc.AffectedAgentIDs)
+	if err != nil {
+		return nil, fmt.Errorf("getting affected agents: %w", err)
+	}
+
+	now := time.Now()
+	start, end, bucket := postmortemWindow(inc, now)
+
+	var history []store.TargetHistoryPoint
+	if len(inc.AffectedTargetIDs) > 0 || len(inc.AffectedAgentIDs) > 0 {
+		history, err = s.store.GetProbeHistoryWindow(ctx, inc.AffectedTargetIDs, inc.AffectedAgentIDs, start, end, bucket)
+		if err != nil {
+			return nil, fmt.Errorf("getting probe history: %w", err)
+		}
+	}
+
+	resolvedOrNow := now
+	if inc.ResolvedAt != nil {
+		resolvedOrNow = *inc.ResolvedAt
+	}
+
+	return &IncidentPostmortem{
+		GeneratedAt:     now,
+		Incident:        inc,
+		Duration:        resolvedOrNow.Sub(inc.DetectedAt).Round(time.Second).String(),
+		Timeline:        buildTimeline(inc, events, alerts),
+		AffectedTargets: nonNil(targets),
+		AffectedAgents:  nonNil(agents),
+		Peaks: PostmortemPeaks{
+			ZScore:        inc.PeakZScore,
+			PacketLossPct: inc.PeakPacketLoss,
+			LatencyMs:     inc.PeakLatencyMs,
+		},
+		BaselineSnapshot: inc.BaselineSnapshot,
+		Window: PostmortemWindow{
+			Start:      start,
+			End:        end,
+			BucketSize: bucket.String(),
+		},
+		ProbeHistory: nonNil(history),
+		Alerts:       nonNil(alerts),
+	}, nil
+}
+
+// nonNil keeps empty sections as [] rather than null in the JSON document.
+func nonNil[T any](s []T) []T {
+	if s == nil {
+		return []T{}
+	}
+	return s
+}
+
+// =============================================================================
+// MARKDOWN RENDERING
+// =============================================================================
+
+// RenderPostmortemMarkdown renders the postmortem as a Markdown document.
+// Probe history is summarised per target (worst bucket) rather than listed.
+func RenderPostmortemMarkdown(pm *IncidentPostmortem) string {
+	var b strings.Builder
+	inc := pm.Incident
+
+	fmt.Fprintf(&b, "# Incident postmortem: %s\n\n", inc.ID)
+	b.WriteString("| Field | Value |\n|---|---|\n")
+	fmt.Fprintf(&b, "| Type | %s |\n", inc.IncidentType)
+	fmt.Fprintf(&b, "| Severity | %s |\n", inc.Severity)
+	fmt.Fprintf(&b, "| Status | %s |\n", inc.Status)
+	if inc.PrimaryEntityID != "" {
+		fmt.Fprintf(&b, "| Primary entity | %s %s |\n", inc.PrimaryEntityType, inc.PrimaryEntityID)
+	}
+	fmt.Fprintf(&b, "| Detected | %s |\n", formatPostmortemTime(inc.DetectedAt))
+	if inc.ResolvedAt != nil {
+		fmt.Fprintf(&b, "| Resolved | %s |\n", formatPostmortemTime(*inc.ResolvedAt))
+	}
+	fmt.Fprintf(&b, "| Duration | %s |\n", pm.Duration)
+	fmt.Fprintf(&b, "| Generated | %s |\n", formatPostmortemTime(pm.GeneratedAt))
+
+	b.WriteString("\n## Timeline\n\n")
+	if len(pm.Timeline) == 0 {
+		b.WriteString("No events recorded.\n")
+	}
+	for _, e := range pm.Timeline {
+		fmt.Fprintf(&b, "- %s **%s** (%s)", formatPostmortemTime(e.Time), e.EventType, e.Source)
+		if e.Description != "" {
+			fmt.Fprintf(&b, ": %s", e.Description)
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n## Peak metrics\n\n")
+	fmt.Fprintf(&b, "- Z-score: %s\n", formatPostmortemFloat(pm.Peaks.ZScore, ""))
+	fmt.Fprintf(&b, "- Packet loss: %s\n", formatPostmortemFloat(pm.Peaks.PacketLossPct, "%"))
+	fmt.Fprintf(&b, "- Latency: %s\n", formatPostmortemFloat(pm.Peaks.LatencyMs, " ms"))
+
+	b.WriteString("\n## Affected targets\n\n")
+	if len(pm.AffectedTargets) == 0 {
+		b.WriteString("None recorded.\n")
+	}
+	for _, t := range pm.AffectedTargets {
+		fmt.Fprintf(&b, "- %s (%s)\n", t.IP, t.Tier)
+	}
+
+	b.WriteString("\n## Affected agents\n\n")
+	if len(pm.AffectedAgents) == 0 {
+		b.WriteString("None recorded.\n")
+	}
+	for _, a := range pm.AffectedAgents {
+		if a.Region != "" {
+			fmt.Fprintf(&b, "- %s (%s)\n", a.Name, a.Region)
+		} else {
+			fmt.Fprintf(&b, "- %s\n", a.Name)
+		}
+	}
+
+	b.WriteString("\n## Probe history\n\n")
+	fmt.Fprintf(&b, "%s to %s in %s buckets.\n\n",
+		formatPostmortemTime(pm.Window.Start), formatPostmortemTime(pm.Window.End), pm.Window.BucketSize)
+	writeHistorySummary(&b, pm)
+
+	b.WriteString("\n## Linked alerts\n\n")
+	if len(pm.Alerts) == 0 {
+		b.WriteString("None.\n")
+	} else {
+		b.WriteString("| Detected | Severity | Type | Target | Title | Resolved |\n|---|---|---|---|---|---|\n")
+		for _, a := range pm.Alerts {
+			resolved := "-"
+			if a.ResolvedAt != nil {
+				resolved = formatPostmortemTime(*a.ResolvedAt)
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s |\n",
+				formatPostmortemTime(a.DetectedAt), a.Severity, a.AlertType, a.TargetIP,
+				escapeMarkdownCell(a.Title), resolved)
+		}
+	}
+
+	if len(pm.BaselineSnapshot) > 0 {
+		b.WriteString("\n## Baseline snapshot\n\n```json\n")
+		var indented bytes.Buffer
+		if json.Indent(&indented, pm.BaselineSnapshot, "", "  ") == nil {
+			b.Write(indented.Bytes())
+		} else {
+			b.Write(pm.BaselineSnapshot)
+		}
+		b.WriteString("\n```\n")
+	}
+
+	if inc.Notes != "" {
+		fmt.Fprintf(&b, "\n## Notes\n\n%s\n", inc.Notes)
+	}
+	return b.String()
+}
+
+// writeHistorySummary writes one row per target with its worst bucket.
+func writeHistorySummary(b *strings.Builder, pm *IncidentPostmortem) {
+	if len(pm.ProbeHistory) == 0 {
+		b.WriteString("No probe results in the window.\n")
+		return
+	}
+
+	ips := make(map[string]string, len(pm.AffectedTargets))
+	for _, t := range pm.AffectedTargets {
+		ips[t.ID] = t.IP
+	}
+
+	type summary struct {
+		maxLatency *float64
+		maxLoss    *float64
+		probes     int
+		successes  int
+	}
+	var order []string
+	byTarget := make(map[string]*summary)
+	for _, p := range pm.ProbeHistory {
+		sum, ok := byTarget[p.TargetID]
+		if !ok {
+			sum = &summary{}
+			byTarget[p.TargetID] = sum
+			order = append(order, p.TargetID)
+		}
+		if p.MaxLatencyMs != nil && (sum.maxLatency == nil || *p.MaxLatencyMs > *sum.maxLatency) {
+			sum.maxLatency = p.MaxLatencyMs
+		}
+		if p.PacketLossPct != nil && (sum.maxLoss == nil || *p.PacketLossPct > *sum.maxLoss) {
+			sum.maxLoss = p.PacketLossPct
+		}
+		sum.probes += p.TotalCount
+		sum.successes += p.SuccessCount
+	}
+
+	b.WriteString("| Target | Probes | Success | Max latency | Worst loss |\n|---|---|---|---|---|\n")
+	for _, id := range order {
+		sum := byTarget[id]
+		name := ips[id]
+		if name == "" {
+			name = id
+		}
+		success := 0.0
+		if sum.probes > 0 {
+			success = float64(sum.successes) / float64(sum.probes) * 100
+		}
+		fmt.Fprintf(b, "| %s | %d | %.1f%% | %s | %s |\n",
+			name, sum.probes, success,
+			formatPostmortemFloat(sum.maxLatency, " ms"), formatPostmortemFloat(sum.maxLoss, "%"))
+	}
+}
+
+func formatPostmortemTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func formatPostmortemFloat(v *float64, unit string) string {
+	if v == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f%s", *v, unit)
+}
+
+func escapeMarkdownCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestPostmortemWindow_Bounds(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	at := func(ago time.Duration) *time.Time {
+		ts := now.Add(-ago)
+		return &ts
+	}
+	pad := config.PostmortemWindowPadding
+
+	tests := []struct {
+		name       string
+		detected   time.Duration // before now
+		resolved   *time.Time
+		wantStart  time.Time
+		wantEnd    time.Time
+		wantBucket time.Duration
+	}{
+		{
+			name:       "resolved incident padded both sides",
+			detected:   3 * time.Hour,
+			resolved:   at(2 * time.Hour),
+			wantStart:  now.Add(-3*time.Hour - pad),
+			wantEnd:    now.Add(-2*time.Hour + pad),
+			wantBucket: config.PostmortemMinBucket,
+		},
+		{
+			name:       "recent resolution ends at now",
+			detected:   time.Hour,
+			resolved:   at(10 * time.Minute),
+			wantStart:  now.Add(-time.Hour - pad),
+			wantEnd:    now,
+			wantBucket: config.PostmortemMinBucket,
+		},
+		{
+			name:       "open incident capped",
+			detected:   30 * 24 * time.Hour,
+			wantStart:  now.Add(-config.PostmortemOpenWindowCap),
+			wantEnd:    now,
+			wantBucket: config.PostmortemOpenWindowCap/config.PostmortemMaxBuckets + config.PostmortemMinBucket,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inc := &store.Incident{DetectedAt: now.Add(-tt.detected), ResolvedAt: tt.resolved}
+			start, end, bucket := postmortemWindow(inc, now)
+			if !start.Equal(tt.wantStart) || !end.Equal(tt.wantEnd) {
+				t.Errorf("window = %s..%s, want %s..%s", start, end, tt.wantStart, tt.wantEnd)
+			}
+			if bucket != tt.wantBucket {
+				t.Errorf("bucket = %s, want %s", bucket, tt.wantBucket)
+			}
+			if n := end.Sub(start) / bucket; n > config.PostmortemMaxBuckets {
+				t.Errorf("window spans %d buckets, want at most %d", n, config.PostmortemMaxBuckets)
+			}
+		})
+	}
+}
+
+func TestBuildTimeline_Merge(t *testing.T) {
+	base := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	minute := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+	confirmed, resolved := minute(5), minute(60)
+	alertResolved := minute(55)
+
+	inc := &store.Incident{
+		IncidentType: "target",
+		Severity:     "high",
+		DetectedAt:   minute(0),
+		ConfirmedAt:  &confirmed,
+		ResolvedAt:   &resolved,
+	}
+
+	tests := []struct {
+		name   string
+		events []store.IncidentEvent
+		alerts []types.Alert
+		want   []string // source/event_type in order
+	}{
+		{
+			name: "lifecycle only",
+			want: []string{"incident/detected", "incident/confirmed", "incident/resolved"},
+		},
+		{
+			name:   "recorded event replaces lifecycle step",
+			events: []store.IncidentEvent{{EventType: "confirmed", Description: "wait elapsed", CreatedAt: minute(6)}},
+			want:   []string{"incident/detected", "incident/confirmed", "incident/resolved"},
+		},
+		{
+			name: "alerts interleaved",
+			alerts: []types.Alert{
+				{ID: "a1", Title: "loss", DetectedAt: minute(2), ResolvedAt: &alertResolved},
+			},
+			want: []string{
+				"incident/detected", "alert/alert_detected", "incident/confirmed",
+				"alert/alert_resolved", "incident/resolved",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			timeline := buildTimeline(inc, tt.events, tt.alerts)
+			var got []string
+			for _, e := range timeline {
+				got = append(got, e.Source+"/"+e.EventType)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("timeline = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderPostmortemMarkdown_Sections(t *testing.T) {
+	detected := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	latency, loss := 180.5, 12.0
+
+	full := &IncidentPostmortem{
+		Incident: &store.Incident{
+			ID:           "inc-1",
+			IncidentType: "target",
+			Severity:     "high",
+			Status:       "resolved",
+			DetectedAt:   detected,
+			Notes:        "upstream fiber cut",
+		},
+		BaselineSnapshot: json.RawMessage(`{"p95_ms":20}`),
+		Duration:         "1h0m0s",
+		AffectedTargets:  []store.IncidentTarget{{ID: "t1", IP: "10.0.0.1", Tier: "vip"}},
+		AffectedAgents:   []store.IncidentAgent{{ID: "ag1", Name: "agent-a", Region: "us-east"}},
+		Peaks:            PostmortemPeaks{LatencyMs: &latency},
+		ProbeHistory: []store.TargetHistoryPoint{
+			{TargetID: "t1", ProbeHistoryPoint: store.ProbeHistoryPoint{MaxLatencyMs: &latency, PacketLossPct: &loss, SuccessCount: 9, TotalCount: 10}},
+		},
+		Alerts: []types.Alert{{Title: "a|b", TargetIP: "10.0.0.1", DetectedAt: detected}},
+	}
+	empty := &IncidentPostmortem{
+		Incident: &store.Incident{ID: "inc-2", DetectedAt: detected},
+	}
+
+	tests := []struct {
+		name    string
+		pm      *IncidentPostmortem
+		want    []string
+		notWant []string
+	}{
+		{
+			name: "full",
+			pm:   full,
+			want: []string{
+				"# Incident postmortem: inc-1",
+				"- Latency: 180.50 ms",
+				"- 10.0.0.1 (vip)",
+				"- agent-a (us-east)",
+				"| 10.0.0.1 | 10 | 90.0% | 180.50 ms | 12.00% |",
+				`a\|b`,
+				"## Baseline snapshot",
+				"## Notes\n\nupstream fiber cut",
+			},
+		},
+		{
+			name:    "empty sections",
+			pm:      empty,
+			want:    []string{"No events recorded.", "No probe results in the window.", "- Z-score: n/a"},
+			notWant: []string{"## Baseline snapshot", "## Notes"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := RenderPostmortemMarkdown(tt.pm)
+			for _, s := range tt.want {
+				if !strings.Contains(md, s) {
+					t.Errorf("markdown missing %q:\n%s", s, md)
+				}
+			}
+			for _, s := range tt.notWant {
+				if strings.Contains(md, s) {
+					t.Errorf("markdown unexpectedly contains %q", s)
+				}
+			}
+		})
+	}
+}
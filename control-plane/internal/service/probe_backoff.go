@@ -0,0 +1,23 @@
+package service
+
+import (
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// downBackoff returns the cap agents may back off a target's probe interval
+// to, or zero to probe it at the tier interval. Only targets held down or
+// excluded back off; one answering a probe is left to the state machine to
+// bring back, and the agent has already reset its interval by then.
+func downBackoff(target *types.Target, tier *types.Tier) time.Duration {
+	switch target.MonitoringState {
+	case types.StateDown, types.StateExcluded:
+	default:
+		return 0
+	}
+	if tier.DownBackoffMaxInterval <= tier.ProbeInterval {
+		return 0
+	}
+	return tier.DownBackoffMaxInterval
+}
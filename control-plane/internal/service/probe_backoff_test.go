@@ -0,0 +1,39 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestDownBackoff_States(t *testing.T) {
+	backoffTier := types.Tier{ProbeInterval: 30 * time.Second, DownBackoffMaxInterval: 10 * time.Minute}
+
+	tests := []struct {
+		name  string
+		state types.MonitoringState
+		tier  types.Tier
+		want  time.Duration
+	}{
+		{name: "down", state: types.StateDown, tier: backoffTier, want: 10 * time.Minute},
+		{name: "excluded", state: types.StateExcluded, tier: backoffTier, want: 10 * time.Minute},
+		{name: "active", state: types.StateActive, tier: backoffTier},
+		{name: "degraded", state: types.StateDegraded, tier: backoffTier},
+		{name: "unknown", state: types.StateUnknown, tier: backoffTier},
+		{name: "tier without backoff", state: types.StateDown, tier: types.Tier{ProbeInterval: 30 * time.Second}},
+		{
+			name: "cap not above interval", state: types.StateExcluded,
+			tier: types.Tier{ProbeInterval: 24 * time.Hour, DownBackoffMaxInterval: 10 * time.Minute},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := &types.Target{MonitoringState: tt.state}
+			if got := downBackoff(target, &tt.tier); got != tt.want {
+				t.Errorf("downBackoff() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// =============================================================================
+// PROMETHEUS EXPOSITION
+// =============================================================================
+
+// PrometheusContentType is the content type of PrometheusMetrics' output,
+// the Prometheus text exposition format.
+const PrometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// PrometheusMetrics renders target and fleet gauges in the Prometheus text
+// format: per-target up and agent counts from the target statuses, latency
+// and loss per agent region over the same window, and the fleet overview.
+func (s *Service) PrometheusMetrics(ctx context.Context) ([]byte, error) {
+	statuses, err := s.GetAllTargetStatuses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting target statuses: %w", err)
+	}
+	regions, err := s.store.GetTargetRegionStats(ctx, config.TargetStatusWindow)
+	if err != nil {
+		return nil, err
+	}
+	overview, err := s.store.GetFleetOverview(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("getting fleet overview: %w", err)
+	}
+	return renderPrometheus(statuses, regions, overview), nil
+}
+
+// renderPrometheus writes the exposition. Targets with no results in the
+// window have no up gauge, since unknown is neither up nor down, and
+// latency is left out where no probe succeeded, as is the health score
+// of a target no agent probed.
+func renderPrometheus(statuses []store.TargetStatus, regions []store.TargetRegionStats, overview *store.FleetOverview) []byte {
+	var p promWriter
+
+	byID := make(map[string]store.TargetStatus, len(statuses))
+	for _, st := range statuses {
+		byID[st.TargetID] = st
+	}
+	targetLabels := func(st store.TargetStatus, extra ...string) []string {
+		return append([]string{"target_id", st.TargetID, "ip", st.IP, "tier", st.Tier}, extra...)
+	}
+
+	p.family("icmpmon_target_up", "Whether enough agents reach the target for its tier (1) or not (0).")
+	for _, st := range statuses {
+		switch st.Status {
+		case "healthy":
+			p.sample("icmpmon_target_up", targetLabels(st), 1)
+		case "down":
+			p.sample("icmpmon_target_up", targetLabels(st), 0)
+		}
+	}
+
+	p.family("icmpmon_target_agents", "Agents that probed the target in the status window.")
+	for _, st := range statuses {
+		p.sample("icmpmon_target_agents", targetLabels(st), float64(st.TotalAgents))
+	}
+	p.family("icmpmon_target_agents_reachable", "Agents with a successful probe of the target in the status window.")
+	for _, st := range statuses {
+		p.sample("icmpmon_target_agents_reachable", targetLabels(st), float64(st.ReachableAgents))
+	}
+	p.family("icmpmon_target_health_score", "Weighted health score of the target, 0 (worst) to 100.")
+	for _, st := range statuses {
+		if st.HealthScore != nil {
+			p.sample("icmpmon_target_health_score", targetLabels(st), float64(st.HealthScore.Score))
+		}
+	}
+
+	p.family("icmpmon_target_latency_ms", "Average latency of successful probes, per agent region.")
+	for _, r := range regions {
+		if st, ok := byID[r.TargetID]; ok && r.AvgLatencyMs != nil {
+			p.sample("icmpmon_target_latency_ms", targetLabels(st, "agent_region", r.AgentRegion), *r.AvgLatencyMs)
+		}
+	}
+	p.family("icmpmon_target_packet_loss_pct", "Average packet loss, per agent region.")
+	for _, r := range regions {
+		if st, ok := byID[r.TargetID]; ok && r.PacketLossPct != nil {
+			p.sample("icmpmon_target_packet_loss_pct", targetLabels(st, "agent_region", r.AgentRegion), *r.PacketLossPct)
+		}
+	}
+
+	if overview != nil {
+		p.family("icmpmon_fleet_agents", "Agents by heartbeat status.")
+		p.sample("icmpmon_fleet_agents", []string{"status", "active"}, float64(overview.ActiveAgents))
+		p.sample("icmpmon_fleet_agents", []string{"status", "degraded"}, float64(overview.DegradedAgents))
+		p.sample("icmpmon_fleet_agents", []string{"status", "offline"}, float64(overview.OfflineAgents))
+
+		p.gauge("icmpmon_fleet_targets", "Targets not archived.", float64(overview.TotalTargets))
+		p.gauge("icmpmon_fleet_targets_active", "Targets in the active monitoring state.", float64(overview.TotalActiveTargets))
+		p.gauge("icmpmon_fleet_targets_monitorable", "Targets with a baseline in the active, degraded or down state.", float64(overview.MonitorableTargets))
+		p.gauge("icmpmon_fleet_targets_healthy", "Monitorable targets in the active state.", float64(overview.HealthyTargets))
+		p.gauge("icmpmon_fleet_health_pct", "Healthy targets as a percentage of monitorable ones.", overview.HealthPercentage)
+		p.gauge("icmpmon_fleet_probes_per_second", "Probes per second across active agents.", overview.TotalProbesPerSec)
+		p.gauge("icmpmon_fleet_results_queued", "Results queued on agents awaiting shipment.", float64(overview.TotalResultsQueued))
+	}
+
+	return p.buf.Bytes()
+}
+
+// promWriter writes gauges in the Prometheus text format.
+type promWriter struct {
+	buf bytes.Buffer
+}
+
+// family starts a gauge metric family.
+func (p *promWriter) family(name, help string) {
+	fmt.Fprintf(&p.buf, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+}
+
+// gauge writes a family with a single unlabelled sample.
+func (p *promWriter) gauge(name, help string, value float64) {
+	p.family(name, help)
+	p.sample(name, nil, value)
+}
+
+// sample writes one sample; labels alternate names and values.
+func (p *promWriter) sample(name string, labels []string, value float64) {
+	p.buf.WriteString(name)
+	if len(labels) > 0 {
+		p.buf.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				p.buf.WriteByte(',')
+			}
+			fmt.Fprintf(&p.buf, "%s=\"%s\"", labels[i], promLabelEscaper.Replace(labels[i+1]))
+		}
+		p.buf.WriteByte('}')
+	}
+	p.buf.WriteByte(' ')
+	p.buf.WriteString(strconv.FormatFloat(value, 'g', -1, 64))
+	p.buf.WriteByte('\n')
+}
+
+// promLabelEscaper escapes a label value for the text format.
+var promLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
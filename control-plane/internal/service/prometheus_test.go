@@ -0,0 +1,55 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestRenderPrometheus_Samples(t *testing.T) {
+	ms := func(v float64) *float64 { return &v }
+
+	statuses := []store.TargetStatus{
+		{TargetID: "t1", IP: "192.0.2.1", Tier: "vip", Status: "healthy", TotalAgents: 3, ReachableAgents: 3, HealthScore: &types.HealthScore{Score: 97}},
+		{TargetID: "t2", IP: "192.0.2.2", Tier: "standard", Status: "down", TotalAgents: 2},
+		{TargetID: "t3", IP: "192.0.2.3", Tier: "standard", Status: "unknown"},
+	}
+	regions := []store.TargetRegionStats{
+		{TargetID: "t1", AgentRegion: "us-east", AvgLatencyMs: ms(12.5), PacketLossPct: ms(0)},
+		{TargetID: "t2", AgentRegion: `we"ird`, PacketLossPct: ms(100)},
+		{TargetID: "gone", AgentRegion: "us-east", AvgLatencyMs: ms(1), PacketLossPct: ms(0)},
+	}
+	overview := &store.FleetOverview{ActiveAgents: 4, OfflineAgents: 1, HealthPercentage: 87.5}
+
+	out := string(renderPrometheus(statuses, regions, overview))
+
+	tests := []struct {
+		name    string
+		line    string
+		present bool
+	}{
+		{name: "healthy target up", line: `icmpmon_target_up{target_id="t1",ip="192.0.2.1",tier="vip"} 1`, present: true},
+		{name: "down target not up", line: `icmpmon_target_up{target_id="t2",ip="192.0.2.2",tier="standard"} 0`, present: true},
+		{name: "unknown target has no up gauge", line: `icmpmon_target_up{target_id="t3"`},
+		{name: "agents reachable", line: `icmpmon_target_agents_reachable{target_id="t1",ip="192.0.2.1",tier="vip"} 3`, present: true},
+		{name: "health score", line: `icmpmon_target_health_score{target_id="t1",ip="192.0.2.1",tier="vip"} 97`, present: true},
+		{name: "no health score unprobed", line: `icmpmon_target_health_score{target_id="t3"`},
+		{name: "latency per region", line: `icmpmon_target_latency_ms{target_id="t1",ip="192.0.2.1",tier="vip",agent_region="us-east"} 12.5`, present: true},
+		{name: "no latency without successes", line: `icmpmon_target_latency_ms{target_id="t2"`},
+		{name: "escaped region label", line: `icmpmon_target_packet_loss_pct{target_id="t2",ip="192.0.2.2",tier="standard",agent_region="we\"ird"} 100`, present: true},
+		{name: "region of unlisted target dropped", line: `target_id="gone"`},
+		{name: "fleet agents by status", line: `icmpmon_fleet_agents{status="offline"} 1`, present: true},
+		{name: "fleet health", line: "icmpmon_fleet_health_pct 87.5", present: true},
+		{name: "family type", line: "# TYPE icmpmon_target_latency_ms gauge", present: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := strings.Contains(out, tt.line); got != tt.present {
+				t.Errorf("output contains %q = %v, want %v\n%s", tt.line, got, tt.present, out)
+			}
+		})
+	}
+}
@@ -73,6 +73,11 @@ func (r *Rebalancer) HandleAgentFailure(ctx context.Context, failedAgentID strin
 		tierMap[tier.Name] = tier
 	}
 
+	rules, err := r.store.ListAffinityRules(ctx, true)
+	if err != nil {
+		return fmt.Errorf("listing affinity rules: %w", err)
+	}
+
 	// Redistribute each assignment
 	reassigned := 0
 	for _, assignment := range assignments {
@@ -95,12 +100,18 @@ func (r *Rebalancer) HandleAgentFailure(ctx context.Context, failedAgentID strin
 			continue
 		}
 
-		// Filter eligible agents based on tier policy
-		eligibleAgents := r.filterAgents(activeAgents, tier.AgentSelection)
+		// Disabled targets shouldn't have assignments; don't hand them a new agent
+		if !target.ProbingEnabled {
+			continue
+		}
+
+		// Filter eligible agents based on tier policy and affinity rules
+		eligibleAgents, unsatisfied := applyAffinityRules(rules, *target, r.filterAgents(activeAgents, tier.AgentSelection))
 		if len(eligibleAgents) == 0 {
 			r.logger.Warn("no eligible agents for target",
 				"target_id", assignment.TargetID,
 				"tier", tier.Name,
+				"unsatisfied_affinity_rules", unsatisfied,
 			)
 			continue
 		}
@@ -197,6 +208,11 @@ func (r *Rebalancer) HandleAgentRecovery(ctx context.Context, agentID string) er
 		}
 	}
 
+	rules, err := r.store.ListAffinityRules(ctx, true)
+	if err != nil {
+		return fmt.Errorf("listing affinity rules: %w", err)
+	}
+
 	assigned := 0
 
 	// For each tier where this agent is eligible
@@ -217,6 +233,15 @@ func (r *Rebalancer) HandleAgentRecovery(ctx context.Context, agentID string) er
 
 		// For each target, check if it needs more agents or could benefit from this one
 		for _, target := range targets {
+			if !target.ProbingEnabled {
+				continue
+			}
+
+			// Skip targets the affinity rules keep this agent off
+			if !agentAllowedByAffinity(rules, target, r.filterAgents(activeAgents, tier.AgentSelection), agentID) {
+				continue
+			}
+
 			// Get current active assignments for this target
 			currentAssignments, err := r.store.GetActiveAssignmentsByTarget(ctx, target.ID)
 			if err != nil {
@@ -324,10 +349,16 @@ func (r *Rebalancer) MaterializeAllAssignmentsWithCount(ctx context.Context) (in
 		tierMap[tier.Name] = tier
 	}
 
+	rules, err := r.store.ListAffinityRules(ctx, true)
+	if err != nil {
+		return 0, fmt.Errorf("listing affinity rules: %w", err)
+	}
+
 	r.logger.Info("computing assignments",
 		"targets", len(targets),
 		"active_agents", len(activeAgents),
 		"tiers", len(tiers),
+		"affinity_rules", len(rules),
 	)
 
 	// Collect all assignments in memory first
@@ -335,8 +366,8 @@ func (r *Rebalancer) MaterializeAllAssignmentsWithCount(ctx context.Context) (in
 	skipped := 0
 
 	for _, target := range targets {
-		// Skip archived/inactive targets
-		if target.ArchivedAt != nil {
+		// Skip archived/inactive and probing-disabled targets
+		if target.ArchivedAt != nil || !target.ProbingEnabled {
 			skipped++
 			continue
 		}
@@ -347,23 +378,7 @@ func (r *Rebalancer) MaterializeAllAssignmentsWithCount(ctx context.Context) (in
 			continue
 		}
 
-		// Filter eligible agents
-		eligibleAgents := r.filterAgents(activeAgents, tier.AgentSelection)
-		if len(eligibleAgents) == 0 {
-			continue
-		}
-
-		// Select agents based on strategy
-		var selectedAgents []types.Agent
-		if tier.AgentSelection.Strategy == "all" {
-			selectedAgents = eligibleAgents
-		} else {
-			count := tier.AgentSelection.Count
-			if count == 0 {
-				count = 4 // Default
-			}
-			selectedAgents = r.selectAgentsForTarget(target, eligibleAgents, count, tier.AgentSelection.Diversity)
-		}
+		selectedAgents := r.selectAgentsForTier(target, tier, activeAgents, rules)
 
 		// Collect assignments
 		for _, agent := range selectedAgents {
@@ -418,10 +433,122 @@ func (r *Rebalancer) MaterializeAllAssignmentsWithCount(ctx context.Context) (in
 	return created, nil
 }
 
+// AssignTarget materializes assignments for a single target, used when its
+// probing is re-enabled. Returns the number of agents assigned.
+func (r *Rebalancer) AssignTarget(ctx context.Context, targetID string) (int, error) {
+	target, err := r.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return 0, fmt.Errorf("getting target: %w", err)
+	}
+	if target == nil || target.ArchivedAt != nil || !target.ProbingEnabled {
+		return 0, nil
+	}
+
+	tier, err := r.store.GetTier(ctx, target.Tier)
+	if err != nil {
+		return 0, fmt.Errorf("getting tier: %w", err)
+	}
+	if tier == nil {
+		return 0, fmt.Errorf("tier not found: %s", target.Tier)
+	}
+
+	allAgents, err := r.store.ListAgentsWithStatus(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("listing agents: %w", err)
+	}
+	activeAgents := make([]types.Agent, 0)
+	for _, a := range allAgents {
+		if a.Status == types.AgentStatusActive {
+			activeAgents = append(activeAgents, a)
+		}
+	}
+
+	rules, err := r.store.ListAffinityRules(ctx, true)
+	if err != nil {
+		return 0, fmt.Errorf("listing affinity rules: %w", err)
+	}
+
+	assigned := 0
+	for _, agent := range r.selectAgentsForTier(*target, *tier, activeAgents, rules) {
+		if err := r.store.CreateAssignment(ctx, &types.TargetAssignment{
+			TargetID:   target.ID,
+			AgentID:    agent.ID,
+			Tier:       tier.Name,
+			AssignedBy: types.AssignedByManual,
+		}); err != nil {
+			return assigned, fmt.Errorf("creating assignment: %w", err)
+		}
+
+		r.store.LogAssignmentHistory(ctx, &types.AssignmentHistory{
+			TargetID: target.ID,
+			AgentID:  agent.ID,
+			Action:   types.AssignmentActionAssigned,
+			Reason:   "probing enabled",
+		})
+		assigned++
+	}
+
+	r.logger.Info("target assigned", "target_id", target.ID, "agents", assigned)
+	return assigned, nil
+}
+
+// UnassignTarget removes all of a target's assignments, used when its
+// probing is disabled. Returns the number of assignments removed.
+func (r *Rebalancer) UnassignTarget(ctx context.Context, targetID string) (int, error) {
+	assignments, err := r.store.GetAssignmentsByTarget(ctx, targetID)
+	if err != nil {
+		return 0, fmt.Errorf("getting assignments: %w", err)
+	}
+
+	removed := 0
+	for _, a := range assignments {
+		if err := r.store.DeleteAssignment(ctx, a.ID); err != nil {
+			return removed, fmt.Errorf("deleting assignment: %w", err)
+		}
+
+		r.store.LogAssignmentHistory(ctx, &types.AssignmentHistory{
+			TargetID: targetID,
+			AgentID:  a.AgentID,
+			Action:   types.AssignmentActionUnassigned,
+			Reason:   "probing disabled",
+		})
+		removed++
+	}
+
+	r.logger.Info("target unassigned", "target_id", targetID, "assignments", removed)
+	return removed, nil
+}
+
 // =============================================================================
 // HELPER METHODS (duplicated from service.go for independence)
 // =============================================================================
 
+// selectAgentsForTier picks the agents that should probe a target under its
+// tier's selection policy and the affinity rules.
+func (r *Rebalancer) selectAgentsForTier(target types.Target, tier types.Tier, activeAgents []types.Agent, rules []types.AffinityRule) []types.Agent {
+	eligibleAgents, unsatisfied := applyAffinityRules(rules, target, r.filterAgents(activeAgents, tier.AgentSelection))
+	if len(eligibleAgents) == 0 {
+		if len(unsatisfied) > 0 {
+			r.logger.Warn("affinity rules leave target unassigned",
+				"target_id", target.ID,
+				"tier", tier.Name,
+				"rule_ids", unsatisfied,
+			)
+		}
+		return nil
+	}
+
+	if tier.AgentSelection.Strategy == "all" {
+		return eligibleAgents
+	}
+
+	count := tier.AgentSelection.Count
+	if count == 0 {
+		count = 4 // Default
+	}
+	return r.selectAgentsForTarget(target, eligibleAgents, count, tier.AgentSelection.Diversity)
+}
+
 // filterAgents returns agents matching the selection policy.
 func (r *Rebalancer) filterAgents(agents []types.Agent, policy types.AgentSelectionPolicy) []types.Agent {
 	var filtered []types.Agent
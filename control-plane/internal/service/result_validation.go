@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/payload"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// RESULT BATCH VALIDATION
+// =============================================================================
+
+// Reasons a probe result is rejected at ingest, reported back to the agent.
+const (
+	RejectUnknownAgent      = "unknown_agent"
+	RejectInvalidTargetID   = "invalid_target_id"
+	RejectInvalidEndpointID = "invalid_endpoint_id"
+	RejectUnknownTarget     = "unknown_target"
+	RejectMissingTimestamp  = "missing_timestamp"
+	RejectFutureTimestamp   = "future_timestamp"
+	RejectStaleTimestamp    = "stale_timestamp"
+	RejectNegativeLatency   = "negative_latency"
+	RejectInvalidPacketLoss = "invalid_packet_loss"
+)
+
+// ResultValidation bounds the timestamps IngestResults accepts.
+type ResultValidation struct {
+	// MaxClockSkew is how far in the future a result may be stamped.
+	// Results within it are clamped to the server's time; later ones are
+	// rejected.
+	MaxClockSkew time.Duration
+
+	// MaxAge is the oldest result accepted; zero accepts any age.
+	MaxAge time.Duration
+}
+
+// DefaultResultValidation returns the validation used unless configured.
+func DefaultResultValidation() ResultValidation {
+	return ResultValidation{
+		MaxClockSkew: config.ResultMaxClockSkew,
+		MaxAge:       config.ResultMaxAge,
+	}
+}
+
+// SetResultValidation overrides the result timestamp bounds.
+func (s *Service) SetResultValidation(v ResultValidation) {
+	s.validation = v
+}
+
+// IngestSummary reports what happened to a result batch. Clamped counts
+// accepted results whose future timestamps were pulled back to server time;
+// Deduplicated counts the valid results of a replayed batch, which are not
+// stored again. QueueDepth is the result buffer's length after the batch,
+// nil when results are written directly.
+type IngestSummary struct {
+	Accepted     int
+	Rejected     int
+	Clamped      int
+	Reasons      map[string]int
+	Duplicate    bool
+	Deduplicated int
+	QueueDepth   *int64
+}
+
+// Response converts the summary to the reply sent to the agent.
+func (s *IngestSummary) Response() types.IngestResponse {
+	return types.IngestResponse{
+		Accepted:     s.Accepted,
+		Rejected:     s.Rejected,
+		Reasons:      s.Reasons,
+		Clamped:      s.Clamped,
+		Duplicate:    s.Duplicate,
+		Deduplicated: s.Deduplicated,
+		QueueDepth:   s.QueueDepth,
+	}
+}
+
+// bufferDepth returns the result buffer's length, or nil without a buffer
+// or if it can't be read; the depth is informational only.
+func (s *Service) bufferDepth(ctx context.Context) *int64 {
+	if s.resultBuffer == nil {
+		return nil
+	}
+	n, err := s.resultBuffer.Len(ctx)
+	if err != nil {
+		s.logger.Debug("failed to read result buffer depth", "error", err)
+		return nil
+	}
+	return &n
+}
+
+// rejectedResult is a result dropped at ingest, kept for logging.
+type rejectedResult struct {
+	TargetID string
+	Reason   string
+}
+
+// checkResult returns why a result should be rejected, or "" if it is valid.
+// Target existence is checked separately against the store.
+func (v ResultValidation) checkResult(r types.ProbeResult, now time.Time) string {
+	if _, err := uuid.Parse(r.TargetID); err != nil {
+		return RejectInvalidTargetID
+	}
+	if r.EndpointID != "" {
+		if _, err := uuid.Parse(r.EndpointID); err != nil {
+			return RejectInvalidEndpointID
+		}
+	}
+	switch {
+	case r.Timestamp.IsZero():
+		return RejectMissingTimestamp
+	case r.Timestamp.After(now.Add(v.MaxClockSkew)):
+		return RejectFutureTimestamp
+	case v.MaxAge > 0 && r.Timestamp.Before(now.Add(-v.MaxAge)):
+		return RejectStaleTimestamp
+	}
+
+	// Executors report latency under different names; any present must be
+	// non-negative
+	m := payload.Decode(r.Payload)
+	for _, lat := range []*float64{m.AvgMs, m.MinMs, m.MaxMs, m.LatencyMs} {
+		if lat != nil && *lat < 0 {
+			return RejectNegativeLatency
+		}
+	}
+	if m.PacketLossPct != nil && (*m.PacketLossPct < 0 || *m.PacketLossPct > 100) {
+		return RejectInvalidPacketLoss
+	}
+	return ""
+}
+
+// partitionResults splits results into valid ones and rejections. known
+// holds the canonical IDs of targets that exist.
+func (v ResultValidation) partitionResults(results []types.ProbeResult, known map[string]bool, now time.Time) ([]types.ProbeResult, []rejectedResult) {
+	valid := make([]types.ProbeResult, 0, len(results))
+	var rejected []rejectedResult
+	for _, r := range results {
+		reason := v.checkResult(r, now)
+		if reason == "" && !known[uuid.MustParse(r.TargetID).String()] {
+			reason = RejectUnknownTarget
+		}
+		if reason != "" {
+			rejected = append(rejected, rejectedResult{TargetID: r.TargetID, Reason: reason})
+			continue
+		}
+		valid = append(valid, r)
+	}
+	return valid, rejected
+}
+
+// clampFutureResults pulls timestamps ahead of now back to now, so a fast
+// agent clock can't put results in a time bucket that hasn't happened yet
+// or make a target look probed later than it was. It returns how many
+// results were clamped.
+func clampFutureResults(results []types.ProbeResult, now time.Time) int {
+	var clamped int
+	for i := range results {
+		if results[i].Timestamp.After(now) {
+			results[i].Timestamp = now
+			clamped++
+		}
+	}
+	return clamped
+}
+
+// clockAhead returns how far the newest result in a batch is ahead of now,
+// or zero when none are.
+func clockAhead(results []types.ProbeResult, now time.Time) time.Duration {
+	var ahead time.Duration
+	for _, r := range results {
+		ahead = max(ahead, r.Timestamp.Sub(now))
+	}
+	return ahead
+}
+
+// validateResults drops results that are malformed or reference unknown
+// agents or targets, logging a sample of the rejections.
+func (s *Service) validateResults(ctx context.Context, batch types.ResultBatch) ([]types.ProbeResult, []rejectedResult, error) {
+	agentKnown := false
+	if _, err := uuid.Parse(batch.AgentID); err == nil {
+		if agentKnown, err = s.store.AgentExists(ctx, batch.AgentID); err != nil {
+			return nil, nil, err
+		}
+	}
+	if !agentKnown {
+		rejected := make([]rejectedResult, len(batch.Results))
+		for i, r := range batch.Results {
+			rejected[i] = rejectedResult{TargetID: r.TargetID, Reason: RejectUnknownAgent}
+		}
+		s.logRejections(batch, rejected)
+		return nil, rejected, nil
+	}
+
+	var targetIDs []string
+	seen := make(map[string]bool)
+	for _, r := range batch.Results {
+		id, err := uuid.Parse(r.TargetID)
+		if err != nil || seen[id.String()] {
+			continue
+		}
+		seen[id.String()] = true
+		targetIDs = append(targetIDs, id.String())
+	}
+	known := map[string]bool{}
+	if len(targetIDs) > 0 {
+		var err error
+		if known, err = s.store.ExistingTargetIDs(ctx, targetIDs); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := time.Now()
+	if ahead := clockAhead(batch.Results, now); ahead > config.ResultClockAheadLogThreshold {
+		s.logger.Warn("agent clock ahead of server",
+			"agent", batch.AgentID,
+			"batch_id", batch.BatchID,
+			"ahead", ahead,
+			"max_clock_skew", s.validation.MaxClockSkew)
+	}
+
+	valid, rejected := s.validation.partitionResults(batch.Results, known, now)
+	if len(rejected) > 0 {
+		s.logRejections(batch, rejected)
+	}
+	return valid, rejected, nil
+}
+
+func (s *Service) logRejections(batch types.ResultBatch, rejected []rejectedResult) {
+	sample := rejected
+	if len(sample) > config.ResultRejectionLogSample {
+		sample = sample[:config.ResultRejectionLogSample]
+	}
+	s.logger.Warn("rejected probe results",
+		"agent", batch.AgentID,
+		"batch_id", batch.BatchID,
+		"rejected", len(rejected),
+		"total", len(batch.Results),
+		"sample", sample)
+}
+
+// countReasons tallies rejections by reason.
+func countReasons(rejected []rejectedResult) map[string]int {
+	reasons := make(map[string]int)
+	for _, r := range rejected {
+		reasons[r.Reason]++
+	}
+	return reasons
+}
@@ -0,0 +1,134 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestPartitionResults_Reasons(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	const (
+		known   = "6f1c0a52-3f4e-4c8b-9a57-0f1e2d3c4b5a"
+		unknown = "0b7e9c1d-2a3f-4e5d-8c6b-7a8f9e0d1c2b"
+	)
+	v := ResultValidation{MaxClockSkew: 5 * time.Minute, MaxAge: 24 * time.Hour}
+
+	tests := []struct {
+		name   string
+		result types.ProbeResult
+		want   string
+	}{
+		{"valid", types.ProbeResult{TargetID: known, Timestamp: now, Payload: json.RawMessage(`{"avg_ms":12.5,"packet_loss_pct":0}`)}, ""},
+		{"uppercase target id", types.ProbeResult{TargetID: "6F1C0A52-3F4E-4C8B-9A57-0F1E2D3C4B5A", Timestamp: now}, ""},
+		{"within clock skew", types.ProbeResult{TargetID: known, Timestamp: now.Add(4 * time.Minute)}, ""},
+		{"malformed target id", types.ProbeResult{TargetID: "10.0.0.1", Timestamp: now}, RejectInvalidTargetID},
+		{"malformed endpoint id", types.ProbeResult{TargetID: known, EndpointID: "v6", Timestamp: now}, RejectInvalidEndpointID},
+		{"unknown target", types.ProbeResult{TargetID: unknown, Timestamp: now}, RejectUnknownTarget},
+		{"missing timestamp", types.ProbeResult{TargetID: known}, RejectMissingTimestamp},
+		{"future timestamp", types.ProbeResult{TargetID: known, Timestamp: now.Add(time.Hour)}, RejectFutureTimestamp},
+		{"stale timestamp", types.ProbeResult{TargetID: known, Timestamp: now.Add(-48 * time.Hour)}, RejectStaleTimestamp},
+		{"negative latency", types.ProbeResult{TargetID: known, Timestamp: now, Payload: json.RawMessage(`{"min_ms":-1}`)}, RejectNegativeLatency},
+		{"loss over 100", types.ProbeResult{TargetID: known, Timestamp: now, Payload: json.RawMessage(`{"packet_loss_pct":150}`)}, RejectInvalidPacketLoss},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			valid, rejected := v.partitionResults([]types.ProbeResult{tt.result}, map[string]bool{known: true}, now)
+			var got string
+			if len(rejected) > 0 {
+				got = rejected[0].Reason
+			}
+			if got != tt.want {
+				t.Errorf("reason = %q, want %q", got, tt.want)
+			}
+			if len(valid)+len(rejected) != 1 {
+				t.Errorf("got %d valid + %d rejected, want 1 total", len(valid), len(rejected))
+			}
+		})
+	}
+}
+
+func TestPartitionResults_NoMaxAge(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	const target = "6f1c0a52-3f4e-4c8b-9a57-0f1e2d3c4b5a"
+
+	tests := []struct {
+		name   string
+		maxAge time.Duration
+		want   int // valid results
+	}{
+		{"age limited", 24 * time.Hour, 0},
+		{"age unlimited", 0, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := ResultValidation{MaxClockSkew: time.Minute, MaxAge: tt.maxAge}
+			results := []types.ProbeResult{{TargetID: target, Timestamp: now.AddDate(0, -1, 0)}}
+			valid, _ := v.partitionResults(results, map[string]bool{target: true}, now)
+			if len(valid) != tt.want {
+				t.Errorf("valid = %d, want %d", len(valid), tt.want)
+			}
+		})
+	}
+}
+
+func TestClampFutureResults_Batch(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+	const target = "6f1c0a52-3f4e-4c8b-9a57-0f1e2d3c4b5a"
+	v := ResultValidation{MaxClockSkew: 5 * time.Minute}
+
+	// A batch from an agent whose clock jumped forward mid-batch
+	batch := []types.ProbeResult{
+		{TargetID: target, Timestamp: now.Add(-30 * time.Second)},
+		{TargetID: target, Timestamp: now},
+		{TargetID: target, Timestamp: now.Add(2 * time.Second)},
+		{TargetID: target, Timestamp: now.Add(4 * time.Minute)},
+		{TargetID: target, Timestamp: now.Add(2 * time.Hour)},
+	}
+
+	if got, want := clockAhead(batch, now), 2*time.Hour; got != want {
+		t.Errorf("clockAhead = %v, want %v", got, want)
+	}
+
+	valid, rejected := v.partitionResults(batch, map[string]bool{target: true}, now)
+	if len(rejected) != 1 || rejected[0].Reason != RejectFutureTimestamp {
+		t.Fatalf("rejected = %+v, want one %s", rejected, RejectFutureTimestamp)
+	}
+
+	if got := clampFutureResults(valid, now); got != 2 {
+		t.Errorf("clamped = %d, want 2", got)
+	}
+	want := []time.Time{now.Add(-30 * time.Second), now, now, now}
+	for i, r := range valid {
+		if !r.Timestamp.Equal(want[i]) {
+			t.Errorf("result %d timestamp = %v, want %v", i, r.Timestamp, want[i])
+		}
+	}
+}
+
+func TestClockAhead_Cases(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		times []time.Time
+		want  time.Duration
+	}{
+		{"empty", nil, 0},
+		{"all past", []time.Time{now.Add(-time.Minute), now}, 0},
+		{"newest ahead", []time.Time{now.Add(-time.Minute), now.Add(10 * time.Second), now.Add(time.Second)}, 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results := make([]types.ProbeResult, len(tt.times))
+			for i, ts := range tt.times {
+				results[i].Timestamp = ts
+			}
+			if got := clockAhead(results, now); got != tt.want {
+				t.Errorf("clockAhead = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
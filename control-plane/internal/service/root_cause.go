@@ -0,0 +1,214 @@
+// Package service - Root-cause ranking for correlated alerts
+package service
+
+import (
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// rootCauseWeights orders entity types by how directly a shared entity
+// explains its alerts: a subnet is more specific than the agent observing
+// it, which is more specific than a region or an upstream gateway.
+var rootCauseWeights = map[string]float64{
+	types.RootCauseSubnet:   1.0,
+	types.RootCauseAgent:    0.85,
+	types.RootCauseRegion:   0.7,
+	types.RootCauseUpstream: 0.55,
+}
+
+// maxProbableCauses caps the ranked list returned to the UI.
+const maxProbableCauses = 10
+
+// alertCluster is a candidate cause being scored.
+type alertCluster struct {
+	entity types.RootCauseEntity
+	key    string
+	alerts []types.Alert
+	ids    map[string]bool
+}
+
+// rankProbableCauses groups active alerts by shared subnet, agent, region
+// and upstream gateway and ranks the groups by likely root cause.
+//
+// Confidence is the entity-type weight scaled by how many distinct targets
+// the cluster spans (two targets is suggestive, ten is convincing). Agent
+// clusters are further scaled by how many of their targets no other agent
+// is alerting on: if every agent sees the same targets fail, the agent is
+// a witness, not the cause. A cluster whose alerts all fall inside a single
+// higher-precedence cluster is dropped as already explained.
+func rankProbableCauses(alerts []types.Alert) []types.ProbableCause {
+	clusters := buildClusters(alerts)
+
+	// Agents alerting per target, for agent exclusivity
+	agentsByTarget := make(map[string]map[string]bool)
+	for _, a := range alerts {
+		if a.AgentID == "" {
+			continue
+		}
+		if agentsByTarget[a.TargetID] == nil {
+			agentsByTarget[a.TargetID] = make(map[string]bool)
+		}
+		agentsByTarget[a.TargetID][a.AgentID] = true
+	}
+
+	// Most specific first, so subsumption only looks at earlier clusters
+	sort.SliceStable(clusters, func(i, j int) bool {
+		wi, wj := rootCauseWeights[clusters[i].entity.Type], rootCauseWeights[clusters[j].entity.Type]
+		if wi != wj {
+			return wi > wj
+		}
+		return len(clusters[i].alerts) > len(clusters[j].alerts)
+	})
+
+	var causes []types.ProbableCause
+	for i, c := range clusters {
+		if subsumed(c, clusters[:i]) {
+			continue
+		}
+
+		cause := summarizeCluster(c)
+		confidence := rootCauseWeights[c.entity.Type] * (1 - 1/float64(cause.TargetCount))
+		if c.entity.Type == types.RootCauseAgent {
+			confidence *= agentExclusivity(c, agentsByTarget)
+		}
+		if confidence <= 0 {
+			continue
+		}
+		cause.Confidence = math.Round(confidence*100) / 100
+		causes = append(causes, cause)
+	}
+
+	sort.SliceStable(causes, func(i, j int) bool {
+		if causes[i].Confidence != causes[j].Confidence {
+			return causes[i].Confidence > causes[j].Confidence
+		}
+		return causes[i].AlertCount > causes[j].AlertCount
+	})
+	if len(causes) > maxProbableCauses {
+		causes = causes[:maxProbableCauses]
+	}
+	return causes
+}
+
+// buildClusters groups alerts by each entity type, keeping groups that span
+// at least two targets. Subnet clusters use the alert's correlation key so
+// they line up with the incidents the alert worker creates.
+func buildClusters(alerts []types.Alert) []*alertCluster {
+	byKey := make(map[string]*alertCluster)
+	var order []string
+
+	add := func(entityType, id, name string, alert types.Alert) {
+		if id == "" {
+			return
+		}
+		key := types.CorrelationKey(entityType, id)
+		c, ok := byKey[key]
+		if !ok {
+			c = &alertCluster{
+				entity: types.RootCauseEntity{Type: entityType, ID: id, Name: name},
+				key:    key,
+				ids:    make(map[string]bool),
+			}
+			byKey[key] = c
+			order = append(order, key)
+		}
+		if c.entity.Name == "" {
+			c.entity.Name = name
+		}
+		c.alerts = append(c.alerts, alert)
+		c.ids[alert.ID] = true
+	}
+
+	subnetPrefix := types.CorrelationKey(types.RootCauseSubnet, "")
+	for _, a := range alerts {
+		if subnetID, ok := strings.CutPrefix(a.CorrelationKey, subnetPrefix); ok {
+			add(types.RootCauseSubnet, subnetID, a.SubnetCIDR, a)
+		}
+		add(types.RootCauseAgent, a.AgentID, a.AgentName, a)
+		add(types.RootCauseRegion, a.Region, "", a)
+		add(types.RootCauseUpstream, a.GatewayDevice, "", a)
+	}
+
+	clusters := make([]*alertCluster, 0, len(order))
+	for _, key := range order {
+		c := byKey[key]
+		targets := make(map[string]bool)
+		for _, a := range c.alerts {
+			targets[a.TargetID] = true
+		}
+		if len(targets) >= 2 {
+			clusters = append(clusters, c)
+		}
+	}
+	return clusters
+}
+
+// subsumed reports whether every alert in c belongs to one of the earlier,
+// higher-precedence clusters.
+func subsumed(c *alertCluster, earlier []*alertCluster) bool {
+	for _, e := range earlier {
+		if rootCauseWeights[e.entity.Type] <= rootCauseWeights[c.entity.Type] {
+			continue
+		}
+		covered := true
+		for id := range c.ids {
+			if !e.ids[id] {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			return true
+		}
+	}
+	return false
+}
+
+// agentExclusivity is the fraction of an agent cluster's targets that no
+// other agent is alerting on.
+func agentExclusivity(c *alertCluster, agentsByTarget map[string]map[string]bool) float64 {
+	targets := make(map[string]bool)
+	exclusive := 0
+	for _, a := range c.alerts {
+		if targets[a.TargetID] {
+			continue
+		}
+		targets[a.TargetID] = true
+		if len(agentsByTarget[a.TargetID]) == 1 {
+			exclusive++
+		}
+	}
+	return float64(exclusive) / float64(len(targets))
+}
+
+// summarizeCluster fills in the counts and member alerts for a cluster.
+func summarizeCluster(c *alertCluster) types.ProbableCause {
+	cause := types.ProbableCause{
+		PrimaryEntity:  c.entity,
+		CorrelationKey: c.key,
+		Severity:       types.AlertSeverityInfo,
+		Alerts:         c.alerts,
+		AlertCount:     len(c.alerts),
+	}
+
+	targets := make(map[string]bool)
+	agents := make(map[string]bool)
+	for _, a := range c.alerts {
+		targets[a.TargetID] = true
+		if a.AgentID != "" {
+			agents[a.AgentID] = true
+		}
+		if a.IncidentID == nil {
+			cause.UnlinkedCount++
+		}
+		if a.Severity.Level() > cause.Severity.Level() {
+			cause.Severity = a.Severity
+		}
+	}
+	cause.TargetCount = len(targets)
+	cause.AgentCount = len(agents)
+	return cause
+}
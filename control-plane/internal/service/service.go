@@ -3,12 +3,16 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/buffer"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
@@ -18,13 +22,25 @@ type Service struct {
 	store        *store.Store
 	logger       *slog.Logger
 	resultBuffer *buffer.ResultBuffer // Optional Redis buffer for probe results
+	publisher    ResultPublisher      // Optional; receives results after they are stored
+	rebalancer   *Rebalancer          // Optional; updates assignments when probing is toggled
+	sequences    *batchSequencer      // Skips replayed result batches
+	validation   ResultValidation     // Timestamp bounds for ingested results
+	assignments  *assignmentCache     // Shares assignment computations between fetches
+	liveStreams  liveStreamSlots      // Open live result streams per target
+
+	alertDefaults  types.AlertTunables        // Worker fallbacks for unset alert_config keys
+	neverResponded types.NeverRespondedPolicy // State worker's archival policy, for reporting
 }
 
 // NewService creates a new service.
 func NewService(store *store.Store, logger *slog.Logger) *Service {
 	return &Service{
-		store:  store,
-		logger: logger,
+		store:       store,
+		logger:      logger,
+		sequences:   newBatchSequencer(store, logger.With("component", "batch_sequencer")),
+		validation:  DefaultResultValidation(),
+		assignments: newAssignmentCache(config.AssignmentCacheTTL),
 	}
 }
 
@@ -34,6 +50,23 @@ func (s *Service) SetResultBuffer(buf *buffer.ResultBuffer) {
 	s.resultBuffer = buf
 }
 
+// ResultPublisher receives probe results after they are stored, for feeds
+// outside the database such as Kafka. It must not block ingestion for long.
+type ResultPublisher interface {
+	PublishResults(results []types.ProbeResult)
+}
+
+// SetResultPublisher sets where stored probe results are also published.
+func (s *Service) SetResultPublisher(p ResultPublisher) {
+	s.publisher = p
+}
+
+// SetRebalancer sets the rebalancer used to add or remove a target's
+// assignments when its probing is enabled or disabled.
+func (s *Service) SetRebalancer(r *Rebalancer) {
+	s.rebalancer = r
+}
+
 // Store returns the underlying store for direct access (used by middleware).
 func (s *Service) Store() *store.Store {
 	return s.store
@@ -54,6 +87,7 @@ type RegisterAgentRequest struct {
 	Version    string
 	Executors  []string
 	MaxTargets int
+	SystemInfo *types.AgentSystemInfo // nil from agents that don't report it
 }
 
 // RegisterAgent registers a new agent or updates an existing one.
@@ -74,6 +108,9 @@ func (s *Service) RegisterAgent(ctx context.Context, req RegisterAgentRequest) (
 		existing.Version = req.Version
 		existing.Executors = req.Executors
 		existing.MaxTargets = req.MaxTargets
+		if req.SystemInfo != nil {
+			existing.SystemInfo = req.SystemInfo
+		}
 		existing.Status = types.AgentStatusActive
 		existing.LastHeartbeat = time.Now()
 
@@ -97,6 +134,7 @@ func (s *Service) RegisterAgent(ctx context.Context, req RegisterAgentRequest) (
 		Version:       req.Version,
 		Executors:     req.Executors,
 		MaxTargets:    req.MaxTargets,
+		SystemInfo:    req.SystemInfo,
 		Status:        types.AgentStatusActive,
 		LastHeartbeat: time.Now(),
 		CreatedAt:     time.Now(),
@@ -131,16 +169,53 @@ func (s *Service) ProcessHeartbeat(ctx context.Context, heartbeat types.Heartbea
 
 	assignmentStale := currentVersion > heartbeat.AssignmentVersion
 
+	// Check if the agent's remote config has changed since it last applied it
+	configVersion, err := s.store.GetAgentConfigVersion(ctx, heartbeat.AgentID)
+	if err != nil {
+		s.logger.Warn("failed to get agent config version", "agent", heartbeat.AgentID, "error", err)
+	}
+
 	return &types.HeartbeatResponse{
 		Acknowledged:    true,
 		AssignmentStale: assignmentStale,
+		ConfigStale:     err == nil && configVersion != heartbeat.ConfigVersion,
 	}, nil
 }
 
 // GetAssignments returns assignments for an agent.
 // Uses persisted assignments from the target_assignments table.
 // Falls back to dynamic calculation if table is empty (for backward compatibility).
+// Targets with additional endpoints get one more assignment per endpoint.
+// Fetches of the same agent at the same version share one computation for
+// config.AssignmentCacheTTL, so the returned set must not be modified.
 func (s *Service) GetAssignments(ctx context.Context, agentID string) (*types.AssignmentSet, error) {
+	version, err := s.store.GetAssignmentVersion(ctx)
+	if err != nil {
+		return s.computeAssignments(ctx, agentID)
+	}
+	// The computation is shared, so one fetch giving up mustn't fail the
+	// others waiting on it
+	shared := context.WithoutCancel(ctx)
+	return s.assignments.get(agentID, version, func() (*types.AssignmentSet, error) {
+		return s.computeAssignments(shared, agentID)
+	})
+}
+
+// AssignmentCacheStats reports how assignment fetches were served.
+func (s *Service) AssignmentCacheStats() types.AssignmentCacheStats {
+	return s.assignments.stats()
+}
+
+func (s *Service) computeAssignments(ctx context.Context, agentID string) (*types.AssignmentSet, error) {
+	set, err := s.getAssignments(ctx, agentID)
+	if err != nil || len(set.Assignments) == 0 {
+		return set, err
+	}
+	set.Assignments = s.withEndpointAssignments(ctx, set.Assignments)
+	return set, nil
+}
+
+func (s *Service) getAssignments(ctx context.Context, agentID string) (*types.AssignmentSet, error) {
 	// Get agent to check it exists
 	agent, err := s.store.GetAgent(ctx, agentID)
 	if err != nil {
@@ -209,6 +284,9 @@ func (s *Service) enrichAssignments(ctx context.Context, persisted []types.Targe
 		if err != nil || target == nil {
 			continue // Skip if target no longer exists
 		}
+		if !target.ProbingEnabled {
+			continue
+		}
 
 		// Get effective tier for this target's state
 		effectiveTier := s.GetEffectiveTier(ctx, target, tierMap)
@@ -217,15 +295,18 @@ func (s *Service) enrichAssignments(ctx context.Context, persisted []types.Targe
 		}
 
 		assignment := types.Assignment{
-			TargetID:        target.ID,
-			IP:              target.IP,
-			Tier:            effectiveTier.Name,
-			ProbeType:       "icmp_ping",
-			ProbeInterval:   effectiveTier.ProbeInterval,
-			ProbeTimeout:    effectiveTier.ProbeTimeout,
-			ProbeRetries:    effectiveTier.ProbeRetries,
-			Tags:            target.Tags,
-			ExpectedOutcome: target.ExpectedOutcome,
+			TargetID:           target.ID,
+			IP:                 target.IP,
+			Tier:               effectiveTier.Name,
+			ProbeType:          target.EffectiveProbeType(),
+			ProbeParams:        target.ProbeParams,
+			ProbeInterval:      effectiveTier.ProbeInterval,
+			ProbeTimeout:       effectiveTier.ProbeTimeout,
+			ProbeRetries:       effectiveTier.ProbeRetries,
+			Tags:               target.Tags,
+			ExpectedOutcome:    target.ExpectedOutcome,
+			DSCP:               effectiveDSCP(target, effectiveTier),
+			BackoffMaxInterval: downBackoff(target, effectiveTier),
 		}
 
 		if target.ExpectedOutcome == nil && effectiveTier.DefaultExpectedOutcome != nil {
@@ -262,8 +343,13 @@ func (s *Service) getAssignmentsDynamic(ctx context.Context, agent *types.Agent,
 		return nil, err
 	}
 
+	rules, err := s.store.ListAffinityRules(ctx, true)
+	if err != nil {
+		return nil, err
+	}
+
 	// Calculate assignments for this agent
-	assignments := s.calculateAssignments(agent, agents, targets, tierMap)
+	assignments := s.calculateAssignments(agent, agents, targets, tierMap, rules)
 
 	return &types.AssignmentSet{
 		Version:     version,
@@ -280,6 +366,7 @@ func (s *Service) calculateAssignments(
 	allAgents []types.Agent,
 	targets []types.Target,
 	tiers map[string]types.Tier,
+	rules []types.AffinityRule,
 ) []types.Assignment {
 	var assignments []types.Assignment
 
@@ -287,8 +374,8 @@ func (s *Service) calculateAssignments(
 	ctx := context.Background()
 
 	for _, target := range targets {
-		// Skip archived targets
-		if target.ArchivedAt != nil {
+		// Skip archived and probing-disabled targets
+		if target.ArchivedAt != nil || !target.ProbingEnabled {
 			continue
 		}
 
@@ -299,32 +386,49 @@ func (s *Service) calculateAssignments(
 		}
 
 		// Check if this agent should monitor this target
-		if !s.shouldAssign(agent, allAgents, target, *effectiveTier) {
+		if !s.shouldAssign(agent, allAgents, target, *effectiveTier, rules) {
 			continue
 		}
 
 		assignments = append(assignments, types.Assignment{
-			TargetID:        target.ID,
-			IP:              target.IP,
-			Tier:            effectiveTier.Name,
-			ProbeType:       "icmp_ping",
-			ProbeInterval:   effectiveTier.ProbeInterval,
-			ProbeTimeout:    effectiveTier.ProbeTimeout,
-			ProbeRetries:    effectiveTier.ProbeRetries,
-			Tags:            target.Tags,
-			ExpectedOutcome: target.ExpectedOutcome,
+			TargetID:           target.ID,
+			IP:                 target.IP,
+			Tier:               effectiveTier.Name,
+			ProbeType:          target.EffectiveProbeType(),
+			ProbeParams:        target.ProbeParams,
+			ProbeInterval:      effectiveTier.ProbeInterval,
+			ProbeTimeout:       effectiveTier.ProbeTimeout,
+			ProbeRetries:       effectiveTier.ProbeRetries,
+			Tags:               target.Tags,
+			ExpectedOutcome:    target.ExpectedOutcome,
+			DSCP:               effectiveDSCP(&target, effectiveTier),
+			BackoffMaxInterval: downBackoff(&target, effectiveTier),
 		})
 	}
 
 	return assignments
 }
 
-// shouldAssign determines if an agent should monitor a target based on tier policy.
+// effectiveDSCP returns the DSCP codepoint to probe a target with.
+// A per-target value overrides the tier; unset on both means best effort (0).
+func effectiveDSCP(target *types.Target, tier *types.Tier) int {
+	if target.DSCP != nil {
+		return *target.DSCP
+	}
+	if tier != nil && tier.DSCP != nil {
+		return *tier.DSCP
+	}
+	return 0
+}
+
+// shouldAssign determines if an agent should monitor a target based on tier
+// policy and affinity rules.
 func (s *Service) shouldAssign(
 	agent *types.Agent,
 	allAgents []types.Agent,
 	target types.Target,
 	tier types.Tier,
+	rules []types.AffinityRule,
 ) bool {
 	policy := tier.AgentSelection
 
@@ -334,11 +438,20 @@ func (s *Service) shouldAssign(
 		return false
 	}
 
-	// Strategy: all - every eligible agent gets every target
+	// Strategy: all - every eligible agent gets every target the affinity
+	// rules allow it
 	if policy.Strategy == "all" {
-		return s.isEligible(agent, policy)
+		if !s.isEligible(agent, policy) {
+			return false
+		}
+		if !slices.ContainsFunc(eligibleAgents, func(a types.Agent) bool { return a.ID == agent.ID }) {
+			eligibleAgents = append(slices.Clone(eligibleAgents), *agent)
+		}
+		return agentAllowedByAffinity(rules, target, eligibleAgents, agent.ID)
 	}
 
+	eligibleAgents, _ = applyAffinityRules(rules, target, eligibleAgents)
+
 	// Strategy: distributed - use consistent hashing to select N agents
 	selectedAgents := s.selectAgentsForTarget(target, eligibleAgents, policy.Count, policy.Diversity)
 
@@ -472,7 +585,7 @@ func (s *Service) ArchiveAgent(ctx context.Context, agentID, reason string) erro
 		return fmt.Errorf("getting agent: %w", err)
 	}
 	if agent == nil {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
 	}
 
 	if err := s.store.ArchiveAgent(ctx, agentID, reason); err != nil {
@@ -490,7 +603,7 @@ func (s *Service) UnarchiveAgent(ctx context.Context, agentID string) error {
 		return fmt.Errorf("getting agent: %w", err)
 	}
 	if agent == nil {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
 	}
 
 	if err := s.store.UnarchiveAgent(ctx, agentID); err != nil {
@@ -518,7 +631,7 @@ func (s *Service) UpdateAgentInfo(ctx context.Context, agentID string, req Updat
 		return fmt.Errorf("getting agent: %w", err)
 	}
 	if agent == nil {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
 	}
 
 	if err := s.store.UpdateAgentInfo(ctx, agentID, req.Name, req.Region, req.Location, req.Provider, req.Tags, req.MaxTargets); err != nil {
@@ -540,17 +653,56 @@ func (s *Service) GetAgentMetrics(ctx context.Context, agentID string, duration
 
 // GetAgentCurrentStats returns the most recent metrics for an agent.
 func (s *Service) GetAgentCurrentStats(ctx context.Context, agentID string) (*store.AgentCurrentStats, error) {
-	return s.store.GetAgentCurrentStats(ctx, agentID)
+	stats, err := s.store.GetAgentCurrentStats(ctx, agentID)
+	if err != nil || stats == nil {
+		return stats, err
+	}
+	lags, err := s.store.GetShipLag(ctx, agentID, config.ShipLagWindow)
+	if err != nil {
+		return nil, err
+	}
+	if len(lags) > 0 {
+		stats.ShipLag = &lags[0]
+	}
+	return stats, nil
 }
 
-// GetFleetOverview returns aggregated stats for all agents.
+// GetFleetOverview returns aggregated stats for all agents, including
+// result shipping over the last config.ShipmentWindow.
 func (s *Service) GetFleetOverview(ctx context.Context) (*store.FleetOverview, error) {
-	return s.store.GetFleetOverview(ctx)
+	overview, err := s.store.GetFleetOverview(ctx)
+	if err != nil {
+		return nil, err
+	}
+	shipment, err := s.GetFleetShipment(ctx, config.ShipmentWindow)
+	if err != nil {
+		return nil, err
+	}
+	overview.Shipment = shipment
+	if overview.Webhooks, err = s.store.GetWebhookDeliveryStats(ctx); err != nil {
+		return nil, fmt.Errorf("getting webhook delivery stats: %w", err)
+	}
+	return overview, nil
 }
 
 // GetAllAgentsCurrentStats returns current stats for all active agents.
 func (s *Service) GetAllAgentsCurrentStats(ctx context.Context) ([]store.AgentCurrentStats, error) {
-	return s.store.GetAllAgentsCurrentStats(ctx)
+	statsList, err := s.store.GetAllAgentsCurrentStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lags, err := s.store.GetShipLag(ctx, "", config.ShipLagWindow)
+	if err != nil {
+		return nil, err
+	}
+	byAgent := make(map[string]*types.AgentShipLag, len(lags))
+	for i := range lags {
+		byAgent[lags[i].AgentID] = &lags[i]
+	}
+	for i := range statsList {
+		statsList[i].ShipLag = byAgent[statsList[i].AgentID]
+	}
+	return statsList, nil
 }
 
 // =============================================================================
@@ -564,31 +716,34 @@ type CreateTargetRequest struct {
 	SubscriberID    string
 	Tags            map[string]string
 	ExpectedOutcome *types.ExpectedOutcome
+	DSCP            *int
+	Region          string
+	RetentionDays   *int
+	DownThreshold   *int   // Seconds; nil uses the global threshold
+	ProbeType       string // Empty means icmp_ping
+	ProbeParams     json.RawMessage
+	OnDuplicate     OnDuplicate // What to do if a target already holds IP
 }
 
-// CreateTarget creates a new target.
-func (s *Service) CreateTarget(ctx context.Context, req CreateTargetRequest) (*types.Target, error) {
-	target := &types.Target{
-		ID:              uuid.New().String(),
-		IP:              req.IP,
-		Tier:            req.Tier,
-		SubscriberID:    req.SubscriberID,
-		Tags:            req.Tags,
-		ExpectedOutcome: req.ExpectedOutcome,
-		CreatedAt:       time.Now(),
-		UpdatedAt:       time.Now(),
-	}
-
+// CreateTarget creates a new target. If a target already holds the IP, it
+// rejects, returns or merges into that target as req.OnDuplicate says;
+// created reports whether a new target was made.
+func (s *Service) CreateTarget(ctx context.Context, req CreateTargetRequest) (target *types.Target, created bool, err error) {
+	target = newTarget(req)
 	if err := target.Validate(); err != nil {
-		return nil, err
+		return nil, false, invalidInput("%s", err)
 	}
 
 	if err := s.store.CreateTarget(ctx, target); err != nil {
-		return nil, err
+		if store.IsUniqueViolation(err) {
+			existing, err := resolveDuplicateTarget(ctx, s.store, s.logger, req)
+			return existing, false, err
+		}
+		return nil, false, fromStore(err, "tier not found: "+req.Tier)
 	}
 
 	s.logger.Info("target created", "ip", req.IP, "tier", req.Tier, "id", target.ID)
-	return target, nil
+	return target, true, nil
 }
 
 // ListTargets returns all targets.
@@ -601,9 +756,21 @@ func (s *Service) ListTargetsPaginated(ctx context.Context, params store.TargetL
 	return s.store.ListTargetsPaginated(ctx, params)
 }
 
-// GetTarget returns a single target.
+// ListProbeResults returns a cursor-paginated page of a target's raw probe results.
+func (s *Service) ListProbeResults(ctx context.Context, params store.ProbeResultListParams) (*store.ProbeResultListResult, error) {
+	return s.store.ListProbeResults(ctx, params)
+}
+
+// GetTarget returns a single target with its additional endpoints.
 func (s *Service) GetTarget(ctx context.Context, id string) (*types.Target, error) {
-	return s.store.GetTarget(ctx, id)
+	target, err := s.store.GetTarget(ctx, id)
+	if err != nil || target == nil {
+		return target, err
+	}
+	if target.Endpoints, err = s.store.ListTargetEndpoints(ctx, id); err != nil {
+		return nil, fmt.Errorf("listing target endpoints: %w", err)
+	}
+	return target, nil
 }
 
 // =============================================================================
@@ -622,31 +789,65 @@ func (s *Service) GetTier(ctx context.Context, name string) (*types.Tier, error)
 
 // CreateTier creates a new tier.
 func (s *Service) CreateTier(ctx context.Context, tier *types.Tier) error {
-	return s.store.CreateTier(ctx, tier)
+	if err := tier.Validate(); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := s.store.CreateTier(ctx, tier); err != nil {
+		if store.IsUniqueViolation(err) {
+			return newError(ErrConflict, nil, "tier %s already exists", tier.Name)
+		}
+		return fromStore(err, "tier not found")
+	}
+	return nil
 }
 
 // UpdateTier updates an existing tier.
 func (s *Service) UpdateTier(ctx context.Context, tier *types.Tier) error {
-	return s.store.UpdateTier(ctx, tier)
+	if err := tier.Validate(); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := s.store.UpdateTier(ctx, tier); err != nil {
+		return fromStore(err, "tier not found")
+	}
+	return nil
 }
 
-// DeleteTier deletes a tier by name.
+// DeleteTier deletes a tier. It fails with ErrTierInUse, carrying the number
+// of targets in the tier, while any target still uses it.
 func (s *Service) DeleteTier(ctx context.Context, name string) error {
-	return s.store.DeleteTier(ctx, name)
+	err := s.store.DeleteTier(ctx, name)
+	var inUse *store.TierInUseError
+	if errors.As(err, &inUse) {
+		return newError(ErrTierInUse, map[string]any{
+			"tier":         inUse.Tier,
+			"target_count": inUse.Targets,
+		}, "%s", inUse)
+	}
+	return err
 }
 
 // =============================================================================
 // RESULT INGESTION
 // =============================================================================
 
-// IngestResults stores probe results and processes state transitions.
-func (s *Service) IngestResults(ctx context.Context, batch types.ResultBatch) error {
+// IngestResults validates probe results, stores the valid ones and processes
+// state transitions. Results that are malformed or reference an unknown
+// agent or target are dropped and counted by reason in the summary; those
+// stamped slightly in the future are clamped to the server's time. The
+// summary reports Duplicate, without storing anything, when the batch's
+// sequence has already been accepted from the agent (a retry after a lost
+// response), and counts the results it skipped. An accepted batch's ship lag
+// is recorded.
+func (s *Service) IngestResults(ctx context.Context, batch types.ResultBatch) (*IngestSummary, error) {
+	receivedAt := time.Now()
+	summary := &IngestSummary{Reasons: map[string]int{}}
 	if len(batch.Results) == 0 {
-		return nil
+		return summary, nil
 	}
 
 	s.logger.Debug("ingesting results",
 		"agent", batch.AgentID,
+		"sequence", batch.Sequence,
 		"count", len(batch.Results))
 
 	// Set agent ID on all results
@@ -654,57 +855,159 @@ func (s *Service) IngestResults(ctx context.Context, batch types.ResultBatch) er
 		batch.Results[i].AgentID = batch.AgentID
 	}
 
-	// Store probe results - use Redis buffer if available, otherwise direct DB write
-	if s.resultBuffer != nil {
-		// Push to Redis buffer for async DB write
-		if err := s.resultBuffer.Push(ctx, batch.Results); err != nil {
-			s.logger.Error("failed to push results to buffer", "error", err)
-			// Fall back to direct DB write
-			if err := s.store.InsertProbeResults(ctx, batch.Results); err != nil {
-				return err
-			}
-		}
-	} else {
-		// Direct DB write (no Redis buffer configured)
-		if err := s.store.InsertProbeResults(ctx, batch.Results); err != nil {
+	results, rejected, err := s.validateResults(ctx, batch)
+	if err != nil {
+		return nil, fmt.Errorf("validating results: %w", err)
+	}
+	summary.Rejected = len(rejected)
+	summary.Reasons = countReasons(rejected)
+	summary.Clamped = clampFutureResults(results, receivedAt)
+	if summary.Reasons[RejectUnknownAgent] > 0 {
+		// Nothing to sequence against an agent that doesn't exist
+		return summary, nil
+	}
+
+	results, endpointResults := splitEndpointResults(results)
+
+	summary.Duplicate, err = s.sequences.Ingest(ctx, batch.AgentID, batch.Sequence, func() error {
+		// Endpoint results go first: their insert ignores rows it already
+		// has, so a failure after it can be retried without duplicating
+		// them, while target results would be buffered twice.
+		if err := s.store.InsertEndpointResults(ctx, endpointResults); err != nil {
 			return err
 		}
+		return s.storeResults(ctx, results)
+	})
+	if err != nil {
+		return nil, err
+	}
+	summary.QueueDepth = s.bufferDepth(ctx)
+	if summary.Duplicate {
+		summary.Deduplicated = len(results) + len(endpointResults)
+		s.logger.Info("skipping replayed result batch",
+			"agent", batch.AgentID,
+			"batch_id", batch.BatchID,
+			"sequence", batch.Sequence)
+		return summary, nil
+	}
+	summary.Accepted = len(results) + len(endpointResults)
+	s.recordShipLag(ctx, batch, receivedAt)
+	if len(results) == 0 {
+		return summary, nil
 	}
 
 	// Process state transitions based on probe results
 	// This runs asynchronously to not block result ingestion
 	go func() {
 		bgCtx := context.Background()
-		if err := s.ProcessProbeResultsBatch(bgCtx, batch.Results); err != nil {
+		s.recordCertificates(bgCtx, results)
+		if err := s.ProcessProbeResultsBatch(bgCtx, results); err != nil {
 			s.logger.Error("failed to process state transitions", "error", err)
 		}
 	}()
 
+	return summary, nil
+}
+
+// storeResults writes probe results - to the Redis buffer if available,
+// otherwise directly to the DB - and then hands them to the publisher, if
+// one is set.
+func (s *Service) storeResults(ctx context.Context, results []types.ProbeResult) error {
+	if err := s.writeResults(ctx, results); err != nil {
+		return err
+	}
+	if s.publisher != nil {
+		s.publisher.PublishResults(results)
+	}
 	return nil
 }
 
+func (s *Service) writeResults(ctx context.Context, results []types.ProbeResult) error {
+	if s.resultBuffer != nil {
+		// Push to Redis buffer for async DB write
+		if err := s.resultBuffer.Push(ctx, results); err != nil {
+			s.logger.Error("failed to push results to buffer", "error", err)
+			// Fall back to direct DB write
+			return s.store.InsertProbeResults(ctx, results)
+		}
+		return nil
+	}
+
+	// Direct DB write (no Redis buffer configured)
+	return s.store.InsertProbeResults(ctx, results)
+}
+
 // =============================================================================
 // TARGET STATUS
 // =============================================================================
 
-// GetTargetStatus returns the current monitoring status for a target.
+// GetTargetStatus returns the current monitoring status for a target,
+// including how its latency compares across agent regions.
 func (s *Service) GetTargetStatus(ctx context.Context, targetID string) (*store.TargetStatus, error) {
-	return s.store.GetTargetStatus(ctx, targetID, 2*time.Minute)
+	status, err := s.store.GetTargetStatus(ctx, targetID, config.TargetStatusWindow)
+	if err != nil || status == nil {
+		return status, err
+	}
+	status.LatencyAsymmetry = s.targetLatencyAsymmetry(ctx, targetID)
+	statuses := []store.TargetStatus{*status}
+	if err := s.applyHealthScores(ctx, statuses, &targetID); err != nil {
+		return nil, err
+	}
+	return &statuses[0], nil
 }
 
 // GetAllTargetStatuses returns status for all targets.
 func (s *Service) GetAllTargetStatuses(ctx context.Context) ([]store.TargetStatus, error) {
-	return s.store.GetAllTargetStatuses(ctx, 2*time.Minute)
+	statuses, err := s.store.GetAllTargetStatuses(ctx, config.TargetStatusWindow)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.applyHealthScores(ctx, statuses, nil); err != nil {
+		return nil, err
+	}
+	return statuses, nil
 }
 
 // GetTargetHistory returns historical probe data for a target.
 func (s *Service) GetTargetHistory(ctx context.Context, targetID string, window time.Duration) ([]store.ProbeHistoryPoint, error) {
-	// Use 1-minute buckets for windows under 2 hours, otherwise 5-minute buckets
-	bucketSize := time.Minute
+	return s.store.GetTargetHistory(ctx, targetID, window, targetHistoryBucketSize(window))
+}
+
+// targetHistoryBucketSize uses 1-minute buckets for windows under 2 hours,
+// otherwise 5-minute buckets.
+func targetHistoryBucketSize(window time.Duration) time.Duration {
 	if window > 2*time.Hour {
-		bucketSize = 5 * time.Minute
+		return 5 * time.Minute
+	}
+	return time.Minute
+}
+
+// TargetHistoryComparison holds in-market and all-agent history for a target
+// on the same bucket grid so the two series can be overlaid.
+type TargetHistoryComparison struct {
+	BucketSize time.Duration             `json:"-"`
+	InMarket   []store.ProbeHistoryPoint `json:"in_market"`
+	All        []store.ProbeHistoryPoint `json:"all"`
+}
+
+// GetTargetHistoryComparison returns in-market and all-agent history for a target.
+func (s *Service) GetTargetHistoryComparison(ctx context.Context, targetID string, window time.Duration) (*TargetHistoryComparison, error) {
+	bucketSize := targetHistoryBucketSize(window)
+
+	all, err := s.store.GetTargetHistory(ctx, targetID, window, bucketSize)
+	if err != nil {
+		return nil, fmt.Errorf("getting target history: %w", err)
+	}
+	inMarket, err := s.store.GetTargetHistoryInMarket(ctx, targetID, window, bucketSize)
+	if err != nil {
+		return nil, fmt.Errorf("getting in-market history: %w", err)
 	}
-	return s.store.GetTargetHistory(ctx, targetID, window, bucketSize)
+
+	return &TargetHistoryComparison{
+		BucketSize: bucketSize,
+		InMarket:   inMarket,
+		All:        all,
+	}, nil
 }
 
 // GetTargetHistoryByAgent returns per-agent historical probe data for a target.
@@ -735,9 +1038,28 @@ func (s *Service) GetLatencyTrend(ctx context.Context, window time.Duration) ([]
 	return s.store.GetLatencyTrend(ctx, window, bucketSize)
 }
 
-// GetTargetLiveResults returns recent raw probe results for live monitoring.
-func (s *Service) GetTargetLiveResults(ctx context.Context, targetID string, seconds int) ([]store.LiveProbeResult, error) {
-	return s.store.GetTargetLiveResults(ctx, targetID, seconds)
+// GetTargetLiveResults returns recent raw probe results for live monitoring
+// over the last seconds (default 1 minute, at most 5), sampled evenly across
+// agents. perAgent, when positive, caps the results kept per agent.
+func (s *Service) GetTargetLiveResults(ctx context.Context, targetID string, seconds, perAgent int) ([]store.LiveProbeResult, error) {
+	if perAgent < 0 {
+		return nil, invalidInput("per_agent must not be negative")
+	}
+	results, err := s.store.GetTargetLiveResults(ctx, targetID, liveWindow(seconds), perAgent, config.LiveResultsLimit)
+	if err != nil {
+		return nil, err
+	}
+	markLiveAnomalies(results, config.LiveAnomalyZScore)
+	return results, nil
+}
+
+// liveWindow converts the requested live view window to a duration,
+// applying the default and cap.
+func liveWindow(seconds int) time.Duration {
+	if seconds <= 0 {
+		return config.LiveResultsDefaultWindow
+	}
+	return min(time.Duration(seconds)*time.Second, config.LiveResultsMaxWindow)
 }
 
 // GetInMarketLatencyTrend returns in-market latency trend for the dashboard.
@@ -818,6 +1140,9 @@ func (s *Service) SaveCommandResult(ctx context.Context, result *store.CommandRe
 	if err != nil || cmd == nil {
 		return err
 	}
+	if cmd.CommandType == types.CommandSubnetSweep {
+		s.seedSweepResult(ctx, cmd, result)
+	}
 
 	results, err := s.store.GetCommandResults(ctx, result.CommandID)
 	if err != nil {
@@ -880,13 +1205,45 @@ func (s *Service) RecalculateAllBaselines(ctx context.Context) (int, error) {
 	return s.store.RecalculateAllBaselines(ctx)
 }
 
+// RecalculateBaselines recalculates the baselines in scope, falling back to
+// the full per-pair recalculation when the scope is empty.
+func (s *Service) RecalculateBaselines(ctx context.Context, scope store.BaselineScope) (int, error) {
+	if scope.IsEmpty() {
+		return s.store.RecalculateAllBaselines(ctx)
+	}
+	if scope.Since != nil && scope.Since.After(time.Now()) {
+		return 0, invalidInput("invalid since: must be in the past")
+	}
+
+	count, err := s.store.RecalculateBaselines(ctx, scope)
+	if err != nil {
+		return 0, fromStore(err, "subnet or agent not found")
+	}
+	s.logger.Info("scoped baselines recalculated",
+		"subnet_id", scope.SubnetID,
+		"region", scope.Region,
+		"tier", scope.Tier,
+		"agent_id", scope.AgentID,
+		"target_id", scope.TargetID,
+		"since", scope.Since,
+		"pairs", count)
+	return count, nil
+}
+
 // =============================================================================
 // REPORTS
 // =============================================================================
 
 // GetTargetReport returns a report for a target over a time window.
-func (s *Service) GetTargetReport(ctx context.Context, targetID string, windowDays int) ([]store.TargetReport, error) {
-	return s.store.GetTargetReport(ctx, targetID, windowDays)
+// tz aligns the window to local midnight; empty means rolling UTC days.
+func (s *Service) GetTargetReport(ctx context.Context, targetID string, windowDays int, tz string) ([]store.TargetReport, error) {
+	return s.store.GetTargetReport(ctx, targetID, windowDays, tz)
+}
+
+// GetTargetDailyReport returns per-day metrics for a target, split at local
+// midnight in tz (empty means UTC).
+func (s *Service) GetTargetDailyReport(ctx context.Context, targetID string, windowDays int, tz string) ([]store.TargetDailyReport, error) {
+	return s.store.GetTargetDailyReport(ctx, targetID, windowDays, tz)
 }
 
 // =============================================================================
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
@@ -49,17 +50,41 @@ func (s *Service) ListAlertEvents(ctx context.Context, alertID string, limit int
 // ALERT CONFIG OPERATIONS
 // =============================================================================
 
-// ListAlertConfigs returns all alert configuration values.
+// ListAlertConfigs returns all alert configuration values, with secrets
+// redacted.
 func (s *Service) ListAlertConfigs(ctx context.Context) ([]types.AlertConfig, error) {
-	return s.store.ListAlertConfigs(ctx)
+	configs, err := s.store.ListAlertConfigs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return redactAlertConfigs(configs), nil
 }
 
 // SetAlertConfig sets a configuration value.
 func (s *Service) SetAlertConfig(ctx context.Context, key string, value interface{}, description string) error {
+	if err := validateWebhookConfig(key, value); err != nil {
+		return err
+	}
 	return s.store.SetAlertConfig(ctx, key, value, description)
 }
 
-// GetAlertCorrelations returns a summary of active alerts grouped by common dimensions.
+// GetAlertCorrelations returns a summary of active alerts grouped by common
+// dimensions, with the groups ranked by probable root cause.
 func (s *Service) GetAlertCorrelations(ctx context.Context) (*types.AlertCorrelationSummary, error) {
-	return s.store.GetAlertCorrelations(ctx)
+	summary, err := s.store.GetAlertCorrelations(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []types.Alert
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		batch, err := s.store.ListAlerts(ctx, types.AlertFilter{Status: &status, Limit: 1000})
+		if err != nil {
+			return nil, fmt.Errorf("listing %s alerts: %w", status, err)
+		}
+		alerts = append(alerts, batch...)
+	}
+
+	summary.ProbableCauses = rankProbableCauses(alerts)
+	return summary, nil
 }
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// AGENT HEALTH EXCLUSIONS
+// =============================================================================
+
+// ListHealthExclusions returns health exclusions, optionally for one agent.
+func (s *Service) ListHealthExclusions(ctx context.Context, agentID string) ([]types.AgentHealthExclusion, error) {
+	return s.store.ListHealthExclusions(ctx, agentID)
+}
+
+// GetHealthExclusion retrieves a health exclusion by ID.
+func (s *Service) GetHealthExclusion(ctx context.Context, id string) (*types.AgentHealthExclusion, error) {
+	return s.store.GetHealthExclusion(ctx, id)
+}
+
+// CreateHealthExclusion validates and stores a new exclusion. The evaluator,
+// alerting and status queries ignore the pair from their next run.
+func (s *Service) CreateHealthExclusion(ctx context.Context, e *types.AgentHealthExclusion) error {
+	if err := e.Validate(); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := s.store.CreateHealthExclusion(ctx, e); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return newError(ErrConflict, nil, "agent is already excluded for this target or subnet")
+		}
+		return fromStore(err, "agent, target or subnet not found")
+	}
+	s.logger.Info("agent excluded from health computation",
+		"exclusion_id", e.ID,
+		"agent_id", e.AgentID,
+		"target_id", e.TargetID,
+		"subnet_id", e.SubnetID,
+		"reason", e.Reason,
+	)
+	return nil
+}
+
+// UpdateHealthExclusionReason changes an exclusion's reason.
+func (s *Service) UpdateHealthExclusionReason(ctx context.Context, id, reason string) error {
+	return fromStore(s.store.UpdateHealthExclusionReason(ctx, id, reason), "")
+}
+
+// DeleteHealthExclusion removes an exclusion, returning the pair to health computation.
+func (s *Service) DeleteHealthExclusion(ctx context.Context, id string) error {
+	return fromStore(s.store.DeleteHealthExclusion(ctx, id), "")
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/report"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// REPORT SCHEDULE OPERATIONS
+// =============================================================================
+
+// ValidateReportSchedule checks a schedule's fields and cron expression.
+func ValidateReportSchedule(rs *types.ReportSchedule) error {
+	if err := rs.Validate(); err != nil {
+		return err
+	}
+	if _, err := report.ParseCron(rs.CronExpression); err != nil {
+		return invalidInput("invalid cron_expression: %s", err)
+	}
+	return nil
+}
+
+// scheduleNextRun sets NextRunAt from the cron expression (nil when disabled).
+func scheduleNextRun(rs *types.ReportSchedule) error {
+	rs.NextRunAt = nil
+	if !rs.Enabled {
+		return nil
+	}
+	cron, err := report.ParseCron(rs.CronExpression)
+	if err != nil {
+		return invalidInput("invalid cron_expression: %s", err)
+	}
+	if next := cron.Next(time.Now()); !next.IsZero() {
+		rs.NextRunAt = &next
+	}
+	return nil
+}
+
+// ListReportSchedules returns all report schedules.
+func (s *Service) ListReportSchedules(ctx context.Context) ([]types.ReportSchedule, error) {
+	return s.store.ListReportSchedules(ctx)
+}
+
+// GetReportSchedule retrieves a report schedule by ID.
+func (s *Service) GetReportSchedule(ctx context.Context, id string) (*types.ReportSchedule, error) {
+	return s.store.GetReportSchedule(ctx, id)
+}
+
+// CreateReportSchedule validates and stores a new schedule, computing its first run.
+func (s *Service) CreateReportSchedule(ctx context.Context, rs *types.ReportSchedule) error {
+	if err := ValidateReportSchedule(rs); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := scheduleNextRun(rs); err != nil {
+		return err
+	}
+	return s.store.CreateReportSchedule(ctx, rs)
+}
+
+// UpdateReportSchedule validates and replaces a schedule's configuration.
+// The next run is recomputed from the (possibly changed) cron expression.
+func (s *Service) UpdateReportSchedule(ctx context.Context, rs *types.ReportSchedule) error {
+	if err := ValidateReportSchedule(rs); err != nil {
+		return invalidInput("%s", err)
+	}
+	if err := scheduleNextRun(rs); err != nil {
+		return err
+	}
+	return fromStore(s.store.UpdateReportSchedule(ctx, rs), "")
+}
+
+// DeleteReportSchedule deletes a schedule and its delivery history.
+func (s *Service) DeleteReportSchedule(ctx context.Context, id string) error {
+	return fromStore(s.store.DeleteReportSchedule(ctx, id), "")
+}
+
+// TriggerReportSchedule queues a schedule to run on the report worker's next tick.
+func (s *Service) TriggerReportSchedule(ctx context.Context, id string) (bool, error) {
+	return s.store.TriggerReportSchedule(ctx, id)
+}
+
+// ListReportDeliveries returns a schedule's recent delivery attempts.
+func (s *Service) ListReportDeliveries(ctx context.Context, scheduleID string, limit int) ([]types.ReportDelivery, error) {
+	return s.store.ListReportDeliveries(ctx, scheduleID, limit)
+}
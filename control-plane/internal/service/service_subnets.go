@@ -3,10 +3,14 @@ package service
 import (
 	"context"
 	"encoding/binary"
+	"encoding/json"
 	"fmt"
 	"net"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
 	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
@@ -403,6 +407,14 @@ func (s *Service) ArchiveSubnet(ctx context.Context, id string, reason string) e
 		"network", existing.NetworkAddress,
 		"reason", reason,
 	)
+
+	// Incidents on the archived targets would otherwise never clear
+	resolved, err := s.store.ResolveIncidentsBySubnet(ctx, id, "Subnet archived")
+	if err != nil {
+		s.logger.Error("failed to resolve incidents for archived subnet", "id", id, "error", err)
+	} else if resolved > 0 {
+		s.logger.Info("incidents auto-resolved for archived subnet", "id", id, "incidents_resolved", resolved)
+	}
 	return nil
 }
 
@@ -529,6 +541,32 @@ func (s *Service) GetTargetActivity(ctx context.Context, targetID string, limit
 	return s.store.GetRecentActivityForTarget(ctx, targetID, limit)
 }
 
+// TargetHopHistory is a target's inferred hop count per agent over a window,
+// with the changes the route worker detected in it.
+type TargetHopHistory struct {
+	Points  []types.HopCountPoint  `json:"points"`
+	Changes []types.HopCountChange `json:"changes"`
+}
+
+// GetTargetHopHistory returns hop-count history for a target. Buckets are
+// 5 minutes for windows up to a day, hourly beyond that.
+func (s *Service) GetTargetHopHistory(ctx context.Context, targetID string, window time.Duration) (*TargetHopHistory, error) {
+	bucket := 5 * time.Minute
+	if window > 24*time.Hour {
+		bucket = time.Hour
+	}
+
+	points, err := s.store.GetTargetHopHistory(ctx, targetID, window, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("getting hop history: %w", err)
+	}
+	changes, err := s.store.ListHopCountChanges(ctx, targetID, time.Now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("listing hop count changes: %w", err)
+	}
+	return &TargetHopHistory{Points: points, Changes: changes}, nil
+}
+
 // GetSubnetActivity returns recent activity for a subnet and its targets.
 func (s *Service) GetSubnetActivity(ctx context.Context, subnetID string, limit int) ([]types.ActivityLogEntry, error) {
 	if limit <= 0 {
@@ -537,6 +575,16 @@ func (s *Service) GetSubnetActivity(ctx context.Context, subnetID string, limit
 	return s.store.GetRecentActivityForSubnet(ctx, subnetID, limit)
 }
 
+// GetSubnetServiceStatusChanges returns a subnet's recent service status
+// changes, newest first.
+func (s *Service) GetSubnetServiceStatusChanges(ctx context.Context, subnetID string) ([]types.ActivityLogEntry, error) {
+	return s.store.ListActivity(ctx, store.ActivityFilter{
+		SubnetID:  subnetID,
+		EventType: types.ActivityEventServiceStatusChanged,
+		Limit:     config.SubnetServiceStatusChangesLimit,
+	})
+}
+
 // =============================================================================
 // TARGET UPDATE/DELETE
 // =============================================================================
@@ -549,9 +597,16 @@ type UpdateTargetRequest struct {
 	DisplayName     string
 	Notes           string
 	ExpectedOutcome *types.ExpectedOutcome
+	DSCP            *int
+	Region          *string // nil leaves unchanged, "" clears
+	RetentionDays   *int
+	DownThreshold   *int    // Seconds; nil uses the global threshold
+	ProbeType       *string // nil leaves unchanged, "" resets to icmp_ping
+	ProbeParams     json.RawMessage
 }
 
-// UpdateTarget updates a target's metadata.
+// UpdateTarget updates a target's metadata. Probe params are replaced
+// only together with the probe type.
 func (s *Service) UpdateTarget(ctx context.Context, req UpdateTargetRequest) (*types.Target, error) {
 	existing, err := s.store.GetTarget(ctx, req.ID)
 	if err != nil {
@@ -573,6 +628,16 @@ func (s *Service) UpdateTarget(ctx context.Context, req UpdateTargetRequest) (*t
 	}
 	existing.Notes = req.Notes
 	existing.ExpectedOutcome = req.ExpectedOutcome
+	existing.DSCP = req.DSCP
+	existing.RetentionDays = req.RetentionDays
+	existing.DownThresholdSeconds = req.DownThreshold
+	if req.Region != nil {
+		existing.Region = strings.TrimSpace(*req.Region)
+	}
+	if req.ProbeType != nil {
+		existing.ProbeType = strings.TrimSpace(*req.ProbeType)
+		existing.ProbeParams = req.ProbeParams
+	}
 
 	if err := s.store.UpdateTarget(ctx, existing); err != nil {
 		return nil, fmt.Errorf("updating target: %w", err)
@@ -610,3 +675,51 @@ func (s *Service) DeleteTarget(ctx context.Context, id string, reason string) er
 	)
 	return nil
 }
+
+// SetTargetProbing enables or disables probing of a target. Disabling removes
+// its agent assignments but leaves monitoring state and history untouched,
+// so re-enabling resumes where it left off.
+func (s *Service) SetTargetProbing(ctx context.Context, id string, enabled bool, reason, triggeredBy string) (*types.Target, error) {
+	existing, err := s.store.GetTarget(ctx, id)
+	if err != nil {
+		return nil, fromStore(err, "")
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("target %w: %s", ErrNotFound, id)
+	}
+	if existing.ArchivedAt != nil {
+		return nil, newError(ErrConflict, nil, "target is archived: %s", id)
+	}
+
+	changed, err := s.store.SetTargetProbingEnabled(ctx, id, enabled, reason, triggeredBy)
+	if err != nil {
+		return nil, fmt.Errorf("setting target probing: %w", err)
+	}
+	if !changed {
+		return existing, nil
+	}
+
+	if s.rebalancer != nil {
+		if enabled {
+			_, err = s.rebalancer.AssignTarget(ctx, id)
+		} else {
+			_, err = s.rebalancer.UnassignTarget(ctx, id)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("updating assignments: %w", err)
+		}
+	}
+
+	// Bump the version so agents pick up the change on their next heartbeat
+	if _, err := s.store.IncrementAssignmentVersion(ctx); err != nil {
+		s.logger.Warn("failed to bump assignment version", "error", err)
+	}
+
+	s.logger.Info("target probing changed",
+		"id", id,
+		"ip", existing.IP,
+		"enabled", enabled,
+		"reason", reason,
+	)
+	return s.store.GetTarget(ctx, id)
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// recordShipLag stores how long the batch's results took to arrive. It is
+// measured over every result the agent sent, rejected ones included, since
+// it describes the agent's shipping rather than the results. Failures are
+// only logged: lag is diagnostic and mustn't fail ingestion.
+func (s *Service) recordShipLag(ctx context.Context, batch types.ResultBatch, receivedAt time.Time) {
+	lag := types.MeasureShipLag(batch.Results, receivedAt)
+	if err := s.store.InsertShipLag(ctx, batch.AgentID, batch.Sequence, receivedAt, lag); err != nil {
+		s.logger.Warn("failed to record ship lag",
+			"agent", batch.AgentID,
+			"sequence", batch.Sequence,
+			"error", err)
+	}
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// RESULT SHIPMENT ROLLUP
+// =============================================================================
+
+// medianFloat returns the median of values, which it sorts.
+func medianFloat(values []float64) float64 {
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 1 {
+		return values[mid]
+	}
+	return (values[mid-1] + values[mid]) / 2
+}
+
+// summarizeShipments totals agent shipping over window and flags agents
+// whose results are much larger than the fleet median. Only agents with
+// enough results are judged or count towards the median, so an agent that
+// shipped a handful of large MTR results isn't flagged.
+func summarizeShipments(agents []types.AgentShipment, window time.Duration) *types.FleetShipment {
+	fleet := &types.FleetShipment{
+		Window:             window.String(),
+		LargePayloadAgents: []types.AgentShipment{},
+	}
+
+	var perResult []float64
+	for i := range agents {
+		a := &agents[i]
+		a.CompressionRatio = ratio(float64(a.BytesUncompressed), a.BytesShipped)
+		a.BytesPerResult = ratio(float64(a.BytesUncompressed), a.ResultsShipped)
+		if a.BatchesShipped > 0 {
+			fleet.Agents++
+		}
+		if a.BytesPerResult != nil && a.ResultsShipped >= config.LargePayloadMinResults {
+			perResult = append(perResult, *a.BytesPerResult)
+		}
+
+		fleet.ResultsShipped += a.ResultsShipped
+		fleet.ResultsFailed += a.ResultsFailed
+		fleet.BatchesShipped += a.BatchesShipped
+		fleet.ShipFailures += a.ShipFailures
+		fleet.BytesShipped += a.BytesShipped
+		fleet.BytesUncompressed += a.BytesUncompressed
+	}
+
+	fleet.BytesPerSecond = float64(fleet.BytesShipped) / window.Seconds()
+	fleet.CompressionRatio = ratio(float64(fleet.BytesUncompressed), fleet.BytesShipped)
+	if len(perResult) == 0 {
+		return fleet
+	}
+
+	median := medianFloat(perResult)
+	fleet.MedianBytesPerResult = &median
+	threshold := config.LargePayloadFleetMultiple * median
+	for _, a := range agents {
+		if a.ResultsShipped < config.LargePayloadMinResults || a.BytesPerResult == nil || *a.BytesPerResult <= threshold {
+			continue
+		}
+		a.LargePayload = true
+		a.Reason = fmt.Sprintf("%.0f bytes per result vs fleet median %.0f", *a.BytesPerResult, median)
+		fleet.LargePayloadAgents = append(fleet.LargePayloadAgents, a)
+	}
+	sort.SliceStable(fleet.LargePayloadAgents, func(i, j int) bool {
+		return *fleet.LargePayloadAgents[i].BytesPerResult > *fleet.LargePayloadAgents[j].BytesPerResult
+	})
+	return fleet
+}
+
+// GetFleetShipment totals result shipping across agents over window.
+func (s *Service) GetFleetShipment(ctx context.Context, window time.Duration) (*types.FleetShipment, error) {
+	agents, err := s.store.GetAgentShipments(ctx, window)
+	if err != nil {
+		return nil, fmt.Errorf("getting agent shipments: %w", err)
+	}
+	return summarizeShipments(agents, window), nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// shipped builds an agent that shipped results of perResult uncompressed
+// bytes, compressing 4:1.
+func shipped(name string, results, perResult int64) types.AgentShipment {
+	return types.AgentShipment{
+		AgentID:           name,
+		AgentName:         name,
+		ResultsShipped:    results,
+		BatchesShipped:    max(results/100, 1),
+		BytesUncompressed: results * perResult,
+		BytesShipped:      results * perResult / 4,
+	}
+}
+
+func TestSummarizeShipments_LargePayload(t *testing.T) {
+	minResults := int64(config.LargePayloadMinResults)
+	tests := []struct {
+		name        string
+		agents      []types.AgentShipment
+		wantFlagged []string
+	}{
+		{
+			name:        "uniform fleet",
+			agents:      []types.AgentShipment{shipped("a", 1000, 200), shipped("b", 1000, 220), shipped("c", 1000, 180)},
+			wantFlagged: nil,
+		},
+		{
+			name: "one bloated agent",
+			agents: []types.AgentShipment{
+				shipped("a", 1000, 200), shipped("b", 1000, 200), shipped("c", 1000, 2000), shipped("d", 1000, 210),
+			},
+			wantFlagged: []string{"c"},
+		},
+		{
+			name:        "too few results to judge",
+			agents:      []types.AgentShipment{shipped("a", 1000, 200), shipped("b", 1000, 200), shipped("c", minResults-1, 5000)},
+			wantFlagged: nil,
+		},
+		{
+			name: "largest first",
+			agents: []types.AgentShipment{
+				shipped("a", 1000, 200), shipped("b", 1000, 200), shipped("c", 1000, 200),
+				shipped("d", 1000, 900), shipped("e", 1000, 3000),
+			},
+			wantFlagged: []string{"e", "d"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fleet := summarizeShipments(tt.agents, time.Hour)
+
+			if len(fleet.LargePayloadAgents) != len(tt.wantFlagged) {
+				t.Fatalf("flagged %d agents, want %v", len(fleet.LargePayloadAgents), tt.wantFlagged)
+			}
+			for i, a := range fleet.LargePayloadAgents {
+				if a.AgentID != tt.wantFlagged[i] || !a.LargePayload || a.Reason == "" {
+					t.Errorf("flagged[%d] = %s (large %v, reason %q), want %s", i, a.AgentID, a.LargePayload, a.Reason, tt.wantFlagged[i])
+				}
+			}
+		})
+	}
+}
+
+func TestSummarizeShipments_Totals(t *testing.T) {
+	tests := []struct {
+		name      string
+		agents    []types.AgentShipment
+		wantRatio *float64
+		wantBPS   float64
+	}{
+		{"no agents", nil, nil, 0},
+		{"idle agent", []types.AgentShipment{{AgentID: "a"}}, nil, 0},
+		{"two agents", []types.AgentShipment{shipped("a", 1800, 400), shipped("b", 1800, 400)}, ptr(4.0), 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fleet := summarizeShipments(tt.agents, time.Hour)
+
+			if (fleet.CompressionRatio == nil) != (tt.wantRatio == nil) ||
+				(tt.wantRatio != nil && *fleet.CompressionRatio != *tt.wantRatio) {
+				t.Errorf("CompressionRatio = %v, want %v", fleet.CompressionRatio, tt.wantRatio)
+			}
+			if fleet.BytesPerSecond != tt.wantBPS {
+				t.Errorf("BytesPerSecond = %v, want %v", fleet.BytesPerSecond, tt.wantBPS)
+			}
+			if fleet.LargePayloadAgents == nil {
+				t.Error("LargePayloadAgents should be empty, not nil")
+			}
+		})
+	}
+}
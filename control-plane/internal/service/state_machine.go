@@ -103,6 +103,14 @@ func (s *Service) handleFailedProbe(ctx context.Context, target *types.Target, p
 			return s.store.TransitionTargetState(ctx, target.ID, types.StateUnresponsive,
 				"no response after discovery attempts", "discovery")
 		}
+
+	case types.StateUnresponsive:
+		// Keep counting for targets that have never answered, which the
+		// state worker archives after enough attempts
+		if target.FirstResponseAt == nil {
+			_, err := s.store.IncrementDiscoveryAttempts(ctx, target.ID)
+			return err
+		}
 	}
 
 	// Other states: failed probes don't cause immediate transitions
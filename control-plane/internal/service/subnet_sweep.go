@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// SUBNET SWEEP
+// =============================================================================
+
+// CreateSubnetSweep queues a subnet_sweep command that pings every usable
+// address in the subnet (the same ones seeding would create) and reports
+// which answer. Results come back through the command pipeline. With no
+// agentIDs one active ICMP agent is chosen, so a customer range isn't swept
+// by the whole fleet. With autoSeed, responsive addresses become discovery
+// targets as results arrive.
+func (s *Service) CreateSubnetSweep(ctx context.Context, subnetID string, agentIDs []string, autoSeed bool) (*store.Command, error) {
+	subnet, err := s.store.GetSubnet(ctx, subnetID)
+	if err != nil {
+		return nil, fromStore(err, "")
+	}
+	if subnet == nil {
+		return nil, fmt.Errorf("subnet %w: %s", ErrNotFound, subnetID)
+	}
+
+	ips, err := calculateUsableIPs(subnet)
+	if err != nil {
+		return nil, invalidInput("cannot sweep %s: %s", subnet.NetworkAddress, err)
+	}
+	if err := (types.SubnetSweepParams{IPs: ips}).Validate(); err != nil {
+		return nil, invalidInput("cannot sweep %s: %s", subnet.NetworkAddress, err)
+	}
+
+	if len(agentIDs) == 0 {
+		agentID, err := s.pickSweepAgent(ctx)
+		if err != nil {
+			return nil, err
+		}
+		agentIDs = []string{agentID}
+	}
+
+	now := time.Now()
+	expires := now.Add(config.SubnetSweepCommandTTL)
+	cmd := &store.Command{
+		ID:          uuid.New().String(),
+		CommandType: types.CommandSubnetSweep,
+		Params: map[string]any{
+			"ips":       ips,
+			"subnet_id": subnet.ID,
+			"auto_seed": autoSeed,
+		},
+		AgentIDs:    agentIDs,
+		Status:      "pending",
+		RequestedAt: now,
+		ExpiresAt:   &expires,
+	}
+	if err := s.store.CreateCommand(ctx, cmd); err != nil {
+		return nil, fmt.Errorf("creating sweep command: %w", err)
+	}
+
+	s.logger.Info("subnet sweep queued",
+		"command_id", cmd.ID,
+		"subnet_id", subnet.ID,
+		"network", subnet.NetworkAddress,
+		"addresses", len(ips),
+		"agents", agentIDs,
+		"auto_seed", autoSeed,
+	)
+	return cmd, nil
+}
+
+// pickSweepAgent returns the first active agent that can run ICMP probes.
+func (s *Service) pickSweepAgent(ctx context.Context) (string, error) {
+	agents, err := s.store.ListActiveAgents(ctx)
+	if err != nil {
+		return "", fmt.Errorf("listing agents: %w", err)
+	}
+	for _, a := range agents {
+		if slices.Contains(a.Executors, "icmp_ping") {
+			return a.ID, nil
+		}
+	}
+	return "", newError(ErrConflict, nil, "no active agent can run ICMP probes")
+}
+
+// sweptIPs returns the responsive addresses from a sweep result that were
+// in the sweep, in the order reported. Anything else the agent returned is
+// ignored rather than seeded.
+func sweptIPs(params map[string]any, payload types.SubnetSweepPayload) []string {
+	requested := make(map[string]bool)
+	if ips, ok := params["ips"].([]any); ok {
+		for _, ip := range ips {
+			if s, ok := ip.(string); ok {
+				requested[s] = true
+			}
+		}
+	}
+
+	var ips []string
+	for _, h := range payload.Responsive {
+		if requested[h.IP] {
+			ips = append(ips, h.IP)
+			delete(requested, h.IP) // report each address once
+		}
+	}
+	return ips
+}
+
+// seedSweepResult creates discovery targets for the responsive addresses in
+// an auto-seeding sweep's result. Addresses that are already targets are
+// left alone.
+func (s *Service) seedSweepResult(ctx context.Context, cmd *store.Command, result *store.CommandResult) {
+	if autoSeed, _ := cmd.Params["auto_seed"].(bool); !autoSeed || !result.Success {
+		return
+	}
+	subnetID, _ := cmd.Params["subnet_id"].(string)
+	subnet, err := s.store.GetSubnet(ctx, subnetID)
+	if err != nil || subnet == nil {
+		s.logger.Warn("sweep subnet not found, skipping seeding", "command_id", cmd.ID, "subnet_id", subnetID, "error", err)
+		return
+	}
+
+	var payload types.SubnetSweepPayload
+	if err := json.Unmarshal(result.Payload, &payload); err != nil {
+		s.logger.Warn("invalid sweep result payload", "command_id", cmd.ID, "agent", result.AgentID, "error", err)
+		return
+	}
+
+	ips := sweptIPs(cmd.Params, payload)
+	failed := 0
+	for _, ip := range ips {
+		err := s.store.CreateAutoTarget(ctx, store.AutoTargetParams{
+			ID:              uuid.New().String(),
+			IP:              ip,
+			SubnetID:        subnet.ID,
+			IPType:          types.IPTypeCustomer,
+			Tier:            "standard",
+			Ownership:       types.OwnershipAuto,
+			Origin:          types.OriginDiscovery,
+			MonitoringState: types.StateUnknown,
+			Tags: map[string]string{
+				"auto_seeded":   "true",
+				"subnet":        subnet.NetworkAddress,
+				"discovered_by": types.CommandSubnetSweep,
+			},
+		})
+		if err != nil {
+			s.logger.Warn("failed to seed swept address", "ip", ip, "subnet_id", subnet.ID, "error", err)
+			failed++
+		}
+	}
+
+	s.logger.Info("subnet sweep seeded targets",
+		"command_id", cmd.ID,
+		"subnet_id", subnet.ID,
+		"responsive", len(ips),
+		"failed", failed,
+	)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestSweptIPs_Filtering(t *testing.T) {
+	// Params come back from the commands table as decoded JSON
+	var params map[string]any
+	json.Unmarshal([]byte(`{"ips":["192.0.2.1","192.0.2.2","192.0.2.3"],"auto_seed":true}`), &params)
+
+	tests := []struct {
+		name       string
+		params     map[string]any
+		responsive []string
+		want       []string
+	}{
+		{"responsive subset", params, []string{"192.0.2.3", "192.0.2.1"}, []string{"192.0.2.3", "192.0.2.1"}},
+		{"nothing answered", params, nil, nil},
+		{"unrequested address ignored", params, []string{"192.0.2.2", "198.51.100.7"}, []string{"192.0.2.2"}},
+		{"duplicate reported once", params, []string{"192.0.2.1", "192.0.2.1"}, []string{"192.0.2.1"}},
+		{"no ips in params", map[string]any{}, []string{"192.0.2.1"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var payload types.SubnetSweepPayload
+			for _, ip := range tt.responsive {
+				payload.Responsive = append(payload.Responsive, types.SweepHost{IP: ip})
+			}
+			if got := sweptIPs(tt.params, payload); !slices.Equal(got, tt.want) {
+				t.Fatalf("sweptIPs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
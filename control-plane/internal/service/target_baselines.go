@@ -0,0 +1,28 @@
+package service
+
+import (
+	"context"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// RecalculateTargetBaselines recalculates the baselines of every agent
+// probing one target, e.g. after a fix or config change that moved its
+// latency, and returns the refreshed baselines.
+func (s *Service) RecalculateTargetBaselines(ctx context.Context, targetID string) ([]store.AgentTargetBaseline, error) {
+	if _, err := s.requireTarget(ctx, targetID); err != nil {
+		return nil, err
+	}
+
+	count, err := s.store.RecalculateBaselines(ctx, store.BaselineScope{TargetID: targetID})
+	if err != nil {
+		return nil, fromStore(err, "target not found")
+	}
+
+	baselines, err := s.store.GetBaselinesForTarget(ctx, targetID)
+	if err != nil {
+		return nil, fromStore(err, "target not found")
+	}
+	s.logger.Info("target baselines recalculated", "target_id", targetID, "pairs", count)
+	return baselines, nil
+}
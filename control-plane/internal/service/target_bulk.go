@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// BULK TARGET CREATION
+// =============================================================================
+
+// BulkTargetStatus is what happened to one row of a bulk create.
+type BulkTargetStatus string
+
+const (
+	// BulkTargetCreated means the row's target was created.
+	BulkTargetCreated BulkTargetStatus = "created"
+
+	// BulkTargetSkippedDuplicate means a target already held the row's IP,
+	// or an earlier row in the request claimed it.
+	BulkTargetSkippedDuplicate BulkTargetStatus = "skipped_duplicate"
+
+	// BulkTargetError means the row was invalid and nothing was written.
+	BulkTargetError BulkTargetStatus = "error"
+)
+
+// BulkTargetResult is the outcome of one row of a bulk create.
+type BulkTargetResult struct {
+	Index  int              `json:"index"` // Position in the request
+	IP     string           `json:"ip"`
+	Status BulkTargetStatus `json:"status"`
+	ID     string           `json:"id,omitempty"` // Created target, or the one holding the IP
+	Error  string           `json:"error,omitempty"`
+}
+
+// BulkCreateTargetsResult is every row's outcome, with counts by status.
+type BulkCreateTargetsResult struct {
+	Created int                `json:"created"`
+	Skipped int                `json:"skipped"`
+	Failed  int                `json:"failed"`
+	Results []BulkTargetResult `json:"results"`
+}
+
+// newTarget builds the target a create request describes, with a new ID.
+func newTarget(req CreateTargetRequest) *types.Target {
+	now := time.Now()
+	return &types.Target{
+		ID:                   uuid.New().String(),
+		IP:                   req.IP,
+		Tier:                 req.Tier,
+		SubscriberID:         req.SubscriberID,
+		Tags:                 req.Tags,
+		ExpectedOutcome:      req.ExpectedOutcome,
+		DSCP:                 req.DSCP,
+		Region:               strings.TrimSpace(req.Region),
+		RetentionDays:        req.RetentionDays,
+		DownThresholdSeconds: req.DownThreshold,
+		ProbeType:            strings.TrimSpace(req.ProbeType),
+		ProbeParams:          req.ProbeParams,
+		ProbingEnabled:       true,
+		CreatedAt:            now,
+		UpdatedAt:            now,
+	}
+}
+
+// BulkCreateTargets creates up to config.BulkTargetCreateMax targets in one
+// transaction and reports each row's outcome in request order. Invalid rows
+// and rows naming an unknown tier are reported as errors without failing
+// the rest. A row whose IP is already taken is skipped, whatever its
+// OnDuplicate, and reports the ID of the target holding the IP.
+func (s *Service) BulkCreateTargets(ctx context.Context, reqs []CreateTargetRequest) (*BulkCreateTargetsResult, error) {
+	if len(reqs) == 0 {
+		return nil, invalidInput("at least one target is required")
+	}
+	if len(reqs) > config.BulkTargetCreateMax {
+		return nil, newError(ErrInvalidInput, map[string]any{"limit": config.BulkTargetCreateMax},
+			"at most %d targets can be created at once, got %d", config.BulkTargetCreateMax, len(reqs))
+	}
+
+	tiers, err := s.store.ListTiers(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("listing tiers: %w", err)
+	}
+	known := make(map[string]bool, len(tiers))
+	for _, t := range tiers {
+		known[t.Name] = true
+	}
+
+	out := &BulkCreateTargetsResult{Results: make([]BulkTargetResult, len(reqs))}
+	results := out.Results
+	targets := make([]types.Target, 0, len(reqs))
+	rowOf := make([]int, 0, len(reqs)) // request index of each target
+	for i, req := range reqs {
+		results[i] = BulkTargetResult{Index: i, IP: req.IP}
+		target := newTarget(req)
+		if err := target.Validate(); err != nil {
+			results[i].Status, results[i].Error = BulkTargetError, err.Error()
+			out.Failed++
+			continue
+		}
+		if !known[target.Tier] {
+			results[i].Status, results[i].Error = BulkTargetError, "tier not found: "+target.Tier
+			out.Failed++
+			continue
+		}
+		targets = append(targets, *target)
+		rowOf = append(rowOf, i)
+	}
+
+	ids, err := s.store.BulkCreateTargets(ctx, targets)
+	if err != nil {
+		return nil, fromStore(err, "")
+	}
+
+	for j, id := range ids {
+		r := &results[rowOf[j]]
+		r.ID = id
+		if id == targets[j].ID {
+			r.Status = BulkTargetCreated
+			out.Created++
+		} else {
+			r.Status = BulkTargetSkippedDuplicate
+			out.Skipped++
+		}
+	}
+
+	s.logger.Info("targets bulk created",
+		"requested", len(reqs), "created", out.Created, "skipped", out.Skipped, "failed", out.Failed)
+	return out, nil
+}
@@ -0,0 +1,44 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+func TestBulkCreateTargets_BatchSize(t *testing.T) {
+	tests := []struct {
+		name string
+		rows int
+	}{
+		{name: "empty", rows: 0},
+		{name: "over the cap", rows: config.BulkTargetCreateMax + 1},
+	}
+
+	// Both are rejected before the store is touched
+	svc := &Service{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := svc.BulkCreateTargets(context.Background(), make([]CreateTargetRequest, tt.rows))
+			if !errors.Is(err, ErrInvalidInput) {
+				t.Fatalf("BulkCreateTargets(%d rows) error = %v, want ErrInvalidInput", tt.rows, err)
+			}
+		})
+	}
+}
+
+func TestNewTarget_Normalized(t *testing.T) {
+	req := CreateTargetRequest{IP: "10.0.0.1", Tier: "standard", Region: " us-east ", ProbeType: " icmp_ping "}
+	a, b := newTarget(req), newTarget(req)
+	if a.ID == "" || a.ID == b.ID {
+		t.Errorf("IDs %q and %q, want distinct non-empty", a.ID, b.ID)
+	}
+	if a.Region != "us-east" || a.ProbeType != "icmp_ping" {
+		t.Errorf("region %q, probe type %q not trimmed", a.Region, a.ProbeType)
+	}
+	if !a.ProbingEnabled {
+		t.Error("new target not enabled for probing")
+	}
+}
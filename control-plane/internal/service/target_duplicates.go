@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// OnDuplicate says what CreateTarget does when a target already holds the
+// requested IP.
+type OnDuplicate string
+
+const (
+	// OnDuplicateReject fails with ErrConflict naming the existing target.
+	OnDuplicateReject OnDuplicate = "reject"
+
+	// OnDuplicateReturn returns the existing target unchanged.
+	OnDuplicateReturn OnDuplicate = "return"
+
+	// OnDuplicateMerge folds the request into the existing target; see
+	// mergeTargetRequest.
+	OnDuplicateMerge OnDuplicate = "merge"
+)
+
+// ParseOnDuplicate validates an on_duplicate option. Empty means reject.
+func ParseOnDuplicate(s string) (OnDuplicate, error) {
+	switch d := OnDuplicate(strings.TrimSpace(s)); d {
+	case "":
+		return OnDuplicateReject, nil
+	case OnDuplicateReject, OnDuplicateReturn, OnDuplicateMerge:
+		return d, nil
+	default:
+		return "", invalidInput("on_duplicate must be reject, return or merge")
+	}
+}
+
+// DuplicateTargetStore is the store access resolveDuplicateTarget needs.
+type DuplicateTargetStore interface {
+	GetTargetByIP(ctx context.Context, ip string) (*types.Target, error)
+	UpdateTarget(ctx context.Context, target *types.Target) error
+}
+
+// resolveDuplicateTarget handles a create whose IP is already taken,
+// returning the target to respond with.
+func resolveDuplicateTarget(ctx context.Context, st DuplicateTargetStore, logger *slog.Logger, req CreateTargetRequest) (*types.Target, error) {
+	existing, err := st.GetTargetByIP(ctx, req.IP)
+	if err != nil {
+		return nil, err
+	}
+	if existing == nil {
+		// Archived and purged between the insert and the lookup
+		return nil, newError(ErrConflict, nil, "target with IP %s was just removed, retry", req.IP)
+	}
+
+	details := map[string]any{"target_id": existing.ID}
+	if existing.ArchivedAt != nil {
+		return nil, newError(ErrConflict, details, "IP %s belongs to archived target %s", req.IP, existing.ID)
+	}
+
+	switch req.OnDuplicate {
+	case OnDuplicateReturn:
+		return existing, nil
+	case OnDuplicateMerge:
+		if !mergeTargetRequest(existing, req) {
+			return existing, nil
+		}
+		if err := st.UpdateTarget(ctx, existing); err != nil {
+			return nil, fmt.Errorf("merging into target: %w", err)
+		}
+		logger.Info("target create merged into existing", "ip", req.IP, "id", existing.ID)
+		return existing, nil
+	default:
+		return nil, newError(ErrConflict, details, "target with IP %s already exists: %s", req.IP, existing.ID)
+	}
+}
+
+// mergeTargetRequest folds a create request into the target already holding
+// its IP and reports whether anything changed. Tags are merged key by key,
+// the request winning, and expected outcome, DSCP, region and retention are
+// taken from the request when it sets them. Tier, subscriber and probe
+// type are left alone: changing what or how often a target is probed is an
+// update, not a resubmission.
+func mergeTargetRequest(t *types.Target, req CreateTargetRequest) bool {
+	changed := false
+	for k, v := range req.Tags {
+		if cur, ok := t.Tags[k]; !ok || cur != v {
+			if t.Tags == nil {
+				t.Tags = make(map[string]string, len(req.Tags))
+			}
+			t.Tags[k] = v
+			changed = true
+		}
+	}
+	if req.ExpectedOutcome != nil && !reflect.DeepEqual(t.ExpectedOutcome, req.ExpectedOutcome) {
+		t.ExpectedOutcome = req.ExpectedOutcome
+		changed = true
+	}
+	if req.DSCP != nil && (t.DSCP == nil || *t.DSCP != *req.DSCP) {
+		t.DSCP = req.DSCP
+		changed = true
+	}
+	if region := strings.TrimSpace(req.Region); region != "" && region != t.Region {
+		t.Region = region
+		changed = true
+	}
+	if req.RetentionDays != nil && (t.RetentionDays == nil || *t.RetentionDays != *req.RetentionDays) {
+		t.RetentionDays = req.RetentionDays
+		changed = true
+	}
+	if req.DownThreshold != nil && (t.DownThresholdSeconds == nil || *t.DownThresholdSeconds != *req.DownThreshold) {
+		t.DownThresholdSeconds = req.DownThreshold
+		changed = true
+	}
+	return changed
+}
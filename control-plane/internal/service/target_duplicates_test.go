@@ -0,0 +1,169 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestParseOnDuplicate_Values(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    OnDuplicate
+		wantErr bool
+	}{
+		{in: "", want: OnDuplicateReject},
+		{in: "reject", want: OnDuplicateReject},
+		{in: " return ", want: OnDuplicateReturn},
+		{in: "merge", want: OnDuplicateMerge},
+		{in: "replace", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := ParseOnDuplicate(tt.in)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidInput) {
+					t.Fatalf("ParseOnDuplicate(%q) error = %v, want ErrInvalidInput", tt.in, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseOnDuplicate(%q) = %q, %v, want %q", tt.in, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeTargetRequest_Fields(t *testing.T) {
+	dscp := func(v int) *int { return &v }
+
+	tests := []struct {
+		name        string
+		existing    types.Target
+		req         CreateTargetRequest
+		want        types.Target
+		wantChanged bool
+	}{
+		{
+			name:     "identical resubmission changes nothing",
+			existing: types.Target{Tier: "standard", Tags: map[string]string{"site": "ord"}, Region: "us-central"},
+			req:      CreateTargetRequest{Tier: "standard", Tags: map[string]string{"site": "ord"}, Region: "us-central"},
+			want:     types.Target{Tier: "standard", Tags: map[string]string{"site": "ord"}, Region: "us-central"},
+		},
+		{
+			name:        "tags merged with request winning",
+			existing:    types.Target{Tags: map[string]string{"site": "ord", "owner": "noc"}},
+			req:         CreateTargetRequest{Tags: map[string]string{"site": "dfw", "rack": "r12"}},
+			want:        types.Target{Tags: map[string]string{"site": "dfw", "owner": "noc", "rack": "r12"}},
+			wantChanged: true,
+		},
+		{
+			name:        "tags added to a target without any",
+			req:         CreateTargetRequest{Tags: map[string]string{"site": "ord"}},
+			want:        types.Target{Tags: map[string]string{"site": "ord"}},
+			wantChanged: true,
+		},
+		{
+			name:        "metadata set when given, tier and subscriber kept",
+			existing:    types.Target{Tier: "vip", SubscriberID: "sub-1", Region: "us-east"},
+			req:         CreateTargetRequest{Tier: "standard", SubscriberID: "sub-2", Region: " us-west ", DSCP: dscp(46)},
+			want:        types.Target{Tier: "vip", SubscriberID: "sub-1", Region: "us-west", DSCP: dscp(46)},
+			wantChanged: true,
+		},
+		{
+			name:     "unset fields leave existing values",
+			existing: types.Target{Region: "us-east", DSCP: dscp(10)},
+			req:      CreateTargetRequest{},
+			want:     types.Target{Region: "us-east", DSCP: dscp(10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.existing
+			changed := mergeTargetRequest(&got, tt.req)
+			if changed != tt.wantChanged {
+				t.Errorf("changed = %v, want %v", changed, tt.wantChanged)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("merged = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeDuplicateTargetStore serves one existing target and records updates.
+type fakeDuplicateTargetStore struct {
+	existing *types.Target
+	updated  *types.Target
+}
+
+func (f *fakeDuplicateTargetStore) GetTargetByIP(ctx context.Context, ip string) (*types.Target, error) {
+	if f.existing == nil || f.existing.IP != ip {
+		return nil, nil
+	}
+	t := *f.existing
+	return &t, nil
+}
+
+func (f *fakeDuplicateTargetStore) UpdateTarget(ctx context.Context, target *types.Target) error {
+	t := *target
+	f.updated = &t
+	return nil
+}
+
+func TestResolveDuplicateTarget_MergeKeepsDisplayNameAndNotes(t *testing.T) {
+	tests := []struct {
+		name        string
+		req         CreateTargetRequest
+		wantUpdated bool
+	}{
+		{
+			name:        "merge with changes",
+			req:         CreateTargetRequest{IP: "192.0.2.10", OnDuplicate: OnDuplicateMerge, Region: "us-west", Tags: map[string]string{"rack": "r12"}},
+			wantUpdated: true,
+		},
+		{
+			name: "merge without changes",
+			req:  CreateTargetRequest{IP: "192.0.2.10", OnDuplicate: OnDuplicateMerge},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := &fakeDuplicateTargetStore{existing: &types.Target{
+				ID:          "t-1",
+				IP:          "192.0.2.10",
+				DisplayName: "Core router",
+				Notes:       "Maintenance window Sundays",
+				Region:      "us-east",
+				Tags:        map[string]string{"site": "ord"},
+			}}
+			logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+			got, err := resolveDuplicateTarget(context.Background(), st, logger, tt.req)
+			if err != nil {
+				t.Fatalf("resolveDuplicateTarget() error = %v", err)
+			}
+			if (st.updated != nil) != tt.wantUpdated {
+				t.Fatalf("updated = %v, want %v", st.updated != nil, tt.wantUpdated)
+			}
+
+			checks := []*types.Target{got}
+			if st.updated != nil {
+				checks = append(checks, st.updated)
+			}
+			for _, c := range checks {
+				if c.DisplayName != "Core router" || c.Notes != "Maintenance window Sundays" {
+					t.Errorf("display_name, notes = %q, %q, want existing values kept", c.DisplayName, c.Notes)
+				}
+			}
+		})
+	}
+}
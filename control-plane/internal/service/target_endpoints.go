@@ -0,0 +1,209 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET ENDPOINTS
+// =============================================================================
+
+// AddTargetEndpointRequest describes an additional address or port to probe
+// for a target.
+type AddTargetEndpointRequest struct {
+	IP    string
+	Port  *int
+	Label string
+}
+
+// ListTargetEndpoints returns a target's additional endpoints.
+func (s *Service) ListTargetEndpoints(ctx context.Context, targetID string) ([]types.TargetEndpoint, error) {
+	if _, err := s.requireTarget(ctx, targetID); err != nil {
+		return nil, err
+	}
+	endpoints, err := s.store.ListTargetEndpoints(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if endpoints == nil {
+		endpoints = []types.TargetEndpoint{}
+	}
+	return endpoints, nil
+}
+
+// AddTargetEndpoint adds an endpoint to a target. Agents pick it up with
+// their next assignment sync.
+func (s *Service) AddTargetEndpoint(ctx context.Context, targetID string, req AddTargetEndpointRequest) (*types.TargetEndpoint, error) {
+	target, err := s.requireTarget(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &types.TargetEndpoint{TargetID: targetID, IP: req.IP, Port: req.Port, Label: req.Label}
+	if err := e.Validate(); err != nil {
+		return nil, invalidInput("%s", err.Error())
+	}
+	e.IP = netip.MustParseAddr(e.IP).Unmap().String()
+	if e.IP == target.IP && e.Port == nil {
+		return nil, invalidInput("endpoint duplicates the target's primary ip")
+	}
+
+	existing, err := s.store.ListTargetEndpoints(ctx, targetID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= config.EndpointsPerTargetMax {
+		return nil, invalidInput("target already has the maximum of %d endpoints", config.EndpointsPerTargetMax)
+	}
+
+	if err := s.store.CreateTargetEndpoint(ctx, e); err != nil {
+		if errors.Is(err, ErrConflict) {
+			return nil, newError(ErrConflict, nil, "target already has this endpoint")
+		}
+		return nil, fromStore(err, "target not found")
+	}
+	return e, nil
+}
+
+// DeleteTargetEndpoint removes one of a target's endpoints.
+func (s *Service) DeleteTargetEndpoint(ctx context.Context, targetID, endpointID string) error {
+	err := s.store.DeleteTargetEndpoint(ctx, targetID, endpointID)
+	if errors.Is(err, ErrNotFound) {
+		return newError(ErrNotFound, nil, "target endpoint not found")
+	}
+	return fromStore(err, "target endpoint not found")
+}
+
+// GetTargetEndpointStatus breaks a target's state over the last window down
+// by endpoint and address family, listing any family failing while another
+// answers.
+func (s *Service) GetTargetEndpointStatus(ctx context.Context, targetID string, window time.Duration) (*types.TargetEndpointStatus, error) {
+	statuses, err := s.store.GetEndpointStatuses(ctx, []string{targetID}, window)
+	if err != nil {
+		return nil, fromStore(err, "target not found")
+	}
+	endpoints, ok := statuses[targetID]
+	if !ok {
+		return nil, newError(ErrNotFound, nil, "target not found")
+	}
+
+	families := types.SummarizeFamilies(endpoints)
+	return &types.TargetEndpointStatus{
+		TargetID:        targetID,
+		Window:          window.String(),
+		Endpoints:       endpoints,
+		Families:        families,
+		FailingFamilies: types.FailingFamilies(families),
+	}, nil
+}
+
+// GetTargetEndpointHistory returns a target's history per endpoint, its
+// primary IP included, on the same buckets as the target history.
+func (s *Service) GetTargetEndpointHistory(ctx context.Context, targetID string, window time.Duration) ([]store.EndpointHistoryPoint, error) {
+	if _, err := s.requireTarget(ctx, targetID); err != nil {
+		return nil, err
+	}
+	history, err := s.store.GetTargetEndpointHistory(ctx, targetID, window, targetHistoryBucketSize(window))
+	if err != nil {
+		return nil, err
+	}
+	if history == nil {
+		history = []store.EndpointHistoryPoint{}
+	}
+	return history, nil
+}
+
+// requireTarget returns the target, or ErrNotFound if it doesn't exist.
+func (s *Service) requireTarget(ctx context.Context, targetID string) (*types.Target, error) {
+	target, err := s.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return nil, fromStore(err, "target not found")
+	}
+	if target == nil {
+		return nil, newError(ErrNotFound, nil, "target not found")
+	}
+	return target, nil
+}
+
+// withEndpointAssignments appends an assignment for each additional
+// endpoint of the assigned targets, so every agent probing a target also
+// probes its endpoints. If endpoints can't be loaded the primary
+// assignments are returned alone rather than failing the sync.
+func (s *Service) withEndpointAssignments(ctx context.Context, assignments []types.Assignment) []types.Assignment {
+	ids := make([]string, len(assignments))
+	for i, a := range assignments {
+		ids[i] = a.TargetID
+	}
+	endpoints, err := s.store.ListEndpointsForTargets(ctx, ids)
+	if err != nil {
+		s.logger.Warn("failed to load target endpoints, assigning primary IPs only", "error", err)
+		return assignments
+	}
+	if len(endpoints) == 0 {
+		return assignments
+	}
+
+	out := assignments
+	for _, a := range assignments {
+		for _, e := range endpoints[a.TargetID] {
+			ea, err := endpointAssignment(a, e)
+			if err != nil {
+				s.logger.Warn("skipping target endpoint", "target_id", a.TargetID, "endpoint_id", e.ID, "error", err)
+				continue
+			}
+			out = append(out, ea)
+		}
+	}
+	return out
+}
+
+// endpointAssignment derives an endpoint's assignment from its target's,
+// probing the endpoint's address and, when set, its port.
+func endpointAssignment(a types.Assignment, e types.TargetEndpoint) (types.Assignment, error) {
+	a.IP = e.IP
+	a.EndpointID = e.ID
+	if e.Port != nil {
+		params, err := withPort(a.ProbeParams, *e.Port)
+		if err != nil {
+			return a, err
+		}
+		a.ProbeParams = params
+	}
+	return a, nil
+}
+
+// withPort sets the "port" key of a probe params object, leaving the other
+// keys as they are.
+func withPort(params json.RawMessage, port int) (json.RawMessage, error) {
+	fields := make(map[string]json.RawMessage)
+	if len(params) > 0 && string(params) != "null" {
+		if err := json.Unmarshal(params, &fields); err != nil {
+			return nil, fmt.Errorf("probe params are not an object: %w", err)
+		}
+	}
+	fields["port"] = json.RawMessage(fmt.Sprint(port))
+	return json.Marshal(fields)
+}
+
+// splitEndpointResults separates results for targets' additional endpoints
+// from results for their primary IPs.
+func splitEndpointResults(results []types.ProbeResult) (primary, endpoint []types.ProbeResult) {
+	primary = make([]types.ProbeResult, 0, len(results))
+	for _, r := range results {
+		if r.EndpointID != "" {
+			endpoint = append(endpoint, r)
+			continue
+		}
+		primary = append(primary, r)
+	}
+	return primary, endpoint
+}
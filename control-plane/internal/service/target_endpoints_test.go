@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestEndpointAssignment_Params(t *testing.T) {
+	port := 8443
+
+	tests := []struct {
+		name       string
+		params     string
+		port       *int
+		wantParams string
+		wantErr    bool
+	}{
+		{name: "no port keeps params", params: `{"server_name":"example.com"}`, wantParams: `{"server_name":"example.com"}`},
+		{name: "port added to empty params", port: &port, wantParams: `{"port":8443}`},
+		{name: "port added to null params", params: `null`, port: &port, wantParams: `{"port":8443}`},
+		{name: "port overrides target port", params: `{"port":443,"server_name":"example.com"}`, port: &port, wantParams: `{"port":8443,"server_name":"example.com"}`},
+		{name: "params not an object", params: `[443]`, port: &port, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := types.Assignment{TargetID: "t1", IP: "192.0.2.1", ProbeType: types.ProbeTypeTLSCert}
+			if tt.params != "" {
+				target.ProbeParams = json.RawMessage(tt.params)
+			}
+			endpoint := types.TargetEndpoint{ID: "e1", TargetID: "t1", IP: "2001:db8::1", Port: tt.port}
+
+			got, err := endpointAssignment(target, endpoint)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("endpointAssignment() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.TargetID != "t1" || got.EndpointID != "e1" || got.IP != "2001:db8::1" {
+				t.Errorf("endpointAssignment() = target %q endpoint %q ip %q", got.TargetID, got.EndpointID, got.IP)
+			}
+			if string(got.ProbeParams) != tt.wantParams {
+				t.Errorf("ProbeParams = %s, want %s", got.ProbeParams, tt.wantParams)
+			}
+			if string(target.ProbeParams) != tt.params {
+				t.Errorf("target params changed to %s", target.ProbeParams)
+			}
+		})
+	}
+}
+
+func TestSplitEndpointResults_Mixed(t *testing.T) {
+	results := []types.ProbeResult{
+		{TargetID: "t1"},
+		{TargetID: "t1", EndpointID: "e1"},
+		{TargetID: "t2"},
+		{TargetID: "t2", EndpointID: "e2"},
+	}
+
+	primary, endpoint := splitEndpointResults(results)
+
+	tests := []struct {
+		name string
+		got  []types.ProbeResult
+		want []string
+	}{
+		{name: "primary", got: primary, want: []string{"", ""}},
+		{name: "endpoint", got: endpoint, want: []string{"e1", "e2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if len(tt.got) != len(tt.want) {
+				t.Fatalf("got %d results, want %d", len(tt.got), len(tt.want))
+			}
+			for i, r := range tt.got {
+				if r.EndpointID != tt.want[i] {
+					t.Errorf("result %d endpoint = %q, want %q", i, r.EndpointID, tt.want[i])
+				}
+			}
+		})
+	}
+}
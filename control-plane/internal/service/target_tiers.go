@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// BulkTierResult reports the outcome of a bulk tier reassignment.
+type BulkTierResult struct {
+	Tier    string `json:"tier"`
+	Changed int    `json:"changed"`
+}
+
+// BulkReassignTier moves the targets matching filter into tier. The filter
+// must have at least one condition, so a missing filter can't reclassify the
+// whole fleet, and the tier must exist. Agents pick up the new tier's probe
+// settings on their next assignment sync.
+func (s *Service) BulkReassignTier(ctx context.Context, filter *types.TargetFilter, tier, triggeredBy string) (*BulkTierResult, error) {
+	if tier == "" {
+		return nil, invalidInput("tier is required")
+	}
+	if filter.IsEmpty() {
+		return nil, invalidInput("target filter must have at least one condition")
+	}
+
+	existing, err := s.store.GetTier(ctx, tier)
+	if err != nil {
+		return nil, fmt.Errorf("getting tier: %w", err)
+	}
+	if existing == nil {
+		return nil, fmt.Errorf("tier %w: %s", ErrNotFound, tier)
+	}
+
+	changed, err := s.store.BulkSetTargetTier(ctx, filter, tier, triggeredBy)
+	if err != nil {
+		return nil, fromStore(fmt.Errorf("reassigning targets: %w", err), "")
+	}
+
+	s.logger.Info("bulk tier reassignment",
+		"tier", tier,
+		"changed", changed,
+		"triggered_by", triggeredBy,
+	)
+	return &BulkTierResult{Tier: tier, Changed: changed}, nil
+}
@@ -0,0 +1,32 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestBulkReassignTier_Validation(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *types.TargetFilter
+		tier    string
+		wantErr string
+	}{
+		{"missing tier", &types.TargetFilter{Tiers: []string{"standard"}}, "", "tier is required"},
+		{"missing filter", nil, "vip", "at least one condition"},
+		{"empty filter", &types.TargetFilter{Tags: map[string]string{}}, "vip", "at least one condition"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Validation fails before the store is touched
+			_, err := (&Service{}).BulkReassignTier(context.Background(), tt.filter, tt.tier, "test")
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("BulkReassignTier() error = %v, want containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TIER INTERVAL PREVIEW
+// =============================================================================
+
+// policyFanOut is how many agents the tier's selection policy would assign
+// each target, used when the tier has no assignments to measure.
+func policyFanOut(policy types.AgentSelectionPolicy, onlineAgents int) int {
+	if policy.Strategy == "all" || policy.Count <= 0 {
+		return onlineAgents
+	}
+	return min(policy.Count, onlineAgents)
+}
+
+// projectTierInterval projects the tier's probe rate, and each assigned
+// agent's, at the proposed interval.
+func projectTierInterval(tier *types.Tier, load *store.TierProbeLoad, proposed time.Duration) *types.TierIntervalPreview {
+	preview := &types.TierIntervalPreview{
+		Tier:             tier.Name,
+		CurrentInterval:  tier.ProbeInterval.String(),
+		ProposedInterval: proposed.String(),
+		Targets:          load.Targets,
+		ChangeFactor:     tier.ProbeInterval.Seconds() / proposed.Seconds(),
+		Agents:           []types.AgentLoadPreview{},
+	}
+
+	for _, a := range load.Agents {
+		current := float64(a.TierAssignments) / tier.ProbeInterval.Seconds()
+		projected := float64(a.TierAssignments) / proposed.Seconds()
+		preview.Assignments += a.TierAssignments
+		preview.Agents = append(preview.Agents, types.AgentLoadPreview{
+			AgentID:                 a.AgentID,
+			AgentName:               a.AgentName,
+			TierAssignments:         a.TierAssignments,
+			CurrentProbesPerSecond:  a.ProbesPerSecond,
+			ProposedProbesPerSecond: a.ProbesPerSecond - current + projected,
+			DeltaProbesPerSecond:    projected - current,
+		})
+	}
+	if preview.Assignments == 0 {
+		preview.Assignments = load.Targets * policyFanOut(tier.AgentSelection, load.OnlineAgents)
+	}
+	if load.Targets > 0 {
+		preview.FanOut = float64(preview.Assignments) / float64(load.Targets)
+	}
+
+	preview.CurrentProbesPerSecond = float64(preview.Assignments) / tier.ProbeInterval.Seconds()
+	preview.ProposedProbesPerSecond = float64(preview.Assignments) / proposed.Seconds()
+	preview.DeltaProbesPerSecond = preview.ProposedProbesPerSecond - preview.CurrentProbesPerSecond
+
+	if preview.ChangeFactor >= config.TierPreviewWarnFactor {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf(
+			"probe rate would rise %.1fx, from %.1f to %.1f probes/sec",
+			preview.ChangeFactor, preview.CurrentProbesPerSecond, preview.ProposedProbesPerSecond))
+	}
+	if proposed < tier.ProbeTimeout {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf(
+			"interval %s is shorter than the probe timeout %s", proposed, tier.ProbeTimeout))
+	}
+	return preview
+}
+
+// PreviewTierInterval projects the change in probe volume and per-agent load
+// if the tier's probe interval were set to proposed. Nothing is changed.
+func (s *Service) PreviewTierInterval(ctx context.Context, name string, proposed time.Duration) (*types.TierIntervalPreview, error) {
+	if proposed <= 0 {
+		return nil, invalidInput("probe_interval must be positive")
+	}
+
+	tier, err := s.store.GetTier(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("getting tier: %w", err)
+	}
+	if tier == nil {
+		return nil, fmt.Errorf("tier %w: %s", ErrNotFound, name)
+	}
+
+	load, err := s.store.GetTierProbeLoad(ctx, name, config.AgentOfflineThreshold)
+	if err != nil {
+		return nil, fmt.Errorf("getting tier probe load: %w", err)
+	}
+	return projectTierInterval(tier, load, proposed), nil
+}
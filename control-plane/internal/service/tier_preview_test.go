@@ -0,0 +1,87 @@
+package service
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestProjectTierInterval_Rates(t *testing.T) {
+	tier := &types.Tier{
+		Name:           "standard",
+		ProbeInterval:  30 * time.Second,
+		ProbeTimeout:   5 * time.Second,
+		AgentSelection: types.AgentSelectionPolicy{Strategy: "distributed", Count: 4},
+	}
+	assigned := &store.TierProbeLoad{
+		Targets:      300,
+		OnlineAgents: 10,
+		Agents: []store.AgentTierLoad{
+			{AgentID: "a", TierAssignments: 600, ProbesPerSecond: 25},
+			{AgentID: "b", TierAssignments: 300, ProbesPerSecond: 12},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		load            *store.TierProbeLoad
+		proposed        time.Duration
+		wantAssignments int
+		wantCurrent     float64
+		wantProposed    float64
+		wantAgentA      float64 // agent a's proposed total rate
+		wantWarnings    int
+	}{
+		{"halve interval", assigned, 15 * time.Second, 900, 30, 60, 45, 1},
+		{"double interval", assigned, time.Minute, 900, 30, 15, 15, 0},
+		{"fat-fingered 3s", assigned, 3 * time.Second, 900, 30, 300, 205, 2},
+		{"unassigned tier uses policy", &store.TierProbeLoad{Targets: 300, OnlineAgents: 10}, time.Minute, 1200, 40, 20, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := projectTierInterval(tier, tt.load, tt.proposed)
+
+			if p.Assignments != tt.wantAssignments {
+				t.Errorf("Assignments = %d, want %d", p.Assignments, tt.wantAssignments)
+			}
+			if !near(p.CurrentProbesPerSecond, tt.wantCurrent) || !near(p.ProposedProbesPerSecond, tt.wantProposed) {
+				t.Errorf("probes/sec = %.2f -> %.2f, want %.2f -> %.2f",
+					p.CurrentProbesPerSecond, p.ProposedProbesPerSecond, tt.wantCurrent, tt.wantProposed)
+			}
+			if len(p.Agents) > 0 && !near(p.Agents[0].ProposedProbesPerSecond, tt.wantAgentA) {
+				t.Errorf("agent a proposed = %.2f, want %.2f", p.Agents[0].ProposedProbesPerSecond, tt.wantAgentA)
+			}
+			if len(p.Warnings) != tt.wantWarnings {
+				t.Errorf("Warnings = %v, want %d", p.Warnings, tt.wantWarnings)
+			}
+		})
+	}
+}
+
+func TestPolicyFanOut_Strategies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy types.AgentSelectionPolicy
+		online int
+		want   int
+	}{
+		{"all", types.AgentSelectionPolicy{Strategy: "all"}, 12, 12},
+		{"distributed", types.AgentSelectionPolicy{Strategy: "distributed", Count: 4}, 12, 4},
+		{"distributed capped by fleet", types.AgentSelectionPolicy{Strategy: "distributed", Count: 4}, 2, 2},
+		{"no count", types.AgentSelectionPolicy{Strategy: "distributed"}, 7, 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policyFanOut(tt.policy, tt.online); got != tt.want {
+				t.Errorf("policyFanOut() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// validateWebhookConfig checks values for the webhook alert_config keys;
+// other keys pass. webhook_urls must be a string of comma-separated http
+// or https URLs, empty to disable webhooks, and webhook_secret a string.
+func validateWebhookConfig(key string, value any) error {
+	switch key {
+	case types.AlertConfigWebhookURLs:
+		list, ok := value.(string)
+		if !ok {
+			return newError(ErrInvalidInput, nil, "%s must be a string of comma-separated URLs", key)
+		}
+		for _, raw := range strings.Split(list, ",") {
+			raw = strings.TrimSpace(raw)
+			if raw == "" {
+				continue
+			}
+			u, err := url.Parse(raw)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				// Don't echo the URL; webhook URLs often embed credentials
+				return newError(ErrInvalidInput, nil, "%s entries must be http or https URLs", key)
+			}
+		}
+	case types.AlertConfigWebhookSecret:
+		if _, ok := value.(string); !ok {
+			return newError(ErrInvalidInput, nil, "%s must be a string", key)
+		}
+	}
+	return nil
+}
+
+// redactAlertConfigs returns configs with the webhook secret's value
+// replaced, leaving the caller's slice untouched.
+func redactAlertConfigs(configs []types.AlertConfig) []types.AlertConfig {
+	out := make([]types.AlertConfig, len(configs))
+	copy(out, configs)
+	for i := range out {
+		if out[i].Key == types.AlertConfigWebhookSecret && out[i].Value != "" {
+			out[i].Value = types.RedactedAlertConfigValue
+		}
+	}
+	return out
+}
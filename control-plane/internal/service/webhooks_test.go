@@ -0,0 +1,65 @@
+package service
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestValidateWebhookConfig_Values(t *testing.T) {
+	tests := []struct {
+		name    string
+		key     string
+		value   any
+		wantErr bool
+	}{
+		{name: "one url", key: types.AlertConfigWebhookURLs, value: "https://hooks.example.com/a"},
+		{name: "list with blanks", key: types.AlertConfigWebhookURLs, value: "https://a.example.com/x, ,http://b.example.com:8080/y"},
+		{name: "empty disables", key: types.AlertConfigWebhookURLs, value: ""},
+		{name: "non-http scheme", key: types.AlertConfigWebhookURLs, value: "ftp://a.example.com/x", wantErr: true},
+		{name: "no host", key: types.AlertConfigWebhookURLs, value: "https:///x", wantErr: true},
+		{name: "urls not a string", key: types.AlertConfigWebhookURLs, value: []any{"https://a.example.com"}, wantErr: true},
+		{name: "secret string", key: types.AlertConfigWebhookSecret, value: "s3cret"},
+		{name: "secret number", key: types.AlertConfigWebhookSecret, value: float64(42), wantErr: true},
+		{name: "other key untouched", key: "resolution_probe_count", value: float64(3)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateWebhookConfig(tt.key, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidInput) {
+				t.Errorf("err = %v, want ErrInvalidInput", err)
+			}
+		})
+	}
+}
+
+func TestRedactAlertConfigs_Secret(t *testing.T) {
+	configs := []types.AlertConfig{
+		{Key: types.AlertConfigWebhookURLs, Value: "https://hooks.example.com/a"},
+		{Key: types.AlertConfigWebhookSecret, Value: "s3cret"},
+	}
+
+	got := redactAlertConfigs(configs)
+
+	tests := []struct {
+		name string
+		got  any
+		want any
+	}{
+		{name: "secret redacted", got: got[1].Value, want: types.RedactedAlertConfigValue},
+		{name: "urls kept", got: got[0].Value, want: "https://hooks.example.com/a"},
+		{name: "input untouched", got: configs[1].Value, want: "s3cret"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("got %v, want %v", tt.got, tt.want)
+			}
+		})
+	}
+}
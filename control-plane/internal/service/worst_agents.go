@@ -0,0 +1,22 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// GetTargetWorstAgents ranks the agents that probed a target over the
+// window, worst loss then latency first, keeping at most limit of them.
+func (s *Service) GetTargetWorstAgents(ctx context.Context, targetID string, window time.Duration, limit int) (*types.TargetWorstAgents, error) {
+	if _, err := s.requireTarget(ctx, targetID); err != nil {
+		return nil, err
+	}
+	stats, err := s.store.GetTargetAgentPathStats(ctx, targetID, window)
+	if err != nil {
+		return nil, err
+	}
+	ranking := types.BuildTargetWorstAgents(targetID, window, stats, limit)
+	return &ranking, nil
+}
@@ -0,0 +1,84 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// =============================================================================
+// ERRORS
+// =============================================================================
+//
+// Store errors wrap these sentinels so callers can match with errors.Is
+// instead of inspecting message text. Messages keep their existing wording,
+// e.g. "health exclusion not found".
+
+var (
+	// ErrNotFound is wrapped when the row being read or changed doesn't exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrConflict is wrapped when a write would duplicate an existing row.
+	ErrConflict = errors.New("already exists")
+
+	// ErrInvalidCursor is wrapped when a pagination cursor can't be decoded.
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// TierInUseError is returned when deleting a tier that targets still use.
+type TierInUseError struct {
+	Tier    string
+	Targets int
+}
+
+func (e *TierInUseError) Error() string {
+	return fmt.Sprintf("cannot delete tier '%s': %d targets are using it", e.Tier, e.Targets)
+}
+
+// IncidentOpenError is returned when deleting an incident that isn't
+// resolved.
+type IncidentOpenError struct {
+	ID     string
+	Status string
+}
+
+func (e *IncidentOpenError) Error() string {
+	return fmt.Sprintf("cannot delete incident '%s': it is %s, only resolved incidents can be deleted", e.ID, e.Status)
+}
+
+// Postgres SQLSTATE codes the service layer translates into domain errors.
+const (
+	pgInvalidTextRepresentation = "22P02"
+	pgForeignKeyViolation       = "23503"
+	pgUniqueViolation           = "23505"
+)
+
+func pgErrorCode(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code
+	}
+	return ""
+}
+
+// IsForeignKeyViolation reports whether err is a write referencing a row
+// that doesn't exist.
+func IsForeignKeyViolation(err error) bool {
+	return pgErrorCode(err) == pgForeignKeyViolation
+}
+
+// IsUniqueViolation reports whether err is a write duplicating a unique key.
+func IsUniqueViolation(err error) bool {
+	return pgErrorCode(err) == pgUniqueViolation
+}
+
+// InvalidInputMessage returns Postgres's message when err is a value it
+// couldn't parse, such as a malformed UUID, or "" otherwise.
+func InvalidInputMessage(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgInvalidTextRepresentation {
+		return pgErr.Message
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// =============================================================================
+// READ REPLICA
+// =============================================================================
+//
+// Dashboard reads (statuses, history, metrics queries, reports) can be served
+// from a streaming read replica so they don't compete with result ingestion
+// on the primary. Writes, and reads that feed back into writes (workers,
+// assignments, state machines), always use the primary.
+//
+// Replica lag is measured in the background. While the replica is unreachable
+// or further behind than MaxLag, reads go to the primary.
+
+// ReplicaConfig configures read-replica routing.
+type ReplicaConfig struct {
+	// URL is the replica connection string. Empty disables the replica.
+	URL string
+
+	// MaxLag is the replication lag beyond which reads fall back to the primary.
+	MaxLag time.Duration
+
+	// CheckInterval is how often lag is measured.
+	CheckInterval time.Duration
+}
+
+// replica is a read-replica pool with its last measured lag.
+type replica struct {
+	pool   *pgxpool.Pool
+	config ReplicaConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	// usable is true while the last lag check succeeded within MaxLag
+	usable atomic.Bool
+	lag    atomic.Int64 // nanoseconds
+}
+
+// NewStoreWithReplica connects to the primary and a read replica, routing
+// dashboard reads to the replica while its lag stays under MaxLag.
+func NewStoreWithReplica(ctx context.Context, primaryURL string, replicaConfig ReplicaConfig, logger *slog.Logger) (*Store, error) {
+	s, err := NewStoreFromURL(ctx, primaryURL)
+	if err != nil {
+		return nil, err
+	}
+	if replicaConfig.URL == "" {
+		return s, nil
+	}
+
+	if replicaConfig.MaxLag <= 0 {
+		replicaConfig.MaxLag = config.ReplicaMaxLag
+	}
+	if replicaConfig.CheckInterval <= 0 {
+		replicaConfig.CheckInterval = config.ReplicaLagCheckInterval
+	}
+
+	pool, err := pgxpool.New(ctx, replicaConfig.URL)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("connecting to read replica: %w", err)
+	}
+
+	s.replica = &replica{
+		pool:   pool,
+		config: replicaConfig,
+		logger: logger.With("component", "read_replica"),
+		stopCh: make(chan struct{}),
+	}
+	// Measure once up front so routing is correct from the first request
+	s.replica.check(ctx)
+	go s.replica.monitor()
+
+	return s, nil
+}
+
+// reader returns the pool for read-only dashboard queries: the replica when
+// it is configured and caught up, otherwise the primary.
+func (s *Store) reader() *pgxpool.Pool {
+	if s.replica != nil && s.replica.usable.Load() {
+		return s.replica.pool
+	}
+	return s.pool
+}
+
+// ReplicaStatus reports whether a read replica is configured, whether reads
+// are currently routed to it, and its last measured lag.
+func (s *Store) ReplicaStatus() (configured, inUse bool, lag time.Duration) {
+	if s.replica == nil {
+		return false, false, 0
+	}
+	return true, s.replica.usable.Load(), time.Duration(s.replica.lag.Load())
+}
+
+func (r *replica) monitor() {
+	ticker := time.NewTicker(r.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stopCh:
+			return
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), config.DatabasePingTimeout)
+			r.check(ctx)
+			cancel()
+		}
+	}
+}
+
+// check measures replication lag and updates routing. A replica that has
+// replayed everything it received reports zero lag, so an idle primary
+// doesn't make a caught-up replica look stale.
+func (r *replica) check(ctx context.Context) {
+	var lagSeconds float64
+	err := r.pool.QueryRow(ctx, `
+		SELECT CASE
+			WHEN NOT pg_is_in_recovery() THEN 0
+			WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+			ELSE COALESCE(EXTRACT(EPOCH FROM NOW() - pg_last_xact_replay_timestamp()), 0)
+		END
+	`).Scan(&lagSeconds)
+
+	wasUsable := r.usable.Load()
+	if err != nil {
+		r.usable.Store(false)
+		if wasUsable {
+			r.logger.Warn("read replica unreachable, routing reads to primary", "error", err)
+		}
+		return
+	}
+
+	lag := time.Duration(lagSeconds * float64(time.Second))
+	r.lag.Store(int64(lag))
+	usable := lag <= r.config.MaxLag
+	r.usable.Store(usable)
+
+	switch {
+	case usable && !wasUsable:
+		r.logger.Info("routing reads to read replica", "lag", lag)
+	case !usable && wasUsable:
+		r.logger.Warn("read replica lagging, routing reads to primary", "lag", lag, "max_lag", r.config.MaxLag)
+	}
+}
+
+func (r *replica) close() {
+	close(r.stopCh)
+	r.pool.Close()
+}
@@ -11,16 +11,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pilot-net/icmp-mon/pkg/payload"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
 
 // Store provides database operations.
 type Store struct {
-	pool *pgxpool.Pool
+	pool    *pgxpool.Pool
+	replica *replica // Optional read replica for dashboard reads
+
+	payloadSampling *payload.Sampling // Nil stores every payload in full
+	bulkConcurrency int               // Parallel bulk lookup batches; 0 uses the default
+
+	claimsOnce sync.Once
+	claims     chan struct{} // Slots for open batch sequence claims
 }
 
 // NewStore creates a new store with the given connection pool.
@@ -37,8 +46,11 @@ func NewStoreFromURL(ctx context.Context, url string) (*Store, error) {
 	return &Store{pool: pool}, nil
 }
 
-// Close closes the database connection pool.
+// Close closes the database connection pools.
 func (s *Store) Close() {
+	if s.replica != nil {
+		s.replica.close()
+	}
 	s.pool.Close()
 }
 
@@ -60,12 +72,12 @@ func (s *Store) Pool() *pgxpool.Pool {
 func (s *Store) CreateAgent(ctx context.Context, agent *types.Agent) error {
 	tagsJSON, _ := json.Marshal(agent.Tags)
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO agents (id, name, region, location, provider, tags, public_ip, executors, max_targets, version, status, last_heartbeat)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		INSERT INTO agents (id, name, region, location, provider, tags, public_ip, executors, max_targets, version, status, last_heartbeat, system_info, started_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, NOW())
 	`,
 		agent.ID, agent.Name, agent.Region, agent.Location, agent.Provider,
 		tagsJSON, agent.PublicIP, agent.Executors, agent.MaxTargets, agent.Version,
-		agent.Status, time.Now(),
+		agent.Status, time.Now(), agent.SystemInfo,
 	)
 	return err
 }
@@ -77,12 +89,13 @@ func (s *Store) GetAgent(ctx context.Context, id string) (*types.Agent, error) {
 	err := s.pool.QueryRow(ctx, `
 		SELECT id, name, region, location, provider, tags, public_ip::text, executors, max_targets, version,
 			get_agent_status(last_heartbeat, archived_at) as status,
-			last_heartbeat, created_at, archived_at, archive_reason
+			last_heartbeat, created_at, archived_at, archive_reason, system_info
 		FROM agents WHERE id = $1
 	`, id).Scan(
 		&agent.ID, &agent.Name, &agent.Region, &agent.Location, &agent.Provider,
 		&tagsJSON, &agent.PublicIP, &agent.Executors, &agent.MaxTargets, &agent.Version,
 		&agent.Status, &agent.LastHeartbeat, &agent.CreatedAt, &agent.ArchivedAt, &agent.ArchiveReason,
+		&agent.SystemInfo,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -101,12 +114,13 @@ func (s *Store) GetAgentByName(ctx context.Context, name string) (*types.Agent,
 	err := s.pool.QueryRow(ctx, `
 		SELECT id, name, region, location, provider, tags, public_ip::text, executors, max_targets, version,
 			get_agent_status(last_heartbeat, archived_at) as status,
-			last_heartbeat, created_at, archived_at, archive_reason
+			last_heartbeat, created_at, archived_at, archive_reason, system_info
 		FROM agents WHERE name = $1
 	`, name).Scan(
 		&agent.ID, &agent.Name, &agent.Region, &agent.Location, &agent.Provider,
 		&tagsJSON, &agent.PublicIP, &agent.Executors, &agent.MaxTargets, &agent.Version,
 		&agent.Status, &agent.LastHeartbeat, &agent.CreatedAt, &agent.ArchivedAt, &agent.ArchiveReason,
+		&agent.SystemInfo,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -200,7 +214,7 @@ func (s *Store) ArchiveAgent(ctx context.Context, agentID string, reason string)
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("agent not found or already archived: %s", agentID)
+		return fmt.Errorf("agent %w or already archived: %s", ErrNotFound, agentID)
 	}
 	return nil
 }
@@ -216,12 +230,13 @@ func (s *Store) UnarchiveAgent(ctx context.Context, agentID string) error {
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("agent not found or not archived: %s", agentID)
+		return fmt.Errorf("agent %w or not archived: %s", ErrNotFound, agentID)
 	}
 	return nil
 }
 
-// UpdateAgent updates all fields of an existing agent.
+// UpdateAgent updates all fields of an existing agent on re-registration,
+// recording it as started.
 func (s *Store) UpdateAgent(ctx context.Context, agent *types.Agent) error {
 	tagsJSON, _ := json.Marshal(agent.Tags)
 
@@ -236,11 +251,13 @@ func (s *Store) UpdateAgent(ctx context.Context, agent *types.Agent) error {
 			executors = $8,
 			max_targets = $9,
 			status = $10,
+			system_info = $11,
 			last_heartbeat = NOW(),
+			started_at = NOW(),
 			updated_at = NOW()
 		WHERE id = $1
 	`, agent.ID, agent.Region, agent.Location, agent.Provider, tagsJSON,
-		agent.PublicIP, agent.Version, agent.Executors, agent.MaxTargets, agent.Status)
+		agent.PublicIP, agent.Version, agent.Executors, agent.MaxTargets, agent.Status, agent.SystemInfo)
 	return err
 }
 
@@ -263,7 +280,7 @@ func (s *Store) UpdateAgentInfo(ctx context.Context, agentID string, name, regio
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
 	}
 	return nil
 }
@@ -282,7 +299,7 @@ func (s *Store) SetAgentAPIKey(ctx context.Context, agentID, keyHash string) err
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
 	}
 	return nil
 }
@@ -318,7 +335,7 @@ func (s *Store) SetAgentTailscaleIP(ctx context.Context, agentID, tailscaleIP st
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
 	}
 	return nil
 }
@@ -336,23 +353,43 @@ func (s *Store) RevokeAgentAPIKey(ctx context.Context, agentID string) error {
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("agent not found: %s", agentID)
+		return fmt.Errorf("agent %w: %s", ErrNotFound, agentID)
 	}
 	return nil
 }
 
 // RecordAgentMetrics stores agent health metrics.
 func (s *Store) RecordAgentMetrics(ctx context.Context, agentID string, heartbeat types.Heartbeat) error {
+	var shedJSON, intervalsJSON, alignmentJSON, backedOffJSON []byte
+	if len(heartbeat.ProbesShedByTier) > 0 {
+		shedJSON, _ = json.Marshal(heartbeat.ProbesShedByTier)
+	}
+	if len(heartbeat.ProbesBackedOffByTier) > 0 {
+		backedOffJSON, _ = json.Marshal(heartbeat.ProbesBackedOffByTier)
+	}
+	if len(heartbeat.EffectiveIntervals) > 0 {
+		intervalsJSON, _ = json.Marshal(heartbeat.EffectiveIntervals)
+	}
+	if len(heartbeat.ScheduleAlignment) > 0 {
+		alignmentJSON, _ = json.Marshal(heartbeat.ScheduleAlignment)
+	}
+
 	_, err := s.pool.Exec(ctx, `
 		INSERT INTO agent_metrics (
 			time, agent_id, status, cpu_percent, memory_mb, goroutine_count,
 			public_ip, active_targets, probes_per_second, results_queued, results_shipped,
-			assignment_version
-		) VALUES (NOW(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+			assignment_version, probes_shed_by_tier, effective_intervals, schedule_alignment,
+			results_failed, batches_shipped, ship_failures, results_bytes_shipped, results_bytes_uncompressed,
+			shipping_endpoint, endpoint_failovers, config_version, oldest_queued_age_ms,
+			probes_backed_off_by_tier
+		) VALUES (NOW(), $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, NULLIF($20, ''), $21, $22, $23, $24)
 	`,
 		agentID, heartbeat.Status, heartbeat.CPUPercent, heartbeat.MemoryMB, heartbeat.GoroutineCount,
 		heartbeat.PublicIP, heartbeat.ActiveTargets, heartbeat.ProbesPerSecond, heartbeat.ResultsQueued, heartbeat.ResultsShipped,
-		heartbeat.AssignmentVersion,
+		heartbeat.AssignmentVersion, shedJSON, intervalsJSON, alignmentJSON,
+		heartbeat.ResultsFailed, heartbeat.BatchesShipped, heartbeat.ShipFailures, heartbeat.BytesShipped, heartbeat.BytesShippedUncompressed,
+		heartbeat.ShippingEndpoint, heartbeat.EndpointFailovers, heartbeat.ConfigVersion, heartbeat.OldestQueuedAgeMs,
+		backedOffJSON,
 	)
 	return err
 }
@@ -368,13 +405,37 @@ type AgentMetricsPoint struct {
 	ProbesPerSecond float64   `json:"probes_per_second"`
 	ResultsQueued   int       `json:"results_queued"`
 	ResultsShipped  int64     `json:"results_shipped"`
+
+	// Shipping totals since agent start; see types.Heartbeat.
+	BatchesShipped    int64 `json:"batches_shipped"`
+	ShipFailures      int64 `json:"ship_failures"`
+	BytesShipped      int64 `json:"bytes_shipped"`
+	BytesUncompressed int64 `json:"bytes_uncompressed"`
+
+	// ShippingEndpoint is the results URL in use; EndpointFailovers counts
+	// switches to a failover endpoint since agent start.
+	ShippingEndpoint  string `json:"shipping_endpoint,omitempty"`
+	EndpointFailovers int64  `json:"endpoint_failovers"`
+
+	ProbesShedByTier   map[string]int64          `json:"probes_shed_by_tier,omitempty"`
+	EffectiveIntervals map[string]map[string]int `json:"effective_intervals,omitempty"`
+	ScheduleAlignment  map[string]string         `json:"schedule_alignment,omitempty"`
+
+	// ProbesBackedOffByTier counts targets probed at a backed-off interval
+	// because they are down.
+	ProbesBackedOffByTier map[string]int `json:"probes_backed_off_by_tier,omitempty"`
 }
 
 // GetAgentMetrics returns time-series metrics for an agent within the given duration.
 func (s *Store) GetAgentMetrics(ctx context.Context, agentID string, duration time.Duration) ([]AgentMetricsPoint, error) {
-	rows, err := s.pool.Query(ctx, `
+	rows, err := s.reader().Query(ctx, `
 		SELECT time, status, cpu_percent, memory_mb, goroutine_count,
-			   active_targets, probes_per_second, results_queued, results_shipped
+			   active_targets, probes_per_second, results_queued, results_shipped,
+			   probes_shed_by_tier, effective_intervals, schedule_alignment,
+			   COALESCE(batches_shipped, 0), COALESCE(ship_failures, 0),
+			   COALESCE(results_bytes_shipped, 0), COALESCE(results_bytes_uncompressed, 0),
+			   COALESCE(shipping_endpoint, ''), COALESCE(endpoint_failovers, 0),
+			   probes_backed_off_by_tier
 		FROM agent_metrics
 		WHERE agent_id = $1 AND time > NOW() - $2::interval
 		ORDER BY time ASC
@@ -390,10 +451,25 @@ func (s *Store) GetAgentMetrics(ctx context.Context, agentID string, duration ti
 		var cpu, memory, pps *float64
 		var goroutines, targets, queued *int
 		var shipped *int64
+		var shedJSON, intervalsJSON, alignmentJSON, backedOffJSON []byte
 		if err := rows.Scan(&p.Time, &p.Status, &cpu, &memory, &goroutines,
-			&targets, &pps, &queued, &shipped); err != nil {
+			&targets, &pps, &queued, &shipped, &shedJSON, &intervalsJSON, &alignmentJSON,
+			&p.BatchesShipped, &p.ShipFailures, &p.BytesShipped, &p.BytesUncompressed,
+			&p.ShippingEndpoint, &p.EndpointFailovers, &backedOffJSON); err != nil {
 			return nil, err
 		}
+		if len(shedJSON) > 0 {
+			json.Unmarshal(shedJSON, &p.ProbesShedByTier)
+		}
+		if len(intervalsJSON) > 0 {
+			json.Unmarshal(intervalsJSON, &p.EffectiveIntervals)
+		}
+		if len(alignmentJSON) > 0 {
+			json.Unmarshal(alignmentJSON, &p.ScheduleAlignment)
+		}
+		if len(backedOffJSON) > 0 {
+			json.Unmarshal(backedOffJSON, &p.ProbesBackedOffByTier)
+		}
 		if cpu != nil {
 			p.CPUPercent = *cpu
 		}
@@ -432,6 +508,9 @@ type AgentCurrentStats struct {
 	ProbesPerSecond float64   `json:"probes_per_second"`
 	ResultsQueued   int       `json:"results_queued"`
 	ResultsShipped  int64     `json:"results_shipped"`
+
+	// ShipLag is filled in by the service layer.
+	ShipLag *types.AgentShipLag `json:"ship_lag,omitempty"`
 }
 
 // GetAgentCurrentStats returns the most recent metrics for an agent.
@@ -496,6 +575,10 @@ type FleetOverview struct {
 	TotalResultsQueued int     `json:"total_results_queued"`
 	AvgCPUPercent      float64 `json:"avg_cpu_percent"`
 	AvgMemoryMB        float64 `json:"avg_memory_mb"`
+
+	// Shipment and Webhooks are filled in by the service layer.
+	Shipment *types.FleetShipment        `json:"shipment,omitempty"`
+	Webhooks *types.WebhookDeliveryStats `json:"webhooks,omitempty"`
 }
 
 // GetFleetOverview returns aggregated stats for all agents.
@@ -663,12 +746,22 @@ func (s *Store) CreateTarget(ctx context.Context, target *types.Target) error {
 	}
 
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO targets (id, ip_address, tier, subscriber_id, tags, expected_outcome)
-		VALUES ($1, $2, $3, $4, $5, $6)
-	`, target.ID, target.IP, target.Tier, subscriberID, tagsJSON, expectedJSON)
+		INSERT INTO targets (id, ip_address, tier, subscriber_id, tags, expected_outcome, dscp, region, retention_days,
+			probe_type, probe_params, down_threshold_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11, $12)
+	`, target.ID, target.IP, target.Tier, subscriberID, tagsJSON, expectedJSON, target.DSCP, target.Region,
+		target.RetentionDays, target.EffectiveProbeType(), nullableJSON(target.ProbeParams), target.DownThresholdSeconds)
 	return err
 }
 
+// nullableJSON stores an empty or JSON null value as SQL NULL.
+func nullableJSON(raw json.RawMessage) any {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+	return []byte(raw)
+}
+
 // AutoTargetParams contains parameters for auto-creating targets from subnets.
 type AutoTargetParams struct {
 	ID              string
@@ -707,13 +800,17 @@ func (s *Store) GetTarget(ctx context.Context, id string) (*types.Target, error)
 	var subscriberID, subnetID *string
 	err := s.pool.QueryRow(ctx, `
 		SELECT id, host(ip_address), tier, subscriber_id, tags, expected_outcome,
-			monitoring_state, archived_at, subnet_id,
-			created_at, updated_at
+			monitoring_state, archived_at, subnet_id, dscp, COALESCE(region, ''),
+			retention_days, probing_enabled, probing_changed_at, created_at, updated_at,
+			probe_type, probe_params, COALESCE(display_name, ''), COALESCE(notes, ''),
+			asn, COALESCE(as_name, ''), COALESCE(country, ''), down_threshold_seconds
 		FROM targets WHERE id = $1
 	`, id).Scan(
 		&target.ID, &target.IP, &target.Tier, &subscriberID, &tagsJSON, &expectedJSON,
-		&target.MonitoringState, &target.ArchivedAt, &subnetID,
-		&target.CreatedAt, &target.UpdatedAt,
+		&target.MonitoringState, &target.ArchivedAt, &subnetID, &target.DSCP, &target.Region,
+		&target.RetentionDays, &target.ProbingEnabled, &target.ProbingChangedAt, &target.CreatedAt, &target.UpdatedAt,
+		&target.ProbeType, &target.ProbeParams, &target.DisplayName, &target.Notes,
+		&target.ASN, &target.ASName, &target.Country, &target.DownThresholdSeconds,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -740,7 +837,9 @@ func (s *Store) ListTargets(ctx context.Context) ([]types.Target, error) {
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets ORDER BY ip_address
 	`)
 	if err != nil {
@@ -755,6 +854,7 @@ func (s *Store) ListTargets(ctx context.Context) ([]types.Target, error) {
 type TargetListParams struct {
 	Limit           int
 	Offset          int
+	Cursor          *string // Keyset pagination: nil = use Offset, "" = first page
 	Tier            string
 	MonitoringState string
 	Search          string // Searches IP address, display name
@@ -764,9 +864,10 @@ type TargetListParams struct {
 // TargetListResult contains paginated target results.
 type TargetListResult struct {
 	Targets    []types.Target `json:"targets"`
-	TotalCount int            `json:"total_count"`
+	TotalCount *int           `json:"total_count,omitempty"` // Not computed in cursor mode
 	Limit      int            `json:"limit"`
 	Offset     int            `json:"offset"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Empty on the last page
 }
 
 // ListTargetsPaginated returns targets with pagination and filtering.
@@ -800,6 +901,10 @@ func (s *Store) ListTargetsPaginated(ctx context.Context, params TargetListParam
 		whereClause = strings.Join(conditions, " AND ")
 	}
 
+	if params.Cursor != nil {
+		return s.listTargetsByCursor(ctx, conditions, args, params)
+	}
+
 	// Get total count
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM targets WHERE %s", whereClause)
 	var totalCount int
@@ -822,7 +927,9 @@ func (s *Store) ListTargetsPaginated(ctx context.Context, params TargetListParam
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE %s
 		ORDER BY ip_address
@@ -844,7 +951,7 @@ func (s *Store) ListTargetsPaginated(ctx context.Context, params TargetListParam
 
 	return &TargetListResult{
 		Targets:    targets,
-		TotalCount: totalCount,
+		TotalCount: &totalCount,
 		Limit:      params.Limit,
 		Offset:     params.Offset,
 	}, nil
@@ -858,7 +965,9 @@ func (s *Store) ListTargetsByTier(ctx context.Context, tier string) ([]types.Tar
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets WHERE tier = $1 ORDER BY ip_address
 	`, tier)
 	if err != nil {
@@ -878,14 +987,18 @@ func (s *Store) GetTier(ctx context.Context, name string) (*types.Tier, error) {
 	var tier types.Tier
 	var agentSelectionJSON, alertThresholdsJSON, expectedJSON []byte
 	var intervalMs, timeoutMs int
+	var backoffMs *int64
+	var bucketSecs, rawRetentionSecs *int32
 
 	err := s.pool.QueryRow(ctx, `
 		SELECT name, display_name, probe_interval_ms, probe_timeout_ms, probe_retries,
-		       agent_selection, alert_thresholds, default_expected_outcome
+		       agent_selection, alert_thresholds, default_expected_outcome, dscp, retention_days,
+		       ingest_mode, min_agents, down_backoff_max_ms, aggregate_bucket_seconds, raw_retention_seconds
 		FROM tiers WHERE name = $1
 	`, name).Scan(
 		&tier.Name, &tier.DisplayName, &intervalMs, &timeoutMs, &tier.ProbeRetries,
-		&agentSelectionJSON, &alertThresholdsJSON, &expectedJSON,
+		&agentSelectionJSON, &alertThresholdsJSON, &expectedJSON, &tier.DSCP, &tier.RetentionDays,
+		&tier.IngestMode, &tier.MinAgents, &backoffMs, &bucketSecs, &rawRetentionSecs,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -896,6 +1009,10 @@ func (s *Store) GetTier(ctx context.Context, name string) (*types.Tier, error) {
 
 	tier.ProbeInterval = time.Duration(intervalMs) * time.Millisecond
 	tier.ProbeTimeout = time.Duration(timeoutMs) * time.Millisecond
+	if backoffMs != nil {
+		tier.DownBackoffMaxInterval = time.Duration(*backoffMs) * time.Millisecond
+	}
+	setTierAggregation(&tier, bucketSecs, rawRetentionSecs)
 	json.Unmarshal(agentSelectionJSON, &tier.AgentSelection)
 	json.Unmarshal(expectedJSON, &tier.DefaultExpectedOutcome)
 
@@ -906,7 +1023,8 @@ func (s *Store) GetTier(ctx context.Context, name string) (*types.Tier, error) {
 func (s *Store) ListTiers(ctx context.Context) ([]types.Tier, error) {
 	rows, err := s.pool.Query(ctx, `
 		SELECT name, display_name, probe_interval_ms, probe_timeout_ms, probe_retries,
-		       agent_selection, alert_thresholds, default_expected_outcome
+		       agent_selection, alert_thresholds, default_expected_outcome, dscp, retention_days,
+		       ingest_mode, min_agents, down_backoff_max_ms, aggregate_bucket_seconds, raw_retention_seconds
 		FROM tiers ORDER BY name
 	`)
 	if err != nil {
@@ -919,16 +1037,23 @@ func (s *Store) ListTiers(ctx context.Context) ([]types.Tier, error) {
 		var tier types.Tier
 		var agentSelectionJSON, alertThresholdsJSON, expectedJSON []byte
 		var intervalMs, timeoutMs int
+		var backoffMs *int64
+		var bucketSecs, rawRetentionSecs *int32
 
 		if err := rows.Scan(
 			&tier.Name, &tier.DisplayName, &intervalMs, &timeoutMs, &tier.ProbeRetries,
-			&agentSelectionJSON, &alertThresholdsJSON, &expectedJSON,
+			&agentSelectionJSON, &alertThresholdsJSON, &expectedJSON, &tier.DSCP, &tier.RetentionDays,
+			&tier.IngestMode, &tier.MinAgents, &backoffMs, &bucketSecs, &rawRetentionSecs,
 		); err != nil {
 			return nil, err
 		}
 
 		tier.ProbeInterval = time.Duration(intervalMs) * time.Millisecond
 		tier.ProbeTimeout = time.Duration(timeoutMs) * time.Millisecond
+		if backoffMs != nil {
+			tier.DownBackoffMaxInterval = time.Duration(*backoffMs) * time.Millisecond
+		}
+		setTierAggregation(&tier, bucketSecs, rawRetentionSecs)
 		json.Unmarshal(agentSelectionJSON, &tier.AgentSelection)
 		json.Unmarshal(expectedJSON, &tier.DefaultExpectedOutcome)
 		tiers = append(tiers, tier)
@@ -956,10 +1081,14 @@ func (s *Store) CreateTier(ctx context.Context, tier *types.Tier) error {
 
 	_, err = s.pool.Exec(ctx, `
 		INSERT INTO tiers (name, display_name, probe_interval_ms, probe_timeout_ms, probe_retries,
-		                   agent_selection, default_expected_outcome)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		                   agent_selection, default_expected_outcome, dscp, retention_days, ingest_mode,
+		                   min_agents, down_backoff_max_ms, aggregate_bucket_seconds, raw_retention_seconds)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, COALESCE(NULLIF($10, ''), 'raw'), $11, NULLIF($12, 0),
+		        NULLIF($13, 0), NULLIF($14, 0))
 	`, tier.Name, tier.DisplayName, intervalMs, timeoutMs, tier.ProbeRetries,
-		agentSelectionJSON, expectedJSON)
+		agentSelectionJSON, expectedJSON, tier.DSCP, tier.RetentionDays, tier.IngestMode,
+		tier.MinAgents, tier.DownBackoffMaxInterval.Milliseconds(),
+		int(tier.AggregateBucket.Seconds()), int(tier.RawRetention.Seconds()))
 
 	return err
 }
@@ -985,16 +1114,21 @@ func (s *Store) UpdateTier(ctx context.Context, tier *types.Tier) error {
 	result, err := s.pool.Exec(ctx, `
 		UPDATE tiers
 		SET display_name = $2, probe_interval_ms = $3, probe_timeout_ms = $4,
-		    probe_retries = $5, agent_selection = $6, default_expected_outcome = $7
+		    probe_retries = $5, agent_selection = $6, default_expected_outcome = $7, dscp = $8,
+		    retention_days = $9, ingest_mode = COALESCE(NULLIF($10, ''), 'raw'),
+		    min_agents = $11, down_backoff_max_ms = NULLIF($12, 0),
+		    aggregate_bucket_seconds = NULLIF($13, 0), raw_retention_seconds = NULLIF($14, 0)
 		WHERE name = $1
 	`, tier.Name, tier.DisplayName, intervalMs, timeoutMs, tier.ProbeRetries,
-		agentSelectionJSON, expectedJSON)
+		agentSelectionJSON, expectedJSON, tier.DSCP, tier.RetentionDays, tier.IngestMode,
+		tier.MinAgents, tier.DownBackoffMaxInterval.Milliseconds(),
+		int(tier.AggregateBucket.Seconds()), int(tier.RawRetention.Seconds()))
 
 	if err != nil {
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("tier not found: %s", tier.Name)
+		return fmt.Errorf("tier %w: %s", ErrNotFound, tier.Name)
 	}
 	return nil
 }
@@ -1008,7 +1142,7 @@ func (s *Store) DeleteTier(ctx context.Context, name string) error {
 		return err
 	}
 	if count > 0 {
-		return fmt.Errorf("cannot delete tier '%s': %d targets are using it", name, count)
+		return &TierInUseError{Tier: name, Targets: count}
 	}
 
 	result, err := s.pool.Exec(ctx, `DELETE FROM tiers WHERE name = $1`, name)
@@ -1016,7 +1150,7 @@ func (s *Store) DeleteTier(ctx context.Context, name string) error {
 		return err
 	}
 	if result.RowsAffected() == 0 {
-		return fmt.Errorf("tier not found: %s", name)
+		return fmt.Errorf("tier %w: %s", ErrNotFound, name)
 	}
 	return nil
 }
@@ -1049,7 +1183,9 @@ func (s *Store) InsertProbeResults(ctx context.Context, results []types.ProbeRes
 			error_message TEXT,
 			latency_ms DOUBLE PRECISION,
 			packet_loss_pct DOUBLE PRECISION,
-			payload JSONB
+			reply_ttl SMALLINT,
+			payload JSONB,
+			probe_type TEXT
 		) ON COMMIT DROP
 	`)
 	if err != nil {
@@ -1059,15 +1195,16 @@ func (s *Store) InsertProbeResults(ctx context.Context, results []types.ProbeRes
 	// COPY data into staging table
 	rows := make([][]any, len(results))
 	for i, r := range results {
+		m := payload.Decode(r.Payload)
 		rows[i] = []any{
 			r.Timestamp, r.TargetID, r.AgentID, r.Success, r.Error,
-			getLatency(r.Payload), getPacketLoss(r.Payload), r.Payload,
+			m.Latency(), m.PacketLoss(), m.ReplyTTL(), s.payloadSampling.Store(r, m), r.ProbeType,
 		}
 	}
 
 	_, err = tx.CopyFrom(ctx,
 		pgx.Identifier{"probe_results_staging"},
-		[]string{"time", "target_id", "agent_id", "success", "error_message", "latency_ms", "packet_loss_pct", "payload"},
+		[]string{"time", "target_id", "agent_id", "success", "error_message", "latency_ms", "packet_loss_pct", "reply_ttl", "payload", "probe_type"},
 		pgx.CopyFromRows(rows),
 	)
 	if err != nil {
@@ -1076,19 +1213,24 @@ func (s *Store) InsertProbeResults(ctx context.Context, results []types.ProbeRes
 
 	// INSERT from staging to permanent table, computing region columns via JOINs
 	_, err = tx.Exec(ctx, `
-		INSERT INTO probe_results (time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct, payload,
-		                           agent_region, target_region, is_in_market)
+		INSERT INTO probe_results (time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct, reply_ttl, payload,
+		                           probe_type, agent_region, target_region, is_in_market)
 		SELECT
-			s.time, s.target_id, s.agent_id, s.success, s.error_message, s.latency_ms, s.packet_loss_pct, s.payload,
+			s.time, s.target_id, s.agent_id, s.success, s.error_message, s.latency_ms, s.packet_loss_pct, s.reply_ttl, s.payload,
+			NULLIF(s.probe_type, ''),
 			LOWER(TRIM(a.region)),
-			LOWER(TRIM(sub.region)),
-			(LOWER(TRIM(COALESCE(a.region, ''))) = LOWER(TRIM(COALESCE(sub.region, '')))
+			tr.region,
+			(LOWER(TRIM(COALESCE(a.region, ''))) = COALESCE(tr.region, '')
 			 AND a.region IS NOT NULL AND a.region != ''
-			 AND sub.region IS NOT NULL AND sub.region != '')
+			 AND tr.region IS NOT NULL AND tr.region != '')
 		FROM probe_results_staging s
 		JOIN agents a ON s.agent_id = a.id
 		LEFT JOIN targets t ON s.target_id = t.id
 		LEFT JOIN subnets sub ON t.subnet_id = sub.id
+		-- An explicit target region (manual targets) wins over the subnet's
+		CROSS JOIN LATERAL (
+			SELECT LOWER(TRIM(COALESCE(NULLIF(TRIM(t.region), ''), sub.region))) AS region
+		) tr
 		ON CONFLICT (time, target_id, agent_id) DO NOTHING
 	`)
 	if err != nil {
@@ -1134,30 +1276,6 @@ func (s *Store) GetAssignmentVersion(ctx context.Context) (int64, error) {
 	return version, err
 }
 
-// =============================================================================
-// HELPERS
-// =============================================================================
-
-func getLatency(payload json.RawMessage) *float64 {
-	var p struct {
-		AvgMs float64 `json:"avg_ms"`
-	}
-	if err := json.Unmarshal(payload, &p); err == nil && p.AvgMs > 0 {
-		return &p.AvgMs
-	}
-	return nil
-}
-
-func getPacketLoss(payload json.RawMessage) *float64 {
-	var p struct {
-		PacketLoss float64 `json:"packet_loss_pct"`
-	}
-	if err := json.Unmarshal(payload, &p); err == nil {
-		return &p.PacketLoss
-	}
-	return nil
-}
-
 // =============================================================================
 // TARGET STATUS & METRICS
 // =============================================================================
@@ -1176,6 +1294,14 @@ type TargetStatus struct {
 	TotalAgents      int       `json:"total_agents"`
 	LastProbe        time.Time `json:"last_probe"`
 	ProbeCount       int       `json:"probe_count"`
+
+	// LatencyAsymmetry compares latency across agent regions; nil with
+	// fewer than two regions reporting. Set by the service.
+	LatencyAsymmetry *types.LatencyAsymmetry `json:"latency_asymmetry,omitempty"`
+
+	// HealthScore ranks the target from 0 to 100; nil when no agent probed
+	// it in the window. Set by the service.
+	HealthScore *types.HealthScore `json:"health_score,omitempty"`
 }
 
 // GetTargetStatus returns the current status for a single target.
@@ -1185,7 +1311,7 @@ func (s *Store) GetTargetStatus(ctx context.Context, targetID string, window tim
 	cutoffTime := time.Now().Add(-window)
 
 	var lastProbe *time.Time
-	err := s.pool.QueryRow(ctx, `
+	err := s.reader().QueryRow(ctx, `
 		SELECT
 			host(t.ip_address),
 			t.tier,
@@ -1199,6 +1325,7 @@ func (s *Store) GetTargetStatus(ctx context.Context, targetID string, window tim
 			COUNT(*) as probe_count
 		FROM targets t
 		LEFT JOIN probe_results pr ON t.id = pr.target_id AND pr.time > $2
+			AND NOT agent_health_excluded(pr.agent_id, t.id)
 		WHERE t.id = $1
 		GROUP BY t.id, t.ip_address, t.tier
 	`, targetID, cutoffTime).Scan(
@@ -1226,7 +1353,7 @@ func (s *Store) GetTargetStatus(ctx context.Context, targetID string, window tim
 // GetAllTargetStatuses returns status for all targets.
 func (s *Store) GetAllTargetStatuses(ctx context.Context, window time.Duration) ([]TargetStatus, error) {
 	cutoffTime := time.Now().Add(-window)
-	rows, err := s.pool.Query(ctx, `
+	rows, err := s.reader().Query(ctx, `
 		SELECT
 			t.id,
 			host(t.ip_address),
@@ -1241,6 +1368,7 @@ func (s *Store) GetAllTargetStatuses(ctx context.Context, window time.Duration)
 			COUNT(pr.*) as probe_count
 		FROM targets t
 		LEFT JOIN probe_results pr ON t.id = pr.target_id AND pr.time > $1
+			AND NOT agent_health_excluded(pr.agent_id, t.id)
 		GROUP BY t.id, t.ip_address, t.tier
 		ORDER BY t.ip_address
 	`, cutoffTime)
@@ -1285,7 +1413,7 @@ type ProbeHistoryPoint struct {
 func (s *Store) GetTargetHistory(ctx context.Context, targetID string, window time.Duration, bucketSize time.Duration) ([]ProbeHistoryPoint, error) {
 	cutoffTime := time.Now().Add(-window)
 	bucketInterval := fmt.Sprintf("%d seconds", int(bucketSize.Seconds()))
-	rows, err := s.pool.Query(ctx, `
+	rows, err := s.reader().Query(ctx, `
 		SELECT
 			time_bucket($3::interval, time) as bucket,
 			AVG(latency_ms) FILTER (WHERE success) as avg_latency_ms,
@@ -1340,10 +1468,11 @@ func (s *Store) GetTargetHistoryByAgent(ctx context.Context, targetID string, wi
 	cutoffTime := time.Now().Add(-window)
 	bucketInterval := fmt.Sprintf("%d seconds", int(bucketSize.Seconds()))
 
-	// Get the target's subnet region for is_in_market calculation
+	// Get the target's region for is_in_market calculation
+	// (explicit target region first, then its subnet's)
 	var targetRegion *string
-	_ = s.pool.QueryRow(ctx, `
-		SELECT LOWER(TRIM(s.region))
+	_ = s.reader().QueryRow(ctx, `
+		SELECT LOWER(TRIM(COALESCE(NULLIF(TRIM(t.region), ''), s.region)))
 		FROM targets t
 		LEFT JOIN subnets s ON t.subnet_id = s.id
 		WHERE t.id = $1
@@ -1411,7 +1540,7 @@ func (s *Store) GetTargetHistoryByAgent(ctx context.Context, targetID string, wi
 		`
 	}
 
-	rows, err := s.pool.Query(ctx, query, targetID, cutoffTime, bucketInterval)
+	rows, err := s.reader().Query(ctx, query, targetID, cutoffTime, bucketInterval)
 	if err != nil {
 		return nil, err
 	}
@@ -1449,38 +1578,58 @@ type LiveProbeResult struct {
 	LatencyMs     *float64  `json:"latency_ms"`
 	PacketLossPct float64   `json:"packet_loss_pct"`
 	Success       bool      `json:"success"`
-}
-
-// GetTargetLiveResults returns the most recent raw probe results for live monitoring.
-func (s *Store) GetTargetLiveResults(ctx context.Context, targetID string, seconds int) ([]LiveProbeResult, error) {
-	if seconds <= 0 {
-		seconds = 60
-	}
-	if seconds > 300 {
-		seconds = 300 // Cap at 5 minutes
-	}
 
-	cutoffTime := time.Now().Add(-time.Duration(seconds) * time.Second)
+	// ZScore is the latency's deviation from the agent's baseline median
+	// for the target, in baseline standard deviations; nil without a
+	// usable baseline or latency. Anomaly is set by the service.
+	BaselineP50Ms *float64 `json:"baseline_p50_ms,omitempty"`
+	ZScore        *float64 `json:"z_score"`
+	Anomaly       bool     `json:"anomaly"`
+}
+
+// GetTargetLiveResults returns up to limit recent raw probe results for
+// live monitoring, newest first. Rows are sampled round-robin across agents
+// (every agent's newest result, then every agent's second newest, and so
+// on) so that under high fanout the live view covers every vantage instead
+// of whichever agents reported last. perAgent, when positive, also caps the
+// results kept per agent.
+func (s *Store) GetTargetLiveResults(ctx context.Context, targetID string, window time.Duration, perAgent, limit int) ([]LiveProbeResult, error) {
+	cutoffTime := time.Now().Add(-window)
 
 	rows, err := s.pool.Query(ctx, `
+		WITH ranked AS (
+			SELECT
+				pr.time, pr.agent_id, pr.is_in_market, pr.latency_ms, pr.packet_loss_pct, pr.success,
+				ROW_NUMBER() OVER (PARTITION BY pr.agent_id ORDER BY pr.time DESC) AS agent_rank
+			FROM probe_results pr
+			WHERE pr.target_id = $1 AND pr.time > $2
+		), sampled AS (
+			SELECT * FROM ranked
+			WHERE $3 <= 0 OR agent_rank <= $3
+			ORDER BY agent_rank, time DESC
+			LIMIT $4
+		)
 		SELECT
-			pr.time,
-			pr.agent_id,
-			COALESCE(a.name, pr.agent_id::text) as agent_name,
+			sp.time,
+			sp.agent_id,
+			COALESCE(a.name, sp.agent_id::text) as agent_name,
 			COALESCE(a.region, '') as agent_region,
 			COALESCE(a.provider, '') as agent_provider,
-			COALESCE(pr.is_in_market, false) as is_in_market,
-			pr.latency_ms,
-			pr.packet_loss_pct,
-			pr.success
-		FROM probe_results pr
-		LEFT JOIN agents a ON a.id = pr.agent_id
-		WHERE pr.target_id = $1 AND pr.time > $2
-		ORDER BY pr.time DESC
-		LIMIT 500
-	`, targetID, cutoffTime)
-	if err != nil {
-		return nil, err
+			COALESCE(sp.is_in_market, false) as is_in_market,
+			sp.latency_ms,
+			sp.packet_loss_pct,
+			sp.success,
+			b.latency_p50,
+			CASE WHEN sp.success AND b.latency_stddev > 0
+				THEN (sp.latency_ms - b.latency_p50) / b.latency_stddev
+			END as z_score
+		FROM sampled sp
+		LEFT JOIN agents a ON a.id = sp.agent_id
+		LEFT JOIN agent_target_baseline b ON b.agent_id = sp.agent_id AND b.target_id = $1
+		ORDER BY sp.time DESC
+	`, targetID, cutoffTime, perAgent, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying live results: %w", err)
 	}
 	defer rows.Close()
 
@@ -1491,6 +1640,7 @@ func (s *Store) GetTargetLiveResults(ctx context.Context, targetID string, secon
 			&r.Time,
 			&r.AgentID, &r.AgentName, &r.AgentRegion, &r.AgentProvider, &r.IsInMarket,
 			&r.LatencyMs, &r.PacketLossPct, &r.Success,
+			&r.BaselineP50Ms, &r.ZScore,
 		); err != nil {
 			return nil, err
 		}
@@ -1503,7 +1653,7 @@ func (s *Store) GetTargetLiveResults(ctx context.Context, targetID string, secon
 func (s *Store) GetLatencyTrend(ctx context.Context, window time.Duration, bucketSize time.Duration) ([]ProbeHistoryPoint, error) {
 	cutoffTime := time.Now().Add(-window)
 	bucketInterval := fmt.Sprintf("%d seconds", int(bucketSize.Seconds()))
-	rows, err := s.pool.Query(ctx, `
+	rows, err := s.reader().Query(ctx, `
 		SELECT
 			time_bucket($2::interval, time) as bucket,
 			AVG(latency_ms) FILTER (WHERE success) as avg_latency_ms,
@@ -1588,7 +1738,7 @@ func (s *Store) GetTargetLatencyBreakdown(ctx context.Context, targetID string,
 	var breakdown LatencyBreakdown
 
 	// First, get the target's subnet region
-	err := s.pool.QueryRow(ctx, `
+	err := s.reader().QueryRow(ctx, `
 		SELECT s.region
 		FROM targets t
 		LEFT JOIN subnets s ON t.subnet_id = s.id
@@ -1599,7 +1749,7 @@ func (s *Store) GetTargetLatencyBreakdown(ctx context.Context, targetID string,
 	}
 
 	// Query in-market latency stats
-	err = s.pool.QueryRow(ctx, `
+	err = s.reader().QueryRow(ctx, `
 		SELECT
 			AVG(latency_ms) FILTER (WHERE success) as avg_latency,
 			percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms) FILTER (WHERE success) as p95_latency,
@@ -1628,11 +1778,12 @@ func (s *Store) GetTargetLatencyBreakdown(ctx context.Context, targetID string,
 
 // GetInMarketLatencyTrend returns in-market latency trend for the dashboard.
 // Gateway IPs have NULL is_in_market (set at insert time), so they're automatically excluded.
+// Agents excluded from a target's health are left out too.
 func (s *Store) GetInMarketLatencyTrend(ctx context.Context, window time.Duration, bucketSize time.Duration) ([]ProbeHistoryPoint, error) {
 	cutoffTime := time.Now().Add(-window)
 	bucketInterval := fmt.Sprintf("%d seconds", int(bucketSize.Seconds()))
 
-	rows, err := s.pool.Query(ctx, `
+	rows, err := s.reader().Query(ctx, `
 		SELECT
 			time_bucket($2::interval, time) as bucket,
 			AVG(latency_ms) FILTER (WHERE success) as avg_latency_ms,
@@ -1645,6 +1796,7 @@ func (s *Store) GetInMarketLatencyTrend(ctx context.Context, window time.Duratio
 		FROM probe_results
 		WHERE time > $1
 		  AND is_in_market = true
+		  AND NOT agent_health_excluded(agent_id, target_id)
 		GROUP BY bucket
 		ORDER BY bucket ASC
 	`, cutoffTime, bucketInterval)
@@ -1679,7 +1831,7 @@ func (s *Store) GetRegionLatencyMatrix(ctx context.Context, window time.Duration
 	// Query matrix data directly from probe_results for real-time data
 	// The continuous aggregate only refreshes hourly, so we query raw data
 	// Gateway IPs have NULL regions (set at insert time), so they're automatically excluded
-	rows, err := s.pool.Query(ctx, `
+	rows, err := s.reader().Query(ctx, `
 		SELECT
 			agent_region,
 			target_region,
@@ -1760,7 +1912,7 @@ func (s *Store) GetTargetHistoryInMarket(ctx context.Context, targetID string, w
 	cutoffTime := time.Now().Add(-window)
 	bucketInterval := fmt.Sprintf("%d seconds", int(bucketSize.Seconds()))
 
-	rows, err := s.pool.Query(ctx, `
+	rows, err := s.reader().Query(ctx, `
 		SELECT
 			time_bucket($3::interval, time) as bucket,
 			AVG(latency_ms) FILTER (WHERE success) as avg_latency_ms,
@@ -2117,21 +2269,33 @@ func (s *Store) RecalculateAllBaselines(ctx context.Context) (int, error) {
 	return count, err
 }
 
-// UpsertBaseline inserts or updates the agent_target_baseline row.
+// UpsertBaseline inserts or updates the agent_target_baseline row and
+// snapshots it to baseline_history.
 func (s *Store) UpsertBaseline(ctx context.Context, baseline *AgentTargetBaseline) error {
 	_, err := s.pool.Exec(ctx, `
-		INSERT INTO agent_target_baseline (
-			agent_id, target_id, latency_p50, latency_p95, latency_p99, latency_stddev,
-			packet_loss_baseline, sample_count, first_seen, last_updated
-		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
-		ON CONFLICT (agent_id, target_id) DO UPDATE SET
-			latency_p50 = EXCLUDED.latency_p50,
-			latency_p95 = EXCLUDED.latency_p95,
-			latency_p99 = EXCLUDED.latency_p99,
-			latency_stddev = EXCLUDED.latency_stddev,
-			packet_loss_baseline = EXCLUDED.packet_loss_baseline,
-			sample_count = EXCLUDED.sample_count,
-			last_updated = EXCLUDED.last_updated
+		WITH upserted AS (
+			INSERT INTO agent_target_baseline (
+				agent_id, target_id, latency_p50, latency_p95, latency_p99, latency_stddev,
+				packet_loss_baseline, sample_count, first_seen, last_updated
+			) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			ON CONFLICT (agent_id, target_id) DO UPDATE SET
+				latency_p50 = EXCLUDED.latency_p50,
+				latency_p95 = EXCLUDED.latency_p95,
+				latency_p99 = EXCLUDED.latency_p99,
+				latency_stddev = EXCLUDED.latency_stddev,
+				packet_loss_baseline = EXCLUDED.packet_loss_baseline,
+				sample_count = EXCLUDED.sample_count,
+				last_updated = EXCLUDED.last_updated
+			RETURNING *
+		)
+		INSERT INTO baseline_history (
+			agent_id, target_id, snapshot_at, latency_p50, latency_p95, latency_p99,
+			latency_stddev, packet_loss_baseline, sample_count
+		)
+		SELECT agent_id, target_id, last_updated, latency_p50, latency_p95, latency_p99,
+			latency_stddev, packet_loss_baseline, sample_count
+		FROM upserted
+		ON CONFLICT DO NOTHING
 	`, baseline.AgentID, baseline.TargetID, baseline.LatencyP50, baseline.LatencyP95,
 		baseline.LatencyP99, baseline.LatencyStddev, baseline.PacketLossBaseline,
 		baseline.SampleCount, baseline.FirstSeen, baseline.LastUpdated)
@@ -2146,6 +2310,15 @@ func (s *Store) UpsertBaseline(ctx context.Context, baseline *AgentTargetBaselin
 type AgentTargetPair struct {
 	AgentID  string
 	TargetID string
+
+	// ExpectedOutcome is the target's expected outcome, or its tier's
+	// default. Only set by GetActiveAgentTargetPairs.
+	ExpectedOutcome *types.ExpectedOutcome
+
+	// WarmupEndsAt is when the pair's baseline warmup ends (see
+	// baseline_warmup_end); results before it stay out of baselines. Only
+	// set by GetActiveAgentTargetPairs.
+	WarmupEndsAt *time.Time
 }
 
 // ProbeStats represents aggregated probe statistics for evaluation.
@@ -2164,16 +2337,25 @@ type ProbeStats struct {
 }
 
 // GetActiveAgentTargetPairs returns all (agent_id, target_id) pairs with recent probe results.
-// Excludes archived agents and targets from operational queries.
+// Excludes archived agents and targets from operational queries, and pairs
+// excluded from health computation.
 func (s *Store) GetActiveAgentTargetPairs(ctx context.Context, since time.Duration) ([]AgentTargetPair, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT DISTINCT pr.agent_id, pr.target_id
-		FROM probe_results pr
-		JOIN agents a ON pr.agent_id = a.id
-		JOIN targets t ON pr.target_id = t.id
-		WHERE pr.time > NOW() - $1::interval
-		  AND a.archived_at IS NULL
+		WITH active AS (
+			SELECT DISTINCT pr.agent_id, pr.target_id
+			FROM probe_results pr
+			WHERE pr.time > NOW() - $1::interval
+		)
+		SELECT p.agent_id, p.target_id,
+		       COALESCE(NULLIF(t.expected_outcome, 'null'::jsonb), tr.default_expected_outcome),
+		       baseline_warmup_end(a.started_at, t.created_at, tr.probe_interval_ms)
+		FROM active p
+		JOIN agents a ON p.agent_id = a.id
+		JOIN targets t ON p.target_id = t.id
+		LEFT JOIN tiers tr ON tr.name = t.tier
+		WHERE a.archived_at IS NULL
 		  AND t.archived_at IS NULL
+		  AND NOT agent_health_excluded(p.agent_id, p.target_id)
 	`, since.String())
 	if err != nil {
 		return nil, err
@@ -2183,9 +2365,13 @@ func (s *Store) GetActiveAgentTargetPairs(ctx context.Context, since time.Durati
 	var pairs []AgentTargetPair
 	for rows.Next() {
 		var p AgentTargetPair
-		if err := rows.Scan(&p.AgentID, &p.TargetID); err != nil {
+		var outcomeJSON []byte
+		if err := rows.Scan(&p.AgentID, &p.TargetID, &outcomeJSON, &p.WarmupEndsAt); err != nil {
 			return nil, err
 		}
+		if outcomeJSON != nil {
+			json.Unmarshal(outcomeJSON, &p.ExpectedOutcome)
+		}
 		pairs = append(pairs, p)
 	}
 	return pairs, rows.Err()
@@ -2419,34 +2605,14 @@ type PairKey struct {
 const maxPairsPerBatch = 30000
 
 // BulkGetRecentProbeStats retrieves probe stats for multiple agent-target pairs.
-// Automatically batches queries to avoid PostgreSQL's 65535 parameter limit.
+// Automatically batches queries to avoid PostgreSQL's 65535 parameter limit,
+// running batches concurrently (see SetBulkConcurrency).
 // Returns a map keyed by (agent_id, target_id) pair.
 func (s *Store) BulkGetRecentProbeStats(ctx context.Context, pairs []AgentTargetPair, window time.Duration) (map[PairKey]*ProbeStats, error) {
-	if len(pairs) == 0 {
-		return make(map[PairKey]*ProbeStats), nil
-	}
-
-	result := make(map[PairKey]*ProbeStats)
-
-	// Process in batches to avoid parameter limit
-	for i := 0; i < len(pairs); i += maxPairsPerBatch {
-		end := i + maxPairsPerBatch
-		if end > len(pairs) {
-			end = len(pairs)
-		}
-		batch := pairs[i:end]
-
-		batchResult, err := s.bulkGetRecentProbeStatsBatch(ctx, batch, window)
-		if err != nil {
-			return nil, err
-		}
-
-		for k, v := range batchResult {
-			result[k] = v
-		}
-	}
-
-	return result, nil
+	return runPairBatches(ctx, pairs, maxPairsPerBatch, s.bulkLimit(),
+		func(ctx context.Context, batch []AgentTargetPair) (map[PairKey]*ProbeStats, error) {
+			return s.bulkGetRecentProbeStatsBatch(ctx, batch, window)
+		})
 }
 
 // bulkGetRecentProbeStatsBatch retrieves probe stats for a single batch of pairs.
@@ -2551,31 +2717,7 @@ func (s *Store) bulkGetRecentProbeStatsBatch(ctx context.Context, pairs []AgentT
 // BulkGetBaselines retrieves baselines for multiple agent-target pairs.
 // Automatically batches queries to avoid PostgreSQL's 65535 parameter limit.
 func (s *Store) BulkGetBaselines(ctx context.Context, pairs []AgentTargetPair) (map[PairKey]*AgentTargetBaseline, error) {
-	if len(pairs) == 0 {
-		return make(map[PairKey]*AgentTargetBaseline), nil
-	}
-
-	result := make(map[PairKey]*AgentTargetBaseline)
-
-	// Process in batches to avoid parameter limit
-	for i := 0; i < len(pairs); i += maxPairsPerBatch {
-		end := i + maxPairsPerBatch
-		if end > len(pairs) {
-			end = len(pairs)
-		}
-		batch := pairs[i:end]
-
-		batchResult, err := s.bulkGetBaselinesBatch(ctx, batch)
-		if err != nil {
-			return nil, err
-		}
-
-		for k, v := range batchResult {
-			result[k] = v
-		}
-	}
-
-	return result, nil
+	return runPairBatches(ctx, pairs, maxPairsPerBatch, s.bulkLimit(), s.bulkGetBaselinesBatch)
 }
 
 // bulkGetBaselinesBatch retrieves baselines for a single batch of pairs.
@@ -2620,31 +2762,7 @@ func (s *Store) bulkGetBaselinesBatch(ctx context.Context, pairs []AgentTargetPa
 // BulkGetAgentTargetStates retrieves states for multiple agent-target pairs.
 // Automatically batches queries to avoid PostgreSQL's 65535 parameter limit.
 func (s *Store) BulkGetAgentTargetStates(ctx context.Context, pairs []AgentTargetPair) (map[PairKey]*AgentTargetState, error) {
-	if len(pairs) == 0 {
-		return make(map[PairKey]*AgentTargetState), nil
-	}
-
-	result := make(map[PairKey]*AgentTargetState)
-
-	// Process in batches to avoid parameter limit
-	for i := 0; i < len(pairs); i += maxPairsPerBatch {
-		end := i + maxPairsPerBatch
-		if end > len(pairs) {
-			end = len(pairs)
-		}
-		batch := pairs[i:end]
-
-		batchResult, err := s.bulkGetAgentTargetStatesBatch(ctx, batch)
-		if err != nil {
-			return nil, err
-		}
-
-		for k, v := range batchResult {
-			result[k] = v
-		}
-	}
-
-	return result, nil
+	return runPairBatches(ctx, pairs, maxPairsPerBatch, s.bulkLimit(), s.bulkGetAgentTargetStatesBatch)
 }
 
 // bulkGetAgentTargetStatesBatch retrieves states for a single batch of pairs.
@@ -2696,6 +2814,7 @@ func (s *Store) GetAnomalousStates(ctx context.Context) ([]AgentTargetState, err
 		       last_probe_time, last_evaluated
 		FROM agent_target_state
 		WHERE anomaly_start IS NOT NULL
+		  AND NOT agent_health_excluded(agent_id, target_id)
 		ORDER BY anomaly_start ASC
 	`)
 	if err != nil {
@@ -2800,7 +2919,13 @@ type Incident struct {
 
 // CreateIncident creates a new incident.
 func (s *Store) CreateIncident(ctx context.Context, incident *Incident) error {
-	_, err := s.pool.Exec(ctx, `
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
 		INSERT INTO incidents (id, incident_type, severity, primary_entity_type, primary_entity_id,
 		       affected_target_ids, affected_agent_ids, detected_at, confirmed_at, status,
 		       baseline_snapshot, created_at, updated_at)
@@ -2808,7 +2933,25 @@ func (s *Store) CreateIncident(ctx context.Context, incident *Incident) error {
 	`, incident.ID, incident.IncidentType, incident.Severity, incident.PrimaryEntityType,
 		incident.PrimaryEntityID, incident.AffectedTargetIDs, incident.AffectedAgentIDs,
 		incident.DetectedAt, incident.ConfirmedAt, incident.Status, incident.BaselineSnapshot)
-	return err
+	if err != nil {
+		return err
+	}
+
+	err = recordEvent(ctx, tx, types.EventIncidentCreated, incident.ID, map[string]any{
+		"incident_id":         incident.ID,
+		"incident_type":       incident.IncidentType,
+		"severity":            incident.Severity,
+		"primary_entity_type": incident.PrimaryEntityType,
+		"primary_entity_id":   incident.PrimaryEntityID,
+		"affected_target_ids": incident.AffectedTargetIDs,
+		"affected_agent_ids":  incident.AffectedAgentIDs,
+		"status":              incident.Status,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 // GetIncident retrieves an incident by ID.
@@ -2934,11 +3077,37 @@ func (s *Store) AcknowledgeIncident(ctx context.Context, id string, acknowledged
 
 // ResolveIncident marks an incident as resolved.
 func (s *Store) ResolveIncident(ctx context.Context, id string) error {
-	_, err := s.pool.Exec(ctx, `
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	var incidentType, severity string
+	var resolvedAt time.Time
+	err = tx.QueryRow(ctx, `
 		UPDATE incidents SET status = 'resolved', resolved_at = NOW(), updated_at = NOW()
 		WHERE id = $1 AND status != 'resolved'
-	`, id)
-	return err
+		RETURNING incident_type, severity, resolved_at
+	`, id).Scan(&incidentType, &severity, &resolvedAt)
+	if err == pgx.ErrNoRows {
+		return nil // already resolved or unknown
+	}
+	if err != nil {
+		return err
+	}
+
+	err = recordEvent(ctx, tx, types.EventIncidentResolved, id, map[string]any{
+		"incident_id":   id,
+		"incident_type": incidentType,
+		"severity":      severity,
+		"resolved_at":   resolvedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
 }
 
 // UpdateIncidentPeaks updates the peak metrics for an incident.
@@ -2983,8 +3152,14 @@ type TargetReport struct {
 }
 
 // GetTargetReport retrieves a report for a target over a time window.
-func (s *Store) GetTargetReport(ctx context.Context, targetID string, windowDays int) ([]TargetReport, error) {
-	rows, err := s.pool.Query(ctx, `SELECT * FROM get_target_report($1, $2)`, targetID, windowDays)
+// An empty tz uses rolling UTC days; otherwise the window starts at local
+// midnight in that IANA time zone (see getTargetReportInZone).
+func (s *Store) GetTargetReport(ctx context.Context, targetID string, windowDays int, tz string) ([]TargetReport, error) {
+	if tz != "" {
+		return s.getTargetReportInZone(ctx, targetID, windowDays, tz)
+	}
+
+	rows, err := s.reader().Query(ctx, `SELECT * FROM get_target_report($1, $2)`, targetID, windowDays)
 	if err != nil {
 		return nil, err
 	}
@@ -3037,7 +3212,7 @@ func (s *Store) QueryMetrics(ctx context.Context, query *types.MetricsQuery) (*t
 	// Apply defaults
 	metrics := query.Metrics
 	if len(metrics) == 0 {
-		metrics = []string{"avg_latency", "packet_loss"}
+		metrics = types.DefaultQueryMetrics
 	}
 
 	groupBy := query.GroupBy
@@ -3047,7 +3222,7 @@ func (s *Store) QueryMetrics(ctx context.Context, query *types.MetricsQuery) (*t
 
 	limit := query.Limit
 	if limit <= 0 {
-		limit = 10000
+		limit = types.DefaultQueryLimit
 	}
 
 	// Build the query with CTEs for efficient filtering
@@ -3057,7 +3232,7 @@ func (s *Store) QueryMetrics(ctx context.Context, query *types.MetricsQuery) (*t
 	}
 
 	// Execute query
-	rows, err := s.pool.Query(ctx, sql, args...)
+	rows, err := s.reader().Query(ctx, sql, args...)
 	if err != nil {
 		return nil, fmt.Errorf("executing query: %w", err)
 	}
@@ -3084,6 +3259,8 @@ func (s *Store) QueryMetrics(ctx context.Context, query *types.MetricsQuery) (*t
 				TargetIP:      groupKey.TargetIP,
 				TargetTier:    groupKey.TargetTier,
 				TargetRegion:  groupKey.TargetRegion,
+				TargetASN:     groupKey.TargetASN,
+				TargetASName:  groupKey.TargetASName,
 				Points:        make([]types.MetricsDataPoint, 0),
 			}
 			seriesMap[groupKey.Key] = series
@@ -3149,6 +3326,14 @@ func (s *Store) buildMetricsQuery(
 	// Limit argument
 	limitArg := fmt.Sprintf("$%d", argIdx)
 	args = append(args, limit)
+	argIdx++
+
+	// An absolute range also ends somewhere; a window runs to now
+	var endArg string
+	if query.TimeRange.Window == "" && query.TimeRange.End != nil {
+		endArg = fmt.Sprintf("$%d", argIdx)
+		args = append(args, *query.TimeRange.End)
+	}
 
 	// Build SELECT clause based on requested metrics
 	selectCols := buildMetricsSelectClause(metrics, aggTable)
@@ -3165,6 +3350,11 @@ func (s *Store) buildMetricsQuery(
 		timeCol = "pr.bucket"
 	}
 
+	timeCond := fmt.Sprintf("%s > %s", timeCol, cutoffArg)
+	if endArg != "" {
+		timeCond += fmt.Sprintf(" AND %s <= %s", timeCol, endArg)
+	}
+
 	sql := fmt.Sprintf(`
 		WITH filtered_agents AS (
 			%s
@@ -3181,12 +3371,12 @@ func (s *Store) buildMetricsQuery(
 		JOIN filtered_targets ft ON pr.target_id = ft.id
 		LEFT JOIN agents a ON a.id = pr.agent_id
 		LEFT JOIN targets t ON t.id = pr.target_id
-		WHERE %s > %s
+		WHERE %s
 		GROUP BY time_bucket(%s::interval, %s)%s
 		ORDER BY bucket ASC
 		LIMIT %s
 	`, agentCTE, targetCTE, bucketArg, timeCol, buildGroupBySelectClause(groupBy), selectCols,
-		aggTable, timeCol, cutoffArg, bucketArg, timeCol, groupByCols, limitArg)
+		aggTable, timeCond, bucketArg, timeCol, groupByCols, limitArg)
 
 	return sql, args, nil
 }
@@ -3259,8 +3449,19 @@ func buildAgentFilterCTE(filter *types.AgentFilter, startIdx int) (string, []any
 
 // buildTargetFilterCTE creates the CTE for filtering targets.
 func buildTargetFilterCTE(filter *types.TargetFilter, startIdx int) (string, []any, int) {
+	sql, args, idx := buildTargetFilterSelect(filter, startIdx)
+	if sql == "" {
+		return "SELECT id FROM targets LIMIT 10000", args, idx
+	}
+	return sql + " LIMIT 10000", args, idx
+}
+
+// buildTargetFilterSelect creates an uncapped SELECT of the IDs of targets
+// matching filter. It returns an empty query when the filter has no
+// conditions, leaving callers to decide what an empty filter means.
+func buildTargetFilterSelect(filter *types.TargetFilter, startIdx int) (string, []any, int) {
 	if filter == nil {
-		return "SELECT id FROM targets LIMIT 10000", nil, startIdx
+		return "", nil, startIdx
 	}
 
 	conditions := []string{}
@@ -3290,6 +3491,13 @@ func buildTargetFilterCTE(filter *types.TargetFilter, startIdx int) (string, []a
 		needsSubnetJoin = true
 	}
 
+	// Filter by origin ASN (set by ASN enrichment)
+	if len(filter.ASNs) > 0 {
+		conditions = append(conditions, fmt.Sprintf("t.asn = ANY($%d)", idx))
+		args = append(args, filter.ASNs)
+		idx++
+	}
+
 	// Filter by tags (all must match) - legacy simple format
 	if len(filter.Tags) > 0 {
 		tagsJSON, _ := json.Marshal(filter.Tags)
@@ -3318,15 +3526,15 @@ func buildTargetFilterCTE(filter *types.TargetFilter, startIdx int) (string, []a
 	}
 
 	if len(conditions) == 0 {
-		return "SELECT id FROM targets LIMIT 10000", args, idx
+		return "", args, idx
 	}
 
 	var sql string
 	if needsSubnetJoin {
-		sql = fmt.Sprintf("SELECT t.id FROM targets t LEFT JOIN subnets s ON t.subnet_id = s.id WHERE %s LIMIT 10000",
+		sql = fmt.Sprintf("SELECT t.id FROM targets t LEFT JOIN subnets s ON t.subnet_id = s.id WHERE %s",
 			joinConditions(conditions, " AND "))
 	} else {
-		sql = fmt.Sprintf("SELECT t.id FROM targets t WHERE %s LIMIT 10000",
+		sql = fmt.Sprintf("SELECT t.id FROM targets t WHERE %s",
 			joinConditions(conditions, " AND "))
 	}
 	return sql, args, idx
@@ -3643,6 +3851,8 @@ func buildGroupByClause(groupBy []string) string {
 			cols = append(cols, "t.tier")
 		case "target_region":
 			cols = append(cols, "pr.target_region")
+		case "target_asn":
+			cols = append(cols, "t.asn", "t.as_name")
 		// "time" is always included via bucket
 		}
 	}
@@ -3669,6 +3879,8 @@ func buildGroupBySelectClause(groupBy []string) string {
 			cols = append(cols, "COALESCE(t.tier, '') as target_tier")
 		case "target_region":
 			cols = append(cols, "COALESCE(pr.target_region, '') as target_region")
+		case "target_asn":
+			cols = append(cols, "COALESCE(t.asn, 0) as target_asn", "COALESCE(t.as_name, '') as target_as_name")
 		}
 	}
 	if len(cols) == 0 {
@@ -3688,6 +3900,8 @@ type metricsGroupKey struct {
 	TargetIP      string
 	TargetTier    string
 	TargetRegion  string
+	TargetASN     int
+	TargetASName  string
 }
 
 // scanMetricsRow scans a result row into a data point and group key.
@@ -3713,6 +3927,8 @@ func (s *Store) scanMetricsRow(rows pgx.Rows, metrics []string, groupBy []string
 			dests = append(dests, &key.TargetTier)
 		case "target_region":
 			dests = append(dests, &key.TargetRegion)
+		case "target_asn":
+			dests = append(dests, &key.TargetASN, &key.TargetASName)
 		}
 	}
 
@@ -3747,9 +3963,9 @@ func (s *Store) scanMetricsRow(rows pgx.Rows, metrics []string, groupBy []string
 	}
 
 	// Build composite key for grouping
-	key.Key = fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s",
+	key.Key = fmt.Sprintf("%s|%s|%s|%s|%s|%s|%s|%d",
 		key.AgentID, key.AgentRegion, key.AgentProvider,
-		key.TargetID, key.TargetIP, key.TargetTier, key.TargetRegion)
+		key.TargetID, key.TargetIP, key.TargetTier, key.TargetRegion, key.TargetASN)
 
 	return point, key, nil
 }
@@ -0,0 +1,97 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// PROBE ACCOUNTING
+// =============================================================================
+
+// ProbeAccountingRow is one target's or agent's probe counts in one period.
+// Label fields are empty for targets or agents deleted since.
+type ProbeAccountingRow struct {
+	ID    string
+	Name  string // target IP or agent name
+	Label string // target display name or agent region
+	Group string // target tier or agent provider
+	Usage types.ProbeUsage
+}
+
+// GetTargetProbeAccounting returns probe counts per target and period from
+// probe_accounting between start and end, ordered by target and period.
+// targetID, if set, limits it to one target.
+func (s *Store) GetTargetProbeAccounting(ctx context.Context, period types.AccountingPeriod, start, end time.Time, targetID string) ([]ProbeAccountingRow, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			pa.target_id::text, COALESCE(host(t.ip_address), ''),
+			COALESCE(t.display_name, ''), COALESCE(t.tier, ''),
+			time_bucket($1::interval, pa.bucket) AS period_start,
+			SUM(pa.probe_count)::bigint, SUM(pa.success_count)::bigint,
+			COUNT(DISTINCT pa.agent_id)
+		FROM probe_accounting pa
+		LEFT JOIN targets t ON t.id = pa.target_id
+		WHERE pa.bucket >= $2 AND pa.bucket < $3
+		  AND ($4::text = '' OR pa.target_id = NULLIF($4::text, '')::uuid)
+		GROUP BY pa.target_id, t.ip_address, t.display_name, t.tier, period_start
+		ORDER BY pa.target_id, period_start
+	`, period.Interval(), start, end, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("querying target probe accounting: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ProbeAccountingRow
+	for rows.Next() {
+		var r ProbeAccountingRow
+		if err := rows.Scan(
+			&r.ID, &r.Name, &r.Label, &r.Group,
+			&r.Usage.PeriodStart, &r.Usage.Probes, &r.Usage.Successes, &r.Usage.Agents,
+		); err != nil {
+			return nil, fmt.Errorf("scanning target probe accounting: %w", err)
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// GetAgentProbeAccounting returns probe counts per agent and period from
+// probe_accounting between start and end, ordered by agent and period.
+// agentID, if set, limits it to one agent.
+func (s *Store) GetAgentProbeAccounting(ctx context.Context, period types.AccountingPeriod, start, end time.Time, agentID string) ([]ProbeAccountingRow, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			pa.agent_id::text, COALESCE(ag.name, ''),
+			COALESCE(ag.region, ''), COALESCE(ag.provider, ''),
+			time_bucket($1::interval, pa.bucket) AS period_start,
+			SUM(pa.probe_count)::bigint, SUM(pa.success_count)::bigint,
+			COUNT(DISTINCT pa.target_id)
+		FROM probe_accounting pa
+		LEFT JOIN agents ag ON ag.id = pa.agent_id
+		WHERE pa.bucket >= $2 AND pa.bucket < $3
+		  AND ($4::text = '' OR pa.agent_id = NULLIF($4::text, '')::uuid)
+		GROUP BY pa.agent_id, ag.name, ag.region, ag.provider, period_start
+		ORDER BY pa.agent_id, period_start
+	`, period.Interval(), start, end, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("querying agent probe accounting: %w", err)
+	}
+	defer rows.Close()
+
+	var result []ProbeAccountingRow
+	for rows.Next() {
+		var r ProbeAccountingRow
+		if err := rows.Scan(
+			&r.ID, &r.Name, &r.Label, &r.Group,
+			&r.Usage.PeriodStart, &r.Usage.Probes, &r.Usage.Successes, &r.Usage.Targets,
+		); err != nil {
+			return nil, fmt.Errorf("scanning agent probe accounting: %w", err)
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
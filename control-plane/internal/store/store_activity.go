@@ -136,6 +136,11 @@ func (s *Store) ListActivity(ctx context.Context, filter ActivityFilter) ([]type
 		args = append(args, filter.Category)
 		argNum++
 	}
+	if filter.EventType != "" {
+		where += fmt.Sprintf(" AND event_type = $%d", argNum)
+		args = append(args, filter.EventType)
+		argNum++
+	}
 	if filter.Severity != "" {
 		where += fmt.Sprintf(" AND severity = $%d", argNum)
 		args = append(args, filter.Severity)
@@ -217,14 +222,15 @@ func (s *Store) ListActivity(ctx context.Context, filter ActivityFilter) ([]type
 
 // ActivityFilter for querying activity logs.
 type ActivityFilter struct {
-	TargetID string
-	SubnetID string
-	AgentID  string
-	IP       string
-	Category string
-	Severity string
-	Since    time.Time
-	Limit    int
+	TargetID  string
+	SubnetID  string
+	AgentID   string
+	IP        string
+	Category  string
+	EventType string
+	Severity  string
+	Since     time.Time
+	Limit     int
 }
 
 // GetRecentActivityForTarget returns recent activity for a specific target.
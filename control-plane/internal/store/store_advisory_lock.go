@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// =============================================================================
+// ADVISORY LOCKS
+// =============================================================================
+
+// LockSession holds session-level advisory locks on a dedicated connection.
+// A session lock lives as long as the connection that took it, so the
+// connection is kept out of the pool, where any query could otherwise end
+// up running on it or closing it. One session can hold many keys; if the
+// connection drops, Postgres releases all of them.
+type LockSession struct {
+	config *pgx.ConnConfig
+
+	mu   sync.Mutex
+	conn *pgx.Conn
+}
+
+// NewLockSession returns a lock session on the primary database. It
+// connects on first use.
+func (s *Store) NewLockSession() *LockSession {
+	return &LockSession{config: s.pool.Config().ConnConfig.Copy()}
+}
+
+// TryLock takes the advisory lock key without waiting, reconnecting first if
+// the session's connection was lost. It reports false if another session
+// holds the key.
+func (l *LockSession) TryLock(ctx context.Context, key int64) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil || l.conn.IsClosed() {
+		conn, err := pgx.ConnectConfig(ctx, l.config)
+		if err != nil {
+			return false, fmt.Errorf("connecting lock session: %w", err)
+		}
+		l.conn = conn
+	}
+
+	var locked bool
+	if err := l.conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1)`, key).Scan(&locked); err != nil {
+		l.drop()
+		return false, fmt.Errorf("taking advisory lock: %w", err)
+	}
+	return locked, nil
+}
+
+// Held reports whether this session still holds key. A session that has
+// reconnected since taking the key no longer holds it.
+func (l *LockSession) Held(ctx context.Context, key int64) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil || l.conn.IsClosed() {
+		return false, nil
+	}
+
+	// A bigint key is stored split across classid (high 32 bits) and objid
+	// (low 32 bits), with objsubid 1.
+	var held bool
+	err := l.conn.QueryRow(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM pg_locks
+			WHERE locktype = 'advisory' AND granted AND objsubid = 1
+			  AND pid = pg_backend_pid()
+			  AND (classid::bigint << 32) | objid::bigint = $1
+		)
+	`, key).Scan(&held)
+	if err != nil {
+		l.drop()
+		return false, fmt.Errorf("checking advisory lock: %w", err)
+	}
+	return held, nil
+}
+
+// Unlock releases key if this session holds it.
+func (l *LockSession) Unlock(ctx context.Context, key int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil || l.conn.IsClosed() {
+		return nil
+	}
+	if _, err := l.conn.Exec(ctx, `SELECT pg_advisory_unlock($1)`, key); err != nil {
+		l.drop()
+		return fmt.Errorf("releasing advisory lock: %w", err)
+	}
+	return nil
+}
+
+// Close closes the session, releasing every lock it holds.
+func (l *LockSession) Close(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	err := l.conn.Close(ctx)
+	l.conn = nil
+	return err
+}
+
+// drop closes a connection whose state is unknown after a failed query, so
+// the server releases its locks rather than the session carrying on with
+// locks it can't account for. Callers hold mu.
+func (l *LockSession) drop() {
+	ctx, cancel := context.WithTimeout(context.Background(), config.SingletonLockTimeout)
+	defer cancel()
+	l.conn.Close(ctx)
+	l.conn = nil
+}
@@ -0,0 +1,151 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ASSIGNMENT AFFINITY RULES
+// =============================================================================
+
+const affinityRuleColumns = `
+	id, name, COALESCE(description, ''), enabled, priority, mode, fallback,
+	target_filters, agent_filters, created_at, updated_at`
+
+func scanAffinityRule(row pgx.Row) (*types.AffinityRule, error) {
+	var r types.AffinityRule
+	var targetJSON, agentJSON []byte
+	err := row.Scan(
+		&r.ID, &r.Name, &r.Description, &r.Enabled, &r.Priority, &r.Mode, &r.Fallback,
+		&targetJSON, &agentJSON, &r.CreatedAt, &r.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(targetJSON, &r.TargetFilters); err != nil {
+		return nil, fmt.Errorf("decoding target filters: %w", err)
+	}
+	if err := json.Unmarshal(agentJSON, &r.AgentFilters); err != nil {
+		return nil, fmt.Errorf("decoding agent filters: %w", err)
+	}
+	return &r, nil
+}
+
+// affinityRuleError turns a unique violation on name into a readable error.
+func affinityRuleError(action string, err error) error {
+	if IsUniqueViolation(err) {
+		return fmt.Errorf("affinity rule name %w", ErrConflict)
+	}
+	return fmt.Errorf("%s affinity rule: %w", action, err)
+}
+
+// CreateAffinityRule inserts a rule and populates its ID and timestamps.
+func (s *Store) CreateAffinityRule(ctx context.Context, r *types.AffinityRule) error {
+	targetJSON, err := json.Marshal(r.TargetFilters)
+	if err != nil {
+		return fmt.Errorf("encoding target filters: %w", err)
+	}
+	agentJSON, err := json.Marshal(r.AgentFilters)
+	if err != nil {
+		return fmt.Errorf("encoding agent filters: %w", err)
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		INSERT INTO affinity_rules (name, description, enabled, priority, mode, target_filters, agent_filters, fallback)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, COALESCE(NULLIF($8, ''), 'deny'))
+		RETURNING id, fallback, created_at, updated_at
+	`, r.Name, r.Description, r.Enabled, r.Priority, r.Mode, targetJSON, agentJSON, r.Fallback).Scan(&r.ID, &r.Fallback, &r.CreatedAt, &r.UpdatedAt)
+	if err != nil {
+		return affinityRuleError("inserting", err)
+	}
+	return nil
+}
+
+// GetAffinityRule returns a rule by ID, or nil if not found.
+func (s *Store) GetAffinityRule(ctx context.Context, id string) (*types.AffinityRule, error) {
+	r, err := scanAffinityRule(s.pool.QueryRow(ctx,
+		`SELECT `+affinityRuleColumns+` FROM affinity_rules WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting affinity rule: %w", err)
+	}
+	return r, nil
+}
+
+// ListAffinityRules returns rules in the order they are applied: priority,
+// then name. enabledOnly leaves out disabled rules.
+func (s *Store) ListAffinityRules(ctx context.Context, enabledOnly bool) ([]types.AffinityRule, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+affinityRuleColumns+`
+		FROM affinity_rules
+		WHERE enabled OR NOT $1
+		ORDER BY priority, name
+	`, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("listing affinity rules: %w", err)
+	}
+	defer rows.Close()
+
+	var rules []types.AffinityRule
+	for rows.Next() {
+		r, err := scanAffinityRule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning affinity rule: %w", err)
+		}
+		rules = append(rules, *r)
+	}
+	return rules, rows.Err()
+}
+
+// UpdateAffinityRule replaces a rule's settings and refreshes UpdatedAt.
+func (s *Store) UpdateAffinityRule(ctx context.Context, r *types.AffinityRule) error {
+	targetJSON, err := json.Marshal(r.TargetFilters)
+	if err != nil {
+		return fmt.Errorf("encoding target filters: %w", err)
+	}
+	agentJSON, err := json.Marshal(r.AgentFilters)
+	if err != nil {
+		return fmt.Errorf("encoding agent filters: %w", err)
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		UPDATE affinity_rules SET
+			name = $2,
+			description = NULLIF($3, ''),
+			enabled = $4,
+			priority = $5,
+			mode = $6,
+			target_filters = $7,
+			agent_filters = $8,
+			fallback = COALESCE(NULLIF($9, ''), 'deny'),
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING fallback, created_at, updated_at
+	`, r.ID, r.Name, r.Description, r.Enabled, r.Priority, r.Mode, targetJSON, agentJSON, r.Fallback).Scan(&r.Fallback, &r.CreatedAt, &r.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("affinity rule %w", ErrNotFound)
+	}
+	if err != nil {
+		return affinityRuleError("updating", err)
+	}
+	return nil
+}
+
+// DeleteAffinityRule removes a rule.
+func (s *Store) DeleteAffinityRule(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM affinity_rules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting affinity rule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("affinity rule %w", ErrNotFound)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// AGENT ANOMALY TRIAGE
+// =============================================================================
+
+// GetAgentAnomalySummaries returns every non-archived agent with its open
+// anomaly and alert counts, the targets they affect, and its assignment
+// count. Like GetAgentAnomalyCounts, but pairs an agent is excluded from
+// health for are skipped and active per-agent alerts are included.
+// Agents with nothing open are returned with zero counts.
+func (s *Store) GetAgentAnomalySummaries(ctx context.Context) ([]types.AgentAnomalySummary, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH anomalies AS (
+			SELECT agent_id, COUNT(*) AS anomaly_count, array_agg(target_id) AS targets
+			FROM agent_target_state
+			WHERE anomaly_start IS NOT NULL
+			  AND NOT agent_health_excluded(agent_id, target_id)
+			GROUP BY agent_id
+		), open_alerts AS (
+			SELECT agent_id, COUNT(*) AS alert_count,
+			       array_agg(target_id) FILTER (WHERE target_id IS NOT NULL) AS targets
+			FROM alerts
+			WHERE agent_id IS NOT NULL
+			  AND status IN ($1, $2)
+			GROUP BY agent_id
+		)
+		SELECT
+			ag.id::text, ag.name, NULLIF(TRIM(ag.region), ''),
+			COALESCE(an.anomaly_count, 0), COALESCE(al.alert_count, 0),
+			ARRAY(
+				SELECT DISTINCT t::text
+				FROM unnest(COALESCE(an.targets, '{}') || COALESCE(al.targets, '{}')) AS t
+				ORDER BY 1
+			),
+			(SELECT COUNT(*) FROM target_assignments ta WHERE ta.agent_id = ag.id)
+		FROM agents ag
+		LEFT JOIN anomalies an ON an.agent_id = ag.id
+		LEFT JOIN open_alerts al ON al.agent_id = ag.id
+		WHERE ag.archived_at IS NULL
+		ORDER BY ag.name
+	`, types.AlertStatusActive, types.AlertStatusAcknowledged)
+	if err != nil {
+		return nil, fmt.Errorf("querying agent anomaly summaries: %w", err)
+	}
+	defer rows.Close()
+
+	var summaries []types.AgentAnomalySummary
+	for rows.Next() {
+		var a types.AgentAnomalySummary
+		if err := rows.Scan(
+			&a.AgentID, &a.AgentName, &a.AgentRegion,
+			&a.AnomalyCount, &a.AlertCount, &a.AffectedTargets, &a.AssignedTargets,
+		); err != nil {
+			return nil, fmt.Errorf("scanning agent anomaly summary: %w", err)
+		}
+		summaries = append(summaries, a)
+	}
+	return summaries, rows.Err()
+}
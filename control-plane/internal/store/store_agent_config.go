@@ -0,0 +1,125 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// REMOTE AGENT CONFIG
+// =============================================================================
+
+// AgentConfigGlobalScope is the scope of the config every agent receives.
+// Any other scope is an agent ID.
+const AgentConfigGlobalScope = "global"
+
+// AgentConfigScope is the remote config stored for one scope.
+type AgentConfigScope struct {
+	Scope     string                  `json:"scope"`
+	Config    types.AgentRemoteConfig `json:"config"`
+	Version   int64                   `json:"version"`
+	UpdatedAt time.Time               `json:"updated_at"`
+}
+
+func scanAgentConfigScope(row pgx.Row) (*AgentConfigScope, error) {
+	var c AgentConfigScope
+	var configJSON []byte
+	if err := row.Scan(&c.Scope, &configJSON, &c.Version, &c.UpdatedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(configJSON, &c.Config); err != nil {
+		return nil, fmt.Errorf("decoding agent config for %s: %w", c.Scope, err)
+	}
+	return &c, nil
+}
+
+// GetAgentConfigScope returns the config stored for a scope, or nil if
+// there is none.
+func (s *Store) GetAgentConfigScope(ctx context.Context, scope string) (*AgentConfigScope, error) {
+	c, err := scanAgentConfigScope(s.pool.QueryRow(ctx, `
+		SELECT scope, config, version, updated_at
+		FROM agent_remote_config
+		WHERE scope = $1
+	`, scope))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting agent config: %w", err)
+	}
+	return c, nil
+}
+
+// SetAgentConfigScope replaces a scope's config, giving it a new version.
+func (s *Store) SetAgentConfigScope(ctx context.Context, scope string, config types.AgentRemoteConfig) (*AgentConfigScope, error) {
+	configJSON, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("encoding agent config: %w", err)
+	}
+	c, err := scanAgentConfigScope(s.pool.QueryRow(ctx, `
+		INSERT INTO agent_remote_config (scope, config)
+		VALUES ($1, $2)
+		ON CONFLICT (scope) DO UPDATE
+		SET config = EXCLUDED.config,
+		    version = nextval('agent_config_version_seq'),
+		    updated_at = NOW()
+		RETURNING scope, config, version, updated_at
+	`, scope, configJSON))
+	if err != nil {
+		return nil, fmt.Errorf("setting agent config: %w", err)
+	}
+	return c, nil
+}
+
+// DeleteAgentConfigScope removes a scope's config.
+func (s *Store) DeleteAgentConfigScope(ctx context.Context, scope string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM agent_remote_config WHERE scope = $1`, scope)
+	if err != nil {
+		return fmt.Errorf("deleting agent config: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("agent config %w", ErrNotFound)
+	}
+	return nil
+}
+
+// GetAgentConfigVersion returns the version of an agent's effective config:
+// the newest of the global and agent scopes, or 0 if neither is set. It is
+// checked on every heartbeat.
+func (s *Store) GetAgentConfigVersion(ctx context.Context, agentID string) (int64, error) {
+	var version int64
+	err := s.pool.QueryRow(ctx, `
+		SELECT COALESCE(max(version), 0)
+		FROM agent_remote_config
+		WHERE scope IN ($1, $2)
+	`, AgentConfigGlobalScope, agentID).Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("getting agent config version: %w", err)
+	}
+	return version, nil
+}
+
+// GetAppliedAgentConfigVersion returns the config version in the agent's
+// latest heartbeat, or nil if it has not reported one.
+func (s *Store) GetAppliedAgentConfigVersion(ctx context.Context, agentID string) (*int64, error) {
+	var version *int64
+	err := s.reader().QueryRow(ctx, `
+		SELECT config_version
+		FROM agent_metrics
+		WHERE agent_id = $1
+		ORDER BY time DESC
+		LIMIT 1
+	`, agentID).Scan(&version)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting applied agent config version: %w", err)
+	}
+	return version, nil
+}
@@ -17,6 +17,7 @@ import (
 
 // CreateAlert inserts a new alert and its initial "created" event.
 // It also looks up subnet metadata for the target IP and stores it for historical accuracy.
+// Subnet rollup alerts have no target; their TargetIP is the subnet's CIDR.
 func (s *Store) CreateAlert(ctx context.Context, alert *types.Alert) error {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
@@ -25,7 +26,10 @@ func (s *Store) CreateAlert(ctx context.Context, alert *types.Alert) error {
 	defer tx.Rollback(ctx)
 
 	// Convert optional fields to nullable
-	var agentID, incidentID, correlationKey interface{}
+	var targetID, agentID, incidentID, correlationKey interface{}
+	if alert.TargetID != "" {
+		targetID = alert.TargetID
+	}
 	if alert.AgentID != "" {
 		agentID = alert.AgentID
 	}
@@ -42,7 +46,8 @@ func (s *Store) CreateAlert(ctx context.Context, alert *types.Alert) error {
 	err = tx.QueryRow(ctx, `
 		SELECT id, subscriber_name, service_id, location_id, location_address, city, region, pop_name, gateway_device
 		FROM subnets
-		WHERE $1::inet << network_address::inet AND state = 'active'
+		WHERE $1::inet <<= network_address::inet AND state = 'active'
+		ORDER BY masklen(network_address::inet) DESC
 		LIMIT 1
 	`, alert.TargetIP).Scan(&subnetID, &subscriberName, &serviceID, &locationID, &locationAddress, &city, &region, &popName, &gatewayDevice)
 	if err != nil && err != pgx.ErrNoRows {
@@ -76,7 +81,7 @@ func (s *Store) CreateAlert(ctx context.Context, alert *types.Alert) error {
 			$22, $23, $24, $25, $26, $27, $28, $29, $30
 		)
 	`,
-		alert.ID, alert.TargetID, alert.TargetIP, agentID,
+		alert.ID, targetID, alert.TargetIP, agentID,
 		alert.AlertType, alert.Severity, alert.Status,
 		alert.InitialSeverity, alert.PeakSeverity,
 		alert.InitialLatencyMs, alert.InitialPacketLoss,
@@ -112,6 +117,22 @@ func (s *Store) CreateAlert(ctx context.Context, alert *types.Alert) error {
 		return fmt.Errorf("insert created event: %w", err)
 	}
 
+	err = recordEvent(ctx, tx, types.EventAlertCreated, alert.ID, map[string]any{
+		"alert_id":    alert.ID,
+		"alert_type":  alert.AlertType,
+		"severity":    alert.Severity,
+		"target_id":   alert.TargetID,
+		"target_ip":   alert.TargetIP,
+		"agent_id":    alert.AgentID,
+		"subnet_id":   subnetID,
+		"title":       alert.Title,
+		"message":     alert.Message,
+		"detected_at": alert.DetectedAt,
+	})
+	if err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
@@ -124,7 +145,7 @@ func (s *Store) GetAlert(ctx context.Context, id string) (*types.Alert, error) {
 
 	err := s.pool.QueryRow(ctx, `
 		SELECT
-			a.id, a.target_id, host(a.target_ip), a.agent_id,
+			a.id, COALESCE(a.target_id::text, ''), host(a.target_ip), a.agent_id,
 			a.alert_type, a.severity, a.status,
 			a.initial_severity, a.peak_severity,
 			a.initial_latency_ms, a.initial_packet_loss,
@@ -137,7 +158,8 @@ func (s *Store) GetAlert(ctx context.Context, id string) (*types.Alert, error) {
 			a.incident_id, a.correlation_key,
 			a.created_at,
 			t.ip_address::text as target_name,
-			ag.name as agent_name
+			ag.name as agent_name,
+			COALESCE(a.escalation_policy_id::text, ''), a.escalation_step
 		FROM alerts a
 		LEFT JOIN targets t ON a.target_id = t.id
 		LEFT JOIN agents ag ON a.agent_id = ag.id
@@ -156,6 +178,7 @@ func (s *Store) GetAlert(ctx context.Context, id string) (*types.Alert, error) {
 		&incidentID, &correlationKey,
 		&alert.CreatedAt,
 		&targetName, &agentName,
+		&alert.EscalationPolicyID, &alert.EscalationStep,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -261,7 +284,7 @@ func (s *Store) ListAlerts(ctx context.Context, filter types.AlertFilter) ([]typ
 
 	query := fmt.Sprintf(`
 		SELECT
-			a.id, a.target_id, host(a.target_ip), a.agent_id,
+			a.id, COALESCE(a.target_id::text, ''), host(a.target_ip), a.agent_id,
 			a.alert_type, a.severity, a.status,
 			a.initial_severity, a.peak_severity,
 			a.initial_latency_ms, a.initial_packet_loss,
@@ -287,7 +310,8 @@ func (s *Store) ListAlerts(ctx context.Context, filter types.AlertFilter) ([]typ
 			a.city,
 			a.region,
 			a.pop_name,
-			a.gateway_device
+			a.gateway_device,
+			COALESCE(a.escalation_policy_id::text, ''), a.escalation_step
 		FROM alerts a
 		LEFT JOIN targets t ON a.target_id = t.id
 		LEFT JOIN agents ag ON a.agent_id = ag.id
@@ -330,6 +354,7 @@ func (s *Store) ListAlerts(ctx context.Context, filter types.AlertFilter) ([]typ
 			&alert.CreatedAt,
 			&targetName, &agentName,
 			&subnetID, &subnetCIDR, &subscriberName, &serviceID, &locationID, &locationAddress, &city, &region, &popName, &gatewayDevice,
+			&alert.EscalationPolicyID, &alert.EscalationStep,
 		); err != nil {
 			return nil, err
 		}
@@ -418,7 +443,7 @@ func (s *Store) FindActiveAlertForTarget(ctx context.Context, targetID string, a
 
 	err := s.pool.QueryRow(ctx, fmt.Sprintf(`
 		SELECT
-			id, target_id, host(target_ip), agent_id,
+			id, COALESCE(target_id::text, ''), host(target_ip), agent_id,
 			alert_type, severity, status,
 			initial_severity, peak_severity,
 			initial_latency_ms, initial_packet_loss,
@@ -429,7 +454,8 @@ func (s *Store) FindActiveAlertForTarget(ctx context.Context, targetID string, a
 			acknowledged_at, acknowledged_by,
 			resolved_at,
 			incident_id, correlation_key,
-			created_at
+			created_at,
+			COALESCE(escalation_policy_id::text, ''), escalation_step
 		FROM alerts
 		WHERE %s
 		ORDER BY detected_at DESC
@@ -447,6 +473,7 @@ func (s *Store) FindActiveAlertForTarget(ctx context.Context, targetID string, a
 		&resolvedAt,
 		&incidentID, &correlationKey,
 		&alert.CreatedAt,
+		&alert.EscalationPolicyID, &alert.EscalationStep,
 	)
 	if err == pgx.ErrNoRows {
 		return nil, nil
@@ -588,7 +615,12 @@ func (s *Store) ResolveAlert(ctx context.Context, alertID string, description st
 	defer tx.Rollback(ctx)
 
 	var oldStatus types.AlertStatus
-	err = tx.QueryRow(ctx, `SELECT status FROM alerts WHERE id = $1`, alertID).Scan(&oldStatus)
+	var alertType types.AlertType
+	var targetID, targetIP string
+	err = tx.QueryRow(ctx, `
+		SELECT status, alert_type, COALESCE(target_id::text, ''), COALESCE(host(target_ip), '')
+		FROM alerts WHERE id = $1
+	`, alertID).Scan(&oldStatus, &alertType, &targetID, &targetIP)
 	if err != nil {
 		return err
 	}
@@ -615,9 +647,29 @@ func (s *Store) ResolveAlert(ctx context.Context, alertID string, description st
 		return err
 	}
 
+	if oldStatus != types.AlertStatusResolved {
+		err = recordEvent(ctx, tx, types.EventAlertResolved, alertID,
+			alertResolvedPayload(alertID, alertType, targetID, targetIP, oldStatus, description))
+		if err != nil {
+			return err
+		}
+	}
+
 	return tx.Commit(ctx)
 }
 
+// alertResolvedPayload is the alert.resolved event payload.
+func alertResolvedPayload(alertID string, alertType types.AlertType, targetID, targetIP string, oldStatus types.AlertStatus, reason string) map[string]any {
+	return map[string]any{
+		"alert_id":        alertID,
+		"alert_type":      alertType,
+		"target_id":       targetID,
+		"target_ip":       targetIP,
+		"previous_status": oldStatus,
+		"reason":          reason,
+	}
+}
+
 // ReopenAlert reopens a previously resolved alert.
 func (s *Store) ReopenAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error {
 	tx, err := s.pool.Begin(ctx)
@@ -707,6 +759,19 @@ func (s *Store) UpdateAlertMetrics(ctx context.Context, alertID string, latencyM
 	return err
 }
 
+// UpdateAlertSummary rewrites an alert's title and message, e.g. as a subnet
+// rollup alert absorbs more targets.
+func (s *Store) UpdateAlertSummary(ctx context.Context, alertID, title, message string) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE alerts SET
+			title = $2,
+			message = $3,
+			last_updated_at = NOW()
+		WHERE id = $1
+	`, alertID, title, message)
+	return err
+}
+
 // =============================================================================
 // ALERT EVENTS
 // =============================================================================
@@ -818,7 +883,7 @@ func (s *Store) LinkAlertToIncident(ctx context.Context, alertID, incidentID str
 // GetUnlinkedAlertsByCorrelation returns active alerts not yet linked to an incident.
 func (s *Store) GetUnlinkedAlertsByCorrelation(ctx context.Context, correlationKey string, window time.Duration) ([]types.Alert, error) {
 	rows, err := s.pool.Query(ctx, `
-		SELECT id, target_id, agent_id, severity, detected_at
+		SELECT id, COALESCE(target_id::text, ''), agent_id, severity, detected_at
 		FROM alerts
 		WHERE incident_id IS NULL
 		  AND status = 'active'
@@ -935,6 +1000,23 @@ func (s *Store) GetAlertConfigFloat(ctx context.Context, key string, defaultVal
 	}
 }
 
+// GetAlertConfigString retrieves a config value as a string with default.
+// Numbers and booleans are formatted as JSON writes them.
+func (s *Store) GetAlertConfigString(ctx context.Context, key string, defaultVal string) (string, error) {
+	cfg, err := s.GetAlertConfig(ctx, key)
+	if err != nil || cfg == nil {
+		return defaultVal, err
+	}
+	switch v := cfg.Value.(type) {
+	case string:
+		return v, nil
+	case float64, bool:
+		return fmt.Sprint(v), nil
+	default:
+		return defaultVal, nil
+	}
+}
+
 // SetAlertConfig sets a configuration value.
 func (s *Store) SetAlertConfig(ctx context.Context, key string, value interface{}, description string) error {
 	valueJSON, err := json.Marshal(value)
@@ -985,13 +1067,15 @@ func (s *Store) ListAlertConfigs(ctx context.Context) ([]types.AlertConfig, erro
 // ANOMALY DETECTION
 // =============================================================================
 
-// GetCurrentAnomalies returns anomalies detected from agent_target_state.
+// GetCurrentAnomalies returns anomalies detected from agent_target_state,
+// skipping agents excluded from the target's health.
 func (s *Store) GetCurrentAnomalies(ctx context.Context, lookback time.Duration) ([]types.Anomaly, error) {
 	rows, err := s.pool.Query(ctx, `
 		SELECT
 			target_id, host(target_ip), agent_id, anomaly_type, severity,
 			latency_ms, packet_loss, z_score, subnet_id, consecutive_failures
-		FROM get_current_anomalies($1::interval)
+		FROM get_current_anomalies($1::interval) ca
+		WHERE NOT agent_health_excluded(ca.agent_id, ca.target_id)
 	`, lookback.String())
 	if err != nil {
 		return nil, err
@@ -1038,6 +1122,7 @@ func (s *Store) GetHealthyTargetsWithActiveAlerts(ctx context.Context, requiredH
 			FROM alerts a
 			JOIN targets t ON t.id = a.target_id
 			JOIN agent_target_state ats ON ats.target_id = a.target_id
+				AND NOT agent_health_excluded(ats.agent_id, ats.target_id)
 			JOIN agents ag ON ag.id = ats.agent_id AND ag.archived_at IS NULL
 			WHERE a.status IN ('active', 'acknowledged')
 			  AND t.archived_at IS NULL
@@ -1188,6 +1273,19 @@ func (s *Store) CreateIncidentFromAlerts(ctx context.Context, correlationKey str
 		}
 	}
 
+	err = recordEvent(ctx, tx, types.EventIncidentCreated, incidentID, map[string]any{
+		"incident_id":         incidentID,
+		"incident_type":       incidentType,
+		"severity":            severity,
+		"correlation_key":     correlationKey,
+		"alert_ids":           alertIDs,
+		"affected_target_ids": affectedTargetIDs,
+		"affected_agent_ids":  affectedAgentIDs,
+	})
+	if err != nil {
+		return "", err
+	}
+
 	if err := tx.Commit(ctx); err != nil {
 		return "", err
 	}
@@ -1353,10 +1451,10 @@ func (s *Store) ResolveAlertsBySubnet(ctx context.Context, subnetID string, reas
 
 	// Find all active/acknowledged alerts for targets in this subnet
 	rows, err := tx.Query(ctx, `
-		SELECT a.id, a.status
+		SELECT a.id, a.status, a.alert_type, COALESCE(a.target_id::text, ''), COALESCE(host(a.target_ip), '')
 		FROM alerts a
-		JOIN targets t ON a.target_id = t.id
-		WHERE t.subnet_id = $1
+		LEFT JOIN targets t ON a.target_id = t.id
+		WHERE (t.subnet_id = $1 OR (a.target_id IS NULL AND a.subnet_id = $1))
 		  AND a.status IN ('active', 'acknowledged')
 	`, subnetID)
 	if err != nil {
@@ -1365,15 +1463,21 @@ func (s *Store) ResolveAlertsBySubnet(ctx context.Context, subnetID string, reas
 
 	var alertIDs []string
 	var oldStatuses []types.AlertStatus
+	var alertTypes []types.AlertType
+	var targetIDs, targetIPs []string
 	for rows.Next() {
-		var id string
+		var id, targetID, targetIP string
 		var status types.AlertStatus
-		if err := rows.Scan(&id, &status); err != nil {
+		var alertType types.AlertType
+		if err := rows.Scan(&id, &status, &alertType, &targetID, &targetIP); err != nil {
 			rows.Close()
 			return 0, err
 		}
 		alertIDs = append(alertIDs, id)
 		oldStatuses = append(oldStatuses, status)
+		alertTypes = append(alertTypes, alertType)
+		targetIDs = append(targetIDs, targetID)
+		targetIPs = append(targetIPs, targetIP)
 	}
 	rows.Close()
 
@@ -1405,6 +1509,12 @@ func (s *Store) ResolveAlertsBySubnet(ctx context.Context, subnetID string, reas
 		if err != nil {
 			return 0, err
 		}
+
+		err = recordEvent(ctx, tx, types.EventAlertResolved, alertID,
+			alertResolvedPayload(alertID, alertTypes[i], targetIDs[i], targetIPs[i], oldStatuses[i], reason))
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	if err := tx.Commit(ctx); err != nil {
@@ -0,0 +1,160 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET ANNOTATIONS
+// =============================================================================
+
+// CreateTargetAnnotation inserts a manual annotation and populates its ID and
+// creation time.
+func (s *Store) CreateTargetAnnotation(ctx context.Context, a *types.TargetAnnotation) error {
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO annotations (target_id, starts_at, ends_at, text, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, created_at
+	`, a.TargetID, a.StartsAt, a.EndsAt, a.Text, a.CreatedBy).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting annotation: %w", err)
+	}
+	a.Source = types.AnnotationSourceManual
+	return nil
+}
+
+// ListTargetAnnotations returns a target's manual annotations that overlap
+// [from, to], oldest first. A point annotation overlaps when it falls inside.
+func (s *Store) ListTargetAnnotations(ctx context.Context, targetID string, from, to time.Time) ([]types.TargetAnnotation, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT id, target_id, starts_at, ends_at, text, COALESCE(created_by, ''), created_at
+		FROM annotations
+		WHERE target_id = $1
+		  AND starts_at <= $3
+		  AND COALESCE(ends_at, starts_at) >= $2
+		ORDER BY starts_at
+	`, targetID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("listing annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var annotations []types.TargetAnnotation
+	for rows.Next() {
+		a := types.TargetAnnotation{Source: types.AnnotationSourceManual}
+		if err := rows.Scan(&a.ID, &a.TargetID, &a.StartsAt, &a.EndsAt, &a.Text, &a.CreatedBy, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning annotation: %w", err)
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, rows.Err()
+}
+
+// DeleteTargetAnnotation removes a manual annotation from a target.
+func (s *Store) DeleteTargetAnnotation(ctx context.Context, targetID, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM annotations WHERE id = $1 AND target_id = $2`, id, targetID)
+	if err != nil {
+		return fmt.Errorf("deleting annotation: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("annotation %w", ErrNotFound)
+	}
+	return nil
+}
+
+// TargetIncidentSpan is an incident that affected a target, reduced to what
+// a chart annotation needs.
+type TargetIncidentSpan struct {
+	IncidentID   string
+	IncidentType string
+	Severity     string
+	DetectedAt   time.Time
+	ResolvedAt   *time.Time
+}
+
+// ListTargetIncidentSpans returns incidents that affected a target and were
+// open at some point in [from, to], oldest first. Unresolved incidents are
+// treated as still open.
+func (s *Store) ListTargetIncidentSpans(ctx context.Context, targetID string, from, to time.Time) ([]TargetIncidentSpan, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT id::text, incident_type::text, severity::text, detected_at, resolved_at
+		FROM incidents
+		WHERE ($1::uuid = ANY(affected_target_ids)
+		       OR (primary_entity_type = 'target' AND primary_entity_id = $1::text))
+		  AND detected_at <= $3
+		  AND COALESCE(resolved_at, NOW()) >= $2
+		ORDER BY detected_at
+	`, targetID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("listing target incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var spans []TargetIncidentSpan
+	for rows.Next() {
+		var sp TargetIncidentSpan
+		if err := rows.Scan(&sp.IncidentID, &sp.IncidentType, &sp.Severity, &sp.DetectedAt, &sp.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("scanning target incident: %w", err)
+		}
+		spans = append(spans, sp)
+	}
+	return spans, rows.Err()
+}
+
+// ListAnnotations returns manual annotations on any target that overlap
+// [from, to], oldest first, up to limit.
+func (s *Store) ListAnnotations(ctx context.Context, from, to time.Time, limit int) ([]types.TargetAnnotation, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT id, target_id, starts_at, ends_at, text, COALESCE(created_by, ''), created_at
+		FROM annotations
+		WHERE starts_at <= $2
+		  AND COALESCE(ends_at, starts_at) >= $1
+		ORDER BY starts_at
+		LIMIT $3
+	`, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing annotations: %w", err)
+	}
+	defer rows.Close()
+
+	var annotations []types.TargetAnnotation
+	for rows.Next() {
+		a := types.TargetAnnotation{Source: types.AnnotationSourceManual}
+		if err := rows.Scan(&a.ID, &a.TargetID, &a.StartsAt, &a.EndsAt, &a.Text, &a.CreatedBy, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning annotation: %w", err)
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, rows.Err()
+}
+
+// ListIncidentSpans returns incidents of any kind that were open at some
+// point in [from, to], oldest first, up to limit.
+func (s *Store) ListIncidentSpans(ctx context.Context, from, to time.Time, limit int) ([]TargetIncidentSpan, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT id::text, incident_type::text, severity::text, detected_at, resolved_at
+		FROM incidents
+		WHERE detected_at <= $2
+		  AND COALESCE(resolved_at, NOW()) >= $1
+		ORDER BY detected_at
+		LIMIT $3
+	`, from, to, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing incidents: %w", err)
+	}
+	defer rows.Close()
+
+	var spans []TargetIncidentSpan
+	for rows.Next() {
+		var sp TargetIncidentSpan
+		if err := rows.Scan(&sp.IncidentID, &sp.IncidentType, &sp.Severity, &sp.DetectedAt, &sp.ResolvedAt); err != nil {
+			return nil, fmt.Errorf("scanning incident: %w", err)
+		}
+		spans = append(spans, sp)
+	}
+	return spans, rows.Err()
+}
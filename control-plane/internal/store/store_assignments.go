@@ -4,6 +4,7 @@ package store
 import (
 	"context"
 	"encoding/json"
+	"fmt"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -372,3 +373,50 @@ func nilIfEmpty(s string) interface{} {
 	}
 	return s
 }
+
+// ListAssignmentChanges returns the assignment change log for an agent
+// after fromVersion up to and including toVersion, oldest first.
+func (s *Store) ListAssignmentChanges(ctx context.Context, agentID string, fromVersion, toVersion int64) ([]types.AssignmentLogEntry, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT c.version, c.target_id::text, COALESCE(host(t.ip_address), ''),
+		       c.action, COALESCE(c.tier, ''), c.changed_at
+		FROM assignment_changes c
+		LEFT JOIN targets t ON t.id = c.target_id
+		WHERE c.agent_id = $1 AND c.version > $2 AND c.version <= $3
+		ORDER BY c.version, c.changed_at, c.action DESC
+	`, agentID, fromVersion, toVersion)
+	if err != nil {
+		return nil, fmt.Errorf("listing assignment changes: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []types.AssignmentLogEntry
+	for rows.Next() {
+		var e types.AssignmentLogEntry
+		if err := rows.Scan(&e.Version, &e.TargetID, &e.TargetIP, &e.Action, &e.Tier, &e.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scanning assignment change: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetReportedAssignmentVersion returns the assignment version in the
+// agent's latest heartbeat, or 0 if it has reported none.
+func (s *Store) GetReportedAssignmentVersion(ctx context.Context, agentID string) (int64, error) {
+	var version int64
+	err := s.pool.QueryRow(ctx, `
+		SELECT COALESCE(assignment_version, 0)
+		FROM agent_metrics
+		WHERE agent_id = $1
+		ORDER BY time DESC
+		LIMIT 1
+	`, agentID).Scan(&version)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("getting reported assignment version: %w", err)
+	}
+	return version, nil
+}
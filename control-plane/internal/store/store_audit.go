@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// AUDIT LOG
+// =============================================================================
+
+// InsertAuditEntry appends an entry to the audit log.
+func (s *Store) InsertAuditEntry(ctx context.Context, e *types.AuditEntry) error {
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO audit_log (time, actor, actor_role, method, path, entity_type, entity_id,
+		                       body_sha256, status_code, result, remote_addr, duration_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+		RETURNING id
+	`, e.Time, e.Actor, e.ActorRole, e.Method, e.Path, e.EntityType, e.EntityID,
+		e.BodySHA256, e.StatusCode, e.Result, e.RemoteAddr, e.DurationMs).Scan(&e.ID)
+	if err != nil {
+		return fmt.Errorf("inserting audit entry: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries returns audit entries matching the filter, newest first.
+func (s *Store) ListAuditEntries(ctx context.Context, filter types.AuditFilter) ([]types.AuditEntry, error) {
+	query := `
+		SELECT id, time, actor, actor_role, method, path, entity_type, entity_id,
+		       body_sha256, status_code, result, remote_addr, duration_ms
+		FROM audit_log
+		WHERE 1=1
+	`
+	var args []any
+	argNum := 1
+
+	if filter.Actor != "" {
+		query += fmt.Sprintf(" AND actor = $%d", argNum)
+		args = append(args, filter.Actor)
+		argNum++
+	}
+	if filter.EntityType != "" {
+		query += fmt.Sprintf(" AND entity_type = $%d", argNum)
+		args = append(args, filter.EntityType)
+		argNum++
+	}
+	if filter.EntityID != "" {
+		query += fmt.Sprintf(" AND entity_id = $%d", argNum)
+		args = append(args, filter.EntityID)
+		argNum++
+	}
+	if filter.Since != nil {
+		query += fmt.Sprintf(" AND time >= $%d", argNum)
+		args = append(args, *filter.Since)
+		argNum++
+	}
+	if filter.Until != nil {
+		query += fmt.Sprintf(" AND time < $%d", argNum)
+		args = append(args, *filter.Until)
+		argNum++
+	}
+
+	query += fmt.Sprintf(" ORDER BY time DESC, id DESC LIMIT $%d", argNum)
+	args = append(args, filter.Limit)
+
+	rows, err := s.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying audit log: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []types.AuditEntry
+	for rows.Next() {
+		var e types.AuditEntry
+		if err := rows.Scan(
+			&e.ID, &e.Time, &e.Actor, &e.ActorRole, &e.Method, &e.Path, &e.EntityType, &e.EntityID,
+			&e.BodySHA256, &e.StatusCode, &e.Result, &e.RemoteAddr, &e.DurationMs,
+		); err != nil {
+			return nil, fmt.Errorf("scanning audit entry: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// TARGET AVAILABILITY
+// =============================================================================
+
+// AvailabilityHour is a target's probe counts across agents for one hour.
+// Degraded counts are the successes that missed the SLO passed to
+// GetTargetAvailabilityHours; they are included in Successes.
+type AvailabilityHour struct {
+	Bucket            time.Time
+	Probes            int64
+	Successes         int64
+	Degraded          int64
+	InMarketProbes    int64
+	InMarketSuccesses int64
+	InMarketDegraded  int64
+}
+
+// GetTargetAvailabilityHours returns a target's hourly probe and success
+// counts, overall and in-market, for hours starting in [start, end), oldest
+// first. Agents excluded from the target's health are left out of the
+// overall counts; the in-market aggregate has no per-agent rows to filter.
+//
+// When maxLatencyMs or maxLossPct is set, the successes of each agent-hour
+// whose average latency or packet loss exceeds it are also counted as
+// degraded. Nil thresholds are not checked.
+func (s *Store) GetTargetAvailabilityHours(ctx context.Context, targetID string, start, end time.Time, maxLatencyMs, maxLossPct *float64) ([]AvailabilityHour, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH overall AS (
+			SELECT ph.bucket, sum(ph.probe_count) AS probes, sum(ph.success_count) AS successes,
+			       sum(ph.success_count) FILTER (
+			           WHERE ph.avg_latency > $4::DOUBLE PRECISION OR ph.avg_packet_loss > $5::DOUBLE PRECISION
+			       ) AS degraded
+			FROM probe_hourly ph
+			WHERE ph.target_id = $1
+			  AND ph.bucket >= $2 AND ph.bucket < $3
+			  AND NOT agent_health_excluded(ph.agent_id, ph.target_id)
+			GROUP BY ph.bucket
+		),
+		in_market AS (
+			SELECT bucket, probe_count AS probes, success_count AS successes,
+			       CASE WHEN avg_latency > $4::DOUBLE PRECISION OR avg_packet_loss > $5::DOUBLE PRECISION
+			            THEN success_count ELSE 0 END AS degraded
+			FROM probe_hourly_in_market
+			WHERE target_id = $1
+			  AND bucket >= $2 AND bucket < $3
+		)
+		SELECT COALESCE(o.bucket, m.bucket),
+		       COALESCE(o.probes, 0)::BIGINT, COALESCE(o.successes, 0)::BIGINT, COALESCE(o.degraded, 0)::BIGINT,
+		       COALESCE(m.probes, 0)::BIGINT, COALESCE(m.successes, 0)::BIGINT, COALESCE(m.degraded, 0)::BIGINT
+		FROM overall o
+		FULL OUTER JOIN in_market m ON m.bucket = o.bucket
+		ORDER BY 1
+	`, targetID, start, end, maxLatencyMs, maxLossPct)
+	if err != nil {
+		return nil, fmt.Errorf("querying availability hours: %w", err)
+	}
+	defer rows.Close()
+
+	var hours []AvailabilityHour
+	for rows.Next() {
+		var h AvailabilityHour
+		if err := rows.Scan(
+			&h.Bucket, &h.Probes, &h.Successes, &h.Degraded,
+			&h.InMarketProbes, &h.InMarketSuccesses, &h.InMarketDegraded,
+		); err != nil {
+			return nil, fmt.Errorf("scanning availability hour: %w", err)
+		}
+		hours = append(hours, h)
+	}
+	return hours, rows.Err()
+}
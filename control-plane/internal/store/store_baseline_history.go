@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// BASELINE DRIFT
+// =============================================================================
+
+// baselineSnapshotTolerance is how much older than the lookback a snapshot
+// may be and still stand in for "N weeks ago". Baselines are snapshotted
+// daily, so a week covers gaps from downtime without comparing against
+// arbitrarily old history.
+const baselineSnapshotTolerance = 7 * 24 * time.Hour
+
+// BaselineDrift is a target whose baseline p95, averaged over its agents,
+// has risen since an earlier snapshot.
+type BaselineDrift struct {
+	TargetID     string
+	TargetIP     string
+	PastP95Ms    float64
+	CurrentP95Ms float64
+	IncreasePct  float64
+	Agents       int       // agents with both a current baseline and a snapshot
+	PastSnapshot time.Time // oldest snapshot compared against
+}
+
+// GetBaselineDrift returns targets whose current baseline p95 is at least
+// minIncreasePct percent and minIncreaseMs above the baseline from lookback
+// ago. Only agents with a snapshot from then are compared, so adding or
+// losing agents doesn't look like drift. Excluded agents are ignored.
+func (s *Store) GetBaselineDrift(ctx context.Context, lookback time.Duration, minIncreasePct, minIncreaseMs float64) ([]BaselineDrift, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH past AS (
+			SELECT DISTINCT ON (bh.agent_id, bh.target_id)
+				bh.agent_id, bh.target_id, bh.latency_p95, bh.snapshot_at
+			FROM baseline_history bh
+			WHERE bh.snapshot_at <= NOW() - $1::interval
+			  AND bh.snapshot_at > NOW() - $1::interval - $2::interval
+			  AND bh.latency_p95 IS NOT NULL
+			ORDER BY bh.agent_id, bh.target_id, bh.snapshot_at DESC
+		),
+		drift AS (
+			SELECT
+				b.target_id,
+				avg(p.latency_p95) AS past_p95,
+				avg(b.latency_p95) AS current_p95,
+				COUNT(*) AS agents,
+				min(p.snapshot_at) AS past_snapshot
+			FROM agent_target_baseline b
+			JOIN past p ON p.agent_id = b.agent_id AND p.target_id = b.target_id
+			JOIN agents ag ON ag.id = b.agent_id AND ag.archived_at IS NULL
+			WHERE b.latency_p95 IS NOT NULL
+			  AND NOT agent_health_excluded(b.agent_id, b.target_id)
+			GROUP BY b.target_id
+		)
+		SELECT
+			d.target_id, host(t.ip_address),
+			d.past_p95, d.current_p95,
+			((d.current_p95 - d.past_p95) / NULLIF(d.past_p95, 0) * 100)::DOUBLE PRECISION,
+			d.agents, d.past_snapshot
+		FROM drift d
+		JOIN targets t ON t.id = d.target_id AND t.archived_at IS NULL
+		WHERE d.current_p95 - d.past_p95 >= $4
+		  AND d.current_p95 >= d.past_p95 * (1 + $3 / 100.0)
+		ORDER BY (d.current_p95 - d.past_p95) / NULLIF(d.past_p95, 0) DESC NULLS LAST
+	`, lookback.String(), baselineSnapshotTolerance.String(), minIncreasePct, minIncreaseMs)
+	if err != nil {
+		return nil, fmt.Errorf("querying baseline drift: %w", err)
+	}
+	defer rows.Close()
+
+	var drifts []BaselineDrift
+	for rows.Next() {
+		var d BaselineDrift
+		var pct *float64
+		if err := rows.Scan(&d.TargetID, &d.TargetIP, &d.PastP95Ms, &d.CurrentP95Ms,
+			&pct, &d.Agents, &d.PastSnapshot); err != nil {
+			return nil, fmt.Errorf("scanning baseline drift: %w", err)
+		}
+		if pct != nil {
+			d.IncreasePct = *pct
+		}
+		drifts = append(drifts, d)
+	}
+	return drifts, rows.Err()
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// SCOPED BASELINE RECALCULATION
+// =============================================================================
+
+// BaselineScope selects which baselines to recalculate. Empty fields match
+// everything; set fields are combined with AND.
+type BaselineScope struct {
+	SubnetID string `json:"subnet_id,omitempty"` // targets in this subnet
+	Region   string `json:"region,omitempty"`    // agents in this region
+	Tier     string `json:"tier,omitempty"`      // targets in this tier
+	AgentID  string `json:"agent_id,omitempty"`
+	TargetID string `json:"target_id,omitempty"`
+
+	// Since drops results before this time from the baseline window, so a
+	// resolved congestion event doesn't carry into the new baseline. The
+	// window never reaches back further than the usual 7 days.
+	Since *time.Time `json:"since,omitempty"`
+}
+
+// IsEmpty reports whether the scope covers every pair over the full window.
+func (b BaselineScope) IsEmpty() bool {
+	return b.SubnetID == "" && b.Region == "" && b.Tier == "" && b.AgentID == "" && b.TargetID == "" && b.Since == nil
+}
+
+// RecalculateBaselines recalculates the baselines of pairs in scope from
+// their successful results after warmup, snapshotting each to
+// baseline_history. Returns
+// the number of pairs recalculated. It is one set-based statement, so
+// scope it narrowly: the unscoped case is RecalculateAllBaselines.
+func (s *Store) RecalculateBaselines(ctx context.Context, scope BaselineScope) (int, error) {
+	var count int
+	err := s.pool.QueryRow(ctx, `
+		WITH recalculated AS (
+			INSERT INTO agent_target_baseline (
+				agent_id, target_id, latency_p50, latency_p95, latency_p99, latency_stddev,
+				packet_loss_baseline, sample_count, first_seen, last_updated
+			)
+			SELECT
+				pr.agent_id,
+				pr.target_id,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY pr.latency_ms),
+				percentile_cont(0.95) WITHIN GROUP (ORDER BY pr.latency_ms),
+				percentile_cont(0.99) WITHIN GROUP (ORDER BY pr.latency_ms),
+				stddev(pr.latency_ms),
+				avg(pr.packet_loss_pct),
+				count(*),
+				min(pr.time),
+				NOW()
+			FROM probe_results pr
+			JOIN targets t ON t.id = pr.target_id
+			JOIN agents a ON a.id = pr.agent_id
+			LEFT JOIN tiers tr ON tr.name = t.tier
+			WHERE pr.time > GREATEST(NOW() - INTERVAL '7 days', COALESCE($5::timestamptz, '-infinity'))
+			  AND pr.time >= COALESCE(baseline_warmup_end(a.started_at, t.created_at, tr.probe_interval_ms), '-infinity')
+			  AND pr.success = true
+			  AND ($1 = '' OR t.subnet_id = NULLIF($1, '')::uuid)
+			  AND ($2 = '' OR a.region = $2)
+			  AND ($3 = '' OR t.tier = $3)
+			  AND ($4 = '' OR pr.agent_id = NULLIF($4, '')::uuid)
+			  AND ($6 = '' OR pr.target_id = NULLIF($6, '')::uuid)
+			GROUP BY pr.agent_id, pr.target_id
+			ON CONFLICT (agent_id, target_id) DO UPDATE SET
+				latency_p50 = EXCLUDED.latency_p50,
+				latency_p95 = EXCLUDED.latency_p95,
+				latency_p99 = EXCLUDED.latency_p99,
+				latency_stddev = EXCLUDED.latency_stddev,
+				packet_loss_baseline = EXCLUDED.packet_loss_baseline,
+				sample_count = EXCLUDED.sample_count,
+				last_updated = EXCLUDED.last_updated
+			RETURNING *
+		),
+		snapshot AS (
+			INSERT INTO baseline_history (
+				agent_id, target_id, snapshot_at, latency_p50, latency_p95, latency_p99,
+				latency_stddev, packet_loss_baseline, sample_count
+			)
+			SELECT agent_id, target_id, last_updated, latency_p50, latency_p95, latency_p99,
+				latency_stddev, packet_loss_baseline, sample_count
+			FROM recalculated
+			ON CONFLICT DO NOTHING
+		)
+		SELECT count(*) FROM recalculated
+	`, scope.SubnetID, scope.Region, scope.Tier, scope.AgentID, scope.Since, scope.TargetID).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("recalculating scoped baselines: %w", err)
+	}
+	return count, nil
+}
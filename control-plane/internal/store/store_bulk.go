@@ -0,0 +1,68 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// SetBulkConcurrency sets how many batches of a bulk pair lookup (see
+// BulkGetRecentProbeStats) run at once. Zero or less restores the default.
+// It is capped at the pool size. Call it before the store is in use.
+func (s *Store) SetBulkConcurrency(n int) {
+	s.bulkConcurrency = n
+}
+
+// bulkLimit is the effective batch concurrency: the configured value,
+// never more than the pool has connections.
+func (s *Store) bulkLimit() int {
+	limit := s.bulkConcurrency
+	if limit <= 0 {
+		limit = config.BulkQueryConcurrency
+	}
+	if maxConns := int(s.pool.Config().MaxConns); maxConns > 0 && limit > maxConns {
+		limit = maxConns
+	}
+	return limit
+}
+
+// runPairBatches splits pairs into batches of batchSize, runs fetch on up
+// to limit batches at once, and merges the results. The first error
+// cancels the batches still running and is returned.
+func runPairBatches[V any](
+	ctx context.Context,
+	pairs []AgentTargetPair,
+	batchSize, limit int,
+	fetch func(ctx context.Context, batch []AgentTargetPair) (map[PairKey]V, error),
+) (map[PairKey]V, error) {
+	result := make(map[PairKey]V, len(pairs))
+	if len(pairs) == 0 {
+		return result, nil
+	}
+
+	var mu sync.Mutex
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(max(limit, 1))
+	for i := 0; i < len(pairs); i += batchSize {
+		batch := pairs[i:min(i+batchSize, len(pairs))]
+		g.Go(func() error {
+			batchResult, err := fetch(gctx, batch)
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			for k, v := range batchResult {
+				result[k] = v
+			}
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,120 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testPairs(n int) []AgentTargetPair {
+	pairs := make([]AgentTargetPair, n)
+	for i := range pairs {
+		pairs[i] = AgentTargetPair{AgentID: fmt.Sprintf("a%d", i%7), TargetID: fmt.Sprintf("t%d", i)}
+	}
+	return pairs
+}
+
+// echoBatch returns one entry per pair, valued by its target.
+func echoBatch(_ context.Context, batch []AgentTargetPair) (map[PairKey]string, error) {
+	out := make(map[PairKey]string, len(batch))
+	for _, p := range batch {
+		out[PairKey{AgentID: p.AgentID, TargetID: p.TargetID}] = p.TargetID
+	}
+	return out, nil
+}
+
+func TestRunPairBatches_Merge(t *testing.T) {
+	tests := []struct {
+		name      string
+		pairs     int
+		batchSize int
+		limit     int
+	}{
+		{name: "empty", pairs: 0, batchSize: 10, limit: 4},
+		{name: "single batch", pairs: 5, batchSize: 10, limit: 4},
+		{name: "sequential", pairs: 95, batchSize: 10, limit: 1},
+		{name: "parallel", pairs: 1000, batchSize: 7, limit: 8},
+		{name: "zero limit runs sequentially", pairs: 30, batchSize: 10, limit: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pairs := testPairs(tt.pairs)
+			got, err := runPairBatches(context.Background(), pairs, tt.batchSize, tt.limit, echoBatch)
+			if err != nil {
+				t.Fatalf("runPairBatches() error = %v", err)
+			}
+			if len(got) != len(pairs) {
+				t.Fatalf("got %d results, want %d", len(got), len(pairs))
+			}
+			for _, p := range pairs {
+				if got[PairKey{AgentID: p.AgentID, TargetID: p.TargetID}] != p.TargetID {
+					t.Errorf("missing or wrong result for %+v", p)
+				}
+			}
+		})
+	}
+}
+
+func TestRunPairBatches_Limit(t *testing.T) {
+	var running, peak atomic.Int32
+	fetch := func(ctx context.Context, batch []AgentTargetPair) (map[PairKey]string, error) {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+		return echoBatch(ctx, batch)
+	}
+
+	if _, err := runPairBatches(context.Background(), testPairs(200), 10, 3, fetch); err != nil {
+		t.Fatalf("runPairBatches() error = %v", err)
+	}
+	if p := peak.Load(); p > 3 {
+		t.Errorf("peak concurrency = %d, want at most 3", p)
+	}
+}
+
+func TestRunPairBatches_Error(t *testing.T) {
+	errBatch := errors.New("batch failed")
+	fetch := func(ctx context.Context, batch []AgentTargetPair) (map[PairKey]string, error) {
+		if batch[0].TargetID == "t20" {
+			return nil, errBatch
+		}
+		return echoBatch(ctx, batch)
+	}
+
+	got, err := runPairBatches(context.Background(), testPairs(50), 10, 2, fetch)
+	if !errors.Is(err, errBatch) {
+		t.Fatalf("runPairBatches() error = %v, want %v", err, errBatch)
+	}
+	if got != nil {
+		t.Errorf("runPairBatches() returned %d partial results on error", len(got))
+	}
+}
+
+// BenchmarkRunPairBatches simulates batches that each wait on a query, the
+// case where concurrency shortens an evaluator cycle.
+func BenchmarkRunPairBatches(b *testing.B) {
+	pairs := testPairs(2000)
+	fetch := func(ctx context.Context, batch []AgentTargetPair) (map[PairKey]string, error) {
+		time.Sleep(200 * time.Microsecond)
+		return echoBatch(ctx, batch)
+	}
+	for _, limit := range []int{1, 4, 16} {
+		b.Run(fmt.Sprintf("limit=%d", limit), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := runPairBatches(context.Background(), pairs, 100, limit, fetch); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// PIPELINE CANARY
+// =============================================================================
+
+// CanaryAgentStatus is one online agent's view of the pipeline canary.
+type CanaryAgentStatus struct {
+	AgentID       string
+	AgentName     string
+	Assigned      bool
+	AssignedAt    *time.Time
+	LastResultAt  *time.Time // newest canary row in probe_results
+	LastEvaluated *time.Time // last time the evaluator processed the pair
+}
+
+// GetCanaryAgentStatus returns, for every non-archived agent that has
+// heartbeated within heartbeatWindow, whether it is assigned the canary
+// target and when its canary results last landed and were evaluated. Only
+// results newer than resultWindow are looked at, keeping the scan on the
+// most recent chunk.
+func (s *Store) GetCanaryAgentStatus(ctx context.Context, targetID string, heartbeatWindow, resultWindow time.Duration) ([]CanaryAgentStatus, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH recent AS (
+			SELECT agent_id, MAX(time) AS last_result
+			FROM probe_results
+			WHERE target_id = $1 AND time > NOW() - $3::interval
+			GROUP BY agent_id
+		)
+		SELECT
+			ag.id::text, ag.name,
+			ta.agent_id IS NOT NULL, ta.assigned_at,
+			r.last_result, st.last_evaluated
+		FROM agents ag
+		LEFT JOIN target_assignments ta ON ta.agent_id = ag.id AND ta.target_id = $1
+		LEFT JOIN recent r ON r.agent_id = ag.id
+		LEFT JOIN agent_target_state st ON st.agent_id = ag.id AND st.target_id = $1
+		WHERE ag.archived_at IS NULL
+		  AND ag.last_heartbeat > NOW() - $2::interval
+		ORDER BY ag.name
+	`, targetID, heartbeatWindow.String(), resultWindow.String())
+	if err != nil {
+		return nil, fmt.Errorf("querying canary status: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []CanaryAgentStatus
+	for rows.Next() {
+		var c CanaryAgentStatus
+		if err := rows.Scan(&c.AgentID, &c.AgentName, &c.Assigned, &c.AssignedAt, &c.LastResultAt, &c.LastEvaluated); err != nil {
+			return nil, fmt.Errorf("scanning canary status: %w", err)
+		}
+		statuses = append(statuses, c)
+	}
+	return statuses, rows.Err()
+}
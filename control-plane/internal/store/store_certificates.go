@@ -0,0 +1,103 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TLS CERTIFICATES
+// =============================================================================
+
+// UpsertTargetCertificates records the latest certificate seen on each
+// target. An observation older than the stored one is ignored, so a late
+// batch from a slow agent doesn't roll a renewed certificate back, and one
+// for a target deleted since is dropped.
+func (s *Store) UpsertTargetCertificates(ctx context.Context, certs []types.TargetCertificate) error {
+	if len(certs) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, c := range certs {
+		batch.Queue(`
+			INSERT INTO target_certificates (
+				target_id, agent_id, port, server_name, subject, issuer, dns_names,
+				serial_number, not_before, not_after, chain_valid, verify_error, observed_at
+			)
+			SELECT $1::uuid, $2::uuid, $3::int, NULLIF($4::text, ''), NULLIF($5::text, ''), NULLIF($6::text, ''), $7::text[],
+				NULLIF($8::text, ''), $9::timestamptz, $10::timestamptz, $11::bool, NULLIF($12::text, ''), $13::timestamptz
+			WHERE EXISTS (SELECT 1 FROM targets WHERE id = $1::uuid)
+			ON CONFLICT (target_id) DO UPDATE SET
+				agent_id = EXCLUDED.agent_id,
+				port = EXCLUDED.port,
+				server_name = EXCLUDED.server_name,
+				subject = EXCLUDED.subject,
+				issuer = EXCLUDED.issuer,
+				dns_names = EXCLUDED.dns_names,
+				serial_number = EXCLUDED.serial_number,
+				not_before = EXCLUDED.not_before,
+				not_after = EXCLUDED.not_after,
+				chain_valid = EXCLUDED.chain_valid,
+				verify_error = EXCLUDED.verify_error,
+				observed_at = EXCLUDED.observed_at
+			WHERE target_certificates.observed_at <= EXCLUDED.observed_at
+		`, c.TargetID, c.AgentID, c.Port, c.ServerName, c.Subject, c.Issuer, nonNilStrings(c.DNSNames),
+			c.SerialNumber, c.NotBefore, c.NotAfter, c.ChainValid, c.VerifyError, c.ObservedAt)
+	}
+
+	if err := s.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("upserting target certificates: %w", err)
+	}
+	return nil
+}
+
+// ListTargetCertificates returns the latest certificate of each active
+// tls_cert target, soonest expiry first, with days remaining as of now.
+func (s *Store) ListTargetCertificates(ctx context.Context, filter types.CertificateFilter) ([]types.TargetCertificate, error) {
+	var observedSince *time.Time
+	if !filter.ObservedSince.IsZero() {
+		observedSince = &filter.ObservedSince
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			c.target_id::text, host(t.ip_address), COALESCE(t.display_name, ''), c.agent_id::text,
+			c.port, COALESCE(c.server_name, ''), COALESCE(c.subject, ''), COALESCE(c.issuer, ''),
+			c.dns_names, COALESCE(c.serial_number, ''), c.not_before, c.not_after,
+			c.chain_valid, COALESCE(c.verify_error, ''), c.observed_at
+		FROM target_certificates c
+		JOIN targets t ON t.id = c.target_id
+		WHERE t.archived_at IS NULL
+		  AND t.probe_type = $1
+		  AND ($2::int IS NULL OR c.not_after < NOW() + make_interval(days => $2::int))
+		  AND ($3::timestamptz IS NULL OR c.observed_at >= $3)
+		ORDER BY c.not_after, c.target_id
+		LIMIT $4
+	`, types.ProbeTypeTLSCert, filter.WithinDays, observedSince, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing target certificates: %w", err)
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	var certs []types.TargetCertificate
+	for rows.Next() {
+		var c types.TargetCertificate
+		if err := rows.Scan(
+			&c.TargetID, &c.TargetIP, &c.DisplayName, &c.AgentID,
+			&c.Port, &c.ServerName, &c.Subject, &c.Issuer,
+			&c.DNSNames, &c.SerialNumber, &c.NotBefore, &c.NotAfter,
+			&c.ChainValid, &c.VerifyError, &c.ObservedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning target certificate: %w", err)
+		}
+		c.DaysRemaining = types.DaysUntil(c.NotAfter, now)
+		certs = append(certs, c)
+	}
+	return certs, rows.Err()
+}
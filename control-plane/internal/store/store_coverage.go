@@ -0,0 +1,156 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// SUBNET COVERAGE
+// =============================================================================
+
+// GetSubnetTargetCoverage returns assignment and reporting counts for each
+// active, probed target in a subnet. An agent counts as reporting if it sent
+// any result (successful or not) within the window; in-market uses the same
+// region match as ingest. Agents excluded from a target's health and
+// archived agents are not counted. Status is left for the caller.
+func (s *Store) GetSubnetTargetCoverage(ctx context.Context, subnetID string, window time.Duration) ([]types.TargetCoverage, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			t.id, host(t.ip_address), t.tier, tr.region,
+			COALESCE(asg.assigned, 0), COALESCE(asg.assigned_in_market, 0),
+			COALESCE(pr.reporting, 0), COALESCE(pr.reporting_in_market, 0), pr.last_result
+		FROM targets t
+		LEFT JOIN subnets sub ON sub.id = t.subnet_id
+		-- An explicit target region (manual targets) wins over the subnet's
+		CROSS JOIN LATERAL (
+			SELECT NULLIF(LOWER(TRIM(COALESCE(NULLIF(TRIM(t.region), ''), sub.region))), '') AS region
+		) tr
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(*) AS assigned,
+				COUNT(*) FILTER (WHERE LOWER(TRIM(a.region)) = tr.region) AS assigned_in_market
+			FROM target_assignments ta
+			JOIN agents a ON a.id = ta.agent_id
+			WHERE ta.target_id = t.id
+			  AND a.archived_at IS NULL
+			  AND NOT agent_health_excluded(ta.agent_id, ta.target_id)
+		) asg ON true
+		LEFT JOIN LATERAL (
+			SELECT
+				COUNT(DISTINCT p.agent_id) AS reporting,
+				COUNT(DISTINCT p.agent_id) FILTER (WHERE p.is_in_market) AS reporting_in_market,
+				MAX(p.time) AS last_result
+			FROM probe_results p
+			JOIN agents a ON a.id = p.agent_id
+			WHERE p.target_id = t.id
+			  AND p.time > NOW() - $2::interval
+			  AND a.archived_at IS NULL
+			  AND NOT agent_health_excluded(p.agent_id, p.target_id)
+		) pr ON true
+		WHERE t.subnet_id = $1
+		  AND t.archived_at IS NULL
+		  AND t.monitoring_state = 'active'
+		  AND t.probing_enabled
+		ORDER BY t.ip_address
+	`, subnetID, window.String())
+	if err != nil {
+		return nil, fmt.Errorf("querying subnet coverage: %w", err)
+	}
+	defer rows.Close()
+
+	var coverage []types.TargetCoverage
+	for rows.Next() {
+		var c types.TargetCoverage
+		if err := rows.Scan(
+			&c.TargetID, &c.IP, &c.Tier, &c.Region,
+			&c.AssignedAgents, &c.AssignedInMarketAgents,
+			&c.ReportingAgents, &c.ReportingInMarketAgents, &c.LastResultAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning subnet coverage: %w", err)
+		}
+		coverage = append(coverage, c)
+	}
+	return coverage, rows.Err()
+}
+
+// UnderCoveredTarget is an active, probed target with fewer agents reporting
+// than its tier requires.
+type UnderCoveredTarget struct {
+	TargetID  string
+	TargetIP  string
+	Tier      string
+	Assigned  int // non-archived agents assigned and counted for health
+	Reporting int // of those, agents with a result in the window
+	Required  int
+}
+
+// GetUnderCoveredTargets returns active, probed targets where fewer agents
+// reported within the window than the tier requires: the tier's min_agents,
+// or its default minimum when unset, capped at the agents assigned. Reporting
+// counts results of any outcome, so a target that is down but still probed
+// is covered. Agents excluded from a target's health and archived agents are
+// not counted. excludeTargetID skips one target (the pipeline canary, which
+// has its own watchdog).
+func (s *Store) GetUnderCoveredTargets(ctx context.Context, window time.Duration, excludeTargetID string) ([]UnderCoveredTarget, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH asg AS (
+			SELECT ta.target_id, COUNT(*) AS assigned
+			FROM target_assignments ta
+			JOIN agents a ON a.id = ta.agent_id
+			WHERE a.archived_at IS NULL
+			  AND NOT agent_health_excluded(ta.agent_id, ta.target_id)
+			GROUP BY ta.target_id
+		), rep AS (
+			SELECT p.target_id, COUNT(DISTINCT p.agent_id) AS reporting
+			FROM probe_results p
+			JOIN agents a ON a.id = p.agent_id
+			WHERE p.time > NOW() - $1::interval
+			  AND a.archived_at IS NULL
+			  AND NOT agent_health_excluded(p.agent_id, p.target_id)
+			GROUP BY p.target_id
+		)
+		SELECT t.id, host(t.ip_address), t.tier, tr.min_agents, asg.assigned, COALESCE(rep.reporting, 0)
+		FROM targets t
+		JOIN asg ON asg.target_id = t.id
+		LEFT JOIN rep ON rep.target_id = t.id
+		LEFT JOIN tiers tr ON tr.name = t.tier
+		WHERE t.archived_at IS NULL
+		  AND t.monitoring_state = 'active'
+		  AND t.probing_enabled
+		  AND t.id <> $2
+		  AND COALESCE(rep.reporting, 0) < asg.assigned
+	`, window.String(), excludeTargetID)
+	if err != nil {
+		return nil, fmt.Errorf("querying target coverage: %w", err)
+	}
+	defer rows.Close()
+
+	var under []UnderCoveredTarget
+	for rows.Next() {
+		var t UnderCoveredTarget
+		var minAgents *int
+		if err := rows.Scan(&t.TargetID, &t.TargetIP, &t.Tier, &minAgents, &t.Assigned, &t.Reporting); err != nil {
+			return nil, fmt.Errorf("scanning target coverage: %w", err)
+		}
+		t.Required = requiredAgents(t.Tier, minAgents, t.Assigned)
+		if t.Reporting < t.Required {
+			under = append(under, t)
+		}
+	}
+	return under, rows.Err()
+}
+
+// requiredAgents is the number of reporting agents a target needs: the
+// tier's configured minimum, or its default, never more than are assigned.
+// A selection policy that assigns fewer is an assignment problem, not an
+// outage.
+func requiredAgents(tier string, minAgents *int, assigned int) int {
+	if minAgents != nil {
+		return min(*minAgents, assigned)
+	}
+	return getMinAgentsForTier(tier, assigned)
+}
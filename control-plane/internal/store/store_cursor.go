@@ -0,0 +1,273 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// CURSOR (KEYSET) PAGINATION
+// =============================================================================
+//
+// Offset pagination makes Postgres walk and discard every skipped row, so deep
+// pages over hundreds of thousands of targets get steadily slower, and rows
+// inserted mid-iteration shift later pages. Cursor pagination instead resumes
+// from the last row's sort key: WHERE (sort_key) > cursor ORDER BY sort_key.
+//
+// A cursor is an opaque token (base64 of the JSON-encoded sort key). Clients
+// pass "" for the first page and then each response's next_cursor until it
+// comes back empty. Total counts are skipped in cursor mode; counting the
+// whole table is exactly the cost cursor pagination avoids.
+
+// encodeCursor encodes a row's sort key as an opaque cursor token.
+func encodeCursor(key ...string) string {
+	data, _ := json.Marshal(key)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeCursor decodes a cursor token holding n sort-key values.
+func decodeCursor(cursor string, n int) ([]string, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	var key []string
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+	if len(key) != n {
+		return nil, fmt.Errorf("%w: expected %d key values, got %d", ErrInvalidCursor, n, len(key))
+	}
+	return key, nil
+}
+
+// cursorPageLimit applies the list endpoint default and maximum page size.
+func cursorPageLimit(limit int) int {
+	if limit <= 0 {
+		return config.DefaultPaginationLimit
+	}
+	return min(limit, config.MaxPaginationLimit)
+}
+
+// listTargetsByCursor pages targets by ip_address (unique).
+func (s *Store) listTargetsByCursor(ctx context.Context, conditions []string, args []interface{}, params TargetListParams) (*TargetListResult, error) {
+	limit := cursorPageLimit(params.Limit)
+	argNum := len(args) + 1
+
+	if *params.Cursor != "" {
+		key, err := decodeCursor(*params.Cursor, 1)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("ip_address > $%d::inet", argNum))
+		args = append(args, key[0])
+		argNum++
+	}
+
+	whereClause := "1=1"
+	if len(conditions) > 0 {
+		whereClause = strings.Join(conditions, " AND ")
+	}
+
+	// Fetch one extra row to learn whether there is a next page
+	query := fmt.Sprintf(`
+		SELECT
+			id, host(ip_address), tier, subscriber_id, tags, display_name, notes,
+			subnet_id, ownership, origin, ip_type,
+			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
+			first_response_at, baseline_established_at,
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
+		FROM targets
+		WHERE %s
+		ORDER BY ip_address
+		LIMIT $%d
+	`, whereClause, argNum)
+	args = append(args, limit+1)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing targets: %w", err)
+	}
+	defer rows.Close()
+
+	targets, err := s.scanTargets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &TargetListResult{Limit: limit}
+	if len(targets) > limit {
+		targets = targets[:limit]
+		result.NextCursor = encodeCursor(targets[limit-1].IP)
+	}
+	result.Targets = targets
+	return result, nil
+}
+
+// listSubnetsByCursor pages subnets by (network_address, id); network
+// addresses can repeat across archived and active subnets.
+func (s *Store) listSubnetsByCursor(ctx context.Context, conditions []string, args []interface{}, params SubnetListParams) (*SubnetListResult, error) {
+	limit := cursorPageLimit(params.Limit)
+	argNum := len(args) + 1
+
+	if *params.Cursor != "" {
+		key, err := decodeCursor(*params.Cursor, 2)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(network_address, id) > ($%d::inet, $%d::uuid)", argNum, argNum+1))
+		args = append(args, key[0], key[1])
+		argNum += 2
+	}
+
+	whereClause := "1=1"
+	if len(conditions) > 0 {
+		whereClause = strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT
+			id, pilot_subnet_id, network_address::text, network_size,
+			host(gateway_address), host(first_usable_address), host(last_usable_address),
+			vlan_id, service_id, service_status, subscriber_id, subscriber_name,
+			location_id, location_address, city, region, pop_name,
+			gateway_device, state, archived_at, archive_reason,
+			created_at, updated_at
+		FROM subnets
+		WHERE %s
+		ORDER BY network_address, id
+		LIMIT $%d
+	`, whereClause, argNum)
+	args = append(args, limit+1)
+
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing subnets: %w", err)
+	}
+	defer rows.Close()
+
+	subnets, err := s.scanSubnets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SubnetListResult{Limit: limit}
+	if len(subnets) > limit {
+		subnets = subnets[:limit]
+		last := subnets[limit-1]
+		result.NextCursor = encodeCursor(last.NetworkAddress, last.ID)
+	}
+	result.Subnets = subnets
+	return result, nil
+}
+
+// ProbeResultListParams contains parameters for cursor-paginated listing of
+// a target's raw probe results.
+type ProbeResultListParams struct {
+	TargetID string
+	AgentID  string    // Optional
+	Since    time.Time // Optional lower bound (exclusive)
+	Limit    int
+	Cursor   string // "" = newest page
+
+	// Optional filters on typed result columns
+	ProbeType string
+	ReplyTTL  *int
+}
+
+// ProbeResultListResult contains a page of raw probe results, newest first.
+type ProbeResultListResult struct {
+	Results    []types.ProbeResult `json:"results"`
+	Limit      int                 `json:"limit"`
+	NextCursor string              `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// ListProbeResults pages a target's raw probe results newest first by
+// (time, agent_id). Raw results are only ever cursor-paginated: offsets into
+// a hypertable would decompress every skipped chunk.
+func (s *Store) ListProbeResults(ctx context.Context, params ProbeResultListParams) (*ProbeResultListResult, error) {
+	limit := cursorPageLimit(params.Limit)
+
+	conditions := []string{"target_id = $1"}
+	args := []interface{}{params.TargetID}
+	argNum := 2
+
+	if params.AgentID != "" {
+		conditions = append(conditions, fmt.Sprintf("agent_id = $%d", argNum))
+		args = append(args, params.AgentID)
+		argNum++
+	}
+	if !params.Since.IsZero() {
+		conditions = append(conditions, fmt.Sprintf("time > $%d", argNum))
+		args = append(args, params.Since)
+		argNum++
+	}
+	if params.ProbeType != "" {
+		conditions = append(conditions, fmt.Sprintf("probe_type = $%d", argNum))
+		args = append(args, params.ProbeType)
+		argNum++
+	}
+	if params.ReplyTTL != nil {
+		conditions = append(conditions, fmt.Sprintf("reply_ttl = $%d", argNum))
+		args = append(args, *params.ReplyTTL)
+		argNum++
+	}
+	if params.Cursor != "" {
+		key, err := decodeCursor(params.Cursor, 2)
+		if err != nil {
+			return nil, err
+		}
+		ts, err := time.Parse(time.RFC3339Nano, key[0])
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+		}
+		conditions = append(conditions, fmt.Sprintf("(time, agent_id) < ($%d, $%d::uuid)", argNum, argNum+1))
+		args = append(args, ts, key[1])
+		argNum += 2
+	}
+
+	query := fmt.Sprintf(`
+		SELECT time, target_id, agent_id, success, COALESCE(error_message, ''), COALESCE(probe_type, ''), payload
+		FROM probe_results
+		WHERE %s
+		ORDER BY time DESC, agent_id DESC
+		LIMIT $%d
+	`, strings.Join(conditions, " AND "), argNum)
+	args = append(args, limit+1)
+
+	rows, err := s.reader().Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("listing probe results: %w", err)
+	}
+	defer rows.Close()
+
+	var results []types.ProbeResult
+	for rows.Next() {
+		var r types.ProbeResult
+		if err := rows.Scan(&r.Timestamp, &r.TargetID, &r.AgentID, &r.Success, &r.Error, &r.ProbeType, &r.Payload); err != nil {
+			return nil, fmt.Errorf("scanning probe result: %w", err)
+		}
+		results = append(results, r)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := &ProbeResultListResult{Limit: limit}
+	if len(results) > limit {
+		results = results[:limit]
+		last := results[limit-1]
+		result.NextCursor = encodeCursor(last.Timestamp.Format(time.RFC3339Nano), last.AgentID)
+	}
+	result.Results = results
+	return result, nil
+}
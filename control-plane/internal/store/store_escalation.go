@@ -0,0 +1,273 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ALERT ESCALATION POLICIES
+// =============================================================================
+
+const escalationPolicyColumns = `
+	id, name, COALESCE(description, ''), enabled, tiers, regions, steps,
+	created_at, updated_at`
+
+func scanEscalationPolicy(row pgx.Row) (*types.EscalationPolicy, error) {
+	var p types.EscalationPolicy
+	var stepsJSON []byte
+	err := row.Scan(
+		&p.ID, &p.Name, &p.Description, &p.Enabled, &p.Tiers, &p.Regions, &stepsJSON,
+		&p.CreatedAt, &p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(stepsJSON, &p.Steps); err != nil {
+		return nil, fmt.Errorf("decoding steps: %w", err)
+	}
+	return &p, nil
+}
+
+// escalationPolicyError turns a unique violation on name into a readable
+// error.
+func escalationPolicyError(action string, err error) error {
+	if IsUniqueViolation(err) {
+		return fmt.Errorf("escalation policy name %w", ErrConflict)
+	}
+	return fmt.Errorf("%s escalation policy: %w", action, err)
+}
+
+// CreateEscalationPolicy inserts a policy and populates its ID and
+// timestamps.
+func (s *Store) CreateEscalationPolicy(ctx context.Context, p *types.EscalationPolicy) error {
+	stepsJSON, err := json.Marshal(p.Steps)
+	if err != nil {
+		return fmt.Errorf("encoding steps: %w", err)
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		INSERT INTO escalation_policies (name, description, enabled, tiers, regions, steps)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, p.Name, p.Description, p.Enabled, nonNilStrings(p.Tiers), nonNilStrings(p.Regions), stepsJSON,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return escalationPolicyError("inserting", err)
+	}
+	return nil
+}
+
+// GetEscalationPolicy returns a policy by ID, or nil if not found.
+func (s *Store) GetEscalationPolicy(ctx context.Context, id string) (*types.EscalationPolicy, error) {
+	p, err := scanEscalationPolicy(s.pool.QueryRow(ctx,
+		`SELECT `+escalationPolicyColumns+` FROM escalation_policies WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting escalation policy: %w", err)
+	}
+	return p, nil
+}
+
+// ListEscalationPolicies returns policies by name. enabledOnly leaves out
+// disabled policies.
+func (s *Store) ListEscalationPolicies(ctx context.Context, enabledOnly bool) ([]types.EscalationPolicy, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+escalationPolicyColumns+`
+		FROM escalation_policies
+		WHERE enabled OR NOT $1
+		ORDER BY name
+	`, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("listing escalation policies: %w", err)
+	}
+	defer rows.Close()
+
+	var policies []types.EscalationPolicy
+	for rows.Next() {
+		p, err := scanEscalationPolicy(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning escalation policy: %w", err)
+		}
+		policies = append(policies, *p)
+	}
+	return policies, rows.Err()
+}
+
+// UpdateEscalationPolicy replaces a policy's settings and refreshes
+// UpdatedAt. Alerts part way up the ladder continue from their step.
+func (s *Store) UpdateEscalationPolicy(ctx context.Context, p *types.EscalationPolicy) error {
+	stepsJSON, err := json.Marshal(p.Steps)
+	if err != nil {
+		return fmt.Errorf("encoding steps: %w", err)
+	}
+
+	err = s.pool.QueryRow(ctx, `
+		UPDATE escalation_policies SET
+			name = $2,
+			description = NULLIF($3, ''),
+			enabled = $4,
+			tiers = $5,
+			regions = $6,
+			steps = $7,
+			updated_at = NOW()
+		WHERE id = $1
+		RETURNING created_at, updated_at
+	`, p.ID, p.Name, p.Description, p.Enabled, nonNilStrings(p.Tiers), nonNilStrings(p.Regions), stepsJSON,
+	).Scan(&p.CreatedAt, &p.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("escalation policy %w", ErrNotFound)
+	}
+	if err != nil {
+		return escalationPolicyError("updating", err)
+	}
+	return nil
+}
+
+// DeleteEscalationPolicy removes a policy. Alerts it was escalating are
+// picked up by whichever policy covers them next.
+func (s *Store) DeleteEscalationPolicy(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM escalation_policies WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting escalation policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("escalation policy %w", ErrNotFound)
+	}
+	return nil
+}
+
+// ListUnacknowledgedAlerts returns active alerts nobody has acknowledged,
+// oldest first, up to limit, with what escalation needs: target tier,
+// region, escalation state and the fields notifications show.
+func (s *Store) ListUnacknowledgedAlerts(ctx context.Context, limit int) ([]types.Alert, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			a.id, COALESCE(a.target_id::text, ''), host(a.target_ip),
+			a.alert_type, a.severity, a.status,
+			a.initial_severity, a.peak_severity,
+			a.current_latency_ms, a.current_packet_loss,
+			a.title, COALESCE(a.message, ''),
+			a.detected_at, a.last_updated_at,
+			COALESCE(t.tier, ''),
+			COALESCE(a.region, ''), COALESCE(a.city, ''),
+			COALESCE(a.subscriber_name, ''), COALESCE(a.pop_name, ''),
+			COALESCE(a.escalation_policy_id::text, ''), a.escalation_step
+		FROM alerts a
+		LEFT JOIN targets t ON a.target_id = t.id
+		WHERE a.status = 'active' AND a.acknowledged_at IS NULL
+		ORDER BY a.detected_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing unacknowledged alerts: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []types.Alert
+	for rows.Next() {
+		var a types.Alert
+		if err := rows.Scan(
+			&a.ID, &a.TargetID, &a.TargetIP,
+			&a.AlertType, &a.Severity, &a.Status,
+			&a.InitialSeverity, &a.PeakSeverity,
+			&a.CurrentLatencyMs, &a.CurrentPacketLoss,
+			&a.Title, &a.Message,
+			&a.DetectedAt, &a.LastUpdatedAt,
+			&a.TargetTier,
+			&a.Region, &a.City,
+			&a.SubscriberName, &a.PopName,
+			&a.EscalationPolicyID, &a.EscalationStep,
+		); err != nil {
+			return nil, fmt.Errorf("scanning unacknowledged alert: %w", err)
+		}
+		alerts = append(alerts, a)
+	}
+	return alerts, rows.Err()
+}
+
+// AdvanceAlertEscalation records that a policy's steps up to step (1-based)
+// fired for an alert, raising its severity to severity if that is higher,
+// and records an escalated event. It reports false, changing nothing, if
+// the alert was acknowledged, resolved or already past step in the
+// meantime.
+func (s *Store) AdvanceAlertEscalation(ctx context.Context, alertID, policyID string, step int, severity types.AlertSeverity, description string) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var oldSeverity, peakSeverity types.AlertSeverity
+	err = tx.QueryRow(ctx, `
+		SELECT severity, peak_severity FROM alerts
+		WHERE id = $1 AND status = 'active' AND acknowledged_at IS NULL AND escalation_step < $2
+		FOR UPDATE
+	`, alertID, step).Scan(&oldSeverity, &peakSeverity)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("locking alert: %w", err)
+	}
+
+	newSeverity := oldSeverity
+	if severity.Level() > oldSeverity.Level() {
+		newSeverity = severity
+	}
+	newPeak := peakSeverity
+	if newSeverity.Level() > peakSeverity.Level() {
+		newPeak = newSeverity
+	}
+
+	_, err = tx.Exec(ctx, `
+		UPDATE alerts SET
+			severity = $2,
+			peak_severity = $3,
+			escalation_policy_id = $4,
+			escalation_step = $5,
+			last_updated_at = NOW()
+		WHERE id = $1
+	`, alertID, newSeverity, newPeak, policyID, step)
+	if err != nil {
+		return false, fmt.Errorf("updating alert escalation: %w", err)
+	}
+
+	detailsJSON, err := json.Marshal(map[string]any{
+		"escalation_policy_id": policyID,
+		"escalation_step":      step,
+	})
+	if err != nil {
+		return false, fmt.Errorf("encoding event details: %w", err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO alert_events (
+			alert_id, event_type,
+			old_severity, new_severity,
+			description, details, triggered_by
+		) VALUES ($1, 'escalated', $2, $3, $4, $5, 'escalation_policy')
+	`, alertID, oldSeverity, newSeverity, description, detailsJSON)
+	if err != nil {
+		return false, fmt.Errorf("recording escalation event: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("committing escalation: %w", err)
+	}
+	return true, nil
+}
+
+// nonNilStrings keeps a nil list from being stored as NULL in a NOT NULL
+// array column.
+func nonNilStrings(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
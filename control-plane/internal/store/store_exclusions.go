@@ -0,0 +1,116 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// AGENT HEALTH EXCLUSIONS
+// =============================================================================
+//
+// Queries that compute health leave excluded pairs out with
+// agent_health_excluded(agent_id, target_id), defined in migration 035.
+
+const healthExclusionColumns = `
+	id, agent_id, COALESCE(target_id::text, ''), COALESCE(subnet_id::text, ''),
+	COALESCE(reason, ''), COALESCE(created_by, ''), created_at, updated_at`
+
+func scanHealthExclusion(row pgx.Row) (*types.AgentHealthExclusion, error) {
+	var e types.AgentHealthExclusion
+	err := row.Scan(
+		&e.ID, &e.AgentID, &e.TargetID, &e.SubnetID,
+		&e.Reason, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// CreateHealthExclusion inserts an exclusion and populates its ID and timestamps.
+func (s *Store) CreateHealthExclusion(ctx context.Context, e *types.AgentHealthExclusion) error {
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO agent_health_exclusions (agent_id, target_id, subnet_id, reason, created_by)
+		VALUES ($1, NULLIF($2, '')::uuid, NULLIF($3, '')::uuid, NULLIF($4, ''), NULLIF($5, ''))
+		ON CONFLICT DO NOTHING
+		RETURNING id, created_at, updated_at
+	`, e.AgentID, e.TargetID, e.SubnetID, e.Reason, e.CreatedBy).Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("health exclusion %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting health exclusion: %w", err)
+	}
+	return nil
+}
+
+// GetHealthExclusion returns an exclusion by ID, or nil if not found.
+func (s *Store) GetHealthExclusion(ctx context.Context, id string) (*types.AgentHealthExclusion, error) {
+	e, err := scanHealthExclusion(s.pool.QueryRow(ctx,
+		`SELECT `+healthExclusionColumns+` FROM agent_health_exclusions WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting health exclusion: %w", err)
+	}
+	return e, nil
+}
+
+// ListHealthExclusions returns exclusions, newest first, optionally for one agent.
+func (s *Store) ListHealthExclusions(ctx context.Context, agentID string) ([]types.AgentHealthExclusion, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+healthExclusionColumns+`
+		FROM agent_health_exclusions
+		WHERE $1 = '' OR agent_id = NULLIF($1, '')::uuid
+		ORDER BY created_at DESC
+	`, agentID)
+	if err != nil {
+		return nil, fmt.Errorf("listing health exclusions: %w", err)
+	}
+	defer rows.Close()
+
+	var exclusions []types.AgentHealthExclusion
+	for rows.Next() {
+		e, err := scanHealthExclusion(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning health exclusion: %w", err)
+		}
+		exclusions = append(exclusions, *e)
+	}
+	return exclusions, rows.Err()
+}
+
+// UpdateHealthExclusionReason changes an exclusion's reason. The pair it
+// covers is fixed; delete and recreate to change it.
+func (s *Store) UpdateHealthExclusionReason(ctx context.Context, id, reason string) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE agent_health_exclusions
+		SET reason = NULLIF($2, ''), updated_at = NOW()
+		WHERE id = $1
+	`, id, reason)
+	if err != nil {
+		return fmt.Errorf("updating health exclusion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("health exclusion %w", ErrNotFound)
+	}
+	return nil
+}
+
+// DeleteHealthExclusion removes an exclusion. The evaluator picks the pair
+// back up on its next cycle.
+func (s *Store) DeleteHealthExclusion(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM agent_health_exclusions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting health exclusion: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("health exclusion %w", ErrNotFound)
+	}
+	return nil
+}
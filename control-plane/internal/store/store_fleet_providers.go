@@ -0,0 +1,105 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// PROVIDER HEALTH
+// =============================================================================
+
+// ProviderAgentStats holds one agent's raw sums over a window, so they can
+// be added up per provider.
+type ProviderAgentStats struct {
+	AgentID  string
+	Provider *string
+	Online   bool
+
+	// SampledBuckets counts the buckets with at least one heartbeat;
+	// ExpectedBuckets those since the later of window start and creation.
+	SampledBuckets  int64
+	ExpectedBuckets int64
+
+	CPUSum      float64
+	CPUCount    int64
+	MemorySum   float64
+	MemoryCount int64
+
+	ProbeCount   int64
+	SuccessCount int64
+	// LatencySum and LossSum are probe_hourly averages weighted by the
+	// successful and total probe counts respectively.
+	LatencySum    float64
+	LatencyWeight int64
+	LossSum       float64
+	LossWeight    int64
+}
+
+// GetProviderAgentStats returns heartbeat, resource and probe sums for every
+// non-archived agent over window. Heartbeats are counted per uptimeBucket;
+// probes come from probe_hourly and skip the pipeline canary, whose loopback
+// latency says nothing about the provider's network.
+func (s *Store) GetProviderAgentStats(ctx context.Context, window, uptimeBucket, onlineWindow time.Duration) ([]ProviderAgentStats, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH metrics AS (
+			SELECT agent_id,
+			       COUNT(DISTINCT time_bucket($2::interval, time)) AS sampled,
+			       COALESCE(SUM(cpu_percent), 0) AS cpu_sum, COUNT(cpu_percent) AS cpu_count,
+			       COALESCE(SUM(memory_mb), 0) AS memory_sum, COUNT(memory_mb) AS memory_count
+			FROM agent_metrics
+			WHERE time > NOW() - $1::interval
+			GROUP BY agent_id
+		), probes AS (
+			SELECT agent_id,
+			       SUM(probe_count)::bigint AS probe_count, SUM(success_count)::bigint AS success_count,
+			       COALESCE(SUM(avg_latency * success_count) FILTER (WHERE avg_latency IS NOT NULL), 0) AS latency_sum,
+			       COALESCE(SUM(success_count) FILTER (WHERE avg_latency IS NOT NULL), 0)::bigint AS latency_weight,
+			       COALESCE(SUM(avg_packet_loss * probe_count) FILTER (WHERE avg_packet_loss IS NOT NULL), 0) AS loss_sum,
+			       COALESCE(SUM(probe_count) FILTER (WHERE avg_packet_loss IS NOT NULL), 0)::bigint AS loss_weight
+			FROM probe_hourly
+			WHERE bucket > NOW() - $1::interval
+			  AND target_id <> $4
+			GROUP BY agent_id
+		)
+		SELECT
+			ag.id::text, NULLIF(TRIM(ag.provider), ''),
+			COALESCE(ag.last_heartbeat > NOW() - $3::interval, false),
+			COALESCE(m.sampled, 0),
+			CEIL(EXTRACT(EPOCH FROM NOW() - GREATEST(NOW() - $1::interval, ag.created_at))
+			     / EXTRACT(EPOCH FROM $2::interval))::bigint,
+			COALESCE(m.cpu_sum, 0), COALESCE(m.cpu_count, 0),
+			COALESCE(m.memory_sum, 0), COALESCE(m.memory_count, 0),
+			COALESCE(p.probe_count, 0), COALESCE(p.success_count, 0),
+			COALESCE(p.latency_sum, 0), COALESCE(p.latency_weight, 0),
+			COALESCE(p.loss_sum, 0), COALESCE(p.loss_weight, 0)
+		FROM agents ag
+		LEFT JOIN metrics m ON m.agent_id = ag.id
+		LEFT JOIN probes p ON p.agent_id = ag.id
+		WHERE ag.archived_at IS NULL
+		ORDER BY ag.name
+	`, window.String(), uptimeBucket.String(), onlineWindow.String(), types.CanaryTargetID)
+	if err != nil {
+		return nil, fmt.Errorf("querying provider agent stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []ProviderAgentStats
+	for rows.Next() {
+		var a ProviderAgentStats
+		if err := rows.Scan(
+			&a.AgentID, &a.Provider, &a.Online,
+			&a.SampledBuckets, &a.ExpectedBuckets,
+			&a.CPUSum, &a.CPUCount, &a.MemorySum, &a.MemoryCount,
+			&a.ProbeCount, &a.SuccessCount,
+			&a.LatencySum, &a.LatencyWeight, &a.LossSum, &a.LossWeight,
+		); err != nil {
+			return nil, fmt.Errorf("scanning provider agent stats: %w", err)
+		}
+		stats = append(stats, a)
+	}
+	return stats, rows.Err()
+}
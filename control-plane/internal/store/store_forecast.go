@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// LATENCY FORECAST HISTORY
+// =============================================================================
+
+// HourlyLatency is a target's average latency across agents for one hour.
+type HourlyLatency struct {
+	Bucket       time.Time
+	AvgLatencyMs float64
+}
+
+// GetTargetHourlyLatency returns a target's hourly average latency over the
+// lookback, oldest first. Agents excluded from the target's health are left
+// out, as are hours with no successful probes.
+func (s *Store) GetTargetHourlyLatency(ctx context.Context, targetID string, lookback time.Duration) ([]HourlyLatency, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT ph.bucket, avg(ph.avg_latency)::DOUBLE PRECISION
+		FROM probe_hourly ph
+		WHERE ph.target_id = $1
+		  AND ph.bucket > NOW() - $2::interval
+		  AND ph.avg_latency IS NOT NULL
+		  AND NOT agent_health_excluded(ph.agent_id, ph.target_id)
+		GROUP BY ph.bucket
+		ORDER BY ph.bucket
+	`, targetID, lookback.String())
+	if err != nil {
+		return nil, fmt.Errorf("querying hourly latency: %w", err)
+	}
+	defer rows.Close()
+
+	var samples []HourlyLatency
+	for rows.Next() {
+		var h HourlyLatency
+		if err := rows.Scan(&h.Bucket, &h.AvgLatencyMs); err != nil {
+			return nil, fmt.Errorf("scanning hourly latency: %w", err)
+		}
+		samples = append(samples, h)
+	}
+	return samples, rows.Err()
+}
@@ -0,0 +1,163 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// INCIDENT AUTO-RESOLUTION
+// =============================================================================
+
+// ResolveIncidentsBySubnet resolves open incidents on the subnet's targets
+// once none of their affected targets is monitored any more: each is
+// archived, inactive, excluded or deleted. An incident that also covers a
+// live target elsewhere stays open. Each resolution records the reason as
+// an incident event. Returns the number resolved.
+func (s *Store) ResolveIncidentsBySubnet(ctx context.Context, subnetID string, reason string) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	rows, err := tx.Query(ctx, `
+		UPDATE incidents i SET
+			status = 'resolved',
+			resolved_at = NOW(),
+			updated_at = NOW()
+		WHERE i.status != 'resolved'
+		  AND EXISTS (
+			SELECT 1 FROM targets t
+			WHERE t.id = ANY(i.affected_target_ids) AND t.subnet_id = $1
+		  )
+		  AND NOT EXISTS (
+			SELECT 1 FROM targets t
+			WHERE t.id = ANY(i.affected_target_ids)
+			  AND t.archived_at IS NULL
+			  AND t.monitoring_state NOT IN ('inactive', 'excluded')
+		  )
+		RETURNING i.id, i.incident_type, i.severity, i.resolved_at
+	`, subnetID)
+	if err != nil {
+		return 0, fmt.Errorf("resolving subnet incidents: %w", err)
+	}
+
+	type resolved struct {
+		id, incidentType, severity string
+		resolvedAt                 time.Time
+	}
+	var incidents []resolved
+	for rows.Next() {
+		var r resolved
+		if err := rows.Scan(&r.id, &r.incidentType, &r.severity, &r.resolvedAt); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning resolved incident: %w", err)
+		}
+		incidents = append(incidents, r)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("resolving subnet incidents: %w", err)
+	}
+
+	for _, inc := range incidents {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO incident_events (incident_id, event_type, description, details, created_by)
+			VALUES ($1, 'resolved', $2, jsonb_build_object('subnet_id', $3::text), 'sync')
+		`, inc.id, reason, subnetID)
+		if err != nil {
+			return 0, fmt.Errorf("recording incident resolution: %w", err)
+		}
+
+		err = recordEvent(ctx, tx, types.EventIncidentResolved, inc.id, map[string]any{
+			"incident_id":   inc.id,
+			"incident_type": inc.incidentType,
+			"severity":      inc.severity,
+			"resolved_at":   inc.resolvedAt,
+			"reason":        reason,
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return len(incidents), nil
+}
+
+// =============================================================================
+// INCIDENT PURGE
+// =============================================================================
+
+// DeleteIncident permanently removes a resolved incident, for incidents
+// raised by testing or a bug. Its alerts are kept but unlinked, each with an
+// unlinked_from_incident event; its incident events go with it. It fails
+// with IncidentOpenError unless the incident is resolved. Returns the number
+// of alerts unlinked.
+func (s *Store) DeleteIncident(ctx context.Context, id string) (int, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM incidents WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if err == pgx.ErrNoRows {
+		return 0, fmt.Errorf("incident %w", ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("locking incident: %w", err)
+	}
+	if status != "resolved" {
+		return 0, &IncidentOpenError{ID: id, Status: status}
+	}
+
+	rows, err := tx.Query(ctx, `
+		UPDATE alerts SET incident_id = NULL, last_updated_at = NOW()
+		WHERE incident_id = $1
+		RETURNING id
+	`, id)
+	if err != nil {
+		return 0, fmt.Errorf("unlinking incident alerts: %w", err)
+	}
+	var alertIDs []string
+	for rows.Next() {
+		var alertID string
+		if err := rows.Scan(&alertID); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning unlinked alert: %w", err)
+		}
+		alertIDs = append(alertIDs, alertID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("unlinking incident alerts: %w", err)
+	}
+
+	for _, alertID := range alertIDs {
+		_, err = tx.Exec(ctx, `
+			INSERT INTO alert_events (alert_id, event_type, description, details, triggered_by)
+			VALUES ($1, 'unlinked_from_incident', $2, jsonb_build_object('incident_id', $3::text), 'api')
+		`, alertID, fmt.Sprintf("Unlinked from deleted incident %s", id), id)
+		if err != nil {
+			return 0, fmt.Errorf("recording alert unlink: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM incidents WHERE id = $1`, id); err != nil {
+		return 0, fmt.Errorf("deleting incident: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing incident delete: %w", err)
+	}
+	return len(alertIDs), nil
+}
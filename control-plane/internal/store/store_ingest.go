@@ -0,0 +1,109 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// INGESTION SEQUENCES
+// =============================================================================
+
+// ClaimBatchSequence runs ingest for an agent's batch unless seq is at or
+// below the highest sequence already accepted from the agent, in which case
+// it reports a duplicate without calling ingest.
+//
+// The check and the advance are one conditional upsert, held in a
+// transaction until ingest returns: the mark is committed only if ingest
+// succeeds. Another instance handling a retry of the same batch blocks on the
+// agent's row meanwhile, then sees a duplicate if this ingest committed or
+// claims the sequence itself if it rolled back.
+func (s *Store) ClaimBatchSequence(ctx context.Context, agentID string, seq int64, ingest func() error) (duplicate bool, err error) {
+	// A claim holds its connection while ingest takes another, so claims are
+	// limited to half the pool and can never starve ingest of connections.
+	slots := s.claimSlots()
+	select {
+	case slots <- struct{}{}:
+		defer func() { <-slots }()
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// When seq is not above the mark the update is skipped and nothing is
+	// returned; the row is locked either way.
+	var claimed int64
+	err = tx.QueryRow(ctx, `
+		INSERT INTO agent_batch_sequences (agent_id, last_sequence, updated_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (agent_id) DO UPDATE SET
+			last_sequence = EXCLUDED.last_sequence,
+			updated_at = NOW()
+		WHERE agent_batch_sequences.last_sequence < EXCLUDED.last_sequence
+		RETURNING last_sequence
+	`, agentID, seq).Scan(&claimed)
+	if err == pgx.ErrNoRows {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("claiming batch sequence: %w", err)
+	}
+
+	if err := ingest(); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return false, fmt.Errorf("committing batch sequence: %w", err)
+	}
+	return false, nil
+}
+
+// claimSlots returns the semaphore bounding open ClaimBatchSequence
+// transactions to half the pool.
+func (s *Store) claimSlots() chan struct{} {
+	s.claimsOnce.Do(func() {
+		s.claims = make(chan struct{}, max(int(s.pool.Config().MaxConns)/2, 1))
+	})
+	return s.claims
+}
+
+// =============================================================================
+// INGESTION VALIDATION
+// =============================================================================
+
+// AgentExists reports whether an agent with the given ID is registered.
+func (s *Store) AgentExists(ctx context.Context, agentID string) (bool, error) {
+	var exists bool
+	err := s.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM agents WHERE id = $1::uuid)`, agentID).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("checking agent: %w", err)
+	}
+	return exists, nil
+}
+
+// ExistingTargetIDs returns which of the given target IDs exist. IDs must
+// be valid UUIDs.
+func (s *Store) ExistingTargetIDs(ctx context.Context, targetIDs []string) (map[string]bool, error) {
+	rows, err := s.pool.Query(ctx, `SELECT id::text FROM targets WHERE id = ANY($1::uuid[])`, targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("querying targets: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool, len(targetIDs))
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning target id: %w", err)
+		}
+		existing[id] = true
+	}
+	return existing, rows.Err()
+}
@@ -0,0 +1,79 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// LATENCY ASYMMETRY
+// =============================================================================
+
+// GetRegionLatencies returns, per target, the median current latency of the
+// agents in each region that probed it within window, fastest region first.
+// Agents without a region, and pairs the evaluator has marked down, are
+// left out. A nil targetIDs covers every active, probed target.
+func (s *Store) GetRegionLatencies(ctx context.Context, targetIDs []string, window time.Duration) (map[string][]types.RegionLatency, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			ats.target_id::text,
+			LOWER(TRIM(a.region)) AS region,
+			COUNT(*),
+			percentile_cont(0.5) WITHIN GROUP (ORDER BY ats.current_latency_ms) AS latency_ms
+		FROM agent_target_state ats
+		JOIN agents a ON a.id = ats.agent_id
+		JOIN targets t ON t.id = ats.target_id
+		WHERE ats.last_probe_time > $1
+		  AND ats.current_latency_ms IS NOT NULL
+		  AND ats.status <> 'down'
+		  AND COALESCE(TRIM(a.region), '') <> ''
+		  AND t.archived_at IS NULL
+		  AND t.probing_enabled
+		  AND ($2::uuid[] IS NULL OR ats.target_id = ANY($2::uuid[]))
+		GROUP BY ats.target_id, LOWER(TRIM(a.region))
+		ORDER BY ats.target_id, latency_ms
+	`, time.Now().Add(-window), targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("getting region latencies: %w", err)
+	}
+	defer rows.Close()
+
+	byTarget := make(map[string][]types.RegionLatency)
+	for rows.Next() {
+		var targetID string
+		var r types.RegionLatency
+		if err := rows.Scan(&targetID, &r.Region, &r.Agents, &r.LatencyMs); err != nil {
+			return nil, fmt.Errorf("scanning region latency: %w", err)
+		}
+		byTarget[targetID] = append(byTarget[targetID], r)
+	}
+	return byTarget, rows.Err()
+}
+
+// GetLatencyAsymmetryThresholds reads the asymmetry thresholds from
+// alert_config, falling back to the defaults for missing or invalid values.
+func (s *Store) GetLatencyAsymmetryThresholds(ctx context.Context) (types.LatencyAsymmetryThresholds, error) {
+	th := types.LatencyAsymmetryThresholds{
+		Factor:     config.LatencyAsymmetryFactor,
+		MinDeltaMs: config.LatencyAsymmetryMinDeltaMs,
+	}
+	factor, err := s.GetAlertConfigFloat(ctx, "latency_asymmetry_factor", th.Factor)
+	if err != nil {
+		return th, fmt.Errorf("reading latency_asymmetry_factor: %w", err)
+	}
+	if factor > 1 {
+		th.Factor = factor
+	}
+	delta, err := s.GetAlertConfigFloat(ctx, "latency_asymmetry_min_delta_ms", th.MinDeltaMs)
+	if err != nil {
+		return th, fmt.Errorf("reading latency_asymmetry_min_delta_ms: %w", err)
+	}
+	if delta >= 0 {
+		th.MinDeltaMs = delta
+	}
+	return th, nil
+}
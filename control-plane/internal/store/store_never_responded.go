@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// NEVER-RESPONDED ARCHIVAL
+// =============================================================================
+
+// GetTargetsNeverResponded returns up to limit unarchived auto-owned targets,
+// created before cutoff, that have never answered a probe despite at least
+// minAttempts failed discovery probes. Oldest first, so a backlog drains in
+// creation order.
+func (s *Store) GetTargetsNeverResponded(ctx context.Context, cutoff time.Time, minAttempts, limit int) ([]types.Target, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			t.id, host(t.ip_address), t.tier, t.subscriber_id, t.tags, t.display_name, t.notes,
+			t.subnet_id, t.ownership, t.origin, t.ip_type,
+			t.monitoring_state, t.state_changed_at, t.needs_review, t.discovery_attempts, t.last_response_at,
+			t.first_response_at, t.baseline_established_at,
+			t.archived_at, t.archive_reason, t.expected_outcome, t.created_at, t.updated_at, t.is_representative,
+			t.dscp, t.region, t.retention_days, t.probing_enabled, t.probing_changed_at,
+			t.probe_type, t.probe_params, t.down_threshold_seconds
+		FROM targets t
+		WHERE t.ownership = 'auto'
+		  AND t.archived_at IS NULL
+		  AND t.monitoring_state IN ('unknown', 'unresponsive')
+		  AND t.first_response_at IS NULL
+		  AND t.last_response_at IS NULL
+		  AND t.discovery_attempts >= $1
+		  AND t.created_at < $2
+		ORDER BY t.created_at ASC
+		LIMIT $3
+	`, minAttempts, cutoff, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	return s.scanTargets(rows)
+}
@@ -0,0 +1,262 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// EVENT OUTBOX
+// =============================================================================
+
+// outboxSequencerLock is the advisory lock key that serializes sequencing
+// across control plane instances.
+const outboxSequencerLock int64 = 0x6f7574626f78 // "outbox"
+
+// recordEvent appends a domain event to the outbox inside tx, so it is
+// published only if the change it describes commits.
+func recordEvent(ctx context.Context, tx pgx.Tx, eventType, entityID string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encoding %s event: %w", eventType, err)
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox_events (event_type, entity_id, payload) VALUES ($1, $2, $3)
+	`, eventType, entityID, data)
+	if err != nil {
+		return fmt.Errorf("recording %s event: %w", eventType, err)
+	}
+	return nil
+}
+
+// SequenceEvents assigns stream positions to up to limit committed events
+// that don't have one yet. Sequencing runs under an advisory lock and
+// commits before the next run starts, so every seq handed out is higher
+// than any already visible. Returns the number sequenced.
+func (s *Store) SequenceEvents(ctx context.Context, limit int) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `SELECT pg_advisory_xact_lock($1)`, outboxSequencerLock); err != nil {
+		return 0, fmt.Errorf("locking sequencer: %w", err)
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE outbox_events o SET seq = nextval('outbox_event_seq')
+		FROM (
+			SELECT id FROM outbox_events WHERE seq IS NULL ORDER BY id LIMIT $1
+		) pending
+		WHERE o.id = pending.id
+	`, limit)
+	if err != nil {
+		return 0, fmt.Errorf("sequencing events: %w", err)
+	}
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing sequenced events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// ListEvents returns up to limit sequenced events after since, in stream
+// order. eventTypes, when non-empty, limits the types returned.
+func (s *Store) ListEvents(ctx context.Context, since int64, eventTypes []string, limit int) ([]types.Event, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT seq, event_type, entity_id, payload, occurred_at
+		FROM outbox_events
+		WHERE seq > $1
+		  AND (cardinality($2::text[]) = 0 OR event_type = ANY($2))
+		ORDER BY seq
+		LIMIT $3
+	`, since, eventTypes, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []types.Event
+	for rows.Next() {
+		var e types.Event
+		if err := rows.Scan(&e.Seq, &e.Type, &e.EntityID, &e.Payload, &e.OccurredAt); err != nil {
+			return nil, fmt.Errorf("scanning event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// LatestEventSeq returns the highest sequenced position, or 0 if none.
+func (s *Store) LatestEventSeq(ctx context.Context) (int64, error) {
+	var seq int64
+	err := s.pool.QueryRow(ctx, `SELECT COALESCE(MAX(seq), 0) FROM outbox_events`).Scan(&seq)
+	if err != nil {
+		return 0, fmt.Errorf("getting latest event seq: %w", err)
+	}
+	return seq, nil
+}
+
+// PruneEvents deletes sequenced events older than retention. Returns the
+// number deleted.
+func (s *Store) PruneEvents(ctx context.Context, retention time.Duration) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM outbox_events
+		WHERE seq IS NOT NULL AND occurred_at < NOW() - $1::interval
+	`, retention.String())
+	if err != nil {
+		return 0, fmt.Errorf("pruning events: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// =============================================================================
+// EVENT CONSUMERS
+// =============================================================================
+
+const eventConsumerColumns = `
+	name, kind, url, event_types, enabled, last_seq, last_delivered_at,
+	consecutive_failures, COALESCE(last_error, ''), next_attempt_at, created_at, updated_at`
+
+func scanEventConsumer(row pgx.Row) (*types.EventConsumer, error) {
+	var c types.EventConsumer
+	err := row.Scan(
+		&c.Name, &c.Kind, &c.URL, &c.EventTypes, &c.Enabled, &c.LastSeq, &c.LastDeliveredAt,
+		&c.ConsecutiveFailures, &c.LastError, &c.NextAttemptAt, &c.CreatedAt, &c.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// CreateEventConsumer inserts a consumer starting after c.LastSeq and
+// populates its timestamps.
+func (s *Store) CreateEventConsumer(ctx context.Context, c *types.EventConsumer) error {
+	if c.EventTypes == nil {
+		c.EventTypes = []string{}
+	}
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO event_consumers (name, kind, url, event_types, enabled, last_seq)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING created_at, updated_at
+	`, c.Name, c.Kind, c.URL, c.EventTypes, c.Enabled, c.LastSeq).Scan(&c.CreatedAt, &c.UpdatedAt)
+	if IsUniqueViolation(err) {
+		return fmt.Errorf("event consumer %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting event consumer: %w", err)
+	}
+	return nil
+}
+
+// GetEventConsumer returns a consumer by name, or nil if not found.
+func (s *Store) GetEventConsumer(ctx context.Context, name string) (*types.EventConsumer, error) {
+	c, err := scanEventConsumer(s.pool.QueryRow(ctx,
+		`SELECT `+eventConsumerColumns+` FROM event_consumers WHERE name = $1`, name))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting event consumer: %w", err)
+	}
+	return c, nil
+}
+
+// ListEventConsumers returns consumers by name. enabledOnly leaves out
+// disabled ones.
+func (s *Store) ListEventConsumers(ctx context.Context, enabledOnly bool) ([]types.EventConsumer, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+eventConsumerColumns+`
+		FROM event_consumers
+		WHERE enabled OR NOT $1
+		ORDER BY name
+	`, enabledOnly)
+	if err != nil {
+		return nil, fmt.Errorf("listing event consumers: %w", err)
+	}
+	defer rows.Close()
+
+	var consumers []types.EventConsumer
+	for rows.Next() {
+		c, err := scanEventConsumer(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning event consumer: %w", err)
+		}
+		consumers = append(consumers, *c)
+	}
+	return consumers, rows.Err()
+}
+
+// DeleteEventConsumer removes a consumer.
+func (s *Store) DeleteEventConsumer(ctx context.Context, name string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM event_consumers WHERE name = $1`, name)
+	if err != nil {
+		return fmt.Errorf("deleting event consumer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("event consumer %w", ErrNotFound)
+	}
+	return nil
+}
+
+// SeekEventConsumer moves a consumer's offset, to replay from an earlier
+// position or skip ahead, and clears its backoff so delivery resumes now.
+func (s *Store) SeekEventConsumer(ctx context.Context, name string, seq int64) error {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE event_consumers SET
+			last_seq = $2,
+			consecutive_failures = 0,
+			next_attempt_at = NULL,
+			updated_at = NOW()
+		WHERE name = $1
+	`, name, seq)
+	if err != nil {
+		return fmt.Errorf("seeking event consumer: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("event consumer %w", ErrNotFound)
+	}
+	return nil
+}
+
+// AdvanceEventConsumer records that a consumer accepted events up to seq.
+// It only moves the offset from expected, the offset the delivery started
+// at, so a seek (or another instance's delivery) in the meantime isn't
+// overwritten. Reports whether the offset moved.
+func (s *Store) AdvanceEventConsumer(ctx context.Context, name string, expected, seq int64, delivered bool) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE event_consumers SET
+			last_seq = $3,
+			last_delivered_at = CASE WHEN $4 THEN NOW() ELSE last_delivered_at END,
+			consecutive_failures = 0,
+			last_error = NULL,
+			next_attempt_at = NULL,
+			updated_at = NOW()
+		WHERE name = $1 AND last_seq = $2
+	`, name, expected, seq, delivered)
+	if err != nil {
+		return false, fmt.Errorf("advancing event consumer: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RecordEventConsumerFailure records a failed delivery and when to retry.
+func (s *Store) RecordEventConsumerFailure(ctx context.Context, name, errText string, nextAttempt time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE event_consumers SET
+			consecutive_failures = consecutive_failures + 1,
+			last_error = $2,
+			next_attempt_at = $3,
+			updated_at = NOW()
+		WHERE name = $1
+	`, name, errText, nextAttempt)
+	if err != nil {
+		return fmt.Errorf("recording event consumer failure: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,233 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// HOP COUNT / ROUTE CHANGES
+// =============================================================================
+
+// ReplyTTLObservation is the median reply TTL an agent saw from a target
+// over the observation window.
+type ReplyTTLObservation struct {
+	TargetID   string
+	AgentID    string
+	TargetIP   string
+	ReplyTTL   int
+	Samples    int
+	ObservedAt time.Time
+}
+
+// TargetPathState is the last settled reply TTL and hop count for an
+// agent/target pair.
+type TargetPathState struct {
+	TargetID   string
+	AgentID    string
+	ReplyTTL   int
+	HopCount   int
+	ObservedAt time.Time
+	ChangedAt  time.Time
+}
+
+// GetReplyTTLObservations returns the median reply TTL per agent/target pair
+// over the last window, for pairs with at least minSamples TTL-bearing
+// results. The median keeps a handful of replies over an alternate path
+// (ECMP, a flapping link) from registering as a change.
+func (s *Store) GetReplyTTLObservations(ctx context.Context, window time.Duration, minSamples int) ([]ReplyTTLObservation, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT
+			pr.target_id,
+			pr.agent_id,
+			host(t.ip_address),
+			percentile_disc(0.5) WITHIN GROUP (ORDER BY pr.reply_ttl)::int,
+			COUNT(*),
+			MAX(pr.time)
+		FROM probe_results pr
+		JOIN targets t ON t.id = pr.target_id
+		WHERE pr.time > NOW() - $1::interval
+		  AND pr.reply_ttl IS NOT NULL
+		  AND t.archived_at IS NULL
+		  AND t.probing_enabled
+		GROUP BY pr.target_id, pr.agent_id, t.ip_address
+		HAVING COUNT(*) >= $2
+	`, window, minSamples)
+	if err != nil {
+		return nil, fmt.Errorf("querying reply TTLs: %w", err)
+	}
+	defer rows.Close()
+
+	var observations []ReplyTTLObservation
+	for rows.Next() {
+		var o ReplyTTLObservation
+		if err := rows.Scan(&o.TargetID, &o.AgentID, &o.TargetIP, &o.ReplyTTL, &o.Samples, &o.ObservedAt); err != nil {
+			return nil, fmt.Errorf("scanning reply TTL: %w", err)
+		}
+		observations = append(observations, o)
+	}
+	return observations, rows.Err()
+}
+
+// ListTargetPathStates returns the settled path state for every pair.
+func (s *Store) ListTargetPathStates(ctx context.Context) ([]TargetPathState, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT target_id, agent_id, reply_ttl, hop_count, observed_at, changed_at
+		FROM target_path_state
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("querying path state: %w", err)
+	}
+	defer rows.Close()
+
+	var states []TargetPathState
+	for rows.Next() {
+		var st TargetPathState
+		if err := rows.Scan(&st.TargetID, &st.AgentID, &st.ReplyTTL, &st.HopCount, &st.ObservedAt, &st.ChangedAt); err != nil {
+			return nil, fmt.Errorf("scanning path state: %w", err)
+		}
+		states = append(states, st)
+	}
+	return states, rows.Err()
+}
+
+// UpsertTargetPathStates writes path states in a single statement.
+func (s *Store) UpsertTargetPathStates(ctx context.Context, states []TargetPathState) error {
+	if len(states) == 0 {
+		return nil
+	}
+
+	targetIDs := make([]string, len(states))
+	agentIDs := make([]string, len(states))
+	ttls := make([]int16, len(states))
+	hops := make([]int16, len(states))
+	observedAt := make([]time.Time, len(states))
+	changedAt := make([]time.Time, len(states))
+	for i, st := range states {
+		targetIDs[i] = st.TargetID
+		agentIDs[i] = st.AgentID
+		ttls[i] = int16(st.ReplyTTL)
+		hops[i] = int16(st.HopCount)
+		observedAt[i] = st.ObservedAt
+		changedAt[i] = st.ChangedAt
+	}
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO target_path_state (target_id, agent_id, reply_ttl, hop_count, observed_at, changed_at)
+		SELECT * FROM unnest($1::uuid[], $2::uuid[], $3::smallint[], $4::smallint[], $5::timestamptz[], $6::timestamptz[])
+		ON CONFLICT (target_id, agent_id) DO UPDATE SET
+			reply_ttl = EXCLUDED.reply_ttl,
+			hop_count = EXCLUDED.hop_count,
+			observed_at = EXCLUDED.observed_at,
+			changed_at = EXCLUDED.changed_at
+	`, targetIDs, agentIDs, ttls, hops, observedAt, changedAt)
+	if err != nil {
+		return fmt.Errorf("upserting path state: %w", err)
+	}
+	return nil
+}
+
+// RecordHopCountChange stores a detected hop-count change and notes it in
+// the target's activity log.
+func (s *Store) RecordHopCountChange(ctx context.Context, change *types.HopCountChange, targetIP string) error {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO hop_count_changes (
+			target_id, agent_id, previous_ttl, current_ttl, previous_hops, current_hops, alert_id
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, detected_at
+	`, change.TargetID, change.AgentID, change.PreviousTTL, change.CurrentTTL,
+		change.PreviousHops, change.CurrentHops, change.AlertID).Scan(&change.ID, &change.DetectedAt)
+	if err != nil {
+		return fmt.Errorf("inserting hop count change: %w", err)
+	}
+
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"previous_hops": change.PreviousHops,
+		"current_hops":  change.CurrentHops,
+		"previous_ttl":  change.PreviousTTL,
+		"current_ttl":   change.CurrentTTL,
+	})
+	_, err = tx.Exec(ctx, `
+		INSERT INTO activity_log (
+			target_id, agent_id, ip, category, event_type, details, triggered_by, severity
+		) VALUES ($1, $2, $3::inet, 'target', 'route_change', $4, 'route_worker', 'info')
+	`, change.TargetID, change.AgentID, targetIP, detailsJSON)
+	if err != nil {
+		return fmt.Errorf("logging activity: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetTargetHopHistory returns the median reply TTL per agent per bucket for
+// a target, with the inferred hop count.
+func (s *Store) GetTargetHopHistory(ctx context.Context, targetID string, window, bucket time.Duration) ([]types.HopCountPoint, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			time_bucket($3::interval, pr.time) AS bucket,
+			pr.agent_id,
+			COALESCE(a.name, ''),
+			percentile_disc(0.5) WITHIN GROUP (ORDER BY pr.reply_ttl)::int,
+			COUNT(*)
+		FROM probe_results pr
+		LEFT JOIN agents a ON a.id = pr.agent_id
+		WHERE pr.target_id = $1
+		  AND pr.time > NOW() - $2::interval
+		  AND pr.reply_ttl IS NOT NULL
+		GROUP BY bucket, pr.agent_id, a.name
+		ORDER BY bucket, a.name
+	`, targetID, window, bucket)
+	if err != nil {
+		return nil, fmt.Errorf("querying hop history: %w", err)
+	}
+	defer rows.Close()
+
+	var points []types.HopCountPoint
+	for rows.Next() {
+		var p types.HopCountPoint
+		if err := rows.Scan(&p.Time, &p.AgentID, &p.AgentName, &p.ReplyTTL, &p.Samples); err != nil {
+			return nil, fmt.Errorf("scanning hop history: %w", err)
+		}
+		p.HopCount = types.InferHopCount(p.ReplyTTL)
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// ListHopCountChanges returns detected hop-count changes for a target since
+// the given time, newest first.
+func (s *Store) ListHopCountChanges(ctx context.Context, targetID string, since time.Time) ([]types.HopCountChange, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT c.id, c.target_id, c.agent_id, COALESCE(a.name, ''), c.detected_at,
+			c.previous_ttl, c.current_ttl, c.previous_hops, c.current_hops, c.alert_id
+		FROM hop_count_changes c
+		LEFT JOIN agents a ON a.id = c.agent_id
+		WHERE c.target_id = $1 AND c.detected_at > $2
+		ORDER BY c.detected_at DESC
+	`, targetID, since)
+	if err != nil {
+		return nil, fmt.Errorf("querying hop count changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []types.HopCountChange
+	for rows.Next() {
+		var c types.HopCountChange
+		if err := rows.Scan(&c.ID, &c.TargetID, &c.AgentID, &c.AgentName, &c.DetectedAt,
+			&c.PreviousTTL, &c.CurrentTTL, &c.PreviousHops, &c.CurrentHops, &c.AlertID); err != nil {
+			return nil, fmt.Errorf("scanning hop count change: %w", err)
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
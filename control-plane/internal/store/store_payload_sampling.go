@@ -0,0 +1,10 @@
+package store
+
+import "github.com/pilot-net/icmp-mon/pkg/payload"
+
+// SetPayloadSampling makes InsertProbeResults store routine payloads in
+// full only at the sampling rate (see payload.Sampling). Call it before
+// ingest starts.
+func (s *Store) SetPayloadSampling(sampling payload.Sampling) {
+	s.payloadSampling = &sampling
+}
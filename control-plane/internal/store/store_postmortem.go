@@ -0,0 +1,164 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// INCIDENT POSTMORTEM DATA
+// =============================================================================
+
+// IncidentEvent is one entry in an incident's timeline.
+type IncidentEvent struct {
+	EventType   string          `json:"event_type"`
+	Description string          `json:"description,omitempty"`
+	Details     json.RawMessage `json:"details,omitempty"`
+	CreatedBy   string          `json:"created_by,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+// GetIncidentEvents returns an incident's recorded events, oldest first.
+func (s *Store) GetIncidentEvents(ctx context.Context, incidentID string) ([]IncidentEvent, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT event_type, COALESCE(description, ''), details, COALESCE(created_by, ''), created_at
+		FROM incident_events
+		WHERE incident_id = $1
+		ORDER BY created_at
+	`, incidentID)
+	if err != nil {
+		return nil, fmt.Errorf("querying incident events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []IncidentEvent
+	for rows.Next() {
+		var e IncidentEvent
+		if err := rows.Scan(&e.EventType, &e.Description, &e.Details, &e.CreatedBy, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning incident event: %w", err)
+		}
+		events = append(events, e)
+	}
+	return events, rows.Err()
+}
+
+// TargetHistoryPoint is a ProbeHistoryPoint for one of several targets.
+type TargetHistoryPoint struct {
+	TargetID string `json:"target_id"`
+	ProbeHistoryPoint
+}
+
+// GetProbeHistoryWindow returns bucketed probe history between start and end
+// for the given targets, oldest first. A non-empty agentIDs narrows the
+// results to those agents; with no targets, every target those agents probe
+// is included.
+func (s *Store) GetProbeHistoryWindow(ctx context.Context, targetIDs, agentIDs []string, start, end time.Time, bucketSize time.Duration) ([]TargetHistoryPoint, error) {
+	bucketInterval := fmt.Sprintf("%d seconds", int(bucketSize.Seconds()))
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			target_id,
+			time_bucket($5::interval, time) AS bucket,
+			AVG(latency_ms) FILTER (WHERE success),
+			MIN(latency_ms) FILTER (WHERE success),
+			MAX(latency_ms) FILTER (WHERE success),
+			AVG(packet_loss_pct),
+			SUM(CASE WHEN success THEN 1 ELSE 0 END),
+			COUNT(*)
+		FROM probe_results
+		WHERE time >= $3 AND time <= $4
+		  AND (COALESCE(cardinality($1::uuid[]), 0) = 0 OR target_id = ANY($1::uuid[]))
+		  AND (COALESCE(cardinality($2::uuid[]), 0) = 0 OR agent_id = ANY($2::uuid[]))
+		GROUP BY target_id, bucket
+		ORDER BY target_id, bucket
+	`, targetIDs, agentIDs, start, end, bucketInterval)
+	if err != nil {
+		return nil, fmt.Errorf("querying probe history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []TargetHistoryPoint
+	for rows.Next() {
+		var p TargetHistoryPoint
+		if err := rows.Scan(
+			&p.TargetID, &p.Time,
+			&p.AvgLatencyMs, &p.MinLatencyMs, &p.MaxLatencyMs,
+			&p.PacketLossPct, &p.SuccessCount, &p.TotalCount,
+		); err != nil {
+			return nil, fmt.Errorf("scanning probe history: %w", err)
+		}
+		history = append(history, p)
+	}
+	return history, rows.Err()
+}
+
+// IncidentTarget identifies a target affected by an incident.
+type IncidentTarget struct {
+	ID   string `json:"id"`
+	IP   string `json:"ip"`
+	Tier string `json:"tier"`
+}
+
+// IncidentAgent identifies an agent affected by an incident.
+type IncidentAgent struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
+}
+
+// GetIncidentTargets looks up the targets with the given IDs, including
+// archived ones so an old incident still names what it affected.
+func (s *Store) GetIncidentTargets(ctx context.Context, ids []string) ([]IncidentTarget, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.reader().Query(ctx, `
+		SELECT id, host(ip_address), tier
+		FROM targets
+		WHERE id = ANY($1::uuid[])
+		ORDER BY ip_address
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("querying incident targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []IncidentTarget
+	for rows.Next() {
+		var t IncidentTarget
+		if err := rows.Scan(&t.ID, &t.IP, &t.Tier); err != nil {
+			return nil, fmt.Errorf("scanning incident target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// GetIncidentAgents looks up the agents with the given IDs, including
+// archived ones.
+func (s *Store) GetIncidentAgents(ctx context.Context, ids []string) ([]IncidentAgent, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	rows, err := s.reader().Query(ctx, `
+		SELECT id, name, COALESCE(region, '')
+		FROM agents
+		WHERE id = ANY($1::uuid[])
+		ORDER BY name
+	`, ids)
+	if err != nil {
+		return nil, fmt.Errorf("querying incident agents: %w", err)
+	}
+	defer rows.Close()
+
+	var agents []IncidentAgent
+	for rows.Next() {
+		var a IncidentAgent
+		if err := rows.Scan(&a.ID, &a.Name, &a.Region); err != nil {
+			return nil, fmt.Errorf("scanning incident agent: %w", err)
+		}
+		agents = append(agents, a)
+	}
+	return agents, rows.Err()
+}
@@ -0,0 +1,359 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// REPORT SCHEDULES
+// =============================================================================
+
+const reportScheduleColumns = `
+	id, name, COALESCE(subscriber_name, ''), target_ids::text[], window_days,
+	cron_expression, delivery_method, recipients, COALESCE(webhook_url, ''), format,
+	enabled, next_run_at, last_run_at, COALESCE(last_status, ''), COALESCE(last_error, ''),
+	consecutive_failures, created_at, updated_at`
+
+func scanReportSchedule(row pgx.Row) (*types.ReportSchedule, error) {
+	var rs types.ReportSchedule
+	err := row.Scan(
+		&rs.ID, &rs.Name, &rs.SubscriberName, &rs.TargetIDs, &rs.WindowDays,
+		&rs.CronExpression, &rs.DeliveryMethod, &rs.Recipients, &rs.WebhookURL, &rs.Format,
+		&rs.Enabled, &rs.NextRunAt, &rs.LastRunAt, &rs.LastStatus, &rs.LastError,
+		&rs.ConsecutiveFailures, &rs.CreatedAt, &rs.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &rs, nil
+}
+
+// CreateReportSchedule inserts a report schedule and populates its ID and timestamps.
+func (s *Store) CreateReportSchedule(ctx context.Context, rs *types.ReportSchedule) error {
+	if rs.TargetIDs == nil {
+		rs.TargetIDs = []string{}
+	}
+	if rs.Recipients == nil {
+		rs.Recipients = []string{}
+	}
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO report_schedules (
+			name, subscriber_name, target_ids, window_days, cron_expression,
+			delivery_method, recipients, webhook_url, format, enabled, next_run_at
+		) VALUES ($1, NULLIF($2, ''), $3::uuid[], $4, $5, $6, $7, NULLIF($8, ''), $9, $10, $11)
+		RETURNING id, created_at, updated_at
+	`,
+		rs.Name, rs.SubscriberName, rs.TargetIDs, rs.WindowDays, rs.CronExpression,
+		rs.DeliveryMethod, rs.Recipients, rs.WebhookURL, rs.Format, rs.Enabled, rs.NextRunAt,
+	).Scan(&rs.ID, &rs.CreatedAt, &rs.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting report schedule: %w", err)
+	}
+	return nil
+}
+
+// GetReportSchedule returns a report schedule by ID, or nil if not found.
+func (s *Store) GetReportSchedule(ctx context.Context, id string) (*types.ReportSchedule, error) {
+	rs, err := scanReportSchedule(s.pool.QueryRow(ctx,
+		`SELECT `+reportScheduleColumns+` FROM report_schedules WHERE id = $1`, id))
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting report schedule: %w", err)
+	}
+	return rs, nil
+}
+
+// ListReportSchedules returns all report schedules ordered by name.
+func (s *Store) ListReportSchedules(ctx context.Context) ([]types.ReportSchedule, error) {
+	return s.queryReportSchedules(ctx,
+		`SELECT `+reportScheduleColumns+` FROM report_schedules ORDER BY name`)
+}
+
+// ListDueReportSchedules returns enabled schedules whose next run is at or before now.
+func (s *Store) ListDueReportSchedules(ctx context.Context, now time.Time) ([]types.ReportSchedule, error) {
+	return s.queryReportSchedules(ctx, `
+		SELECT `+reportScheduleColumns+`
+		FROM report_schedules
+		WHERE enabled AND next_run_at IS NOT NULL AND next_run_at <= $1
+		ORDER BY next_run_at
+	`, now)
+}
+
+func (s *Store) queryReportSchedules(ctx context.Context, query string, args ...any) ([]types.ReportSchedule, error) {
+	rows, err := s.pool.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying report schedules: %w", err)
+	}
+	defer rows.Close()
+
+	var schedules []types.ReportSchedule
+	for rows.Next() {
+		rs, err := scanReportSchedule(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning report schedule: %w", err)
+		}
+		schedules = append(schedules, *rs)
+	}
+	return schedules, rows.Err()
+}
+
+// UpdateReportSchedule replaces a schedule's configuration and next run time.
+// Run state (last run, failures) is left untouched.
+func (s *Store) UpdateReportSchedule(ctx context.Context, rs *types.ReportSchedule) error {
+	if rs.TargetIDs == nil {
+		rs.TargetIDs = []string{}
+	}
+	if rs.Recipients == nil {
+		rs.Recipients = []string{}
+	}
+	err := s.pool.QueryRow(ctx, `
+		UPDATE report_schedules SET
+			name = $2, subscriber_name = NULLIF($3, ''), target_ids = $4::uuid[],
+			window_days = $5, cron_expression = $6, delivery_method = $7,
+			recipients = $8, webhook_url = NULLIF($9, ''), format = $10,
+			enabled = $11, next_run_at = $12, updated_at = NOW()
+		WHERE id = $1
+		RETURNING updated_at
+	`,
+		rs.ID, rs.Name, rs.SubscriberName, rs.TargetIDs,
+		rs.WindowDays, rs.CronExpression, rs.DeliveryMethod,
+		rs.Recipients, rs.WebhookURL, rs.Format,
+		rs.Enabled, rs.NextRunAt,
+	).Scan(&rs.UpdatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("report schedule %w: %s", ErrNotFound, rs.ID)
+	}
+	if err != nil {
+		return fmt.Errorf("updating report schedule: %w", err)
+	}
+	return nil
+}
+
+// DeleteReportSchedule removes a schedule and its delivery history.
+func (s *Store) DeleteReportSchedule(ctx context.Context, id string) error {
+	tag, err := s.pool.Exec(ctx, `DELETE FROM report_schedules WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("deleting report schedule: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("report schedule %w: %s", ErrNotFound, id)
+	}
+	return nil
+}
+
+// TriggerReportSchedule makes a schedule due immediately.
+// Returns false if the schedule doesn't exist.
+func (s *Store) TriggerReportSchedule(ctx context.Context, id string) (bool, error) {
+	tag, err := s.pool.Exec(ctx, `
+		UPDATE report_schedules SET next_run_at = NOW(), consecutive_failures = 0, updated_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		return false, fmt.Errorf("triggering report schedule: %w", err)
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// RecordReportScheduleRun stores the outcome of a run and when to run next.
+func (s *Store) RecordReportScheduleRun(ctx context.Context, id string, status types.ReportDeliveryStatus, errMsg string, failures int, nextRun *time.Time) error {
+	_, err := s.pool.Exec(ctx, `
+		UPDATE report_schedules SET
+			last_run_at = NOW(), last_status = $2, last_error = NULLIF($3, ''),
+			consecutive_failures = $4, next_run_at = $5, updated_at = NOW()
+		WHERE id = $1
+	`, id, status, errMsg, failures, nextRun)
+	if err != nil {
+		return fmt.Errorf("recording report schedule run: %w", err)
+	}
+	return nil
+}
+
+// GetReportScheduleTargets resolves the targets a schedule reports on: its
+// explicit target list, or every active target in the subscriber's subnets.
+func (s *Store) GetReportScheduleTargets(ctx context.Context, rs *types.ReportSchedule) ([]types.Target, error) {
+	var rows pgx.Rows
+	var err error
+	if len(rs.TargetIDs) > 0 {
+		rows, err = s.pool.Query(ctx, `
+			SELECT id, host(ip_address)
+			FROM targets
+			WHERE id = ANY($1::uuid[]) AND archived_at IS NULL
+			ORDER BY ip_address
+		`, rs.TargetIDs)
+	} else {
+		rows, err = s.pool.Query(ctx, `
+			SELECT t.id, host(t.ip_address)
+			FROM targets t
+			JOIN subnets sub ON sub.id = t.subnet_id
+			WHERE sub.subscriber_name = $1
+			  AND sub.state = 'active'
+			  AND t.archived_at IS NULL
+			ORDER BY t.ip_address
+		`, rs.SubscriberName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying report targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []types.Target
+	for rows.Next() {
+		var t types.Target
+		if err := rows.Scan(&t.ID, &t.IP); err != nil {
+			return nil, fmt.Errorf("scanning report target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// =============================================================================
+// REPORT DELIVERIES
+// =============================================================================
+
+// CreateReportDelivery records a delivery attempt.
+func (s *Store) CreateReportDelivery(ctx context.Context, d *types.ReportDelivery) error {
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO report_deliveries (
+			schedule_id, status, delivery_method, destination, target_count,
+			size_bytes, attempt, error, window_start, window_end
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, NULLIF($8, ''), $9, $10)
+		RETURNING id, created_at
+	`,
+		d.ScheduleID, d.Status, d.DeliveryMethod, d.Destination, d.TargetCount,
+		d.SizeBytes, d.Attempt, d.Error, d.WindowStart, d.WindowEnd,
+	).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("inserting report delivery: %w", err)
+	}
+	return nil
+}
+
+// ListReportDeliveries returns a schedule's most recent delivery attempts.
+func (s *Store) ListReportDeliveries(ctx context.Context, scheduleID string, limit int) ([]types.ReportDelivery, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, schedule_id, status, delivery_method, destination, target_count,
+			size_bytes, attempt, COALESCE(error, ''), window_start, window_end, created_at
+		FROM report_deliveries
+		WHERE schedule_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, scheduleID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying report deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []types.ReportDelivery
+	for rows.Next() {
+		var d types.ReportDelivery
+		if err := rows.Scan(
+			&d.ID, &d.ScheduleID, &d.Status, &d.DeliveryMethod, &d.Destination, &d.TargetCount,
+			&d.SizeBytes, &d.Attempt, &d.Error, &d.WindowStart, &d.WindowEnd, &d.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning report delivery: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// =============================================================================
+// TIME-ZONE AWARE REPORTS
+// =============================================================================
+
+// localWindowStart is the start of a report window of $2 local days in time
+// zone $3: local midnight $2-1 days ago, so today counts as the last day.
+const localWindowStart = `(date_trunc('day', NOW() AT TIME ZONE $3) - make_interval(days => $2 - 1)) AT TIME ZONE $3`
+
+// TargetDailyReport is one local day of a target's metrics across all agents.
+type TargetDailyReport struct {
+	Date         string   `json:"date"` // YYYY-MM-DD in the report's time zone
+	AvgLatencyMs *float64 `json:"avg_latency_ms"`
+	PacketLoss   *float64 `json:"packet_loss_pct"`
+	UptimePct    *float64 `json:"uptime_pct"`
+	TotalProbes  int64    `json:"total_probes"`
+}
+
+// getTargetReportInZone is GetTargetReport over whole local days. It reads
+// probe_hourly rather than probe_daily, whose buckets are fixed to UTC
+// midnight. Zones with a sub-hour offset are aligned to the nearest hour.
+func (s *Store) getTargetReportInZone(ctx context.Context, targetID string, windowDays int, tz string) ([]TargetReport, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			a.name, a.region,
+			avg(ph.avg_latency)::DOUBLE PRECISION,
+			avg(ph.p95_latency)::DOUBLE PRECISION,
+			avg(ph.p99_latency)::DOUBLE PRECISION,
+			avg(ph.latency_stddev)::DOUBLE PRECISION,
+			avg(ph.avg_packet_loss)::DOUBLE PRECISION,
+			(sum(ph.success_count)::float / NULLIF(sum(ph.probe_count), 0) * 100)::DOUBLE PRECISION,
+			sum(ph.probe_count)::BIGINT
+		FROM probe_hourly ph
+		JOIN agents a ON ph.agent_id = a.id
+		WHERE ph.target_id = $1
+		  AND ph.bucket >= `+localWindowStart+`
+		GROUP BY a.name, a.region
+		ORDER BY a.region, a.name
+	`, targetID, windowDays, tz)
+	if err != nil {
+		return nil, fmt.Errorf("querying target report in %s: %w", tz, err)
+	}
+	defer rows.Close()
+
+	var reports []TargetReport
+	for rows.Next() {
+		var r TargetReport
+		if err := rows.Scan(
+			&r.AgentName, &r.AgentRegion, &r.AvgLatencyMs, &r.P95LatencyMs, &r.P99LatencyMs,
+			&r.JitterMs, &r.PacketLoss, &r.UptimePct, &r.TotalProbes,
+		); err != nil {
+			return nil, fmt.Errorf("scanning target report: %w", err)
+		}
+		reports = append(reports, r)
+	}
+	return reports, rows.Err()
+}
+
+// GetTargetDailyReport returns a target's per-day metrics over the window,
+// with days split at local midnight in tz (empty means UTC).
+func (s *Store) GetTargetDailyReport(ctx context.Context, targetID string, windowDays int, tz string) ([]TargetDailyReport, error) {
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	rows, err := s.reader().Query(ctx, `
+		SELECT
+			to_char(date_trunc('day', ph.bucket AT TIME ZONE $3), 'YYYY-MM-DD') AS day,
+			avg(ph.avg_latency)::DOUBLE PRECISION,
+			avg(ph.avg_packet_loss)::DOUBLE PRECISION,
+			(sum(ph.success_count)::float / NULLIF(sum(ph.probe_count), 0) * 100)::DOUBLE PRECISION,
+			sum(ph.probe_count)::BIGINT
+		FROM probe_hourly ph
+		WHERE ph.target_id = $1
+		  AND ph.bucket >= `+localWindowStart+`
+		GROUP BY day
+		ORDER BY day
+	`, targetID, windowDays, tz)
+	if err != nil {
+		return nil, fmt.Errorf("querying daily report in %s: %w", tz, err)
+	}
+	defer rows.Close()
+
+	var days []TargetDailyReport
+	for rows.Next() {
+		var d TargetDailyReport
+		if err := rows.Scan(&d.Date, &d.AvgLatencyMs, &d.PacketLoss, &d.UptimePct, &d.TotalProbes); err != nil {
+			return nil, fmt.Errorf("scanning daily report: %w", err)
+		}
+		days = append(days, d)
+	}
+	return days, rows.Err()
+}
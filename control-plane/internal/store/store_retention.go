@@ -0,0 +1,137 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// =============================================================================
+// PROBE RETENTION OVERRIDES
+// =============================================================================
+
+// RetainedTarget is a target whose raw probe results are (or were) copied
+// into probe_results_archive.
+type RetainedTarget struct {
+	TargetID string
+
+	// RetentionDays is the effective retention (target override, else tier).
+	// Zero means the target is no longer retained (override removed or
+	// target deleted) and its archived rows should be purged.
+	RetentionDays int
+
+	// ArchivedThrough is how far results have been copied; nil if never.
+	ArchivedThrough *time.Time
+}
+
+// GetProbeRetentionDays returns the probe_results retention policy in days,
+// or 0 if the hypertable has no retention policy.
+func (s *Store) GetProbeRetentionDays(ctx context.Context) (int, error) {
+	var days int
+	err := s.pool.QueryRow(ctx, `
+		SELECT (EXTRACT(EPOCH FROM (config->>'drop_after')::interval) / 86400)::int
+		FROM timescaledb_information.jobs
+		WHERE proc_name = 'policy_retention' AND hypertable_name = 'probe_results'
+		LIMIT 1
+	`).Scan(&days)
+	if err == pgx.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("reading probe_results retention policy: %w", err)
+	}
+	return days, nil
+}
+
+// ListRetainedTargets returns targets whose effective retention exceeds
+// globalDays, plus any previously archived targets that no longer qualify.
+func (s *Store) ListRetainedTargets(ctx context.Context, globalDays int) ([]RetainedTarget, error) {
+	rows, err := s.pool.Query(ctx, `
+		WITH flagged AS (
+			SELECT t.id, COALESCE(t.retention_days, tr.retention_days) AS days
+			FROM targets t
+			LEFT JOIN tiers tr ON tr.name = t.tier
+			WHERE COALESCE(t.retention_days, tr.retention_days) > $1
+		)
+		SELECT COALESCE(f.id, p.target_id)::text, COALESCE(f.days, 0), p.archived_through
+		FROM flagged f
+		FULL OUTER JOIN probe_retention_progress p ON p.target_id = f.id
+	`, globalDays)
+	if err != nil {
+		return nil, fmt.Errorf("listing retained targets: %w", err)
+	}
+	defer rows.Close()
+
+	var targets []RetainedTarget
+	for rows.Next() {
+		var t RetainedTarget
+		if err := rows.Scan(&t.TargetID, &t.RetentionDays, &t.ArchivedThrough); err != nil {
+			return nil, fmt.Errorf("scanning retained target: %w", err)
+		}
+		targets = append(targets, t)
+	}
+	return targets, rows.Err()
+}
+
+// ArchiveProbeResults copies a target's raw results in (from, to] into
+// probe_results_archive and advances its archive watermark to `to`.
+func (s *Store) ArchiveProbeResults(ctx context.Context, targetID string, from, to time.Time) (int64, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO probe_results_archive (
+			time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct,
+			payload, agent_region, target_region, is_in_market, reply_ttl, probe_type
+		)
+		SELECT time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct,
+			payload, agent_region, target_region, is_in_market, reply_ttl, probe_type
+		FROM probe_results
+		WHERE target_id = $1 AND time > $2 AND time <= $3
+		ON CONFLICT (time, target_id, agent_id) DO NOTHING
+	`, targetID, from, to)
+	if err != nil {
+		return 0, fmt.Errorf("copying probe results: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, `
+		INSERT INTO probe_retention_progress (target_id, archived_through)
+		VALUES ($1, $2)
+		ON CONFLICT (target_id) DO UPDATE SET
+			archived_through = EXCLUDED.archived_through,
+			updated_at = NOW()
+	`, targetID, to)
+	if err != nil {
+		return 0, fmt.Errorf("updating archive progress: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing archive: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// PruneProbeArchive deletes a target's archived results older than before.
+func (s *Store) PruneProbeArchive(ctx context.Context, targetID string, before time.Time) (int64, error) {
+	tag, err := s.pool.Exec(ctx, `
+		DELETE FROM probe_results_archive WHERE target_id = $1 AND time < $2
+	`, targetID, before)
+	if err != nil {
+		return 0, fmt.Errorf("pruning probe archive: %w", err)
+	}
+	return tag.RowsAffected(), nil
+}
+
+// DeleteProbeRetentionProgress forgets a target's archive watermark.
+func (s *Store) DeleteProbeRetentionProgress(ctx context.Context, targetID string) error {
+	_, err := s.pool.Exec(ctx, `DELETE FROM probe_retention_progress WHERE target_id = $1`, targetID)
+	if err != nil {
+		return fmt.Errorf("deleting archive progress: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// RESULT SHIP LAG
+// =============================================================================
+
+// InsertShipLag records the ship lag of one result batch received from an
+// agent.
+func (s *Store) InsertShipLag(ctx context.Context, agentID string, sequence int64, receivedAt time.Time, lag types.BatchShipLag) error {
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO agent_ship_lag (time, agent_id, sequence, results, lag_p50_ms, lag_p95_ms, lag_max_ms)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, receivedAt, agentID, sequence, lag.Results, lag.P50Ms, lag.P95Ms, lag.MaxMs)
+	if err != nil {
+		return fmt.Errorf("inserting ship lag: %w", err)
+	}
+	return nil
+}
+
+// GetShipLag summarises the ship lag over window of each non-archived agent,
+// or only of agentID when it is set. Agents with neither batches nor
+// heartbeats in the window are left out. OldestQueuedAgeMs is from the
+// agent's latest heartbeat in the window.
+func (s *Store) GetShipLag(ctx context.Context, agentID string, window time.Duration) ([]types.AgentShipLag, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH lag AS (
+			SELECT agent_id, COUNT(*) AS batches,
+				percentile_cont(0.95) WITHIN GROUP (ORDER BY lag_p95_ms) AS p95_ms,
+				MAX(lag_max_ms) AS max_ms
+			FROM agent_ship_lag
+			WHERE time > NOW() - $1::interval
+			  AND ($2 = '' OR agent_id = NULLIF($2, '')::uuid)
+			GROUP BY agent_id
+		), queued AS (
+			SELECT DISTINCT ON (agent_id) agent_id, oldest_queued_age_ms
+			FROM agent_metrics
+			WHERE time > NOW() - $1::interval
+			  AND ($2 = '' OR agent_id = NULLIF($2, '')::uuid)
+			ORDER BY agent_id, time DESC
+		)
+		SELECT ag.id::text, ag.name,
+			COALESCE(l.batches, 0), COALESCE(l.p95_ms, 0), COALESCE(l.max_ms, 0),
+			COALESCE(q.oldest_queued_age_ms, 0)
+		FROM agents ag
+		LEFT JOIN lag l ON l.agent_id = ag.id
+		LEFT JOIN queued q ON q.agent_id = ag.id
+		WHERE ag.archived_at IS NULL
+		  AND (l.agent_id IS NOT NULL OR q.agent_id IS NOT NULL)
+		ORDER BY ag.name
+	`, window.String(), agentID)
+	if err != nil {
+		return nil, fmt.Errorf("querying ship lag: %w", err)
+	}
+	defer rows.Close()
+
+	var lags []types.AgentShipLag
+	for rows.Next() {
+		l := types.AgentShipLag{Window: window.String()}
+		if err := rows.Scan(&l.AgentID, &l.AgentName, &l.Batches, &l.P95Ms, &l.MaxMs, &l.OldestQueuedAgeMs); err != nil {
+			return nil, fmt.Errorf("scanning ship lag: %w", err)
+		}
+		lags = append(lags, l)
+	}
+	return lags, rows.Err()
+}
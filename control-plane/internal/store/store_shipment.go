@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// RESULT SHIPMENT
+// =============================================================================
+
+// shipmentCounters are the cumulative agent_metrics counters read by
+// GetAgentShipments, in scan order.
+var shipmentCounters = []string{
+	"results_shipped", "results_failed", "batches_shipped",
+	"ship_failures", "results_bytes_shipped", "results_bytes_uncompressed",
+}
+
+// counterIncrease sums a cumulative counter's increase between consecutive
+// heartbeats. A drop means the agent restarted and its counter began again
+// from zero, so the new value is the increase since the restart.
+func counterIncrease(col string) string {
+	return fmt.Sprintf(`COALESCE(SUM(CASE
+		WHEN prev_%[1]s IS NULL THEN 0
+		WHEN %[1]s >= prev_%[1]s THEN %[1]s - prev_%[1]s
+		ELSE %[1]s
+	END), 0)::bigint`, col)
+}
+
+// GetAgentShipments returns each non-archived agent's result shipping over
+// window, as the increase in its heartbeat counters. Agents without
+// heartbeats in the window are left out.
+func (s *Store) GetAgentShipments(ctx context.Context, window time.Duration) ([]types.AgentShipment, error) {
+	prev := make([]string, len(shipmentCounters))
+	sums := make([]string, len(shipmentCounters))
+	for i, col := range shipmentCounters {
+		prev[i] = fmt.Sprintf("%[1]s, LAG(%[1]s) OVER w AS prev_%[1]s", col)
+		sums[i] = counterIncrease(col)
+	}
+
+	rows, err := s.reader().Query(ctx, fmt.Sprintf(`
+		WITH samples AS (
+			SELECT agent_id, %s
+			FROM agent_metrics
+			WHERE time > NOW() - $1::interval
+			WINDOW w AS (PARTITION BY agent_id ORDER BY time)
+		)
+		SELECT ag.id::text, ag.name, %s
+		FROM samples sm
+		JOIN agents ag ON ag.id = sm.agent_id
+		WHERE ag.archived_at IS NULL
+		GROUP BY ag.id, ag.name
+		ORDER BY ag.name
+	`, strings.Join(prev, ", "), strings.Join(sums, ", ")), window.String())
+	if err != nil {
+		return nil, fmt.Errorf("querying agent shipments: %w", err)
+	}
+	defer rows.Close()
+
+	var shipments []types.AgentShipment
+	for rows.Next() {
+		var a types.AgentShipment
+		if err := rows.Scan(&a.AgentID, &a.AgentName,
+			&a.ResultsShipped, &a.ResultsFailed, &a.BatchesShipped,
+			&a.ShipFailures, &a.BytesShipped, &a.BytesUncompressed,
+		); err != nil {
+			return nil, fmt.Errorf("scanning agent shipment: %w", err)
+		}
+		shipments = append(shipments, a)
+	}
+	return shipments, rows.Err()
+}
@@ -250,6 +250,7 @@ func (s *Store) listSubnetsWithFilter(ctx context.Context, where string, args []
 type SubnetListParams struct {
 	Limit           int
 	Offset          int
+	Cursor          *string // Keyset pagination: nil = use Offset, "" = first page
 	POPName         string
 	City            string
 	Region          string
@@ -262,9 +263,10 @@ type SubnetListParams struct {
 // SubnetListResult contains paginated subnet results.
 type SubnetListResult struct {
 	Subnets    []types.Subnet `json:"subnets"`
-	TotalCount int            `json:"total_count"`
+	TotalCount *int           `json:"total_count,omitempty"` // Not computed in cursor mode
 	Limit      int            `json:"limit"`
 	Offset     int            `json:"offset"`
+	NextCursor string         `json:"next_cursor,omitempty"` // Empty on the last page
 }
 
 // ListSubnetsPaginated returns subnets with pagination and filtering.
@@ -316,6 +318,10 @@ func (s *Store) ListSubnetsPaginated(ctx context.Context, params SubnetListParam
 		whereClause = strings.Join(conditions, " AND ")
 	}
 
+	if params.Cursor != nil {
+		return s.listSubnetsByCursor(ctx, conditions, args, params)
+	}
+
 	// Get total count
 	countQuery := fmt.Sprintf("SELECT COUNT(*) FROM subnets WHERE %s", whereClause)
 	var totalCount int
@@ -354,6 +360,21 @@ func (s *Store) ListSubnetsPaginated(ctx context.Context, params SubnetListParam
 	}
 	defer rows.Close()
 
+	subnets, err := s.scanSubnets(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SubnetListResult{
+		Subnets:    subnets,
+		TotalCount: &totalCount,
+		Limit:      params.Limit,
+		Offset:     params.Offset,
+	}, nil
+}
+
+// scanSubnets scans rows selected with the ListSubnetsPaginated column list.
+func (s *Store) scanSubnets(rows pgx.Rows) ([]types.Subnet, error) {
 	var subnets []types.Subnet
 	for rows.Next() {
 		var subnet types.Subnet
@@ -387,16 +408,7 @@ func (s *Store) ListSubnetsPaginated(ctx context.Context, params SubnetListParam
 		subnets = append(subnets, subnet)
 	}
 
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return &SubnetListResult{
-		Subnets:    subnets,
-		TotalCount: totalCount,
-		Limit:      params.Limit,
-		Offset:     params.Offset,
-	}, nil
+	return subnets, rows.Err()
 }
 
 // UpdateSubnet updates a subnet's metadata.
@@ -577,7 +589,9 @@ func (s *Store) ListTargetsBySubnet(ctx context.Context, subnetID string) ([]typ
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE subnet_id = $1 AND archived_at IS NULL
 		ORDER BY ip_address
@@ -599,6 +613,7 @@ func (s *Store) ListTargetsNeedingReview(ctx context.Context) ([]types.TargetEnr
 			t.monitoring_state, t.state_changed_at, t.needs_review, t.discovery_attempts, t.last_response_at,
 			t.first_response_at, t.baseline_established_at,
 			t.archived_at, t.archive_reason, t.expected_outcome, t.created_at, t.updated_at, t.is_representative,
+			t.dscp, t.region, t.retention_days, t.probing_enabled, t.probing_changed_at,
 			s.network_address::text, s.network_size, s.pilot_subnet_id,
 			s.service_id, s.subscriber_id, s.subscriber_name,
 			s.location_id, s.location_address, s.city, s.region, s.pop_name,
@@ -622,7 +637,7 @@ func (s *Store) scanTargets(rows pgx.Rows) ([]types.Target, error) {
 	for rows.Next() {
 		var target types.Target
 		var tagsJSON, expectedJSON []byte
-		var subscriberID, subnetID, displayName, notes, archiveReason *string
+		var subscriberID, subnetID, displayName, notes, archiveReason, region *string
 		var origin, ipType *string
 		var ownership, monitoringState string
 
@@ -632,7 +647,9 @@ func (s *Store) scanTargets(rows pgx.Rows) ([]types.Target, error) {
 			&monitoringState, &target.StateChangedAt, &target.NeedsReview, &target.DiscoveryAttempts, &target.LastResponseAt,
 			&target.FirstResponseAt, &target.BaselineEstablishedAt,
 			&target.ArchivedAt, &archiveReason, &expectedJSON, &target.CreatedAt, &target.UpdatedAt,
-			&target.IsRepresentative,
+			&target.IsRepresentative, &target.DSCP, &region, &target.RetentionDays,
+			&target.ProbingEnabled, &target.ProbingChangedAt,
+			&target.ProbeType, &target.ProbeParams, &target.DownThresholdSeconds,
 		); err != nil {
 			return nil, err
 		}
@@ -652,6 +669,9 @@ func (s *Store) scanTargets(rows pgx.Rows) ([]types.Target, error) {
 		if archiveReason != nil {
 			target.ArchiveReason = *archiveReason
 		}
+		if region != nil {
+			target.Region = *region
+		}
 		if origin != nil {
 			target.Origin = types.OriginType(*origin)
 		}
@@ -675,7 +695,7 @@ func (s *Store) scanEnrichedTargets(rows pgx.Rows) ([]types.TargetEnriched, erro
 	for rows.Next() {
 		var target types.TargetEnriched
 		var tagsJSON, expectedJSON []byte
-		var subscriberID, subnetID, displayName, notes, archiveReason *string
+		var subscriberID, subnetID, displayName, notes, archiveReason, region *string
 		var origin, ipType *string
 		var ownership, monitoringState string
 
@@ -685,7 +705,8 @@ func (s *Store) scanEnrichedTargets(rows pgx.Rows) ([]types.TargetEnriched, erro
 			&monitoringState, &target.StateChangedAt, &target.NeedsReview, &target.DiscoveryAttempts, &target.LastResponseAt,
 			&target.FirstResponseAt, &target.BaselineEstablishedAt,
 			&target.ArchivedAt, &archiveReason, &expectedJSON, &target.CreatedAt, &target.UpdatedAt,
-			&target.IsRepresentative,
+			&target.IsRepresentative, &target.DSCP, &region, &target.RetentionDays,
+			&target.ProbingEnabled, &target.ProbingChangedAt,
 			// Subnet fields
 			&target.NetworkAddress, &target.NetworkSize, &target.PilotSubnetID,
 			&target.ServiceID, &target.SubnetSubscriberID, &target.SubscriberName,
@@ -710,6 +731,9 @@ func (s *Store) scanEnrichedTargets(rows pgx.Rows) ([]types.TargetEnriched, erro
 		if archiveReason != nil {
 			target.ArchiveReason = *archiveReason
 		}
+		if region != nil {
+			target.Target.Region = *region
+		}
 		if origin != nil {
 			target.Origin = types.OriginType(*origin)
 		}
@@ -778,6 +802,12 @@ func (s *Store) TransitionTargetState(ctx context.Context, targetID string, newS
 		return err
 	}
 
+	err = recordEvent(ctx, tx, types.EventTargetStateChanged, targetID,
+		targetStatePayload(targetID, ip, oldState, string(newState), reason, triggeredBy))
+	if err != nil {
+		return err
+	}
+
 	// Determine severity based on state transition
 	severity := "info"
 	if newState == types.StateExcluded {
@@ -809,6 +839,18 @@ func (s *Store) TransitionTargetState(ctx context.Context, targetID string, newS
 	return tx.Commit(ctx)
 }
 
+// targetStatePayload is the target.state_changed event payload.
+func targetStatePayload(targetID, ip, fromState, toState, reason, triggeredBy string) map[string]any {
+	return map[string]any{
+		"target_id":    targetID,
+		"ip":           ip,
+		"from_state":   fromState,
+		"to_state":     toState,
+		"reason":       reason,
+		"triggered_by": triggeredBy,
+	}
+}
+
 // GetTargetStateHistory returns recent state transitions for a target.
 func (s *Store) GetTargetStateHistory(ctx context.Context, targetID string, limit int) ([]types.TargetStateTransition, error) {
 	rows, err := s.pool.Query(ctx, `
@@ -852,23 +894,25 @@ func (s *Store) UpdateTargetLastResponse(ctx context.Context, targetID string, r
 }
 
 // GetTargetsForBaselineCheck returns ACTIVE targets that have been responding
-// for at least the threshold duration but don't have a baseline yet.
-func (s *Store) GetTargetsForBaselineCheck(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
+// since before cutoff but don't have a baseline yet.
+func (s *Store) GetTargetsForBaselineCheck(ctx context.Context, cutoff time.Time) ([]types.Target, error) {
 	rows, err := s.pool.Query(ctx, `
 		SELECT
 			id, host(ip_address), tier, subscriber_id, tags, display_name, notes,
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE monitoring_state = 'active'
 		  AND archived_at IS NULL
 		  AND first_response_at IS NOT NULL
 		  AND baseline_established_at IS NULL
-		  AND first_response_at < NOW() - $1::interval
+		  AND first_response_at < $1
 		ORDER BY first_response_at ASC
-	`, threshold)
+	`, cutoff)
 	if err != nil {
 		return nil, err
 	}
@@ -922,24 +966,29 @@ func (s *Store) AcknowledgeTarget(ctx context.Context, targetID string, markInac
 // =============================================================================
 
 // GetTargetsForDownTransition returns ACTIVE targets that haven't responded
-// within the given threshold (should transition to DOWN).
+// for their down threshold as of now (should transition to DOWN): the
+// target's own override, else defaultThreshold.
 // Only targets with an established baseline can transition to DOWN (alertable).
 // Targets without a baseline go to UNRESPONSIVE instead.
-func (s *Store) GetTargetsForDownTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
+func (s *Store) GetTargetsForDownTransition(ctx context.Context, now time.Time, defaultThreshold time.Duration) ([]types.Target, error) {
 	rows, err := s.pool.Query(ctx, `
 		SELECT
 			id, host(ip_address), tier, subscriber_id, tags, display_name, notes,
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE monitoring_state = 'active'
 		  AND archived_at IS NULL
-		  AND last_response_at < NOW() - $1::interval
+		  AND probing_enabled
+		  AND GREATEST(last_response_at, probing_changed_at)  -- Silence while disabled doesn't count
+		      < $1::timestamptz - make_interval(secs => COALESCE(down_threshold_seconds, $2::double precision))
 		  AND baseline_established_at IS NOT NULL  -- Only targets with baseline can be DOWN
 		ORDER BY last_response_at ASC
-	`, threshold)
+	`, now, defaultThreshold.Seconds())
 	if err != nil {
 		return nil, err
 	}
@@ -949,22 +998,25 @@ func (s *Store) GetTargetsForDownTransition(ctx context.Context, threshold time.
 }
 
 // GetTargetsForUnresponsiveTransition returns ACTIVE targets WITHOUT a baseline
-// that have stopped responding. These should transition to UNRESPONSIVE (not alertable).
-func (s *Store) GetTargetsForUnresponsiveTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
+// that haven't responded since cutoff. These should transition to UNRESPONSIVE (not alertable).
+func (s *Store) GetTargetsForUnresponsiveTransition(ctx context.Context, cutoff time.Time) ([]types.Target, error) {
 	rows, err := s.pool.Query(ctx, `
 		SELECT
 			id, host(ip_address), tier, subscriber_id, tags, display_name, notes,
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE monitoring_state = 'active'
 		  AND archived_at IS NULL
-		  AND last_response_at < NOW() - $1::interval
+		  AND probing_enabled
+		  AND GREATEST(last_response_at, probing_changed_at) < $1  -- Silence while disabled doesn't count
 		  AND baseline_established_at IS NULL  -- No baseline = not alertable
 		ORDER BY last_response_at ASC
-	`, threshold)
+	`, cutoff)
 	if err != nil {
 		return nil, err
 	}
@@ -973,24 +1025,27 @@ func (s *Store) GetTargetsForUnresponsiveTransition(ctx context.Context, thresho
 	return s.scanTargets(rows)
 }
 
-// GetTargetsForExcludedTransition returns DOWN targets that have been
-// unresponsive for the given duration (should transition to EXCLUDED).
+// GetTargetsForExcludedTransition returns targets that have been DOWN since
+// before cutoff (should transition to EXCLUDED).
 // Excludes infrastructure and gateway IPs which should stay DOWN.
-func (s *Store) GetTargetsForExcludedTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error) {
+func (s *Store) GetTargetsForExcludedTransition(ctx context.Context, cutoff time.Time) ([]types.Target, error) {
 	rows, err := s.pool.Query(ctx, `
 		SELECT
 			id, host(ip_address), tier, subscriber_id, tags, display_name, notes,
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE monitoring_state = 'down'
 		  AND archived_at IS NULL
-		  AND state_changed_at < NOW() - $1::interval
+		  AND probing_enabled
+		  AND GREATEST(state_changed_at, probing_changed_at) < $1
 		  AND (ip_type IS NULL OR ip_type = 'customer')  -- Infrastructure/gateway IPs stay down
 		ORDER BY state_changed_at ASC
-	`, threshold)
+	`, cutoff)
 	if err != nil {
 		return nil, err
 	}
@@ -1010,10 +1065,13 @@ func (s *Store) GetTargetsForSmartRecheck(ctx context.Context) ([]types.Target,
 			t.subnet_id, t.ownership, t.origin, t.ip_type,
 			t.monitoring_state, t.state_changed_at, t.needs_review, t.discovery_attempts, t.last_response_at,
 			t.first_response_at, t.baseline_established_at,
-			t.archived_at, t.archive_reason, t.expected_outcome, t.created_at, t.updated_at
+			t.archived_at, t.archive_reason, t.expected_outcome, t.created_at, t.updated_at, t.is_representative,
+			t.dscp, t.region, t.retention_days, t.probing_enabled, t.probing_changed_at,
+			t.probe_type, t.probe_params, t.down_threshold_seconds
 		FROM targets t
 		WHERE t.monitoring_state IN ('excluded', 'unresponsive')
 		  AND t.archived_at IS NULL
+		  AND t.probing_enabled
 		  AND t.subnet_id IS NOT NULL
 		  AND t.tier != 'smart_recheck'  -- Don't re-queue if already in smart_recheck tier
 		  AND NOT EXISTS (
@@ -1064,6 +1122,12 @@ func (s *Store) UpdateTarget(ctx context.Context, target *types.Target) error {
 			display_name = $4,
 			notes = $5,
 			expected_outcome = $6,
+			dscp = $7,
+			region = NULLIF($8, ''),
+			retention_days = $9,
+			probe_type = $10,
+			probe_params = $11,
+			down_threshold_seconds = $12,
 			updated_at = NOW()
 		WHERE id = $1 AND archived_at IS NULL
 	`,
@@ -1073,6 +1137,12 @@ func (s *Store) UpdateTarget(ctx context.Context, target *types.Target) error {
 		target.DisplayName,
 		target.Notes,
 		expectedOutcomeJSON,
+		target.DSCP,
+		target.Region,
+		target.RetentionDays,
+		target.EffectiveProbeType(),
+		nullableJSON(target.ProbeParams),
+		target.DownThresholdSeconds,
 	)
 	return err
 }
@@ -1122,6 +1192,12 @@ func (s *Store) GetDistinctTargetTagKeys(ctx context.Context) ([]string, error)
 
 // ArchiveTarget soft-deletes a target with a reason.
 func (s *Store) ArchiveTarget(ctx context.Context, targetID string, reason string) error {
+	return s.ArchiveTargetBy(ctx, targetID, reason, "api")
+}
+
+// ArchiveTargetBy soft-deletes a target with a reason, logging the activity
+// as triggered by triggeredBy.
+func (s *Store) ArchiveTargetBy(ctx context.Context, targetID, reason, triggeredBy string) error {
 	tx, err := s.pool.Begin(ctx)
 	if err != nil {
 		return err
@@ -1155,9 +1231,9 @@ func (s *Store) ArchiveTarget(ctx context.Context, targetID string, reason strin
 		INSERT INTO activity_log (
 			target_id, ip, category, event_type, details, triggered_by, severity
 		) VALUES (
-			$1, $2::inet, 'target', 'archived', $3, 'api', 'info'
+			$1, $2::inet, 'target', 'archived', $3, $4, 'info'
 		)
-	`, targetID, ip, fmt.Sprintf(`{"reason": "%s"}`, reason))
+	`, targetID, ip, fmt.Sprintf(`{"reason": "%s"}`, reason), triggeredBy)
 	if err != nil {
 		return err
 	}
@@ -1165,6 +1241,52 @@ func (s *Store) ArchiveTarget(ctx context.Context, targetID string, reason strin
 	return tx.Commit(ctx)
 }
 
+// SetTargetProbingEnabled turns probing of a target on or off without
+// touching its monitoring state. Returns false if the target is archived,
+// missing, or already in the requested state.
+func (s *Store) SetTargetProbingEnabled(ctx context.Context, targetID string, enabled bool, reason, triggeredBy string) (bool, error) {
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx)
+
+	var ip string
+	var subnetID *string
+	err = tx.QueryRow(ctx, `
+		UPDATE targets SET
+			probing_enabled = $2,
+			probing_changed_at = NOW(),
+			updated_at = NOW()
+		WHERE id = $1 AND archived_at IS NULL AND probing_enabled != $2
+		RETURNING host(ip_address), subnet_id
+	`, targetID, enabled).Scan(&ip, &subnetID)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("updating probing_enabled: %w", err)
+	}
+
+	eventType := "probing_disabled"
+	if enabled {
+		eventType = "probing_enabled"
+	}
+	detailsJSON, _ := json.Marshal(map[string]interface{}{
+		"reason": reason,
+	})
+	_, err = tx.Exec(ctx, `
+		INSERT INTO activity_log (
+			target_id, subnet_id, ip, category, event_type, details, triggered_by, severity
+		) VALUES ($1, $2, $3::inet, 'target', $4, $5, $6, 'info')
+	`, targetID, subnetID, ip, eventType, detailsJSON, triggeredBy)
+	if err != nil {
+		return false, fmt.Errorf("logging activity: %w", err)
+	}
+
+	return true, tx.Commit(ctx)
+}
+
 // =============================================================================
 // SERVICE STATUS MANAGEMENT
 // =============================================================================
@@ -1211,7 +1333,7 @@ func (s *Store) TransitionTargetsByServiceCancellation(ctx context.Context, subn
 
 	// Get all active targets in this subnet
 	rows, err := tx.Query(ctx, `
-		SELECT id, monitoring_state
+		SELECT id, monitoring_state, host(ip_address)
 		FROM targets
 		WHERE subnet_id = $1
 		  AND archived_at IS NULL
@@ -1223,14 +1345,16 @@ func (s *Store) TransitionTargetsByServiceCancellation(ctx context.Context, subn
 
 	var targetIDs []string
 	var oldStates []string
+	var ips []string
 	for rows.Next() {
-		var id, state string
-		if err := rows.Scan(&id, &state); err != nil {
+		var id, state, ip string
+		if err := rows.Scan(&id, &state, &ip); err != nil {
 			rows.Close()
 			return 0, err
 		}
 		targetIDs = append(targetIDs, id)
 		oldStates = append(oldStates, state)
+		ips = append(ips, ip)
 	}
 	rows.Close()
 
@@ -1261,6 +1385,12 @@ func (s *Store) TransitionTargetsByServiceCancellation(ctx context.Context, subn
 		if err != nil {
 			return 0, err
 		}
+
+		err = recordEvent(ctx, tx, types.EventTargetStateChanged, targetID,
+			targetStatePayload(targetID, ips[i], oldStates[i], string(types.StateInactive), "service_cancelled", "sync"))
+		if err != nil {
+			return 0, err
+		}
 	}
 
 	// Log activity
@@ -1294,7 +1424,9 @@ func (s *Store) GetSubnetRepresentative(ctx context.Context, subnetID string) (*
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE subnet_id = $1
 		  AND is_representative = true
@@ -1362,7 +1494,9 @@ func (s *Store) GetStandbyTargetsForSubnet(ctx context.Context, subnetID string)
 			subnet_id, ownership, origin, ip_type,
 			monitoring_state, state_changed_at, needs_review, discovery_attempts, last_response_at,
 			first_response_at, baseline_established_at,
-			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative
+			archived_at, archive_reason, expected_outcome, created_at, updated_at, is_representative,
+			dscp, region, retention_days, probing_enabled, probing_changed_at,
+			probe_type, probe_params, down_threshold_seconds
 		FROM targets
 		WHERE subnet_id = $1
 		  AND monitoring_state = 'standby'
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// TARGET ASN ENRICHMENT
+// =============================================================================
+
+// TargetASN is a target's origin network as looked up from the IP-to-ASN
+// dataset. A zero ASN records that the lookup found nothing.
+type TargetASN struct {
+	TargetID string
+	ASN      int
+	ASName   string
+	Country  string
+}
+
+// ListTargetsNeedingASN returns the IPs of non-archived targets never
+// looked up, or last looked up before checkedBefore, oldest first.
+func (s *Store) ListTargetsNeedingASN(ctx context.Context, checkedBefore time.Time, limit int) (map[string]string, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT id, host(ip_address)
+		FROM targets
+		WHERE archived_at IS NULL
+		  AND (asn_checked_at IS NULL OR asn_checked_at < $1)
+		ORDER BY asn_checked_at NULLS FIRST
+		LIMIT $2
+	`, checkedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("listing targets needing ASN: %w", err)
+	}
+	defer rows.Close()
+
+	ips := make(map[string]string)
+	for rows.Next() {
+		var id, ip string
+		if err := rows.Scan(&id, &ip); err != nil {
+			return nil, fmt.Errorf("scanning target: %w", err)
+		}
+		ips[id] = ip
+	}
+	return ips, rows.Err()
+}
+
+// SetTargetASNs records lookup results and marks each target checked. A
+// zero ASN clears any previous match, for addresses no longer announced.
+// It is one statement, since every UPDATE on targets bumps the assignment
+// version.
+func (s *Store) SetTargetASNs(ctx context.Context, results []TargetASN) error {
+	if len(results) == 0 {
+		return nil
+	}
+	ids := make([]string, len(results))
+	asns := make([]int32, len(results))
+	names := make([]string, len(results))
+	countries := make([]string, len(results))
+	for i, r := range results {
+		ids[i], asns[i], names[i], countries[i] = r.TargetID, int32(r.ASN), r.ASName, r.Country
+	}
+
+	_, err := s.pool.Exec(ctx, `
+		UPDATE targets t SET
+			asn = NULLIF(r.asn, 0),
+			as_name = NULLIF(r.as_name, ''),
+			country = NULLIF(r.country, ''),
+			asn_checked_at = NOW()
+		FROM unnest($1::uuid[], $2::int[], $3::text[], $4::text[]) AS r(id, asn, as_name, country)
+		WHERE t.id = r.id
+	`, ids, asns, names, countries)
+	if err != nil {
+		return fmt.Errorf("setting target ASNs: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// GetTargetByIP retrieves the target holding an IP address, archived or
+// not, or nil if there is none. The address is compared as INET, so
+// differently written forms of the same address match.
+func (s *Store) GetTargetByIP(ctx context.Context, ip string) (*types.Target, error) {
+	var id string
+	err := s.pool.QueryRow(ctx, `SELECT id FROM targets WHERE ip_address = $1::inet`, ip).Scan(&id)
+	if err == pgx.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up target by IP: %w", err)
+	}
+	return s.GetTarget(ctx, id)
+}
@@ -0,0 +1,274 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pilot-net/icmp-mon/pkg/payload"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET ENDPOINTS
+// =============================================================================
+
+const targetEndpointColumns = `id::text, target_id::text, host(ip_address), port, COALESCE(label, ''), created_at`
+
+func scanTargetEndpoint(row pgx.Row) (types.TargetEndpoint, error) {
+	var e types.TargetEndpoint
+	if err := row.Scan(&e.ID, &e.TargetID, &e.IP, &e.Port, &e.Label, &e.CreatedAt); err != nil {
+		return e, err
+	}
+	e.Family = types.AddressFamily(e.IP)
+	return e, nil
+}
+
+// ListTargetEndpoints returns a target's additional endpoints.
+func (s *Store) ListTargetEndpoints(ctx context.Context, targetID string) ([]types.TargetEndpoint, error) {
+	byTarget, err := s.ListEndpointsForTargets(ctx, []string{targetID})
+	if err != nil {
+		return nil, err
+	}
+	return byTarget[targetID], nil
+}
+
+// ListEndpointsForTargets returns the additional endpoints of the given
+// targets, keyed by target ID. Targets without endpoints are absent.
+func (s *Store) ListEndpointsForTargets(ctx context.Context, targetIDs []string) (map[string][]types.TargetEndpoint, error) {
+	byTarget := make(map[string][]types.TargetEndpoint)
+	if len(targetIDs) == 0 {
+		return byTarget, nil
+	}
+
+	rows, err := s.pool.Query(ctx, `
+		SELECT `+targetEndpointColumns+`
+		FROM target_endpoints
+		WHERE target_id = ANY($1::uuid[])
+		ORDER BY target_id, family(ip_address), ip_address, port NULLS FIRST
+	`, targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("listing target endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e, err := scanTargetEndpoint(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning target endpoint: %w", err)
+		}
+		byTarget[e.TargetID] = append(byTarget[e.TargetID], e)
+	}
+	return byTarget, rows.Err()
+}
+
+// CreateTargetEndpoint inserts an endpoint and populates its ID and
+// creation time. Adding an address and port the target already has wraps
+// ErrConflict.
+func (s *Store) CreateTargetEndpoint(ctx context.Context, e *types.TargetEndpoint) error {
+	err := s.pool.QueryRow(ctx, `
+		INSERT INTO target_endpoints (target_id, ip_address, port, label)
+		VALUES ($1, $2::inet, $3, NULLIF($4, ''))
+		ON CONFLICT DO NOTHING
+		RETURNING id::text, created_at
+	`, e.TargetID, e.IP, e.Port, e.Label).Scan(&e.ID, &e.CreatedAt)
+	if err == pgx.ErrNoRows {
+		return fmt.Errorf("target endpoint %w", ErrConflict)
+	}
+	if err != nil {
+		return fmt.Errorf("inserting target endpoint: %w", err)
+	}
+	e.Family = types.AddressFamily(e.IP)
+	return nil
+}
+
+// DeleteTargetEndpoint removes one of a target's endpoints. Its results
+// are left to age out with retention.
+func (s *Store) DeleteTargetEndpoint(ctx context.Context, targetID, endpointID string) error {
+	result, err := s.pool.Exec(ctx, `
+		DELETE FROM target_endpoints WHERE id = $1 AND target_id = $2
+	`, endpointID, targetID)
+	if err != nil {
+		return fmt.Errorf("deleting target endpoint: %w", err)
+	}
+	if result.RowsAffected() == 0 {
+		return fmt.Errorf("target endpoint %w", ErrNotFound)
+	}
+	return nil
+}
+
+// ListTargetIDsWithEndpoints returns the active, probed targets that have
+// at least one additional endpoint.
+func (s *Store) ListTargetIDsWithEndpoints(ctx context.Context) ([]string, error) {
+	rows, err := s.pool.Query(ctx, `
+		SELECT DISTINCT e.target_id::text
+		FROM target_endpoints e
+		JOIN targets t ON t.id = e.target_id
+		WHERE t.archived_at IS NULL AND t.probing_enabled
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("listing targets with endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scanning target id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// =============================================================================
+// TARGET ENDPOINT RESULTS
+// =============================================================================
+
+// InsertEndpointResults writes results for targets' additional endpoints.
+// Results for an endpoint that doesn't exist, or doesn't belong to the
+// result's target, are dropped.
+func (s *Store) InsertEndpointResults(ctx context.Context, results []types.ProbeResult) error {
+	if len(results) == 0 {
+		return nil
+	}
+
+	batch := &pgx.Batch{}
+	for _, r := range results {
+		m := payload.Decode(r.Payload)
+		batch.Queue(`
+			INSERT INTO target_endpoint_results (
+				time, endpoint_id, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct
+			)
+			SELECT $1, e.id, e.target_id, $4::uuid, $5, NULLIF($6, ''), $7, $8
+			FROM target_endpoints e
+			WHERE e.id = $2::uuid AND e.target_id = $3::uuid
+			ON CONFLICT (time, endpoint_id, agent_id) DO NOTHING
+		`, r.Timestamp, r.EndpointID, r.TargetID, r.AgentID, r.Success, r.Error, m.Latency(), m.PacketLoss())
+	}
+
+	if err := s.pool.SendBatch(ctx, batch).Close(); err != nil {
+		return fmt.Errorf("inserting endpoint results: %w", err)
+	}
+	return nil
+}
+
+// GetEndpointStatuses returns the state over the last window of each of
+// the given targets' primary IP (as types.PrimaryEndpointID) and additional
+// endpoints, keyed by target ID.
+func (s *Store) GetEndpointStatuses(ctx context.Context, targetIDs []string, window time.Duration) (map[string][]types.EndpointStatus, error) {
+	byTarget := make(map[string][]types.EndpointStatus)
+	if len(targetIDs) == 0 {
+		return byTarget, nil
+	}
+
+	rows, err := s.reader().Query(ctx, `
+		WITH primary_stats AS (
+			SELECT target_id, COUNT(*) AS probes, COUNT(*) FILTER (WHERE success) AS successes,
+				COUNT(DISTINCT agent_id) AS agents, AVG(latency_ms) FILTER (WHERE success) AS avg_latency,
+				AVG(packet_loss_pct) AS avg_loss, MAX(time) AS last_probe
+			FROM probe_results
+			WHERE target_id = ANY($1::uuid[]) AND time > $2
+			GROUP BY target_id
+		), endpoint_stats AS (
+			SELECT endpoint_id, COUNT(*) AS probes, COUNT(*) FILTER (WHERE success) AS successes,
+				COUNT(DISTINCT agent_id) AS agents, AVG(latency_ms) FILTER (WHERE success) AS avg_latency,
+				AVG(packet_loss_pct) AS avg_loss, MAX(time) AS last_probe
+			FROM target_endpoint_results
+			WHERE target_id = ANY($1::uuid[]) AND time > $2
+			GROUP BY endpoint_id
+		)
+		SELECT t.id::text AS target_id, $3::text AS endpoint_id, host(t.ip_address) AS ip, NULL::int AS port, '' AS label,
+			COALESCE(ps.probes, 0), COALESCE(ps.successes, 0), COALESCE(ps.agents, 0),
+			ps.avg_latency::float8, ps.avg_loss::float8, ps.last_probe, 0 AS sort
+		FROM targets t
+		LEFT JOIN primary_stats ps ON ps.target_id = t.id
+		WHERE t.id = ANY($1::uuid[])
+		UNION ALL
+		SELECT e.target_id::text, e.id::text, host(e.ip_address), e.port, COALESCE(e.label, ''),
+			COALESCE(es.probes, 0), COALESCE(es.successes, 0), COALESCE(es.agents, 0),
+			es.avg_latency::float8, es.avg_loss::float8, es.last_probe, 1
+		FROM target_endpoints e
+		LEFT JOIN endpoint_stats es ON es.endpoint_id = e.id
+		WHERE e.target_id = ANY($1::uuid[])
+		ORDER BY target_id, sort, ip, port NULLS FIRST
+	`, targetIDs, time.Now().Add(-window), types.PrimaryEndpointID)
+	if err != nil {
+		return nil, fmt.Errorf("getting endpoint statuses: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			targetID string
+			sort     int
+			e        types.EndpointStatus
+		)
+		if err := rows.Scan(
+			&targetID, &e.ID, &e.IP, &e.Port, &e.Label,
+			&e.Probes, &e.Successes, &e.AgentCount,
+			&e.AvgLatencyMs, &e.AvgLossPct, &e.LastProbeAt, &sort,
+		); err != nil {
+			return nil, fmt.Errorf("scanning endpoint status: %w", err)
+		}
+		e.Family = types.AddressFamily(e.IP)
+		e.State = types.ClassifyEndpoint(e.Probes, e.Successes)
+		byTarget[targetID] = append(byTarget[targetID], e)
+	}
+	return byTarget, rows.Err()
+}
+
+// EndpointHistoryPoint is one bucket of an endpoint's history. The target's
+// primary IP has EndpointID types.PrimaryEndpointID.
+type EndpointHistoryPoint struct {
+	EndpointID string `json:"endpoint_id"`
+	ProbeHistoryPoint
+}
+
+// GetTargetEndpointHistory returns bucketed history for a target's primary
+// IP and each of its additional endpoints, ordered by endpoint then time.
+func (s *Store) GetTargetEndpointHistory(ctx context.Context, targetID string, window, bucketSize time.Duration) ([]EndpointHistoryPoint, error) {
+	bucketInterval := fmt.Sprintf("%d seconds", int(bucketSize.Seconds()))
+	rows, err := s.reader().Query(ctx, `
+		SELECT $4::text AS endpoint_id, time_bucket($3::interval, time) AS bucket,
+			AVG(latency_ms) FILTER (WHERE success)::float8,
+			MIN(latency_ms) FILTER (WHERE success)::float8,
+			MAX(latency_ms) FILTER (WHERE success)::float8,
+			AVG(packet_loss_pct)::float8,
+			COUNT(*) FILTER (WHERE success), COUNT(*)
+		FROM probe_results
+		WHERE target_id = $1 AND time > $2
+		GROUP BY bucket
+		UNION ALL
+		SELECT endpoint_id::text, time_bucket($3::interval, time) AS bucket,
+			AVG(latency_ms) FILTER (WHERE success)::float8,
+			MIN(latency_ms) FILTER (WHERE success)::float8,
+			MAX(latency_ms) FILTER (WHERE success)::float8,
+			AVG(packet_loss_pct)::float8,
+			COUNT(*) FILTER (WHERE success), COUNT(*)
+		FROM target_endpoint_results
+		WHERE target_id = $1 AND time > $2
+		GROUP BY endpoint_id, bucket
+		ORDER BY 1, 2
+	`, targetID, time.Now().Add(-window), bucketInterval, types.PrimaryEndpointID)
+	if err != nil {
+		return nil, fmt.Errorf("getting endpoint history: %w", err)
+	}
+	defer rows.Close()
+
+	var history []EndpointHistoryPoint
+	for rows.Next() {
+		var p EndpointHistoryPoint
+		if err := rows.Scan(
+			&p.EndpointID, &p.Time,
+			&p.AvgLatencyMs, &p.MinLatencyMs, &p.MaxLatencyMs,
+			&p.PacketLossPct, &p.SuccessCount, &p.TotalCount,
+		); err != nil {
+			return nil, fmt.Errorf("scanning endpoint history: %w", err)
+		}
+		history = append(history, p)
+	}
+	return history, rows.Err()
+}
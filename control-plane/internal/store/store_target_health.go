@@ -0,0 +1,61 @@
+package store
+
+import (
+	"context"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TARGET HEALTH SCORE INPUTS
+// =============================================================================
+
+// GetTargetHealthInputs returns, per target, the latency and jitter inputs
+// of its health score over the window: each agent's mean and standard
+// deviation of successful probe latency, and its baseline p50 and standard
+// deviation, averaged over agents that have a baseline. Agents excluded
+// from the target's health don't count. targetID limits the query to one
+// target; nil covers every target. Targets without a baselined agent that
+// succeeded in the window are absent.
+func (s *Store) GetTargetHealthInputs(ctx context.Context, targetID *string, window time.Duration) (map[string]types.HealthScoreInputs, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH per_agent AS (
+			SELECT
+				pr.target_id,
+				pr.agent_id,
+				AVG(pr.latency_ms) FILTER (WHERE pr.success) AS latency_ms,
+				STDDEV_SAMP(pr.latency_ms) FILTER (WHERE pr.success) AS jitter_ms
+			FROM probe_results pr
+			WHERE pr.time > $1
+			  AND ($2::uuid IS NULL OR pr.target_id = $2::uuid)
+			  AND NOT agent_health_excluded(pr.agent_id, pr.target_id)
+			GROUP BY pr.target_id, pr.agent_id
+		)
+		SELECT
+			pa.target_id,
+			AVG(pa.latency_ms),
+			AVG(b.latency_p50),
+			AVG(pa.jitter_ms),
+			AVG(b.latency_stddev) FILTER (WHERE pa.jitter_ms IS NOT NULL)
+		FROM per_agent pa
+		JOIN agent_target_baseline b ON b.agent_id = pa.agent_id AND b.target_id = pa.target_id
+		WHERE pa.latency_ms IS NOT NULL
+		GROUP BY pa.target_id
+	`, time.Now().Add(-window), targetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	inputs := make(map[string]types.HealthScoreInputs)
+	for rows.Next() {
+		var id string
+		var in types.HealthScoreInputs
+		if err := rows.Scan(&id, &in.LatencyMs, &in.BaselineLatencyMs, &in.JitterMs, &in.BaselineJitterMs); err != nil {
+			return nil, err
+		}
+		inputs[id] = in
+	}
+	return inputs, rows.Err()
+}
@@ -0,0 +1,52 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// TARGET REGION STATS
+// =============================================================================
+
+// TargetRegionStats is one target's latency and loss as seen from one agent
+// region. AgentRegion is empty for agents without a region.
+type TargetRegionStats struct {
+	TargetID      string
+	AgentRegion   string
+	AvgLatencyMs  *float64
+	PacketLossPct *float64
+}
+
+// GetTargetRegionStats returns every target's latency and packet loss per
+// agent region over the window, on the same results as
+// GetAllTargetStatuses: agents excluded from a target's health are left
+// out, and latency is over successful probes only.
+func (s *Store) GetTargetRegionStats(ctx context.Context, window time.Duration) ([]TargetRegionStats, error) {
+	rows, err := s.reader().Query(ctx, `
+		SELECT pr.target_id,
+		       COALESCE(pr.agent_region, ''),
+		       AVG(pr.latency_ms) FILTER (WHERE pr.success),
+		       AVG(pr.packet_loss_pct)
+		FROM probe_results pr
+		WHERE pr.time > $1
+		  AND NOT agent_health_excluded(pr.agent_id, pr.target_id)
+		GROUP BY pr.target_id, COALESCE(pr.agent_region, '')
+		ORDER BY pr.target_id, 2
+	`, time.Now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("querying target region stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []TargetRegionStats
+	for rows.Next() {
+		var st TargetRegionStats
+		if err := rows.Scan(&st.TargetID, &st.AgentRegion, &st.AvgLatencyMs, &st.PacketLossPct); err != nil {
+			return nil, fmt.Errorf("scanning target region stats: %w", err)
+		}
+		stats = append(stats, st)
+	}
+	return stats, rows.Err()
+}
@@ -0,0 +1,96 @@
+package store
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// BULK TIER REASSIGNMENT
+// =============================================================================
+
+// BulkSetTargetTier moves every non-archived target matching filter into
+// tier, in one transaction. Targets already in the tier are left alone.
+// Each moved target gets a tier_changed activity entry, and its persisted
+// assignments are relabelled so assignment views agree with the target.
+// Returns the number of targets changed.
+//
+// An empty filter would select every target and is rejected.
+func (s *Store) BulkSetTargetTier(ctx context.Context, filter *types.TargetFilter, tier, triggeredBy string) (int, error) {
+	matchSQL, matchArgs, _ := buildTargetFilterSelect(filter, 2)
+	if matchSQL == "" {
+		return 0, fmt.Errorf("target filter must have at least one condition")
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Joining the pre-update row lets RETURNING report the old tier
+	args := append([]any{tier}, matchArgs...)
+	rows, err := tx.Query(ctx, fmt.Sprintf(`
+		WITH matched AS (%s)
+		UPDATE targets t SET
+			tier = $1,
+			updated_at = NOW()
+		FROM targets old
+		WHERE old.id = t.id
+		  AND t.id IN (SELECT id FROM matched)
+		  AND t.archived_at IS NULL
+		  AND t.tier <> $1
+		RETURNING t.id::text, host(t.ip_address), t.subnet_id::text, old.tier
+	`, matchSQL), args...)
+	if err != nil {
+		return 0, fmt.Errorf("updating target tiers: %w", err)
+	}
+
+	var ids, ips, fromTiers []string
+	var subnetIDs []*string
+	for rows.Next() {
+		var id, ip, fromTier string
+		var subnetID *string
+		if err := rows.Scan(&id, &ip, &subnetID, &fromTier); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scanning changed target: %w", err)
+		}
+		ids = append(ids, id)
+		ips = append(ips, ip)
+		subnetIDs = append(subnetIDs, subnetID)
+		fromTiers = append(fromTiers, fromTier)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("updating target tiers: %w", err)
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE target_assignments SET tier = $2
+		WHERE target_id = ANY($1::uuid[]) AND tier <> $2
+	`, ids, tier); err != nil {
+		return 0, fmt.Errorf("updating assignment tiers: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		INSERT INTO activity_log (
+			target_id, subnet_id, ip, category, event_type, details, triggered_by, severity
+		)
+		SELECT c.id, c.subnet_id, c.ip::inet, 'target', 'tier_changed',
+		       jsonb_build_object('from_tier', c.from_tier, 'to_tier', $5::text, 'bulk', true),
+		       $6, 'info'
+		FROM unnest($1::uuid[], $2::uuid[], $3::text[], $4::text[]) AS c(id, subnet_id, ip, from_tier)
+	`, ids, subnetIDs, ips, fromTiers, tier, triggeredBy); err != nil {
+		return 0, fmt.Errorf("logging activity: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, fmt.Errorf("committing tier change: %w", err)
+	}
+	return len(ids), nil
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// BULK TARGET CREATION
+// =============================================================================
+
+// BulkCreateTargets inserts targets in one transaction, COPYing them into a
+// staging table and inserting from there with ON CONFLICT (ip_address) DO
+// NOTHING, so a target whose IP is already held, by an existing target or
+// an earlier one in the batch, is skipped. It returns, per target, the ID
+// of the target holding its IP afterwards: the target's own ID when it was
+// created. Every target's tier must exist.
+func (s *Store) BulkCreateTargets(ctx context.Context, targets []types.Target) ([]string, error) {
+	if len(targets) == 0 {
+		return nil, nil
+	}
+
+	tx, err := s.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("beginning transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	_, err = tx.Exec(ctx, `
+		CREATE TEMP TABLE targets_staging (
+			ord INTEGER NOT NULL,
+			id UUID NOT NULL,
+			ip_address TEXT NOT NULL,
+			tier TEXT NOT NULL,
+			subscriber_id TEXT,
+			tags JSONB,
+			expected_outcome JSONB,
+			dscp INTEGER,
+			region TEXT,
+			retention_days INTEGER,
+			probe_type TEXT,
+			probe_params JSONB,
+			down_threshold_seconds INTEGER
+		) ON COMMIT DROP
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("creating staging table: %w", err)
+	}
+
+	rows := make([][]any, len(targets))
+	for i := range targets {
+		t := &targets[i]
+		tagsJSON, _ := json.Marshal(t.Tags)
+		expectedJSON, _ := json.Marshal(t.ExpectedOutcome)
+		var subscriberID any
+		if t.SubscriberID != "" {
+			subscriberID = t.SubscriberID
+		}
+		rows[i] = []any{
+			i, t.ID, t.IP, t.Tier, subscriberID, tagsJSON, expectedJSON, t.DSCP, t.Region,
+			t.RetentionDays, t.EffectiveProbeType(), nullableJSON(t.ProbeParams), t.DownThresholdSeconds,
+		}
+	}
+	_, err = tx.CopyFrom(ctx,
+		pgx.Identifier{"targets_staging"},
+		[]string{"ord", "id", "ip_address", "tier", "subscriber_id", "tags", "expected_outcome", "dscp", "region",
+			"retention_days", "probe_type", "probe_params", "down_threshold_seconds"},
+		pgx.CopyFromRows(rows),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("copying targets: %w", err)
+	}
+
+	// Inserting in request order makes the first of several rows with one
+	// IP the one created
+	_, err = tx.Exec(ctx, `
+		INSERT INTO targets (id, ip_address, tier, subscriber_id, tags, expected_outcome, dscp, region, retention_days,
+			probe_type, probe_params, down_threshold_seconds)
+		SELECT id, ip_address::inet, tier, subscriber_id, tags, expected_outcome, dscp, NULLIF(region, ''), retention_days,
+			probe_type, probe_params, down_threshold_seconds
+		FROM targets_staging
+		ORDER BY ord
+		ON CONFLICT (ip_address) DO NOTHING
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("inserting targets: %w", err)
+	}
+
+	holders, err := tx.Query(ctx, `
+		SELECT s.ord, t.id::text
+		FROM targets_staging s
+		JOIN targets t ON t.ip_address = s.ip_address::inet
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("resolving target IDs: %w", err)
+	}
+	ids := make([]string, len(targets))
+	for holders.Next() {
+		var ord int
+		var id string
+		if err := holders.Scan(&ord, &id); err != nil {
+			holders.Close()
+			return nil, fmt.Errorf("scanning target ID: %w", err)
+		}
+		ids[ord] = id
+	}
+	holders.Close()
+	if err := holders.Err(); err != nil {
+		return nil, fmt.Errorf("resolving target IDs: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("committing targets: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,19 @@
+package store
+
+import (
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// setTierAggregation fills a scanned tier's aggregation policy from its
+// nullable aggregate_bucket_seconds and raw_retention_seconds columns.
+// NULL leaves the zero value, which means the default.
+func setTierAggregation(tier *types.Tier, bucketSecs, rawRetentionSecs *int32) {
+	if bucketSecs != nil {
+		tier.AggregateBucket = time.Duration(*bucketSecs) * time.Second
+	}
+	if rawRetentionSecs != nil {
+		tier.RawRetention = time.Duration(*rawRetentionSecs) * time.Second
+	}
+}
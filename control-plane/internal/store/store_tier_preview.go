@@ -0,0 +1,77 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// TIER PROBE LOAD
+// =============================================================================
+
+// TierProbeLoad is the current probe load behind one tier, for projecting
+// the effect of changing its interval.
+type TierProbeLoad struct {
+	Targets      int // non-archived targets with probing enabled
+	OnlineAgents int
+	Agents       []AgentTierLoad
+}
+
+// AgentTierLoad is an agent's share of a tier and its total probe rate.
+type AgentTierLoad struct {
+	AgentID         string
+	AgentName       string
+	TierAssignments int
+
+	// ProbesPerSecond covers all the agent's assignments at their tiers'
+	// current intervals.
+	ProbesPerSecond float64
+}
+
+// GetTierProbeLoad returns the tier's probed targets and, for every agent
+// assigned any of them, its assignments in the tier and overall probe rate.
+// Assignments are counted under their target's tier, which is what agents
+// probe at.
+func (s *Store) GetTierProbeLoad(ctx context.Context, tier string, onlineWindow time.Duration) (*TierProbeLoad, error) {
+	var load TierProbeLoad
+	err := s.reader().QueryRow(ctx, `
+		SELECT
+			(SELECT COUNT(*) FROM targets
+			 WHERE tier = $1 AND archived_at IS NULL AND probing_enabled),
+			(SELECT COUNT(*) FROM agents
+			 WHERE archived_at IS NULL AND last_heartbeat > NOW() - $2::interval)
+	`, tier, onlineWindow.String()).Scan(&load.Targets, &load.OnlineAgents)
+	if err != nil {
+		return nil, fmt.Errorf("counting tier targets: %w", err)
+	}
+
+	rows, err := s.reader().Query(ctx, `
+		SELECT ag.id::text, ag.name,
+		       COUNT(*) FILTER (WHERE tg.tier = $1)::int,
+		       SUM(1000.0 / ti.probe_interval_ms)::float8
+		FROM target_assignments ta
+		JOIN agents ag ON ag.id = ta.agent_id
+		JOIN targets tg ON tg.id = ta.target_id
+		JOIN tiers ti ON ti.name = tg.tier
+		WHERE ag.archived_at IS NULL
+		  AND tg.archived_at IS NULL
+		  AND tg.probing_enabled
+		GROUP BY ag.id, ag.name
+		HAVING COUNT(*) FILTER (WHERE tg.tier = $1) > 0
+		ORDER BY 3 DESC, ag.name
+	`, tier)
+	if err != nil {
+		return nil, fmt.Errorf("querying agent tier load: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var a AgentTierLoad
+		if err := rows.Scan(&a.AgentID, &a.AgentName, &a.TierAssignments, &a.ProbesPerSecond); err != nil {
+			return nil, fmt.Errorf("scanning agent tier load: %w", err)
+		}
+		load.Agents = append(load.Agents, a)
+	}
+	return &load, rows.Err()
+}
@@ -0,0 +1,76 @@
+package store
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// ALERT WEBHOOK DELIVERIES
+// =============================================================================
+
+// RecordWebhookDelivery counts one delivery to a webhook URL, as failed
+// when deliveryErr is set. Only a hash of the URL and its host are stored.
+func (s *Store) RecordWebhookDelivery(ctx context.Context, webhookURL string, deliveryErr error) error {
+	sum := sha256.Sum256([]byte(webhookURL))
+	host := ""
+	if u, err := url.Parse(webhookURL); err == nil {
+		host = u.Host
+	}
+	var errMsg *string
+	if deliveryErr != nil {
+		msg := deliveryErr.Error()
+		errMsg = &msg
+	}
+
+	_, err := s.pool.Exec(ctx, `
+		INSERT INTO webhook_deliveries (
+			url_hash, host, delivered, failed, last_delivered_at, last_failed_at, last_error
+		) VALUES (
+			$1, $2,
+			CASE WHEN $3::text IS NULL THEN 1 ELSE 0 END,
+			CASE WHEN $3::text IS NULL THEN 0 ELSE 1 END,
+			CASE WHEN $3::text IS NULL THEN NOW() END,
+			CASE WHEN $3::text IS NULL THEN NULL ELSE NOW() END,
+			$3
+		)
+		ON CONFLICT (url_hash) DO UPDATE SET
+			host = EXCLUDED.host,
+			delivered = webhook_deliveries.delivered + EXCLUDED.delivered,
+			failed = webhook_deliveries.failed + EXCLUDED.failed,
+			last_delivered_at = COALESCE(EXCLUDED.last_delivered_at, webhook_deliveries.last_delivered_at),
+			last_failed_at = COALESCE(EXCLUDED.last_failed_at, webhook_deliveries.last_failed_at),
+			last_error = COALESCE(EXCLUDED.last_error, webhook_deliveries.last_error),
+			updated_at = NOW()
+	`, hex.EncodeToString(sum[:]), host, errMsg)
+	return err
+}
+
+// GetWebhookDeliveryStats totals webhook deliveries across every URL, with
+// the most recent failure's time and error.
+func (s *Store) GetWebhookDeliveryStats(ctx context.Context) (*types.WebhookDeliveryStats, error) {
+	var stats types.WebhookDeliveryStats
+	var lastError *string
+	err := s.reader().QueryRow(ctx, `
+		SELECT
+			COALESCE(SUM(delivered), 0),
+			COALESCE(SUM(failed), 0),
+			MAX(last_failed_at),
+			(SELECT last_error FROM webhook_deliveries
+			 WHERE last_failed_at IS NOT NULL
+			 ORDER BY last_failed_at DESC
+			 LIMIT 1)
+		FROM webhook_deliveries
+	`).Scan(&stats.Delivered, &stats.Failed, &stats.LastFailedAt, &lastError)
+	if err != nil {
+		return nil, err
+	}
+	if lastError != nil {
+		stats.LastError = *lastError
+	}
+	return &stats, nil
+}
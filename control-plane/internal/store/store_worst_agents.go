@@ -0,0 +1,64 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// WORST AGENTS
+// =============================================================================
+
+// GetTargetAgentPathStats returns each agent's probe counts, packet loss and
+// latency for a target over the window, from raw probe results. Latency is
+// over successful probes only; an agent in the target's market for any
+// probe in the window counts as in-market.
+func (s *Store) GetTargetAgentPathStats(ctx context.Context, targetID string, window time.Duration) ([]types.AgentPathStats, error) {
+	rows, err := s.reader().Query(ctx, `
+		WITH per_agent AS (
+			SELECT pr.agent_id,
+			       count(*) AS probes,
+			       count(*) FILTER (WHERE pr.success) AS successes,
+			       COALESCE(avg(pr.packet_loss_pct), 0) AS packet_loss_pct,
+			       avg(pr.latency_ms) FILTER (WHERE pr.success) AS avg_latency,
+			       percentile_cont(0.95) WITHIN GROUP (ORDER BY pr.latency_ms) FILTER (WHERE pr.success) AS p95_latency,
+			       bool_or(COALESCE(pr.is_in_market, false)) AS in_market,
+			       max(pr.time) AS last_probe_at
+			FROM probe_results pr
+			WHERE pr.target_id = $1 AND pr.time > $2
+			GROUP BY pr.agent_id
+		)
+		SELECT pa.agent_id,
+		       COALESCE(a.name, pa.agent_id::text),
+		       COALESCE(a.region, ''),
+		       COALESCE(a.provider, ''),
+		       pa.in_market,
+		       agent_health_excluded(pa.agent_id, $1),
+		       pa.probes, pa.successes, pa.packet_loss_pct,
+		       pa.avg_latency, pa.p95_latency, pa.last_probe_at
+		FROM per_agent pa
+		LEFT JOIN agents a ON a.id = pa.agent_id
+	`, targetID, time.Now().Add(-window))
+	if err != nil {
+		return nil, fmt.Errorf("querying agent path stats: %w", err)
+	}
+	defer rows.Close()
+
+	var stats []types.AgentPathStats
+	for rows.Next() {
+		var a types.AgentPathStats
+		if err := rows.Scan(
+			&a.AgentID, &a.AgentName, &a.AgentRegion, &a.AgentProvider,
+			&a.InMarket, &a.HealthExcluded,
+			&a.Probes, &a.Successes, &a.PacketLossPct,
+			&a.AvgLatencyMs, &a.P95LatencyMs, &a.LastProbeAt,
+		); err != nil {
+			return nil, fmt.Errorf("scanning agent path stats: %w", err)
+		}
+		stats = append(stats, a)
+	}
+	return stats, rows.Err()
+}
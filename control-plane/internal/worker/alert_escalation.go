@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/notify"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// TIME-BASED ESCALATION
+// =============================================================================
+//
+// Metric escalation raises an alert when the target gets worse. Escalation
+// policies raise one nobody has acknowledged: each cycle, every
+// unacknowledged active alert is checked against the ladder of the policy
+// covering it, and a step whose time has come fires once.
+
+// escalateUnacknowledged fires the escalation policy steps that have come
+// due and returns how many alerts escalated.
+func (w *AlertWorker) escalateUnacknowledged(ctx context.Context) int {
+	policies, err := w.alertStore.ListEscalationPolicies(ctx, true)
+	if err != nil {
+		w.logger.Error("failed to list escalation policies", "error", err)
+		return 0
+	}
+	if len(policies) == 0 {
+		return 0
+	}
+
+	alerts, err := w.alertStore.ListUnacknowledgedAlerts(ctx, config.EscalationAlertLimit)
+	if err != nil {
+		w.logger.Error("failed to list unacknowledged alerts", "error", err)
+		return 0
+	}
+
+	now := w.now()
+	escalated := 0
+	for i := range alerts {
+		alert := &alerts[i]
+		policy := escalationPolicyFor(policies, alert)
+		if policy == nil {
+			continue
+		}
+		step, due := policy.DueStep(now.Sub(alert.DetectedAt), alert.EscalationStep)
+		if !due {
+			continue
+		}
+		if w.fireEscalationStep(ctx, alert, policy, step, now) {
+			escalated++
+		}
+	}
+	return escalated
+}
+
+// escalationPolicyFor returns the policy escalating an alert: the one that
+// fired its earlier steps, or else the one covering it now. An alert whose
+// policy has been disabled stays where it is.
+func escalationPolicyFor(policies []types.EscalationPolicy, alert *types.Alert) *types.EscalationPolicy {
+	if alert.EscalationPolicyID == "" {
+		return types.SelectEscalationPolicy(policies, alert)
+	}
+	for i := range policies {
+		if policies[i].ID == alert.EscalationPolicyID {
+			return &policies[i]
+		}
+	}
+	return nil
+}
+
+// fireEscalationStep records a step against an alert and notifies. It
+// reports false if the alert was acknowledged or resolved in the meantime.
+func (w *AlertWorker) fireEscalationStep(ctx context.Context, alert *types.Alert, policy *types.EscalationPolicy, step int, now time.Time) bool {
+	s := policy.Steps[step]
+	severity := s.EscalatedSeverity(alert.Severity)
+	desc := fmt.Sprintf("Unacknowledged for %s: escalation policy %q step %d of %d",
+		now.Sub(alert.DetectedAt).Truncate(time.Minute), policy.Name, step+1, len(policy.Steps))
+	if severity != alert.Severity {
+		desc += fmt.Sprintf(", %s to %s", alert.Severity, severity)
+	}
+
+	advanced, err := w.alertStore.AdvanceAlertEscalation(ctx, alert.ID, policy.ID, step+1, severity, desc)
+	if err != nil {
+		w.logger.Error("failed to escalate alert", "alert_id", alert.ID, "policy", policy.Name, "error", err)
+		return false
+	}
+	if !advanced {
+		return false
+	}
+	w.logger.Info("alert escalated by policy",
+		"alert_id", alert.ID,
+		"policy", policy.Name,
+		"step", step+1,
+		"old_severity", alert.Severity,
+		"new_severity", severity,
+	)
+
+	escalated := *alert
+	escalated.Severity = severity
+	escalated.EscalationPolicyID = policy.ID
+	escalated.EscalationStep = step + 1
+	w.notify(ctx, notify.Event{
+		Type:             notify.EventAlertEscalated,
+		Alert:            escalated,
+		PreviousSeverity: alert.Severity,
+		Description:      desc,
+		Escalation: &notify.Escalation{
+			PolicyID:   policy.ID,
+			PolicyName: policy.Name,
+			Step:       step + 1,
+			Steps:      len(policy.Steps),
+			Recipients: s.Recipients,
+		},
+	})
+	return true
+}
@@ -0,0 +1,265 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/notify"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// globalRateKey is the alertRateLimiter key counting alerts across all subnets.
+const globalRateKey = ""
+
+// alertRateLimiter remembers when recent alerts were created, per key, so
+// the worker can measure creation rate over a sliding window. Only the
+// worker goroutine uses it, so it is not synchronised.
+type alertRateLimiter struct {
+	created map[string][]time.Time
+}
+
+func newAlertRateLimiter() *alertRateLimiter {
+	return &alertRateLimiter{created: make(map[string][]time.Time)}
+}
+
+// record notes an alert created at the given time.
+func (l *alertRateLimiter) record(key string, at time.Time) {
+	l.created[key] = append(l.created[key], at)
+}
+
+// count returns how many alerts were recorded for key since the last prune.
+func (l *alertRateLimiter) count(key string) int {
+	return len(l.created[key])
+}
+
+// prune forgets alerts created before the cutoff.
+func (l *alertRateLimiter) prune(cutoff time.Time) {
+	for key, times := range l.created {
+		i := sort.Search(len(times), func(i int) bool { return !times[i].Before(cutoff) })
+		if i == len(times) {
+			delete(l.created, key)
+			continue
+		}
+		l.created[key] = times[i:]
+	}
+}
+
+// createAlerts creates alerts for anomalies that have none yet, applying
+// the subnet and global rate caps.
+//
+// A subnet that would exceed its cap gets alerts for its worst anomalies up
+// to the cap, and the rest fold into one subnet_rollup alert. While the
+// rollup is active, the subnet's new anomalies only update it. Once a cycle's
+// new anomalies fit under the cap again, the rollup resolves and per-target
+// alerts resume. Anomalies beyond the global cap are left for a later cycle.
+func (w *AlertWorker) createAlerts(ctx context.Context, pending []types.Anomaly) (created int) {
+	now := w.now()
+	w.rates.prune(now.Add(-w.config.AlertRateWindow))
+
+	rollups := w.activeRollups(ctx)
+
+	var subnets []string
+	bySubnet := make(map[string][]types.Anomaly)
+	for _, anomaly := range pending {
+		if _, ok := bySubnet[anomaly.SubnetID]; !ok {
+			subnets = append(subnets, anomaly.SubnetID)
+		}
+		bySubnet[anomaly.SubnetID] = append(bySubnet[anomaly.SubnetID], anomaly)
+	}
+
+	deferred := 0
+	for _, subnetID := range subnets {
+		anomalies := bySubnet[subnetID]
+		// Worst first, so a capped subnet still alerts on its most severe targets
+		sort.SliceStable(anomalies, func(i, j int) bool {
+			return w.calculateSeverity(anomalies[i]).Level() > w.calculateSeverity(anomalies[j]).Level()
+		})
+
+		if subnetID != "" && w.config.SubnetAlertRateCap > 0 {
+			allowed := max(0, w.config.SubnetAlertRateCap-w.rates.count(subnetID))
+			rollup, rolledUp := rollups[subnetID]
+			delete(rollups, subnetID)
+
+			switch {
+			case rolledUp && len(anomalies) > allowed:
+				w.updateRollup(ctx, rollup, anomalies)
+				continue
+			case rolledUp:
+				w.resolveRollup(ctx, rollup, fmt.Sprintf("New alert rate back under %d per %s; resuming per-target alerts",
+					w.config.SubnetAlertRateCap, w.config.AlertRateWindow))
+			case len(anomalies) > allowed:
+				if w.createRollup(ctx, subnetID, anomalies[allowed:]) {
+					w.rates.record(globalRateKey, now)
+					created++
+				}
+				anomalies = anomalies[:allowed]
+			}
+		}
+
+		for _, anomaly := range anomalies {
+			if w.config.GlobalAlertRateCap > 0 && w.rates.count(globalRateKey) >= w.config.GlobalAlertRateCap {
+				deferred++
+				continue
+			}
+			if w.createAlert(ctx, anomaly) {
+				w.rates.record(globalRateKey, now)
+				if subnetID != "" {
+					w.rates.record(subnetID, now)
+				}
+				created++
+			}
+		}
+	}
+
+	// Rolled-up subnets with no new anomalies at all have calmed down
+	for _, rollup := range rollups {
+		w.resolveRollup(ctx, rollup, "No new anomalies on subnet; resuming per-target alerts")
+	}
+
+	if deferred > 0 {
+		w.logger.Warn("global alert rate cap reached, deferring new alerts",
+			"cap", w.config.GlobalAlertRateCap,
+			"window", w.config.AlertRateWindow,
+			"deferred", deferred,
+		)
+	}
+
+	return created
+}
+
+// activeRollups returns unresolved subnet rollup alerts keyed by subnet ID.
+func (w *AlertWorker) activeRollups(ctx context.Context) map[string]*types.Alert {
+	rollups := make(map[string]*types.Alert)
+	alertType := types.AlertTypeSubnetRollup
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		alerts, err := w.alertStore.ListAlerts(ctx, types.AlertFilter{
+			Status:    &status,
+			AlertType: &alertType,
+			Limit:     1000,
+		})
+		if err != nil {
+			w.logger.Error("failed to list subnet rollup alerts", "status", status, "error", err)
+			continue
+		}
+		for i := range alerts {
+			if alerts[i].SubnetID != "" {
+				rollups[alerts[i].SubnetID] = &alerts[i]
+			}
+		}
+	}
+	return rollups
+}
+
+// createRollup creates one subnet_rollup alert standing in for anomalies.
+func (w *AlertWorker) createRollup(ctx context.Context, subnetID string, anomalies []types.Anomaly) bool {
+	subnet, err := w.alertStore.GetSubnet(ctx, subnetID)
+	if err != nil || subnet == nil {
+		w.logger.Error("failed to get subnet for rollup alert", "subnet_id", subnetID, "error", err)
+		return false
+	}
+
+	severity := w.rollupSeverity(anomalies)
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetIP:        subnet.NetworkAddress,
+		AlertType:       types.AlertTypeSubnetRollup,
+		Severity:        severity,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: severity,
+		PeakSeverity:    severity,
+		Title:           w.rollupTitle(subnet.NetworkAddress, len(anomalies), severity),
+		Message:         w.rollupMessage(subnet.NetworkAddress, len(anomalies)),
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+		CorrelationKey:  types.CorrelationKey(types.RootCauseSubnet, subnetID),
+	}
+
+	if err := w.alertStore.CreateAlert(ctx, alert); err != nil {
+		w.logger.Error("failed to create subnet rollup alert", "subnet_id", subnetID, "error", err)
+		return false
+	}
+
+	w.logger.Warn("subnet alert rate cap reached, rolled up alerts",
+		"alert_id", alert.ID,
+		"subnet_id", subnetID,
+		"subnet", subnet.NetworkAddress,
+		"rolled_up", len(anomalies),
+		"cap", w.config.SubnetAlertRateCap,
+	)
+
+	notified := alert
+	if stored, err := w.alertStore.GetAlert(ctx, alert.ID); err == nil && stored != nil {
+		notified = stored
+	}
+	w.notify(ctx, notify.Event{
+		Type:        notify.EventAlertCreated,
+		Alert:       *notified,
+		Description: alert.Message,
+	})
+	return true
+}
+
+// updateRollup refreshes an active rollup's severity and target count.
+func (w *AlertWorker) updateRollup(ctx context.Context, rollup *types.Alert, anomalies []types.Anomaly) {
+	severity := w.rollupSeverity(anomalies)
+	w.evolveAlert(ctx, rollup, severity, nil, nil)
+
+	cidr := rollup.SubnetCIDR
+	if cidr == "" {
+		cidr = rollup.TargetIP
+	}
+	title := w.rollupTitle(cidr, len(anomalies), severity)
+	if title == rollup.Title {
+		return
+	}
+	if err := w.alertStore.UpdateAlertSummary(ctx, rollup.ID, title, w.rollupMessage(cidr, len(anomalies))); err != nil {
+		w.logger.Error("failed to update subnet rollup alert", "alert_id", rollup.ID, "error", err)
+	}
+}
+
+// resolveRollup resolves a rollup alert once its subnet is under the cap.
+func (w *AlertWorker) resolveRollup(ctx context.Context, rollup *types.Alert, desc string) {
+	if err := w.alertStore.ResolveAlert(ctx, rollup.ID, desc); err != nil {
+		w.logger.Error("failed to resolve subnet rollup alert", "alert_id", rollup.ID, "error", err)
+		return
+	}
+	w.logger.Info("subnet rollup alert resolved",
+		"alert_id", rollup.ID,
+		"subnet_id", rollup.SubnetID,
+	)
+
+	resolved := *rollup
+	now := w.now()
+	resolved.Status = types.AlertStatusResolved
+	resolved.ResolvedAt = &now
+	w.notify(ctx, notify.Event{
+		Type:        notify.EventAlertResolved,
+		Alert:       resolved,
+		Description: desc,
+	})
+}
+
+// rollupSeverity is the worst severity among the rolled-up anomalies.
+func (w *AlertWorker) rollupSeverity(anomalies []types.Anomaly) types.AlertSeverity {
+	severity := types.AlertSeverityInfo
+	for _, anomaly := range anomalies {
+		if s := w.calculateSeverity(anomaly); s.Level() > severity.Level() {
+			severity = s
+		}
+	}
+	return severity
+}
+
+func (w *AlertWorker) rollupTitle(cidr string, targets int, severity types.AlertSeverity) string {
+	return fmt.Sprintf("%s alert storm, %d targets - %s", cidr, targets, severity)
+}
+
+func (w *AlertWorker) rollupMessage(cidr string, targets int) string {
+	return fmt.Sprintf("%d targets on subnet %s have new anomalies, more than the cap of %d new alerts per %s. "+
+		"They are summarised here instead of alerting individually; per-target alerts resume when the rate drops.",
+		targets, cidr, w.config.SubnetAlertRateCap, w.config.AlertRateWindow)
+}
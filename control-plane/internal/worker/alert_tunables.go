@@ -0,0 +1,62 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// DefaultAlertTunables returns the values the workers fall back to for each
+// alert_config key they read, taken from their default configs, and the
+// evaluator's fixed thresholds.
+func DefaultAlertTunables() types.AlertTunables {
+	alerts := DefaultAlertWorkerConfig()
+	evaluator := DefaultEvaluatorWorkerConfig()
+	certs := DefaultCertExpiryWatchdogConfig()
+	asymmetry := DefaultLatencyAsymmetryWatchdogConfig()
+	shipLag := DefaultShipLagWatchdogConfig()
+	weights := types.DefaultHealthScoreWeights()
+
+	return types.AlertTunables{
+		LatencyWarningMs:          alerts.LatencyWarningMs,
+		LatencyCriticalMs:         alerts.LatencyCriticalMs,
+		PacketLossWarningPct:      alerts.PacketLossWarningPct,
+		PacketLossCriticalPct:     alerts.PacketLossCriticalPct,
+		ResolutionProbeCount:      alerts.ResolutionProbeCount,
+		IncidentCreationThreshold: alerts.IncidentCreationThreshold,
+		SubnetAlertRateCap:        alerts.SubnetAlertRateCap,
+		GlobalAlertRateCap:        alerts.GlobalAlertRateCap,
+		AlertRateWindowSeconds:    int(alerts.AlertRateWindow / time.Second),
+
+		BaselineDriftWeeks: int(evaluator.DriftLookback / (7 * 24 * time.Hour)),
+		BaselineDriftPct:   evaluator.DriftIncreasePct,
+		BaselineDriftMinMs: evaluator.DriftMinIncreaseMs,
+
+		// baseline_warmup_end() treats missing warmup keys as no warmup
+
+		CertExpiryWarningDays:  certs.WarningDays,
+		CertExpiryCriticalDays: certs.CriticalDays,
+
+		LatencyAsymmetryFactor:         config.LatencyAsymmetryFactor,
+		LatencyAsymmetryMinDeltaMs:     config.LatencyAsymmetryMinDeltaMs,
+		LatencyAsymmetrySustainMinutes: int(asymmetry.SustainFor / time.Minute),
+
+		ShipLagAlertSeconds: int(shipLag.Threshold / time.Second),
+
+		HealthScoreWeightReachability: weights.Reachability,
+		HealthScoreWeightLatency:      weights.Latency,
+		HealthScoreWeightLoss:         weights.Loss,
+		HealthScoreWeightJitter:       weights.Jitter,
+
+		Evaluator: types.EvaluatorThresholds{
+			ZScoreWarning:              evaluator.ZScoreWarningThreshold,
+			ZScoreCritical:             evaluator.ZScoreCriticalThreshold,
+			PacketLossWarningPct:       evaluator.PacketLossWarningPct,
+			PacketLossCriticalPct:      evaluator.PacketLossCriticalPct,
+			ConsecutiveFailuresForDown: evaluator.ConsecutiveFailuresForDown,
+			ConsecutiveSuccessesForUp:  evaluator.ConsecutiveSuccessesForUp,
+			MinSamplesForBaseline:      evaluator.MinSamplesForBaseline,
+		},
+	}
+}
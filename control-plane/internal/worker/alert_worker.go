@@ -5,9 +5,11 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/notify"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
 
@@ -30,11 +32,20 @@ type AlertStore interface {
 	ResolveAlert(ctx context.Context, alertID string, description string) error
 	ReopenAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error
 	UpdateAlertMetrics(ctx context.Context, alertID string, latencyMs, packetLoss *float64) error
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+
+	// Subnet lookup for rollup alerts
+	GetSubnet(ctx context.Context, id string) (*types.Subnet, error)
 
 	// Incident correlation
 	LinkAlertToIncident(ctx context.Context, alertID, incidentID string) error
 	GetUnlinkedAlertsByCorrelation(ctx context.Context, correlationKey string, window time.Duration) ([]types.Alert, error)
 
+	// Time-based escalation
+	ListEscalationPolicies(ctx context.Context, enabledOnly bool) ([]types.EscalationPolicy, error)
+	ListUnacknowledgedAlerts(ctx context.Context, limit int) ([]types.Alert, error)
+	AdvanceAlertEscalation(ctx context.Context, alertID, policyID string, step int, severity types.AlertSeverity, description string) (bool, error)
+
 	// Configuration
 	GetAlertConfigInt(ctx context.Context, key string, defaultVal int) (int, error)
 	GetAlertConfigFloat(ctx context.Context, key string, defaultVal float64) (float64, error)
@@ -73,6 +84,19 @@ type AlertWorkerConfig struct {
 	LatencyCriticalMs    float64
 	PacketLossWarningPct float64
 	PacketLossCriticalPct float64
+
+	// SubnetAlertRateCap is the most new alerts a single subnet may create
+	// per AlertRateWindow. Beyond it, the subnet's remaining anomalies are
+	// folded into one subnet_rollup alert. 0 disables the cap.
+	SubnetAlertRateCap int
+
+	// GlobalAlertRateCap is the most new alerts across all subnets per
+	// AlertRateWindow. Anomalies beyond it wait for a later cycle. 0 disables
+	// the cap.
+	GlobalAlertRateCap int
+
+	// AlertRateWindow is the sliding window both caps are measured over.
+	AlertRateWindow time.Duration
 }
 
 // DefaultAlertWorkerConfig returns sensible defaults.
@@ -87,6 +111,9 @@ func DefaultAlertWorkerConfig() AlertWorkerConfig {
 		LatencyCriticalMs:         500,
 		PacketLossWarningPct:      5,
 		PacketLossCriticalPct:     20,
+		SubnetAlertRateCap:        20,
+		GlobalAlertRateCap:        200,
+		AlertRateWindow:           time.Minute,
 	}
 }
 
@@ -97,6 +124,17 @@ type AlertWorker struct {
 	config        AlertWorkerConfig
 	logger        *slog.Logger
 	stopCh        chan struct{}
+
+	// ready is set once the first cycle has completed
+	ready atomic.Bool
+
+	// notifier receives alert lifecycle events (optional)
+	notifier notify.Notifier
+
+	// rates tracks recent alert creation for the rate caps
+	rates *alertRateLimiter
+
+	clocked
 }
 
 // NewAlertWorker creates a new alert worker.
@@ -107,9 +145,16 @@ func NewAlertWorker(alertStore AlertStore, incidentStore IncidentStore, config A
 		config:        config,
 		logger:        logger.With("component", "alert_worker"),
 		stopCh:        make(chan struct{}),
+		rates:         newAlertRateLimiter(),
 	}
 }
 
+// SetNotifier sets the destination for alert notifications.
+// Must be called before Start.
+func (w *AlertWorker) SetNotifier(n notify.Notifier) {
+	w.notifier = n
+}
+
 // Start begins the alert worker in a goroutine.
 func (w *AlertWorker) Start(ctx context.Context) {
 	go w.run(ctx)
@@ -120,6 +165,11 @@ func (w *AlertWorker) Stop() {
 	close(w.stopCh)
 }
 
+// Ready reports whether the worker has completed its first cycle.
+func (w *AlertWorker) Ready() bool {
+	return w.ready.Load()
+}
+
 func (w *AlertWorker) run(ctx context.Context) {
 	w.logger.Info("alert worker started",
 		"interval", w.config.Interval,
@@ -132,6 +182,7 @@ func (w *AlertWorker) run(ctx context.Context) {
 
 	// Run immediately on start
 	w.runOnce(ctx)
+	w.ready.Store(true)
 
 	ticker := time.NewTicker(w.config.Interval)
 	configTicker := time.NewTicker(5 * time.Minute) // Refresh config periodically
@@ -174,10 +225,19 @@ func (w *AlertWorker) refreshConfig(ctx context.Context) {
 	if val, err := w.alertStore.GetAlertConfigInt(ctx, "incident_creation_threshold", w.config.IncidentCreationThreshold); err == nil {
 		w.config.IncidentCreationThreshold = val
 	}
+	if val, err := w.alertStore.GetAlertConfigInt(ctx, "subnet_alert_rate_cap", w.config.SubnetAlertRateCap); err == nil {
+		w.config.SubnetAlertRateCap = val
+	}
+	if val, err := w.alertStore.GetAlertConfigInt(ctx, "global_alert_rate_cap", w.config.GlobalAlertRateCap); err == nil {
+		w.config.GlobalAlertRateCap = val
+	}
+	if val, err := w.alertStore.GetAlertConfigInt(ctx, "alert_rate_window_seconds", int(w.config.AlertRateWindow.Seconds())); err == nil && val > 0 {
+		w.config.AlertRateWindow = time.Duration(val) * time.Second
+	}
 }
 
 func (w *AlertWorker) runOnce(ctx context.Context) {
-	start := time.Now()
+	start := w.now()
 
 	// Phase 1: Process anomalies into alerts (create new or evolve existing)
 	created, evolved := w.processAnomalies(ctx)
@@ -188,17 +248,23 @@ func (w *AlertWorker) runOnce(ctx context.Context) {
 	// Phase 3: Correlate unlinked alerts to incidents
 	linked, incidentsCreated := w.correlateToIncidents(ctx)
 
+	// Phase 4: Escalate alerts nobody has acknowledged
+	timeEscalated := w.escalateUnacknowledged(ctx)
+
 	w.logger.Info("alert worker cycle complete",
-		"duration", time.Since(start),
+		"duration", w.since(start),
 		"alerts_created", created,
 		"alerts_evolved", evolved,
 		"alerts_resolved", resolved,
 		"alerts_linked", linked,
 		"incidents_created", incidentsCreated,
+		"alerts_time_escalated", timeEscalated,
 	)
 }
 
-// processAnomalies converts detected anomalies into alerts.
+// processAnomalies converts detected anomalies into alerts. Existing alerts
+// evolve in place; anomalies without one go through the rate caps in
+// createAlerts.
 func (w *AlertWorker) processAnomalies(ctx context.Context) (created, evolved int) {
 	anomalies, err := w.alertStore.GetCurrentAnomalies(ctx, w.config.AnomalyLookback)
 	if err != nil {
@@ -206,38 +272,49 @@ func (w *AlertWorker) processAnomalies(ctx context.Context) (created, evolved in
 		return 0, 0
 	}
 
+	// Several agents can report the same target; keep the worst anomaly per
+	// target and type so it gets a single alert
+	var pending []types.Anomaly
+	pendingIdx := make(map[string]int)
 	for _, anomaly := range anomalies {
-		c, e := w.processAnomaly(ctx, anomaly)
-		created += c
-		evolved += e
+		alertType := w.anomalyToAlertType(anomaly.AnomalyType)
+
+		// Check if there's an existing active alert for this target+type (ignoring agent_id for target-level alerts)
+		existing, err := w.alertStore.FindActiveAlertForTarget(ctx, anomaly.TargetID, alertType, "")
+		if err != nil {
+			w.logger.Error("failed to find active alert",
+				"target_id", anomaly.TargetID,
+				"error", err,
+			)
+			continue
+		}
+
+		if existing != nil {
+			evolved += w.evolveAlert(ctx, existing, w.calculateSeverity(anomaly), &anomaly.LatencyMs, &anomaly.PacketLoss)
+			continue
+		}
+
+		key := anomaly.TargetID + "/" + string(alertType)
+		if i, ok := pendingIdx[key]; ok {
+			if w.calculateSeverity(anomaly).Level() > w.calculateSeverity(pending[i]).Level() {
+				pending[i] = anomaly
+			}
+			continue
+		}
+		pendingIdx[key] = len(pending)
+		pending = append(pending, anomaly)
 	}
 
-	return created, evolved
+	return w.createAlerts(ctx, pending), evolved
 }
 
-// processAnomaly handles a single anomaly - either creates a new alert or evolves an existing one.
-func (w *AlertWorker) processAnomaly(ctx context.Context, anomaly types.Anomaly) (created, evolved int) {
+// createAlert creates a new target-level alert for an anomaly.
+func (w *AlertWorker) createAlert(ctx context.Context, anomaly types.Anomaly) bool {
 	alertType := w.anomalyToAlertType(anomaly.AnomalyType)
 	severity := w.calculateSeverity(anomaly)
-
-	// Check if there's an existing active alert for this target+type (ignoring agent_id for target-level alerts)
-	existing, err := w.alertStore.FindActiveAlertForTarget(ctx, anomaly.TargetID, alertType, "")
-	if err != nil {
-		w.logger.Error("failed to find active alert",
-			"target_id", anomaly.TargetID,
-			"error", err,
-		)
-		return 0, 0
-	}
-
 	latency := &anomaly.LatencyMs
 	packetLoss := &anomaly.PacketLoss
 
-	if existing != nil {
-		// Evolve the existing alert
-		return 0, w.evolveAlert(ctx, existing, severity, latency, packetLoss)
-	}
-
 	// Create a new alert (target-level, not per-agent)
 	alert := &types.Alert{
 		ID:              uuid.New().String(),
@@ -257,8 +334,8 @@ func (w *AlertWorker) processAnomaly(ctx context.Context, anomaly types.Anomaly)
 		CurrentPacketLoss:  packetLoss,
 		Title:           w.generateTitle(alertType, anomaly.TargetIP, severity),
 		Message:         w.generateMessage(alertType, anomaly),
-		DetectedAt:      time.Now(),
-		LastUpdatedAt:   time.Now(),
+		DetectedAt:      w.now(),
+		LastUpdatedAt:   w.now(),
 		CorrelationKey:  w.generateCorrelationKey(anomaly),
 	}
 
@@ -267,7 +344,7 @@ func (w *AlertWorker) processAnomaly(ctx context.Context, anomaly types.Anomaly)
 			"target_id", anomaly.TargetID,
 			"error", err,
 		)
-		return 0, 0
+		return false
 	}
 
 	w.logger.Info("created new alert",
@@ -278,7 +355,18 @@ func (w *AlertWorker) processAnomaly(ctx context.Context, anomaly types.Anomaly)
 		"severity", severity,
 	)
 
-	return 1, 0
+	// Re-read to pick up the subnet metadata the store attached on insert
+	notified := alert
+	if stored, err := w.alertStore.GetAlert(ctx, alert.ID); err == nil && stored != nil {
+		notified = stored
+	}
+	w.notify(ctx, notify.Event{
+		Type:        notify.EventAlertCreated,
+		Alert:       *notified,
+		Description: alert.Message,
+	})
+
+	return true
 }
 
 // evolveAlert updates an existing alert based on new anomaly data.
@@ -298,9 +386,21 @@ func (w *AlertWorker) evolveAlert(ctx context.Context, alert *types.Alert, newSe
 			"old_severity", alert.Severity,
 			"new_severity", newSeverity,
 		)
+
+		escalated := *alert
+		escalated.Severity = newSeverity
+		escalated.CurrentLatencyMs = latency
+		escalated.CurrentPacketLoss = packetLoss
+		w.notify(ctx, notify.Event{
+			Type:             notify.EventAlertEscalated,
+			Alert:            escalated,
+			PreviousSeverity: alert.Severity,
+			Description:      desc,
+		})
 		return 1
-	} else if newLevel < oldLevel {
-		// De-escalate
+	} else if newLevel < oldLevel && alert.EscalationStep == 0 {
+		// De-escalate. An alert an escalation policy raised holds its
+		// severity until someone acknowledges or it resolves.
 		desc := fmt.Sprintf("De-escalated from %s to %s", alert.Severity, newSeverity)
 		if err := w.alertStore.DeescalateAlert(ctx, alert.ID, newSeverity, latency, packetLoss, desc); err != nil {
 			w.logger.Error("failed to de-escalate alert", "alert_id", alert.ID, "error", err)
@@ -311,9 +411,20 @@ func (w *AlertWorker) evolveAlert(ctx context.Context, alert *types.Alert, newSe
 			"old_severity", alert.Severity,
 			"new_severity", newSeverity,
 		)
+
+		deescalated := *alert
+		deescalated.Severity = newSeverity
+		deescalated.CurrentLatencyMs = latency
+		deescalated.CurrentPacketLoss = packetLoss
+		w.notify(ctx, notify.Event{
+			Type:             notify.EventAlertDeescalated,
+			Alert:            deescalated,
+			PreviousSeverity: alert.Severity,
+			Description:      desc,
+		})
 		return 1
 	} else {
-		// Same severity - just update metrics
+		// Same severity, or held by escalation - just update metrics
 		if err := w.alertStore.UpdateAlertMetrics(ctx, alert.ID, latency, packetLoss); err != nil {
 			w.logger.Error("failed to update alert metrics", "alert_id", alert.ID, "error", err)
 		}
@@ -338,6 +449,12 @@ func (w *AlertWorker) checkResolutions(ctx context.Context) int {
 		}
 
 		for _, alert := range alerts {
+			// Path changes, baseline drift and pipeline health aren't
+			// outages; the route, evaluator and canary workers resolve them
+			if alert.AlertType == types.AlertTypePathChange || alert.AlertType == types.AlertTypeBaselineDrift ||
+				alert.AlertType == types.AlertTypePipelineHealth {
+				continue
+			}
 			desc := fmt.Sprintf("Target recovered after %d consecutive healthy probes", w.config.ResolutionProbeCount)
 			if err := w.alertStore.ResolveAlert(ctx, alert.ID, desc); err != nil {
 				w.logger.Error("failed to resolve alert", "alert_id", alert.ID, "error", err)
@@ -348,6 +465,15 @@ func (w *AlertWorker) checkResolutions(ctx context.Context) int {
 				"target_id", targetID,
 			)
 			resolved++
+
+			now := w.now()
+			alert.Status = types.AlertStatusResolved
+			alert.ResolvedAt = &now
+			w.notify(ctx, notify.Event{
+				Type:        notify.EventAlertResolved,
+				Alert:       alert,
+				Description: desc,
+			})
 		}
 	}
 
@@ -462,6 +588,20 @@ func (w *AlertWorker) alertSeverityToIncidentSeverity(alertSev types.AlertSeveri
 // HELPER METHODS
 // =============================================================================
 
+// notify sends an event to the configured notifier, if any. Delivery
+// failures are logged by the notifier and never block alert processing.
+func (w *AlertWorker) notify(ctx context.Context, event notify.Event) {
+	if w.notifier == nil {
+		return
+	}
+	if event.OccurredAt.IsZero() {
+		event.OccurredAt = w.now()
+	}
+	if err := w.notifier.Notify(ctx, event); err != nil {
+		w.logger.Warn("alert notification failed", "alert_id", event.Alert.ID, "event", event.Type, "error", err)
+	}
+}
+
 func (w *AlertWorker) anomalyToAlertType(anomalyType string) types.AlertType {
 	switch anomalyType {
 	case "availability":
@@ -530,8 +670,8 @@ func (w *AlertWorker) generateMessage(alertType types.AlertType, anomaly types.A
 func (w *AlertWorker) generateCorrelationKey(anomaly types.Anomaly) string {
 	// Prefer subnet-based correlation for blast radius tracking
 	if anomaly.SubnetID != "" {
-		return "subnet:" + anomaly.SubnetID
+		return types.CorrelationKey(types.RootCauseSubnet, anomaly.SubnetID)
 	}
 	// Fall back to target-based correlation
-	return "target:" + anomaly.TargetID
+	return types.CorrelationKey("target", anomaly.TargetID)
 }
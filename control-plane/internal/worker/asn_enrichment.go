@@ -0,0 +1,138 @@
+// Package worker - ASN enrichment looks up each target's origin ASN and
+// country in a local IP-to-ASN dataset and stores them on the target.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"net/netip"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/ipasn"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// ASNEnrichmentStore defines the storage interface for ASN enrichment.
+type ASNEnrichmentStore interface {
+	ListTargetsNeedingASN(ctx context.Context, checkedBefore time.Time, limit int) (map[string]string, error)
+	SetTargetASNs(ctx context.Context, results []store.TargetASN) error
+}
+
+// ASNEnrichmentConfig holds configuration for the ASN enrichment worker.
+type ASNEnrichmentConfig struct {
+	// Interval between runs. New targets wait at most this long.
+	Interval time.Duration
+
+	// RefreshAfter is how long a lookup stands before it is repeated.
+	RefreshAfter time.Duration
+
+	// BatchSize caps the targets looked up per run; a backlog drains over
+	// several runs.
+	BatchSize int
+}
+
+// DefaultASNEnrichmentConfig returns sensible defaults.
+func DefaultASNEnrichmentConfig() ASNEnrichmentConfig {
+	return ASNEnrichmentConfig{
+		Interval:     5 * time.Minute,
+		RefreshAfter: config.ASNRefreshAfter,
+		BatchSize:    5000,
+	}
+}
+
+// ASNEnrichmentWorker keeps targets' asn, as_name and country current from
+// the dataset loaded at startup. Lookups are in memory, so the cost is the
+// one UPDATE per run.
+type ASNEnrichmentWorker struct {
+	store  ASNEnrichmentStore
+	db     *ipasn.Database
+	config ASNEnrichmentConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	clocked
+}
+
+// NewASNEnrichmentWorker creates a new ASN enrichment worker.
+func NewASNEnrichmentWorker(store ASNEnrichmentStore, db *ipasn.Database, config ASNEnrichmentConfig, logger *slog.Logger) *ASNEnrichmentWorker {
+	return &ASNEnrichmentWorker{
+		store:  store,
+		db:     db,
+		config: config,
+		logger: logger.With("component", "asn_enrichment"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *ASNEnrichmentWorker) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *ASNEnrichmentWorker) Stop() {
+	close(w.stopCh)
+}
+
+func (w *ASNEnrichmentWorker) run(ctx context.Context) {
+	w.logger.Info("ASN enrichment started", "interval", w.config.Interval, "ranges", w.db.Len())
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	w.runOnce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("ASN enrichment stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("ASN enrichment stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *ASNEnrichmentWorker) runOnce(ctx context.Context) {
+	targets, err := w.store.ListTargetsNeedingASN(ctx, w.now().Add(-w.config.RefreshAfter), w.config.BatchSize)
+	if err != nil {
+		w.logger.Error("failed to list targets for ASN lookup", "error", err)
+		return
+	}
+	if len(targets) == 0 {
+		return
+	}
+
+	results := lookupTargetASNs(w.db, targets)
+	if err := w.store.SetTargetASNs(ctx, results); err != nil {
+		w.logger.Error("failed to store target ASNs", "error", err)
+		return
+	}
+
+	matched := 0
+	for _, r := range results {
+		if r.ASN != 0 {
+			matched++
+		}
+	}
+	w.logger.Info("targets enriched with ASN", "looked_up", len(results), "matched", matched)
+}
+
+// lookupTargetASNs looks up each target IP. Unmatched and unparseable IPs
+// get a zero ASN, so they are marked checked rather than retried every run.
+func lookupTargetASNs(db *ipasn.Database, targets map[string]string) []store.TargetASN {
+	results := make([]store.TargetASN, 0, len(targets))
+	for id, ip := range targets {
+		result := store.TargetASN{TargetID: id}
+		if addr, err := netip.ParseAddr(ip); err == nil {
+			if rec, ok := db.Lookup(addr); ok {
+				result.ASN, result.ASName, result.Country = rec.ASN, rec.ASName, rec.Country
+			}
+		}
+		results = append(results, result)
+	}
+	return results
+}
@@ -5,6 +5,7 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/pilot-net/icmp-mon/control-plane/internal/service"
@@ -42,6 +43,9 @@ type AssignmentWorker struct {
 	logger     *slog.Logger
 	stopCh     chan struct{}
 
+	// ready is set once the first cycle has completed
+	ready atomic.Bool
+
 	// Track last known states for change detection
 	lastKnownStates map[string]types.AgentStatus
 	statesMu        sync.RWMutex
@@ -52,6 +56,8 @@ type AssignmentWorker struct {
 
 	// Track if we've initialized (first run)
 	initialized bool
+
+	clocked
 }
 
 // NewAssignmentWorker creates a new assignment worker.
@@ -82,6 +88,11 @@ func (w *AssignmentWorker) Stop() {
 	close(w.stopCh)
 }
 
+// Ready reports whether the worker has completed its first cycle.
+func (w *AssignmentWorker) Ready() bool {
+	return w.ready.Load()
+}
+
 func (w *AssignmentWorker) run(ctx context.Context) {
 	w.logger.Info("assignment worker started",
 		"interval", w.config.Interval,
@@ -91,6 +102,7 @@ func (w *AssignmentWorker) run(ctx context.Context) {
 
 	// Run immediately on start to initialize state
 	w.runOnce(ctx)
+	w.ready.Store(true)
 
 	ticker := time.NewTicker(w.config.Interval)
 	defer ticker.Stop()
@@ -218,7 +230,7 @@ func (w *AssignmentWorker) recordTransition(agentID string) {
 	w.transitionsMu.Lock()
 	defer w.transitionsMu.Unlock()
 
-	now := time.Now()
+	now := w.now()
 	w.transitions[agentID] = append(w.transitions[agentID], now)
 
 	// Prune old transitions outside the window
@@ -237,7 +249,7 @@ func (w *AssignmentWorker) isFlapping(agentID string) bool {
 	w.transitionsMu.Lock()
 	defer w.transitionsMu.Unlock()
 
-	cutoff := time.Now().Add(-w.config.FlappingWindow)
+	cutoff := w.now().Add(-w.config.FlappingWindow)
 	count := 0
 	for _, t := range w.transitions[agentID] {
 		if t.After(cutoff) {
@@ -0,0 +1,162 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// =============================================================================
+// BASELINE DRIFT
+// =============================================================================
+//
+// Z-scores measure latency against the pair's own baseline, so a baseline
+// that creeps up week over week (chronic congestion) never looks anomalous.
+// Each refresh snapshots the baselines; the drift check then compares today's
+// baseline p95 per target with the snapshot from DriftLookback ago.
+
+// refreshBaselines recalculates every baseline, then checks for drift.
+func (w *EvaluatorWorker) refreshBaselines(ctx context.Context) {
+	start := w.now()
+	count, err := w.store.RecalculateAllBaselines(ctx)
+	if err != nil {
+		w.logger.Error("failed to recalculate baselines", "error", err)
+		return
+	}
+	w.logger.Info("baselines recalculated", "pairs", count, "duration", w.since(start))
+
+	w.refreshDriftConfig(ctx)
+	w.checkBaselineDrift(ctx)
+}
+
+// refreshDriftConfig applies drift settings from the alert_config table.
+func (w *EvaluatorWorker) refreshDriftConfig(ctx context.Context) {
+	weeks := int(w.config.DriftLookback / (7 * 24 * time.Hour))
+	if val, err := w.store.GetAlertConfigInt(ctx, "baseline_drift_weeks", weeks); err == nil && val > 0 {
+		w.config.DriftLookback = time.Duration(val) * 7 * 24 * time.Hour
+	}
+	if val, err := w.store.GetAlertConfigFloat(ctx, "baseline_drift_pct", w.config.DriftIncreasePct); err == nil && val > 0 {
+		w.config.DriftIncreasePct = val
+	}
+	if val, err := w.store.GetAlertConfigFloat(ctx, "baseline_drift_min_ms", w.config.DriftMinIncreaseMs); err == nil && val >= 0 {
+		w.config.DriftMinIncreaseMs = val
+	}
+}
+
+// checkBaselineDrift raises or updates a baseline_drift alert for each
+// drifting target and resolves alerts for targets that no longer drift.
+func (w *EvaluatorWorker) checkBaselineDrift(ctx context.Context) {
+	drifts, err := w.store.GetBaselineDrift(ctx, w.config.DriftLookback, w.config.DriftIncreasePct, w.config.DriftMinIncreaseMs)
+	if err != nil {
+		w.logger.Error("failed to get baseline drift", "error", err)
+		return
+	}
+
+	drifting := make(map[string]bool, len(drifts))
+	raised := 0
+	for _, d := range drifts {
+		drifting[d.TargetID] = true
+		created, err := w.raiseDriftAlert(ctx, d)
+		if err != nil {
+			w.logger.Error("failed to raise baseline drift alert", "target_id", d.TargetID, "error", err)
+			continue
+		}
+		if created {
+			raised++
+		}
+	}
+
+	resolved := w.resolveDriftAlerts(ctx, drifting)
+
+	w.logger.Info("baseline drift check complete",
+		"lookback", w.config.DriftLookback,
+		"drifting_targets", len(drifts),
+		"alerts_raised", raised,
+		"alerts_resolved", resolved,
+	)
+}
+
+// raiseDriftAlert creates a target-level baseline_drift alert, or refreshes
+// the text of the one already open. Reports whether an alert was created.
+func (w *EvaluatorWorker) raiseDriftAlert(ctx context.Context, d store.BaselineDrift) (bool, error) {
+	title := fmt.Sprintf("Baseline latency rising on %s", d.TargetIP)
+	message := fmt.Sprintf("Baseline p95 rose %.0f%% from %.1fms to %.1fms since %s across %d agents",
+		d.IncreasePct, d.PastP95Ms, d.CurrentP95Ms, d.PastSnapshot.Format("2006-01-02"), d.Agents)
+
+	existing, err := w.store.FindActiveAlertForTarget(ctx, d.TargetID, types.AlertTypeBaselineDrift, "")
+	if err != nil {
+		return false, fmt.Errorf("finding active alert: %w", err)
+	}
+	if existing != nil {
+		if err := w.store.UpdateAlertSummary(ctx, existing.ID, title, message); err != nil {
+			return false, fmt.Errorf("updating alert: %w", err)
+		}
+		return false, nil
+	}
+
+	now := w.now()
+	current := d.CurrentP95Ms
+	alert := &types.Alert{
+		ID:               uuid.New().String(),
+		TargetID:         d.TargetID,
+		TargetIP:         d.TargetIP,
+		AlertType:        types.AlertTypeBaselineDrift,
+		Severity:         types.AlertSeverityWarning,
+		Status:           types.AlertStatusActive,
+		InitialSeverity:  types.AlertSeverityWarning,
+		PeakSeverity:     types.AlertSeverityWarning,
+		InitialLatencyMs: &current,
+		CurrentLatencyMs: &current,
+		Title:            title,
+		Message:          message,
+		DetectedAt:       now,
+		LastUpdatedAt:    now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return false, fmt.Errorf("creating alert: %w", err)
+	}
+
+	w.logger.Warn("baseline drift detected",
+		"target_id", d.TargetID,
+		"target_ip", d.TargetIP,
+		"past_p95_ms", d.PastP95Ms,
+		"current_p95_ms", d.CurrentP95Ms,
+		"increase_pct", d.IncreasePct,
+	)
+	return true, nil
+}
+
+// resolveDriftAlerts resolves open baseline_drift alerts for targets not in
+// drifting.
+func (w *EvaluatorWorker) resolveDriftAlerts(ctx context.Context, drifting map[string]bool) int {
+	alertType := types.AlertTypeBaselineDrift
+	resolved := 0
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		alerts, err := w.store.ListAlerts(ctx, types.AlertFilter{
+			AlertType: &alertType,
+			Status:    &status,
+			Limit:     1000,
+		})
+		if err != nil {
+			w.logger.Error("failed to list baseline drift alerts", "error", err)
+			continue
+		}
+
+		for _, alert := range alerts {
+			if drifting[alert.TargetID] {
+				continue
+			}
+			if err := w.store.ResolveAlert(ctx, alert.ID, "Baseline back within drift threshold"); err != nil {
+				w.logger.Error("failed to resolve baseline drift alert", "alert_id", alert.ID, "error", err)
+				continue
+			}
+			resolved++
+		}
+	}
+	return resolved
+}
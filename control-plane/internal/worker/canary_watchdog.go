@@ -0,0 +1,277 @@
+// Package worker - Canary watchdog checks that the pipeline canary's results
+// keep landing and being evaluated, and alerts when they stop.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// CanaryStore defines the storage interface for the canary watchdog.
+type CanaryStore interface {
+	// GetCanaryAgentStatus returns each online agent's canary assignment,
+	// newest result and last evaluation.
+	GetCanaryAgentStatus(ctx context.Context, targetID string, heartbeatWindow, resultWindow time.Duration) ([]store.CanaryAgentStatus, error)
+
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+	EscalateAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error
+	DeescalateAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error
+	ResolveAlert(ctx context.Context, alertID string, description string) error
+}
+
+// CanaryAssigner assigns the canary target to agents missing it.
+type CanaryAssigner interface {
+	AssignTarget(ctx context.Context, targetID string) (int, error)
+}
+
+// CanaryWatchdogConfig holds configuration for the canary watchdog.
+type CanaryWatchdogConfig struct {
+	// Interval between checks.
+	Interval time.Duration
+
+	// ResultStaleAfter is how old an agent's newest canary result may be
+	// before its results are considered not to be landing. It must cover
+	// the canary probe interval plus agent batching and buffer flushing.
+	ResultStaleAfter time.Duration
+
+	// EvaluatorStaleAfter is how long fresh canary results may go without
+	// the evaluator processing them.
+	EvaluatorStaleAfter time.Duration
+
+	// AssignmentGrace gives a newly assigned agent time to pick up the
+	// canary and ship its first results before it is judged.
+	AssignmentGrace time.Duration
+}
+
+// DefaultCanaryWatchdogConfig returns sensible defaults.
+func DefaultCanaryWatchdogConfig() CanaryWatchdogConfig {
+	return CanaryWatchdogConfig{
+		Interval:            30 * time.Second,
+		ResultStaleAfter:    3 * time.Minute,
+		EvaluatorStaleAfter: 3 * time.Minute,
+		AssignmentGrace:     2 * time.Minute,
+	}
+}
+
+// CanaryWatchdog raises a single pipeline_health alert when the canary
+// target's results stop arriving or stop being evaluated. Target alerts
+// can't fire while the pipeline is stalled, so without it an outage of the
+// monitoring itself looks like a quiet, healthy network.
+type CanaryWatchdog struct {
+	store    CanaryStore
+	assigner CanaryAssigner
+	config   CanaryWatchdogConfig
+	logger   *slog.Logger
+	stopCh   chan struct{}
+
+	clocked
+}
+
+// NewCanaryWatchdog creates a new canary watchdog.
+func NewCanaryWatchdog(store CanaryStore, assigner CanaryAssigner, config CanaryWatchdogConfig, logger *slog.Logger) *CanaryWatchdog {
+	return &CanaryWatchdog{
+		store:    store,
+		assigner: assigner,
+		config:   config,
+		logger:   logger.With("component", "canary_watchdog"),
+		stopCh:   make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *CanaryWatchdog) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *CanaryWatchdog) Stop() {
+	close(w.stopCh)
+}
+
+func (w *CanaryWatchdog) run(ctx context.Context) {
+	w.logger.Info("canary watchdog started",
+		"interval", w.config.Interval,
+		"result_stale_after", w.config.ResultStaleAfter,
+	)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("canary watchdog stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("canary watchdog stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// canaryHealth summarizes one check.
+type canaryHealth struct {
+	Judged        int      // agents assigned long enough to be judged
+	ResultsStale  []string // agents whose canary results aren't landing
+	EvalStale     []string // agents whose fresh results aren't being evaluated
+	NeedsAssigned bool
+}
+
+// evaluateCanary classifies each online agent's canary status at now.
+func (c CanaryWatchdogConfig) evaluateCanary(statuses []store.CanaryAgentStatus, now time.Time) canaryHealth {
+	var h canaryHealth
+	for _, s := range statuses {
+		if !s.Assigned {
+			h.NeedsAssigned = true
+			continue
+		}
+		if s.AssignedAt != nil && now.Sub(*s.AssignedAt) < c.AssignmentGrace {
+			continue
+		}
+		h.Judged++
+		switch {
+		case s.LastResultAt == nil || now.Sub(*s.LastResultAt) > c.ResultStaleAfter:
+			h.ResultsStale = append(h.ResultsStale, s.AgentName)
+		case s.LastEvaluated == nil || now.Sub(*s.LastEvaluated) > c.EvaluatorStaleAfter:
+			h.EvalStale = append(h.EvalStale, s.AgentName)
+		}
+	}
+	return h
+}
+
+// severity is critical when nothing gets through (no agent's results land,
+// or none of the landing results are evaluated) and warning when only some
+// agents are affected. Empty means healthy.
+func (h canaryHealth) severity() types.AlertSeverity {
+	landing := h.Judged - len(h.ResultsStale)
+	switch {
+	case h.Judged == 0 || len(h.ResultsStale)+len(h.EvalStale) == 0:
+		return ""
+	case landing == 0 || len(h.EvalStale) == landing:
+		return types.AlertSeverityCritical
+	default:
+		return types.AlertSeverityWarning
+	}
+}
+
+func (h canaryHealth) summary() (title, message string) {
+	var parts []string
+	if n := len(h.ResultsStale); n > 0 {
+		parts = append(parts, fmt.Sprintf("no recent canary results from %d of %d agents (%s)",
+			n, h.Judged, strings.Join(h.ResultsStale, ", ")))
+	}
+	if n := len(h.EvalStale); n > 0 {
+		parts = append(parts, fmt.Sprintf("canary results from %d agents not evaluated (%s)",
+			n, strings.Join(h.EvalStale, ", ")))
+	}
+	title = "Monitoring pipeline degraded"
+	if h.severity() == types.AlertSeverityCritical {
+		title = "Monitoring pipeline stalled"
+	}
+	return title, strings.Join(parts, "; ")
+}
+
+func (w *CanaryWatchdog) runOnce(ctx context.Context) {
+	statuses, err := w.store.GetCanaryAgentStatus(ctx, types.CanaryTargetID,
+		config.AgentOfflineThreshold, w.config.ResultStaleAfter+w.config.AssignmentGrace)
+	if err != nil {
+		w.logger.Error("failed to get canary status", "error", err)
+		return
+	}
+
+	health := w.config.evaluateCanary(statuses, w.now())
+	if health.NeedsAssigned {
+		if n, err := w.assigner.AssignTarget(ctx, types.CanaryTargetID); err != nil {
+			w.logger.Error("failed to assign canary target", "error", err)
+		} else if n > 0 {
+			w.logger.Info("assigned canary target", "agents", n)
+		}
+	}
+
+	if health.Judged == 0 {
+		// No agent to judge the pipeline by; agent_down alerts cover this
+		return
+	}
+
+	existing, err := w.store.FindActiveAlertForTarget(ctx, types.CanaryTargetID, types.AlertTypePipelineHealth, "")
+	if err != nil {
+		w.logger.Error("failed to find pipeline health alert", "error", err)
+		return
+	}
+
+	severity := health.severity()
+	if severity == "" {
+		if existing != nil {
+			if err := w.store.ResolveAlert(ctx, existing.ID, "Canary results landing and evaluated from all agents"); err != nil {
+				w.logger.Error("failed to resolve pipeline health alert", "alert_id", existing.ID, "error", err)
+				return
+			}
+			w.logger.Info("pipeline health recovered", "alert_id", existing.ID)
+		}
+		return
+	}
+
+	if err := w.raise(ctx, existing, health, severity); err != nil {
+		w.logger.Error("failed to raise pipeline health alert", "error", err)
+	}
+}
+
+// raise creates the pipeline_health alert or brings the open one up to date.
+func (w *CanaryWatchdog) raise(ctx context.Context, existing *types.Alert, h canaryHealth, severity types.AlertSeverity) error {
+	title, message := h.summary()
+
+	if existing != nil {
+		if existing.Severity != severity {
+			change := w.store.DeescalateAlert
+			if severity == types.AlertSeverityCritical {
+				change = w.store.EscalateAlert
+			}
+			if err := change(ctx, existing.ID, severity, nil, nil, message); err != nil {
+				return fmt.Errorf("changing alert severity: %w", err)
+			}
+		}
+		if err := w.store.UpdateAlertSummary(ctx, existing.ID, title, message); err != nil {
+			return fmt.Errorf("updating alert: %w", err)
+		}
+		return nil
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetID:        types.CanaryTargetID,
+		AlertType:       types.AlertTypePipelineHealth,
+		Severity:        severity,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: severity,
+		PeakSeverity:    severity,
+		Title:           title,
+		Message:         message,
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return fmt.Errorf("creating alert: %w", err)
+	}
+
+	w.logger.Warn("monitoring pipeline unhealthy",
+		"severity", severity,
+		"results_stale", len(h.ResultsStale),
+		"evaluation_stale", len(h.EvalStale),
+		"agents", h.Judged,
+	)
+	return nil
+}
@@ -0,0 +1,288 @@
+// Package worker - Certificate expiry watchdog alerts when a tls_cert
+// target's certificate nears or passes its expiry.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// CertExpiryStore defines the storage interface for the certificate expiry
+// watchdog.
+type CertExpiryStore interface {
+	ListTargetCertificates(ctx context.Context, filter types.CertificateFilter) ([]types.TargetCertificate, error)
+	GetAlertConfigInt(ctx context.Context, key string, defaultVal int) (int, error)
+
+	ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error)
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+	EscalateAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error
+	DeescalateAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error
+	ResolveAlert(ctx context.Context, alertID string, description string) error
+}
+
+// CertExpiryWatchdogConfig holds configuration for the certificate expiry
+// watchdog.
+type CertExpiryWatchdogConfig struct {
+	// Interval between checks. Expiry moves in days, so this can be slow.
+	Interval time.Duration
+
+	// WarningDays and CriticalDays are the defaults for the
+	// cert_expiry_warning_days and cert_expiry_critical_days alert_config
+	// keys, which are re-read every check.
+	WarningDays  int
+	CriticalDays int
+
+	// StaleAfter is how long a certificate may go unobserved and still be
+	// judged.
+	StaleAfter time.Duration
+
+	// MaxCertificates caps the expiring certificates read per check.
+	MaxCertificates int
+
+	// MaxNewAlerts caps the alerts created per check.
+	MaxNewAlerts int
+}
+
+// DefaultCertExpiryWatchdogConfig returns sensible defaults.
+func DefaultCertExpiryWatchdogConfig() CertExpiryWatchdogConfig {
+	return CertExpiryWatchdogConfig{
+		Interval:        15 * time.Minute,
+		WarningDays:     config.CertExpiryWarningDays,
+		CriticalDays:    config.CertExpiryCriticalDays,
+		StaleAfter:      config.CertStaleAfter,
+		MaxCertificates: 10000,
+		MaxNewAlerts:    100,
+	}
+}
+
+// CertExpiryWatchdog raises a cert_expiry alert on each tls_cert target
+// whose latest certificate is within the warning threshold of expiry,
+// critical within the critical threshold or once expired, and resolves it
+// when a renewed certificate is seen.
+type CertExpiryWatchdog struct {
+	store  CertExpiryStore
+	config CertExpiryWatchdogConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	clocked
+}
+
+// NewCertExpiryWatchdog creates a new certificate expiry watchdog.
+func NewCertExpiryWatchdog(store CertExpiryStore, config CertExpiryWatchdogConfig, logger *slog.Logger) *CertExpiryWatchdog {
+	return &CertExpiryWatchdog{
+		store:  store,
+		config: config,
+		logger: logger.With("component", "cert_expiry_watchdog"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *CertExpiryWatchdog) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *CertExpiryWatchdog) Stop() {
+	close(w.stopCh)
+}
+
+func (w *CertExpiryWatchdog) run(ctx context.Context) {
+	w.logger.Info("certificate expiry watchdog started", "interval", w.config.Interval)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	w.runOnce(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("certificate expiry watchdog stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("certificate expiry watchdog stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// thresholds reads the warning and critical days from alert_config.
+func (w *CertExpiryWatchdog) thresholds(ctx context.Context) (warningDays, criticalDays int) {
+	warningDays, criticalDays = w.config.WarningDays, w.config.CriticalDays
+	if val, err := w.store.GetAlertConfigInt(ctx, "cert_expiry_warning_days", warningDays); err == nil && val > 0 {
+		warningDays = val
+	}
+	if val, err := w.store.GetAlertConfigInt(ctx, "cert_expiry_critical_days", criticalDays); err == nil && val >= 0 {
+		criticalDays = val
+	}
+	return warningDays, criticalDays
+}
+
+func (w *CertExpiryWatchdog) runOnce(ctx context.Context) {
+	warningDays, criticalDays := w.thresholds(ctx)
+	certs, err := w.store.ListTargetCertificates(ctx, types.CertificateFilter{
+		WithinDays:    &warningDays,
+		ObservedSince: w.now().Add(-w.config.StaleAfter),
+		Limit:         w.config.MaxCertificates,
+	})
+	if err != nil {
+		w.logger.Error("failed to list expiring certificates", "error", err)
+		return
+	}
+
+	now := w.now()
+	expiring := make(map[string]bool, len(certs))
+	created, deferred := 0, 0
+	for _, c := range certs {
+		c.DaysRemaining = types.DaysUntil(c.NotAfter, now)
+		severity, alert := types.CertExpirySeverity(c.DaysRemaining, warningDays, criticalDays)
+		if !alert {
+			continue
+		}
+		expiring[c.TargetID] = true
+
+		existing, err := w.store.FindActiveAlertForTarget(ctx, c.TargetID, types.AlertTypeCertExpiry, "")
+		if err != nil {
+			w.logger.Error("failed to find cert expiry alert", "target_id", c.TargetID, "error", err)
+			continue
+		}
+		if existing == nil && created >= w.config.MaxNewAlerts {
+			deferred++
+			continue
+		}
+		if err := w.raise(ctx, existing, c, severity); err != nil {
+			w.logger.Error("failed to raise cert expiry alert", "target_id", c.TargetID, "error", err)
+			continue
+		}
+		if existing == nil {
+			created++
+		}
+	}
+
+	resolved := w.resolveRenewed(ctx, expiring)
+
+	if len(expiring) > 0 || resolved > 0 {
+		w.logger.Info("certificate expiry check complete",
+			"expiring", len(expiring),
+			"alerts_raised", created,
+			"alerts_deferred", deferred,
+			"alerts_resolved", resolved,
+		)
+	}
+}
+
+// certExpirySummary is the alert title and message for a certificate.
+func certExpirySummary(c types.TargetCertificate) (title, message string) {
+	endpoint := net.JoinHostPort(c.TargetIP, strconv.Itoa(c.Port))
+	if c.DisplayName != "" {
+		endpoint = c.DisplayName + " (" + endpoint + ")"
+	}
+
+	if c.DaysRemaining < 0 {
+		title = "TLS certificate expired on " + endpoint
+		message = fmt.Sprintf("Certificate expired %d days ago", -c.DaysRemaining)
+	} else {
+		title = "TLS certificate expiring on " + endpoint
+		message = fmt.Sprintf("Certificate expires in %d days", c.DaysRemaining)
+	}
+	message += fmt.Sprintf(" (not after %s); subject %s, issuer %s, serial %s",
+		c.NotAfter.UTC().Format(time.RFC3339), c.Subject, c.Issuer, c.SerialNumber)
+	if c.ServerName != "" {
+		message += ", server name " + c.ServerName
+	}
+	return title, message
+}
+
+// raise creates the target's cert_expiry alert or brings the open one up to
+// date.
+func (w *CertExpiryWatchdog) raise(ctx context.Context, existing *types.Alert, c types.TargetCertificate, severity types.AlertSeverity) error {
+	title, message := certExpirySummary(c)
+
+	if existing != nil {
+		if existing.Severity != severity {
+			change := w.store.DeescalateAlert
+			if severity == types.AlertSeverityCritical {
+				change = w.store.EscalateAlert
+			}
+			if err := change(ctx, existing.ID, severity, nil, nil, message); err != nil {
+				return fmt.Errorf("changing alert severity: %w", err)
+			}
+		}
+		if err := w.store.UpdateAlertSummary(ctx, existing.ID, title, message); err != nil {
+			return fmt.Errorf("updating alert: %w", err)
+		}
+		return nil
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetID:        c.TargetID,
+		TargetIP:        c.TargetIP,
+		AlertType:       types.AlertTypeCertExpiry,
+		Severity:        severity,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: severity,
+		PeakSeverity:    severity,
+		Title:           title,
+		Message:         message,
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return fmt.Errorf("creating alert: %w", err)
+	}
+
+	w.logger.Warn("certificate expiring",
+		"target_id", c.TargetID,
+		"target_ip", c.TargetIP,
+		"port", c.Port,
+		"not_after", c.NotAfter,
+		"days_remaining", c.DaysRemaining,
+	)
+	return nil
+}
+
+// resolveRenewed resolves open cert_expiry alerts for targets whose
+// certificate is no longer expiring: renewed, or no longer observed.
+func (w *CertExpiryWatchdog) resolveRenewed(ctx context.Context, expiring map[string]bool) int {
+	alertType := types.AlertTypeCertExpiry
+	resolved := 0
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		alerts, err := w.store.ListAlerts(ctx, types.AlertFilter{
+			AlertType: &alertType,
+			Status:    &status,
+			Limit:     1000,
+		})
+		if err != nil {
+			w.logger.Error("failed to list cert expiry alerts", "error", err)
+			continue
+		}
+
+		for _, alert := range alerts {
+			if expiring[alert.TargetID] {
+				continue
+			}
+			if err := w.store.ResolveAlert(ctx, alert.ID, "Certificate renewed or no longer observed"); err != nil {
+				w.logger.Error("failed to resolve cert expiry alert", "alert_id", alert.ID, "error", err)
+				continue
+			}
+			resolved++
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,34 @@
+package worker
+
+import (
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/clock"
+)
+
+// clocked gives a worker an injectable clock. Workers embed it and read the
+// time through now and since; the zero value uses the system clock, so
+// constructors don't need to set it.
+type clocked struct {
+	clock clock.Clock
+}
+
+// SetClock replaces the worker's clock, letting tests step through
+// thresholds and windows without sleeping. Must be called before Start.
+func (c *clocked) SetClock(clk clock.Clock) {
+	c.clock = clk
+}
+
+func (c *clocked) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+func (c *clocked) since(t time.Time) time.Duration {
+	if c.clock == nil {
+		return time.Since(t)
+	}
+	return c.clock.Since(t)
+}
@@ -0,0 +1,227 @@
+// Package worker - environment overrides for worker configs.
+package worker
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// Each worker's FromEnv function starts from its defaults and applies
+// ICMPMON_<WORKER>_<FIELD> variables, e.g. ICMPMON_EVALUATOR_WORKER_INTERVAL=15s.
+// Durations use Go syntax; every value is bounds-checked, and an invalid
+// one is an error rather than a silent fallback to the default.
+
+// envField is one overridable config field.
+type envField struct {
+	field    string
+	duration *time.Duration
+	integer  *int
+	flag     *bool
+	min, max int64 // nanoseconds for durations
+}
+
+func intervalField(dst *time.Duration) envField {
+	return envField{field: "INTERVAL", duration: dst, min: int64(config.WorkerIntervalMin), max: int64(config.WorkerIntervalMax)}
+}
+
+func windowField(field string, dst *time.Duration) envField {
+	return envField{field: field, duration: dst, min: int64(config.WorkerIntervalMin), max: int64(config.WorkerWindowMax)}
+}
+
+func batchField(field string, dst *int) envField {
+	return envField{field: field, integer: dst, min: 1, max: config.WorkerBatchSizeMax}
+}
+
+func flagField(field string, dst *bool) envField {
+	return envField{field: field, flag: dst}
+}
+
+// applyEnv applies ICMPMON_<worker>_<field> overrides to fields.
+func applyEnv(worker string, fields ...envField) error {
+	for _, f := range fields {
+		name := "ICMPMON_" + strings.ToUpper(worker) + "_" + f.field
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+
+		if f.flag != nil {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q: want true or false", name, v)
+			}
+			*f.flag = b
+			continue
+		}
+
+		if f.duration != nil {
+			d, err := time.ParseDuration(v)
+			if err != nil || int64(d) < f.min || int64(d) > f.max {
+				return fmt.Errorf("invalid %s %q: want a duration from %s to %s", name, v, time.Duration(f.min), time.Duration(f.max))
+			}
+			*f.duration = d
+			continue
+		}
+
+		n, err := strconv.Atoi(v)
+		if err != nil || int64(n) < f.min || int64(n) > f.max {
+			return fmt.Errorf("invalid %s %q: want an integer from %d to %d", name, v, f.min, f.max)
+		}
+		*f.integer = n
+	}
+	return nil
+}
+
+// StateWorkerConfigFromEnv overrides the state worker's interval, state
+// thresholds and never-responded archival policy.
+func StateWorkerConfigFromEnv() (StateWorkerConfig, error) {
+	cfg := DefaultStateWorkerConfig()
+	err := applyEnv("state_worker",
+		intervalField(&cfg.Interval),
+		windowField("BASELINE_THRESHOLD", &cfg.BaselineThreshold),
+		windowField("DOWN_THRESHOLD", &cfg.DownThreshold),
+		windowField("UNRESPONSIVE_THRESHOLD", &cfg.UnresponsiveThreshold),
+		windowField("EXCLUDED_THRESHOLD", &cfg.ExcludedThreshold),
+		flagField("NEVER_RESPONDED_ARCHIVE", &cfg.NeverResponded.Enabled),
+		windowField("NEVER_RESPONDED_WINDOW", &cfg.NeverResponded.DiscoveryWindow),
+		batchField("NEVER_RESPONDED_MAX_ATTEMPTS", &cfg.NeverResponded.MaxAttempts),
+	)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.ExcludedThreshold <= cfg.DownThreshold {
+		return cfg, fmt.Errorf("state worker excluded threshold (%s) must be longer than the down threshold (%s)", cfg.ExcludedThreshold, cfg.DownThreshold)
+	}
+	return cfg, nil
+}
+
+// AssignmentWorkerConfigFromEnv overrides the assignment worker's interval.
+func AssignmentWorkerConfigFromEnv() (AssignmentWorkerConfig, error) {
+	cfg := DefaultAssignmentWorkerConfig()
+	return cfg, applyEnv("assignment_worker", intervalField(&cfg.Interval))
+}
+
+// EvaluatorWorkerConfigFromEnv overrides the evaluator's interval, the
+// window of results each run evaluates, and how often baselines refresh.
+func EvaluatorWorkerConfigFromEnv() (EvaluatorWorkerConfig, error) {
+	cfg := DefaultEvaluatorWorkerConfig()
+	err := applyEnv("evaluator_worker",
+		intervalField(&cfg.Interval),
+		windowField("EVALUATION_WINDOW", &cfg.EvaluationWindow),
+		windowField("BASELINE_REFRESH_INTERVAL", &cfg.BaselineRefreshInterval),
+	)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.EvaluationWindow < cfg.Interval {
+		return cfg, fmt.Errorf("evaluator evaluation window (%s) must be at least the interval (%s)", cfg.EvaluationWindow, cfg.Interval)
+	}
+	return cfg, nil
+}
+
+// AlertWorkerConfigFromEnv overrides the alert worker's interval. Its
+// thresholds are alert_config tunables.
+func AlertWorkerConfigFromEnv() (AlertWorkerConfig, error) {
+	cfg := DefaultAlertWorkerConfig()
+	return cfg, applyEnv("alert_worker", intervalField(&cfg.Interval))
+}
+
+// ReportWorkerConfigFromEnv overrides the report worker's interval.
+func ReportWorkerConfigFromEnv() (ReportWorkerConfig, error) {
+	cfg := DefaultReportWorkerConfig()
+	return cfg, applyEnv("report_worker", intervalField(&cfg.Interval))
+}
+
+// RetentionWorkerConfigFromEnv overrides the retention worker's interval.
+func RetentionWorkerConfigFromEnv() (RetentionWorkerConfig, error) {
+	cfg := DefaultRetentionWorkerConfig()
+	return cfg, applyEnv("retention_worker", intervalField(&cfg.Interval))
+}
+
+// CanaryWatchdogConfigFromEnv overrides the canary watchdog's interval.
+func CanaryWatchdogConfigFromEnv() (CanaryWatchdogConfig, error) {
+	cfg := DefaultCanaryWatchdogConfig()
+	return cfg, applyEnv("canary_watchdog", intervalField(&cfg.Interval))
+}
+
+// CoverageWatchdogConfigFromEnv overrides the coverage watchdog's interval.
+func CoverageWatchdogConfigFromEnv() (CoverageWatchdogConfig, error) {
+	cfg := DefaultCoverageWatchdogConfig()
+	return cfg, applyEnv("coverage_watchdog", intervalField(&cfg.Interval))
+}
+
+// CertExpiryWatchdogConfigFromEnv overrides the certificate expiry
+// watchdog's interval.
+func CertExpiryWatchdogConfigFromEnv() (CertExpiryWatchdogConfig, error) {
+	cfg := DefaultCertExpiryWatchdogConfig()
+	return cfg, applyEnv("cert_expiry_watchdog", intervalField(&cfg.Interval))
+}
+
+// EndpointFamilyWatchdogConfigFromEnv overrides the endpoint family
+// watchdog's interval.
+func EndpointFamilyWatchdogConfigFromEnv() (EndpointFamilyWatchdogConfig, error) {
+	cfg := DefaultEndpointFamilyWatchdogConfig()
+	return cfg, applyEnv("endpoint_family_watchdog", intervalField(&cfg.Interval))
+}
+
+// LatencyAsymmetryWatchdogConfigFromEnv overrides the latency asymmetry
+// watchdog's interval.
+func LatencyAsymmetryWatchdogConfigFromEnv() (LatencyAsymmetryWatchdogConfig, error) {
+	cfg := DefaultLatencyAsymmetryWatchdogConfig()
+	return cfg, applyEnv("latency_asymmetry_watchdog", intervalField(&cfg.Interval))
+}
+
+// ShipLagWatchdogConfigFromEnv overrides the ship lag watchdog's interval.
+func ShipLagWatchdogConfigFromEnv() (ShipLagWatchdogConfig, error) {
+	cfg := DefaultShipLagWatchdogConfig()
+	return cfg, applyEnv("ship_lag_watchdog", intervalField(&cfg.Interval))
+}
+
+// ASNEnrichmentConfigFromEnv overrides ASN enrichment's interval and the
+// targets enriched per run.
+func ASNEnrichmentConfigFromEnv() (ASNEnrichmentConfig, error) {
+	cfg := DefaultASNEnrichmentConfig()
+	return cfg, applyEnv("asn_enrichment",
+		intervalField(&cfg.Interval),
+		batchField("BATCH_SIZE", &cfg.BatchSize),
+	)
+}
+
+// EventDispatcherConfigFromEnv overrides the event dispatcher's interval
+// and batch sizes.
+func EventDispatcherConfigFromEnv() (EventDispatcherConfig, error) {
+	cfg := DefaultEventDispatcherConfig()
+	return cfg, applyEnv("event_dispatcher",
+		intervalField(&cfg.Interval),
+		batchField("SEQUENCE_BATCH", &cfg.SequenceBatch),
+		batchField("DELIVERY_BATCH", &cfg.DeliveryBatch),
+	)
+}
+
+// RouteWorkerConfigFromEnv overrides the route worker's interval.
+func RouteWorkerConfigFromEnv() (RouteWorkerConfig, error) {
+	cfg := DefaultRouteWorkerConfig()
+	return cfg, applyEnv("route_worker", intervalField(&cfg.Interval))
+}
+
+// PilotSyncConfigFromEnv overrides the Pilot sync's incremental and full
+// sync intervals.
+func PilotSyncConfigFromEnv() (PilotSyncConfig, error) {
+	cfg := DefaultPilotSyncConfig()
+	err := applyEnv("pilot_sync",
+		intervalField(&cfg.Interval),
+		windowField("FULL_SYNC_INTERVAL", &cfg.FullSyncInterval),
+	)
+	if err != nil {
+		return cfg, err
+	}
+	if cfg.FullSyncInterval < cfg.Interval {
+		return cfg, fmt.Errorf("pilot sync full sync interval (%s) must be at least the interval (%s)", cfg.FullSyncInterval, cfg.Interval)
+	}
+	return cfg, nil
+}
@@ -0,0 +1,167 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+// testEnvConfig is a config with one field of each kind applyEnv handles.
+type testEnvConfig struct {
+	Interval  time.Duration
+	Window    time.Duration
+	BatchSize int
+	Enabled   bool
+}
+
+func TestApplyEnv_Values(t *testing.T) {
+	defaults := testEnvConfig{Interval: time.Minute, Window: time.Hour, BatchSize: 100, Enabled: true}
+
+	tests := []struct {
+		name    string
+		env     map[string]string
+		want    testEnvConfig
+		wantErr bool
+	}{
+		{name: "unset keeps defaults", want: defaults},
+		{
+			name: "valid values",
+			env: map[string]string{
+				"ICMPMON_TEST_WORKER_INTERVAL":   "15s",
+				"ICMPMON_TEST_WORKER_WINDOW":     "720h",
+				"ICMPMON_TEST_WORKER_BATCH_SIZE": "100000",
+				"ICMPMON_TEST_WORKER_ENABLED":    "false",
+			},
+			want: testEnvConfig{Interval: 15 * time.Second, Window: 720 * time.Hour, BatchSize: 100000, Enabled: false},
+		},
+		{
+			name: "lower bounds",
+			env: map[string]string{
+				"ICMPMON_TEST_WORKER_INTERVAL":   "1s",
+				"ICMPMON_TEST_WORKER_WINDOW":     "1s",
+				"ICMPMON_TEST_WORKER_BATCH_SIZE": "1",
+			},
+			want: testEnvConfig{Interval: time.Second, Window: time.Second, BatchSize: 1, Enabled: true},
+		},
+		{name: "interval below minimum", env: map[string]string{"ICMPMON_TEST_WORKER_INTERVAL": "500ms"}, wantErr: true},
+		{name: "interval above maximum", env: map[string]string{"ICMPMON_TEST_WORKER_INTERVAL": "25h"}, wantErr: true},
+		{name: "window above maximum", env: map[string]string{"ICMPMON_TEST_WORKER_WINDOW": "721h"}, wantErr: true},
+		{name: "batch size zero", env: map[string]string{"ICMPMON_TEST_WORKER_BATCH_SIZE": "0"}, wantErr: true},
+		{name: "batch size above maximum", env: map[string]string{"ICMPMON_TEST_WORKER_BATCH_SIZE": "100001"}, wantErr: true},
+		{name: "duration unparseable", env: map[string]string{"ICMPMON_TEST_WORKER_INTERVAL": "30"}, wantErr: true},
+		{name: "integer unparseable", env: map[string]string{"ICMPMON_TEST_WORKER_BATCH_SIZE": "1e3"}, wantErr: true},
+		{name: "flag unparseable", env: map[string]string{"ICMPMON_TEST_WORKER_ENABLED": "yes"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			got := defaults
+			err := applyEnv("test_worker",
+				intervalField(&got.Interval),
+				windowField("WINDOW", &got.Window),
+				batchField("BATCH_SIZE", &got.BatchSize),
+				flagField("ENABLED", &got.Enabled),
+			)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("applyEnv() = nil, want error; config %+v", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("applyEnv() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("config = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv_CrossField(t *testing.T) {
+	state := func() error { _, err := StateWorkerConfigFromEnv(); return err }
+	evaluator := func() error { _, err := EvaluatorWorkerConfigFromEnv(); return err }
+	pilot := func() error { _, err := PilotSyncConfigFromEnv(); return err }
+
+	tests := []struct {
+		name    string
+		load    func() error
+		env     map[string]string
+		wantErr bool
+	}{
+		{name: "state defaults", load: state},
+		{
+			name: "excluded threshold longer than down threshold",
+			load: state,
+			env: map[string]string{
+				"ICMPMON_STATE_WORKER_DOWN_THRESHOLD":     "2h",
+				"ICMPMON_STATE_WORKER_EXCLUDED_THRESHOLD": "3h",
+			},
+		},
+		{
+			name: "excluded threshold equal to down threshold",
+			load: state,
+			env: map[string]string{
+				"ICMPMON_STATE_WORKER_DOWN_THRESHOLD":     "2h",
+				"ICMPMON_STATE_WORKER_EXCLUDED_THRESHOLD": "2h",
+			},
+			wantErr: true,
+		},
+		{
+			name:    "excluded threshold shorter than default down threshold",
+			load:    state,
+			env:     map[string]string{"ICMPMON_STATE_WORKER_EXCLUDED_THRESHOLD": "10m"},
+			wantErr: true,
+		},
+		{name: "evaluator defaults", load: evaluator},
+		{
+			name: "evaluation window equal to interval",
+			load: evaluator,
+			env: map[string]string{
+				"ICMPMON_EVALUATOR_WORKER_INTERVAL":          "10m",
+				"ICMPMON_EVALUATOR_WORKER_EVALUATION_WINDOW": "10m",
+			},
+		},
+		{
+			name: "evaluation window shorter than interval",
+			load: evaluator,
+			env: map[string]string{
+				"ICMPMON_EVALUATOR_WORKER_INTERVAL":          "10m",
+				"ICMPMON_EVALUATOR_WORKER_EVALUATION_WINDOW": "5m",
+			},
+			wantErr: true,
+		},
+		{name: "pilot sync defaults", load: pilot},
+		{
+			name: "full sync interval equal to interval",
+			load: pilot,
+			env: map[string]string{
+				"ICMPMON_PILOT_SYNC_INTERVAL":           "2h",
+				"ICMPMON_PILOT_SYNC_FULL_SYNC_INTERVAL": "2h",
+			},
+		},
+		{
+			name: "full sync interval shorter than interval",
+			load: pilot,
+			env: map[string]string{
+				"ICMPMON_PILOT_SYNC_INTERVAL":           "2h",
+				"ICMPMON_PILOT_SYNC_FULL_SYNC_INTERVAL": "1h",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+			if err := tt.load(); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,272 @@
+// Package worker - Coverage watchdog alerts when a target has fewer agents
+// reporting on it than its tier requires.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// CoverageStore defines the storage interface for the coverage watchdog.
+type CoverageStore interface {
+	GetUnderCoveredTargets(ctx context.Context, window time.Duration, excludeTargetID string) ([]store.UnderCoveredTarget, error)
+
+	ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error)
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+	EscalateAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error
+	DeescalateAlert(ctx context.Context, alertID string, newSeverity types.AlertSeverity, latencyMs, packetLoss *float64, description string) error
+	ResolveAlert(ctx context.Context, alertID string, description string) error
+}
+
+// CoverageWatchdogConfig holds configuration for the coverage watchdog.
+type CoverageWatchdogConfig struct {
+	// Interval between checks.
+	Interval time.Duration
+
+	// ReportingWindow is how recently an agent must have sent a result for
+	// a target to count as reporting on it. It must cover the slowest tier
+	// interval plus agent batching.
+	ReportingWindow time.Duration
+
+	// SustainFor is how long a target must stay under-covered before an
+	// alert is raised. It gives assignment failover time to move an offline
+	// agent's targets to healthy agents.
+	SustainFor time.Duration
+
+	// MaxNewAlerts caps the alerts created per check, so a fleet-wide
+	// outage doesn't open one per target at once; the rest are raised on
+	// later checks if still under-covered.
+	MaxNewAlerts int
+}
+
+// DefaultCoverageWatchdogConfig returns sensible defaults.
+func DefaultCoverageWatchdogConfig() CoverageWatchdogConfig {
+	return CoverageWatchdogConfig{
+		Interval:        time.Minute,
+		ReportingWindow: 5 * time.Minute,
+		SustainFor:      10 * time.Minute,
+		MaxNewAlerts:    100,
+	}
+}
+
+// CoverageWatchdog raises a coverage alert on each target that has had fewer
+// reporting agents than its tier requires for SustainFor. Reachability
+// alerts judge what the reporting agents see; this one flags that too few
+// are looking, so a target isn't quietly monitored by one agent while the
+// others are down.
+type CoverageWatchdog struct {
+	store  CoverageStore
+	config CoverageWatchdogConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	// underSince records when each target was first seen under-covered in
+	// the current run of checks. It is in-memory, so a restart restarts the
+	// sustain period.
+	underSince map[string]time.Time
+
+	clocked
+}
+
+// NewCoverageWatchdog creates a new coverage watchdog.
+func NewCoverageWatchdog(store CoverageStore, config CoverageWatchdogConfig, logger *slog.Logger) *CoverageWatchdog {
+	return &CoverageWatchdog{
+		store:      store,
+		config:     config,
+		logger:     logger.With("component", "coverage_watchdog"),
+		stopCh:     make(chan struct{}),
+		underSince: make(map[string]time.Time),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *CoverageWatchdog) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *CoverageWatchdog) Stop() {
+	close(w.stopCh)
+}
+
+func (w *CoverageWatchdog) run(ctx context.Context) {
+	w.logger.Info("coverage watchdog started",
+		"interval", w.config.Interval,
+		"reporting_window", w.config.ReportingWindow,
+		"sustain_for", w.config.SustainFor,
+	)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("coverage watchdog stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("coverage watchdog stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *CoverageWatchdog) runOnce(ctx context.Context) {
+	under, err := w.store.GetUnderCoveredTargets(ctx, w.config.ReportingWindow, types.CanaryTargetID)
+	if err != nil {
+		w.logger.Error("failed to get under-covered targets", "error", err)
+		return
+	}
+
+	now := w.now()
+	current := make(map[string]bool, len(under))
+	created, deferred := 0, 0
+	for _, t := range under {
+		current[t.TargetID] = true
+		since, ok := w.underSince[t.TargetID]
+		if !ok {
+			w.underSince[t.TargetID] = now
+			since = now
+		}
+		if now.Sub(since) < w.config.SustainFor {
+			continue
+		}
+
+		existing, err := w.store.FindActiveAlertForTarget(ctx, t.TargetID, types.AlertTypeCoverage, "")
+		if err != nil {
+			w.logger.Error("failed to find coverage alert", "target_id", t.TargetID, "error", err)
+			continue
+		}
+		if existing == nil && created >= w.config.MaxNewAlerts {
+			deferred++
+			continue
+		}
+		if err := w.raise(ctx, existing, t, since); err != nil {
+			w.logger.Error("failed to raise coverage alert", "target_id", t.TargetID, "error", err)
+			continue
+		}
+		if existing == nil {
+			created++
+		}
+	}
+
+	for id := range w.underSince {
+		if !current[id] {
+			delete(w.underSince, id)
+		}
+	}
+
+	resolved := w.resolveRecovered(ctx, current)
+
+	if len(under) > 0 || resolved > 0 {
+		w.logger.Info("coverage check complete",
+			"under_covered", len(under),
+			"alerts_raised", created,
+			"alerts_deferred", deferred,
+			"alerts_resolved", resolved,
+		)
+	}
+}
+
+// coverageSeverity is critical when no agent is reporting on the target at
+// all, leaving it unmonitored, and warning when some still are.
+func coverageSeverity(t store.UnderCoveredTarget) types.AlertSeverity {
+	if t.Reporting == 0 {
+		return types.AlertSeverityCritical
+	}
+	return types.AlertSeverityWarning
+}
+
+// raise creates the target's coverage alert or brings the open one up to date.
+func (w *CoverageWatchdog) raise(ctx context.Context, existing *types.Alert, t store.UnderCoveredTarget, since time.Time) error {
+	severity := coverageSeverity(t)
+	title := fmt.Sprintf("Monitoring coverage low on %s", t.TargetIP)
+	message := fmt.Sprintf("%d of %d assigned agents reporting; tier %s requires %d (under-covered since %s)",
+		t.Reporting, t.Assigned, t.Tier, t.Required, since.UTC().Format(time.RFC3339))
+
+	if existing != nil {
+		if existing.Severity != severity {
+			change := w.store.DeescalateAlert
+			if severity == types.AlertSeverityCritical {
+				change = w.store.EscalateAlert
+			}
+			if err := change(ctx, existing.ID, severity, nil, nil, message); err != nil {
+				return fmt.Errorf("changing alert severity: %w", err)
+			}
+		}
+		if err := w.store.UpdateAlertSummary(ctx, existing.ID, title, message); err != nil {
+			return fmt.Errorf("updating alert: %w", err)
+		}
+		return nil
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetID:        t.TargetID,
+		TargetIP:        t.TargetIP,
+		AlertType:       types.AlertTypeCoverage,
+		Severity:        severity,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: severity,
+		PeakSeverity:    severity,
+		Title:           title,
+		Message:         message,
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return fmt.Errorf("creating alert: %w", err)
+	}
+
+	w.logger.Warn("target under-covered",
+		"target_id", t.TargetID,
+		"target_ip", t.TargetIP,
+		"tier", t.Tier,
+		"reporting", t.Reporting,
+		"required", t.Required,
+		"assigned", t.Assigned,
+	)
+	return nil
+}
+
+// resolveRecovered resolves open coverage alerts for targets no longer
+// under-covered.
+func (w *CoverageWatchdog) resolveRecovered(ctx context.Context, under map[string]bool) int {
+	alertType := types.AlertTypeCoverage
+	resolved := 0
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		alerts, err := w.store.ListAlerts(ctx, types.AlertFilter{
+			AlertType: &alertType,
+			Status:    &status,
+			Limit:     1000,
+		})
+		if err != nil {
+			w.logger.Error("failed to list coverage alerts", "error", err)
+			continue
+		}
+
+		for _, alert := range alerts {
+			if under[alert.TargetID] {
+				continue
+			}
+			if err := w.store.ResolveAlert(ctx, alert.ID, "Enough agents reporting again"); err != nil {
+				w.logger.Error("failed to resolve coverage alert", "alert_id", alert.ID, "error", err)
+				continue
+			}
+			resolved++
+		}
+	}
+	return resolved
+}
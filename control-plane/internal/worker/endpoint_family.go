@@ -0,0 +1,236 @@
+// Package worker - Endpoint family watchdog alerts when one address family
+// of a dual-stacked target fails while another still answers.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// EndpointFamilyStore defines the storage interface for the endpoint family
+// watchdog.
+type EndpointFamilyStore interface {
+	ListTargetIDsWithEndpoints(ctx context.Context) ([]string, error)
+	GetEndpointStatuses(ctx context.Context, targetIDs []string, window time.Duration) (map[string][]types.EndpointStatus, error)
+
+	ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error)
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+	ResolveAlert(ctx context.Context, alertID string, description string) error
+}
+
+// EndpointFamilyWatchdogConfig holds configuration for the endpoint family
+// watchdog.
+type EndpointFamilyWatchdogConfig struct {
+	// Interval between checks.
+	Interval time.Duration
+
+	// Window is how far back endpoint results are judged. It must cover
+	// the slowest tier interval plus agent batching, or a family goes
+	// unknown between probes instead of down.
+	Window time.Duration
+}
+
+// DefaultEndpointFamilyWatchdogConfig returns sensible defaults.
+func DefaultEndpointFamilyWatchdogConfig() EndpointFamilyWatchdogConfig {
+	return EndpointFamilyWatchdogConfig{
+		Interval: time.Minute,
+		Window:   config.EndpointStatusDefaultWindow,
+	}
+}
+
+// EndpointFamilyWatchdog raises an endpoint_family alert on each target with
+// endpoints in one address family all down while another family answers,
+// e.g. a host whose IPv6 path broke while IPv4 works. Availability alerts
+// judge only the primary IP, so without this a broken family goes unnoticed
+// as long as the primary's family is healthy, and when the primary's family
+// is the broken one the target looks down although it is reachable.
+type EndpointFamilyWatchdog struct {
+	store  EndpointFamilyStore
+	config EndpointFamilyWatchdogConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	clocked
+}
+
+// NewEndpointFamilyWatchdog creates a new endpoint family watchdog.
+func NewEndpointFamilyWatchdog(store EndpointFamilyStore, config EndpointFamilyWatchdogConfig, logger *slog.Logger) *EndpointFamilyWatchdog {
+	return &EndpointFamilyWatchdog{
+		store:  store,
+		config: config,
+		logger: logger.With("component", "endpoint_family_watchdog"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *EndpointFamilyWatchdog) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *EndpointFamilyWatchdog) Stop() {
+	close(w.stopCh)
+}
+
+func (w *EndpointFamilyWatchdog) run(ctx context.Context) {
+	w.logger.Info("endpoint family watchdog started",
+		"interval", w.config.Interval,
+		"window", w.config.Window,
+	)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("endpoint family watchdog stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("endpoint family watchdog stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *EndpointFamilyWatchdog) runOnce(ctx context.Context) {
+	ids, err := w.store.ListTargetIDsWithEndpoints(ctx)
+	if err != nil {
+		w.logger.Error("failed to list targets with endpoints", "error", err)
+		return
+	}
+
+	failing := make(map[string]bool)
+	raised := 0
+	if len(ids) > 0 {
+		statuses, err := w.store.GetEndpointStatuses(ctx, ids, w.config.Window)
+		if err != nil {
+			w.logger.Error("failed to get endpoint statuses", "error", err)
+			return
+		}
+
+		for targetID, endpoints := range statuses {
+			families := types.FailingFamilies(types.SummarizeFamilies(endpoints))
+			if len(families) == 0 {
+				continue
+			}
+			failing[targetID] = true
+			if err := w.raise(ctx, targetID, endpoints, families); err != nil {
+				w.logger.Error("failed to raise endpoint family alert", "target_id", targetID, "error", err)
+				continue
+			}
+			raised++
+		}
+	}
+
+	resolved := w.resolveRecovered(ctx, failing)
+
+	if raised > 0 || resolved > 0 {
+		w.logger.Info("endpoint family check complete",
+			"targets", len(ids),
+			"failing", len(failing),
+			"alerts_raised", raised,
+			"alerts_resolved", resolved,
+		)
+	}
+}
+
+// raise creates the target's endpoint family alert or brings the open one
+// up to date with the families and endpoints failing now.
+func (w *EndpointFamilyWatchdog) raise(ctx context.Context, targetID string, endpoints []types.EndpointStatus, families []string) error {
+	var targetIP string
+	var down []string
+	for _, e := range endpoints {
+		if e.ID == types.PrimaryEndpointID {
+			targetIP = e.IP
+		}
+		if e.State == types.EndpointStateDown {
+			down = append(down, e.IP)
+		}
+	}
+
+	title := fmt.Sprintf("%s unreachable on %s", strings.Join(families, ", "), targetIP)
+	message := fmt.Sprintf("No successful probes to %s in the last %s while other address families answer",
+		strings.Join(down, ", "), w.config.Window)
+
+	existing, err := w.store.FindActiveAlertForTarget(ctx, targetID, types.AlertTypeEndpointFamily, "")
+	if err != nil {
+		return fmt.Errorf("finding alert: %w", err)
+	}
+	if existing != nil {
+		if err := w.store.UpdateAlertSummary(ctx, existing.ID, title, message); err != nil {
+			return fmt.Errorf("updating alert: %w", err)
+		}
+		return nil
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetID:        targetID,
+		TargetIP:        targetIP,
+		AlertType:       types.AlertTypeEndpointFamily,
+		Severity:        types.AlertSeverityWarning,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: types.AlertSeverityWarning,
+		PeakSeverity:    types.AlertSeverityWarning,
+		Title:           title,
+		Message:         message,
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return fmt.Errorf("creating alert: %w", err)
+	}
+
+	w.logger.Warn("address family failing",
+		"target_id", targetID,
+		"target_ip", targetIP,
+		"families", families,
+		"endpoints", down,
+	)
+	return nil
+}
+
+// resolveRecovered resolves open endpoint family alerts for targets whose
+// families all answer again (or that no longer have endpoints).
+func (w *EndpointFamilyWatchdog) resolveRecovered(ctx context.Context, failing map[string]bool) int {
+	alertType := types.AlertTypeEndpointFamily
+	resolved := 0
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		alerts, err := w.store.ListAlerts(ctx, types.AlertFilter{
+			AlertType: &alertType,
+			Status:    &status,
+			Limit:     1000,
+		})
+		if err != nil {
+			w.logger.Error("failed to list endpoint family alerts", "error", err)
+			continue
+		}
+
+		for _, alert := range alerts {
+			if failing[alert.TargetID] {
+				continue
+			}
+			if err := w.store.ResolveAlert(ctx, alert.ID, "All address families answering again"); err != nil {
+				w.logger.Error("failed to resolve endpoint family alert", "alert_id", alert.ID, "error", err)
+				continue
+			}
+			resolved++
+		}
+	}
+	return resolved
+}
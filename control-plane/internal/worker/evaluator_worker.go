@@ -5,9 +5,11 @@ import (
 	"context"
 	"log/slog"
 	"math"
+	"sync/atomic"
 	"time"
 
 	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
 )
 
 // EvaluatorStore defines the storage interface for the evaluator worker.
@@ -30,6 +32,17 @@ type EvaluatorStore interface {
 
 	// UpsertBaseline inserts or updates the agent_target_baseline row.
 	UpsertBaseline(ctx context.Context, baseline *store.AgentTargetBaseline) error
+
+	// Baseline refresh and drift detection (see baseline_drift.go)
+	RecalculateAllBaselines(ctx context.Context) (int, error)
+	GetBaselineDrift(ctx context.Context, lookback time.Duration, minIncreasePct, minIncreaseMs float64) ([]store.BaselineDrift, error)
+	GetAlertConfigInt(ctx context.Context, key string, defaultVal int) (int, error)
+	GetAlertConfigFloat(ctx context.Context, key string, defaultVal float64) (float64, error)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+	ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error)
+	ResolveAlert(ctx context.Context, alertID string, description string) error
 }
 
 // EvaluatorWorkerConfig holds configuration for the evaluator worker.
@@ -63,6 +76,23 @@ type EvaluatorWorkerConfig struct {
 
 	// ConsecutiveSuccessesForUp is how many consecutive successes before marking as up.
 	ConsecutiveSuccessesForUp int
+
+	// BaselineRefreshInterval is how often all baselines are recalculated
+	// (snapshotting each to baseline_history) and checked for drift.
+	// 0 disables both.
+	BaselineRefreshInterval time.Duration
+
+	// DriftLookback is how far back the baseline p95 is compared against.
+	// Overridden by the baseline_drift_weeks alert config.
+	DriftLookback time.Duration
+
+	// DriftIncreasePct is the rise in baseline p95 that raises a
+	// baseline_drift alert. Overridden by baseline_drift_pct.
+	DriftIncreasePct float64
+
+	// DriftMinIncreaseMs ignores rises smaller than this in absolute terms,
+	// so 1ms to 2ms isn't a 100% drift. Overridden by baseline_drift_min_ms.
+	DriftMinIncreaseMs float64
 }
 
 // DefaultEvaluatorWorkerConfig returns sensible defaults.
@@ -78,6 +108,10 @@ func DefaultEvaluatorWorkerConfig() EvaluatorWorkerConfig {
 		PacketLossCriticalPct:      20.0,
 		ConsecutiveFailuresForDown: 3,
 		ConsecutiveSuccessesForUp:  3,
+		BaselineRefreshInterval:    24 * time.Hour,
+		DriftLookback:              4 * 7 * 24 * time.Hour, // 4 weeks
+		DriftIncreasePct:           50.0,
+		DriftMinIncreaseMs:         5.0,
 	}
 }
 
@@ -87,6 +121,11 @@ type EvaluatorWorker struct {
 	config EvaluatorWorkerConfig
 	logger *slog.Logger
 	stopCh chan struct{}
+
+	// ready is set once the first cycle has completed
+	ready atomic.Bool
+
+	clocked
 }
 
 // NewEvaluatorWorker creates a new evaluator worker.
@@ -109,6 +148,11 @@ func (w *EvaluatorWorker) Stop() {
 	close(w.stopCh)
 }
 
+// Ready reports whether the worker has completed its first cycle.
+func (w *EvaluatorWorker) Ready() bool {
+	return w.ready.Load()
+}
+
 func (w *EvaluatorWorker) run(ctx context.Context) {
 	w.logger.Info("evaluator worker started",
 		"interval", w.config.Interval,
@@ -119,10 +163,20 @@ func (w *EvaluatorWorker) run(ctx context.Context) {
 
 	// Run immediately on start
 	w.runOnce(ctx)
+	w.ready.Store(true)
 
 	ticker := time.NewTicker(w.config.Interval)
 	defer ticker.Stop()
 
+	// Baselines refresh on their own, slower schedule, starting one interval
+	// after startup so restarts don't each trigger a full recalculation
+	var refresh <-chan time.Time
+	if w.config.BaselineRefreshInterval > 0 {
+		refreshTicker := time.NewTicker(w.config.BaselineRefreshInterval)
+		defer refreshTicker.Stop()
+		refresh = refreshTicker.C
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -133,12 +187,14 @@ func (w *EvaluatorWorker) run(ctx context.Context) {
 			return
 		case <-ticker.C:
 			w.runOnce(ctx)
+		case <-refresh:
+			w.refreshBaselines(ctx)
 		}
 	}
 }
 
 func (w *EvaluatorWorker) runOnce(ctx context.Context) {
-	start := time.Now()
+	start := w.now()
 
 	// Get all active agent-target pairs with recent probes
 	pairs, err := w.store.GetActiveAgentTargetPairs(ctx, w.config.EvaluationWindow)
@@ -153,7 +209,7 @@ func (w *EvaluatorWorker) runOnce(ctx context.Context) {
 	}
 
 	// Fetch all data in bulk (3 queries instead of 3*N queries)
-	fetchStart := time.Now()
+	fetchStart := w.now()
 
 	allStats, err := w.store.BulkGetRecentProbeStats(ctx, pairs, w.config.EvaluationWindow)
 	if err != nil {
@@ -173,7 +229,7 @@ func (w *EvaluatorWorker) runOnce(ctx context.Context) {
 		return
 	}
 
-	fetchDuration := time.Since(fetchStart)
+	fetchDuration := w.since(fetchStart)
 
 	// Process all pairs using pre-fetched data
 	evaluated := 0
@@ -211,7 +267,7 @@ func (w *EvaluatorWorker) runOnce(ctx context.Context) {
 	}
 
 	w.logger.Info("evaluator worker cycle complete",
-		"duration", time.Since(start),
+		"duration", w.since(start),
 		"fetch_duration", fetchDuration,
 		"pairs_evaluated", evaluated,
 		"states_updated", len(statesToUpdate),
@@ -220,6 +276,12 @@ func (w *EvaluatorWorker) runOnce(ctx context.Context) {
 	)
 }
 
+// inWarmup reports whether the evaluation window reaches back into the
+// pair's baseline warmup.
+func (w *EvaluatorWorker) inWarmup(pair store.AgentTargetPair) bool {
+	return pair.WarmupEndsAt != nil && pair.WarmupEndsAt.After(w.now().Add(-w.config.EvaluationWindow))
+}
+
 // evaluatePairWithData evaluates a single agent-target pair using pre-fetched data.
 // Returns (newState, stateChanged, baselineCreated). newState may be nil if no stats available.
 func (w *EvaluatorWorker) evaluatePairWithData(ctx context.Context, pair store.AgentTargetPair, stats *store.ProbeStats, baseline *store.AgentTargetBaseline, currentState *store.AgentTargetState) (*store.AgentTargetState, bool, bool) {
@@ -229,8 +291,10 @@ func (w *EvaluatorWorker) evaluatePairWithData(ctx context.Context, pair store.A
 
 	baselineCreated := false
 
-	// If no baseline exists and we have enough samples, create one
-	if baseline == nil && stats.SuccessCount >= w.config.MinSamplesForBaseline {
+	// If no baseline exists and we have enough samples, create one. A window
+	// reaching back into the pair's warmup would bake its slow first probes
+	// into the baseline, so wait until it doesn't.
+	if baseline == nil && stats.SuccessCount >= w.config.MinSamplesForBaseline && !w.inWarmup(pair) {
 		p50 := stats.P50LatencyMs
 		p95 := stats.P95LatencyMs
 		p99 := stats.MaxLatencyMs // Use max as P99 approximation
@@ -244,8 +308,8 @@ func (w *EvaluatorWorker) evaluatePairWithData(ctx context.Context, pair store.A
 			LatencyStddev:      &stddev,
 			PacketLossBaseline: stats.PacketLossPct,
 			SampleCount:        stats.SuccessCount,
-			FirstSeen:          time.Now(),
-			LastUpdated:        time.Now(),
+			FirstSeen:          w.now(),
+			LastUpdated:        w.now(),
 		}
 		if err := w.store.UpsertBaseline(ctx, baseline); err != nil {
 			w.logger.Error("failed to create baseline",
@@ -265,12 +329,12 @@ func (w *EvaluatorWorker) evaluatePairWithData(ctx context.Context, pair store.A
 	}
 
 	// Calculate new state
-	result := w.calculateState(stats, baseline, currentState)
+	result := w.calculateState(stats, baseline, currentState, pair.ExpectedOutcome)
 	newState := result.State
 	newState.AgentID = pair.AgentID
 	newState.TargetID = pair.TargetID
 	newState.LastProbeTime = &stats.LastProbeTime
-	newState.LastEvaluated = time.Now()
+	newState.LastEvaluated = w.now()
 
 	// Determine if state changed
 	stateChanged := currentState == nil || currentState.Status != newState.Status
@@ -283,7 +347,7 @@ func (w *EvaluatorWorker) evaluatePairWithData(ctx context.Context, pair store.A
 			newState.ConsecutiveAnomalies = currentState.ConsecutiveAnomalies + 1
 			newState.ConsecutiveSuccesses = 0
 			if currentState.AnomalyStart == nil {
-				now := time.Now()
+				now := w.now()
 				newState.AnomalyStart = &now
 			} else {
 				newState.AnomalyStart = currentState.AnomalyStart
@@ -298,7 +362,7 @@ func (w *EvaluatorWorker) evaluatePairWithData(ctx context.Context, pair store.A
 		// No previous state
 		if result.ObservedAnomaly {
 			newState.ConsecutiveAnomalies = 1
-			now := time.Now()
+			now := w.now()
 			newState.AnomalyStart = &now
 		} else {
 			newState.ConsecutiveSuccesses = 1
@@ -307,7 +371,7 @@ func (w *EvaluatorWorker) evaluatePairWithData(ctx context.Context, pair store.A
 
 	// Update status_since if status changed
 	if stateChanged {
-		now := time.Now()
+		now := w.now()
 		newState.StatusSince = &now
 	} else if currentState != nil {
 		newState.StatusSince = currentState.StatusSince
@@ -337,7 +401,9 @@ type stateResult struct {
 // calculateState determines the status based on probe stats and baseline.
 // All anomaly conditions require consecutive observations before changing state.
 // This prevents spurious alerts from single bad measurements.
-func (w *EvaluatorWorker) calculateState(stats *store.ProbeStats, baseline *store.AgentTargetBaseline, current *store.AgentTargetState) stateResult {
+// If the expected outcome uses SLO success criteria, replies outside the SLO
+// are degraded even when the baseline considers them normal.
+func (w *EvaluatorWorker) calculateState(stats *store.ProbeStats, baseline *store.AgentTargetBaseline, current *store.AgentTargetState, outcome *types.ExpectedOutcome) stateResult {
 	state := &store.AgentTargetState{}
 	result := stateResult{State: state}
 
@@ -379,6 +445,9 @@ func (w *EvaluatorWorker) calculateState(stats *store.ProbeStats, baseline *stor
 	} else if hasZScore && zScore >= w.config.ZScoreWarningThreshold {
 		// Warning latency deviation
 		rawStatus = "degraded"
+	} else if !outcome.MeetsSLO(stats.AvgLatencyMs, stats.PacketLossPct) {
+		// Reachable but outside the SLO
+		rawStatus = "degraded"
 	}
 
 	// Record whether we observed an anomaly (for counter tracking)
@@ -0,0 +1,297 @@
+// Package worker - Event dispatcher sequences the outbox and delivers events
+// to push consumers.
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// EventStore defines the storage interface for the event dispatcher.
+type EventStore interface {
+	SequenceEvents(ctx context.Context, limit int) (int64, error)
+	ListEvents(ctx context.Context, since int64, eventTypes []string, limit int) ([]types.Event, error)
+	PruneEvents(ctx context.Context, retention time.Duration) (int64, error)
+
+	ListEventConsumers(ctx context.Context, enabledOnly bool) ([]types.EventConsumer, error)
+	AdvanceEventConsumer(ctx context.Context, name string, expected, seq int64, delivered bool) (bool, error)
+	RecordEventConsumerFailure(ctx context.Context, name, errText string, nextAttempt time.Time) error
+}
+
+// EventSink delivers a batch of events to a consumer. A nil error means the
+// consumer accepted every event in the batch.
+type EventSink interface {
+	Deliver(ctx context.Context, consumer *types.EventConsumer, events []types.Event) error
+}
+
+// EventSinks routes each consumer to the sink for its kind.
+type EventSinks map[string]EventSink
+
+// Deliver implements EventSink. A consumer whose kind has no sink, such as
+// a kafka consumer while Kafka isn't configured, fails and backs off.
+func (s EventSinks) Deliver(ctx context.Context, consumer *types.EventConsumer, events []types.Event) error {
+	sink, ok := s[consumer.Kind]
+	if !ok {
+		return fmt.Errorf("no sink configured for %s consumers", consumer.Kind)
+	}
+	return sink.Deliver(ctx, consumer, events)
+}
+
+// EventDispatcherConfig holds configuration for the event dispatcher.
+type EventDispatcherConfig struct {
+	// Interval between sequencing and delivery passes. It bounds how soon
+	// an event is visible to pull consumers after its change commits.
+	Interval time.Duration
+
+	// SequenceBatch caps the events sequenced per pass.
+	SequenceBatch int
+
+	// DeliveryBatch caps the events sent to a consumer in one request.
+	DeliveryBatch int
+
+	// RetryBase is the delay after a consumer's first failed delivery; it
+	// doubles with each further failure up to RetryMax.
+	RetryBase time.Duration
+	RetryMax  time.Duration
+
+	// Retention is how long events are kept. A consumer further behind than
+	// this loses the events in between.
+	Retention time.Duration
+
+	// PruneInterval is how often events past Retention are deleted.
+	PruneInterval time.Duration
+}
+
+// DefaultEventDispatcherConfig returns sensible defaults.
+func DefaultEventDispatcherConfig() EventDispatcherConfig {
+	return EventDispatcherConfig{
+		Interval:      2 * time.Second,
+		SequenceBatch: 5000,
+		DeliveryBatch: 100,
+		RetryBase:     5 * time.Second,
+		RetryMax:      10 * time.Minute,
+		Retention:     7 * 24 * time.Hour,
+		PruneInterval: time.Hour,
+	}
+}
+
+// retryDelay is the backoff before the next attempt after failures
+// consecutive failed deliveries.
+func (c EventDispatcherConfig) retryDelay(failures int) time.Duration {
+	delay := c.RetryBase
+	for i := 1; i < failures && delay < c.RetryMax; i++ {
+		delay *= 2
+	}
+	return min(delay, c.RetryMax)
+}
+
+// EventDispatcher assigns stream positions to committed outbox events and
+// pushes them to each enabled consumer in order. A consumer's offset only
+// advances after it accepts a batch, so delivery is at least once: a failed
+// or interrupted delivery is retried, and consumers dedupe by seq.
+type EventDispatcher struct {
+	store     EventStore
+	sink      EventSink
+	config    EventDispatcherConfig
+	logger    *slog.Logger
+	stopCh    chan struct{}
+	lastPrune time.Time
+
+	clocked
+}
+
+// NewEventDispatcher creates a new event dispatcher.
+func NewEventDispatcher(store EventStore, sink EventSink, config EventDispatcherConfig, logger *slog.Logger) *EventDispatcher {
+	return &EventDispatcher{
+		store:  store,
+		sink:   sink,
+		config: config,
+		logger: logger.With("component", "event_dispatcher"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *EventDispatcher) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *EventDispatcher) Stop() {
+	close(w.stopCh)
+}
+
+func (w *EventDispatcher) run(ctx context.Context) {
+	w.logger.Info("event dispatcher started",
+		"interval", w.config.Interval,
+		"retention", w.config.Retention,
+	)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("event dispatcher stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("event dispatcher stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *EventDispatcher) runOnce(ctx context.Context) {
+	if n, err := w.store.SequenceEvents(ctx, w.config.SequenceBatch); err != nil {
+		w.logger.Error("failed to sequence events", "error", err)
+	} else if n > 0 {
+		w.logger.Debug("sequenced events", "count", n)
+	}
+
+	consumers, err := w.store.ListEventConsumers(ctx, true)
+	if err != nil {
+		w.logger.Error("failed to list event consumers", "error", err)
+		return
+	}
+	now := w.now()
+	for i := range consumers {
+		c := &consumers[i]
+		if c.NextAttemptAt != nil && now.Before(*c.NextAttemptAt) {
+			continue
+		}
+		w.dispatch(ctx, c)
+	}
+
+	if now.Sub(w.lastPrune) >= w.config.PruneInterval {
+		w.lastPrune = now
+		if n, err := w.store.PruneEvents(ctx, w.config.Retention); err != nil {
+			w.logger.Error("failed to prune events", "error", err)
+		} else if n > 0 {
+			w.logger.Info("pruned events", "count", n, "retention", w.config.Retention)
+		}
+	}
+}
+
+// dispatch delivers batches to a consumer until it is caught up or a
+// delivery fails. Events the consumer doesn't subscribe to are skipped but
+// still move its offset.
+func (w *EventDispatcher) dispatch(ctx context.Context, c *types.EventConsumer) {
+	for ctx.Err() == nil {
+		events, err := w.store.ListEvents(ctx, c.LastSeq, nil, w.config.DeliveryBatch)
+		if err != nil {
+			w.logger.Error("failed to list events", "consumer", c.Name, "error", err)
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		wanted := make([]types.Event, 0, len(events))
+		for _, e := range events {
+			if c.Wants(e.Type) {
+				wanted = append(wanted, e)
+			}
+		}
+		if len(wanted) > 0 {
+			if err := w.sink.Deliver(ctx, c, wanted); err != nil {
+				w.recordFailure(ctx, c, err)
+				return
+			}
+		}
+
+		last := events[len(events)-1].Seq
+		advanced, err := w.store.AdvanceEventConsumer(ctx, c.Name, c.LastSeq, last, len(wanted) > 0)
+		if err != nil {
+			w.logger.Error("failed to advance event consumer", "consumer", c.Name, "error", err)
+			return
+		}
+		if !advanced {
+			// Offset moved underneath us (seek or another instance); pick
+			// up from the stored offset next pass
+			return
+		}
+		if c.ConsecutiveFailures > 0 {
+			w.logger.Info("event consumer recovered", "consumer", c.Name, "failures", c.ConsecutiveFailures)
+			c.ConsecutiveFailures = 0
+		}
+		c.LastSeq = last
+
+		if len(events) < w.config.DeliveryBatch {
+			return
+		}
+	}
+}
+
+func (w *EventDispatcher) recordFailure(ctx context.Context, c *types.EventConsumer, deliveryErr error) {
+	failures := c.ConsecutiveFailures + 1
+	next := w.now().Add(w.config.retryDelay(failures))
+	w.logger.Warn("event delivery failed",
+		"consumer", c.Name,
+		"after_seq", c.LastSeq,
+		"failures", failures,
+		"retry_at", next,
+		"error", deliveryErr,
+	)
+	if err := w.store.RecordEventConsumerFailure(ctx, c.Name, deliveryErr.Error(), next); err != nil {
+		w.logger.Error("failed to record event delivery failure", "consumer", c.Name, "error", err)
+	}
+}
+
+// =============================================================================
+// WEBHOOK SINK
+// =============================================================================
+
+// WebhookEventSink POSTs batches as JSON ({consumer, events}) to the
+// consumer's URL. Any 2xx response accepts the batch.
+type WebhookEventSink struct {
+	client *http.Client
+}
+
+// NewWebhookEventSink creates a webhook sink.
+func NewWebhookEventSink() *WebhookEventSink {
+	return &WebhookEventSink{client: &http.Client{Timeout: config.DefaultHTTPTimeout}}
+}
+
+// Deliver implements EventSink.
+func (s *WebhookEventSink) Deliver(ctx context.Context, consumer *types.EventConsumer, events []types.Event) error {
+	body, err := json.Marshal(map[string]any{
+		"consumer": consumer.Name,
+		"events":   events,
+	})
+	if err != nil {
+		return fmt.Errorf("encoding events: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, consumer.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("creating webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Event-Consumer", consumer.Name)
+	req.Header.Set("X-Event-Last-Seq", strconv.FormatInt(events[len(events)-1].Seq, 10))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("posting webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,284 @@
+// Package worker - Latency asymmetry watchdog alerts when one agent region
+// sees a target far slower than another.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// LatencyAsymmetryStore defines the storage interface for the latency
+// asymmetry watchdog.
+type LatencyAsymmetryStore interface {
+	GetRegionLatencies(ctx context.Context, targetIDs []string, window time.Duration) (map[string][]types.RegionLatency, error)
+	GetLatencyAsymmetryThresholds(ctx context.Context) (types.LatencyAsymmetryThresholds, error)
+	GetAlertConfigInt(ctx context.Context, key string, defaultVal int) (int, error)
+	GetTarget(ctx context.Context, id string) (*types.Target, error)
+
+	ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error)
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+	ResolveAlert(ctx context.Context, alertID string, description string) error
+}
+
+// LatencyAsymmetryWatchdogConfig holds configuration for the latency
+// asymmetry watchdog.
+type LatencyAsymmetryWatchdogConfig struct {
+	// Interval between checks.
+	Interval time.Duration
+
+	// Window is how recently an agent must have probed a target for its
+	// latency to count toward its region.
+	Window time.Duration
+
+	// SustainFor is how long a target must stay asymmetric before an alert
+	// is raised. Overridden by latency_asymmetry_sustain_minutes in
+	// alert_config.
+	SustainFor time.Duration
+
+	// MaxNewAlerts caps the alerts created per check, so a regional routing
+	// problem affecting many targets doesn't open one per target at once.
+	MaxNewAlerts int
+}
+
+// DefaultLatencyAsymmetryWatchdogConfig returns sensible defaults.
+func DefaultLatencyAsymmetryWatchdogConfig() LatencyAsymmetryWatchdogConfig {
+	return LatencyAsymmetryWatchdogConfig{
+		Interval:     time.Minute,
+		Window:       config.LatencyAsymmetryWindow,
+		SustainFor:   config.LatencyAsymmetrySustain,
+		MaxNewAlerts: 100,
+	}
+}
+
+// LatencyAsymmetryWatchdog raises a latency_asymmetry alert on each target
+// whose slowest agent region has seen it far slower than its fastest for
+// SustainFor. Latency alerts judge each agent against its own baseline, so a
+// region whose path to the target has always been long, or got long slowly,
+// never trips them; comparing regions catches the routing problem directly.
+type LatencyAsymmetryWatchdog struct {
+	store  LatencyAsymmetryStore
+	config LatencyAsymmetryWatchdogConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	// asymmetricSince records when each target was first seen asymmetric
+	// in the current run of checks. It is in-memory, so a restart restarts
+	// the sustain period.
+	asymmetricSince map[string]time.Time
+
+	clocked
+}
+
+// NewLatencyAsymmetryWatchdog creates a new latency asymmetry watchdog.
+func NewLatencyAsymmetryWatchdog(store LatencyAsymmetryStore, config LatencyAsymmetryWatchdogConfig, logger *slog.Logger) *LatencyAsymmetryWatchdog {
+	return &LatencyAsymmetryWatchdog{
+		store:           store,
+		config:          config,
+		logger:          logger.With("component", "latency_asymmetry_watchdog"),
+		stopCh:          make(chan struct{}),
+		asymmetricSince: make(map[string]time.Time),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *LatencyAsymmetryWatchdog) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *LatencyAsymmetryWatchdog) Stop() {
+	close(w.stopCh)
+}
+
+func (w *LatencyAsymmetryWatchdog) run(ctx context.Context) {
+	w.logger.Info("latency asymmetry watchdog started",
+		"interval", w.config.Interval,
+		"window", w.config.Window,
+	)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("latency asymmetry watchdog stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("latency asymmetry watchdog stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// sustainFor reads the sustain period from alert_config.
+func (w *LatencyAsymmetryWatchdog) sustainFor(ctx context.Context) time.Duration {
+	minutes := int(w.config.SustainFor / time.Minute)
+	if val, err := w.store.GetAlertConfigInt(ctx, "latency_asymmetry_sustain_minutes", minutes); err == nil && val >= 0 {
+		return time.Duration(val) * time.Minute
+	}
+	return w.config.SustainFor
+}
+
+func (w *LatencyAsymmetryWatchdog) runOnce(ctx context.Context) {
+	regions, err := w.store.GetRegionLatencies(ctx, nil, w.config.Window)
+	if err != nil {
+		w.logger.Error("failed to get region latencies", "error", err)
+		return
+	}
+	th, err := w.store.GetLatencyAsymmetryThresholds(ctx)
+	if err != nil {
+		w.logger.Warn("failed to read latency asymmetry thresholds, using defaults", "error", err)
+	}
+	sustain := w.sustainFor(ctx)
+
+	now := w.now()
+	current := make(map[string]bool)
+	created, deferred := 0, 0
+	for targetID, rs := range regions {
+		a := types.AssessLatencyAsymmetry(rs, th)
+		if a == nil || !a.Asymmetric {
+			continue
+		}
+		current[targetID] = true
+		since, ok := w.asymmetricSince[targetID]
+		if !ok {
+			w.asymmetricSince[targetID] = now
+			since = now
+		}
+		if now.Sub(since) < sustain {
+			continue
+		}
+
+		existing, err := w.store.FindActiveAlertForTarget(ctx, targetID, types.AlertTypeLatencyAsymmetry, "")
+		if err != nil {
+			w.logger.Error("failed to find latency asymmetry alert", "target_id", targetID, "error", err)
+			continue
+		}
+		if existing == nil && created >= w.config.MaxNewAlerts {
+			deferred++
+			continue
+		}
+		if err := w.raise(ctx, existing, targetID, a, since); err != nil {
+			w.logger.Error("failed to raise latency asymmetry alert", "target_id", targetID, "error", err)
+			continue
+		}
+		if existing == nil {
+			created++
+		}
+	}
+
+	for id := range w.asymmetricSince {
+		if !current[id] {
+			delete(w.asymmetricSince, id)
+		}
+	}
+
+	resolved := w.resolveRecovered(ctx, current)
+
+	if len(current) > 0 || resolved > 0 {
+		w.logger.Info("latency asymmetry check complete",
+			"asymmetric", len(current),
+			"alerts_raised", created,
+			"alerts_deferred", deferred,
+			"alerts_resolved", resolved,
+		)
+	}
+}
+
+// raise creates the target's latency asymmetry alert or brings the open one
+// up to date.
+func (w *LatencyAsymmetryWatchdog) raise(ctx context.Context, existing *types.Alert, targetID string, a *types.LatencyAsymmetry, since time.Time) error {
+	slowest, fastest := a.Regions[len(a.Regions)-1], a.Regions[0]
+	message := fmt.Sprintf("%s agents see %.1fms, %.1fx the %.1fms seen from %s (asymmetric since %s)",
+		slowest.Region, slowest.LatencyMs, a.Factor, fastest.LatencyMs, fastest.Region,
+		since.UTC().Format(time.RFC3339))
+
+	title := func(ip string) string {
+		return fmt.Sprintf("Latency to %s asymmetric from %s", ip, slowest.Region)
+	}
+
+	if existing != nil {
+		if err := w.store.UpdateAlertSummary(ctx, existing.ID, title(existing.TargetIP), message); err != nil {
+			return fmt.Errorf("updating alert: %w", err)
+		}
+		return nil
+	}
+
+	target, err := w.store.GetTarget(ctx, targetID)
+	if err != nil {
+		return fmt.Errorf("getting target: %w", err)
+	}
+	if target == nil {
+		return nil
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetID:        targetID,
+		TargetIP:        target.IP,
+		AlertType:       types.AlertTypeLatencyAsymmetry,
+		Severity:        types.AlertSeverityWarning,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: types.AlertSeverityWarning,
+		PeakSeverity:    types.AlertSeverityWarning,
+		Title:           title(target.IP),
+		Message:         message,
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return fmt.Errorf("creating alert: %w", err)
+	}
+
+	w.logger.Warn("latency asymmetric across regions",
+		"target_id", targetID,
+		"target_ip", target.IP,
+		"slowest_region", slowest.Region,
+		"fastest_region", fastest.Region,
+		"factor", a.Factor,
+	)
+	return nil
+}
+
+// resolveRecovered resolves open latency asymmetry alerts for targets no
+// longer asymmetric.
+func (w *LatencyAsymmetryWatchdog) resolveRecovered(ctx context.Context, asymmetric map[string]bool) int {
+	alertType := types.AlertTypeLatencyAsymmetry
+	resolved := 0
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		alerts, err := w.store.ListAlerts(ctx, types.AlertFilter{
+			AlertType: &alertType,
+			Status:    &status,
+			Limit:     1000,
+		})
+		if err != nil {
+			w.logger.Error("failed to list latency asymmetry alerts", "error", err)
+			continue
+		}
+
+		for _, alert := range alerts {
+			if asymmetric[alert.TargetID] {
+				continue
+			}
+			if err := w.store.ResolveAlert(ctx, alert.ID, "Latency even across regions again"); err != nil {
+				w.logger.Error("failed to resolve latency asymmetry alert", "alert_id", alert.ID, "error", err)
+				continue
+			}
+			resolved++
+		}
+	}
+	return resolved
+}
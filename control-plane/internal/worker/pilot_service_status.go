@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// serviceCancellation is what handling a cancelled service changed.
+type serviceCancellation struct {
+	TargetsTransitioned int
+	AlertsResolved      int
+	IncidentsResolved   int
+}
+
+// recordServiceStatusChange logs a subnet's service status change to its
+// activity and, when enabled, raises an informational alert. Cancellation
+// silently stops monitoring the subnet's targets, so this is how the NOC
+// learns why they disappeared. Failures are logged; the sync carries on.
+func (w *PilotSyncWorker) recordServiceStatusChange(ctx context.Context, subnet *types.Subnet, oldStatus *string, c serviceCancellation) {
+	from, to := statusOrNone(oldStatus), statusOrNone(subnet.ServiceStatus)
+	cancelled := to == "cancelled"
+
+	severity := "info"
+	if cancelled {
+		severity = "warning"
+	}
+	details := map[string]interface{}{
+		"from_status": from,
+		"to_status":   to,
+	}
+	if subnet.ServiceID != nil {
+		details["service_id"] = *subnet.ServiceID
+	}
+	if subnet.SubscriberName != nil {
+		details["subscriber"] = *subnet.SubscriberName
+	}
+	if cancelled {
+		details["targets_transitioned"] = c.TargetsTransitioned
+		details["alerts_resolved"] = c.AlertsResolved
+		details["incidents_resolved"] = c.IncidentsResolved
+		details["reason"] = fmt.Sprintf("%d targets stopped monitoring", c.TargetsTransitioned)
+	}
+	if err := w.store.LogSubnetActivity(ctx, subnet.ID, types.ActivityEventServiceStatusChanged, "pilot_sync", severity, details); err != nil {
+		w.logger.Error("failed to log service status change", "subnet_id", subnet.ID, "error", err)
+	}
+
+	w.logger.Info("subnet service status changed",
+		"subnet_id", subnet.ID,
+		"network", subnet.NetworkAddress,
+		"from", from,
+		"to", to,
+	)
+
+	if !w.config.ServiceStatusAlerts {
+		return
+	}
+	if err := w.raiseServiceStatusAlert(ctx, subnet, from, to, c); err != nil {
+		w.logger.Error("failed to raise service status alert", "subnet_id", subnet.ID, "error", err)
+	}
+}
+
+// raiseServiceStatusAlert creates an informational service_status alert on
+// the subnet. It is created after any cancellation has resolved the
+// subnet's alerts, so it stays open until someone resolves it.
+func (w *PilotSyncWorker) raiseServiceStatusAlert(ctx context.Context, subnet *types.Subnet, from, to string, c serviceCancellation) error {
+	name := subnet.NetworkAddress
+	if subnet.SubscriberName != nil && *subnet.SubscriberName != "" {
+		name = fmt.Sprintf("%s (%s)", *subnet.SubscriberName, subnet.NetworkAddress)
+	}
+
+	title := fmt.Sprintf("Service status changed for %s", name)
+	message := fmt.Sprintf("Service status changed from %s to %s", from, to)
+	if to == "cancelled" {
+		title = fmt.Sprintf("Service cancelled for %s", name)
+		message = fmt.Sprintf("%s; %d targets stopped monitoring, %d alerts and %d incidents were resolved",
+			message, c.TargetsTransitioned, c.AlertsResolved, c.IncidentsResolved)
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetIP:        subnet.NetworkAddress,
+		AlertType:       types.AlertTypeServiceStatus,
+		Severity:        types.AlertSeverityInfo,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: types.AlertSeverityInfo,
+		PeakSeverity:    types.AlertSeverityInfo,
+		Title:           title,
+		Message:         message,
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+		CorrelationKey:  types.CorrelationKey(types.RootCauseSubnet, subnet.ID),
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return fmt.Errorf("creating alert: %w", err)
+	}
+	return nil
+}
+
+// statusOrNone returns the status, or "none" when it is unset.
+func statusOrNone(status *string) string {
+	if status == nil || *status == "" {
+		return "none"
+	}
+	return *status
+}
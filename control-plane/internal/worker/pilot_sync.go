@@ -51,6 +51,17 @@ type PilotSyncStore interface {
 
 	// ResolveAlertsBySubnet resolves all active alerts for the subnet.
 	ResolveAlertsBySubnet(ctx context.Context, subnetID string, reason string) (int, error)
+
+	// ResolveIncidentsBySubnet resolves the subnet's open incidents whose
+	// affected targets are no longer monitored.
+	ResolveIncidentsBySubnet(ctx context.Context, subnetID string, reason string) (int, error)
+
+	// LogSubnetActivity records a service status change on the subnet.
+	LogSubnetActivity(ctx context.Context, subnetID, eventType, triggeredBy, severity string, details map[string]interface{}) error
+
+	// CreateAlert raises a service_status alert (only used when
+	// ServiceStatusAlerts is set).
+	CreateAlert(ctx context.Context, alert *types.Alert) error
 }
 
 // PilotSyncConfig holds configuration for the sync worker.
@@ -63,6 +74,11 @@ type PilotSyncConfig struct {
 
 	// AutoCreateTargets controls whether to auto-create targets for IPs in pools.
 	AutoCreateTargets bool
+
+	// ServiceStatusAlerts raises an informational service_status alert when
+	// a subnet's service status changes. The change is logged to the
+	// subnet's activity either way.
+	ServiceStatusAlerts bool
 }
 
 // DefaultPilotSyncConfig returns sensible defaults.
@@ -82,6 +98,8 @@ type PilotSyncWorker struct {
 	logger     *slog.Logger
 	stopCh     chan struct{}
 	lastFullSync time.Time
+
+	clocked
 }
 
 // NewPilotSyncWorker creates a new Pilot sync worker.
@@ -132,10 +150,10 @@ func (w *PilotSyncWorker) run(ctx context.Context) {
 }
 
 func (w *PilotSyncWorker) runOnce(ctx context.Context) {
-	start := time.Now()
+	start := w.now()
 
 	// Check if we need a full sync
-	isFullSync := time.Since(w.lastFullSync) >= w.config.FullSyncInterval
+	isFullSync := w.since(w.lastFullSync) >= w.config.FullSyncInterval
 
 	pools, err := w.client.ListIPPools(ctx)
 	if err != nil {
@@ -197,14 +215,15 @@ func (w *PilotSyncWorker) runOnce(ctx context.Context) {
 						"subnet_id", subnet.ID,
 						"pilot_id", *subnet.PilotSubnetID,
 					)
+					w.resolveSubnetIncidents(ctx, subnet.ID, "Subnet removed from Flight Deck")
 				}
 			}
 		}
-		w.lastFullSync = time.Now()
+		w.lastFullSync = w.now()
 	}
 
 	w.logger.Info("pilot sync complete",
-		"duration", time.Since(start),
+		"duration", w.since(start),
 		"pools_fetched", len(pools),
 		"created", created,
 		"updated", updated,
@@ -453,6 +472,7 @@ func (w *PilotSyncWorker) updateSubnet(ctx context.Context, existing *types.Subn
 	// Check for service status change to "cancelled"
 	oldStatus := existing.ServiceStatus
 	newStatus := pool.ServiceStatus
+	statusChanged := ptrStringNotEqual(oldStatus, newStatus)
 	becameCancelled := newStatus != nil && *newStatus == "cancelled" &&
 		(oldStatus == nil || *oldStatus != "cancelled")
 
@@ -482,8 +502,12 @@ func (w *PilotSyncWorker) updateSubnet(ctx context.Context, existing *types.Subn
 	}
 
 	// Handle service cancellation: stop monitoring and resolve alerts
+	var cancellation serviceCancellation
 	if becameCancelled {
-		w.handleServiceCancellation(ctx, existing)
+		cancellation = w.handleServiceCancellation(ctx, existing)
+	}
+	if statusChanged {
+		w.recordServiceStatusChange(ctx, existing, oldStatus, cancellation)
 	}
 
 	// Sync tags to all targets in this subnet (for point-in-time metadata accuracy)
@@ -500,8 +524,10 @@ func (w *PilotSyncWorker) updateSubnet(ctx context.Context, existing *types.Subn
 	return nil
 }
 
-// handleServiceCancellation stops monitoring and resolves alerts for a cancelled service.
-func (w *PilotSyncWorker) handleServiceCancellation(ctx context.Context, subnet *types.Subnet) {
+// handleServiceCancellation stops monitoring and resolves alerts for a
+// cancelled service, returning what it changed.
+func (w *PilotSyncWorker) handleServiceCancellation(ctx context.Context, subnet *types.Subnet) serviceCancellation {
+	var result serviceCancellation
 	w.logger.Info("service cancelled - stopping monitoring",
 		"subnet_id", subnet.ID,
 		"network", subnet.NetworkAddress,
@@ -517,6 +543,7 @@ func (w *PilotSyncWorker) handleServiceCancellation(ctx context.Context, subnet
 			"error", err,
 		)
 	} else if targetsTransitioned > 0 {
+		result.TargetsTransitioned = targetsTransitioned
 		w.logger.Info("targets transitioned to INACTIVE for cancelled service",
 			"subnet_id", subnet.ID,
 			"targets_affected", targetsTransitioned,
@@ -531,11 +558,38 @@ func (w *PilotSyncWorker) handleServiceCancellation(ctx context.Context, subnet
 			"error", err,
 		)
 	} else if alertsResolved > 0 {
+		result.AlertsResolved = alertsResolved
 		w.logger.Info("alerts resolved for cancelled service",
 			"subnet_id", subnet.ID,
 			"alerts_resolved", alertsResolved,
 		)
 	}
+
+	// 3. Resolve incidents left with nothing monitored
+	result.IncidentsResolved = w.resolveSubnetIncidents(ctx, subnet.ID, "Service cancelled in Flight Deck")
+	return result
+}
+
+// resolveSubnetIncidents auto-resolves incidents on a decommissioned
+// subnet so they don't linger with targets no one probes. Failures are
+// logged and count as none resolved.
+func (w *PilotSyncWorker) resolveSubnetIncidents(ctx context.Context, subnetID, reason string) int {
+	resolved, err := w.store.ResolveIncidentsBySubnet(ctx, subnetID, reason)
+	if err != nil {
+		w.logger.Error("failed to resolve incidents for subnet",
+			"subnet_id", subnetID,
+			"error", err,
+		)
+		return 0
+	}
+	if resolved > 0 {
+		w.logger.Info("incidents auto-resolved for subnet",
+			"subnet_id", subnetID,
+			"incidents_resolved", resolved,
+			"reason", reason,
+		)
+	}
+	return resolved
 }
 
 func (w *PilotSyncWorker) subnetNeedsUpdate(existing *types.Subnet, pool *pilot.IPPool) bool {
@@ -0,0 +1,229 @@
+// Package worker - Report worker generates and delivers scheduled reports
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/report"
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// ReportStore defines the storage interface for the report worker.
+type ReportStore interface {
+	// ListDueReportSchedules returns enabled schedules due at or before now.
+	ListDueReportSchedules(ctx context.Context, now time.Time) ([]types.ReportSchedule, error)
+
+	// GetReportScheduleTargets resolves the targets a schedule covers.
+	GetReportScheduleTargets(ctx context.Context, rs *types.ReportSchedule) ([]types.Target, error)
+
+	// GetTargetReport computes per-agent report metrics for a target
+	// (tz "" = rolling UTC days).
+	GetTargetReport(ctx context.Context, targetID string, windowDays int, tz string) ([]store.TargetReport, error)
+
+	// CreateReportDelivery records a delivery attempt.
+	CreateReportDelivery(ctx context.Context, d *types.ReportDelivery) error
+
+	// RecordReportScheduleRun stores a run's outcome and the next run time.
+	RecordReportScheduleRun(ctx context.Context, id string, status types.ReportDeliveryStatus, errMsg string, failures int, nextRun *time.Time) error
+}
+
+// ReportWorkerConfig holds configuration for the report worker.
+type ReportWorkerConfig struct {
+	// Interval between checks for due schedules.
+	Interval time.Duration
+
+	// MaxAttempts is how many times a run is tried before giving up and
+	// waiting for the next scheduled occurrence.
+	MaxAttempts int
+
+	// RetryBackoff is the delay before the first retry; it doubles per attempt.
+	RetryBackoff time.Duration
+
+	// SendTimeout bounds generating and delivering a single report.
+	SendTimeout time.Duration
+}
+
+// DefaultReportWorkerConfig returns sensible defaults.
+func DefaultReportWorkerConfig() ReportWorkerConfig {
+	return ReportWorkerConfig{
+		Interval:     time.Minute,
+		MaxAttempts:  3,
+		RetryBackoff: 5 * time.Minute,
+		SendTimeout:  2 * time.Minute,
+	}
+}
+
+// ReportWorker generates reports for due schedules and delivers them.
+type ReportWorker struct {
+	store  ReportStore
+	sender report.Sender
+	config ReportWorkerConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	clocked
+}
+
+// NewReportWorker creates a new report worker.
+func NewReportWorker(store ReportStore, sender report.Sender, config ReportWorkerConfig, logger *slog.Logger) *ReportWorker {
+	return &ReportWorker{
+		store:  store,
+		sender: sender,
+		config: config,
+		logger: logger.With("component", "report_worker"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *ReportWorker) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *ReportWorker) Stop() {
+	close(w.stopCh)
+}
+
+func (w *ReportWorker) run(ctx context.Context) {
+	w.logger.Info("report worker started",
+		"interval", w.config.Interval,
+		"max_attempts", w.config.MaxAttempts,
+	)
+
+	w.runOnce(ctx)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("report worker stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("report worker stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *ReportWorker) runOnce(ctx context.Context) {
+	now := w.now()
+	schedules, err := w.store.ListDueReportSchedules(ctx, now)
+	if err != nil {
+		w.logger.Error("failed to list due report schedules", "error", err)
+		return
+	}
+
+	for i := range schedules {
+		w.process(ctx, &schedules[i], now)
+	}
+}
+
+// process runs one schedule and records the outcome. On failure the run is
+// retried with exponential backoff up to MaxAttempts, after which the
+// schedule moves on to its next regular occurrence.
+func (w *ReportWorker) process(ctx context.Context, rs *types.ReportSchedule, now time.Time) {
+	attempt := rs.ConsecutiveFailures + 1
+	log := w.logger.With("schedule_id", rs.ID, "schedule", rs.Name, "attempt", attempt)
+
+	cron, err := report.ParseCron(rs.CronExpression)
+	if err != nil {
+		// Validated on save, so this only happens if the row was edited by hand
+		log.Error("invalid cron expression, disabling run", "cron", rs.CronExpression, "error", err)
+		w.recordRun(ctx, rs.ID, types.ReportDeliveryFailed, fmt.Sprintf("invalid cron expression: %v", err), attempt, nil)
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(ctx, w.config.SendTimeout)
+	doc, sendErr := w.generateAndSend(sendCtx, rs, now)
+	cancel()
+
+	delivery := &types.ReportDelivery{
+		ScheduleID:     rs.ID,
+		Status:         types.ReportDeliverySent,
+		DeliveryMethod: rs.DeliveryMethod,
+		Destination:    report.Destination(rs),
+		Attempt:        attempt,
+		WindowStart:    now.AddDate(0, 0, -rs.WindowDays),
+		WindowEnd:      now,
+	}
+	if doc != nil {
+		delivery.TargetCount = doc.TargetCount
+		delivery.SizeBytes = len(doc.Body)
+		delivery.WindowStart = doc.WindowStart
+		delivery.WindowEnd = doc.WindowEnd
+	}
+	if sendErr != nil {
+		delivery.Status = types.ReportDeliveryFailed
+		delivery.Error = sendErr.Error()
+	}
+	if err := w.store.CreateReportDelivery(ctx, delivery); err != nil {
+		log.Error("failed to record report delivery", "error", err)
+	}
+
+	if sendErr == nil {
+		next := nextRun(cron, now)
+		log.Info("report delivered", "method", rs.DeliveryMethod, "targets", delivery.TargetCount, "bytes", delivery.SizeBytes, "next_run", next)
+		w.recordRun(ctx, rs.ID, types.ReportDeliverySent, "", 0, next)
+		return
+	}
+
+	if attempt < w.config.MaxAttempts {
+		retryAt := now.Add(w.config.RetryBackoff << (attempt - 1))
+		log.Warn("report delivery failed, will retry", "error", sendErr, "retry_at", retryAt)
+		w.recordRun(ctx, rs.ID, types.ReportDeliveryFailed, sendErr.Error(), attempt, &retryAt)
+		return
+	}
+
+	next := nextRun(cron, now)
+	log.Error("report delivery failed, giving up until next occurrence", "error", sendErr, "next_run", next)
+	w.recordRun(ctx, rs.ID, types.ReportDeliveryFailed, sendErr.Error(), 0, next)
+}
+
+func (w *ReportWorker) generateAndSend(ctx context.Context, rs *types.ReportSchedule, now time.Time) (*report.Document, error) {
+	targets, err := w.store.GetReportScheduleTargets(ctx, rs)
+	if err != nil {
+		return nil, fmt.Errorf("resolving targets: %w", err)
+	}
+
+	data := make([]report.TargetData, 0, len(targets))
+	for _, t := range targets {
+		rows, err := w.store.GetTargetReport(ctx, t.ID, rs.WindowDays, "")
+		if err != nil {
+			return nil, fmt.Errorf("computing report for %s: %w", t.IP, err)
+		}
+		data = append(data, report.TargetData{TargetID: t.ID, TargetIP: t.IP, Rows: rows})
+	}
+
+	doc, err := report.BuildDocument(rs, data, now)
+	if err != nil {
+		return nil, fmt.Errorf("building report: %w", err)
+	}
+	if err := w.sender.Send(ctx, rs, doc); err != nil {
+		return doc, err
+	}
+	return doc, nil
+}
+
+func (w *ReportWorker) recordRun(ctx context.Context, id string, status types.ReportDeliveryStatus, errMsg string, failures int, next *time.Time) {
+	if err := w.store.RecordReportScheduleRun(ctx, id, status, errMsg, failures, next); err != nil {
+		w.logger.Error("failed to record report schedule run", "schedule_id", id, "error", err)
+	}
+}
+
+// nextRun returns the schedule's next occurrence, or nil if it never fires again.
+func nextRun(cron *report.CronSchedule, now time.Time) *time.Time {
+	next := cron.Next(now)
+	if next.IsZero() {
+		return nil
+	}
+	return &next
+}
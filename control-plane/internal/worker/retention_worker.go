@@ -0,0 +1,205 @@
+// Package worker - Retention worker preserves raw probe results for targets
+// with a retention override beyond the global probe_results policy.
+package worker
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+)
+
+// RetentionStore defines the storage interface for the retention worker.
+type RetentionStore interface {
+	// GetProbeRetentionDays returns the global probe_results retention (0 = none).
+	GetProbeRetentionDays(ctx context.Context) (int, error)
+
+	// ListRetainedTargets returns targets retained beyond globalDays and
+	// previously archived targets that no longer are.
+	ListRetainedTargets(ctx context.Context, globalDays int) ([]store.RetainedTarget, error)
+
+	// ArchiveProbeResults copies results in (from, to] and advances the watermark.
+	ArchiveProbeResults(ctx context.Context, targetID string, from, to time.Time) (int64, error)
+
+	// PruneProbeArchive deletes archived results older than before.
+	PruneProbeArchive(ctx context.Context, targetID string, before time.Time) (int64, error)
+
+	// DeleteProbeRetentionProgress forgets a target's archive watermark.
+	DeleteProbeRetentionProgress(ctx context.Context, targetID string) error
+}
+
+// RetentionWorkerConfig holds configuration for the retention worker.
+type RetentionWorkerConfig struct {
+	// Interval between archive passes. Must be far shorter than the global
+	// probe_results retention so rows are copied before their chunk drops.
+	Interval time.Duration
+
+	// ArchiveDelay skips the most recent results so late-arriving (buffered)
+	// agent results are still in probe_results when their window is copied.
+	ArchiveDelay time.Duration
+
+	// BatchWindow bounds how much of one target's history is copied per
+	// statement, keeping the initial backfill of a new override cheap.
+	BatchWindow time.Duration
+}
+
+// DefaultRetentionWorkerConfig returns sensible defaults.
+func DefaultRetentionWorkerConfig() RetentionWorkerConfig {
+	return RetentionWorkerConfig{
+		Interval:     time.Hour,
+		ArchiveDelay: time.Hour,
+		BatchWindow:  24 * time.Hour,
+	}
+}
+
+// RetentionWorker copies raw probe results for targets with a retention
+// override into probe_results_archive and prunes the archive per target.
+type RetentionWorker struct {
+	store  RetentionStore
+	config RetentionWorkerConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	clocked
+}
+
+// NewRetentionWorker creates a new retention worker.
+func NewRetentionWorker(store RetentionStore, config RetentionWorkerConfig, logger *slog.Logger) *RetentionWorker {
+	return &RetentionWorker{
+		store:  store,
+		config: config,
+		logger: logger.With("component", "retention_worker"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *RetentionWorker) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *RetentionWorker) Stop() {
+	close(w.stopCh)
+}
+
+func (w *RetentionWorker) run(ctx context.Context) {
+	w.logger.Info("retention worker started",
+		"interval", w.config.Interval,
+		"archive_delay", w.config.ArchiveDelay,
+	)
+
+	w.runOnce(ctx)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("retention worker stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("retention worker stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *RetentionWorker) runOnce(ctx context.Context) {
+	globalDays, err := w.store.GetProbeRetentionDays(ctx)
+	if err != nil {
+		w.logger.Error("failed to read probe retention policy", "error", err)
+		return
+	}
+	if globalDays == 0 {
+		// Nothing is ever dropped from probe_results, so there's nothing to preserve
+		w.logger.Debug("probe_results has no retention policy, skipping")
+		return
+	}
+
+	targets, err := w.store.ListRetainedTargets(ctx, globalDays)
+	if err != nil {
+		w.logger.Error("failed to list retained targets", "error", err)
+		return
+	}
+
+	now := w.now()
+	var archived, pruned int64
+	for _, t := range targets {
+		if t.RetentionDays == 0 {
+			n, err := w.purge(ctx, t.TargetID, now)
+			if err != nil {
+				w.logger.Error("failed to purge archive", "target", t.TargetID, "error", err)
+				continue
+			}
+			pruned += n
+			continue
+		}
+
+		n, err := w.archive(ctx, t, now, globalDays)
+		archived += n
+		if err != nil {
+			w.logger.Error("failed to archive probe results", "target", t.TargetID, "error", err)
+			continue
+		}
+
+		n, err = w.store.PruneProbeArchive(ctx, t.TargetID, now.AddDate(0, 0, -t.RetentionDays))
+		if err != nil {
+			w.logger.Error("failed to prune archive", "target", t.TargetID, "error", err)
+			continue
+		}
+		pruned += n
+	}
+
+	if archived > 0 || pruned > 0 {
+		w.logger.Info("retention pass complete",
+			"targets", len(targets),
+			"archived_rows", archived,
+			"pruned_rows", pruned,
+		)
+	}
+}
+
+// archive copies a target's results from its watermark up to now minus the
+// archive delay, in BatchWindow steps.
+func (w *RetentionWorker) archive(ctx context.Context, t store.RetainedTarget, now time.Time, globalDays int) (int64, error) {
+	until := now.Add(-w.config.ArchiveDelay)
+
+	// Never look further back than probe_results still holds
+	from := now.AddDate(0, 0, -globalDays)
+	if t.ArchivedThrough != nil && t.ArchivedThrough.After(from) {
+		from = *t.ArchivedThrough
+	}
+
+	var total int64
+	for from.Before(until) {
+		to := from.Add(w.config.BatchWindow)
+		if to.After(until) {
+			to = until
+		}
+		n, err := w.store.ArchiveProbeResults(ctx, t.TargetID, from, to)
+		if err != nil {
+			return total, err
+		}
+		total += n
+		from = to
+	}
+	return total, nil
+}
+
+// purge removes all archived results for a target that is no longer retained.
+func (w *RetentionWorker) purge(ctx context.Context, targetID string, now time.Time) (int64, error) {
+	n, err := w.store.PruneProbeArchive(ctx, targetID, now)
+	if err != nil {
+		return 0, err
+	}
+	if err := w.store.DeleteProbeRetentionProgress(ctx, targetID); err != nil {
+		return n, err
+	}
+	w.logger.Info("purged archive for target no longer retained", "target", targetID, "rows", n)
+	return n, nil
+}
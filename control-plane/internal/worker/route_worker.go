@@ -0,0 +1,313 @@
+// Package worker - Route worker detects routing changes from reply TTLs.
+//
+// An ICMP echo reply arrives with its initial TTL (64, 128 or 255) minus one
+// per router on the return path, so the reply TTL reveals the hop count. A
+// material shift in a target's hop count as seen from one agent usually
+// means a routing change or path failover, often before latency or loss
+// make it obvious.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/store"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// RouteStore defines the storage interface for the route worker.
+type RouteStore interface {
+	// GetReplyTTLObservations returns the median reply TTL per agent/target
+	// pair over the window, for pairs with at least minSamples results.
+	GetReplyTTLObservations(ctx context.Context, window time.Duration, minSamples int) ([]store.ReplyTTLObservation, error)
+
+	// ListTargetPathStates returns the settled hop count per pair.
+	ListTargetPathStates(ctx context.Context) ([]store.TargetPathState, error)
+
+	// UpsertTargetPathStates writes settled hop counts.
+	UpsertTargetPathStates(ctx context.Context, states []store.TargetPathState) error
+
+	// RecordHopCountChange stores a change and logs it on the target.
+	RecordHopCountChange(ctx context.Context, change *types.HopCountChange, targetIP string) error
+
+	// Alerts (only used when RaiseAlerts is set)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error)
+	ResolveAlert(ctx context.Context, alertID string, description string) error
+}
+
+// RouteWorkerConfig holds configuration for the route worker.
+type RouteWorkerConfig struct {
+	// Interval between detection passes.
+	Interval time.Duration
+
+	// Window is how much recent probe history the median reply TTL is taken
+	// over. A new path must carry the majority of replies in the window
+	// before it registers, which filters out brief ECMP flips.
+	Window time.Duration
+
+	// MinSamples is the fewest TTL-bearing results a pair needs in Window.
+	MinSamples int
+
+	// MinHopDelta is the smallest hop-count change that counts as material.
+	MinHopDelta int
+
+	// RaiseAlerts creates a low-severity path_change alert per change.
+	RaiseAlerts bool
+
+	// AlertHold is how long a path_change alert stays active before the
+	// worker resolves it.
+	AlertHold time.Duration
+}
+
+// DefaultRouteWorkerConfig returns sensible defaults.
+func DefaultRouteWorkerConfig() RouteWorkerConfig {
+	return RouteWorkerConfig{
+		Interval:    5 * time.Minute,
+		Window:      15 * time.Minute,
+		MinSamples:  5,
+		MinHopDelta: 2,
+		RaiseAlerts: false,
+		AlertHold:   time.Hour,
+	}
+}
+
+// RouteWorker tracks per-pair hop counts and records material changes.
+type RouteWorker struct {
+	store  RouteStore
+	config RouteWorkerConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	clocked
+}
+
+// NewRouteWorker creates a new route worker.
+func NewRouteWorker(store RouteStore, config RouteWorkerConfig, logger *slog.Logger) *RouteWorker {
+	return &RouteWorker{
+		store:  store,
+		config: config,
+		logger: logger.With("component", "route_worker"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *RouteWorker) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *RouteWorker) Stop() {
+	close(w.stopCh)
+}
+
+func (w *RouteWorker) run(ctx context.Context) {
+	w.logger.Info("route worker started",
+		"interval", w.config.Interval,
+		"window", w.config.Window,
+		"min_hop_delta", w.config.MinHopDelta,
+		"alerts", w.config.RaiseAlerts,
+	)
+
+	w.runOnce(ctx)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("route worker stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("route worker stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+func (w *RouteWorker) runOnce(ctx context.Context) {
+	observations, err := w.store.GetReplyTTLObservations(ctx, w.config.Window, w.config.MinSamples)
+	if err != nil {
+		w.logger.Error("failed to get reply TTL observations", "error", err)
+		return
+	}
+
+	states, err := w.store.ListTargetPathStates(ctx)
+	if err != nil {
+		w.logger.Error("failed to list path states", "error", err)
+		return
+	}
+	settled := make(map[string]store.TargetPathState, len(states))
+	for _, st := range states {
+		settled[st.TargetID+"|"+st.AgentID] = st
+	}
+
+	updates := make([]store.TargetPathState, 0, len(observations))
+	changes := 0
+	for _, o := range observations {
+		hops := types.InferHopCount(o.ReplyTTL)
+		if hops < 0 {
+			continue
+		}
+
+		next := store.TargetPathState{
+			TargetID:   o.TargetID,
+			AgentID:    o.AgentID,
+			ReplyTTL:   o.ReplyTTL,
+			HopCount:   hops,
+			ObservedAt: o.ObservedAt,
+			ChangedAt:  o.ObservedAt,
+		}
+
+		if prev, ok := settled[o.TargetID+"|"+o.AgentID]; ok {
+			delta := hops - prev.HopCount
+			if delta < 0 {
+				delta = -delta
+			}
+			if delta < w.config.MinHopDelta {
+				// Not material: keep the settled baseline so a slow creep
+				// still registers once it adds up
+				next.ReplyTTL = prev.ReplyTTL
+				next.HopCount = prev.HopCount
+				next.ChangedAt = prev.ChangedAt
+			} else if err := w.recordChange(ctx, o, prev, hops); err != nil {
+				w.logger.Error("failed to record hop count change",
+					"target_id", o.TargetID,
+					"agent_id", o.AgentID,
+					"error", err,
+				)
+				continue
+			} else {
+				changes++
+			}
+		}
+
+		updates = append(updates, next)
+	}
+
+	if err := w.store.UpsertTargetPathStates(ctx, updates); err != nil {
+		w.logger.Error("failed to update path states", "error", err)
+		return
+	}
+
+	resolved := 0
+	if w.config.RaiseAlerts {
+		resolved = w.resolveExpiredAlerts(ctx)
+	}
+
+	if changes > 0 || resolved > 0 {
+		w.logger.Info("route pass complete",
+			"pairs", len(updates),
+			"changes", changes,
+			"alerts_resolved", resolved,
+		)
+	}
+}
+
+// recordChange stores a material hop-count change, raising an alert first
+// when enabled so the change row can point at it.
+func (w *RouteWorker) recordChange(ctx context.Context, o store.ReplyTTLObservation, prev store.TargetPathState, hops int) error {
+	change := &types.HopCountChange{
+		TargetID:     o.TargetID,
+		AgentID:      o.AgentID,
+		PreviousTTL:  prev.ReplyTTL,
+		CurrentTTL:   o.ReplyTTL,
+		PreviousHops: prev.HopCount,
+		CurrentHops:  hops,
+	}
+
+	if w.config.RaiseAlerts {
+		alertID, err := w.raiseAlert(ctx, o, change)
+		if err != nil {
+			// The change is still worth recording without its alert
+			w.logger.Error("failed to raise path change alert", "target_id", o.TargetID, "error", err)
+		} else {
+			change.AlertID = &alertID
+		}
+	}
+
+	if err := w.store.RecordHopCountChange(ctx, change, o.TargetIP); err != nil {
+		return err
+	}
+
+	w.logger.Info("hop count changed",
+		"target_id", o.TargetID,
+		"target_ip", o.TargetIP,
+		"agent_id", o.AgentID,
+		"previous_hops", prev.HopCount,
+		"current_hops", hops,
+	)
+	return nil
+}
+
+// raiseAlert creates an informational path_change alert for the pair, or
+// returns the one already open.
+func (w *RouteWorker) raiseAlert(ctx context.Context, o store.ReplyTTLObservation, change *types.HopCountChange) (string, error) {
+	existing, err := w.store.FindActiveAlertForTarget(ctx, o.TargetID, types.AlertTypePathChange, o.AgentID)
+	if err != nil {
+		return "", fmt.Errorf("finding active alert: %w", err)
+	}
+	if existing != nil {
+		return existing.ID, nil
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetID:        o.TargetID,
+		TargetIP:        o.TargetIP,
+		AgentID:         o.AgentID,
+		AlertType:       types.AlertTypePathChange,
+		Severity:        types.AlertSeverityInfo,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: types.AlertSeverityInfo,
+		PeakSeverity:    types.AlertSeverityInfo,
+		Title:           fmt.Sprintf("Route change to %s", o.TargetIP),
+		Message: fmt.Sprintf("Hop count changed from %d to %d (reply TTL %d -> %d)",
+			change.PreviousHops, change.CurrentHops, change.PreviousTTL, change.CurrentTTL),
+		DetectedAt:    now,
+		LastUpdatedAt: now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return "", fmt.Errorf("creating alert: %w", err)
+	}
+	return alert.ID, nil
+}
+
+// resolveExpiredAlerts resolves path_change alerts older than AlertHold.
+func (w *RouteWorker) resolveExpiredAlerts(ctx context.Context) int {
+	alertType := types.AlertTypePathChange
+	status := types.AlertStatusActive
+	alerts, err := w.store.ListAlerts(ctx, types.AlertFilter{
+		AlertType: &alertType,
+		Status:    &status,
+		Limit:     1000,
+	})
+	if err != nil {
+		w.logger.Error("failed to list path change alerts", "error", err)
+		return 0
+	}
+
+	resolved := 0
+	cutoff := w.now().Add(-w.config.AlertHold)
+	for _, alert := range alerts {
+		if alert.DetectedAt.After(cutoff) {
+			continue
+		}
+		if err := w.store.ResolveAlert(ctx, alert.ID, "Route change acknowledged by hold period"); err != nil {
+			w.logger.Error("failed to resolve path change alert", "alert_id", alert.ID, "error", err)
+			continue
+		}
+		resolved++
+	}
+	return resolved
+}
@@ -0,0 +1,244 @@
+// Package worker - Ship lag watchdog alerts when an agent's results reach
+// the control plane long after they were probed.
+package worker
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// ShipLagStore defines the storage interface for the ship lag watchdog.
+type ShipLagStore interface {
+	GetShipLag(ctx context.Context, agentID string, window time.Duration) ([]types.AgentShipLag, error)
+	GetAlertConfigInt(ctx context.Context, key string, defaultVal int) (int, error)
+	GetTarget(ctx context.Context, id string) (*types.Target, error)
+
+	ListAlerts(ctx context.Context, filter types.AlertFilter) ([]types.Alert, error)
+	FindActiveAlertForTarget(ctx context.Context, targetID string, alertType types.AlertType, agentID string) (*types.Alert, error)
+	CreateAlert(ctx context.Context, alert *types.Alert) error
+	UpdateAlertSummary(ctx context.Context, alertID, title, message string) error
+	ResolveAlert(ctx context.Context, alertID string, description string) error
+}
+
+// ShipLagWatchdogConfig holds configuration for the ship lag watchdog.
+type ShipLagWatchdogConfig struct {
+	// Interval between checks.
+	Interval time.Duration
+
+	// Window is how far back each agent's batch lags are summarised.
+	Window time.Duration
+
+	// Threshold is the p95 ship lag, or oldest queued result age, at which
+	// an agent is alerted on. Overridden by ship_lag_alert_seconds in
+	// alert_config.
+	Threshold time.Duration
+}
+
+// DefaultShipLagWatchdogConfig returns sensible defaults.
+func DefaultShipLagWatchdogConfig() ShipLagWatchdogConfig {
+	return ShipLagWatchdogConfig{
+		Interval:  time.Minute,
+		Window:    config.ShipLagWindow,
+		Threshold: config.ShipLagAlertThreshold,
+	}
+}
+
+// ShipLagWatchdog raises a ship_lag alert for each agent that is falling
+// behind on shipping its results. A backed-up agent keeps delivering
+// results, so its targets look monitored, but every state change they show
+// is as old as the queue. The alerts hang off the pipeline canary target,
+// which every agent probes, with the agent set.
+type ShipLagWatchdog struct {
+	store  ShipLagStore
+	config ShipLagWatchdogConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	clocked
+}
+
+// NewShipLagWatchdog creates a new ship lag watchdog.
+func NewShipLagWatchdog(store ShipLagStore, config ShipLagWatchdogConfig, logger *slog.Logger) *ShipLagWatchdog {
+	return &ShipLagWatchdog{
+		store:  store,
+		config: config,
+		logger: logger.With("component", "ship_lag_watchdog"),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// Start begins the worker in a goroutine.
+func (w *ShipLagWatchdog) Start(ctx context.Context) {
+	go w.run(ctx)
+}
+
+// Stop signals the worker to stop.
+func (w *ShipLagWatchdog) Stop() {
+	close(w.stopCh)
+}
+
+func (w *ShipLagWatchdog) run(ctx context.Context) {
+	w.logger.Info("ship lag watchdog started",
+		"interval", w.config.Interval,
+		"window", w.config.Window,
+	)
+
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			w.logger.Info("ship lag watchdog stopping (context cancelled)")
+			return
+		case <-w.stopCh:
+			w.logger.Info("ship lag watchdog stopping (stop signal)")
+			return
+		case <-ticker.C:
+			w.runOnce(ctx)
+		}
+	}
+}
+
+// threshold reads the alert threshold from alert_config.
+func (w *ShipLagWatchdog) threshold(ctx context.Context) time.Duration {
+	seconds := int(w.config.Threshold / time.Second)
+	if val, err := w.store.GetAlertConfigInt(ctx, "ship_lag_alert_seconds", seconds); err == nil && val > 0 {
+		return time.Duration(val) * time.Second
+	}
+	return w.config.Threshold
+}
+
+func (w *ShipLagWatchdog) runOnce(ctx context.Context) {
+	lags, err := w.store.GetShipLag(ctx, "", w.config.Window)
+	if err != nil {
+		w.logger.Error("failed to get ship lag", "error", err)
+		return
+	}
+	threshold := w.threshold(ctx)
+
+	behind := make(map[string]bool)
+	raised := 0
+	for _, lag := range lags {
+		if !lag.Behind(threshold) {
+			continue
+		}
+		behind[lag.AgentID] = true
+
+		existing, err := w.store.FindActiveAlertForTarget(ctx, types.CanaryTargetID, types.AlertTypeShipLag, lag.AgentID)
+		if err != nil {
+			w.logger.Error("failed to find ship lag alert", "agent_id", lag.AgentID, "error", err)
+			continue
+		}
+		if err := w.raise(ctx, existing, lag, threshold); err != nil {
+			w.logger.Error("failed to raise ship lag alert", "agent_id", lag.AgentID, "error", err)
+			continue
+		}
+		raised++
+	}
+
+	resolved := w.resolveRecovered(ctx, behind)
+
+	if len(behind) > 0 || resolved > 0 {
+		w.logger.Info("ship lag check complete",
+			"agents_behind", len(behind),
+			"alerts_raised", raised,
+			"alerts_resolved", resolved,
+		)
+	}
+}
+
+// raise creates the agent's ship lag alert or brings the open one up to
+// date.
+func (w *ShipLagWatchdog) raise(ctx context.Context, existing *types.Alert, lag types.AgentShipLag, threshold time.Duration) error {
+	title := fmt.Sprintf("Agent %s falling behind shipping results", lag.AgentName)
+	message := fmt.Sprintf("p95 ship lag %s over %d batches in the last %s, oldest queued result %s (threshold %s)",
+		msDuration(lag.P95Ms), lag.Batches, lag.Window,
+		msDuration(float64(lag.OldestQueuedAgeMs)), threshold)
+
+	if existing != nil {
+		if err := w.store.UpdateAlertSummary(ctx, existing.ID, title, message); err != nil {
+			return fmt.Errorf("updating alert: %w", err)
+		}
+		return nil
+	}
+
+	canary, err := w.store.GetTarget(ctx, types.CanaryTargetID)
+	if err != nil {
+		return fmt.Errorf("getting canary target: %w", err)
+	}
+	if canary == nil {
+		return nil
+	}
+
+	now := w.now()
+	alert := &types.Alert{
+		ID:              uuid.New().String(),
+		TargetID:        types.CanaryTargetID,
+		TargetIP:        canary.IP,
+		AgentID:         lag.AgentID,
+		AlertType:       types.AlertTypeShipLag,
+		Severity:        types.AlertSeverityWarning,
+		Status:          types.AlertStatusActive,
+		InitialSeverity: types.AlertSeverityWarning,
+		PeakSeverity:    types.AlertSeverityWarning,
+		Title:           title,
+		Message:         message,
+		DetectedAt:      now,
+		LastUpdatedAt:   now,
+	}
+	if err := w.store.CreateAlert(ctx, alert); err != nil {
+		return fmt.Errorf("creating alert: %w", err)
+	}
+
+	w.logger.Warn("agent falling behind shipping results",
+		"agent_id", lag.AgentID,
+		"agent", lag.AgentName,
+		"p95_ms", lag.P95Ms,
+		"oldest_queued_age_ms", lag.OldestQueuedAgeMs,
+	)
+	return nil
+}
+
+// resolveRecovered resolves open ship lag alerts for agents no longer
+// behind. An agent that has stopped heartbeating drops out of the lag
+// summary and is resolved here; agent_down alerts cover it.
+func (w *ShipLagWatchdog) resolveRecovered(ctx context.Context, behind map[string]bool) int {
+	alertType := types.AlertTypeShipLag
+	resolved := 0
+	for _, status := range []types.AlertStatus{types.AlertStatusActive, types.AlertStatusAcknowledged} {
+		alerts, err := w.store.ListAlerts(ctx, types.AlertFilter{
+			AlertType: &alertType,
+			Status:    &status,
+			Limit:     1000,
+		})
+		if err != nil {
+			w.logger.Error("failed to list ship lag alerts", "error", err)
+			continue
+		}
+
+		for _, alert := range alerts {
+			if behind[alert.AgentID] {
+				continue
+			}
+			if err := w.store.ResolveAlert(ctx, alert.ID, "Agent shipping results promptly again"); err != nil {
+				w.logger.Error("failed to resolve ship lag alert", "alert_id", alert.ID, "error", err)
+				continue
+			}
+			resolved++
+		}
+	}
+	return resolved
+}
+
+// msDuration renders milliseconds as a duration rounded to the second.
+func msDuration(ms float64) time.Duration {
+	return (time.Duration(ms) * time.Millisecond).Round(time.Second)
+}
@@ -0,0 +1,197 @@
+// Package worker - Singleton runs a worker on one control plane instance at
+// a time.
+package worker
+
+import (
+	"context"
+	"hash/fnv"
+	"log/slog"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
+)
+
+// SingletonLocker holds the advisory locks that elect each singleton
+// worker's leader. store.LockSession implements it.
+type SingletonLocker interface {
+	TryLock(ctx context.Context, key int64) (bool, error)
+	Held(ctx context.Context, key int64) (bool, error)
+	Unlock(ctx context.Context, key int64) error
+}
+
+// SingletonWorker is a worker that runs until the context passed to Start
+// is cancelled, and can be started again afterwards.
+type SingletonWorker interface {
+	Start(ctx context.Context)
+}
+
+// SingletonConfig holds configuration for a singleton worker.
+type SingletonConfig struct {
+	// Interval between lock attempts while standing by, and between checks
+	// that the lock is still held while leading.
+	Interval time.Duration
+
+	// Timeout bounds each lock attempt, check and release.
+	Timeout time.Duration
+}
+
+// DefaultSingletonConfig returns sensible defaults.
+func DefaultSingletonConfig() SingletonConfig {
+	return SingletonConfig{
+		Interval: config.SingletonLockInterval,
+		Timeout:  config.SingletonLockTimeout,
+	}
+}
+
+// Singleton runs a worker only while this instance holds the worker's
+// advisory lock, so workers that write shared state (state transitions,
+// evaluation, alerting, syncs) run on exactly one control plane instance
+// while ingestion and the API scale out. Standby instances retry the lock
+// every Interval. The leader checks it still holds the lock at the same
+// interval, and cancels the worker as soon as it doesn't, so a failed-over
+// worker overlaps its old leader by at most about one Interval.
+type Singleton struct {
+	name   string
+	key    int64
+	worker SingletonWorker
+	locker SingletonLocker
+	config SingletonConfig
+	logger *slog.Logger
+	stopCh chan struct{}
+
+	// leading is set while this instance holds the lock and runs the
+	// worker; settled once the first lock attempt has completed
+	leading atomic.Bool
+	settled atomic.Bool
+}
+
+// NewSingleton wraps worker so that it runs on one instance at a time. name
+// identifies the worker across instances and derives its lock key.
+func NewSingleton(name string, worker SingletonWorker, locker SingletonLocker, config SingletonConfig, logger *slog.Logger) *Singleton {
+	return &Singleton{
+		name:   name,
+		key:    singletonLockKey(name),
+		worker: worker,
+		locker: locker,
+		config: config,
+		logger: logger.With("component", "singleton", "worker", name),
+		stopCh: make(chan struct{}),
+	}
+}
+
+// singletonLockKey derives a worker's advisory lock key from its name. The
+// prefix keeps keys clear of the store's own advisory locks, and the key is
+// kept positive.
+func singletonLockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("icmp-mon:singleton:" + name))
+	return int64(h.Sum64() & math.MaxInt64)
+}
+
+// Start begins contending for the lock in a goroutine.
+func (s *Singleton) Start(ctx context.Context) {
+	go s.run(ctx)
+}
+
+// Stop stops the worker, if leading, and releases the lock.
+func (s *Singleton) Stop() {
+	close(s.stopCh)
+}
+
+// Leading reports whether this instance is running the worker.
+func (s *Singleton) Leading() bool {
+	return s.leading.Load()
+}
+
+// Ready reports whether the singleton has settled: a standby instance once
+// it has tried the lock, the leader once its worker is ready. Standby
+// instances are ready so they can still serve the API.
+func (s *Singleton) Ready() bool {
+	if !s.settled.Load() {
+		return false
+	}
+	if !s.leading.Load() {
+		return true
+	}
+	if r, ok := s.worker.(interface{ Ready() bool }); ok {
+		return r.Ready()
+	}
+	return true
+}
+
+func (s *Singleton) run(ctx context.Context) {
+	s.logger.Info("singleton worker contending for lock", "interval", s.config.Interval)
+
+	var cancel context.CancelFunc
+	yield := func(reason string) {
+		if cancel == nil {
+			return
+		}
+		cancel()
+		cancel = nil
+		s.leading.Store(false)
+		s.logger.Warn("singleton worker yielded lock", "reason", reason)
+	}
+	defer func() {
+		if cancel == nil {
+			return
+		}
+		yield("stopping")
+		unlockCtx, done := context.WithTimeout(context.Background(), s.config.Timeout)
+		defer done()
+		if err := s.locker.Unlock(unlockCtx, s.key); err != nil {
+			s.logger.Error("failed to release singleton lock", "error", err)
+		}
+	}()
+
+	tick := func() {
+		checkCtx, done := context.WithTimeout(ctx, s.config.Timeout)
+		defer done()
+
+		if cancel != nil {
+			held, err := s.locker.Held(checkCtx, s.key)
+			switch {
+			case err != nil:
+				s.logger.Error("failed to check singleton lock", "error", err)
+				yield("lock check failed")
+			case !held:
+				yield("lock lost")
+			}
+			return
+		}
+
+		locked, err := s.locker.TryLock(checkCtx, s.key)
+		s.settled.Store(true)
+		if err != nil {
+			s.logger.Error("failed to take singleton lock", "error", err)
+			return
+		}
+		if !locked {
+			return
+		}
+
+		var workerCtx context.Context
+		workerCtx, cancel = context.WithCancel(ctx)
+		s.leading.Store(true)
+		s.logger.Info("singleton worker took lock, starting")
+		s.worker.Start(workerCtx)
+	}
+
+	tick()
+
+	ticker := time.NewTicker(s.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			tick()
+		}
+	}
+}
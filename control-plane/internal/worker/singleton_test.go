@@ -0,0 +1,198 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeLocker is one advisory lock shared with an imaginary other instance:
+// TryLock takes it when free, Unlock frees it.
+type fakeLocker struct {
+	mu      sync.Mutex
+	free    bool
+	held    bool
+	heldErr error
+	tries   int
+	unlocks int
+}
+
+func (l *fakeLocker) TryLock(ctx context.Context, key int64) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.tries++
+	if !l.free {
+		return false, nil
+	}
+	l.free, l.held = false, true
+	return true, nil
+}
+
+func (l *fakeLocker) Held(ctx context.Context, key int64) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.held, l.heldErr
+}
+
+func (l *fakeLocker) Unlock(ctx context.Context, key int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.unlocks++
+	l.free, l.held = true, false
+	return nil
+}
+
+// with runs fn holding the locker's mutex.
+func (l *fakeLocker) with(fn func(l *fakeLocker)) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fn(l)
+}
+
+// fakeSingletonWorker records the context of every Start.
+type fakeSingletonWorker struct {
+	mu   sync.Mutex
+	ctxs []context.Context
+}
+
+func (w *fakeSingletonWorker) Start(ctx context.Context) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.ctxs = append(w.ctxs, ctx)
+}
+
+func (w *fakeSingletonWorker) starts() []context.Context {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]context.Context(nil), w.ctxs...)
+}
+
+// eventually fails the test if cond isn't true within a second.
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func cancelled(ctx context.Context) func() bool {
+	return func() bool { return ctx.Err() != nil }
+}
+
+func TestSingleton_Leadership(t *testing.T) {
+	tests := []struct {
+		name string
+		free bool
+		run  func(t *testing.T, s *Singleton, l *fakeLocker, w *fakeSingletonWorker)
+	}{
+		{
+			name: "standby takes the lock once it is free",
+			run: func(t *testing.T, s *Singleton, l *fakeLocker, w *fakeSingletonWorker) {
+				eventually(t, "several lock attempts", func() bool {
+					var tries int
+					l.with(func(l *fakeLocker) { tries = l.tries })
+					return tries >= 3
+				})
+				if s.Leading() || len(w.starts()) != 0 {
+					t.Fatal("standby started the worker without the lock")
+				}
+				if !s.Ready() {
+					t.Error("standby not ready after trying the lock")
+				}
+
+				l.with(func(l *fakeLocker) { l.free = true })
+				eventually(t, "worker start", func() bool { return len(w.starts()) == 1 })
+				if !s.Leading() {
+					t.Error("not leading after taking the lock")
+				}
+			},
+		},
+		{
+			name: "lost lock cancels the worker",
+			free: true,
+			run: func(t *testing.T, s *Singleton, l *fakeLocker, w *fakeSingletonWorker) {
+				eventually(t, "worker start", func() bool { return len(w.starts()) == 1 })
+
+				l.with(func(l *fakeLocker) { l.held = false })
+				eventually(t, "worker context cancelled", cancelled(w.starts()[0]))
+				if s.Leading() {
+					t.Error("still leading after losing the lock")
+				}
+			},
+		},
+		{
+			name: "failed lock check yields",
+			free: true,
+			run: func(t *testing.T, s *Singleton, l *fakeLocker, w *fakeSingletonWorker) {
+				eventually(t, "worker start", func() bool { return len(w.starts()) == 1 })
+
+				l.with(func(l *fakeLocker) { l.heldErr = errors.New("connection reset") })
+				eventually(t, "worker context cancelled", cancelled(w.starts()[0]))
+				if s.Leading() {
+					t.Error("still leading after the lock check failed")
+				}
+			},
+		},
+		{
+			name: "re-acquired lock starts the worker again",
+			free: true,
+			run: func(t *testing.T, s *Singleton, l *fakeLocker, w *fakeSingletonWorker) {
+				eventually(t, "worker start", func() bool { return len(w.starts()) == 1 })
+
+				l.with(func(l *fakeLocker) { l.held = false })
+				eventually(t, "worker context cancelled", cancelled(w.starts()[0]))
+
+				l.with(func(l *fakeLocker) { l.free = true })
+				eventually(t, "second worker start", func() bool { return len(w.starts()) == 2 })
+				if !s.Leading() {
+					t.Error("not leading after re-acquiring the lock")
+				}
+				if err := w.starts()[1].Err(); err != nil {
+					t.Errorf("restarted worker context already done: %v", err)
+				}
+			},
+		},
+		{
+			name: "stop while leading releases the lock",
+			free: true,
+			run: func(t *testing.T, s *Singleton, l *fakeLocker, w *fakeSingletonWorker) {
+				eventually(t, "worker start", func() bool { return len(w.starts()) == 1 })
+
+				s.Stop()
+				eventually(t, "lock release", func() bool {
+					var unlocks int
+					l.with(func(l *fakeLocker) { unlocks = l.unlocks })
+					return unlocks == 1
+				})
+				if err := w.starts()[0].Err(); err == nil {
+					t.Error("worker context not cancelled on stop")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &fakeLocker{free: tt.free}
+			w := &fakeSingletonWorker{}
+			s := NewSingleton("test_worker", w, l, SingletonConfig{
+				Interval: time.Millisecond,
+				Timeout:  time.Second,
+			}, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			s.Start(ctx)
+
+			tt.run(t, s, l, w)
+		})
+	}
+}
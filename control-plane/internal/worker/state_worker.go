@@ -4,34 +4,36 @@ package worker
 import (
 	"context"
 	"log/slog"
+	"sync/atomic"
 	"time"
 
+	"github.com/pilot-net/icmp-mon/control-plane/internal/config"
 	"github.com/pilot-net/icmp-mon/pkg/types"
 )
 
 // StateStore defines the storage interface for the state worker.
 type StateStore interface {
 	// GetTargetsForDownTransition returns ACTIVE targets that haven't responded
-	// within the given threshold (should transition to DOWN).
+	// for their down threshold (override, else defaultThreshold) as of now.
 	// Only includes targets WITH an established baseline (alertable).
-	GetTargetsForDownTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error)
+	GetTargetsForDownTransition(ctx context.Context, now time.Time, defaultThreshold time.Duration) ([]types.Target, error)
 
 	// GetTargetsForUnresponsiveTransition returns ACTIVE targets WITHOUT a baseline
-	// that have stopped responding. These should transition to UNRESPONSIVE (not alertable).
-	GetTargetsForUnresponsiveTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error)
+	// that haven't responded since cutoff. These should transition to UNRESPONSIVE (not alertable).
+	GetTargetsForUnresponsiveTransition(ctx context.Context, cutoff time.Time) ([]types.Target, error)
 
-	// GetTargetsForExcludedTransition returns DOWN targets that have been
-	// unresponsive for the given duration (should transition to EXCLUDED).
+	// GetTargetsForExcludedTransition returns targets that have been DOWN
+	// since before cutoff (should transition to EXCLUDED).
 	// Excludes infrastructure and gateway IPs which should stay DOWN.
-	GetTargetsForExcludedTransition(ctx context.Context, threshold time.Duration) ([]types.Target, error)
+	GetTargetsForExcludedTransition(ctx context.Context, cutoff time.Time) ([]types.Target, error)
 
 	// GetTargetsForSmartRecheck returns EXCLUDED or UNRESPONSIVE targets in
 	// subnets that have no active customer coverage. These should be re-probed.
 	GetTargetsForSmartRecheck(ctx context.Context) ([]types.Target, error)
 
 	// GetTargetsForBaselineCheck returns ACTIVE targets that have been responding
-	// for at least the threshold duration but don't have a baseline yet.
-	GetTargetsForBaselineCheck(ctx context.Context, threshold time.Duration) ([]types.Target, error)
+	// since before cutoff but don't have a baseline yet.
+	GetTargetsForBaselineCheck(ctx context.Context, cutoff time.Time) ([]types.Target, error)
 
 	// TransitionTargetState changes a target's monitoring state with history.
 	TransitionTargetState(ctx context.Context, targetID string, newState types.MonitoringState, reason, triggeredBy string) error
@@ -53,6 +55,13 @@ type StateStore interface {
 
 	// PromoteStandbyToRepresentative promotes the oldest standby target to representative.
 	PromoteStandbyToRepresentative(ctx context.Context, subnetID string) (*types.Target, error)
+
+	// GetTargetsNeverResponded returns up to limit auto-owned targets created
+	// before cutoff that have never responded despite minAttempts discovery probes.
+	GetTargetsNeverResponded(ctx context.Context, cutoff time.Time, minAttempts, limit int) ([]types.Target, error)
+
+	// ArchiveTargetBy soft-deletes a target with a reason.
+	ArchiveTargetBy(ctx context.Context, targetID, reason, triggeredBy string) error
 }
 
 // StateWorkerConfig holds configuration for the state worker.
@@ -65,7 +74,7 @@ type StateWorkerConfig struct {
 	BaselineThreshold time.Duration
 
 	// DownThreshold is how long an ACTIVE target with baseline must be unresponsive
-	// before transitioning to DOWN.
+	// before transitioning to DOWN, unless the target overrides it.
 	DownThreshold time.Duration
 
 	// UnresponsiveThreshold is how long an ACTIVE target WITHOUT baseline must be
@@ -79,6 +88,10 @@ type StateWorkerConfig struct {
 	// SmartRecheckEnabled enables the smart re-check feature for subnets
 	// without active coverage.
 	SmartRecheckEnabled bool
+
+	// NeverResponded archives auto-owned targets that never answered
+	// discovery, keeping seeded subnets down to their reachable addresses.
+	NeverResponded types.NeverRespondedPolicy
 }
 
 // DefaultStateWorkerConfig returns sensible defaults.
@@ -90,6 +103,11 @@ func DefaultStateWorkerConfig() StateWorkerConfig {
 		UnresponsiveThreshold: 15 * time.Minute, // No response for 15 min = unresponsive (not alertable)
 		ExcludedThreshold:     24 * time.Hour,   // No response for 24h = excluded
 		SmartRecheckEnabled:   true,
+		NeverResponded: types.NeverRespondedPolicy{
+			Enabled:         true,
+			DiscoveryWindow: config.NeverRespondedWindow,
+			MaxAttempts:     config.NeverRespondedMaxAttempts,
+		},
 	}
 }
 
@@ -99,6 +117,11 @@ type StateWorker struct {
 	config StateWorkerConfig
 	logger *slog.Logger
 	stopCh chan struct{}
+
+	// ready is set once the first cycle has completed
+	ready atomic.Bool
+
+	clocked
 }
 
 // NewStateWorker creates a new state worker.
@@ -121,6 +144,11 @@ func (w *StateWorker) Stop() {
 	close(w.stopCh)
 }
 
+// Ready reports whether the worker has completed its first cycle.
+func (w *StateWorker) Ready() bool {
+	return w.ready.Load()
+}
+
 func (w *StateWorker) run(ctx context.Context) {
 	w.logger.Info("state worker started",
 		"interval", w.config.Interval,
@@ -128,10 +156,14 @@ func (w *StateWorker) run(ctx context.Context) {
 		"down_threshold", w.config.DownThreshold,
 		"unresponsive_threshold", w.config.UnresponsiveThreshold,
 		"excluded_threshold", w.config.ExcludedThreshold,
+		"never_responded_archival", w.config.NeverResponded.Enabled,
+		"never_responded_window", w.config.NeverResponded.DiscoveryWindow,
+		"never_responded_max_attempts", w.config.NeverResponded.MaxAttempts,
 	)
 
 	// Run immediately on start
 	w.runOnce(ctx)
+	w.ready.Store(true)
 
 	ticker := time.NewTicker(w.config.Interval)
 	defer ticker.Stop()
@@ -151,7 +183,7 @@ func (w *StateWorker) run(ctx context.Context) {
 }
 
 func (w *StateWorker) runOnce(ctx context.Context) {
-	start := time.Now()
+	start := w.now()
 
 	// First, establish baselines for targets that have been stable long enough
 	baselineCount := w.establishBaselines(ctx)
@@ -171,13 +203,20 @@ func (w *StateWorker) runOnce(ctx context.Context) {
 		recheckCount = w.queueSmartRecheck(ctx)
 	}
 
+	// Retire auto targets that never answered discovery
+	archivedCount := 0
+	if w.config.NeverResponded.Enabled {
+		archivedCount = w.archiveNeverResponded(ctx)
+	}
+
 	w.logger.Info("state worker cycle complete",
-		"duration", time.Since(start),
+		"duration", w.since(start),
 		"baselines_established", baselineCount,
 		"down_transitions", downCount,
 		"unresponsive_transitions", unresponsiveCount,
 		"excluded_transitions", excludedCount,
 		"smart_recheck_queued", recheckCount,
+		"never_responded_archived", archivedCount,
 	)
 }
 
@@ -185,7 +224,7 @@ func (w *StateWorker) runOnce(ctx context.Context) {
 // and marks them as having an established baseline (alertable on future outages).
 // For customer IPs, also handles representative election.
 func (w *StateWorker) establishBaselines(ctx context.Context) int {
-	targets, err := w.store.GetTargetsForBaselineCheck(ctx, w.config.BaselineThreshold)
+	targets, err := w.store.GetTargetsForBaselineCheck(ctx, w.now().Add(-w.config.BaselineThreshold))
 	if err != nil {
 		w.logger.Error("failed to get targets for baseline check", "error", err)
 		return 0
@@ -272,7 +311,7 @@ func (w *StateWorker) electRepresentativeIfNeeded(ctx context.Context, target *t
 // recently and transitions them to DOWN (alertable outage).
 // For representative targets, also triggers failover to standby.
 func (w *StateWorker) transitionToDown(ctx context.Context) int {
-	targets, err := w.store.GetTargetsForDownTransition(ctx, w.config.DownThreshold)
+	targets, err := w.store.GetTargetsForDownTransition(ctx, w.now(), w.config.DownThreshold)
 	if err != nil {
 		w.logger.Error("failed to get targets for down transition", "error", err)
 		return 0
@@ -280,7 +319,7 @@ func (w *StateWorker) transitionToDown(ctx context.Context) int {
 
 	count := 0
 	for _, t := range targets {
-		reason := "no probe response for " + w.config.DownThreshold.String()
+		reason := "no probe response for " + t.EffectiveDownThreshold(w.config.DownThreshold).String()
 		if err := w.store.TransitionTargetState(ctx, t.ID, types.StateDown, reason, "state_worker"); err != nil {
 			w.logger.Error("failed to transition target to down",
 				"target_id", t.ID,
@@ -337,7 +376,7 @@ func (w *StateWorker) handleRepresentativeFailure(ctx context.Context, target *t
 // transitionToUnresponsive finds ACTIVE targets WITHOUT baseline that haven't
 // responded recently and transitions them to UNRESPONSIVE (not alertable).
 func (w *StateWorker) transitionToUnresponsive(ctx context.Context) int {
-	targets, err := w.store.GetTargetsForUnresponsiveTransition(ctx, w.config.UnresponsiveThreshold)
+	targets, err := w.store.GetTargetsForUnresponsiveTransition(ctx, w.now().Add(-w.config.UnresponsiveThreshold))
 	if err != nil {
 		w.logger.Error("failed to get targets for unresponsive transition", "error", err)
 		return 0
@@ -366,7 +405,7 @@ func (w *StateWorker) transitionToUnresponsive(ctx context.Context) int {
 // transitionToExcluded finds DEGRADED targets that have been unresponsive
 // for too long and transitions them to EXCLUDED (except infra/gateway IPs).
 func (w *StateWorker) transitionToExcluded(ctx context.Context) int {
-	targets, err := w.store.GetTargetsForExcludedTransition(ctx, w.config.ExcludedThreshold)
+	targets, err := w.store.GetTargetsForExcludedTransition(ctx, w.now().Add(-w.config.ExcludedThreshold))
 	if err != nil {
 		w.logger.Error("failed to get targets for excluded transition", "error", err)
 		return 0
@@ -421,3 +460,35 @@ func (w *StateWorker) queueSmartRecheck(ctx context.Context) int {
 	}
 	return count
 }
+
+// archiveNeverResponded archives auto-owned targets that have been silent
+// through their discovery window, so unassigned seeded addresses stop being
+// probed. Manual targets are left alone however long they stay silent.
+func (w *StateWorker) archiveNeverResponded(ctx context.Context) int {
+	policy := w.config.NeverResponded
+	targets, err := w.store.GetTargetsNeverResponded(ctx, w.now().Add(-policy.DiscoveryWindow),
+		policy.MaxAttempts, config.NeverRespondedArchiveBatch)
+	if err != nil {
+		w.logger.Error("failed to get never-responded targets", "error", err)
+		return 0
+	}
+
+	count := 0
+	for _, t := range targets {
+		if err := w.store.ArchiveTargetBy(ctx, t.ID, types.ArchiveReasonNeverResponded, "state_worker"); err != nil {
+			w.logger.Error("failed to archive never-responded target",
+				"target_id", t.ID,
+				"ip", t.IP,
+				"error", err,
+			)
+			continue
+		}
+		w.logger.Debug("never-responded target archived",
+			"target_id", t.ID,
+			"ip", t.IP,
+			"discovery_attempts", t.DiscoveryAttempts,
+		)
+		count++
+	}
+	return count
+}
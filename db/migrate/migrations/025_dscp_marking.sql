@@ -0,0 +1,13 @@
+-- Migration 025: DSCP marking for probes
+-- Lets tiers and individual targets probe with a specific DSCP codepoint so
+-- latency/loss can be compared across QoS traffic classes (e.g. EF vs BE).
+-- A target's dscp overrides its tier's; NULL on both means unmarked probes.
+
+ALTER TABLE tiers ADD COLUMN dscp SMALLINT
+    CHECK (dscp IS NULL OR dscp BETWEEN 0 AND 63);
+
+ALTER TABLE targets ADD COLUMN dscp SMALLINT
+    CHECK (dscp IS NULL OR dscp BETWEEN 0 AND 63);
+
+COMMENT ON COLUMN tiers.dscp IS 'DSCP codepoint for probes in this tier (NULL = unmarked)';
+COMMENT ON COLUMN targets.dscp IS 'DSCP codepoint override for this target (NULL = inherit from tier)';
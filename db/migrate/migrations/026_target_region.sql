@@ -0,0 +1,9 @@
+-- Migration 026: Explicit target region
+-- Manually-created targets usually have no subnet, so target_region and
+-- is_in_market were always NULL/false for them and they never appeared in
+-- in-market trends. targets.region lets them carry a region directly; when
+-- set it takes precedence over the subnet's region at probe insert time.
+
+ALTER TABLE targets ADD COLUMN region TEXT;
+
+COMMENT ON COLUMN targets.region IS 'Explicit region for in-market computation (overrides subnet region)';
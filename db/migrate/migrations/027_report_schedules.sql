@@ -0,0 +1,70 @@
+-- Migration 027: Scheduled report delivery
+-- Report schedules generate target reports on a cron schedule and deliver
+-- them by email or webhook. Every attempt is recorded in report_deliveries
+-- so failed sends are visible and auditable.
+
+-- =============================================================================
+-- REPORT SCHEDULES
+-- =============================================================================
+
+CREATE TABLE report_schedules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+
+    -- Scope: explicit targets, or every target in the subscriber's subnets
+    subscriber_name TEXT,
+    target_ids UUID[] NOT NULL DEFAULT '{}',
+    window_days INT NOT NULL DEFAULT 30 CHECK (window_days > 0),
+
+    -- Standard 5-field cron expression evaluated in UTC (e.g. '0 6 1 * *')
+    cron_expression TEXT NOT NULL,
+
+    -- Delivery
+    delivery_method TEXT NOT NULL CHECK (delivery_method IN ('email', 'webhook')),
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    webhook_url TEXT,
+    format TEXT NOT NULL DEFAULT 'pdf' CHECK (format IN ('pdf', 'json')),
+
+    enabled BOOLEAN NOT NULL DEFAULT true,
+
+    -- Run state
+    next_run_at TIMESTAMPTZ,
+    last_run_at TIMESTAMPTZ,
+    last_status TEXT,
+    last_error TEXT,
+    consecutive_failures INT NOT NULL DEFAULT 0,
+
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CHECK (subscriber_name IS NOT NULL OR cardinality(target_ids) > 0),
+    CHECK (delivery_method <> 'email' OR cardinality(recipients) > 0),
+    CHECK (delivery_method <> 'webhook' OR webhook_url IS NOT NULL)
+);
+
+CREATE INDEX idx_report_schedules_due ON report_schedules(next_run_at) WHERE enabled;
+
+-- =============================================================================
+-- REPORT DELIVERIES
+-- One row per delivery attempt (successful or not)
+-- =============================================================================
+
+CREATE TABLE report_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    schedule_id UUID NOT NULL REFERENCES report_schedules(id) ON DELETE CASCADE,
+    status TEXT NOT NULL CHECK (status IN ('sent', 'failed')),
+    delivery_method TEXT NOT NULL,
+    destination TEXT NOT NULL,
+    target_count INT NOT NULL DEFAULT 0,
+    size_bytes INT NOT NULL DEFAULT 0,
+    attempt INT NOT NULL DEFAULT 1,
+    error TEXT,
+    window_start TIMESTAMPTZ NOT NULL,
+    window_end TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_report_deliveries_schedule ON report_deliveries(schedule_id, created_at DESC);
+
+COMMENT ON TABLE report_schedules IS 'Cron-scheduled report generation and delivery per subscriber';
+COMMENT ON TABLE report_deliveries IS 'Record of every scheduled report delivery attempt';
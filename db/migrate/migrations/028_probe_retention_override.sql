@@ -0,0 +1,90 @@
+-- Migration 028: Per-target probe retention overrides
+--
+-- Some customers need raw probe data kept longer than the global policy
+-- (e.g. 13 months for compliance). TimescaleDB retention policies drop whole
+-- chunks, so rows for individual targets can't be exempted in place. Instead,
+-- targets (or whole tiers) with a longer retention_days have their raw rows
+-- copied by the control plane's retention worker into probe_results_archive
+-- before the probe_results policy drops them. The archive has its own, much
+-- longer, chunk retention as a hard cap and rows past each target's own window
+-- are deleted by the worker.
+--
+-- Storage tradeoff: retained rows exist twice while they are still inside
+-- the probe_results window, and the archive grows linearly with the number of
+-- flagged targets times their retention. Archive chunks are large (30 days)
+-- and compressed after 7 days, so per-row cost is similar to compressed
+-- probe_results, but keep the flag to targets that actually need it.
+
+-- A target's retention_days overrides its tier's; NULL on both means the
+-- global probe_results policy applies.
+ALTER TABLE tiers ADD COLUMN retention_days INTEGER
+    CHECK (retention_days IS NULL OR retention_days BETWEEN 1 AND 1825);
+
+ALTER TABLE targets ADD COLUMN retention_days INTEGER
+    CHECK (retention_days IS NULL OR retention_days BETWEEN 1 AND 1825);
+
+COMMENT ON COLUMN tiers.retention_days IS 'Raw probe retention for targets in this tier (NULL = global policy)';
+COMMENT ON COLUMN targets.retention_days IS 'Raw probe retention override for this target (NULL = inherit from tier)';
+
+-- =============================================================================
+-- ARCHIVE HYPERTABLE
+-- =============================================================================
+
+CREATE TABLE probe_results_archive (
+    time TIMESTAMPTZ NOT NULL,
+    target_id UUID NOT NULL,
+    agent_id UUID NOT NULL,
+    success BOOLEAN NOT NULL,
+    error_message TEXT,
+    latency_ms REAL,
+    packet_loss_pct REAL,
+    payload JSONB,
+    agent_region TEXT,
+    target_region TEXT,
+    is_in_market BOOLEAN,
+
+    PRIMARY KEY (time, target_id, agent_id)
+);
+
+SELECT create_hypertable('probe_results_archive', 'time', chunk_time_interval => INTERVAL '30 days');
+
+ALTER TABLE probe_results_archive SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'target_id, agent_id'
+);
+SELECT add_compression_policy('probe_results_archive', INTERVAL '7 days');
+
+-- Hard cap matching the retention_days CHECK; per-target windows are
+-- enforced by the retention worker
+SELECT add_retention_policy('probe_results_archive', INTERVAL '1825 days');
+
+CREATE INDEX idx_probe_results_archive_target ON probe_results_archive(target_id, time DESC);
+
+-- How far each retained target has been copied into the archive. No FK to
+-- targets: the row must outlive a deleted target so the worker can find and
+-- purge its archived results.
+CREATE TABLE probe_retention_progress (
+    target_id UUID PRIMARY KEY,
+    archived_through TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Raw history for compliance exports: archive rows older than the live
+-- table's oldest chunk plus everything still in probe_results
+CREATE VIEW probe_results_retained AS
+SELECT time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct,
+       payload, agent_region, target_region, is_in_market
+FROM probe_results
+UNION ALL
+SELECT a.time, a.target_id, a.agent_id, a.success, a.error_message, a.latency_ms, a.packet_loss_pct,
+       a.payload, a.agent_region, a.target_region, a.is_in_market
+FROM probe_results_archive a
+WHERE a.time < (SELECT COALESCE(MIN(range_start), NOW()) FROM timescaledb_information.chunks
+                WHERE hypertable_name = 'probe_results');
+
+INSERT INTO retention_config (table_name, retention_interval, description) VALUES
+    ('probe_results_archive', '1825 days', 'Raw probe results for targets with a retention override (per-target window enforced by worker)')
+ON CONFLICT (table_name) DO UPDATE SET
+    retention_interval = EXCLUDED.retention_interval,
+    description = EXCLUDED.description,
+    updated_at = NOW();
@@ -0,0 +1,16 @@
+-- Migration 029: Target-level probe disable
+-- Operators sometimes need to stop probing a target for a while (customer
+-- maintenance, a noisy CPE) without archiving it or losing its monitoring
+-- state. probing_enabled = false removes the target from assignment
+-- generation; monitoring_state, history and alert configuration are kept.
+--
+-- probing_changed_at records when the flag last flipped so the state worker
+-- doesn't treat the silence while disabled as the target going down.
+
+ALTER TABLE targets ADD COLUMN probing_enabled BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE targets ADD COLUMN probing_changed_at TIMESTAMPTZ;
+
+CREATE INDEX idx_targets_probing_disabled ON targets(id) WHERE NOT probing_enabled;
+
+COMMENT ON COLUMN targets.probing_enabled IS 'False stops all probing without archiving or changing monitoring_state';
+COMMENT ON COLUMN targets.probing_changed_at IS 'When probing_enabled last changed';
@@ -0,0 +1,51 @@
+-- Migration 030: Reply TTL and hop-count change detection
+-- ICMP echo replies carry the TTL left when they arrive. Hosts start from a
+-- small set of initial TTLs (64, 128, 255), so the difference gives the hop
+-- count of the return path; a sudden shift usually means a routing change
+-- or path failover even when latency barely moves.
+--
+-- Agents report the TTL of the most recent reply in the probe payload;
+-- reply_ttl stores it alongside latency so it can be aggregated cheaply.
+
+ALTER TABLE probe_results ADD COLUMN reply_ttl SMALLINT;
+ALTER TABLE probe_results_archive ADD COLUMN reply_ttl SMALLINT;
+
+COMMENT ON COLUMN probe_results.reply_ttl IS 'TTL of the most recent ICMP echo reply (NULL = not reported)';
+
+-- Keep the compliance view in step with the tables (new columns go last)
+CREATE OR REPLACE VIEW probe_results_retained AS
+SELECT time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct,
+       payload, agent_region, target_region, is_in_market, reply_ttl
+FROM probe_results
+UNION ALL
+SELECT a.time, a.target_id, a.agent_id, a.success, a.error_message, a.latency_ms, a.packet_loss_pct,
+       a.payload, a.agent_region, a.target_region, a.is_in_market, a.reply_ttl
+FROM probe_results_archive a
+WHERE a.time < (SELECT COALESCE(MIN(range_start), NOW()) FROM timescaledb_information.chunks
+                WHERE hypertable_name = 'probe_results');
+
+-- Last settled hop count per agent/target pair, maintained by the route worker
+CREATE TABLE target_path_state (
+    target_id UUID NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    reply_ttl SMALLINT NOT NULL,
+    hop_count SMALLINT NOT NULL,
+    observed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (target_id, agent_id)
+);
+
+-- Detected hop-count changes (history shown on the target)
+CREATE TABLE hop_count_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    target_id UUID NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    detected_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    previous_ttl SMALLINT NOT NULL,
+    current_ttl SMALLINT NOT NULL,
+    previous_hops SMALLINT NOT NULL,
+    current_hops SMALLINT NOT NULL,
+    alert_id UUID REFERENCES alerts(id) ON DELETE SET NULL
+);
+
+CREATE INDEX idx_hop_count_changes_target ON hop_count_changes(target_id, detected_at DESC);
@@ -0,0 +1,7 @@
+-- Migration 031: Keyset pagination index for subnets
+-- Cursor pagination walks subnets in (network_address, id) order. The only
+-- index on network_address is GiST (for containment lookups), which can't
+-- serve an ordered range scan, so add a btree for the sort key.
+-- targets needs nothing new: the ip_address unique constraint is a btree.
+
+CREATE INDEX IF NOT EXISTS idx_subnets_network_keyset ON subnets(network_address, id);
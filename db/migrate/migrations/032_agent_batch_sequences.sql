@@ -0,0 +1,13 @@
+-- Migration 032: Agent batch sequences
+-- Agents number their result batches with a per-agent sequence that only
+-- increases. A batch retried after a lost response (timeout, proxy reset)
+-- arrives with a sequence the control plane has already accepted, so it is
+-- acknowledged without being inserted or fed to the state machine again.
+--
+-- One row per agent holding the highest sequence accepted so far.
+
+CREATE TABLE agent_batch_sequences (
+    agent_id UUID PRIMARY KEY REFERENCES agents(id) ON DELETE CASCADE,
+    last_sequence BIGINT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
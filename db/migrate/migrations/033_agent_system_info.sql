@@ -0,0 +1,9 @@
+-- Migration 033: Agent system info
+-- Agents report host details at registration (kernel, platform, NIC driver,
+-- virtualization, raw/unprivileged ICMP socket availability) to help explain
+-- platform-specific probe behavior. Stored as JSONB since the set of fields
+-- is descriptive and expected to grow; NULL for agents that don't report it.
+
+ALTER TABLE agents ADD COLUMN system_info JSONB;
+
+COMMENT ON COLUMN agents.system_info IS 'Host details reported by the agent at registration';
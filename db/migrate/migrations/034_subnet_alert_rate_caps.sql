@@ -0,0 +1,17 @@
+-- Migration 034: Subnet alert rate caps
+-- When a subnet produces more new alerts per window than its cap, the alert
+-- worker folds the excess into a single subnet_rollup alert instead of one
+-- alert per target. Rollup alerts have no target: target_id is NULL and
+-- target_ip holds the subnet's network address.
+--
+-- Also adds path_change, which the route worker has been creating since
+-- traceroute path tracking but was never added to the enum.
+
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'path_change';
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'subnet_rollup';
+
+INSERT INTO alert_config (key, value, description) VALUES
+    ('subnet_alert_rate_cap', '20', 'Max new alerts per subnet per rate window before rolling up (0 = unlimited)'),
+    ('global_alert_rate_cap', '200', 'Max new alerts across all subnets per rate window; excess is deferred (0 = unlimited)'),
+    ('alert_rate_window_seconds', '60', 'Sliding window for alert rate caps')
+ON CONFLICT (key) DO NOTHING;
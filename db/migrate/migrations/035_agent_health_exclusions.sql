@@ -0,0 +1,41 @@
+-- Migration 035: Agent health exclusions
+-- A known-bad vantage point (e.g. an agent on a congested link) can drag
+-- down a target's health and in-market stats. An exclusion quarantines an
+-- agent for one target or for every target in a subnet: the agent keeps
+-- probing and its raw results are still stored and shown, but the evaluator,
+-- alerting and status aggregates ignore them.
+
+CREATE TABLE agent_health_exclusions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    target_id UUID REFERENCES targets(id) ON DELETE CASCADE,
+    subnet_id UUID REFERENCES subnets(id) ON DELETE CASCADE,
+    reason TEXT,
+    created_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    -- Exactly one scope: a single target or a whole subnet
+    CONSTRAINT agent_health_exclusions_scope CHECK ((target_id IS NULL) <> (subnet_id IS NULL))
+);
+
+CREATE UNIQUE INDEX idx_agent_health_exclusions_target
+    ON agent_health_exclusions(agent_id, target_id) WHERE target_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_agent_health_exclusions_subnet
+    ON agent_health_exclusions(agent_id, subnet_id) WHERE subnet_id IS NOT NULL;
+
+-- agent_health_excluded reports whether an agent's results for a target are
+-- excluded, either directly or through the target's subnet.
+CREATE OR REPLACE FUNCTION agent_health_excluded(p_agent_id UUID, p_target_id UUID)
+RETURNS BOOLEAN AS $$
+    SELECT EXISTS (
+        SELECT 1
+        FROM agent_health_exclusions e
+        WHERE e.agent_id = p_agent_id
+          AND (e.target_id = p_target_id
+               OR e.subnet_id = (SELECT t.subnet_id FROM targets t WHERE t.id = p_target_id))
+    )
+$$ LANGUAGE sql STABLE;
+
+COMMENT ON TABLE agent_health_exclusions IS 'Agent/target and agent/subnet pairs left out of health and alert computation';
+COMMENT ON FUNCTION agent_health_excluded(UUID, UUID) IS 'True if the agent is excluded from health computation for the target';
@@ -0,0 +1,8 @@
+-- Migration 036: Per-tier probe shedding in agent metrics
+-- An overloaded agent sheds queued probes from its lowest-priority tiers
+-- first. Recording the per-tier counts from each heartbeat shows which
+-- coverage an agent is losing, not just that it is behind.
+
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS probes_shed_by_tier JSONB;  -- {"standard": 1200, "discovery": 400}, cumulative since agent start
+
+COMMENT ON COLUMN agent_metrics.probes_shed_by_tier IS 'Targets shed from the agent probe queue per tier since agent start';
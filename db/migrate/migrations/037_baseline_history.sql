@@ -0,0 +1,75 @@
+-- Migration 037: Baseline history and drift alerts
+-- Z-score alerting compares current latency against the pair's baseline, so
+-- when the baseline itself creeps up over weeks (chronic congestion) nothing
+-- ever fires. Snapshotting each baseline recalculation lets the evaluator
+-- compare today's baseline p95 against the one from several weeks ago and
+-- raise a baseline_drift alert when it has risen too far.
+
+CREATE TABLE baseline_history (
+    agent_id UUID NOT NULL REFERENCES agents(id) ON DELETE CASCADE,
+    target_id UUID NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    snapshot_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    latency_p50 DOUBLE PRECISION,
+    latency_p95 DOUBLE PRECISION,
+    latency_p99 DOUBLE PRECISION,
+    latency_stddev DOUBLE PRECISION,
+    packet_loss_baseline DOUBLE PRECISION,
+    sample_count INTEGER,
+
+    PRIMARY KEY (agent_id, target_id, snapshot_at)
+);
+
+SELECT create_hypertable('baseline_history', 'snapshot_at');
+
+-- Drift looks back weeks, not years
+SELECT add_retention_policy('baseline_history', INTERVAL '180 days');
+
+COMMENT ON TABLE baseline_history IS 'Snapshot of agent_target_baseline taken at each recalculation, for drift detection';
+
+-- calculate_baseline now appends the recalculated row to baseline_history
+CREATE OR REPLACE FUNCTION calculate_baseline(p_agent_id UUID, p_target_id UUID)
+RETURNS void AS $$
+BEGIN
+    INSERT INTO agent_target_baseline (agent_id, target_id, latency_p50, latency_p95, latency_p99, latency_stddev, packet_loss_baseline, sample_count, first_seen, last_updated)
+    SELECT
+        agent_id,
+        target_id,
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms) as latency_p50,
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms) as latency_p95,
+        percentile_cont(0.99) WITHIN GROUP (ORDER BY latency_ms) as latency_p99,
+        stddev(latency_ms) as latency_stddev,
+        avg(packet_loss_pct) as packet_loss_baseline,
+        count(*) as sample_count,
+        min(time) as first_seen,
+        NOW()
+    FROM probe_results
+    WHERE agent_id = p_agent_id
+      AND target_id = p_target_id
+      AND time > NOW() - INTERVAL '7 days'
+      AND success = true
+    GROUP BY agent_id, target_id
+    ON CONFLICT (agent_id, target_id) DO UPDATE SET
+        latency_p50 = EXCLUDED.latency_p50,
+        latency_p95 = EXCLUDED.latency_p95,
+        latency_p99 = EXCLUDED.latency_p99,
+        latency_stddev = EXCLUDED.latency_stddev,
+        packet_loss_baseline = EXCLUDED.packet_loss_baseline,
+        sample_count = EXCLUDED.sample_count,
+        last_updated = NOW();
+
+    INSERT INTO baseline_history (agent_id, target_id, snapshot_at, latency_p50, latency_p95, latency_p99, latency_stddev, packet_loss_baseline, sample_count)
+    SELECT agent_id, target_id, last_updated, latency_p50, latency_p95, latency_p99, latency_stddev, packet_loss_baseline, sample_count
+    FROM agent_target_baseline
+    WHERE agent_id = p_agent_id AND target_id = p_target_id
+    ON CONFLICT DO NOTHING;
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'baseline_drift';
+
+INSERT INTO alert_config (key, value, description) VALUES
+    ('baseline_drift_weeks', '4', 'Compare each baseline p95 against its snapshot from this many weeks ago'),
+    ('baseline_drift_pct', '50', 'Raise baseline_drift when a target''s baseline p95 rose by at least this percent'),
+    ('baseline_drift_min_ms', '5', 'Ignore baseline increases smaller than this many ms, however large in percent')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,9 @@
+-- Migration 038: Adaptive probe intervals in agent metrics
+-- Agents with adaptive probing back stable targets off from the tier
+-- interval. Recording how many targets sit at each effective interval shows
+-- how much probe volume adaptation is saving and whether targets are
+-- oscillating between intervals.
+
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS effective_intervals JSONB;  -- {"standard": {"30s": 120, "2m0s": 4000}}
+
+COMMENT ON COLUMN agent_metrics.effective_intervals IS 'Targets per effective probe interval, per tier, when adaptive probing is on';
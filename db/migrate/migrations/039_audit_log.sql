@@ -0,0 +1,40 @@
+-- Migration 039: Audit log of management API mutations
+-- activity_log records what happened to an entity; compliance also needs
+-- who asked for it. Every mutating management request is recorded here with
+-- the operator resolved from its API token, a hash of the request body and
+-- the outcome. Rows are append-only: a trigger rejects UPDATE and DELETE so
+-- the trail can't be edited after the fact.
+
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    time TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    actor TEXT NOT NULL,                -- operator name from the token, or 'anonymous'
+    actor_role TEXT NOT NULL DEFAULT '',
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    entity_type TEXT NOT NULL DEFAULT '',  -- first path segment after /api/v1, e.g. 'targets'
+    entity_id TEXT NOT NULL DEFAULT '',
+    body_sha256 TEXT NOT NULL DEFAULT '',  -- hex; empty for an empty body
+    status_code INT NOT NULL,
+    result TEXT NOT NULL,               -- success, failure
+    remote_addr TEXT NOT NULL DEFAULT '',
+    duration_ms INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_log_time ON audit_log(time DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_actor ON audit_log(actor, time DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_log_entity ON audit_log(entity_type, entity_id, time DESC);
+
+CREATE OR REPLACE FUNCTION audit_log_immutable()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_log_no_modify ON audit_log;
+CREATE TRIGGER audit_log_no_modify
+    BEFORE UPDATE OR DELETE ON audit_log
+    FOR EACH ROW EXECUTE FUNCTION audit_log_immutable();
+
+COMMENT ON TABLE audit_log IS 'Append-only record of mutating management API requests';
@@ -0,0 +1,46 @@
+-- Migration 040: Aggregate-at-ingest for high-volume tiers
+-- Raw probe_results dominate storage and write load on very large fleets.
+-- Tiers can now opt into aggregate ingest: the control plane's buffer
+-- flusher rolls buffered results up into 1-minute per-(agent, target)
+-- rows in probe_1min and keeps raw rows only briefly ('aggregate') or not
+-- at all ('aggregate_only'). Aggregated tiers lose per-probe granularity:
+-- individual RTTs, error messages, payloads and reply TTLs are gone once
+-- the raw rows are.
+
+ALTER TABLE tiers ADD COLUMN ingest_mode TEXT NOT NULL DEFAULT 'raw'
+    CHECK (ingest_mode IN ('raw', 'aggregate', 'aggregate_only'));
+
+COMMENT ON COLUMN tiers.ingest_mode IS 'raw: keep every result; aggregate: 1-minute rollups plus short-lived raw rows; aggregate_only: rollups only';
+
+CREATE TABLE probe_1min (
+    bucket TIMESTAMPTZ NOT NULL,
+    target_id UUID NOT NULL,
+    agent_id UUID NOT NULL,
+
+    probe_count INTEGER NOT NULL,
+    success_count INTEGER NOT NULL,
+
+    -- Sums rather than averages so late results for a flushed minute can
+    -- be merged in; avg = sum / count, stddev from the sum of squares.
+    latency_count INTEGER NOT NULL DEFAULT 0,
+    latency_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+    latency_sum_sq DOUBLE PRECISION NOT NULL DEFAULT 0,
+    latency_min DOUBLE PRECISION,
+    latency_max DOUBLE PRECISION,
+    loss_count INTEGER NOT NULL DEFAULT 0,
+    loss_sum DOUBLE PRECISION NOT NULL DEFAULT 0,
+
+    agent_region TEXT,
+    target_region TEXT,
+    is_in_market BOOLEAN,
+
+    PRIMARY KEY (bucket, target_id, agent_id)
+);
+
+SELECT create_hypertable('probe_1min', 'bucket');
+
+CREATE INDEX idx_probe_1min_target ON probe_1min (target_id, bucket DESC);
+
+SELECT add_retention_policy('probe_1min', INTERVAL '90 days');
+
+COMMENT ON TABLE probe_1min IS '1-minute per-(agent, target) rollups for tiers with aggregate ingest';
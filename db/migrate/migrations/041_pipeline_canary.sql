@@ -0,0 +1,24 @@
+-- Migration 041: Pipeline canary target
+-- Target alerts can only fire if results reach probe_results and the state
+-- evaluator runs; if either stalls, the dashboard just goes quiet. Every
+-- agent now probes a reserved canary target (its own loopback, so the probe
+-- never leaves the host) and the control plane's canary watchdog raises a
+-- pipeline_health alert when the canary's results or evaluations go stale.
+
+INSERT INTO tiers (name, display_name, probe_interval_ms, probe_timeout_ms, probe_retries, agent_selection) VALUES
+    ('canary', 'Pipeline Canary', 30000, 2000, 0, '{"strategy": "all"}')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO targets (id, ip_address, tier, display_name, notes, tags, monitoring_state)
+VALUES (
+    '00000000-0000-0000-0000-00000000ca11',
+    '127.0.0.1',
+    'canary',
+    'Pipeline canary',
+    'Reserved: probed by every agent to verify results land and are evaluated. Do not delete.',
+    '{"system": "pipeline_canary"}',
+    'active'
+)
+ON CONFLICT DO NOTHING;
+
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'pipeline_health';
@@ -0,0 +1,27 @@
+-- Migration 042: Store probe type alongside results
+-- Results arrive tagged with the executor that produced them (icmp_ping,
+-- tcp_connect, ...) but only the payload was kept, so results could not be
+-- filtered by probe type without scanning JSON. probe_type is now set at
+-- ingest like latency_ms, packet_loss_pct and reply_ttl, which are the
+-- payload fields queries filter on.
+--
+-- No new index: result listings always filter by target_id and time, which
+-- idx_probe_results_target already covers, and a GIN index over payload
+-- would cost every insert far more than these typed columns.
+
+ALTER TABLE probe_results ADD COLUMN probe_type TEXT;
+ALTER TABLE probe_results_archive ADD COLUMN probe_type TEXT;
+
+COMMENT ON COLUMN probe_results.probe_type IS 'Executor that produced the result (NULL = rows ingested before 042)';
+
+-- Keep the compliance view in step with the tables (new columns go last)
+CREATE OR REPLACE VIEW probe_results_retained AS
+SELECT time, target_id, agent_id, success, error_message, latency_ms, packet_loss_pct,
+       payload, agent_region, target_region, is_in_market, reply_ttl, probe_type
+FROM probe_results
+UNION ALL
+SELECT a.time, a.target_id, a.agent_id, a.success, a.error_message, a.latency_ms, a.packet_loss_pct,
+       a.payload, a.agent_region, a.target_region, a.is_in_market, a.reply_ttl, a.probe_type
+FROM probe_results_archive a
+WHERE a.time < (SELECT COALESCE(MIN(range_start), NOW()) FROM timescaledb_information.chunks
+                WHERE hypertable_name = 'probe_results');
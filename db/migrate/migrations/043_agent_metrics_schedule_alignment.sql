@@ -0,0 +1,9 @@
+-- Migration 043: Schedule alignment in agent metrics
+-- Agents can run a tier's probe loop aligned to wall-clock boundaries or
+-- drifting from agent start. Recording each tier's mode shows which agents
+-- probe in step (and so burst together) when comparing results across
+-- agents or chasing periodic load on shared targets.
+
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS schedule_alignment JSONB;  -- {"standard": "drifting", "infrastructure": "aligned"}
+
+COMMENT ON COLUMN agent_metrics.schedule_alignment IS 'Probe loop alignment (aligned or drifting) per tier';
@@ -0,0 +1,22 @@
+-- Migration 044: Target annotations
+-- Operators mark known events (maintenance, config changes, upstream
+-- notices) on a target so latency spikes on its history charts can be read
+-- against them. An annotation is a point in time (ends_at NULL) or a range.
+-- Incidents are not copied here; the history API derives their annotations
+-- from the incidents table so they always match the incident's timeline.
+
+CREATE TABLE IF NOT EXISTS annotations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    target_id UUID NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ,                -- NULL for a point-in-time event
+    text TEXT NOT NULL,
+    created_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT annotations_range CHECK (ends_at IS NULL OR ends_at >= starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_annotations_target_time ON annotations(target_id, starts_at DESC);
+
+COMMENT ON TABLE annotations IS 'Operator notes on a target timeline, overlaid on history charts';
@@ -0,0 +1,24 @@
+-- Migration 045: Assignment affinity rules
+-- Tier agent selection only places targets by agent region, provider and
+-- fixed tags. Affinity rules add tag-based placement across tiers: a rule
+-- matches targets by tag filters and either limits them to agents matching
+-- its agent filters (affinity) or keeps those agents off them
+-- (anti_affinity). Rules apply in priority order, lowest first; one that
+-- would leave a target without an eligible agent is skipped for it.
+
+CREATE TABLE IF NOT EXISTS affinity_rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    priority INTEGER NOT NULL DEFAULT 100,
+    mode VARCHAR(20) NOT NULL CHECK (mode IN ('affinity', 'anti_affinity')),
+    target_filters JSONB NOT NULL,  -- [{"key": "pci", "operator": "equals", "value": "true"}]
+    agent_filters JSONB NOT NULL,   -- [{"key": "compliant", "operator": "equals", "value": "true"}]
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_affinity_rules_enabled ON affinity_rules(enabled, priority);
+
+COMMENT ON TABLE affinity_rules IS 'Tag-based constraints on which agents may probe which targets';
@@ -0,0 +1,17 @@
+-- Migration 046: Result shipment metrics in agent metrics
+-- Agents report how much result data they ship so ingestion capacity can be
+-- sized from real bandwidth, and so an agent whose batches are unusually
+-- large (e.g. a payload bug) stands out. All counters are totals since agent
+-- start; rates come from the difference between heartbeats.
+--
+-- results_failed and results_bytes_shipped (compressed) date from migration
+-- 002 but were never populated; the agent now reports them.
+
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS results_bytes_uncompressed BIGINT;
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS batches_shipped BIGINT;
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS ship_failures BIGINT;
+
+COMMENT ON COLUMN agent_metrics.results_bytes_shipped IS 'Gzip-compressed bytes of accepted result batches since agent start';
+COMMENT ON COLUMN agent_metrics.results_bytes_uncompressed IS 'Bytes of accepted result batches before compression since agent start';
+COMMENT ON COLUMN agent_metrics.batches_shipped IS 'Result batches accepted by the control plane since agent start';
+COMMENT ON COLUMN agent_metrics.ship_failures IS 'Failed result batch send attempts since agent start';
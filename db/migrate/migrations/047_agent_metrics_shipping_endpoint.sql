@@ -0,0 +1,11 @@
+-- Migration 047: Result shipping endpoint in agent metrics
+-- Agents can be configured with failover control plane URLs for result
+-- shipping. They report which one they are currently shipping to, so an
+-- agent still failed over after the primary recovered (or one that never
+-- reached the primary) is visible, and how often they have failed over.
+
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS shipping_endpoint TEXT;
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS endpoint_failovers BIGINT;
+
+COMMENT ON COLUMN agent_metrics.shipping_endpoint IS 'Results URL the agent was shipping to at heartbeat time';
+COMMENT ON COLUMN agent_metrics.endpoint_failovers IS 'Switches to a failover results endpoint since agent start';
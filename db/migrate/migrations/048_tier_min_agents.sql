@@ -0,0 +1,11 @@
+-- Migration 048: Tier minimum agents
+-- Each tier implies a minimum number of agents a target needs (all agents
+-- for pilot_infra, 3 for vlan, 2 otherwise). min_agents lets a tier set its
+-- own. The coverage watchdog raises a coverage alert when fewer agents than
+-- that are reporting on a target for a sustained period, which separates
+-- losing agents from the target itself going down. NULL keeps the default.
+
+ALTER TABLE tiers ADD COLUMN IF NOT EXISTS min_agents INTEGER
+    CHECK (min_agents IS NULL OR min_agents > 0);
+
+COMMENT ON COLUMN tiers.min_agents IS 'Reporting agents a target in this tier needs before a coverage alert; NULL uses the tier default';
@@ -0,0 +1,47 @@
+-- Migration 049: Event outbox
+-- Integrations (ticketing, data lake) consume domain events: alert created
+-- or resolved, incident created or resolved, target state changed. Events are
+-- inserted into outbox_events in the same transaction as the change, so an
+-- event exists exactly when its change committed.
+--
+-- Inserts get an id from a sequence, but transactions commit out of id
+-- order, so a reader paging by id could pass a row that commits later. The
+-- dispatcher therefore assigns seq, the stream position consumers page by,
+-- only to committed rows and one batch at a time; a lower seq can never
+-- appear after a higher one has been read.
+--
+-- event_consumers holds push consumers and their offsets: the last seq each
+-- has accepted. Delivery is at least once; consumers dedupe by seq.
+
+CREATE SEQUENCE IF NOT EXISTS outbox_event_seq;
+
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    seq BIGINT UNIQUE,                       -- stream position, NULL until sequenced
+    event_type VARCHAR(50) NOT NULL,         -- alert.created, incident.resolved, ...
+    entity_id TEXT NOT NULL,
+    payload JSONB NOT NULL DEFAULT '{}',
+    occurred_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_events_unsequenced ON outbox_events(id) WHERE seq IS NULL;
+CREATE INDEX IF NOT EXISTS idx_outbox_events_occurred ON outbox_events(occurred_at);
+
+CREATE TABLE IF NOT EXISTS event_consumers (
+    name VARCHAR(100) PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('webhook')),
+    url TEXT NOT NULL,
+    event_types TEXT[] NOT NULL DEFAULT '{}',  -- empty = every type
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    last_seq BIGINT NOT NULL DEFAULT 0,
+    last_delivered_at TIMESTAMPTZ,
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE outbox_events IS 'Domain events written with the change that caused them, for integrations';
+COMMENT ON COLUMN outbox_events.seq IS 'Stream position, assigned after commit; consumers page by it';
+COMMENT ON TABLE event_consumers IS 'Push consumers of outbox_events and the last seq each accepted';
@@ -0,0 +1,10 @@
+-- Migration 050: Kafka event consumers
+-- The control plane can publish events to Kafka (through a Kafka REST
+-- Proxy) as well as to webhooks. A kafka consumer keeps its topic in url;
+-- offsets, backoff and at-least-once delivery work as for webhooks.
+
+ALTER TABLE event_consumers DROP CONSTRAINT IF EXISTS event_consumers_kind_check;
+ALTER TABLE event_consumers ADD CONSTRAINT event_consumers_kind_check
+    CHECK (kind IN ('webhook', 'kafka'));
+
+COMMENT ON COLUMN event_consumers.url IS 'Webhook URL, or topic for kafka consumers';
@@ -0,0 +1,22 @@
+-- Migration 051: Remote agent config
+-- Agent settings pushed from the control plane (log level, poll intervals,
+-- schedule alignment, feature flags), so fleet-wide changes don't need a
+-- redeploy. Each row is one scope: 'global' applies to every agent, an
+-- agent ID overrides the global config for that agent. Agents merge the
+-- effective config over their local one.
+--
+-- Every write takes a fresh version from one sequence, so an agent's
+-- effective version (the greater of its scopes' versions) changes whenever
+-- either scope does. Agents echo the version they applied in heartbeats,
+-- recorded in agent_metrics.config_version.
+
+CREATE SEQUENCE IF NOT EXISTS agent_config_version_seq;
+
+CREATE TABLE IF NOT EXISTS agent_remote_config (
+    scope TEXT PRIMARY KEY,                  -- 'global' or an agent ID
+    config JSONB NOT NULL DEFAULT '{}',      -- types.AgentRemoteConfig
+    version BIGINT NOT NULL DEFAULT nextval('agent_config_version_seq'),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS config_version BIGINT;
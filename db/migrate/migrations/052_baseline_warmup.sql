@@ -0,0 +1,78 @@
+-- Migration 052: Baseline warmup exclusion
+-- The first probes after an agent starts or a target is created are often
+-- slow for reasons that have nothing to do with the path (ARP resolution,
+-- cold route and neighbor caches), and they skew the baseline they land in.
+-- Baselines now skip each pair's warmup: results from before
+-- baseline_warmup_end, which is the later of the agent's last start and the
+-- target's creation, plus the longer of baseline_warmup_seconds and
+-- baseline_warmup_probes probe intervals of the target's tier.
+
+-- Set on every registration, which agents do at startup
+ALTER TABLE agents ADD COLUMN started_at TIMESTAMPTZ;
+
+COMMENT ON COLUMN agents.started_at IS 'When the agent last registered (started)';
+
+INSERT INTO alert_config (key, value, description) VALUES
+    ('baseline_warmup_probes', '5', 'Leave this many probe intervals after an agent start or target creation out of baselines'),
+    ('baseline_warmup_seconds', '60', 'Leave at least this many seconds after an agent start or target creation out of baselines')
+ON CONFLICT (key) DO NOTHING;
+
+CREATE OR REPLACE FUNCTION baseline_warmup_end(p_agent_started TIMESTAMPTZ, p_target_created TIMESTAMPTZ, p_interval_ms INTEGER)
+RETURNS TIMESTAMPTZ AS $$
+    SELECT GREATEST(p_agent_started, p_target_created) + GREATEST(
+        make_interval(secs => COALESCE((SELECT (value #>> '{}')::int FROM alert_config WHERE key = 'baseline_warmup_seconds'), 0)),
+        COALESCE((SELECT (value #>> '{}')::int FROM alert_config WHERE key = 'baseline_warmup_probes'), 0)
+            * COALESCE(p_interval_ms, 0) * INTERVAL '1 millisecond'
+    )
+$$ LANGUAGE sql STABLE;
+
+COMMENT ON FUNCTION baseline_warmup_end(TIMESTAMPTZ, TIMESTAMPTZ, INTEGER) IS 'End of an agent-target pair''s warmup; earlier results are left out of baselines';
+
+-- calculate_baseline now skips the pair's warmup
+CREATE OR REPLACE FUNCTION calculate_baseline(p_agent_id UUID, p_target_id UUID)
+RETURNS void AS $$
+DECLARE
+    warmup_end TIMESTAMPTZ;
+BEGIN
+    SELECT baseline_warmup_end(a.started_at, t.created_at, tr.probe_interval_ms)
+    INTO warmup_end
+    FROM agents a
+    CROSS JOIN targets t
+    LEFT JOIN tiers tr ON tr.name = t.tier
+    WHERE a.id = p_agent_id AND t.id = p_target_id;
+
+    INSERT INTO agent_target_baseline (agent_id, target_id, latency_p50, latency_p95, latency_p99, latency_stddev, packet_loss_baseline, sample_count, first_seen, last_updated)
+    SELECT
+        agent_id,
+        target_id,
+        percentile_cont(0.5) WITHIN GROUP (ORDER BY latency_ms) as latency_p50,
+        percentile_cont(0.95) WITHIN GROUP (ORDER BY latency_ms) as latency_p95,
+        percentile_cont(0.99) WITHIN GROUP (ORDER BY latency_ms) as latency_p99,
+        stddev(latency_ms) as latency_stddev,
+        avg(packet_loss_pct) as packet_loss_baseline,
+        count(*) as sample_count,
+        min(time) as first_seen,
+        NOW()
+    FROM probe_results
+    WHERE agent_id = p_agent_id
+      AND target_id = p_target_id
+      AND time > NOW() - INTERVAL '7 days'
+      AND time >= COALESCE(warmup_end, '-infinity')
+      AND success = true
+    GROUP BY agent_id, target_id
+    ON CONFLICT (agent_id, target_id) DO UPDATE SET
+        latency_p50 = EXCLUDED.latency_p50,
+        latency_p95 = EXCLUDED.latency_p95,
+        latency_p99 = EXCLUDED.latency_p99,
+        latency_stddev = EXCLUDED.latency_stddev,
+        packet_loss_baseline = EXCLUDED.packet_loss_baseline,
+        sample_count = EXCLUDED.sample_count,
+        last_updated = NOW();
+
+    INSERT INTO baseline_history (agent_id, target_id, snapshot_at, latency_p50, latency_p95, latency_p99, latency_stddev, packet_loss_baseline, sample_count)
+    SELECT agent_id, target_id, last_updated, latency_p50, latency_p95, latency_p99, latency_stddev, packet_loss_baseline, sample_count
+    FROM agent_target_baseline
+    WHERE agent_id = p_agent_id AND target_id = p_target_id
+    ON CONFLICT DO NOTHING;
+END;
+$$ LANGUAGE plpgsql;
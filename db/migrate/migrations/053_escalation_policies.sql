@@ -0,0 +1,32 @@
+-- Migration 053: Alert escalation policies
+-- Alerts escalate on their metrics, but one nobody looks at can sit at the
+-- same severity indefinitely. An escalation policy is an on-call ladder:
+-- ordered steps, each firing once an alert has gone unacknowledged for its
+-- duration, raising severity and notifying again, optionally to different
+-- recipients. Policies cover target tiers and alert regions (empty = any).
+-- Alerts record the policy escalating them and how many steps have fired.
+
+CREATE TABLE IF NOT EXISTS escalation_policies (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    description TEXT,
+    enabled BOOLEAN NOT NULL DEFAULT true,
+    tiers TEXT[] NOT NULL DEFAULT '{}',
+    regions TEXT[] NOT NULL DEFAULT '{}',
+    steps JSONB NOT NULL,  -- [{"after_minutes": 15, "severity": "critical", "recipients": ["oncall@example.com"]}]
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE escalation_policies IS 'Time-based escalation ladders for unacknowledged alerts';
+
+ALTER TABLE alerts
+    ADD COLUMN IF NOT EXISTS escalation_policy_id UUID REFERENCES escalation_policies(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS escalation_step INTEGER NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN alerts.escalation_policy_id IS 'Escalation policy that fired this alert''s first step';
+COMMENT ON COLUMN alerts.escalation_step IS 'Number of escalation policy steps fired';
+
+-- The alert worker scans unacknowledged active alerts every cycle
+CREATE INDEX IF NOT EXISTS idx_alerts_unacknowledged ON alerts(detected_at)
+    WHERE status = 'active' AND acknowledged_at IS NULL;
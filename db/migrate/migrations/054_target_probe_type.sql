@@ -0,0 +1,12 @@
+-- Migration 054: Per-target probe type
+-- Agents can load executor plugins for custom check types (e.g. tls_expiry).
+-- A target names the executor that probes it and that executor's params;
+-- the control plane passes both through in assignments without
+-- interpreting them.
+
+ALTER TABLE targets
+    ADD COLUMN probe_type TEXT NOT NULL DEFAULT 'icmp_ping',
+    ADD COLUMN probe_params JSONB;
+
+COMMENT ON COLUMN targets.probe_type IS 'Agent executor that probes the target (icmp_ping unless a plugin check is configured)';
+COMMENT ON COLUMN targets.probe_params IS 'Executor-specific probe parameters, passed to the agent as-is';
@@ -0,0 +1,36 @@
+-- Migration 055: TLS certificate tracking
+-- tls_cert targets report their leaf certificate on every probe. The latest
+-- one seen per target is kept here, so certificates expiring across the
+-- fleet can be listed without reading probe payloads, and the control plane
+-- raises a cert_expiry alert once a certificate is within
+-- cert_expiry_warning_days of expiring (critical within
+-- cert_expiry_critical_days, or once expired).
+
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'cert_expiry';
+
+CREATE TABLE target_certificates (
+    target_id UUID PRIMARY KEY REFERENCES targets(id) ON DELETE CASCADE,
+    agent_id UUID NOT NULL,
+    port INTEGER NOT NULL,
+    server_name TEXT,
+    subject TEXT,
+    issuer TEXT,
+    dns_names TEXT[] NOT NULL DEFAULT '{}',
+    serial_number TEXT,
+    not_before TIMESTAMPTZ NOT NULL,
+    not_after TIMESTAMPTZ NOT NULL,
+    chain_valid BOOLEAN NOT NULL,
+    verify_error TEXT,
+    observed_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX idx_target_certificates_not_after ON target_certificates(not_after);
+
+COMMENT ON TABLE target_certificates IS 'Latest TLS certificate seen on each tls_cert target';
+COMMENT ON COLUMN target_certificates.agent_id IS 'Agent that reported the certificate';
+COMMENT ON COLUMN target_certificates.chain_valid IS 'Whether the chain verified against the reporting agent''s roots and server_name';
+
+INSERT INTO alert_config (key, value, description) VALUES
+    ('cert_expiry_warning_days', '30', 'Raise a warning cert_expiry alert when a certificate expires within this many days'),
+    ('cert_expiry_critical_days', '7', 'Raise cert_expiry alerts to critical when a certificate expires within this many days')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,35 @@
+-- Migration 056: Probe accounting rollup
+-- Capacity planning and billing need probe counts per target and per agent
+-- over long periods. probe_accounting rolls probe_hourly's counts up by day;
+-- it carries nothing but counts, so it is cheap to keep for longer than
+-- probe_daily's two years, and real-time aggregation covers the current day.
+-- Tiers with aggregate_only ingest never write probe_results, so their
+-- probes are not counted here.
+
+CREATE MATERIALIZED VIEW probe_accounting
+WITH (timescaledb.continuous, timescaledb.materialized_only = false) AS
+SELECT
+    time_bucket('1 day', bucket) AS bucket,
+    agent_id,
+    target_id,
+    sum(probe_count)::bigint AS probe_count,
+    sum(success_count)::bigint AS success_count
+FROM probe_hourly
+GROUP BY time_bucket('1 day', bucket), agent_id, target_id
+WITH NO DATA;
+
+SELECT add_continuous_aggregate_policy('probe_accounting',
+    start_offset => INTERVAL '3 days',
+    end_offset => INTERVAL '1 hour',
+    schedule_interval => INTERVAL '1 hour');
+
+SELECT add_retention_policy('probe_accounting', INTERVAL '5 years', if_not_exists => true);
+
+INSERT INTO retention_config (table_name, retention_interval, description) VALUES
+    ('probe_accounting', '5 years', 'Daily probe counts for accounting')
+ON CONFLICT (table_name) DO NOTHING;
+
+CREATE INDEX idx_probe_accounting_target ON probe_accounting(target_id, bucket DESC);
+CREATE INDEX idx_probe_accounting_agent ON probe_accounting(agent_id, bucket DESC);
+
+COMMENT ON MATERIALIZED VIEW probe_accounting IS 'Daily probe counts per agent-target pair for capacity planning and billing';
@@ -0,0 +1,88 @@
+-- Migration 057: Assignment change log
+-- The assignment version says that something changed, not what. Every
+-- statement that adds or removes assignments now records each (agent,
+-- target) pair it touched under the version it produced, so an agent's
+-- assignments can be diffed between two versions ("why did this agent start
+-- probing X?"). Kept as long as assignment_history.
+--
+-- The version bump moves into the logging triggers so each change is stored
+-- under exactly the version its statement produced. Transition tables allow
+-- one event per trigger, hence three triggers.
+
+CREATE TABLE assignment_changes (
+    version BIGINT NOT NULL,
+    agent_id UUID NOT NULL,
+    target_id UUID NOT NULL,
+    action VARCHAR(10) NOT NULL CHECK (action IN ('add', 'remove')),
+    tier VARCHAR(50),
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+SELECT create_hypertable('assignment_changes', 'changed_at');
+
+CREATE INDEX idx_assignment_changes_agent ON assignment_changes(agent_id, version);
+
+SELECT add_retention_policy('assignment_changes', INTERVAL '30 days');
+
+COMMENT ON TABLE assignment_changes IS 'Assignments added and removed per agent, by the assignment version that made the change';
+
+DROP TRIGGER IF EXISTS target_assignments_changed ON target_assignments;
+
+CREATE OR REPLACE FUNCTION assignment_inserted_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    v BIGINT := increment_assignment_version();
+BEGIN
+    INSERT INTO assignment_changes (version, agent_id, target_id, action, tier)
+    SELECT v, agent_id, target_id, 'add', tier FROM new_rows;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION assignment_deleted_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    v BIGINT := increment_assignment_version();
+BEGIN
+    INSERT INTO assignment_changes (version, agent_id, target_id, action, tier)
+    SELECT v, agent_id, target_id, 'remove', tier FROM old_rows;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+-- Updates normally only change the tier; a row moved to another agent or
+-- target is logged as a removal and an addition
+CREATE OR REPLACE FUNCTION assignment_updated_trigger()
+RETURNS TRIGGER AS $$
+DECLARE
+    v BIGINT := increment_assignment_version();
+BEGIN
+    INSERT INTO assignment_changes (version, agent_id, target_id, action, tier)
+    SELECT v, o.agent_id, o.target_id, 'remove', o.tier
+    FROM old_rows o JOIN new_rows n ON n.id = o.id
+    WHERE (n.agent_id, n.target_id) IS DISTINCT FROM (o.agent_id, o.target_id)
+    UNION ALL
+    SELECT v, n.agent_id, n.target_id, 'add', n.tier
+    FROM old_rows o JOIN new_rows n ON n.id = o.id
+    WHERE (n.agent_id, n.target_id) IS DISTINCT FROM (o.agent_id, o.target_id);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER target_assignments_inserted
+AFTER INSERT ON target_assignments
+REFERENCING NEW TABLE AS new_rows
+FOR EACH STATEMENT
+EXECUTE FUNCTION assignment_inserted_trigger();
+
+CREATE TRIGGER target_assignments_deleted
+AFTER DELETE ON target_assignments
+REFERENCING OLD TABLE AS old_rows
+FOR EACH STATEMENT
+EXECUTE FUNCTION assignment_deleted_trigger();
+
+CREATE TRIGGER target_assignments_updated
+AFTER UPDATE ON target_assignments
+REFERENCING OLD TABLE AS old_rows NEW TABLE AS new_rows
+FOR EACH STATEMENT
+EXECUTE FUNCTION assignment_updated_trigger();
@@ -0,0 +1,19 @@
+-- Migration 058: Target ASN and country
+-- When an IP-to-ASN dataset is configured, the control plane looks up each
+-- target's origin ASN and country and keeps them on the target, so metrics
+-- can be grouped and filtered by network ("all targets on AS X are
+-- degraded") without a lookup per probe. asn_checked_at is set even when
+-- the dataset has no match, so unannounced addresses aren't retried until
+-- the refresh interval passes.
+
+ALTER TABLE targets
+    ADD COLUMN asn INTEGER,
+    ADD COLUMN as_name TEXT,
+    ADD COLUMN country TEXT,
+    ADD COLUMN asn_checked_at TIMESTAMPTZ;
+
+CREATE INDEX idx_targets_asn ON targets(asn) WHERE asn IS NOT NULL;
+
+COMMENT ON COLUMN targets.asn IS 'Origin AS number from the configured IP-to-ASN dataset';
+COMMENT ON COLUMN targets.country IS 'ISO 3166 alpha-2 country from the IP-to-ASN dataset';
+COMMENT ON COLUMN targets.asn_checked_at IS 'When the target IP was last looked up, matched or not';
@@ -0,0 +1,12 @@
+-- Migration 059: Per-target down threshold
+-- An ACTIVE target with a baseline goes DOWN after the state worker's down
+-- threshold without a response (15 minutes by default). Endpoints that
+-- sleep, like residential CPEs, need a longer grace than infrastructure;
+-- down_threshold_seconds overrides the global threshold for one target.
+-- NULL inherits it.
+
+ALTER TABLE targets
+    ADD COLUMN down_threshold_seconds INTEGER
+        CHECK (down_threshold_seconds > 0);
+
+COMMENT ON COLUMN targets.down_threshold_seconds IS 'Silence before ACTIVE goes DOWN; NULL uses the global threshold';
@@ -0,0 +1,54 @@
+-- Migration 060: Target endpoints
+-- A target reachable over both IPv4 and IPv6 (or on more than one port) can
+-- list extra probe endpoints. Agents probe each endpoint alongside the
+-- target's primary address and report the results separately, so a broken
+-- v6 path isn't masked by a healthy v4 one. Endpoint results are kept out of
+-- probe_results: they would collide on its (time, target_id, agent_id) key
+-- and mix per-family latencies into the target's baselines.
+
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'endpoint_family';
+
+CREATE TABLE target_endpoints (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    target_id UUID NOT NULL REFERENCES targets(id) ON DELETE CASCADE,
+    ip_address INET NOT NULL,
+    port INTEGER CHECK (port BETWEEN 1 AND 65535),
+    label TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_target_endpoints_unique
+    ON target_endpoints(target_id, ip_address, COALESCE(port, 0));
+
+COMMENT ON TABLE target_endpoints IS 'Additional probe endpoints (address family / port) for a target';
+COMMENT ON COLUMN target_endpoints.port IS 'Overrides the probe port for port-based probe types; NULL uses the target''s params';
+
+-- Endpoints are part of what agents are assigned, so changing them must bump
+-- the assignment version just like changing targets does.
+CREATE TRIGGER target_endpoints_changed
+AFTER INSERT OR UPDATE OR DELETE ON target_endpoints
+FOR EACH STATEMENT
+EXECUTE FUNCTION targets_changed_trigger();
+
+CREATE TABLE target_endpoint_results (
+    time TIMESTAMPTZ NOT NULL,
+    endpoint_id UUID NOT NULL,
+    target_id UUID NOT NULL,
+    agent_id UUID NOT NULL,
+    success BOOLEAN NOT NULL,
+    error_message TEXT,
+    latency_ms REAL,
+    packet_loss_pct REAL,
+    PRIMARY KEY (time, endpoint_id, agent_id)
+);
+
+SELECT create_hypertable('target_endpoint_results', 'time');
+
+ALTER TABLE target_endpoint_results SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'endpoint_id, agent_id'
+);
+SELECT add_compression_policy('target_endpoint_results', INTERVAL '1 day');
+SELECT add_retention_policy('target_endpoint_results', INTERVAL '90 days');
+
+CREATE INDEX idx_target_endpoint_results_target ON target_endpoint_results(target_id, time DESC);
@@ -0,0 +1,17 @@
+-- Migration 061: Regional latency asymmetry alerts
+-- A target that one region reaches quickly while another sees it slowly
+-- usually has a regional routing problem, even when every agent stays under
+-- its own thresholds. The latency asymmetry watchdog compares the median
+-- current latency of each agent region on a target and raises a
+-- latency_asymmetry alert once the slowest region is
+-- latency_asymmetry_factor times the fastest (and at least
+-- latency_asymmetry_min_delta_ms slower) for
+-- latency_asymmetry_sustain_minutes.
+
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'latency_asymmetry';
+
+INSERT INTO alert_config (key, value, description) VALUES
+    ('latency_asymmetry_factor', '3', 'Alert when the slowest agent region''s median latency to a target is this many times the fastest region''s'),
+    ('latency_asymmetry_min_delta_ms', '30', 'Minimum latency gap in ms between the slowest and fastest regions before asymmetry alerts'),
+    ('latency_asymmetry_sustain_minutes', '10', 'How long a target must stay asymmetric before a latency_asymmetry alert is raised')
+ON CONFLICT (key) DO NOTHING;
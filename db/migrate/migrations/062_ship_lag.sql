@@ -0,0 +1,41 @@
+-- Migration 062: Result ship lag
+-- Results wait in an agent's ship queue while shipping is backed up, so a
+-- result that has only just arrived can describe the network minutes ago.
+-- Agents now report the age of their oldest unshipped result with each
+-- heartbeat, and ingestion records each batch's ship lag (receive time
+-- minus probe time). The ship lag watchdog raises a ship_lag alert for an
+-- agent whose p95 lag or oldest queued result reaches
+-- ship_lag_alert_seconds.
+
+ALTER TYPE alert_type ADD VALUE IF NOT EXISTS 'ship_lag';
+
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS oldest_queued_age_ms BIGINT;
+
+COMMENT ON COLUMN agent_metrics.oldest_queued_age_ms IS 'Age of the oldest result the agent had not yet shipped; 0 when its queue was empty';
+
+CREATE TABLE agent_ship_lag (
+    time TIMESTAMPTZ NOT NULL,
+    agent_id UUID NOT NULL,
+    sequence BIGINT NOT NULL,
+    results INTEGER NOT NULL,
+    lag_p50_ms DOUBLE PRECISION NOT NULL,
+    lag_p95_ms DOUBLE PRECISION NOT NULL,
+    lag_max_ms DOUBLE PRECISION NOT NULL
+);
+
+COMMENT ON TABLE agent_ship_lag IS 'Per-batch result ship lag: server receive time minus probe time';
+
+SELECT create_hypertable('agent_ship_lag', 'time');
+
+ALTER TABLE agent_ship_lag SET (
+    timescaledb.compress,
+    timescaledb.compress_segmentby = 'agent_id'
+);
+SELECT add_compression_policy('agent_ship_lag', INTERVAL '1 day');
+SELECT add_retention_policy('agent_ship_lag', INTERVAL '30 days');
+
+CREATE INDEX idx_agent_ship_lag_agent ON agent_ship_lag(agent_id, time DESC);
+
+INSERT INTO alert_config (key, value, description) VALUES
+    ('ship_lag_alert_seconds', '120', 'Alert when an agent''s p95 result ship lag, or its oldest unshipped result, is at least this many seconds old')
+ON CONFLICT (key) DO NOTHING;
@@ -0,0 +1,20 @@
+-- Migration 063: Down target probe backoff
+-- A target that stays down keeps costing a probe every tier interval from
+-- every assigned agent. down_backoff_max_ms lets a tier back those probes
+-- off: agents double the interval of a down target after each failed probe,
+-- up to this cap, and return to the tier interval on the first response.
+-- NULL keeps down targets at the tier interval.
+
+ALTER TABLE tiers ADD COLUMN IF NOT EXISTS down_backoff_max_ms INTEGER
+    CHECK (down_backoff_max_ms IS NULL OR down_backoff_max_ms > 0);
+
+COMMENT ON COLUMN tiers.down_backoff_max_ms IS 'Longest interval agents back off to probing a down target in this tier; NULL disables backoff';
+
+-- Customer tiers back off to 10 minutes; infrastructure stays at full cadence
+UPDATE tiers SET down_backoff_max_ms = 600000
+WHERE name IN ('standard', 'vip') AND down_backoff_max_ms IS NULL;
+
+-- Probes each agent has backed off, by tier, from its heartbeat
+ALTER TABLE agent_metrics ADD COLUMN IF NOT EXISTS probes_backed_off_by_tier JSONB;  -- {"standard": 12}, at heartbeat time
+
+COMMENT ON COLUMN agent_metrics.probes_backed_off_by_tier IS 'Targets the agent is probing at a backed-off interval per tier';
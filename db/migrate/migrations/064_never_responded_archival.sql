@@ -0,0 +1,13 @@
+-- Migration 064: Never-responded target archival
+-- Subnet seeding creates a target for every usable address, and the
+-- unassigned ones never answer. The state worker archives auto-owned
+-- targets that are still silent after the discovery window, with
+-- archive_reason 'never_responded'. This index covers the state worker's
+-- scan for them, which runs every cycle over what can be most of the
+-- targets table.
+
+CREATE INDEX IF NOT EXISTS idx_targets_never_responded ON targets(created_at)
+    WHERE ownership = 'auto'
+      AND archived_at IS NULL
+      AND first_response_at IS NULL
+      AND last_response_at IS NULL;
@@ -0,0 +1,16 @@
+-- Migration 065: Alert webhook deliveries
+-- The alert worker POSTs alert events to the URLs in the webhook_urls
+-- alert_config key. Delivery outcomes are counted per URL so failures show
+-- up in the fleet overview. URLs often embed credentials, so rows are keyed
+-- by a hash of the URL and keep only its host.
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    url_hash TEXT PRIMARY KEY,                -- hex SHA-256 of the URL
+    host TEXT NOT NULL,
+    delivered BIGINT NOT NULL DEFAULT 0,
+    failed BIGINT NOT NULL DEFAULT 0,         -- deliveries that failed every attempt
+    last_delivered_at TIMESTAMPTZ,
+    last_failed_at TIMESTAMPTZ,
+    last_error TEXT,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,18 @@
+-- Migration 066: Per-tier aggregation granularity and raw retention
+-- Aggregated tiers all rolled up into 1-minute rows and kept raw results
+-- for a fixed two hours. Low-value tiers can now roll up coarser (5, 15,
+-- 60 minutes...) and keep raw rows for less time, so they store less.
+-- probe_1min rows record their own width; rows written before this
+-- migration are 1-minute rows.
+
+ALTER TABLE tiers ADD COLUMN aggregate_bucket_seconds INTEGER
+    CHECK (aggregate_bucket_seconds > 0 AND aggregate_bucket_seconds % 60 = 0 AND 3600 % aggregate_bucket_seconds = 0);
+ALTER TABLE tiers ADD COLUMN raw_retention_seconds INTEGER
+    CHECK (raw_retention_seconds > 0);
+
+COMMENT ON COLUMN tiers.aggregate_bucket_seconds IS 'Rollup width for aggregated ingest modes, whole minutes dividing an hour; NULL is 1 minute';
+COMMENT ON COLUMN tiers.raw_retention_seconds IS 'How long the aggregate ingest mode keeps raw results; NULL is the control plane default (2 hours)';
+
+ALTER TABLE probe_1min ADD COLUMN bucket_seconds INTEGER NOT NULL DEFAULT 60;
+
+COMMENT ON COLUMN probe_1min.bucket_seconds IS 'Width of the rollup starting at bucket';
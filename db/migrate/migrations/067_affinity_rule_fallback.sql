@@ -0,0 +1,10 @@
+-- Migration 067: Affinity rules are hard constraints by default
+-- A rule that no agent satisfied used to be skipped, so when every
+-- compliant agent was down a pci=true target went to any agent. A rule
+-- now leaves such a target unassigned unless it opts into the old
+-- behaviour with fallback 'allow'. Existing rules become hard constraints.
+
+ALTER TABLE affinity_rules ADD COLUMN fallback VARCHAR(10) NOT NULL DEFAULT 'deny'
+    CHECK (fallback IN ('deny', 'allow'));
+
+COMMENT ON COLUMN affinity_rules.fallback IS 'deny: leave targets unassigned when no agent satisfies the rule; allow: skip the rule for them';
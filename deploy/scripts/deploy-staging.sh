@@ -82,7 +82,7 @@ sleep 5
 
 # Health check
 log_info "Running health check..."
-HEALTH_URL="http://localhost:8080/api/v1/health"
+HEALTH_URL="http://localhost:8080/api/v1/health/ready"
 if curl -sf "$HEALTH_URL" > /dev/null; then
     log_info "Health check passed!"
 else
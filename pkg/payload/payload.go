@@ -0,0 +1,75 @@
+// Package payload decodes the executor-specific JSON payload of probe
+// results.
+//
+// The control plane reads only a few payload fields: latency and loss for
+// the typed probe_results columns, rollups and validation, and the reply
+// TTL for path change detection. Decode parses them in a single pass so
+// ingest no longer unmarshals each payload once per field.
+package payload
+
+import (
+	"encoding/json"
+	"math"
+)
+
+// Metrics holds the commonly read payload fields. Pointers are nil when a
+// field is absent.
+type Metrics struct {
+	AvgMs         *float64 `json:"avg_ms"`
+	MinMs         *float64 `json:"min_ms"`
+	MaxMs         *float64 `json:"max_ms"`
+	LatencyMs     *float64 `json:"latency_ms"` // most recent RTT
+	PacketLossPct *float64 `json:"packet_loss_pct"`
+	TTL           *int     `json:"reply_ttl"`
+
+	valid bool
+}
+
+// Decode parses a payload. An empty or malformed payload yields Metrics
+// with no fields set and Valid false.
+func Decode(raw json.RawMessage) Metrics {
+	var m Metrics
+	if len(raw) == 0 || json.Unmarshal(raw, &m) != nil {
+		return Metrics{}
+	}
+	m.valid = true
+	return m
+}
+
+// Valid reports whether the payload was decodable JSON.
+func (m Metrics) Valid() bool {
+	return m.valid
+}
+
+// Latency returns the average RTT, or nil if the probe measured none
+// (failed probes, MTR).
+func (m Metrics) Latency() *float64 {
+	if m.AvgMs == nil || *m.AvgMs <= 0 {
+		return nil
+	}
+	v := *m.AvgMs
+	return &v
+}
+
+// PacketLoss returns the loss percentage. Any valid payload without one
+// reports 0, matching what probe_results has always stored for them.
+func (m Metrics) PacketLoss() *float64 {
+	if !m.valid {
+		return nil
+	}
+	v := 0.0
+	if m.PacketLossPct != nil {
+		v = *m.PacketLossPct
+	}
+	return &v
+}
+
+// ReplyTTL returns the TTL of the most recent echo reply, or nil if none
+// was reported or it doesn't fit the SMALLINT column.
+func (m Metrics) ReplyTTL() *int16 {
+	if m.TTL == nil || *m.TTL <= 0 || *m.TTL > math.MaxInt16 {
+		return nil
+	}
+	v := int16(*m.TTL)
+	return &v
+}
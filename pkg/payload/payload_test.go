@@ -0,0 +1,117 @@
+package payload
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecode_Accessors(t *testing.T) {
+	tests := []struct {
+		name        string
+		raw         string
+		wantValid   bool
+		wantLatency *float64
+		wantLoss    *float64
+		wantTTL     *int16
+	}{
+		{
+			name:        "icmp reply",
+			raw:         `{"avg_ms":12.5,"min_ms":10,"packet_loss_pct":20,"reply_ttl":57}`,
+			wantValid:   true,
+			wantLatency: ptr(12.5),
+			wantLoss:    ptr(20.0),
+			wantTTL:     ttl(57),
+		},
+		{
+			name:      "no reply",
+			raw:       `{"avg_ms":0,"packet_loss_pct":100}`,
+			wantValid: true,
+			wantLoss:  ptr(100.0),
+		},
+		{
+			name:      "no loss field reports zero",
+			raw:       `{"hops":[]}`,
+			wantValid: true,
+			wantLoss:  ptr(0.0),
+		},
+		{
+			name:      "ttl out of range",
+			raw:       `{"reply_ttl":70000}`,
+			wantValid: true,
+			wantLoss:  ptr(0.0),
+		},
+		{
+			name: "empty",
+		},
+		{
+			name: "malformed",
+			raw:  `{"avg_ms":"fast"}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := Decode(json.RawMessage(tt.raw))
+			if m.Valid() != tt.wantValid {
+				t.Errorf("Valid() = %v, want %v", m.Valid(), tt.wantValid)
+			}
+			if !equalFloat(m.Latency(), tt.wantLatency) {
+				t.Errorf("Latency() = %v, want %v", deref(m.Latency()), deref(tt.wantLatency))
+			}
+			if !equalFloat(m.PacketLoss(), tt.wantLoss) {
+				t.Errorf("PacketLoss() = %v, want %v", deref(m.PacketLoss()), deref(tt.wantLoss))
+			}
+			got := m.ReplyTTL()
+			if (got == nil) != (tt.wantTTL == nil) || (got != nil && *got != *tt.wantTTL) {
+				t.Errorf("ReplyTTL() = %v, want %v", got, tt.wantTTL)
+			}
+		})
+	}
+}
+
+var benchPayload = json.RawMessage(`{"reachable":true,"latency_ms":11.8,"min_ms":10.2,"max_ms":14.1,` +
+	`"avg_ms":12.3,"stddev_ms":1.1,"packet_loss_pct":0,"packets_sent":5,"packets_recvd":5,"reply_ttl":57,"mode":"fping"}`)
+
+// BenchmarkIngestFields_Decode is the ingest path: one pass per result.
+func BenchmarkIngestFields_Decode(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		m := Decode(benchPayload)
+		_, _, _ = m.Latency(), m.PacketLoss(), m.ReplyTTL()
+	}
+}
+
+// BenchmarkIngestFields_PerField is the previous ingest path, which
+// unmarshaled the payload once for each column.
+func BenchmarkIngestFields_PerField(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		var lat struct {
+			AvgMs float64 `json:"avg_ms"`
+		}
+		var loss struct {
+			PacketLoss float64 `json:"packet_loss_pct"`
+		}
+		var ttl struct {
+			ReplyTTL int16 `json:"reply_ttl"`
+		}
+		_ = json.Unmarshal(benchPayload, &lat)
+		_ = json.Unmarshal(benchPayload, &loss)
+		_ = json.Unmarshal(benchPayload, &ttl)
+	}
+}
+
+func ptr(v float64) *float64 { return &v }
+
+func ttl(v int16) *int16 { return &v }
+
+func equalFloat(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func deref(v *float64) any {
+	if v == nil {
+		return nil
+	}
+	return *v
+}
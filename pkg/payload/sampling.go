@@ -0,0 +1,76 @@
+package payload
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"hash/fnv"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// Sampling decides which probe payloads are stored in full. Failed probes,
+// probes with an error and probes that lost packets always keep their
+// payload; of the rest (routine successes), only a Rate fraction do, and
+// the others are stored as Minimal. The typed columns and aggregates are
+// computed from the full payload either way.
+type Sampling struct {
+	// Rate is the fraction of routine probes stored in full, 0 to 1; at 0
+	// only anomalous payloads are kept.
+	Rate float64
+}
+
+// Store returns the payload to store for r, whose payload decoded to m. A
+// nil Sampling stores every payload in full.
+func (s *Sampling) Store(r types.ProbeResult, m Metrics) json.RawMessage {
+	if s == nil || s.Rate >= 1 || !m.valid || anomalous(r, m) || sampleFraction(r) < s.Rate {
+		return r.Payload
+	}
+	return m.Minimal()
+}
+
+// anomalous reports whether a probe is worth keeping in full whatever the
+// rate: it failed, carried an error or lost packets.
+func anomalous(r types.ProbeResult, m Metrics) bool {
+	return !r.Success || r.Error != "" || (m.PacketLossPct != nil && *m.PacketLossPct > 0)
+}
+
+// sampleFraction maps a result to [0, 1) from its identity, so a replayed
+// or duplicate result gets the same decision.
+func sampleFraction(r types.ProbeResult) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(r.TargetID))
+	h.Write([]byte(r.AgentID))
+	var ts [8]byte
+	binary.BigEndian.PutUint64(ts[:], uint64(r.Timestamp.UnixNano()))
+	h.Write(ts[:])
+	return float64(h.Sum64()>>11) / (1 << 53)
+}
+
+// minimalPayload is what a sampled-out payload keeps: the fields the
+// control plane reads.
+type minimalPayload struct {
+	AvgMs         *float64 `json:"avg_ms,omitempty"`
+	MinMs         *float64 `json:"min_ms,omitempty"`
+	MaxMs         *float64 `json:"max_ms,omitempty"`
+	LatencyMs     *float64 `json:"latency_ms,omitempty"`
+	PacketLossPct *float64 `json:"packet_loss_pct,omitempty"`
+	TTL           *int     `json:"reply_ttl,omitempty"`
+
+	// Minimal marks the payload as reduced, so readers know detail such as
+	// per-packet RTTs was dropped rather than never collected.
+	Minimal bool `json:"minimal"`
+}
+
+// Minimal encodes just the decoded fields, marked as a minimal payload.
+func (m Metrics) Minimal() json.RawMessage {
+	raw, _ := json.Marshal(minimalPayload{
+		AvgMs:         m.AvgMs,
+		MinMs:         m.MinMs,
+		MaxMs:         m.MaxMs,
+		LatencyMs:     m.LatencyMs,
+		PacketLossPct: m.PacketLossPct,
+		TTL:           m.TTL,
+		Minimal:       true,
+	})
+	return raw
+}
@@ -0,0 +1,99 @@
+package payload
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+func TestSampling_Store(t *testing.T) {
+	base := types.ProbeResult{
+		TargetID:  "t1",
+		AgentID:   "a1",
+		Timestamp: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC),
+		Success:   true,
+		Payload:   benchPayload,
+	}
+	lossy := base
+	lossy.Payload = json.RawMessage(`{"avg_ms":12,"packet_loss_pct":20,"rtts":[12,null,12,12,12]}`)
+	failed := base
+	failed.Success = false
+	failed.Payload = json.RawMessage(`{"avg_ms":0,"packet_loss_pct":100}`)
+	errored := base
+	errored.Error = "timeout on 1 of 5"
+	malformed := base
+	malformed.Payload = json.RawMessage(`{"avg_ms":"fast"}`)
+
+	tests := []struct {
+		name     string
+		sampling *Sampling
+		result   types.ProbeResult
+		wantFull bool
+	}{
+		{name: "nil keeps routine", result: base, wantFull: true},
+		{name: "full rate keeps routine", sampling: &Sampling{Rate: 1}, result: base, wantFull: true},
+		{name: "zero rate minimizes routine", sampling: &Sampling{}, result: base},
+		{name: "packet loss kept", sampling: &Sampling{}, result: lossy, wantFull: true},
+		{name: "failure kept", sampling: &Sampling{}, result: failed, wantFull: true},
+		{name: "error kept", sampling: &Sampling{}, result: errored, wantFull: true},
+		{name: "undecodable kept as is", sampling: &Sampling{}, result: malformed, wantFull: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.sampling.Store(tt.result, Decode(tt.result.Payload))
+			if full := string(got) == string(tt.result.Payload); full != tt.wantFull {
+				t.Errorf("Store() = %s, want full payload %v", got, tt.wantFull)
+			}
+		})
+	}
+}
+
+func TestSampling_MinimalKeepsIngestFields(t *testing.T) {
+	m := Decode(benchPayload)
+	minimal := Decode(m.Minimal())
+
+	if !equalFloat(minimal.Latency(), m.Latency()) || !equalFloat(minimal.PacketLoss(), m.PacketLoss()) {
+		t.Errorf("minimal latency/loss = %v/%v, want %v/%v",
+			deref(minimal.Latency()), deref(minimal.PacketLoss()), deref(m.Latency()), deref(m.PacketLoss()))
+	}
+	if got, want := minimal.ReplyTTL(), m.ReplyTTL(); got == nil || *got != *want {
+		t.Errorf("minimal ReplyTTL() = %v, want %v", got, *want)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(m.Minimal(), &fields); err != nil {
+		t.Fatal(err)
+	}
+	if fields["minimal"] != true || fields["stddev_ms"] != nil {
+		t.Errorf("minimal payload = %v, want marked and without detail fields", fields)
+	}
+}
+
+func TestSampling_RateFraction(t *testing.T) {
+	sampling := &Sampling{Rate: 0.25}
+	r := types.ProbeResult{AgentID: "a1", Success: true, Payload: benchPayload}
+	m := Decode(benchPayload)
+
+	const n = 4000
+	full := 0
+	for i := 0; i < n; i++ {
+		r.TargetID = fmt.Sprintf("t%d", i)
+		r.Timestamp = time.Unix(int64(i)*30, 0)
+		if string(sampling.Store(r, m)) == string(r.Payload) {
+			full++
+		}
+	}
+	if got := float64(full) / n; got < 0.2 || got > 0.3 {
+		t.Errorf("kept %.3f of routine payloads, want about 0.25", got)
+	}
+
+	// The same result always gets the same decision
+	first := sampling.Store(r, m)
+	if again := sampling.Store(r, m); string(again) != string(first) {
+		t.Error("decision changed for the same result")
+	}
+}
@@ -0,0 +1,132 @@
+// Package resultpb encodes result batches in protobuf, the compact
+// alternative to JSON for POST /api/v1/results.
+//
+// The wire schema is results.proto, and results.pb.go holds the types
+// protoc-gen-go generates from it. Agent and control plane both work in
+// types.ResultBatch; Marshal and Unmarshal convert to and from the
+// generated ResultBatch at the wire. Payloads stay the executor's JSON
+// bytes: the savings are in the per-result envelope, where JSON repeats
+// every key and spells out IDs and timestamps as text.
+//
+// Unknown fields are skipped on decode, so either side can gain fields
+// before the other.
+package resultpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative results.proto
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// ContentType marks a request body as a protobuf ResultBatch.
+const ContentType = "application/x-protobuf"
+
+// ErrMalformed is returned for input that isn't a valid ResultBatch.
+var ErrMalformed = errors.New("malformed protobuf result batch")
+
+// Marshal encodes a batch.
+func Marshal(batch *types.ResultBatch) ([]byte, error) {
+	data, err := proto.Marshal(toProto(batch))
+	if err != nil {
+		return nil, fmt.Errorf("marshaling protobuf batch: %w", err)
+	}
+	return data, nil
+}
+
+// Unmarshal decodes a batch.
+func Unmarshal(data []byte, batch *types.ResultBatch) error {
+	var msg ResultBatch
+	if err := proto.Unmarshal(data, &msg); err != nil {
+		return fmt.Errorf("%w: %w", ErrMalformed, err)
+	}
+	*batch = fromProto(&msg)
+	return nil
+}
+
+// toProto converts a batch to its wire form.
+func toProto(batch *types.ResultBatch) *ResultBatch {
+	msg := &ResultBatch{
+		AgentId:           validUTF8(batch.AgentID),
+		BatchId:           validUTF8(batch.BatchID),
+		CreatedAtUnixNano: unixNano(batch.CreatedAt),
+		Sequence:          batch.Sequence,
+		Results:           make([]*ProbeResult, len(batch.Results)),
+	}
+	for i := range batch.Results {
+		r := &batch.Results[i]
+		msg.Results[i] = &ProbeResult{
+			TargetId:          validUTF8(r.TargetID),
+			AgentId:           validUTF8(r.AgentID),
+			EndpointId:        validUTF8(r.EndpointID),
+			TimestampUnixNano: unixNano(r.Timestamp),
+			DurationNanos:     int64(r.Duration),
+			Success:           r.Success,
+			Error:             validUTF8(r.Error),
+			ProbeType:         validUTF8(r.ProbeType),
+			Payload:           r.Payload,
+		}
+	}
+	return msg
+}
+
+// fromProto converts a decoded batch back. Unset repeated and bytes fields
+// stay nil, as JSON decoding leaves them.
+func fromProto(msg *ResultBatch) types.ResultBatch {
+	batch := types.ResultBatch{
+		AgentID:   msg.GetAgentId(),
+		BatchID:   msg.GetBatchId(),
+		CreatedAt: fromUnixNano(msg.GetCreatedAtUnixNano()),
+		Sequence:  msg.GetSequence(),
+	}
+	if len(msg.GetResults()) > 0 {
+		batch.Results = make([]types.ProbeResult, len(msg.Results))
+	}
+	for i, r := range msg.GetResults() {
+		batch.Results[i] = types.ProbeResult{
+			TargetID:   r.GetTargetId(),
+			AgentID:    r.GetAgentId(),
+			EndpointID: r.GetEndpointId(),
+			Timestamp:  fromUnixNano(r.GetTimestampUnixNano()),
+			Duration:   time.Duration(r.GetDurationNanos()),
+			Success:    r.GetSuccess(),
+			Error:      r.GetError(),
+			ProbeType:  r.GetProbeType(),
+		}
+		if len(r.GetPayload()) > 0 {
+			batch.Results[i].Payload = json.RawMessage(r.Payload)
+		}
+	}
+	return batch
+}
+
+// validUTF8 replaces invalid UTF-8, which proto3 strings reject, with
+// U+FFFD as JSON shipping would, so a probe error quoting raw output still
+// ships.
+func validUTF8(s string) string {
+	return strings.ToValidUTF8(s, "\uFFFD")
+}
+
+// unixNano is t in Unix nanoseconds, 0 for the zero time, whose
+// UnixNano is out of range.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano reverses unixNano, in UTC.
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
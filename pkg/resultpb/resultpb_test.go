@@ -0,0 +1,251 @@
+package resultpb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/pilot-net/icmp-mon/pkg/types"
+)
+
+// mustMarshal is Marshal for batches that can't fail to encode.
+func mustMarshal(t testing.TB, batch *types.ResultBatch) []byte {
+	t.Helper()
+	data, err := Marshal(batch)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return data
+}
+
+func TestMarshal_RoundTrip(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 123456789, time.UTC)
+
+	tests := []struct {
+		name  string
+		batch types.ResultBatch
+	}{
+		{name: "empty batch", batch: types.ResultBatch{}},
+		{
+			name: "full batch",
+			batch: types.ResultBatch{
+				AgentID:   "agent-1",
+				BatchID:   "agent-1-42",
+				CreatedAt: at,
+				Sequence:  1700000000000000042,
+				Results: []types.ProbeResult{
+					{
+						TargetID: "t1", AgentID: "agent-1", Timestamp: at, Duration: 12 * time.Millisecond,
+						Success: true, ProbeType: types.DefaultProbeType, Payload: json.RawMessage(`{"avg_ms":12.3}`),
+					},
+					{
+						TargetID: "t2", AgentID: "agent-1", EndpointID: "e1", Timestamp: at.Add(time.Second),
+						Error: "timeout", ProbeType: types.DefaultProbeType,
+					},
+				},
+			},
+		},
+		{
+			name:  "negative sequence",
+			batch: types.ResultBatch{AgentID: "a", Sequence: -5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got types.ResultBatch
+			if err := Unmarshal(mustMarshal(t, &tt.batch), &got); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.batch) {
+				t.Errorf("round trip = %+v, want %+v", got, tt.batch)
+			}
+		})
+	}
+}
+
+func TestMarshal_InvalidUTF8(t *testing.T) {
+	batch := types.ResultBatch{
+		AgentID: "agent-1",
+		Results: []types.ProbeResult{{TargetID: "t1", Error: "bad reply \xff\xfe from host"}},
+	}
+
+	var got types.ResultBatch
+	if err := Unmarshal(mustMarshal(t, &batch), &got); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if want := "bad reply \uFFFD from host"; got.Results[0].Error != want {
+		t.Errorf("Error = %q, want %q", got.Results[0].Error, want)
+	}
+}
+
+func TestUnmarshal_Malformed(t *testing.T) {
+	valid := mustMarshal(t, &types.ResultBatch{
+		AgentID: "agent-1",
+		Results: []types.ProbeResult{{TargetID: "t1", Payload: json.RawMessage(`{}`)}},
+	})
+
+	// A field number this version doesn't know, in each wire type
+	var unknown []byte
+	unknown = protowire.AppendTag(unknown, 99, protowire.VarintType)
+	unknown = protowire.AppendVarint(unknown, 7)
+	unknown = protowire.AppendTag(unknown, 98, protowire.BytesType)
+	unknown = protowire.AppendString(unknown, "future")
+	unknown = protowire.AppendTag(unknown, 97, protowire.Fixed64Type)
+	unknown = protowire.AppendFixed64(unknown, 0)
+	unknown = protowire.AppendTag(unknown, 96, protowire.Fixed32Type)
+	unknown = protowire.AppendFixed32(unknown, 0)
+
+	// agent_id holding bytes that aren't UTF-8
+	var invalidUTF8 []byte
+	invalidUTF8 = protowire.AppendTag(invalidUTF8, 1, protowire.BytesType)
+	invalidUTF8 = protowire.AppendBytes(invalidUTF8, []byte{0xff})
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{name: "valid", data: valid},
+		{name: "unknown fields skipped", data: append(append([]byte{}, unknown...), valid...)},
+		{name: "truncated", data: valid[:len(valid)-3], wantErr: true},
+		{name: "length past end", data: []byte{0x0a, 0x10, 'a'}, wantErr: true},
+		{name: "field zero", data: []byte{0x00, 0x01}, wantErr: true},
+		{name: "unterminated group", data: []byte{0x0b}, wantErr: true},
+		{name: "invalid utf-8", data: invalidUTF8, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batch types.ResultBatch
+			err := Unmarshal(tt.data, &batch)
+			if tt.wantErr {
+				if !errors.Is(err, ErrMalformed) {
+					t.Errorf("err = %v, want ErrMalformed", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if batch.AgentID != "agent-1" || len(batch.Results) != 1 {
+				t.Errorf("decoded %+v", batch)
+			}
+		})
+	}
+}
+
+// benchBatch is a full shipper batch of ICMP results.
+func benchBatch() *types.ResultBatch {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	batch := &types.ResultBatch{
+		AgentID:   "6f1c7a52-3f0e-4d8b-9a51-2d7c4e0b9f13",
+		BatchID:   "6f1c7a52-3f0e-4d8b-9a51-2d7c4e0b9f13-1700000000000000001",
+		CreatedAt: at,
+		Sequence:  1700000000000000001,
+	}
+	for i := range 1000 {
+		batch.Results = append(batch.Results, types.ProbeResult{
+			TargetID:  fmt.Sprintf("0b5e%04x-7c1d-4f2a-8e3b-5a9c6d1e2f30", i),
+			AgentID:   batch.AgentID,
+			Timestamp: at.Add(time.Duration(i) * time.Millisecond),
+			Duration:  time.Duration(10+i%40) * time.Millisecond,
+			Success:   true,
+			ProbeType: types.DefaultProbeType,
+			Payload: json.RawMessage(fmt.Sprintf(`{"reachable":true,"latency_ms":%d.4,"min_ms":10.2,"max_ms":14.1,`+
+				`"avg_ms":12.3,"stddev_ms":1.1,"packet_loss_pct":0,"packets_sent":5,"packets_recvd":5,"reply_ttl":57}`, 10+i%40)),
+		})
+	}
+	return batch
+}
+
+func gzipBytes(b *testing.B, data []byte) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		b.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func gunzip(b *testing.B, data []byte) []byte {
+	gz, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		b.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(gz); err != nil {
+		b.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// BenchmarkParse_JSONGzip is the default ingest path: gunzip, then decode
+// JSON. It reports the body size before and after gzip.
+func BenchmarkParse_JSONGzip(b *testing.B) {
+	raw, err := json.Marshal(benchBatch())
+	if err != nil {
+		b.Fatal(err)
+	}
+	body := gzipBytes(b, raw)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var batch types.ResultBatch
+		if err := json.Unmarshal(gunzip(b, body), &batch); err != nil {
+			b.Fatal(err)
+		}
+	}
+	reportSizes(b, raw, body)
+}
+
+// BenchmarkParse_ProtobufGzip is the protobuf ingest path as the agent
+// ships it: gunzip, then Unmarshal.
+func BenchmarkParse_ProtobufGzip(b *testing.B) {
+	raw := mustMarshal(b, benchBatch())
+	body := gzipBytes(b, raw)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		var batch types.ResultBatch
+		if err := Unmarshal(gunzip(b, body), &batch); err != nil {
+			b.Fatal(err)
+		}
+	}
+	reportSizes(b, raw, body)
+}
+
+// reportSizes adds a batch's size before and after gzip to the results.
+func reportSizes(b *testing.B, raw, gzipped []byte) {
+	b.ReportMetric(float64(len(raw)), "raw-bytes")
+	b.ReportMetric(float64(len(gzipped)), "gzip-bytes")
+}
+
+// BenchmarkMarshal_JSON and BenchmarkMarshal_Protobuf are the agent's side.
+func BenchmarkMarshal_JSON(b *testing.B) {
+	batch := benchBatch()
+	for i := 0; i < b.N; i++ {
+		if _, err := json.Marshal(batch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkMarshal_Protobuf(b *testing.B) {
+	batch := benchBatch()
+	for i := 0; i < b.N; i++ {
+		if _, err := Marshal(batch); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
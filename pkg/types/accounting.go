@@ -0,0 +1,87 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// AccountingPeriod is the bucket probe counts are reported in.
+type AccountingPeriod string
+
+const (
+	AccountingPeriodDay   AccountingPeriod = "day"
+	AccountingPeriodWeek  AccountingPeriod = "week"
+	AccountingPeriodMonth AccountingPeriod = "month"
+)
+
+// ParseAccountingPeriod validates a period name; empty means a day.
+func ParseAccountingPeriod(s string) (AccountingPeriod, error) {
+	switch p := AccountingPeriod(s); p {
+	case "":
+		return AccountingPeriodDay, nil
+	case AccountingPeriodDay, AccountingPeriodWeek, AccountingPeriodMonth:
+		return p, nil
+	default:
+		return "", fmt.Errorf("invalid period %q (want day, week or month)", s)
+	}
+}
+
+// Interval returns the period as a PostgreSQL interval for time_bucket.
+// Weeks start on Monday and months on the 1st, in UTC.
+func (p AccountingPeriod) Interval() string {
+	return "1 " + string(p)
+}
+
+// ProbeUsage is the probes counted in one period.
+type ProbeUsage struct {
+	PeriodStart time.Time `json:"period_start"`
+	Probes      int64     `json:"probes"`
+	Successes   int64     `json:"successes"`
+
+	// Agents is how many agents probed the target (target accounting);
+	// Targets how many targets the agent probed (agent accounting).
+	Agents  int64 `json:"agents,omitempty"`
+	Targets int64 `json:"targets,omitempty"`
+}
+
+// TargetProbeAccounting is a target's probe counts by period.
+type TargetProbeAccounting struct {
+	TargetID    string       `json:"target_id"`
+	TargetIP    string       `json:"target_ip"`
+	DisplayName string       `json:"display_name,omitempty"`
+	Tier        string       `json:"tier"`
+	TotalProbes int64        `json:"total_probes"`
+	Periods     []ProbeUsage `json:"periods"`
+}
+
+// AgentProbeAccounting is an agent's probe counts by period.
+type AgentProbeAccounting struct {
+	AgentID     string       `json:"agent_id"`
+	AgentName   string       `json:"agent_name"`
+	Region      string       `json:"region,omitempty"`
+	Provider    string       `json:"provider,omitempty"`
+	TotalProbes int64        `json:"total_probes"`
+	Periods     []ProbeUsage `json:"periods"`
+}
+
+// ProbeAccountingRange is the range and total of an accounting report:
+// probes from Start up to End, both whole UTC days. Weekly and monthly
+// periods at either end may be partial.
+type ProbeAccountingRange struct {
+	Period      AccountingPeriod `json:"period"`
+	Start       time.Time        `json:"start"`
+	End         time.Time        `json:"end"`
+	TotalProbes int64            `json:"total_probes"`
+}
+
+// TargetProbeAccountingReport is probe counts per target.
+type TargetProbeAccountingReport struct {
+	ProbeAccountingRange
+	Targets []TargetProbeAccounting `json:"targets"`
+}
+
+// AgentProbeAccountingReport is probe counts per agent.
+type AgentProbeAccountingReport struct {
+	ProbeAccountingRange
+	Agents []AgentProbeAccounting `json:"agents"`
+}
@@ -0,0 +1,231 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// =============================================================================
+// ASSIGNMENT AFFINITY RULES
+// =============================================================================
+
+// AffinityMode says how a rule constrains agents for the targets it matches.
+type AffinityMode string
+
+const (
+	// AffinityModeRequire limits matched targets to agents matching the
+	// rule's agent filters.
+	AffinityModeRequire AffinityMode = "affinity"
+	// AffinityModeAvoid keeps agents matching the rule's agent filters off
+	// matched targets.
+	AffinityModeAvoid AffinityMode = "anti_affinity"
+)
+
+// AffinityFallback says what happens to a target a rule matches when no
+// agent satisfies the rule.
+type AffinityFallback string
+
+const (
+	// AffinityFallbackDeny leaves the target unassigned until an agent
+	// satisfies the rule. It is the default: a rule is a hard constraint.
+	AffinityFallbackDeny AffinityFallback = "deny"
+	// AffinityFallbackAllow skips the rule for the target, which is then
+	// placed as if the rule didn't exist.
+	AffinityFallbackAllow AffinityFallback = "allow"
+)
+
+// DefaultAffinityPriority is the priority of a rule created without one.
+const DefaultAffinityPriority = 100
+
+// AffinityRule constrains which agents may probe targets, by tags, on top of
+// the tier's agent selection policy. For example, targets tagged pci=true
+// probed only by agents tagged compliant=true.
+//
+// Rules are applied in priority order. A rule that would leave a target with
+// no eligible agent leaves it unassigned, unless its Fallback is allow, in
+// which case the rule is skipped for that target.
+type AffinityRule struct {
+	ID          string       `json:"id"`
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Enabled     bool         `json:"enabled"`
+	Priority    int          `json:"priority"` // Lower = higher priority, applied first
+	Mode        AffinityMode `json:"mode"`
+
+	// Fallback when no agent satisfies the rule; empty is deny
+	Fallback AffinityFallback `json:"fallback"`
+
+	// Targets the rule applies to (same key ORed, different keys ANDed)
+	TargetFilters []TagFilter `json:"target_filters"`
+
+	// Agents the rule requires or avoids, matched against agent tags
+	AgentFilters []TagFilter `json:"agent_filters"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the rule names a mode and has usable filters.
+func (r *AffinityRule) Validate() error {
+	if strings.TrimSpace(r.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Mode != AffinityModeRequire && r.Mode != AffinityModeAvoid {
+		return fmt.Errorf("mode must be %s or %s", AffinityModeRequire, AffinityModeAvoid)
+	}
+	switch r.Fallback {
+	case "", AffinityFallbackDeny, AffinityFallbackAllow:
+	default:
+		return fmt.Errorf("fallback must be %s or %s", AffinityFallbackDeny, AffinityFallbackAllow)
+	}
+	if len(r.TargetFilters) == 0 {
+		return fmt.Errorf("target_filters is required")
+	}
+	if len(r.AgentFilters) == 0 {
+		return fmt.Errorf("agent_filters is required")
+	}
+	for _, f := range append(append([]TagFilter{}, r.TargetFilters...), r.AgentFilters...) {
+		if err := f.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FailsOpen reports whether the rule is skipped, rather than leaving a
+// target unassigned, when no agent satisfies it.
+func (r *AffinityRule) FailsOpen() bool {
+	return r.Fallback == AffinityFallbackAllow
+}
+
+// MatchesTarget reports whether the rule applies to a target with tags.
+func (r *AffinityRule) MatchesTarget(tags map[string]string) bool {
+	return MatchTagFilters(r.TargetFilters, tags)
+}
+
+// AllowsAgent reports whether the rule lets an agent with tags probe the
+// targets it matches.
+func (r *AffinityRule) AllowsAgent(tags map[string]string) bool {
+	matched := MatchTagFilters(r.AgentFilters, tags)
+	if r.Mode == AffinityModeAvoid {
+		return !matched
+	}
+	return matched
+}
+
+// =============================================================================
+// TAG FILTER MATCHING
+// =============================================================================
+
+// Validate checks the filter's key and operator, and that a regex compiles.
+func (f TagFilter) Validate() error {
+	if f.Key == "" {
+		return fmt.Errorf("tag filter key is required")
+	}
+	switch f.Operator {
+	case "", "equals", "not_equals", "contains", "not_contains", "starts_with", "in", "not_in":
+		return nil
+	case "regex":
+		if _, err := regexp.Compile(f.Value); err != nil {
+			return fmt.Errorf("tag filter %s: invalid regex: %w", f.Key, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("tag filter %s: unknown operator %q", f.Key, f.Operator)
+	}
+}
+
+// Matches reports whether tags satisfy the filter. It follows the SQL the
+// metrics query builds for the same filter: text comparisons other than
+// equality are case-insensitive, and the negative operators match when the
+// tag is missing.
+func (f TagFilter) Matches(tags map[string]string) bool {
+	v, ok := tags[f.Key]
+	switch f.Operator {
+	case "", "equals":
+		return ok && v == f.Value
+	case "not_equals":
+		return !ok || v != f.Value
+	case "contains":
+		return ok && strings.Contains(strings.ToLower(v), strings.ToLower(f.Value))
+	case "not_contains":
+		return !ok || !strings.Contains(strings.ToLower(v), strings.ToLower(f.Value))
+	case "starts_with":
+		return ok && strings.HasPrefix(strings.ToLower(v), strings.ToLower(f.Value))
+	case "in", "not_in":
+		values := splitList(f.Value)
+		if len(values) == 0 {
+			return true // an empty list places no condition
+		}
+		in := ok && slices.Contains(values, v)
+		if f.Operator == "not_in" {
+			return !in
+		}
+		return in
+	case "regex":
+		re := compiledTagRegex(f.Value)
+		return re != nil && ok && re.MatchString(v)
+	default:
+		return ok && v == f.Value
+	}
+}
+
+// MatchTagFilters reports whether tags satisfy every key in filters.
+// Filters on the same key are ORed and different keys are ANDed. No filters
+// match everything.
+func MatchTagFilters(filters []TagFilter, tags map[string]string) bool {
+	byKey := make(map[string]bool, len(filters))
+	for _, f := range filters {
+		byKey[f.Key] = byKey[f.Key] || f.Matches(tags)
+	}
+	for _, matched := range byKey {
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// tagRegexes caches compiled regex filter values; assignment computation
+// matches the same few rules against every target. Invalid patterns are
+// stored as nil.
+var tagRegexes sync.Map
+
+func compiledTagRegex(pattern string) *regexp.Regexp {
+	if re, ok := tagRegexes.Load(pattern); ok {
+		return re.(*regexp.Regexp)
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	tagRegexes.Store(pattern, re)
+	return re
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(list string) []string {
+	var values []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// AffinityCheck reports how a rule plays out against the current fleet.
+type AffinityCheck struct {
+	RuleID         string `json:"rule_id"`
+	MatchedTargets int    `json:"matched_targets"`
+
+	// UnsatisfiableTargets are matched targets no agent satisfies the rule
+	// for. They are left unassigned, or the rule is skipped for them if its
+	// fallback is allow.
+	UnsatisfiableTargets int      `json:"unsatisfiable_targets"`
+	SampleTargetIDs      []string `json:"sample_target_ids,omitempty"`
+}
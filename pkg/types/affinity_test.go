@@ -0,0 +1,132 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTagFilterMatches_Operators(t *testing.T) {
+	tags := map[string]string{"pop": "ORD1", "env": "prod"}
+
+	tests := []struct {
+		name   string
+		filter TagFilter
+		want   bool
+	}{
+		{"equals", TagFilter{Key: "env", Operator: "equals", Value: "prod"}, true},
+		{"empty operator is equals", TagFilter{Key: "env", Value: "lab"}, false},
+		{"equals missing key", TagFilter{Key: "pci", Operator: "equals", Value: "true"}, false},
+		{"not_equals", TagFilter{Key: "env", Operator: "not_equals", Value: "lab"}, true},
+		{"not_equals missing key", TagFilter{Key: "pci", Operator: "not_equals", Value: "true"}, true},
+		{"contains ignores case", TagFilter{Key: "pop", Operator: "contains", Value: "rd"}, true},
+		{"not_contains", TagFilter{Key: "pop", Operator: "not_contains", Value: "ord"}, false},
+		{"starts_with ignores case", TagFilter{Key: "pop", Operator: "starts_with", Value: "ord"}, true},
+		{"in", TagFilter{Key: "env", Operator: "in", Value: "lab, prod"}, true},
+		{"in missing", TagFilter{Key: "env", Operator: "in", Value: "lab,staging"}, false},
+		{"in empty list", TagFilter{Key: "env", Operator: "in", Value: " , "}, true},
+		{"not_in", TagFilter{Key: "env", Operator: "not_in", Value: "lab,staging"}, true},
+		{"not_in missing key", TagFilter{Key: "pci", Operator: "not_in", Value: "true"}, true},
+		{"regex", TagFilter{Key: "pop", Operator: "regex", Value: "^ORD[0-9]$"}, true},
+		{"invalid regex never matches", TagFilter{Key: "pop", Operator: "regex", Value: "("}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(tags); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchTagFilters_KeyGrouping(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters []TagFilter
+		tags    map[string]string
+		want    bool
+	}{
+		{"no filters match everything", nil, map[string]string{}, true},
+		{"same key ORed", []TagFilter{{Key: "pop", Value: "ord"}, {Key: "pop", Value: "iad"}}, map[string]string{"pop": "iad"}, true},
+		{"different keys ANDed", []TagFilter{{Key: "pop", Value: "ord"}, {Key: "pci", Value: "true"}}, map[string]string{"pop": "ord"}, false},
+		{"all keys satisfied", []TagFilter{{Key: "pop", Value: "ord"}, {Key: "pci", Value: "true"}}, map[string]string{"pop": "ord", "pci": "true"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MatchTagFilters(tt.filters, tt.tags); got != tt.want {
+				t.Errorf("MatchTagFilters() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffinityRule_AllowsAgent(t *testing.T) {
+	compliant := []TagFilter{{Key: "compliant", Value: "true"}}
+
+	tests := []struct {
+		name string
+		mode AffinityMode
+		tags map[string]string
+		want bool
+	}{
+		{"affinity matching agent", AffinityModeRequire, map[string]string{"compliant": "true"}, true},
+		{"affinity other agent", AffinityModeRequire, map[string]string{}, false},
+		{"anti-affinity matching agent", AffinityModeAvoid, map[string]string{"compliant": "true"}, false},
+		{"anti-affinity other agent", AffinityModeAvoid, map[string]string{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := AffinityRule{Mode: tt.mode, AgentFilters: compliant}
+			if got := r.AllowsAgent(tt.tags); got != tt.want {
+				t.Errorf("AllowsAgent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAffinityRuleValidate_Fields(t *testing.T) {
+	valid := func() AffinityRule {
+		return AffinityRule{
+			Name:          "pci",
+			Mode:          AffinityModeRequire,
+			TargetFilters: []TagFilter{{Key: "pci", Value: "true"}},
+			AgentFilters:  []TagFilter{{Key: "compliant", Value: "true"}},
+		}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(r *AffinityRule)
+		wantErr string
+	}{
+		{"valid", func(r *AffinityRule) {}, ""},
+		{"missing name", func(r *AffinityRule) { r.Name = " " }, "name is required"},
+		{"bad mode", func(r *AffinityRule) { r.Mode = "prefer" }, "mode must be"},
+		{"fallback allow", func(r *AffinityRule) { r.Fallback = AffinityFallbackAllow }, ""},
+		{"bad fallback", func(r *AffinityRule) { r.Fallback = "ignore" }, "fallback must be"},
+		{"no target filters", func(r *AffinityRule) { r.TargetFilters = nil }, "target_filters is required"},
+		{"no agent filters", func(r *AffinityRule) { r.AgentFilters = nil }, "agent_filters is required"},
+		{"unknown operator", func(r *AffinityRule) { r.AgentFilters[0].Operator = "like" }, "unknown operator"},
+		{"bad regex", func(r *AffinityRule) { r.TargetFilters[0] = TagFilter{Key: "pci", Operator: "regex", Value: "["} }, "invalid regex"},
+		{"missing key", func(r *AffinityRule) { r.TargetFilters[0].Key = "" }, "key is required"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.mutate(&r)
+			err := r.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
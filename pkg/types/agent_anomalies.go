@@ -0,0 +1,39 @@
+package types
+
+import "time"
+
+// AgentAnomalySummary is one agent's share of the open anomalies and alerts.
+type AgentAnomalySummary struct {
+	AgentID     string  `json:"agent_id"`
+	AgentName   string  `json:"agent_name"`
+	AgentRegion *string `json:"agent_region,omitempty"`
+
+	// AnomalyCount is the agent's targets currently in anomaly; AlertCount
+	// its active or acknowledged per-agent alerts.
+	AnomalyCount int `json:"anomaly_count"`
+	AlertCount   int `json:"alert_count"`
+
+	// AffectedTargets are the targets with an anomaly or alert from this
+	// agent; AssignedTargets is how many it probes in total.
+	AffectedTargets []string `json:"affected_targets"`
+	AssignedTargets int      `json:"assigned_targets"`
+
+	// LikelyAgentSide flags an agent whose affected targets far exceed the
+	// fleet's typical count, i.e. the agent is the common factor.
+	LikelyAgentSide bool   `json:"likely_agent_side"`
+	Reason          string `json:"reason,omitempty"`
+}
+
+// AgentAnomalyReport ranks agents by how many targets they see problems on.
+type AgentAnomalyReport struct {
+	GeneratedAt time.Time `json:"generated_at"`
+
+	// ActiveAgents counts every non-archived agent, including those with no
+	// problems, which the fleet median is taken over.
+	ActiveAgents         int     `json:"active_agents"`
+	FleetMedianAffected  float64 `json:"fleet_median_affected"`
+	LikelyAgentSideCount int     `json:"likely_agent_side_count"`
+
+	// Agents with at least one anomaly or alert, most affected first.
+	Agents []AgentAnomalySummary `json:"agents"`
+}
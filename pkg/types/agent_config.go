@@ -0,0 +1,115 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// REMOTE AGENT CONFIG
+// =============================================================================
+
+// Log levels an agent's remote config may set.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info"
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// MinRemoteIntervalS is the shortest heartbeat or poll interval the control
+// plane may push, so a typo can't have the fleet hammering it.
+const MinRemoteIntervalS = 1
+
+// AgentRemoteConfig is the agent configuration the control plane can push.
+// These are the only remotely overridable settings: each can be applied by
+// a running agent without a restart. Nil fields leave the agent's local
+// value in place.
+type AgentRemoteConfig struct {
+	LogLevel *string `json:"log_level,omitempty"` // debug, info, warn or error
+
+	// ScheduleAlignment replaces probing.schedule_alignment ("aligned" or
+	// "drifting"); local per-tier alignments still take precedence.
+	ScheduleAlignment *string `json:"schedule_alignment,omitempty"`
+
+	HeartbeatIntervalS      *int `json:"heartbeat_interval_s,omitempty"`
+	AssignmentPollIntervalS *int `json:"assignment_poll_interval_s,omitempty"`
+	CommandPollIntervalS    *int `json:"command_poll_interval_s,omitempty"`
+
+	// FeatureFlags turn optional agent behavior on or off by name.
+	FeatureFlags map[string]bool `json:"feature_flags,omitempty"`
+}
+
+// Validate checks the values that are set.
+func (c AgentRemoteConfig) Validate() error {
+	if c.LogLevel != nil {
+		switch *c.LogLevel {
+		case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+		default:
+			return fmt.Errorf("log_level must be %s, %s, %s or %s", LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError)
+		}
+	}
+	if c.ScheduleAlignment != nil && *c.ScheduleAlignment != "aligned" && *c.ScheduleAlignment != "drifting" {
+		return fmt.Errorf("schedule_alignment must be 'aligned' or 'drifting'")
+	}
+	intervals := []struct {
+		name string
+		s    *int
+	}{
+		{"heartbeat_interval_s", c.HeartbeatIntervalS},
+		{"assignment_poll_interval_s", c.AssignmentPollIntervalS},
+		{"command_poll_interval_s", c.CommandPollIntervalS},
+	}
+	for _, iv := range intervals {
+		if iv.s != nil && *iv.s < MinRemoteIntervalS {
+			return fmt.Errorf("%s must be at least %d", iv.name, MinRemoteIntervalS)
+		}
+	}
+	for name := range c.FeatureFlags {
+		if name == "" {
+			return fmt.Errorf("feature flag names must not be empty")
+		}
+	}
+	return nil
+}
+
+// Merge returns c with every field set in over replacing c's. Feature flags
+// are merged by name.
+func (c AgentRemoteConfig) Merge(over AgentRemoteConfig) AgentRemoteConfig {
+	merged := c
+	if over.LogLevel != nil {
+		merged.LogLevel = over.LogLevel
+	}
+	if over.ScheduleAlignment != nil {
+		merged.ScheduleAlignment = over.ScheduleAlignment
+	}
+	if over.HeartbeatIntervalS != nil {
+		merged.HeartbeatIntervalS = over.HeartbeatIntervalS
+	}
+	if over.AssignmentPollIntervalS != nil {
+		merged.AssignmentPollIntervalS = over.AssignmentPollIntervalS
+	}
+	if over.CommandPollIntervalS != nil {
+		merged.CommandPollIntervalS = over.CommandPollIntervalS
+	}
+	if len(c.FeatureFlags)+len(over.FeatureFlags) > 0 {
+		merged.FeatureFlags = make(map[string]bool, len(c.FeatureFlags)+len(over.FeatureFlags))
+		for name, on := range c.FeatureFlags {
+			merged.FeatureFlags[name] = on
+		}
+		for name, on := range over.FeatureFlags {
+			merged.FeatureFlags[name] = on
+		}
+	}
+	return merged
+}
+
+// AgentConfigSet is an agent's effective remote config: the global config
+// with the agent's own overrides merged over it. The agent echoes Version
+// in its heartbeat once applied.
+type AgentConfigSet struct {
+	AgentID     string            `json:"agent_id"`
+	Version     int64             `json:"version"` // 0 when nothing is configured
+	Config      AgentRemoteConfig `json:"config"`
+	GeneratedAt time.Time         `json:"generated_at"`
+}
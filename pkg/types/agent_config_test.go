@@ -0,0 +1,86 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestAgentRemoteConfigValidate_Cases(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	tests := []struct {
+		name    string
+		config  AgentRemoteConfig
+		wantErr string
+	}{
+		{"empty", AgentRemoteConfig{}, ""},
+		{"all set", AgentRemoteConfig{
+			LogLevel:                str(LogLevelDebug),
+			ScheduleAlignment:       str("aligned"),
+			HeartbeatIntervalS:      num(15),
+			AssignmentPollIntervalS: num(60),
+			CommandPollIntervalS:    num(2),
+			FeatureFlags:            map[string]bool{"gzip_results": true},
+		}, ""},
+		{"unknown log level", AgentRemoteConfig{LogLevel: str("trace")}, "log_level must be"},
+		{"unknown alignment", AgentRemoteConfig{ScheduleAlignment: str("random")}, "schedule_alignment must be"},
+		{"zero heartbeat", AgentRemoteConfig{HeartbeatIntervalS: num(0)}, "heartbeat_interval_s must be at least"},
+		{"negative command poll", AgentRemoteConfig{CommandPollIntervalS: num(-5)}, "command_poll_interval_s must be at least"},
+		{"empty flag name", AgentRemoteConfig{FeatureFlags: map[string]bool{"": true}}, "feature flag names"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAgentRemoteConfigMerge_OverridesSetFields(t *testing.T) {
+	str := func(s string) *string { return &s }
+	num := func(n int) *int { return &n }
+
+	global := AgentRemoteConfig{
+		LogLevel:           str(LogLevelInfo),
+		HeartbeatIntervalS: num(30),
+		FeatureFlags:       map[string]bool{"a": true, "b": true},
+	}
+	agent := AgentRemoteConfig{
+		LogLevel:     str(LogLevelDebug),
+		FeatureFlags: map[string]bool{"b": false, "c": true},
+	}
+
+	merged := global.Merge(agent)
+
+	if *merged.LogLevel != LogLevelDebug {
+		t.Errorf("log_level = %q, want the agent override", *merged.LogLevel)
+	}
+	if merged.HeartbeatIntervalS == nil || *merged.HeartbeatIntervalS != 30 {
+		t.Errorf("heartbeat_interval_s = %v, want the global 30", merged.HeartbeatIntervalS)
+	}
+	if merged.ScheduleAlignment != nil {
+		t.Errorf("schedule_alignment = %q, want unset", *merged.ScheduleAlignment)
+	}
+	want := map[string]bool{"a": true, "b": false, "c": true}
+	if len(merged.FeatureFlags) != len(want) {
+		t.Fatalf("feature flags = %v, want %v", merged.FeatureFlags, want)
+	}
+	for name, on := range want {
+		if merged.FeatureFlags[name] != on {
+			t.Errorf("flag %s = %v, want %v", name, merged.FeatureFlags[name], on)
+		}
+	}
+	if !global.FeatureFlags["b"] {
+		t.Error("Merge modified the base config's flags")
+	}
+}
@@ -0,0 +1,201 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// =============================================================================
+// ALERT TUNABLES
+// =============================================================================
+
+// AlertTunables is every knob the alerting workers consult, with one value
+// each, plus the health score weights. All fields but Evaluator are
+// alert_config keys (the JSON name is the key) that their reader re-reads
+// every cycle or request; Evaluator is fixed at startup.
+type AlertTunables struct {
+	// Alert worker: severity escalation, resolution, incidents and rate caps
+	LatencyWarningMs          float64 `json:"escalation_latency_warning_ms"`
+	LatencyCriticalMs         float64 `json:"escalation_latency_critical_ms"`
+	PacketLossWarningPct      float64 `json:"escalation_packet_loss_warning_pct"`
+	PacketLossCriticalPct     float64 `json:"escalation_packet_loss_critical_pct"`
+	ResolutionProbeCount      int     `json:"resolution_probe_count"`
+	IncidentCreationThreshold int     `json:"incident_creation_threshold"`
+	SubnetAlertRateCap        int     `json:"subnet_alert_rate_cap"`
+	GlobalAlertRateCap        int     `json:"global_alert_rate_cap"`
+	AlertRateWindowSeconds    int     `json:"alert_rate_window_seconds"`
+
+	// Evaluator: baseline drift
+	BaselineDriftWeeks int     `json:"baseline_drift_weeks"`
+	BaselineDriftPct   float64 `json:"baseline_drift_pct"`
+	BaselineDriftMinMs float64 `json:"baseline_drift_min_ms"`
+
+	// Baseline warmup, applied in SQL by baseline_warmup_end()
+	BaselineWarmupSeconds int `json:"baseline_warmup_seconds"`
+	BaselineWarmupProbes  int `json:"baseline_warmup_probes"`
+
+	// Certificate expiry watchdog
+	CertExpiryWarningDays  int `json:"cert_expiry_warning_days"`
+	CertExpiryCriticalDays int `json:"cert_expiry_critical_days"`
+
+	// Latency asymmetry watchdog
+	LatencyAsymmetryFactor         float64 `json:"latency_asymmetry_factor"`
+	LatencyAsymmetryMinDeltaMs     float64 `json:"latency_asymmetry_min_delta_ms"`
+	LatencyAsymmetrySustainMinutes int     `json:"latency_asymmetry_sustain_minutes"`
+
+	// Ship lag watchdog
+	ShipLagAlertSeconds int `json:"ship_lag_alert_seconds"`
+
+	// Target health score weights, read per status request
+	HealthScoreWeightReachability float64 `json:"health_score_weight_reachability"`
+	HealthScoreWeightLatency      float64 `json:"health_score_weight_latency"`
+	HealthScoreWeightLoss         float64 `json:"health_score_weight_loss"`
+	HealthScoreWeightJitter       float64 `json:"health_score_weight_jitter"`
+
+	Evaluator EvaluatorThresholds `json:"evaluator"`
+}
+
+// EvaluatorThresholds are the evaluator's state thresholds. They are set in
+// code, not alert_config, so changing them takes a restart.
+type EvaluatorThresholds struct {
+	ZScoreWarning              float64 `json:"z_score_warning"`
+	ZScoreCritical             float64 `json:"z_score_critical"`
+	PacketLossWarningPct       float64 `json:"packet_loss_warning_pct"`
+	PacketLossCriticalPct      float64 `json:"packet_loss_critical_pct"`
+	ConsecutiveFailuresForDown int     `json:"consecutive_failures_for_down"`
+	ConsecutiveSuccessesForUp  int     `json:"consecutive_successes_for_up"`
+	MinSamplesForBaseline      int     `json:"min_samples_for_baseline"`
+}
+
+// alertTunable ties an alert_config key to its AlertTunables field. One of
+// intVal and floatVal is set.
+type alertTunable struct {
+	key      string
+	usedBy   string
+	intVal   *int
+	floatVal *float64
+}
+
+func (t *AlertTunables) tunables() []alertTunable {
+	const (
+		alertWorker = "alert_worker"
+		evaluator   = "evaluator"
+		warmup      = "baseline_warmup_end"
+		certExpiry  = "cert_expiry_watchdog"
+		asymmetry   = "latency_asymmetry_watchdog"
+		shipLag     = "ship_lag_watchdog"
+		healthScore = "health_score"
+	)
+	return []alertTunable{
+		{key: "escalation_latency_warning_ms", usedBy: alertWorker, floatVal: &t.LatencyWarningMs},
+		{key: "escalation_latency_critical_ms", usedBy: alertWorker, floatVal: &t.LatencyCriticalMs},
+		{key: "escalation_packet_loss_warning_pct", usedBy: alertWorker, floatVal: &t.PacketLossWarningPct},
+		{key: "escalation_packet_loss_critical_pct", usedBy: alertWorker, floatVal: &t.PacketLossCriticalPct},
+		{key: "resolution_probe_count", usedBy: alertWorker, intVal: &t.ResolutionProbeCount},
+		{key: "incident_creation_threshold", usedBy: alertWorker, intVal: &t.IncidentCreationThreshold},
+		{key: "subnet_alert_rate_cap", usedBy: alertWorker, intVal: &t.SubnetAlertRateCap},
+		{key: "global_alert_rate_cap", usedBy: alertWorker, intVal: &t.GlobalAlertRateCap},
+		{key: "alert_rate_window_seconds", usedBy: alertWorker, intVal: &t.AlertRateWindowSeconds},
+		{key: "baseline_drift_weeks", usedBy: evaluator, intVal: &t.BaselineDriftWeeks},
+		{key: "baseline_drift_pct", usedBy: evaluator, floatVal: &t.BaselineDriftPct},
+		{key: "baseline_drift_min_ms", usedBy: evaluator, floatVal: &t.BaselineDriftMinMs},
+		{key: "baseline_warmup_seconds", usedBy: warmup, intVal: &t.BaselineWarmupSeconds},
+		{key: "baseline_warmup_probes", usedBy: warmup, intVal: &t.BaselineWarmupProbes},
+		{key: "cert_expiry_warning_days", usedBy: certExpiry, intVal: &t.CertExpiryWarningDays},
+		{key: "cert_expiry_critical_days", usedBy: certExpiry, intVal: &t.CertExpiryCriticalDays},
+		{key: "latency_asymmetry_factor", usedBy: asymmetry, floatVal: &t.LatencyAsymmetryFactor},
+		{key: "latency_asymmetry_min_delta_ms", usedBy: asymmetry, floatVal: &t.LatencyAsymmetryMinDeltaMs},
+		{key: "latency_asymmetry_sustain_minutes", usedBy: asymmetry, intVal: &t.LatencyAsymmetrySustainMinutes},
+		{key: "ship_lag_alert_seconds", usedBy: shipLag, intVal: &t.ShipLagAlertSeconds},
+		{key: "health_score_weight_reachability", usedBy: healthScore, floatVal: &t.HealthScoreWeightReachability},
+		{key: "health_score_weight_latency", usedBy: healthScore, floatVal: &t.HealthScoreWeightLatency},
+		{key: "health_score_weight_loss", usedBy: healthScore, floatVal: &t.HealthScoreWeightLoss},
+		{key: "health_score_weight_jitter", usedBy: healthScore, floatVal: &t.HealthScoreWeightJitter},
+	}
+}
+
+// value returns the field's current value.
+func (a alertTunable) value() any {
+	if a.intVal != nil {
+		return *a.intVal
+	}
+	return *a.floatVal
+}
+
+// set parses a stored alert_config value into the field the way the
+// workers' GetAlertConfigInt and GetAlertConfigFloat do. Values of any
+// other type leave the field alone.
+func (a alertTunable) set(v any) {
+	switch v := v.(type) {
+	case float64:
+		if a.intVal != nil {
+			*a.intVal = int(v)
+		} else {
+			*a.floatVal = v
+		}
+	case string:
+		if a.intVal != nil {
+			fmt.Sscanf(v, "%d", a.intVal)
+		} else {
+			fmt.Sscanf(v, "%f", a.floatVal)
+		}
+	}
+}
+
+// AlertTunableKey describes one alert_config key.
+type AlertTunableKey struct {
+	Key         string     `json:"key"`
+	UsedBy      string     `json:"used_by,omitempty"` // empty when nothing reads the key
+	Value       any        `json:"value"`
+	Default     any        `json:"default,omitempty"`
+	Overridden  bool       `json:"overridden"` // set in alert_config
+	Description string     `json:"description,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+}
+
+// AlertTunablesReport is the complete alert tuning surface: the effective
+// values, the defaults they fall back to, and every alert_config key.
+type AlertTunablesReport struct {
+	Effective AlertTunables     `json:"effective"`
+	Defaults  AlertTunables     `json:"defaults"`
+	Keys      []AlertTunableKey `json:"keys"`
+}
+
+// BuildAlertTunablesReport applies the stored alert_config rows over the
+// defaults. Keys are sorted, and include rows no worker reads.
+func BuildAlertTunablesReport(defaults AlertTunables, configs []AlertConfig) AlertTunablesReport {
+	report := AlertTunablesReport{Effective: defaults, Defaults: defaults}
+
+	stored := make(map[string]AlertConfig, len(configs))
+	for _, cfg := range configs {
+		stored[cfg.Key] = cfg
+	}
+
+	defaultTunables := report.Defaults.tunables()
+	for i, t := range report.Effective.tunables() {
+		key := AlertTunableKey{Key: t.key, UsedBy: t.usedBy, Default: defaultTunables[i].value()}
+		if cfg, ok := stored[t.key]; ok {
+			t.set(cfg.Value)
+			key.Overridden = true
+			key.Description = cfg.Description
+			key.UpdatedAt = &cfg.UpdatedAt
+			delete(stored, t.key)
+		}
+		key.Value = t.value()
+		report.Keys = append(report.Keys, key)
+	}
+
+	for _, cfg := range stored {
+		report.Keys = append(report.Keys, AlertTunableKey{
+			Key:         cfg.Key,
+			Value:       cfg.Value,
+			Overridden:  true,
+			Description: cfg.Description,
+			UpdatedAt:   &cfg.UpdatedAt,
+		})
+	}
+
+	sort.Slice(report.Keys, func(i, j int) bool { return report.Keys[i].Key < report.Keys[j].Key })
+	return report
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBuildAlertTunablesReport_Overrides(t *testing.T) {
+	defaults := AlertTunables{ResolutionProbeCount: 3, LatencyWarningMs: 100, Evaluator: EvaluatorThresholds{ZScoreWarning: 3}}
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		configs        []AlertConfig
+		wantResolution int
+		wantLatency    float64
+		wantKey        string
+		wantUsedBy     string
+		wantOverridden bool
+	}{
+		{
+			name:           "no config uses defaults",
+			wantResolution: 3, wantLatency: 100,
+			wantKey: "resolution_probe_count", wantUsedBy: "alert_worker",
+		},
+		{
+			name:           "number overrides int",
+			configs:        []AlertConfig{{Key: "resolution_probe_count", Value: float64(5), UpdatedAt: updated}},
+			wantResolution: 5, wantLatency: 100,
+			wantKey: "resolution_probe_count", wantUsedBy: "alert_worker", wantOverridden: true,
+		},
+		{
+			name:           "string overrides float",
+			configs:        []AlertConfig{{Key: "escalation_latency_warning_ms", Value: "150.5", UpdatedAt: updated}},
+			wantResolution: 3, wantLatency: 150.5,
+			wantKey: "escalation_latency_warning_ms", wantUsedBy: "alert_worker", wantOverridden: true,
+		},
+		{
+			name:           "unread key listed",
+			configs:        []AlertConfig{{Key: "alert_retention_days", Value: float64(90), UpdatedAt: updated}},
+			wantResolution: 3, wantLatency: 100,
+			wantKey: "alert_retention_days", wantOverridden: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := BuildAlertTunablesReport(defaults, tt.configs)
+
+			if report.Effective.ResolutionProbeCount != tt.wantResolution || report.Effective.LatencyWarningMs != tt.wantLatency {
+				t.Errorf("Effective = resolution %d latency %v, want %d %v",
+					report.Effective.ResolutionProbeCount, report.Effective.LatencyWarningMs, tt.wantResolution, tt.wantLatency)
+			}
+			if report.Defaults != defaults {
+				t.Errorf("Defaults = %+v, want %+v", report.Defaults, defaults)
+			}
+			if report.Effective.Evaluator != defaults.Evaluator {
+				t.Errorf("Evaluator = %+v, want %+v", report.Effective.Evaluator, defaults.Evaluator)
+			}
+
+			var found *AlertTunableKey
+			for i := range report.Keys {
+				if i > 0 && report.Keys[i-1].Key >= report.Keys[i].Key {
+					t.Errorf("keys not sorted at %q", report.Keys[i].Key)
+				}
+				if report.Keys[i].Key == tt.wantKey {
+					found = &report.Keys[i]
+				}
+			}
+			if found == nil {
+				t.Fatalf("key %q missing", tt.wantKey)
+			}
+			if found.UsedBy != tt.wantUsedBy || found.Overridden != tt.wantOverridden {
+				t.Errorf("key %q = used_by %q overridden %v, want %q %v",
+					tt.wantKey, found.UsedBy, found.Overridden, tt.wantUsedBy, tt.wantOverridden)
+			}
+		})
+	}
+}
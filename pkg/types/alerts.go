@@ -99,6 +99,11 @@ type Alert struct {
 	IncidentID     *string `json:"incident_id,omitempty"`
 	CorrelationKey string  `json:"correlation_key,omitempty"` // e.g., "subnet:xxx", "target:xxx"
 
+	// Time-based escalation: the policy escalating the alert and how many
+	// of its steps have fired
+	EscalationPolicyID string `json:"escalation_policy_id,omitempty"`
+	EscalationStep     int    `json:"escalation_step,omitempty"`
+
 	// For API responses - populated by joins
 	TargetName string `json:"target_name,omitempty"`
 	AgentName  string `json:"agent_name,omitempty"`
@@ -230,6 +235,43 @@ type AlertCorrelationSummary struct {
 	BySubscriber      []AlertCorrelation `json:"by_subscriber"`
 	ByLocation        []AlertCorrelation `json:"by_location"`
 	ByRegion          []AlertCorrelation `json:"by_region"`
+
+	// ProbableCauses ranks clusters of active alerts by likely root cause.
+	ProbableCauses []ProbableCause `json:"probable_causes"`
+}
+
+// Root-cause entity types, in order of precedence when ranking causes.
+const (
+	RootCauseSubnet   = "subnet"
+	RootCauseAgent    = "agent"
+	RootCauseRegion   = "region"
+	RootCauseUpstream = "upstream" // gateway device upstream of the target
+)
+
+// CorrelationKey builds the key alerts are grouped by, e.g. "subnet:<id>".
+func CorrelationKey(entityType, id string) string {
+	return entityType + ":" + id
+}
+
+// RootCauseEntity identifies the entity a cluster of alerts has in common.
+type RootCauseEntity struct {
+	Type string `json:"type"`           // subnet, agent, region, upstream
+	ID   string `json:"id"`             // subnet/agent ID, region or gateway name
+	Name string `json:"name,omitempty"` // subnet CIDR or agent name, when known
+}
+
+// ProbableCause is a cluster of active alerts sharing an entity, scored by
+// how likely that entity is the root cause.
+type ProbableCause struct {
+	PrimaryEntity  RootCauseEntity `json:"primary_entity"`
+	CorrelationKey string          `json:"correlation_key"` // e.g. "subnet:xxx", "agent:xxx"
+	Confidence     float64         `json:"confidence"`      // 0-1
+	AlertCount     int             `json:"alert_count"`
+	TargetCount    int             `json:"target_count"`
+	AgentCount     int             `json:"agent_count"`
+	UnlinkedCount  int             `json:"unlinked_count"` // members not yet linked to an incident
+	Severity       AlertSeverity   `json:"severity"`       // most severe member
+	Alerts         []Alert         `json:"alerts"`
 }
 
 // SeverityLevel returns numeric level for comparison (higher = more severe).
@@ -266,6 +308,15 @@ const (
 	AlertTypePathChange         AlertType = "path_change"         // Routing path changed
 	AlertTypeAgentDown          AlertType = "agent_down"          // Monitoring agent offline
 	AlertTypeFleetAnomaly       AlertType = "fleet_anomaly"       // Widespread issue detected
+	AlertTypeSubnetRollup       AlertType = "subnet_rollup"       // Alert storm on a subnet, rolled up
+	AlertTypeBaselineDrift      AlertType = "baseline_drift"      // Baseline crept up over weeks
+	AlertTypePipelineHealth     AlertType = "pipeline_health"     // Canary results or evaluation stalled
+	AlertTypeServiceStatus      AlertType = "service_status"      // Subnet's service status changed in Pilot
+	AlertTypeCoverage           AlertType = "coverage"            // Too few agents reporting on a target
+	AlertTypeCertExpiry         AlertType = "cert_expiry"         // TLS certificate expiring or expired
+	AlertTypeEndpointFamily     AlertType = "endpoint_family"     // One address family of a target failing while another answers
+	AlertTypeLatencyAsymmetry   AlertType = "latency_asymmetry"   // One agent region sees a target much slower than another
+	AlertTypeShipLag            AlertType = "ship_lag"            // Agent's results reaching the control plane long after probing
 )
 
 // AlertStatus tracks the alert lifecycle.
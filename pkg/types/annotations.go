@@ -0,0 +1,58 @@
+package types
+
+import (
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// TARGET ANNOTATIONS
+// =============================================================================
+
+// AnnotationSource says where an annotation came from.
+type AnnotationSource string
+
+const (
+	// AnnotationSourceManual annotations are created through the API.
+	AnnotationSourceManual AnnotationSource = "manual"
+	// AnnotationSourceIncident annotations are derived from incidents that
+	// affected the target and can't be edited or deleted.
+	AnnotationSourceIncident AnnotationSource = "incident"
+)
+
+// MaxAnnotationTextLength caps annotation text so it fits a chart tooltip.
+const MaxAnnotationTextLength = 1000
+
+// TargetAnnotation marks a known event on a target's timeline so history
+// charts can show it next to the probe data. EndsAt is nil for a point in
+// time, or for an incident that hasn't resolved yet.
+type TargetAnnotation struct {
+	ID        string           `json:"id"`
+	TargetID  string           `json:"target_id"`
+	Source    AnnotationSource `json:"source"`
+	StartsAt  time.Time        `json:"starts_at"`
+	EndsAt    *time.Time       `json:"ends_at,omitempty"`
+	Text      string           `json:"text"`
+	CreatedBy string           `json:"created_by,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
+}
+
+// Validate checks a manual annotation before it is stored.
+func (a *TargetAnnotation) Validate() error {
+	if a.TargetID == "" {
+		return fmt.Errorf("target_id is required")
+	}
+	if a.Text == "" {
+		return fmt.Errorf("text is required")
+	}
+	if len(a.Text) > MaxAnnotationTextLength {
+		return fmt.Errorf("text must be at most %d characters", MaxAnnotationTextLength)
+	}
+	if a.StartsAt.IsZero() {
+		return fmt.Errorf("starts_at is required")
+	}
+	if a.EndsAt != nil && a.EndsAt.Before(a.StartsAt) {
+		return fmt.Errorf("ends_at must not be before starts_at")
+	}
+	return nil
+}
@@ -0,0 +1,43 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTargetAnnotationValidate_Fields(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	later := start.Add(time.Hour)
+	earlier := start.Add(-time.Hour)
+
+	tests := []struct {
+		name    string
+		a       TargetAnnotation
+		wantErr string
+	}{
+		{"point in time", TargetAnnotation{TargetID: "t", Text: "config push", StartsAt: start}, ""},
+		{"range", TargetAnnotation{TargetID: "t", Text: "maintenance", StartsAt: start, EndsAt: &later}, ""},
+		{"zero-length range", TargetAnnotation{TargetID: "t", Text: "blip", StartsAt: start, EndsAt: &start}, ""},
+		{"missing target", TargetAnnotation{Text: "x", StartsAt: start}, "target_id is required"},
+		{"missing text", TargetAnnotation{TargetID: "t", StartsAt: start}, "text is required"},
+		{"text too long", TargetAnnotation{TargetID: "t", Text: strings.Repeat("a", MaxAnnotationTextLength+1), StartsAt: start}, "at most"},
+		{"missing start", TargetAnnotation{TargetID: "t", Text: "x"}, "starts_at is required"},
+		{"ends before start", TargetAnnotation{TargetID: "t", Text: "x", StartsAt: start, EndsAt: &earlier}, "must not be before"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.a.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,41 @@
+package types
+
+import "time"
+
+// AssignmentChangeAction is what an assignment change log entry did.
+const (
+	AssignmentChangeAdd    = "add"
+	AssignmentChangeRemove = "remove"
+)
+
+// AssignmentLogEntry is one assignment added to or removed from an agent,
+// under the assignment version the change produced.
+type AssignmentLogEntry struct {
+	Version   int64     `json:"version"`
+	TargetID  string    `json:"target_id"`
+	TargetIP  string    `json:"target_ip,omitempty"` // Empty once the target is deleted
+	Action    string    `json:"action"`
+	Tier      string    `json:"tier,omitempty"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+// AssignmentDiff is the net change in an agent's assignments between two
+// versions: targets assigned after FromVersion and still assigned at
+// ToVersion, and the reverse. A target added and removed again in between
+// appears in neither.
+type AssignmentDiff struct {
+	AgentID     string `json:"agent_id"`
+	FromVersion int64  `json:"from_version"`
+	ToVersion   int64  `json:"to_version"`
+
+	// FromReported is set when FromVersion defaulted to the version the
+	// agent last reported applying.
+	FromReported bool `json:"from_reported,omitempty"`
+
+	// Added and Removed carry each target's last change in the range.
+	Added   []AssignmentLogEntry `json:"added"`
+	Removed []AssignmentLogEntry `json:"removed"`
+
+	// Changes counts the log entries in the range, churn included.
+	Changes int `json:"changes"`
+}
@@ -0,0 +1,50 @@
+package types
+
+import "time"
+
+// =============================================================================
+// AUDIT LOG
+// =============================================================================
+
+// Operator roles. Admins can read the audit log; any valid token identifies
+// the actor of a mutation.
+const (
+	OperatorRoleAdmin    = "admin"
+	OperatorRoleOperator = "operator"
+)
+
+// AuditActorAnonymous is recorded for requests without a valid operator token.
+const AuditActorAnonymous = "anonymous"
+
+// Audit results.
+const (
+	AuditResultSuccess = "success"
+	AuditResultFailure = "failure"
+)
+
+// AuditEntry is one mutating management request.
+type AuditEntry struct {
+	ID         int64     `json:"id"`
+	Time       time.Time `json:"time"`
+	Actor      string    `json:"actor"`
+	ActorRole  string    `json:"actor_role,omitempty"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	EntityType string    `json:"entity_type,omitempty"`
+	EntityID   string    `json:"entity_id,omitempty"`
+	BodySHA256 string    `json:"body_sha256,omitempty"`
+	StatusCode int       `json:"status_code"`
+	Result     string    `json:"result"`
+	RemoteAddr string    `json:"remote_addr,omitempty"`
+	DurationMs int       `json:"duration_ms"`
+}
+
+// AuditFilter narrows an audit log query. Zero values match everything.
+type AuditFilter struct {
+	Actor      string
+	EntityType string
+	EntityID   string
+	Since      *time.Time
+	Until      *time.Time
+	Limit      int
+}
@@ -0,0 +1,40 @@
+package types
+
+import "time"
+
+// =============================================================================
+// TARGET AVAILABILITY
+// =============================================================================
+
+// TargetAvailability holds a target's availability SLIs over several
+// windows, computed together from hourly aggregates. Every window ends at
+// the last complete hour. SuccessCriteria is the expected outcome's
+// definition of success the ratios were computed with.
+type TargetAvailability struct {
+	TargetID        string               `json:"target_id"`
+	SuccessCriteria string               `json:"success_criteria"`
+	GeneratedAt     time.Time            `json:"generated_at"`
+	Windows         []AvailabilityWindow `json:"windows"`
+}
+
+// AvailabilityWindow is one window's SLIs. Ratios are nil when the window
+// has no probes. Success ratios follow the success criteria, so under SLO
+// criteria replies outside the SLO are not successes; reachable ratios
+// count every reply. A failure is a run of consecutive failed hours (hours
+// in which too few probes succeeded); MTBFHours is the observed healthy
+// hours per failure and is nil when there were no failures.
+type AvailabilityWindow struct {
+	Window string    `json:"window"`
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+
+	Probes                 int64    `json:"probes"`
+	SuccessRatio           *float64 `json:"success_ratio"`
+	ReachableRatio         *float64 `json:"reachable_ratio"`
+	InMarketProbes         int64    `json:"in_market_probes"`
+	InMarketSuccessRatio   *float64 `json:"in_market_success_ratio"`
+	InMarketReachableRatio *float64 `json:"in_market_reachable_ratio"`
+
+	Failures  int      `json:"failures"`
+	MTBFHours *float64 `json:"mtbf_hours"`
+}
@@ -0,0 +1,58 @@
+package types
+
+import "time"
+
+// =============================================================================
+// SUBNET COVERAGE GAPS
+// =============================================================================
+
+// Per-target coverage statuses, worst first.
+const (
+	// CoverageStatusNoCoverage: no agent reported on the target in the window.
+	CoverageStatusNoCoverage = "no_coverage"
+	// CoverageStatusInMarketGap: agents report, but none in the target's region.
+	CoverageStatusInMarketGap = "in_market_gap"
+	// CoverageStatusUnknownMarket: agents report, but the target has no
+	// region so in-market coverage can't be judged.
+	CoverageStatusUnknownMarket = "unknown_market"
+	// CoverageStatusCovered: at least one in-market agent reports.
+	CoverageStatusCovered = "covered"
+)
+
+// TargetCoverage is the current monitoring coverage of one target.
+type TargetCoverage struct {
+	TargetID string  `json:"target_id"`
+	IP       string  `json:"ip"`
+	Tier     string  `json:"tier"`
+	Region   *string `json:"region,omitempty"` // target region, else subnet region
+
+	// From target_assignments
+	AssignedAgents         int `json:"assigned_agents"`
+	AssignedInMarketAgents int `json:"assigned_in_market_agents"`
+
+	// Distinct agents with probe results in the window
+	ReportingAgents         int        `json:"reporting_agents"`
+	ReportingInMarketAgents int        `json:"reporting_in_market_agents"`
+	LastResultAt            *time.Time `json:"last_result_at,omitempty"`
+
+	Status string `json:"status"`
+}
+
+// SubnetCoverage summarises coverage across a subnet's active targets.
+type SubnetCoverage struct {
+	SubnetID    string    `json:"subnet_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Window      string    `json:"window"`
+
+	ActiveTargets int `json:"active_targets"`
+	Covered       int `json:"covered"`
+	InMarketGaps  int `json:"in_market_gaps"`
+	CoverageGaps  int `json:"coverage_gaps"`
+	UnknownMarket int `json:"unknown_market"`
+
+	// InMarketCoveragePct is the share of active targets with a reporting
+	// in-market agent (100 when the subnet has no active targets).
+	InMarketCoveragePct float64 `json:"in_market_coverage_pct"`
+
+	Targets []TargetCoverage `json:"targets"`
+}
@@ -0,0 +1,195 @@
+package types
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"time"
+)
+
+// =============================================================================
+// TARGET ENDPOINTS
+// =============================================================================
+
+// Address families of a probe endpoint.
+const (
+	AddressFamilyIPv4 = "ipv4"
+	AddressFamilyIPv6 = "ipv6"
+)
+
+// EndpointDegradedSuccessRate is the success rate below which an endpoint
+// that still answers some probes is reported degraded rather than up.
+const EndpointDegradedSuccessRate = 0.9
+
+// PrimaryEndpointID stands in for a target's primary IP wherever endpoints
+// are listed together, since the primary has no target_endpoints row.
+const PrimaryEndpointID = "primary"
+
+// TargetEndpoint is an additional address or port probed for a target, such
+// as the IPv6 address of a dual-stacked host. Agents probe it alongside the
+// target's primary IP and report it separately.
+type TargetEndpoint struct {
+	ID       string `json:"id"`
+	TargetID string `json:"target_id"`
+	IP       string `json:"ip"`
+	Family   string `json:"family"`
+
+	// Port overrides the probe port for port-based probe types (e.g.
+	// tls_cert); nil uses the target's probe params.
+	Port *int `json:"port,omitempty"`
+
+	Label     string    `json:"label,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AddressFamily returns the family of ip, or "" if it doesn't parse.
+func AddressFamily(ip string) string {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return ""
+	}
+	if addr.Unmap().Is4() {
+		return AddressFamilyIPv4
+	}
+	return AddressFamilyIPv6
+}
+
+// Validate checks the endpoint's address and port.
+func (e *TargetEndpoint) Validate() error {
+	if AddressFamily(e.IP) == "" {
+		return fmt.Errorf("invalid endpoint ip: %q", e.IP)
+	}
+	if e.Port != nil && (*e.Port < 1 || *e.Port > 65535) {
+		return fmt.Errorf("endpoint port must be between 1 and 65535")
+	}
+	return nil
+}
+
+// EndpointState summarizes one endpoint's recent results.
+type EndpointState string
+
+const (
+	EndpointStateUp       EndpointState = "up"
+	EndpointStateDegraded EndpointState = "degraded"
+	EndpointStateDown     EndpointState = "down"
+	EndpointStateUnknown  EndpointState = "unknown" // No results in the window
+)
+
+// EndpointStatus is an endpoint's state over a recent window. The target's
+// primary IP is included with ID PrimaryEndpointID.
+type EndpointStatus struct {
+	ID     string `json:"id"`
+	IP     string `json:"ip"`
+	Family string `json:"family"`
+	Port   *int   `json:"port,omitempty"`
+	Label  string `json:"label,omitempty"`
+
+	Probes       int        `json:"probes"`
+	Successes    int        `json:"successes"`
+	AgentCount   int        `json:"agent_count"`
+	AvgLatencyMs *float64   `json:"avg_latency_ms,omitempty"`
+	AvgLossPct   *float64   `json:"avg_packet_loss_pct,omitempty"`
+	LastProbeAt  *time.Time `json:"last_probe_at,omitempty"`
+
+	State EndpointState `json:"state"`
+}
+
+// ClassifyEndpoint derives an endpoint's state from its probe counts.
+func ClassifyEndpoint(probes, successes int) EndpointState {
+	switch {
+	case probes == 0:
+		return EndpointStateUnknown
+	case successes == 0:
+		return EndpointStateDown
+	case float64(successes)/float64(probes) < EndpointDegradedSuccessRate:
+		return EndpointStateDegraded
+	default:
+		return EndpointStateUp
+	}
+}
+
+// FamilyStatus rolls a target's endpoints up per address family.
+type FamilyStatus struct {
+	Family    string        `json:"family"`
+	Endpoints int           `json:"endpoints"`
+	Probes    int           `json:"probes"`
+	Successes int           `json:"successes"`
+	State     EndpointState `json:"state"`
+}
+
+// TargetEndpointStatus breaks a target's reachability down by endpoint and
+// address family.
+type TargetEndpointStatus struct {
+	TargetID  string           `json:"target_id"`
+	Window    string           `json:"window"`
+	Endpoints []EndpointStatus `json:"endpoints"`
+	Families  []FamilyStatus   `json:"families"`
+
+	// FailingFamilies lists families that are down while another family
+	// of the same target is answering.
+	FailingFamilies []string `json:"failing_families,omitempty"`
+}
+
+// SummarizeFamilies rolls endpoint statuses up per family, sorted by
+// family. A family is as healthy as its best endpoint with data.
+func SummarizeFamilies(endpoints []EndpointStatus) []FamilyStatus {
+	byFamily := make(map[string]*FamilyStatus)
+	for _, e := range endpoints {
+		f := byFamily[e.Family]
+		if f == nil {
+			f = &FamilyStatus{Family: e.Family, State: EndpointStateUnknown}
+			byFamily[e.Family] = f
+		}
+		f.Endpoints++
+		f.Probes += e.Probes
+		f.Successes += e.Successes
+		if endpointRank(e.State) > endpointRank(f.State) {
+			f.State = e.State
+		}
+	}
+
+	out := make([]FamilyStatus, 0, len(byFamily))
+	for _, f := range byFamily {
+		out = append(out, *f)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Family < out[j].Family })
+	return out
+}
+
+// FailingFamilies returns the families that are down while at least one
+// other family is up or degraded. When every family is down the target
+// itself is down, which availability alerts already cover.
+func FailingFamilies(families []FamilyStatus) []string {
+	answering := false
+	for _, f := range families {
+		if f.State == EndpointStateUp || f.State == EndpointStateDegraded {
+			answering = true
+			break
+		}
+	}
+	if !answering {
+		return nil
+	}
+
+	var failing []string
+	for _, f := range families {
+		if f.State == EndpointStateDown {
+			failing = append(failing, f.Family)
+		}
+	}
+	return failing
+}
+
+// endpointRank orders states from least to most healthy.
+func endpointRank(s EndpointState) int {
+	switch s {
+	case EndpointStateDown:
+		return 1
+	case EndpointStateDegraded:
+		return 2
+	case EndpointStateUp:
+		return 3
+	default:
+		return 0
+	}
+}
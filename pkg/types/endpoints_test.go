@@ -0,0 +1,113 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAddressFamily_Parse(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want string
+	}{
+		{ip: "192.0.2.1", want: AddressFamilyIPv4},
+		{ip: "2001:db8::1", want: AddressFamilyIPv6},
+		{ip: "::ffff:192.0.2.1", want: AddressFamilyIPv4},
+		{ip: "192.0.2.0/24", want: ""},
+		{ip: "not-an-ip", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ip, func(t *testing.T) {
+			if got := AddressFamily(tt.ip); got != tt.want {
+				t.Errorf("AddressFamily(%q) = %q, want %q", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyEndpoint_Thresholds(t *testing.T) {
+	tests := []struct {
+		name      string
+		probes    int
+		successes int
+		want      EndpointState
+	}{
+		{name: "no data", probes: 0, successes: 0, want: EndpointStateUnknown},
+		{name: "all failing", probes: 10, successes: 0, want: EndpointStateDown},
+		{name: "mostly failing", probes: 10, successes: 5, want: EndpointStateDegraded},
+		{name: "at threshold", probes: 10, successes: 9, want: EndpointStateUp},
+		{name: "all succeeding", probes: 10, successes: 10, want: EndpointStateUp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ClassifyEndpoint(tt.probes, tt.successes); got != tt.want {
+				t.Errorf("ClassifyEndpoint(%d, %d) = %s, want %s", tt.probes, tt.successes, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailingFamilies_OneFamilyDown(t *testing.T) {
+	tests := []struct {
+		name      string
+		endpoints []EndpointStatus
+		want      []string
+	}{
+		{
+			name: "both up",
+			endpoints: []EndpointStatus{
+				{Family: AddressFamilyIPv4, State: EndpointStateUp},
+				{Family: AddressFamilyIPv6, State: EndpointStateUp},
+			},
+		},
+		{
+			name: "v6 down while v4 up",
+			endpoints: []EndpointStatus{
+				{Family: AddressFamilyIPv4, State: EndpointStateUp},
+				{Family: AddressFamilyIPv6, State: EndpointStateDown},
+			},
+			want: []string{AddressFamilyIPv6},
+		},
+		{
+			name: "v4 down while v6 degraded",
+			endpoints: []EndpointStatus{
+				{Family: AddressFamilyIPv4, State: EndpointStateDown},
+				{Family: AddressFamilyIPv6, State: EndpointStateDegraded},
+			},
+			want: []string{AddressFamilyIPv4},
+		},
+		{
+			name: "both down",
+			endpoints: []EndpointStatus{
+				{Family: AddressFamilyIPv4, State: EndpointStateDown},
+				{Family: AddressFamilyIPv6, State: EndpointStateDown},
+			},
+		},
+		{
+			name: "one v6 endpoint still answering",
+			endpoints: []EndpointStatus{
+				{Family: AddressFamilyIPv4, State: EndpointStateUp},
+				{Family: AddressFamilyIPv6, State: EndpointStateDown},
+				{Family: AddressFamilyIPv6, State: EndpointStateUp},
+			},
+		},
+		{
+			name: "v6 without data",
+			endpoints: []EndpointStatus{
+				{Family: AddressFamilyIPv4, State: EndpointStateUp},
+				{Family: AddressFamilyIPv6, State: EndpointStateUnknown},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := FailingFamilies(SummarizeFamilies(tt.endpoints))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FailingFamilies() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package types
+
+import (
+	"fmt"
+	"net/mail"
+	"slices"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// ALERT ESCALATION POLICIES
+// =============================================================================
+
+// MaxEscalationSteps caps the steps in one policy.
+const MaxEscalationSteps = 10
+
+// EscalationPolicy is an on-call ladder for alerts nobody has acknowledged.
+// Each step fires once an alert has gone unacknowledged for its duration,
+// raising the alert's severity and notifying again, optionally somewhere
+// new. Acknowledging or resolving the alert stops the ladder.
+//
+// A policy covers alerts on targets in its tiers and in its regions; an
+// empty list matches any. When several enabled policies cover an alert the
+// most specific wins, then the first by name. The policy that fired an
+// alert's first step keeps it for the remaining steps.
+type EscalationPolicy struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Enabled     bool   `json:"enabled"`
+
+	Tiers   []string `json:"tiers,omitempty"`
+	Regions []string `json:"regions,omitempty"`
+
+	// Steps in the order they fire, by increasing AfterMinutes
+	Steps []EscalationStep `json:"steps"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EscalationStep is one rung of the ladder.
+type EscalationStep struct {
+	// AfterMinutes is how long after detection the alert must still be
+	// unacknowledged for the step to fire.
+	AfterMinutes int `json:"after_minutes"`
+
+	// Severity raises the alert to at least this severity. Empty keeps it.
+	Severity AlertSeverity `json:"severity,omitempty"`
+
+	// Recipients are the email addresses notified. Empty notifies the
+	// usual recipients for the alert's severity.
+	Recipients []string `json:"recipients,omitempty"`
+}
+
+// Validate checks the policy has a name and a well-formed ladder.
+func (p *EscalationPolicy) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(p.Steps) == 0 {
+		return fmt.Errorf("at least one step is required")
+	}
+	if len(p.Steps) > MaxEscalationSteps {
+		return fmt.Errorf("at most %d steps are allowed", MaxEscalationSteps)
+	}
+	for i, step := range p.Steps {
+		n := i + 1
+		if step.AfterMinutes <= 0 {
+			return fmt.Errorf("step %d: after_minutes must be positive", n)
+		}
+		if i > 0 && step.AfterMinutes <= p.Steps[i-1].AfterMinutes {
+			return fmt.Errorf("step %d: after_minutes must be later than step %d", n, i)
+		}
+		if step.Severity != "" && step.Severity.Level() == 0 {
+			return fmt.Errorf("step %d: invalid severity %q", n, step.Severity)
+		}
+		for _, addr := range step.Recipients {
+			if _, err := mail.ParseAddress(addr); err != nil {
+				return fmt.Errorf("step %d: invalid recipient %q", n, addr)
+			}
+		}
+	}
+	return nil
+}
+
+// Matches reports whether the policy covers an alert.
+func (p *EscalationPolicy) Matches(alert *Alert) bool {
+	if len(p.Tiers) > 0 && !slices.Contains(p.Tiers, alert.TargetTier) {
+		return false
+	}
+	if len(p.Regions) > 0 && !slices.Contains(p.Regions, alert.Region) {
+		return false
+	}
+	return true
+}
+
+// specificity ranks policies covering the same alert: scoped by tier and
+// region, then by either, then catch-all.
+func (p *EscalationPolicy) specificity() int {
+	n := 0
+	if len(p.Tiers) > 0 {
+		n++
+	}
+	if len(p.Regions) > 0 {
+		n++
+	}
+	return n
+}
+
+// SelectEscalationPolicy returns the policy that covers an alert, or nil.
+// Policies must be enabled and ordered by name.
+func SelectEscalationPolicy(policies []EscalationPolicy, alert *Alert) *EscalationPolicy {
+	var best *EscalationPolicy
+	for i := range policies {
+		p := &policies[i]
+		if p.Matches(alert) && (best == nil || p.specificity() > best.specificity()) {
+			best = p
+		}
+	}
+	return best
+}
+
+// DueStep returns the index of the latest step due for an alert
+// unacknowledged for age that has already fired fired steps. Steps that
+// came due together, say while the control plane was down, fire as one.
+func (p *EscalationPolicy) DueStep(age time.Duration, fired int) (int, bool) {
+	due := -1
+	for i := fired; i < len(p.Steps); i++ {
+		if age < time.Duration(p.Steps[i].AfterMinutes)*time.Minute {
+			break
+		}
+		due = i
+	}
+	return due, due >= 0
+}
+
+// EscalatedSeverity is the severity after a step fires: the step's if it is
+// higher, otherwise the current one. Steps never lower severity.
+func (s EscalationStep) EscalatedSeverity(current AlertSeverity) AlertSeverity {
+	if s.Severity.Level() > current.Level() {
+		return s.Severity
+	}
+	return current
+}
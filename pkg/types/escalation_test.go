@@ -0,0 +1,155 @@
+package types
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestEscalationPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  EscalationPolicy
+		wantErr string
+	}{
+		{
+			name: "valid ladder",
+			policy: EscalationPolicy{Name: "vip", Steps: []EscalationStep{
+				{AfterMinutes: 15},
+				{AfterMinutes: 30, Severity: AlertSeverityCritical, Recipients: []string{"noc@example.com"}},
+			}},
+		},
+		{
+			name:    "missing name",
+			policy:  EscalationPolicy{Name: " ", Steps: []EscalationStep{{AfterMinutes: 5}}},
+			wantErr: "name is required",
+		},
+		{
+			name:    "no steps",
+			policy:  EscalationPolicy{Name: "vip"},
+			wantErr: "at least one step",
+		},
+		{
+			name:    "non-positive minutes",
+			policy:  EscalationPolicy{Name: "vip", Steps: []EscalationStep{{AfterMinutes: 0}}},
+			wantErr: "step 1: after_minutes must be positive",
+		},
+		{
+			name: "minutes not increasing",
+			policy: EscalationPolicy{Name: "vip", Steps: []EscalationStep{
+				{AfterMinutes: 30}, {AfterMinutes: 30},
+			}},
+			wantErr: "step 2: after_minutes must be later than step 1",
+		},
+		{
+			name:    "unknown severity",
+			policy:  EscalationPolicy{Name: "vip", Steps: []EscalationStep{{AfterMinutes: 5, Severity: "urgent"}}},
+			wantErr: `step 1: invalid severity "urgent"`,
+		},
+		{
+			name:    "bad recipient",
+			policy:  EscalationPolicy{Name: "vip", Steps: []EscalationStep{{AfterMinutes: 5, Recipients: []string{"noc"}}}},
+			wantErr: `step 1: invalid recipient "noc"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.policy.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSelectEscalationPolicy_Specificity(t *testing.T) {
+	policies := []EscalationPolicy{
+		{Name: "a-catch-all"},
+		{Name: "b-ord", Regions: []string{"ord"}},
+		{Name: "c-vip", Tiers: []string{"vip"}},
+		{Name: "d-vip-ord", Tiers: []string{"vip"}, Regions: []string{"ord"}},
+	}
+
+	tests := []struct {
+		name  string
+		alert Alert
+		want  string
+	}{
+		{name: "tier and region", alert: Alert{TargetTier: "vip", Region: "ord"}, want: "d-vip-ord"},
+		{name: "tier only, first by name among equals", alert: Alert{TargetTier: "vip", Region: "dfw"}, want: "c-vip"},
+		{name: "region only", alert: Alert{TargetTier: "standard", Region: "ord"}, want: "b-ord"},
+		{name: "nothing specific", alert: Alert{TargetTier: "standard", Region: "dfw"}, want: "a-catch-all"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SelectEscalationPolicy(policies, &tt.alert)
+			if got == nil || got.Name != tt.want {
+				t.Fatalf("SelectEscalationPolicy() = %v, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if got := SelectEscalationPolicy(policies[2:3], &Alert{TargetTier: "standard"}); got != nil {
+		t.Errorf("SelectEscalationPolicy() = %s, want nil", got.Name)
+	}
+}
+
+func TestEscalationPolicy_DueStep(t *testing.T) {
+	p := EscalationPolicy{Steps: []EscalationStep{
+		{AfterMinutes: 15}, {AfterMinutes: 30}, {AfterMinutes: 60},
+	}}
+
+	tests := []struct {
+		name   string
+		age    time.Duration
+		fired  int
+		want   int
+		wantOK bool
+	}{
+		{name: "too early", age: 10 * time.Minute, want: -1},
+		{name: "first step due", age: 15 * time.Minute, want: 0, wantOK: true},
+		{name: "first already fired", age: 20 * time.Minute, fired: 1, want: -1},
+		{name: "second due", age: 45 * time.Minute, fired: 1, want: 1, wantOK: true},
+		{name: "missed steps fire as one", age: 2 * time.Hour, want: 2, wantOK: true},
+		{name: "ladder exhausted", age: 2 * time.Hour, fired: 3, want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := p.DueStep(tt.age, tt.fired)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("DueStep(%v, %d) = %d, %v, want %d, %v", tt.age, tt.fired, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestEscalationStep_EscalatedSeverity(t *testing.T) {
+	tests := []struct {
+		name    string
+		step    AlertSeverity
+		current AlertSeverity
+		want    AlertSeverity
+	}{
+		{name: "raises", step: AlertSeverityCritical, current: AlertSeverityWarning, want: AlertSeverityCritical},
+		{name: "never lowers", step: AlertSeverityInfo, current: AlertSeverityWarning, want: AlertSeverityWarning},
+		{name: "empty keeps", current: AlertSeverityInfo, want: AlertSeverityInfo},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EscalationStep{Severity: tt.step}.EscalatedSeverity(tt.current)
+			if got != tt.want {
+				t.Errorf("EscalatedSeverity(%s) = %s, want %s", tt.current, got, tt.want)
+			}
+		})
+	}
+}
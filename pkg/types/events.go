@@ -0,0 +1,104 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// EVENT STREAM (OUTBOX)
+// =============================================================================
+
+// Event types published to the outbox.
+const (
+	EventAlertCreated       = "alert.created"
+	EventAlertResolved      = "alert.resolved"
+	EventIncidentCreated    = "incident.created"
+	EventIncidentResolved   = "incident.resolved"
+	EventTargetStateChanged = "target.state_changed"
+)
+
+// EventTypes lists every event type, for validating consumer filters.
+var EventTypes = []string{
+	EventAlertCreated,
+	EventAlertResolved,
+	EventIncidentCreated,
+	EventIncidentResolved,
+	EventTargetStateChanged,
+}
+
+// Event is a domain event from the outbox. Seq orders the stream and is the
+// offset consumers resume from; it increases but may have gaps.
+type Event struct {
+	Seq        int64           `json:"seq"`
+	Type       string          `json:"type"`
+	EntityID   string          `json:"entity_id"` // alert, incident or target ID
+	Payload    json.RawMessage `json:"payload"`
+	OccurredAt time.Time       `json:"occurred_at"`
+}
+
+// Event consumer kinds.
+const (
+	EventConsumerWebhook = "webhook" // POSTs batches of events to a URL
+	EventConsumerKafka   = "kafka"   // publishes events to a Kafka topic
+)
+
+// kafkaTopicPattern matches the names Kafka accepts for a topic.
+var kafkaTopicPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{1,249}$`)
+
+// EventConsumer is a push consumer of the event stream. The dispatcher
+// delivers events after LastSeq in order and advances LastSeq once the
+// consumer accepts them.
+type EventConsumer struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	URL        string   `json:"url"`         // webhook URL, or topic for kafka
+	EventTypes []string `json:"event_types"` // empty = every type
+	Enabled    bool     `json:"enabled"`
+
+	LastSeq             int64      `json:"last_seq"`
+	LastDeliveredAt     *time.Time `json:"last_delivered_at,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	NextAttemptAt       *time.Time `json:"next_attempt_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks the consumer's name, kind, URL (or topic) and event type
+// filter.
+func (c *EventConsumer) Validate() error {
+	if strings.TrimSpace(c.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+	switch c.Kind {
+	case EventConsumerWebhook:
+		u, err := url.Parse(c.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("url must be an http or https URL")
+		}
+	case EventConsumerKafka:
+		if !kafkaTopicPattern.MatchString(c.URL) {
+			return fmt.Errorf("url must be a kafka topic name")
+		}
+	default:
+		return fmt.Errorf("kind must be %s or %s", EventConsumerWebhook, EventConsumerKafka)
+	}
+	for _, t := range c.EventTypes {
+		if !slices.Contains(EventTypes, t) {
+			return fmt.Errorf("unknown event type %q", t)
+		}
+	}
+	return nil
+}
+
+// Wants reports whether the consumer subscribes to an event type.
+func (c *EventConsumer) Wants(eventType string) bool {
+	return len(c.EventTypes) == 0 || slices.Contains(c.EventTypes, eventType)
+}
@@ -0,0 +1,68 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEventConsumerValidate_Cases(t *testing.T) {
+	valid := func() EventConsumer {
+		return EventConsumer{Name: "datalake", Kind: EventConsumerWebhook, URL: "https://ingest.example.com/events"}
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(c *EventConsumer)
+		wantErr string
+	}{
+		{"valid", func(c *EventConsumer) {}, ""},
+		{"valid with filter", func(c *EventConsumer) { c.EventTypes = []string{EventAlertCreated, EventIncidentResolved} }, ""},
+		{"missing name", func(c *EventConsumer) { c.Name = "  " }, "name is required"},
+		{"unknown kind", func(c *EventConsumer) { c.Kind = "sqs" }, "kind must be"},
+		{"kafka topic", func(c *EventConsumer) { c.Kind, c.URL = EventConsumerKafka, "icmpmon.events" }, ""},
+		{"kafka topic with slash", func(c *EventConsumer) { c.Kind, c.URL = EventConsumerKafka, "icmpmon/events" }, "kafka topic"},
+		{"kafka url instead of topic", func(c *EventConsumer) { c.Kind = EventConsumerKafka }, "kafka topic"},
+		{"relative url", func(c *EventConsumer) { c.URL = "/events" }, "url must be"},
+		{"non-http url", func(c *EventConsumer) { c.URL = "ftp://example.com/events" }, "url must be"},
+		{"unknown event type", func(c *EventConsumer) { c.EventTypes = []string{"alert.deleted"} }, "unknown event type"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := valid()
+			tt.mutate(&c)
+			err := c.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestEventConsumerWants_Filter(t *testing.T) {
+	tests := []struct {
+		name       string
+		eventTypes []string
+		eventType  string
+		want       bool
+	}{
+		{"no filter takes everything", nil, EventTargetStateChanged, true},
+		{"listed type", []string{EventAlertCreated, EventAlertResolved}, EventAlertResolved, true},
+		{"unlisted type", []string{EventAlertCreated}, EventIncidentCreated, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := EventConsumer{EventTypes: tt.eventTypes}
+			if got := c.Wants(tt.eventType); got != tt.want {
+				t.Errorf("Wants(%q) = %v, want %v", tt.eventType, got, tt.want)
+			}
+		})
+	}
+}
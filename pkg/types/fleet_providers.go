@@ -0,0 +1,33 @@
+package types
+
+import "time"
+
+// ProviderUnknown groups agents with no provider set.
+const ProviderUnknown = "unknown"
+
+// ProviderHealth rolls up agent reliability and observed probe performance
+// for every agent hosted with one provider.
+type ProviderHealth struct {
+	Provider     string `json:"provider"`
+	Agents       int    `json:"agents"`
+	OnlineAgents int    `json:"online_agents"`
+
+	// UptimePct is the share of minutes in the window, since each agent was
+	// created, in which the agent heartbeated.
+	UptimePct     *float64 `json:"uptime_pct,omitempty"`
+	AvgCPUPercent *float64 `json:"avg_cpu_percent,omitempty"`
+	AvgMemoryMB   *float64 `json:"avg_memory_mb,omitempty"`
+
+	// Probe results from the provider's agents across all their targets.
+	ProbeCount       int64    `json:"probe_count"`
+	SuccessRatePct   *float64 `json:"success_rate_pct,omitempty"`
+	AvgLatencyMs     *float64 `json:"avg_latency_ms,omitempty"`
+	AvgPacketLossPct *float64 `json:"avg_packet_loss_pct,omitempty"`
+}
+
+// ProviderHealthReport compares providers over a window.
+type ProviderHealthReport struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Window      string           `json:"window"`
+	Providers   []ProviderHealth `json:"providers"`
+}
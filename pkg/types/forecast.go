@@ -0,0 +1,51 @@
+package types
+
+import "time"
+
+// =============================================================================
+// LATENCY FORECAST
+// =============================================================================
+
+// Forecast trend directions. A trend is only rising or falling when the
+// fitted slope is statistically distinguishable from zero.
+const (
+	ForecastTrendRising           = "rising"
+	ForecastTrendFalling          = "falling"
+	ForecastTrendFlat             = "flat"
+	ForecastTrendInsufficientData = "insufficient_data"
+)
+
+// LatencyForecast projects a target's latency from its hourly history, so
+// slow degradations can be flagged before they cross the alert threshold.
+type LatencyForecast struct {
+	TargetID    string    `json:"target_id"`
+	GeneratedAt time.Time `json:"generated_at"`
+	Model       string    `json:"model"`
+	Lookback    string    `json:"lookback"`
+	Horizon     string    `json:"horizon"`
+	Samples     int       `json:"samples"`
+
+	// Fitted trend
+	Trend          string  `json:"trend"`
+	SlopeMsPerHour float64 `json:"slope_ms_per_hour"`
+	RSquared       float64 `json:"r_squared"`
+	CurrentMs      float64 `json:"current_ms"` // fitted value at the latest sample
+
+	// Threshold projection. Breached means the fitted latency is already
+	// over the threshold; BreachProjected means it will cross within the
+	// horizon at the current slope.
+	ThresholdMs     float64    `json:"threshold_ms"`
+	Breached        bool       `json:"breached"`
+	BreachProjected bool       `json:"breach_projected"`
+	BreachAt        *time.Time `json:"breach_at,omitempty"`
+
+	Points []ForecastPoint `json:"points"`
+}
+
+// ForecastPoint is one projected value with its prediction interval.
+type ForecastPoint struct {
+	Time        time.Time `json:"time"`
+	PredictedMs float64   `json:"predicted_ms"`
+	LowerMs     float64   `json:"lower_ms"`
+	UpperMs     float64   `json:"upper_ms"`
+}
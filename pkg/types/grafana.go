@@ -0,0 +1,280 @@
+// Package types - Grafana JSON data source contract.
+//
+// Grafana's JSON data source (and Infinity in its JSON backend mode) talks
+// to a server through three POSTs:
+//
+//   - search: names a panel or template variable can pick from
+//   - query: one time series per panel target over the dashboard's range
+//   - annotations: events drawn across the dashboard's panels
+//
+// A query target's name is a metric; its payload narrows and groups the
+// data the same way a MetricsQuery does.
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// QUERY
+// =============================================================================
+
+// GrafanaRange is a dashboard's time range.
+type GrafanaRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// GrafanaQueryRequest is the body of a Grafana query.
+type GrafanaQueryRequest struct {
+	Range         GrafanaRange    `json:"range"`
+	IntervalMs    int64           `json:"intervalMs,omitempty"`
+	MaxDataPoints int             `json:"maxDataPoints,omitempty"`
+	Targets       []GrafanaTarget `json:"targets"`
+}
+
+// GrafanaTarget is one query on a panel. Target is the metric name.
+type GrafanaTarget struct {
+	RefID   string                `json:"refId"`
+	Target  string                `json:"target"`
+	Type    string                `json:"type,omitempty"` // Only "timeserie" is supported
+	Hide    bool                  `json:"hide,omitempty"`
+	Payload *GrafanaTargetPayload `json:"payload,omitempty"`
+
+	// Data is where older plugin versions put the payload.
+	Data *GrafanaTargetPayload `json:"data,omitempty"`
+}
+
+// GrafanaTargetPayload narrows and groups a panel target. The fields mean
+// what they do in MetricsQuery.
+type GrafanaTargetPayload struct {
+	AgentFilter  *AgentFilter  `json:"agent_filter,omitempty"`
+	TargetFilter *TargetFilter `json:"target_filter,omitempty"`
+	GroupBy      []string      `json:"group_by,omitempty"`
+}
+
+// UnmarshalJSON also accepts the payload as a JSON string, which is how
+// the plugin sends it when edited as raw text. An empty string is no
+// payload.
+func (p *GrafanaTargetPayload) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		if strings.TrimSpace(text) == "" {
+			*p = GrafanaTargetPayload{}
+			return nil
+		}
+		data = []byte(text)
+	}
+	type plain GrafanaTargetPayload
+	return json.Unmarshal(data, (*plain)(p))
+}
+
+// GrafanaTimeSeries is one series in a query response. Each datapoint is
+// [value, unix milliseconds].
+type GrafanaTimeSeries struct {
+	Target     string       `json:"target"`
+	Datapoints [][2]float64 `json:"datapoints"`
+}
+
+// MetricsQuery maps a panel target onto a metrics query over the request's
+// range. Grafana's interval becomes the bucket, rounded up to whole
+// minutes since the finest aggregate is 5 minutes anyway.
+func (r *GrafanaQueryRequest) MetricsQuery(t GrafanaTarget) *MetricsQuery {
+	from, to := r.Range.From, r.Range.To
+	q := &MetricsQuery{
+		TimeRange: TimeRange{Start: &from, End: &to},
+		Metrics:   []string{t.Target},
+		GroupBy:   []string{"time"},
+	}
+	if r.IntervalMs > 0 {
+		minutes := math.Ceil(float64(r.IntervalMs) / float64(time.Minute/time.Millisecond))
+		q.Bucket = fmt.Sprintf("%dm", int64(minutes))
+	}
+
+	payload := t.Payload
+	if payload == nil {
+		payload = t.Data
+	}
+	if payload != nil {
+		q.AgentFilter = payload.AgentFilter
+		q.TargetFilter = payload.TargetFilter
+		for _, g := range payload.GroupBy {
+			if g != "time" {
+				q.GroupBy = append(q.GroupBy, g)
+			}
+		}
+	}
+	return q
+}
+
+// GrafanaSeries turns a metrics query result into Grafana series for one
+// metric, named after the metric and the series' group labels and sorted
+// by name. Points without the metric are left out.
+func GrafanaSeries(metric string, result *MetricsQueryResult) []GrafanaTimeSeries {
+	series := make([]GrafanaTimeSeries, 0, len(result.Series))
+	for _, s := range result.Series {
+		ts := GrafanaTimeSeries{
+			Target:     grafanaSeriesName(metric, s),
+			Datapoints: make([][2]float64, 0, len(s.Points)),
+		}
+		for _, p := range s.Points {
+			if v, ok := p.Value(metric); ok {
+				ts.Datapoints = append(ts.Datapoints, [2]float64{v, float64(p.Time.UnixMilli())})
+			}
+		}
+		series = append(series, ts)
+	}
+	sort.Slice(series, func(i, j int) bool { return series[i].Target < series[j].Target })
+	return series
+}
+
+// grafanaSeriesName labels a series with its grouping dimensions, e.g.
+// "avg_latency agent_region=ord target_tier=vip".
+func grafanaSeriesName(metric string, s MetricsSeries) string {
+	agent := s.AgentName
+	if agent == "" {
+		agent = s.AgentID
+	}
+	target := s.TargetIP
+	if target == "" {
+		target = s.TargetID
+	}
+	var asn string
+	if s.TargetASN != 0 {
+		asn = "AS" + strconv.Itoa(s.TargetASN)
+	}
+	labels := []struct{ key, value string }{
+		{"agent", agent},
+		{"agent_region", s.AgentRegion},
+		{"agent_provider", s.AgentProvider},
+		{"target", target},
+		{"target_tier", s.TargetTier},
+		{"target_region", s.TargetRegion},
+		{"target_asn", asn},
+	}
+
+	name := metric
+	for _, l := range labels {
+		if l.value != "" {
+			name += " " + l.key + "=" + l.value
+		}
+	}
+	return name
+}
+
+// =============================================================================
+// SEARCH
+// =============================================================================
+
+// GrafanaSearchRequest is the body of a Grafana search. Target picks what
+// to list:
+//
+//	""  or "metrics"   metric names
+//	"group_by"         group-by dimensions
+//	"tiers"            tier names
+//	"agents"           agents, valued by ID
+//	"targets[:text]"   targets whose IP or name contains text, valued by ID
+type GrafanaSearchRequest struct {
+	Target string `json:"target"`
+}
+
+// GrafanaSearchResult is one search result.
+type GrafanaSearchResult struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// Kinds of Grafana search.
+const (
+	GrafanaSearchMetrics = "metrics"
+	GrafanaSearchGroupBy = "group_by"
+	GrafanaSearchTiers   = "tiers"
+	GrafanaSearchAgents  = "agents"
+	GrafanaSearchTargets = "targets"
+)
+
+// Parse splits a search into its kind and, for targets, the text to match.
+func (r GrafanaSearchRequest) Parse() (kind, text string, err error) {
+	kind, text, _ = strings.Cut(strings.TrimSpace(r.Target), ":")
+	switch kind {
+	case "":
+		return GrafanaSearchMetrics, "", nil
+	case GrafanaSearchMetrics, GrafanaSearchGroupBy, GrafanaSearchTiers, GrafanaSearchAgents:
+		if text != "" {
+			return "", "", fmt.Errorf("search %q takes no text", kind)
+		}
+		return kind, "", nil
+	case GrafanaSearchTargets:
+		return kind, strings.TrimSpace(text), nil
+	default:
+		return "", "", fmt.Errorf("unknown search %q", r.Target)
+	}
+}
+
+// GrafanaNames returns names as search results valued by themselves.
+func GrafanaNames(names []string) []GrafanaSearchResult {
+	results := make([]GrafanaSearchResult, len(names))
+	for i, n := range names {
+		results[i] = GrafanaSearchResult{Text: n, Value: n}
+	}
+	return results
+}
+
+// =============================================================================
+// ANNOTATIONS
+// =============================================================================
+
+// GrafanaAnnotationRequest is the body of a Grafana annotation query.
+type GrafanaAnnotationRequest struct {
+	Range      GrafanaRange           `json:"range"`
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+}
+
+// GrafanaAnnotationQuery is the dashboard's annotation definition, echoed
+// back on each annotation. Query picks the source: "incidents",
+// "annotations" (operator notes such as maintenance), or empty for both.
+type GrafanaAnnotationQuery struct {
+	Name       string          `json:"name"`
+	Datasource json.RawMessage `json:"datasource,omitempty"`
+	Enable     bool            `json:"enable"`
+	IconColor  string          `json:"iconColor,omitempty"`
+	Query      string          `json:"query,omitempty"`
+}
+
+// Sources an annotation query can draw from.
+const (
+	GrafanaAnnotationsIncidents = "incidents"
+	GrafanaAnnotationsNotes     = "annotations"
+)
+
+// Sources reports which sources the query asks for.
+func (q GrafanaAnnotationQuery) Sources() (incidents, notes bool, err error) {
+	switch strings.TrimSpace(q.Query) {
+	case "":
+		return true, true, nil
+	case GrafanaAnnotationsIncidents:
+		return true, false, nil
+	case GrafanaAnnotationsNotes:
+		return false, true, nil
+	default:
+		return false, false, fmt.Errorf("unknown annotation query %q", q.Query)
+	}
+}
+
+// GrafanaAnnotation is one annotation in the response. Times are unix
+// milliseconds; a region has a TimeEnd.
+type GrafanaAnnotation struct {
+	Annotation GrafanaAnnotationQuery `json:"annotation"`
+	Time       int64                  `json:"time"`
+	TimeEnd    int64                  `json:"timeEnd,omitempty"`
+	IsRegion   bool                   `json:"isRegion,omitempty"`
+	Title      string                 `json:"title"`
+	Text       string                 `json:"text"`
+	Tags       []string               `json:"tags,omitempty"`
+}
@@ -0,0 +1,202 @@
+package types
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGrafanaQueryRequest_MetricsQuery(t *testing.T) {
+	from := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	to := from.Add(6 * time.Hour)
+
+	tests := []struct {
+		name       string
+		intervalMs int64
+		target     GrafanaTarget
+		want       MetricsQuery
+	}{
+		{
+			name:   "metric only, bucket auto-selected",
+			target: GrafanaTarget{RefID: "A", Target: "avg_latency"},
+			want: MetricsQuery{
+				TimeRange: TimeRange{Start: &from, End: &to},
+				Metrics:   []string{"avg_latency"},
+				GroupBy:   []string{"time"},
+			},
+		},
+		{
+			name:       "interval rounded up to minutes",
+			intervalMs: 90000,
+			target:     GrafanaTarget{RefID: "A", Target: "p95_latency"},
+			want: MetricsQuery{
+				TimeRange: TimeRange{Start: &from, End: &to},
+				Bucket:    "2m",
+				Metrics:   []string{"p95_latency"},
+				GroupBy:   []string{"time"},
+			},
+		},
+		{
+			name: "payload filters and group_by keep time first",
+			target: GrafanaTarget{RefID: "A", Target: "packet_loss", Payload: &GrafanaTargetPayload{
+				AgentFilter:  &AgentFilter{Regions: []string{"ord"}},
+				TargetFilter: &TargetFilter{Tiers: []string{"vip"}},
+				GroupBy:      []string{"agent_region", "time"},
+			}},
+			want: MetricsQuery{
+				TimeRange:    TimeRange{Start: &from, End: &to},
+				AgentFilter:  &AgentFilter{Regions: []string{"ord"}},
+				TargetFilter: &TargetFilter{Tiers: []string{"vip"}},
+				Metrics:      []string{"packet_loss"},
+				GroupBy:      []string{"time", "agent_region"},
+			},
+		},
+		{
+			name: "legacy data field",
+			target: GrafanaTarget{RefID: "A", Target: "jitter", Data: &GrafanaTargetPayload{
+				GroupBy: []string{"target"},
+			}},
+			want: MetricsQuery{
+				TimeRange: TimeRange{Start: &from, End: &to},
+				Metrics:   []string{"jitter"},
+				GroupBy:   []string{"time", "target"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &GrafanaQueryRequest{Range: GrafanaRange{From: from, To: to}, IntervalMs: tt.intervalMs}
+			got := req.MetricsQuery(tt.target)
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("MetricsQuery() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrafanaTargetPayload_UnmarshalForms(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		want *GrafanaTargetPayload
+	}{
+		{"object", `{"payload":{"group_by":["agent"]}}`, &GrafanaTargetPayload{GroupBy: []string{"agent"}}},
+		{"string", `{"payload":"{\"group_by\":[\"agent\"]}"}`, &GrafanaTargetPayload{GroupBy: []string{"agent"}}},
+		{"empty string", `{"payload":""}`, &GrafanaTargetPayload{}},
+		{"absent", `{}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var target GrafanaTarget
+			if err := json.Unmarshal([]byte(tt.body), &target); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			if !reflect.DeepEqual(target.Payload, tt.want) {
+				t.Errorf("Payload = %+v, want %+v", target.Payload, tt.want)
+			}
+		})
+	}
+}
+
+func TestGrafanaSeries_NamesAndPoints(t *testing.T) {
+	t0 := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	t1 := t0.Add(5 * time.Minute)
+	v := func(f float64) *float64 { return &f }
+
+	result := &MetricsQueryResult{Series: []MetricsSeries{
+		{AgentRegion: "ord", TargetTier: "vip", Points: []MetricsDataPoint{
+			{Time: t0, AvgLatency: v(12.5)},
+			{Time: t1}, // No value, dropped
+		}},
+		{AgentName: "agent-1", AgentID: "a1", Points: []MetricsDataPoint{
+			{Time: t0, AvgLatency: v(3)},
+		}},
+		{Points: []MetricsDataPoint{{Time: t1, AvgLatency: v(7)}}},
+	}}
+
+	want := []GrafanaTimeSeries{
+		{Target: "avg_latency", Datapoints: [][2]float64{{7, float64(t1.UnixMilli())}}},
+		{Target: "avg_latency agent=agent-1", Datapoints: [][2]float64{{3, float64(t0.UnixMilli())}}},
+		{Target: "avg_latency agent_region=ord target_tier=vip", Datapoints: [][2]float64{{12.5, float64(t0.UnixMilli())}}},
+	}
+	if got := GrafanaSeries("avg_latency", result); !reflect.DeepEqual(got, want) {
+		t.Errorf("GrafanaSeries() = %+v, want %+v", got, want)
+	}
+}
+
+func TestMetricsDataPoint_Value(t *testing.T) {
+	loss, count := 1.5, int64(40)
+	p := MetricsDataPoint{PacketLoss: &loss, ProbeCount: &count}
+
+	tests := []struct {
+		metric string
+		want   float64
+		wantOK bool
+	}{
+		{"packet_loss", 1.5, true},
+		{"probe_count", 40, true},
+		{"avg_latency", 0, false},
+		{"unknown", 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.metric, func(t *testing.T) {
+			got, ok := p.Value(tt.metric)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("Value(%q) = %v, %v, want %v, %v", tt.metric, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestGrafanaSearchRequest_Parse(t *testing.T) {
+	tests := []struct {
+		target   string
+		wantKind string
+		wantText string
+		wantErr  bool
+	}{
+		{"", GrafanaSearchMetrics, "", false},
+		{"metrics", GrafanaSearchMetrics, "", false},
+		{"group_by", GrafanaSearchGroupBy, "", false},
+		{"agents", GrafanaSearchAgents, "", false},
+		{"targets", GrafanaSearchTargets, "", false},
+		{"targets: 10.0.0", GrafanaSearchTargets, "10.0.0", false},
+		{"tiers:vip", "", "", true},
+		{"subnets", "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.target, func(t *testing.T) {
+			kind, text, err := GrafanaSearchRequest{Target: tt.target}.Parse()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if kind != tt.wantKind || text != tt.wantText {
+				t.Errorf("Parse() = %q, %q, want %q, %q", kind, text, tt.wantKind, tt.wantText)
+			}
+		})
+	}
+}
+
+func TestGrafanaAnnotationQuery_Sources(t *testing.T) {
+	tests := []struct {
+		query                    string
+		wantIncidents, wantNotes bool
+		wantErr                  bool
+	}{
+		{"", true, true, false},
+		{"incidents", true, false, false},
+		{"annotations", false, true, false},
+		{"alerts", false, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			incidents, notes, err := GrafanaAnnotationQuery{Query: tt.query}.Sources()
+			if (err != nil) != tt.wantErr || incidents != tt.wantIncidents || notes != tt.wantNotes {
+				t.Errorf("Sources() = %v, %v, %v", incidents, notes, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package types
+
+import "math"
+
+// =============================================================================
+// TARGET HEALTH SCORE
+// =============================================================================
+
+// Latency and jitter score 1 up to their baseline and fall linearly to 0 at
+// healthScoreZeroRatio times it. Baselines are floored so a near-constant
+// baseline doesn't turn sub-millisecond wobble into a low score.
+const (
+	healthScoreZeroRatio          = 3.0
+	healthScoreMinBaselineLatency = 1.0 // ms
+	healthScoreMinBaselineJitter  = 1.0 // ms
+)
+
+// HealthScoreWeights weight the components of a target's health score.
+// Only their ratios matter; a component weighted zero or less is ignored.
+type HealthScoreWeights struct {
+	Reachability float64 `json:"reachability"`
+	Latency      float64 `json:"latency"`
+	Loss         float64 `json:"loss"`
+	Jitter       float64 `json:"jitter"`
+}
+
+// DefaultHealthScoreWeights favour reachability, then latency and loss.
+func DefaultHealthScoreWeights() HealthScoreWeights {
+	return HealthScoreWeights{Reachability: 0.4, Latency: 0.25, Loss: 0.25, Jitter: 0.1}
+}
+
+// HealthScoreInputs are a target's recent measurements. LatencyMs and
+// JitterMs are the mean and standard deviation of successful probes'
+// latency, averaged over the agents that have a baseline for the target;
+// the baselines are those agents' average p50 and standard deviation.
+type HealthScoreInputs struct {
+	ReachableAgents int
+	TotalAgents     int
+	PacketLossPct   *float64
+
+	LatencyMs         *float64
+	BaselineLatencyMs *float64
+	JitterMs          *float64
+	BaselineJitterMs  *float64
+}
+
+// HealthScoreComponents are each component's score from 0 (worst) to 1.
+// A component is nil when there was nothing to judge it by, such as
+// latency with no baseline, and then doesn't count toward the score.
+type HealthScoreComponents struct {
+	Reachability *float64 `json:"reachability"`
+	Latency      *float64 `json:"latency"`
+	Loss         *float64 `json:"loss"`
+	Jitter       *float64 `json:"jitter"`
+}
+
+// HealthScore is a single 0-100 ranking of a target's health.
+type HealthScore struct {
+	Score      int                   `json:"score"` // 0 (worst) to 100
+	Components HealthScoreComponents `json:"components"`
+}
+
+// ComputeHealthScore combines reachability, latency and jitter against
+// baseline, and packet loss into a weighted 0-100 score. It returns nil
+// when no agent probed the target, since there is nothing to score.
+func ComputeHealthScore(in HealthScoreInputs, w HealthScoreWeights) *HealthScore {
+	if in.TotalAgents <= 0 {
+		return nil
+	}
+
+	var c HealthScoreComponents
+	c.Reachability = scorePtr(float64(in.ReachableAgents) / float64(in.TotalAgents))
+	if in.PacketLossPct != nil {
+		c.Loss = scorePtr(1 - *in.PacketLossPct/100)
+	}
+	if in.LatencyMs != nil && in.BaselineLatencyMs != nil {
+		c.Latency = scorePtr(baselineRatioScore(*in.LatencyMs, *in.BaselineLatencyMs, healthScoreMinBaselineLatency))
+	}
+	if in.JitterMs != nil && in.BaselineJitterMs != nil {
+		c.Jitter = scorePtr(baselineRatioScore(*in.JitterMs, *in.BaselineJitterMs, healthScoreMinBaselineJitter))
+	}
+
+	var sum, weights float64
+	for _, part := range []struct {
+		score  *float64
+		weight float64
+	}{
+		{c.Reachability, w.Reachability},
+		{c.Latency, w.Latency},
+		{c.Loss, w.Loss},
+		{c.Jitter, w.Jitter},
+	} {
+		if part.score == nil || part.weight <= 0 {
+			continue
+		}
+		sum += *part.score * part.weight
+		weights += part.weight
+	}
+
+	// With every available component weighted out, fall back to
+	// reachability so the score still means something
+	score := *c.Reachability
+	if weights > 0 {
+		score = sum / weights
+	}
+	return &HealthScore{Score: int(math.Round(score * 100)), Components: c}
+}
+
+// baselineRatioScore scores value against its baseline: 1 at or under it,
+// 0 at healthScoreZeroRatio times it or more.
+func baselineRatioScore(value, baseline, minBaseline float64) float64 {
+	ratio := value / math.Max(baseline, minBaseline)
+	if ratio <= 1 {
+		return 1
+	}
+	return 1 - (ratio-1)/(healthScoreZeroRatio-1)
+}
+
+// scorePtr clamps a component score to [0, 1].
+func scorePtr(v float64) *float64 {
+	v = math.Min(math.Max(v, 0), 1)
+	return &v
+}
@@ -0,0 +1,78 @@
+package types
+
+import "testing"
+
+func TestComputeHealthScore_Weighting(t *testing.T) {
+	f := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name    string
+		in      HealthScoreInputs
+		weights HealthScoreWeights
+		want    int
+		wantNil bool
+	}{
+		{
+			name:    "no agents",
+			in:      HealthScoreInputs{},
+			weights: DefaultHealthScoreWeights(),
+			wantNil: true,
+		},
+		{
+			name: "healthy at baseline",
+			in: HealthScoreInputs{ReachableAgents: 3, TotalAgents: 3, PacketLossPct: f(0),
+				LatencyMs: f(10), BaselineLatencyMs: f(10), JitterMs: f(1), BaselineJitterMs: f(1)},
+			weights: DefaultHealthScoreWeights(),
+			want:    100,
+		},
+		{
+			name: "every component degraded",
+			in: HealthScoreInputs{ReachableAgents: 1, TotalAgents: 2, PacketLossPct: f(50),
+				LatencyMs: f(20), BaselineLatencyMs: f(10), JitterMs: f(9), BaselineJitterMs: f(3)},
+			weights: DefaultHealthScoreWeights(),
+			want:    45,
+		},
+		{
+			name:    "no baseline skips latency and jitter",
+			in:      HealthScoreInputs{ReachableAgents: 2, TotalAgents: 2, PacketLossPct: f(10), LatencyMs: f(50)},
+			weights: DefaultHealthScoreWeights(),
+			want:    96,
+		},
+		{
+			name:    "sub-millisecond baseline floored",
+			in:      HealthScoreInputs{ReachableAgents: 1, TotalAgents: 1, LatencyMs: f(1.5), BaselineLatencyMs: f(0.2)},
+			weights: DefaultHealthScoreWeights(),
+			want:    90,
+		},
+		{
+			name:    "zero weights fall back to reachability",
+			in:      HealthScoreInputs{ReachableAgents: 2, TotalAgents: 4, PacketLossPct: f(0)},
+			weights: HealthScoreWeights{},
+			want:    50,
+		},
+		{
+			name:    "loss only",
+			in:      HealthScoreInputs{ReachableAgents: 0, TotalAgents: 4, PacketLossPct: f(25)},
+			weights: HealthScoreWeights{Loss: 1},
+			want:    75,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ComputeHealthScore(tt.in, tt.weights)
+			if tt.wantNil {
+				if got != nil {
+					t.Errorf("got %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("got nil score")
+			}
+			if got.Score != tt.want {
+				t.Errorf("score = %d, want %d", got.Score, tt.want)
+			}
+		})
+	}
+}
@@ -9,6 +9,8 @@ type InfrastructureHealth struct {
 	Database        DatabaseHealth     `json:"database"`
 	Buffer          BufferHealth       `json:"buffer"`
 	StorageForecast StorageForecast    `json:"storage_forecast"`
+
+	AssignmentCache AssignmentCacheStats `json:"assignment_cache"`
 }
 
 // ControlPlaneHealth contains control plane runtime metrics.
@@ -53,6 +55,24 @@ type BufferHealth struct {
 	Connected  bool    `json:"connected"`
 	QueueDepth int64   `json:"queue_depth"`
 	FlushRate  float64 `json:"flush_rate_per_second"`
+
+	// BatchSize is the flusher's current batch size (it adapts to load),
+	// LastBatchSize the results in the last non-empty flush and
+	// FlushLatencyMs a moving average of how long flushes take.
+	BatchSize      int     `json:"batch_size"`
+	LastBatchSize  int     `json:"last_batch_size"`
+	FlushLatencyMs float64 `json:"flush_latency_ms"`
+	Flushed        int64   `json:"flushed_total"`
+}
+
+// AssignmentCacheStats counts how agent assignment fetches were served
+// since startup: from a cached computation (Hits), by joining one already
+// in flight (Coalesced) or by computing them (Misses).
+type AssignmentCacheStats struct {
+	Hits      int64   `json:"hits"`
+	Coalesced int64   `json:"coalesced"`
+	Misses    int64   `json:"misses"`
+	HitRate   float64 `json:"hit_rate"` // (hits + coalesced) / fetches
 }
 
 // StorageForecast contains storage growth projections.
@@ -77,4 +97,10 @@ type BufferStats struct {
 	QueueDepth int64
 	FlushRate  float64
 	Connected  bool
+
+	// Flusher metrics, zero when only the buffer reports
+	BatchSize      int
+	LastBatchSize  int
+	FlushLatencyMs float64
+	Flushed        int64
 }
@@ -0,0 +1,26 @@
+package types
+
+// IngestResponse is the control plane's reply to a result batch.
+type IngestResponse struct {
+	// Accepted counts results stored from this batch. Rejected results are
+	// dropped, and counted by reason in Reasons.
+	Accepted int            `json:"accepted"`
+	Rejected int            `json:"rejected"`
+	Reasons  map[string]int `json:"reasons"`
+
+	// Clamped counts accepted results whose future timestamps were pulled
+	// back to server time.
+	Clamped int `json:"clamped,omitempty"`
+
+	// Duplicate is set when the batch's sequence had already been accepted,
+	// so nothing was stored again; Deduplicated counts the results skipped.
+	// A duplicate is acknowledged like a fresh batch so the agent stops
+	// retrying it.
+	Duplicate    bool `json:"duplicate,omitempty"`
+	Deduplicated int  `json:"deduplicated,omitempty"`
+
+	// QueueDepth is how many results are waiting in the server's buffer to
+	// be written, when results are buffered. A depth that keeps growing
+	// means the database is falling behind ingest.
+	QueueDepth *int64 `json:"queue_depth,omitempty"`
+}
@@ -0,0 +1,68 @@
+package types
+
+import "sort"
+
+// =============================================================================
+// LATENCY ASYMMETRY
+// =============================================================================
+
+// LatencyAsymmetryFloorMs is the smallest latency the fastest region is
+// credited with when computing the spread factor, so a sub-millisecond
+// in-market path doesn't turn an ordinary remote latency into a huge factor.
+const LatencyAsymmetryFloorMs = 1.0
+
+// RegionLatency is the median current latency to a target of the agents in
+// one region.
+type RegionLatency struct {
+	Region    string  `json:"region"`
+	Agents    int     `json:"agents"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// LatencyAsymmetryThresholds decide when a spread across regions counts as
+// asymmetric.
+type LatencyAsymmetryThresholds struct {
+	// Factor is the slowest region's latency over the fastest's at or
+	// above which a target is asymmetric.
+	Factor float64 `json:"factor"`
+
+	// MinDeltaMs is the smallest gap between the slowest and fastest
+	// regions that counts, so 1ms against 4ms isn't flagged.
+	MinDeltaMs float64 `json:"min_delta_ms"`
+}
+
+// LatencyAsymmetry compares a target's latency across agent regions.
+type LatencyAsymmetry struct {
+	Regions       []RegionLatency `json:"regions"`
+	FastestRegion string          `json:"fastest_region"`
+	SlowestRegion string          `json:"slowest_region"`
+	Factor        float64         `json:"factor"`
+	DeltaMs       float64         `json:"delta_ms"`
+
+	Thresholds LatencyAsymmetryThresholds `json:"thresholds"`
+	Asymmetric bool                       `json:"asymmetric"`
+}
+
+// AssessLatencyAsymmetry compares the fastest and slowest regions. It
+// returns nil with fewer than two regions, where there is nothing to
+// compare.
+func AssessLatencyAsymmetry(regions []RegionLatency, th LatencyAsymmetryThresholds) *LatencyAsymmetry {
+	if len(regions) < 2 {
+		return nil
+	}
+
+	sorted := append([]RegionLatency(nil), regions...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].LatencyMs < sorted[j].LatencyMs })
+	fastest, slowest := sorted[0], sorted[len(sorted)-1]
+
+	a := &LatencyAsymmetry{
+		Regions:       sorted,
+		FastestRegion: fastest.Region,
+		SlowestRegion: slowest.Region,
+		Factor:        slowest.LatencyMs / max(fastest.LatencyMs, LatencyAsymmetryFloorMs),
+		DeltaMs:       slowest.LatencyMs - fastest.LatencyMs,
+		Thresholds:    th,
+	}
+	a.Asymmetric = a.Factor >= th.Factor && a.DeltaMs >= th.MinDeltaMs
+	return a
+}
@@ -0,0 +1,67 @@
+package types
+
+import "testing"
+
+func TestAssessLatencyAsymmetry_Thresholds(t *testing.T) {
+	th := LatencyAsymmetryThresholds{Factor: 3, MinDeltaMs: 30}
+
+	tests := []struct {
+		name        string
+		regions     []RegionLatency
+		wantNil     bool
+		wantSlowest string
+		wantFactor  float64
+		want        bool
+	}{
+		{
+			name:    "single region",
+			regions: []RegionLatency{{Region: "nyc", LatencyMs: 10}},
+			wantNil: true,
+		},
+		{
+			name:        "even spread",
+			regions:     []RegionLatency{{Region: "nyc", LatencyMs: 20}, {Region: "chi", LatencyMs: 35}},
+			wantSlowest: "chi",
+			wantFactor:  1.75,
+		},
+		{
+			name:        "one region far slower",
+			regions:     []RegionLatency{{Region: "lax", LatencyMs: 150}, {Region: "nyc", LatencyMs: 20}, {Region: "chi", LatencyMs: 30}},
+			wantSlowest: "lax",
+			wantFactor:  7.5,
+			want:        true,
+		},
+		{
+			name:        "large factor but small gap",
+			regions:     []RegionLatency{{Region: "nyc", LatencyMs: 2}, {Region: "chi", LatencyMs: 12}},
+			wantSlowest: "chi",
+			wantFactor:  6,
+		},
+		{
+			name:        "sub-millisecond fastest uses floor",
+			regions:     []RegionLatency{{Region: "nyc", LatencyMs: 0.2}, {Region: "chi", LatencyMs: 40}},
+			wantSlowest: "chi",
+			wantFactor:  40,
+			want:        true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := AssessLatencyAsymmetry(tt.regions, th)
+			if tt.wantNil {
+				if got != nil {
+					t.Fatalf("AssessLatencyAsymmetry() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil {
+				t.Fatal("AssessLatencyAsymmetry() = nil")
+			}
+			if got.SlowestRegion != tt.wantSlowest || got.Factor != tt.wantFactor || got.Asymmetric != tt.want {
+				t.Errorf("AssessLatencyAsymmetry() = slowest %s factor %v asymmetric %v, want %s %v %v",
+					got.SlowestRegion, got.Factor, got.Asymmetric, tt.wantSlowest, tt.wantFactor, tt.want)
+			}
+		})
+	}
+}
@@ -16,7 +16,13 @@
 package types
 
 import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 )
 
@@ -24,6 +30,25 @@ import (
 // METRICS QUERY
 // =============================================================================
 
+// DefaultQueryMetrics are returned when a query doesn't list any metrics.
+var DefaultQueryMetrics = []string{"avg_latency", "packet_loss"}
+
+// DefaultQueryLimit caps data points when a query doesn't set a limit.
+const DefaultQueryLimit = 10000
+
+// QueryMetricNames are the metrics a query can ask for.
+var QueryMetricNames = []string{
+	"avg_latency", "min_latency", "max_latency",
+	"p50_latency", "p95_latency", "p99_latency",
+	"jitter", "packet_loss", "success_rate", "probe_count",
+}
+
+// QueryGroupByDimensions are the dimensions a query can group by.
+var QueryGroupByDimensions = []string{
+	"time", "agent", "agent_region", "agent_provider",
+	"target", "target_tier", "target_region", "target_asn",
+}
+
 // MetricsQuery defines a flexible query for probe metrics.
 type MetricsQuery struct {
 	// Filters narrow down which agent-target pairs to include
@@ -45,7 +70,8 @@ type MetricsQuery struct {
 	Metrics []string `json:"metrics,omitempty"`
 
 	// How to group results
-	// Options: "time", "agent", "agent_region", "agent_provider", "target", "target_tier"
+	// Options: "time", "agent", "agent_region", "agent_provider", "target", "target_tier",
+	//          "target_region", "target_asn"
 	// Default: ["time"] - single aggregated time series
 	// Example: ["time", "agent_region"] - one series per agent region
 	GroupBy []string `json:"group_by,omitempty"`
@@ -88,6 +114,10 @@ type TargetFilter struct {
 	// Filter by region (any of these) - filters by subnet region
 	Regions []string `json:"regions,omitempty"`
 
+	// Filter by origin ASN (any of these). Targets are only matched once
+	// enriched from an IP-to-ASN dataset.
+	ASNs []int `json:"asns,omitempty"`
+
 	// Filter by tags (all must match) - legacy simple format
 	Tags map[string]string `json:"tags,omitempty"`
 
@@ -99,6 +129,13 @@ type TargetFilter struct {
 	TagFilters []TagFilter `json:"tag_filters,omitempty"`
 }
 
+// IsEmpty reports whether the filter has no conditions and so matches every
+// target.
+func (f *TargetFilter) IsEmpty() bool {
+	return f == nil || (len(f.IDs) == 0 && len(f.Tiers) == 0 && len(f.Regions) == 0 && len(f.ASNs) == 0 &&
+		len(f.Tags) == 0 && len(f.ExcludeTags) == 0 && len(f.TagFilters) == 0)
+}
+
 // TagFilter defines a single tag filter with an operator.
 type TagFilter struct {
 	Key      string `json:"key"`
@@ -124,37 +161,13 @@ func (q *MetricsQuery) Validate() error {
 		return fmt.Errorf("time_range.window or time_range.start is required")
 	}
 
-	// Validate metrics
-	validMetrics := map[string]bool{
-		"avg_latency":  true,
-		"min_latency":  true,
-		"max_latency":  true,
-		"p50_latency":  true,
-		"p95_latency":  true,
-		"p99_latency":  true,
-		"jitter":       true,
-		"packet_loss":  true,
-		"success_rate": true,
-		"probe_count":  true,
-	}
 	for _, m := range q.Metrics {
-		if !validMetrics[m] {
+		if !slices.Contains(QueryMetricNames, m) {
 			return fmt.Errorf("invalid metric: %s", m)
 		}
 	}
-
-	// Validate group_by
-	validGroupBy := map[string]bool{
-		"time":           true,
-		"agent":          true,
-		"agent_region":   true,
-		"agent_provider": true,
-		"target":         true,
-		"target_tier":    true,
-		"target_region":  true,
-	}
 	for _, g := range q.GroupBy {
-		if !validGroupBy[g] {
+		if !slices.Contains(QueryGroupByDimensions, g) {
 			return fmt.Errorf("invalid group_by: %s", g)
 		}
 	}
@@ -178,6 +191,117 @@ func (tr *TimeRange) GetWindowDuration() (time.Duration, error) {
 	return ParseDuration(tr.Window)
 }
 
+// CacheKey returns a stable key for caching this query's result. Queries
+// that differ only in the order of filters, metrics or group_by, or in
+// spelling out a default (bucket, metrics, limit), share a key.
+//
+// ok is false when the result must not be cached: an absolute range ending
+// in the future still has data arriving.
+func (q *MetricsQuery) CacheKey(now time.Time) (key string, ok bool) {
+	if q.TimeRange.Window == "" && q.TimeRange.End != nil && q.TimeRange.End.After(now) {
+		return "", false
+	}
+	window, err := q.TimeRange.GetWindowDuration()
+	if err != nil {
+		return "", false
+	}
+
+	n := MetricsQuery{
+		AgentFilter:  q.AgentFilter.normalized(),
+		TargetFilter: q.TargetFilter.normalized(),
+		Bucket:       q.Bucket,
+		Metrics:      sortedUnique(q.Metrics),
+		GroupBy:      sortedUnique(q.GroupBy),
+		Limit:        q.Limit,
+	}
+	if q.TimeRange.Window != "" {
+		n.TimeRange.Window = window.String()
+	}
+	if q.TimeRange.Start != nil {
+		start := q.TimeRange.Start.UTC()
+		n.TimeRange.Start = &start
+	}
+	if q.TimeRange.End != nil {
+		end := q.TimeRange.End.UTC()
+		n.TimeRange.End = &end
+	}
+	if n.Bucket == "" {
+		n.Bucket = AutoSelectBucket(window)
+	}
+	if len(n.Metrics) == 0 {
+		n.Metrics = sortedUnique(DefaultQueryMetrics)
+	}
+	if len(n.GroupBy) == 0 {
+		n.GroupBy = []string{"time"}
+	}
+	if n.Limit <= 0 {
+		n.Limit = DefaultQueryLimit
+	}
+
+	// encoding/json sorts map keys, so tag maps serialise deterministically
+	data, err := json.Marshal(n)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return "metrics_query_" + hex.EncodeToString(sum[:]), true
+}
+
+func (f *AgentFilter) normalized() *AgentFilter {
+	if f == nil {
+		return nil
+	}
+	return &AgentFilter{
+		IDs:         sortedUnique(f.IDs),
+		Regions:     sortedUnique(f.Regions),
+		Providers:   sortedUnique(f.Providers),
+		Tags:        f.Tags,
+		ExcludeTags: f.ExcludeTags,
+		TagFilters:  sortedTagFilters(f.TagFilters),
+	}
+}
+
+func (f *TargetFilter) normalized() *TargetFilter {
+	if f == nil {
+		return nil
+	}
+	return &TargetFilter{
+		IDs:         sortedUnique(f.IDs),
+		Tiers:       sortedUnique(f.Tiers),
+		Regions:     sortedUnique(f.Regions),
+		ASNs:        sortedUnique(f.ASNs),
+		Tags:        f.Tags,
+		ExcludeTags: f.ExcludeTags,
+		TagFilters:  sortedTagFilters(f.TagFilters),
+	}
+}
+
+func sortedUnique[T cmp.Ordered](values []T) []T {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+	return slices.Compact(sorted)
+}
+
+func sortedTagFilters(filters []TagFilter) []TagFilter {
+	if len(filters) == 0 {
+		return nil
+	}
+	sorted := slices.Clone(filters)
+	slices.SortFunc(sorted, func(a, b TagFilter) int {
+		if c := strings.Compare(a.Key, b.Key); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Operator, b.Operator); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Value, b.Value)
+	})
+	return slices.Compact(sorted)
+}
+
 // ParseDuration parses duration strings including days.
 func ParseDuration(s string) (time.Duration, error) {
 	// Handle day suffix
@@ -228,6 +352,8 @@ type MetricsSeries struct {
 	TargetIP      string `json:"target_ip,omitempty"`
 	TargetTier    string `json:"target_tier,omitempty"`
 	TargetRegion  string `json:"target_region,omitempty"`
+	TargetASN     int    `json:"target_asn,omitempty"`
+	TargetASName  string `json:"target_as_name,omitempty"`
 
 	// Time-series data points
 	Points []MetricsDataPoint `json:"points"`
@@ -250,6 +376,41 @@ type MetricsDataPoint struct {
 	ProbeCount  *int64   `json:"probe_count,omitempty"`
 }
 
+// Value returns the named metric's value, or false when the point doesn't
+// carry it.
+func (p *MetricsDataPoint) Value(metric string) (float64, bool) {
+	var v *float64
+	switch metric {
+	case "avg_latency":
+		v = p.AvgLatency
+	case "min_latency":
+		v = p.MinLatency
+	case "max_latency":
+		v = p.MaxLatency
+	case "p50_latency":
+		v = p.P50Latency
+	case "p95_latency":
+		v = p.P95Latency
+	case "p99_latency":
+		v = p.P99Latency
+	case "jitter":
+		v = p.Jitter
+	case "packet_loss":
+		v = p.PacketLoss
+	case "success_rate":
+		v = p.SuccessRate
+	case "probe_count":
+		if p.ProbeCount == nil {
+			return 0, false
+		}
+		return float64(*p.ProbeCount), true
+	}
+	if v == nil {
+		return 0, false
+	}
+	return *v, true
+}
+
 // =============================================================================
 // HELPER - Auto bucket selection
 // =============================================================================
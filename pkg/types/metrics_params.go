@@ -0,0 +1,129 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// METRICS QUERY FROM URL PARAMS
+// =============================================================================
+
+// MetricsQueryFromValues builds a MetricsQuery from URL query params, for
+// tools that can only issue GETs. It covers a subset of the JSON query:
+//
+//	metrics, group_by              comma-separated or repeated
+//	window | start, end            relative window, or RFC 3339 times
+//	bucket, limit
+//	agent_id, agent_region, agent_provider
+//	target_id, target_tier, target_region
+//	target_asn                     AS numbers, comma-separated or repeated
+//	agent_tag, target_tag          key:value, repeated tags must all match
+//
+// Operator tag filters and exclusions need the JSON query. The result is
+// not validated.
+func MetricsQueryFromValues(v url.Values) (*MetricsQuery, error) {
+	q := &MetricsQuery{
+		Metrics: splitParam(v["metrics"]),
+		GroupBy: splitParam(v["group_by"]),
+		Bucket:  v.Get("bucket"),
+		TimeRange: TimeRange{
+			Window: v.Get("window"),
+		},
+	}
+
+	for _, t := range []struct {
+		name string
+		dst  **time.Time
+	}{{"start", &q.TimeRange.Start}, {"end", &q.TimeRange.End}} {
+		s := v.Get(t.name)
+		if s == "" {
+			continue
+		}
+		parsed, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be an RFC 3339 time: %q", t.name, s)
+		}
+		*t.dst = &parsed
+	}
+
+	if s := v.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit < 0 {
+			return nil, fmt.Errorf("limit must be a non-negative integer: %q", s)
+		}
+		q.Limit = limit
+	}
+
+	agentTags, err := tagParams(v["agent_tag"])
+	if err != nil {
+		return nil, err
+	}
+	agent := &AgentFilter{
+		IDs:       splitParam(v["agent_id"]),
+		Regions:   splitParam(v["agent_region"]),
+		Providers: splitParam(v["agent_provider"]),
+		Tags:      agentTags,
+	}
+	if len(agent.IDs)+len(agent.Regions)+len(agent.Providers)+len(agent.Tags) > 0 {
+		q.AgentFilter = agent
+	}
+
+	targetTags, err := tagParams(v["target_tag"])
+	if err != nil {
+		return nil, err
+	}
+	var asns []int
+	for _, s := range splitParam(v["target_asn"]) {
+		asn, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(s), "AS"))
+		if err != nil || asn <= 0 {
+			return nil, fmt.Errorf("target_asn must be an AS number: %q", s)
+		}
+		asns = append(asns, asn)
+	}
+	target := &TargetFilter{
+		IDs:     splitParam(v["target_id"]),
+		Tiers:   splitParam(v["target_tier"]),
+		Regions: splitParam(v["target_region"]),
+		ASNs:    asns,
+		Tags:    targetTags,
+	}
+	if !target.IsEmpty() {
+		q.TargetFilter = target
+	}
+
+	return q, nil
+}
+
+// splitParam flattens repeated and comma-separated values, dropping empty
+// entries.
+func splitParam(values []string) []string {
+	var out []string
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				out = append(out, part)
+			}
+		}
+	}
+	return out
+}
+
+// tagParams parses key:value tag params into a tag map.
+func tagParams(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	tags := make(map[string]string, len(values))
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, ":")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("tag filter must be key:value: %q", v)
+		}
+		tags[key] = value
+	}
+	return tags, nil
+}
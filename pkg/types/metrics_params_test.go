@@ -0,0 +1,109 @@
+package types
+
+import (
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetricsQueryFromValues_Cases(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2024, 5, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		query string
+		want  MetricsQuery
+	}{
+		{
+			name:  "window only",
+			query: "window=24h",
+			want:  MetricsQuery{TimeRange: TimeRange{Window: "24h"}},
+		},
+		{
+			name:  "lists comma separated and repeated",
+			query: "window=7d&metrics=avg_latency,p95_latency&metrics=packet_loss&group_by=time,agent_region&bucket=1h&limit=500",
+			want: MetricsQuery{
+				TimeRange: TimeRange{Window: "7d"},
+				Metrics:   []string{"avg_latency", "p95_latency", "packet_loss"},
+				GroupBy:   []string{"time", "agent_region"},
+				Bucket:    "1h",
+				Limit:     500,
+			},
+		},
+		{
+			name:  "absolute range",
+			query: "start=2024-05-01T00:00:00Z&end=2024-05-02T00:00:00Z",
+			want:  MetricsQuery{TimeRange: TimeRange{Start: &start, End: &end}},
+		},
+		{
+			name:  "filters",
+			query: "window=1h&agent_region=ord,nyc&agent_provider=aws&agent_tag=env:prod&target_tier=vip&target_tag=customer:acme&target_tag=site:",
+			want: MetricsQuery{
+				TimeRange: TimeRange{Window: "1h"},
+				AgentFilter: &AgentFilter{
+					Regions:   []string{"ord", "nyc"},
+					Providers: []string{"aws"},
+					Tags:      map[string]string{"env": "prod"},
+				},
+				TargetFilter: &TargetFilter{
+					Tiers: []string{"vip"},
+					Tags:  map[string]string{"customer": "acme", "site": ""},
+				},
+			},
+		},
+		{
+			name:  "target asn with or without prefix",
+			query: "window=1h&group_by=target_asn&target_asn=15169,AS13335&target_asn=as64500",
+			want: MetricsQuery{
+				TimeRange:    TimeRange{Window: "1h"},
+				GroupBy:      []string{"target_asn"},
+				TargetFilter: &TargetFilter{ASNs: []int{15169, 13335, 64500}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := url.ParseQuery(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := MetricsQueryFromValues(v)
+			if err != nil {
+				t.Fatalf("MetricsQueryFromValues() error = %v", err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("MetricsQueryFromValues() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMetricsQueryFromValues_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		wantErr string
+	}{
+		{"bad start", "start=yesterday", "start must be an RFC 3339 time"},
+		{"bad limit", "window=1h&limit=lots", "limit must be"},
+		{"negative limit", "window=1h&limit=-1", "limit must be"},
+		{"tag without value separator", "window=1h&agent_tag=prod", "key:value"},
+		{"tag without key", "window=1h&target_tag=:acme", "key:value"},
+		{"asn not a number", "window=1h&target_asn=google", "target_asn must be an AS number"},
+		{"asn zero", "window=1h&target_asn=0", "target_asn must be an AS number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, _ := url.ParseQuery(tt.query)
+			_, err := MetricsQueryFromValues(v)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMetricsQuery_CacheKey(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	past := now.Add(-2 * time.Hour)
+	recent := now.Add(-time.Hour)
+	future := now.Add(time.Hour)
+
+	base := MetricsQuery{
+		AgentFilter: &AgentFilter{Regions: []string{"ord", "nyc"}},
+		TimeRange:   TimeRange{Window: "24h"},
+		Metrics:     []string{"packet_loss", "p95_latency"},
+		GroupBy:     []string{"time", "agent_region"},
+	}
+
+	tests := []struct {
+		name     string
+		query    MetricsQuery
+		wantOK   bool
+		wantSame bool // same key as base
+	}{
+		{
+			name: "reordered filters, metrics and group_by",
+			query: MetricsQuery{
+				AgentFilter: &AgentFilter{Regions: []string{"nyc", "ord", "nyc"}},
+				TimeRange:   TimeRange{Window: "1d"},
+				Metrics:     []string{"p95_latency", "packet_loss"},
+				GroupBy:     []string{"agent_region", "time"},
+			},
+			wantOK:   true,
+			wantSame: true,
+		},
+		{
+			name: "explicit defaults",
+			query: MetricsQuery{
+				AgentFilter: &AgentFilter{Regions: []string{"ord", "nyc"}},
+				TimeRange:   TimeRange{Window: "24h"},
+				Bucket:      "15m",
+				Metrics:     []string{"packet_loss", "p95_latency"},
+				GroupBy:     []string{"time", "agent_region"},
+				Limit:       DefaultQueryLimit,
+			},
+			wantOK:   true,
+			wantSame: true,
+		},
+		{
+			name: "different bucket",
+			query: MetricsQuery{
+				AgentFilter: &AgentFilter{Regions: []string{"ord", "nyc"}},
+				TimeRange:   TimeRange{Window: "24h"},
+				Bucket:      "1h",
+				Metrics:     []string{"packet_loss", "p95_latency"},
+				GroupBy:     []string{"time", "agent_region"},
+			},
+			wantOK: true,
+		},
+		{
+			name:   "absolute range in the past",
+			query:  MetricsQuery{TimeRange: TimeRange{Start: &past, End: &recent}},
+			wantOK: true,
+		},
+		{
+			name:   "absolute range ending in the future",
+			query:  MetricsQuery{TimeRange: TimeRange{Start: &past, End: &future}},
+			wantOK: false,
+		},
+		{
+			name:   "no time range",
+			query:  MetricsQuery{},
+			wantOK: false,
+		},
+	}
+
+	baseKey, ok := base.CacheKey(now)
+	if !ok {
+		t.Fatal("base query should be cacheable")
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key, ok := tt.query.CacheKey(now)
+			if ok != tt.wantOK {
+				t.Fatalf("CacheKey() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if (key == baseKey) != tt.wantSame {
+				t.Errorf("CacheKey() same as base = %v, want %v", key == baseKey, tt.wantSame)
+			}
+		})
+	}
+}
+
+func TestMetricsQuery_CacheKeyTagMapOrder(t *testing.T) {
+	now := time.Now()
+	a := MetricsQuery{
+		TimeRange:    TimeRange{Window: "1h"},
+		TargetFilter: &TargetFilter{Tags: map[string]string{"env": "prod", "pop": "ord"}},
+	}
+	b := MetricsQuery{
+		TimeRange:    TimeRange{Window: "1h"},
+		TargetFilter: &TargetFilter{Tags: map[string]string{"pop": "ord", "env": "prod"}},
+	}
+	keyA, _ := a.CacheKey(now)
+	keyB, _ := b.CacheKey(now)
+	if keyA != keyB {
+		t.Error("tag map insertion order changed the cache key")
+	}
+}
+
+func TestTargetFilter_IsEmpty(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter *TargetFilter
+		want   bool
+	}{
+		{"nil", nil, true},
+		{"zero value", &TargetFilter{}, true},
+		{"empty slices and maps", &TargetFilter{IDs: []string{}, Tags: map[string]string{}}, true},
+		{"ids", &TargetFilter{IDs: []string{"a"}}, false},
+		{"tiers", &TargetFilter{Tiers: []string{"standard"}}, false},
+		{"regions", &TargetFilter{Regions: []string{"us-east"}}, false},
+		{"asns", &TargetFilter{ASNs: []int{15169}}, false},
+		{"tags", &TargetFilter{Tags: map[string]string{"customer": "acme"}}, false},
+		{"exclude tags", &TargetFilter{ExcludeTags: map[string]string{"env": "lab"}}, false},
+		{"tag filters", &TargetFilter{TagFilters: []TagFilter{{Key: "pop", Operator: "equals", Value: "ord"}}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.IsEmpty(); got != tt.want {
+				t.Errorf("IsEmpty() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
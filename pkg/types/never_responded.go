@@ -0,0 +1,42 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// =============================================================================
+// NEVER-RESPONDED ARCHIVAL
+// =============================================================================
+
+// ArchiveReasonNeverResponded is the archive_reason of targets archived by
+// the never-responded policy.
+const ArchiveReasonNeverResponded = "never_responded"
+
+// NeverRespondedPolicy is when the state worker archives auto-owned targets
+// that have never answered a probe, typically unassigned addresses created
+// by subnet seeding. A target qualifies once it was created more than
+// DiscoveryWindow ago and has at least MaxAttempts failed discovery probes.
+// Manual targets are never archived by the policy.
+type NeverRespondedPolicy struct {
+	Enabled         bool
+	DiscoveryWindow time.Duration
+	MaxAttempts     int
+}
+
+// MarshalJSON reports the window both as a Go duration and in seconds.
+func (p NeverRespondedPolicy) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Enabled                bool   `json:"enabled"`
+		DiscoveryWindow        string `json:"discovery_window"`
+		DiscoveryWindowSeconds int64  `json:"discovery_window_seconds"`
+		MaxAttempts            int    `json:"max_attempts"`
+		ArchiveReason          string `json:"archive_reason"`
+	}{
+		Enabled:                p.Enabled,
+		DiscoveryWindow:        p.DiscoveryWindow.String(),
+		DiscoveryWindowSeconds: int64(p.DiscoveryWindow.Seconds()),
+		MaxAttempts:            p.MaxAttempts,
+		ArchiveReason:          ArchiveReasonNeverResponded,
+	})
+}
@@ -0,0 +1,38 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestNeverRespondedPolicy_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy NeverRespondedPolicy
+		want   string
+	}{
+		{
+			name:   "enabled",
+			policy: NeverRespondedPolicy{Enabled: true, DiscoveryWindow: 7 * 24 * time.Hour, MaxAttempts: 5},
+			want:   `{"enabled":true,"discovery_window":"168h0m0s","discovery_window_seconds":604800,"max_attempts":5,"archive_reason":"never_responded"}`,
+		},
+		{
+			name:   "disabled",
+			policy: NeverRespondedPolicy{DiscoveryWindow: 90 * time.Minute, MaxAttempts: 20},
+			want:   `{"enabled":false,"discovery_window":"1h30m0s","discovery_window_seconds":5400,"max_attempts":20,"archive_reason":"never_responded"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.policy)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package types
+
+import "fmt"
+
+// =============================================================================
+// PATH MTU DISCOVERY
+// =============================================================================
+
+// CommandPMTUD asks an agent to find the path MTU to the command's target by
+// sending don't-fragment pings of decreasing size. It explains paths where
+// ping works but full-size packets are silently dropped.
+const CommandPMTUD = "pmtud"
+
+// Bounds on the packet sizes a pmtud command may search, in bytes of IP
+// packet. The defaults search from the common Ethernet MTU down to the
+// smallest MTU each family guarantees.
+const (
+	PMTUDMinMTU            = 68   // smallest IPv4 MTU (RFC 791)
+	PMTUDMaxMTU            = 9216 // jumbo frames
+	PMTUDDefaultMaxMTU     = 1500
+	PMTUDDefaultMinMTUIPv4 = 576
+	PMTUDDefaultMinMTUIPv6 = 1280
+)
+
+// PMTUDParams are the params of a pmtud command. Zero values take the
+// defaults for the target's address family.
+type PMTUDParams struct {
+	MinMTU int `json:"min_mtu,omitempty"`
+	MaxMTU int `json:"max_mtu,omitempty"`
+}
+
+// Validate checks the sizes that are set are within bounds and ordered.
+func (p PMTUDParams) Validate() error {
+	for _, size := range []struct {
+		name string
+		v    int
+	}{{"min_mtu", p.MinMTU}, {"max_mtu", p.MaxMTU}} {
+		if size.v != 0 && (size.v < PMTUDMinMTU || size.v > PMTUDMaxMTU) {
+			return fmt.Errorf("%s must be between %d and %d", size.name, PMTUDMinMTU, PMTUDMaxMTU)
+		}
+	}
+	if p.MinMTU != 0 && p.MaxMTU != 0 && p.MinMTU > p.MaxMTU {
+		return fmt.Errorf("min_mtu %d exceeds max_mtu %d", p.MinMTU, p.MaxMTU)
+	}
+	return nil
+}
+
+// Range returns the sizes to search for a target, filling in defaults. An
+// explicit min_mtu above the default max raises the max to match.
+func (p PMTUDParams) Range(ipv6 bool) (minMTU, maxMTU int) {
+	minMTU, maxMTU = p.MinMTU, p.MaxMTU
+	if maxMTU == 0 {
+		maxMTU = max(PMTUDDefaultMaxMTU, minMTU)
+	}
+	if minMTU == 0 {
+		minMTU = PMTUDDefaultMinMTUIPv4
+		if ipv6 {
+			minMTU = PMTUDDefaultMinMTUIPv6
+		}
+		minMTU = min(minMTU, maxMTU)
+	}
+	return minMTU, maxMTU
+}
+
+// PMTUDProbe is one size tried during discovery.
+type PMTUDProbe struct {
+	Size    int  `json:"size"` // IP packet size in bytes
+	Replied bool `json:"replied"`
+}
+
+// PMTUDPayload is the result payload of a pmtud command.
+type PMTUDPayload struct {
+	TargetIP string `json:"target_ip"`
+
+	// PathMTU is the largest packet that got a reply with fragmentation
+	// prohibited.
+	PathMTU int `json:"path_mtu"`
+
+	// AtMax is set when the largest size searched got through, so the real
+	// path MTU may be higher.
+	AtMax bool `json:"at_max"`
+
+	MinMTU int          `json:"min_mtu"`
+	MaxMTU int          `json:"max_mtu"`
+	Probes []PMTUDProbe `json:"probes"` // In the order sent
+}
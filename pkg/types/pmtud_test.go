@@ -0,0 +1,59 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPMTUDParamsValidate_Cases(t *testing.T) {
+	tests := []struct {
+		name    string
+		params  PMTUDParams
+		wantErr string
+	}{
+		{"defaults", PMTUDParams{}, ""},
+		{"explicit range", PMTUDParams{MinMTU: 1280, MaxMTU: 9000}, ""},
+		{"min below floor", PMTUDParams{MinMTU: 40}, "min_mtu must be between"},
+		{"max above jumbo", PMTUDParams{MaxMTU: 65535}, "max_mtu must be between"},
+		{"inverted", PMTUDParams{MinMTU: 1500, MaxMTU: 1400}, "exceeds max_mtu"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.params.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPMTUDParamsRange_Defaults(t *testing.T) {
+	tests := []struct {
+		name             string
+		params           PMTUDParams
+		ipv6             bool
+		wantMin, wantMax int
+	}{
+		{"ipv4 defaults", PMTUDParams{}, false, 576, 1500},
+		{"ipv6 defaults", PMTUDParams{}, true, 1280, 1500},
+		{"jumbo max", PMTUDParams{MaxMTU: 9000}, false, 576, 9000},
+		{"min above default max", PMTUDParams{MinMTU: 4000}, false, 4000, 4000},
+		{"max below default min", PMTUDParams{MaxMTU: 1000}, true, 1000, 1000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lo, hi := tt.params.Range(tt.ipv6)
+			if lo != tt.wantMin || hi != tt.wantMax {
+				t.Errorf("Range() = %d, %d, want %d, %d", lo, hi, tt.wantMin, tt.wantMax)
+			}
+		})
+	}
+}
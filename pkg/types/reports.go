@@ -0,0 +1,120 @@
+package types
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// =============================================================================
+// SCHEDULED REPORTS
+// =============================================================================
+
+// ReportDeliveryMethod is how a scheduled report reaches its recipients.
+type ReportDeliveryMethod string
+
+const (
+	ReportDeliveryEmail   ReportDeliveryMethod = "email"
+	ReportDeliveryWebhook ReportDeliveryMethod = "webhook"
+)
+
+// ReportFormat is the rendering of a delivered report.
+type ReportFormat string
+
+const (
+	ReportFormatPDF  ReportFormat = "pdf"
+	ReportFormatJSON ReportFormat = "json"
+)
+
+// ReportDeliveryStatus is the outcome of a single delivery attempt.
+type ReportDeliveryStatus string
+
+const (
+	ReportDeliverySent   ReportDeliveryStatus = "sent"
+	ReportDeliveryFailed ReportDeliveryStatus = "failed"
+)
+
+// ReportSchedule generates target reports on a cron schedule and delivers them.
+//
+// Scope is either an explicit list of targets or, when TargetIDs is empty,
+// every target in the subscriber's subnets.
+type ReportSchedule struct {
+	ID             string   `json:"id"`
+	Name           string   `json:"name"`
+	SubscriberName string   `json:"subscriber_name,omitempty"`
+	TargetIDs      []string `json:"target_ids"`
+	WindowDays     int      `json:"window_days"`
+
+	// CronExpression is a standard 5-field cron expression evaluated in UTC.
+	CronExpression string `json:"cron_expression"`
+
+	DeliveryMethod ReportDeliveryMethod `json:"delivery_method"`
+	Recipients     []string             `json:"recipients,omitempty"`
+	WebhookURL     string               `json:"webhook_url,omitempty"`
+	Format         ReportFormat         `json:"format"`
+
+	Enabled bool `json:"enabled"`
+
+	NextRunAt           *time.Time `json:"next_run_at,omitempty"`
+	LastRunAt           *time.Time `json:"last_run_at,omitempty"`
+	LastStatus          string     `json:"last_status,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the schedule has a scope and a usable destination.
+// The cron expression itself is parsed by the scheduler.
+func (r *ReportSchedule) Validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.SubscriberName == "" && len(r.TargetIDs) == 0 {
+		return fmt.Errorf("subscriber_name or target_ids is required")
+	}
+	if r.WindowDays <= 0 {
+		return fmt.Errorf("window_days must be positive")
+	}
+	if r.CronExpression == "" {
+		return fmt.Errorf("cron_expression is required")
+	}
+
+	switch r.Format {
+	case ReportFormatPDF, ReportFormatJSON:
+	default:
+		return fmt.Errorf("invalid format: %s", r.Format)
+	}
+
+	switch r.DeliveryMethod {
+	case ReportDeliveryEmail:
+		if len(r.Recipients) == 0 {
+			return fmt.Errorf("recipients are required for email delivery")
+		}
+	case ReportDeliveryWebhook:
+		u, err := url.Parse(r.WebhookURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("a valid http(s) webhook_url is required for webhook delivery")
+		}
+	default:
+		return fmt.Errorf("invalid delivery_method: %s", r.DeliveryMethod)
+	}
+	return nil
+}
+
+// ReportDelivery records one attempt to deliver a scheduled report.
+type ReportDelivery struct {
+	ID             string               `json:"id"`
+	ScheduleID     string               `json:"schedule_id"`
+	Status         ReportDeliveryStatus `json:"status"`
+	DeliveryMethod ReportDeliveryMethod `json:"delivery_method"`
+	Destination    string               `json:"destination"`
+	TargetCount    int                  `json:"target_count"`
+	SizeBytes      int                  `json:"size_bytes"`
+	Attempt        int                  `json:"attempt"`
+	Error          string               `json:"error,omitempty"`
+	WindowStart    time.Time            `json:"window_start"`
+	WindowEnd      time.Time            `json:"window_end"`
+	CreatedAt      time.Time            `json:"created_at"`
+}
@@ -0,0 +1,75 @@
+package types
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// =============================================================================
+// SHIP LAG
+// =============================================================================
+
+// BatchShipLag is how long a batch's results took to reach the control
+// plane: server receive time minus probe time, per result. Results wait in
+// the agent's ship queue during backpressure, so a high lag means the data
+// is old even though it has just arrived.
+type BatchShipLag struct {
+	Results int     `json:"results"`
+	P50Ms   float64 `json:"p50_ms"`
+	P95Ms   float64 `json:"p95_ms"`
+	MaxMs   float64 `json:"max_ms"`
+}
+
+// MeasureShipLag computes a batch's lag at receivedAt. Results stamped after
+// receivedAt (agent clock skew) count as no lag.
+func MeasureShipLag(results []ProbeResult, receivedAt time.Time) BatchShipLag {
+	if len(results) == 0 {
+		return BatchShipLag{}
+	}
+
+	lags := make([]float64, len(results))
+	for i, r := range results {
+		lags[i] = max(float64(receivedAt.Sub(r.Timestamp).Microseconds())/1000, 0)
+	}
+	sort.Float64s(lags)
+
+	return BatchShipLag{
+		Results: len(lags),
+		P50Ms:   nearestRank(lags, 0.50),
+		P95Ms:   nearestRank(lags, 0.95),
+		MaxMs:   lags[len(lags)-1],
+	}
+}
+
+// nearestRank returns the p-th percentile of sorted, non-empty values.
+func nearestRank(sorted []float64, p float64) float64 {
+	rank := int(math.Ceil(p*float64(len(sorted)))) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
+
+// AgentShipLag summarises one agent's ship lag over a window, with the
+// queue age from its latest heartbeat.
+type AgentShipLag struct {
+	AgentID   string `json:"agent_id"`
+	AgentName string `json:"agent_name"`
+	Window    string `json:"window"`
+	Batches   int    `json:"batches"`
+
+	// P95Ms is the 95th percentile of the window's per-batch p95 lags;
+	// MaxMs is the largest single result's lag.
+	P95Ms float64 `json:"p95_ms"`
+	MaxMs float64 `json:"max_ms"`
+
+	// OldestQueuedAgeMs is the agent-reported age of its oldest unshipped
+	// result. It catches an agent so backed up that nothing arrives to
+	// measure lag on.
+	OldestQueuedAgeMs int64 `json:"oldest_queued_age_ms"`
+}
+
+// Behind reports whether the agent is falling behind on shipping: its p95
+// lag or its oldest queued result is at least threshold old.
+func (l AgentShipLag) Behind(threshold time.Duration) bool {
+	thresholdMs := float64(threshold.Milliseconds())
+	return l.P95Ms >= thresholdMs || float64(l.OldestQueuedAgeMs) >= thresholdMs
+}
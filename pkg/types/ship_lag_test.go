@@ -0,0 +1,76 @@
+package types
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMeasureShipLag_Percentiles(t *testing.T) {
+	received := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	aged := func(ages ...time.Duration) []ProbeResult {
+		results := make([]ProbeResult, len(ages))
+		for i, age := range ages {
+			results[i] = ProbeResult{Timestamp: received.Add(-age)}
+		}
+		return results
+	}
+
+	tests := []struct {
+		name    string
+		results []ProbeResult
+		want    BatchShipLag
+	}{
+		{
+			name: "empty batch",
+		},
+		{
+			name:    "single result",
+			results: aged(1500 * time.Millisecond),
+			want:    BatchShipLag{Results: 1, P50Ms: 1500, P95Ms: 1500, MaxMs: 1500},
+		},
+		{
+			name: "spread",
+			results: aged(
+				10*time.Second, 1*time.Second, 2*time.Second, 3*time.Second, 4*time.Second,
+				5*time.Second, 6*time.Second, 7*time.Second, 8*time.Second, 9*time.Second,
+			),
+			want: BatchShipLag{Results: 10, P50Ms: 5000, P95Ms: 10000, MaxMs: 10000},
+		},
+		{
+			name:    "future timestamps count as no lag",
+			results: aged(-2*time.Second, time.Second),
+			want:    BatchShipLag{Results: 2, P50Ms: 0, P95Ms: 1000, MaxMs: 1000},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MeasureShipLag(tt.results, received); got != tt.want {
+				t.Errorf("MeasureShipLag() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAgentShipLag_Behind(t *testing.T) {
+	threshold := 2 * time.Minute
+
+	tests := []struct {
+		name string
+		lag  AgentShipLag
+		want bool
+	}{
+		{name: "fresh", lag: AgentShipLag{P95Ms: 800, OldestQueuedAgeMs: 5000}},
+		{name: "high p95", lag: AgentShipLag{P95Ms: 120000}, want: true},
+		{name: "old queued result", lag: AgentShipLag{P95Ms: 800, OldestQueuedAgeMs: 180000}, want: true},
+		{name: "just under", lag: AgentShipLag{P95Ms: 119999, OldestQueuedAgeMs: 119999}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.lag.Behind(threshold); got != tt.want {
+				t.Errorf("Behind(%v) = %v, want %v", threshold, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package types
+
+// AgentShipment is one agent's result shipping over a window. Counts are
+// increases in the agent's heartbeat totals, so an agent restart mid-window
+// doesn't produce negative or inflated figures.
+type AgentShipment struct {
+	AgentID   string `json:"agent_id"`
+	AgentName string `json:"agent_name"`
+
+	ResultsShipped    int64 `json:"results_shipped"`
+	ResultsFailed     int64 `json:"results_failed"`
+	BatchesShipped    int64 `json:"batches_shipped"`
+	ShipFailures      int64 `json:"ship_failures"`
+	BytesShipped      int64 `json:"bytes_shipped"`      // gzip-compressed
+	BytesUncompressed int64 `json:"bytes_uncompressed"` // JSON before compression
+
+	// CompressionRatio is uncompressed over compressed bytes.
+	CompressionRatio *float64 `json:"compression_ratio,omitempty"`
+
+	// BytesPerResult is uncompressed bytes per shipped result, which stays
+	// comparable across agents with different batch sizes and target counts.
+	BytesPerResult *float64 `json:"bytes_per_result,omitempty"`
+
+	// LargePayload flags results far larger than the fleet's typical
+	// result, which usually means an agent-side payload bug.
+	LargePayload bool   `json:"large_payload,omitempty"`
+	Reason       string `json:"reason,omitempty"`
+}
+
+// FleetShipment totals result shipping across the fleet over a window.
+type FleetShipment struct {
+	Window string `json:"window"`
+	Agents int    `json:"agents"` // agents that shipped anything
+
+	ResultsShipped    int64 `json:"results_shipped"`
+	ResultsFailed     int64 `json:"results_failed"`
+	BatchesShipped    int64 `json:"batches_shipped"`
+	ShipFailures      int64 `json:"ship_failures"`
+	BytesShipped      int64 `json:"bytes_shipped"`
+	BytesUncompressed int64 `json:"bytes_uncompressed"`
+
+	// BytesPerSecond is the average compressed ingest bandwidth.
+	BytesPerSecond   float64  `json:"bytes_per_second"`
+	CompressionRatio *float64 `json:"compression_ratio,omitempty"`
+
+	// MedianBytesPerResult is the median of the agents' BytesPerResult.
+	MedianBytesPerResult *float64 `json:"median_bytes_per_result,omitempty"`
+
+	// LargePayloadAgents lists agents flagged LargePayload, largest first.
+	LargePayloadAgents []AgentShipment `json:"large_payload_agents"`
+}
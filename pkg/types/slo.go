@@ -0,0 +1,70 @@
+package types
+
+import "fmt"
+
+// =============================================================================
+// SLO SUCCESS CRITERIA
+// =============================================================================
+
+// Success criteria for ExpectedOutcome.SuccessCriteria.
+const (
+	// SuccessCriteriaReachable counts any reply as a success.
+	SuccessCriteriaReachable = "reachable"
+
+	// SuccessCriteriaSLO counts a reply as a success only if latency and
+	// packet loss are within the outcome's thresholds; a reply outside them
+	// is degraded, so availability reflects usable service.
+	SuccessCriteriaSLO = "slo"
+)
+
+// Validate checks the success criteria and SLO thresholds. A nil outcome is
+// valid.
+func (o *ExpectedOutcome) Validate() error {
+	if o == nil {
+		return nil
+	}
+	switch o.SuccessCriteria {
+	case "", SuccessCriteriaReachable:
+	case SuccessCriteriaSLO:
+		if o.MaxLatencyMs == nil && o.MaxPacketLossPct == nil {
+			return fmt.Errorf("success_criteria %q requires max_latency_ms or max_packet_loss_pct", SuccessCriteriaSLO)
+		}
+	default:
+		return fmt.Errorf("success_criteria must be %q or %q", SuccessCriteriaReachable, SuccessCriteriaSLO)
+	}
+	if o.MaxLatencyMs != nil && *o.MaxLatencyMs <= 0 {
+		return fmt.Errorf("max_latency_ms must be positive")
+	}
+	if o.MaxPacketLossPct != nil && (*o.MaxPacketLossPct < 0 || *o.MaxPacketLossPct >= 100) {
+		return fmt.Errorf("max_packet_loss_pct must be at least 0 and below 100")
+	}
+	return nil
+}
+
+// UsesSLO reports whether replies outside the SLO thresholds count as
+// degraded rather than successful.
+func (o *ExpectedOutcome) UsesSLO() bool {
+	return o != nil && o.SuccessCriteria == SuccessCriteriaSLO
+}
+
+// SLOThresholds returns the latency and loss thresholds that define
+// success, or nils when any reply is a success.
+func (o *ExpectedOutcome) SLOThresholds() (maxLatencyMs, maxPacketLossPct *float64) {
+	if !o.UsesSLO() {
+		return nil, nil
+	}
+	return o.MaxLatencyMs, o.MaxPacketLossPct
+}
+
+// MeetsSLO reports whether a measured latency and packet loss count as a
+// success. It is always true unless the outcome uses SLO criteria.
+func (o *ExpectedOutcome) MeetsSLO(latencyMs, packetLossPct float64) bool {
+	maxLatency, maxLoss := o.SLOThresholds()
+	if maxLatency != nil && latencyMs > *maxLatency {
+		return false
+	}
+	if maxLoss != nil && packetLossPct > *maxLoss {
+		return false
+	}
+	return true
+}
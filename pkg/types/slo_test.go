@@ -0,0 +1,69 @@
+package types
+
+import (
+	"strings"
+	"testing"
+)
+
+func floatPtr(f float64) *float64 { return &f }
+
+func TestExpectedOutcomeValidate_Cases(t *testing.T) {
+	tests := []struct {
+		name    string
+		outcome *ExpectedOutcome
+		wantErr string
+	}{
+		{"nil", nil, ""},
+		{"default criteria", &ExpectedOutcome{ShouldSucceed: true}, ""},
+		{"reachable", &ExpectedOutcome{SuccessCriteria: SuccessCriteriaReachable}, ""},
+		{"slo latency only", &ExpectedOutcome{SuccessCriteria: SuccessCriteriaSLO, MaxLatencyMs: floatPtr(50)}, ""},
+		{"slo zero loss", &ExpectedOutcome{SuccessCriteria: SuccessCriteriaSLO, MaxPacketLossPct: floatPtr(0)}, ""},
+		{"slo without thresholds", &ExpectedOutcome{SuccessCriteria: SuccessCriteriaSLO}, "requires max_latency_ms"},
+		{"unknown criteria", &ExpectedOutcome{SuccessCriteria: "fast"}, "success_criteria must be"},
+		{"zero latency", &ExpectedOutcome{SuccessCriteria: SuccessCriteriaSLO, MaxLatencyMs: floatPtr(0)}, "max_latency_ms must be positive"},
+		{"loss of 100", &ExpectedOutcome{SuccessCriteria: SuccessCriteriaSLO, MaxPacketLossPct: floatPtr(100)}, "max_packet_loss_pct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.outcome.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestExpectedOutcomeMeetsSLO_Cases(t *testing.T) {
+	slo := &ExpectedOutcome{SuccessCriteria: SuccessCriteriaSLO, MaxLatencyMs: floatPtr(20), MaxPacketLossPct: floatPtr(1)}
+	reachable := &ExpectedOutcome{MaxLatencyMs: floatPtr(20), MaxPacketLossPct: floatPtr(1)}
+
+	tests := []struct {
+		name    string
+		outcome *ExpectedOutcome
+		latency float64
+		loss    float64
+		want    bool
+	}{
+		{"within slo", slo, 12, 0, true},
+		{"at thresholds", slo, 20, 1, true},
+		{"latency over slo", slo, 200, 0, false},
+		{"loss over slo", slo, 12, 5, false},
+		{"reachable ignores thresholds", reachable, 200, 5, true},
+		{"nil outcome", nil, 200, 5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.outcome.MeetsSLO(tt.latency, tt.loss); got != tt.want {
+				t.Fatalf("MeetsSLO(%v, %v) = %v, want %v", tt.latency, tt.loss, got, tt.want)
+			}
+		})
+	}
+}
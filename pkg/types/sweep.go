@@ -0,0 +1,51 @@
+package types
+
+import (
+	"fmt"
+	"net"
+)
+
+// =============================================================================
+// SUBNET SWEEP
+// =============================================================================
+
+// CommandSubnetSweep asks an agent to ping every address in a subnet and
+// report which ones answer, so only live addresses need to become targets.
+const CommandSubnetSweep = "subnet_sweep"
+
+// SubnetSweepMaxHosts bounds the addresses in one sweep (a /22's worth).
+const SubnetSweepMaxHosts = 1024
+
+// SubnetSweepParams are the params of a subnet_sweep command.
+type SubnetSweepParams struct {
+	IPs []string `json:"ips"`
+}
+
+// Validate checks the sweep has addresses, no more than SubnetSweepMaxHosts,
+// and that each is an IP.
+func (p SubnetSweepParams) Validate() error {
+	if len(p.IPs) == 0 {
+		return fmt.Errorf("no addresses to sweep")
+	}
+	if len(p.IPs) > SubnetSweepMaxHosts {
+		return fmt.Errorf("sweep of %d addresses exceeds the limit of %d", len(p.IPs), SubnetSweepMaxHosts)
+	}
+	for _, ip := range p.IPs {
+		if net.ParseIP(ip) == nil {
+			return fmt.Errorf("invalid address %q", ip)
+		}
+	}
+	return nil
+}
+
+// SweepHost is an address that answered a sweep.
+type SweepHost struct {
+	IP        string  `json:"ip"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// SubnetSweepPayload is the result payload of a subnet_sweep command.
+type SubnetSweepPayload struct {
+	Scanned    int         `json:"scanned"`
+	Responsive []SweepHost `json:"responsive"`
+}
@@ -0,0 +1,41 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSubnetSweepParamsValidate_Cases(t *testing.T) {
+	tooMany := make([]string, SubnetSweepMaxHosts+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("10.%d.%d.1", i/256, i%256)
+	}
+
+	tests := []struct {
+		name    string
+		ips     []string
+		wantErr string
+	}{
+		{"valid", []string{"192.0.2.1", "192.0.2.2"}, ""},
+		{"at limit", tooMany[:SubnetSweepMaxHosts], ""},
+		{"empty", nil, "no addresses"},
+		{"over limit", tooMany, "exceeds the limit"},
+		{"not an ip", []string{"192.0.2.1", "router"}, "invalid address"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SubnetSweepParams{IPs: tt.ips}.Validate()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Validate() = %v, want error containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
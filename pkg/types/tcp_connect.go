@@ -0,0 +1,61 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// =============================================================================
+// TCP CONNECT PROBES
+// =============================================================================
+
+// ProbeTypeTCPConnect is the agent executor that times TCP connects to a
+// target port, for devices that drop ICMP but answer on a service port.
+const ProbeTypeTCPConnect = "tcp_connect"
+
+// TCPConnectDefaultPort is the port tcp_connect connects to unless told
+// otherwise.
+const TCPConnectDefaultPort = 443
+
+// TCPConnectParams are the probe_params of a tcp_connect target.
+type TCPConnectParams struct {
+	Port int `json:"port,omitempty"` // Default 443
+}
+
+// ParseTCPConnectParams decodes tcp_connect probe params, filling in the
+// default port.
+func ParseTCPConnectParams(raw json.RawMessage) (TCPConnectParams, error) {
+	var p TCPConnectParams
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return p, fmt.Errorf("invalid tcp_connect params: %w", err)
+		}
+	}
+	if p.Port == 0 {
+		p.Port = TCPConnectDefaultPort
+	}
+	if p.Port < 1 || p.Port > 65535 {
+		return p, fmt.Errorf("invalid tcp_connect port: %d", p.Port)
+	}
+	return p, nil
+}
+
+// TCPConnectPayload is the payload of a tcp_connect result. It uses the
+// ICMP payload's latency and loss fields, so results are stored, evaluated
+// and charted like pings: each connect attempt counts as a packet, and a
+// refused or timed-out attempt as a lost one. The probe succeeds when any
+// attempt connects.
+type TCPConnectPayload struct {
+	Port      int     `json:"port"`
+	Reachable bool    `json:"reachable"`
+	LatencyMs float64 `json:"latency_ms"` // Most recent successful connect
+	MinMs     float64 `json:"min_ms"`
+	MaxMs     float64 `json:"max_ms"`
+	AvgMs     float64 `json:"avg_ms"`
+
+	PacketLoss   float64 `json:"packet_loss_pct"`
+	PacketsSent  int     `json:"packets_sent"`  // Connect attempts
+	PacketsRecvd int     `json:"packets_recvd"` // Attempts that connected
+
+	DSCP int `json:"dscp,omitempty"`
+}
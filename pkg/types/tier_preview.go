@@ -0,0 +1,42 @@
+package types
+
+// TierIntervalPreview projects the probe volume of a tier at a proposed
+// probe interval, before the change is made. Probe rates count one probe
+// per assignment per interval.
+type TierIntervalPreview struct {
+	Tier             string `json:"tier"`
+	CurrentInterval  string `json:"current_interval"`
+	ProposedInterval string `json:"proposed_interval"`
+
+	// Targets are the tier's non-archived targets with probing enabled.
+	Targets int `json:"targets"`
+
+	// Assignments is the projected number of agent-target pairs: the
+	// current assignments, or targets times the selection policy's agent
+	// count when the tier hasn't been assigned yet.
+	Assignments int     `json:"assignments"`
+	FanOut      float64 `json:"fan_out"` // agents per target
+
+	CurrentProbesPerSecond  float64 `json:"current_probes_per_second"`
+	ProposedProbesPerSecond float64 `json:"proposed_probes_per_second"`
+	DeltaProbesPerSecond    float64 `json:"delta_probes_per_second"`
+
+	// ChangeFactor is proposed over current probe rate (10 means 10x).
+	ChangeFactor float64 `json:"change_factor"`
+
+	// Agents lists the load change on each agent assigned targets in the
+	// tier, most affected first. Rates cover all of the agent's tiers.
+	Agents []AgentLoadPreview `json:"agents"`
+
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// AgentLoadPreview is one agent's probe rate before and after a tier change.
+type AgentLoadPreview struct {
+	AgentID                 string  `json:"agent_id"`
+	AgentName               string  `json:"agent_name"`
+	TierAssignments         int     `json:"tier_assignments"`
+	CurrentProbesPerSecond  float64 `json:"current_probes_per_second"`
+	ProposedProbesPerSecond float64 `json:"proposed_probes_per_second"`
+	DeltaProbesPerSecond    float64 `json:"delta_probes_per_second"`
+}
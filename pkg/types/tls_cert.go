@@ -0,0 +1,128 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// =============================================================================
+// TLS CERTIFICATE CHECKS
+// =============================================================================
+
+// ProbeTypeTLSCert is the agent executor that reads a target's TLS
+// certificate.
+const ProbeTypeTLSCert = "tls_cert"
+
+// TLSCertDefaultPort is the port tls_cert connects to unless told otherwise.
+const TLSCertDefaultPort = 443
+
+// TLSCertParams are the probe_params of a tls_cert target.
+type TLSCertParams struct {
+	Port       int    `json:"port,omitempty"`        // Default 443
+	ServerName string `json:"server_name,omitempty"` // SNI and the name the chain is verified for
+}
+
+// ParseTLSCertParams decodes tls_cert probe params, filling in the default
+// port.
+func ParseTLSCertParams(raw json.RawMessage) (TLSCertParams, error) {
+	var p TLSCertParams
+	if len(raw) > 0 && string(raw) != "null" {
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return p, fmt.Errorf("invalid tls_cert params: %w", err)
+		}
+	}
+	if p.Port == 0 {
+		p.Port = TLSCertDefaultPort
+	}
+	if p.Port < 1 || p.Port > 65535 {
+		return p, fmt.Errorf("invalid tls_cert port: %d", p.Port)
+	}
+	return p, nil
+}
+
+// TLSCertPayload is the payload of a tls_cert result. A result succeeds
+// when the handshake does; expiry is judged by the control plane.
+type TLSCertPayload struct {
+	Port         int       `json:"port"`
+	ServerName   string    `json:"server_name,omitempty"`
+	HandshakeMs  float64   `json:"handshake_ms"`
+	Subject      string    `json:"subject,omitempty"`
+	Issuer       string    `json:"issuer,omitempty"`
+	DNSNames     []string  `json:"dns_names,omitempty"`
+	SerialNumber string    `json:"serial_number,omitempty"`
+	NotBefore    time.Time `json:"not_before,omitempty"`
+	NotAfter     time.Time `json:"not_after,omitempty"`
+
+	// DaysRemaining is whole days until NotAfter when probed, negative once
+	// expired.
+	DaysRemaining int `json:"days_remaining"`
+
+	// ChainValid reports whether the chain verified against the agent's
+	// system roots (and ServerName, if set) when probed.
+	ChainValid  bool   `json:"chain_valid"`
+	VerifyError string `json:"verify_error,omitempty"`
+}
+
+// DaysUntil returns whole days from now until t, rounding toward negative
+// infinity so a certificate expiring later today has 0 days left and one
+// that expired an hour ago has -1.
+func DaysUntil(t, now time.Time) int {
+	d := t.Sub(now)
+	days := int(d / (24 * time.Hour))
+	if d < 0 && d%(24*time.Hour) != 0 {
+		days--
+	}
+	return days
+}
+
+// TargetCertificate is the certificate most recently seen on a tls_cert
+// target, from any agent.
+type TargetCertificate struct {
+	TargetID    string `json:"target_id"`
+	TargetIP    string `json:"target_ip"`
+	DisplayName string `json:"display_name,omitempty"`
+	AgentID     string `json:"agent_id"`
+
+	Port         int       `json:"port"`
+	ServerName   string    `json:"server_name,omitempty"`
+	Subject      string    `json:"subject,omitempty"`
+	Issuer       string    `json:"issuer,omitempty"`
+	DNSNames     []string  `json:"dns_names,omitempty"`
+	SerialNumber string    `json:"serial_number,omitempty"`
+	NotBefore    time.Time `json:"not_before"`
+	NotAfter     time.Time `json:"not_after"`
+	ChainValid   bool      `json:"chain_valid"`
+	VerifyError  string    `json:"verify_error,omitempty"`
+	ObservedAt   time.Time `json:"observed_at"`
+
+	// DaysRemaining is computed when read, not stored.
+	DaysRemaining int `json:"days_remaining"`
+}
+
+// CertExpirySeverity grades a certificate with days left against the
+// warning and critical thresholds. Expired certificates are critical. It
+// reports false when the certificate is outside both.
+func CertExpirySeverity(daysRemaining, warningDays, criticalDays int) (AlertSeverity, bool) {
+	switch {
+	case daysRemaining < 0 || daysRemaining < criticalDays:
+		return AlertSeverityCritical, true
+	case daysRemaining < warningDays:
+		return AlertSeverityWarning, true
+	default:
+		return "", false
+	}
+}
+
+// CertificateFilter narrows a certificate listing.
+type CertificateFilter struct {
+	// WithinDays keeps certificates expiring within this many days,
+	// including expired ones (nil = all).
+	WithinDays *int
+
+	// ObservedSince leaves out certificates last seen before this time
+	// (zero = no bound).
+	ObservedSince time.Time
+
+	Limit int
+}
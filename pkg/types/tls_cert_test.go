@@ -0,0 +1,88 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseTLSCertParams_Defaults(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		wantPort int
+		wantSNI  string
+		wantErr  bool
+	}{
+		{name: "empty", raw: ``, wantPort: TLSCertDefaultPort},
+		{name: "null", raw: `null`, wantPort: TLSCertDefaultPort},
+		{name: "port and name", raw: `{"port": 8443, "server_name": "example.com"}`, wantPort: 8443, wantSNI: "example.com"},
+		{name: "port out of range", raw: `{"port": 70000}`, wantErr: true},
+		{name: "negative port", raw: `{"port": -1}`, wantErr: true},
+		{name: "not an object", raw: `[443]`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := ParseTLSCertParams(json.RawMessage(tt.raw))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseTLSCertParams() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if p.Port != tt.wantPort || p.ServerName != tt.wantSNI {
+				t.Errorf("ParseTLSCertParams() = %+v, want port %d name %q", p, tt.wantPort, tt.wantSNI)
+			}
+		})
+	}
+}
+
+func TestDaysUntil_Rounding(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name string
+		t    time.Time
+		want int
+	}{
+		{name: "later today", t: now.Add(time.Hour), want: 0},
+		{name: "exactly a day", t: now.Add(24 * time.Hour), want: 1},
+		{name: "just under two days", t: now.Add(48*time.Hour - time.Second), want: 1},
+		{name: "now", t: now, want: 0},
+		{name: "an hour ago", t: now.Add(-time.Hour), want: -1},
+		{name: "exactly a day ago", t: now.Add(-24 * time.Hour), want: -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DaysUntil(tt.t, now); got != tt.want {
+				t.Errorf("DaysUntil() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCertExpirySeverity_Thresholds(t *testing.T) {
+	tests := []struct {
+		name         string
+		days         int
+		wantSeverity AlertSeverity
+		wantAlert    bool
+	}{
+		{name: "plenty left", days: 60},
+		{name: "at warning threshold", days: 30},
+		{name: "inside warning", days: 29, wantSeverity: AlertSeverityWarning, wantAlert: true},
+		{name: "at critical threshold", days: 7, wantSeverity: AlertSeverityWarning, wantAlert: true},
+		{name: "inside critical", days: 6, wantSeverity: AlertSeverityCritical, wantAlert: true},
+		{name: "expired", days: -3, wantSeverity: AlertSeverityCritical, wantAlert: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			severity, alert := CertExpirySeverity(tt.days, 30, 7)
+			if severity != tt.wantSeverity || alert != tt.wantAlert {
+				t.Errorf("CertExpirySeverity(%d) = %q, %v; want %q, %v", tt.days, severity, alert, tt.wantSeverity, tt.wantAlert)
+			}
+		})
+	}
+}
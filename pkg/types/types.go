@@ -70,6 +70,47 @@ type Target struct {
 	// For security testing: ShouldSucceed=false (alert on success)
 	ExpectedOutcome *ExpectedOutcome `json:"expected_outcome,omitempty"`
 
+	// DSCP marks probe packets with a specific traffic class (0-63).
+	// Overrides the tier's DSCP; nil inherits from the tier.
+	DSCP *int `json:"dscp,omitempty"`
+
+	// Region is an explicit region for in-market computation. Manual
+	// targets without a subnet set this; when present it takes precedence
+	// over the subnet's region.
+	Region string `json:"region,omitempty"`
+
+	// RetentionDays keeps this target's raw probe results longer than the
+	// global policy. Overrides the tier's; nil inherits from the tier.
+	RetentionDays *int `json:"retention_days,omitempty"`
+
+	// DownThresholdSeconds is how long the target may go without responding
+	// before it is marked DOWN, for endpoints that sleep or are otherwise
+	// flaky. Nil uses the state worker's threshold.
+	DownThresholdSeconds *int `json:"down_threshold_seconds,omitempty"`
+
+	// ProbingEnabled false stops all probing of the target: no agents are
+	// assigned to it. Unlike archiving or the INACTIVE state, the target keeps
+	// its monitoring state and history and resumes where it left off.
+	ProbingEnabled   bool       `json:"probing_enabled"`
+	ProbingChangedAt *time.Time `json:"probing_changed_at,omitempty"`
+
+	// ProbeType names the agent executor that probes the target, e.g. a
+	// custom check registered as an agent plugin. Empty means icmp_ping.
+	// The control plane doesn't interpret it or ProbeParams; both are
+	// passed through in assignments.
+	ProbeType   string          `json:"probe_type,omitempty"`
+	ProbeParams json.RawMessage `json:"probe_params,omitempty"`
+
+	// Origin network of the target IP, looked up from the IP-to-ASN
+	// dataset when one is configured. Only target detail reads these.
+	ASN     *int   `json:"asn,omitempty"`
+	ASName  string `json:"as_name,omitempty"`
+	Country string `json:"country,omitempty"`
+
+	// Endpoints are additional addresses (e.g. the target's IPv6 address)
+	// or ports probed alongside IP. Only target detail reads these.
+	Endpoints []TargetEndpoint `json:"endpoints,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -142,6 +183,122 @@ func (t *Target) Validate() error {
 	if t.Tier == "" {
 		return fmt.Errorf("target tier is required")
 	}
+	if err := ValidateRetentionDays(t.RetentionDays); err != nil {
+		return err
+	}
+	if err := ValidateDownThreshold(t.DownThresholdSeconds); err != nil {
+		return err
+	}
+	if err := t.ExpectedOutcome.Validate(); err != nil {
+		return err
+	}
+	if err := ValidateProbeType(t.ProbeType, t.ProbeParams); err != nil {
+		return err
+	}
+	return ValidateDSCP(t.DSCP)
+}
+
+// DefaultProbeType is the executor targets are probed with unless they
+// name another.
+const DefaultProbeType = "icmp_ping"
+
+// MaxProbeTypeLength bounds an executor name.
+const MaxProbeTypeLength = 64
+
+// EffectiveProbeType returns the target's probe type, defaulting to
+// icmp_ping.
+func (t *Target) EffectiveProbeType() string {
+	if t.ProbeType == "" {
+		return DefaultProbeType
+	}
+	return t.ProbeType
+}
+
+// ValidateProbeType checks that a probe type looks like an executor name
+// (lowercase letters, digits and underscores) and that its params, if
+// any, are a JSON object; tls_cert and tcp_connect params are checked in
+// full. Whether an agent has the executor is not checked here: agents
+// report their executors at registration.
+func ValidateProbeType(probeType string, params json.RawMessage) error {
+	if len(probeType) > MaxProbeTypeLength {
+		return fmt.Errorf("probe_type must be at most %d characters", MaxProbeTypeLength)
+	}
+	for _, c := range probeType {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' {
+			return fmt.Errorf("probe_type must be lowercase letters, digits and underscores: %q", probeType)
+		}
+	}
+	if len(params) > 0 && string(params) != "null" {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(params, &obj); err != nil {
+			return fmt.Errorf("probe_params must be a JSON object")
+		}
+	}
+	switch probeType {
+	case ProbeTypeTLSCert:
+		if _, err := ParseTLSCertParams(params); err != nil {
+			return err
+		}
+	case ProbeTypeTCPConnect:
+		if _, err := ParseTCPConnectParams(params); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MaxDSCP is the largest valid DSCP codepoint (6 bits).
+const MaxDSCP = 63
+
+// ValidateDSCP checks that an optional DSCP value is in range.
+func ValidateDSCP(dscp *int) error {
+	if dscp != nil && (*dscp < 0 || *dscp > MaxDSCP) {
+		return fmt.Errorf("dscp must be between 0 and %d", MaxDSCP)
+	}
+	return nil
+}
+
+// MaxRetentionDays is the longest raw probe retention override (5 years).
+// It matches the probe_results_archive retention policy.
+const MaxRetentionDays = 1825
+
+// ValidateRetentionDays checks that an optional retention override is in range.
+func ValidateRetentionDays(days *int) error {
+	if days != nil && (*days < 1 || *days > MaxRetentionDays) {
+		return fmt.Errorf("retention_days must be between 1 and %d", MaxRetentionDays)
+	}
+	return nil
+}
+
+// Bounds on a target's down threshold override.
+const (
+	MinDownThresholdSeconds = 60
+	MaxDownThresholdSeconds = 7 * 24 * 60 * 60
+)
+
+// ValidateDownThreshold checks that an optional down threshold override is
+// in range.
+func ValidateDownThreshold(seconds *int) error {
+	if seconds != nil && (*seconds < MinDownThresholdSeconds || *seconds > MaxDownThresholdSeconds) {
+		return fmt.Errorf("down_threshold_seconds must be between %d and %d", MinDownThresholdSeconds, MaxDownThresholdSeconds)
+	}
+	return nil
+}
+
+// EffectiveDownThreshold returns the target's down threshold override, or
+// def when it has none.
+func (t *Target) EffectiveDownThreshold(def time.Duration) time.Duration {
+	if t.DownThresholdSeconds == nil {
+		return def
+	}
+	return time.Duration(*t.DownThresholdSeconds) * time.Second
+}
+
+// ValidateMinAgents checks that an optional tier minimum agent count is positive.
+func ValidateMinAgents(n *int) error {
+	if n != nil && *n < 1 {
+		return fmt.Errorf("min_agents must be at least 1")
+	}
 	return nil
 }
 
@@ -160,6 +317,16 @@ type ExpectedOutcome struct {
 
 	// AlertMessage is a custom message for the alert.
 	AlertMessage string `json:"alert_message,omitempty"`
+
+	// SuccessCriteria defines what counts as success for SLA purposes:
+	// "reachable" (default) counts any reply, "slo" counts a reply only if
+	// it is within the latency and loss thresholds below. See slo.go.
+	SuccessCriteria string `json:"success_criteria,omitempty"`
+
+	// MaxLatencyMs and MaxPacketLossPct are the SLO thresholds used when
+	// SuccessCriteria is "slo". Either may be omitted.
+	MaxLatencyMs     *float64 `json:"max_latency_ms,omitempty"`
+	MaxPacketLossPct *float64 `json:"max_packet_loss_pct,omitempty"`
 }
 
 // =============================================================================
@@ -254,6 +421,10 @@ type ActivityLogEntry struct {
 	CreatedAt time.Time `json:"created_at"`
 }
 
+// ActivityEventServiceStatusChanged is logged on a subnet when its service
+// status changes in Pilot; details carry from_status and to_status.
+const ActivityEventServiceStatusChanged = "service_status_changed"
+
 // TargetEnriched is a target with denormalized subnet metadata.
 // Used for API responses to avoid N+1 queries.
 type TargetEnriched struct {
@@ -294,13 +465,87 @@ type Tier struct {
 	ProbeTimeout  time.Duration `json:"probe_timeout"`
 	ProbeRetries  int           `json:"probe_retries"`
 
+	// AdaptiveMaxInterval lets agents with adaptive probing on back stable
+	// targets off from ProbeInterval up to this interval. Zero keeps the
+	// tier at ProbeInterval. Keep it under the control plane's evaluation
+	// window or backed-off targets drop out of evaluation.
+	AdaptiveMaxInterval time.Duration `json:"adaptive_max_interval,omitempty"`
+
+	// DownBackoffMaxInterval lets agents back off probing a target the
+	// control plane holds DOWN or EXCLUDED, doubling its interval after each
+	// failed probe up to this cap and returning to ProbeInterval on the
+	// first response. Zero probes such targets at full cadence.
+	DownBackoffMaxInterval time.Duration `json:"down_backoff_max_interval,omitempty"`
+
 	// Agent selection policy
 	AgentSelection AgentSelectionPolicy `json:"agent_selection"`
 
 	// Default expected outcome for targets in this tier (can be overridden per-target)
 	DefaultExpectedOutcome *ExpectedOutcome `json:"default_expected_outcome,omitempty"`
+
+	// DSCP marking for probes in this tier (can be overridden per-target).
+	// nil sends probes unmarked (best effort).
+	DSCP *int `json:"dscp,omitempty"`
+
+	// RetentionDays keeps raw probe results for targets in this tier longer
+	// than the global policy (can be overridden per-target).
+	RetentionDays *int `json:"retention_days,omitempty"`
+
+	// IngestMode selects how buffered results for this tier are persisted
+	// (see TierIngestMode*). Empty is raw.
+	IngestMode string `json:"ingest_mode,omitempty"`
+
+	// AggregateBucket is the rollup width for aggregated ingest modes.
+	// Zero is one minute.
+	AggregateBucket time.Duration `json:"aggregate_bucket,omitempty"`
+
+	// RawRetention is how long the "aggregate" ingest mode keeps raw
+	// results. Zero uses the control plane default.
+	RawRetention time.Duration `json:"raw_retention,omitempty"`
+
+	// MinAgents is how many agents must be reporting on a target in this
+	// tier before it raises a coverage alert. nil uses the tier default
+	// (every assigned agent for pilot_infra, 3 for vlan, 2 otherwise).
+	MinAgents *int `json:"min_agents,omitempty"`
 }
 
+// Tier ingest modes. Aggregated modes store per-(agent, target) rollups,
+// one minute wide unless the tier sets AggregateBucket, in probe_1min.
+// They only take effect when the control plane runs with the Redis result
+// buffer; otherwise every tier ingests raw.
+const (
+	// TierIngestRaw persists every probe result to probe_results.
+	TierIngestRaw = "raw"
+
+	// TierIngestAggregate persists aggregates and keeps raw results only
+	// briefly (RawRetention), long enough for alert evaluation and live
+	// status.
+	TierIngestAggregate = "aggregate"
+
+	// TierIngestAggregateOnly persists aggregates and no raw
+	// results. Raw-result consumers (alerting, status, baselines) see
+	// nothing for the tier.
+	TierIngestAggregateOnly = "aggregate_only"
+)
+
+// ValidateTierIngestMode checks that an ingest mode is known. Empty is
+// allowed and means raw.
+func ValidateTierIngestMode(mode string) error {
+	switch mode {
+	case "", TierIngestRaw, TierIngestAggregate, TierIngestAggregateOnly:
+		return nil
+	}
+	return fmt.Errorf("ingest_mode must be one of %s, %s, %s", TierIngestRaw, TierIngestAggregate, TierIngestAggregateOnly)
+}
+
+// The pipeline canary is a reserved target, seeded by migration, that every
+// agent probes at its own loopback. Its results prove that probes are being
+// shipped, buffered, flushed and evaluated end to end.
+const (
+	CanaryTargetID = "00000000-0000-0000-0000-00000000ca11"
+	CanaryTierName = "canary"
+)
+
 // AgentSelectionPolicy defines which agents monitor targets in a tier.
 type AgentSelectionPolicy struct {
 	// Strategy: "all" (every agent) or "distributed" (subset of agents)
@@ -337,16 +582,54 @@ type DiversityRequirement struct {
 	MinProviders int `json:"min_providers,omitempty"`
 }
 
+// Tier probe timing bounds. A timeout at or over the interval lets a slow
+// target's probes overlap, piling up in-flight probes on every agent.
+const (
+	TierMinProbeInterval = time.Second
+	TierMinProbeTimeout  = 100 * time.Millisecond
+	TierMaxProbeRetries  = 5
+)
+
+// Tier aggregation bounds. Buckets are whole minutes dividing an hour, so
+// every tier's buckets line up on the hour. Raw retention must outlast the
+// alert evaluation windows that read raw rows, and stay clear of the
+// probe_results compression delay (3h) so pruning never has to rewrite
+// compressed chunks.
+const (
+	TierMaxAggregateBucket = time.Hour
+	TierMinRawRetention    = 30 * time.Minute
+	TierMaxRawRetention    = 150 * time.Minute
+)
+
+// ValidateTierAggregation checks a tier's aggregate bucket and raw
+// retention. Zero is allowed for either and means the default.
+func ValidateTierAggregation(bucket, rawRetention time.Duration) error {
+	if bucket != 0 && (bucket < time.Minute || bucket > TierMaxAggregateBucket ||
+		bucket%time.Minute != 0 || TierMaxAggregateBucket%bucket != 0) {
+		return fmt.Errorf("aggregate_bucket must be a whole number of minutes that divides %s", TierMaxAggregateBucket)
+	}
+	if rawRetention != 0 && (rawRetention < TierMinRawRetention || rawRetention > TierMaxRawRetention) {
+		return fmt.Errorf("raw_retention must be between %s and %s", TierMinRawRetention, TierMaxRawRetention)
+	}
+	return nil
+}
+
 // Validate checks that the tier configuration is valid.
 func (t *Tier) Validate() error {
 	if t.Name == "" {
 		return fmt.Errorf("tier name is required")
 	}
-	if t.ProbeInterval <= 0 {
-		return fmt.Errorf("probe_interval must be positive")
+	if t.ProbeInterval < TierMinProbeInterval {
+		return fmt.Errorf("probe_interval must be at least %s", TierMinProbeInterval)
+	}
+	if t.ProbeTimeout < TierMinProbeTimeout {
+		return fmt.Errorf("probe_timeout must be at least %s", TierMinProbeTimeout)
+	}
+	if t.ProbeTimeout >= t.ProbeInterval {
+		return fmt.Errorf("probe_timeout (%s) must be shorter than probe_interval (%s)", t.ProbeTimeout, t.ProbeInterval)
 	}
-	if t.ProbeTimeout <= 0 {
-		return fmt.Errorf("probe_timeout must be positive")
+	if t.ProbeRetries < 0 || t.ProbeRetries > TierMaxProbeRetries {
+		return fmt.Errorf("probe_retries must be between 0 and %d", TierMaxProbeRetries)
 	}
 	if t.AgentSelection.Strategy != "all" && t.AgentSelection.Strategy != "distributed" {
 		return fmt.Errorf("agent_selection.strategy must be 'all' or 'distributed'")
@@ -354,7 +637,16 @@ func (t *Tier) Validate() error {
 	if t.AgentSelection.Strategy == "distributed" && t.AgentSelection.Count <= 0 {
 		return fmt.Errorf("agent_selection.count must be positive for distributed strategy")
 	}
-	return nil
+	if t.DownBackoffMaxInterval != 0 && t.DownBackoffMaxInterval <= t.ProbeInterval {
+		return fmt.Errorf("down_backoff_max_interval (%s) must be longer than probe_interval (%s)", t.DownBackoffMaxInterval, t.ProbeInterval)
+	}
+	if err := ValidateRetentionDays(t.RetentionDays); err != nil {
+		return err
+	}
+	if err := ValidateTierAggregation(t.AggregateBucket, t.RawRetention); err != nil {
+		return err
+	}
+	return ValidateDSCP(t.DSCP)
 }
 
 // =============================================================================
@@ -400,6 +692,40 @@ type Agent struct {
 
 	// Tailscale integration
 	TailscaleIP *string `json:"tailscale_ip,omitempty"`
+
+	// Host details reported at registration (nil for older agents)
+	SystemInfo *AgentSystemInfo `json:"system_info,omitempty"`
+}
+
+// AgentSystemInfo describes the host an agent runs on, for explaining
+// platform-specific probe behavior (e.g. unprivileged ICMP sockets timestamp
+// replies later than raw sockets, and virtual NICs add jitter).
+type AgentSystemInfo struct {
+	OS            string `json:"os"`             // runtime.GOOS
+	Arch          string `json:"arch"`           // runtime.GOARCH
+	Platform      string `json:"platform"`       // Distribution and version, e.g. "ubuntu 22.04"
+	KernelVersion string `json:"kernel_version"` // e.g. "6.1.0-18-amd64"
+
+	// Virtualization is the detected hypervisor or container runtime
+	// ("kvm", "xen", "docker", ...); empty on bare metal or when unknown.
+	Virtualization     string `json:"virtualization,omitempty"`
+	VirtualizationRole string `json:"virtualization_role,omitempty"` // "guest" or "host"
+
+	// Interface is the default-route interface and NICDriver its kernel
+	// driver ("virtio_net", "ixgbe", ...; empty for virtual interfaces).
+	Interface string `json:"interface,omitempty"`
+	NICDriver string `json:"nic_driver,omitempty"`
+
+	// RawSocket is true if the agent can open raw ICMP sockets (root or
+	// CAP_NET_RAW); UnprivilegedICMP is true if its group falls within
+	// net.ipv4.ping_group_range, allowing ICMP datagram sockets.
+	RawSocket        bool `json:"raw_socket"`
+	UnprivilegedICMP bool `json:"unprivileged_icmp"`
+
+	// ICMPMode is how the icmp_ping executor reaches targets: "raw",
+	// "dgram" (unprivileged ICMP socket) or "tcp" (connect fallback).
+	// Empty if no mode was usable or the agent predates mode detection.
+	ICMPMode string `json:"icmp_mode,omitempty"`
 }
 
 // AgentStatus represents the health state of an agent.
@@ -411,6 +737,31 @@ const (
 	AgentStatusOffline  AgentStatus = "offline"
 )
 
+// AgentHealthExclusion leaves an agent's results for one target, or for every
+// target in a subnet, out of health and alert computation. The agent keeps
+// probing and its raw results are still stored and displayed.
+type AgentHealthExclusion struct {
+	ID        string    `json:"id"`
+	AgentID   string    `json:"agent_id"`
+	TargetID  string    `json:"target_id,omitempty"` // set for a single target
+	SubnetID  string    `json:"subnet_id,omitempty"` // set for a whole subnet
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate checks that the exclusion names an agent and exactly one scope.
+func (e *AgentHealthExclusion) Validate() error {
+	if e.AgentID == "" {
+		return fmt.Errorf("agent_id is required")
+	}
+	if (e.TargetID == "") == (e.SubnetID == "") {
+		return fmt.Errorf("exactly one of target_id or subnet_id is required")
+	}
+	return nil
+}
+
 // =============================================================================
 // ASSIGNMENT
 // =============================================================================
@@ -430,9 +781,33 @@ type Assignment struct {
 	// Probe-specific parameters
 	ProbeParams json.RawMessage `json:"probe_params,omitempty"`
 
+	// DSCP codepoint to mark probe packets with (0 = best effort)
+	DSCP int `json:"dscp,omitempty"`
+
 	// For correlation and alerting
 	Tags            map[string]string `json:"tags,omitempty"`
 	ExpectedOutcome *ExpectedOutcome  `json:"expected_outcome,omitempty"`
+
+	// EndpointID is set when the assignment probes one of the target's
+	// additional endpoints rather than its primary IP. Results carry it
+	// back so the control plane can report endpoints separately.
+	EndpointID string `json:"endpoint_id,omitempty"`
+
+	// BackoffMaxInterval is set while the target is DOWN or EXCLUDED and
+	// its tier allows backing off: the agent doubles the target's interval
+	// after each failed probe, up to this cap, and resets it on the first
+	// response.
+	BackoffMaxInterval time.Duration `json:"backoff_max_interval,omitempty"`
+}
+
+// ProbeID identifies what the assignment probes: the endpoint for endpoint
+// assignments, otherwise the target. A target and its endpoints share a
+// TargetID, so agents key their schedules on this instead.
+func (a *Assignment) ProbeID() string {
+	if a.EndpointID != "" {
+		return a.EndpointID
+	}
+	return a.TargetID
 }
 
 // AssignmentSet is a versioned collection of assignments for an agent.
@@ -507,6 +882,10 @@ type ProbeResult struct {
 	TargetID string `json:"target_id"`
 	AgentID  string `json:"agent_id"`
 
+	// EndpointID is set for results from one of the target's additional
+	// endpoints; empty means the target's primary IP.
+	EndpointID string `json:"endpoint_id,omitempty"`
+
 	// Timing
 	Timestamp time.Time     `json:"timestamp"`
 	Duration  time.Duration `json:"duration"`
@@ -526,6 +905,11 @@ type ResultBatch struct {
 	BatchID   string        `json:"batch_id"`
 	Results   []ProbeResult `json:"results"`
 	CreatedAt time.Time     `json:"created_at"`
+
+	// Sequence increases with every batch an agent ships (0 = not set, from
+	// older agents). The control plane acknowledges but does not re-insert a
+	// batch at or below the highest sequence it has accepted from the agent.
+	Sequence int64 `json:"sequence,omitempty"`
 }
 
 // =============================================================================
@@ -543,6 +927,65 @@ type ICMPPingPayload struct {
 	PacketLoss   float64 `json:"packet_loss_pct"`
 	PacketsSent  int     `json:"packets_sent"`
 	PacketsRecvd int     `json:"packets_recvd"`
+
+	// Source binding on multi-homed agents (empty if unbound)
+	SourceAddress   string `json:"source_address,omitempty"`
+	SourceInterface string `json:"source_interface,omitempty"`
+
+	// DSCP codepoint the probe was marked with (0 = best effort)
+	DSCP int `json:"dscp,omitempty"`
+
+	// TTL of the most recent echo reply (0 = not reported)
+	ReplyTTL int `json:"reply_ttl,omitempty"`
+
+	// How the agent probed: "raw", "dgram" or "tcp" (empty = raw, from
+	// agents that predate mode detection). tcp RTTs are connect times and
+	// not directly comparable with ICMP.
+	Mode string `json:"mode,omitempty"`
+}
+
+// initialTTLs are the starting TTLs in common use (Linux/BSD, Windows,
+// network gear), in ascending order.
+var initialTTLs = []int{64, 128, 255}
+
+// InferHopCount estimates the hop count of an echo reply's return path from
+// its remaining TTL, assuming the sender used the smallest common initial
+// TTL not below it. Returns -1 for an invalid TTL.
+func InferHopCount(replyTTL int) int {
+	if replyTTL <= 0 || replyTTL > 255 {
+		return -1
+	}
+	for _, initial := range initialTTLs {
+		if replyTTL <= initial {
+			return initial - replyTTL
+		}
+	}
+	return -1
+}
+
+// HopCountChange records a material change in a target's observed hop
+// count from one agent, usually a routing change or path failover.
+type HopCountChange struct {
+	ID           string    `json:"id"`
+	TargetID     string    `json:"target_id"`
+	AgentID      string    `json:"agent_id"`
+	AgentName    string    `json:"agent_name,omitempty"`
+	DetectedAt   time.Time `json:"detected_at"`
+	PreviousTTL  int       `json:"previous_ttl"`
+	CurrentTTL   int       `json:"current_ttl"`
+	PreviousHops int       `json:"previous_hops"`
+	CurrentHops  int       `json:"current_hops"`
+	AlertID      *string   `json:"alert_id,omitempty"`
+}
+
+// HopCountPoint is one bucket of a target's hop-count history from an agent.
+type HopCountPoint struct {
+	Time      time.Time `json:"time"`
+	AgentID   string    `json:"agent_id"`
+	AgentName string    `json:"agent_name,omitempty"`
+	ReplyTTL  int       `json:"reply_ttl"`
+	HopCount  int       `json:"hop_count"`
+	Samples   int       `json:"samples"`
 }
 
 // MTRPayload contains MTR trace results.
@@ -550,6 +993,13 @@ type MTRPayload struct {
 	DestinationReached bool     `json:"destination_reached"`
 	TotalHops          int      `json:"total_hops"`
 	Hops               []MTRHop `json:"hops"`
+
+	// Source binding on multi-homed agents (empty if unbound)
+	SourceAddress   string `json:"source_address,omitempty"`
+	SourceInterface string `json:"source_interface,omitempty"`
+
+	// DSCP codepoint the probe was marked with (0 = best effort)
+	DSCP int `json:"dscp,omitempty"`
 }
 
 // MTRHop represents a single hop in an MTR trace.
@@ -567,16 +1017,6 @@ type MTRHop struct {
 	StdDevMs float64 `json:"stddev_ms"`
 }
 
-// TCPConnectPayload contains TCP connection test results.
-type TCPConnectPayload struct {
-	Connected  bool    `json:"connected"`
-	Port       int     `json:"port"`
-	LatencyMs  float64 `json:"latency_ms"`
-	Error      string  `json:"error,omitempty"`
-	TLSVersion string  `json:"tls_version,omitempty"`
-	TLSCipher  string  `json:"tls_cipher,omitempty"`
-}
-
 // =============================================================================
 // COMMAND (On-Demand Execution)
 // =============================================================================
@@ -726,12 +1166,52 @@ type Heartbeat struct {
 	// Task stats
 	ActiveTargets   int   `json:"active_targets"`
 	ProbesPerSecond int   `json:"probes_per_second"`
-	ResultsQueued   int   `json:"results_queued"`
+	ResultsQueued   int   `json:"results_queued"` // awaiting shipping plus targets waiting for a probe worker
 	ResultsShipped  int64 `json:"results_shipped_total"`
 
+	// Result shipping totals since agent start. Bytes are request bodies of
+	// accepted batches, gzip-compressed and before compression; their ratio
+	// shows how well payloads compress. ShipFailures counts failed send
+	// attempts; ResultsFailed counts results dropped after the last attempt.
+	ResultsFailed            int64 `json:"results_failed_total"`
+	BatchesShipped           int64 `json:"batches_shipped_total"`
+	ShipFailures             int64 `json:"ship_failures_total"`
+	BytesShipped             int64 `json:"bytes_shipped_total"`
+	BytesShippedUncompressed int64 `json:"bytes_shipped_uncompressed_total"`
+
+	// ShippingEndpoint is the results URL the agent is shipping to; it
+	// differs from the primary while failed over. EndpointFailovers counts
+	// switches to a failover endpoint since agent start.
+	ShippingEndpoint  string `json:"shipping_endpoint,omitempty"`
+	EndpointFailovers int64  `json:"endpoint_failovers_total"`
+
+	// OldestQueuedAgeMs is the age of the oldest result the agent has not
+	// yet shipped, 0 when its queue is empty. It grows while shipping is
+	// backed up.
+	OldestQueuedAgeMs int64 `json:"oldest_queued_age_ms"`
+
+	// ProbesShedByTier counts targets dropped from the agent's probe queue
+	// per tier since start. Low-priority tiers are shed first under overload.
+	ProbesShedByTier map[string]int64 `json:"probes_shed_by_tier,omitempty"`
+
+	// ProbesBackedOffByTier counts targets per tier currently probed less
+	// often than their tier interval because they keep failing while down.
+	ProbesBackedOffByTier map[string]int `json:"probes_backed_off_by_tier,omitempty"`
+
+	// EffectiveIntervals counts targets per effective probe interval, per
+	// tier, when adaptive probing is on.
+	EffectiveIntervals map[string]map[string]int `json:"effective_intervals,omitempty"`
+
+	// ScheduleAlignment is each tier's probe loop alignment ("aligned" or
+	// "drifting").
+	ScheduleAlignment map[string]string `json:"schedule_alignment,omitempty"`
+
 	// Assignment sync state
 	AssignmentVersion int64 `json:"assignment_version"`
 
+	// ConfigVersion is the remote config version the agent has applied
+	ConfigVersion int64 `json:"config_version"`
+
 	// Network info
 	PublicIP string `json:"public_ip"`
 }
@@ -750,6 +1230,7 @@ type HeartbeatResponse struct {
 
 	// Hints for agent behavior
 	AssignmentStale bool `json:"assignment_stale,omitempty"` // Should re-sync assignments
+	ConfigStale     bool `json:"config_stale,omitempty"`     // Should re-fetch remote config
 
 	// Pending commands to execute
 	Commands []Command `json:"commands,omitempty"`
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestValidateProbeType_Values(t *testing.T) {
+	tests := []struct {
+		name      string
+		probeType string
+		params    string
+		wantErr   bool
+	}{
+		{name: "default"},
+		{name: "plugin with params", probeType: "tls_cert", params: `{"port": 8443}`},
+		{name: "null params", probeType: "tls_cert", params: `null`},
+		{name: "uppercase", probeType: "TLS_Expiry", wantErr: true},
+		{name: "punctuation", probeType: "tls-expiry", wantErr: true},
+		{name: "params not an object", probeType: "tls_cert", params: `[443]`, wantErr: true},
+		{name: "too long", probeType: string(make([]byte, MaxProbeTypeLength+1)), wantErr: true},
+		{name: "tcp connect default port", probeType: "tcp_connect"},
+		{name: "tcp connect port", probeType: "tcp_connect", params: `{"port": 22}`},
+		{name: "tcp connect port out of range", probeType: "tcp_connect", params: `{"port": 70000}`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateProbeType(tt.probeType, json.RawMessage(tt.params))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateProbeType(%q, %s) error = %v, wantErr %v", tt.probeType, tt.params, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTarget_EffectiveProbeType(t *testing.T) {
+	if got := (&Target{}).EffectiveProbeType(); got != DefaultProbeType {
+		t.Errorf("EffectiveProbeType() = %q, want %q", got, DefaultProbeType)
+	}
+	if got := (&Target{ProbeType: "tls_cert"}).EffectiveProbeType(); got != "tls_cert" {
+		t.Errorf("EffectiveProbeType() = %q, want tls_cert", got)
+	}
+}
+
+func TestTarget_EffectiveDownThreshold(t *testing.T) {
+	secs := func(n int) *int { return &n }
+
+	tests := []struct {
+		name     string
+		override *int
+		want     time.Duration
+		wantErr  bool
+	}{
+		{name: "inherits", want: 15 * time.Minute},
+		{name: "longer grace", override: secs(4 * 60 * 60), want: 4 * time.Hour},
+		{name: "minimum", override: secs(MinDownThresholdSeconds), want: time.Minute},
+		{name: "too short", override: secs(30), wantErr: true},
+		{name: "too long", override: secs(MaxDownThresholdSeconds + 1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDownThreshold(tt.override); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateDownThreshold() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			target := &Target{DownThresholdSeconds: tt.override}
+			if got := target.EffectiveDownThreshold(15 * time.Minute); got != tt.want {
+				t.Errorf("EffectiveDownThreshold() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTier_ValidateAggregation(t *testing.T) {
+	tests := []struct {
+		name         string
+		bucket       time.Duration
+		rawRetention time.Duration
+		wantErr      bool
+	}{
+		{name: "defaults"},
+		{name: "five minutes", bucket: 5 * time.Minute, rawRetention: time.Hour},
+		{name: "bounds", bucket: TierMaxAggregateBucket, rawRetention: TierMaxRawRetention},
+		{name: "sub-minute bucket", bucket: 30 * time.Second, wantErr: true},
+		{name: "bucket not dividing an hour", bucket: 7 * time.Minute, wantErr: true},
+		{name: "bucket not whole minutes", bucket: 90 * time.Second, wantErr: true},
+		{name: "bucket over an hour", bucket: 2 * time.Hour, wantErr: true},
+		{name: "retention too short", rawRetention: TierMinRawRetention - time.Minute, wantErr: true},
+		{name: "retention too long", rawRetention: TierMaxRawRetention + time.Minute, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier := &Tier{
+				Name:           "test",
+				ProbeInterval:  30 * time.Second,
+				ProbeTimeout:   5 * time.Second,
+				AgentSelection: AgentSelectionPolicy{Strategy: "all"},
+				IngestMode:     TierIngestAggregate,
+
+				AggregateBucket: tt.bucket,
+				RawRetention:    tt.rawRetention,
+			}
+			if err := tier.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTier_ValidateTiming(t *testing.T) {
+	tests := []struct {
+		name     string
+		interval time.Duration
+		timeout  time.Duration
+		retries  int
+		backoff  time.Duration
+		wantErr  bool
+	}{
+		{name: "valid", interval: 30 * time.Second, timeout: 5 * time.Second, retries: 2},
+		{name: "down backoff", interval: 30 * time.Second, timeout: 5 * time.Second, backoff: 10 * time.Minute},
+		{name: "down backoff not above interval", interval: 30 * time.Second, timeout: 5 * time.Second, backoff: 30 * time.Second, wantErr: true},
+		{name: "minimums", interval: TierMinProbeInterval, timeout: TierMinProbeTimeout},
+		{name: "timeout equals interval", interval: 5 * time.Second, timeout: 5 * time.Second, wantErr: true},
+		{name: "timeout over interval", interval: 5 * time.Second, timeout: 10 * time.Second, wantErr: true},
+		{name: "interval below floor", interval: 500 * time.Millisecond, timeout: 200 * time.Millisecond, wantErr: true},
+		{name: "timeout below floor", interval: 5 * time.Second, timeout: 50 * time.Millisecond, wantErr: true},
+		{name: "negative retries", interval: 30 * time.Second, timeout: 5 * time.Second, retries: -1, wantErr: true},
+		{name: "too many retries", interval: 30 * time.Second, timeout: 5 * time.Second, retries: TierMaxProbeRetries + 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tier := &Tier{
+				Name:           "test",
+				ProbeInterval:  tt.interval,
+				ProbeTimeout:   tt.timeout,
+				ProbeRetries:   tt.retries,
+				AgentSelection: AgentSelectionPolicy{Strategy: "all"},
+
+				DownBackoffMaxInterval: tt.backoff,
+			}
+			if err := tier.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package types
+
+import "time"
+
+// =============================================================================
+// ALERT WEBHOOKS
+// =============================================================================
+
+// alert_config keys read by the webhook notifier for every event, so
+// changes apply without a restart.
+const (
+	// AlertConfigWebhookURLs is a comma-separated list of http(s) URLs each
+	// alert event is POSTed to. Unset or empty disables webhooks.
+	AlertConfigWebhookURLs = "webhook_urls"
+
+	// AlertConfigWebhookSecret keys the HMAC-SHA256 signature sent with
+	// each delivery. It is redacted wherever alert_config is listed.
+	AlertConfigWebhookSecret = "webhook_secret"
+)
+
+// RedactedAlertConfigValue replaces secret alert_config values in listings.
+const RedactedAlertConfigValue = "[redacted]"
+
+// WebhookDeliveryStats counts alert webhook deliveries across every URL
+// since the first one. A delivery is one event to one URL, however many
+// attempts it took; Failed counts deliveries that failed every attempt.
+type WebhookDeliveryStats struct {
+	Delivered    int64      `json:"delivered"`
+	Failed       int64      `json:"failed"`
+	LastFailedAt *time.Time `json:"last_failed_at,omitempty"`
+	LastError    string     `json:"last_error,omitempty"`
+}
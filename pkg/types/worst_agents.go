@@ -0,0 +1,87 @@
+package types
+
+import (
+	"sort"
+	"time"
+)
+
+// =============================================================================
+// WORST AGENTS
+// =============================================================================
+
+// AgentPathStats is one agent's recent probing of a target.
+type AgentPathStats struct {
+	AgentID       string `json:"agent_id"`
+	AgentName     string `json:"agent_name"`
+	AgentRegion   string `json:"agent_region"`
+	AgentProvider string `json:"agent_provider"`
+	InMarket      bool   `json:"in_market"`
+
+	// HealthExcluded is set when the agent is excluded from the target's
+	// health, so its results don't move the target's state.
+	HealthExcluded bool `json:"health_excluded"`
+
+	Probes        int64     `json:"probes"`
+	Successes     int64     `json:"successes"`
+	PacketLossPct float64   `json:"packet_loss_pct"`
+	AvgLatencyMs  *float64  `json:"avg_latency_ms"`
+	P95LatencyMs  *float64  `json:"p95_latency_ms"`
+	LastProbeAt   time.Time `json:"last_probe_at"`
+}
+
+// TargetWorstAgents ranks the agents probing a target, worst first.
+type TargetWorstAgents struct {
+	TargetID string `json:"target_id"`
+	Window   string `json:"window"`
+
+	// AgentCount is every agent that reported in the window, and
+	// AgentsWithLoss those that saw any loss; Agents may be truncated. Loss
+	// on most agents points at the target, loss on a few at their paths.
+	AgentCount     int              `json:"agent_count"`
+	AgentsWithLoss int              `json:"agents_with_loss"`
+	Agents         []AgentPathStats `json:"agents"`
+}
+
+// RankWorstAgents sorts agents worst first: by packet loss, then average
+// latency, with agents that have no latency after those that do at equal
+// loss. Ties fall back to agent name so the order is stable.
+func RankWorstAgents(agents []AgentPathStats) {
+	sort.SliceStable(agents, func(i, j int) bool {
+		a, b := agents[i], agents[j]
+		if a.PacketLossPct != b.PacketLossPct {
+			return a.PacketLossPct > b.PacketLossPct
+		}
+		if (a.AvgLatencyMs == nil) != (b.AvgLatencyMs == nil) {
+			return a.AvgLatencyMs != nil
+		}
+		if a.AvgLatencyMs != nil && *a.AvgLatencyMs != *b.AvgLatencyMs {
+			return *a.AvgLatencyMs > *b.AvgLatencyMs
+		}
+		return a.AgentName < b.AgentName
+	})
+}
+
+// BuildTargetWorstAgents ranks agents and keeps the worst limit of them,
+// all of them when limit is zero.
+func BuildTargetWorstAgents(targetID string, window time.Duration, agents []AgentPathStats, limit int) TargetWorstAgents {
+	RankWorstAgents(agents)
+
+	result := TargetWorstAgents{
+		TargetID:   targetID,
+		Window:     window.String(),
+		AgentCount: len(agents),
+		Agents:     agents,
+	}
+	for _, a := range agents {
+		if a.PacketLossPct > 0 {
+			result.AgentsWithLoss++
+		}
+	}
+	if limit > 0 && len(result.Agents) > limit {
+		result.Agents = result.Agents[:limit]
+	}
+	if result.Agents == nil {
+		result.Agents = []AgentPathStats{}
+	}
+	return result
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestBuildTargetWorstAgents_Ranking(t *testing.T) {
+	ms := func(v float64) *float64 { return &v }
+
+	tests := []struct {
+		name         string
+		agents       []AgentPathStats
+		limit        int
+		wantOrder    []string
+		wantWithLoss int
+	}{
+		{
+			name:      "no agents",
+			wantOrder: []string{},
+		},
+		{
+			name: "loss before latency",
+			agents: []AgentPathStats{
+				{AgentName: "slow", PacketLossPct: 0, AvgLatencyMs: ms(200)},
+				{AgentName: "lossy", PacketLossPct: 20, AvgLatencyMs: ms(10)},
+				{AgentName: "fast", PacketLossPct: 0, AvgLatencyMs: ms(5)},
+			},
+			wantOrder:    []string{"lossy", "slow", "fast"},
+			wantWithLoss: 1,
+		},
+		{
+			name: "no latency after latency at equal loss",
+			agents: []AgentPathStats{
+				{AgentName: "blind", PacketLossPct: 100},
+				{AgentName: "seen", PacketLossPct: 100, AvgLatencyMs: ms(50)},
+			},
+			wantOrder:    []string{"seen", "blind"},
+			wantWithLoss: 2,
+		},
+		{
+			name: "ties by name",
+			agents: []AgentPathStats{
+				{AgentName: "b", AvgLatencyMs: ms(10)},
+				{AgentName: "a", AvgLatencyMs: ms(10)},
+			},
+			wantOrder: []string{"a", "b"},
+		},
+		{
+			name: "limit keeps the worst but counts all",
+			agents: []AgentPathStats{
+				{AgentName: "a", PacketLossPct: 5},
+				{AgentName: "b", PacketLossPct: 50},
+				{AgentName: "c", PacketLossPct: 10},
+			},
+			limit:        2,
+			wantOrder:    []string{"b", "c"},
+			wantWithLoss: 3,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := BuildTargetWorstAgents("t1", 15*time.Minute, tt.agents, tt.limit)
+
+			var order []string
+			for _, a := range got.Agents {
+				order = append(order, a.AgentName)
+			}
+			if !slices.Equal(order, tt.wantOrder) {
+				t.Errorf("order = %v, want %v", order, tt.wantOrder)
+			}
+			if got.Agents == nil {
+				t.Error("Agents is nil, want empty slice")
+			}
+			if got.AgentCount != len(tt.agents) {
+				t.Errorf("AgentCount = %d, want %d", got.AgentCount, len(tt.agents))
+			}
+			if got.AgentsWithLoss != tt.wantWithLoss {
+				t.Errorf("AgentsWithLoss = %d, want %d", got.AgentsWithLoss, tt.wantWithLoss)
+			}
+			if got.Window != "15m0s" {
+				t.Errorf("Window = %q, want 15m0s", got.Window)
+			}
+		})
+	}
+}
@@ -197,7 +197,7 @@ export function EnrollAgentModal({ isOpen, onClose, onSuccess, reEnrollAgent = n
 
       if (!response.ok) {
         const errorData = await response.json();
-        throw new Error(errorData.error || 'Enrollment failed');
+        throw new Error(errorData.error?.message || 'Enrollment failed');
       }
 
       // Handle SSE stream
@@ -321,7 +321,7 @@ export function EnrollAgentModal({ isOpen, onClose, onSuccess, reEnrollAgent = n
 
       if (!response.ok) {
         const errorData = await response.json();
-        throw new Error(errorData.error || 'Resume failed');
+        throw new Error(errorData.error?.message || 'Resume failed');
       }
 
       // Handle SSE stream (same as enrollment)
@@ -22,8 +22,9 @@ class ApiClient {
     const response = await fetch(url, config);
 
     if (!response.ok) {
-      const error = await response.json().catch(() => ({}));
-      throw new ApiError(response.status, error.message || response.statusText);
+      const body = await response.json().catch(() => ({}));
+      const error = body.error || {};
+      throw new ApiError(response.status, error.message || response.statusText, error.code, error.details);
     }
 
     if (response.status === 204) {
@@ -50,10 +51,14 @@ class ApiClient {
   }
 }
 
+// ApiError carries the server's stable error code (e.g. "tier_in_use") and
+// optional details alongside the message.
 class ApiError extends Error {
-  constructor(status, message) {
+  constructor(status, message, code, details) {
     super(message);
     this.status = status;
+    this.code = code;
+    this.details = details;
     this.name = 'ApiError';
   }
 }
@@ -74,6 +79,11 @@ export const endpoints = {
   getAgentStats: (id) => api.get(`/agents/${id}/stats`),
   archiveAgent: (id, reason = '') => api.post(`/agents/${id}/archive`, { reason }),
   unarchiveAgent: (id) => api.post(`/agents/${id}/unarchive`),
+  getAgentConfigStatus: (id) => api.get(`/agents/${id}/config/status`),
+  setAgentConfig: (id, config) => api.put(`/agents/${id}/config`, config),
+  deleteAgentConfig: (id) => api.delete(`/agents/${id}/config`),
+  getGlobalAgentConfig: () => api.get('/agent-config'),
+  setGlobalAgentConfig: (config) => api.put('/agent-config', config),
 
   // Fleet overview
   getFleetOverview: () => api.get('/fleet/overview'),
@@ -84,8 +94,10 @@ export const endpoints = {
     const query = new URLSearchParams(params).toString();
     return api.get(`/targets${query ? `?${query}` : ''}`);
   },
-  listTargetsPaginated: ({ limit = 50, offset = 0, tier = '', state = '', search = '', includeArchived = false } = {}) => {
+  // Pass cursor ('' for the first page, then next_cursor) for keyset pagination
+  listTargetsPaginated: ({ limit = 50, offset = 0, cursor, tier = '', state = '', search = '', includeArchived = false } = {}) => {
     const params = new URLSearchParams({ limit, offset });
+    if (cursor !== undefined) params.set('cursor', cursor);
     if (tier) params.set('tier', tier);
     if (state) params.set('state', state);
     if (search) params.set('search', search);
@@ -96,12 +108,25 @@ export const endpoints = {
   getTargetStatus: (id) => api.get(`/targets/${id}/status`),
   getTargetHistory: (id, window = '1h') => api.get(`/targets/${id}/history?window=${window}`),
   getTargetHistoryByAgent: (id, window = '1h') => api.get(`/targets/${id}/history/by-agent?window=${window}`),
+  getTargetHistoryInMarket: (id, window = '1h') => api.get(`/targets/${id}/history/in-market?window=${window}`),
+  getTargetAnnotations: (id, window = '24h') => api.get(`/targets/${id}/annotations?window=${window}`),
+  createTargetAnnotation: (id, data) => api.post(`/targets/${id}/annotations`, data),
+  deleteTargetAnnotation: (id, annotationId) => api.delete(`/targets/${id}/annotations/${annotationId}`),
   getAllTargetStatuses: () => api.get('/targets/status'),
+  getTargetResults: (id, { limit = 100, cursor = '', window = '', agentId = '' } = {}) => {
+    const params = new URLSearchParams({ limit });
+    if (cursor) params.set('cursor', cursor);
+    if (window) params.set('window', window);
+    if (agentId) params.set('agent_id', agentId);
+    return api.get(`/targets/${id}/results?${params.toString()}`);
+  },
   createTarget: (data) => api.post('/targets', data),
   updateTarget: (id, data) => api.put(`/targets/${id}`, data),
   deleteTarget: (id) => api.delete(`/targets/${id}`),
   triggerMTR: (id, agentIds = []) => api.post(`/targets/${id}/mtr`, { agent_ids: agentIds }),
+  triggerPMTUD: (id, agentIds = [], mtu = {}) => api.post(`/targets/${id}/pmtud`, { agent_ids: agentIds, ...mtu }),
   getTargetLive: (id, seconds = 60) => api.get(`/targets/${id}/live?seconds=${seconds}`),
+  getTargetDiagnostics: (id) => api.get(`/diagnostics/target/${id}`),
   getTargetCommands: (id, limit = 20) => api.get(`/targets/${id}/commands?limit=${limit}`),
 
   // Tiers
@@ -109,6 +134,7 @@ export const endpoints = {
   getTier: (name) => api.get(`/tiers/${name}`),
   createTier: (data) => api.post('/tiers', data),
   updateTier: (name, data) => api.put(`/tiers/${name}`, data),
+  previewTier: (name, data) => api.post(`/tiers/${name}/preview`, data),
   deleteTier: (name) => api.delete(`/tiers/${name}`),
 
   // Results
@@ -164,8 +190,20 @@ export const endpoints = {
   recalculateBaselines: () => api.post('/baselines/recalculate'),
 
   // Reports
-  getTargetReport: (targetId, window = '90d') =>
-    api.get(`/reports/targets/${targetId}?window=${window}`),
+  getTargetReport: (targetId, window = '90d', tz = '') =>
+    api.get(`/reports/targets/${targetId}?window=${window}${tz ? `&tz=${encodeURIComponent(tz)}` : ''}`),
+  getTargetReportPdfUrl: (targetId, window = '90d', tz = '') =>
+    `${API_BASE}/reports/targets/${targetId}?window=${window}&format=pdf${tz ? `&tz=${encodeURIComponent(tz)}` : ''}`,
+
+  // Scheduled reports
+  listReportSchedules: () => api.get('/reports/schedules'),
+  getReportSchedule: (id) => api.get(`/reports/schedules/${id}`),
+  createReportSchedule: (data) => api.post('/reports/schedules', data),
+  updateReportSchedule: (id, data) => api.put(`/reports/schedules/${id}`, data),
+  deleteReportSchedule: (id) => api.delete(`/reports/schedules/${id}`),
+  runReportSchedule: (id) => api.post(`/reports/schedules/${id}/run`),
+  listReportDeliveries: (id, limit = 50) =>
+    api.get(`/reports/schedules/${id}/deliveries?limit=${limit}`),
 
   // Enrollment
   listEnrollments: () => api.get('/enrollments'),
@@ -218,8 +256,9 @@ export const endpoints = {
 
   // Subnets
   listSubnets: (includeArchived = true) => api.get(`/subnets?include_archived=${includeArchived}`),
-  listSubnetsPaginated: ({ limit = 50, offset = 0, pop = '', city = '', region = '', service_status = '', search = '', includeArchived = true } = {}) => {
+  listSubnetsPaginated: ({ limit = 50, offset = 0, cursor, pop = '', city = '', region = '', service_status = '', search = '', includeArchived = true } = {}) => {
     const params = new URLSearchParams({ limit, offset });
+    if (cursor !== undefined) params.set('cursor', cursor);
     if (pop) params.set('pop', pop);
     if (city) params.set('city', city);
     if (region) params.set('region', region);
@@ -237,8 +276,11 @@ export const endpoints = {
 
   // Target state
   getTargetStateHistory: (id, limit = 50) => api.get(`/targets/${id}/state-history?limit=${limit}`),
+  getTargetHops: (id, window = '24h') => api.get(`/targets/${id}/hops?window=${window}`),
   transitionTargetState: (id, newState, reason = '') =>
     api.post(`/targets/${id}/state`, { new_state: newState, reason }),
+  disableTargetProbing: (id, reason = '') => api.post(`/targets/${id}/disable`, { reason }),
+  enableTargetProbing: (id, reason = '') => api.post(`/targets/${id}/enable`, { reason }),
 
   // Review Queue (targets needing review)
   getReviewQueue: () => api.get('/targets/review'),
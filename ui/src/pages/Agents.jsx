@@ -17,6 +17,7 @@ import {
   CheckCircle,
   XCircle,
   Loader2,
+  Upload,
 } from 'lucide-react';
 
 import { PageHeader, PageContent } from '../components/Layout';
@@ -27,7 +28,7 @@ import { Button } from '../components/Button';
 import { SearchInput, Select } from '../components/Input';
 import { Table, TableHeader, TableBody, TableRow, TableHead, TableCell, MobileCardList, MobileCard, MobileCardRow } from '../components/Table';
 import { EnrollAgentModal } from '../components/EnrollAgentModal';
-import { formatRelativeTime } from '../lib/utils';
+import { formatRelativeTime, formatBytes } from '../lib/utils';
 import { endpoints } from '../lib/api';
 
 const regions = [
@@ -325,6 +326,43 @@ export function Agents() {
                 </div>
               </div>
 
+              {/* Result Shipping */}
+              {fleetOverview.shipment && (
+                <div className="mt-3 sm:mt-4 pt-3 sm:pt-4 border-t border-border-subtle">
+                  <div className="flex flex-wrap items-center gap-x-4 gap-y-1 text-xs text-theme-muted">
+                    <span className="flex items-center gap-1.5 text-sm text-theme-primary font-medium">
+                      <Upload className="w-4 h-4 text-theme-muted" />
+                      Result Shipping
+                      <span className="text-xs text-theme-muted font-normal">(last {fleetOverview.shipment.window})</span>
+                    </span>
+                    <span>{formatBytes(Math.round(fleetOverview.shipment.bytes_per_second))}/s ingest</span>
+                    <span>{formatBytes(fleetOverview.shipment.bytes_shipped)} shipped</span>
+                    {fleetOverview.shipment.compression_ratio != null && (
+                      <span>{fleetOverview.shipment.compression_ratio.toFixed(1)}x compression</span>
+                    )}
+                    <span>{fleetOverview.shipment.batches_shipped.toLocaleString()} batches</span>
+                    <span className={fleetOverview.shipment.ship_failures > 0 ? 'text-pilot-red' : ''}>
+                      {fleetOverview.shipment.ship_failures.toLocaleString()} failed sends
+                    </span>
+                  </div>
+                  {fleetOverview.shipment.large_payload_agents?.length > 0 && (
+                    <div className="mt-2 space-y-1">
+                      {fleetOverview.shipment.large_payload_agents.map((a) => (
+                        <button
+                          key={a.agent_id}
+                          onClick={() => navigate(`/agents/${a.agent_id}`)}
+                          className="flex items-center gap-2 text-xs text-pilot-yellow hover:underline"
+                        >
+                          <AlertTriangle className="w-3 h-3 flex-shrink-0" />
+                          <span className="font-medium">{a.agent_name}</span>
+                          <span className="text-theme-muted">{a.reason}</span>
+                        </button>
+                      ))}
+                    </div>
+                  )}
+                </div>
+              )}
+
               {/* Rebalance Button */}
               <div className="mt-3 sm:mt-4 pt-3 sm:pt-4 border-t border-border-subtle flex flex-col sm:flex-row sm:items-center gap-3 sm:justify-between">
                 <div className="flex items-center gap-2 sm:gap-3">
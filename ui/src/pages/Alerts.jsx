@@ -265,6 +265,33 @@ export function Alerts() {
                 {correlations.total_active_alerts} active alerts
               </span>
             </div>
+            {/* Probable causes, ranked by confidence */}
+            {correlations.probable_causes?.length > 0 && (
+              <div className="mb-4 space-y-2">
+                <div className="text-xs text-theme-muted uppercase tracking-wide">Probable Causes</div>
+                {correlations.probable_causes.slice(0, 5).map((cause) => (
+                  <div
+                    key={cause.correlation_key}
+                    className={`flex items-center justify-between px-3 py-2 rounded-lg cursor-pointer hover:bg-neutral-700/30 ${
+                      cause.severity === 'critical' ? 'bg-pilot-red/10 border border-pilot-red/30' :
+                      cause.severity === 'warning' ? 'bg-warning/10 border border-warning/30' :
+                      'bg-neutral-700/20'
+                    }`}
+                    onClick={() => setSearch(cause.primary_entity.name || cause.primary_entity.id)}
+                  >
+                    <span className="flex items-center gap-2">
+                      <span className="text-xs text-theme-muted uppercase w-16">{cause.primary_entity.type}</span>
+                      <span className="text-sm text-theme-primary truncate max-w-[240px]">
+                        {cause.primary_entity.name || cause.primary_entity.id}
+                      </span>
+                    </span>
+                    <span className="text-sm text-theme-secondary">
+                      {cause.alert_count} alerts · {cause.target_count} targets · {Math.round(cause.confidence * 100)}%
+                    </span>
+                  </div>
+                ))}
+              </div>
+            )}
             <div className="grid grid-cols-1 md:grid-cols-2 lg:grid-cols-3 gap-4">
               {/* POP Correlations */}
               {correlations.by_pop?.length > 0 && (
@@ -66,7 +66,10 @@ function TierModal({ isOpen, onClose, tier, onSave }) {
   });
   const [saving, setSaving] = useState(false);
   const [error, setError] = useState(null);
+  const [preview, setPreview] = useState(null);
   const isEditing = !!tier;
+  const currentIntervalSeconds = tier ? Math.floor((tier.probe_interval || 30000000000) / 1000000000) : null;
+  const intervalChanged = isEditing && formData.probe_interval_seconds !== currentIntervalSeconds;
 
   useEffect(() => {
     if (tier) {
@@ -100,8 +103,27 @@ function TierModal({ isOpen, onClose, tier, onSave }) {
       });
     }
     setError(null);
+    setPreview(null);
   }, [tier, isOpen]);
 
+  // Preview the probe volume change before an interval edit is saved
+  useEffect(() => {
+    if (!intervalChanged) {
+      setPreview(null);
+      return;
+    }
+    let cancelled = false;
+    const timer = setTimeout(() => {
+      endpoints.previewTier(tier.name, { probe_interval_seconds: formData.probe_interval_seconds })
+        .then((res) => { if (!cancelled) setPreview(res); })
+        .catch(() => { if (!cancelled) setPreview(null); });
+    }, 300);
+    return () => {
+      cancelled = true;
+      clearTimeout(timer);
+    };
+  }, [intervalChanged, tier, formData.probe_interval_seconds]);
+
   const handleSubmit = async () => {
     setError(null);
     setSaving(true);
@@ -200,6 +222,26 @@ function TierModal({ isOpen, onClose, tier, onSave }) {
           </div>
         </div>
 
+        {preview && (
+          <div className="p-3 bg-surface-tertiary border border-theme rounded-lg text-sm space-y-1">
+            <div className="text-theme-secondary">
+              Probe volume: {preview.current_probes_per_second.toFixed(1)}/s
+              {' → '}{preview.proposed_probes_per_second.toFixed(1)}/s
+              {' '}({preview.delta_probes_per_second >= 0 ? '+' : ''}{preview.delta_probes_per_second.toFixed(1)}/s
+              {' across '}{preview.assignments} assignments)
+            </div>
+            {preview.agents?.length > 0 && (
+              <div className="text-xs text-theme-muted">
+                Most affected agent: {preview.agents[0].agent_name} ({preview.agents[0].current_probes_per_second.toFixed(1)}/s
+                {' → '}{preview.agents[0].proposed_probes_per_second.toFixed(1)}/s)
+              </div>
+            )}
+            {preview.warnings?.map((w) => (
+              <div key={w} className="text-xs text-pilot-yellow">{w}</div>
+            ))}
+          </div>
+        )}
+
         <div className="border-t border-theme pt-4">
           <h4 className="text-sm font-medium text-theme-secondary mb-3 flex items-center gap-2">
             <Users className="w-4 h-4" />
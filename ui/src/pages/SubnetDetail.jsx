@@ -53,6 +53,7 @@ const eventTypeStyles = {
   archived: { bg: 'bg-pilot-red/20', text: 'text-pilot-red' },
   state_change: { bg: 'bg-accent/20', text: 'text-accent' },
   transitioned: { bg: 'bg-accent/20', text: 'text-accent' },
+  service_status_changed: { bg: 'bg-accent/20', text: 'text-accent' },
 };
 
 const severityColors = {
@@ -128,6 +129,13 @@ function ActivityLog({ activity, loading }) {
                         <span>{entry.details.to_state}</span>
                       </span>
                     )}
+                    {entry.details.from_status && entry.details.to_status && (
+                      <span className="inline-flex items-center gap-1">
+                        <span className="text-theme-muted">service {entry.details.from_status}</span>
+                        <ArrowRight className="w-3 h-3" />
+                        <span>{entry.details.to_status}</span>
+                      </span>
+                    )}
                     {entry.details.reason && (
                       <span className="text-theme-muted ml-2">({entry.details.reason})</span>
                     )}
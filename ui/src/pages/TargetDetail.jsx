@@ -28,6 +28,8 @@ import {
   Tooltip,
   ResponsiveContainer,
   Legend,
+  ReferenceLine,
+  ReferenceArea,
 } from 'recharts';
 
 import { PageHeader, PageContent } from '../components/Layout';
@@ -213,7 +215,44 @@ function TargetModal({ isOpen, onClose, target, tiers, onSave }) {
   );
 }
 
-function PerAgentChart({ data, visibleAgents, metric = 'latency', timeWindow }) {
+const annotationColors = { manual: '#F59E0B', incident: '#EF4444' };
+
+// Snap an annotation time to the label of the nearest chart point, since the
+// x-axis is categorical.
+function nearestTimeLabel(chartData, time) {
+  const t = new Date(time).getTime();
+  let best = null;
+  let bestDiff = Infinity;
+  chartData.forEach(point => {
+    const diff = Math.abs(new Date(point.time).getTime() - t);
+    if (diff < bestDiff) {
+      bestDiff = diff;
+      best = point.timeLabel;
+    }
+  });
+  return best;
+}
+
+function annotationOverlay({ annotations, chartData }) {
+  if (!annotations || annotations.length === 0 || chartData.length === 0) return null;
+  return annotations.map(a => {
+    const color = annotationColors[a.source] || annotationColors.manual;
+    const start = nearestTimeLabel(chartData, a.starts_at);
+    const end = a.ends_at ? nearestTimeLabel(chartData, a.ends_at) : null;
+    if (end && end !== start) {
+      return (
+        <ReferenceArea key={`${a.source}-${a.id}`} x1={start} x2={end} fill={color} fillOpacity={0.08} stroke={color} strokeOpacity={0.3}
+          label={{ value: a.text, position: 'insideTopLeft', fill: color, fontSize: 10 }} />
+      );
+    }
+    return (
+      <ReferenceLine key={`${a.source}-${a.id}`} x={start} stroke={color} strokeDasharray="3 3"
+        label={{ value: a.text, position: 'insideTopLeft', fill: color, fontSize: 10 }} />
+    );
+  });
+}
+
+function PerAgentChart({ data, visibleAgents, metric = 'latency', timeWindow, annotations = [] }) {
   if (!data || data.length === 0) {
     return (
       <div className="h-64 flex items-center justify-center text-theme-muted">
@@ -324,6 +363,7 @@ function PerAgentChart({ data, visibleAgents, metric = 'latency', timeWindow })
             />
           );
         })}
+        {annotationOverlay({ annotations, chartData })}
       </LineChart>
     </ResponsiveContainer>
   );
@@ -669,6 +709,7 @@ export function TargetDetail() {
   const [agents, setAgents] = useState([]);
   const [targetHistory, setTargetHistory] = useState([]);
   const [perAgentHistory, setPerAgentHistory] = useState([]);
+  const [historyAnnotations, setHistoryAnnotations] = useState([]);
   const [historyLoading, setHistoryLoading] = useState(false);
   const [timeWindow, setTimeWindow] = useState('1h');
   const [chartMetric, setChartMetric] = useState('latency');
@@ -678,6 +719,7 @@ export function TargetDetail() {
   const [showDeleteConfirm, setShowDeleteConfirm] = useState(false);
   const [deleteLoading, setDeleteLoading] = useState(false);
   const [mtrLoading, setMtrLoading] = useState(false);
+  const [pmtudLoading, setPmtudLoading] = useState(false);
   const [mtrResult, setMtrResult] = useState(null);
   const [historicalMtrResult, setHistoricalMtrResult] = useState(null);
   const [commandHistory, setCommandHistory] = useState([]);
@@ -732,10 +774,12 @@ export function TargetDetail() {
       ]);
       setTargetHistory(aggregatedRes.history || []);
       setPerAgentHistory(perAgentRes.history || []);
+      setHistoryAnnotations(perAgentRes.annotations || []);
     } catch (err) {
       console.error('Failed to fetch target history:', err);
       setTargetHistory([]);
       setPerAgentHistory([]);
+      setHistoryAnnotations([]);
     } finally {
       setHistoryLoading(false);
     }
@@ -770,6 +814,21 @@ export function TargetDetail() {
     }
   };
 
+  // Path MTU results land in the diagnostic history once agents report
+  const handleTriggerPMTUD = async () => {
+    if (!target) return;
+    setPmtudLoading(true);
+    try {
+      await endpoints.triggerPMTUD(target.id);
+      refreshCommandHistory();
+      setTimeout(refreshCommandHistory, 30000);
+    } catch (err) {
+      console.error('Failed to trigger path MTU discovery:', err);
+    } finally {
+      setPmtudLoading(false);
+    }
+  };
+
   const pollMTRResults = async (commandId) => {
     let attempts = 0;
     const maxAttempts = 30;
@@ -1034,6 +1093,9 @@ export function TargetDetail() {
                   </div>
                 )}
               </div>
+              <Button variant="secondary" size="sm" className="gap-1" onClick={handleTriggerPMTUD} disabled={pmtudLoading}>
+                {pmtudLoading ? <RefreshCw className="w-3 h-3 animate-spin" /> : <ExternalLink className="w-3 h-3" />}Path MTU
+              </Button>
             </div>
           </div>
 
@@ -1158,7 +1220,7 @@ export function TargetDetail() {
                 {historyLoading ? (
                   <div className="h-64 flex items-center justify-center"><RefreshCw className="w-6 h-6 animate-spin text-theme-muted" /></div>
                 ) : (
-                  <PerAgentChart data={perAgentHistory} visibleAgents={visibleChartAgents} metric={chartMetric} timeWindow={timeWindow} />
+                  <PerAgentChart data={perAgentHistory} visibleAgents={visibleChartAgents} metric={chartMetric} timeWindow={timeWindow} annotations={historyAnnotations} />
                 )}
               </>
             )}
@@ -1338,13 +1400,28 @@ export function TargetDetail() {
                           <div className="flex items-center gap-2">
                             <span className="text-theme-primary font-medium">{result.agent_name}</span>
                             <span className="text-theme-muted">→</span>
-                            <span className="text-theme-muted font-mono">{result.payload?.target}</span>
+                            <span className="text-theme-muted font-mono">{result.payload?.target || result.payload?.target_ip}</span>
                           </div>
                           <span className={`text-sm ${result.success ? 'text-status-healthy' : 'text-pilot-red'}`}>
-                            {result.success ? (result.payload?.reached_dst ? 'Reached' : 'Unreachable') : 'Failed'}
+                            {historicalMtrResult.commandType === 'pmtud'
+                              ? (result.success ? `MTU ${result.payload.path_mtu}${result.payload.at_max ? '+' : ''}` : 'Failed')
+                              : result.success ? (result.payload?.reached_dst ? 'Reached' : 'Unreachable') : 'Failed'}
                           </span>
                         </div>
-                        {result.payload?.hops?.length > 0 ? (
+                        {historicalMtrResult.commandType === 'pmtud' ? (
+                          <div className="text-xs font-mono text-theme-secondary">
+                            {result.payload?.probes?.length > 0 && (
+                              <div className="flex flex-wrap gap-2">
+                                {result.payload.probes.map((probe, probeIdx) => (
+                                  <span key={probeIdx} className={probe.replied ? 'text-status-healthy' : 'text-pilot-red'}>
+                                    {probe.size}
+                                  </span>
+                                ))}
+                              </div>
+                            )}
+                            {result.error && <div className="text-pilot-red text-sm mt-1">{result.error}</div>}
+                          </div>
+                        ) : result.payload?.hops?.length > 0 ? (
                           <div className="overflow-x-auto">
                             <table className="w-full text-xs font-mono">
                               <thead>